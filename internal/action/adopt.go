@@ -0,0 +1,239 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/internal/store/root"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/urfave/cli/v2"
+)
+
+// adoptReport describes an existing password store
+type adoptReport struct {
+	// entries is the number of secrets
+	entries int
+	// scopes maps every folder with its own id file to its recipients. The
+	// root of the store is the empty string.
+	scopes map[string][]string
+	// noKey contains the scopes none of the local private keys can decrypt
+	noKey map[string]bool
+	// git is true if the store is a git repo
+	git bool
+	// quirks are things gopass handles differently than pass
+	quirks []string
+}
+
+// setupAdopt registers an existing pass store as the root store or as a mount.
+// Only the gopass config is written, the store itself is left alone.
+func (s *Action) setupAdopt(ctx context.Context, c *cli.Context, path, alias string) error {
+	path = fsutil.CleanPath(path)
+	if !fsutil.IsDir(path) {
+		return ExitError(ExitNotFound, nil, "%s is not a directory", path)
+	}
+
+	if !c.IsSet("storage") {
+		sb := backend.FS
+		if fsutil.IsDir(filepath.Join(path, ".git")) {
+			sb = backend.GitFS
+		}
+		ctx = backend.WithStorageBackend(ctx, sb)
+	}
+
+	crypto, err := backend.NewCrypto(ctx, backend.GetCryptoBackend(ctx))
+	if err != nil {
+		return ExitError(ExitUnsupported, err, "can not continue without crypto: %s", err)
+	}
+	if !fsutil.IsFile(filepath.Join(path, crypto.IDFile())) {
+		return ExitError(ExitNotInitialized, nil, "%s has no %s file, it's not a password store", path, crypto.IDFile())
+	}
+
+	s.Store = root.New(s.cfg)
+	inited, err := s.Store.IsInitialized(ctx)
+	if err != nil {
+		return ExitError(ExitUnknown, err, "Failed to initialize store: %s", err)
+	}
+	switch {
+	case alias == "" && inited && fsutil.CleanPath(s.cfg.Path) != path:
+		return ExitError(ExitAlreadyInitialized, nil, "There already is a root store at %s. Use --mount to add %s as a mount", s.cfg.Path, path)
+	case alias != "" && !inited:
+		return ExitError(ExitNotInitialized, nil, "There is no root store to mount %s into. Adopt it without --mount or run '%s setup' first", path, s.Name)
+	}
+
+	report, err := adoptScan(ctx, crypto, path)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read %s: %s", path, err)
+	}
+	if !report.hasUseableKey() {
+		return ExitError(ExitRecipients, nil, "none of the recipients of %s has a usable private key. Nothing was changed", path)
+	}
+
+	if alias == "" {
+		s.cfg.Path = path
+		s.Store = root.New(s.cfg)
+	} else if err := s.Store.AddMount(ctx, alias, path); err != nil {
+		return ExitError(ExitMount, err, "failed to add mount %q: %s", alias, err)
+	}
+	debug.Log("Writing configuration to %q", s.cfg.ConfigPath)
+	if err := s.cfg.Save(); err != nil {
+		return ExitError(ExitConfig, err, "failed to write config: %s", err)
+	}
+
+	s.printAdoptReport(ctx, path, alias, report)
+	return nil
+}
+
+// adoptScan collects the secrets, recipients and quirks of the store at path
+// without changing anything
+func adoptScan(ctx context.Context, crypto backend.Crypto, path string) (*adoptReport, error) {
+	report := &adoptReport{
+		scopes: make(map[string][]string, 1),
+		noKey:  make(map[string]bool),
+		git:    fsutil.IsDir(filepath.Join(path, ".git")),
+	}
+	ext := "." + crypto.Ext()
+	// other files by extension
+	others := make(map[string][]string)
+
+	err := filepath.WalkDir(path, func(fn string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, fn)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		name := d.Name()
+
+		if d.IsDir() {
+			switch {
+			case rel == ".":
+				return nil
+			case name == ".git" || name == ".public-keys" || name == ".gpg-keys":
+				return filepath.SkipDir
+			case strings.HasPrefix(name, "."):
+				report.quirks = append(report.quirks, fmt.Sprintf("The hidden folder %s is ignored by gopass", rel))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case name == crypto.IDFile():
+			buf, err := os.ReadFile(fn)
+			if err != nil {
+				return err
+			}
+			dir := filepath.ToSlash(filepath.Dir(rel))
+			if dir == "." {
+				dir = ""
+			}
+			rs := recipients.Unmarshal(buf)
+			sort.Strings(rs)
+			report.scopes[dir] = rs
+		case name == crypto.IDFile()+".sig":
+			report.quirks = append(report.quirks, fmt.Sprintf("%s signs the recipients, gopass doesn't verify it", rel))
+		case strings.HasSuffix(name, ext):
+			report.entries++
+		case name == ".gitattributes" || name == ".gitignore":
+		default:
+			e := filepath.Ext(name)
+			others[e] = append(others[e], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exts := make([]string, 0, len(others))
+	for e := range others {
+		exts = append(exts, e)
+	}
+	sort.Strings(exts)
+	for _, e := range exts {
+		files := others[e]
+		if len(files) > 3 {
+			files = append(files[:3:3], fmt.Sprintf("and %d more", len(others[e])-3))
+		}
+		switch {
+		case strings.EqualFold(e, ext):
+			report.quirks = append(report.quirks, fmt.Sprintf("Secrets must end in %s, gopass ignores %s", ext, strings.Join(files, ", ")))
+		case e == "":
+			report.quirks = append(report.quirks, fmt.Sprintf("Files without an extension are ignored by gopass: %s", strings.Join(files, ", ")))
+		default:
+			report.quirks = append(report.quirks, fmt.Sprintf("Files ending in %s are ignored by gopass: %s", e, strings.Join(files, ", ")))
+		}
+	}
+
+	// only count "usable" keys, see initHasUseablePrivateKeys
+	ctx = gpg.WithAlwaysTrust(ctx, false)
+	for _, dir := range report.dirs() {
+		rs := report.scopes[dir]
+		if len(rs) < 1 {
+			report.noKey[dir] = true
+			report.quirks = append(report.quirks, fmt.Sprintf("The recipients file of /%s is empty", dir))
+			continue
+		}
+		kl, err := crypto.FindIdentities(ctx, rs...)
+		if err != nil {
+			debug.Log("failed to find private keys for %+v: %s", rs, err)
+		}
+		if len(kl) < 1 {
+			report.noKey[dir] = true
+			report.quirks = append(report.quirks, fmt.Sprintf("You have no usable private key for the recipients of /%s, gopass can't decrypt the secrets there", dir))
+		}
+	}
+	return report, nil
+}
+
+// dirs returns the sorted folders with their own recipients
+func (r *adoptReport) dirs() []string {
+	dirs := make([]string, 0, len(r.scopes))
+	for dir := range r.scopes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// hasUseableKey returns true if at least one scope can be decrypted with a
+// local private key
+func (r *adoptReport) hasUseableKey() bool {
+	for dir := range r.scopes {
+		if !r.noKey[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// printAdoptReport prints a summary of the adopted store
+func (s *Action) printAdoptReport(ctx context.Context, path, alias string, report *adoptReport) {
+	if alias == "" {
+		out.OKf(ctx, "Adopted %s as the root store", path)
+	} else {
+		out.OKf(ctx, "Adopted %s as the mount %s", path, alias)
+	}
+	out.Printf(ctx, "📦 %d secrets", report.entries)
+	for _, dir := range report.dirs() {
+		out.Printf(ctx, "📩 /%s: %s", dir, strings.Join(report.scopes[dir], ", "))
+	}
+	if !report.git {
+		out.Printf(ctx, "The store is not a git repo. Run '%s git init' to track changes", s.Name)
+	}
+	for _, q := range report.quirks {
+		out.Warningf(ctx, "%s", q)
+	}
+}
@@ -0,0 +1,115 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupAdopt(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	dir := filepath.Join(u.Dir, "pass")
+	for fn, content := range map[string]string{
+		".plain-id":             "DEADBEEF\n",
+		"web/github.txt":        "secret",
+		"web/gitlab.txt":        "secret",
+		"bank.TXT":              "secret",
+		"notes.md":              "notes",
+		"work/.plain-id":        "0xCAFEBABE\n",
+		"work/vpn.txt":          "secret",
+		".extensions/foo.bash":  "echo",
+		".public-keys/DEADBEEF": "key",
+		"web/.plain-id.sig":     "sig",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, fn)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fn), []byte(content), 0600))
+	}
+
+	t.Run("scan", func(t *testing.T) {
+		report, err := adoptScan(ctx, act.Store.Crypto(ctx, ""), dir)
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.entries)
+		assert.Equal(t, map[string][]string{"": {"DEADBEEF"}, "work": {"0xCAFEBABE"}}, report.scopes)
+		assert.False(t, report.git)
+		assert.True(t, report.hasUseableKey())
+		assert.Equal(t, []string{
+			"The hidden folder .extensions is ignored by gopass",
+			"web/.plain-id.sig signs the recipients, gopass doesn't verify it",
+			"Secrets must end in .txt, gopass ignores bank.TXT",
+			"Files ending in .md are ignored by gopass: notes.md",
+			"You have no usable private key for the recipients of /work, gopass can't decrypt the secrets there",
+		}, report.quirks)
+	})
+
+	t.Run("root store exists", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"adopt": dir})))
+	})
+
+	t.Run("not a store", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"adopt": filepath.Join(dir, "web"), "mount": "web"})))
+	})
+
+	t.Run("no usable key", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"adopt": filepath.Join(dir, "work"), "mount": "work"})))
+		assert.NotContains(t, act.cfg.Mounts, "work")
+	})
+
+	t.Run("mount", func(t *testing.T) {
+		defer buf.Reset()
+		before := listFiles(t, dir)
+		require.NoError(t, act.Setup(gptest.CliCtxWithFlags(ctx, t, map[string]string{"adopt": dir, "mount": "pass"})))
+		assert.Equal(t, dir, act.cfg.Mounts["pass"])
+		assert.Equal(t, before, listFiles(t, dir))
+		assert.Contains(t, buf.String(), "3 secrets")
+		assert.Contains(t, buf.String(), "/work: 0xCAFEBABE")
+
+		assert.True(t, act.Store.Exists(ctx, "pass/web/github"))
+	})
+}
+
+// listFiles returns all files below dir
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	var files []string
+	require.NoError(t, filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		files = append(files, path)
+		return nil
+	}))
+	return files
+}
@@ -0,0 +1,157 @@
+package action
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/age"
+	gpgcli "github.com/gopasspw/gopass/internal/backend/crypto/gpg/cli"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Agent runs the age agent in the foreground until it is interrupted
+func (s *Action) Agent(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	a, err := age.New()
+	if err != nil {
+		return ExitError(ExitUnknown, err, "failed to initialize age: %s", err)
+	}
+	if !s.cfg.AgeAgent {
+		out.Noticef(ctx, "The agent is not used until it is enabled with '%s config ageagent true'", s.Name)
+	}
+
+	ag := age.NewAgent(a, c.Duration("ttl"))
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ag.SetActivity(s.startAutoLock(actx, func(reason string) {
+		ag.Lock()
+		out.Noticef(ctx, "Locked the agent %s", s.lockReason(reason))
+	}))
+
+	out.Printf(ctx, "Listening on %s. Press Ctrl+C to stop the agent.", age.AgentSocket())
+	if err := ag.Run(ctx); err != nil {
+		return ExitError(ExitUnsupported, err, "failed to run the agent: %s", err)
+	}
+	return nil
+}
+
+// AgentLock purges the identities held by the age agent
+func (s *Action) AgentLock(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	a, err := age.New()
+	if err != nil {
+		return ExitError(ExitUnknown, err, "failed to initialize age: %s", err)
+	}
+	if err := a.AgentLock(); err != nil {
+		if errors.Is(err, age.ErrAgentNotRunning) {
+			out.Noticef(ctx, "The agent is not running")
+			return nil
+		}
+		return ExitError(ExitIO, err, "failed to lock the agent: %s", err)
+	}
+	out.OKf(ctx, "Agent locked")
+	return nil
+}
+
+// gpgAgent is implemented by crypto backends using the gpg-agent
+type gpgAgent interface {
+	AgentStatus(context.Context) ([]gpgcli.AgentKey, error)
+	AgentClear(context.Context, bool) (int, error)
+}
+
+// gpgAgents returns the gpg backends of the root store and all mounts
+func (s *Action) gpgAgents(ctx context.Context) []gpgAgent {
+	var agents []gpgAgent
+	for _, mp := range append([]string{""}, s.Store.MountPoints()...) {
+		if ga, ok := s.Store.Crypto(ctx, mp).(gpgAgent); ok {
+			agents = append(agents, ga)
+		}
+	}
+	return agents
+}
+
+// AgentStatus shows for each secret gpg key if the gpg-agent has cached its
+// passphrase
+func (s *Action) AgentStatus(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	agents := s.gpgAgents(ctx)
+	if len(agents) < 1 {
+		out.Noticef(ctx, "None of the stores uses gpg")
+		return nil
+	}
+
+	seen := make(map[string]bool, len(agents))
+	for _, ga := range agents {
+		keys, err := ga.AgentStatus(ctx)
+		if err != nil {
+			if errors.Is(err, gpgcli.ErrAgentNotRunning) {
+				out.Noticef(ctx, "The gpg-agent is not running, no passphrases are cached")
+				continue
+			}
+			return ExitError(ExitGPG, err, "failed to ask the gpg-agent: %s", err)
+		}
+		for _, k := range keys {
+			if seen[k.Key.Fingerprint] {
+				continue
+			}
+			seen[k.Key.Fingerprint] = true
+			if k.Cached {
+				out.Printf(ctx, "🔓 %s: passphrase cached", k.Key.OneLine())
+				continue
+			}
+			out.Printf(ctx, "🔒 %s: not cached", k.Key.OneLine())
+		}
+	}
+	return nil
+}
+
+// AgentClear makes the gpg-agent forget the passphrases of the secret gpg
+// keys, or all passphrases with --all
+func (s *Action) AgentClear(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	agents := s.gpgAgents(ctx)
+	if len(agents) < 1 {
+		out.Noticef(ctx, "None of the stores uses gpg")
+		return nil
+	}
+
+	n, err := clearAgents(ctx, agents, c.Bool("all"))
+	if err != nil {
+		if errors.Is(err, gpgcli.ErrAgentNotRunning) {
+			out.Noticef(ctx, "The gpg-agent is not running, no passphrases are cached")
+			return nil
+		}
+		return ExitError(ExitGPG, err, "failed to clear the cache of the gpg-agent: %s", err)
+	}
+	if c.Bool("all") {
+		out.OKf(ctx, "Reloaded the gpg-agent, it has forgotten all passphrases")
+		return nil
+	}
+	if n < 1 {
+		out.OKf(ctx, "No passphrases of your keys were cached")
+		return nil
+	}
+	out.OKf(ctx, "Cleared %d cached passphrases", n)
+	return nil
+}
+
+// clearAgents clears the caches of the gpg-agents and returns the number of
+// passphrases that have been forgotten
+func clearAgents(ctx context.Context, agents []gpgAgent, all bool) (int, error) {
+	total := 0
+	for _, ga := range agents {
+		n, err := ga.AgentClear(ctx, all)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
@@ -0,0 +1,73 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	gpgcli "github.com/gopasspw/gopass/internal/backend/crypto/gpg/cli"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGPGAgent struct {
+	cached  int
+	running bool
+}
+
+func (f *fakeGPGAgent) AgentStatus(context.Context) ([]gpgcli.AgentKey, error) {
+	return nil, nil
+}
+
+func (f *fakeGPGAgent) AgentClear(context.Context, bool) (int, error) {
+	if !f.running {
+		return 0, gpgcli.ErrAgentNotRunning
+	}
+	n := f.cached
+	f.cached = 0
+	return n, nil
+}
+
+func TestAgentStatus(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	// the mock store doesn't use gpg
+	assert.NoError(t, act.AgentStatus(gptest.CliCtx(ctx, t)))
+	assert.Contains(t, buf.String(), "None of the stores uses gpg")
+	buf.Reset()
+	assert.NoError(t, act.AgentClear(gptest.CliCtx(ctx, t)))
+	assert.Contains(t, buf.String(), "None of the stores uses gpg")
+}
+
+func TestClearAgents(t *testing.T) {
+	ctx := context.Background()
+
+	a, b := &fakeGPGAgent{cached: 2, running: true}, &fakeGPGAgent{cached: 1, running: true}
+	n, err := clearAgents(ctx, []gpgAgent{a, b}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = clearAgents(ctx, []gpgAgent{a, b}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	_, err = clearAgents(ctx, []gpgAgent{&fakeGPGAgent{}}, false)
+	assert.ErrorIs(t, err, gpgcli.ErrAgentNotRunning)
+}
@@ -32,11 +32,11 @@ func (s *Action) AliasesAdd(c *cli.Context) error {
 	alias := c.Args().Get(1)
 
 	if domain == "" || alias == "" {
-		return ExitError(ExitUsage, nil, "Usage: %s alias add <domain> <alias>", s.Name)
+		return ExitError(ExitUsage, nil, "Usage: %s alias domains add <domain> <alias>", s.Name)
 	}
 
 	if err := pwrules.AddCustomAlias(domain, alias); err != nil {
-		return err
+		return ExitError(ExitIO, err, "failed to add alias %q to domain %q: %s", alias, domain, err)
 	}
 
 	out.Printf(ctx, "Added alias %q to domain %q", alias, domain)
@@ -50,11 +50,11 @@ func (s *Action) AliasesRemove(c *cli.Context) error {
 	alias := c.Args().Get(1)
 
 	if domain == "" || alias == "" {
-		return ExitError(ExitUsage, nil, "Usage: %s alias remove <domain> <alias>", s.Name)
+		return ExitError(ExitUsage, nil, "Usage: %s alias domains remove <domain> <alias>", s.Name)
 	}
 
 	if err := pwrules.RemoveCustomAlias(domain, alias); err != nil {
-		return err
+		return ExitError(ExitIO, err, "failed to remove alias %q from domain %q: %s", alias, domain, err)
 	}
 
 	out.Printf(ctx, "Remove alias %q from domain %q", alias, domain)
@@ -67,11 +67,11 @@ func (s *Action) AliasesDelete(c *cli.Context) error {
 	domain := c.Args().First()
 
 	if domain == "" {
-		return ExitError(ExitUsage, nil, "Usage: %s alias delete <domain>", s.Name)
+		return ExitError(ExitUsage, nil, "Usage: %s alias domains delete <domain>", s.Name)
 	}
 
 	if err := pwrules.DeleteCustomAlias(domain); err != nil {
-		return err
+		return ExitError(ExitIO, err, "failed to remove aliases for domain %q: %s", domain, err)
 	}
 
 	out.Printf(ctx, "Remove aliases for domain %q", domain)
@@ -1,11 +1,17 @@
 package action
 
 import (
+	"context"
+	"time"
+
 	"github.com/gopasspw/gopass/internal/audit"
+	"github.com/gopasspw/gopass/internal/expiry"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/hibp/bloom"
+	"github.com/gopasspw/gopass/pkg/hibp/dump"
 
 	"github.com/urfave/cli/v2"
 )
@@ -15,28 +21,174 @@ func (s *Action) Audit(c *cli.Context) error {
 	s.rem.Reset("audit")
 
 	filter := c.Args().First()
+	ctx, err := auditParseArgs(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	structured := out.IsStructured(audit.GetFormat(ctx))
+	if !structured {
+		out.Print(ctx, "Auditing passwords for common flaws ...")
+	}
+	list, err := s.auditList(ctx, filter, c.StringSlice("exclude"))
+	if err != nil {
+		return err
+	}
+
+	// structured output always contains the (empty) list of findings
+	if len(list) < 1 && !structured {
+		out.Printf(ctx, "No secrets found")
+		return nil
+	}
+
+	if err := s.warmup(ctx, c, list); err != nil {
+		return err
+	}
+	if err := audit.Batch(withJobs(ctx, c), list, s.Store); err != nil {
+		return ExitError(ExitAudit, err, "%s", err)
+	}
+	return nil
+}
+
+// AuditHIBP checks all passwords against the haveibeenpwned.com API or
+// local copies of the dumps
+func (s *Action) AuditHIBP(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	filter := c.Args().First()
 
-	out.Print(ctx, "Auditing passwords for common flaws ...")
+	if dumps := c.StringSlice("build-filter"); len(dumps) > 0 {
+		return s.auditBuildFilter(ctx, c, dumps)
+	}
+
+	checkers := make([]audit.BreachChecker, 0, 3)
+	if c.Bool("api") {
+		if ctxutil.IsNoNetwork(ctx) {
+			out.Warningf(ctx, "Not checking the passwords with the HIBP API, network access is disabled (offline)")
+		} else {
+			checkers = append(checkers, audit.APIChecker{})
+		}
+	}
+	if dumps := c.StringSlice("dumps"); len(dumps) > 0 {
+		scanner, err := dump.New(dumps...)
+		if err != nil {
+			return ExitError(ExitUsage, err, "%s", err)
+		}
+		checkers = append(checkers, scanner)
+	}
+	for _, fn := range c.StringSlice("filter") {
+		f, err := bloom.Open(fn)
+		if err != nil {
+			return ExitError(ExitUsage, err, "failed to open bloom filter: %s", err)
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		debug.Log("using bloom filter %s with %d hashes", f.Name(), f.Len())
+		checkers = append(checkers, f)
+	}
+	if len(checkers) < 1 && c.Bool("api") {
+		return ExitError(ExitUsage, nil, "Can't use the HIBP API offline, use --dumps or --filter instead")
+	}
+	if len(checkers) < 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s audit hibp [--api] [--dumps <file>] [--filter <file>] [filter]", s.Name)
+	}
+
+	list, err := s.auditList(ctx, filter, c.StringSlice("exclude"))
+	if err != nil {
+		return err
+	}
+
+	if len(list) < 1 {
+		out.Printf(ctx, "No secrets found")
+		return nil
+	}
+
+	if err := s.warmup(ctx, c, list); err != nil {
+		return err
+	}
+	if err := audit.HIBP(withJobs(ctx, c), list, s.Store, checkers...); err != nil {
+		return ExitError(ExitAudit, err, "%s", err)
+	}
+	return nil
+}
+
+// auditBuildFilter builds a bloom filter from the given HIBP dumps
+func (s *Action) auditBuildFilter(ctx context.Context, c *cli.Context, dumps []string) error {
+	dst := c.String("output")
+	if dst == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s audit hibp --build-filter <file> [--fp-rate <rate>] -o <filter>", s.Name)
+	}
+
+	out.Printf(ctx, "Building bloom filter from %d dumps. This may take some time ...", len(dumps))
+	st, err := bloom.Build(ctx, dst, c.Float64("fp-rate"), dumps...)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to build bloom filter: %s", err)
+	}
+	out.OKf(ctx, "Wrote bloom filter with %d hashes to %s (%d bytes, %g%% false positives)", st.Hashes, dst, st.Size, c.Float64("fp-rate")*100)
+	return nil
+}
+
+// auditList returns the secrets below filter, without the excluded ones
+func (s *Action) auditList(ctx context.Context, filter string, excludes []string) ([]string, error) {
 	t, err := s.Store.Tree(ctx)
 	if err != nil {
-		return ExitError(ExitList, err, "failed to get store tree: %s", err)
+		return nil, ExitError(ExitList, err, "failed to get store tree: %s", err)
 	}
 
 	if filter != "" {
 		subtree, err := t.FindFolder(filter)
 		if err != nil {
-			return ExitError(ExitUnknown, err, "failed to find subtree: %s", err)
+			return nil, ExitError(ExitUnknown, err, "failed to find subtree: %s", err)
 		}
 		debug.Log("subtree for %q: %+v", filter, subtree)
 		t = subtree
 	}
-	list := t.List(tree.INF)
+	return auditExclude(t.List(tree.INF), excludes), nil
+}
 
-	if len(list) < 1 {
-		out.Printf(ctx, "No secrets found")
-		return nil
+func auditParseArgs(c *cli.Context) (context.Context, error) {
+	ctx := ctxutil.WithGlobalFlags(c)
+	if c.IsSet("min-entropy") {
+		ctx = audit.WithMinEntropy(ctx, c.Float64("min-entropy"))
+	}
+	if c.IsSet("max-age") {
+		ctx = audit.WithMaxAge(ctx, time.Duration(c.Int("max-age"))*24*time.Hour)
+	}
+	if c.IsSet("expiring") {
+		window, err := expiry.ParseDuration(c.String("expiring"))
+		if err != nil {
+			return ctx, err
+		}
+		ctx = audit.WithExpiring(ctx, window)
+	}
+	if c.IsSet("fail-on") {
+		sev, err := audit.ParseSeverity(c.String("fail-on"))
+		if err != nil {
+			return ctx, err
+		}
+		ctx = audit.WithFailSeverity(ctx, sev)
+	}
+	format, err := outputFormat(c)
+	if err != nil {
+		return ctx, err
+	}
+	return audit.WithFormat(ctx, format), nil
+}
+
+// auditExclude removes all secrets matching any of the given glob patterns.
+// A pattern matching a folder excludes everything below it, e.g. "wifi/*"
+// or "wifi" exclude wifi/home and wifi/office/guest.
+func auditExclude(list, patterns []string) []string {
+	if len(patterns) < 1 {
+		return list
 	}
 
-	return audit.Batch(ctx, list, s.Store)
+	res := make([]string, 0, len(list))
+	for _, name := range list {
+		if !matchName(name, patterns) {
+			res = append(res, name)
+		}
+	}
+	debug.Log("excluded %d of %d secrets", len(list)-len(res), len(list))
+	return res
 }
@@ -3,16 +3,30 @@ package action
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/audit"
+	"github.com/gopasspw/gopass/internal/expiry"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/hibp/api"
+	"github.com/gopasspw/gopass/pkg/hibp/bloom"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func TestAudit(t *testing.T) {
@@ -50,6 +64,119 @@ func TestAudit(t *testing.T) {
 		buf.Reset()
 	})
 
+	t.Run("fail only on high severity findings", func(t *testing.T) {
+		// shared passwords have a medium severity
+		sec := &secrets.Plain{}
+		sec.SetPassword("Ohni8eiw9Aiquaezex5shoo1ahhoh3We")
+		assert.NoError(t, act.Store.Set(ctx, "shared/a", sec))
+		assert.NoError(t, act.Store.Set(ctx, "shared/b", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "high"}, "shared")
+		assert.NoError(t, act.Audit(c))
+		assert.Contains(t, buf.String(), "Shared secrets (severity: medium)")
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "medium"}, "shared")
+		assert.Error(t, act.Audit(c))
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "critical"}, "shared")
+		assert.Error(t, act.Audit(c))
+		buf.Reset()
+
+		for _, v := range []string{"shared/a", "shared/b"} {
+			assert.NoError(t, act.Store.Delete(ctx, v))
+		}
+	})
+
+	t.Run("replaced passwords are ignored", func(t *testing.T) {
+		// a value of another key equal to the password makes it weak
+		sec := secrets.NewKV()
+		sec.SetPassword("Ohni8eiw9Aiquaezex5shoo1ahhoh3We")
+		require.NoError(t, sec.Set("old-password-2021-05-01", "Ohni8eiw9Aiquaezex5shoo1ahhoh3We"))
+		assert.NoError(t, act.Store.Set(ctx, "rotated/a", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "low"}, "rotated")
+		assert.NoError(t, act.Audit(c))
+		buf.Reset()
+
+		require.NoError(t, sec.Set("note", "Ohni8eiw9Aiquaezex5shoo1ahhoh3We"))
+		assert.NoError(t, act.Store.Set(ctx, "rotated/a", sec))
+		assert.Error(t, act.Audit(c))
+		buf.Reset()
+
+		assert.NoError(t, act.Store.Delete(ctx, "rotated/a"))
+	})
+
+	t.Run("password rule violations", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir(""), ".pwrules"), []byte("example.com minlength: 8; maxlength: 16;\n"), 0600))
+		defer func() {
+			_ = os.Remove(filepath.Join(u.StoreDir(""), ".pwrules"))
+		}()
+
+		sec := &secrets.Plain{}
+		sec.SetPassword("Ohni8eiw9Aiquaezex5shoo1ahhoh3We")
+		assert.NoError(t, act.Store.Set(ctx, "web/example.com/user", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"fail-on": "medium"}, "web")
+		assert.Error(t, act.Audit(c))
+		assert.Contains(t, buf.String(), "Password rule violations (severity: medium)")
+		assert.Contains(t, buf.String(), "Violates the password rules for example.com: longer than 16 characters")
+		assert.NotContains(t, buf.String(), "Ohni8eiw9Aiquaezex5shoo1ahhoh3We")
+		buf.Reset()
+
+		assert.NoError(t, act.Store.Delete(ctx, "web/example.com/user"))
+	})
+
+	t.Run("expired and expiring secrets", func(t *testing.T) {
+		for name, exp := range map[string]string{
+			"tokens/old":  "2020-01-01",
+			"tokens/soon": expiry.Format(time.Now().Add(72 * time.Hour)),
+			"tokens/late": "2099-01-01",
+		} {
+			sec := secrets.NewKV()
+			sec.SetPassword("Ohni8eiw9Aiquaezex5shoo1ahhoh3We" + name)
+			require.NoError(t, sec.Set(expiry.Key, exp))
+			assert.NoError(t, act.Store.Set(ctx, name, sec))
+		}
+
+		// without --expiring the expiry date is ignored
+		assert.NoError(t, act.Audit(gptest.CliCtx(ctx, t, "tokens")))
+		buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"expiring": "30d"}, "tokens")
+		assert.Error(t, act.Audit(c))
+		assert.Contains(t, buf.String(), "Expired secrets (severity: high)")
+		assert.Contains(t, buf.String(), "Expired on 2020-01-01")
+		assert.Contains(t, buf.String(), "Expiring secrets (severity: medium)")
+		assert.NotContains(t, buf.String(), "tokens/late")
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"expiring": "soon"}, "tokens")
+		assert.Error(t, act.Audit(c))
+		buf.Reset()
+
+		for _, v := range []string{"tokens/old", "tokens/soon", "tokens/late"} {
+			assert.NoError(t, act.Store.Delete(ctx, v))
+		}
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json"})
+		assert.Error(t, act.Audit(c))
+
+		var res struct {
+			Findings []audit.Finding `json:"findings"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		require.NotEmpty(t, res.Findings)
+		assert.Equal(t, audit.FindingShared, res.Findings[0].Type)
+		assert.Equal(t, audit.SeverityMedium, res.Findings[0].Severity)
+		assert.Equal(t, []string{"bar", "baz"}, res.Findings[0].Secrets)
+		assert.NotContains(t, buf.String(), "123")
+		buf.Reset()
+	})
+
 	t.Run("test empty store", func(t *testing.T) {
 		for _, v := range []string{"foo", "bar", "baz"} {
 			assert.NoError(t, act.Store.Delete(ctx, v))
@@ -59,3 +186,139 @@ func TestAudit(t *testing.T) {
 		buf.Reset()
 	})
 }
+
+func TestAuditHIBP(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	// SHA-1 of "123" is 40BD001563085FC35165329EA1FF5C5ECBDBBEEF
+	var mu sync.Mutex
+	var prefixes []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		mu.Lock()
+		prefixes = append(prefixes, prefix)
+		mu.Unlock()
+		if prefix == "40BD0" {
+			fmt.Fprintf(w, "01563085FC35165329EA1FF5C5ECBDBBEEF:1337\r\n0FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1\r\n")
+		}
+	}))
+	defer ts.Close()
+
+	oldURL := api.URL
+	api.URL = ts.URL
+	defer func() {
+		api.URL = oldURL
+	}()
+
+	t.Run("no api and no dumps", func(t *testing.T) {
+		assert.Error(t, act.AuditHIBP(gptest.CliCtx(ctx, t)))
+		buf.Reset()
+	})
+
+	t.Run("no breached passwords", func(t *testing.T) {
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"api": "true"})
+		assert.NoError(t, act.AuditHIBP(c))
+		assert.Len(t, prefixes, 1)
+		buf.Reset()
+	})
+
+	t.Run("breached password", func(t *testing.T) {
+		sec := &secrets.Plain{}
+		sec.SetPassword("123")
+		assert.NoError(t, act.Store.Set(ctx, "bar", sec))
+		assert.NoError(t, act.Store.Set(ctx, "baz", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"api": "true"})
+		assert.Error(t, act.AuditHIBP(c))
+		assert.Contains(t, buf.String(), "\t- bar (seen 1337 times)")
+		assert.Contains(t, buf.String(), "\t- baz (seen 1337 times)")
+		assert.NotContains(t, buf.String(), "\t- foo")
+		assert.NotContains(t, buf.String(), "123")
+		// only the prefix is sent
+		for _, p := range prefixes {
+			assert.Len(t, p, 5)
+		}
+		buf.Reset()
+	})
+
+	testFilter := filepath.Join("..", "..", "pkg", "hibp", "bloom", "testdata", "test.blm")
+
+	t.Run("bloom filter", func(t *testing.T) {
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--filter", "missing.blm")))
+		buf.Reset()
+
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--filter", testFilter)))
+		assert.Contains(t, buf.String(), "\t- bar (possible match, 0.1% false positives)")
+		assert.Contains(t, buf.String(), "\t- baz (possible match, 0.1% false positives)")
+		assert.NotContains(t, buf.String(), "\t- foo")
+		buf.Reset()
+
+		// confirmed by the API
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--filter", testFilter, "--api")))
+		assert.Contains(t, buf.String(), "\t- bar (seen 1337 times)")
+		assert.NotContains(t, buf.String(), "possible match")
+		buf.Reset()
+	})
+
+	t.Run("build filter", func(t *testing.T) {
+		td := t.TempDir()
+		dump := filepath.Join(td, "dump.txt")
+		require.NoError(t, os.WriteFile(dump, []byte("40BD001563085FC35165329EA1FF5C5ECBDBBEEF:1337\n"), 0644))
+		fn := filepath.Join(td, "filter.blm")
+
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--build-filter", dump)))
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--build-filter", dump, "--fp-rate", "2", "--output", fn)))
+		assert.NoError(t, act.AuditHIBP(hibpCtx(ctx, t, "--build-filter", dump, "--output", fn)))
+		buf.Reset()
+
+		assert.Error(t, act.AuditHIBP(hibpCtx(ctx, t, "--filter", fn)))
+		assert.Contains(t, buf.String(), "\t- bar (possible match, 0.1% false positives)")
+		buf.Reset()
+	})
+}
+
+// hibpCtx returns a cli context for audit hibp. gptest.CliCtxWithFlags only
+// supports plain string flags.
+func hibpCtx(ctx context.Context, t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+
+	fs := flag.NewFlagSet("default", flag.ContinueOnError)
+	for _, f := range []cli.Flag{
+		&cli.BoolFlag{Name: "api"},
+		&cli.StringSliceFlag{Name: "dumps"},
+		&cli.StringSliceFlag{Name: "filter"},
+		&cli.StringSliceFlag{Name: "build-filter"},
+		&cli.StringFlag{Name: "output"},
+		&cli.Float64Flag{Name: "fp-rate", Value: bloom.DefaultFalsePositiveRate},
+	} {
+		require.NoError(t, f.Apply(fs))
+	}
+	require.NoError(t, fs.Parse(args))
+
+	c := cli.NewContext(cli.NewApp(), fs, nil)
+	c.Context = ctx
+	return c
+}
+
+func TestAuditExclude(t *testing.T) {
+	list := []string{"foo", "wifi", "wifi/home", "wifi/office/guest", "wifiled/pin", "web/wifi"}
+
+	assert.Equal(t, list, auditExclude(list, nil))
+	assert.Equal(t, []string{"foo", "wifi", "wifiled/pin", "web/wifi"}, auditExclude(list, []string{"wifi/*"}))
+	assert.Equal(t, []string{"foo", "wifiled/pin", "web/wifi"}, auditExclude(list, []string{"wifi/"}))
+	assert.Equal(t, []string{"foo"}, auditExclude(list, []string{"wifi*", "web/*"}))
+}
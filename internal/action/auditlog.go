@@ -0,0 +1,199 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const formatCSV = "csv"
+
+// auditLogEntry is the schema of audit log --format csv|json|yaml. It must be
+// kept stable.
+type auditLogEntry struct {
+	Time   time.Time `json:"time"`
+	Author string    `json:"author"`
+	Email  string    `json:"email"`
+	// Operation is created, updated, deleted, renamed, re-encrypted or
+	// recipients
+	Operation string `json:"operation"`
+	Name      string `json:"name"`
+	// OldName is the previous name of a renamed secret
+	OldName  string `json:"old_name,omitempty"`
+	Revision string `json:"revision"`
+	// Added and Removed are the recipients added or removed by a change of
+	// the recipients
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// AuditLog prints all changes of the secrets and recipients of the store or
+// below a folder, oldest first
+func (s *Action) AuditLog(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	prefix := c.Args().First()
+
+	format := auditLogFormat(c)
+	if format != formatCSV {
+		if _, err := out.ParseFormat(format); err != nil {
+			return ExitError(ExitUsage, err, "unknown format %q. Must be text, csv, json or yaml", format)
+		}
+	}
+
+	since, err := parseAuditLogTime(c.String("since"), false)
+	if err != nil {
+		return ExitError(ExitUsage, err, "invalid --since: %s", err)
+	}
+	until, err := parseAuditLogTime(c.String("until"), true)
+	if err != nil {
+		return ExitError(ExitUsage, err, "invalid --until: %s", err)
+	}
+
+	changes, err := s.Store.Changes(ctx, prefix)
+	if errors.Is(err, backend.ErrNotSupported) {
+		return ExitError(ExitUnsupported, err, "The store keeps no history. Use the git storage backend, e.g. with '%s git init'", s.Name)
+	}
+	if err != nil {
+		return ExitError(ExitGit, err, "failed to read the history: %s", err)
+	}
+
+	entries := make([]auditLogEntry, 0, len(changes))
+	for _, ch := range changes {
+		if (!since.IsZero() && ch.Date.Before(since)) || (!until.IsZero() && !ch.Date.Before(until)) {
+			continue
+		}
+		entries = append(entries, auditLogEntry{
+			Time:      ch.Date.UTC(),
+			Author:    ch.AuthorName,
+			Email:     ch.AuthorEmail,
+			Operation: ch.Op,
+			Name:      ch.Name,
+			OldName:   ch.OldName,
+			Revision:  ch.Hash,
+			Added:     ch.Added,
+			Removed:   ch.Removed,
+		})
+	}
+
+	switch {
+	case format == formatCSV:
+		return auditLogCSV(entries)
+	case out.IsStructured(format):
+		if err := out.Encode(stdout, format, entries); err != nil {
+			return ExitError(ExitUnknown, err, "failed to encode the audit log: %s", err)
+		}
+		return nil
+	}
+
+	if len(entries) < 1 {
+		out.Printf(ctx, "No changes found")
+		return nil
+	}
+	auditLogText(ctx, entries)
+	return nil
+}
+
+// auditLogFormat returns the value of the innermost --format flag. Unlike
+// the other commands audit log supports csv.
+func auditLogFormat(c *cli.Context) string {
+	for _, cc := range c.Lineage() {
+		if cc.IsSet("format") {
+			return strings.ToLower(cc.String("format"))
+		}
+	}
+	return out.FormatText
+}
+
+// parseAuditLogTime parses a date, e.g. 2021-06-01, or a timestamp in RFC
+// 3339 format. A date given as the end of a range includes that whole day.
+func parseAuditLogTime(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		return ts, nil
+	}
+	ts, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is no date (YYYY-MM-DD) or RFC 3339 timestamp", s)
+	}
+	if end {
+		ts = ts.AddDate(0, 0, 1)
+	}
+	return ts, nil
+}
+
+// auditLogText prints the audit log as a table. Recipient changes are
+// highlighted.
+func auditLogText(ctx context.Context, entries []auditLogEntry) {
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tAUTHOR\tOPERATION\tNAME")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s <%s>\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Author, e.Email, e.Operation, auditLogDetails(e))
+	}
+	_ = tw.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		// the first line is the header
+		if i > 0 && entries[i-1].Operation == backend.ChangeRecipients {
+			line = color.YellowString(line)
+		}
+		out.Printf(ctx, "%s", line)
+	}
+}
+
+// auditLogDetails returns the name of the changed secret along with the old
+// name or the added and removed recipients
+func auditLogDetails(e auditLogEntry) string {
+	if e.OldName != "" {
+		return fmt.Sprintf("%s (from %s)", e.Name, e.OldName)
+	}
+
+	details := []string{e.Name}
+	for _, r := range e.Added {
+		details = append(details, "+"+r)
+	}
+	for _, r := range e.Removed {
+		details = append(details, "-"+r)
+	}
+	return strings.Join(details, " ")
+}
+
+// auditLogCSV writes the audit log as CSV with a header, the recipients are
+// separated by spaces
+func auditLogCSV(entries []auditLogEntry) error {
+	w := csv.NewWriter(stdout)
+	_ = w.Write([]string{"time", "author", "email", "operation", "name", "old_name", "revision", "added", "removed"})
+	for _, e := range entries {
+		_ = w.Write([]string{
+			e.Time.Format(time.RFC3339),
+			e.Author,
+			e.Email,
+			e.Operation,
+			e.Name,
+			e.OldName,
+			e.Revision,
+			strings.Join(e.Added, " "),
+			strings.Join(e.Removed, " "),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return ExitError(ExitIO, err, "failed to write the audit log: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("no history", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.AuditLog(gptest.CliCtx(ctx, t)))
+	})
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	at := func(month time.Month) context.Context {
+		return ctxutil.WithCommitTimestamp(ctx, time.Date(2020, month, 1, 12, 0, 0, 0, time.UTC))
+	}
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, act.Store.Set(at(time.January), "web/old", sec))
+	require.NoError(t, act.Store.Set(at(time.January), "db", sec))
+	sec.SetPassword("bar")
+	require.NoError(t, act.Store.Set(at(time.February), "web/old", sec))
+	require.NoError(t, act.Store.Move(at(time.March), "web/old", "web/new"))
+	require.NoError(t, act.Store.AddRecipient(at(time.April), "", "0xFEEDBEEF"))
+	// the plain backend doesn't change the content when re-encrypting, so
+	// do what gpg would do
+	storage := act.Store.Storage(ctx, "")
+	require.NoError(t, storage.Set(ctx, plain.IDFile, []byte("0xDEADBEEF\n")))
+	require.NoError(t, storage.Set(ctx, "web/new."+plain.Ext, []byte("re-encrypted")))
+	require.NoError(t, storage.Add(ctx, plain.IDFile, "web/new."+plain.Ext))
+	require.NoError(t, storage.Commit(at(time.May), "Removed Recipient 0xFEEDBEEF"))
+	require.NoError(t, act.Store.Delete(at(time.June), "db"))
+	buf.Reset()
+
+	auditLog := func(t *testing.T, flags map[string]string, args ...string) []auditLogEntry {
+		t.Helper()
+
+		flags["format"] = "json"
+		require.NoError(t, act.AuditLog(gptest.CliCtxWithFlags(ctx, t, flags, args...)))
+		var entries []auditLogEntry
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+		return entries
+	}
+	summary := func(entries []auditLogEntry) []string {
+		var res []string
+		for _, e := range entries {
+			res = append(res, e.Time.Format("2006-01")+" "+e.Operation+" "+e.Name)
+		}
+		return res
+	}
+
+	t.Run("json", func(t *testing.T) {
+		defer buf.Reset()
+		// the store was initialized with the current date
+		entries := auditLog(t, map[string]string{"until": "2020-12-31"})
+		assert.Equal(t, []string{
+			"2020-01 created web/old",
+			"2020-01 created db",
+			"2020-02 updated web/old",
+			"2020-03 renamed web/new",
+			"2020-04 recipients .plain-id",
+			"2020-05 re-encrypted web/new",
+			"2020-05 recipients .plain-id",
+			"2020-06 deleted db",
+		}, summary(entries))
+		assert.Equal(t, "web/old", entries[3].OldName)
+		assert.Equal(t, "foo bar", entries[3].Author)
+		assert.Equal(t, "foo.bar@example.org", entries[3].Email)
+		assert.Len(t, entries[3].Revision, 40)
+		assert.Equal(t, []string{"0xFEEDBEEF"}, entries[4].Added)
+		assert.Empty(t, entries[4].Removed)
+		assert.Empty(t, entries[6].Added)
+		assert.Equal(t, []string{"0xFEEDBEEF"}, entries[6].Removed)
+	})
+
+	t.Run("follow renames", func(t *testing.T) {
+		defer buf.Reset()
+		entries := auditLog(t, map[string]string{}, "web/new")
+		assert.Equal(t, []string{
+			"2020-01 created web/old",
+			"2020-02 updated web/old",
+			"2020-03 renamed web/new",
+			"2020-05 re-encrypted web/new",
+		}, summary(entries))
+	})
+
+	t.Run("since and until", func(t *testing.T) {
+		defer buf.Reset()
+		entries := auditLog(t, map[string]string{"since": "2020-02-01", "until": "2020-03-01"}, "web")
+		assert.Equal(t, []string{
+			"2020-02 updated web/old",
+			"2020-03 renamed web/new",
+		}, summary(entries))
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.AuditLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"since": "last week"})))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.AuditLog(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "csv"}, "web/new")))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 5, buf.String())
+		assert.Equal(t, "time,author,email,operation,name,old_name,revision,added,removed", lines[0])
+		assert.True(t, strings.HasPrefix(lines[3], "2020-03-01T12:00:00Z,foo bar,foo.bar@example.org,renamed,web/new,web/old,"), lines[3])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.AuditLog(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "TIME ")
+		assert.Contains(t, buf.String(), "  foo bar <foo.bar@example.org>  renamed       web/new (from web/old)\n")
+		assert.Contains(t, buf.String(), "  recipients    .plain-id +0xFEEDBEEF\n")
+		assert.Contains(t, buf.String(), "  recipients    .plain-id -0xFEEDBEEF\n")
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.AuditLog(gptest.CliCtx(ctx, t, "nope")))
+		assert.Contains(t, buf.String(), "No changes found")
+	})
+}
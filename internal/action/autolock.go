@@ -0,0 +1,72 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/autolock"
+	gpgcli "github.com/gopasspw/gopass/internal/backend/crypto/gpg/cli"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// startAutoLock starts a watcher calling lock after lockafter seconds
+// without activity, when the system goes to sleep and on SIGUSR1. It stops
+// when the context is canceled.
+func (s *Action) startAutoLock(ctx context.Context, lock func(reason string)) *autolock.Watcher {
+	al := autolock.New(time.Duration(s.cfg.LockAfter)*time.Second, lock)
+	go al.Run(ctx)
+	return al
+}
+
+// lockStores drops the cached credentials and decrypted secrets of all stores
+// and makes the gpg-agent forget the passphrases of the gpg keys
+func (s *Action) lockStores(ctx context.Context, reason string) {
+	if err := s.Store.Lock(); err != nil {
+		debug.Log("failed to lock the stores: %s", err)
+		return
+	}
+	if _, err := clearAgents(ctx, s.gpgAgents(ctx), false); err != nil && !errors.Is(err, gpgcli.ErrAgentNotRunning) {
+		debug.Log("failed to clear the cache of the gpg-agent: %s", err)
+	}
+	debug.Log("locked the stores, reason: %s", reason)
+}
+
+// lockReason describes why the watcher has locked, for humans
+func (s *Action) lockReason(reason string) string {
+	switch reason {
+	case autolock.Inactivity:
+		return fmt.Sprintf("after %s of inactivity", time.Duration(s.cfg.LockAfter)*time.Second)
+	case autolock.Sleep:
+		return "because the system went to sleep"
+	case autolock.Signal:
+		return "by SIGUSR1"
+	default:
+		return "by the lock command"
+	}
+}
+
+// unlockStores decrypts a secret to make the user authenticate again after
+// the stores have been locked. The watcher stays locked if that fails.
+func (s *Action) unlockStores(ctx context.Context, al *autolock.Watcher) error {
+	reason, locked := al.Locked()
+	if !locked {
+		return nil
+	}
+	out.Noticef(ctx, "The stores were locked %s", s.lockReason(reason))
+
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	if len(names) > 0 {
+		if err := s.Store.Warmup(ctx, names[:1]); err != nil {
+			return fmt.Errorf("still locked: %w", err)
+		}
+	}
+	al.Unlock()
+	return nil
+}
@@ -0,0 +1,55 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/autolock"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlockStores(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	act.cfg.LockAfter = 900
+	al := autolock.New(0, func(reason string) {
+		act.lockStores(ctx, reason)
+	})
+
+	// nothing to do while unlocked
+	require.NoError(t, act.unlockStores(ctx, al))
+	assert.Empty(t, buf.String())
+
+	al.Lock(autolock.Inactivity)
+	require.NoError(t, act.unlockStores(ctx, al))
+	assert.Contains(t, buf.String(), "The stores were locked after 15m0s of inactivity")
+	_, locked := al.Locked()
+	assert.False(t, locked)
+
+	assert.Equal(t, "because the system went to sleep", act.lockReason(autolock.Sleep))
+	assert.Equal(t, "by SIGUSR1", act.lockReason(autolock.Signal))
+}
@@ -7,7 +7,9 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/gopasspw/gopass/internal/out"
@@ -16,6 +18,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/fsutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
 
 	"github.com/urfave/cli/v2"
 )
@@ -41,28 +44,58 @@ func (s *Action) Cat(c *cli.Context) error {
 	// if content is piped to stdin, read and save it
 	if info.Mode()&os.ModeCharDevice == 0 {
 		debug.Log("Reading from STDIN ...")
-		content := &bytes.Buffer{}
-
-		if written, err := io.Copy(content, binstdin); err != nil {
-			return ExitError(ExitIO, err, "Failed to copy after %d bytes: %s", written, err)
-		}
-
-		return s.Store.Set(
+		r := &limitReader{r: binstdin, limit: int64(s.cfg.BinaryLimit)}
+		err := s.Store.SetFrom(
 			ctxutil.WithCommitMessage(ctx, "Read secret from STDIN"),
 			name,
-			secFromBytes(name, "STDIN", content.Bytes()),
+			secrets.NewAttachmentEncoder("STDIN", r),
 		)
+		if r.n > 0 {
+			debug.Log("Read %d bytes from STDIN to %s", r.n, name)
+		}
+		if r.n > r.limit && r.limit > 0 {
+			return ExitError(ExitUsage, err, "%s", s.binaryCheckSize("STDIN", r.n))
+		}
+		if r.err != nil {
+			return ExitError(ExitIO, r.err, "Failed to copy after %d bytes: %s", r.n, r.err)
+		}
+		return err
 	}
 
-	buf, err := s.binaryGet(ctx, name)
+	br, err := s.binaryOpen(ctx, name)
 	if err != nil {
-		return ExitError(ExitDecrypt, err, "failed to read secret: %s", err)
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to read secret: %s", err)
 	}
+	defer br.Close()
 
-	fmt.Fprint(stdout, string(buf))
+	if _, err := io.Copy(stdout, br); err != nil {
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to read secret: %s", err)
+	}
 	return nil
 }
 
+// limitReader fails once more than limit bytes have been read, unless limit
+// is zero. It remembers the error of the underlying reader, the store only
+// reports that the encryption failed.
+type limitReader struct {
+	r     io.Reader
+	n     int64
+	limit int64
+	err   error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.limit > 0 && l.n > l.limit {
+		return 0, fmt.Errorf("more than %d bytes", l.limit)
+	}
+	if err != nil && err != io.EOF {
+		l.err = err
+	}
+	return n, err
+}
+
 func secFromBytes(dst, src string, in []byte) gopass.Secret {
 	debug.Log("Read %d bytes from %s to %s", len(in), src, dst)
 
@@ -75,6 +108,9 @@ func secFromBytes(dst, src string, in []byte) gopass.Secret {
 	if err := sec.Set("Content-Transfer-Encoding", "Base64"); err != nil {
 		debug.Log("Failed to set Content-Transfer-Encoding: %q", err)
 	}
+	if err := sec.Set(secrets.ContentSHA256, secrets.Checksum(in)); err != nil {
+		debug.Log("Failed to set %s: %q", secrets.ContentSHA256, err)
+	}
 
 	return sec
 }
@@ -88,7 +124,7 @@ func (s *Action) BinaryCopy(c *cli.Context) error {
 
 	// argument checking is in s.binaryCopy
 	if err := s.binaryCopy(ctx, c, from, to, false); err != nil {
-		return ExitError(ExitUnknown, err, "%s", err)
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "%s", err)
 	}
 	return nil
 }
@@ -103,7 +139,7 @@ func (s *Action) BinaryMove(c *cli.Context) error {
 
 	// argument checking is in s.binaryCopy
 	if err := s.binaryCopy(ctx, c, from, to, true); err != nil {
-		return ExitError(ExitUnknown, err, "%s", err)
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "%s", err)
 	}
 	return nil
 }
@@ -120,16 +156,16 @@ func (s *Action) binaryCopy(ctx context.Context, c *cli.Context, from, to string
 		if deleteSource {
 			op = "move"
 		}
-		return fmt.Errorf("usage: %s fs%s from to", c.App.Name, op)
+		return ExitError(ExitUsage, nil, "usage: %s fs%s from to", c.App.Name, op)
 	}
 
 	switch {
 	case fsutil.IsFile(from) && fsutil.IsFile(to):
 		// copying from on file to another file is not supported
-		return fmt.Errorf("ambiguity detected. Only from or to can be a file")
+		return ExitError(ExitUsage, nil, "ambiguity detected. Only from or to can be a file")
 	case s.Store.Exists(ctx, from) && s.Store.Exists(ctx, to):
 		// copying from one secret to another secret is not supported
-		return fmt.Errorf("ambiguity detected. Either from or to must be a file")
+		return ExitError(ExitUsage, nil, "ambiguity detected. Either from or to must be a file")
 	case fsutil.IsFile(from) && !fsutil.IsFile(to):
 		return s.binaryCopyFromFileToStore(ctx, from, to, deleteSource)
 	case !fsutil.IsFile(from):
@@ -145,13 +181,24 @@ func (s *Action) binaryCopyFromFileToStore(ctx context.Context, from, to string,
 	// and a relative one for the secret
 
 	// copy from FS to store
-	buf, err := os.ReadFile(from)
+	fi, err := os.Stat(from)
+	if err != nil {
+		return fmt.Errorf("failed to stat file %q: %w", from, err)
+	}
+	if err := s.binaryCheckSize(from, fi.Size()); err != nil {
+		return err
+	}
+	fh, err := os.Open(from)
 	if err != nil {
 		return fmt.Errorf("failed to read file from %q: %w", from, err)
 	}
+	defer fh.Close()
 
-	if err := s.Store.Set(
-		ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Copied data from %s to %s", from, to)), to, secFromBytes(to, from, buf)); err != nil {
+	// the file is encoded and encrypted while it's read
+	h := sha256.New()
+	if err := s.Store.SetFrom(
+		ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Copied data from %s to %s", from, to)), to,
+		secrets.NewAttachmentEncoder(filepath.Base(from), io.TeeReader(fh, h))); err != nil {
 		return fmt.Errorf("failed to save buffer to store: %w", err)
 	}
 
@@ -161,7 +208,7 @@ func (s *Action) binaryCopyFromFileToStore(ctx context.Context, from, to string,
 
 	// it's important that we return if the validation fails, because
 	// in that case we don't want to shred our (only) copy of this data!
-	if err := s.binaryValidate(ctx, buf, to); err != nil {
+	if err := s.binaryValidate(ctx, fmt.Sprintf("%x", h.Sum(nil)), to); err != nil {
 		return fmt.Errorf("failed to validate written data: %w", err)
 	}
 	if err := fsutil.Shred(from, 8); err != nil {
@@ -172,15 +219,21 @@ func (s *Action) binaryCopyFromFileToStore(ctx context.Context, from, to string,
 
 func (s *Action) binaryCopyFromStoreToFile(ctx context.Context, from, to string, deleteSource bool) error {
 	// if the source is no file we assume it's a secret and to is a filename
-	// (which may already exist or not)
+	// (which may already exist or not) or a directory
 
 	// copy from store to FS
-	buf, err := s.binaryGet(ctx, from)
+	br, err := s.binaryOpen(ctx, from)
 	if err != nil {
-		return fmt.Errorf("failed to read data from %q: %w", from, err)
+		return fmt.Errorf("failed to read %q from the store: %w", from, err)
 	}
-	if err := os.WriteFile(to, buf, 0600); err != nil {
-		return fmt.Errorf("failed to write data to %q: %w", to, err)
+	defer br.Close()
+
+	if fsutil.IsDir(to) {
+		to = filepath.Join(to, binaryFilename(from, br.headers))
+	}
+	fileSum, err := binaryWriteFile(to, br)
+	if err != nil {
+		return fmt.Errorf("failed to write data from %q to %q: %w", from, to, err)
 	}
 
 	if !deleteSource {
@@ -189,7 +242,7 @@ func (s *Action) binaryCopyFromStoreToFile(ctx context.Context, from, to string,
 
 	// as before: if validation of the written data fails, we MUST NOT
 	// delete the (only) source
-	if err := s.binaryValidate(ctx, buf, from); err != nil {
+	if err := s.binaryValidate(ctx, fileSum, from); err != nil {
 		return fmt.Errorf("failed to validate the written data: %w", err)
 	}
 	if err := s.Store.Delete(ctx, from); err != nil {
@@ -198,23 +251,54 @@ func (s *Action) binaryCopyFromStoreToFile(ctx context.Context, from, to string,
 	return nil
 }
 
-func (s *Action) binaryValidate(ctx context.Context, buf []byte, name string) error {
-	h := sha256.New()
-	_, _ = h.Write(buf)
-	fileSum := fmt.Sprintf("%x", h.Sum(nil))
-	h.Reset()
+// binaryWriteFile writes the content read from r to a temp file next to to
+// and renames it once all content has been read. The content is decrypted
+// and decoded while it's read, so a failure, e.g. a checksum mismatch, is
+// only detected at its end and must not leave a partial file behind. It
+// returns the checksum of the content.
+func binaryWriteFile(to string, r io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(to), "."+filepath.Base(to)+".tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
 
-	debug.Log("in: %s - %q", fileSum, string(buf))
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), to); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
 
-	var err error
-	buf, err = s.binaryGet(ctx, name)
+// binaryValidate compares the checksum of a copied file with the content of
+// the secret
+func (s *Action) binaryValidate(ctx context.Context, fileSum, name string) error {
+	br, err := s.binaryOpen(ctx, name)
 	if err != nil {
 		return fmt.Errorf("failed to read %q from the store: %w", name, err)
 	}
-	_, _ = h.Write(buf)
+	defer br.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, br); err != nil {
+		return fmt.Errorf("failed to read %q from the store: %w", name, err)
+	}
 	storeSum := fmt.Sprintf("%x", h.Sum(nil))
 
-	debug.Log("store: %s - %q", storeSum, string(buf))
+	debug.Log("file: %s - store: %s", fileSum, storeSum)
 
 	if fileSum != storeSum {
 		return fmt.Errorf("hashsum mismatch (file: %s, store: %s)", fileSum, storeSum)
@@ -222,24 +306,113 @@ func (s *Action) binaryValidate(ctx context.Context, buf []byte, name string) er
 	return nil
 }
 
+// binaryCheckSize returns an error if size exceeds the configured limit
+func (s *Action) binaryCheckSize(name string, size int64) error {
+	if s.cfg.BinaryLimit < 1 || size <= int64(s.cfg.BinaryLimit) {
+		return nil
+	}
+	return fmt.Errorf("%s is too large. The limit is %d bytes, use 'gopass config binarylimit' to change it", name, s.cfg.BinaryLimit)
+}
+
 func (s *Action) binaryGet(ctx context.Context, name string) ([]byte, error) {
-	sec, err := s.Store.Get(ctx, name)
+	br, err := s.binaryOpen(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer br.Close()
+	return io.ReadAll(br)
+}
+
+// headerGetter is the part of a secret holding the headers of an attachment
+type headerGetter interface {
+	Get(key string) (string, bool)
+}
+
+// binaryReader is the content of a secret opened by binaryOpen
+type binaryReader struct {
+	io.Reader
+	headers headerGetter
+	closer  io.Closer
+}
+
+// Close stops the decryption
+func (b *binaryReader) Close() error {
+	if b.closer == nil {
+		return nil
+	}
+	return b.closer.Close()
+}
+
+// binaryOpen returns a reader for the decoded content of an attachment or
+// the body of any other secret, like binaryDecode. Attachments are decrypted
+// and decoded while they are read, so they are never held in memory. Their
+// checksum is verified once all of the content has been read.
+func (s *Action) binaryOpen(ctx context.Context, name string) (*binaryReader, error) {
+	rc, err := s.Store.Open(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %q from the store: %w", name, err)
 	}
+	ar, err := secrets.NewAttachmentReader(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, fmt.Errorf("failed to read %q from the store: %w", name, err)
+	}
+	if ar.IsAttachment() {
+		return &binaryReader{Reader: ar, headers: ar, closer: rc}, nil
+	}
 
-	if cte, _ := sec.Get("content-transfer-encoding"); cte != "Base64" {
+	// any other secret is small enough to be parsed as usual
+	defer rc.Close()
+	raw, err := io.ReadAll(ar.Raw())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q from the store: %w", name, err)
+	}
+	var sec gopass.Secret = secrets.ParsePlain(raw)
+	if ctxutil.IsShowParsing(ctx) {
+		if sec, err = secparse.Parse(raw); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := binaryDecode(sec)
+	if err != nil {
+		return nil, err
+	}
+	return &binaryReader{Reader: bytes.NewReader(buf), headers: sec}, nil
+}
+
+// binaryFilename returns the original filename of a secret created by
+// fscopy or cat. It falls back to the name of the secret.
+func binaryFilename(name string, sec headerGetter) string {
+	if cd, found := sec.Get("content-disposition"); found {
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			// never allow the header to point outside of the target directory
+			if fn := filepath.Base(params["filename"]); fn != "." && fn != ".." && fn != string(filepath.Separator) {
+				return fn
+			}
+		}
+	}
+	return path.Base(name)
+}
+
+// binaryDecode returns the decoded content of an attachment or the body of
+// any other secret. The content of an attachment must match its checksum.
+func binaryDecode(sec gopass.Secret) ([]byte, error) {
+	if !secrets.IsAttachment(sec) {
 		return []byte(sec.Body()), nil
 	}
 
-	buf, err := base64.StdEncoding.DecodeString(sec.Body())
+	buf, err := secrets.AttachmentContent(sec)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode to base64: %w", err)
+		return nil, err
+	}
+	if _, err := secrets.VerifyChecksum(sec, buf); err != nil {
+		return nil, err
 	}
 	return buf, nil
 }
 
-// Sum decodes binary content and computes the SHA256 checksum
+// Sum computes the SHA256 checksum of the decoded content of an attachment
+// or of the content of any other secret, exactly as it was stored
 func (s *Action) Sum(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	name := c.Args().First()
@@ -247,14 +420,20 @@ func (s *Action) Sum(c *cli.Context) error {
 		return ExitError(ExitUsage, nil, "Usage: %s sha256 name", c.App.Name)
 	}
 
-	buf, err := s.binaryGet(ctx, name)
+	// parsing may normalize the content, e.g. the line endings
+	raw, err := s.Store.Get(ctxutil.WithShowParsing(ctx, false), name)
 	if err != nil {
-		return ExitError(ExitDecrypt, err, "failed to read secret: %s", err)
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to read secret: %s", err)
 	}
+	buf := raw.Bytes()
 
-	h := sha256.New()
-	_, _ = h.Write(buf)
-	out.Printf(ctx, "%x", h.Sum(nil))
+	if sec, err := secparse.Parse(buf); err == nil && secrets.IsAttachment(sec) {
+		buf, err = binaryDecode(sec)
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "failed to decode %s: %s", name, err)
+		}
+	}
 
+	out.Printf(ctx, "%s", secrets.Checksum(buf))
 	return nil
 }
@@ -6,10 +6,13 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
@@ -93,6 +96,32 @@ func TestBinaryCat(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, string(buf), string(sec))
 	})
+
+	t.Run("binary cat from stdin larger than the limit", func(t *testing.T) {
+		act.cfg.BinaryLimit = 512
+		defer func() {
+			act.cfg.BinaryLimit = config.DefaultBinaryLimit
+		}()
+
+		fd, err := os.Open(stdinfile)
+		assert.NoError(t, err)
+		binstdin = fd
+		defer func() {
+			binstdin = os.Stdin
+			fd.Close()
+		}()
+
+		assert.Error(t, act.Cat(gptest.CliCtx(ctx, t, "big")))
+		assert.False(t, act.Store.Exists(ctx, "big"))
+	})
+
+	t.Run("show refuses binary content on a terminal", func(t *testing.T) {
+		defer buf.Reset()
+
+		ctx := ctxutil.WithTerminal(ctx, true)
+		assert.Error(t, act.show(ctx, gptest.CliCtx(ctx, t), "baz", false))
+		assert.NoError(t, act.show(ctxutil.WithForce(ctx, true), gptest.CliCtx(ctx, t), "baz", false))
+	})
 }
 
 func TestBinaryCopy(t *testing.T) {
@@ -155,6 +184,26 @@ func TestBinaryCopy(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.BinaryMove(gptest.CliCtx(ctx, t, "bar2", outfile)))
 	})
+
+	t.Run("binary copy bar to a directory", func(t *testing.T) {
+		defer buf.Reset()
+
+		outdir := filepath.Join(u.Dir, "out")
+		require.NoError(t, os.Mkdir(outdir, 0700))
+		assert.NoError(t, act.BinaryCopy(gptest.CliCtx(ctx, t, "bar", outdir)))
+		assert.FileExists(t, filepath.Join(outdir, "input.raw"))
+	})
+
+	t.Run("binary copy a file larger than the limit", func(t *testing.T) {
+		defer buf.Reset()
+
+		act.cfg.BinaryLimit = 512
+		defer func() {
+			act.cfg.BinaryLimit = config.DefaultBinaryLimit
+		}()
+		assert.Error(t, act.BinaryCopy(gptest.CliCtx(ctx, t, infile, "big")))
+		assert.False(t, act.Store.Exists(ctx, "big"))
+	})
 }
 
 func TestBinarySum(t *testing.T) {
@@ -163,7 +212,6 @@ func TestBinarySum(t *testing.T) {
 
 	ctx := context.Background()
 	ctx = ctxutil.WithAlwaysYes(ctx, true)
-	ctx = ctxutil.WithHidden(ctx, true)
 
 	buf := &bytes.Buffer{}
 	out.Stdout = buf
@@ -184,6 +232,27 @@ func TestBinarySum(t *testing.T) {
 
 	t.Run("binary sum bar", func(t *testing.T) {
 		assert.NoError(t, act.Sum(gptest.CliCtx(ctx, t, "bar")))
+		// the input has been moved to the store, but it's predictable
+		writeBinfile(t, infile)
+		content, err := os.ReadFile(infile)
+		require.NoError(t, err)
+		assert.Equal(t, secrets.Checksum(content), strings.TrimSpace(buf.String()))
+		buf.Reset()
+	})
+
+	t.Run("text secret is hashed as stored", func(t *testing.T) {
+		content := []byte("pw\r\nuser: foo\r\n")
+		require.NoError(t, act.Store.Set(ctx, "text", secrets.ParsePlain(content)))
+		assert.NoError(t, act.Sum(gptest.CliCtx(ctx, t, "text")))
+		assert.Equal(t, secrets.Checksum(content), strings.TrimSpace(buf.String()))
+		buf.Reset()
+	})
+
+	t.Run("corrupted attachment", func(t *testing.T) {
+		sec := secFromBytes("broken", "broken", []byte("foobar"))
+		require.NoError(t, sec.Set(secrets.ContentSHA256, secrets.Checksum([]byte("foo"))))
+		require.NoError(t, act.Store.Set(ctx, "broken", sec))
+		assert.Error(t, act.Sum(gptest.CliCtx(ctx, t, "broken")))
 		buf.Reset()
 	})
 }
@@ -0,0 +1,125 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// confirmOverwrite prints how an existing secret changes and, if ask is set,
+// asks the user to confirm overwriting it. Nothing is printed or asked if
+// gopass isn't used interactively, scripts rely on --force.
+func (s *Action) confirmOverwrite(ctx context.Context, name string, old, new gopass.Secret, ask bool) error {
+	if !ctxutil.IsInteractive(ctx) || !ctxutil.IsTerminal(ctx) {
+		return nil
+	}
+
+	cs := secrets.Diff(old, new)
+	if err := s.printChanges(ctx, name, cs); err != nil {
+		return err
+	}
+	if !ask || !cs.IsChanged() {
+		return nil
+	}
+
+	if !termio.Confirm(ctx, fmt.Sprintf("Overwrite %s?", name)) {
+		return ExitError(ExitAborted, nil, "not overwriting your current secret")
+	}
+	return nil
+}
+
+// printChanges prints which fields of a secret change. The values are only
+// shown with --unsafe. A summary longer than the terminal is paged.
+func (s *Action) printChanges(ctx context.Context, name string, cs secrets.Changeset) error {
+	changes := cs.Changes()
+	if len(changes) < 1 {
+		out.Noticef(ctx, "No changes to %s", name)
+		return nil
+	}
+
+	paged := usePager(ctx, len(changes)+1)
+	lines := make([]string, 0, len(changes)+1)
+	lines = append(lines, fmt.Sprintf("Changes to %s:", name))
+	for _, fc := range changes {
+		line := formatChange(fc, IsUnsafe(ctx))
+		if !paged {
+			line = colorChange(fc.State, line)
+		}
+		lines = append(lines, line)
+	}
+
+	if paged {
+		return s.pager(ctx, bytes.NewBufferString(strings.Join(lines, "\n")+"\n"))
+	}
+	for _, line := range lines {
+		out.Printf(ctx, "%s", line)
+	}
+	return nil
+}
+
+// formatChange returns a single line of the change summary, e.g. "+ user"
+func formatChange(fc secrets.FieldChange, unsafe bool) string {
+	switch fc.State {
+	case secrets.Added:
+		if unsafe {
+			return fmt.Sprintf("  + %s: %q", fc.Field, fc.New)
+		}
+		return "  + " + fc.Field
+	case secrets.Removed:
+		if unsafe {
+			return fmt.Sprintf("  - %s: %q", fc.Field, fc.Old)
+		}
+		return "  - " + fc.Field
+	default:
+		if unsafe {
+			return fmt.Sprintf("  ~ %s: %q -> %q", fc.Field, fc.Old, fc.New)
+		}
+		return "  ~ " + fc.Field
+	}
+}
+
+func colorChange(state, line string) string {
+	switch state {
+	case secrets.Added:
+		return color.GreenString(line)
+	case secrets.Removed:
+		return color.RedString(line)
+	default:
+		return color.YellowString(line)
+	}
+}
+
+// usePager returns true if output of the given number of lines doesn't fit
+// the terminal and a pager is configured
+func usePager(ctx context.Context, lines int) bool {
+	if ctxutil.IsNoPager(ctx) || os.Getenv("PAGER") == "" {
+		return false
+	}
+	_, rows, err := term.GetSize(0)
+	if err != nil {
+		return false
+	}
+	return lines >= rows
+}
+
+// parseSecret parses the content of a secret like show does, so the keys of
+// both versions can be compared
+func parseSecret(buf []byte) gopass.Secret {
+	sec, err := secparse.Parse(buf)
+	if err != nil {
+		return secrets.ParsePlain(buf)
+	}
+	return sec
+}
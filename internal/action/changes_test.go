@@ -0,0 +1,108 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintChanges(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithNoPager(ctx, true)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	act := &Action{}
+	cs := secrets.Diff(
+		secrets.ParsePlain([]byte("secret\nuser: jane\nurl: example.com\n")),
+		secrets.ParsePlain([]byte("hunter2\nuser: jane\nport: 22\n")),
+	)
+
+	require.NoError(t, act.printChanges(ctx, "foo", cs))
+	assert.Equal(t, "Changes to foo:\n  ~ Password\n  + port\n  - url\n", buf.String())
+	buf.Reset()
+
+	require.NoError(t, act.printChanges(WithUnsafe(ctx, true), "foo", cs))
+	assert.Equal(t, "Changes to foo:\n  ~ Password: \"secret\" -> \"hunter2\"\n  + port: \"22\"\n  - url: \"example.com\"\n", buf.String())
+}
+
+func TestInsertConfirmOverwrite(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, true)
+	ctx = ctxutil.WithNoPager(ctx, true)
+	ctx = WithQuiet(ctx, true)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		termio.Stdin = os.Stdin
+	}()
+
+	t.Run("abort", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("n\n")
+		assert.Error(t, act.insertSingle(ctx, "foo", "hunter2", nil, true))
+		assert.Contains(t, buf.String(), "Changes to foo:\n  ~ Password\n")
+		assert.NotContains(t, buf.String(), "hunter2")
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("y\n")
+		require.NoError(t, act.insertSingle(ctx, "foo", "hunter2", map[string]string{"user": "jane"}, true))
+		assert.Contains(t, buf.String(), "Changes to foo:\n  ~ Password\n  + user\n")
+
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", sec.Password())
+	})
+
+	t.Run("no confirmation without a terminal", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("")
+		require.NoError(t, act.insertSingle(ctxutil.WithInteractive(ctx, false), "foo", "hunter3", nil, true))
+		assert.NotContains(t, buf.String(), "Changes to foo")
+	})
+
+	t.Run("edit prints the changes", func(t *testing.T) {
+		defer buf.Reset()
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.NoError(t, act.editUpdate(ctx, "foo", sec.Bytes(), []byte("hunter3\nurl: example.com\n"), false, "test"))
+		assert.Contains(t, buf.String(), "Changes to foo:\n  + url\n")
+	})
+}
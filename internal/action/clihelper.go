@@ -1,8 +1,14 @@
 package action
 
 import (
+	"context"
+	"os"
+	"path"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/table"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/urfave/cli/v2"
 )
 
@@ -44,3 +50,61 @@ OUTER:
 	}
 	return args, kvps
 }
+
+// withJobs returns a context with the number of concurrent workers set, if
+// it was given with the --jobs flag
+func withJobs(ctx context.Context, c *cli.Context) context.Context {
+	if !c.IsSet("jobs") {
+		return ctx
+	}
+	return ctxutil.WithWorkers(ctx, c.Int("jobs"))
+}
+
+// warmup decrypts one of the given secrets of every mount before a batch
+// operation starts its workers. That way the pinentry shows up right away
+// instead of minutes into the run and nothing is done if it fails. Skipped
+// with --no-warmup, e.g. if the agent has the passphrase cached anyway.
+func (s *Action) warmup(ctx context.Context, c *cli.Context, names []string) error {
+	if c.Bool("no-warmup") || ctxutil.IsDryRun(ctx) || len(names) < 1 {
+		return nil
+	}
+	if err := s.Store.Warmup(ctx, names); err != nil {
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "%s. Nothing was done, use --no-warmup to skip this check", err)
+	}
+	return nil
+}
+
+// outputFormat returns the output format given with --format. The flag of
+// the command takes precedence over the global one, e.g. gopass --format json
+// ls.
+func outputFormat(c *cli.Context) (string, error) {
+	for _, cc := range c.Lineage() {
+		if cc.IsSet("format") {
+			return out.ParseFormat(cc.String("format"))
+		}
+	}
+	return out.FormatText, nil
+}
+
+// newTable returns a table that fits into the terminal if stdout is one
+func newTable(columns ...table.Column) *table.Table {
+	if f, ok := stdout.(*os.File); ok {
+		return table.ForTerminal(f, columns...)
+	}
+	return table.New(columns...)
+}
+
+// matchName returns true if the secret or one of its folders matches any of
+// the given glob patterns, e.g. "wifi/*" and "wifi" both match wifi/home
+func matchName(name string, patterns []string) bool {
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		for _, p := range patterns {
+			if m, err := path.Match(strings.TrimSuffix(p, "/"), prefix); err == nil && m {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,10 +1,15 @@
 package action
 
 import (
+	"context"
 	"flag"
 	"testing"
 
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
 
@@ -82,3 +87,36 @@ func TestParseArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchName(t *testing.T) {
+	for _, p := range []string{"wifi", "wifi/", "wifi/*", "*/home"} {
+		assert.True(t, matchName("wifi/home", []string{p}), p)
+	}
+	assert.True(t, matchName("wifi/office/guest", []string{"wifi/*"}))
+	assert.False(t, matchName("wifi-old/home", []string{"wifi"}))
+	assert.False(t, matchName("wifi/home", nil))
+}
+
+func TestWarmup(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	assert.NoError(t, act.warmup(ctx, gptest.CliCtx(ctx, t), nil))
+	assert.NoError(t, act.warmup(ctx, gptest.CliCtx(ctx, t), []string{"foo"}))
+
+	// nothing is done if the first secret can't be decrypted
+	err = act.warmup(ctx, gptest.CliCtx(ctx, t), []string{"doesnotexist", "foo"})
+	require.Error(t, err)
+	assert.Equal(t, ExitNotFound, ExitCode(err))
+	assert.Contains(t, err.Error(), "--no-warmup")
+
+	assert.NoError(t, act.warmup(ctx, gptest.CliCtxWithFlags(ctx, t, map[string]string{"no-warmup": "true"}), []string{"doesnotexist"}))
+	assert.NoError(t, act.warmup(ctxutil.WithDryRun(ctx, true), gptest.CliCtx(ctx, t), []string{"doesnotexist"}))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/config"
@@ -23,6 +24,12 @@ func (s *Action) Clone(c *cli.Context) error {
 	if c.IsSet("crypto") {
 		ctx = backend.WithCryptoBackendString(ctx, c.String("crypto"))
 	}
+	if c.IsSet("depth") {
+		if c.Int("depth") < 1 {
+			return ExitError(ExitUsage, nil, "--depth must be at least 1")
+		}
+		ctx = backend.WithCloneDepth(ctx, c.Int("depth"))
+	}
 	path := c.String("path")
 
 	if c.Args().Len() < 1 {
@@ -74,7 +81,10 @@ func (s *Action) clone(ctx context.Context, repo, mount, path string) error {
 	// clone repo
 	out.Noticef(ctx, "Cloning git repository %q to %q ...", repo, path)
 	if _, err := backend.Clone(ctx, storageBackendOrDefault(ctx), repo, path); err != nil {
-		return ExitError(ExitGit, err, "failed to clone repo %q to %q: %s", repo, path, err)
+		return ExitError(ExitGit, err, "failed to clone repo %q to %q: %s. Run the same command again to continue", repo, path, err)
+	}
+	if backend.GetCloneDepth(ctx) > 0 {
+		out.Noticef(ctx, "This is a shallow clone without the full history. Run 'git -C %s fetch --unshallow' to fetch it later", path)
 	}
 
 	// add mount
@@ -103,6 +113,26 @@ func (s *Action) clone(ctx context.Context, repo, mount, path string) error {
 		out.Errorf(ctx, "Failed to configure git: %s", err)
 	}
 
+	// import the public keys of all team members
+	if ctxutil.IsExportKeys(ctx) {
+		if sub, err := s.Store.GetSubStore(mount); err == nil {
+			if err := sub.ImportMissingPublicKeys(ctx); err != nil {
+				out.Errorf(ctx, "Failed to import missing public keys: %s", err)
+			}
+		}
+	}
+
+	// trust the recipients of the new store, so later changes by others
+	// have to be confirmed
+	if sub, err := s.Store.GetSubStore(mount); err == nil && ctxutil.IsCheckRecipientHash(ctx) {
+		if err := sub.AckRecipients(ctx); err != nil {
+			out.Errorf(ctx, "Failed to acknowledge the recipients: %s", err)
+		}
+	}
+
+	s.printExpiringRecipients(ctx, mount)
+	s.printCloneSummary(ctx, mount)
+
 	if mount != "" {
 		mount = " " + mount
 	}
@@ -111,6 +141,34 @@ func (s *Action) clone(ctx context.Context, repo, mount, path string) error {
 	return nil
 }
 
+// printCloneSummary prints the number of entries and recipients of the
+// cloned store and if its first secret can be decrypted with the local keys
+func (s *Action) printCloneSummary(ctx context.Context, mount string) {
+	sub, err := s.Store.GetSubStore(mount)
+	if err != nil {
+		debug.Log("failed to get store %q: %s", mount, err)
+		return
+	}
+	names, err := sub.List(ctx, "")
+	if err != nil {
+		out.Errorf(ctx, "Failed to list the cloned secrets: %s", err)
+		return
+	}
+	out.Printf(ctx, "📦 %d entries encrypted for %d recipients", len(names), len(sub.Recipients(ctx)))
+	if len(names) < 1 {
+		return
+	}
+
+	// the listed names include the mount point, the sub store expects them
+	// relative to it
+	name := names[0]
+	if _, err := sub.Get(ctx, strings.TrimPrefix(name, mount+"/")); err != nil {
+		out.Warningf(ctx, "Can not decrypt %s: %s. Ask one of the recipients to add your key with `%s recipients add`", name, err, s.Name)
+		return
+	}
+	out.OKf(ctx, "Your keys can decrypt %s", name)
+}
+
 func (s *Action) cloneAddMount(ctx context.Context, mount, path string) error {
 	if mount == "" {
 		return nil
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
 	git "github.com/gopasspw/gopass/internal/backend/storage/gitfs"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
@@ -76,6 +77,32 @@ func TestClone(t *testing.T) {
 		defer buf.Reset()
 		gd := aGitRepo(ctx, u, t, "other-repo")
 		assert.NoError(t, act.clone(ctx, gd, "gd", filepath.Join(u.Dir, "mount")))
+		assert.Contains(t, buf.String(), "0 entries encrypted for 1 recipients")
+	})
+
+	t.Run("clone to mount with a secret", func(t *testing.T) {
+		defer buf.Reset()
+		gd := filepath.Join(u.Dir, "secret-repo")
+		require.NoError(t, os.MkdirAll(filepath.Join(gd, "db"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(gd, plain.IDFile), []byte("0xDEADBEEF"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(gd, "db", "root."+plain.Ext), []byte("secret"), 0600))
+		_, err := git.Init(ctx, gd, "Nobody", "foo.bar@example.org")
+		require.NoError(t, err)
+
+		require.NoError(t, act.clone(ctx, gd, "sr", filepath.Join(u.Dir, "secret-mount")))
+		assert.Contains(t, buf.String(), "1 entries encrypted for 1 recipients")
+		assert.Contains(t, buf.String(), "Your keys can decrypt sr/db/root")
+	})
+
+	t.Run("shallow clone", func(t *testing.T) {
+		defer buf.Reset()
+		gd := aGitRepo(ctx, u, t, "shallow-repo")
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"depth": "1"}, "file://"+gd, "shallow")
+		assert.NoError(t, act.Clone(c))
+		assert.Contains(t, buf.String(), "fetch --unshallow")
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"depth": "0"}, "file://"+gd, "shallow2")
+		assert.Error(t, act.Clone(c))
 	})
 }
 
@@ -0,0 +1,49 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// confirmCollision asks before creating a secret named like an existing
+// folder or inside a folder that is named like an existing secret. Both work,
+// but one name then refers to a secret and a folder. Nothing is lost, so
+// scripts only get a warning.
+func (s *Action) confirmCollision(ctx context.Context, name string, force bool) error {
+	if force || ctxutil.IsDryRun(ctx) || s.Store.Exists(ctx, name) {
+		return nil
+	}
+	msg := s.collision(ctx, name)
+	if msg == "" {
+		return nil
+	}
+
+	if !ctxutil.IsInteractive(ctx) || ctxutil.IsConfirm(ctx) {
+		out.Warningf(ctx, "%s. Creating %s anyway", msg, name)
+		return nil
+	}
+	if !termio.AskForConfirmation(ctx, fmt.Sprintf("%s. Create %s anyway?", msg, name)) {
+		return ExitError(ExitAborted, nil, "user aborted. not creating %s", name)
+	}
+	return nil
+}
+
+// collision describes what a new secret would share its name with, if any
+func (s *Action) collision(ctx context.Context, name string) string {
+	name = strings.TrimSuffix(name, "/")
+	if s.Store.IsDir(ctx, name) {
+		return fmt.Sprintf("%s is a folder already", name)
+	}
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		if p := strings.Join(parts[:i], "/"); s.Store.Exists(ctx, p) {
+			return fmt.Sprintf("%s is a secret already and would become a folder as well", p)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmCollision(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		termio.Stdin = os.Stdin
+	}()
+
+	sec := secrets.New()
+	sec.SetPassword("123")
+	require.NoError(t, act.Store.Set(ctx, "web/admin", sec))
+
+	assert.Equal(t, "", act.collision(ctx, "other"))
+	assert.Equal(t, "web is a folder already", act.collision(ctx, "web"))
+	assert.Equal(t, "foo is a secret already and would become a folder as well", act.collision(ctx, "foo/bar/baz"))
+
+	// scripts are only warned
+	require.NoError(t, act.confirmCollision(ctx, "web", false))
+	assert.Contains(t, buf.String(), "web is a folder already. Creating web anyway")
+	buf.Reset()
+
+	// existing secrets and --force are not checked
+	require.NoError(t, act.confirmCollision(ctx, "foo", false))
+	require.NoError(t, act.confirmCollision(ctx, "web", true))
+	assert.Empty(t, buf.String())
+
+	ctx = ctxutil.WithInteractive(ctx, true)
+	termio.Stdin = strings.NewReader("n\n")
+	assert.Error(t, act.confirmCollision(ctx, "foo/bar", false))
+	termio.Stdin = strings.NewReader("y\n")
+	assert.NoError(t, act.confirmCollision(ctx, "foo/bar", false))
+}
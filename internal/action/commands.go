@@ -3,8 +3,16 @@ package action
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/age"
+	"github.com/gopasspw/gopass/internal/cred"
+	"github.com/gopasspw/gopass/internal/k8s"
+	"github.com/gopasspw/gopass/internal/serve"
+	"github.com/gopasspw/gopass/internal/tpl"
+	"github.com/gopasspw/gopass/internal/vault"
+	"github.com/gopasspw/gopass/pkg/hibp/bloom"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,6 +25,34 @@ func ShowFlags() []cli.Flag {
 			Aliases: []string{"y"},
 			Usage:   "Always answer yes to yes/no questions",
 		},
+		&cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "Do not use the on-disk caches of GPG key listings and secret names",
+		},
+		&cli.BoolFlag{
+			Name:  "no-autosync",
+			Usage: "Do not pull and push after changes, overrides autosync",
+		},
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "Skip all network access, e.g. git pull and push, keyserver lookups and update checks. Changes are pushed by the next sync",
+		},
+		&cli.BoolFlag{
+			Name:  "no-pager",
+			Usage: "Do not invoke a pager for long output, overrides nopager",
+		},
+		&cli.BoolFlag{
+			Name:  "no-notify",
+			Usage: "Do not show desktop notifications, overrides notifications",
+		},
+		&cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "Log which secrets are read and written and show the raw output of gpg and git, e.g. if a secret can not be decrypted. Logs go to stderr or GOPASS_DEBUG_LOG",
+		},
+		&cli.BoolFlag{
+			Name:  "debug",
+			Usage: "Log everything --verbose does and the debug messages, including the command lines, durations and stderr of gpg and git. Secrets are never logged",
+		},
 		&cli.BoolFlag{
 			Name:    "clip",
 			Aliases: []string{"c"},
@@ -31,25 +67,59 @@ func ShowFlags() []cli.Flag {
 			Name:  "qr",
 			Usage: "Print the password as a QR Code",
 		},
+		&cli.StringFlag{
+			Name:  "chars",
+			Usage: "Print only the characters at the given positions of the password, e.g. 3,7,12. The first one is 1",
+		},
+		&cli.BoolFlag{
+			Name:  "type",
+			Usage: "Type the password (or the autotype sequence of the secret) into the focused window after a short countdown",
+		},
+		&cli.StringFlag{
+			Name:  "key",
+			Usage: "Use the value of this key instead of the password, e.g. with --qr",
+		},
 		&cli.BoolFlag{
 			Name:    "unsafe",
 			Aliases: []string{"u", "force", "f"},
-			Usage:   "Display unsafe content (e.g. the password) even if safecontent is enabled",
+			Usage:   "Display unsafe content (e.g. the password) even if safecontent is enabled. Also confirms destructive actions, e.g. overwriting or removing secrets",
 		},
 		&cli.BoolFlag{
 			Name:    "password",
 			Aliases: []string{"o"},
 			Usage:   "Display only the password. Takes precedence over all other flags.",
 		},
+		&cli.BoolFlag{
+			Name:  "no-newline",
+			Usage: "Do not print a final newline, even if the output is a terminal",
+		},
+		&cli.BoolFlag{
+			Name:  "safe",
+			Usage: "Hide the password and unsafe keys even if the output is not a terminal, as if safecontent was enabled",
+		},
 		&cli.StringFlag{
 			Name:  "revision",
 			Usage: "Show a past revision. Does NOT support RCS specific shortcuts. Use exact revision or -N to select the Nth oldest revision of this entry.",
 		},
+		&cli.BoolFlag{
+			Name:  "with-meta",
+			Usage: "Show when the secret was created, when it was last changed and by whom",
+		},
 		&cli.BoolFlag{
 			Name:    "noparsing",
 			Aliases: []string{"n"},
 			Usage:   "Do not parse the output.",
 		},
+		&cli.BoolFlag{
+			Name:    "recursive",
+			Aliases: []string{"r"},
+			Usage:   "Show all secrets below the given folder",
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Output format of show, list, mounts, recipients and audit: text, json or yaml. show also supports k8s",
+			Value: "text",
+		},
 	}
 }
 
@@ -57,32 +127,108 @@ func ShowFlags() []cli.Flag {
 func (s *Action) GetCommands() []*cli.Command {
 	return []*cli.Command{
 		{
-			Name:        "alias",
-			Usage:       "Manage domain aliases",
-			Description: "Manages domain aliases. Note: this command might change or go away.",
-			Action:      s.AliasesPrint,
-			Hidden:      true,
+			Name:  "agent",
+			Usage: "Run the age agent",
+			Description: "" +
+				"Runs the age agent in the foreground. It unlocks the age keyring once and decrypts " +
+				"secrets for other gopass processes of the same user, so they don't ask for the " +
+				"passphrase again. The passphrase is never sent to the agent, the identities are " +
+				"held in locked memory and purged after the TTL. Enable it with 'gopass config ageagent true'. " +
+				"The status and clear subcommands inspect and clear the cache of the gpg-agent instead.",
+			Action: s.Agent,
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "ttl",
+					Usage: "Purge the unlocked identities after this long, 0 keeps them until the agent is locked",
+					Value: age.DefaultAgentTTL,
+				},
+			},
 			Subcommands: []*cli.Command{
 				{
-					Name:        "add",
-					Action:      s.AliasesAdd,
-					Usage:       "Add a new alias",
-					ArgsUsage:   "[alias] [domain]",
-					Description: "Adds a new alias",
+					Name:        "lock",
+					Usage:       "Purge the unlocked identities",
+					Description: "Purges the identities held by the agent. The next decrypt asks for the passphrase again.",
+					Action:      s.AgentLock,
 				},
 				{
-					Name:        "remove",
-					Action:      s.AliasesRemove,
-					Usage:       "Remove an alias from a domain",
-					ArgsUsage:   "[alias] [domain]",
-					Description: "Remove an alias from a domain",
+					Name:  "status",
+					Usage: "Show which gpg keys have their passphrase cached by the gpg-agent",
+					Description: "" +
+						"Lists the secret keys of the gpg stores and whether the gpg-agent has cached " +
+						"their passphrase. The gpg-agent is not started if it's not running.",
+					Action: s.AgentStatus,
 				},
 				{
-					Name:        "delete",
-					Action:      s.AliasesDelete,
-					Usage:       "Delete an entire domain",
-					ArgsUsage:   "[alias]",
-					Description: "Delete an entire domain",
+					Name:  "clear",
+					Usage: "Make the gpg-agent forget the cached passphrases",
+					Description: "" +
+						"Clears the passphrases of the secret keys of the gpg stores from the cache of the " +
+						"gpg-agent. The next decrypt asks for the passphrase again. This is done as well when " +
+						"a long running command locks after 'lockafter' seconds without activity.",
+					Action: s.AgentClear,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "all",
+							Usage: "Reload the gpg-agent to forget all passphrases, including those of keys not used by gopass",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "alias",
+			Usage: "Manage path aliases",
+			Description: "" +
+				"Aliases are personal short names for long path prefixes, e.g. 'prod' for " +
+				"'customers/acme/production'. They are expanded in all commands and only " +
+				"stored in the config, never in a store.",
+			Action: s.PathAliasesPrint,
+			Subcommands: []*cli.Command{
+				{
+					Name:         "add",
+					Action:       s.PathAliasAdd,
+					Usage:        "Add a new path alias",
+					ArgsUsage:    "<name> <path>",
+					Description:  "Adds a new path alias. Aliases may point to other aliases, but cycles are rejected.",
+					BashComplete: s.Complete,
+				},
+				{
+					Name:         "remove",
+					Aliases:      []string{"rm"},
+					Action:       s.PathAliasRemove,
+					Usage:        "Remove a path alias",
+					ArgsUsage:    "<name>",
+					Description:  "Removes a path alias. The entries it points to are not changed.",
+					BashComplete: s.PathAliasesComplete,
+				},
+				{
+					Name:        "domains",
+					Usage:       "Manage domain aliases",
+					Description: "Manages domain aliases. Note: this command might change or go away.",
+					Action:      s.AliasesPrint,
+					Subcommands: []*cli.Command{
+						{
+							Name:        "add",
+							Action:      s.AliasesAdd,
+							Usage:       "Add a new alias",
+							ArgsUsage:   "[alias] [domain]",
+							Description: "Adds a new alias",
+						},
+						{
+							Name:        "remove",
+							Action:      s.AliasesRemove,
+							Usage:       "Remove an alias from a domain",
+							ArgsUsage:   "[alias] [domain]",
+							Description: "Remove an alias from a domain",
+						},
+						{
+							Name:        "delete",
+							Action:      s.AliasesDelete,
+							Usage:       "Delete an entire domain",
+							ArgsUsage:   "[alias]",
+							Description: "Delete an entire domain",
+						},
+					},
 				},
 			},
 		},
@@ -95,6 +241,131 @@ func (s *Action) GetCommands() []*cli.Command {
 				"against a list of previously leaked passwords.",
 			Before: s.IsInitialized,
 			Action: s.Audit,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:    "jobs",
+					Aliases: []string{"j"},
+					Usage:   "Number of secrets to decrypt concurrently",
+				},
+				&cli.BoolFlag{
+					Name:  "no-warmup",
+					Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+				},
+				&cli.Float64Flag{
+					Name:  "min-entropy",
+					Usage: "Report passwords with less entropy (in bits) as weak. Uses the zxcvbn score if not set",
+				},
+				&cli.IntFlag{
+					Name:  "max-age",
+					Usage: "Report passwords not changed for more than this many days as old, 0 disables the check",
+					Value: 90,
+				},
+				&cli.StringFlag{
+					Name:  "expiring",
+					Usage: "Report secrets that have expired or expire within this duration, e.g. 30d, according to their expires key",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format, text, json or yaml",
+					Value: "text",
+				},
+				&cli.StringFlag{
+					Name:  "fail-on",
+					Usage: "Exit with an error if there are findings of at least this severity (low, medium or high)",
+					Value: "low",
+				},
+				&cli.StringSliceFlag{
+					Name:  "exclude",
+					Usage: "Skip secrets matching this glob pattern, e.g. 'wifi/*'. Can be given multiple times",
+				},
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:      "hibp",
+					Usage:     "Check passwords against haveibeenpwned.com",
+					ArgsUsage: "[filter]",
+					Description: "" +
+						"This command decrypts all secrets and checks if their passwords were part of a " +
+						"data breach, either using the haveibeenpwned.com range API or local copies of " +
+						"the password dumps. The API only receives the first five characters of the " +
+						"SHA-1 sum of each password. With --build-filter the dumps are turned into a " +
+						"bloom filter, a small fraction of their size, that is used with --filter to " +
+						"check the passwords entirely offline. Matches of a filter are reported as " +
+						"possible matches since bloom filters have false positives.",
+					Before: s.IsInitialized,
+					Action: s.AuditHIBP,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "no-warmup",
+							Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+						},
+						&cli.BoolFlag{
+							Name:  "api",
+							Usage: "Use the haveibeenpwned.com range API",
+						},
+						&cli.StringSliceFlag{
+							Name:  "dumps",
+							Usage: "Check against this HIBP password dump (SHA-1, ordered by hash for best performance). Can be given multiple times",
+						},
+						&cli.StringSliceFlag{
+							Name:  "filter",
+							Usage: "Check against this bloom filter built with --build-filter. Can be given multiple times",
+						},
+						&cli.StringSliceFlag{
+							Name:  "build-filter",
+							Usage: "Build a bloom filter from this HIBP password dump (SHA-1, gzipped if ending in .gz) instead of checking the passwords. Can be given multiple times",
+						},
+						&cli.StringFlag{
+							Name:    "output",
+							Aliases: []string{"o"},
+							Usage:   "Write the bloom filter to this file",
+						},
+						&cli.Float64Flag{
+							Name:  "fp-rate",
+							Usage: "False positive rate of the bloom filter",
+							Value: bloom.DefaultFalsePositiveRate,
+						},
+						&cli.IntFlag{
+							Name:    "jobs",
+							Aliases: []string{"j"},
+							Usage:   "Number of secrets to decrypt concurrently",
+						},
+						&cli.StringSliceFlag{
+							Name:  "exclude",
+							Usage: "Skip secrets matching this glob pattern, e.g. 'wifi/*'. Can be given multiple times",
+						},
+					},
+				},
+				{
+					Name:      "log",
+					Usage:     "Show who changed which secrets and recipients",
+					ArgsUsage: "[path]",
+					Description: "" +
+						"This command prints a chronological report of all changes of the secrets and " +
+						"recipients, of all mounts or below the given path, from the git history. The " +
+						"operation (created, updated, deleted, renamed, re-encrypted or recipients) is " +
+						"inferred from the commits. Changes of the recipients list the added and removed " +
+						"keys. Renamed secrets are followed, so their changes under the old name are " +
+						"included. Nothing is decrypted.",
+					Before: s.IsInitialized,
+					Action: s.AuditLog,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "since",
+							Usage: "Only show changes on or after this date (YYYY-MM-DD) or RFC 3339 timestamp",
+						},
+						&cli.StringFlag{
+							Name:  "until",
+							Usage: "Only show changes on or before this date (YYYY-MM-DD) or before this RFC 3339 timestamp",
+						},
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Output format, text, csv, json or yaml",
+							Value: "text",
+						},
+					},
+				},
+			},
 		},
 		{
 			Name:      "cat",
@@ -120,13 +391,18 @@ func (s *Action) GetCommands() []*cli.Command {
 				"" +
 				"Needs at least one argument (git URL) to clone from. " +
 				"Accepts a second argument (mount location) to clone and mount a sub-store, e.g. " +
-				"'gopass clone git@example.com/store.git foo/bar'",
+				"'gopass clone git@example.com/store.git foo/bar'. " +
+				"An interrupted clone is continued when it's run again.",
 			Action: s.Clone,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Name:  "path",
 					Usage: "Path to clone the repo to",
 				},
+				&cli.IntFlag{
+					Name:  "depth",
+					Usage: "Only fetch this many of the latest commits, e.g. 1 for a shallow clone",
+				},
 				&cli.StringFlag{
 					Name:  "crypto",
 					Usage: fmt.Sprintf("Select crypto backend: %s", strings.Join(backend.CryptoBackends(), ", ")),
@@ -141,35 +417,63 @@ func (s *Action) GetCommands() []*cli.Command {
 				"This command allows for easy printing and editing of the configuration. " +
 				"Without argument, the entire config is printed. " +
 				"With a single argument, a single key can be printed. " +
-				"With two arguments a setting specified by key can be set to value.",
+				"With two arguments a setting specified by key can be set to value. " +
+				"Keys can be given with their section, e.g. git.autopush. Values are taken from the " +
+				"defaults, the system config, the user config, the per mount config and GOPASS_<SECTION>_<KEY> " +
+				"environment variables, in increasing precedence. Command line flags always win.",
 			Action:       s.Config,
 			BashComplete: s.ConfigComplete,
-		},
-		{
-			Name:        "convert",
-			Usage:       "Convert a store to different backends",
-			Description: "Convert a store to a different set of backends",
-			Action:      s.Convert,
-			Before:      s.IsInitialized,
-			Hidden:      true,
 			Flags: []cli.Flag{
 				&cli.StringFlag{
-					Name:     "store",
-					Usage:    "Specify which store to convert",
-					Required: true,
+					Name:  "store",
+					Usage: "Display or edit the per mount configuration of this mount, e.g. gnupghome",
 				},
 				&cli.BoolFlag{
-					Name:  "move",
-					Value: true,
-					Usage: "Replace store?",
+					Name:  "system",
+					Usage: "Display or edit the system wide configuration",
+				},
+				&cli.BoolFlag{
+					Name:  "unset",
+					Usage: "Remove the given key, so the value of a lower layer or the default is used again",
+				},
+				&cli.BoolFlag{
+					Name:    "list",
+					Aliases: []string{"l"},
+					Usage:   "List all effective values as section.key=value",
+				},
+				&cli.BoolFlag{
+					Name:  "show-origin",
+					Usage: "Show where each value listed with --list came from",
+				},
+			},
+		},
+		{
+			Name:  "convert",
+			Usage: "Convert a store to different backends",
+			Description: "" +
+				"This command converts a store in place to a different crypto or storage backend, " +
+				"e.g. from gpg to age or from fs to gitfs. Every secret is decrypted, re-encrypted " +
+				"with the new backend and verified before the old files are removed. The conversion " +
+				"is committed as one change, the git history is preserved unless --fresh-history is " +
+				"given. An interrupted conversion is resumed when it is run again with the same backends.",
+			Action: s.Convert,
+			Before: s.IsInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Specify which store to convert. Default: the root store",
 				},
 				&cli.StringFlag{
 					Name:  "crypto",
-					Usage: fmt.Sprintf("Which crypto backend? %s", strings.Join(backend.CryptoBackends(), ", ")),
+					Usage: fmt.Sprintf("Which crypto backend? %s. Default: keep the current one", strings.Join(backend.CryptoBackends(), ", ")),
 				},
 				&cli.StringFlag{
 					Name:  "storage",
-					Usage: fmt.Sprintf("Which storage backend? %s", strings.Join(backend.StorageBackends(), ", ")),
+					Usage: fmt.Sprintf("Which storage backend? %s. Default: keep the current one", strings.Join(backend.StorageBackends(), ", ")),
+				},
+				&cli.BoolFlag{
+					Name:  "fresh-history",
+					Usage: "Start a new git history. The previous one is moved next to the store",
 				},
 			},
 		},
@@ -183,15 +487,24 @@ func (s *Action) GetCommands() []*cli.Command {
 				"This also works across different sub-stores. If the source is a directory it will " +
 				"automatically copy recursively. In that case, the source directory is re-created " +
 				"at the destination if no trailing slash is found, otherwise the contents are " +
-				"flattened (similar to rsync).",
+				"flattened (similar to rsync). Each copied secret is committed on its own.",
 			Before:       s.IsInitialized,
 			Action:       s.Copy,
 			BashComplete: s.Complete,
 			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "recursive",
+					Aliases: []string{"r"},
+					Usage:   "Copy all secrets below the given folder. Folders are always copied recursively",
+				},
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
-					Usage:   "Force to copy the secret and overwrite existing one",
+					Usage:   "Force to copy the secret and overwrite existing one. Copies within the same mount and recipients scope don't re-encrypt the secret",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be written and committed",
 				},
 			},
 		},
@@ -201,10 +514,23 @@ func (s *Action) GetCommands() []*cli.Command {
 			Usage:     "Easy creation of new secrets",
 			ArgsUsage: "[secret]",
 			Description: "" +
-				"This command starts a wizard to aid in creation of new secrets.",
+				"This command starts a wizard to aid in creation of new secrets. " +
+				"Empty answers skip optional fields. The path of the new secret is " +
+				"printed at the end, only the path if stdout is not a terminal. " +
+				"With --template the new secret is rendered from the named template instead.",
 			Before: s.IsInitialized,
 			Action: s.Create,
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "template",
+					Aliases: []string{"t"},
+					Usage:   "Create the secret from this named template",
+				},
+				&cli.BoolFlag{
+					Name:    "print",
+					Aliases: []string{"p"},
+					Usage:   "Print the generated password instead of copying it to the clipboard",
+				},
 				&cli.StringFlag{
 					Name:    "store",
 					Aliases: []string{"s"},
@@ -217,13 +543,82 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:  "cred",
+			Usage: "Serve secrets as systemd credentials",
+			Description: "" +
+				"Maps systemd credentials to secrets. Units load them with " +
+				"LoadCredential=<id>:/run/gopass-cred.sock while 'gopass cred serve' is running. " +
+				"The credential content is the password of the secret.",
+			Action: s.CredPrint,
+			Subcommands: []*cli.Command{
+				{
+					Name:         "add",
+					Action:       s.CredAdd,
+					Usage:        "Map a credential to a secret",
+					ArgsUsage:    "<[unit/]id> <secret>",
+					Description:  "Maps the credential id to a secret. <unit>/<id> only serves the credential to this unit and wins over <id>.",
+					BashComplete: s.Complete,
+				},
+				{
+					Name:        "remove",
+					Aliases:     []string{"rm"},
+					Action:      s.CredRemove,
+					Usage:       "Remove a credential",
+					ArgsUsage:   "<[unit/]id>",
+					Description: "Removes a credential. The secret it points to is not changed.",
+				},
+				{
+					Name:  "serve",
+					Usage: "Serve the credentials on a unix socket",
+					Description: "" +
+						"Answers the LoadCredential= requests of systemd in the foreground until it is interrupted. " +
+						"Only connections from the allowed uids are served, systemd itself connects as root.",
+					Before: s.IsInitialized,
+					Action: s.CredServe,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "socket",
+							Usage: "Path of the unix socket",
+							Value: cred.DefaultSocket,
+						},
+						&cli.IntSliceFlag{
+							Name:  "allow-uid",
+							Usage: "Serve connections from this uid, may be given multiple times",
+							Value: cli.NewIntSlice(0),
+						},
+					},
+				},
+				{
+					Name:      "write",
+					Usage:     "Write a secret to a credential file",
+					ArgsUsage: "<secret> <file>",
+					Description: "" +
+						"Writes the password of the secret to a file only readable by the current user, " +
+						"e.g. in ExecStartPre= for LoadCredential=<id>:<file>. The file is replaced atomically.",
+					Before:       s.IsInitialized,
+					Action:       s.CredWrite,
+					BashComplete: s.Complete,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "force",
+							Aliases: []string{"f"},
+							Usage:   "Write to a directory other users can write to",
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:      "delete",
 			Usage:     "Remove one or many secrets from the store",
 			ArgsUsage: "[secret [key]]",
 			Description: "" +
 				"This command removes secrets. It can work recursively on folders. " +
-				"Recursing across stores is purposefully not supported.",
+				"Recursive deletes list all affected entries and ask for a single " +
+				"confirmation. Recursing across stores is purposefully not supported. " +
+				"Names that are a secret and a folder at the same time need either " +
+				"--recursive to remove both or --leaf-only to remove the secret.",
 			Aliases:      []string{"remove", "rm"},
 			Before:       s.IsInitialized,
 			Action:       s.Delete,
@@ -234,11 +629,35 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"r"},
 					Usage:   "Recursive delete files and folders",
 				},
+				&cli.BoolFlag{
+					Name:  "leaf-only",
+					Usage: "Only delete the secret if there is a folder with the same name",
+				},
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
 					Usage:   "Force to delete the secret",
 				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be written and committed",
+				},
+			},
+		},
+		{
+			Name:  "deleted",
+			Usage: "List removed secrets",
+			Description: "" +
+				"This command lists the secrets that were removed from the store and " +
+				"can be restored with 'gopass undelete'. The most recently removed " +
+				"secrets are listed first.",
+			Before: s.IsInitialized,
+			Action: s.Deleted,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "limit",
+					Usage: "Only list the given number of secrets",
+				},
 			},
 		},
 		{
@@ -247,11 +666,12 @@ func (s *Action) GetCommands() []*cli.Command {
 			ArgsUsage: "[secret]",
 			Description: "" +
 				"Use this command to insert a new secret or edit an existing one using " +
-				"your $EDITOR. It will attempt to create a secure temporary directory " +
-				"for storing your secret while the editor is accessing it. Please make " +
-				"sure your editor doesn't leak sensitive data to other locations while " +
-				"editing.\n" +
-				"Note: If $EDITOR is not set we will try 'editor'. If that's not available " +
+				"your $GOPASS_EDITOR, $VISUAL or $EDITOR. It will attempt to create a secure " +
+				"temporary directory for storing your secret while the editor is accessing it " +
+				"and overwrites the file afterwards. Please make sure your editor doesn't leak " +
+				"sensitive data to other locations while editing. Nothing is saved if the " +
+				"content is unchanged or the editor fails.\n" +
+				"Note: If none of them is set we will try 'editor'. If that's not available " +
 				"either we fall back to 'vi'. Consider using 'update-alternatives --config editor " +
 				"to change the defaults.",
 			Before:       s.IsInitialized,
@@ -269,26 +689,190 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"c"},
 					Usage:   "Create a new secret if none found",
 				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Do not print the password strength assessment",
+				},
+				&cli.BoolFlag{
+					Name:  "unsafe",
+					Usage: "Include the old and new values in the summary of the changes",
+				},
+				&cli.BoolFlag{
+					Name:  "force-weak",
+					Usage: "Save a password that violates the policy of its folder. The violated rules are recorded in the commit",
+				},
+				&cli.BoolFlag{
+					Name:  "follow",
+					Usage: "Change the target if the secret is a link",
+				},
+				&cli.BoolFlag{
+					Name:  "break-link",
+					Usage: "Replace the secret by one of its own if it is a link",
+				},
 			},
 		},
 		{
-			Name:         "env",
-			Usage:        "Run a subprocess with a pre-populated environment",
-			ArgsUsage:    "[secret] [command and args...]",
-			Description:  "This command runs a sub process with the environment populated from the keys of a secret.",
+			Name:      "env",
+			Usage:     "Run a subprocess with a pre-populated environment",
+			ArgsUsage: "[secret] -- [command and args...]",
+			Description: "" +
+				"This command runs a sub process with the environment populated from the keys of a secret. " +
+				"Every key is exported upper-cased, with all other characters than letters and digits " +
+				"replaced by underscores, the password as PASSWORD. If the secret is a folder all secrets " +
+				"below it are merged, later ones override earlier ones. The values are never written to disk. " +
+				"Interrupts are forwarded to the sub process and its exit code is passed on.",
 			Before:       s.IsInitialized,
 			Action:       s.Env,
 			BashComplete: s.Complete,
-			Hidden:       true,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "keep-env",
+					Usage: "Pass the environment of gopass on to the sub process. Use --keep-env=false for a clean environment with only the secrets",
+					Value: true,
+				},
+			},
+		},
+		{
+			Name:  "export",
+			Usage: "Export the store to an encrypted archive",
+			Description: "" +
+				"This command decrypts all secrets and writes them to a single tar archive, " +
+				"encrypted as a whole with age. The archive is either encrypted for the given age " +
+				"recipients or with a passphrase. It contains a manifest with the checksums of " +
+				"all secrets and can be restored with 'gopass import --format archive'. " +
+				"The plaintext is never written to disk.",
+			Before: s.IsInitialized,
+			Action: s.Export,
+			Subcommands: []*cli.Command{
+				{
+					Name:      "vault",
+					Usage:     "Export secrets to HashiCorp Vault",
+					ArgsUsage: "[folder]",
+					Description: "" +
+						"Writes all secrets below the folder, or the whole store, to --path, e.g. secret/team, " +
+						"of a Vault KV v2 mount. Every secret becomes a new version with the password, " +
+						"the body as notes and all keys. Dotted keys become nested maps.",
+					Before:       s.IsInitialized,
+					Action:       s.ExportVault,
+					BashComplete: s.Complete,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "addr",
+							Usage: "Address of the Vault server, default: $VAULT_ADDR. The token is read from $VAULT_TOKEN",
+						},
+						&cli.StringFlag{
+							Name:  "path",
+							Usage: "Mount and folder to export to, e.g. secret/team",
+						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Only print the secrets that would be exported",
+						},
+						&cli.BoolFlag{
+							Name:  "resume",
+							Usage: "Continue an interrupted export after the last exported secret",
+						},
+						&cli.IntFlag{
+							Name:  "rate",
+							Usage: "Maximum number of requests per second, 0 for no limit",
+							Value: vault.DefaultRate,
+						},
+					},
+				},
+				{
+					Name:      "k8s",
+					Usage:     "Export secrets as Kubernetes Secrets",
+					ArgsUsage: "<path>",
+					Description: "" +
+						"Renders a secret as a v1 Secret manifest with the password and all keys " +
+						"of the secret as data, e.g. for 'kubectl apply -f -'. For a folder " +
+						"each child secret is one data key holding its password, or one Secret " +
+						"with --per-entry. With --apply the Secrets are created or updated with " +
+						"server-side apply by kubectl.",
+					Before:       s.IsInitialized,
+					Action:       s.ExportK8s,
+					BashComplete: s.Complete,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "name",
+							Usage: "Name of the Secret, the prefix of the names with --per-entry. Default: derived from the path",
+						},
+						&cli.StringFlag{
+							Name:  "namespace",
+							Usage: "Namespace of the Secrets",
+						},
+						&cli.StringFlag{
+							Name:  "password-key",
+							Usage: "Data key of the password",
+							Value: k8s.DefaultPasswordKey,
+						},
+						&cli.BoolFlag{
+							Name:  "per-entry",
+							Usage: "Render one Secret per child of a folder instead of one data key",
+						},
+						&cli.BoolFlag{
+							Name:  "apply",
+							Usage: "Create or update the Secrets in the cluster with kubectl instead of printing them",
+						},
+						&cli.StringFlag{
+							Name:  "kubeconfig",
+							Usage: "kubeconfig used by kubectl, default: its default kubeconfig",
+						},
+					},
+				},
+			},
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "no-warmup",
+					Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Format of the export. Only archive is supported",
+					Value: "archive",
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the archive to this file, - for stdout",
+				},
+				&cli.StringSliceFlag{
+					Name:  "recipient",
+					Usage: "Encrypt the archive for this age recipient instead of a passphrase. Can be given multiple times",
+				},
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Only export the secrets of this mount",
+				},
+				&cli.StringSliceFlag{
+					Name:  "include",
+					Usage: "Only export secrets matching this glob pattern, e.g. 'websites/*'. Can be given multiple times",
+				},
+				&cli.StringSliceFlag{
+					Name:  "exclude",
+					Usage: "Skip secrets matching this glob pattern, e.g. 'wifi/*'. Can be given multiple times",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Write the archive even if other users can access the location",
+				},
+				&cli.IntFlag{
+					Name:    "jobs",
+					Aliases: []string{"j"},
+					Usage:   "Number of secrets to decrypt concurrently",
+				},
+			},
 		},
 		{
 			Name:      "find",
 			Usage:     "Search for secrets",
 			ArgsUsage: "[needle]",
 			Description: "" +
-				"This command will first attempt a simple pattern match on the name of the " +
-				"secret.  If there is an exact match it will be shown directly; if there are " +
-				"multiple matches, a selection will be shown.",
+				"This command will fuzzy match the needle against the names of all secrets " +
+				"and rank the matches. On a terminal a single match will be shown directly; " +
+				"if there are multiple matches, a selection will be shown. Otherwise the " +
+				"ranked list of matches is printed.",
 			Before:       s.IsInitialized,
 			Action:       s.FindNoFuzzy,
 			Aliases:      []string{"search"},
@@ -304,6 +888,11 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"u", "force", "f"},
 					Usage:   "In the case of an exact match, display the password even if safecontent is enabled",
 				},
+				&cli.BoolFlag{
+					Name:    "regexp",
+					Aliases: []string{"r"},
+					Usage:   "Match the names with a RE2 regular expression instead of fuzzy matching",
+				},
 			},
 		},
 		{
@@ -312,14 +901,61 @@ func (s *Action) GetCommands() []*cli.Command {
 			ArgsUsage: "[filter]",
 			Description: "" +
 				"Check the integrity of the given sub-store or all stores if none are specified. " +
-				"Will automatically fix all issues found.",
+				"Reports secrets that are not encrypted for exactly their current recipients. " +
+				"Use --fix to re-encrypt them.",
 			Before:       s.IsInitialized,
 			Action:       s.Fsck,
 			BashComplete: s.MountsComplete,
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
 					Name:  "decrypt",
-					Usage: "Decrypt and reencryt during fsck.\nWARNING: This will update the secret content to the latest format. This might be incompatible with other implementations. Use with caution!",
+					Usage: "Try to decrypt every secret to detect corrupted ones",
+				},
+				&cli.BoolFlag{
+					Name:  "checksums",
+					Usage: "Verify the checksums of all attachments and add them to attachments without one. Implies --decrypt",
+				},
+				&cli.BoolFlag{
+					Name:  "verify",
+					Usage: "Verify the signatures of all commits and report unsigned or badly signed ones",
+				},
+				&cli.BoolFlag{
+					Name:  "fix",
+					Usage: "Re-encrypt secrets with missing or extra recipients and commit the result. Fix permissions, owners and leftover temp files of the store",
+				},
+				&cli.BoolFlag{
+					Name:  "rebuild-index",
+					Usage: "Rebuild the on-disk index of secret names of all stores before checking them",
+				},
+				&cli.BoolFlag{
+					Name:  "strict",
+					Usage: "Fail if any recipient key is weak, e.g. RSA 1024 or DSA",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format, text or json",
+					Value: "text",
+				},
+				&cli.BoolFlag{
+					Name:  "compact-history",
+					Usage: "Rewrite the git history to keep only the last revisions of each secret. Only reports the savings without --force",
+				},
+				&cli.IntFlag{
+					Name:  "keep",
+					Usage: "Number of revisions of each secret to keep with --compact-history",
+					Value: 10,
+				},
+				&cli.StringFlag{
+					Name:  "store",
+					Usage: "Store to compact with --compact-history",
+				},
+				&cli.BoolFlag{
+					Name:  "force",
+					Usage: "Rewrite the history with --compact-history after confirming it",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only report the savings of --compact-history",
 				},
 			},
 		},
@@ -361,7 +997,8 @@ func (s *Action) GetCommands() []*cli.Command {
 			ArgsUsage: "[secret [key [length]|length]]",
 			Description: "" +
 				"Dialog to generate a new password and write it into a new or existing secret. " +
-				"By default, the new password will replace the first line of an existing secret (or create a new one).",
+				"By default, the new password will replace the first line of an existing secret (or create a new one). " +
+				"The rest of the secret is kept and the replaced password is stored in an old-password-<date> key.",
 			Before:       s.IsInitialized,
 			Action:       s.Generate,
 			BashComplete: s.CompleteGenerate,
@@ -369,7 +1006,7 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "clip",
 					Aliases: []string{"c"},
-					Usage:   "Copy the generated password to the clipboard",
+					Usage:   "Copy the generated password to the clipboard. Use --clip=false to not copy it even if autoclip is enabled",
 				},
 				&cli.BoolFlag{
 					Name:    "print",
@@ -381,24 +1018,86 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"f"},
 					Usage:   "Force to overwrite existing password",
 				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be written and committed",
+				},
 				&cli.BoolFlag{
 					Name:    "edit",
 					Aliases: []string{"e"},
-					Usage:   "Open secret for editing after generating a password",
+					Usage:   "Open the secret with the generated password in $EDITOR before saving it",
 				},
 				&cli.BoolFlag{
+					Name:  "no-archive",
+					Usage: "Do not keep the replaced password in an old-password-<date> key of the secret",
+				},
+				&cli.GenericFlag{
 					Name:    "symbols",
 					Aliases: []string{"s"},
-					Usage:   "Use symbols in the password",
+					Usage:   "Use symbols in the password. Use --symbols=<set> to choose the symbols, e.g. --symbols='#%+'",
+					Value:   &optionalValue{},
+				},
+				&cli.BoolFlag{
+					Name:  "no-symbols",
+					Usage: "Do not use symbols in the password, even if the symbols config option is set",
+				},
+				&cli.BoolFlag{
+					Name:  "no-digits",
+					Usage: "Do not use digits in the password",
+				},
+				&cli.BoolFlag{
+					Name:  "no-uppercase",
+					Usage: "Do not use uppercase letters in the password",
+				},
+				&cli.BoolFlag{
+					Name:  "no-ambiguous",
+					Usage: "Do not use characters that are easily confused, e.g. 0 and O or 1, l and I",
 				},
 				&cli.StringFlag{
 					Name:    "generator",
 					Aliases: []string{"g"},
-					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd or external. Default: cryptic",
+					Usage:   "Choose a password generator, use one of: cryptic, memorable, xkcd, pronounceable or external. Default: cryptic or the generate.mode config option",
+				},
+				&cli.BoolFlag{
+					Name:  "memorable",
+					Usage: "Generate a passphrase of random words (xkcd style). Same as --generator xkcd",
+				},
+				&cli.IntFlag{
+					Name:  "words",
+					Usage: "Number of words of the passphrase (default: 4, or the length argument)",
+				},
+				&cli.BoolFlag{
+					Name:  "capitalize",
+					Usage: "Capitalize the first character of each word of the passphrase, or one letter of a pronounceable password",
+				},
+				&cli.BoolFlag{
+					Name:  "digit",
+					Usage: "Append a random digit to the passphrase, or insert one into a pronounceable password",
+				},
+				&cli.BoolFlag{
+					Name:  "force-weak",
+					Usage: "Save a password that violates the policy of its folder. The violated rules are recorded in the commit",
+				},
+				&cli.BoolFlag{
+					Name:  "follow",
+					Usage: "Change the target if the secret is a link",
+				},
+				&cli.BoolFlag{
+					Name:  "break-link",
+					Usage: "Replace the secret by one of its own if it is a link",
+				},
+				&cli.BoolFlag{
+					Name:  "print-entropy",
+					Usage: "Print the estimated entropy of the generated password. It's never stored",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Do not print the entropy of generated passphrases and pronounceable passwords",
 				},
 				&cli.BoolFlag{
 					Name:  "strict",
-					Usage: "Require strict character class rules",
+					Usage: "Fail if the password can not contain every character class or conflicts with the password rules of the domain",
 				},
 				&cli.StringFlag{
 					Name:    "sep",
@@ -416,8 +1115,8 @@ func (s *Action) GetCommands() []*cli.Command {
 		},
 		{
 			Name:      "git",
-			Usage:     "Run a git command inside a password store (init, remote, push, pull)",
-			ArgsUsage: "[init|remote|push|pull]",
+			Usage:     "Run a git command inside a password store (init, remote, push, pull, gc)",
+			ArgsUsage: "[init|remote|push|pull|gc]",
 			Description: "" +
 				"If the password store is a git repository, execute a git command " +
 				"specified by git-command-args." +
@@ -516,6 +1215,23 @@ func (s *Action) GetCommands() []*cli.Command {
 						},
 					},
 				},
+				{
+					Name:        "gc",
+					Usage:       "Pack the git repository",
+					Description: "Remove unreachable objects and pack the git repository of the store",
+					Before:      s.IsInitialized,
+					Action:      s.RCSGC,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+						&cli.BoolFlag{
+							Name:  "aggressive",
+							Usage: "Pack more tightly. This takes much longer",
+						},
+					},
+				},
 				{
 					Name:        "status",
 					Usage:       "RCS status",
@@ -531,30 +1247,94 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "git-credential",
+			Usage:     "Use gopass as git credential helper",
+			ArgsUsage: "[get|store|erase]",
+			Description: "" +
+				"Implements the git credential helper protocol. Enable it with " +
+				"'git config credential.helper \"!gopass git-credential\"'. " +
+				"Credentials are stored in <prefix>/<host>/<username>, the prefix " +
+				"defaults to 'git' and can be changed with the config option gitcredentialprefix. " +
+				"If a host has several secrets and git does not provide a username nothing is returned.",
+			Before: s.IsInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:        "get",
+					Usage:       "Print the username and password matching the credential description on stdin",
+					Description: "Reads a credential description from stdin and prints the username and password of the matching secret. Prints nothing if there is no unambiguous match.",
+					Action:      s.GitCredentialGet,
+				},
+				{
+					Name:        "store",
+					Usage:       "Create or update the secret matching the credential description on stdin",
+					Description: "Reads a credential description from stdin and saves the password and username in <prefix>/<host>/<username>.",
+					Action:      s.GitCredentialStore,
+				},
+				{
+					Name:        "erase",
+					Usage:       "Remove the secret matching the credential description on stdin",
+					Description: "Reads a credential description from stdin and removes the matching secret without asking.",
+					Action:      s.GitCredentialErase,
+				},
+			},
+		},
 		{
 			Name:      "grep",
 			Usage:     "Search for secrets files containing search-string when decrypted.",
 			ArgsUsage: "[needle]",
 			Description: "" +
 				"This command decrypts all secrets and performs a pattern matching on the " +
-				"content.",
+				"content. Every matching line is printed as name:key: value. Password lines " +
+				"are masked unless --unsafe is given, binary secrets are skipped.",
 			Before: s.IsInitialized,
 			Action: s.Grep,
 			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "no-warmup",
+					Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+				},
 				&cli.BoolFlag{
 					Name:    "regexp",
 					Aliases: []string{"r"},
 					Usage:   "Interpret pattern as RE2 regular expression",
 				},
+				&cli.BoolFlag{
+					Name:    "ignore-case",
+					Aliases: []string{"i"},
+					Usage:   "Ignore case when matching the pattern",
+				},
+				&cli.StringFlag{
+					Name:    "key",
+					Aliases: []string{"k"},
+					Usage:   "Only search the values of this key",
+				},
+				&cli.StringFlag{
+					Name:    "store",
+					Aliases: []string{"s"},
+					Usage:   "Only search the secrets of this mount",
+				},
+				&cli.BoolFlag{
+					Name:    "unsafe",
+					Aliases: []string{"u"},
+					Usage:   "Show matching password lines instead of masking them",
+				},
+				&cli.IntFlag{
+					Name:    "jobs",
+					Aliases: []string{"j"},
+					Usage:   "Number of secrets to decrypt concurrently",
+				},
 			},
 		},
 		{
 			Name:      "history",
 			Usage:     "Show password history",
-			ArgsUsage: "[secret]",
+			ArgsUsage: "[secret] [revision] [revision]",
 			Aliases:   []string{"hist"},
 			Description: "" +
-				"Display the change history for a secret",
+				"Display the change history for a secret. " +
+				"With --diff the secret is compared between two revisions, or a revision and the current content. " +
+				"Only the names of the changed fields are shown, unless --unsafe is given.",
 			Before:       s.IsInitialized,
 			Action:       s.History,
 			BashComplete: s.Complete,
@@ -564,6 +1344,99 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"p"},
 					Usage:   "Include passwords in output",
 				},
+				&cli.BoolFlag{
+					Name:  "diff",
+					Usage: "Compare the secret between two revisions",
+				},
+				&cli.BoolFlag{
+					Name:  "unsafe",
+					Usage: "Include the old and new values of changed fields in the --diff output",
+				},
+			},
+		},
+		{
+			Name:      "import",
+			Usage:     "Import secrets from other password managers",
+			ArgsUsage: "<file|dir>",
+			Description: "" +
+				"This command imports the entries of an export of another password manager. " +
+				"Supported formats are the directory of a pass store (pass), KeePass 2 and " +
+				"KeePassXC XML exports (keepassxml), unencrypted Bitwarden JSON exports " +
+				"(bitwarden-json), 1Password CSV exports (1password-csv) and archives written " +
+				"by 'gopass export' (archive). " +
+				"The password becomes the first line of a secret, the username, url and other " +
+				"fields become keys and the notes the body. Folders are preserved. " +
+				"The secrets of a pass store are imported as is and re-encrypted for the " +
+				"recipients of the gopass store. KeePass attachments become binary secrets.",
+			Before: s.IsInitialized,
+			Action: s.Import,
+			Subcommands: []*cli.Command{
+				{
+					Name:  "vault",
+					Usage: "Import secrets from HashiCorp Vault",
+					Description: "" +
+						"Imports the latest version of all secrets below --path, e.g. secret/team, " +
+						"of a Vault KV v2 mount. The password key becomes the password, the notes key " +
+						"the body and all other keys become keys. Nested maps are flattened to dotted keys, " +
+						"e.g. db.host.",
+					Before: s.IsInitialized,
+					Action: s.ImportVault,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "addr",
+							Usage: "Address of the Vault server, default: $VAULT_ADDR. The token is read from $VAULT_TOKEN",
+						},
+						&cli.StringFlag{
+							Name:  "path",
+							Usage: "Mount and folder to import, e.g. secret/team",
+						},
+						&cli.StringFlag{
+							Name:  "prefix",
+							Usage: "Import all secrets below this folder",
+						},
+						&cli.StringFlag{
+							Name:  "conflict",
+							Usage: "What to do with secrets that already exist, one of skip, overwrite or rename",
+							Value: "skip",
+						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Only print the secrets that would be imported",
+						},
+						&cli.BoolFlag{
+							Name:  "resume",
+							Usage: "Continue an interrupted import after the last imported secret",
+						},
+						&cli.IntFlag{
+							Name:  "rate",
+							Usage: "Maximum number of requests per second, 0 for no limit",
+							Value: vault.DefaultRate,
+						},
+					},
+				},
+			},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Format of the export, one of pass, keepassxml, bitwarden-json, 1password-csv or archive",
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Import all secrets below this folder",
+				},
+				&cli.StringFlag{
+					Name:  "conflict",
+					Usage: "What to do with secrets that already exist, one of skip, overwrite or rename",
+					Value: "skip",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print the secrets that would be imported",
+				},
+				&cli.StringFlag{
+					Name:  "identity",
+					Usage: "Decrypt an archive with the age identities in this file instead of a passphrase",
+				},
 			},
 		},
 		{
@@ -599,11 +1472,13 @@ func (s *Action) GetCommands() []*cli.Command {
 		{
 			Name:      "insert",
 			Usage:     "Insert a new secret",
-			ArgsUsage: "[secret]",
+			ArgsUsage: "[secret [key]]",
 			Description: "" +
 				"Insert a new secret. Optionally, echo the secret back to the console during entry. " +
 				"Or, optionally, the entry may be multiline. " +
-				"Prompt before overwriting existing secret unless forced.",
+				"Prompt before overwriting existing secret unless forced. " +
+				"When used interactively the changed fields of an existing secret are listed before it's overwritten. " +
+				"With --force they must be confirmed instead. The values are only shown with --unsafe.",
 			Before:       s.IsInitialized,
 			Action:       s.Insert,
 			BashComplete: s.Complete,
@@ -611,34 +1486,127 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "echo",
 					Aliases: []string{"e"},
-					Usage:   "Display secret while typing",
+					Usage:   "Display secret while typing and don't ask to repeat it. Tab toggles the display while typing",
+				},
+				&cli.BoolFlag{
+					Name:    "multiline",
+					Aliases: []string{"m"},
+					Usage:   "Insert using $EDITOR or all lines read from STDIN",
+				},
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"f"},
+					Usage:   "Overwrite any existing secret and do not prompt to confirm recipients",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be written and committed",
+				},
+				&cli.BoolFlag{
+					Name:    "append",
+					Aliases: []string{"a"},
+					Usage:   "Append data read from STDIN to existing data",
+				},
+				&cli.StringFlag{
+					Name:  "key",
+					Usage: "Set a single key, e.g. db.port. The value can be given as the next argument",
+				},
+				&cli.StringFlag{
+					Name:  "expires",
+					Usage: "Set the expires key to this date (YYYY-MM-DD), RFC 3339 timestamp or duration from now, e.g. 90d",
+				},
+				&cli.GenericFlag{
+					Name:  "generate",
+					Usage: "Generate the password and ask for the username. Use --generate=<length> to set the length, with --multiline the editor is opened instead",
+					Value: &optionalValue{},
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Do not print the password strength assessment",
 				},
 				&cli.BoolFlag{
-					Name:    "multiline",
-					Aliases: []string{"m"},
-					Usage:   "Insert using $EDITOR",
+					Name:  "unsafe",
+					Usage: "Include the old and new values in the summary of the changes",
 				},
 				&cli.BoolFlag{
-					Name:    "force",
-					Aliases: []string{"f"},
-					Usage:   "Overwrite any existing secret and do not prompt to confirm recipients",
+					Name:  "force-weak",
+					Usage: "Save a password that violates the policy of its folder. The violated rules are recorded in the commit",
+				},
+				&cli.StringFlag{
+					Name:  "batch",
+					Usage: "Insert a secret for every row of this CSV file, or TSV if it ends in .tsv. Existing secrets are skipped unless --force is given",
+				},
+				&cli.StringFlag{
+					Name:  "map",
+					Usage: "Map the keys of the secrets to the columns of the --batch file by number, counted from 1, or header name, e.g. path=1,password=3,user=Login",
+				},
+				&cli.StringFlag{
+					Name:  "path-template",
+					Usage: "Build the names of the --batch secrets from the mapped keys, e.g. 'imported/{{.url | host}}/{{.user}}'",
 				},
 				&cli.BoolFlag{
-					Name:    "append",
-					Aliases: []string{"a"},
-					Usage:   "Append data read from STDIN to existing data",
+					Name:  "header",
+					Usage: "Skip the first row of the --batch file. Implied if a column is mapped by header name",
+				},
+			},
+		},
+		{
+			Name:  "jsonapi",
+			Usage: "Native messaging host for browser extensions",
+			Description: "" +
+				"Implements the native messaging protocol of Chrome and Firefox, e.g. for gopassbridge. " +
+				"Run 'gopass jsonapi configure' once to install the manifest for your browser.",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "listen",
+					Usage: "Answer the requests of a browser extension on stdin",
+					Description: "" +
+						"This command is started by the browser. It answers query, getLogin and create requests " +
+						"and only returns secrets to the extensions allowed by the installed manifests.",
+					Action: s.JSONAPIListen,
+				},
+				{
+					Name:  "configure",
+					Usage: "Install the native messaging manifest for a browser",
+					Description: "" +
+						"Writes a wrapper script starting 'gopass jsonapi listen' and the manifest pointing the browser to it. " +
+						"Only the extensions in the manifest can retrieve secrets.",
+					Action: s.JSONAPIConfigure,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "browser",
+							Usage: "Install the manifest for this browser: brave, chrome, chromium or firefox",
+						},
+						&cli.StringFlag{
+							Name:  "path",
+							Usage: "Write the manifest to this file instead of the default location of the browser",
+						},
+						&cli.StringFlag{
+							Name:  "wrapper",
+							Usage: "Write the wrapper script to this file instead of the config dir",
+						},
+						&cli.StringSliceFlag{
+							Name:  "extension-id",
+							Usage: "Allow the extension with this ID. Can be given multiple times. Defaults to gopassbridge",
+						},
+						&cli.BoolFlag{
+							Name:  "print",
+							Usage: "Print the manifest instead of installing it",
+						},
+					},
 				},
 			},
 		},
 		{
 			Name:      "link",
-			Usage:     "Create a symlink",
-			ArgsUsage: "[from] [to]",
+			Usage:     "Create a link to a secret",
+			ArgsUsage: "[target] [alias]",
 			Description: "" +
-				"This command creates a symlink from one entry in a mounted store to another entry." +
-				"Important: Does not cross mounts!",
+				"This command creates a secret that links to another secret, also in a different mount. " +
+				"show, otp and clip on the link return its target. Changing a link asks whether to " +
+				"change its target or to break the link.",
 			Aliases:      []string{"ln", "symlink"},
-			Hidden:       true,
 			Before:       s.IsInitialized,
 			Action:       s.Link,
 			BashComplete: s.Complete,
@@ -657,17 +1625,17 @@ func (s *Action) GetCommands() []*cli.Command {
 			Flags: []cli.Flag{
 				&cli.IntFlag{
 					Name:    "limit",
-					Aliases: []string{"l"},
-					Usage:   "Display no more than this many levels of the tree",
+					Aliases: []string{"l", "depth"},
+					Usage:   "Display no more than this many levels of the tree. Folders below are summarized by their number of entries",
 				},
 				&cli.BoolFlag{
 					Name:    "flat",
 					Aliases: []string{"f"},
-					Usage:   "Print a flat list",
+					Usage:   "Print a flat, sorted list of full paths without any decoration",
 				},
 				&cli.BoolFlag{
 					Name:    "folders",
-					Aliases: []string{"d"},
+					Aliases: []string{"d", "dirs-only"},
 					Usage:   "Print a flat list of folders",
 				},
 				&cli.BoolFlag{
@@ -675,18 +1643,34 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"s"},
 					Usage:   "Strip this prefix from filtered entries",
 				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format, text, json or yaml. The tree is printed as nested objects, --flat as a list",
+					Value: "text",
+				},
+				&cli.StringFlag{
+					Name:  "prefix",
+					Usage: "Print a flat list of the secrets and aliases starting with this prefix, e.g. for shell completion",
+				},
+				&cli.BoolFlag{
+					Name:  "all",
+					Usage: "Also list files matched by the ignore rules of the stores, e.g. to debug a .gopass-ignore file",
+				},
 			},
 		},
 		{
 			Name:      "merge",
-			Usage:     "Merge multiple secrets into one",
+			Usage:     "Merge multiple secrets into one or resolve conflicting changes",
 			ArgsUsage: "[to] [from]...",
 			Description: "" +
 				"This command implements a merge workflow to help deduplicate " +
 				"secrets. It requires exactly one destination (may already exist) " +
 				"and at least one source (must exist, can be multiple). gopass will " +
 				"then merge all entries into one, drop into an editor, save the result " +
-				"and remove all merged entries.",
+				"and remove all merged entries. " +
+				"Given only one secret it merges the conflicting remote changes that " +
+				"sync kept next to it. Changes to different lines are merged " +
+				"automatically, conflicting ones have to be resolved in the editor.",
 			Before:       s.IsInitialized,
 			Action:       s.Merge,
 			BashComplete: s.Complete,
@@ -700,7 +1684,12 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
-					Usage:   "Skip editor, merge entries unattended",
+					Usage:   "Skip editor, merge entries unattended. Fails on conflicting changes",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Do not print the password strength assessment",
 				},
 			},
 		},
@@ -713,7 +1702,8 @@ func (s *Action) GetCommands() []*cli.Command {
 				"This command moves a secret from one path to another. This also works " +
 				"across different sub-stores. If the source is a directory, the source directory " +
 				"is re-created at the destination if no trailing slash is found, otherwise the " +
-				"contents are flattened (similar to rsync).",
+				"contents are flattened (similar to rsync). Secrets are re-encrypted for the " +
+				"recipients of the destination. Each moved secret is committed on its own.",
 			Before:       s.IsInitialized,
 			Action:       s.Move,
 			BashComplete: s.Complete,
@@ -721,7 +1711,11 @@ func (s *Action) GetCommands() []*cli.Command {
 				&cli.BoolFlag{
 					Name:    "force",
 					Aliases: []string{"f"},
-					Usage:   "Force to move the secret and overwrite existing one",
+					Usage:   "Force to move the secret and overwrite existing one. Moves within the same mount and recipients scope don't re-encrypt the secret",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Only print what would be written and committed",
 				},
 			},
 		},
@@ -730,9 +1724,23 @@ func (s *Action) GetCommands() []*cli.Command {
 			Usage: "Edit mounted stores",
 			Description: "" +
 				"This command displays all mounted password stores. It offers several " +
-				"subcommands to create or remove mounts.",
+				"subcommands to create or remove mounts. With --verbose the backends, the " +
+				"remote and its sync status, the number of entries and recipients of each " +
+				"mount are shown, e.g. to debug team setups.",
 			Before: s.IsInitialized,
 			Action: s.MountsPrint,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format, text, json or yaml",
+					Value: "text",
+				},
+				&cli.BoolFlag{
+					Name:    "verbose",
+					Aliases: []string{"v"},
+					Usage:   "Show the backend versions, the remote, the number of entries and recipients of each mount. The remotes are contacted",
+				},
+			},
 			Subcommands: []*cli.Command{
 				{
 					Name:    "add",
@@ -743,6 +1751,12 @@ func (s *Action) GetCommands() []*cli.Command {
 						"at any path in an existing root store.",
 					Before: s.IsInitialized,
 					Action: s.MountAdd,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "readonly",
+							Usage: "Mount the store read-only. Secrets can be read but not changed and sync never pushes it",
+						},
+					},
 				},
 				{
 					Name:    "remove",
@@ -755,6 +1769,17 @@ func (s *Action) GetCommands() []*cli.Command {
 					Action:       s.MountRemove,
 					BashComplete: s.MountsComplete,
 				},
+				{
+					Name:      "set",
+					Usage:     "Change the options of a mounted password store",
+					ArgsUsage: "<alias> <option>=<value>...",
+					Description: "" +
+						"This command changes per mount options, e.g. 'gopass mounts set team readonly=false'. " +
+						"An empty value removes the option.",
+					Before:       s.IsInitialized,
+					Action:       s.MountSet,
+					BashComplete: s.MountsComplete,
+				},
 			},
 		},
 		{
@@ -774,10 +1799,14 @@ func (s *Action) GetCommands() []*cli.Command {
 					Aliases: []string{"c"},
 					Usage:   "Copy the time-based token into the clipboard",
 				},
-				&cli.StringFlag{
+				&cli.BoolFlag{
 					Name:    "qr",
 					Aliases: []string{"q"},
-					Usage:   "Write QR code to FILE",
+					Usage:   "Print the OTP URL as a QR code, e.g. to enroll a phone",
+				},
+				&cli.StringFlag{
+					Name:  "qr-file",
+					Usage: "Write QR code to FILE",
 				},
 				&cli.BoolFlag{
 					Name:    "password",
@@ -785,16 +1814,171 @@ func (s *Action) GetCommands() []*cli.Command {
 					Usage:   "Only display the token",
 				},
 			},
+			Subcommands: []*cli.Command{
+				{
+					Name:      "export",
+					Usage:     "Export OTP keys to the backup of a phone authenticator",
+					ArgsUsage: "[folder]",
+					Description: "" +
+						"Writes the OTP keys of all secrets below the folder to an unencrypted " +
+						"Aegis (aegis) or andOTP (andotp) backup. Only secrets with an otpauth:// URL " +
+						"or a totp key are exported.",
+					Before: s.IsInitialized,
+					Action: s.OTPExport,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Format of the backup, one of aegis or andotp",
+						},
+						&cli.StringFlag{
+							Name:    "output",
+							Aliases: []string{"o"},
+							Usage:   "Write the backup to this file, only readable by the current user",
+						},
+					},
+				},
+				{
+					Name:      "import",
+					Usage:     "Import the OTP keys of a phone authenticator backup",
+					ArgsUsage: "<file>",
+					Description: "" +
+						"Imports a plain or encrypted Aegis backup (aegis) or a plain andOTP backup (andotp). " +
+						"Every key becomes a secret with its otpauth:// URL in the totp key. Keys gopass " +
+						"can't generate codes for, e.g. Steam keys, are imported with a warning.",
+					Before: s.IsInitialized,
+					Action: s.OTPImport,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Format of the backup, one of aegis or andotp",
+						},
+						&cli.StringFlag{
+							Name:  "prefix",
+							Usage: "Import all keys below this folder",
+							Value: "otp",
+						},
+						&cli.StringFlag{
+							Name:  "layout",
+							Usage: "Name of the secret below the prefix, {issuer} and {account} are replaced",
+							Value: "{issuer}/{account}",
+						},
+						&cli.StringFlag{
+							Name:  "conflict",
+							Usage: "What to do with secrets that already exist, one of skip, overwrite or rename",
+							Value: "skip",
+						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Only print the secrets that would be imported",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "pick",
+			Usage: "Pick a secret with rofi, wofi or dmenu and copy or type it",
+			Description: "" +
+				"This command lists all secrets in a dmenu style picker and copies the password of the " +
+				"selected one to the clipboard or types it into the focused window. The picker is " +
+				"configured with picker.cmd, otherwise rofi, wofi or dmenu is used. It exits silently " +
+				"with status 1 if the picker is cancelled and never prints the secret.",
+			Before: s.IsInitialized,
+			Action: s.Pick,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "clip",
+					Aliases: []string{"c"},
+					Usage:   "Copy the password to the clipboard. This is the default unless autotype is enabled",
+				},
+				&cli.BoolFlag{
+					Name:    "type",
+					Aliases: []string{"t"},
+					Usage:   "Type the password (or the autotype sequence of the secret) into the focused window",
+				},
+				&cli.StringFlag{
+					Name:    "key",
+					Aliases: []string{"k"},
+					Usage:   "Use the value of this key instead of the password, e.g. user",
+				},
+				&cli.BoolFlag{
+					Name:    "otp",
+					Aliases: []string{"o"},
+					Usage:   "Use the current OTP code of the secret instead of the password",
+				},
+			},
+		},
+		{
+			Name:      "process",
+			Usage:     "Render a config file template with secrets inlined",
+			ArgsUsage: "[template-file]",
+			Description: "" +
+				"This command renders a Go text/template read from the given file or stdin and " +
+				"writes the result to stdout, e.g. for docker-compose env files or systemd credentials. " +
+				"{{ gopass \"path\" }} is replaced by the password of a secret, {{ gopassKey \"path\" \"key\" }} " +
+				"by the value of a key and {{ gopassJSON \"path\" }} by the whole secret as a JSON object. " +
+				"A missing secret or key fails the rendering unless -missingkey=zero is given.",
+			Before: s.IsInitialized,
+			Action: s.Process,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the result to this file, only readable by the current user",
+				},
+				&cli.StringFlag{
+					Name:  "missingkey",
+					Usage: "What to do with missing secrets and keys. Either error or zero, i.e. replace them with an empty string",
+					Value: tpl.MissingError,
+				},
+			},
 		},
 		{
 			Name:  "recipients",
 			Usage: "Edit recipient permissions",
 			Description: "" +
 				"This command displays all existing recipients for all mounted stores. " +
+				"With --path it shows who can decrypt a single secret or folder. " +
 				"The subcommands allow adding or removing recipients.",
 			Before: s.IsInitialized,
 			Action: s.RecipientsPrint,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "verbose",
+					Usage: "Show the ownertrust and validity of each key",
+				},
+				&cli.StringFlag{
+					Name:  "format",
+					Usage: "Output format, text, json or yaml. Structured output includes the details of each key",
+					Value: "text",
+				},
+				&cli.StringFlag{
+					Name:  "path",
+					Usage: "Only show the recipients this secret or folder is encrypted for and the file they are read from",
+				},
+				&cli.BoolFlag{
+					Name:  "tree",
+					Usage: "Show the folders with their own recipients as a tree, keys of the parent folder are marked as inherited",
+				},
+			},
 			Subcommands: []*cli.Command{
+				{
+					Name:  "ack",
+					Usage: "Accept the current recipients of all stores",
+					Description: "" +
+						"gopass remembers the recipients of each store. If they are changed outside " +
+						"of gopass, e.g. by a pull, the changes are shown and secrets are only " +
+						"encrypted for the new recipients once they are confirmed. This command " +
+						"shows the changes and accepts the current recipients.",
+					Before: s.IsInitialized,
+					Action: s.RecipientsAck,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "store",
+							Usage: "Store to operate on",
+						},
+					},
+				},
 				{
 					Name:    "add",
 					Aliases: []string{"authorize"},
@@ -804,18 +1988,31 @@ func (s *Action) GetCommands() []*cli.Command {
 						"If none are given it will display a list of usable public keys. " +
 						"After adding the recipient to the list it will re-encrypt the whole " +
 						"affected store to make sure the recipient has access to all existing " +
-						"secrets.",
+						"secrets. With --path the recipient is added to the recipients of this " +
+						"directory only, creating a new scope if it has none of its own yet.",
 					Before: s.IsInitialized,
 					Action: s.RecipientsAdd,
 					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "no-warmup",
+							Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+						},
 						&cli.StringFlag{
 							Name:  "store",
 							Usage: "Store to operate on",
 						},
+						&cli.StringFlag{
+							Name:  "path",
+							Usage: "Directory with its own recipients to operate on, e.g. work/prod",
+						},
 						&cli.BoolFlag{
 							Name:  "force",
 							Usage: "Force adding non-existing keys",
 						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Only print what would be written and committed, including the secrets to re-encrypt",
+						},
 					},
 				},
 				{
@@ -830,32 +2027,147 @@ func (s *Action) GetCommands() []*cli.Command {
 						"all existing secrets. Please note that the removed recipients will still " +
 						"be able to decrypt old revisions of the password store and any local " +
 						"copies they might have. The only way to reliably remove a recipient is to " +
-						"rotate all existing secrets.",
+						"rotate all existing secrets, e.g. with --rotate right away or with --affected to get a " +
+						"list of them for gopass rotate. With --path the recipient is removed from the " +
+						"recipients of this directory. Removing the last one removes the scope, " +
+						"the secrets will use the recipients of the parent directory then.",
 					Before:       s.IsInitialized,
 					Action:       s.RecipientsRemove,
 					BashComplete: s.RecipientsComplete,
 					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "no-warmup",
+							Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+						},
 						&cli.StringFlag{
 							Name:  "store",
 							Usage: "Store to operate on",
 						},
+						&cli.StringFlag{
+							Name:  "path",
+							Usage: "Directory with its own recipients to operate on, e.g. work/prod",
+						},
 						&cli.BoolFlag{
 							Name:  "force",
 							Usage: "Force adding non-existing keys",
 						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Only print what would be written and committed, including the secrets to re-encrypt",
+						},
+						&cli.BoolFlag{
+							Name:  "rotate",
+							Usage: "Generate new passwords for all secrets the removed recipients could decrypt, like gopass rotate",
+						},
+						&cli.StringFlag{
+							Name:  "affected",
+							Usage: "Write the secrets the removed recipients could decrypt to this file, one per line, e.g. to rotate them later",
+						},
 					},
 				},
 			},
 		},
+		{
+			Name:    "repl",
+			Aliases: []string{"shell"},
+			Usage:   "Start the built-in shell",
+			Description: "" +
+				"Starts an interactive shell running gopass commands with tab completion of secret names. " +
+				"The stores are initialized only once. 'cd' changes the current folder, names are resolved " +
+				"relative to it. Passwords are only shown with -u, as if safecontent was enabled. Ctrl+C cancels the " +
+				"running command, Ctrl+D or 'quit' leaves the shell. The same shell is started by running gopass without any arguments.",
+			Before: s.IsInitialized,
+			Action: s.REPL,
+		},
+		{
+			Name:      "rotate",
+			Usage:     "Generate new passwords for all secrets below a folder",
+			ArgsUsage: "<prefix or pattern>...",
+			Description: "" +
+				"Generates a new password for every secret below the given folders or matching the given " +
+				"glob patterns, e.g. infra/... or 'infra/*/root'. The password rules of the domains are applied. " +
+				"The rest of each secret is kept and the replaced password is stored in an old-password-<date> key, " +
+				"like gopass generate does. Every secret is committed on its own, referencing the id of the rotation batch. " +
+				"At the end the secrets with an url are listed, the password has to be changed on these services.",
+			Before:       s.IsInitialized,
+			Action:       s.Rotate,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "no-warmup",
+					Usage: "Don't decrypt one secret before starting, e.g. if the agent has the passphrase cached anyway",
+				},
+				&cli.BoolFlag{
+					Name:    "dry-run",
+					Aliases: []string{"n"},
+					Usage:   "Only list the secrets that would be rotated and print what would be written and committed",
+				},
+				&cli.BoolFlag{
+					Name:    "interactive",
+					Aliases: []string{"i"},
+					Usage:   "Ask before rotating each secret",
+				},
+			},
+		},
+		{
+			Name:  "serve",
+			Usage: "Serve a read-only REST API for other tools",
+			Description: "" +
+				"Serves GET /v1/secrets?prefix=<folder> and GET /v1/secret/<name> in the foreground until it is interrupted. " +
+				"Every request must send the token as bearer token. It's generated and printed at startup unless --token-file is given. " +
+				"Every access is logged with the name of the secret only. There are no endpoints to change secrets.",
+			Before: s.IsInitialized,
+			Action: s.Serve,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "listen",
+					Usage: "TCP address to listen on. It must be a loopback address unless --insecure-remote is given",
+					Value: serve.DefaultListen,
+				},
+				&cli.StringFlag{
+					Name:  "socket",
+					Usage: "Listen on this unix socket instead",
+				},
+				&cli.StringFlag{
+					Name:  "token-file",
+					Usage: "Read the token from this file instead of generating one",
+				},
+				&cli.StringFlag{
+					Name:  "tls-cert",
+					Usage: "Serve TLS with this certificate",
+				},
+				&cli.StringFlag{
+					Name:  "tls-key",
+					Usage: "Key of the TLS certificate",
+				},
+				&cli.StringSliceFlag{
+					Name:  "allow",
+					Usage: "Only serve the secrets below this folder, may be given multiple times",
+				},
+				&cli.BoolFlag{
+					Name:  "insecure-remote",
+					Usage: "Allow listening on addresses other than loopback",
+				},
+			},
+		},
 		{
 			Name:  "setup",
 			Usage: "Initialize a new password store",
 			Description: "" +
 				"This command is automatically invoked if gopass is started without any " +
 				"existing password store. This command exists so users can be provided with " +
-				"simple one-command setup instructions.",
+				"simple one-command setup instructions. With --adopt it registers an " +
+				"existing pass store, e.g. ~/.password-store, without changing it.",
 			Action: s.Setup,
 			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "adopt",
+					Usage: "Use the existing pass store at this path in place, only the gopass config is written",
+				},
+				&cli.StringFlag{
+					Name:  "mount",
+					Usage: "Add the adopted store as a mount with this name instead of as the root store",
+				},
 				&cli.StringFlag{
 					Name:  "remote",
 					Usage: "URL to a git remote, will attempt to join this team",
@@ -876,6 +2188,10 @@ func (s *Action) GetCommands() []*cli.Command {
 					Name:  "email",
 					Usage: "EMail for unattended GPG key generation",
 				},
+				&cli.BoolFlag{
+					Name:  "rsa",
+					Usage: "Generate a RSA 4096 key pair instead of ed25519/cv25519 (GPG only)",
+				},
 				&cli.StringFlag{
 					Name:  "crypto",
 					Usage: fmt.Sprintf("Select crypto backend %s", strings.Join(backend.CryptoBackends(), ", ")),
@@ -886,17 +2202,53 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "share",
+			Usage:     "Share a secret with someone who is not a recipient",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"Encrypt a secret once for a GPG key or an age public key that is not a " +
+				"recipient of the store, e.g. to hand it to a contractor. The secret in " +
+				"the store is not changed, the share is recorded in its history. The " +
+				"shared secret embeds when it expires, 'gopass show --from-file' refuses " +
+				"to display it afterwards.",
+			Before:       s.IsInitialized,
+			Action:       s.Share,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "recipient",
+					Usage: "GPG key ID, fingerprint or email, or age public key to share the secret with",
+				},
+				&cli.DurationFlag{
+					Name:  "expire",
+					Usage: "Refuse to show the shared secret after this long",
+					Value: 24 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "Write the shared secret to this file instead of stdout",
+				},
+			},
+		},
 		{
 			Name:      "show",
 			Usage:     "Display the content of a secret",
 			ArgsUsage: "[secret]",
 			Description: "" +
 				"Show an existing secret and optionally put its first line on the clipboard. " +
-				"If put on the clipboard, it will be cleared after 45 seconds.",
-			Before:       s.IsInitialized,
+				"If put on the clipboard, it will be cleared after 45 seconds. " +
+				"With --recursive all secrets below a folder are shown, optionally as " +
+				"a single JSON object with --format json. With --from-file a secret " +
+				"shared with 'gopass share' is shown instead.",
+			Before:       s.showIsInitialized,
 			Action:       s.Show,
 			BashComplete: s.Complete,
-			Flags:        ShowFlags(),
+			Flags: append(ShowFlags(), &cli.StringFlag{
+				Name:  "from-file",
+				Usage: "Show a secret shared with 'gopass share' from this file instead of the store, unless it has expired",
+			}),
 		},
 		{
 			Name:      "sum",
@@ -911,19 +2263,33 @@ func (s *Action) GetCommands() []*cli.Command {
 			Action:       s.Sum,
 			BashComplete: s.Complete,
 		},
+		{
+			Name:      "summon",
+			Usage:     "Print a secret as a summon provider",
+			ArgsUsage: "<secret>[#key]",
+			Description: "" +
+				"Implements the summon provider interface. Prints the password, or the value of the " +
+				"given key, without a trailing newline. Nothing else is written to stdout and it never asks " +
+				"any questions, e.g. for the passphrase of a gpg key not cached by the agent. Exits with 1 on any error. " +
+				"Use it with 'summon --provider gopass-summon', where gopass-summon is a script running 'exec gopass summon \"$@\"'.",
+			Action:       s.Summon,
+			BashComplete: s.Complete,
+		},
 		{
 			Name:  "sync",
 			Usage: "Sync all local stores with their remotes",
 			Description: "" +
 				"Sync all local stores with their git remotes, if any, and check " +
-				"any possibly affected gpg keys.",
+				"any possibly affected gpg keys. Mounts with nosync set are skipped, " +
+				"unless selected with --store. Prints a summary of the changes to " +
+				"each store and fails if any of them failed to sync.",
 			Before: s.IsInitialized,
 			Action: s.Sync,
 			Flags: []cli.Flag{
-				&cli.StringFlag{
+				&cli.StringSliceFlag{
 					Name:    "store",
 					Aliases: []string{"s"},
-					Usage:   "Select the store to sync",
+					Usage:   "Select the store to sync. Can be given multiple times. Use root for the root store",
 				},
 			},
 		},
@@ -932,7 +2298,8 @@ func (s *Action) GetCommands() []*cli.Command {
 			Usage: "Edit templates",
 			Description: "" +
 				"List existing templates in the password store and allow for editing " +
-				"and creating them.",
+				"and creating them. Templates of a folder apply to all new secrets below " +
+				"it. Named templates are encrypted and used with 'gopass new --template'.",
 			Before: s.IsInitialized,
 			Action: s.TemplatesPrint,
 			Subcommands: []*cli.Command{
@@ -944,6 +2311,13 @@ func (s *Action) GetCommands() []*cli.Command {
 					Before:       s.IsInitialized,
 					Action:       s.TemplatePrint,
 					BashComplete: s.TemplatesComplete,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "named",
+							Aliases: []string{"n"},
+							Usage:   "Use the named template, for 'gopass new --template', instead of the template of a folder",
+						},
+					},
 				},
 				{
 					Name:         "edit",
@@ -953,6 +2327,13 @@ func (s *Action) GetCommands() []*cli.Command {
 					Before:       s.IsInitialized,
 					Action:       s.TemplateEdit,
 					BashComplete: s.TemplatesComplete,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "named",
+							Aliases: []string{"n"},
+							Usage:   "Use the named template, for 'gopass new --template', instead of the template of a folder",
+						},
+					},
 				},
 				{
 					Name:         "remove",
@@ -962,19 +2343,28 @@ func (s *Action) GetCommands() []*cli.Command {
 					Before:       s.IsInitialized,
 					Action:       s.TemplateRemove,
 					BashComplete: s.TemplatesComplete,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "named",
+							Aliases: []string{"n"},
+							Usage:   "Use the named template, for 'gopass new --template', instead of the template of a folder",
+						},
+					},
 				},
 			},
 		},
 		{
-			Name:        "unclip",
-			Usage:       "Internal command to clear clipboard",
-			Description: "Clear the clipboard if the content matches the checksum.",
-			Action:      s.Unclip,
-			Hidden:      true,
+			Name:  "unclip",
+			Usage: "Clear the clipboard",
+			Description: "" +
+				"This command clears the clipboard. gopass runs it in the background " +
+				"after copying a secret, in that case it only clears the clipboard if it " +
+				"still contains the secret.",
+			Action: s.Unclip,
 			Flags: []cli.Flag{
 				&cli.IntFlag{
 					Name:  "timeout",
-					Usage: "Time to wait",
+					Usage: "Seconds to wait before clearing the clipboard",
 				},
 				&cli.BoolFlag{
 					Name:  "force",
@@ -982,13 +2372,44 @@ func (s *Action) GetCommands() []*cli.Command {
 				},
 			},
 		},
+		{
+			Name:      "undelete",
+			Usage:     "Restore a removed secret",
+			ArgsUsage: "[secret]",
+			Description: "" +
+				"This command restores a removed secret from the git history. By default " +
+				"the last revision containing the secret is restored. Use --revision to " +
+				"select an older one, e.g. if it was removed multiple times.",
+			Before:       s.IsInitialized,
+			Action:       s.Undelete,
+			BashComplete: s.Complete,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "revision",
+					Usage: "Restore the given revision. Use exact revision or -N to select the Nth oldest revision of this entry.",
+				},
+				&cli.BoolFlag{
+					Name:    "force",
+					Aliases: []string{"f"},
+					Usage:   "Overwrite an existing secret",
+				},
+			},
+		},
 		{
 			Name:  "update",
 			Usage: "Check for updates",
 			Description: "" +
 				"This command checks for gopass updates at GitHub and automatically " +
-				"downloads and installs any missing update.",
+				"downloads and installs any missing update. The download is verified with " +
+				"the signature of the release before the running binary is replaced. " +
+				"Binaries managed by a package manager or owned by root are not updated.",
 			Action: s.Update,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "check-only",
+					Usage: "Only check if an update is available, don't install it",
+				},
+			},
 		},
 		{
 			Name:  "version",
@@ -3,11 +3,13 @@ package action
 import (
 	"fmt"
 	"regexp"
-	"runtime"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/debug"
 
+	bashcomp "github.com/gopasspw/gopass/internal/completion/bash"
 	fishcomp "github.com/gopasspw/gopass/internal/completion/fish"
 	zshcomp "github.com/gopasspw/gopass/internal/completion/zsh"
 	"github.com/gopasspw/gopass/internal/out"
@@ -43,6 +45,9 @@ func (s *Action) Complete(c *cli.Context) {
 	for _, v := range list {
 		fmt.Fprintln(stdout, bashEscape(v))
 	}
+	for _, name := range s.cfg.AliasNames() {
+		fmt.Fprintln(stdout, bashEscape(name+"/"))
+	}
 }
 
 // CompletionOpenBSDKsh returns an OpenBSD ksh script used for auto completion
@@ -69,24 +74,64 @@ set -A complete_gopass -- $PASS_LIST %s
 }
 
 // CompletionBash returns a bash script used for auto completion
-func (s *Action) CompletionBash(c *cli.Context) error {
-	out := `_gopass_bash_autocomplete() {
-     local cur opts base
-     COMPREPLY=()
-     cur="${COMP_WORDS[COMP_CWORD]}"
-     opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
-     local IFS=$'\n'
-     COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
-     return 0
- }
+func (s *Action) CompletionBash(a *cli.App) error {
+	if a == nil {
+		return fmt.Errorf("app is nil")
+	}
+	comp, err := bashcomp.GetCompletion(a)
+	if err != nil {
+		return err
+	}
 
-`
-	out += "complete -F _gopass_bash_autocomplete " + s.Name
-	if runtime.GOOS == "windows" {
-		out += "\ncomplete -F _gopass_bash_autocomplete " + s.Name + ".exe"
+	fmt.Fprintln(stdout, comp)
+	return nil
+}
+
+// CompletionMounts prints the mount points for the completion of --store
+func (s *Action) CompletionMounts(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	if inited, err := s.Store.IsInitialized(ctx); err != nil || !inited {
+		return nil
 	}
-	fmt.Fprintln(stdout, out)
+	for _, mp := range s.Store.MountPoints() {
+		fmt.Fprintln(stdout, mp)
+	}
+	return nil
+}
 
+// CompletionKeys prints the keys of the given secret for the completion of
+// --key. It only decrypts the secret if completion.decrypt is enabled and
+// never asks for a passphrase, the keys are only completed if the agent
+// already holds it.
+func (s *Action) CompletionKeys(c *cli.Context) error {
+	name := c.Args().First()
+	if !s.cfg.CompletionDecrypt || name == "" {
+		return nil
+	}
+
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = gpg.WithNoPinentry(ctx, true)
+	ctx = ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+		return nil, fmt.Errorf("no passphrase prompts during completion")
+	})
+	if inited, err := s.Store.IsInitialized(ctx); err != nil || !inited {
+		return nil
+	}
+
+	name = s.Store.ExpandAlias(name)
+	if !s.Store.Exists(ctx, name) || s.Store.IsDir(ctx, name) {
+		return nil
+	}
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		debug.Log("failed to decrypt %s for completion: %s", name, err)
+		return nil
+	}
+	for _, k := range sec.Keys() {
+		fmt.Fprintln(stdout, k)
+	}
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
@@ -58,8 +59,54 @@ func TestComplete(t *testing.T) {
 	t.Run("bash completion", func(t *testing.T) {
 		defer buf.Reset()
 
-		assert.NoError(t, act.CompletionBash(nil))
-		assert.Contains(t, buf.String(), "action.test")
+		assert.NoError(t, act.CompletionBash(app))
+		assert.Contains(t, buf.String(), "complete -F _action_test_complete action.test")
+		assert.Contains(t, buf.String(), "ls --flat --prefix")
+		assert.Error(t, act.CompletionBash(nil))
+	})
+
+	t.Run("complete mounts", func(t *testing.T) {
+		defer buf.Reset()
+
+		require.NoError(t, u.InitStore("team"))
+		require.NoError(t, act.Store.AddMount(ctx, "team", u.StoreDir("team")))
+		assert.NoError(t, act.CompletionMounts(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "team\n", buf.String())
+	})
+
+	t.Run("complete keys", func(t *testing.T) {
+		defer buf.Reset()
+
+		sec := secrets.NewKV()
+		sec.SetPassword("secret")
+		sec.Set("user", "jdoe")
+		require.NoError(t, act.Store.Set(ctx, "web/example", sec))
+
+		// nothing is decrypted by default
+		assert.NoError(t, act.CompletionKeys(gptest.CliCtx(ctx, t, "web/example")))
+		assert.Equal(t, "", buf.String())
+
+		act.cfg.CompletionDecrypt = true
+		defer func() {
+			act.cfg.CompletionDecrypt = false
+		}()
+		assert.NoError(t, act.CompletionKeys(gptest.CliCtx(ctx, t, "web/example")))
+		assert.Equal(t, "user\n", buf.String())
+
+		buf.Reset()
+		assert.NoError(t, act.CompletionKeys(gptest.CliCtx(ctx, t, "web")))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("list prefix", func(t *testing.T) {
+		defer buf.Reset()
+
+		assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"prefix": "we"})))
+		assert.Equal(t, "web/example\n", buf.String())
+
+		buf.Reset()
+		assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"prefix": "te"})))
+		assert.Equal(t, "team/foo\n", buf.String())
 	})
 
 	t.Run("fish completion", func(t *testing.T) {
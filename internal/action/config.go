@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
@@ -14,13 +15,33 @@ import (
 // Config handles changes to the gopass configuration
 func (s *Action) Config(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.IsSet("store") && c.Bool("system") {
+		return ExitError(ExitUsage, nil, "--store and --system can not be combined")
+	}
+	if c.Bool("list") {
+		return s.listConfig(ctx, c.String("store"), c.Bool("show-origin"))
+	}
+	if c.Bool("unset") {
+		return s.unsetConfig(ctx, c)
+	}
+	if c.Bool("system") {
+		return s.systemConfig(ctx, c)
+	}
+	if c.IsSet("store") {
+		return s.mountConfig(ctx, c, c.String("store"))
+	}
+
 	if c.Args().Len() < 1 {
 		s.printConfigValues(ctx)
 		return nil
 	}
 
 	if c.Args().Len() == 1 {
-		s.printConfigValues(ctx, c.Args().Get(0))
+		key := c.Args().Get(0)
+		if !s.cfg.IsOption(key) {
+			return ExitError(ExitConfig, nil, "Unknown config option %q", key)
+		}
+		s.printConfigValues(ctx, key)
 		return nil
 	}
 
@@ -29,13 +50,16 @@ func (s *Action) Config(c *cli.Context) error {
 	}
 
 	if err := s.setConfigValue(ctx, c.Args().Get(0), c.Args().Get(1)); err != nil {
-		return ExitError(ExitUnknown, err, "Error setting config value")
+		return ExitError(ExitConfig, err, "Error setting config value")
 	}
 	return nil
 }
 
 func (s *Action) printConfigValues(ctx context.Context, needles ...string) {
 	m := s.cfg.ConfigMap()
+	for i, n := range needles {
+		needles[i] = config.OptionName(n)
+	}
 	for _, k := range filterMap(m, needles) {
 		out.Printf(ctx, "%s: %s", k, m[k])
 	}
@@ -44,6 +68,47 @@ func (s *Action) printConfigValues(ctx context.Context, needles ...string) {
 			out.Printf(ctx, "mount %q => %q", alias, path)
 		}
 	}
+	for alias, home := range s.cfg.GnupgHome {
+		if len(needles) < 1 {
+			out.Printf(ctx, "gnupghome %q => %q", alias, home)
+		}
+	}
+}
+
+// mountConfig handles changes to the per mount configuration
+func (s *Action) mountConfig(ctx context.Context, c *cli.Context, mount string) error {
+	if _, found := s.cfg.Mounts[mount]; !found {
+		return ExitError(ExitMount, nil, "No such mount point %q", mount)
+	}
+
+	switch c.Args().Len() {
+	case 0:
+		s.printMountConfigValues(ctx, mount)
+	case 1:
+		key := c.Args().Get(0)
+		if _, found := s.cfg.MountConfigMap(mount)[config.OptionName(key)]; !found {
+			return ExitError(ExitConfig, nil, "Unknown per mount config option %q", key)
+		}
+		s.printMountConfigValues(ctx, mount, key)
+	case 2:
+		if err := s.cfg.SetMountConfigValue(mount, c.Args().Get(0), c.Args().Get(1)); err != nil {
+			return ExitError(ExitConfig, err, "Error setting config value: %s", err)
+		}
+		s.printMountConfigValues(ctx, mount, c.Args().Get(0))
+	default:
+		return ExitError(ExitUsage, nil, "Usage: %s config --store mount key value", s.Name)
+	}
+	return nil
+}
+
+func (s *Action) printMountConfigValues(ctx context.Context, mount string, needles ...string) {
+	m := s.cfg.MountConfigMap(mount)
+	for i, n := range needles {
+		needles[i] = config.OptionName(n)
+	}
+	for _, k := range filterMap(m, needles) {
+		out.Printf(ctx, "%s: %s", k, m[k])
+	}
 }
 
 func filterMap(haystack map[string]string, needles []string) []string {
@@ -74,10 +139,117 @@ func (s *Action) setConfigValue(ctx context.Context, key, value string) error {
 	if err := s.cfg.SetConfigValue(key, value); err != nil {
 		return fmt.Errorf("failed to set config value %q: %w", key, err)
 	}
+	s.warnEnvOverride(ctx, key)
 	s.printConfigValues(ctx, key)
 	return nil
 }
 
+func (s *Action) warnEnvOverride(ctx context.Context, key string) {
+	if o := s.cfg.Origin("", key); o.Layer == config.LayerEnv {
+		out.Warningf(ctx, "%s is overridden by %s", key, o.Source)
+	}
+}
+
+// listConfig prints all effective values, git-config style. With a mount only
+// the options that can be set per mount are printed.
+func (s *Action) listConfig(ctx context.Context, mount string, showOrigin bool) error {
+	keys := s.cfg.Keys()
+	values := func(key string) string {
+		return s.cfg.Get(key)
+	}
+	if mount != "" {
+		if _, found := s.cfg.Mounts[mount]; !found {
+			return ExitError(ExitMount, nil, "No such mount point %q", mount)
+		}
+		m := s.cfg.MountConfigMap(mount)
+		keys = make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, config.OptionKey(k))
+		}
+		sort.Strings(keys)
+		values = func(key string) string {
+			name := config.OptionName(key)
+			if s.cfg.Origin(mount, name).Layer == config.LayerMount || !s.cfg.IsOption(name) {
+				return m[name]
+			}
+			return s.cfg.Get(name)
+		}
+	}
+
+	for _, k := range keys {
+		if showOrigin {
+			out.Printf(ctx, "%s\t%s=%s", s.cfg.Origin(mount, k), k, values(k))
+			continue
+		}
+		out.Printf(ctx, "%s=%s", k, values(k))
+	}
+	return nil
+}
+
+// unsetConfig removes an option from the user config, the system config or a
+// mount
+func (s *Action) unsetConfig(ctx context.Context, c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s config --unset [--store mount | --system] key", s.Name)
+	}
+	key := c.Args().Get(0)
+
+	switch {
+	case c.IsSet("store"):
+		mount := c.String("store")
+		if _, found := s.cfg.Mounts[mount]; !found {
+			return ExitError(ExitMount, nil, "No such mount point %q", mount)
+		}
+		if err := s.cfg.SetMountConfigValue(mount, key, ""); err != nil {
+			return ExitError(ExitConfig, err, "Error removing config value: %s", err)
+		}
+		s.printMountConfigValues(ctx, mount, key)
+		return nil
+	case c.Bool("system"):
+		if err := s.cfg.UnsetSystemConfigValue(key); err != nil {
+			return ExitError(ExitConfig, err, "Error removing system config value: %s", err)
+		}
+	default:
+		if err := s.cfg.UnsetConfigValue(key); err != nil {
+			return ExitError(ExitConfig, err, "Error removing config value: %s", err)
+		}
+		s.warnEnvOverride(ctx, key)
+	}
+	s.printConfigValues(ctx, key)
+	return nil
+}
+
+// systemConfig handles changes to the system wide configuration
+func (s *Action) systemConfig(ctx context.Context, c *cli.Context) error {
+	switch c.Args().Len() {
+	case 0:
+		for _, k := range s.cfg.Keys() {
+			if v, found := s.cfg.SystemConfigValue(k); found {
+				out.Printf(ctx, "%s: %s", config.OptionName(k), v)
+			}
+		}
+	case 1:
+		if !s.cfg.IsOption(c.Args().Get(0)) {
+			return ExitError(ExitConfig, nil, "Unknown config option %q", c.Args().Get(0))
+		}
+		if v, found := s.cfg.SystemConfigValue(c.Args().Get(0)); found {
+			out.Printf(ctx, "%s: %s", config.OptionName(c.Args().Get(0)), v)
+		}
+	case 2:
+		key := c.Args().Get(0)
+		if err := s.cfg.SetSystemConfigValue(key, c.Args().Get(1)); err != nil {
+			return ExitError(ExitConfig, err, "Error setting system config value: %s", err)
+		}
+		if o := s.cfg.Origin("", key); o.Layer > config.LayerSystem {
+			out.Warningf(ctx, "%s is overridden by %s", key, o)
+		}
+		out.Printf(ctx, "%s: %s", config.OptionName(key), c.Args().Get(1))
+	default:
+		return ExitError(ExitUsage, nil, "Usage: %s config --system key value", s.Name)
+	}
+	return nil
+}
+
 func (s *Action) configKeys() []string {
 	cm := s.cfg.ConfigMap()
 	keys := make([]string, 0, len(cm)+1)
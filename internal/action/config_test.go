@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -38,16 +39,58 @@ func TestConfig(t *testing.T) {
 
 		c := gptest.CliCtx(ctx, t)
 		assert.NoError(t, act.Config(c))
-		want := `autoclip: true
+		want := `ageagent: false
+autoclip: true
 autoimport: true
+autooffline: false
+autopush: true
+autosync: true
+autosyncinterval: 0
+autotype: false
+binarylimit: 1048576
+checkrecipienthash: true
+clipboard: 
 cliptimeout: 45
+cmd: 
+commitmsghashnames: false
+commitmsgtemplate: 
+decrypt: false
+decryptcache: 100
+exectimeout: 60
+expirywarn: 30
 exportkeys: true
+formatpasswords: false
+gitcredentialprefix: 
+hooks: false
+keepbackup: false
+keycache: true
+keyserver: 
+lockafter: 900
+locktimeout: 10
+metadata: false
+mode: 
+noambiguous: false
+nodigits: false
 nopager: false
 notifications: true
+nouppercase: false
+oldpasswords: 3
+ownertrust: false
 parsing: true
 `
 		want += "path: " + u.StoreDir("") + "\n"
-		want += `safecontent: false
+		want += `pullstrategy: merge
+qrtimeout: 45
+recordingcheck: true
+recordingvars: 
+safecontent: false
+showautoclip: false
+signcommits: false
+symbols: 
+unsafekeys: 
+updatestartuptty: true
+wordlistfile: 
+workers: 0
 `
 		assert.Equal(t, want, buf.String())
 	})
@@ -74,20 +117,74 @@ parsing: true
 		assert.Equal(t, want, strings.TrimSpace(buf.String()), "action.printConfigValues")
 	})
 
+	t.Run("print unknown config value", func(t *testing.T) {
+		defer buf.Reset()
+
+		for _, key := range []string{"foo", "core.foo", "git.nopager"} {
+			err := act.Config(gptest.CliCtx(ctx, t, key))
+			require.Error(t, err, key)
+			assert.Contains(t, err.Error(), "Unknown config option", key)
+		}
+		assert.Error(t, act.Config(gptest.CliCtxWithFlags(ctx, t, map[string]string{"system": "true"}, "foo")))
+		assert.Empty(t, buf.String())
+	})
+
 	t.Run("print all config values", func(t *testing.T) {
 		defer buf.Reset()
 
 		act.printConfigValues(ctx)
-		want := `autoclip: true
+		want := `ageagent: false
+autoclip: true
 autoimport: true
+autooffline: false
+autopush: true
+autosync: true
+autosyncinterval: 0
+autotype: false
+binarylimit: 1048576
+checkrecipienthash: true
+clipboard: 
 cliptimeout: 45
+cmd: 
+commitmsghashnames: false
+commitmsgtemplate: 
+decrypt: false
+decryptcache: 100
+exectimeout: 60
+expirywarn: 30
 exportkeys: true
+formatpasswords: false
+gitcredentialprefix: 
+hooks: false
+keepbackup: false
+keycache: true
+keyserver: 
+lockafter: 900
+locktimeout: 10
+metadata: false
+mode: 
+noambiguous: false
+nodigits: false
 nopager: true
 notifications: true
+nouppercase: false
+oldpasswords: 3
+ownertrust: false
 parsing: true
 `
 		want += "path: " + u.StoreDir("") + "\n"
-		want += `safecontent: false`
+		want += `pullstrategy: merge
+qrtimeout: 45
+recordingcheck: true
+recordingvars: 
+safecontent: false
+showautoclip: false
+signcommits: false
+symbols: 
+unsafekeys: 
+updatestartuptty: true
+wordlistfile: 
+workers: 0`
 		assert.Equal(t, want, strings.TrimSpace(buf.String()), "action.printConfigValues")
 
 		delete(act.cfg.Mounts, "foo")
@@ -113,16 +210,58 @@ parsing: true
 		defer buf.Reset()
 
 		act.ConfigComplete(gptest.CliCtx(ctx, t))
-		want := `autoclip
+		want := `ageagent
+autoclip
 autoimport
+autooffline
+autopush
+autosync
+autosyncinterval
+autotype
+binarylimit
+checkrecipienthash
+clipboard
 cliptimeout
+cmd
+commitmsghashnames
+commitmsgtemplate
+decrypt
+decryptcache
+exectimeout
+expirywarn
 exportkeys
+formatpasswords
+gitcredentialprefix
+hooks
+keepbackup
+keycache
+keyserver
+lockafter
+locktimeout
+metadata
+mode
+noambiguous
+nodigits
 nopager
 notifications
+nouppercase
+oldpasswords
+ownertrust
 parsing
 path
+pullstrategy
+qrtimeout
+recordingcheck
+recordingvars
 remote
 safecontent
+showautoclip
+signcommits
+symbols
+unsafekeys
+updatestartuptty
+wordlistfile
+workers
 `
 		assert.Equal(t, want, buf.String())
 	})
@@ -133,4 +272,100 @@ safecontent
 		c := gptest.CliCtx(ctx, t, "autoimport", "false", "42")
 		assert.Error(t, act.Config(c))
 	})
+
+	t.Run("set per mount gnupghome", func(t *testing.T) {
+		defer buf.Reset()
+
+		act.cfg.Mounts["work"] = u.StoreDir("work")
+		defer delete(act.cfg.Mounts, "work")
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "gnupghome", "/tmp/Work-GnuPG")
+		assert.NoError(t, act.Config(c))
+		assert.Equal(t, "gnupghome: /tmp/Work-GnuPG", strings.TrimSpace(buf.String()))
+		assert.Equal(t, "/tmp/Work-GnuPG", act.cfg.GnupgHome["work"])
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "autoclip", "true")
+		assert.Error(t, act.Config(c))
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "autoclip")
+		assert.Error(t, act.Config(c))
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "personal"}, "gnupghome")
+		assert.Error(t, act.Config(c))
+	})
+}
+
+func TestConfigLayers(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	t.Run("list", func(t *testing.T) {
+		defer buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"list": "true"})
+		assert.NoError(t, act.Config(c))
+		assert.Contains(t, buf.String(), "git.autopush=true\n")
+		assert.Contains(t, buf.String(), "show.cliptimeout=45\n")
+	})
+
+	t.Run("set sectioned key", func(t *testing.T) {
+		defer buf.Reset()
+
+		c := gptest.CliCtx(ctx, t, "show.cliptimeout", "20")
+		assert.NoError(t, act.Config(c))
+		assert.Equal(t, "cliptimeout: 20", strings.TrimSpace(buf.String()))
+		assert.Equal(t, 20, act.cfg.ClipTimeout)
+	})
+
+	t.Run("system", func(t *testing.T) {
+		defer buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"system": "true"}, "core.nopager", "true")
+		assert.NoError(t, act.Config(c))
+		assert.True(t, act.cfg.NoPager)
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"system": "true"})
+		assert.NoError(t, act.Config(c))
+		assert.Equal(t, "nopager: true", strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"list": "true", "show-origin": "true"})
+		assert.NoError(t, act.Config(c))
+		assert.Contains(t, buf.String(), "file:"+filepath.Join(u.Dir, "system-config.yml")+"\tcore.nopager=true\n")
+		assert.Contains(t, buf.String(), "default\tgit.autopush=true\n")
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"system": "true", "store": "foo"}, "nopager", "true")
+		assert.Error(t, act.Config(c))
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		defer buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"unset": "true"}, "cliptimeout")
+		assert.NoError(t, act.Config(c))
+		assert.Equal(t, "cliptimeout: 45", strings.TrimSpace(buf.String()))
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"unset": "true", "system": "true"}, "nopager")
+		assert.NoError(t, act.Config(c))
+		assert.False(t, act.cfg.NoPager)
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"unset": "true"})
+		assert.Error(t, act.Config(c))
+	})
 }
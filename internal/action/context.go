@@ -12,6 +12,16 @@ const (
 	ctxKeyKey
 	ctxKeyOnlyClip
 	ctxKeyAlsoClip
+	ctxKeyAutotype
+	ctxKeyQuiet
+	ctxKeyRegexp
+	ctxKeyNoNewline
+	ctxKeyChars
+	ctxKeyShowMeta
+	ctxKeyExpires
+	ctxKeyUnsafe
+	ctxKeyForceWeak
+	ctxKeyFollowLink
 )
 
 // WithClip returns a context with the value for clip (for copy to clipboard)
@@ -73,6 +83,21 @@ func IsPasswordOnly(ctx context.Context) bool {
 	return bv
 }
 
+// WithAutotype returns a context with the value of autotype (type the
+// secret into the focused window) set
+func WithAutotype(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyAutotype, bv)
+}
+
+// IsAutotype returns the value of autotype or the default (false)
+func IsAutotype(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyAutotype).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
 // WithPrintQR returns a context with the value of print QR set
 func WithPrintQR(ctx context.Context, qr bool) context.Context {
 	return context.WithValue(ctx, ctxKeyPrintQR, qr)
@@ -126,3 +151,151 @@ func GetKey(ctx context.Context) string {
 	}
 	return sv
 }
+
+// WithQuiet returns a context with the value for quiet set. Quiet suppresses
+// the password strength feedback.
+func WithQuiet(ctx context.Context, quiet bool) context.Context {
+	return context.WithValue(ctx, ctxKeyQuiet, quiet)
+}
+
+// IsQuiet returns the value of quiet or the default (false)
+func IsQuiet(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyQuiet).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithRegexp returns a context with the value for regexp set. Regexp makes
+// find match the names with a regular expression instead of fuzzy matching.
+func WithRegexp(ctx context.Context, re bool) context.Context {
+	return context.WithValue(ctx, ctxKeyRegexp, re)
+}
+
+// IsRegexp returns the value of regexp or the default (false)
+func IsRegexp(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyRegexp).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithNoNewline returns a context with the value for no newline set. It
+// omits the final newline of the output, even on a terminal.
+func WithNoNewline(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoNewline, bv)
+}
+
+// IsNoNewline returns the value of no newline or the default (false)
+func IsNoNewline(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyNoNewline).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithChars returns a context with the 1-indexed positions of the password
+// characters to show set
+func WithChars(ctx context.Context, pos []int) context.Context {
+	return context.WithValue(ctx, ctxKeyChars, pos)
+}
+
+// HasChars returns true if only some characters of the password should be
+// shown
+func HasChars(ctx context.Context) bool {
+	pos, ok := ctx.Value(ctxKeyChars).([]int)
+	return ok && len(pos) > 0
+}
+
+// GetChars returns the positions of the password characters to show
+func GetChars(ctx context.Context) []int {
+	pos, ok := ctx.Value(ctxKeyChars).([]int)
+	if !ok {
+		return nil
+	}
+	return pos
+}
+
+// WithShowMeta returns a context with the value for showing the metadata of
+// a secret set
+func WithShowMeta(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyShowMeta, bv)
+}
+
+// IsShowMeta returns the value of show meta or the default (false)
+func IsShowMeta(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyShowMeta).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithExpires returns a context with the expiry date set that insert writes
+// to the expires key of the secret
+func WithExpires(ctx context.Context, date string) context.Context {
+	return context.WithValue(ctx, ctxKeyExpires, date)
+}
+
+// GetExpires returns the expiry date to set or an empty string
+func GetExpires(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyExpires).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithUnsafe returns a context with the flag set that shows the values of
+// changed fields when a secret is overwritten
+func WithUnsafe(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyUnsafe, bv)
+}
+
+// IsUnsafe returns the value of unsafe or the default (false)
+func IsUnsafe(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyUnsafe).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithForceWeak returns a context with the flag set that saves passwords
+// violating the policy of their folder
+func WithForceWeak(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyForceWeak, bv)
+}
+
+// IsForceWeak returns the value of force weak or the default (false)
+func IsForceWeak(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyForceWeak).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithFollowLink returns a context with the answer to whether a link or its
+// target should be changed
+func WithFollowLink(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyFollowLink, bv)
+}
+
+// HasFollowLink returns true if the answer to follow links was given
+func HasFollowLink(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyFollowLink).(bool)
+	return ok
+}
+
+// IsFollowLink returns the value of follow link or the default (false)
+func IsFollowLink(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyFollowLink).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
@@ -31,6 +31,13 @@ func TestWithPasswordOnly(t *testing.T) {
 	}
 }
 
+func TestWithAutotype(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsAutotype(ctx))
+	assert.True(t, IsAutotype(WithAutotype(ctx, true)))
+}
+
 func TestWithPrintQR(t *testing.T) {
 	ctx := context.Background()
 
@@ -38,6 +45,16 @@ func TestWithPrintQR(t *testing.T) {
 	assert.True(t, IsPrintQR(WithPrintQR(ctx, true)))
 }
 
+func TestWithChars(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasChars(ctx))
+	assert.Nil(t, GetChars(ctx))
+	assert.False(t, HasChars(WithChars(ctx, nil)))
+	assert.True(t, HasChars(WithChars(ctx, []int{3, 7})))
+	assert.Equal(t, []int{3, 7}, GetChars(WithChars(ctx, []int{3, 7})))
+}
+
 func TestWithRevision(t *testing.T) {
 	ctx := context.Background()
 
@@ -67,3 +84,31 @@ func TestWithAlsoClip(t *testing.T) {
 	assert.False(t, IsAlsoClip(ctx))
 	assert.True(t, IsAlsoClip(WithAlsoClip(ctx, true)))
 }
+
+func TestWithQuiet(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsQuiet(ctx))
+	assert.True(t, IsQuiet(WithQuiet(ctx, true)))
+}
+
+func TestWithRegexp(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsRegexp(ctx))
+	assert.True(t, IsRegexp(WithRegexp(ctx, true)))
+}
+
+func TestWithNoNewline(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsNoNewline(ctx))
+	assert.True(t, IsNoNewline(WithNoNewline(ctx, true)))
+}
+
+func TestWithUnsafe(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsUnsafe(ctx))
+	assert.True(t, IsUnsafe(WithUnsafe(ctx, true)))
+}
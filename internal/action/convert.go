@@ -2,18 +2,46 @@ package action
 
 import (
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/urfave/cli/v2"
 )
 
-// Convert converts a store to a different set of backends
+// Convert converts a store in place to a different set of backends
 func (s *Action) Convert(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 
 	store := c.String("store")
-	move := c.Bool("move")
-	storage := backend.StorageBackendFromName(c.String("storage"))
-	crypto := backend.CryptoBackendFromName(c.String("crypto"))
+	if _, found := s.Store.Mounts()[store]; store != "" && !found {
+		return ExitError(ExitMount, nil, "store %q is not mounted. See '%s mounts'", store, s.Name)
+	}
 
-	return s.Store.Convert(ctx, store, crypto, storage, move)
+	// default to the current backends of the store
+	current := backend.CryptoBackendFromName(s.Store.Crypto(ctx, store).Name())
+	crypto := current
+	if name := c.String("crypto"); name != "" {
+		crypto = backend.CryptoBackendFromName(name)
+		if crypto < 0 {
+			return ExitError(ExitUsage, nil, "unknown crypto backend %q", name)
+		}
+	}
+	currentStorage := backend.StorageBackendFromName(s.Store.Storage(ctx, store).Name())
+	storage := currentStorage
+	if name := c.String("storage"); name != "" {
+		storage = backend.StorageBackendFromName(name)
+		if backend.StorageNameFromBackend(storage) != name {
+			return ExitError(ExitUsage, nil, "unknown storage backend %q", name)
+		}
+	}
+
+	if crypto == current && storage == currentStorage && !c.Bool("fresh-history") {
+		return ExitError(ExitUsage, nil, "store %q already uses %s and %s. Nothing to convert", store, crypto, storage)
+	}
+
+	if err := s.Store.Convert(ctx, store, crypto, storage, c.Bool("fresh-history")); err != nil {
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to convert store %q: %s", store, err)
+	}
+
+	out.OKf(ctx, "Converted store %q to %s and %s", store, crypto, storage)
+	return nil
 }
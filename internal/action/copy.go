@@ -30,13 +30,19 @@ func (s *Action) copy(ctx context.Context, from, to string, force bool) error {
 		return ExitError(ExitNotFound, nil, "%s does not exist", from)
 	}
 
-	if !force {
-		if s.Store.Exists(ctx, to) && !termio.AskForConfirmation(ctx, fmt.Sprintf("%s already exists. Overwrite it?", to)) {
-			return ExitError(ExitAborted, nil, "not overwriting your current secret")
+	if !force && !ctxutil.IsDryRun(ctx) {
+		if s.Store.Exists(ctx, to) {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("%s already exists. Overwrite it?", to), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not overwriting your current secret: %s", err)
+			}
+			if !ok {
+				return ExitError(ExitAborted, nil, "not overwriting your current secret")
+			}
 		}
 	}
 
-	if err := s.Store.Copy(ctx, from, to); err != nil {
+	if err := s.Store.Copy(ctxutil.WithForce(ctx, force), from, to); err != nil {
 		return ExitError(ExitIO, err, "failed to copy from %q to %q", from, to)
 	}
 
@@ -10,10 +10,12 @@ import (
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/internal/cui"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tpl"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
@@ -34,6 +36,30 @@ func fmtfn(d int, n string, t string) string {
 func (s *Action) Create(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 
+	if tmpl := c.String("template"); tmpl != "" {
+		return s.createFromTemplate(ctx, c, tmpl)
+	}
+
+	// the wizard needs someone to answer its questions
+	if ctxutil.IsStdin(ctx) {
+		return ExitError(ExitUsage, nil, "%s create is an interactive wizard and needs a terminal. Use '%s insert' or '%s generate' in scripts instead", s.Name, s.Name, s.Name)
+	}
+	if store := c.String("store"); store != "" {
+		if _, found := s.Store.Mounts()[store]; !found {
+			return ExitError(ExitMount, nil, "store %q is not mounted. See '%s mounts'", store, s.Name)
+		}
+	}
+	if !ctxutil.IsTerminal(ctx) {
+		// stdout is captured, e.g. by NAME=$(gopass create). The wizard talks
+		// to the user on stderr and only prints the path of the new secret.
+		ctx = ctxutil.WithInteractive(ctx, true)
+		so := out.Stdout
+		out.Stdout = out.Stderr
+		defer func() {
+			out.Stdout = so
+		}()
+	}
+
 	out.Printf(ctx, "🌟 Welcome to the secret creation wizard (gopass create)!")
 	out.Printf(ctx, "🧪 Hint: Use 'gopass edit -c' for more control!")
 
@@ -53,6 +79,51 @@ func (s *Action) Create(c *cli.Context) error {
 	}
 }
 
+// createFromTemplate renders a new secret from a named template. Nothing is
+// written if the template can not be rendered.
+func (s *Action) createFromTemplate(ctx context.Context, c *cli.Context, tmpl string) error {
+	name := c.Args().First()
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s new --template <template> <secret>", s.Name)
+	}
+	if err := s.Store.CheckWritable(name); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+	if !c.Bool("force") && s.Store.Exists(ctx, name) {
+		ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("%s already exists. Overwrite it?", name), "--force")
+		if err != nil {
+			return ExitError(ExitAborted, err, "not overwriting your current secret: %s", err)
+		}
+		if !ok {
+			return ExitError(ExitAborted, nil, "not overwriting your current secret")
+		}
+	}
+	if err := s.confirmCollision(ctx, name, c.Bool("force")); err != nil {
+		return err
+	}
+
+	tName, content, err := s.Store.LookupNamedTemplate(ctx, tmpl, name)
+	if err != nil {
+		return ExitError(ExitNotFound, err, "%s", err)
+	}
+
+	nc, err := tpl.Execute(ctx, string(content), name, []byte(pwgen.GeneratePassword(defaultLength, false)), s.Store)
+	if err != nil {
+		return ExitError(ExitUnknown, err, "failed to execute template %q: %s", tName, err)
+	}
+
+	sec := &secrets.Plain{}
+	if _, err := sec.Write(nc); err != nil {
+		return ExitError(exitCodeFor(err, ExitEncrypt), err, "failed to create secret: %s", err)
+	}
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Created from template %s", tName)), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Created %s from template %s", name, tName)
+	return nil
+}
+
 // extractHostname tries to extract the hostname from a URL in a filepath-safe
 // way for use in the name of a secret
 func extractHostname(in string) string {
@@ -134,7 +205,10 @@ func (s *Action) createWebsite(ctx context.Context, c *cli.Context) error {
 	// input. Only when the force flag is given it will accept a secrets path
 	// as the first argument.
 	if name == "" || !force {
-		name = fmt.Sprintf("%swebsites/%s/%s", store, fsutil.CleanFilename(hostname), fsutil.CleanFilename(username))
+		name = fmt.Sprintf("%swebsites/%s", store, fsutil.CleanFilename(hostname))
+		if username != "" {
+			name += "/" + fsutil.CleanFilename(username)
+		}
 	}
 	if force && !strings.HasPrefix(name, store) {
 		out.Warningf(ctx, "User supplied secret name %q does not match requested mount %q. Ignoring store flag.", name, store)
@@ -147,24 +221,45 @@ func (s *Action) createWebsite(ctx context.Context, c *cli.Context) error {
 			return err
 		}
 	}
+	if err := s.confirmCollision(ctx, name, force); err != nil {
+		return err
+	}
 
 	// populate a new secret with the gathered information
 	sec := secrets.New()
 	sec.SetPassword(password)
 	sec.Set("url", urlStr)
-	sec.Set("username", username)
-	sec.Set("comment", comment)
+	setNonEmpty(sec, "username", username)
+	setNonEmpty(sec, "comment", comment)
 	if u := pwrules.LookupChangeURL(hostname); u != "" {
 		sec.Set("password-change-url", u)
 	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Created new entry"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
 	}
-	out.OKf(ctx, "Credentials saved to %q", name)
+	s.createPrintName(ctx, name)
 
 	return s.createPrintOrCopy(ctx, c, name, password, genPw)
 }
 
+// createPrintName prints the path of the new secret. If stdout is not a
+// terminal only the path is printed, so scripts can capture it.
+func (s *Action) createPrintName(ctx context.Context, name string) {
+	if !ctxutil.IsTerminal(ctx) {
+		fmt.Fprintln(stdout, name)
+		return
+	}
+	out.OKf(ctx, "Credentials saved to %q", name)
+}
+
+// setNonEmpty sets the key unless the user skipped it
+func setNonEmpty(sec gopass.Secret, key, value string) {
+	if value == "" {
+		return
+	}
+	sec.Set(key, value)
+}
+
 // createPrintOrCopy will display the created password (or copy to clipboard)
 func (s *Action) createPrintOrCopy(ctx context.Context, c *cli.Context, name, password string, genPw bool) error {
 	if !genPw {
@@ -194,7 +289,7 @@ func (s *Action) createPIN(ctx context.Context, c *cli.Context) error {
 		return err
 	}
 	if authority == "" {
-		return ExitError(ExitUnknown, nil, "Authority must not be empty")
+		return ExitError(ExitNoName, nil, "Authority must not be empty")
 	}
 
 	application, err := termio.AskForString(ctx, fmtfn(2, "2", "Entity"), "")
@@ -202,7 +297,7 @@ func (s *Action) createPIN(ctx context.Context, c *cli.Context) error {
 		return err
 	}
 	if application == "" {
-		return ExitError(ExitUnknown, nil, "Application must not be empty")
+		return ExitError(ExitNoName, nil, "Application must not be empty")
 	}
 
 	genPw, err := termio.AskForBool(ctx, fmtfn(2, "3", "Generate PIN?"), false)
@@ -256,15 +351,18 @@ func (s *Action) createPIN(ctx context.Context, c *cli.Context) error {
 			return err
 		}
 	}
+	if err := s.confirmCollision(ctx, name, force); err != nil {
+		return err
+	}
 
 	sec := secrets.New()
 	sec.SetPassword(password)
 	sec.Set("application", application)
-	sec.Set("comment", comment)
+	setNonEmpty(sec, "comment", comment)
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Created new entry"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
 	}
-	out.OKf(ctx, "Credentials saved to %q", name)
+	s.createPrintName(ctx, name)
 
 	return s.createPrintOrCopy(ctx, c, name, password, genPw)
 }
@@ -281,7 +379,7 @@ func (s *Action) createGeneric(ctx context.Context, c *cli.Context) error {
 		return err
 	}
 	if shortname == "" {
-		return ExitError(ExitUnknown, nil, "Name must not be empty")
+		return ExitError(ExitNoName, nil, "Name must not be empty")
 	}
 
 	genPw, err := termio.AskForBool(ctx, fmtfn(2, "2", "Generate password?"), true)
@@ -329,6 +427,9 @@ func (s *Action) createGeneric(ctx context.Context, c *cli.Context) error {
 			return err
 		}
 	}
+	if err := s.confirmCollision(ctx, name, force); err != nil {
+		return err
+	}
 
 	sec := secrets.New()
 	sec.SetPassword(password)
@@ -350,20 +451,20 @@ func (s *Action) createGeneric(ctx context.Context, c *cli.Context) error {
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Created new entry"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
 	}
-	out.OKf(ctx, "Credentials saved to %q", name)
+	s.createPrintName(ctx, name)
 
 	return s.createPrintOrCopy(ctx, c, name, password, genPw)
 }
 
 // createGeneratePasssword will walk through the password generation steps
 func (s *Action) createGeneratePassword(ctx context.Context, hostname string) (string, error) {
-	if _, found := pwrules.LookupRule(hostname); found {
+	if _, rule, found := s.Store.PasswordRule(ctx, hostname); found {
 		out.Noticef(ctx, "Using password rules for %s ...", hostname)
 		length, err := termio.AskForInt(ctx, fmtfn(4, "b", "How long?"), defaultLength)
 		if err != nil {
 			return "", err
 		}
-		return pwgen.NewCrypticForDomain(length, hostname).Password(), nil
+		return pwgen.NewCrypticForRule(length, rule).Password(), nil
 	}
 	xkcd, err := termio.AskForBool(ctx, fmtfn(4, "a", "Human-pronounceable passphrase?"), false)
 	if err != nil {
@@ -225,3 +225,126 @@ y
 	assert.NoError(t, act.createGeneric(ctx, c))
 	buf.Reset()
 }
+
+func TestCreateFromTemplate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.Store.SetNamedTemplate(ctx, "db", []byte("{{ genpw 24 }}\nuser: {{ .Name }}\nhost: {{ .Dir }}.example.org\n")))
+	require.NoError(t, act.Store.SetNamedTemplate(ctx, "prompt", []byte("{{ genpw 24 }}\nuser: {{ prompt \"user\" }}\n")))
+
+	t.Run("create from template", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"template": "db"}, "db/billing")
+		require.NoError(t, act.Create(c))
+		assert.Contains(t, buf.String(), "Created db/billing from template db")
+
+		sec, err := act.Store.Get(ctx, "db/billing")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 24)
+		v, _ := sec.Get("user")
+		assert.Equal(t, "billing", v)
+		v, _ = sec.Get("host")
+		assert.Equal(t, "db.example.org", v)
+	})
+
+	t.Run("render errors don't write anything", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"template": "prompt"}, "db/orders")
+		assert.Error(t, act.Create(c))
+		assert.False(t, act.Store.Exists(ctx, "db/orders"))
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"template": "ldap"}, "db/users")
+		assert.Error(t, act.Create(c))
+		assert.False(t, act.Store.Exists(ctx, "db/users"))
+	})
+
+	t.Run("missing secret name", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"template": "db"})
+		assert.Error(t, act.Create(c))
+	})
+}
+
+func TestCreateWizardChecks(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, true)
+	ctx = ctxutil.WithNotifications(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	t.Run("no terminal", func(t *testing.T) {
+		c := gptest.CliCtx(ctxutil.WithStdin(ctx, true), t)
+		err := act.Create(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is an interactive wizard and needs a terminal")
+	})
+
+	t.Run("unknown store", func(t *testing.T) {
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"})
+		err := act.Create(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `store "work" is not mounted`)
+	})
+}
+
+func TestCreateWebsiteSkipFields(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	aclip.Unsupported = true
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, true)
+	ctx = ctxutil.WithNotifications(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	stdout = buf
+	termio.Stderr = &bytes.Buffer{}
+	defer func() {
+		stdout = os.Stdout
+		termio.Stderr = os.Stderr
+	}()
+
+	// skip the login and the comment
+	termio.Stdin = strings.NewReader("https://shop.example.org/\n\ny\ny\n5\n\n")
+	defer func() {
+		termio.Stdin = os.Stdin
+	}()
+
+	// only the path is printed if stdout is captured
+	ctx = ctxutil.WithTerminal(ctx, false)
+	c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"print": "true"})
+	require.NoError(t, act.createWebsite(ctx, c))
+	assert.Equal(t, "websites/shop.example.org\n", buf.String())
+
+	sec, err := act.Store.Get(ctx, "websites/shop.example.org")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"url"}, sec.Keys())
+}
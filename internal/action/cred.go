@@ -0,0 +1,123 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/cred"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/urfave/cli/v2"
+)
+
+// CredPrint prints all systemd credentials and their secrets
+func (s *Action) CredPrint(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	names := s.cfg.CredentialNames()
+	if len(names) < 1 {
+		out.Printf(ctx, "No credentials")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Fprintf(stdout, "%s -> %s\n", name, s.cfg.Credentials[name])
+	}
+	return nil
+}
+
+// CredAdd maps a systemd credential to a secret
+func (s *Action) CredAdd(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	secret := c.Args().Get(1)
+	if name == "" || secret == "" || c.Args().Len() > 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s cred add <[unit/]id> <secret>", s.Name)
+	}
+
+	if err := s.cfg.AddCredential(name, secret); err != nil {
+		return ExitError(ExitConfig, err, "failed to add credential %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Added credential %s -> %s", name, s.cfg.Credentials[name])
+	if !s.Store.Exists(ctx, s.cfg.Credentials[name]) {
+		out.Warningf(ctx, "The secret %s does not exist (yet)", s.cfg.Credentials[name])
+	}
+	return nil
+}
+
+// CredRemove removes a systemd credential
+func (s *Action) CredRemove(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" || c.Args().Len() > 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s cred remove <[unit/]id>", s.Name)
+	}
+
+	if err := s.cfg.RemoveCredential(name); err != nil {
+		return ExitError(ExitConfig, err, "failed to remove credential %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Removed credential %s", name)
+	return nil
+}
+
+// CredServe answers the LoadCredential= requests of systemd in the
+// foreground until it is interrupted
+func (s *Action) CredServe(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	socket := c.String("socket")
+	uids := c.IntSlice("allow-uid")
+
+	if len(s.cfg.Credentials) < 1 {
+		out.Noticef(ctx, "No credentials are configured yet, add them with '%s cred add'", s.Name)
+	}
+
+	out.Printf(ctx, "Serving credentials on %s. Press Ctrl+C to stop.", socket)
+	if err := cred.NewServer(socket, uids, s.resolveCred).Run(ctx); err != nil {
+		return ExitError(ExitUnsupported, err, "failed to serve credentials: %s", err)
+	}
+	return nil
+}
+
+// resolveCred returns the password of the secret mapped to the credential
+func (s *Action) resolveCred(ctx context.Context, unit, id string) ([]byte, error) {
+	name, found := s.cfg.CredentialSecret(unit, id)
+	if !found {
+		return nil, cred.ErrUnknown
+	}
+	debug.Log("credential %q of %s is %s", id, unit, name)
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(sec.Password()), nil
+}
+
+// CredWrite writes the password of a secret to a file only readable by the
+// current user, e.g. for ExecStartPre=
+func (s *Action) CredWrite(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	dst := c.Args().Get(1)
+	if name == "" || dst == "" || c.Args().Len() > 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s cred write <secret> <file>", s.Name)
+	}
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ExitError(ExitNotFound, err, "failed to read %s: %s", name, err)
+		}
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to read %s: %s", name, err)
+	}
+
+	if err := cred.WriteFile(dst, []byte(sec.Password()), c.Bool("force")); err != nil {
+		return ExitError(ExitIO, err, "failed to write %s: %s", dst, err)
+	}
+	out.OKf(ctx, "Wrote %s to %s", name, dst)
+	return nil
+}
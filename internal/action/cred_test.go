@@ -0,0 +1,108 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/cred"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCred(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("user", "admin"))
+	require.NoError(t, act.Store.Set(ctx, "infra/db", sec))
+
+	t.Run("no credentials", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.CredPrint(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "No credentials\n", buf.String())
+	})
+
+	t.Run("add credentials", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.CredAdd(gptest.CliCtx(ctx, t, "db-password")))
+		require.NoError(t, act.CredAdd(gptest.CliCtx(ctx, t, "db-password", "infra/db")))
+		require.NoError(t, act.CredAdd(gptest.CliCtx(ctx, t, "web.service/db-password", "infra/web")))
+		assert.Contains(t, buf.String(), "The secret infra/web does not exist (yet)")
+
+		err := act.CredAdd(gptest.CliCtx(ctx, t, "a:b", "infra/db"))
+		require.Error(t, err)
+		var ec cli.ExitCoder
+		require.True(t, errors.As(err, &ec))
+		assert.Equal(t, ExitConfig, ec.ExitCode())
+	})
+
+	t.Run("print credentials", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.CredPrint(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "db-password -> infra/db\nweb.service/db-password -> infra/web\n", buf.String())
+	})
+
+	t.Run("resolve credentials", func(t *testing.T) {
+		defer buf.Reset()
+		content, err := act.resolveCred(ctx, "app.service", "db-password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", string(content))
+
+		_, err = act.resolveCred(ctx, "web.service", "db-password")
+		assert.Error(t, err)
+		_, err = act.resolveCred(ctx, "app.service", "api-token")
+		assert.True(t, errors.Is(err, cred.ErrUnknown))
+	})
+
+	t.Run("write credential", func(t *testing.T) {
+		defer buf.Reset()
+		dst := filepath.Join(t.TempDir(), "db-password")
+		assert.Error(t, act.CredWrite(gptest.CliCtx(ctx, t, "infra/db")))
+		require.NoError(t, act.CredWrite(gptest.CliCtx(ctx, t, "infra/db", dst)))
+		content, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", string(content))
+
+		err = act.CredWrite(gptest.CliCtx(ctx, t, "infra/web", dst))
+		require.Error(t, err)
+		var ec cli.ExitCoder
+		require.True(t, errors.As(err, &ec))
+		assert.Equal(t, ExitNotFound, ec.ExitCode())
+	})
+
+	t.Run("remove credential", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.CredRemove(gptest.CliCtx(ctx, t, "web.service/db-password")))
+		assert.Error(t, act.CredRemove(gptest.CliCtx(ctx, t, "web.service/db-password")))
+		assert.Equal(t, []string{"db-password"}, act.cfg.CredentialNames())
+	})
+}
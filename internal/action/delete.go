@@ -3,7 +3,9 @@ package action
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/termio"
@@ -14,14 +16,22 @@ import (
 // Delete a secret file with its content
 func (s *Action) Delete(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
-	force := c.Bool("force")
+	// nothing is removed in dry-run mode, so there is nothing to confirm
+	force := c.Bool("force") || ctxutil.IsDryRun(ctx)
 	recursive := c.Bool("recursive")
+	leafOnly := c.Bool("leaf-only")
 
 	name := c.Args().First()
 	if name == "" {
 		return ExitError(ExitUsage, nil, "Usage: %s rm name", s.Name)
 	}
+	if err := s.Store.CheckWritable(name); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
 
+	if recursive && leafOnly {
+		return ExitError(ExitUsage, nil, "Can not use -r with --leaf-only. Invoke delete either with -r to remove the folder or with --leaf-only to remove the secret")
+	}
 	if !recursive && s.Store.IsDir(ctx, name) && !s.Store.Exists(ctx, name) {
 		return ExitError(ExitUsage, nil, "Cannot remove %q: Is a directory. Use 'gopass rm -r %s' to delete", name, name)
 	}
@@ -29,24 +39,59 @@ func (s *Action) Delete(c *cli.Context) error {
 	// specifying a key is optional
 	key := c.Args().Get(1)
 
+	// removing only the secret or also the folder of the same name must be
+	// asked for explicitly. A key can only be removed from the secret.
+	if !recursive && !leafOnly && key == "" && s.Store.IsDir(ctx, name) && s.Store.Exists(ctx, name) {
+		return ExitError(ExitUsage, nil, "%s is a secret and a folder. Use 'gopass rm --leaf-only %s' to delete the secret or 'gopass rm -r %s' to delete both", name, name, name)
+	}
+
 	if recursive && key != "" {
 		return ExitError(ExitUsage, nil, "Can not use -r with a key. Invoke delete either with a key or with -r")
 	}
 
-	if !force { // don't check if it's force anyway
-		recStr := ""
-		if recursive {
-			recStr = "recursively "
+	// links to the secret point to nothing after it's removed
+	if !recursive && key == "" {
+		if aliases := s.Store.LinksTo(ctx, name); len(aliases) > 0 {
+			out.Warningf(ctx, "%s is the target of %d link(s) that will be dangling: %s", name, len(aliases), strings.Join(aliases, ", "))
+		}
+	}
+
+	if !force && recursive { // don't check if it's force anyway
+		entries, err := s.subtreeEntries(ctx, name)
+		if err != nil {
+			return ExitError(ExitList, err, "failed to list store: %s", err)
 		}
-		if (s.Store.Exists(ctx, name) || s.Store.IsDir(ctx, name)) && key == "" && !termio.AskForConfirmation(ctx, fmt.Sprintf("☠ Are you sure you would like to %sdelete %s?", recStr, name)) {
-			return nil
+		entries = s.sameMount(name, entries)
+		if len(entries) > 0 {
+			out.Printf(ctx, "The following %d entries will be deleted:", len(entries))
+			for _, e := range entries {
+				out.Printf(ctx, "  %s", e)
+			}
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("☠ Are you sure you would like to recursively delete %d entries below %s?", len(entries), name), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not deleting %s: %s", name, err)
+			}
+			if !ok {
+				return nil
+			}
+		}
+	}
+	if !force && !recursive {
+		if s.Store.Exists(ctx, name) && key == "" {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("☠ Are you sure you would like to delete %s?", name), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not deleting %s: %s", name, err)
+			}
+			if !ok {
+				return nil
+			}
 		}
 	}
 
 	if recursive && key == "" {
 		debug.Log("pruning %q", name)
 		if err := s.Store.Prune(ctx, name); err != nil {
-			return ExitError(ExitUnknown, err, "failed to prune %q: %s", name, err)
+			return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to prune %q: %s", name, err)
 		}
 		debug.Log("pruned %q", name)
 		return nil
@@ -65,6 +110,19 @@ func (s *Action) Delete(c *cli.Context) error {
 	return nil
 }
 
+// sameMount returns the entries in the same mount as name. Prune never
+// removes the entries of mounts below it.
+func (s *Action) sameMount(name string, entries []string) []string {
+	mp := s.Store.MountPoint(strings.TrimSuffix(s.Store.ExpandAlias(name), "/"))
+	res := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if s.Store.MountPoint(e) == mp {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
 // deleteKeyFromYAML deletes a single key from YAML
 func (s *Action) deleteKeyFromYAML(ctx context.Context, name, key string) error {
 	sec, err := s.Store.Get(ctx, name)
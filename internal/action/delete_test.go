@@ -6,9 +6,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
@@ -66,3 +68,84 @@ func TestDelete(t *testing.T) {
 	assert.Error(t, act.Delete(c))
 	buf.Reset()
 }
+
+func TestDeleteDryRun(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+	buf.Reset()
+
+	// no confirmation is needed, nothing is removed
+	assert.NoError(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true"}, "foo")))
+	assert.True(t, act.Store.Exists(ctx, "foo"))
+	assert.Contains(t, buf.String(), "Would remove foo.txt")
+	assert.Contains(t, buf.String(), "Would commit: Remove foo from store.")
+	buf.Reset()
+
+	// it fails like the real run would
+	assert.Error(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true"}, "nothing")))
+}
+
+func TestDeleteDual(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("123")
+	require.NoError(t, act.Store.Set(ctx, "foo/bar", sec))
+
+	// foo is a secret and a folder
+	err = act.Delete(gptest.CliCtx(ctx, t, "foo"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "foo is a secret and a folder")
+	assert.True(t, act.Store.Exists(ctx, "foo"))
+
+	assert.Error(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true", "leaf-only": "true"}, "foo")))
+
+	require.NoError(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"leaf-only": "true"}, "foo")))
+	assert.False(t, act.Store.Exists(ctx, "foo"))
+	assert.True(t, act.Store.Exists(ctx, "foo/bar"))
+
+	// both are removed recursively
+	require.NoError(t, act.Store.Set(ctx, "foo", sec))
+	require.NoError(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "foo")))
+	assert.False(t, act.Store.Exists(ctx, "foo"))
+	assert.False(t, act.Store.IsDir(ctx, "foo"))
+}
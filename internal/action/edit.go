@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/editor"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -19,6 +18,13 @@ import (
 // Edit the content of a password file
 func (s *Action) Edit(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = WithQuiet(ctx, c.Bool("quiet"))
+	ctx = WithUnsafe(ctx, c.Bool("unsafe"))
+	ctx = WithForceWeak(ctx, c.Bool("force-weak"))
+	ctx, err := withFollowLinkFlags(ctx, c)
+	if err != nil {
+		return err
+	}
 	name := c.Args().First()
 	if name == "" {
 		return ExitError(ExitUsage, nil, "Usage: %s edit secret", s.Name)
@@ -28,6 +34,10 @@ func (s *Action) Edit(c *cli.Context) error {
 }
 
 func (s *Action) edit(ctx context.Context, c *cli.Context, name string) error {
+	if err := s.Store.CheckWritable(name); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
 	ed := editor.Path(c)
 	if err := editor.Check(ctx, ed); err != nil {
 		out.Warningf(ctx, "Failed to check editor config: %s", err)
@@ -38,11 +48,14 @@ func (s *Action) edit(ctx context.Context, c *cli.Context, name string) error {
 	if err != nil {
 		return err
 	}
+	if err := s.confirmCollision(ctx, name, false); err != nil {
+		return err
+	}
 
 	// invoke the editor to let the user edit the content
 	newContent, err := editor.Invoke(ctx, ed, content)
 	if err != nil {
-		return ExitError(ExitUnknown, err, "failed to invoke editor: %s", err)
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to edit %s: %s", name, err)
 	}
 	return s.editUpdate(ctx, name, content, newContent, changed, ed)
 }
@@ -50,6 +63,7 @@ func (s *Action) edit(ctx context.Context, c *cli.Context, name string) error {
 func (s *Action) editUpdate(ctx context.Context, name string, content, nContent []byte, changed bool, ed string) error {
 	// If content is equal, nothing changed, exiting
 	if bytes.Equal(content, nContent) && !changed {
+		out.Noticef(ctx, "No changes to %s, nothing was saved", name)
 		return nil
 	}
 
@@ -57,7 +71,18 @@ func (s *Action) editUpdate(ctx context.Context, name string, content, nContent
 
 	// if the secret has a password, we check it's strength
 	if pw := nSec.Password(); pw != "" {
-		audit.Single(ctx, pw)
+		printStrength(ctx, name, pw)
+	}
+	ctx, err := s.checkPolicy(ctx, name, nSec.Password())
+	if err != nil {
+		return err
+	}
+
+	// saving in the editor already confirmed the changes
+	if len(content) > 0 && s.Store.Exists(ctx, name) {
+		if err := s.confirmOverwrite(ctx, name, parseSecret(content), parseSecret(nContent), false); err != nil {
+			return err
+		}
 	}
 
 	// write result (back) to store
@@ -88,10 +113,24 @@ func (s *Action) editGetContent(ctx context.Context, name string, create bool) (
 
 	// edit existing entry
 	if s.Store.Exists(ctx, name) {
+		target, broken, err := s.followLink(ctx, name)
+		if err != nil {
+			return name, nil, false, err
+		}
+		// a broken link starts as a copy of its target
+		if broken {
+			_, sec, err := s.Store.Resolve(ctxutil.WithShowParsing(ctx, false), name)
+			if err != nil {
+				return name, nil, false, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
+			}
+			return name, sec.Bytes(), true, nil
+		}
+		name = target
+
 		// we make sure we are not parsing the content of the file when editing
 		sec, err := s.Store.Get(ctxutil.WithShowParsing(ctx, false), name)
 		if err != nil {
-			return name, nil, false, ExitError(ExitDecrypt, err, "failed to decrypt %s: %s", name, err)
+			return name, nil, false, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
 		}
 		return name, sec.Bytes(), false, nil
 	}
@@ -101,7 +140,11 @@ func (s *Action) editGetContent(ctx context.Context, name string, create bool) (
 	}
 
 	// load template if it exists
-	if content, found := s.renderTemplate(ctx, name, []byte(pwgen.GeneratePassword(defaultLength, false))); found {
+	content, found, err := s.renderTemplate(ctx, name, []byte(pwgen.GeneratePassword(defaultLength, false)))
+	if err != nil {
+		return name, nil, false, err
+	}
+	if found {
 		return name, content, true, nil
 	}
 
@@ -42,6 +42,14 @@ func TestEdit(t *testing.T) {
 	// edit bar (new)
 	assert.Error(t, act.Edit(gptest.CliCtx(ctx, t, "foo")))
 	buf.Reset()
+
+	// the editor fails, foo is untouched
+	ctx = ctxutil.WithTerminal(ctx, true)
+	assert.Error(t, act.Edit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"editor": "false"}, "foo")))
+	sec, err := act.Store.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", sec.Password())
+	buf.Reset()
 }
 
 func TestEditUpdate(t *testing.T) {
@@ -64,6 +72,7 @@ func TestEditUpdate(t *testing.T) {
 	content := []byte("foobar")
 	// no changes
 	assert.NoError(t, act.editUpdate(ctx, "foo", content, content, false, "test"))
+	assert.Contains(t, buf.String(), "nothing was saved")
 	buf.Reset()
 
 	// changes
@@ -1,12 +1,17 @@
 package action
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"strings"
+	"syscall"
 
+	"github.com/gopasspw/gopass/internal/interrupt"
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -21,6 +26,14 @@ func (s *Action) Env(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	name := c.Args().First()
 	args := c.Args().Tail()
+	// gopass env secret -- cmd --flag
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	keepEnv := true
+	if c.IsSet("keep-env") {
+		keepEnv = c.Bool("keep-env")
+	}
 
 	if len(args) == 0 {
 		return ExitError(ExitUsage, nil, "Missing subcommand to execute")
@@ -52,20 +65,103 @@ func (s *Action) Env(c *cli.Context) error {
 		keys = append(keys, name)
 	}
 
-	env := make([]string, 0, 1)
-	for _, key := range keys {
-		debug.Log("exporting to environment key: %s", key)
-		sec, err := s.Store.Get(ctx, key)
-		if err != nil {
-			return fmt.Errorf("failed to get entry for env prefix %q: %w", name, err)
-		}
-		env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(path.Base(key)), sec.Password()))
+	env, err := s.envVars(ctx, name, keys)
+	if err != nil {
+		return err
 	}
 
-	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
-	cmd.Env = append(os.Environ(), env...)
+	cmd := exec.Command(args[0], args[1:]...)
+	if keepEnv {
+		cmd.Env = append(os.Environ(), env...)
+	} else {
+		cmd.Env = env
+	}
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+
+	return runForwardSignals(cmd)
+}
+
+// envVars returns the environment variables of the given secrets. Every key
+// of a secret is exported with its value, the password as PASSWORD. For
+// compatibility the name of each secret holds its password as well. Later
+// secrets override earlier ones.
+func (s *Action) envVars(ctx context.Context, prefix string, names []string) ([]string, error) {
+	vars := make(map[string]string, len(names))
+	for _, name := range names {
+		debug.Log("exporting to environment key: %s", name)
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get entry for env prefix %q: %w", prefix, err)
+		}
+		vars[strings.ToUpper(path.Base(name))] = sec.Password()
+		for _, k := range sec.Keys() {
+			v, found := sec.Get(k)
+			if !found {
+				continue
+			}
+			vars[envName(k)] = v
+		}
+		vars["PASSWORD"] = sec.Password()
+	}
+
+	env := make([]string, 0, len(vars))
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// envName turns a key into the name of an environment variable, e.g.
+// db-user becomes DB_USER
+func envName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// forwardingSignals is called by runForwardSignals once the signals are
+// forwarded. Tests replace it to know when they can send one.
+var forwardingSignals = func() {}
+
+// runForwardSignals runs the command and forwards SIGTERM to it. Ctrl+C is
+// sent to the whole process group by the terminal, the command already got
+// it and it's not sent again. Either way gopass keeps waiting for the command
+// and passes on its exit code.
+func runForwardSignals(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	restore := interrupt.Redirect(func(sig os.Signal) {
+		if sig != syscall.SIGTERM {
+			debug.Log("not forwarding %s to %d", sig, cmd.Process.Pid)
+			return
+		}
+		debug.Log("forwarding %s to %d", sig, cmd.Process.Pid)
+		_ = cmd.Process.Signal(sig)
+	})
+	defer restore()
+	forwardingSignals()
+
+	err := cmd.Wait()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		code := ee.ExitCode()
+		if code < 0 {
+			// killed by a signal
+			code = ExitUnknown
+		}
+		return cli.Exit("", code)
+	}
+	return err
 }
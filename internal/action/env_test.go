@@ -3,17 +3,24 @@ package action
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"runtime"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/interrupt"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func TestEnvLeafHappyPath(t *testing.T) {
@@ -103,3 +110,118 @@ func TestEnvProgramNotSpecified(t *testing.T) {
 	assert.EqualError(t, act.Env(gptest.CliCtx(ctx, t, "foo")),
 		"Missing subcommand to execute")
 }
+
+func TestEnvKeyValues(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("devpw")
+	sec.Set("db-user", "admin")
+	sec.Set("api.url", "http://localhost")
+	require.NoError(t, act.Store.Set(ctx, "myproject/dev", sec))
+	sec = secrets.NewKV()
+	sec.SetPassword("localpw")
+	sec.Set("db-user", "root")
+	require.NoError(t, act.Store.Set(ctx, "myproject/local", sec))
+
+	t.Run("single secret", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Env(gptest.CliCtx(ctx, t, "myproject/dev", "--", "env")))
+		assert.Contains(t, buf.String(), "DB_USER=admin\n")
+		assert.Contains(t, buf.String(), "API_URL=http://localhost\n")
+		assert.Contains(t, buf.String(), "PASSWORD=devpw\n")
+		assert.Contains(t, buf.String(), "DEV=devpw\n")
+	})
+
+	t.Run("later secrets override earlier ones", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Env(gptest.CliCtx(ctx, t, "myproject", "env")))
+		assert.Contains(t, buf.String(), "DB_USER=root\n")
+		assert.Contains(t, buf.String(), "API_URL=http://localhost\n")
+		assert.Contains(t, buf.String(), "PASSWORD=localpw\n")
+	})
+
+	t.Run("clean environment", func(t *testing.T) {
+		defer buf.Reset()
+		t.Setenv("GOPASS_ENV_TEST", "leaked")
+		require.NoError(t, act.Env(gptest.CliCtxWithFlags(ctx, t, map[string]string{"keep-env": "false"}, "myproject/local", "env")))
+		assert.Equal(t, "DB_USER=root\nLOCAL=localpw\nPASSWORD=localpw\n", buf.String())
+	})
+}
+
+func TestEnvExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh on windows")
+	}
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	err = act.Env(gptest.CliCtx(ctx, t, "foo", "sh", "-c", "exit 3"))
+	require.Error(t, err)
+	var ec cli.ExitCoder
+	require.True(t, errors.As(err, &ec))
+	assert.Equal(t, 3, ec.ExitCode())
+}
+
+func TestEnvForwardSignals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no signals on windows")
+	}
+
+	ctx, stop := interrupt.Notify(context.Background())
+	defer stop()
+
+	// the signal must not be sent before it's caught, it would kill the test
+	ready := make(chan struct{})
+	forwardingSignals = func() { close(ready) }
+	defer func() {
+		forwardingSignals = func() {}
+	}()
+
+	// the command exits with 3 if it gets SIGINT, it must only get SIGTERM. A
+	// Ctrl+C from the terminal reaches it without gopass.
+	cmd := exec.Command("sh", "-c", "trap 'exit 3' INT; sleep 10 & wait")
+	go func() {
+		<-ready
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Signal(os.Interrupt)
+			time.Sleep(100 * time.Millisecond)
+			_ = p.Signal(syscall.SIGTERM)
+		}
+	}()
+
+	start := time.Now()
+	err := runForwardSignals(cmd)
+	require.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	var ec cli.ExitCoder
+	require.True(t, errors.As(err, &ec))
+	assert.Equal(t, ExitUnknown, ec.ExitCode())
+
+	// the signals don't cancel gopass while the command runs
+	assert.NoError(t, ctx.Err())
+}
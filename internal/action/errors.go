@@ -1,9 +1,20 @@
 package action
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/picker"
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/urfave/cli/v2"
 )
 
@@ -52,6 +63,149 @@ const (
 	ExitGPG
 )
 
+// exitCodes lists the class of each exit code. The classes are part of the
+// JSON error output and must not change, scripts match on them.
+var exitCodes = []struct {
+	code  int
+	class string
+	usage string
+}{
+	{ExitOK, "ok", "success"},
+	{ExitUnknown, "unknown", "any error that isn't classified below"},
+	{ExitUsage, "usage", "invalid arguments or flags"},
+	{ExitAborted, "aborted", "aborted by the user or a question can't be asked without a terminal"},
+	{ExitUnsupported, "unsupported", "the operation is not supported"},
+	{ExitAlreadyInitialized, "already_initialized", "the store is already initialized"},
+	{ExitNotInitialized, "not_initialized", "the store is not initialized"},
+	{ExitGit, "git", "a git operation failed"},
+	{ExitMount, "mount", "a mount operation failed or the store is read-only"},
+	{ExitNoName, "no_name", "no name given"},
+	{ExitNotFound, "not_found", "the secret or key was not found"},
+	{ExitDecrypt, "decryption", "decrypting a secret failed"},
+	{ExitEncrypt, "encryption", "encrypting a secret failed"},
+	{ExitList, "list", "listing the store failed"},
+	{ExitAudit, "audit", "the audit found issues"},
+	{ExitFsck, "fsck", "the integrity check found issues"},
+	{ExitConfig, "config", "reading or writing the config failed"},
+	{ExitRecipients, "recipients", "a recipient operation failed"},
+	{ExitIO, "io", "reading or writing a file failed"},
+	{ExitGPG, "gpg", "a GPG operation failed"},
+}
+
+// ExitClass returns the class of an exit code, e.g. not_found
+func ExitClass(code int) string {
+	for _, ec := range exitCodes {
+		if ec.code == code {
+			return ec.class
+		}
+	}
+	return "unknown"
+}
+
+// ExitCodesHelp returns the list of exit codes for gopass --help
+func ExitCodesHelp() string {
+	var sb strings.Builder
+	for _, ec := range exitCodes {
+		fmt.Fprintf(&sb, "   %2d  %-20s %s\n", ec.code, ec.class, ec.usage)
+	}
+	return sb.String()
+}
+
+// ExitCode returns the exit code for an error returned by an action. Errors
+// created by ExitError carry their code, all others are classified by the
+// errors they wrap.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ec cli.ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return classify(err)
+}
+
+// classify returns the exit code for the well known errors of the stores
+// and backends. Everything else is ExitUnknown.
+func classify(err error) int {
+	var nie *termio.NoInteractionError
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, store.ErrDecrypt):
+		return ExitDecrypt
+	case errors.Is(err, store.ErrEncrypt):
+		return ExitEncrypt
+	case errors.Is(err, store.ErrGitInit), errors.Is(err, store.ErrGitNotInit), errors.Is(err, store.ErrGitNoRemote), errors.Is(err, store.ErrGitNoSigningKey):
+		return ExitGit
+	case errors.Is(err, store.ErrReadOnly):
+		return ExitMount
+	case errors.Is(err, store.ErrIO):
+		return ExitIO
+	case errors.Is(err, termio.ErrAborted), errors.Is(err, picker.ErrCancelled), errors.Is(err, context.Canceled), errors.As(err, &nie):
+		return ExitAborted
+	case errors.Is(err, backend.ErrNotSupported):
+		return ExitUnsupported
+	case errors.Is(err, config.ErrConfigNotFound), errors.Is(err, config.ErrConfigNotParsed):
+		return ExitConfig
+	}
+	return ExitUnknown
+}
+
+// ExitErrHandler handles the errors returned by all commands. Errors that
+// aren't created by ExitError are classified, so they exit with the right
+// code, too. With --format json the error is printed as JSON to stderr:
+//
+//	{"error":{"class":"not_found","code":10,"message":"..."}}
+func ExitErrHandler(c *cli.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	code := ExitCode(err)
+	if f, _ := outputFormat(c); f != out.FormatJSON {
+		var ec cli.ExitCoder
+		if !errors.As(err, &ec) && code != ExitUnknown {
+			err = cli.Exit(err.Error(), code)
+		}
+		cli.HandleExitCoder(err)
+		return
+	}
+
+	buf, jerr := exitJSON(err)
+	if jerr != nil {
+		cli.HandleExitCoder(err)
+		return
+	}
+	fmt.Fprintln(out.Stderr, string(buf))
+	os.Exit(code)
+}
+
+func exitJSON(err error) ([]byte, error) {
+	code := ExitCode(err)
+	type jsonError struct {
+		Class   string `json:"class"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	return json.Marshal(map[string]jsonError{
+		"error": {
+			Class:   ExitClass(code),
+			Code:    code,
+			Message: err.Error(),
+		},
+	})
+}
+
+// exitCodeFor returns the exit code of err if it can be classified and def
+// otherwise
+func exitCodeFor(err error, def int) int {
+	if code := ExitCode(err); code != ExitUnknown {
+		return code
+	}
+	return def
+}
+
 // ExitError returns a user friendly CLI error
 func ExitError(exitCode int, err error, format string, args ...interface{}) error {
 	msg := fmt.Sprintf(format, args...)
@@ -7,8 +7,11 @@ import (
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/termio"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExitError(t *testing.T) {
@@ -21,3 +24,47 @@ func TestExitError(t *testing.T) {
 	assert.Error(t, ExitError(ExitUnknown, fmt.Errorf("test"), "test"))
 	assert.NotContains(t, buf.String(), "Stacktrace")
 }
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, ExitOK},
+		{fmt.Errorf("test"), ExitUnknown},
+		{ExitError(ExitNotFound, nil, "test"), ExitNotFound},
+		{store.ErrNotFound, ExitNotFound},
+		{fmt.Errorf("failed to read foo: %w", store.ErrDecrypt), ExitDecrypt},
+		{fmt.Errorf("failed to write foo: %w", store.ErrEncrypt), ExitEncrypt},
+		{store.ErrGitNoRemote, ExitGit},
+		{termio.ErrAborted, ExitAborted},
+		{&termio.NoInteractionError{Question: "Overwrite?", Flag: "--force"}, ExitAborted},
+	} {
+		assert.Equal(t, tc.code, ExitCode(tc.err), "%v", tc.err)
+	}
+
+	assert.Equal(t, ExitDecrypt, exitCodeFor(fmt.Errorf("test"), ExitDecrypt))
+	assert.Equal(t, ExitNotFound, exitCodeFor(store.ErrNotFound, ExitDecrypt))
+}
+
+func TestExitCodesHelp(t *testing.T) {
+	// every exit code has a class
+	for code := ExitOK; code <= ExitGPG; code++ {
+		if code != ExitUnknown {
+			assert.NotEqual(t, "unknown", ExitClass(code), "exit code %d", code)
+		}
+		assert.Contains(t, ExitCodesHelp(), fmt.Sprintf("%2d  %s ", code, ExitClass(code)))
+	}
+	assert.Equal(t, "not_found", ExitClass(ExitNotFound))
+	assert.Equal(t, "unknown", ExitClass(42))
+}
+
+func TestExitJSON(t *testing.T) {
+	buf, err := exitJSON(ExitError(ExitNotFound, store.ErrNotFound, "Secret %q not found", "foo"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":{"class":"not_found","code":10,"message":"Secret \"foo\" not found"}}`, string(buf))
+
+	buf, err = exitJSON(fmt.Errorf("test"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":{"class":"unknown","code":1,"message":"test"}}`, string(buf))
+}
@@ -0,0 +1,283 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/internal/archive"
+	"github.com/gopasspw/gopass/internal/importer"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/decrypt"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Export writes the secrets of the store to an encrypted archive
+func (s *Action) Export(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	if format := c.String("format"); format != "" && format != "archive" {
+		return ExitError(ExitUsage, nil, "unknown format %q. Only archive is supported", format)
+	}
+	dst := c.String("output")
+	if dst == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s export --format archive -o <file>", s.Name)
+	}
+
+	names, err := s.exportNames(ctx, c.String("store"), c.StringSlice("include"), c.StringSlice("exclude"))
+	if err != nil {
+		return err
+	}
+	if len(names) < 1 {
+		return ExitError(ExitNotFound, nil, "no secrets to export")
+	}
+
+	recipients, err := exportRecipients(ctx, c.StringSlice("recipient"))
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	if err := s.warmup(ctx, c, names); err != nil {
+		return err
+	}
+
+	w, err := exportOpen(dst, c.Bool("force"))
+	if err != nil {
+		return ExitError(ExitIO, err, "%s", err)
+	}
+
+	if err := s.exportWrite(withJobs(ctx, c), w, names, recipients); err != nil {
+		_ = w.Close()
+		if dst != "-" {
+			_ = os.Remove(dst)
+		}
+		return ExitError(ExitIO, err, "failed to export: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return ExitError(ExitIO, err, "failed to close %s: %s", dst, err)
+	}
+
+	out.OKf(ctx, "Exported %d secrets to %s", len(names), dst)
+	return nil
+}
+
+// exportNames returns the names of the secrets to export, optionally only
+// of one mount
+func (s *Action) exportNames(ctx context.Context, store string, include, exclude []string) ([]string, error) {
+	if store != "" {
+		if _, found := s.Store.Mounts()[store]; !found {
+			return nil, ExitError(ExitMount, nil, "store %q is not mounted. See '%s mounts'", store, s.Name)
+		}
+	}
+
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+
+	names := make([]string, 0, len(list))
+	for _, name := range list {
+		if store != "" && s.Store.MountPoint(name) != store {
+			continue
+		}
+		if len(include) > 0 && !matchName(name, include) {
+			continue
+		}
+		if matchName(name, exclude) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// exportStreamSize is the size of the ciphertext above which a secret is
+// streamed into the archive instead of being decrypted into memory. The size
+// of an archive entry must be known before its content is written, so a
+// streamed secret is decrypted twice. It's a variable so that tests can
+// stream small secrets.
+var exportStreamSize int64 = 1 << 20
+
+// exportWrite decrypts the secrets and streams them into the archive. Small
+// secrets are decrypted concurrently, large ones one by one, in the order of
+// names.
+func (s *Action) exportWrite(ctx context.Context, w io.Writer, names []string, recipients []age.Recipient) error {
+	aw, err := archive.NewWriter(w, recipients...)
+	if err != nil {
+		return err
+	}
+
+	// export the content as stored, without parsing
+	ctx = ctxutil.WithShowParsing(ctx, false)
+	var batch []string
+	flush := func() error {
+		defer func() {
+			batch = nil
+		}()
+		return decrypt.All(ctx, s.Store, batch, func(r decrypt.Result) error {
+			if r.Err != nil {
+				return fmt.Errorf("failed to decrypt %s: %w", r.Name, r.Err)
+			}
+			debug.Log("adding %s to the archive", r.Name)
+			return aw.Add(r.Name, r.Secret.Bytes())
+		})
+	}
+	for _, name := range names {
+		if size, err := s.Store.Size(ctx, name); err != nil || size <= exportStreamSize {
+			batch = append(batch, name)
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if err := s.exportStream(ctx, aw, name); err != nil {
+			return err
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return aw.Close()
+}
+
+// exportStream adds a large secret to the archive without holding it in
+// memory. It's decrypted once to get the size of its plaintext and again
+// while it's added.
+func (s *Action) exportStream(ctx context.Context, aw *archive.Writer, name string) error {
+	debug.Log("streaming %s into the archive", name)
+	rc, err := s.Store.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	size, err := io.Copy(io.Discard, rc)
+	_ = rc.Close()
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+
+	rc, err = s.Store.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", name, err)
+	}
+	defer rc.Close()
+	return aw.AddFrom(name, size, rc)
+}
+
+// exportRecipients returns the age recipients of the archive. Without any
+// recipient the archive is encrypted with a passphrase.
+func exportRecipients(ctx context.Context, keys []string) ([]age.Recipient, error) {
+	if len(keys) > 0 {
+		recipients := make([]age.Recipient, 0, len(keys))
+		for _, k := range keys {
+			r, err := age.ParseX25519Recipient(k)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recipient %q: %w", k, err)
+			}
+			recipients = append(recipients, r)
+		}
+		return recipients, nil
+	}
+
+	pw, err := termio.AskForPassword(ctx, "passphrase for the archive", true)
+	if err != nil {
+		return nil, err
+	}
+	if pw == "" {
+		return nil, fmt.Errorf("the passphrase must not be empty")
+	}
+	r, err := age.NewScryptRecipient(pw)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Recipient{r}, nil
+}
+
+// exportOpen opens the archive for writing. Unless forced it refuses to
+// write into a directory other users can write to, e.g. /tmp, or to an
+// existing file other users can read. "-" writes to stdout.
+func exportOpen(dst string, force bool) (io.WriteCloser, error) {
+	if dst == "-" {
+		return nopCloser{stdout}, nil
+	}
+
+	if !force {
+		dir, err := os.Stat(filepath.Dir(dst))
+		if err != nil {
+			return nil, err
+		}
+		if dir.Mode().Perm()&0002 != 0 {
+			return nil, fmt.Errorf("refusing to write to %s, the directory is writable by other users. Use --force to write anyway", dst)
+		}
+		if fi, err := os.Stat(dst); err == nil && fi.Mode().Perm()&0044 != 0 {
+			return nil, fmt.Errorf("refusing to overwrite %s, it is readable by other users. Use --force to write anyway", dst)
+		}
+	}
+
+	fh, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	// an existing file keeps its mode
+	if err := fh.Chmod(0600); err != nil {
+		_ = fh.Close()
+		return nil, err
+	}
+	return fh, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// importArchive reads an archive written by export. It is decrypted with
+// the age identities in the identity file or a passphrase.
+func (s *Action) importArchive(ctx context.Context, src, identityFile string) ([]importer.Entry, error) {
+	var ids []age.Identity
+	if identityFile != "" {
+		fh, err := os.Open(identityFile)
+		if err != nil {
+			return nil, err
+		}
+		defer fh.Close()
+		if ids, err = age.ParseIdentities(fh); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", identityFile, err)
+		}
+	} else {
+		pw, err := termio.AskForPassword(ctx, "passphrase for the archive", false)
+		if err != nil {
+			return nil, err
+		}
+		id, err := age.NewScryptIdentity(pw)
+		if err != nil {
+			return nil, err
+		}
+		ids = []age.Identity{id}
+	}
+
+	fh, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	files, _, err := archive.Read(fh, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]importer.Entry, 0, len(files))
+	for _, f := range files {
+		entries = append(entries, importer.Entry{Name: f.Name, Raw: f.Content})
+	}
+	return entries, nil
+}
@@ -0,0 +1,182 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// exportCtx returns a cli context for export. gptest.CliCtxWithFlags only
+// supports plain string flags.
+func exportCtx(ctx context.Context, t *testing.T, args ...string) *cli.Context {
+	t.Helper()
+
+	fs := flag.NewFlagSet("default", flag.ContinueOnError)
+	for _, f := range []cli.Flag{
+		&cli.StringFlag{Name: "output"},
+		&cli.StringFlag{Name: "format"},
+		&cli.StringFlag{Name: "store"},
+		&cli.StringSliceFlag{Name: "recipient"},
+		&cli.StringSliceFlag{Name: "include"},
+		&cli.StringSliceFlag{Name: "exclude"},
+		&cli.BoolFlag{Name: "force"},
+	} {
+		require.NoError(t, f.Apply(fs))
+	}
+	require.NoError(t, fs.Parse(args))
+
+	c := cli.NewContext(cli.NewApp(), fs, nil)
+	c.Context = ctx
+	return c
+}
+
+func TestExport(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.New()
+	sec.SetPassword("hunter2")
+	require.NoError(t, sec.Set("user", "alice"))
+	require.NoError(t, act.Store.Set(ctx, "websites/example.org", sec))
+	require.NoError(t, act.Store.Set(ctx, "wifi/home", secrets.ParsePlain([]byte("wpa"))))
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	idFile := filepath.Join(u.Dir, "identity.txt")
+	require.NoError(t, os.WriteFile(idFile, []byte(id.String()+"\n"), 0600))
+
+	dir := filepath.Join(u.Dir, "backup")
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	dst := filepath.Join(dir, "backup.age")
+
+	t.Run("usage", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Export(exportCtx(ctx, t)))
+		assert.Error(t, act.Export(exportCtx(ctx, t, "--format", "zip", "--output", dst)))
+		assert.Error(t, act.Export(exportCtx(ctx, t, "--output", dst, "--recipient", "age1invalid")))
+		assert.Error(t, act.Export(exportCtx(ctx, t, "--output", dst, "--store", "nope")))
+		// the passphrase prompt returns an empty passphrase with AlwaysYes
+		assert.Error(t, act.Export(exportCtx(ctx, t, "--output", dst)))
+		assert.NoFileExists(t, dst)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Export(exportCtx(ctx, t, "--output", dst, "--recipient", id.Recipient().String(), "--exclude", "wifi")))
+		assert.Contains(t, buf.String(), "Exported 2 secrets to "+dst)
+
+		fi, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+		raw, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "hunter2")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "archive", "prefix": "restored", "identity": idFile}, dst)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "Imported 2 secrets, skipped 0")
+
+		got, err := act.Store.Get(ctx, "restored/websites/example.org")
+		require.NoError(t, err)
+		assert.Equal(t, string(sec.Bytes()), string(got.Bytes()))
+		got, err = act.Store.Get(ctx, "restored/foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", got.Password())
+		assert.False(t, act.Store.Exists(ctx, "restored/wifi/home"))
+	})
+
+	t.Run("include", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Export(exportCtx(ctx, t, "--output", dst, "--recipient", id.Recipient().String(), "--include", "websites/*")))
+		assert.Contains(t, buf.String(), "Exported 1 secrets")
+	})
+
+	t.Run("passphrase", func(t *testing.T) {
+		defer buf.Reset()
+		ctx := termio.WithPassPromptFunc(ctx, func(context.Context, string) (string, error) {
+			return "correct horse", nil
+		})
+		ctx = ctxutil.WithAlwaysYes(ctx, false)
+		require.NoError(t, act.Export(exportCtx(ctx, t, "--output", dst, "--include", "wifi")))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "archive", "prefix": "pw"}, dst)
+		require.NoError(t, act.Import(c))
+		got, err := act.Store.Get(ctx, "pw/wifi/home")
+		require.NoError(t, err)
+		assert.Equal(t, "wpa", got.Password())
+
+		ctx = termio.WithPassPromptFunc(ctx, func(context.Context, string) (string, error) {
+			return "wrong", nil
+		})
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "archive", "prefix": "wrong"}, dst)
+		assert.Error(t, act.Import(c))
+	})
+
+	t.Run("stream large secrets", func(t *testing.T) {
+		defer buf.Reset()
+		defer func(size int64) {
+			exportStreamSize = size
+		}(exportStreamSize)
+		exportStreamSize = 0
+
+		require.NoError(t, act.Export(exportCtx(ctx, t, "--output", dst, "--recipient", id.Recipient().String(), "--include", "websites/*", "--include", "wifi/*")))
+		assert.Contains(t, buf.String(), "Exported 2 secrets")
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "archive", "prefix": "streamed", "identity": idFile}, dst)
+		require.NoError(t, act.Import(c))
+		got, err := act.Store.Get(ctx, "streamed/websites/example.org")
+		require.NoError(t, err)
+		assert.Equal(t, string(sec.Bytes()), string(got.Bytes()))
+		got, err = act.Store.Get(ctx, "streamed/wifi/home")
+		require.NoError(t, err)
+		assert.Equal(t, "wpa", got.Password())
+	})
+
+	t.Run("refuse shared locations", func(t *testing.T) {
+		defer buf.Reset()
+		shared := filepath.Join(u.Dir, "shared")
+		require.NoError(t, os.MkdirAll(shared, 0700))
+		require.NoError(t, os.Chmod(shared, 0777))
+		args := []string{"--output", filepath.Join(shared, "backup.age"), "--recipient", id.Recipient().String()}
+		assert.Error(t, act.Export(exportCtx(ctx, t, args...)))
+		assert.NoError(t, act.Export(exportCtx(ctx, t, append(args, "--force")...)))
+
+		require.NoError(t, os.Chmod(dst, 0644))
+		assert.Error(t, act.Export(exportCtx(ctx, t, "--output", dst, "--recipient", id.Recipient().String())))
+	})
+}
@@ -3,12 +3,13 @@ package action
 import (
 	"context"
 	"fmt"
-	"sort"
+	"regexp"
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/gopasspw/gopass/internal/cui"
+	"github.com/gopasspw/gopass/internal/fuzzy"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -17,12 +18,17 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
-// FindNoFuzzy runs find without fuzzy search
+// FindNoFuzzy runs find without falling back to the closest matches. It is
+// used by gopass find, so it only prints the ranked matches if not printing
+// to a terminal to keep scripts predictable.
 func (s *Action) FindNoFuzzy(c *cli.Context) error {
-	return s.findCmd(c, nil, false)
+	if !ctxutil.IsTerminal(c.Context) {
+		return s.findCmd(c, nil, false)
+	}
+	return s.findCmd(c, s.show, false)
 }
 
-// Find runs find
+// Find runs find and falls back to the closest matches if nothing matches
 func (s *Action) Find(c *cli.Context) error {
 	return s.findCmd(c, s.show, true)
 }
@@ -38,6 +44,10 @@ func (s *Action) findCmd(c *cli.Context, cb showFunc, fuzzy bool) error {
 		ctx = ctxutil.WithForce(ctx, c.Bool("unsafe"))
 	}
 
+	if c.IsSet("regexp") {
+		ctx = WithRegexp(ctx, c.Bool("regexp"))
+	}
+
 	if !c.Args().Present() {
 		return ExitError(ExitUsage, nil, "Usage: %s find <NEEDLE>", s.Name)
 	}
@@ -56,22 +66,23 @@ func (s *Action) find(ctx context.Context, c *cli.Context, needle string, cb sho
 	}
 
 	// filter our the ones from the haystack matching the needle
-	needle = strings.ToLower(needle)
-	choices := filter(haystack, needle)
-
-	// if we have an exact match print it
-	if len(choices) == 1 {
-		if cb == nil {
-			out.Printf(ctx, choices[0])
-			return nil
+	choices, err := filter(ctx, haystack, needle)
+	if err != nil {
+		return ExitError(ExitUsage, err, "invalid regular expression %q: %s", needle, err)
+	}
+
+	// if we have a single match print it
+	if len(choices) == 1 && cb != nil {
+		if isExactMatch(choices[0], needle) {
+			out.OKf(ctx, "Found exact match in %q", choices[0])
+		} else {
+			out.OKf(ctx, "Found one match in %q", choices[0])
 		}
-		out.OKf(ctx, "Found exact match in %q", choices[0])
 		return cb(ctx, c, choices[0], false)
 	}
 
-	// if we don't have a match yet try a fuzzy search
+	// if we don't have a match yet try the closest matches
 	if len(choices) < 1 && fuzzy {
-		// try fuzzy match
 		cm := closestmatch.New(haystack, []int{2})
 		choices = cm.ClosestN(needle, 5)
 	}
@@ -81,9 +92,8 @@ func (s *Action) find(ctx context.Context, c *cli.Context, needle string, cb sho
 		return ExitError(ExitNotFound, nil, "no results found")
 	}
 
-	// do not invoke wizard if not printing to terminal or if
-	// gopass find/search was invoked directly (for scripts)
-	if !ctxutil.IsTerminal(ctx) || (c != nil && c.Command.Name == "find") {
+	// do not invoke wizard if not printing to terminal
+	if cb == nil || !ctxutil.IsTerminal(ctx) {
 		for _, value := range choices {
 			out.Printf(ctx, value)
 		}
@@ -93,6 +103,11 @@ func (s *Action) find(ctx context.Context, c *cli.Context, needle string, cb sho
 	return s.findSelection(ctx, c, choices, needle, cb)
 }
 
+// isExactMatch returns true if the name is the needle or its last element is
+func isExactMatch(name, needle string) bool {
+	return name == needle || strings.HasSuffix(name, "/"+needle)
+}
+
 // findSelection runs a wizard that lets the user select an entry
 func (s *Action) findSelection(ctx context.Context, c *cli.Context, choices []string, needle string, cb showFunc) error {
 	if cb == nil {
@@ -102,7 +117,6 @@ func (s *Action) findSelection(ctx context.Context, c *cli.Context, choices []st
 		return fmt.Errorf("out of options")
 	}
 
-	sort.Strings(choices)
 	act, sel := cui.GetSelection(ctx, "Found secrets - Please select an entry", choices)
 	debug.Log("Action: %s - Selection: %d", act, sel)
 	switch act {
@@ -133,12 +147,22 @@ func (s *Action) findSelection(ctx context.Context, c *cli.Context, choices []st
 	}
 }
 
-func filter(l []string, needle string) []string {
+// filter returns the names matching the needle. Fuzzy matches are ranked
+// by their score, regular expression matches keep their order.
+func filter(ctx context.Context, l []string, needle string) ([]string, error) {
+	if !IsRegexp(ctx) {
+		return fuzzy.Rank(needle, l), nil
+	}
+
+	re, err := regexp.Compile(needle)
+	if err != nil {
+		return nil, err
+	}
 	choices := make([]string, 0, 10)
 	for _, value := range l {
-		if strings.Contains(strings.ToLower(value), needle) {
+		if re.MatchString(value) {
 			choices = append(choices, value)
 		}
 	}
-	return choices
+	return choices, nil
 }
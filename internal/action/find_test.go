@@ -56,7 +56,7 @@ func TestFind(t *testing.T) {
 	// find fo
 	c = gptest.CliCtxWithFlags(ctx, t, nil, "fo")
 	assert.NoError(t, act.Find(c))
-	assert.Contains(t, strings.TrimSpace(buf.String()), "Found exact match in \"foo\"\nsecret")
+	assert.Contains(t, strings.TrimSpace(buf.String()), "Found one match in \"foo\"\nsecret")
 	buf.Reset()
 
 	// find fo (no fuzzy search)
@@ -75,14 +75,14 @@ func TestFind(t *testing.T) {
 	c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"clip": "true"}, "fo")
 	assert.NoError(t, act.Find(c))
 	out := strings.TrimSpace(buf.String())
-	assert.Contains(t, out, "Found exact match in \"foo\"")
+	assert.Contains(t, out, "Found one match in \"foo\"")
 	buf.Reset()
 
 	// safecontent case with force flag set
 	c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"unsafe": "true"}, "fo")
 	assert.NoError(t, act.Find(c))
 	out = strings.TrimSpace(buf.String())
-	assert.Contains(t, out, "Found exact match in \"foo\"\nsecret")
+	assert.Contains(t, out, "Found one match in \"foo\"\nsecret")
 	buf.Reset()
 
 	// stopping with the safecontent tests
@@ -107,6 +107,29 @@ func TestFind(t *testing.T) {
 	assert.Equal(t, "bar/baz\nbar/zab", strings.TrimSpace(buf.String()))
 	buf.Reset()
 
+	// find zab is an exact match of the last element
+	c = gptest.CliCtx(ctx, t, "zab")
+	assert.NoError(t, act.Find(c))
+	assert.Contains(t, buf.String(), "Found exact match in \"bar/zab\"")
+	buf.Reset()
+
+	// find ba ranks the matches
+	assert.NoError(t, act.Store.Set(ctx, "misc/xbxa", sec))
+	c = gptest.CliCtx(ctx, t, "ba")
+	assert.NoError(t, act.FindNoFuzzy(c))
+	assert.Equal(t, "bar/baz\nbar/zab\nmisc/xbxa", strings.TrimSpace(buf.String()))
+	buf.Reset()
+
+	// find with a regular expression
+	c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"regexp": "true"}, "^bar/z")
+	assert.NoError(t, act.FindNoFuzzy(c))
+	assert.Equal(t, "bar/zab", strings.TrimSpace(buf.String()))
+	buf.Reset()
+
+	c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"regexp": "true"}, "^bar/(")
+	assert.Error(t, act.FindNoFuzzy(c))
+	buf.Reset()
+
 	// find w/o callback
 	c = gptest.CliCtx(ctx, t)
 	assert.NoError(t, act.find(ctx, c, "foo", nil, false))
@@ -0,0 +1,140 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	reGoldenModified = regexp.MustCompile(`("modified": |modified: )"?[0-9TZ:.-]+"?`)
+	reGoldenRevision = regexp.MustCompile(`("revision": |revision: )"?[0-9a-f]{40}"?`)
+)
+
+// assertGolden compares the output with testdata/<name>.golden. The
+// temporary dir and the metadata of the last commit are replaced by
+// placeholders.
+func assertGolden(t *testing.T, u *gptest.Unit, name, got string) {
+	t.Helper()
+
+	got = strings.ReplaceAll(got, u.Dir, "$DIR")
+	got = reGoldenModified.ReplaceAllString(got, `${1}"$$MODIFIED"`)
+	got = reGoldenRevision.ReplaceAllString(got, `${1}"$$REVISION"`)
+
+	want, err := os.ReadFile(filepath.Join("testdata", name+".golden"))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got, name)
+}
+
+func TestFormatGolden(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = errBuf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	sec.Set("user", "jdoe")
+	sec.Add("url", "https://example.com")
+	sec.Add("url", "https://example.org")
+	_, err = sec.Write([]byte("some notes\n"))
+	require.NoError(t, err)
+	require.NoError(t, act.Store.Set(ctx, "web/example", sec))
+	require.NoError(t, u.InitStore("team"))
+	require.NoError(t, act.Store.AddMount(ctx, "team", u.StoreDir("team")))
+
+	for _, format := range []string{"json", "yaml"} {
+		format := format
+		ext := "." + format
+
+		t.Run("ls "+format, func(t *testing.T) {
+			defer buf.Reset()
+			require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format})))
+			assertGolden(t, u, "list"+ext, buf.String())
+		})
+
+		t.Run("ls --flat "+format, func(t *testing.T) {
+			defer buf.Reset()
+			require.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format, "flat": "true"}, "web")))
+			assertGolden(t, u, "list-flat"+ext, buf.String())
+		})
+
+		t.Run("show "+format, func(t *testing.T) {
+			defer buf.Reset()
+			require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format, "unsafe": "true"}, "web/example")))
+			assertGolden(t, u, "show"+ext, buf.String())
+		})
+
+		t.Run("mounts "+format, func(t *testing.T) {
+			defer buf.Reset()
+			require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format})))
+			assertGolden(t, u, "mounts"+ext, buf.String())
+		})
+
+		t.Run("recipients "+format, func(t *testing.T) {
+			defer buf.Reset()
+			require.NoError(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format})))
+			assertGolden(t, u, "recipients"+ext, buf.String())
+		})
+
+		t.Run("audit "+format, func(t *testing.T) {
+			defer buf.Reset()
+			assert.Error(t, act.Audit(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": format}, "web")))
+			assertGolden(t, u, "audit"+ext, buf.String())
+		})
+	}
+	assert.Empty(t, errBuf.String())
+
+	t.Run("show json without --unsafe", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json"}, "web/example")))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("show json with formatpasswords", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.FormatPasswords = true
+		defer func() {
+			act.cfg.FormatPasswords = false
+		}()
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json"}, "web/example")))
+		assertGolden(t, u, "show.json", buf.String())
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "xml"})))
+		assert.Error(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "xml"})))
+		assert.Empty(t, buf.String())
+	})
+}
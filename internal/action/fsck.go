@@ -1,6 +1,9 @@
 package action
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -20,9 +23,33 @@ func (s *Action) Fsck(c *cli.Context) error {
 	s.rem.Reset("fsck")
 
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.Bool("compact-history") {
+		return s.fsckCompactHistory(ctx, c)
+	}
 	if c.IsSet("decrypt") {
 		ctx = leaf.WithFsckDecrypt(ctx, c.Bool("decrypt"))
 	}
+	if c.IsSet("verify") {
+		ctx = leaf.WithFsckVerify(ctx, c.Bool("verify"))
+	}
+	if c.IsSet("checksums") {
+		ctx = leaf.WithFsckChecksums(ctx, c.Bool("checksums"))
+	}
+	if c.IsSet("fix") {
+		ctx = leaf.WithFsckFix(ctx, c.Bool("fix"))
+	}
+	asJSON := false
+	switch format := c.String("format"); format {
+	case "", "text":
+	case "json":
+		asJSON = true
+		// only the report must end up on stdout
+		ctx = ctxutil.WithHidden(ctx, true)
+	default:
+		return ExitError(ExitUsage, nil, "unknown format %q. Must be text or json", format)
+	}
+	report := &leaf.FsckReport{}
+	ctx = leaf.WithFsckReport(ctx, report)
 
 	out.Printf(ctx, "Checking store integrity ...")
 	// make sure config is in the right place
@@ -39,6 +66,13 @@ func (s *Action) Fsck(c *cli.Context) error {
 		}
 	}
 
+	if c.Bool("rebuild-index") {
+		out.Printf(ctx, "Rebuilding the index of secret names ...")
+		if err := s.Store.RebuildIndex(ctx); err != nil {
+			return ExitError(ExitIO, err, "failed to rebuild index: %s", err)
+		}
+	}
+
 	// display progress bar
 	t, err := s.Store.Tree(ctx)
 	if err != nil {
@@ -55,9 +89,80 @@ func (s *Action) Fsck(c *cli.Context) error {
 	ctx = out.AddPrefix(ctx, "\n")
 
 	// the main work in done by the sub stores
-	if err := s.Store.Fsck(ctx, c.Args().Get(0)); err != nil {
-		return ExitError(ExitFsck, err, "fsck found errors: %s", err)
-	}
+	fsckErr := s.Store.Fsck(ctx, c.Args().Get(0))
 	bar.Done()
+
+	if asJSON {
+		if err := printFsckJSON(report); err != nil {
+			return ExitError(ExitIO, err, "%s", err)
+		}
+	} else {
+		printFsckSummary(ctx, report)
+	}
+	if fsckErr != nil {
+		return ExitError(ExitFsck, fsckErr, "fsck found errors: %s", fsckErr)
+	}
+
+	if ctxutil.IsExportKeys(ctx) {
+		if err := s.Store.ImportMissingPublicKeys(ctx); err != nil {
+			out.Errorf(ctx, "Failed to import missing public keys: %s", err)
+		}
+	}
+
+	if s.cfg.Ownertrust {
+		if err := s.Store.SyncOwnertrust(ctx); err != nil {
+			out.Errorf(ctx, "Failed to sync ownertrust: %s", err)
+		}
+	}
+
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	s.printExpiringRecipients(ctx, stores...)
+	if weak := s.printRecipientStrength(ctx, stores...); weak > 0 && c.Bool("strict") {
+		return ExitError(ExitFsck, nil, "%d recipient keys are weak", weak)
+	}
+	return nil
+}
+
+// fsckTitles are the descriptions of the fsck problem classes
+var fsckTitles = map[string]string{
+	leaf.FsckMissingRecipients: "Missing recipients",
+	leaf.FsckExtraRecipients:   "Extra recipients",
+	leaf.FsckDecryptFailed:     "Decryption failed",
+	leaf.FsckUnreadable:        "Unreadable",
+	leaf.FsckShadowed:          "Shadowed",
+	leaf.FsckChecksumMismatch:  "Checksum mismatch",
+	leaf.FsckDanglingLink:      "Dangling links",
+	leaf.FsckUnnormalizedName:  "Unnormalized names",
+}
+
+func printFsckSummary(ctx context.Context, r *leaf.FsckReport) {
+	counts, fixed := r.Counts()
+	out.Printf(ctx, "Summary:")
+	for _, c := range leaf.FsckProblems {
+		out.Printf(ctx, "  %-20s %d", fsckTitles[c]+":", counts[c])
+	}
+	out.Printf(ctx, "  %-20s %d", "Fixed:", fixed)
+}
+
+func printFsckJSON(r *leaf.FsckReport) error {
+	counts, fixed := r.Counts()
+	problems := r.Problems()
+	if problems == nil {
+		problems = []leaf.FsckProblem{}
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Problems []leaf.FsckProblem `json:"problems"`
+		Counts   map[string]int     `json:"counts"`
+		Fixed    int                `json:"fixed"`
+	}{
+		Problems: problems,
+		Counts:   counts,
+		Fixed:    fixed,
+	}); err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
 	return nil
 }
@@ -3,11 +3,13 @@ package action
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 
@@ -42,6 +44,7 @@ func TestFsck(t *testing.T) {
 	output := strings.TrimSpace(buf.String())
 	assert.Contains(t, output, "Checking store integrity ...")
 	assert.Contains(t, output, "Extra recipients on foo: [0xFEEDBEEF]")
+	assert.Contains(t, output, "Extra recipients:    1")
 	buf.Reset()
 
 	// fsck (hidden)
@@ -58,6 +61,29 @@ func TestFsck(t *testing.T) {
 	assert.Contains(t, output, "Extra recipients on foo: [0xFEEDBEEF]")
 	buf.Reset()
 
+	// fsck --format json
+	assert.NoError(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json"})))
+	var report struct {
+		Problems []leaf.FsckProblem `json:"problems"`
+		Counts   map[string]int     `json:"counts"`
+		Fixed    int                `json:"fixed"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, 1, report.Counts[leaf.FsckExtraRecipients])
+	assert.Equal(t, 0, report.Counts[leaf.FsckMissingRecipients])
+	assert.Equal(t, []leaf.FsckProblem{{Type: leaf.FsckExtraRecipients, Secret: "foo", Recipients: []string{"0xFEEDBEEF"}}}, report.Problems)
+	buf.Reset()
+
+	assert.Error(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "yaml"})))
+	buf.Reset()
+
+	// fsck --fix
+	assert.NoError(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, map[string]string{"fix": "true"})))
+	output = strings.TrimSpace(buf.String())
+	assert.Contains(t, output, "Re-encrypting 1 secrets to fix their recipients")
+	assert.Contains(t, output, "Fixed:               1")
+	buf.Reset()
+
 	// fsck fo
 	assert.NoError(t, act.Fsck(gptest.CliCtx(ctx, t, "fo")))
 	output = strings.TrimSpace(buf.String())
@@ -0,0 +1,116 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// fsckCompactHistory drops all but the last revisions of each secret from
+// the history of a store. Without --force it only reports what would be
+// removed. The history is only rewritten after confirming it twice and
+// typing the name of the store.
+func (s *Action) fsckCompactHistory(ctx context.Context, c *cli.Context) error {
+	store := c.String("store")
+	name := store
+	if name == "" {
+		name = "root"
+	}
+	keep := c.Int("keep")
+	if keep < 1 {
+		return ExitError(ExitUsage, nil, "--keep must be at least 1")
+	}
+
+	stats, err := s.Store.CompactHistory(ctx, store, keep, true)
+	if err != nil {
+		if errors.Is(err, backend.ErrNotSupported) {
+			return ExitError(ExitUnsupported, err, "%s", err)
+		}
+		return ExitError(ExitGit, err, "failed to read the history of %s: %s", name, err)
+	}
+	if stats.KeptCommits == stats.Commits {
+		out.OKf(ctx, "The history of %s has at most %d revisions per secret. Nothing to do", name, keep)
+		return nil
+	}
+	out.Printf(ctx, "Keeping the last %d revisions per secret of %s removes %d of %d commits and %d of %d revisions, saving about %s of %s.",
+		keep, name,
+		stats.Commits-stats.KeptCommits, stats.Commits,
+		stats.Revisions-stats.KeptRevisions, stats.Revisions,
+		humanize.Bytes(uint64(stats.Savings)), humanize.Bytes(uint64(stats.Size)),
+	)
+
+	if ctxutil.IsDryRun(ctx) {
+		return nil
+	}
+	if !c.Bool("force") {
+		out.Printf(ctx, "This is a dry run. Pass --force to rewrite the history.")
+		return nil
+	}
+	if !ctxutil.IsInteractive(ctx) {
+		return ExitError(ExitAborted, nil, "rewriting the history must be confirmed interactively")
+	}
+
+	if err := s.confirmCompactHistory(ctx, store, name); err != nil {
+		return err
+	}
+
+	stats, err = s.Store.CompactHistory(ctx, store, keep, false)
+	if err != nil {
+		return ExitError(ExitGit, err, "failed to compact the history of %s: %s", name, err)
+	}
+	out.OKf(ctx, "Compacted the history of %s to %d commits", name, stats.KeptCommits)
+
+	if stats.Remote == "" {
+		return nil
+	}
+	out.Printf(ctx, "The new history was pushed to %s.", stats.Remote)
+	out.Warningf(ctx, "Everyone else must remove their copy of the store and clone it again, e.g. with")
+	if store == "" {
+		out.Printf(ctx, "  %s clone %s", s.Name, stats.Remote)
+	} else {
+		out.Printf(ctx, "  %s mounts remove %s && %s clone %s %s", s.Name, store, s.Name, stats.Remote, store)
+	}
+	out.Printf(ctx, "Pushing from an old copy brings back the removed history.")
+	return nil
+}
+
+// confirmCompactHistory asks to confirm rewriting the history twice and to
+// type the name of the store. Neither --yes nor --force answer these.
+func (s *Action) confirmCompactHistory(ctx context.Context, store, name string) error {
+	ctx = ctxutil.WithAlwaysYes(ctx, false)
+
+	ok, err := termio.AskForBool(ctx, color.RedString("The removed revisions can not be restored. Rewrite the history of %s?", name), false)
+	if err != nil || !ok {
+		return ExitError(ExitAborted, err, "not rewriting the history")
+	}
+
+	recps := s.Store.ListRecipients(ctx, store)
+	crypto := s.Store.Crypto(ctx, store)
+	out.Printf(ctx, "Everyone using %s has to clone it again afterwards:", name)
+	for _, r := range recps {
+		out.Printf(ctx, "  - %s", crypto.FormatKey(ctx, r, ""))
+	}
+	ok, err = termio.AskForBool(ctx, fmt.Sprintf("Did all %d recipients acknowledge this?", len(recps)), false)
+	if err != nil || !ok {
+		return ExitError(ExitAborted, err, "not rewriting the history")
+	}
+
+	typed, err := termio.AskForString(ctx, fmt.Sprintf("Type %q to rewrite the history", name), "")
+	if err != nil {
+		return ExitError(ExitAborted, err, "not rewriting the history: %s", err)
+	}
+	if strings.TrimSpace(typed) != name {
+		return ExitError(ExitAborted, nil, "not rewriting the history: %q is not %q", typed, name)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFsckCompactHistory(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	for _, pw := range []string{"one", "two", "three"} {
+		sec := secrets.New()
+		sec.SetPassword(pw)
+		require.NoError(t, act.Store.Set(ctx, "web", sec))
+	}
+	revisions := func() int {
+		revs, err := act.Store.ListRevisions(ctx, "web")
+		require.NoError(t, err)
+		return len(revs)
+	}
+	require.Equal(t, 3, revisions())
+
+	flags := map[string]string{"compact-history": "true", "keep": "1"}
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Contains(t, buf.String(), "Keeping the last 1 revisions per secret of root removes 2 of")
+		assert.Contains(t, buf.String(), "Pass --force to rewrite the history")
+		assert.NotContains(t, buf.String(), "Checking store integrity")
+		assert.Equal(t, 3, revisions())
+	})
+
+	flags["force"] = "true"
+
+	t.Run("not without interaction", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Equal(t, 3, revisions())
+	})
+
+	ctx = ctxutil.WithInteractive(ctx, true)
+	defer func() {
+		termio.Stdin = os.Stdin
+	}()
+
+	t.Run("wrong store name", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("y\ny\nfoo\n")
+		assert.Error(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Equal(t, 3, revisions())
+	})
+
+	t.Run("not acknowledged", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("y\nn\nroot\n")
+		assert.Error(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Equal(t, 3, revisions())
+	})
+
+	t.Run("compact the history", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("y\ny\nroot\n")
+		require.NoError(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Contains(t, buf.String(), "Compacted the history of root")
+		assert.Equal(t, 1, revisions())
+
+		sec, err := act.Store.Get(ctx, "web")
+		require.NoError(t, err)
+		assert.Equal(t, "three", sec.Password())
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Fsck(gptest.CliCtxWithFlags(ctx, t, flags)))
+		assert.Contains(t, buf.String(), "Nothing to do")
+	})
+}
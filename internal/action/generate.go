@@ -1,6 +1,7 @@
 package action
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"path"
@@ -8,7 +9,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/editor"
+	"github.com/gopasspw/gopass/internal/link"
 	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
@@ -16,10 +20,12 @@ import (
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
 	"github.com/gopasspw/gopass/pkg/pwgen/xkcdgen"
+	"github.com/gopasspw/gopass/pkg/strength"
 	"github.com/gopasspw/gopass/pkg/termio"
 
 	"github.com/urfave/cli/v2"
@@ -38,6 +44,11 @@ var (
 func (s *Action) Generate(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	ctx = WithClip(ctx, c.Bool("clip"))
+	ctx = WithForceWeak(ctx, c.Bool("force-weak"))
+	ctx, err := withFollowLinkFlags(ctx, c)
+	if err != nil {
+		return err
+	}
 	force := c.Bool("force")
 	edit := c.Bool("edit")
 
@@ -49,17 +60,35 @@ func (s *Action) Generate(c *cli.Context) error {
 
 	// ask for name of the secret if it wasn't provided already
 	if name == "" {
-		var err error
 		name, err = termio.AskForString(ctx, "Which name do you want to use?", "")
 		if err != nil || name == "" {
 			return ExitError(ExitNoName, err, "please provide a password name")
 		}
 	}
+	if err := s.Store.CheckWritable(name); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
+	// generating a password for a link changes its target, unless the link
+	// is broken
+	name, _, err = s.followLink(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := s.confirmCollision(ctx, name, force); err != nil {
+		return err
+	}
 
 	// ask for confirmation before overwriting existing entry
-	if !force { // don't check if it's force anyway
-		if s.Store.Exists(ctx, name) && key == "" && !termio.AskForConfirmation(ctx, fmt.Sprintf("An entry already exists for %s. Overwrite the current password?", name)) {
-			return ExitError(ExitAborted, nil, "user aborted. not overwriting your current password")
+	if !force && !ctxutil.IsDryRun(ctx) { // don't check if it's force anyway
+		if s.Store.Exists(ctx, name) && key == "" {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("An entry already exists for %s. Overwrite the current password?", name), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not overwriting your current password: %s", err)
+			}
+			if !ok {
+				return ExitError(ExitAborted, nil, "user aborted. not overwriting your current password")
+			}
 		}
 	}
 
@@ -69,25 +98,43 @@ func (s *Action) Generate(c *cli.Context) error {
 		return err
 	}
 
-	// display or copy to clipboard
-	if err := s.generateCopyOrPrint(ctx, c, name, key, password); err != nil {
+	// the estimate is only printed, never stored
+	if c.Bool("print-entropy") && !c.Bool("quiet") {
+		e := strength.Check(password, name)
+		out.Printf(ctx, "Password strength: ~%.1f bits of entropy (%d / 4, cracked in %s)", e.Entropy, e.Score, e.CrackTime)
+	}
+
+	sec, msg, err := s.generateSecret(ctx, name, key, password, kvps, !c.Bool("no-archive"))
+	if err != nil {
 		return err
 	}
 
-	// write generated password to store
-	ctx, err = s.generateSetPassword(ctx, name, key, password, kvps)
+	// if requested let the user add more data before anything is saved
+	if edit && !ctxutil.IsDryRun(ctx) {
+		nSec, ed, err := s.generateEdit(ctx, c, name, sec)
+		if err != nil {
+			return err
+		}
+		sec, msg = nSec, fmt.Sprintf("Generated password and edited with %s", ed)
+		password = generatedValue(sec, key)
+	}
+	ctx, err = s.checkPolicy(ctx, name, sec.Password())
 	if err != nil {
 		return err
 	}
 
-	// if requested launch editor to add more data to the generated secret
-	if edit && termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add more data for %s?", name)) {
-		c.Context = ctx
-		if err := s.Edit(c); err != nil {
-			return ExitError(ExitUnknown, err, "failed to edit %q: %s", name, err)
+	// display or copy to clipboard, unless it's not saved anyway
+	if !ctxutil.IsDryRun(ctx) {
+		if err := s.generateCopyOrPrint(ctx, c, name, key, password); err != nil {
+			return err
 		}
 	}
 
+	// write generated password to store
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, msg), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to save %q: %s", name, err)
+	}
+
 	return nil
 }
 
@@ -117,16 +164,19 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 
 	out.OKf(ctx, "Password for entry %q generated", entry)
 
+	// --clip=false overrides autoclip for this invocation
+	autoClip := s.cfg.AutoClip && (!c.IsSet("clip") || c.Bool("clip"))
+
 	// copy to clipboard if:
 	// - explicitly requested with -c
 	// - autoclip=true, but only if output is not being redirected
-	if IsClip(ctx) || (s.cfg.AutoClip && ctxutil.IsTerminal(ctx)) {
+	if IsClip(ctx) || (autoClip && ctxutil.IsTerminal(ctx)) {
 		if err := clipboard.CopyTo(ctx, name, []byte(password), s.cfg.ClipTimeout); err != nil {
 			return ExitError(ExitIO, err, "failed to copy to clipboard: %s", err)
 		}
 		// if autoclip is on and we're not printing the password to the terminal
 		// at least leave a notice that we did indeed copy it
-		if s.cfg.AutoClip && !c.Bool("print") {
+		if autoClip && !c.Bool("print") {
 			out.Print(ctx, "Copied to clipboard")
 			return nil
 		}
@@ -149,30 +199,22 @@ func (s *Action) generateCopyOrPrint(ctx context.Context, c *cli.Context, name,
 	return nil
 }
 
-func hasPwRuleForSecret(name string) (string, pwrules.Rule) {
-	for name != "" && name != "." {
-		d := path.Base(name)
-		if r, found := pwrules.LookupRule(d); found {
-			return d, r
-		}
-		name = path.Dir(name)
-	}
-	return "", pwrules.Rule{}
-}
-
 // generatePassword will run through the password generation steps
 func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, name string) (string, error) {
-	if domain, rule := hasPwRuleForSecret(name); domain != "" {
+	if domain, rule, found := s.Store.PasswordRule(ctx, name); found {
 		out.Printf(ctx, "Using password rules for %s ...", domain)
 		wl := 16
 		if iv, err := strconv.Atoi(length); err == nil {
-			if iv < rule.Minlen {
-				iv = rule.Minlen
-			}
-			if iv > rule.Maxlen {
-				iv = rule.Maxlen
-			}
 			wl = iv
+			if err := checkRuleLength(c, domain, rule, wl); err != nil {
+				return "", err
+			}
+		}
+		if wl < rule.Minlen {
+			wl = rule.Minlen
+		}
+		if wl > rule.Maxlen {
+			wl = rule.Maxlen
 		}
 
 		question := fmt.Sprintf("How long should the password be? (min: %d, max: %d)", rule.Minlen, rule.Maxlen)
@@ -180,8 +222,11 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 		if err != nil {
 			return "", ExitError(ExitUsage, err, "password length must be a number")
 		}
+		if err := checkRuleLength(c, domain, rule, iv); err != nil {
+			return "", err
+		}
 
-		pw := pwgen.NewCrypticForDomain(iv, domain).Password()
+		pw := pwgen.NewCrypticForRule(iv, rule).Password()
 		if pw == "" {
 			return "", fmt.Errorf("failed to generate password for %s", domain)
 		}
@@ -189,11 +234,17 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 		return pw, nil
 	}
 
-	symbols := false
-	if c.IsSet("symbols") {
-		symbols = c.Bool("symbols")
+	generator := strings.ToLower(c.String("generator"))
+	if generator == "" {
+		generator = s.cfg.GenerateMode
+	}
+	if c.Bool("memorable") || generator == "xkcd" {
+		return s.generatePasswordXKCD(ctx, c, length)
 	}
 
+	cs := s.generateCharset(c)
+	symbols := cs.Symbols != ""
+
 	var pwlen int
 	if length == "" {
 		candidateLength := defaultLength
@@ -215,9 +266,9 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 		return "", ExitError(ExitUsage, nil, "password length must not be zero")
 	}
 
-	switch c.String("generator") {
-	case "xkcd":
-		return s.generatePasswordXKCD(ctx, c, length)
+	switch generator {
+	case "pronounceable":
+		return s.generatePasswordPronounceable(ctx, c, pwlen)
 	case "memorable":
 		if c.Bool("strict") {
 			return pwgen.GenerateMemorablePassword(pwlen, symbols, true), nil
@@ -226,11 +277,100 @@ func (s *Action) generatePassword(ctx context.Context, c *cli.Context, length, n
 	case "external":
 		return pwgen.GenerateExternal(pwlen)
 	default:
+		if len(cs.Classes()) < 1 {
+			return "", ExitError(ExitUsage, nil, "no character classes selected")
+		}
 		if c.Bool("strict") {
-			return pwgen.GeneratePasswordWithAllClasses(pwlen, symbols)
+			pw, err := pwgen.GeneratePasswordClasses(pwlen, cs.Classes()...)
+			if err != nil {
+				return "", ExitError(ExitUsage, err, "failed to generate password: %s", err)
+			}
+			return pw, nil
 		}
-		return pwgen.GeneratePassword(pwlen, symbols), nil
+		return pwgen.GeneratePasswordCharsetClasses(pwlen, cs), nil
+	}
+}
+
+// generateCharset returns the character classes selected by the flags,
+// falling back to the config
+func (s *Action) generateCharset(c *cli.Context) pwgen.Charset {
+	cs := pwgen.Charset{
+		Digits:      !s.cfg.NoDigits,
+		Upper:       !s.cfg.NoUppercase,
+		Lower:       true,
+		Symbols:     s.cfg.Symbols,
+		NoAmbiguous: s.cfg.NoAmbiguous,
+	}
+	if c.IsSet("no-digits") {
+		cs.Digits = !c.Bool("no-digits")
+	}
+	if c.IsSet("no-uppercase") {
+		cs.Upper = !c.Bool("no-uppercase")
+	}
+	if c.IsSet("no-ambiguous") {
+		cs.NoAmbiguous = c.Bool("no-ambiguous")
+	}
+	if c.IsSet("symbols") {
+		cs.Symbols = parseSymbols(c.String("symbols"), s.cfg.Symbols)
+	}
+	if c.Bool("no-symbols") {
+		cs.Symbols = ""
+	}
+	return cs
+}
+
+// parseSymbols returns the symbol alphabet for the value of the --symbols
+// flag. A boolean value enables the default symbols or disables symbols.
+func parseSymbols(value, def string) string {
+	bv, err := strconv.ParseBool(value)
+	if err != nil {
+		return value
+	}
+	if !bv {
+		return ""
 	}
+	if def != "" {
+		return def
+	}
+	return pwgen.Syms
+}
+
+// optionalValue is the value of a flag which can be used like a boolean flag
+// (--symbols, --symbols=false) or with an explicit value (--symbols='#%+').
+type optionalValue struct {
+	value string
+}
+
+// Set implements flag.Value
+func (v *optionalValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// String implements flag.Value
+func (v *optionalValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+// IsBoolFlag allows using the flag without a value
+func (v *optionalValue) IsBoolFlag() bool {
+	return true
+}
+
+// checkRuleLength returns an error if strict mode is requested and the
+// length conflicts with the password rules of the domain. Otherwise the
+// length is silently adjusted to the rules.
+func checkRuleLength(c *cli.Context, domain string, rule pwrules.Rule, length int) error {
+	if !c.Bool("strict") {
+		return nil
+	}
+	if length < rule.Minlen || length > rule.Maxlen {
+		return ExitError(ExitUsage, nil, "password length %d conflicts with the password rules for %s (min: %d, max: %d)", length, domain, rule.Minlen, rule.Maxlen)
+	}
+	return nil
 }
 
 // generatePasswordXKCD walks through the steps necessary to create an XKCD-style
@@ -242,7 +382,9 @@ func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, lengt
 	}
 
 	var pwlen int
-	if length == "" {
+	if c.IsSet("words") {
+		pwlen = c.Int("words")
+	} else if length == "" {
 		candidateLength := defaultXKCDLength
 		question := "How many words should be combined to a password?"
 		iv, err := termio.AskForInt(ctx, question, candidateLength)
@@ -262,30 +404,68 @@ func (s *Action) generatePasswordXKCD(ctx context.Context, c *cli.Context, lengt
 		return "", ExitError(ExitUsage, nil, "password length must not be zero")
 	}
 
-	return xkcdgen.RandomLengthDelim(pwlen, xkcdSeparator, c.String("lang"))
+	opts := xkcdgen.Options{
+		Words:      pwlen,
+		Separator:  xkcdSeparator,
+		Capitalize: c.Bool("capitalize") || xkcdSeparator == "",
+		Digit:      c.Bool("digit"),
+		Lang:       c.String("lang"),
+	}
+	if s.cfg.WordlistFile != "" {
+		wl, err := xkcdgen.LoadWordlist(fsutil.CleanPath(s.cfg.WordlistFile))
+		if err != nil {
+			return "", ExitError(ExitConfig, err, "failed to load wordlist: %s", err)
+		}
+		opts.Wordlist = wl
+	}
+
+	pw, bits, err := xkcdgen.Generate(opts)
+	if err != nil {
+		return "", ExitError(ExitUsage, err, "failed to generate passphrase: %s", err)
+	}
+	if !c.Bool("quiet") {
+		out.Printf(ctx, "Passphrase of %d words with ~%.1f bits of entropy", pwlen, bits)
+	}
+	return pw, nil
+}
+
+// generatePasswordPronounceable creates a password of alternating consonant
+// and vowel clusters
+func (s *Action) generatePasswordPronounceable(ctx context.Context, c *cli.Context, pwlen int) (string, error) {
+	pw, bits, err := pwgen.GeneratePronounceable(pwgen.PronounceableOptions{
+		Length:  pwlen,
+		Capital: c.Bool("capitalize"),
+		Digit:   c.Bool("digit"),
+	})
+	if err != nil {
+		return "", ExitError(ExitUsage, err, "failed to generate password: %s", err)
+	}
+	// pronounceable passwords are weaker per character than cryptic ones
+	if !c.Bool("quiet") {
+		out.Printf(ctx, "Pronounceable password of %d characters with ~%.1f bits of entropy", pwlen, bits)
+	}
+	return pw, nil
 }
 
-// generateSetPassword will update or create a secret
-func (s *Action) generateSetPassword(ctx context.Context, name, key, password string, kvps map[string]string) (context.Context, error) {
+// generateSecret returns the new or updated secret with the generated
+// password and the commit message to save it with
+func (s *Action) generateSecret(ctx context.Context, name, key, password string, kvps map[string]string, archive bool) (gopass.Secret, string, error) {
 	// set a single key in an entry
 	if key != "" {
-		sec, err := s.Store.Get(ctx, name)
+		sec, err := s.generateGetExisting(ctx, name)
 		if err != nil {
-			return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
+			return nil, "", ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
 		}
 		setMetadata(sec, kvps)
 		sec.Set(key, password)
-		if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated password for key"), name, sec); err != nil {
-			return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
-		}
-		return ctx, nil
+		return sec, "Generated password for key", nil
 	}
 
 	// replace password in existing secret
 	if s.Store.Exists(ctx, name) {
-		ctx, err := s.generateReplaceExisting(ctx, name, key, password, kvps)
+		sec, err := s.generateReplaceExisting(ctx, name, password, kvps, archive)
 		if err == nil {
-			return ctx, nil
+			return sec, "Generated password for YAML key", nil
 		}
 		out.Errorf(ctx, "Failed to read existing secret. Creating anew. Error: %s", err.Error())
 	}
@@ -298,7 +478,11 @@ func (s *Action) generateSetPassword(ctx context.Context, name, key, password st
 		sec.Set("password-change-url", u)
 	}
 
-	if content, found := s.renderTemplate(ctx, name, []byte(password)); found {
+	content, found, err := s.renderTemplate(ctx, name, []byte(password))
+	if err != nil {
+		return nil, "", err
+	}
+	if found {
 		nSec := &secrets.Plain{}
 		if _, err := nSec.Write(content); err == nil {
 			sec = nSec
@@ -307,10 +491,36 @@ func (s *Action) generateSetPassword(ctx context.Context, name, key, password st
 		}
 	}
 
-	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated Password"), name, sec); err != nil {
-		return ctx, ExitError(ExitEncrypt, err, "failed to create %q: %s", name, err)
+	return sec, "Generated Password", nil
+}
+
+// generateEdit opens the generated secret in the editor before it's saved,
+// the password is on the first line. Nothing is saved if the editor fails or
+// all of the content is removed.
+func (s *Action) generateEdit(ctx context.Context, c *cli.Context, name string, sec gopass.Secret) (gopass.Secret, string, error) {
+	ed := editor.Path(c)
+	if err := editor.Check(ctx, ed); err != nil {
+		out.Warningf(ctx, "Failed to check editor config: %s", err)
 	}
-	return ctx, nil
+
+	content, err := editor.Invoke(ctx, ed, sec.Bytes())
+	if err != nil {
+		return nil, "", ExitError(ExitAborted, err, "failed to edit %s: %s", name, err)
+	}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, "", ExitError(ExitAborted, nil, "%s is empty, nothing was saved", name)
+	}
+
+	return secrets.ParsePlain(content), ed, nil
+}
+
+// generatedValue returns the generated value after the secret was edited
+func generatedValue(sec gopass.Secret, key string) string {
+	if key == "" {
+		return sec.Password()
+	}
+	v, _ := parseSecret(sec.Bytes()).Get(key)
+	return v
 }
 
 func hasChangeURL(name string) string {
@@ -323,19 +533,36 @@ func hasChangeURL(name string) string {
 	return ""
 }
 
-func (s *Action) generateReplaceExisting(ctx context.Context, name, key, password string, kvps map[string]string) (context.Context, error) {
-	sec, err := s.Store.Get(ctx, name)
+// generateReplaceExisting replaces the password of an existing secret and keeps
+// the rest of it. The old password is archived in the secret, unless disabled.
+func (s *Action) generateReplaceExisting(ctx context.Context, name, password string, kvps map[string]string, archive bool) (gopass.Secret, error) {
+	sec, err := s.generateGetExisting(ctx, name)
 	if err != nil {
-		return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
+		return nil, ExitError(ExitEncrypt, err, "failed to read %q: %s", name, err)
 	}
 
 	setMetadata(sec, kvps)
-	sec.SetPassword(password)
-	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Generated password for YAML key"), name, sec); err != nil {
-		return ctx, ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
+	if old := sec.Password(); archive && old != password {
+		if err := secrets.ArchivePassword(sec, old, time.Now(), s.cfg.OldPasswords); err != nil {
+			out.Warningf(ctx, "Failed to keep the old password of %s: %s", name, err)
+		}
 	}
+	sec.SetPassword(password)
 
-	return ctx, nil
+	return sec, nil
+}
+
+// generateGetExisting returns the secret to update. Links are only updated
+// after the user chose to break them, they are replaced by a new secret.
+func (s *Action) generateGetExisting(ctx context.Context, name string) (gopass.Secret, error) {
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, found := link.Target(sec); found {
+		return secrets.New(), nil
+	}
+	return sec, nil
 }
 
 func setMetadata(sec gopass.Secret, kvps map[string]string) {
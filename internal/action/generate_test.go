@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
@@ -13,6 +14,9 @@ import (
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/urfave/cli/v2"
 
@@ -21,8 +25,15 @@ import (
 )
 
 func TestRuleLookup(t *testing.T) {
-	domain, _ := hasPwRuleForSecret("foo/amazon.de")
-	assert.Equal(t, "", domain)
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+
+	_, _, found := act.Store.PasswordRule(ctx, "foo/amazon.de")
+	assert.False(t, found)
 }
 
 func TestGenerate(t *testing.T) {
@@ -151,6 +162,83 @@ func TestGenerate(t *testing.T) {
 		buf.Reset()
 	})
 
+	// generate --force --memorable --words 5 --digit --print foobar
+	t.Run("generate --force --memorable --words 5 --digit --print foobar", func(t *testing.T) {
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "memorable": "true", "words": "5", "digit": "true", "print": "true", "sep": "-", "lang": "en"}, "foobar")))
+		assert.Contains(t, buf.String(), "Passphrase of 5 words with ~")
+		sec, err := act.Store.Get(ctx, "foobar")
+		require.NoError(t, err)
+		words := strings.Split(sec.Password(), "-")
+		assert.Len(t, words, 5)
+		assert.Regexp(t, `[0-9]$`, words[4])
+		buf.Reset()
+	})
+
+	t.Run("generate --force --generator pronounceable --capitalize --digit foobar 14", func(t *testing.T) {
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "generator": "pronounceable", "capitalize": "true", "digit": "true"}, "foobar", "14")))
+		assert.Contains(t, buf.String(), "Pronounceable password of 14 characters with ~")
+		sec, err := act.Store.Get(ctx, "foobar")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 14)
+		assert.Regexp(t, `^[a-z]*[A-Z][a-z]*$`, strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return -1
+			}
+			return r
+		}, sec.Password()))
+		assert.Regexp(t, `[0-9]`, sec.Password())
+		buf.Reset()
+	})
+
+	t.Run("generate.mode pronounceable", func(t *testing.T) {
+		act.cfg.GenerateMode = "pronounceable"
+		defer func() {
+			act.cfg.GenerateMode = ""
+		}()
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "foobar", "10")))
+		assert.Contains(t, buf.String(), "Pronounceable password of 10 characters")
+		sec, err := act.Store.Get(ctx, "foobar")
+		require.NoError(t, err)
+		assert.Regexp(t, `^[a-z]{10}$`, sec.Password())
+		buf.Reset()
+	})
+
+	t.Run("generate --force --no-digits --no-uppercase --no-ambiguous --symbols=#% foobar 12", func(t *testing.T) {
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true", "no-digits": "true", "no-uppercase": "true", "no-ambiguous": "true", "symbols": "#%"}, "foobar", "12")))
+		sec, err := act.Store.Get(ctx, "foobar")
+		require.NoError(t, err)
+		assert.Regexp(t, `^[a-km-z#%]{12}$`, sec.Password())
+		assert.Regexp(t, `[#%]`, sec.Password())
+		buf.Reset()
+	})
+
+	t.Run("generate --force --strict --symbols foobar 3", func(t *testing.T) {
+		err := act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "strict": "true", "symbols": "true"}, "foobar", "3"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "can not contain all 4 character classes")
+		buf.Reset()
+	})
+
+	t.Run("generate with custom password rules", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir(""), ".pwrules"), []byte("example.com minlength: 8; maxlength: 10; required: digit; allowed: lower;\n"), 0600))
+		defer func() {
+			_ = os.Remove(filepath.Join(u.StoreDir(""), ".pwrules"))
+		}()
+
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true"}, "web/example.com/user", "32")))
+		assert.Contains(t, buf.String(), "Using password rules for example.com")
+		sec, err := act.Store.Get(ctx, "web/example.com/user")
+		require.NoError(t, err)
+		assert.Regexp(t, `^[a-z0-9]{10}$`, sec.Password())
+		assert.Regexp(t, `[0-9]`, sec.Password())
+		buf.Reset()
+
+		err = act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "strict": "true"}, "web/example.com/user", "32"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "conflicts with the password rules for example.com")
+		buf.Reset()
+	})
+
 	// generate --force foobar 24 w/ autoclip and output redirection
 	t.Run("generate --force foobar 24", func(t *testing.T) {
 		ov := act.cfg.AutoClip
@@ -176,6 +264,140 @@ func TestGenerate(t *testing.T) {
 		assert.Contains(t, buf.String(), "Copied to clipboard")
 		buf.Reset()
 	})
+
+	// generate --force --clip=false foobar 24 w/ autoclip
+	t.Run("generate --force --clip=false foobar 24", func(t *testing.T) {
+		ov := act.cfg.AutoClip
+		defer func() {
+			act.cfg.AutoClip = ov
+		}()
+		act.cfg.AutoClip = true
+		ctx := ctxutil.WithTerminal(ctx, true)
+		assert.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "clip": "false"}, "foobar", "24")))
+		assert.NotContains(t, buf.String(), "Copied to clipboard")
+		assert.Contains(t, buf.String(), "Not printing secrets by default")
+		buf.Reset()
+	})
+}
+
+func TestGenerateArchive(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.AutoClip = false
+	act.cfg.OldPasswords = 2
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	var sec gopass.Secret = secrets.NewKV()
+	sec.SetPassword("first")
+	require.NoError(t, sec.Set("user", "jane"))
+	require.NoError(t, sec.Set("totp", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, act.Store.Set(ctx, "site", sec))
+
+	generate := func(flags map[string]string) gopass.Secret {
+		t.Helper()
+		flags["force"] = "true"
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, flags, "site", "24")))
+		sec, err := act.Store.Get(ctx, "site")
+		require.NoError(t, err)
+		buf.Reset()
+		return sec
+	}
+
+	sec = generate(map[string]string{})
+	assert.Len(t, sec.Password(), 24)
+	user, _ := sec.Get("user")
+	assert.Equal(t, "jane", user)
+	totp, _ := sec.Get("totp")
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", totp)
+	old := secrets.OldPasswords(sec)
+	require.Len(t, old, 1)
+	pw, _ := sec.Get(old[0])
+	assert.Equal(t, "first", pw)
+
+	// only the most recent passwords are kept
+	second := sec.Password()
+	sec = generate(map[string]string{})
+	third := sec.Password()
+	sec = generate(map[string]string{})
+	old = secrets.OldPasswords(sec)
+	require.Len(t, old, 2)
+	pw, _ = sec.Get(old[0])
+	assert.Equal(t, second, pw)
+	pw, _ = sec.Get(old[1])
+	assert.Equal(t, third, pw)
+
+	sec = generate(map[string]string{"no-archive": "true"})
+	assert.Len(t, secrets.OldPasswords(sec), 2)
+	user, _ = sec.Get("user")
+	assert.Equal(t, "jane", user)
+}
+
+func TestGenerateEdit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test editor is a shell script")
+	}
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.AutoClip = false
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	ed := filepath.Join(u.Dir, "editor.sh")
+	require.NoError(t, os.WriteFile(ed, []byte("#!/bin/sh\necho 'user: jane' >> \"$1\"\n"), 0700))
+
+	t.Run("editor aborted", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"edit": "true", "editor": "false"}, "site/login", "24")))
+		assert.False(t, act.Store.Exists(ctx, "site/login"))
+	})
+
+	t.Run("content removed", func(t *testing.T) {
+		defer buf.Reset()
+		empty := filepath.Join(u.Dir, "empty.sh")
+		require.NoError(t, os.WriteFile(empty, []byte("#!/bin/sh\n: > \"$1\"\n"), 0700))
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"edit": "true", "editor": empty}, "site/login", "24")))
+		assert.False(t, act.Store.Exists(ctx, "site/login"))
+	})
+
+	t.Run("edited", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"edit": "true", "editor": ed}, "site/login", "24")))
+		sec, err := act.Store.Get(ctx, "site/login")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 24)
+		user, _ := parseSecret(sec.Bytes()).Get("user")
+		assert.Equal(t, "jane", user)
+	})
 }
 
 func passIsAlphaNum(t *testing.T, buf string, want bool) {
@@ -190,6 +412,13 @@ func passIsAlphaNum(t *testing.T, buf string, want bool) {
 	}
 }
 
+func TestParseSymbols(t *testing.T) {
+	assert.Equal(t, pwgen.Syms, parseSymbols("true", ""))
+	assert.Equal(t, "#%", parseSymbols("true", "#%"))
+	assert.Equal(t, "", parseSymbols("false", "#%"))
+	assert.Equal(t, "!?", parseSymbols("!?", "#%"))
+}
+
 func TestKeyAndLength(t *testing.T) {
 	app := cli.NewApp()
 
@@ -0,0 +1,245 @@
+package action
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/urfave/cli/v2"
+)
+
+// gitCredential is a credential description as exchanged with git, see
+// git-credential(1)
+type gitCredential struct {
+	Protocol string
+	Host     string
+	Path     string
+	Username string
+	Password string
+}
+
+// parseGitCredential reads a credential description. It ends at the first
+// empty line or at EOF. Unknown attributes are ignored.
+func parseGitCredential(r io.Reader) (*gitCredential, error) {
+	cred := &gitCredential{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		p := strings.SplitN(line, "=", 2)
+		if len(p) < 2 {
+			return nil, fmt.Errorf("invalid line %q", line)
+		}
+		switch p[0] {
+		case "protocol":
+			cred.Protocol = p[1]
+		case "host":
+			cred.Host = p[1]
+		case "path":
+			cred.Path = p[1]
+		case "username":
+			cred.Username = p[1]
+		case "password":
+			cred.Password = p[1]
+		default:
+			debug.Log("ignoring git credential attribute %q", p[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if cred.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	// the host and the username are parts of the secret name, they must not
+	// point outside of the prefix, e.g. with host=../prod
+	for _, kv := range [][2]string{{"host", cred.Host}, {"username", cred.Username}} {
+		if kv[1] == "" {
+			continue
+		}
+		if strings.ContainsAny(kv[1], `/\`) {
+			return nil, fmt.Errorf("invalid %s %q: must not contain / or \\", kv[0], kv[1])
+		}
+		if err := store.ValidateName(kv[1]); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", kv[0], err)
+		}
+	}
+	return cred, nil
+}
+
+// WriteTo writes the credential description in the format expected by git.
+// Empty attributes are omitted.
+func (g *gitCredential) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for _, kv := range [][2]string{
+		{"protocol", g.Protocol},
+		{"host", g.Host},
+		{"path", g.Path},
+		{"username", g.Username},
+		{"password", g.Password},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		written, err := fmt.Fprintf(w, "%s=%s\n", kv[0], kv[1])
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// GitCredentialGet implements the get operation of the git credential helper.
+// It prints nothing if no or more than one matching secret exists.
+func (s *Action) GitCredentialGet(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	cred, err := s.gitCredentialRead(ctx)
+	if err != nil {
+		return err
+	}
+
+	name, err := s.gitCredentialLookup(ctx, cred)
+	if err != nil || name == "" {
+		return err
+	}
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", name, err)
+	}
+
+	res := &gitCredential{
+		Username: path.Base(name),
+		Password: sec.Password(),
+	}
+	if username, found := sec.Get("username"); found && username != "" {
+		res.Username = username
+	}
+	if _, err := res.WriteTo(stdout); err != nil {
+		return ExitError(ExitIO, err, "failed to write credentials: %s", err)
+	}
+	return nil
+}
+
+// GitCredentialStore implements the store operation of the git credential
+// helper. It creates or updates the secret of the username on the host.
+func (s *Action) GitCredentialStore(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	cred, err := s.gitCredentialRead(ctx)
+	if err != nil {
+		return err
+	}
+	if cred.Username == "" || cred.Password == "" {
+		debug.Log("not storing incomplete credentials for %q", cred.Host)
+		return nil
+	}
+
+	name := s.gitCredentialName(cred)
+	var sec *secrets.KV
+	if s.Store.Exists(ctx, name) {
+		old, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", name, err)
+		}
+		sec, err = secrets.ParseKV(old.Bytes())
+		if err != nil {
+			return ExitError(ExitDecrypt, err, "failed to parse secret %q: %s", name, err)
+		}
+	} else {
+		sec = secrets.NewKV()
+	}
+	if sec.Password() == cred.Password {
+		if u, _ := sec.Get("username"); u == cred.Username {
+			debug.Log("credentials for %q unchanged", name)
+			return nil
+		}
+	}
+	sec.SetPassword(cred.Password)
+	sec.Set("username", cred.Username)
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Saved git credentials for %s", cred.Host)), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to save secret %q: %s", name, err)
+	}
+	return nil
+}
+
+// GitCredentialErase implements the erase operation of the git credential
+// helper. It removes the matching secret without asking.
+func (s *Action) GitCredentialErase(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	cred, err := s.gitCredentialRead(ctx)
+	if err != nil {
+		return err
+	}
+
+	name, err := s.gitCredentialLookup(ctx, cred)
+	if err != nil || name == "" {
+		return err
+	}
+	if err := s.Store.Delete(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Removed git credentials for %s", cred.Host)), name); err != nil {
+		return ExitError(ExitIO, err, "failed to remove secret %q: %s", name, err)
+	}
+	return nil
+}
+
+// gitCredentialRead reads the credential description git writes to stdin
+func (s *Action) gitCredentialRead(ctx context.Context) (*gitCredential, error) {
+	if !ctxutil.IsStdin(ctx) {
+		return nil, ExitError(ExitUsage, nil, "%s git-credential must be called by git. Run 'git config credential.helper \"!%s git-credential\"'", s.Name, s.Name)
+	}
+	cred, err := parseGitCredential(stdin)
+	if err != nil {
+		return nil, ExitError(ExitUsage, err, "failed to read credential description: %s", err)
+	}
+	return cred, nil
+}
+
+// gitCredentialName returns the name of the secret holding the credentials
+// of the username on the host
+func (s *Action) gitCredentialName(cred *gitCredential) string {
+	name := path.Join(s.cfg.GetGitCredentialPrefix(), cred.Host)
+	if cred.Username != "" {
+		name = path.Join(name, cred.Username)
+	}
+	return name
+}
+
+// gitCredentialLookup returns the secret matching the credential description.
+// Without a username the host must have exactly one secret, otherwise an
+// empty name is returned.
+func (s *Action) gitCredentialLookup(ctx context.Context, cred *gitCredential) (string, error) {
+	name := s.gitCredentialName(cred)
+	if cred.Username != "" {
+		if !s.Store.Exists(ctx, name) {
+			debug.Log("no git credentials at %q", name)
+			return "", nil
+		}
+		return name, nil
+	}
+
+	t, err := s.Store.Tree(ctx)
+	if err != nil {
+		return "", ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+	sub, err := t.FindFolder(name)
+	if err != nil {
+		debug.Log("no git credentials below %q", name)
+		return "", nil
+	}
+	entries := sub.List(tree.INF)
+	if len(entries) != 1 {
+		debug.Log("found %d git credentials below %q, need a username", len(entries), name)
+		return "", nil
+	}
+	return entries[0], nil
+}
@@ -0,0 +1,145 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestGitCredential(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithStdin(ctx, true)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	run := func(t *testing.T, fn func(*cli.Context) error, in string) string {
+		t.Helper()
+		buf.Reset()
+		stdin = strings.NewReader(in)
+		require.NoError(t, fn(gptest.CliCtx(ctx, t)))
+		return buf.String()
+	}
+
+	t.Run("get unknown host", func(t *testing.T) {
+		assert.Equal(t, "", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\n\n"))
+	})
+
+	t.Run("store and get", func(t *testing.T) {
+		assert.Equal(t, "", run(t, act.GitCredentialStore, "protocol=https\nhost=example.org\nusername=alice\npassword=s3cret\n\n"))
+		assert.True(t, act.Store.Exists(ctx, "git/example.org/alice"))
+
+		assert.Equal(t, "username=alice\npassword=s3cret\n", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\n\n"))
+		assert.Equal(t, "username=alice\npassword=s3cret\n", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\nusername=alice\n\n"))
+		assert.Equal(t, "", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\nusername=bob\n\n"))
+	})
+
+	t.Run("store updates the password", func(t *testing.T) {
+		run(t, act.GitCredentialStore, "protocol=https\nhost=example.org\nusername=alice\npassword=n3w\n\n")
+		assert.Equal(t, "username=alice\npassword=n3w\n", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\nusername=alice\n\n"))
+	})
+
+	t.Run("several accounts on one host", func(t *testing.T) {
+		run(t, act.GitCredentialStore, "protocol=https\nhost=example.org\nusername=bob\npassword=hunter2\n\n")
+
+		assert.Equal(t, "", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\n\n"))
+		assert.Equal(t, "username=bob\npassword=hunter2\n", run(t, act.GitCredentialGet, "protocol=https\nhost=example.org\nusername=bob\n\n"))
+
+		// erase must not guess either
+		run(t, act.GitCredentialErase, "protocol=https\nhost=example.org\n\n")
+		assert.True(t, act.Store.Exists(ctx, "git/example.org/alice"))
+		assert.True(t, act.Store.Exists(ctx, "git/example.org/bob"))
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		assert.Equal(t, "", run(t, act.GitCredentialErase, "protocol=https\nhost=example.org\nusername=bob\npassword=hunter2\n\n"))
+		assert.False(t, act.Store.Exists(ctx, "git/example.org/bob"))
+		assert.True(t, act.Store.Exists(ctx, "git/example.org/alice"))
+	})
+
+	t.Run("custom prefix", func(t *testing.T) {
+		act.cfg.GitCredentialPrefix = "dev/git"
+		defer func() {
+			act.cfg.GitCredentialPrefix = ""
+		}()
+
+		run(t, act.GitCredentialStore, "protocol=https\nhost=git.example.com\nusername=carol@example.com\npassword=pw\n\n")
+		assert.True(t, act.Store.Exists(ctx, "dev/git/git.example.com/carol@example.com"))
+		assert.Equal(t, "username=carol@example.com\npassword=pw\n", run(t, act.GitCredentialGet, "host=git.example.com\n"))
+	})
+
+	t.Run("missing host", func(t *testing.T) {
+		stdin = strings.NewReader("protocol=https\n\n")
+		assert.Error(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("names outside of the prefix", func(t *testing.T) {
+		sec := secrets.NewKV()
+		sec.SetPassword("hunter2")
+		require.NoError(t, act.Store.Set(ctx, "prod/db", sec))
+		for _, in := range []string{
+			"host=../prod\nusername=db\n",
+			"host=..\nusername=prod\n",
+			"host=example.org\nusername=../../prod/db\n",
+			"host=example.org\\..\nusername=db\n",
+			"host=example.org\nusername=.\n",
+		} {
+			stdin = strings.NewReader(in)
+			assert.Error(t, act.GitCredentialErase(gptest.CliCtx(ctx, t)), in)
+		}
+		assert.True(t, act.Store.Exists(ctx, "prod/db"))
+	})
+
+	t.Run("not called by git", func(t *testing.T) {
+		ctx := ctxutil.WithStdin(ctx, false)
+		assert.Error(t, act.GitCredentialGet(gptest.CliCtx(ctx, t)))
+	})
+}
+
+func TestParseGitCredential(t *testing.T) {
+	t.Parallel()
+
+	in := "protocol=https\nhost=example.org:8443\npath=foo.git\nusername=alice\npassword=a=b\nwwwauth[]=Basic\n\nignored=true\n"
+	cred, err := parseGitCredential(strings.NewReader(in))
+	require.NoError(t, err)
+	assert.Equal(t, &gitCredential{
+		Protocol: "https",
+		Host:     "example.org:8443",
+		Path:     "foo.git",
+		Username: "alice",
+		Password: "a=b",
+	}, cred)
+
+	buf := &bytes.Buffer{}
+	_, err = cred.WriteTo(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "protocol=https\nhost=example.org:8443\npath=foo.git\nusername=alice\npassword=a=b\n", buf.String())
+
+	_, err = parseGitCredential(strings.NewReader("host\n"))
+	assert.Error(t, err)
+}
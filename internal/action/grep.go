@@ -4,7 +4,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/store/decrypt"
 	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -13,6 +16,14 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// grepLine is a single line of a secret along with the name of its key. The
+// first line is the password, lines without a key belong to the body.
+type grepLine struct {
+	key   string
+	value string
+	raw   string
+}
+
 // Grep searches a string inside the content of all files
 func (s *Action) Grep(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
@@ -23,35 +34,79 @@ func (s *Action) Grep(c *cli.Context) error {
 	// get the search term
 	needle := c.Args().First()
 
+	pattern := needle
+	if !c.Bool("regexp") {
+		pattern = regexp.QuoteMeta(needle)
+	}
+	if c.Bool("ignore-case") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to compile regexp %q: %s", needle, err)
+	}
+
 	haystack, err := s.Store.List(ctx, tree.INF)
 	if err != nil {
 		return ExitError(ExitList, err, "failed to list store: %s", err)
 	}
 
-	matchFn := func(haystack string) bool {
-		return strings.Contains(haystack, needle)
+	if store := c.String("store"); store != "" {
+		if _, found := s.Store.Mounts()[store]; !found {
+			return ExitError(ExitMount, nil, "store %q is not mounted. See '%s mounts'", store, s.Name)
+		}
+		filtered := make([]string, 0, len(haystack))
+		for _, name := range haystack {
+			if s.Store.MountPoint(name) == store {
+				filtered = append(filtered, name)
+			}
+		}
+		haystack = filtered
 	}
 
-	if c.Bool("regexp") {
-		re, err := regexp.Compile(needle)
-		if err != nil {
-			return ExitError(ExitUsage, err, "failed to compile regexp %q: %s", needle, err)
-		}
-		matchFn = re.MatchString
+	key := c.String("key")
+	unsafe := c.Bool("unsafe")
+	highlight := color.New(color.FgRed, color.Bold).SprintFunc()
+
+	if err := s.warmup(ctx, c, haystack); err != nil {
+		return err
 	}
 
 	var matches int
 	var errors int
-	for _, v := range haystack {
-		sec, err := s.Store.Get(ctx, v)
-		if err != nil {
-			out.Errorf(ctx, "failed to decrypt %s: %v", v, err)
-			continue
+	if err := decrypt.All(withJobs(ctx, c), s.Store, haystack, func(r decrypt.Result) error {
+		if r.Err != nil {
+			out.Errorf(ctx, "failed to decrypt %s: %v", r.Name, r.Err)
+			errors++
+			return nil
 		}
 
-		if matchFn(string(sec.Bytes())) {
-			out.Printf(ctx, "%s matches", color.BlueString(v))
+		if secrets.IsAttachment(r.Secret) {
+			out.Warningf(ctx, "Skipping binary secret %s", r.Name)
+			return nil
+		}
+
+		var found bool
+		for _, l := range grepLines(r.Secret, key) {
+			if !re.MatchString(l.raw) {
+				continue
+			}
+			found = true
+
+			value := re.ReplaceAllStringFunc(l.value, func(m string) string {
+				return highlight(m)
+			})
+			if l.key == "password" && key == "" && !unsafe {
+				value = "*****"
+			}
+			out.Printf(ctx, "%s:%s: %s", color.BlueString(r.Name), l.key, value)
 		}
+		if found {
+			matches++
+		}
+		return nil
+	}); err != nil {
+		return ExitError(ExitAborted, err, "aborted: %s", err)
 	}
 
 	if errors > 0 {
@@ -60,3 +115,44 @@ func (s *Action) Grep(c *cli.Context) error {
 	out.Printf(ctx, "\nScanned %d secrets. %d matches, %d errors", len(haystack), matches, errors)
 	return nil
 }
+
+// grepLines returns the lines of the secret to search. If a key is given
+// only the values of this key are returned.
+func grepLines(sec gopass.Secret, key string) []grepLine {
+	if key != "" {
+		values, _ := sec.Values(key)
+		lines := make([]grepLine, 0, len(values))
+		for _, v := range values {
+			lines = append(lines, grepLine{key: key, value: v, raw: v})
+		}
+		return lines
+	}
+
+	raw := strings.Split(string(sec.Bytes()), "\n")
+	lines := make([]grepLine, 0, len(raw))
+	for i, line := range raw {
+		if i == 0 {
+			lines = append(lines, grepLine{key: "password", value: line, raw: line})
+			continue
+		}
+		if k, v, ok := grepParseLine(line); ok {
+			lines = append(lines, grepLine{key: k, value: v, raw: line})
+			continue
+		}
+		lines = append(lines, grepLine{key: "body", value: line, raw: line})
+	}
+	return lines
+}
+
+// grepParseLine splits a key-value line like the KV secret does
+func grepParseLine(line string) (string, string, bool) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	k := strings.ToLower(strings.TrimSpace(parts[0]))
+	if k == "" {
+		return "", "", false
+	}
+	return k, strings.TrimSpace(parts[1]), true
+}
@@ -3,6 +3,7 @@ package action
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
@@ -49,6 +50,58 @@ func TestGrep(t *testing.T) {
 	t.Run("should find existing", func(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "foo:password: *****\n")
+		assert.Contains(t, buf.String(), "foo:body: foobar\n")
+		assert.NotContains(t, buf.String(), "foo:password: foobar")
+	})
+
+	t.Run("unsafe shows the password", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"unsafe": "true"}, "foo")
+		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "foo:password: foobar\n")
+	})
+
+	t.Run("key and ignore case", func(t *testing.T) {
+		defer buf.Reset()
+		sec := secrets.NewKV()
+		sec.SetPassword("hunter2")
+		require.NoError(t, sec.Set("user", "Alice"))
+		require.NoError(t, sec.Set("url", "alice.example.org"))
+		require.NoError(t, act.Store.Set(ctx, "web/alice", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"key": "user"}, "alice")
+		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "0 matches")
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"key": "user", "ignore-case": "true"}, "alice")
+		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "web/alice:user: Alice\n")
+		assert.NotContains(t, buf.String(), "web/alice:url:")
+		assert.Contains(t, buf.String(), "1 matches")
+		buf.Reset()
+
+		c = gptest.CliCtx(ctx, t, "alice")
+		assert.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "web/alice:url: alice.example.org\n")
+		assert.NotContains(t, buf.String(), "web/alice:user:")
+	})
+
+	t.Run("binary secrets are skipped", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Store.Set(ctx, "files/foo.bin", secFromBytes("files/foo.bin", "foo.bin", []byte("foobar"))))
+
+		c := gptest.CliCtx(ctx, t, "Zm9v")
+		assert.NoError(t, act.Grep(c))
+		assert.NotContains(t, buf.String(), "files/foo.bin:")
+		assert.Contains(t, buf.String(), "0 matches")
+	})
+
+	t.Run("invalid store", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "nope"}, "foo")
+		assert.Error(t, act.Grep(c))
 	})
 
 	t.Run("RE2", func(t *testing.T) {
@@ -56,4 +109,43 @@ func TestGrep(t *testing.T) {
 		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"regexp": "true"}, "f..bar")
 		assert.NoError(t, act.Grep(c))
 	})
+
+	t.Run("concurrent output matches sequential", func(t *testing.T) {
+		defer buf.Reset()
+		for i := 0; i < 20; i++ {
+			sec := &secrets.Plain{}
+			sec.SetPassword(fmt.Sprintf("secret%d", i))
+			if i%3 == 0 {
+				sec.WriteString("foobar")
+			}
+			require.NoError(t, act.Store.Set(ctx, fmt.Sprintf("many/%02d", i), sec))
+		}
+		buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"jobs": "1"}, "foobar")
+		require.NoError(t, act.Grep(c))
+		sequential := buf.String()
+		assert.Contains(t, sequential, "8 matches, 0 errors")
+		buf.Reset()
+
+		c = gptest.CliCtxWithFlags(ctx, t, map[string]string{"jobs": "4"}, "foobar")
+		require.NoError(t, act.Grep(c))
+		assert.Equal(t, sequential, buf.String())
+	})
+
+	t.Run("store", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, u.InitStore("work"))
+		require.NoError(t, act.Store.AddMount(ctx, "work", u.StoreDir("work")))
+		sec := &secrets.Plain{}
+		sec.SetPassword("secret")
+		sec.WriteString("foobar")
+		require.NoError(t, act.Store.Set(ctx, "work/foo", sec))
+		buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "work"}, "foobar")
+		require.NoError(t, act.Grep(c))
+		assert.Contains(t, buf.String(), "work/foo:body: foobar\n")
+		assert.Contains(t, buf.String(), "Scanned 1 secrets. 1 matches")
+	})
 }
@@ -1,11 +1,16 @@
 package action
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
 	"github.com/urfave/cli/v2"
 )
@@ -24,9 +29,13 @@ func (s *Action) History(c *cli.Context) error {
 		return ExitError(ExitNotFound, nil, "Secret not found")
 	}
 
+	if c.Bool("diff") {
+		return s.historyDiff(ctx, c, name)
+	}
+
 	revs, err := s.Store.ListRevisions(ctx, name)
 	if err != nil {
-		return ExitError(ExitUnknown, err, "Failed to get revisions: %s", err)
+		return ExitError(ExitGit, err, "Failed to get revisions: %s", err)
 	}
 
 	for _, rev := range revs {
@@ -44,3 +53,55 @@ func (s *Action) History(c *cli.Context) error {
 	}
 	return nil
 }
+
+// historyDiff compares two revisions of a secret. Only the names of the
+// changed fields are printed, unless --unsafe is given.
+func (s *Action) historyDiff(ctx context.Context, c *cli.Context, name string) error {
+	if c.Args().Len() < 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s history --diff <NAME> <REVISION> [<REVISION>]", s.Name)
+	}
+
+	from, err := s.historyRevision(ctx, name, c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	// compare with the current content by default
+	to, err := s.historyRevision(ctx, name, c.Args().Get(2))
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range secrets.Diff(from, to) {
+		if c.Bool("unsafe") && fc.State != secrets.Unchanged {
+			out.Printf(ctx, "%s: %s (%q -> %q)", fc.Field, fc.State, fc.Old, fc.New)
+			continue
+		}
+		out.Printf(ctx, "%s: %s", fc.Field, fc.State)
+	}
+	return nil
+}
+
+// historyRevision returns the secret at the given revision or the current
+// secret if no revision is given
+func (s *Action) historyRevision(ctx context.Context, name, revision string) (gopass.Secret, error) {
+	if revision == "" {
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return nil, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
+		}
+		return sec, nil
+	}
+
+	rev, err := s.parseRevision(ctx, name, revision)
+	if err != nil {
+		return nil, ExitError(ExitGit, err, "Failed to get revisions: %s", err)
+	}
+	_, sec, err := s.Store.GetRevision(ctx, name, rev)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ExitError(ExitNotFound, err, "Secret %q did not exist at revision %s", name, revision)
+	}
+	if err != nil {
+		return nil, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to get revision %s of %s: %s", revision, name, err)
+	}
+	return sec, nil
+}
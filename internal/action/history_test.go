@@ -10,6 +10,7 @@ import (
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/gopass/tests/gptest"
 
@@ -70,4 +71,37 @@ func TestHistory(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "bar")))
 	})
+
+	t.Run("update bar", func(t *testing.T) {
+		defer buf.Reset()
+		sec := secrets.New()
+		sec.SetPassword("n3wpassw0rd")
+		require.NoError(t, sec.Set("user", "jane"))
+		require.NoError(t, act.Store.Set(ctx, "bar", sec))
+	})
+
+	t.Run("history --diff bar", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"diff": "true"}, "bar", "HEAD~1")))
+		assert.Equal(t, "Password: changed\nuser: added\nBody: unchanged\n", buf.String())
+		assert.NotContains(t, buf.String(), "n3wpassw0rd")
+	})
+
+	t.Run("history --diff --unsafe bar", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"diff": "true", "unsafe": "true"}, "bar", "HEAD~1", "HEAD")))
+		assert.Contains(t, buf.String(), `-> "n3wpassw0rd"`)
+	})
+
+	t.Run("history --diff without revision", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.History(gptest.CliCtxWithFlags(ctx, t, map[string]string{"diff": "true"}, "bar")))
+	})
+
+	t.Run("show revision before bar existed", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"revision": "HEAD~2"}, "bar"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "did not exist at revision HEAD~2")
+	})
 }
@@ -0,0 +1,174 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/importer"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	importSkip      = "skip"
+	importOverwrite = "overwrite"
+	importRename    = "rename"
+)
+
+// importItem is a single entry of the import along with the name it is
+// imported as
+type importItem struct {
+	name   string
+	entry  importer.Entry
+	exists bool
+}
+
+// Import imports secrets from the export of another password manager
+func (s *Action) Import(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	src := c.Args().First()
+	format := c.String("format")
+	if src == "" || format == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s import --format {%s} <file|dir>", s.Name, strings.Join(append(importer.Formats(), "archive"), "|"))
+	}
+
+	conflict, err := importConflict(c.String("conflict"))
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.Trim(c.String("prefix"), "/")
+	if err := s.Store.CheckWritable(prefix); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
+	var entries []importer.Entry
+	if format == "archive" {
+		entries, err = s.importArchive(ctx, src, c.String("identity"))
+	} else {
+		entries, err = importer.Read(ctx, format, src, s.Store.Crypto(ctx, prefix))
+	}
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read %s: %s", src, err)
+	}
+
+	items := s.importPlan(ctx, entries, prefix, conflict)
+	if c.Bool("dry-run") {
+		return s.importPrint(ctx, items, conflict)
+	}
+
+	return s.importSave(ctx, items, conflict, format)
+}
+
+// importSave saves the planned secrets, those that already exist are skipped
+// if requested
+func (s *Action) importSave(ctx context.Context, items []importItem, conflict, format string) error {
+	var imported, skipped int
+	for _, item := range items {
+		if item.exists && conflict == importSkip {
+			out.Warningf(ctx, "Skipping %s, it already exists", item.name)
+			skipped++
+			continue
+		}
+
+		debug.Log("importing %s as %s", item.entry.Name, item.name)
+		ctx := ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Imported %s from %s", item.name, format))
+		if err := s.Store.Set(ctx, item.name, importSecret(item.name, item.entry)); err != nil {
+			return ExitError(ExitEncrypt, err, "failed to save %s: %s", item.name, err)
+		}
+		imported++
+	}
+
+	out.OKf(ctx, "Imported %d secrets, skipped %d", imported, skipped)
+	return nil
+}
+
+// importConflict validates the value of --conflict
+func importConflict(conflict string) (string, error) {
+	switch conflict {
+	case "":
+		return importSkip, nil
+	case importSkip, importOverwrite, importRename:
+		return conflict, nil
+	default:
+		return "", ExitError(ExitUsage, nil, "invalid value %q for --conflict, use one of skip, overwrite or rename", conflict)
+	}
+}
+
+// importPlan determines the name of every entry. Entries that already exist
+// in the store or earlier in the import are renamed if requested.
+func (s *Action) importPlan(ctx context.Context, entries []importer.Entry, prefix, conflict string) []importItem {
+	seen := make(map[string]bool, len(entries))
+	exists := func(name string) bool {
+		return seen[name] || s.Store.Exists(ctx, name)
+	}
+
+	items := make([]importItem, 0, len(entries))
+	for _, e := range entries {
+		name := path.Join(prefix, e.Name)
+		item := importItem{name: name, entry: e, exists: exists(name)}
+		if item.exists && conflict == importRename {
+			for i := 1; exists(item.name); i++ {
+				item.name = fmt.Sprintf("%s-%d", name, i)
+			}
+			item.exists = false
+		}
+		seen[item.name] = true
+		items = append(items, item)
+	}
+	return items
+}
+
+// importPrint prints the secrets that would be imported as a tree
+func (s *Action) importPrint(ctx context.Context, items []importItem, conflict string) error {
+	root := tree.New("gopass")
+	var n int
+	for _, item := range items {
+		if item.exists && conflict == importSkip {
+			out.Printf(ctx, "Would skip %s, it already exists", item.name)
+			continue
+		}
+		if item.exists {
+			out.Printf(ctx, "Would overwrite %s", item.name)
+		}
+		if err := root.AddFile(item.name, ""); err != nil {
+			return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to add %s to the tree: %s", item.name, err)
+		}
+		n++
+	}
+
+	fmt.Fprintln(stdout, root.Format(tree.INF))
+	out.Printf(ctx, "Would import %s secrets", color.GreenString(fmt.Sprintf("%d", n)))
+	return nil
+}
+
+// importSecret converts an entry to a secret
+func importSecret(name string, e importer.Entry) gopass.Secret {
+	if e.Raw != nil {
+		return secrets.ParsePlain(e.Raw)
+	}
+	if e.Binary != nil {
+		return secFromBytes(name, path.Base(name), e.Binary)
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword(e.Password)
+	for _, f := range e.Fields {
+		if err := sec.Add(f.Key, f.Value); err != nil {
+			debug.Log("failed to add %s to %s: %s", f.Key, name, err)
+		}
+	}
+	if e.Notes != "" {
+		_, _ = sec.Write([]byte(e.Notes))
+	}
+	return sec
+}
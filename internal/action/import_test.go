@@ -0,0 +1,126 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	bitwarden := filepath.Join("..", "importer", "testdata", "bitwarden.json")
+
+	t.Run("usage", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Import(gptest.CliCtx(ctx, t)))
+		assert.Error(t, act.Import(gptest.CliCtx(ctx, t, bitwarden)))
+		assert.Error(t, act.Import(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "conflict": "merge"}, bitwarden)))
+		assert.Error(t, act.Import(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "lastpass"}, bitwarden)))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "prefix": "bw", "dry-run": "true"}, bitwarden)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "gopass\n└── bw/\n    ├── Work/\n    │   └── Servers/\n")
+		assert.Contains(t, buf.String(), "Would import 4 secrets")
+		assert.False(t, act.Store.Exists(ctx, "bw/example.org"))
+	})
+
+	t.Run("import", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "prefix": "bw"}, bitwarden)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "Imported 4 secrets, skipped 0")
+
+		sec, err := act.Store.Get(ctx, "bw/example.org")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t\nusername: alice\nurl: https://example.org\nurl: https://login.example.org\ntotp: JBSWY3DPEHPK3PXP\npin: 1234\nsome notes", string(sec.Bytes()))
+		assert.True(t, act.Store.Exists(ctx, "bw/Work/Servers/db"))
+	})
+
+	t.Run("skip existing", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "prefix": "bw"}, bitwarden)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "Imported 0 secrets, skipped 4")
+	})
+
+	t.Run("rename existing", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "prefix": "bw", "conflict": "rename"}, bitwarden)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "Imported 4 secrets, skipped 0")
+		assert.True(t, act.Store.Exists(ctx, "bw/example.org-1"))
+		assert.True(t, act.Store.Exists(ctx, "bw/Work/Servers/db-1"))
+	})
+
+	t.Run("overwrite existing", func(t *testing.T) {
+		defer buf.Reset()
+		sec, err := act.Store.Get(ctx, "bw/note")
+		require.NoError(t, err)
+		sec.SetPassword("changed")
+		require.NoError(t, act.Store.Set(ctx, "bw/note", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "bitwarden-json", "prefix": "bw", "conflict": "overwrite"}, bitwarden)
+		require.NoError(t, act.Import(c))
+		assert.Contains(t, buf.String(), "Imported 4 secrets, skipped 0")
+
+		sec, err = act.Store.Get(ctx, "bw/note")
+		require.NoError(t, err)
+		assert.Equal(t, "", sec.Password())
+	})
+
+	t.Run("keepass attachments", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "keepassxml", "prefix": "kp"}, filepath.Join("..", "importer", "testdata", "keepass.xml"))
+		require.NoError(t, act.Import(c))
+
+		buf, err := act.binaryGet(ctx, "kp/example.org/key.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "hello attachment\n", string(buf))
+	})
+
+	t.Run("pass", func(t *testing.T) {
+		defer buf.Reset()
+		// the mock store uses the plain crypto backend
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "web"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "web", "example.org.gpg"), []byte("s3cr3t\nuser: alice\n"), 0600))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "pass", "prefix": "pass"}, dir)
+		require.NoError(t, act.Import(c))
+
+		sec, err := act.Store.Get(ctx, "pass/web/example.org")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t\nuser: alice\n", string(sec.Bytes()))
+	})
+}
@@ -34,7 +34,10 @@ func (s *Action) IsInitialized(c *cli.Context) error {
 	}
 	if inited {
 		debug.Log("Store is already initialized")
-		s.printReminder(ctx)
+		// structured output must not be mixed with reminders
+		if format, err := outputFormat(c); err == nil && !out.IsStructured(format) {
+			s.printReminder(ctx)
+		}
 		return nil
 	}
 
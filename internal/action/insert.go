@@ -5,9 +5,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
-	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/editor"
+	"github.com/gopasspw/gopass/internal/expiry"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -21,11 +25,19 @@ import (
 // Insert a string as content to a secret file
 func (s *Action) Insert(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = WithQuiet(ctx, c.Bool("quiet"))
+	ctx = WithUnsafe(ctx, c.Bool("unsafe"))
+	ctx = WithForceWeak(ctx, c.Bool("force-weak"))
 	echo := c.Bool("echo")
 	multiline := c.Bool("multiline")
 	force := c.Bool("force")
 	append := c.Bool("append")
 
+	// gopass insert --batch accounts.csv --map path=1,password=2
+	if file := c.String("batch"); file != "" {
+		return s.insertBatch(ctx, c, file, force)
+	}
+
 	args, kvps := parseArgs(c)
 	name := args.Get(0)
 	key := args.Get(1)
@@ -33,10 +45,125 @@ func (s *Action) Insert(c *cli.Context) error {
 	if name == "" {
 		return ExitError(ExitNoName, nil, "Usage: %s insert name", s.Name)
 	}
+	if err := s.Store.CheckWritable(name); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+	if err := s.confirmCollision(ctx, name, force); err != nil {
+		return err
+	}
+	if multiline && (key != "" || c.IsSet("key")) {
+		return ExitError(ExitUsage, nil, "--multiline inserts the whole secret and can not be used to set a key")
+	}
+	if c.IsSet("expires") {
+		exp, err := expiry.Parse(c.String("expires"), time.Now())
+		if err != nil {
+			return ExitError(ExitUsage, err, "%s", err)
+		}
+		ctx = WithExpires(ctx, expiry.Format(exp))
+	}
+
+	// gopass insert foo --key db.port 5432
+	if c.IsSet("key") {
+		key = c.String("key")
+		if value := args.Get(1); value != "" {
+			return s.insertYAML(ctx, name, key, []byte(value), kvps)
+		}
+	}
+
+	// gopass insert site/login --generate=24
+	if length, ok := generateLength(c); ok {
+		if key != "" || c.IsSet("key") || append {
+			return ExitError(ExitUsage, nil, "--generate creates the whole secret and can not be used to set a key or to append")
+		}
+		return s.insertGenerate(ctx, c, name, length, multiline, force, kvps)
+	}
 
 	return s.insert(ctx, c, name, key, echo, multiline, force, append, kvps)
 }
 
+// generateLength returns the length given with --generate and if a password
+// should be generated at all
+func generateLength(c *cli.Context) (string, bool) {
+	if !c.IsSet("generate") {
+		return "", false
+	}
+	v := c.String("generate")
+	if bv, err := strconv.ParseBool(v); err == nil {
+		return "", bv
+	}
+	return v, true
+}
+
+// insertGenerate creates or updates a secret with a generated password. The
+// username is asked for, with multiline the whole secret is edited instead.
+// Nothing is saved if the editor is aborted.
+func (s *Action) insertGenerate(ctx context.Context, c *cli.Context, name, length string, multiline, force bool, kvps map[string]string) error {
+	if !force && !ctxutil.IsDryRun(ctx) && s.Store.Exists(ctx, name) {
+		ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("An entry already exists for %s. Overwrite the current password?", name), "--force")
+		if err != nil {
+			return ExitError(ExitAborted, err, "not overwriting your current password: %s", err)
+		}
+		if !ok {
+			return ExitError(ExitAborted, nil, "user aborted. not overwriting your current password")
+		}
+	}
+
+	password, err := s.generatePassword(ctx, c, length, name)
+	if err != nil {
+		return err
+	}
+	sec, _, err := s.generateSecret(ctx, name, "", password, kvps, true)
+	if err != nil {
+		return err
+	}
+	msg := "Inserted generated password"
+
+	switch {
+	case multiline && ctxutil.IsInteractive(ctx):
+		nSec, ed, err := s.generateEdit(ctx, c, name, sec)
+		if err != nil {
+			return err
+		}
+		sec, msg = nSec, fmt.Sprintf("Inserted generated password with %s", ed)
+		password = sec.Password()
+	case ctxutil.IsInteractive(ctx) && kvps["username"] == "":
+		def, _ := sec.Get("username")
+		user, err := termio.AskForString(ctx, fmt.Sprintf("Username for %s", name), def)
+		if err != nil {
+			return ExitError(ExitIO, err, "failed to ask for the username: %s", err)
+		}
+		if user != "" {
+			if sec, err = kvSecret(sec); err != nil {
+				return ExitError(ExitUsage, err, "failed to set the username of %s: %s", name, err)
+			}
+			if err := sec.Set("username", user); err != nil {
+				return ExitError(ExitUsage, err, "failed to set the username of %s: %s", name, err)
+			}
+		}
+	}
+
+	sec, err = setExpires(ctx, sec)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to set the expiry date of %s: %s", name, err)
+	}
+	ctx, err = s.checkPolicy(ctx, name, password)
+	if err != nil {
+		return err
+	}
+
+	// display or copy to clipboard, unless it's not saved anyway
+	if !ctxutil.IsDryRun(ctx) {
+		if err := s.generateCopyOrPrint(ctx, c, name, "", password); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, msg), name, sec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to write secret %q: %s", name, err)
+	}
+	return nil
+}
+
 func (s *Action) insert(ctx context.Context, c *cli.Context, name, key string, echo, multiline, force, append bool, kvps map[string]string) error {
 	var content []byte
 
@@ -58,34 +185,66 @@ func (s *Action) insert(ctx context.Context, c *cli.Context, name, key string, e
 
 	if ctxutil.IsStdin(ctx) {
 		if !force && !append && s.Store.Exists(ctx, name) {
-			return ExitError(ExitAborted, nil, "not overwriting your current secret")
+			return ExitError(ExitAborted, nil, "not overwriting your current secret. Pass --force to overwrite it")
+		}
+		// only the password is read, unless the whole secret is given
+		if !multiline && !append {
+			pw, err := firstLine(content)
+			if err != nil {
+				return ExitError(ExitUsage, err, "%s", err)
+			}
+			warnTrailingSpace(ctx, pw)
+			content = []byte(pw + "\n")
 		}
 		return s.insertStdin(ctx, name, content, append)
 	}
 
 	// don't check if it's force anyway
-	if !force && s.Store.Exists(ctx, name) && !termio.AskForConfirmation(ctx, fmt.Sprintf("An entry already exists for %s. Overwrite it?", name)) {
-		return ExitError(ExitAborted, nil, "not overwriting your current secret")
+	if !force && !ctxutil.IsDryRun(ctx) && s.Store.Exists(ctx, name) {
+		ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("An entry already exists for %s. Overwrite it?", name), "--force")
+		if err != nil {
+			return ExitError(ExitAborted, err, "not overwriting your current secret: %s", err)
+		}
+		if !ok {
+			return ExitError(ExitAborted, nil, "not overwriting your current secret")
+		}
 	}
 
 	// if multi-line input is requested start an editor
 	if multiline && ctxutil.IsInteractive(ctx) {
-		return s.insertMultiline(ctx, c, name)
-	}
-
-	// if echo mode is requested use a simple string input function
-	if echo {
-		ctx = termio.WithPassPromptFunc(ctx, func(ctx context.Context, prompt string) (string, error) {
-			return termio.AskForString(ctx, prompt, "")
-		})
+		return s.insertMultiline(ctx, c, name, force)
 	}
 
-	pw, err := termio.AskForPassword(ctx, fmt.Sprintf("password for %s", name), true)
+	// the password doesn't need to be repeated if it's shown anyway
+	ctx = termio.WithPassEcho(ctx, echo)
+	pw, err := termio.AskForPassword(ctx, fmt.Sprintf("password for %s", name), !echo)
 	if err != nil {
 		return ExitError(ExitIO, err, "failed to ask for password: %s", err)
 	}
+	warnTrailingSpace(ctx, pw)
 
-	return s.insertSingle(ctx, name, pw, kvps)
+	return s.insertSingle(ctx, name, pw, kvps, force)
+}
+
+// firstLine returns the first line of the input without its line break. Any
+// further lines are an error, they would be lost otherwise.
+func firstLine(content []byte) (string, error) {
+	line, rest := content, []byte(nil)
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		line, rest = content[:i], content[i+1:]
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return "", fmt.Errorf("the input has more than one line. Pass --multiline to insert all of it")
+	}
+	return string(bytes.TrimSuffix(line, []byte("\r"))), nil
+}
+
+// warnTrailingSpace warns about whitespace at the end of a password. It's
+// kept, but it's most likely a copy and paste error.
+func warnTrailingSpace(ctx context.Context, pw string) {
+	if strings.TrimRightFunc(pw, unicode.IsSpace) != pw {
+		out.Warningf(ctx, "The password ends with whitespace. It has been kept as it is")
+	}
 }
 
 func (s *Action) insertStdin(ctx context.Context, name string, content []byte, appendTo bool) error {
@@ -100,6 +259,10 @@ func (s *Action) insertStdin(ctx context.Context, name string, content []byte, a
 		if !ok {
 			return fmt.Errorf("%T is not an io.Writer", eSec)
 		}
+		// the appended lines must not continue the last line
+		if p, ok := eSec.(*secrets.Plain); ok && len(p.Bytes()) > 0 && !bytes.HasSuffix(p.Bytes(), []byte("\n")) {
+			p.WriteString("\n")
+		}
 		if _, err := secW.Write(content); err != nil {
 			return ExitError(ExitEncrypt, err, "failed to write %q: %q", content, err)
 		}
@@ -116,14 +279,25 @@ func (s *Action) insertStdin(ctx context.Context, name string, content []byte, a
 		debug.Log("Created new plain secret with input")
 	}
 
+	sec, err := setExpires(ctx, sec)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to set the expiry date of %s: %s", name, err)
+	}
+	ctx, err = s.checkPolicy(ctx, name, sec.Password())
+	if err != nil {
+		return err
+	}
+
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Read secret from STDIN"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set %q: %s", name, err)
 	}
 	return nil
 }
 
-func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[string]string) error {
-	var sec gopass.Secret
+// insertSingle sets the password of a new or existing secret. If confirm is
+// set the user is asked to confirm the changes to an existing secret.
+func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[string]string, confirm bool) error {
+	var sec, old gopass.Secret
 	sec = secrets.New()
 	if s.Store.Exists(ctx, name) {
 		gs, err := s.Store.Get(ctx, name)
@@ -131,8 +305,13 @@ func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[str
 			return ExitError(ExitDecrypt, err, "failed to decrypt existing secret: %s", err)
 		}
 		sec = gs
+		old = parseSecret(append([]byte{}, gs.Bytes()...))
 	} else {
-		if content, found := s.renderTemplate(ctx, name, []byte(pw)); found {
+		content, found, err := s.renderTemplate(ctx, name, []byte(pw))
+		if err != nil {
+			return err
+		}
+		if found {
 			nSec := &secrets.Plain{}
 			if _, err := nSec.Write(content); err == nil {
 				sec = nSec
@@ -143,11 +322,26 @@ func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[str
 	}
 
 	setMetadata(sec, kvps)
+	sec, err := setExpires(ctx, sec)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to set the expiry date of %s: %s", name, err)
+	}
 
 	// we only update the pw if the kvps were not set or if it's non-empty, because otherwise we were updating the kvps
 	if pw != "" || len(kvps) == 0 {
 		sec.SetPassword(pw)
-		audit.Single(ctx, pw)
+		printStrength(ctx, name, pw)
+	}
+
+	ctx, err = s.checkPolicy(ctx, name, sec.Password())
+	if err != nil {
+		return err
+	}
+
+	if old != nil {
+		if err := s.confirmOverwrite(ctx, name, old, parseSecret(sec.Bytes()), confirm); err != nil {
+			return err
+		}
 	}
 
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Inserted user supplied password"), name, sec); err != nil {
@@ -157,7 +351,7 @@ func (s *Action) insertSingle(ctx context.Context, name, pw string, kvps map[str
 }
 
 func (s *Action) insertYAML(ctx context.Context, name, key string, content []byte, kvps map[string]string) error {
-	if ctxutil.IsInteractive(ctx) {
+	if content == nil && ctxutil.IsInteractive(ctx) {
 		pw, err := termio.AskForString(ctx, name+":"+key, "")
 		if err != nil {
 			return ExitError(ExitIO, err, "failed to ask for user input: %s", err)
@@ -175,19 +369,66 @@ func (s *Action) insertYAML(ctx context.Context, name, key string, content []byt
 	} else {
 		sec = secrets.New()
 	}
+	// single line secrets are parsed as plain secrets, which can't hold keys
+	if p, ok := sec.(*secrets.Plain); ok && !bytes.Contains(p.Bytes(), []byte("\n")) {
+		if kv, err := secrets.ParseKV([]byte(p.Password() + "\n")); err == nil {
+			sec = kv
+		}
+	}
 	setMetadata(sec, kvps)
 	if err := sec.Set(key, string(content)); err != nil {
 		return ExitError(ExitUsage, err, "failed set key %q of %q: %q", key, name, err)
 	}
+	sec, err := setExpires(ctx, sec)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to set the expiry date of %s: %s", name, err)
+	}
+	ctx, err = s.checkPolicy(ctx, name, sec.Password())
+	if err != nil {
+		return err
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, "Inserted YAML value from STDIN"), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to set key %q of %q: %s", key, name, err)
 	}
 	return nil
 }
 
-func (s *Action) insertMultiline(ctx context.Context, c *cli.Context, name string) error {
+// setExpires sets the expires key to the date given with --expires, if any.
+// Plain secrets can't hold keys, they are converted to KV secrets first.
+func setExpires(ctx context.Context, sec gopass.Secret) (gopass.Secret, error) {
+	exp := GetExpires(ctx)
+	if exp == "" {
+		return sec, nil
+	}
+	sec, err := kvSecret(sec)
+	if err != nil {
+		return nil, err
+	}
+	if err := sec.Set(expiry.Key, exp); err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
+
+// kvSecret converts plain secrets, which can't hold keys, to KV secrets
+func kvSecret(sec gopass.Secret) (gopass.Secret, error) {
+	p, ok := sec.(*secrets.Plain)
+	if !ok {
+		return sec, nil
+	}
+	buf := append([]byte{}, p.Bytes()...)
+	if !bytes.HasSuffix(buf, []byte("\n")) {
+		buf = append(buf, '\n')
+	}
+	return secrets.ParseKV(buf)
+}
+
+// insertMultiline lets the user edit the whole secret. If confirm is set the
+// user is asked to confirm the changes to an existing secret.
+func (s *Action) insertMultiline(ctx context.Context, c *cli.Context, name string, confirm bool) error {
 	buf := []byte{}
-	if s.Store.Exists(ctx, name) {
+	exists := s.Store.Exists(ctx, name)
+	if exists {
 		var err error
 		sec, err := s.Store.Get(ctx, name)
 		if err != nil {
@@ -200,11 +441,24 @@ func (s *Action) insertMultiline(ctx context.Context, c *cli.Context, name strin
 	if err != nil {
 		return ExitError(ExitUnknown, err, "failed to start editor: %s", err)
 	}
-	sec := &secrets.Plain{}
-	n, err := sec.Write(content)
+	plain := &secrets.Plain{}
+	n, err := plain.Write(content)
 	if err != nil || n < 0 {
 		out.Errorf(ctx, "WARNING: Invalid secret: %s of len %d", err, n)
 	}
+	sec, err := setExpires(ctx, plain)
+	if err != nil {
+		return ExitError(ExitUsage, err, "failed to set the expiry date of %s: %s", name, err)
+	}
+	ctx, err = s.checkPolicy(ctx, name, sec.Password())
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := s.confirmOverwrite(ctx, name, parseSecret(buf), parseSecret(sec.Bytes()), confirm); err != nil {
+			return err
+		}
+	}
 	if err := s.Store.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Inserted user supplied password with %s", ed)), name, sec); err != nil {
 		return ExitError(ExitEncrypt, err, "failed to store secret %q: %s", name, err)
 	}
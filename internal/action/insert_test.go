@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/fatih/color"
@@ -42,6 +45,26 @@ func TestInsert(t *testing.T) {
 		buf.Reset()
 	})
 
+	t.Run("print the password strength", func(t *testing.T) {
+		out.Stderr = buf
+		defer func() {
+			out.Stderr = os.Stderr
+		}()
+
+		assert.NoError(t, act.insertSingle(ctx, "weak", "winter2024", nil, false))
+		assert.Contains(t, buf.String(), "Password strength: weak (0 / 4")
+		assert.Contains(t, buf.String(), `contains common password "winter"`)
+		buf.Reset()
+
+		sec, err := act.Store.Get(ctx, "weak")
+		require.NoError(t, err)
+		assert.Equal(t, "winter2024\n", string(sec.Bytes()))
+
+		assert.NoError(t, act.insertSingle(WithQuiet(ctx, true), "weak", "winter2024", nil, false))
+		assert.Equal(t, "", buf.String())
+		buf.Reset()
+	})
+
 	t.Run("insert bar baz", func(t *testing.T) {
 		assert.NoError(t, act.Insert(gptest.CliCtx(ctx, t, "bar", "baz")))
 		buf.Reset()
@@ -70,7 +93,7 @@ func TestInsert(t *testing.T) {
 		buf.Reset()
 
 		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "baz", false))
-		assert.Equal(t, "foobar\n---\nother: meh\nuser: name\n", buf.String())
+		assert.Equal(t, "foobar\n---\nuser: name\nother: meh", buf.String())
 		buf.Reset()
 	})
 
@@ -79,7 +102,8 @@ func TestInsert(t *testing.T) {
 		buf.Reset()
 
 		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "baz", false))
-		assert.Equal(t, "foobar\nother: meh\nuser: name\ninvalid key-value\nbody text", buf.String())
+		// the order and spelling of the lines is preserved
+		assert.Equal(t, "foobar\ninvalid key-value\nOther: meh\nUser: name\nbody text", buf.String())
 		buf.Reset()
 
 		assert.NoError(t, act.show(ctxutil.WithShowParsing(ctx, false), gptest.CliCtx(ctx, t), "baz", false))
@@ -96,8 +120,60 @@ func TestInsert(t *testing.T) {
 		buf.Reset()
 	})
 
-	t.Run("insert --multiline bar baz", func(t *testing.T) {
-		assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"multiline": "true"}, "bar", "baz")))
+	t.Run("update a single key", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, false)
+		assert.NoError(t, act.insertStdin(ctx, "kv", []byte("s3cret\nnotes\nUser: jane\nurl:http://example.org\n"), false))
+		assert.NoError(t, act.insertYAML(ctx, "kv", "user", []byte("john"), nil))
+		buf.Reset()
+
+		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "kv", false))
+		assert.Equal(t, "s3cret\nnotes\nUser: john\nurl:http://example.org\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("add a key to a single line secret", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, false)
+		assert.NoError(t, act.insertStdin(ctx, "single", []byte("s3cret"), false))
+		assert.NoError(t, act.insertYAML(ctx, "single", "user", []byte("jane"), nil))
+		buf.Reset()
+
+		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "single", false))
+		assert.Equal(t, "s3cret\nuser: jane", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("insert --key db.port 5432", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, false)
+		assert.NoError(t, act.insertStdin(ctx, "nested", []byte("s3cret\n---\ndb:\n  host: localhost\n"), false))
+		assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"key": "db.port"}, "nested", "5432")))
+		buf.Reset()
+
+		assert.NoError(t, act.show(WithKey(ctx, "db.port"), gptest.CliCtx(ctx, t), "nested", false))
+		assert.Equal(t, "5432", buf.String())
+		buf.Reset()
+
+		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "nested", false))
+		assert.Equal(t, "s3cret\n---\ndb:\n  host: localhost\n  port: 5432\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("insert exp via stdin w/ expiry date", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, false)
+		assert.NoError(t, act.insertStdin(WithExpires(ctx, "2030-01-02"), "exp", []byte("s3cret\n"), false))
+		buf.Reset()
+
+		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "exp", false))
+		assert.Equal(t, "s3cret\nexpires: 2030-01-02", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("insert --expires soon is rejected", func(t *testing.T) {
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"expires": "soon"}, "exp", "s3cret")))
+		buf.Reset()
+	})
+
+	t.Run("insert --multiline bar baz is rejected", func(t *testing.T) {
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"multiline": "true"}, "bar", "baz")))
 		buf.Reset()
 	})
 
@@ -113,7 +189,7 @@ func TestInsert(t *testing.T) {
 		buf.Reset()
 
 		assert.NoError(t, act.show(ctx, gptest.CliCtx(ctx, t), "baz", false))
-		assert.Equal(t, "other: 83\nuser: name", buf.String())
+		assert.Equal(t, "*****\nother: 83\nuser: name", buf.String())
 		buf.Reset()
 	})
 
@@ -184,4 +260,153 @@ func TestInsertStdin(t *testing.T) {
 	assert.NoError(t, act.insert(ctx, gptest.CliCtx(ctx, t), "baz", "", false, true, false, false, nil))
 	ibuf.Reset()
 	buf.Reset()
+
+	// only the first line is read without --multiline
+	ibuf.WriteString("foobar\r\n\n")
+	assert.NoError(t, act.insert(ctx, gptest.CliCtx(ctx, t), "single", "", false, false, false, false, nil))
+	sec, err := act.Store.Get(ctx, "single")
+	require.NoError(t, err)
+	assert.Equal(t, "foobar\n", string(sec.Bytes()))
+	ibuf.Reset()
+
+	ibuf.WriteString("foobar\nuser: name")
+	assert.Error(t, act.insert(ctx, gptest.CliCtx(ctx, t), "lines", "", false, false, false, false, nil))
+	ibuf.Reset()
+	ibuf.WriteString("foobar\nuser: name")
+	assert.NoError(t, act.insert(ctx, gptest.CliCtx(ctx, t), "lines", "", false, true, false, false, nil))
+	sec, err = act.Store.Get(ctx, "lines")
+	require.NoError(t, err)
+	assert.Equal(t, "foobar\nuser: name", string(sec.Bytes()))
+	ibuf.Reset()
+}
+
+func TestFirstLine(t *testing.T) {
+	for in, want := range map[string]string{
+		"":           "",
+		"foo":        "foo",
+		"foo\n":      "foo",
+		"foo\r\n":    "foo",
+		"foo \n":     "foo ",
+		"foo\n\n \n": "foo",
+		"foo\nbar\n": "",
+		"\nfoo":      "",
+		"foo\r\nbar": "",
+	} {
+		got, err := firstLine([]byte(in))
+		if want == "" && in != "" {
+			assert.Error(t, err, in)
+			continue
+		}
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestInsertMultilineStdin(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithStdin(ctx, true)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	ibuf := &bytes.Buffer{}
+	stdin = ibuf
+	defer func() {
+		stdin = os.Stdin
+	}()
+
+	for _, tc := range []struct {
+		name    string
+		content string
+		pw      string
+	}{
+		{name: "lf", content: "s3cret\nuser: name\n\nnotes\n\n", pw: "s3cret"},
+		{name: "crlf", content: "s3cret\r\nuser: name\r\nnotes\r\n", pw: "s3cret"},
+		{name: "bom", content: "\ufeffs3cret\nbody", pw: "s3cret"},
+		{name: "bom crlf", content: "\ufeffs3cret\r\n", pw: "s3cret"},
+		{name: "no newline", content: "s3cret", pw: "s3cret"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ibuf.WriteString(tc.content)
+			assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"multiline": "true"}, "ml/"+tc.name)))
+			ibuf.Reset()
+
+			sec, err := act.Store.Get(ctx, "ml/"+tc.name)
+			require.NoError(t, err)
+			assert.Equal(t, tc.content, string(sec.Bytes()))
+			assert.Equal(t, tc.pw, sec.Password())
+		})
+	}
+
+	t.Run("append", func(t *testing.T) {
+		ibuf.WriteString("more\nlines\n")
+		assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"append": "true"}, "ml/no newline")))
+		ibuf.Reset()
+
+		sec, err := act.Store.Get(ctx, "ml/no newline")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret\nmore\nlines\n", string(sec.Bytes()))
+	})
+
+	t.Run("multiline with key", func(t *testing.T) {
+		ibuf.WriteString("value")
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"multiline": "true", "key": "user"}, "ml/lf")))
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"multiline": "true"}, "ml/lf", "user")))
+		ibuf.Reset()
+	})
+}
+
+func TestInsertGenerate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, true)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.AutoClip = false
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		termio.Stdin = os.Stdin
+	}()
+
+	t.Run("ask for the username", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("jane\n")
+		require.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"generate": "24"}, "site/login")))
+
+		sec, err := act.Store.Get(ctx, "site/login")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 24)
+		user, _ := sec.Get("username")
+		assert.Equal(t, "jane", user)
+	})
+
+	t.Run("with a key", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"generate": "true", "key": "user"}, "site/other")))
+		assert.False(t, act.Store.Exists(ctx, "site/other"))
+	})
+
+	t.Run("editor aborted", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("false is not available")
+		}
+		defer buf.Reset()
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"generate": "24", "multiline": "true", "editor": "false"}, "site/other")))
+		assert.False(t, act.Store.Exists(ctx, "site/other"))
+	})
 }
@@ -0,0 +1,218 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/gopasspw/gopass/internal/importer"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/internal/table"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/urfave/cli/v2"
+)
+
+// batchItem is a row of a batch insert along with the name of its secret and
+// the result of inserting it
+type batchItem struct {
+	row    int
+	name   string
+	entry  importer.Entry
+	exists bool
+	// weak are the policy rules the password violates, if forced
+	weak string
+	code int
+	err  error
+}
+
+// insertBatch creates a secret for every row of a CSV or TSV file. Existing
+// secrets are only overwritten with --force. Rows that fail don't stop the
+// others, all results are printed at the end.
+func (s *Action) insertBatch(ctx context.Context, c *cli.Context, file string, force bool) error {
+	if c.Args().Len() > 0 {
+		return ExitError(ExitUsage, nil, "--batch creates the secrets named by the rows and can not be used with a name")
+	}
+	if !c.IsSet("map") {
+		return ExitError(ExitUsage, nil, "Usage: %s insert --batch <file> --map path=1,password=2", s.Name)
+	}
+	cols, err := importer.ParseColumns(c.String("map"))
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	var pathTpl *template.Template
+	if tpl := c.String("path-template"); tpl != "" {
+		pathTpl, err = template.New("path").Funcs(template.FuncMap{
+			"host":  extractHostname,
+			"lower": strings.ToLower,
+		}).Option("missingkey=error").Parse(tpl)
+		if err != nil {
+			return ExitError(ExitUsage, err, "invalid --path-template: %s", err)
+		}
+	} else if _, found := cols["path"]; !found {
+		return ExitError(ExitUsage, nil, "map a column to path or build it with --path-template")
+	}
+
+	fh, err := os.Open(file)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to open %s: %s", file, err)
+	}
+	defer fh.Close()
+
+	comma := ','
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".tsv" || ext == ".tab" {
+		comma = '\t'
+	}
+	rows, err := importer.CSV(fh, comma, cols, c.Bool("header"))
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read %s: %s", file, err)
+	}
+
+	items := s.batchPlan(ctx, rows, pathTpl)
+	if ctxutil.IsDryRun(ctx) {
+		return s.batchReport(ctx, items, force, true)
+	}
+
+	for i, item := range items {
+		if item.err != nil || (item.exists && !force) {
+			continue
+		}
+		debug.Log("inserting row %d as %s", item.row, item.name)
+		ctx := ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Inserted %s from %s", item.name, filepath.Base(file)))
+		ctx = leaf.WithWeakPassword(ctx, item.weak)
+		if err := s.Store.Set(ctx, item.name, importSecret(item.name, item.entry)); err != nil {
+			items[i].code, items[i].err = ExitEncrypt, err
+		}
+	}
+
+	return s.batchReport(ctx, items, force, false)
+}
+
+// batchPlan determines the name of the secret of every row. Rows whose name
+// can't be built, that can't be written, whose password violates the policy
+// of its folder or that have the same name as an earlier row fail.
+func (s *Action) batchPlan(ctx context.Context, rows []importer.Row, pathTpl *template.Template) []batchItem {
+	seen := make(map[string]int, len(rows))
+	items := make([]batchItem, 0, len(rows))
+	for _, row := range rows {
+		item := batchItem{row: row.Number}
+		items = append(items, item)
+		it := &items[len(items)-1]
+
+		if row.Err != nil {
+			it.code, it.err = ExitUsage, row.Err
+			continue
+		}
+
+		name := row.Values["path"]
+		if pathTpl != nil {
+			sb := &strings.Builder{}
+			if err := pathTpl.Execute(sb, row.Values); err != nil {
+				it.code, it.err = ExitUsage, err
+				continue
+			}
+			name = sb.String()
+		}
+		it.name = batchName(name)
+		if it.name == "" {
+			it.code, it.err = ExitUsage, fmt.Errorf("empty path")
+			continue
+		}
+		if err := s.Store.CheckWritable(it.name); err != nil {
+			it.code, it.err = ExitMount, err
+			continue
+		}
+		if prev, found := seen[it.name]; found {
+			it.code, it.err = ExitUsage, fmt.Errorf("same path as row %d", prev)
+			continue
+		}
+		seen[it.name] = row.Number
+
+		it.entry = row.Entry(it.name)
+		it.exists = s.Store.Exists(ctx, it.name)
+
+		pctx, err := s.checkPolicy(ctx, it.name, it.entry.Password)
+		if err != nil {
+			it.code, it.err = ExitUsage, err
+			continue
+		}
+		it.weak = leaf.GetWeakPassword(pctx)
+	}
+	return items
+}
+
+// batchName cleans the components of the name built for a row. Leading dots
+// are removed, so a row can't create hidden files or leave the store.
+func batchName(name string) string {
+	parts := strings.Split(name, "/")
+	clean := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimLeft(strings.TrimSpace(p), "."); p != "" {
+			clean = append(clean, p)
+		}
+	}
+	return strings.Join(clean, "/")
+}
+
+// batchReport prints the result of every row and fails if any row failed
+func (s *Action) batchReport(ctx context.Context, items []batchItem, force, dryRun bool) error {
+	t := newTable(
+		table.Column{Header: "ROW"},
+		table.Column{Header: "PATH", Truncate: table.Start},
+		table.Column{Header: "RESULT", Truncate: table.End},
+	)
+
+	var inserted, skipped, failed, code int
+	for _, item := range items {
+		status, result := batchResult(item, force, dryRun)
+		switch status {
+		case table.Error:
+			failed++
+			if code == 0 {
+				code = item.code
+			}
+		case table.Warning:
+			skipped++
+		default:
+			inserted++
+		}
+		t.AddRow(status, fmt.Sprintf("%d", item.row), item.name, result)
+	}
+	if err := t.Render(stdout); err != nil {
+		return ExitError(ExitIO, err, "failed to print the report: %s", err)
+	}
+
+	if dryRun {
+		out.Printf(ctx, "Would insert %d secrets, skip %d, %d rows fail", inserted, skipped, failed)
+		return nil
+	}
+	if failed > 0 {
+		return ExitError(code, nil, "Inserted %d secrets, skipped %d, %d rows failed", inserted, skipped, failed)
+	}
+	out.OKf(ctx, "Inserted %d secrets, skipped %d", inserted, skipped)
+	return nil
+}
+
+// batchResult returns the status and the description of the result of a row
+func batchResult(item batchItem, force, dryRun bool) (table.Status, string) {
+	switch {
+	case item.err != nil:
+		return table.Error, item.err.Error()
+	case item.exists && !force:
+		return table.Warning, "exists, skipped"
+	case dryRun && item.exists:
+		return table.OK, "would overwrite"
+	case dryRun:
+		return table.OK, "would insert"
+	case item.exists:
+		return table.OK, "overwritten"
+	default:
+		return table.OK, "inserted"
+	}
+}
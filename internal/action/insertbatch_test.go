@@ -0,0 +1,106 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertBatch(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	fn := filepath.Join(u.Dir, "accounts.csv")
+	require.NoError(t, os.WriteFile(fn, []byte("\xef\xbb\xbfurl,user,password,notes\n"+
+		"https://github.com/login,alice,s3cr3t,\"first\nsecond\"\n"+
+		"gitlab.com,bob,hunter2,\n"+
+		"gitlab.com,bob,again,\n"+
+		"example.org,carol\n"), 0600))
+	flags := map[string]string{
+		"batch":         fn,
+		"map":           "url=url,user=2,password=3,notes=notes",
+		"path-template": "imported/{{.url | host}}/{{.user}}",
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		dryRun := map[string]string{"dry-run": "true"}
+		for k, v := range flags {
+			dryRun[k] = v
+		}
+		assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, dryRun)))
+		assert.Contains(t, buf.String(), "imported/github.com/alice  would insert")
+		assert.Contains(t, buf.String(), "imported/gitlab.com/bob    would insert")
+		assert.Contains(t, buf.String(), "same path as row 3")
+		assert.Contains(t, buf.String(), `no column 4 for "notes"`)
+		assert.False(t, act.Store.Exists(ctx, "imported/github.com/alice"))
+	})
+
+	t.Run("insert", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Insert(gptest.CliCtxWithFlags(ctx, t, flags))
+		require.Error(t, err)
+		assert.Equal(t, "Inserted 2 secrets, skipped 0, 2 rows failed", err.Error())
+
+		sec, err := act.Store.Get(ctx, "imported/github.com/alice")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", sec.Password())
+		user, _ := sec.Get("user")
+		assert.Equal(t, "alice", user)
+		assert.Contains(t, sec.Body(), "second")
+
+		sec, err = act.Store.Get(ctx, "imported/gitlab.com/bob")
+		require.NoError(t, err)
+		assert.Equal(t, "hunter2", sec.Password())
+	})
+
+	t.Run("skip existing", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Insert(gptest.CliCtxWithFlags(ctx, t, flags))
+		require.Error(t, err)
+		assert.Equal(t, "Inserted 0 secrets, skipped 2, 2 rows failed", err.Error())
+		assert.Contains(t, buf.String(), "imported/gitlab.com/bob    exists, skipped")
+	})
+
+	t.Run("path column", func(t *testing.T) {
+		defer buf.Reset()
+		tsv := filepath.Join(u.Dir, "accounts.tsv")
+		require.NoError(t, os.WriteFile(tsv, []byte("../web/foo\tbar\n"), 0600))
+		assert.NoError(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"batch": tsv, "map": "path=1,password=2"})))
+		assert.True(t, act.Store.Exists(ctx, "web/foo"))
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"batch": fn, "map": "password=3"})))
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"batch": fn})))
+		assert.Error(t, act.Insert(gptest.CliCtxWithFlags(ctx, t, map[string]string{"batch": fn, "map": "path=title"})))
+	})
+}
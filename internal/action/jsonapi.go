@@ -0,0 +1,98 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/jsonapi"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// JSONAPIListen answers the native messaging requests of a browser extension
+// on stdin and stdout
+func (s *Action) JSONAPIListen(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	// stdout is reserved for the messages
+	oldStdout := out.Stdout
+	out.Stdout = out.Stderr
+	defer func() {
+		out.Stdout = oldStdout
+	}()
+
+	if !ctxutil.IsStdin(ctx) {
+		return ExitError(ExitUsage, nil, "%s jsonapi listen must be started by a browser. Run '%s jsonapi configure' to set it up", s.Name, s.Name)
+	}
+	if inited, err := s.Store.IsInitialized(ctx); err != nil || !inited {
+		return ExitError(ExitNotInitialized, err, "password-store is not initialized. Try '%s init'", s.Name)
+	}
+
+	origin, manifest := jsonapi.Caller(c.Args().Slice())
+	debug.Log("started by %q with manifest %q", origin, manifest)
+
+	api := jsonapi.New(s.Store, stdin, stdout, origin, jsonapi.AllowedOrigins(manifest))
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	api.SetActivity(s.startAutoLock(actx, func(reason string) {
+		s.lockStores(actx, reason)
+	}))
+	if err := api.Serve(ctx); err != nil {
+		return ExitError(ExitIO, err, "failed to handle request: %s", err)
+	}
+	return nil
+}
+
+// JSONAPIConfigure writes the wrapper script and installs the native
+// messaging manifest for the browser
+func (s *Action) JSONAPIConfigure(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	browser := c.String("browser")
+
+	wrapper := c.String("wrapper")
+	if wrapper == "" {
+		wrapper = filepath.Join(config.Directory(), jsonapi.WrapperName)
+	}
+	m, err := jsonapi.NewManifest(browser, wrapper, c.StringSlice("extension-id"))
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	if c.Bool("print") {
+		buf, err := m.Bytes()
+		if err != nil {
+			return ExitError(ExitUnknown, err, "failed to encode manifest: %s", err)
+		}
+		fmt.Fprint(stdout, string(buf))
+		return nil
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return ExitError(ExitUnknown, err, "failed to find the gopass binary: %s", err)
+	}
+	if err := jsonapi.WriteWrapper(wrapper, binary); err != nil {
+		return ExitError(ExitIO, err, "%s", err)
+	}
+	out.OKf(ctx, "Wrote wrapper script to %s", wrapper)
+
+	path := c.String("path")
+	if path == "" {
+		path, err = jsonapi.ManifestPath(browser)
+		if err != nil {
+			return ExitError(ExitUsage, err, "%s", err)
+		}
+	}
+	if err := m.Install(browser, path); err != nil {
+		return ExitError(ExitIO, err, "%s", err)
+	}
+	out.OKf(ctx, "Installed manifest for %s to %s", browser, path)
+	return nil
+}
@@ -0,0 +1,105 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONAPI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("configure writes the registry")
+	}
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	td := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", td)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	_ = sec.Set("user", "alice")
+	require.NoError(t, act.Store.Set(ctx, "websites/example.org/alice", sec))
+
+	t.Run("print manifest", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"browser": "firefox", "print": "true", "wrapper": "/tmp/wrapper.sh"})
+		require.NoError(t, act.JSONAPIConfigure(c))
+		assert.Contains(t, buf.String(), `"allowed_extensions": [`)
+		assert.Contains(t, buf.String(), `"path": "/tmp/wrapper.sh"`)
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		defer buf.Reset()
+		wrapper := filepath.Join(td, "wrapper.sh")
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"browser": "chrome", "wrapper": wrapper})
+		require.NoError(t, act.JSONAPIConfigure(c))
+		assert.FileExists(t, wrapper)
+		assert.FileExists(t, filepath.Join(td, ".config", "google-chrome", "NativeMessagingHosts", "com.justwatch.gopass.json"))
+	})
+
+	listen := func(t *testing.T, origin string) map[string]interface{} {
+		t.Helper()
+		defer buf.Reset()
+
+		in := &bytes.Buffer{}
+		req := []byte(`{"type":"getLogin","entry":"websites/example.org/alice"}`)
+		require.NoError(t, binary.Write(in, binary.LittleEndian, uint32(len(req))))
+		in.Write(req)
+		stdin = in
+
+		ctx := ctxutil.WithStdin(ctx, true)
+		require.NoError(t, act.JSONAPIListen(gptest.CliCtx(ctx, t, origin)))
+
+		var length uint32
+		require.NoError(t, binary.Read(buf, binary.LittleEndian, &length))
+		require.Equal(t, int(length), buf.Len())
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		return res
+	}
+
+	t.Run("listen", func(t *testing.T) {
+		res := listen(t, "chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/")
+		assert.Equal(t, map[string]interface{}{"username": "alice", "password": "s3cret"}, res)
+	})
+
+	t.Run("listen with an unknown origin", func(t *testing.T) {
+		res := listen(t, "chrome-extension://foo/")
+		assert.NotContains(t, res, "password")
+		assert.Contains(t, res, "error")
+	})
+
+	t.Run("listen without a browser", func(t *testing.T) {
+		assert.Error(t, act.JSONAPIListen(gptest.CliCtx(ctx, t)))
+	})
+}
@@ -0,0 +1,166 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/k8s"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/urfave/cli/v2"
+)
+
+// formatK8s is the show format rendering a Kubernetes Secret manifest
+const formatK8s = "k8s"
+
+// ExportK8s renders secrets as Kubernetes Secret manifests or applies them
+// to a cluster
+func (s *Action) ExportK8s(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" || c.Args().Len() > 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s export k8s <path> [--name <name>] [--namespace <namespace>] [--apply]", s.Name)
+	}
+
+	secrets, err := s.k8sSecrets(ctx, name, c.String("name"), c.String("namespace"), c.String("password-key"), c.Bool("per-entry"))
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("apply") {
+		if err := k8s.Encode(stdout, secrets); err != nil {
+			return ExitError(ExitIO, err, "failed to write manifests: %s", err)
+		}
+		return nil
+	}
+
+	if err := k8s.Apply(ctx, stdout, secrets, c.String("kubeconfig")); err != nil {
+		return ExitError(ExitUnknown, err, "failed to apply %d Secrets: %s", len(secrets), err)
+	}
+	out.OKf(ctx, "Applied %d Secrets", len(secrets))
+	return nil
+}
+
+// showK8s renders the secret or folder as Kubernetes Secret manifest
+func (s *Action) showK8s(ctx context.Context, name string) error {
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s show --format k8s [name]", s.Name)
+	}
+	// base64 is no protection, the manifest contains the plain passwords
+	if !ctxutil.IsForce(ctx) && !s.cfg.FormatPasswords {
+		return ExitError(ExitUsage, nil, "show --format k8s prints the password. Use --unsafe or enable it with '%s config show.formatpasswords true'", s.Name)
+	}
+	secrets, err := s.k8sSecrets(ctx, name, "", "", k8s.DefaultPasswordKey, false)
+	if err != nil {
+		return err
+	}
+	if err := k8s.Encode(stdout, secrets); err != nil {
+		return ExitError(ExitIO, err, "failed to write manifest: %s", err)
+	}
+	return nil
+}
+
+// k8sSecrets returns the manifests of a secret or a folder. A secret is
+// rendered with its password and all keys. The children of a folder are
+// either one data key each, holding their password, or one Secret each if
+// perEntry is set.
+func (s *Action) k8sSecrets(ctx context.Context, name, k8sName, namespace, passwordKey string, perEntry bool) ([]*k8s.Secret, error) {
+	if passwordKey == "" {
+		passwordKey = k8s.DefaultPasswordKey
+	}
+
+	if s.Store.Exists(ctx, name) {
+		sec, err := s.k8sSecret(ctx, name, k8sName, namespace, passwordKey)
+		if err != nil {
+			return nil, err
+		}
+		return []*k8s.Secret{sec}, nil
+	}
+	if !s.Store.IsDir(ctx, name) {
+		return nil, ExitError(ExitNotFound, nil, "%s is not in the password store", name)
+	}
+
+	children, err := s.k8sChildren(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if perEntry {
+		secrets := make([]*k8s.Secret, 0, len(children))
+		for _, child := range children {
+			childName := ""
+			if k8sName != "" {
+				childName = k8sName + "-" + k8s.Name(strings.TrimPrefix(child, name+"/"))
+			}
+			sec, err := s.k8sSecret(ctx, child, childName, namespace, passwordKey)
+			if err != nil {
+				return nil, err
+			}
+			secrets = append(secrets, sec)
+		}
+		return secrets, nil
+	}
+
+	sec, err := k8s.New(k8sName, namespace, name)
+	if err != nil {
+		return nil, ExitError(ExitUsage, err, "%s", err)
+	}
+	for _, child := range children {
+		content, err := s.Store.Get(ctx, child)
+		if err != nil {
+			return nil, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", child, err)
+		}
+		key := k8s.Key(strings.TrimPrefix(child, name+"/"))
+		debug.Log("adding %s as data key %s", child, key)
+		if err := sec.Add(key, []byte(content.Password())); err != nil {
+			return nil, ExitError(ExitUsage, err, "failed to add %s: %s", child, err)
+		}
+	}
+	return []*k8s.Secret{sec}, nil
+}
+
+// k8sSecret returns the manifest of a single secret
+func (s *Action) k8sSecret(ctx context.Context, name, k8sName, namespace, passwordKey string) (*k8s.Secret, error) {
+	content, err := s.Store.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ExitError(ExitNotFound, err, "%s is not in the password store", name)
+		}
+		return nil, ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
+	}
+
+	sec, err := k8s.New(k8sName, namespace, name)
+	if err != nil {
+		return nil, ExitError(ExitUsage, err, "%s", err)
+	}
+	if err := sec.AddSecret(content, passwordKey); err != nil {
+		return nil, ExitError(ExitUsage, err, "failed to convert %s: %s", name, err)
+	}
+	debug.Log("rendered %s as Secret %s with keys %+v", name, sec.Metadata.Name, content.Keys())
+	return sec, nil
+}
+
+// k8sChildren returns all secrets below the folder
+func (s *Action) k8sChildren(ctx context.Context, dir string) ([]string, error) {
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+	children := make([]string, 0, len(list))
+	for _, e := range list {
+		if strings.HasPrefix(e, dir+"/") {
+			children = append(children, e)
+		}
+	}
+	if len(children) < 1 {
+		return nil, ExitError(ExitNotFound, nil, "no secrets below %s", dir)
+	}
+	sort.Strings(children)
+	return children, nil
+}
@@ -0,0 +1,97 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestExportK8s(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("user", "admin"))
+	require.NoError(t, act.Store.Set(ctx, "infra/db", sec))
+	sec = secrets.NewKV()
+	sec.SetPassword("t0ken")
+	require.NoError(t, act.Store.Set(ctx, "infra/api/token", sec))
+
+	t.Run("no path", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.ExportK8s(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.ExportK8s(gptest.CliCtxWithFlags(ctx, t, map[string]string{"name": "mysecret", "namespace": "prod", "password-key": "db-password"}, "infra/db")))
+		assert.Contains(t, buf.String(), "name: mysecret\n  namespace: prod\n")
+		assert.Contains(t, buf.String(), "gopass.pw/path: infra.db\n")
+		assert.Contains(t, buf.String(), "db-password: czNjcmV0\n  user: YWRtaW4=\n")
+		assert.NotContains(t, buf.String(), "s3cret")
+	})
+
+	t.Run("folder", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.ExportK8s(gptest.CliCtx(ctx, t, "infra")))
+		assert.Equal(t, 1, strings.Count(buf.String(), "kind: Secret"))
+		assert.Contains(t, buf.String(), "name: infra\n")
+		assert.Contains(t, buf.String(), "api.token: dDBrZW4=\n  db: czNjcmV0\n")
+	})
+
+	t.Run("folder per entry", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.ExportK8s(gptest.CliCtxWithFlags(ctx, t, map[string]string{"name": "app", "per-entry": "true"}, "infra")))
+		assert.Equal(t, 2, strings.Count(buf.String(), "kind: Secret"))
+		assert.Contains(t, buf.String(), "name: app-api-token\n")
+		assert.Contains(t, buf.String(), "name: app-db\n")
+		assert.Contains(t, buf.String(), "user: YWRtaW4=\n")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.ExportK8s(gptest.CliCtx(ctx, t, "infra/web"))
+		require.Error(t, err)
+		var ec cli.ExitCoder
+		require.True(t, errors.As(err, &ec))
+		assert.Equal(t, ExitNotFound, ec.ExitCode())
+	})
+
+	t.Run("show", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "k8s"}, "infra/db")))
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "k8s", "unsafe": "true"}, "infra/db")))
+		assert.Contains(t, buf.String(), "name: infra-db\n")
+		assert.Contains(t, buf.String(), "password: czNjcmV0\n")
+	})
+}
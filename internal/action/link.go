@@ -1,20 +1,86 @@
 package action
 
 import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+
 	"github.com/urfave/cli/v2"
 )
 
-// Link creates a symlink
+// Link creates a secret pointing to another one
 func (s *Action) Link(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 
-	from := c.Args().Get(0)
-	to := c.Args().Get(1)
+	target := c.Args().Get(0)
+	alias := c.Args().Get(1)
+
+	if target == "" || alias == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s link <target> <alias>", s.Name)
+	}
+	if err := s.Store.CheckWritable(alias); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
+	if err := s.Store.Link(ctx, target, alias); err != nil {
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to link %s to %s: %s", alias, target, err)
+	}
+	if !ctxutil.IsDryRun(ctx) {
+		out.OKf(ctx, "Linked %s to %s", alias, target)
+	}
+	return nil
+}
+
+// withFollowLinkFlags sets the answer of followLink from --follow or
+// --break-link, if one of them is given
+func withFollowLinkFlags(ctx context.Context, c *cli.Context) (context.Context, error) {
+	follow, breakLink := c.Bool("follow"), c.Bool("break-link")
+	if follow && breakLink {
+		return ctx, ExitError(ExitUsage, nil, "--follow and --break-link can not be used together")
+	}
+	if follow || breakLink {
+		ctx = WithFollowLink(ctx, follow)
+	}
+	return ctx, nil
+}
 
-	if from == "" || to == "" {
-		return ExitError(ExitUsage, nil, "Usage: link <from> <to>")
+// followLink is called before a secret is changed. If it's a link the user
+// is asked whether to change its target instead or to break the link and
+// replace it with a secret of its own. Without a terminal to ask, or with
+// --yes, one of --follow or --break-link is required. It returns the name of
+// the secret to change and whether the link is broken.
+func (s *Action) followLink(ctx context.Context, name string) (string, bool, error) {
+	target, found := s.Store.LinkTarget(ctx, name)
+	if !found {
+		return name, false, nil
 	}
 
-	return s.Store.Link(ctx, from, to)
+	follow := IsFollowLink(ctx)
+	var err error
+	if !HasFollowLink(ctx) {
+		if !ctxutil.IsInteractive(ctx) || ctxutil.IsAlwaysYes(ctx) {
+			return name, false, ExitError(ExitUsage, nil, "%s is a link to %s. Pass --follow to change %s or --break-link to replace the link", name, target, target)
+		}
+		out.Warningf(ctx, "%s is a link to %s", name, target)
+		follow, err = termio.AskForBool(ctx, fmt.Sprintf("Change %s instead? Otherwise the link is replaced by a secret of its own", target), true)
+		if err != nil {
+			return name, false, ExitError(ExitAborted, err, "user aborted")
+		}
+	}
+	if !follow {
+		return name, true, nil
+	}
+
+	// the target may be a link, too
+	target, _, err = s.Store.Resolve(ctx, name)
+	if err != nil {
+		return name, false, ExitError(ExitNotFound, err, "%s", err)
+	}
+	if err := s.Store.CheckWritable(target); err != nil {
+		return name, false, ExitError(ExitMount, err, "%s", err)
+	}
+	return target, false, nil
 }
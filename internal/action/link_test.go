@@ -0,0 +1,130 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	assert.Error(t, act.Link(gptest.CliCtx(ctx, t, "foo")))
+	require.NoError(t, act.Link(gptest.CliCtx(ctx, t, "foo", "web/foo")))
+	assert.Contains(t, buf.String(), "Linked web/foo to foo")
+	buf.Reset()
+
+	t.Run("show the target", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "web/foo")))
+		assert.Equal(t, "secret", buf.String())
+	})
+
+	t.Run("list with an arrow", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.List(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "foo -> foo")
+	})
+
+	t.Run("edit needs an answer without a terminal", func(t *testing.T) {
+		_, _, _, err := act.editGetContent(ctx, "web/foo", false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Pass --follow")
+	})
+
+	t.Run("edit follows the link", func(t *testing.T) {
+		name, content, changed, err := act.editGetContent(WithFollowLink(ctx, true), "web/foo", false)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", name)
+		assert.Equal(t, "secret\nsecond\nthird", string(content))
+		assert.False(t, changed)
+	})
+
+	t.Run("delete the target", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true"}, "foo")))
+		assert.Contains(t, buf.String(), "foo is the target of 1 link(s) that will be dangling: web/foo")
+	})
+
+	t.Run("generate needs an answer without a terminal", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true"}, "web/foo", "12"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "web/foo is a link to foo")
+		assert.Error(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "follow": "true", "break-link": "true"}, "web/foo", "12")))
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+	})
+
+	t.Run("generate follows the link", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true", "follow": "true"}, "web/foo", "12")))
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 12)
+		target, found := act.Store.LinkTarget(ctx, "web/foo")
+		assert.True(t, found)
+		assert.Equal(t, "foo", target)
+	})
+
+	ctx = ctxutil.WithAlwaysYes(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, true)
+	defer func() {
+		termio.Stdin = os.Stdin
+	}()
+
+	t.Run("edit breaks the link", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("n\n")
+		name, content, changed, err := act.editGetContent(ctx, "web/foo", false)
+		require.NoError(t, err)
+		assert.Equal(t, "web/foo", name)
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, string(sec.Bytes()), string(content))
+		assert.True(t, changed)
+	})
+
+	t.Run("generate breaks the link", func(t *testing.T) {
+		defer buf.Reset()
+		termio.Stdin = strings.NewReader("n\n")
+		require.NoError(t, act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "print": "true"}, "web/foo", "16")))
+		_, found := act.Store.LinkTarget(ctx, "web/foo")
+		assert.False(t, found)
+		sec, err := act.Store.Get(ctx, "web/foo")
+		require.NoError(t, err)
+		assert.Len(t, sec.Password(), 16)
+		assert.Equal(t, []string{}, sec.Keys())
+	})
+}
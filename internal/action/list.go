@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store/leaf"
 
 	"github.com/fatih/color"
@@ -24,15 +25,32 @@ import (
 // display only those that have this prefix
 func (s *Action) List(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
-	filter := c.Args().First()
+	if c.Bool("all") {
+		ctx = leaf.WithNoIgnore(ctx, true)
+	}
+	if c.IsSet("prefix") {
+		return s.listPrefix(ctx, c.String("prefix"))
+	}
+	filter := s.Store.ExpandAlias(c.Args().First())
 	flat := c.Bool("flat")
 	stripPrefix := c.Bool("strip-prefix")
 	folders := c.Bool("folders")
+	format, err := outputFormat(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
 
 	// print the path if the argument is a direct hit
 	if s.Store.Exists(ctx, filter) && !s.Store.IsDir(ctx, filter) {
-		fmt.Println(filter)
-		return nil
+		if !out.IsStructured(format) {
+			fmt.Println(filter)
+			return nil
+		}
+		var v interface{} = tree.Entry{Name: filter, Type: "secret"}
+		if flat {
+			v = []string{filter}
+		}
+		return encodeList(format, v)
 	}
 
 	// we only support listing folders in flat mode currently
@@ -51,10 +69,10 @@ func (s *Action) List(c *cli.Context) error {
 		limit = c.Int("limit")
 	}
 
-	return s.listFiltered(ctx, l, limit, flat, folders, stripPrefix, filter)
+	return s.listFiltered(ctx, l, limit, flat, folders, stripPrefix, filter, format)
 }
 
-func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat, folders, stripPrefix bool, filter string) error {
+func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat, folders, stripPrefix bool, filter, format string) error {
 
 	sep := string(leaf.Sep)
 
@@ -81,15 +99,28 @@ func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat
 		if folders {
 			listOver = l.ListFolders
 		}
-		for _, e := range listOver(limit) {
+		entries := listOver(limit)
+		for i, e := range entries {
 			if stripPrefix {
-				e = strings.TrimPrefix(e, filter+sep)
+				entries[i] = strings.TrimPrefix(e, filter+sep)
 			}
+		}
+		if out.IsStructured(format) {
+			return encodeList(format, entries)
+		}
+		for _, e := range entries {
 			fmt.Fprintln(stdout, e)
 		}
 		return nil
 	}
 
+	if out.IsStructured(format) {
+		e := l.Entry(limit)
+		// the name of the listed folder, empty for the whole store
+		e.Name = strings.TrimSuffix(filter, sep)
+		return encodeList(format, e)
+	}
+
 	// we may need to redirect stdout for the pager support
 	so, buf := redirectPager(ctx, l)
 
@@ -102,6 +133,34 @@ func (s *Action) listFiltered(ctx context.Context, l *tree.Root, limit int, flat
 	return nil
 }
 
+// listPrefix prints the full names of all secrets and aliases starting with
+// prefix. It's called by the shell completion scripts on every key press.
+func (s *Action) listPrefix(ctx context.Context, prefix string) error {
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+
+	for _, e := range list {
+		if strings.HasPrefix(e, prefix) {
+			fmt.Fprintln(stdout, e)
+		}
+	}
+	for _, name := range s.cfg.AliasNames() {
+		if strings.HasPrefix(name+"/", prefix) {
+			fmt.Fprintln(stdout, name+"/")
+		}
+	}
+	return nil
+}
+
+func encodeList(format string, v interface{}) error {
+	if err := out.Encode(stdout, format, v); err != nil {
+		return ExitError(ExitUnknown, err, "failed to encode list: %s", err)
+	}
+	return nil
+}
+
 // redirectPager returns a redirected io.Writer if the output would exceed
 // the terminal size
 func redirectPager(ctx context.Context, subtree *tree.Root) (io.Writer, *bytes.Buffer) {
@@ -148,8 +148,10 @@ foo2/
 	assert.Equal(t, want, buf.String())
 	buf.Reset()
 
+	// foo is a secret and a folder
 	assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"flat": "true", "limit": "-1"})))
-	want = `foo/bar
+	want = `foo
+foo/bar
 foo/zen/baz/bar
 foo2/bar2
 `
@@ -157,14 +159,16 @@ foo2/bar2
 	buf.Reset()
 
 	assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"flat": "true", "limit": "0"})))
-	want = `foo/
+	want = `foo
+foo/
 foo2/
 `
 	assert.Equal(t, want, buf.String())
 	buf.Reset()
 
 	assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"flat": "true", "limit": "2"})))
-	want = `foo/bar
+	want = `foo
+foo/bar
 foo/zen/baz/
 foo2/bar2
 `
@@ -172,6 +176,27 @@ foo2/bar2
 	assert.Equal(t, want, buf.String())
 	buf.Reset()
 
+	// the tree summarizes the folders below the limit
+	assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"limit": "0"})))
+	want = `gopass
+├── foo/ (also a secret) (3 entries)
+└── foo2/ (1 entry)
+
+`
+	assert.Equal(t, want, buf.String())
+	buf.Reset()
+
+	assert.NoError(t, act.List(gptest.CliCtxWithFlags(ctx, t, map[string]string{"limit": "1"})))
+	want = `gopass
+├── foo/ (also a secret)
+│   ├── bar
+│   └── zen/ (1 entry)
+└── foo2/
+    └── bar2
+
+`
+	assert.Equal(t, want, buf.String())
+	buf.Reset()
 }
 
 func TestRedirectPager(t *testing.T) {
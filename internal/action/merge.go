@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/gopasspw/gopass/internal/audit"
 	"github.com/gopasspw/gopass/internal/editor"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/queue"
@@ -22,10 +21,7 @@ func (s *Action) Merge(c *cli.Context) error {
 	from := c.Args().Tail()
 
 	if to == "" {
-		return ExitError(ExitUsage, nil, "usage: %s merge <to> <from> [<from>]", s.Name)
-	}
-	if len(from) < 1 {
-		return ExitError(ExitUsage, nil, "usage: %s merge <to> <from> [<from>]", s.Name)
+		return ExitError(ExitUsage, nil, "usage: %s merge <name> | <to> <from> [<from>]", s.Name)
 	}
 
 	ed := editor.Path(c)
@@ -33,6 +29,11 @@ func (s *Action) Merge(c *cli.Context) error {
 		out.Warningf(ctx, "Failed to check editor config: %s", err)
 	}
 
+	// a single secret is merged with its conflicting remote changes
+	if len(from) < 1 {
+		return s.mergeConflict(ctx, to, ed, c.Bool("force"))
+	}
+
 	content := &bytes.Buffer{}
 	for _, k := range c.Args().Slice() {
 		if !s.Store.Exists(ctx, k) {
@@ -40,7 +41,7 @@ func (s *Action) Merge(c *cli.Context) error {
 		}
 		sec, err := s.Store.Get(ctxutil.WithShowParsing(ctx, false), k)
 		if err != nil {
-			return ExitError(ExitDecrypt, err, "failed to decrypt: %s: %s", k, err)
+			return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt: %s: %s", k, err)
 		}
 		_, err = content.WriteString("\n# Secret: " + k + "\n")
 		if err != nil {
@@ -70,8 +71,8 @@ func (s *Action) Merge(c *cli.Context) error {
 	nSec := secrets.ParsePlain(newContent)
 
 	// if the secret has a password, we check it's strength
-	if pw := nSec.Password(); pw != "" && !c.Bool("force") {
-		audit.Single(ctx, pw)
+	if pw := nSec.Password(); pw != "" && !c.Bool("force") && !c.Bool("quiet") {
+		printStrength(ctx, to, pw)
 	}
 
 	// write result (back) to store
@@ -89,7 +90,7 @@ func (s *Action) Merge(c *cli.Context) error {
 	// details of the underlying VCS. Or create some kind of transaction on top
 	// of the Git wrapper.
 	if err := queue.GetQueue(ctx).Idle(time.Minute); err != nil {
-		return err
+		return ExitError(ExitUnknown, err, "failed to wait for the previous commit: %s", err)
 	}
 
 	for _, old := range from {
@@ -98,7 +99,7 @@ func (s *Action) Merge(c *cli.Context) error {
 		}
 		debug.Log("deleting merged entry %s", old)
 		if err := s.Store.Delete(ctx, old); err != nil {
-			return ExitError(ExitUnknown, err, "failed to delete %s: %s", old, err)
+			return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to delete %s: %s", old, err)
 		}
 	}
 	return nil
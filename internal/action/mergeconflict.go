@@ -0,0 +1,144 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/diff"
+	"github.com/gopasspw/gopass/internal/editor"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// mergeConflict merges the remote copies kept by a sync into the secret
+func (s *Action) mergeConflict(ctx context.Context, name, ed string, unattended bool) error {
+	conflicts, err := s.Store.Conflicts(ctx)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list conflicts: %s", err)
+	}
+	copies := conflicts[name]
+	if len(copies) < 1 {
+		return ExitError(ExitNotFound, nil, "%s has no conflicting changes. Use '%s merge <to> <from> [<from>]' to merge secrets", name, s.Name)
+	}
+	return s.resolveConflict(ctx, name, copies, ed, unattended)
+}
+
+// resolveConflict does a three-way merge of the secret with each remote
+// copy. If they can't be merged automatically the user has to resolve the
+// conflicts in the editor.
+func (s *Action) resolveConflict(ctx context.Context, name string, copies []string, ed string, unattended bool) error {
+	ctx = ctxutil.WithShowParsing(ctx, false)
+
+	local, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
+	}
+
+	merged := local.Bytes()
+	conflicts := 0
+	for _, cp := range copies {
+		remote, err := s.Store.Get(ctx, cp)
+		if err != nil {
+			return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", cp, err)
+		}
+		var base []byte
+		if sec, err := s.Store.ConflictBase(ctx, name, cp); err == nil {
+			base = sec.Bytes()
+		} else {
+			debug.Log("no merge base of %s: %s", cp, err)
+			out.Warningf(ctx, "The version %s and %s are based on is unknown, all differences have to be resolved manually", name, cp)
+		}
+
+		var n int
+		merged, n = mergeSecret(base, merged, remote.Bytes())
+		conflicts += n
+	}
+
+	if conflicts > 0 {
+		if unattended {
+			return ExitError(ExitAborted, nil, "%s has %d conflicting changes that can't be merged automatically", name, conflicts)
+		}
+
+		out.Noticef(ctx, "%s has %d conflicting changes. Please resolve them in the editor", name, conflicts)
+		edited, err := editor.Invoke(ctx, ed, merged)
+		if err != nil {
+			return ExitError(ExitUnknown, err, "failed to invoke editor: %s", err)
+		}
+		if diff.HasConflicts(splitLines(edited)) {
+			return ExitError(ExitAborted, nil, "%s still has conflict markers, nothing was saved. Run '%s merge %s' again", name, s.Name, name)
+		}
+		merged = edited
+	} else {
+		out.Printf(ctx, "Merged the changes of %s automatically", name)
+	}
+
+	sec := secrets.ParsePlain(merged)
+	if err := s.Store.ResolveConflict(ctxutil.WithCommitMessage(ctx, "Merged conflicting changes"), name, sec, copies); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to encrypt secret %s: %s", name, err)
+	}
+	out.OKf(ctx, "Resolved the conflicting changes of %s", name)
+	return nil
+}
+
+// syncConflicts offers to merge the secrets changed locally and remotely
+// after a sync. Without a terminal they are only reported.
+func (s *Action) syncConflicts(ctx context.Context, mp string, r *syncResult) {
+	if r.err != nil || r.skipped != "" {
+		return
+	}
+
+	sub, err := s.Store.GetSubStore(mp)
+	if err != nil || sub == nil {
+		return
+	}
+	conflicts, err := sub.Conflicts(ctx)
+	if err != nil {
+		debug.Log("failed to list conflicts of %q: %s", r.name, err)
+		return
+	}
+
+	names := make([]string, 0, len(conflicts))
+	for name := range conflicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !ctxutil.IsInteractive(ctx) || !ctxutil.IsTerminal(ctx) || sub.IsReadOnly() {
+			out.Warningf(ctx, "%s has conflicting changes. Run '%s merge %s' to resolve them", name, s.Name, name)
+			continue
+		}
+		if ok, err := termio.AskForBool(ctx, "Do you want to merge the conflicting changes of "+name+" now?", true); err != nil || !ok {
+			continue
+		}
+		if err := s.resolveConflict(ctx, name, conflicts[name], editor.Path(nil), false); err != nil {
+			out.Errorf(ctx, "Failed to merge %s: %s", name, err)
+		}
+	}
+}
+
+// mergeSecret merges the password line and the body of a secret separately,
+// so a conflicting password is never merged with other lines
+func mergeSecret(base, local, remote []byte) ([]byte, int) {
+	var bpw, bbody []string
+	if base != nil {
+		bl := splitLines(base)
+		bpw, bbody = bl[:1], bl[1:]
+	}
+	ll := splitLines(local)
+	rl := splitLines(remote)
+
+	pw, pc := diff.Merge(bpw, ll[:1], rl[:1])
+	body, bc := diff.Merge(bbody, ll[1:], rl[1:])
+	return []byte(strings.Join(append(pw, body...), "\n") + "\n"), pc + bc
+}
+
+// splitLines returns at least one, possibly empty, line
+func splitLines(buf []byte) []string {
+	return strings.Split(string(bytes.TrimSuffix(buf, []byte("\n"))), "\n")
+}
@@ -0,0 +1,166 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/diff"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConflict(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	// what a sync leaves behind if db was changed locally and remotely
+	conflict := func(t *testing.T, base, local, remote string) {
+		t.Helper()
+
+		set := func(name, content string) {
+			require.NoError(t, act.Store.Set(ctx, name, secrets.ParsePlain([]byte(content))))
+		}
+		set("db", base)
+		revs, err := act.Store.ListRevisions(ctx, "db")
+		require.NoError(t, err)
+		set("db", local)
+		set("db.conflict-1a2b3c4", remote)
+
+		cmd := exec.Command("git", "config", "--local", "gopass-conflict.db.conflict-1a2b3c4.txt.base", revs[0].Hash)
+		cmd.Dir = u.StoreDir("")
+		require.NoError(t, cmd.Run())
+	}
+
+	t.Run("no conflict", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Merge(gptest.CliCtx(ctx, t, "foo")))
+	})
+
+	t.Run("merged automatically", func(t *testing.T) {
+		defer buf.Reset()
+		conflict(t, "secret\nuser: alice\nurl: a.example.org\n", "secret\nuser: bob\nurl: a.example.org\n", "secret\nuser: alice\nurl: b.example.org\n")
+
+		require.NoError(t, act.Merge(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "db")))
+		sec, err := act.Store.Get(ctxutil.WithShowParsing(ctx, false), "db")
+		require.NoError(t, err)
+		assert.Equal(t, "secret\nuser: bob\nurl: b.example.org\n", string(sec.Bytes()))
+		assert.False(t, act.Store.Exists(ctx, "db.conflict-1a2b3c4"))
+
+		revs, err := act.Store.ListRevisions(ctx, "db")
+		require.NoError(t, err)
+		assert.Contains(t, revs[0].Subject, "Merged conflicting changes of db")
+
+		conflicts, err := act.Store.Conflicts(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+	})
+
+	t.Run("conflicting password", func(t *testing.T) {
+		defer buf.Reset()
+		conflict(t, "secret\nuser: alice\n", "local\nuser: alice\n", "remote\nuser: alice\n")
+
+		// unattended merges must not pick a password
+		assert.Error(t, act.Merge(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "db")))
+
+		// the conflict markers were not removed
+		tctx := ctxutil.WithTerminal(ctx, true)
+		err := act.Merge(gptest.CliCtxWithFlags(tctx, t, map[string]string{"editor": "true"}, "db"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "still has conflict markers")
+		assert.True(t, act.Store.Exists(ctx, "db.conflict-1a2b3c4"))
+	})
+}
+
+func TestMergeSecret(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		base      string
+		local     string
+		remote    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "body",
+			base:   "pw\na\nb\n",
+			local:  "pw\nx\nb\n",
+			remote: "pw\na\nb\ny\n",
+			want:   "pw\nx\nb\ny\n",
+		},
+		{
+			name:   "password changed on one side",
+			base:   "pw\na\n",
+			local:  "new\na\n",
+			remote: "pw\na\nb\n",
+			want:   "new\na\nb\n",
+		},
+		{
+			name:      "password changed on both sides",
+			base:      "pw\na\n",
+			local:     "local\na\n",
+			remote:    "remote\na\n",
+			want:      diff.MarkerLocal + "\nlocal\n" + diff.MarkerBase + "\npw\n" + diff.MarkerSep + "\nremote\n" + diff.MarkerRemote + "\na\n",
+			conflicts: 1,
+		},
+		{
+			name:      "password only",
+			base:      "pw",
+			local:     "local",
+			remote:    "remote",
+			want:      diff.MarkerLocal + "\nlocal\n" + diff.MarkerBase + "\npw\n" + diff.MarkerSep + "\nremote\n" + diff.MarkerRemote + "\n",
+			conflicts: 1,
+		},
+		{
+			name:      "no base",
+			local:     "pw\na\n",
+			remote:    "pw\nb\n",
+			want:      "pw\n" + diff.MarkerLocal + "\na\n" + diff.MarkerSep + "\nb\n" + diff.MarkerRemote + "\n",
+			conflicts: 1,
+		},
+	} {
+		var base []byte
+		if tc.base != "" {
+			base = []byte(tc.base)
+		}
+		res, conflicts := mergeSecret(base, []byte(tc.local), []byte(tc.remote))
+		assert.Equal(t, tc.want, string(res), tc.name)
+		assert.Equal(t, tc.conflicts, conflicts, tc.name)
+	}
+}
@@ -1,16 +1,17 @@
 package action
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"errors"
 
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/out"
-	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/internal/store/root"
-	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/internal/table"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 
@@ -37,27 +38,151 @@ func (s *Action) MountRemove(c *cli.Context) error {
 	return nil
 }
 
+// mountOutput is the schema of mounts --format json|yaml. It must be kept
+// stable.
+type mountOutput struct {
+	// Name is the mount point, empty for the root store
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readonly"`
+	Crypto   string `json:"crypto"`
+	Storage  string `json:"storage"`
+	// Status is only included with --verbose
+	Status *mountStatus `json:"status,omitempty"`
+}
+
 // MountsPrint prints all existing mounts
 func (s *Action) MountsPrint(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
-	if len(s.Store.Mounts()) < 1 {
-		out.Printf(ctx, "No mounts")
-		return nil
+	format, err := outputFormat(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	// mounts are opened lazily, open all of them to warn about broken ones
+	s.Store.OpenMounts()
+	if out.IsStructured(format) {
+		return s.mountsStructured(ctx, format, c.Bool("verbose"))
+	}
+	if c.Bool("verbose") {
+		return s.mountsVerbose(ctx)
 	}
 
-	root := tree.New(color.GreenString(fmt.Sprintf("gopass (%s)", s.Store.Path())))
+	return s.mountsTable(ctx)
+}
+
+// mountsTable prints the root store and all mounts, sorted by mount point
+func (s *Action) mountsTable(ctx context.Context) error {
+	mps := s.Store.MountPoints()
+	sort.Strings(mps)
 	mounts := s.Store.Mounts()
+	debug.Log("MountsPrint - %+v - %+v", mounts, mps)
+
+	t := newTable(
+		table.Column{Header: "MOUNT", Truncate: table.Start},
+		table.Column{Header: "PATH", Truncate: table.Start},
+		table.Column{Header: "CRYPTO"},
+		table.Column{Header: "STORAGE"},
+		table.Column{Header: "MODE"},
+	)
+	for _, alias := range append([]string{""}, mps...) {
+		path := s.Store.Path()
+		if alias != "" {
+			path = mounts[alias]
+		}
+		mo := s.mountOutput(ctx, alias, path)
+
+		name := mo.Name
+		if name == "" {
+			name = "<root>"
+		}
+		mode := "rw"
+		if mo.ReadOnly {
+			mode = "ro"
+		}
+		status := table.OK
+		if mo.Crypto == "" || mo.Storage == "" {
+			status = table.Error
+		}
+		t.AddRow(status, name, mo.Path, mo.Crypto, mo.Storage, mode)
+	}
+
+	if err := t.Render(stdout); err != nil {
+		return ExitError(ExitIO, err, "failed to print mounts: %s", err)
+	}
+	return nil
+}
+
+// mountsStructured prints the root store and all mounts, sorted by mount point
+func (s *Action) mountsStructured(ctx context.Context, format string, verbose bool) error {
 	mps := s.Store.MountPoints()
-	sort.Sort(store.ByPathLen(mps))
+	sort.Strings(mps)
+	mounts := s.Store.Mounts()
+
+	res := make([]mountOutput, 0, len(mps)+1)
+	res = append(res, s.mountOutput(ctx, "", s.Store.Path()))
 	for _, alias := range mps {
-		path := mounts[alias]
-		if err := root.AddMount(alias, path); err != nil {
-			out.Errorf(ctx, "Failed to add mount to tree: %s", err)
+		res = append(res, s.mountOutput(ctx, alias, mounts[alias]))
+	}
+	if verbose {
+		for i, ms := range s.mountsStatus(ctx, append([]string{""}, mps...)) {
+			res[i].Status = ms
 		}
 	}
-	debug.Log("MountsPrint - %+v - %+v", mounts, mps)
 
-	fmt.Fprintln(stdout, root.Format(tree.INF))
+	if err := out.Encode(stdout, format, res); err != nil {
+		return ExitError(ExitUnknown, err, "failed to encode mounts: %s", err)
+	}
+	return nil
+}
+
+func (s *Action) mountOutput(ctx context.Context, alias, path string) mountOutput {
+	mo := mountOutput{
+		Name:     alias,
+		Path:     path,
+		ReadOnly: s.cfg.IsReadOnly(alias),
+	}
+	if crypto := s.Store.Crypto(ctx, alias); crypto != nil {
+		mo.Crypto = crypto.Name()
+	}
+	if storage := s.Store.Storage(ctx, alias); storage != nil {
+		mo.Storage = storage.Name()
+	}
+	return mo
+}
+
+// mountsVerbose prints the backends and the health of the root store and all
+// mounts, sorted by mount point
+func (s *Action) mountsVerbose(ctx context.Context) error {
+	mps := s.Store.MountPoints()
+	sort.Strings(mps)
+	mounts := s.Store.Mounts()
+
+	aliases := append([]string{""}, mps...)
+	for i, ms := range s.mountsStatus(ctx, aliases) {
+		mo := s.mountOutput(ctx, aliases[i], s.Store.Path())
+		if aliases[i] != "" {
+			mo.Path = mounts[aliases[i]]
+		}
+
+		name := mo.Name
+		if name == "" {
+			name = "<root>"
+		}
+		if mo.ReadOnly {
+			name += " (read-only)"
+		}
+		fmt.Fprintln(stdout, color.GreenString(name))
+		fmt.Fprintf(stdout, "  %-11s %s\n", "path:", mo.Path)
+		if ms == nil {
+			fmt.Fprintf(stdout, "  %-11s %s\n", "status:", "not available")
+			continue
+		}
+		fmt.Fprintf(stdout, "  %-11s %s %s\n", "crypto:", mo.Crypto, ms.CryptoVersion)
+		fmt.Fprintf(stdout, "  %-11s %s %s\n", "storage:", mo.Storage, ms.StorageVersion)
+		fmt.Fprintf(stdout, "  %-11s %s\n", "remote:", ms.remoteInfo())
+		fmt.Fprintf(stdout, "  %-11s %d\n", "entries:", ms.Entries)
+		fmt.Fprintf(stdout, "  %-11s %s\n", "recipients:", ms.recipientsInfo())
+	}
 	return nil
 }
 
@@ -82,11 +207,18 @@ func (s *Action) MountAdd(c *cli.Context) error {
 		localPath = config.PwStoreDir(alias)
 	}
 
-	if s.Store.Exists(ctx, alias) {
-		out.Warningf(ctx, "shadowing %s entry", alias)
+	readOnly := c.Bool("readonly")
+	if readOnly {
+		if s.cfg.MountReadOnly == nil {
+			s.cfg.MountReadOnly = make(map[string]bool, 1)
+		}
+		s.cfg.MountReadOnly[alias] = true
 	}
 
 	if err := s.Store.AddMount(ctx, alias, localPath); err != nil {
+		if readOnly {
+			delete(s.cfg.MountReadOnly, alias)
+		}
 		switch e := errors.Unwrap(err).(type) {
 		case root.AlreadyMountedError:
 			out.Printf(ctx, "Store is already mounted")
@@ -103,6 +235,60 @@ func (s *Action) MountAdd(c *cli.Context) error {
 		return ExitError(ExitConfig, err, "failed to save config: %s", err)
 	}
 
-	out.Printf(ctx, "Mounted %s as %s", alias, localPath)
+	if readOnly {
+		out.Printf(ctx, "Mounted %s as %s (read-only)", alias, localPath)
+	} else {
+		out.Printf(ctx, "Mounted %s as %s", alias, localPath)
+	}
+	s.printShadowed(ctx, alias)
+	return nil
+}
+
+// MountSet changes the per mount options of an existing mount
+func (s *Action) MountSet(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	alias := c.Args().First()
+	if alias == "" || c.Args().Len() < 2 {
+		return ExitError(ExitUsage, nil, "usage: %s mounts set <alias> <option>=<value>", s.Name)
+	}
+	if _, found := s.cfg.Mounts[alias]; !found {
+		return ExitError(ExitMount, nil, "No such mount point %q", alias)
+	}
+
+	for _, kv := range c.Args().Tail() {
+		p := strings.SplitN(kv, "=", 2)
+		if len(p) < 2 {
+			return ExitError(ExitUsage, nil, "usage: %s mounts set <alias> <option>=<value>", s.Name)
+		}
+		if err := s.cfg.SetMountConfigValue(alias, p[0], p[1]); err != nil {
+			return ExitError(ExitConfig, err, "failed to set %s for %s: %s", p[0], alias, err)
+		}
+		out.Printf(ctx, "%s: %s", p[0], s.cfg.MountConfigMap(alias)[p[0]])
+	}
 	return nil
 }
+
+// printShadowed warns about existing entries hidden by the given mount
+func (s *Action) printShadowed(ctx context.Context, alias string) {
+	shadowed, err := s.Store.Shadowed(ctx)
+	if err != nil {
+		debug.Log("failed to check for shadowed entries: %s", err)
+		return
+	}
+
+	names := make([]string, 0, len(shadowed))
+	for name, mp := range shadowed {
+		if mp == alias {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 1 {
+		return
+	}
+	sort.Strings(names)
+
+	out.Warningf(ctx, "The mount %s shadows %d existing entries. They can't be accessed while %s is mounted:", alias, len(names), alias)
+	for _, name := range names {
+		out.Printf(ctx, "  %s", name)
+	}
+}
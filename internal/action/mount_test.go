@@ -5,10 +5,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/stretchr/testify/assert"
@@ -74,4 +76,47 @@ func TestMounts(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.MountsPrint(gptest.CliCtx(ctx, t)))
 	})
+
+	t.Run("add mount shadowing entries", func(t *testing.T) {
+		defer buf.Reset()
+		sec := secrets.New()
+		sec.SetPassword("foo")
+		require.NoError(t, act.Store.Set(ctx, "mount3/old", sec))
+		require.NoError(t, u.InitStore("mount3"))
+		assert.NoError(t, act.MountAdd(gptest.CliCtx(ctx, t, "mount3", u.StoreDir("mount3"))))
+		assert.Contains(t, buf.String(), "The mount mount3 shadows 1 existing entries")
+		assert.Contains(t, buf.String(), "  mount3/old\n")
+	})
+
+	t.Run("add read-only mount", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, u.InitStore("mount4"))
+		assert.NoError(t, act.MountAdd(gptest.CliCtxWithFlags(ctx, t, map[string]string{"readonly": "true"}, "mount4", u.StoreDir("mount4"))))
+		assert.Contains(t, buf.String(), "(read-only)")
+		assert.True(t, act.cfg.IsReadOnly("mount4"))
+
+		buf.Reset()
+		assert.NoError(t, act.MountsPrint(gptest.CliCtx(ctx, t)))
+		assert.Regexp(t, `\nmount4 +`+regexp.QuoteMeta(u.StoreDir("mount4"))+` +plain +fs +ro\n`, buf.String())
+
+		err := act.Delete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "mount4/foo"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "mount 'mount4' is read-only")
+
+		err = act.Generate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "mount4/foo", "24"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "mount 'mount4' is read-only")
+	})
+
+	t.Run("set mount options", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.MountSet(gptest.CliCtx(ctx, t, "mount4")))
+		assert.Error(t, act.MountSet(gptest.CliCtx(ctx, t, "mount4", "readonly")))
+		assert.Error(t, act.MountSet(gptest.CliCtx(ctx, t, "nomount", "readonly=false")))
+		assert.Error(t, act.MountSet(gptest.CliCtx(ctx, t, "mount4", "foo=bar")))
+
+		assert.NoError(t, act.MountSet(gptest.CliCtx(ctx, t, "mount4", "readonly=false")))
+		assert.Equal(t, "readonly: false\n", buf.String())
+		assert.False(t, act.cfg.IsReadOnly("mount4"))
+	})
 }
@@ -0,0 +1,161 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// mountsRemoteTimeout limits the time spent checking the remote of each
+// mount. A remote that doesn't answer in time is reported as unknown.
+var mountsRemoteTimeout = 5 * time.Second
+
+const (
+	remoteOK      = "ok"
+	remoteUnknown = "unknown"
+	remoteNone    = "none"
+)
+
+// remoteStatuser is implemented by storage backends that sync with a remote
+type remoteStatuser interface {
+	RemoteURL(ctx context.Context) string
+	RemoteStatus(ctx context.Context) (int, int, error)
+}
+
+// mountStatus is the health of a mount printed by mounts --verbose. It's part
+// of the mounts --format json|yaml schema and must be kept stable.
+type mountStatus struct {
+	CryptoVersion  string `json:"crypto_version"`
+	StorageVersion string `json:"storage_version"`
+	// Remote is the URL of the git remote, empty if there is none
+	Remote string `json:"remote"`
+	// RemoteStatus is ok, unknown (e.g. offline) or none
+	RemoteStatus string `json:"remote_status"`
+	// Ahead and Behind are the number of commits only in the local or the
+	// remote branch, null if unknown
+	Ahead      *int `json:"ahead"`
+	Behind     *int `json:"behind"`
+	Entries    int  `json:"entries"`
+	Recipients int  `json:"recipients"`
+	// OwnKey is true if one of the local keys is a recipient of the store
+	OwnKey bool `json:"own_key"`
+}
+
+// mountsStatus returns the status of the given mounts, "" being the root
+// store. The remotes are checked concurrently.
+func (s *Action) mountsStatus(ctx context.Context, aliases []string) []*mountStatus {
+	res := make([]*mountStatus, len(aliases))
+
+	var wg sync.WaitGroup
+	for i, alias := range aliases {
+		sub, err := s.Store.GetSubStore(alias)
+		if err != nil || sub == nil || !sub.Valid() {
+			debug.Log("no status for mount %q: %s", alias, err)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, sub *leaf.Store) {
+			defer wg.Done()
+			res[i] = mountStatusOf(ctx, sub)
+		}(i, sub)
+	}
+	wg.Wait()
+
+	return res
+}
+
+func mountStatusOf(ctx context.Context, sub *leaf.Store) *mountStatus {
+	ms := &mountStatus{
+		RemoteStatus: remoteNone,
+	}
+
+	if entries, err := sub.List(ctx, ""); err == nil {
+		ms.Entries = len(entries)
+	} else {
+		debug.Log("failed to list %q: %s", sub.Alias(), err)
+	}
+
+	if crypto := sub.Crypto(); crypto != nil {
+		if v, err := crypto.Version(ctx); err == nil {
+			ms.CryptoVersion = v.String()
+		} else {
+			debug.Log("failed to detect %s version: %s", crypto.Name(), err)
+			ms.CryptoVersion = "unknown"
+		}
+
+		rs := sub.Recipients(ctx)
+		ms.Recipients = len(rs)
+		fps := make(map[string]bool, len(rs))
+		for _, r := range rs {
+			fps[crypto.Fingerprint(ctx, r)] = true
+		}
+		ids, err := crypto.ListIdentities(ctx)
+		if err != nil {
+			debug.Log("failed to list identities: %s", err)
+		}
+		for _, id := range ids {
+			if fps[crypto.Fingerprint(ctx, id)] {
+				ms.OwnKey = true
+				break
+			}
+		}
+	}
+
+	storage := sub.Storage()
+	if storage == nil {
+		return ms
+	}
+	ms.StorageVersion = storage.Version(ctx).String()
+
+	rst, ok := storage.(remoteStatuser)
+	if !ok {
+		return ms
+	}
+	ms.Remote = rst.RemoteURL(ctx)
+	if ms.Remote == "" {
+		return ms
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mountsRemoteTimeout)
+	defer cancel()
+
+	ahead, behind, err := rst.RemoteStatus(ctx)
+	if err != nil {
+		if errors.Is(err, store.ErrGitNoRemote) {
+			return ms
+		}
+		debug.Log("failed to check the remote of %q: %s", sub.Alias(), err)
+		ms.RemoteStatus = remoteUnknown
+		return ms
+	}
+	ms.RemoteStatus = remoteOK
+	ms.Ahead = &ahead
+	ms.Behind = &behind
+	return ms
+}
+
+// remoteInfo describes the remote in one line
+func (ms *mountStatus) remoteInfo() string {
+	switch ms.RemoteStatus {
+	case remoteNone:
+		return remoteNone
+	case remoteOK:
+		return fmt.Sprintf("%s (%d ahead, %d behind)", ms.Remote, *ms.Ahead, *ms.Behind)
+	default:
+		return fmt.Sprintf("%s (%s)", ms.Remote, ms.RemoteStatus)
+	}
+}
+
+// recipientsInfo describes the recipients in one line
+func (ms *mountStatus) recipientsInfo() string {
+	if ms.OwnKey {
+		return fmt.Sprintf("%d (including your key)", ms.Recipients)
+	}
+	return fmt.Sprintf("%d (your key is not a recipient)", ms.Recipients)
+}
@@ -0,0 +1,124 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMountsVerbose(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	require.NoError(t, u.InitStore("team"))
+	require.NoError(t, act.Store.AddMount(ctx, "team", u.StoreDir("team")))
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, act.Store.Set(ctx, "team/db", sec))
+
+	verbose := map[string]string{"verbose": "true"}
+
+	t.Run("text", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, verbose)))
+		assert.Contains(t, buf.String(), "<root>\n  path:       "+u.StoreDir("")+"\n")
+		assert.Contains(t, buf.String(), "team\n  path:       "+u.StoreDir("team")+"\n")
+		assert.Contains(t, buf.String(), "  storage:    fs ")
+		assert.Contains(t, buf.String(), "  remote:     none\n")
+		assert.Contains(t, buf.String(), "  entries:    2\n")
+		assert.Contains(t, buf.String(), "  recipients: 1 (including your key)\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json", "verbose": "true"})))
+
+		var res []mountOutput
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		require.Len(t, res, 2)
+		assert.Equal(t, "team", res[1].Name)
+		require.NotNil(t, res[1].Status)
+		assert.Equal(t, 2, res[1].Status.Entries)
+		assert.Equal(t, 1, res[1].Status.Recipients)
+		assert.True(t, res[1].Status.OwnKey)
+		assert.Equal(t, remoteNone, res[1].Status.RemoteStatus)
+		assert.Nil(t, res[1].Status.Ahead)
+		assert.NotContains(t, buf.String(), `"ahead": 0`)
+	})
+
+	t.Run("json without verbose", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "json"})))
+		assert.NotContains(t, buf.String(), "status")
+	})
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+	remote := filepath.Join(u.Dir, "remote.git")
+	require.NoError(t, exec.Command("git", "init", "--bare", remote).Run())
+	storage := act.Store.Storage(ctx, "")
+	require.NoError(t, storage.AddRemote(ctx, "origin", remote))
+
+	t.Run("unreachable remote", func(t *testing.T) {
+		defer buf.Reset()
+		// nothing was pushed yet, so there is nothing to fetch
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, verbose)))
+		assert.Contains(t, buf.String(), "  storage:    git ")
+		assert.Contains(t, buf.String(), "  remote:     "+remote+" (unknown)\n")
+	})
+
+	require.NoError(t, storage.Push(ctx, "origin", ""))
+
+	t.Run("offline", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctxutil.WithNoNetwork(ctx, true), t, verbose)))
+		assert.Contains(t, buf.String(), "  remote:     "+remote+" (unknown)\n")
+	})
+
+	t.Run("synced remote", func(t *testing.T) {
+		defer buf.Reset()
+		// not pushed
+		require.NoError(t, act.Store.Set(ctxutil.WithNoNetwork(ctx, true), "web", sec))
+		require.NoError(t, act.MountsPrint(gptest.CliCtxWithFlags(ctx, t, verbose)))
+		assert.Contains(t, buf.String(), "  remote:     "+remote+" (1 ahead, 0 behind)\n")
+	})
+}
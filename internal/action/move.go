@@ -21,14 +21,20 @@ func (s *Action) Move(c *cli.Context) error {
 	from := c.Args().Get(0)
 	to := c.Args().Get(1)
 
-	if !force {
-		if s.Store.Exists(ctx, to) && !termio.AskForConfirmation(ctx, fmt.Sprintf("%s already exists. Overwrite it?", to)) {
-			return ExitError(ExitAborted, nil, "not overwriting your current secret")
+	if !force && !ctxutil.IsDryRun(ctx) {
+		if s.Store.Exists(ctx, to) {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("%s already exists. Overwrite it?", to), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not overwriting your current secret: %s", err)
+			}
+			if !ok {
+				return ExitError(ExitAborted, nil, "not overwriting your current secret")
+			}
 		}
 	}
 
-	if err := s.Store.Move(ctx, from, to); err != nil {
-		return ExitError(ExitUnknown, err, "%s", err)
+	if err := s.Store.Move(ctxutil.WithForce(ctx, force), from, to); err != nil {
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "%s", err)
 	}
 
 	return nil
@@ -2,6 +2,7 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,18 +10,14 @@ import (
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/otp"
+	"github.com/gopasspw/gopass/pkg/qrcon"
 	"github.com/gopasspw/gopass/pkg/termio"
 
 	"github.com/urfave/cli/v2"
 )
 
-const (
-	// we might want to replace this with the currently un-exported step value
-	// from twofactor.FromURL if it gets ever exported
-	otpPeriod = 30
-)
-
 // OTP implements OTP token handling for TOTP and HOTP
 func (s *Action) OTP(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
@@ -29,27 +26,38 @@ func (s *Action) OTP(c *cli.Context) error {
 		return ExitError(ExitUsage, nil, "Usage: %s otp <NAME>", s.Name)
 	}
 
-	qrf := c.String("qr")
+	qrf := c.String("qr-file")
 	clip := c.Bool("clip")
 	pw := c.Bool("password")
+	if c.Bool("qr") {
+		ctx = WithPrintQR(ctx, true)
+	}
 
 	return s.otp(ctx, name, qrf, clip, pw, true)
 }
 
 func (s *Action) otp(ctx context.Context, name, qrf string, clip, pw, recurse bool) error {
-	sec, err := s.Store.Get(ctx, name)
+	target, sec, err := s.Store.Resolve(ctx, name)
 	if err != nil {
 		return s.otpHandleError(ctx, name, qrf, clip, pw, recurse, err)
 	}
-	two, label, err := otp.Calculate(name, sec)
+	// links use the key of their target, so a HOTP counter is saved there
+	name = target
+	key, err := otp.FromSecret(name, sec)
 	if err != nil {
-		return ExitError(ExitUnknown, err, "No OTP entry found for %s: %s", name, err)
+		if errors.Is(err, otp.ErrNoKey) {
+			return ExitError(ExitNotFound, err, "No OTP entry found for %s: %s", name, err)
+		}
+		return ExitError(ExitDecrypt, err, "Invalid OTP entry in %s: %s", name, err)
 	}
-	token := two.OTP()
 
 	now := time.Now()
-	expiresAt := now.Add(otpPeriod * time.Second).Truncate(otpPeriod * time.Second)
-	secondsLeft := int(time.Until(expiresAt).Seconds())
+	token := key.Code(now)
+	if key.Type == otp.TypeHOTP {
+		if err := s.otpIncrementCounter(ctx, name, sec, key); err != nil {
+			return err
+		}
+	}
 
 	if clip {
 		if err := clipboard.CopyTo(ctx, fmt.Sprintf("token for %s", name), []byte(token), s.cfg.ClipTimeout); err != nil {
@@ -57,44 +65,50 @@ func (s *Action) otp(ctx context.Context, name, qrf string, clip, pw, recurse bo
 		}
 	}
 
-	done := make(chan bool)
-	skip := false
-	// check if we are in "password only" or in "qr code" mode or being redirected to a pipe
-	if pw || qrf != "" || out.OutputIsRedirected() {
-		out.Printf(ctx, "%s", token)
-		skip = true
-	} else { // if not then we want to print a progress bar with the expiry time
-		out.Printf(ctx, "%s", token)
-		out.Warningf(ctx, "This OTP password still lasts for:", nil)
-		bar := termio.NewProgressBar(int64(secondsLeft))
-		bar.Hidden = ctxutil.IsHidden(ctx)
-		if bar.Hidden {
-			skip = true
-		} else {
-			bar.Set(0)
-			go func() {
-				ticker := time.NewTicker(1 * time.Second)
-				defer ticker.Stop()
-				for tt := range ticker.C {
-					if tt.After(expiresAt) {
-						bar.Done()
-						done <- true
-						return
-					}
-					bar.Inc()
-				}
-			}()
+	if IsPrintQR(ctx) {
+		qr, err := qrcon.QRCode(key.URL())
+		if err != nil {
+			return ExitError(ExitUnknown, err, "failed to encode %q as QR: %s", name, err)
 		}
+		fmt.Fprintln(stdout, qr)
 	}
 
 	if qrf != "" {
-		return otp.WriteQRFile(two, label, qrf)
+		if err := otp.WriteQRFile(key, qrf); err != nil {
+			return ExitError(ExitIO, err, "failed to write QR code: %s", err)
+		}
+	}
+
+	out.Printf(ctx, "%s", token)
+	// check if we are in "password only" or in "qr code" mode or being redirected to a pipe
+	if pw || qrf != "" || IsPrintQR(ctx) || out.OutputIsRedirected() || key.Type == otp.TypeHOTP {
+		return nil
 	}
 
-	// we need to return if we are skipping, to avoid a deadlock in select
-	if skip {
+	// if not then we want to print a progress bar with the expiry time
+	remaining := key.Remaining(now)
+	expiresAt := now.Add(remaining)
+	out.Warningf(ctx, "This OTP password is valid for %d more seconds:", int(remaining.Seconds()))
+	bar := termio.NewProgressBar(int64(remaining.Seconds()))
+	bar.Hidden = ctxutil.IsHidden(ctx)
+	if bar.Hidden {
 		return nil
 	}
+	bar.Set(0)
+
+	done := make(chan bool)
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for tt := range ticker.C {
+			if tt.After(expiresAt) {
+				bar.Done()
+				done <- true
+				return
+			}
+			bar.Inc()
+		}
+	}()
 
 	// we wait until our ticker is done or we get a cancelation
 	select {
@@ -105,9 +119,24 @@ func (s *Action) otp(ctx context.Context, name, qrf string, clip, pw, recurse bo
 	}
 }
 
+// otpIncrementCounter persists the next HOTP counter value, so every code
+// is only used once
+func (s *Action) otpIncrementCounter(ctx context.Context, name string, sec gopass.Secret, key *otp.Key) error {
+	key.Counter++
+	nsec, err := key.UpdateSecret(sec)
+	if err != nil {
+		return ExitError(exitCodeFor(err, ExitEncrypt), err, "failed to update HOTP counter of %s: %s", name, err)
+	}
+	ctx = ctxutil.WithCommitMessage(ctx, "Increment HOTP counter")
+	if err := s.Store.Set(ctx, name, nsec); err != nil {
+		return ExitError(ExitEncrypt, err, "failed to save HOTP counter of %s: %s", name, err)
+	}
+	return nil
+}
+
 func (s *Action) otpHandleError(ctx context.Context, name, qrf string, clip, pw, recurse bool, err error) error {
 	if err != store.ErrNotFound || !recurse || !ctxutil.IsTerminal(ctx) {
-		return ExitError(ExitUnknown, err, "failed to retrieve secret %q: %s", name, err)
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", name, err)
 	}
 	out.Printf(ctx, "Entry %q not found. Starting search...", name)
 	cb := func(ctx context.Context, c *cli.Context, name string, recurse bool) error {
@@ -12,7 +12,6 @@ import (
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
-	"github.com/gokyle/twofactor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,10 +43,37 @@ func TestOTP(t *testing.T) {
 		defer buf.Reset()
 		sec := &secrets.Plain{}
 		sec.SetPassword("foo")
-		sec.WriteString(twofactor.GenerateGoogleTOTP().URL("foo"))
+		sec.WriteString("otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP&algorithm=SHA256&digits=8&period=60")
 		assert.NoError(t, act.Store.Set(ctx, "bar", sec))
 
 		assert.NoError(t, act.OTP(gptest.CliCtx(ctx, t, "bar")))
+		assert.Regexp(t, `^\d{8}\n`, buf.String())
+	})
+
+	t.Run("invalid OTP URL", func(t *testing.T) {
+		defer buf.Reset()
+		sec := &secrets.Plain{}
+		sec.SetPassword("foo")
+		sec.WriteString("otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP&digits=7")
+		assert.NoError(t, act.Store.Set(ctx, "baz", sec))
+
+		assert.Error(t, act.OTP(gptest.CliCtx(ctx, t, "baz")))
+	})
+
+	t.Run("HOTP increments the counter", func(t *testing.T) {
+		defer buf.Reset()
+		sec := &secrets.Plain{}
+		sec.SetPassword("foo")
+		sec.WriteString("otpauth://hotp/foo?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=0")
+		assert.NoError(t, act.Store.Set(ctx, "hotp", sec))
+
+		assert.NoError(t, act.OTP(gptest.CliCtx(ctx, t, "hotp")))
+		assert.NoError(t, act.OTP(gptest.CliCtx(ctx, t, "hotp")))
+		assert.Equal(t, "755224\n287082\n", buf.String())
+
+		sec2, err := act.Store.Get(ctx, "hotp")
+		require.NoError(t, err)
+		assert.Contains(t, string(sec2.Bytes()), "counter=2")
 	})
 
 	t.Run("copy to clipboard", func(t *testing.T) {
@@ -58,7 +84,18 @@ func TestOTP(t *testing.T) {
 	t.Run("write QR file", func(t *testing.T) {
 		defer buf.Reset()
 		fn := filepath.Join(u.Dir, "qr.png")
-		assert.NoError(t, act.OTP(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr": fn}, "bar")))
+		assert.NoError(t, act.OTP(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr-file": fn}, "bar")))
 		assert.FileExists(t, fn)
 	})
+
+	t.Run("print QR code", func(t *testing.T) {
+		defer buf.Reset()
+		qbuf := &bytes.Buffer{}
+		stdout = qbuf
+		defer func() {
+			stdout = os.Stdout
+		}()
+		assert.NoError(t, act.OTP(gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr": "true"}, "bar")))
+		assert.NotEmpty(t, qbuf.String())
+	})
 }
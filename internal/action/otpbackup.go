@@ -0,0 +1,182 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/importer"
+	"github.com/gopasspw/gopass/internal/otpbackup"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/otp"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+// OTPImport imports the OTP keys of a phone authenticator backup. Every key
+// becomes a secret with the otpauth:// URL in the totp key.
+func (s *Action) OTPImport(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	src := c.Args().First()
+	format := c.String("format")
+	if src == "" || format == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s otp import --format {%s} <file>", s.Name, strings.Join(otpbackup.Formats(), "|"))
+	}
+
+	conflict, err := importConflict(c.String("conflict"))
+	if err != nil {
+		return err
+	}
+
+	prefix := strings.Trim(c.String("prefix"), "/")
+	if err := s.Store.CheckWritable(prefix); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
+	fh, err := os.Open(src)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read %s: %s", src, err)
+	}
+	defer fh.Close()
+
+	keys, err := otpbackup.Read(format, fh, func() (string, error) {
+		return termio.AskForPassword(ctx, "the passphrase of the backup", false)
+	})
+	if err != nil {
+		if errors.Is(err, otpbackup.ErrWrongPassword) {
+			return ExitError(ExitDecrypt, err, "failed to decrypt %s: %s", src, err)
+		}
+		return ExitError(ExitIO, err, "failed to read %s: %s", src, err)
+	}
+
+	layout := c.String("layout")
+	entries := make([]importer.Entry, 0, len(keys))
+	for _, k := range keys {
+		name := otpName(layout, k)
+		u, err := k.URL()
+		if err != nil {
+			// the key is kept, gopass just can't generate its codes
+			out.Warningf(ctx, "gopass can't generate codes for %s, importing it anyway: %s", name, err)
+		}
+		entries = append(entries, importer.Entry{
+			Name:   name,
+			Fields: []importer.Field{{Key: "totp", Value: u}},
+			Notes:  k.Note,
+		})
+	}
+
+	items := s.importPlan(ctx, entries, prefix, conflict)
+	if c.Bool("dry-run") {
+		return s.importPrint(ctx, items, conflict)
+	}
+
+	return s.importSave(ctx, items, conflict, format)
+}
+
+// otpName returns the name of the secret for an OTP key. The layout contains
+// the placeholders {issuer} and {account}, empty folders are dropped.
+func otpName(layout string, k otpbackup.Entry) string {
+	clean := func(p string) string {
+		p = strings.NewReplacer("/", "-", "\\", "-", "\n", " ", "\r", " ").Replace(p)
+		return strings.TrimLeft(strings.TrimSpace(p), ".")
+	}
+	name := strings.NewReplacer("{issuer}", clean(k.Issuer), "{account}", clean(k.Account)).Replace(layout)
+
+	parts := make([]string, 0, 2)
+	for _, p := range strings.Split(name, "/") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) < 1 {
+		return "untitled"
+	}
+	return strings.Join(parts, "/")
+}
+
+// OTPExport writes the OTP keys of all secrets below the given folder to an
+// unencrypted backup of a phone authenticator
+func (s *Action) OTPExport(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	format := c.String("format")
+	if format == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s otp export --format {%s} [folder]", s.Name, strings.Join(otpbackup.Formats(), "|"))
+	}
+	output := c.String("output")
+	if output == "" && ctxutil.IsTerminal(ctx) {
+		return ExitError(ExitUsage, nil, "Refusing to write the backup to the terminal, use --output")
+	}
+
+	prefix := strings.Trim(c.Args().First(), "/")
+	names, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list secrets: %s", err)
+	}
+
+	keys := make([]otpbackup.Entry, 0, len(names))
+	for _, name := range names {
+		if prefix != "" && name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			out.Warningf(ctx, "Skipping %s, failed to decrypt it: %s", name, err)
+			continue
+		}
+		if !otpHasKey(sec) {
+			continue
+		}
+		k, err := otp.FromSecret(name, sec)
+		if err != nil {
+			out.Warningf(ctx, "Skipping %s, invalid OTP key: %s", name, err)
+			continue
+		}
+		keys = append(keys, otpbackup.FromKey(k))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := otpbackup.Write(format, buf, keys); err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	if output == "" {
+		if _, err := stdout.Write(buf.Bytes()); err != nil {
+			return ExitError(ExitIO, err, "failed to write the backup: %s", err)
+		}
+		return nil
+	}
+	if fsutil.IsFile(output) {
+		ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("%s exists. Overwrite it?", output), "--force")
+		if err != nil {
+			return ExitError(ExitAborted, err, "not overwriting %s: %s", output, err)
+		}
+		if !ok {
+			return ExitError(ExitAborted, nil, "not overwriting your current file")
+		}
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0600); err != nil {
+		return ExitError(ExitIO, err, "failed to write %s: %s", output, err)
+	}
+	out.OKf(ctx, "Exported %d OTP keys to %s", len(keys), output)
+	out.Warningf(ctx, "The backup is not encrypted. Remove it once it's imported")
+	return nil
+}
+
+// otpHasKey returns true if the secret has an OTP key. otp.FromSecret falls
+// back to the password, which would export every password that happens to be
+// valid base32.
+func otpHasKey(sec gopass.Secret) bool {
+	for _, k := range []string{"totp", "otpauth"} {
+		if _, found := sec.Get(k); found {
+			return true
+		}
+	}
+	return strings.Contains(string(sec.Bytes()), "otpauth://")
+}
@@ -0,0 +1,102 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/otpbackup"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/otp"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPBackup(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	backup := filepath.Join(u.Dir, "andotp.json")
+	require.NoError(t, os.WriteFile(backup, []byte(`[
+  {"secret": "JBSWY3DPEHPK3PXP", "issuer": "GitHub", "label": "jane@example.com", "digits": 6, "type": "TOTP", "algorithm": "SHA1", "period": 30, "tags": []},
+  {"secret": "GEZDGNBVGY3TQOJQ", "issuer": "Steam", "label": "gamer", "digits": 5, "type": "STEAM", "algorithm": "SHA1", "period": 30, "tags": []}
+]`), 0600))
+
+	t.Run("missing format", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.OTPImport(gptest.CliCtx(ctx, t, backup)))
+	})
+
+	t.Run("import", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.OTPImport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "andotp", "prefix": "otp", "layout": "{issuer}/{account}"}, backup)))
+		assert.Contains(t, buf.String(), "gopass can't generate codes for Steam/gamer")
+		assert.Contains(t, buf.String(), "Imported 2 secrets")
+
+		sec, err := act.Store.Get(ctx, "otp/GitHub/jane@example.com")
+		require.NoError(t, err)
+		k, err := otp.FromSecret("otp/GitHub/jane@example.com", sec)
+		require.NoError(t, err)
+		assert.Equal(t, "GitHub", k.Issuer)
+
+		// unsupported keys are kept
+		sec, err = act.Store.Get(ctx, "otp/Steam/gamer")
+		require.NoError(t, err)
+		v, _ := sec.Get("totp")
+		assert.Contains(t, v, "otpauth://steam/")
+	})
+
+	t.Run("duplicates", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.OTPImport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "andotp", "prefix": "otp", "layout": "{issuer}/{account}", "conflict": "rename"}, backup)))
+		assert.True(t, act.Store.Exists(ctx, "otp/GitHub/jane@example.com-1"))
+	})
+
+	t.Run("export", func(t *testing.T) {
+		defer buf.Reset()
+		fn := filepath.Join(u.Dir, "aegis.json")
+		require.NoError(t, act.OTPExport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "aegis", "output": fn}, "otp/GitHub")))
+		assert.Contains(t, buf.String(), "Exported 2 OTP keys")
+
+		fh, err := os.Open(fn)
+		require.NoError(t, err)
+		defer fh.Close()
+		keys, err := otpbackup.ReadAegis(fh, nil)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "GitHub", keys[0].Issuer)
+		assert.Equal(t, "jane@example.com", keys[0].Account)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", keys[0].Secret)
+	})
+
+	t.Run("export skips passwords", func(t *testing.T) {
+		defer buf.Reset()
+		// secrets without a totp key or an otpauth URL are skipped
+		require.NoError(t, act.OTPExport(gptest.CliCtxWithFlags(ctx, t, map[string]string{"format": "andotp"}, "foo")))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+}
@@ -0,0 +1,95 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/urfave/cli/v2"
+)
+
+// PathAliasesPrint prints all path aliases
+func (s *Action) PathAliasesPrint(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	names := s.cfg.AliasNames()
+	if len(names) < 1 {
+		out.Printf(ctx, "No aliases")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Fprintf(stdout, "%s -> %s\n", name, s.cfg.Aliases[name])
+	}
+	return nil
+}
+
+// PathAliasAdd defines a short name for a path prefix
+func (s *Action) PathAliasAdd(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	target := c.Args().Get(1)
+	if name == "" || target == "" || c.Args().Len() > 2 {
+		return ExitError(ExitUsage, nil, "Usage: %s alias add <name> <path>", s.Name)
+	}
+	// alias add <domain> <alias> used to add domain aliases
+	if isDomainLike(name) && isDomainLike(target) && !s.Store.Exists(ctx, target) && !s.Store.IsDir(ctx, target) {
+		return ExitError(ExitUsage, nil, "%s and %s look like domains, but %s is no entry or folder. Domain aliases are added with `%s alias domains add %s %s`", name, target, target, s.Name, name, target)
+	}
+
+	if err := s.cfg.AddAlias(name, target); err != nil {
+		return ExitError(ExitConfig, err, "failed to add alias %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Added alias %s -> %s", name, s.cfg.Aliases[name])
+	// the alias wins over existing entries with the same name
+	if s.isHiddenByAlias(ctx, name) {
+		out.Warningf(ctx, "The alias %s hides the existing entries at %s", name, name)
+	}
+	return nil
+}
+
+// PathAliasRemove removes a path alias
+func (s *Action) PathAliasRemove(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" || c.Args().Len() > 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s alias remove <name>", s.Name)
+	}
+
+	if err := s.cfg.RemoveAlias(name); err != nil {
+		return ExitError(ExitConfig, err, "failed to remove alias %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Removed alias %s", name)
+	return nil
+}
+
+// PathAliasesComplete prints all alias names for bash completion
+func (s *Action) PathAliasesComplete(*cli.Context) {
+	for _, name := range s.cfg.AliasNames() {
+		fmt.Fprintln(stdout, name)
+	}
+}
+
+// isDomainLike returns true if name looks like a domain name, e.g.
+// example.org, rather than a path
+func isDomainLike(name string) bool {
+	return strings.Contains(strings.Trim(name, "."), ".") && !strings.Contains(name, "/")
+}
+
+// isHiddenByAlias returns true if there are entries at name that can't be
+// accessed while name is an alias
+func (s *Action) isHiddenByAlias(ctx context.Context, name string) bool {
+	l, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return false
+	}
+	for _, e := range l {
+		if e == name || strings.HasPrefix(e, name+"/") {
+			return true
+		}
+	}
+	return false
+}
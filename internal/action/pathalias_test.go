@@ -0,0 +1,96 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathAliases(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("no aliases", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.PathAliasesPrint(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "No aliases\n", buf.String())
+	})
+
+	t.Run("add alias", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.PathAliasAdd(gptest.CliCtx(ctx, t, "prod")))
+		assert.NoError(t, act.PathAliasAdd(gptest.CliCtx(ctx, t, "prod", "customers/acme/production")))
+		assert.NoError(t, act.PathAliasAdd(gptest.CliCtx(ctx, t, "web", "prod/web")))
+		assert.Error(t, act.PathAliasAdd(gptest.CliCtx(ctx, t, "prod", "web/prod")))
+		assert.Equal(t, "customers/acme/production", act.cfg.Aliases["prod"])
+	})
+
+	t.Run("old domain alias form", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.PathAliasAdd(gptest.CliCtx(ctx, t, "example.org", "example.com"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "alias domains add example.org example.com")
+		assert.NotContains(t, act.cfg.Aliases, "example.org")
+	})
+
+	t.Run("alias hiding entries", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.PathAliasAdd(gptest.CliCtx(ctx, t, "foo", "bar")))
+		assert.Contains(t, buf.String(), "The alias foo hides the existing entries at foo")
+		assert.NoError(t, act.PathAliasRemove(gptest.CliCtx(ctx, t, "foo")))
+	})
+
+	t.Run("print aliases", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.PathAliasesPrint(gptest.CliCtx(ctx, t)))
+		assert.Equal(t, "prod -> customers/acme/production\nweb -> prod/web\n", buf.String())
+	})
+
+	t.Run("list shows aliases", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.List(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "prod -> customers/acme/production\n")
+	})
+
+	t.Run("complete aliases", func(t *testing.T) {
+		defer buf.Reset()
+		act.Complete(gptest.CliCtx(ctx, t))
+		assert.Contains(t, buf.String(), "prod/\n")
+		buf.Reset()
+		act.PathAliasesComplete(gptest.CliCtx(ctx, t))
+		assert.Equal(t, "prod\nweb\n", buf.String())
+	})
+
+	t.Run("remove alias", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.PathAliasRemove(gptest.CliCtx(ctx, t)))
+		assert.NoError(t, act.PathAliasRemove(gptest.CliCtx(ctx, t, "web")))
+		assert.Error(t, act.PathAliasRemove(gptest.CliCtx(ctx, t, "web")))
+		assert.Equal(t, []string{"prod"}, act.cfg.AliasNames())
+	})
+}
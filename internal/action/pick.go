@@ -0,0 +1,120 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/picker"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/autotype"
+	"github.com/gopasspw/gopass/pkg/clipboard"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/otp"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Pick lets the user select a secret with a dmenu style picker and copies or
+// types its password, the value of a key or its OTP code. Nothing is printed
+// to stdout, cancelling the picker exits with status 1 without a message.
+func (s *Action) Pick(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	key := c.String("key")
+
+	if c.Bool("type") && c.Bool("clip") {
+		return ExitError(ExitUsage, nil, "--type and --clip can not be combined")
+	}
+	if c.Bool("otp") && key != "" {
+		return ExitError(ExitUsage, nil, "--otp and --key can not be combined")
+	}
+
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list secrets: %s", err)
+	}
+	if len(list) < 1 {
+		return ExitError(ExitNotFound, nil, "no secrets to pick from")
+	}
+
+	args, err := picker.Command(s.cfg.PickerCmd, s.Name)
+	if err != nil {
+		return ExitError(ExitUnsupported, err, "%s", err)
+	}
+	name, err := picker.Pick(ctx, args, list)
+	if errors.Is(err, picker.ErrCancelled) {
+		return ExitError(ExitAborted, err, "")
+	}
+	if err != nil {
+		return ExitError(ExitUnknown, err, "%s", err)
+	}
+
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ExitError(ExitNotFound, err, "%s not found", name)
+		}
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to read %s: %s", name, err)
+	}
+
+	value, what, err := s.pickValue(ctx, name, sec, key, c.Bool("otp"))
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("type") || (!c.Bool("clip") && s.cfg.AutoType && !autotype.IsSSH()) {
+		steps := []autotype.Step{{Text: value}}
+		if key == "" && !c.Bool("otp") {
+			steps, err = autotype.Sequence(sec)
+			if err != nil {
+				return ExitError(ExitUsage, err, "invalid autotype sequence in %s: %s", name, err)
+			}
+		}
+		if err := autotype.Type(ctx, what, steps); err != nil {
+			return ExitError(ExitUnsupported, err, "failed to type %s: %s", what, err)
+		}
+		return nil
+	}
+
+	if err := clipboard.CopyTo(ctx, what, []byte(value), s.cfg.ClipTimeout); err != nil {
+		return ExitError(ExitIO, err, "failed to copy to clipboard: %s", err)
+	}
+	return nil
+}
+
+// pickValue returns the password, the value of the key or the OTP code of
+// the picked secret and how to refer to it in messages
+func (s *Action) pickValue(ctx context.Context, name string, sec gopass.Secret, key string, useOTP bool) (string, string, error) {
+	if useOTP {
+		k, err := otp.FromSecret(name, sec)
+		if err != nil {
+			if errors.Is(err, otp.ErrNoKey) {
+				return "", "", ExitError(ExitNotFound, err, "No OTP entry found for %s: %s", name, err)
+			}
+			return "", "", ExitError(ExitDecrypt, err, "Invalid OTP entry in %s: %s", name, err)
+		}
+		token := k.Code(time.Now())
+		if k.Type == otp.TypeHOTP {
+			if err := s.otpIncrementCounter(ctx, name, sec, k); err != nil {
+				return "", "", err
+			}
+		}
+		return token, fmt.Sprintf("token for %s", name), nil
+	}
+
+	if key != "" {
+		value, found := sec.Get(key)
+		if !found || value == "" {
+			return "", "", ExitError(ExitNotFound, store.ErrEmptySecret, "key %q of %s is empty", key, name)
+		}
+		return value, fmt.Sprintf("%s of %s", key, name), nil
+	}
+
+	if sec.Password() == "" {
+		return "", "", ExitError(ExitNotFound, store.ErrEmptySecret, "%s has no password", name)
+	}
+	return sec.Password(), name, nil
+}
@@ -0,0 +1,153 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/atotto/clipboard"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/autotype"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestPick(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake picker and xdotool are shell scripts")
+	}
+
+	ov := clipboard.Unsupported
+	defer func() {
+		clipboard.Unsupported = ov
+	}()
+	clipboard.Unsupported = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = autotype.WithCountdown(ctx, 0)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("user", "admin"))
+	require.NoError(t, act.Store.Set(ctx, "web/example.com", sec))
+
+	otpSec := &secrets.Plain{}
+	otpSec.SetPassword("otp-pass")
+	otpSec.WriteString("otpauth://hotp/foo?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=0")
+	require.NoError(t, act.Store.Set(ctx, "web/otp", otpSec))
+
+	// a fake xdotool records what is typed
+	bin := t.TempDir()
+	typed := filepath.Join(bin, "typed")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "xdotool"), []byte("#!/bin/sh\ncat >> "+typed+"\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("XDG_SESSION_TYPE", "")
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		t.Setenv(key, "")
+	}
+
+	act.cfg.PickerCmd = "grep example.com"
+
+	t.Run("copy the password", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Pick(gptest.CliCtx(ctx, t)))
+		assert.NotContains(t, buf.String(), "s3cret")
+	})
+
+	t.Run("type a key", func(t *testing.T) {
+		defer buf.Reset()
+		defer os.Remove(typed)
+		require.NoError(t, act.Pick(gptest.CliCtxWithFlags(ctx, t, map[string]string{"type": "true", "key": "user"})))
+		content, err := os.ReadFile(typed)
+		require.NoError(t, err)
+		assert.Equal(t, "admin", string(content))
+		assert.NotContains(t, buf.String(), "admin")
+	})
+
+	t.Run("autotype setting types the password", func(t *testing.T) {
+		defer buf.Reset()
+		defer os.Remove(typed)
+		act.cfg.AutoType = true
+		defer func() {
+			act.cfg.AutoType = false
+		}()
+		require.NoError(t, act.Pick(gptest.CliCtx(ctx, t)))
+		content, err := os.ReadFile(typed)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", string(content))
+	})
+
+	t.Run("type the OTP code", func(t *testing.T) {
+		defer buf.Reset()
+		defer os.Remove(typed)
+		act.cfg.PickerCmd = "grep otp"
+		defer func() {
+			act.cfg.PickerCmd = "grep example.com"
+		}()
+		require.NoError(t, act.Pick(gptest.CliCtxWithFlags(ctx, t, map[string]string{"type": "true", "otp": "true"})))
+		content, err := os.ReadFile(typed)
+		require.NoError(t, err)
+		assert.Equal(t, "755224", string(content))
+		assert.Empty(t, buf.String())
+
+		// the HOTP counter was incremented
+		sec, err := act.Store.Get(ctx, "web/otp")
+		require.NoError(t, err)
+		assert.Contains(t, string(sec.Bytes()), "counter=1")
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Pick(gptest.CliCtxWithFlags(ctx, t, map[string]string{"key": "email"})))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.PickerCmd = "false"
+		defer func() {
+			act.cfg.PickerCmd = "grep example.com"
+		}()
+		err := act.Pick(gptest.CliCtx(ctx, t))
+		require.Error(t, err)
+		var ec cli.ExitCoder
+		require.True(t, errors.As(err, &ec))
+		assert.Equal(t, ExitAborted, ec.ExitCode())
+		assert.Equal(t, "", err.Error())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("conflicting flags", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Pick(gptest.CliCtxWithFlags(ctx, t, map[string]string{"type": "true", "clip": "true"})))
+		assert.Error(t, act.Pick(gptest.CliCtxWithFlags(ctx, t, map[string]string{"otp": "true", "key": "user"})))
+	})
+}
@@ -0,0 +1,43 @@
+package action
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/policy"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+)
+
+// checkPolicy rejects a password violating the policy of the folder of the
+// secret, unless --force-weak is set. Then the violated rules are recorded in
+// the commit of the returned context. Passwords that don't change aren't
+// checked, existing violations are reported by audit.
+func (s *Action) checkPolicy(ctx context.Context, name, pw string) (context.Context, error) {
+	p, found, err := s.Store.PasswordPolicy(ctx, name)
+	if err != nil {
+		return ctx, ExitError(ExitConfig, err, "failed to check the password policy for %s: %s", name, err)
+	}
+	if !found {
+		return ctx, nil
+	}
+	vs := p.Check(pw, name)
+	if len(vs) == 0 {
+		return ctx, nil
+	}
+	if s.Store.Exists(ctx, name) {
+		if sec, err := s.Store.Get(ctx, name); err == nil && sec.Password() == pw {
+			return ctx, nil
+		}
+	}
+
+	failed := make([]string, 0, len(vs))
+	for _, v := range vs {
+		failed = append(failed, v.String())
+	}
+	if !IsForceWeak(ctx) {
+		return ctx, ExitError(ExitUsage, nil, "The password for %s violates the policy for %s/: %s. Pass --force-weak to save it anyway", name, p.Prefix, strings.Join(failed, "; "))
+	}
+	out.Warningf(ctx, "Saving a password for %s that violates the policy for %s/: %s", name, p.Prefix, strings.Join(failed, "; "))
+	return leaf.WithWeakPassword(ctx, policy.Rules(vs)), nil
+}
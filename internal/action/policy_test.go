@@ -0,0 +1,97 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.AutoClip = false
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	fn := filepath.Join(u.StoreDir(""), ".gopass", "policy", "infra", "prod")
+	require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0700))
+	require.NoError(t, os.WriteFile(fn, []byte("min-length: 16\nrequired: symbol\n"), 0600))
+
+	t.Run("reject a weak password", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.insertStdin(ctx, "infra/prod/db", []byte("short\n"), false)
+		require.Error(t, err)
+		assert.Equal(t, "The password for infra/prod/db violates the policy for infra/prod/: min-length: 16 (has 5); required: symbol. Pass --force-weak to save it anyway", err.Error())
+		assert.False(t, act.Store.Exists(ctx, "infra/prod/db"))
+
+		assert.Error(t, act.Generate(gptest.CliCtx(ctx, t, "infra/prod/gen", "8")))
+		assert.False(t, act.Store.Exists(ctx, "infra/prod/gen"))
+	})
+
+	t.Run("reject an empty password", func(t *testing.T) {
+		defer buf.Reset()
+		_, err := act.checkPolicy(ctx, "infra/prod/empty", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "min-length: 16 (has 0)")
+
+		assert.Error(t, act.insertStdin(ctx, "infra/prod/empty", []byte("\nuser: admin\n"), false))
+		assert.False(t, act.Store.Exists(ctx, "infra/prod/empty"))
+	})
+
+	t.Run("other folders have no policy", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.insertStdin(ctx, "infra/staging/db", []byte("short\n"), false))
+	})
+
+	t.Run("force a weak password", func(t *testing.T) {
+		defer buf.Reset()
+		fctx, err := act.checkPolicy(WithForceWeak(ctx, true), "infra/prod/db", "short")
+		require.NoError(t, err)
+		assert.Equal(t, "min-length: 16; required: symbol", leaf.GetWeakPassword(fctx))
+		assert.Contains(t, buf.String(), "Saving a password for infra/prod/db that violates the policy for infra/prod/")
+
+		assert.NoError(t, act.insertStdin(WithForceWeak(ctx, true), "infra/prod/db", []byte("short\n"), false))
+		assert.True(t, act.Store.Exists(ctx, "infra/prod/db"))
+	})
+
+	t.Run("unchanged passwords are not checked", func(t *testing.T) {
+		defer buf.Reset()
+		fctx, err := act.checkPolicy(ctx, "infra/prod/db", "short")
+		require.NoError(t, err)
+		assert.Equal(t, "", leaf.GetWeakPassword(fctx))
+	})
+
+	t.Run("audit reports violations", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Audit(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "Policy violations (severity: high)")
+		assert.Contains(t, buf.String(), "Violates the policy for infra/prod/: min-length: 16; required: symbol")
+		assert.Contains(t, buf.String(), "\t- infra/prod/db\n")
+	})
+}
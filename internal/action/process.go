@@ -0,0 +1,82 @@
+package action
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tpl"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Process renders a config file template from a file or stdin with the
+// secrets inlined
+func (s *Action) Process(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	name := c.Args().First()
+	var buf []byte
+	var err error
+	if name == "" || name == "-" {
+		if !ctxutil.IsStdin(ctx) {
+			return ExitError(ExitUsage, nil, "Usage: %s process <template-file> or pipe the template to stdin", s.Name)
+		}
+		name = "stdin"
+		buf, err = io.ReadAll(stdin)
+	} else {
+		buf, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read template %s: %s", name, err)
+	}
+
+	res, err := tpl.Process(ctx, name, buf, s.Store, c.String("missingkey"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ExitError(ExitNotFound, err, "failed to process %s: %s", name, err)
+		}
+		return ExitError(exitCodeFor(err, ExitUnknown), err, "failed to process %s: %s", name, err)
+	}
+
+	// the file is only written after the template was rendered completely,
+	// so a failed render never leaves a partial config behind
+	dst := c.String("output")
+	if dst == "" {
+		if _, err := stdout.Write(res); err != nil {
+			return ExitError(ExitIO, err, "failed to write output: %s", err)
+		}
+		return nil
+	}
+	if err := writePrivate(dst, res); err != nil {
+		return ExitError(ExitIO, err, "failed to write %s: %s", dst, err)
+	}
+	return nil
+}
+
+// writePrivate replaces the file with one only readable by the user. The
+// content is written to a temp file in the same directory first, which is
+// created with mode 0600, and renamed into place, so the secrets are never
+// readable with the mode of an existing file.
+func writePrivate(fn string, buf []byte) error {
+	fh, err := os.CreateTemp(filepath.Dir(fn), "."+filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err := fh.Write(buf); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
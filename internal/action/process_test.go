@@ -0,0 +1,103 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestProcess(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+		stdin = os.Stdin
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("user", "admin"))
+	require.NoError(t, act.Store.Set(ctx, "infra/db", sec))
+
+	dir := t.TempDir()
+	tpl := filepath.Join(dir, "db.env.tpl")
+	require.NoError(t, os.WriteFile(tpl, []byte("DB_USER={{ gopassKey \"infra/db\" \"user\" }}\nDB_PASSWORD={{ gopass \"infra/db\" }}\n"), 0644))
+
+	t.Run("no template", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Process(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Process(gptest.CliCtx(ctx, t, tpl)))
+		assert.Equal(t, "DB_USER=admin\nDB_PASSWORD=s3cret\n", buf.String())
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		defer buf.Reset()
+		stdin = strings.NewReader(`{{ gopass "infra/db" }}`)
+		require.NoError(t, act.Process(gptest.CliCtx(ctxutil.WithStdin(ctx, true), t)))
+		assert.Equal(t, "s3cret", buf.String())
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		defer buf.Reset()
+		dst := filepath.Join(dir, "db.env")
+		require.NoError(t, os.WriteFile(dst, []byte("old"), 0644))
+
+		require.NoError(t, act.Process(gptest.CliCtxWithFlags(ctx, t, map[string]string{"output": dst}, tpl)))
+		content, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		assert.Equal(t, "DB_USER=admin\nDB_PASSWORD=s3cret\n", string(content))
+		fi, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		defer buf.Reset()
+		missing := filepath.Join(dir, "missing.tpl")
+		require.NoError(t, os.WriteFile(missing, []byte(`PASSWORD={{ gopass "infra/web" }}`), 0644))
+		dst := filepath.Join(dir, "missing.env")
+
+		err := act.Process(gptest.CliCtxWithFlags(ctx, t, map[string]string{"output": dst}, missing))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "infra/web")
+		var ec cli.ExitCoder
+		require.True(t, errors.As(err, &ec))
+		assert.Equal(t, ExitNotFound, ec.ExitCode())
+		assert.NoFileExists(t, dst)
+
+		require.NoError(t, act.Process(gptest.CliCtxWithFlags(ctx, t, map[string]string{"missingkey": "zero"}, missing)))
+		assert.Equal(t, "PASSWORD=", buf.String())
+	})
+}
@@ -43,6 +43,10 @@ func GetCommands() []*cli.Command {
 					Aliases: []string{"x"},
 					Usage:   "Use multiple random english words combined to a password. By default, space is used as separator and all words are lowercase",
 				},
+				&cli.BoolFlag{
+					Name:  "pronounceable",
+					Usage: "Generate pronounceable passwords of alternating consonants and vowels, like the default of pwgen(1). They are easier to read out, but weaker per character",
+				},
 				&cli.StringFlag{
 					Name:    "sep",
 					Aliases: []string{"xkcdsep", "xs"},
@@ -55,6 +59,19 @@ func GetCommands() []*cli.Command {
 					Usage:   "Language to generate password from, currently de (german) and en (english, default) are supported",
 					Value:   "en",
 				},
+				&cli.IntFlag{
+					Name:  "words",
+					Usage: "Number of words of the generated xkcd style password",
+					Value: 4,
+				},
+				&cli.BoolFlag{
+					Name:  "capitalize",
+					Usage: "Capitalize the first character of each word of the xkcd style password, or one letter of a pronounceable password",
+				},
+				&cli.BoolFlag{
+					Name:  "digit",
+					Usage: "Append a random digit to the xkcd style password, or insert one into a pronounceable password",
+				},
 			},
 		},
 	}
@@ -6,9 +6,12 @@ package pwgen
 
 import (
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/gopasspw/gopass/internal/action"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/pkg/fsutil"
 	"github.com/gopasspw/gopass/pkg/pwgen"
 	"github.com/gopasspw/gopass/pkg/pwgen/xkcdgen"
 	"github.com/urfave/cli/v2"
@@ -39,21 +42,77 @@ func Pwgen(c *cli.Context) error {
 		}
 	}
 
-	if c.Bool("xkcd") {
+	// the default generator of gopass generate is used unless a mode is
+	// selected explicitly
+	mode := ""
+	if !c.IsSet("xkcd") && !c.IsSet("pronounceable") {
+		mode = config.LoadWithFallbackRelaxed().GenerateMode
+	}
+
+	if c.Bool("xkcd") || mode == "xkcd" {
 		return xkcdGen(c, pwNum)
 	}
+	if c.Bool("pronounceable") || mode == "pronounceable" {
+		return pronounceableGen(c, pwLen, pwNum)
+	}
 
 	return pwGen(c, pwLen, pwNum)
 }
 
 func xkcdGen(c *cli.Context, num int) error {
+	opts := xkcdgen.Options{
+		Words:      c.Int("words"),
+		Separator:  c.String("sep"),
+		Capitalize: c.Bool("capitalize") || c.String("sep") == "",
+		Digit:      c.Bool("digit"),
+		Lang:       c.String("lang"),
+	}
+	if fn := config.LoadWithFallbackRelaxed().WordlistFile; fn != "" {
+		wl, err := xkcdgen.LoadWordlist(fsutil.CleanPath(fn))
+		if err != nil {
+			return action.ExitError(action.ExitConfig, err, "Failed to load wordlist: %s", err)
+		}
+		opts.Wordlist = wl
+	}
+
+	var bits float64
 	for i := 0; i < num; i++ {
-		s, err := xkcdgen.RandomLengthDelim(4, c.String("sep"), c.String("lang"))
+		s, b, err := xkcdgen.Generate(opts)
 		if err != nil {
-			return err
+			return action.ExitError(action.ExitUsage, err, "Failed to generate passphrase: %s", err)
 		}
+		bits = b
 		fmt.Println(s)
 	}
+	// stderr, so the output can still be piped
+	fmt.Fprintf(os.Stderr, "Entropy: ~%.1f bits per passphrase\n", bits)
+	return nil
+}
+
+func pronounceableGen(c *cli.Context, pwLen, pwNum int) error {
+	perLine := numPerLine(pwLen)
+	if c.Bool("one-per-line") || perLine < 1 {
+		perLine = 1
+	}
+	opts := pwgen.PronounceableOptions{
+		Length:  pwLen,
+		Capital: c.Bool("capitalize"),
+		Digit:   c.Bool("digit"),
+	}
+
+	for i := 0; i < pwNum; i++ {
+		for j := 0; j < perLine; j++ {
+			pw, _, err := pwgen.GeneratePronounceable(opts)
+			if err != nil {
+				return action.ExitError(action.ExitUsage, err, "Failed to generate password: %s", err)
+			}
+			fmt.Print(pw)
+			fmt.Print(" ")
+		}
+		fmt.Println()
+	}
+	// stderr, so the output can still be piped
+	fmt.Fprintf(os.Stderr, "Entropy: ~%.1f bits per password\n", pwgen.PronounceableEntropy(opts))
 	return nil
 }
 
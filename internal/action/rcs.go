@@ -119,7 +119,7 @@ func (s *Action) RCSRemoveRemote(c *cli.Context) error {
 
 // RCSPull pulls from a git remote
 func (s *Action) RCSPull(c *cli.Context) error {
-	ctx := ctxutil.WithGlobalFlags(c)
+	ctx := ctxutil.WithExplicitSync(ctxutil.WithGlobalFlags(c), true)
 	store := c.String("store")
 	origin := c.Args().Get(0)
 	branch := c.Args().Get(1)
@@ -129,7 +129,7 @@ func (s *Action) RCSPull(c *cli.Context) error {
 
 // RCSPush pushes to a git remote
 func (s *Action) RCSPush(c *cli.Context) error {
-	ctx := ctxutil.WithGlobalFlags(c)
+	ctx := ctxutil.WithExplicitSync(ctxutil.WithGlobalFlags(c), true)
 	store := c.String("store")
 	origin := c.Args().Get(0)
 	branch := c.Args().Get(1)
@@ -152,3 +152,19 @@ func (s *Action) RCSStatus(c *cli.Context) error {
 
 	return s.Store.RCSStatus(ctx, store)
 }
+
+// RCSGC packs the git repository of a store
+func (s *Action) RCSGC(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	store := c.String("store")
+
+	if err := s.Store.RCSGC(ctx, store, c.Bool("aggressive")); err != nil {
+		if errors.Is(err, backend.ErrNotSupported) {
+			out.Noticef(ctx, "%s", err)
+			return nil
+		}
+		return ExitError(ExitGit, err, "failed to run git gc: %s", err)
+	}
+	out.OKf(ctx, "Packed git repository")
+	return nil
+}
@@ -59,4 +59,10 @@ func TestGit(t *testing.T) {
 	// GitPush
 	assert.Error(t, act.RCSPush(c))
 	buf.Reset()
+
+	// GitGC
+	assert.NoError(t, act.RCSGC(gptest.CliCtxWithFlags(ctx, t, map[string]string{"aggressive": "true"})))
+	// the mock store has no git repository
+	assert.Contains(t, buf.String(), "can not be packed")
+	buf.Reset()
 }
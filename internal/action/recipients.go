@@ -1,9 +1,17 @@
 package action
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/gopasspw/gopass/internal/cui"
@@ -37,17 +45,124 @@ credentials.
 // RecipientsPrint prints all recipients per store
 func (s *Action) RecipientsPrint(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
+	if c.Bool("verbose") {
+		ctx = ctxutil.WithVerbose(ctx, true)
+	}
+	format, err := outputFormat(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	if c.IsSet("path") {
+		store, dir := s.recipientsScope(c)
+		return s.recipientsPath(ctx, store, dir, format)
+	}
+	if c.Bool("tree") {
+		if out.IsStructured(format) {
+			return ExitError(ExitUsage, nil, "--tree only supports the text format")
+		}
+		s.recipientsScopeTree(ctx)
+		return nil
+	}
+	if out.IsStructured(format) {
+		return s.recipientsStructured(ctx, format)
+	}
 	out.Printf(ctx, "Hint: run 'gopass sync' to import any missing public keys")
 
-	t, err := s.Store.RecipientsTree(ctx, true)
-	if err != nil {
-		return ExitError(ExitList, err, "failed to list recipients: %s", err)
+	if err := s.recipientsTable(ctx); err != nil {
+		return ExitError(ExitIO, err, "failed to print recipients: %s", err)
 	}
 
-	fmt.Fprintln(stdout, t.Format(tree.INF))
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	s.printRecipientStrength(ctx, stores...)
 	return nil
 }
 
+// printExpiringRecipients warns about any recipient of the given stores whose
+// key will expire within the configured window
+func (s *Action) printExpiringRecipients(ctx context.Context, stores ...string) {
+	if s.cfg.ExpiryWarn < 1 {
+		return
+	}
+	d := time.Duration(s.cfg.ExpiryWarn) * 24 * time.Hour
+	for _, store := range stores {
+		prefix := ""
+		if store != "" {
+			prefix = "[" + store + "] "
+		}
+		for _, k := range s.Store.ExpiringRecipients(ctx, store, d) {
+			out.Warningf(ctx, "%sRecipient key expires within %d days: %s", prefix, s.cfg.ExpiryWarn, k)
+		}
+	}
+}
+
+// printRecipientStrength classifies the keys of all recipients of the given
+// stores, including those of folders with their own recipients, and prints a
+// summary per mount. Legacy and weak keys are listed. It returns the number
+// of weak keys.
+func (s *Action) printRecipientStrength(ctx context.Context, stores ...string) int {
+	weak := 0
+	for _, store := range stores {
+		kl, ok := s.Store.Crypto(ctx, store).(publicKeyLookup)
+		if !ok {
+			continue
+		}
+		prefix := ""
+		if store != "" {
+			prefix = "[" + store + "] "
+		}
+		weak += printKeyStrength(ctx, prefix, kl, allRecipients(s.Store.ListRecipientScopes(ctx, store)))
+	}
+	return weak
+}
+
+// printKeyStrength prints the legacy and weak keys among the given ones and a
+// summary. It returns the number of weak keys.
+func printKeyStrength(ctx context.Context, prefix string, kl publicKeyLookup, ids []string) int {
+	counts := make(map[string]int, 4)
+	for _, id := range ids {
+		k, found := kl.PublicKey(ctx, id)
+		if !found {
+			continue
+		}
+		st := k.Strength()
+		counts[st]++
+		switch st {
+		case gpg.StrengthWeak:
+			out.Warningf(ctx, "%sWeak recipient key (%s), it should be replaced: %s", prefix, k.WeakestAlgorithm(), k.OneLine())
+		case gpg.StrengthLegacy:
+			out.Noticef(ctx, "%sLegacy recipient key (%s), consider replacing it: %s", prefix, k.WeakestAlgorithm(), k.OneLine())
+		}
+	}
+	if len(counts) < 1 {
+		return 0
+	}
+
+	summary := fmt.Sprintf("%sRecipient keys: %d ok, %d legacy, %d weak", prefix, counts[gpg.StrengthOK], counts[gpg.StrengthLegacy], counts[gpg.StrengthWeak])
+	if n := counts[gpg.StrengthUnknown]; n > 0 {
+		summary += fmt.Sprintf(", %d unknown", n)
+	}
+	out.Printf(ctx, "%s", summary)
+	return counts[gpg.StrengthWeak]
+}
+
+// allRecipients returns the recipients of all scopes of a store, sorted and
+// without duplicates
+func allRecipients(scopes map[string][]string) []string {
+	seen := make(map[string]bool, len(scopes))
+	res := make([]string, 0, len(scopes))
+	for _, ids := range scopes {
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
 func (s *Action) recipientsList(ctx context.Context) []string {
 	t, err := s.Store.RecipientsTree(ctxutil.WithHidden(ctx, true), false)
 	if err != nil {
@@ -67,15 +182,40 @@ func (s *Action) RecipientsComplete(c *cli.Context) {
 	}
 }
 
+// RecipientsAck accepts the current recipients of the selected or all stores
+func (s *Action) RecipientsAck(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	stores := []string{c.String("store")}
+	if !c.IsSet("store") {
+		stores = append([]string{""}, s.Store.MountPoints()...)
+	}
+
+	for _, store := range stores {
+		sub, err := s.Store.GetSubStore(store)
+		if err != nil {
+			return ExitError(ExitMount, err, "failed to get store %q: %s", store, err)
+		}
+		if err := sub.AckRecipients(ctx); err != nil {
+			return ExitError(ExitRecipients, err, "failed to acknowledge the recipients of %q: %s", store, err)
+		}
+	}
+	return nil
+}
+
+type recipientResolver interface {
+	ResolveRecipient(ctx context.Context, query string) (string, error)
+}
+
 // RecipientsAdd adds new recipients
 func (s *Action) RecipientsAdd(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
-	store := c.String("store")
+	store, dir := s.recipientsScope(c)
 	force := c.Bool("force")
 	added := 0
 
 	// select store
-	if store == "" {
+	if store == "" && dir == "" {
 		store = cui.AskForStore(ctx, s.Store)
 	}
 
@@ -92,9 +232,27 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 		recipients = r
 	}
 
+	if err := s.recipientsWarmup(ctx, c, store, dir); err != nil {
+		return err
+	}
+
 	debug.Log("adding recipients: %+v", recipients)
 	for _, r := range recipients {
+		if rr, ok := crypto.(recipientResolver); ok {
+			fp, err := rr.ResolveRecipient(ctx, r)
+			if err != nil {
+				return ExitError(ExitRecipients, err, "failed to resolve recipient %q: %s", r, err)
+			}
+			if fp != "" {
+				debug.Log("resolved %q to %s", r, fp)
+				r = fp
+			}
+		}
+
 		keys, err := crypto.FindRecipients(ctx, r)
+		if errors.Is(err, gpg.ErrSignOnly) {
+			return ExitError(ExitRecipients, err, "key %q can only be used for signing, not for encryption. Please add an encryption subkey first", r)
+		}
 		if err != nil {
 			out.Printf(ctx, "WARNING: Failed to list public key %q: %s", r, err)
 			if !force {
@@ -113,17 +271,27 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 		recp := r
 		debug.Log("found recipients for %q: %+v", r, keys)
 
-		if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to add %q (key %q) as a recipient to the store %q?", crypto.FormatKey(ctx, recp, ""), recp, store)) {
-			continue
+		if !ctxutil.IsDryRun(ctx) {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("Do you want to add %q (key %q) as a recipient to %s?", crypto.FormatKey(ctx, recp, ""), recp, scopeName(store, dir)), "--yes")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not adding recipient %q: %s", r, err)
+			}
+			if !ok {
+				continue
+			}
 		}
 
-		if err := s.Store.AddRecipient(ctx, store, recp); err != nil {
+		if err := s.Store.AddRecipientAt(ctx, store, dir, recp); err != nil {
 			return ExitError(ExitRecipients, err, "failed to add recipient %q: %s", r, err)
 		}
 		added++
 	}
 	if added < 1 {
-		return ExitError(ExitUnknown, nil, "no key added")
+		return ExitError(ExitRecipients, nil, "no key added")
+	}
+	if ctxutil.IsDryRun(ctx) {
+		out.Printf(ctx, "\nWould add %d recipients", added)
+		return nil
 	}
 
 	out.Printf(ctx, "\nAdded %d recipients", added)
@@ -131,15 +299,30 @@ func (s *Action) RecipientsAdd(c *cli.Context) error {
 	return nil
 }
 
-// RecipientsRemove removes recipients
+// recipientsWarmup decrypts a secret of the scope before its secrets are
+// re-encrypted, see warmup
+func (s *Action) recipientsWarmup(ctx context.Context, c *cli.Context, store, dir string) error {
+	if c.Bool("no-warmup") || ctxutil.IsDryRun(ctx) {
+		return nil
+	}
+	names, err := s.Store.ListSecretsAt(ctx, store, dir)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list the secrets of %s: %s", scopeName(store, dir), err)
+	}
+	return s.warmup(ctx, c, names)
+}
+
+// RecipientsRemove removes recipients. With --rotate the passwords of all
+// secrets the removed recipients could decrypt are rotated afterwards, with
+// --affected they are written to a file for a staged rotation.
 func (s *Action) RecipientsRemove(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
-	store := c.String("store")
+	store, dir := s.recipientsScope(c)
 	force := c.Bool("force")
 	removed := 0
 
 	// select store
-	if store == "" {
+	if store == "" && dir == "" {
 		store = cui.AskForStore(ctx, s.Store)
 	}
 
@@ -148,23 +331,28 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 	// select recipient
 	recipients := []string(c.Args().Slice())
 	if len(recipients) < 1 {
-		rs, err := s.recipientsSelectForRemoval(ctx, store)
+		rs, err := s.recipientsSelectForRemoval(ctx, store, dir)
 		if err != nil {
 			return err
 		}
 		recipients = rs
 	}
 
-	for _, r := range recipients {
-		kl, err := crypto.FindIdentities(ctx, r)
-		if err == nil {
-			if len(kl) > 0 {
-				if !termio.AskForConfirmation(ctx, fmt.Sprintf("Do you want to remove yourself (%s) from the recipients?", r)) {
-					continue
-				}
-			}
+	// the secrets have to be listed before the scope might be removed
+	var affected []string
+	if c.Bool("rotate") || c.String("affected") != "" {
+		names, err := s.Store.ListSecretsAt(ctx, store, dir)
+		if err != nil {
+			return ExitError(ExitList, err, "failed to list the secrets of %s: %s", scopeName(store, dir), err)
 		}
+		affected = names
+	}
 
+	if err := s.recipientsWarmup(ctx, c, store, dir); err != nil {
+		return err
+	}
+
+	for _, r := range recipients {
 		keys, err := crypto.FindRecipients(ctx, r)
 		if err != nil {
 			out.Printf(ctx, "WARNING: Failed to list public key %q: %s", r, err)
@@ -181,31 +369,151 @@ func (s *Action) RecipientsRemove(c *cli.Context) error {
 			out.Printf(ctx, "You may need to run 'gpg --update-trustdb' afterwards")
 			continue
 		}
+		keys = recipientsInScope(keys, s.Store.ListRecipientsAt(ctx, store, dir))
+		if len(keys) > 1 {
+			out.Printf(ctx, "%q matches %d recipients of %s:", r, len(keys), scopeName(store, dir))
+			for _, k := range keys {
+				out.Printf(ctx, "%s\n", describeKey(ctx, crypto, k))
+			}
+			return ExitError(ExitUsage, nil, "%q is ambiguous. Please use the fingerprint of the key to remove", r)
+		}
 
 		recp := r
 		if len(keys) > 0 {
 			recp = crypto.Fingerprint(ctx, keys[0])
 		}
 
-		if err := s.Store.RemoveRecipient(ctx, store, recp); err != nil {
+		if !ctxutil.IsDryRun(ctx) {
+			ok, err := s.confirmRecipientRemoval(ctx, store, dir, r, recp)
+			if err != nil {
+				return ExitError(ExitAborted, err, "not removing recipient %q: %s", r, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		if err := s.Store.RemoveRecipientAt(ctx, store, dir, recp); err != nil {
 			return ExitError(ExitRecipients, err, "failed to remove recipient %q: %s", recp, err)
 		}
-		fmt.Fprintf(stdout, removalWarning, r)
 		removed++
+		if ctxutil.IsDryRun(ctx) {
+			continue
+		}
+		fmt.Fprintf(stdout, removalWarning, r)
 	}
 	if removed < 1 {
-		return ExitError(ExitUnknown, nil, "no key removed")
+		return ExitError(ExitRecipients, nil, "no key removed")
+	}
+	if ctxutil.IsDryRun(ctx) {
+		out.Printf(ctx, "\nWould remove %d recipients", removed)
+	} else {
+		out.Printf(ctx, "\nRemoved %d recipients", removed)
 	}
 
-	out.Printf(ctx, "\nRemoved %d recipients", removed)
-	out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
+	if fn := c.String("affected"); fn != "" {
+		if ctxutil.IsDryRun(ctx) {
+			out.Printf(ctx, "Would write the %d secrets the removed recipients could decrypt to %s", len(affected), fn)
+		} else {
+			if err := writeAffected(fn, affected); err != nil {
+				return ExitError(ExitIO, err, "failed to write the affected secrets to %s: %s", fn, err)
+			}
+			out.Printf(ctx, "Wrote the %d secrets the removed recipients could decrypt to %s", len(affected), fn)
+		}
+	}
+	if c.Bool("rotate") && len(affected) > 0 {
+		if err := s.rotateSecrets(ctx, c, affected, false); err != nil {
+			return err
+		}
+	}
+
+	if !ctxutil.IsDryRun(ctx) {
+		out.Printf(ctx, "You need to run 'gopass sync' to push these changes")
+	}
 	return nil
 }
 
-func (s *Action) recipientsSelectForRemoval(ctx context.Context, store string) ([]string, error) {
+// confirmRecipientRemoval shows the details of the key to remove and asks to
+// confirm removing it. Removing our own key always has to be confirmed,
+// others are only asked for in interactive sessions.
+func (s *Action) confirmRecipientRemoval(ctx context.Context, store, dir, r, recp string) (bool, error) {
+	crypto := s.Store.Crypto(ctx, store)
+	kl, err := crypto.FindIdentities(ctx, r)
+	self := err == nil && len(kl) > 0
+	if !self && !ctxutil.IsInteractive(ctx) {
+		return true, nil
+	}
+
+	out.Printf(ctx, "%s\n", describeKey(ctx, crypto, recp))
+	if self {
+		return termio.ConfirmDestructive(ctx, fmt.Sprintf("Do you want to remove yourself (%s) from the recipients?", r), "--yes")
+	}
+	return termio.ConfirmDestructive(ctx, fmt.Sprintf("Do you want to remove this key from the recipients of %s?", scopeName(store, dir)), "--yes")
+}
+
+// recipientsInScope returns the keys that are recipients of the scope, or
+// all keys if none of them is
+func recipientsInScope(keys, recipients []string) []string {
+	found := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, r := range recipients {
+			if strings.HasSuffix(k, r) {
+				found = append(found, k)
+				break
+			}
+		}
+	}
+	if len(found) < 1 {
+		return keys
+	}
+	return found
+}
+
+// describeKey returns all details of the key, e.g. its fingerprint, all
+// identities and the subkeys, if the crypto backend knows them
+func describeKey(ctx context.Context, crypto backend.Crypto, id string) string {
+	if kl, ok := crypto.(publicKeyLookup); ok {
+		if k, found := kl.PublicKey(ctx, id); found {
+			return k.String()
+		}
+	}
+	return crypto.FormatKey(ctx, id, "")
+}
+
+// writeAffected writes the names of the secrets to the file, one per line
+func writeAffected(fn string, names []string) error {
+	buf := &bytes.Buffer{}
+	for _, name := range names {
+		fmt.Fprintln(buf, name)
+	}
+	return os.WriteFile(fn, buf.Bytes(), 0600)
+}
+
+// recipientsScope returns the store and the directory inside this store
+// selected with --store and --path. Without --store the store is derived from
+// the path.
+func (s *Action) recipientsScope(c *cli.Context) (string, string) {
+	store := c.String("store")
+	dir := strings.Trim(s.Store.ExpandAlias(c.String("path")), "/")
+	if store == "" && dir != "" {
+		store = s.Store.MountPoint(dir)
+		dir = strings.TrimPrefix(strings.TrimPrefix(dir, store), "/")
+	}
+	return store, dir
+}
+
+// scopeName returns a description of a recipients scope for the user
+func scopeName(store, dir string) string {
+	if dir == "" {
+		return fmt.Sprintf("the store %q", store)
+	}
+	return fmt.Sprintf("%q in the store %q", dir, store)
+}
+
+func (s *Action) recipientsSelectForRemoval(ctx context.Context, store, dir string) ([]string, error) {
 	crypto := s.Store.Crypto(ctx, store)
 
-	ids := s.Store.ListRecipients(ctx, store)
+	ids := s.Store.ListRecipientsAt(ctx, store, dir)
 	choices := make([]string, 0, len(ids))
 	for _, id := range ids {
 		choices = append(choices, crypto.FormatKey(ctx, id, ""))
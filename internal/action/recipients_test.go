@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/table"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/fatih/color"
@@ -38,13 +44,14 @@ func TestRecipients(t *testing.T) {
 		stdout = os.Stdout
 	}()
 
-	t.Run("print recipients tree", func(t *testing.T) {
+	t.Run("print recipients", func(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.RecipientsPrint(gptest.CliCtx(ctx, t)))
 
 		hint := `Hint: run 'gopass sync' to import any missing public keys`
-		want := `gopass
-└── 0xDEADBEEF`
+		want := `STORE   SCOPE  KEY         IDENTITY  STATUS
+<root>  /      0xDEADBEEF            ok
+`
 
 		assert.Contains(t, buf.String(), hint)
 		assert.Contains(t, buf.String(), want)
@@ -81,4 +88,231 @@ func TestRecipients(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.RecipientsRemove(gptest.CliCtx(ctx, t, "0xDEADBEEF")))
 	})
+
+	t.Run("add recipient to a subfolder", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsAdd(gptest.CliCtxWithFlags(ctx, t, map[string]string{"path": "foo"}, "0xDEADBEEF")))
+		assert.Contains(t, buf.String(), "Creating new recipients scope for foo")
+		// our own key is added to new scopes
+		assert.Equal(t, []string{"0xDEADBEEF", "0xFEEDBEEF"}, act.Store.ListRecipientsAt(ctx, "", "foo/bar"))
+		assert.NotContains(t, act.Store.ListRecipients(ctx, ""), "0xDEADBEEF")
+
+		buf.Reset()
+		assert.NoError(t, act.RecipientsPrint(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "<root>  /      0xBEEFFEED            missing\n")
+		assert.Contains(t, buf.String(), "<root>  foo/   0xDEADBEEF            ok\n<root>  foo/   0xFEEDBEEF            ok\n")
+	})
+
+	t.Run("print the recipients of a path", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"path": "foo/bar"})))
+		assert.Equal(t, "foo/bar is encrypted for the recipients from foo/"+plain.IDFile+":\n  0xDEADBEEF\n  0xFEEDBEEF\n", buf.String())
+		buf.Reset()
+
+		require.NoError(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"path": "other/db"})))
+		assert.Contains(t, buf.String(), "other/db is encrypted for the recipients from "+plain.IDFile+":\n")
+	})
+
+	t.Run("print the scope tree", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"tree": "true"})))
+		want := `gopass
+├── 0xBEEFFEED (key not in keyring)
+├── 0xFEEDBEEF
+└── foo/
+    ├── 0xDEADBEEF
+    └── 0xFEEDBEEF (inherited)
+`
+		assert.Equal(t, want, buf.String())
+		assert.Error(t, act.RecipientsPrint(gptest.CliCtxWithFlags(ctx, t, map[string]string{"tree": "true", "format": "json"})))
+	})
+
+	t.Run("remove last recipient from a subfolder", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.RecipientsRemove(gptest.CliCtxWithFlags(ctx, t, map[string]string{"path": "foo"}, "0xDEADBEEF", "0xFEEDBEEF")))
+		assert.Contains(t, buf.String(), "Secrets below foo are encrypted for the recipients from")
+		assert.NotContains(t, act.Store.ListRecipientsAt(ctx, "", "foo/bar"), "0xDEADBEEF")
+	})
+}
+
+func TestRecipientsRemoveRotate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	color.NoColor = true
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	for _, name := range []string{"team/db", "team/web", "other/db"} {
+		sec := secrets.New()
+		sec.SetPassword("old-" + name)
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+	require.NoError(t, act.RecipientsAdd(gptest.CliCtxWithFlags(ctx, t, map[string]string{"path": "team"}, "0xFEEDBEEF")))
+	buf.Reset()
+
+	affected := filepath.Join(t.TempDir(), "affected")
+	flags := map[string]string{"path": "team", "rotate": "true", "affected": affected}
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsRemove(gptest.CliCtxWithFlags(ctxutil.WithDryRun(ctx, true), t, flags, "0xFEEDBEEF")))
+		assert.Contains(t, buf.String(), "Would rotate the passwords of 2 secrets:\n  team/db\n  team/web\n")
+		assert.NoFileExists(t, affected)
+		assert.Contains(t, act.Store.ListRecipientsAt(ctx, "", "team"), "0xFEEDBEEF")
+	})
+
+	t.Run("remove and rotate", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.RecipientsRemove(gptest.CliCtxWithFlags(ctx, t, flags, "0xFEEDBEEF")))
+		assert.NotContains(t, act.Store.ListRecipientsAt(ctx, "", "team"), "0xFEEDBEEF")
+		assert.Contains(t, buf.String(), "Rotated 2 passwords")
+
+		names, err := os.ReadFile(affected)
+		require.NoError(t, err)
+		assert.Equal(t, "team/db\nteam/web\n", string(names))
+
+		for name, rotated := range map[string]bool{"team/db": true, "team/web": true, "other/db": false} {
+			sec, err := act.Store.Get(ctx, name)
+			require.NoError(t, err)
+			assert.Equal(t, rotated, sec.Password() != "old-"+name, name)
+		}
+	})
+}
+
+func TestRecipientsInScope(t *testing.T) {
+	keys := []string{"0x1111111111111111BOB0", "0x2222222222222222BOB1"}
+
+	assert.Equal(t, []string{"0x2222222222222222BOB1"}, recipientsInScope(keys, []string{"0xALICE", "2222222222222222BOB1"}))
+	assert.Equal(t, keys, recipientsInScope(keys, []string{"0x1111111111111111BOB0", "0x2222222222222222BOB1"}))
+	assert.Equal(t, keys, recipientsInScope(keys, []string{"0xALICE"}))
+}
+
+// fakeKeyring implements publicKeyLookup
+type fakeKeyring map[string]gpg.Key
+
+func (f fakeKeyring) PublicKey(ctx context.Context, id string) (gpg.Key, bool) {
+	k, found := f[id]
+	return k, found
+}
+
+func TestPrintKeyStrength(t *testing.T) {
+	ctx := context.Background()
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	kl := fakeKeyring{
+		"alice": {Fingerprint: "00000000000000000000000000000000000A11CE", PubKeyAlgo: 22, Curve: "ed25519"},
+		"bob":   {Fingerprint: "0000000000000000000000000000000000000B0B", PubKeyAlgo: 1, KeyLength: 2048},
+		"carol": {Fingerprint: "000000000000000000000000000000000000CA01", PubKeyAlgo: 17, KeyLength: 1024},
+	}
+
+	assert.Equal(t, 1, printKeyStrength(ctx, "[team] ", kl, []string{"alice", "bob", "carol", "missing"}))
+	assert.Contains(t, buf.String(), "[team] Weak recipient key (dsa1024), it should be replaced: 0x000000000000CA01")
+	assert.Contains(t, buf.String(), "[team] Legacy recipient key (rsa2048), consider replacing it: 0x0000000000000B0B")
+	assert.Contains(t, buf.String(), "[team] Recipient keys: 1 ok, 1 legacy, 1 weak")
+	assert.NotContains(t, buf.String(), "A11CE (")
+	buf.Reset()
+
+	assert.Equal(t, 0, printKeyStrength(ctx, "", kl, []string{"missing"}))
+	assert.Equal(t, "", buf.String())
+}
+
+func TestRecipientLine(t *testing.T) {
+	ctx := context.Background()
+	kl := struct {
+		*plain.Mocker
+		fakeKeyring
+	}{
+		Mocker:      plain.New(),
+		fakeKeyring: fakeKeyring{"alice": {Fingerprint: "00000000000000000000000000000000000A11CE", Identities: map[string]gpg.Identity{"Alice": {Name: "Alice", Email: "alice@example.com"}}}},
+	}
+
+	assert.Equal(t, "0x00000000000A11CE - Alice <alice@example.com>", recipientLine(ctx, kl, "alice"))
+	assert.Equal(t, "bob (key not in keyring)", recipientLine(ctx, kl, "bob"))
+	assert.Equal(t, "bob (key not in keyring)", recipientLine(ctx, nil, "bob"))
+}
+
+func TestAllRecipients(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob", "carol"}, allRecipients(map[string][]string{
+		"":     {"bob", "alice"},
+		"prod": {"alice", "carol"},
+	}))
+}
+
+func TestRecipientStatus(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	alice := map[string]gpg.Identity{"Alice": {Name: "Alice", Email: "alice@example.com"}}
+	caps := gpg.Capabilities{Encrypt: true}
+	kl := struct {
+		*plain.Mocker
+		fakeKeyring
+	}{
+		Mocker: plain.New(),
+		fakeKeyring: fakeKeyring{
+			"ok":        {Fingerprint: "00000000000000000000000000000000000A11CE", Validity: "u", Caps: caps, Identities: alice},
+			"expiring":  {Fingerprint: "00000000000000000000000000000000000A11CE", Validity: "u", Caps: caps, Identities: alice, ExpirationDate: now.Add(72 * time.Hour)},
+			"expired":   {Fingerprint: "00000000000000000000000000000000000A11CE", Validity: "u", Caps: caps, Identities: alice, ExpirationDate: now.Add(-time.Hour)},
+			"revoked":   {Fingerprint: "00000000000000000000000000000000000A11CE", Validity: "r", Caps: caps, Identities: alice},
+			"untrusted": {Fingerprint: "00000000000000000000000000000000000A11CE", Validity: "-", Caps: caps, Identities: alice},
+		},
+	}
+	week := 7 * 24 * time.Hour
+
+	for _, tc := range []struct {
+		id     string
+		status table.Status
+		desc   string
+	}{
+		{"ok", table.OK, "ok"},
+		{"expiring", table.Warning, "expires " + now.Add(72*time.Hour).Format("2006-01-02")},
+		{"expired", table.Error, "expired"},
+		{"revoked", table.Error, "revoked"},
+		{"untrusted", table.Error, "not usable"},
+		{"missing", table.Error, "missing"},
+	} {
+		status, key, identity, desc := recipientStatus(ctx, kl, tc.id, week)
+		assert.Equal(t, tc.status, status, tc.id)
+		assert.Equal(t, tc.desc, desc, tc.id)
+		if tc.id != "missing" {
+			assert.Equal(t, "00000000000000000000000000000000000A11CE", key, tc.id)
+			assert.Equal(t, "Alice <alice@example.com>", identity, tc.id)
+		}
+	}
+
+	// without an expiry warning window expiring keys are ok
+	status, _, _, _ := recipientStatus(ctx, kl, "expiring", 0)
+	assert.Equal(t, table.OK, status)
+
+	// other backends can only tell if the key is missing
+	status, key, _, desc := recipientStatus(ctx, plain.New(), "DEADBEEF", week)
+	assert.Equal(t, table.OK, status)
+	assert.Equal(t, "DEADBEEF", key)
+	assert.Equal(t, "ok", desc)
+	status, _, _, desc = recipientStatus(ctx, plain.New(), "0xBEEFFEED", week)
+	assert.Equal(t, table.Error, status)
+	assert.Equal(t, "missing", desc)
 }
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+// recipientsOutput is the schema of recipients --format json|yaml, one entry
+// per store and scope. It must be kept stable.
+type recipientsOutput struct {
+	// Store is the mount point, empty for the root store
+	Store string `json:"store"`
+	// Scope is the directory with its own recipients, empty for the store
+	Scope      string      `json:"scope"`
+	Recipients []keyOutput `json:"recipients"`
+}
+
+// keyOutput describes a recipient. The details are only available for gpg
+// keys in the keyring.
+type keyOutput struct {
+	ID           string     `json:"id"`
+	Fingerprint  string     `json:"fingerprint,omitempty"`
+	Missing      bool       `json:"missing,omitempty"`
+	Validity     string     `json:"validity,omitempty"`
+	Ownertrust   string     `json:"ownertrust,omitempty"`
+	Length       int        `json:"length,omitempty"`
+	Curve        string     `json:"curve,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	Capabilities string     `json:"capabilities,omitempty"`
+	// Strength is ok, legacy, weak or unknown for the weakest of the
+	// primary key and its encryption subkeys
+	Strength   string           `json:"strength,omitempty"`
+	Identities []identityOutput `json:"identities,omitempty"`
+	SubKeys    []subKeyOutput   `json:"subkeys,omitempty"`
+}
+
+type identityOutput struct {
+	Name     string `json:"name"`
+	Comment  string `json:"comment,omitempty"`
+	Email    string `json:"email"`
+	Validity string `json:"validity,omitempty"`
+}
+
+type subKeyOutput struct {
+	Fingerprint  string     `json:"fingerprint"`
+	Validity     string     `json:"validity,omitempty"`
+	Length       int        `json:"length,omitempty"`
+	Curve        string     `json:"curve,omitempty"`
+	Created      *time.Time `json:"created,omitempty"`
+	Expires      *time.Time `json:"expires,omitempty"`
+	Capabilities string     `json:"capabilities,omitempty"`
+	Strength     string     `json:"strength,omitempty"`
+}
+
+// publicKeyLookup is implemented by crypto backends exposing the details of
+// the keys in their keyring, i.e. gpg
+type publicKeyLookup interface {
+	PublicKey(ctx context.Context, id string) (gpg.Key, bool)
+}
+
+// recipientsStructured prints the recipients of all stores and scopes
+func (s *Action) recipientsStructured(ctx context.Context, format string) error {
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	sort.Strings(stores)
+
+	res := make([]recipientsOutput, 0, len(stores))
+	for _, store := range stores {
+		crypto := s.Store.Crypto(ctx, store)
+		scopes := s.Store.ListRecipientScopes(ctx, store)
+		names := make([]string, 0, len(scopes))
+		for scope := range scopes {
+			names = append(names, scope)
+		}
+		sort.Strings(names)
+
+		for _, scope := range names {
+			ro := recipientsOutput{
+				Store:      store,
+				Scope:      scope,
+				Recipients: make([]keyOutput, 0, len(scopes[scope])),
+			}
+			for _, id := range scopes[scope] {
+				ro.Recipients = append(ro.Recipients, recipientOutput(ctx, crypto, id))
+			}
+			res = append(res, ro)
+		}
+	}
+
+	if err := out.Encode(stdout, format, res); err != nil {
+		return ExitError(ExitUnknown, err, "failed to encode recipients: %s", err)
+	}
+	return nil
+}
+
+func recipientOutput(ctx context.Context, crypto backend.Crypto, id string) keyOutput {
+	ko := keyOutput{ID: id, Missing: true}
+	if crypto == nil {
+		return ko
+	}
+	if kl, ok := crypto.(publicKeyLookup); ok {
+		if k, found := kl.PublicKey(ctx, id); found {
+			return newKeyOutput(id, k)
+		}
+		return ko
+	}
+	if recps, err := crypto.FindRecipients(ctx, id); err == nil && len(recps) > 0 {
+		ko.Missing = false
+	}
+	return ko
+}
+
+func newKeyOutput(id string, k gpg.Key) keyOutput {
+	ko := keyOutput{
+		ID:           id,
+		Fingerprint:  k.Fingerprint,
+		Validity:     k.Validity,
+		Ownertrust:   k.Ownertrust,
+		Length:       k.KeyLength,
+		Curve:        k.Curve,
+		Created:      timeOutput(k.CreationDate),
+		Expires:      timeOutput(k.ExpirationDate),
+		Capabilities: k.Caps.Usage(),
+		Strength:     k.Strength(),
+	}
+
+	for _, id := range k.Identities {
+		ko.Identities = append(ko.Identities, identityOutput{
+			Name:     id.Name,
+			Comment:  id.Comment,
+			Email:    id.Email,
+			Validity: id.Validity,
+		})
+	}
+	sort.Slice(ko.Identities, func(i, j int) bool {
+		if ko.Identities[i].Name != ko.Identities[j].Name {
+			return ko.Identities[i].Name < ko.Identities[j].Name
+		}
+		return ko.Identities[i].Email < ko.Identities[j].Email
+	})
+
+	for _, sk := range k.SubKeys {
+		ko.SubKeys = append(ko.SubKeys, subKeyOutput{
+			Fingerprint:  sk.Fingerprint,
+			Validity:     sk.Validity,
+			Length:       sk.KeyLength,
+			Curve:        sk.Curve,
+			Created:      timeOutput(sk.CreationDate),
+			Expires:      timeOutput(sk.ExpirationDate),
+			Capabilities: sk.Caps.Usage(),
+			Strength:     sk.Strength(),
+		})
+	}
+	sort.Slice(ko.SubKeys, func(i, j int) bool {
+		return ko.SubKeys[i].Fingerprint < ko.SubKeys[j].Fingerprint
+	})
+
+	return ko
+}
+
+// timeOutput returns the time in UTC or nil if it's not set
+func timeOutput(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
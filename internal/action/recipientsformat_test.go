@@ -0,0 +1,30 @@
+package action
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/colons"
+	"github.com/gopasspw/gopass/internal/out"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyOutput(t *testing.T) {
+	in, err := os.ReadFile(filepath.Join("..", "backend", "crypto", "gpg", "colons", "testdata", "rsa.colons"))
+	require.NoError(t, err)
+	kl := colons.Parse(bytes.NewReader(in))
+	require.Equal(t, 1, len(kl))
+
+	for _, format := range []string{"json", "yaml"} {
+		buf := &bytes.Buffer{}
+		require.NoError(t, out.Encode(buf, format, newKeyOutput(kl[0].ID(), kl[0])))
+
+		want, err := os.ReadFile(filepath.Join("testdata", "key."+format+".golden"))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), buf.String(), format)
+	}
+}
@@ -0,0 +1,101 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/table"
+)
+
+// recipientsTable prints the recipients of all stores and scopes, one key per
+// row, colored by the status of the key
+func (s *Action) recipientsTable(ctx context.Context) error {
+	stores := append([]string{""}, s.Store.MountPoints()...)
+	sort.Strings(stores)
+
+	var warn time.Duration
+	if s.cfg.ExpiryWarn > 0 {
+		warn = time.Duration(s.cfg.ExpiryWarn) * 24 * time.Hour
+	}
+
+	t := newTable(
+		table.Column{Header: "STORE", Truncate: table.Start},
+		table.Column{Header: "SCOPE", Truncate: table.Start},
+		table.Column{Header: "KEY"},
+		table.Column{Header: "IDENTITY", Truncate: table.End},
+		table.Column{Header: "STATUS"},
+	)
+	for _, store := range stores {
+		crypto := s.Store.Crypto(ctx, store)
+		scopes := s.Store.ListRecipientScopes(ctx, store)
+		dirs := make([]string, 0, len(scopes))
+		for dir := range scopes {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+
+		name := store
+		if name == "" {
+			name = "<root>"
+		}
+		for _, dir := range dirs {
+			scope := "/"
+			if dir != "" {
+				scope = dir + "/"
+			}
+			for _, id := range scopes[dir] {
+				status, key, identity, desc := recipientStatus(ctx, crypto, id, warn)
+				t.AddRow(status, name, scope, key, identity, desc)
+			}
+		}
+	}
+
+	return t.Render(stdout)
+}
+
+// recipientStatus returns the status, the fingerprint, the identity and a
+// description of the status of a recipient. Only gpg has details about the
+// keys, other backends can only tell if a key is missing.
+func recipientStatus(ctx context.Context, crypto backend.Crypto, id string, warn time.Duration) (table.Status, string, string, string) {
+	if crypto == nil {
+		return table.Error, id, "", "missing"
+	}
+	kl, ok := crypto.(publicKeyLookup)
+	if !ok {
+		recps, err := crypto.FindRecipients(ctx, id)
+		if err != nil || len(recps) < 1 {
+			return table.Error, id, "", "missing"
+		}
+		identity := crypto.FormatKey(ctx, recps[0], "")
+		if identity == id {
+			identity = ""
+		}
+		return table.OK, id, identity, "ok"
+	}
+
+	k, found := kl.PublicKey(ctx, id)
+	if !found {
+		return table.Error, id, "", "missing"
+	}
+	identity := k.Identity().ID()
+	switch {
+	case k.Validity == "r":
+		return table.Error, k.Fingerprint, identity, "revoked"
+	case k.Validity == "e" || (!k.ExpirationDate.IsZero() && k.ExpirationDate.Before(time.Now())):
+		return table.Error, k.Fingerprint, identity, "expired"
+	case k.Caps.Deactivated:
+		return table.Error, k.Fingerprint, identity, "disabled"
+	case !k.IsUseable(gpg.IsAlwaysTrust(ctx)):
+		return table.Error, k.Fingerprint, identity, "not usable"
+	case warn > 0 && k.ExpiresIn(warn):
+		if expires := k.ExpirationDate; !expires.IsZero() && expires.Before(time.Now().Add(warn)) {
+			return table.Warning, k.Fingerprint, identity, fmt.Sprintf("expires %s", expires.Format("2006-01-02"))
+		}
+		return table.Warning, k.Fingerprint, identity, "expires soon"
+	}
+	return table.OK, k.Fingerprint, identity, "ok"
+}
@@ -0,0 +1,173 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+const (
+	scopeBranch = "├── "
+	scopeLeaf   = "└── "
+	scopeVert   = "│   "
+	scopeSpace  = "    "
+)
+
+// recipientsPath prints the recipients a secret or folder is encrypted for
+// and the id file they are read from
+func (s *Action) recipientsPath(ctx context.Context, store, dir, format string) error {
+	name := strings.Trim(path.Join(store, dir), "/")
+	idf := filepath.ToSlash(s.Store.RecipientsFileAt(ctx, store, dir))
+	ids := s.Store.ListRecipientsAt(ctx, store, dir)
+	if len(ids) < 1 {
+		return ExitError(ExitRecipients, nil, "no recipients found for %s", name)
+	}
+	crypto := s.Store.Crypto(ctx, store)
+
+	if out.IsStructured(format) {
+		ro := recipientsOutput{
+			Store:      store,
+			Scope:      path.Dir(idf),
+			Recipients: make([]keyOutput, 0, len(ids)),
+		}
+		if ro.Scope == "." {
+			ro.Scope = ""
+		}
+		for _, id := range ids {
+			ro.Recipients = append(ro.Recipients, recipientOutput(ctx, crypto, id))
+		}
+		if err := out.Encode(stdout, format, ro); err != nil {
+			return ExitError(ExitUnknown, err, "failed to encode recipients: %s", err)
+		}
+		return nil
+	}
+
+	if store != "" {
+		idf = store + "/" + idf
+	}
+	fmt.Fprintf(stdout, "%s is encrypted for the recipients from %s:\n", name, idf)
+	for _, id := range ids {
+		fmt.Fprintf(stdout, "  %s\n", recipientLine(ctx, crypto, id))
+	}
+	return nil
+}
+
+// recipientLine describes a recipient by its key if it's in the local keyring
+func recipientLine(ctx context.Context, crypto backend.Crypto, id string) string {
+	missing := id + " (key not in keyring)"
+	if crypto == nil {
+		return missing
+	}
+	if kl, ok := crypto.(publicKeyLookup); ok {
+		if k, found := kl.PublicKey(ctx, id); found {
+			return k.OneLine()
+		}
+		return missing
+	}
+	if recps, err := crypto.FindRecipients(ctx, id); err == nil && len(recps) > 0 {
+		return crypto.FormatKey(ctx, recps[0], "")
+	}
+	return missing
+}
+
+// scopeNode is a store or a folder with its own recipients
+type scopeNode struct {
+	label    string
+	keys     []string
+	children []*scopeNode
+}
+
+// recipientsScopeTree prints the scopes of all stores as a tree. A key that
+// is a recipient of the parent scope as well is only listed by its id and
+// marked as inherited.
+func (s *Action) recipientsScopeTree(ctx context.Context) {
+	root := s.scopeTree(ctx, "", "gopass")
+
+	mps := s.Store.MountPoints()
+	sort.Strings(mps)
+	for _, mp := range mps {
+		root.children = append(root.children, s.scopeTree(ctx, mp, mp+"/"))
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(root.label + "\n")
+	root.format(sb, "")
+	fmt.Fprint(stdout, sb.String())
+}
+
+// scopeTree returns the scopes of a store, nested by their directories
+func (s *Action) scopeTree(ctx context.Context, store, label string) *scopeNode {
+	crypto := s.Store.Crypto(ctx, store)
+	scopes := s.Store.ListRecipientScopes(ctx, store)
+
+	dirs := make([]string, 0, len(scopes))
+	for dir := range scopes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	nodes := make(map[string]*scopeNode, len(dirs))
+	for _, dir := range dirs {
+		node := &scopeNode{label: label}
+		parent := ""
+		if dir != "" {
+			parent = parentScope(scopes, dir)
+			node.label = strings.TrimPrefix(dir, parent+"/") + "/"
+			nodes[parent].children = append(nodes[parent].children, node)
+		}
+
+		inherited := make(map[string]bool, len(scopes[parent]))
+		if dir != "" {
+			for _, id := range scopes[parent] {
+				inherited[id] = true
+			}
+		}
+		for _, id := range scopes[dir] {
+			if inherited[id] {
+				node.keys = append(node.keys, id+" (inherited)")
+				continue
+			}
+			node.keys = append(node.keys, recipientLine(ctx, crypto, id))
+		}
+		nodes[dir] = node
+	}
+	return nodes[""]
+}
+
+// parentScope returns the closest scope above the directory
+func parentScope(scopes map[string][]string, dir string) string {
+	for {
+		dir = path.Dir(dir)
+		if dir == "." || dir == "/" {
+			return ""
+		}
+		if _, found := scopes[dir]; found {
+			return dir
+		}
+	}
+}
+
+func (n *scopeNode) format(sb *strings.Builder, prefix string) {
+	total := len(n.keys) + len(n.children)
+	for i, key := range n.keys {
+		sym := scopeBranch
+		if i == total-1 {
+			sym = scopeLeaf
+		}
+		sb.WriteString(prefix + sym + key + "\n")
+	}
+	for i, child := range n.children {
+		sym, indent := scopeBranch, scopeVert
+		if len(n.keys)+i == total-1 {
+			sym, indent = scopeLeaf, scopeSpace
+		}
+		sb.WriteString(prefix + sym + child.label + "\n")
+		child.format(sb, prefix+indent)
+	}
+}
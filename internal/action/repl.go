@@ -3,27 +3,53 @@ package action
 import (
 	"context"
 	"fmt"
+	"os"
+	"path"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/autolock"
+	"github.com/gopasspw/gopass/internal/interrupt"
 	"github.com/gopasspw/gopass/internal/tree"
 
 	"github.com/chzyer/readline"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	shellquote "github.com/kballard/go-shellquote"
 	"github.com/urfave/cli/v2"
 )
 
-func (s *Action) entriesForCompleter(ctx context.Context) ([]readline.PrefixCompleterInterface, error) {
+// entriesForCompleter returns the names of all secrets and the folders
+// below dir. Names below dir can also be completed relative to it.
+func (s *Action) entriesForCompleter(ctx context.Context, dir string) ([]readline.PrefixCompleterInterface, []readline.PrefixCompleterInterface, error) {
 	args := []readline.PrefixCompleterInterface{}
+	dirs := []readline.PrefixCompleterInterface{}
 	list, err := s.Store.List(ctx, tree.INF)
 	if err != nil {
-		return args, err
+		return args, dirs, err
 	}
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	seen := make(map[string]bool, len(list))
 	for _, v := range list {
-		args = append(args, readline.PcItem(v))
+		if prefix == "" {
+			args = append(args, readline.PcItem(v))
+		} else {
+			args = append(args, readline.PcItem("/"+v))
+			if !strings.HasPrefix(v, prefix) {
+				continue
+			}
+			v = strings.TrimPrefix(v, prefix)
+			args = append(args, readline.PcItem(v))
+		}
+		for d := path.Dir(v); d != "." && !seen[d]; d = path.Dir(d) {
+			seen[d] = true
+			dirs = append(dirs, readline.PcItem(d+"/"))
+		}
 	}
-	return args, nil
+	return args, dirs, nil
 }
 
 func (s *Action) replCompleteRecipients(ctx context.Context, cmd *cli.Command) []readline.PrefixCompleterInterface {
@@ -54,12 +80,18 @@ func (s *Action) replCompleteTemplates(ctx context.Context, cmd *cli.Command) []
 	return args
 }
 
-func (s *Action) prefixCompleter(c *cli.Context) *readline.PrefixCompleter {
-	secrets, err := s.entriesForCompleter(c.Context)
+func (s *Action) prefixCompleter(c *cli.Context, dir string) *readline.PrefixCompleter {
+	secrets, dirs, err := s.entriesForCompleter(c.Context, dir)
 	if err != nil {
 		debug.Log("failed to list secrets: %s", err)
 	}
-	cmds := []readline.PrefixCompleterInterface{}
+	cmds := []readline.PrefixCompleterInterface{
+		readline.PcItem("cd", append(dirs, readline.PcItem(".."), readline.PcItem("/"))...),
+		readline.PcItem("pwd"),
+		readline.PcItem("clear"),
+		readline.PcItem("lock"),
+		readline.PcItem("quit"),
+	}
 	for _, cmd := range c.App.Commands {
 		if cmd.Hidden {
 			continue
@@ -105,6 +137,22 @@ func (s *Action) prefixCompleter(c *cli.Context) *readline.PrefixCompleter {
 	return readline.NewPrefixCompleter(cmds...)
 }
 
+// replNameArgs is the number of leading arguments of a command that are
+// names of secrets and are resolved relative to the current folder
+var replNameArgs = map[string]int{
+	"cat":      1,
+	"copy":     2,
+	"delete":   1,
+	"edit":     1,
+	"generate": 1,
+	"history":  1,
+	"insert":   1,
+	"list":     1,
+	"move":     2,
+	"otp":      1,
+	"show":     1,
+}
+
 // REPL implements a read-execute-print-line shell
 // with readline support and autocompletion.
 func (s *Action) REPL(c *cli.Context) error {
@@ -117,63 +165,225 @@ func (s *Action) REPL(c *cli.Context) error {
 
 	out.Printf(c.Context, logo)
 	out.Printf(c.Context, "🌟 Welcome to gopass!")
-	out.Printf(c.Context, "⚠ This is the built-in shell. Type 'help' for a list of commands.")
+	out.Printf(c.Context, "⚠ This is the built-in shell. Type 'help' for a list of commands, 'cd' to change the current folder.")
 
 	rl, err := readline.New("gopass> ")
 	if err != nil {
-		return err
+		return ExitError(ExitIO, err, "failed to start the shell: %s", err)
 	}
 	defer rl.Close()
 
+	// Ctrl+C only cancels the current command, not the shell. Like other
+	// interactive shells it ignores SIGTERM, too. At the prompt Ctrl+C is
+	// read by readline.
+	ctx := ctxutil.WithoutCancel(c.Context)
+	defer interrupt.Redirect(func(sig os.Signal) {
+		debug.Log("ignoring %s while waiting for input", sig)
+	})()
+	// the shell is shared with anyone looking at the screen
+	ctx = ctxutil.WithShowSafeContent(ctx, true)
+	var dir string
+
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	al := s.startAutoLock(actx, func(reason string) {
+		s.lockStores(actx, reason)
+	})
+
 READ:
 	for {
-		// check for context cancelation
-		select {
-		case <-c.Context.Done():
-			return fmt.Errorf("user aborted")
-		default:
-		}
-		rl.Config.AutoComplete = s.prefixCompleter(c)
+		// the time without activity starts when the shell waits for input
+		al.End()
+		rl.SetPrompt(replPrompt(dir))
+		rl.Config.AutoComplete = s.prefixCompleter(c, dir)
 		line, err := rl.Readline()
+		al.Begin()
+		if err == readline.ErrInterrupt {
+			continue
+		}
 		if err != nil {
 			debug.Log("Readline error: %s", err)
 			break
 		}
 		args, err := shellquote.Split(line)
 		if err != nil {
-			out.Printf(c.Context, "Error: %s", err)
+			out.Printf(ctx, "Error: %s", err)
 			continue
 		}
 		if len(args) < 1 {
 			continue
 		}
 		switch strings.ToLower(args[0]) {
-		case "quit":
+		case "quit", "exit":
 			break READ
 		case "lock":
-			s.replLock(c.Context)
+			s.replLock(ctx, al)
 			continue
 		case "clear":
 			readline.ClearScreen(stdout)
 			continue
+		case "cd":
+			nd, err := s.replCd(ctx, dir, args[1:])
+			if err != nil {
+				out.Errorf(ctx, "%s", err)
+				continue
+			}
+			dir = nd
+			continue
+		case "pwd":
+			out.Printf(ctx, "/%s", dir)
+			continue
+		case "repl", "shell":
+			out.Printf(ctx, "Already in the built-in shell")
+			continue
 		default:
 		}
-		// need to reinitialize the config to pick up any changes from the
-		// previous iteration
-		// TODO: this means the context will grow with every loop. Eventually
-		// this might lead to memory issues so we should see if we can optimize it.
-		c.Context = s.cfg.WithContext(c.Context)
-		if err := c.App.RunContext(c.Context, append([]string{"gopass"}, args...)); err != nil {
+		// a locked shell runs nothing until the user has authenticated again
+		if err := s.unlockStores(ctx, al); err != nil {
+			out.Errorf(ctx, "%s", err)
 			continue
 		}
+		args = replArgs(c.App, dir, args)
+		debug.Log("running %q in %q", args, dir)
+
+		// need to reinitialize the config to pick up any changes from the
+		// previous iteration
+		cctx, cancel := context.WithCancel(s.cfg.WithContext(ctx))
+		s.replRun(cctx, cancel, c.App, args)
 	}
 	return nil
 }
 
-func (s *Action) replLock(ctx context.Context) {
-	if err := s.Store.Lock(); err != nil {
-		out.Errorf(ctx, "Failed to lock stores: %s", err)
-		return
+// replRun runs a single command. It's canceled on Ctrl+C.
+func (s *Action) replRun(ctx context.Context, cancel context.CancelFunc, app *cli.App, args []string) {
+	restore := interrupt.Redirect(func(sig os.Signal) {
+		debug.Log("canceling %q on %s", args, sig)
+		cancel()
+	})
+	defer func() {
+		restore()
+		cancel()
+	}()
+
+	if err := app.RunContext(ctx, append([]string{"gopass"}, args...)); err != nil {
+		debug.Log("command %q failed: %s", args, err)
+	}
+}
+
+// replPrompt returns the prompt showing the current folder
+func replPrompt(dir string) string {
+	if dir == "" {
+		return "gopass> "
+	}
+	return fmt.Sprintf("gopass:%s> ", dir)
+}
+
+// replCd returns the folder to change to. Without an argument it returns to
+// the root of the store.
+func (s *Action) replCd(ctx context.Context, dir string, args []string) (string, error) {
+	if len(args) < 1 {
+		return "", nil
+	}
+	if len(args) > 1 {
+		return dir, fmt.Errorf("Usage: cd [folder]")
+	}
+
+	nd := replResolve(dir, args[0])
+	nd = strings.TrimSuffix(nd, "/")
+	if nd == "" {
+		return "", nil
+	}
+	if !s.Store.IsDir(ctx, nd) {
+		return dir, fmt.Errorf("%q is not a folder", nd)
+	}
+	return nd, nil
+}
+
+// replResolve resolves a name relative to the current folder. Names starting
+// with a / are absolute.
+func replResolve(dir, name string) string {
+	if strings.HasPrefix(name, "/") {
+		name = strings.TrimLeft(name, "/")
+		if name == "" {
+			return ""
+		}
+		return path.Clean(name) + trailingSlash(name)
+	}
+	res := path.Join(dir, name)
+	if res == "." || strings.HasPrefix(res, "..") {
+		return ""
+	}
+	return res + trailingSlash(name)
+}
+
+// trailingSlash keeps the trailing slash of a name, e.g. for the rsync like
+// semantics of copy and move
+func trailingSlash(name string) string {
+	if strings.HasSuffix(name, "/") {
+		return "/"
+	}
+	return ""
+}
+
+// replArgs resolves the names of secrets given to a command relative to the
+// current folder. list, without a folder, lists the current folder.
+func replArgs(app *cli.App, dir string, args []string) []string {
+	if dir == "" {
+		return args
+	}
+	cmd := app.Command(args[0])
+	if cmd == nil {
+		return args
+	}
+	n := replNameArgs[cmd.Name]
+	if n < 1 {
+		return args
+	}
+
+	// flags that take a value
+	valueFlags := make(map[string]bool, len(cmd.Flags))
+	for _, f := range cmd.Flags {
+		_, isBool := f.(*cli.BoolFlag)
+		for _, name := range f.Names() {
+			valueFlags[name] = !isBool
+		}
 	}
+
+	res := make([]string, 0, len(args)+1)
+	res = append(res, args[0])
+	var positional int
+	var flagsDone bool
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !flagsDone && arg == "--" {
+			flagsDone = true
+			res = append(res, arg)
+			continue
+		}
+		if !flagsDone && strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			res = append(res, arg)
+			name := strings.TrimLeft(arg, "-")
+			if !strings.Contains(name, "=") && valueFlags[name] && i+1 < len(args) {
+				i++
+				res = append(res, args[i])
+			}
+			continue
+		}
+		if positional < n {
+			arg = replResolve(dir, arg)
+		}
+		positional++
+		res = append(res, arg)
+	}
+	if cmd.Name == "list" && positional == 0 {
+		res = append(res, dir)
+	}
+	return res
+}
+
+// replLock locks the stores like the inactivity timeout does, the next
+// command asks for the passphrase again
+func (s *Action) replLock(ctx context.Context, al *autolock.Watcher) {
+	al.Lock(autolock.Command)
 	out.OKf(ctx, "Locked")
 }
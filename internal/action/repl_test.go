@@ -0,0 +1,138 @@
+package action
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/interrupt"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestReplResolve(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		dir  string
+		name string
+		want string
+	}{
+		{"", "foo", "foo"},
+		{"websites", "example.org", "websites/example.org"},
+		{"websites", "example.org/", "websites/example.org/"},
+		{"websites", "/misc/wifi", "misc/wifi"},
+		{"websites/example.org", "..", "websites"},
+		{"websites", "../misc", "misc"},
+		{"websites", "../..", ""},
+		{"websites", "/", ""},
+	} {
+		assert.Equal(t, tc.want, replResolve(tc.dir, tc.name), "%s in %s", tc.name, tc.dir)
+	}
+}
+
+func TestReplArgs(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+
+	app := cli.NewApp()
+	app.Commands = act.GetCommands()
+
+	for _, tc := range []struct {
+		dir  string
+		args []string
+		want []string
+	}{
+		{"", []string{"show", "foo"}, []string{"show", "foo"}},
+		{"web", []string{"show", "foo", "user"}, []string{"show", "web/foo", "user"}},
+		{"web", []string{"show", "-u", "/misc/foo"}, []string{"show", "-u", "misc/foo"}},
+		{"web", []string{"show", "--revision", "-2", "foo"}, []string{"show", "--revision", "-2", "web/foo"}},
+		{"web", []string{"generate", "foo", "24"}, []string{"generate", "web/foo", "24"}},
+		{"web", []string{"mv", "foo", "bar/"}, []string{"mv", "web/foo", "web/bar/"}},
+		{"web", []string{"ls"}, []string{"ls", "web"}},
+		{"web", []string{"ls", "--flat"}, []string{"ls", "--flat", "web"}},
+		{"web", []string{"find", "foo"}, []string{"find", "foo"}},
+		{"web", []string{"nosuchcommand", "foo"}, []string{"nosuchcommand", "foo"}},
+	} {
+		assert.Equal(t, tc.want, replArgs(app, tc.dir, tc.args), "%q in %s", tc.args, tc.dir)
+	}
+}
+
+func TestReplCd(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+
+	sec := secrets.NewKV()
+	sec.SetPassword("pw")
+	require.NoError(t, act.Store.Set(ctx, "websites/example.org/alice", sec))
+
+	dir, err := act.replCd(ctx, "", []string{"websites"})
+	require.NoError(t, err)
+	assert.Equal(t, "websites", dir)
+	assert.Equal(t, "gopass:websites> ", replPrompt(dir))
+
+	dir, err = act.replCd(ctx, dir, []string{"example.org/"})
+	require.NoError(t, err)
+	assert.Equal(t, "websites/example.org", dir)
+
+	_, err = act.replCd(ctx, dir, []string{"alice"})
+	assert.Error(t, err)
+
+	dir, err = act.replCd(ctx, dir, []string{".."})
+	require.NoError(t, err)
+	assert.Equal(t, "websites", dir)
+
+	dir, err = act.replCd(ctx, dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", dir)
+	assert.Equal(t, "gopass> ", replPrompt(dir))
+}
+
+func TestReplRunInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no signals on windows")
+	}
+
+	ctx, stop := interrupt.Notify(context.Background())
+	defer stop()
+
+	// the command waits for Ctrl+C
+	started := make(chan struct{})
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{{
+		Name: "wait",
+		Action: func(c *cli.Context) error {
+			close(started)
+			<-c.Context.Done()
+			return c.Context.Err()
+		},
+	}}
+	go func() {
+		<-started
+		if p, err := os.FindProcess(os.Getpid()); err == nil {
+			_ = p.Signal(os.Interrupt)
+		}
+	}()
+
+	act := &Action{}
+	cctx, cancel := context.WithCancel(ctx)
+	act.replRun(cctx, cancel, app, []string{"wait"})
+	assert.Error(t, cctx.Err())
+
+	// only the command was canceled, the shell keeps running
+	assert.NoError(t, ctx.Err())
+}
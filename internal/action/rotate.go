@@ -0,0 +1,176 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+// rotatedURL is a service the user has to change a rotated password on
+type rotatedURL struct {
+	name string
+	url  string
+}
+
+// Rotate generates new passwords for all secrets matching the given prefixes
+// or glob patterns. The rest of each secret is kept and the replaced password
+// is archived like gopass generate does.
+func (s *Action) Rotate(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	if c.Args().Len() < 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s rotate [--dry-run] [--interactive] <prefix or pattern>...", s.Name)
+	}
+
+	names, err := s.rotateNames(ctx, c.Args().Slice())
+	if err != nil {
+		return err
+	}
+	if len(names) < 1 {
+		return ExitError(ExitNotFound, nil, "no secrets match %s", strings.Join(c.Args().Slice(), ", "))
+	}
+
+	interactive := c.Bool("interactive")
+	if !interactive && !ctxutil.IsDryRun(ctx) {
+		ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("Do you want to rotate the passwords of %d secrets?", len(names)), "--force")
+		if err != nil {
+			return ExitError(ExitAborted, err, "not rotating any password: %s", err)
+		}
+		if !ok {
+			return ExitError(ExitAborted, nil, "user aborted. not rotating any password")
+		}
+	}
+
+	return s.rotateSecrets(ctx, c, names, interactive)
+}
+
+// rotateSecrets generates new passwords for the given secrets in one batch.
+// If interactive is set it asks before rotating each secret.
+func (s *Action) rotateSecrets(ctx context.Context, c *cli.Context, names []string, interactive bool) error {
+	dryRun := ctxutil.IsDryRun(ctx)
+	if dryRun {
+		out.Printf(ctx, "Would rotate the passwords of %d secrets:", len(names))
+		for _, name := range names {
+			out.Printf(ctx, "  %s", name)
+		}
+	}
+
+	if err := s.warmup(ctx, c, names); err != nil {
+		return err
+	}
+
+	batch := "rotate-" + time.Now().UTC().Format("20060102T150405Z")
+	out.Printf(ctx, "Rotating the passwords of %d secrets in batch %s", len(names), batch)
+	ctx = ctxutil.WithCommitMessage(ctx, "Rotated password in batch "+batch)
+
+	var urls []rotatedURL
+	rotated, failed := 0, 0
+	for _, name := range names {
+		if interactive {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("Rotate the password of %s?", name), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "stopped rotating after %d secrets: %s", rotated, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		u, err := s.rotateSecret(ctx, c, name)
+		if err != nil {
+			out.Errorf(ctx, "Failed to rotate %s: %s", name, err)
+			failed++
+			continue
+		}
+		rotated++
+		if u != "" {
+			urls = append(urls, rotatedURL{name: name, url: u})
+		}
+	}
+
+	if !dryRun {
+		out.OKf(ctx, "Rotated %d passwords in batch %s", rotated, batch)
+		if len(urls) > 0 {
+			out.Printf(ctx, "Change the passwords on these services:")
+			for _, u := range urls {
+				out.Printf(ctx, "  [ ] %s: %s", u.name, u.url)
+			}
+		}
+	}
+	if failed > 0 {
+		return ExitError(ExitUnknown, nil, "failed to rotate %d of %d secrets", failed, len(names))
+	}
+	return nil
+}
+
+// rotateNames returns the writable secrets matching any of the given prefixes
+// or glob patterns, e.g. infra, infra/... or 'infra/*/root'
+func (s *Action) rotateNames(ctx context.Context, args []string) ([]string, error) {
+	patterns := make([]string, 0, len(args))
+	for _, a := range args {
+		patterns = append(patterns, strings.TrimSuffix(strings.TrimSuffix(a, "..."), "/"))
+	}
+
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list secrets: %s", err)
+	}
+
+	names := make([]string, 0, len(list))
+	for _, name := range list {
+		if !matchName(name, patterns) {
+			continue
+		}
+		if err := s.Store.CheckWritable(name); err != nil {
+			debug.Log("not rotating %s: %s", name, err)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// rotateSecret replaces the password of a single secret. It returns the URL
+// the password has to be changed on, if the secret has one.
+func (s *Action) rotateSecret(ctx context.Context, c *cli.Context, name string) (string, error) {
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read it: %w", err)
+	}
+	if secrets.IsAttachment(sec) {
+		return "", fmt.Errorf("it contains a file")
+	}
+
+	// the length and the password rules of the domain are applied without
+	// asking for each secret
+	pw, err := s.generatePassword(ctxutil.WithAlwaysYes(ctx, true), c, "", name)
+	if err != nil {
+		return "", err
+	}
+
+	if err := secrets.ArchivePassword(sec, sec.Password(), time.Now(), s.cfg.OldPasswords); err != nil {
+		return "", fmt.Errorf("failed to keep the old password: %w", err)
+	}
+	sec.SetPassword(pw)
+	if err := s.Store.Set(ctx, name, sec); err != nil {
+		return "", fmt.Errorf("failed to save it: %w", err)
+	}
+	if !ctxutil.IsDryRun(ctx) {
+		out.OKf(ctx, "Rotated %s", name)
+	}
+
+	if u, found := sec.Get("password-change-url"); found && u != "" {
+		return u, nil
+	}
+	u, _ := sec.Get("url")
+	return u, nil
+}
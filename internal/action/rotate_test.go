@@ -0,0 +1,114 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	for name, url := range map[string]string{
+		"infra/db/root":  "",
+		"infra/web/root": "https://web.example.org/admin",
+		"other/root":     "",
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword("old-" + name)
+		require.NoError(t, sec.Set("user", "root"))
+		if url != "" {
+			require.NoError(t, sec.Set("url", url))
+		}
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+
+	t.Run("no args", func(t *testing.T) {
+		assert.Error(t, act.Rotate(gptest.CliCtx(ctx, t)))
+		buf.Reset()
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert.Error(t, act.Rotate(gptest.CliCtx(ctx, t, "nothing")))
+		buf.Reset()
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		assert.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true"}, "infra/...")))
+		assert.Contains(t, buf.String(), "Would rotate the passwords of 2 secrets")
+		assert.Contains(t, buf.String(), "infra/db/root")
+		assert.NotContains(t, buf.String(), "other/root")
+		buf.Reset()
+
+		assert.NoError(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"dry-run": "true"}, "*/root")))
+		assert.Contains(t, buf.String(), "Would rotate the passwords of 1 secrets")
+		assert.Contains(t, buf.String(), "other/root")
+		assert.Contains(t, buf.String(), "Would overwrite other/root")
+		buf.Reset()
+
+		sec, err := act.Store.Get(ctx, "other/root")
+		require.NoError(t, err)
+		assert.Equal(t, "old-other/root", sec.Password())
+	})
+
+	t.Run("confirmation without terminal", func(t *testing.T) {
+		assert.Error(t, act.Rotate(gptest.CliCtx(ctx, t, "infra")))
+		assert.Error(t, act.Rotate(gptest.CliCtxWithFlags(ctx, t, map[string]string{"interactive": "true"}, "infra")))
+		sec, err := act.Store.Get(ctx, "infra/db/root")
+		require.NoError(t, err)
+		assert.Equal(t, "old-infra/db/root", sec.Password())
+		buf.Reset()
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		ctx := ctxutil.WithAlwaysYes(ctx, true)
+		assert.NoError(t, act.Rotate(gptest.CliCtx(ctx, t, "infra/")))
+		assert.Contains(t, buf.String(), "Rotated 2 passwords in batch rotate-")
+		assert.Contains(t, buf.String(), "Change the passwords on these services:")
+		assert.Contains(t, buf.String(), "[ ] infra/web/root: https://web.example.org/admin")
+		assert.NotContains(t, buf.String(), "infra/db/root: ")
+		buf.Reset()
+
+		for _, name := range []string{"infra/db/root", "infra/web/root"} {
+			sec, err := act.Store.Get(ctx, name)
+			require.NoError(t, err)
+			assert.Len(t, sec.Password(), defaultLength)
+			user, _ := sec.Get("user")
+			assert.Equal(t, "root", user)
+			old := secrets.OldPasswords(sec)
+			require.Len(t, old, 1)
+			pw, _ := sec.Get(old[0])
+			assert.Equal(t, "old-"+name, pw)
+		}
+
+		sec, err := act.Store.Get(ctx, "other/root")
+		require.NoError(t, err)
+		assert.Equal(t, "old-other/root", sec.Password())
+	})
+}
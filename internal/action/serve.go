@@ -0,0 +1,69 @@
+package action
+
+import (
+	"context"
+	"log"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/serve"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Serve serves a read-only REST API for other tools in the foreground until
+// it is interrupted
+func (s *Action) Serve(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	cfg := serve.Config{
+		Listen:         c.String("listen"),
+		Socket:         c.String("socket"),
+		CertFile:       c.String("tls-cert"),
+		KeyFile:        c.String("tls-key"),
+		Allow:          c.StringSlice("allow"),
+		InsecureRemote: c.Bool("insecure-remote"),
+		Log:            log.New(out.Stderr, "", log.LstdFlags),
+	}
+
+	var err error
+	generated := false
+	if fn := c.String("token-file"); fn != "" {
+		cfg.Token, err = serve.ReadToken(fn)
+	} else {
+		cfg.Token, err = serve.NewToken()
+		generated = true
+	}
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to get a token: %s", err)
+	}
+
+	actx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cfg.Activity = s.startAutoLock(actx, func(reason string) {
+		s.lockStores(actx, reason)
+		out.Noticef(ctx, "Locked the stores %s", s.lockReason(reason))
+	})
+
+	srv := serve.NewServer(s.Store, cfg)
+	if err := srv.Check(); err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	if cfg.Socket == "" && cfg.InsecureRemote && cfg.CertFile == "" {
+		out.Warningf(ctx, "Serving secrets to remote clients without TLS, the token and the secrets are sent in plain text")
+	}
+	if len(cfg.Allow) < 1 {
+		out.Noticef(ctx, "All secrets are served, restrict them with --allow")
+	}
+	if generated {
+		// the only time the token is shown
+		out.Printf(ctx, "Token: %s", cfg.Token)
+	}
+
+	out.Printf(ctx, "Serving secrets on %s. Press Ctrl+C to stop.", srv.Address())
+	if err := srv.Run(ctx); err != nil {
+		return ExitError(ExitIO, err, "failed to serve secrets: %s", err)
+	}
+	return nil
+}
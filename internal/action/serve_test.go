@@ -0,0 +1,59 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	t.Run("remote address", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Serve(gptest.CliCtxWithFlags(ctx, t, map[string]string{"listen": "0.0.0.0:9777"}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "--insecure-remote")
+	})
+
+	t.Run("missing token file", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Serve(gptest.CliCtxWithFlags(ctx, t, map[string]string{"token-file": filepath.Join(u.Dir, "missing")})))
+	})
+
+	t.Run("stops when interrupted", func(t *testing.T) {
+		defer buf.Reset()
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		require.NoError(t, act.Serve(gptest.CliCtxWithFlags(ctx, t, map[string]string{"socket": filepath.Join(u.Dir, "api.sock")})))
+		assert.Contains(t, buf.String(), "Token: ")
+		assert.Contains(t, buf.String(), "All secrets are served")
+	})
+}
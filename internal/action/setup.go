@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/gopasspw/gopass/internal/backend"
@@ -26,16 +28,23 @@ func (s *Action) Setup(c *cli.Context) error {
 
 	ctx = initParseContext(ctx, c)
 
+	if path := c.String("adopt"); path != "" {
+		return s.setupAdopt(ctx, c, path, c.String("mount"))
+	}
+
 	out.Printf(ctx, logo)
 	out.Printf(ctx, "🌟 Welcome to gopass!")
 	out.Printf(ctx, "🌟 Initializing a new password store ...")
 
-	if name := termio.DetectName(c.Context, c); name != "" {
+	if name := detectIdentity(ctx, c, "name", "user.name", termio.DetectName); name != "" {
 		ctx = ctxutil.WithUsername(ctx, name)
 	}
-	if email := termio.DetectEmail(c.Context, c); email != "" {
+	if email := detectIdentity(ctx, c, "email", "user.email", termio.DetectEmail); email != "" {
 		ctx = ctxutil.WithEmail(ctx, email)
 	}
+	if c.Bool("rsa") {
+		ctx = gpg.WithRSAKeys(ctx, true)
+	}
 	// need to re-initialize the root store or it's already initialized
 	// and won't properly set up crypto according to our context.
 	s.Store = root.New(s.cfg)
@@ -52,17 +61,20 @@ func (s *Action) Setup(c *cli.Context) error {
 
 	crypto := s.getCryptoFor(ctx, team)
 	if crypto == nil {
-		return fmt.Errorf("can not continue without crypto")
+		return ExitError(ExitUnsupported, nil, "can not continue without crypto")
 	}
 	debug.Log("Crypto Backend initialized as: %s", crypto.Name())
 
 	// check for existing GPG/Age keypairs (private/secret keys). We need at least
 	// one useable key pair. If none exists try to create one
 	if !s.initHasUseablePrivateKeys(ctx, crypto) {
-		out.Printf(ctx, "🔐 No useable cryptographic keys. Generating new key pair")
+		out.Printf(ctx, "🔐 No useable cryptographic keys found")
+		if want, err := termio.AskForBool(ctx, "❓ Do you want to generate a new key pair?", true); err != nil || !want {
+			return ExitError(ExitAborted, err, "can not continue without a useable key pair. Please create one, e.g. with `gpg --gen-key`")
+		}
 		out.Printf(ctx, "🕰 Key generation may take up to a few minutes")
 		if err := s.initGenerateIdentity(ctx, crypto, ctxutil.GetUsername(ctx), ctxutil.GetEmail(ctx)); err != nil {
-			return fmt.Errorf("failed to create new private key: %w", err)
+			return ExitError(ExitGPG, err, "failed to create new private key: %s", err)
 		}
 		out.Printf(ctx, "🔐 Cryptographic keys generated")
 	}
@@ -117,7 +129,15 @@ func (s *Action) initGenerateIdentity(ctx context.Context, crypto backend.Crypto
 		return fmt.Errorf("user aborted: %w", err)
 	}
 
-	if err := crypto.GenerateIdentity(ctx, name, email, passphrase); err != nil {
+	if kg, ok := crypto.(keyGenerator); ok {
+		key, err := kg.GenerateKey(ctx, name, email, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to create new private key: %w", err)
+		}
+		if key.Fingerprint != "" {
+			out.Printf(ctx, "🔑 %s", key.OneLine())
+		}
+	} else if err := crypto.GenerateIdentity(ctx, name, email, passphrase); err != nil {
 		return fmt.Errorf("failed to create new private key: %w", err)
 	}
 
@@ -149,6 +169,33 @@ func (s *Action) initGenerateIdentity(ctx context.Context, crypto backend.Crypto
 	return nil
 }
 
+type keyGenerator interface {
+	GenerateKey(ctx context.Context, name, email, passphrase string) (gpg.Key, error)
+}
+
+// detectIdentity returns the name or email of the user. An explicit flag takes
+// precedence over the global git config, which takes precedence over the
+// environment.
+func detectIdentity(ctx context.Context, c *cli.Context, flag, gitKey string, detect func(context.Context, *cli.Context) string) string {
+	if sv := c.String(flag); sv != "" {
+		return sv
+	}
+	if sv := gitConfigGet(ctx, gitKey); sv != "" {
+		return sv
+	}
+	return detect(ctx, c)
+}
+
+// gitConfigGet returns the value of the given key from the global git config
+func gitConfigGet(ctx context.Context, key string) string {
+	buf, err := exec.CommandContext(ctx, "git", "config", "--global", "--get", key).Output()
+	if err != nil {
+		debug.Log("failed to read git config %s: %s", key, err)
+		return ""
+	}
+	return strings.TrimSpace(string(buf))
+}
+
 type keyExporter interface {
 	ExportPublicKey(ctx context.Context, id string) ([]byte, error)
 }
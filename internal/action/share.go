@@ -0,0 +1,235 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/age"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/share"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/urfave/cli/v2"
+)
+
+// shareKeyFetcher is implemented by crypto backends that can download a
+// public key, e.g. from WKD or a keyserver
+type shareKeyFetcher interface {
+	FetchPublicKey(ctx context.Context, fingerprint string, emails ...string) ([]byte, error)
+	ReadKeys(ctx context.Context, buf []byte) (gpg.KeyList, error)
+	ImportPublicKey(ctx context.Context, buf []byte) error
+}
+
+// Share encrypts a secret once for someone who is not a recipient of the
+// store. The secret in the store is not changed.
+func (s *Action) Share(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s share <secret> --recipient <key>", s.Name)
+	}
+	recipient := c.String("recipient")
+	if recipient == "" {
+		return ExitError(ExitUsage, nil, "Missing --recipient, a GPG key or an age public key")
+	}
+	expire := c.Duration("expire")
+	if expire <= 0 {
+		return ExitError(ExitUsage, nil, "--expire must be positive")
+	}
+	output := c.String("output")
+	if output == "" && ctxutil.IsTerminal(ctx) {
+		return ExitError(ExitUsage, nil, "Refusing to write the ciphertext to the terminal, use --output")
+	}
+
+	target, sec, err := s.Store.Resolve(ctx, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ExitError(ExitNotFound, err, "Secret %s not found", name)
+		}
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s: %s", name, err)
+	}
+
+	shared := share.Secret{
+		Name:    target,
+		Expires: time.Now().Add(expire).UTC().Truncate(time.Second),
+		Content: sec.Bytes(),
+	}
+	buf, err := s.shareEncrypt(ctx, target, recipient, shared.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		if _, err := stdout.Write(buf); err != nil {
+			return ExitError(ExitIO, err, "failed to write the shared secret: %s", err)
+		}
+	} else {
+		if fsutil.IsFile(output) {
+			ok, err := termio.ConfirmDestructive(ctx, fmt.Sprintf("%s exists. Overwrite it?", output), "--force")
+			if err != nil {
+				return ExitError(ExitAborted, err, "not overwriting %s: %s", output, err)
+			}
+			if !ok {
+				return ExitError(ExitAborted, nil, "not overwriting your current file")
+			}
+		}
+		if err := os.WriteFile(output, buf, 0600); err != nil {
+			return ExitError(ExitIO, err, "failed to write %s: %s", output, err)
+		}
+		out.OKf(ctx, "Shared %s with %s until %s in %s", target, recipient, shared.Expires.Local().Format(time.RFC1123), output)
+	}
+
+	if err := s.Store.RecordShare(ctx, target, recipient, shared.Expires); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			out.Noticef(ctx, "The share was not recorded, the store has no history")
+			return nil
+		}
+		return ExitError(ExitGit, err, "failed to record the share: %s", err)
+	}
+	return nil
+}
+
+// shareEncrypt encrypts the plaintext only for the recipient. age public keys
+// are used as is, any other recipient must be a GPG key. If it's not in the
+// keyring it's fetched after a confirmation.
+func (s *Action) shareEncrypt(ctx context.Context, name, recipient string, plaintext []byte) ([]byte, error) {
+	if age.IsRecipient(recipient) {
+		buf, err := age.EncryptFor(plaintext, recipient)
+		if err != nil {
+			return nil, ExitError(ExitEncrypt, err, "failed to encrypt for %s: %s", recipient, err)
+		}
+		return buf, nil
+	}
+
+	crypto := s.Store.Crypto(ctx, name)
+	if crypto == nil || crypto.Name() == "age" {
+		c, err := backend.NewCrypto(ctx, backend.GPGCLI)
+		if err != nil {
+			return nil, ExitError(ExitRecipients, err, "%s is not an age public key and GPG is not available: %s", recipient, err)
+		}
+		crypto = c
+	}
+
+	recps, err := crypto.FindRecipients(ctx, recipient)
+	if err != nil {
+		return nil, ExitError(ExitRecipients, err, "failed to look up %s: %s", recipient, err)
+	}
+	if len(recps) < 1 {
+		if err := shareFetchKey(ctx, crypto, recipient); err != nil {
+			return nil, err
+		}
+		if recps, err = crypto.FindRecipients(ctx, recipient); err != nil || len(recps) < 1 {
+			return nil, ExitError(ExitRecipients, err, "no usable key found for %s", recipient)
+		}
+	}
+	if len(recps) > 1 {
+		return nil, ExitError(ExitRecipients, nil, "%s matches %d keys, use the fingerprint", recipient, len(recps))
+	}
+
+	id := recps[0]
+	if rr, ok := crypto.(recipientResolver); ok {
+		if fp, err := rr.ResolveRecipient(ctx, id); err == nil {
+			id = fp
+		}
+	}
+	out.Noticef(ctx, "Sharing %s with %s", name, recipientLine(ctx, crypto, id))
+
+	buf, err := crypto.Encrypt(ctx, plaintext, []string{id})
+	if err != nil {
+		return nil, ExitError(ExitEncrypt, err, "failed to encrypt for %s: %s", recipient, err)
+	}
+	return buf, nil
+}
+
+// shareFetchKey downloads the key of the recipient and imports it once the
+// user has checked the fingerprint
+func shareFetchKey(ctx context.Context, crypto backend.Crypto, recipient string) error {
+	kf, ok := crypto.(shareKeyFetcher)
+	if !ok || (!ctxutil.IsInteractive(ctx) && !ctxutil.IsConfirm(ctx)) || ctxutil.IsNoNetwork(ctx) {
+		debug.Log("not fetching key %s for %T", recipient, crypto)
+		return ExitError(ExitRecipients, nil, "the key of %s is not in the keyring", recipient)
+	}
+	if !termio.Confirm(ctx, fmt.Sprintf("The key of %s is not in the keyring. Fetch it from WKD or the keyserver?", recipient)) {
+		return ExitError(ExitAborted, nil, "the key of %s is not in the keyring", recipient)
+	}
+
+	var emails []string
+	if a, err := mail.ParseAddress(recipient); err == nil {
+		emails = append(emails, a.Address)
+	}
+	buf, err := kf.FetchPublicKey(ctx, recipient, emails...)
+	if err != nil {
+		return ExitError(ExitRecipients, err, "failed to fetch the key of %s: %s", recipient, err)
+	}
+	kl, err := kf.ReadKeys(ctx, buf)
+	if err != nil || len(kl) < 1 {
+		return ExitError(ExitRecipients, err, "failed to read the fetched key of %s", recipient)
+	}
+	for _, k := range kl {
+		out.Printf(ctx, "Fetched %s\n  Fingerprint: %s", k.OneLine(), k.Fingerprint)
+	}
+	if !termio.Confirm(ctx, "Do the fingerprints match the key you want to share with?") {
+		return ExitError(ExitAborted, nil, "user aborted")
+	}
+	if err := kf.ImportPublicKey(ctx, buf); err != nil {
+		return ExitError(ExitRecipients, err, "failed to import the key of %s: %s", recipient, err)
+	}
+	return nil
+}
+
+// showFromFile shows a secret shared with gopass share unless it has expired
+func (s *Action) showFromFile(ctx context.Context, filename string) error {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to read %s: %s", filename, err)
+	}
+
+	crypto, err := s.shareCrypto(ctx, buf)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "no crypto backend to decrypt %s: %s", filename, err)
+	}
+	plain, err := crypto.Decrypt(ctx, buf)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "failed to decrypt %s: %s", filename, err)
+	}
+
+	shared, err := share.Parse(plain)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "%s", err)
+	}
+	if shared.Expired(time.Now()) {
+		return ExitError(ExitAborted, nil, "%s was shared until %s and has expired", shared.Name, shared.Expires.Local().Format(time.RFC1123))
+	}
+	debug.Log("shared secret %s expires at %s", shared.Name, shared.Expires)
+
+	sec, err := secparse.Parse(shared.Content)
+	if err != nil {
+		return ExitError(ExitDecrypt, err, "failed to parse %s: %s", shared.Name, err)
+	}
+	return s.showHandleOutput(ctx, shared.Name, sec)
+}
+
+// shareCrypto returns the crypto backend for a shared ciphertext. The store
+// crypto is preferred, it might be a mock.
+func (s *Action) shareCrypto(ctx context.Context, buf []byte) (backend.Crypto, error) {
+	crypto := s.Store.Crypto(ctx, "")
+	isAge := crypto != nil && crypto.Name() == "age"
+	if crypto != nil && isAge == age.IsCiphertext(buf) {
+		return crypto, nil
+	}
+	if age.IsCiphertext(buf) {
+		return backend.NewCrypto(ctx, backend.Age)
+	}
+	return backend.NewCrypto(ctx, backend.GPGCLI)
+}
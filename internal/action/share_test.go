@@ -0,0 +1,85 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/share"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShare(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	fn := filepath.Join(u.Dir, "foo.share")
+
+	t.Run("missing recipient", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Share(gptest.CliCtxWithFlags(ctx, t, map[string]string{"expire": "1h"}, "foo")))
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Share(gptest.CliCtxWithFlags(ctx, t, map[string]string{"expire": "1h", "recipient": "0xC0FFEE"}, "foo")))
+	})
+
+	t.Run("share to file", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Share(gptest.CliCtxWithFlags(ctx, t, map[string]string{"expire": "1h", "recipient": "FEEDBEEF", "output": fn}, "foo")))
+		assert.Contains(t, buf.String(), "Shared foo with FEEDBEEF")
+
+		// the store is not changed
+		sec, err := act.Store.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+	})
+
+	t.Run("show from file", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"from-file": fn})))
+		assert.Equal(t, "secret\nsecond\nthird", buf.String())
+	})
+
+	t.Run("show expired", func(t *testing.T) {
+		defer buf.Reset()
+		expired := share.Secret{
+			Name:    "foo",
+			Expires: time.Now().Add(-time.Minute),
+			Content: []byte("secret\n"),
+		}
+		// the plain mock doesn't encrypt
+		require.NoError(t, os.WriteFile(fn, expired.Bytes(), 0600))
+
+		err := act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"from-file": fn}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has expired")
+		assert.NotContains(t, buf.String(), "secret")
+	})
+}
@@ -1,15 +1,22 @@
 package action
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/expiry"
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/autotype"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -17,7 +24,10 @@ import (
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
 	"github.com/gopasspw/gopass/pkg/qrcon"
+	"github.com/gopasspw/gopass/pkg/termio"
 
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
@@ -32,12 +42,24 @@ func showParseArgs(c *cli.Context) context.Context {
 	if c.IsSet("qr") {
 		ctx = WithPrintQR(ctx, c.Bool("qr"))
 	}
+	if c.IsSet("type") {
+		ctx = WithAutotype(ctx, c.Bool("type"))
+	}
+	if c.IsSet("key") {
+		ctx = WithKey(ctx, c.String("key"))
+	}
 	if c.IsSet("password") {
 		ctx = WithPasswordOnly(ctx, c.Bool("password"))
 	}
+	if c.IsSet("no-newline") {
+		ctx = WithNoNewline(ctx, c.Bool("no-newline"))
+	}
 	if c.IsSet("revision") {
 		ctx = WithRevision(ctx, c.String("revision"))
 	}
+	if c.IsSet("with-meta") {
+		ctx = WithShowMeta(ctx, c.Bool("with-meta"))
+	}
 	if c.IsSet("alsoclip") {
 		ctx = WithAlsoClip(ctx, c.Bool("alsoclip"))
 	}
@@ -45,59 +67,152 @@ func showParseArgs(c *cli.Context) context.Context {
 		ctx = ctxutil.WithShowParsing(ctx, !c.Bool("noparsing"))
 	}
 	ctx = WithClip(ctx, IsOnlyClip(ctx) || IsAlsoClip(ctx))
+
+	// safecontent protects against shoulder surfing. Scripts reading the
+	// output keep getting the full secret unless it is forced with --safe.
+	if c.Bool("safe") {
+		ctx = ctxutil.WithShowSafeContent(ctx, true)
+	} else if !ctxutil.IsTerminal(ctx) {
+		ctx = ctxutil.WithShowSafeContent(ctx, false)
+	}
 	return ctx
 }
 
+// showIsInitialized checks that the store is initialized unless a shared
+// secret is shown, it can be shown without a store
+func (s *Action) showIsInitialized(c *cli.Context) error {
+	if c.IsSet("from-file") {
+		return nil
+	}
+	return s.IsInitialized(c)
+}
+
 // Show the content of a secret file
 func (s *Action) Show(c *cli.Context) error {
 	name := c.Args().First()
 
 	ctx := showParseArgs(c)
 
+	if fn := c.String("from-file"); fn != "" {
+		return s.showFromFile(ctx, fn)
+	}
+
+	if c.IsSet("chars") {
+		pos, err := parseCharPositions(c.String("chars"))
+		if err != nil {
+			return ExitError(ExitUsage, err, "%s", err)
+		}
+		ctx = WithChars(ctx, pos)
+	}
+
 	if key := c.Args().Get(1); key != "" {
 		debug.Log("Adding key to ctx: %s", key)
 		ctx = WithKey(ctx, key)
 	}
 
+	if c.String("format") == formatK8s {
+		return s.showK8s(ctx, name)
+	}
+
+	format, err := outputFormat(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+
+	if c.Bool("recursive") {
+		return s.showRecursive(ctx, name, format)
+	}
+
+	if out.IsStructured(format) {
+		return s.showStructured(ctx, name, format)
+	}
+
+	if s.isShowAutoClip(ctx, c) {
+		ctx = WithClip(WithOnlyClip(ctx, true), true)
+	}
+
 	if err := s.show(ctx, c, name, true); err != nil {
-		return ExitError(ExitDecrypt, err, "%s", err)
+		// scripts using --password can rely on exit code 1 for any error
+		if IsPasswordOnly(ctx) {
+			return ExitError(ExitUnknown, err, "%s", err)
+		}
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "%s", err)
 	}
 	return nil
 }
 
+// isShowAutoClip returns true if show should copy the password instead of
+// printing it, because showautoclip is enabled, stdout is a terminal and no
+// other output was asked for
+func (s *Action) isShowAutoClip(ctx context.Context, c *cli.Context) bool {
+	if !s.cfg.ShowAutoClip || !ctxutil.IsTerminal(ctx) {
+		return false
+	}
+	for _, flag := range []string{"clip", "alsoclip", "qr", "type", "chars", "password", "revision"} {
+		if c.IsSet(flag) {
+			return false
+		}
+	}
+	return true
+}
+
 // show displays the given secret/key
 func (s *Action) show(ctx context.Context, c *cli.Context, name string, recurse bool) error {
 	if name == "" {
 		return ExitError(ExitUsage, nil, "Usage: %s show [name]", s.Name)
 	}
 
-	if s.Store.IsDir(ctx, name) && !s.Store.Exists(ctx, name) {
+	// the secret wins over the folder of the same name, a trailing slash
+	// selects the folder
+	if s.Store.IsDir(ctx, name) && (strings.HasSuffix(name, "/") || !s.Store.Exists(ctx, name)) {
+		s.showShadowedNote(ctx, name)
 		return s.List(c)
 	}
-	if s.Store.IsDir(ctx, name) && ctxutil.IsTerminal(ctx) {
-		out.Warningf(ctx, "%s is a secret and a folder. Use 'gopass show %s' to display the secret and 'gopass list %s' to show the content of the folder", name, name, name)
+	if s.Store.IsDir(ctx, name) {
+		out.Warningf(ctx, "Note: there is a folder named %s as well. Use 'gopass list %s' to show its content", name, name)
 	}
 
 	if HasRevision(ctx) {
 		return s.showHandleRevision(ctx, c, name, GetRevision(ctx))
 	}
 
-	sec, err := s.Store.Get(ctx, name)
+	// links show their target
+	target, sec, err := s.Store.Resolve(ctx, name)
 	if err != nil {
 		return s.showHandleError(ctx, c, name, recurse, err)
 	}
 
-	return s.showHandleOutput(ctx, name, sec)
+	return s.showHandleOutput(ctx, target, sec)
+}
+
+// showShadowedNote points out that a mount point is hiding a secret of the
+// same name in the parent store
+func (s *Action) showShadowedNote(ctx context.Context, name string) {
+	name = strings.TrimSuffix(name, "/")
+	if s.Store.MountPoint(name) != name {
+		return
+	}
+	shadowed, err := s.Store.Shadowed(ctx)
+	if err != nil {
+		debug.Log("failed to list shadowed entries: %s", err)
+		return
+	}
+	if mp, found := shadowed[name]; found {
+		out.Warningf(ctx, "Note: the secret %s of the parent store is hidden by the mount %s", name, mp)
+	}
 }
 
 // showHandleRevision displays a single revision
 func (s *Action) showHandleRevision(ctx context.Context, c *cli.Context, name, revision string) error {
 	revision, err := s.parseRevision(ctx, name, revision)
 	if err != nil {
-		return ExitError(ExitUnknown, err, "Failed to get revisions: %s", err)
+		return ExitError(ExitGit, err, "Failed to get revisions: %s", err)
 	}
 
 	ctx, sec, err := s.Store.GetRevision(ctx, name, revision)
+	if errors.Is(err, store.ErrNotFound) {
+		return ExitError(ExitNotFound, err, "Secret %q did not exist at revision %s", name, revision)
+	}
 	if err != nil {
 		return s.showHandleError(ctx, c, name, false, err)
 	}
@@ -135,11 +250,20 @@ func (s *Action) parseRevision(ctx context.Context, name, revision string) (stri
 
 // showHandleOutput displays a secret
 func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.Secret) error {
+	showExpiry(ctx, sec)
+
 	pw, body, err := s.showGetContent(ctx, sec)
 	if err != nil {
 		return err
 	}
 
+	if IsPasswordOnly(ctx) && pw == "" {
+		if HasKey(ctx) {
+			return ExitError(ExitNotFound, store.ErrEmptySecret, "key %q of %s is empty", GetKey(ctx), name)
+		}
+		return ExitError(ExitNotFound, store.ErrEmptySecret, "%s has no password", name)
+	}
+
 	if pw == "" && body == "" {
 		if ctxutil.IsShowSafeContent(ctx) && !ctxutil.IsForce(ctx) {
 			out.Warning(ctx, "safecontent=true. Use -f to display password, if any")
@@ -147,23 +271,47 @@ func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.S
 		return ExitError(ExitNotFound, store.ErrEmptySecret, store.ErrEmptySecret.Error())
 	}
 
-	if IsPrintQR(ctx) && pw != "" {
-		if err := s.showPrintQR(name, pw); err != nil {
+	if pw != "" && !IsOnlyClip(ctx) && !IsAutotype(ctx) && (HasChars(ctx) || IsPrintQR(ctx) || strings.Contains(body, pw)) {
+		if err := s.checkRecording(ctx, name); err != nil {
 			return err
 		}
 	}
 
+	if HasChars(ctx) {
+		return s.showChars(ctx, name, pw)
+	}
+
+	if IsAutotype(ctx) {
+		return s.showAutotype(ctx, name, sec, pw)
+	}
+
 	if IsClip(ctx) && pw != "" {
+		// the autotype setting replaces copying to the clipboard, except over
+		// SSH where typing is not available
+		if IsOnlyClip(ctx) && s.cfg.AutoType && !autotype.IsSSH() {
+			return s.showAutotype(ctx, name, sec, pw)
+		}
 		if err := clipboard.CopyTo(ctx, name, []byte(pw), s.cfg.ClipTimeout); err != nil {
 			return err
 		}
 	}
 
+	if IsPrintQR(ctx) && pw != "" {
+		if err := s.showPrintQR(ctx, name, pw); err != nil {
+			return err
+		}
+	}
+
 	if body == "" {
 		return nil
 	}
 
-	ctx = out.WithNewline(ctx, ctxutil.IsTerminal(ctx))
+	// binary content could mess up the terminal
+	if ctxutil.IsTerminal(ctx) && !ctxutil.IsForce(ctx) && !HasKey(ctx) && secrets.IsAttachment(sec) {
+		return ExitError(ExitUnsupported, nil, "%s contains binary data. Use '%s cat %s' or '%s fscopy %s <file>' to extract it or -f to show it anyway", name, s.Name, name, s.Name, name)
+	}
+
+	ctx = out.WithNewline(ctx, ctxutil.IsTerminal(ctx) && !IsNoNewline(ctx))
 	if ctxutil.IsTerminal(ctx) && !IsPasswordOnly(ctx) {
 		header := fmt.Sprintf("Secret: %s\n", name)
 		if HasKey(ctx) {
@@ -177,9 +325,67 @@ func (s *Action) showHandleOutput(ctx context.Context, name string, sec gopass.S
 	// output the actual secret, newlines are handled by ctx and Print
 	out.Print(ctx, out.Secret(body))
 
+	s.showMeta(ctx, name, strings.HasSuffix(body, "\n"))
 	return nil
 }
 
+// checkRecording returns an error if the terminal seems to be recorded, e.g.
+// by asciinema or script, so printing the password would leak it into the
+// recording. Pipes are never checked, scripts rely on the output.
+func (s *Action) checkRecording(ctx context.Context, name string) error {
+	if !s.cfg.RecordingCheck || !ctxutil.IsTerminal(ctx) || ctxutil.IsForce(ctx) {
+		return nil
+	}
+	v := termio.RecordedBy(s.cfg.GetRecordingVars())
+	if v == "" {
+		return nil
+	}
+	return ExitError(ExitUnsupported, nil, "Refusing to print the password of %s, the terminal seems to be recorded (%s is set). Use '%s show -c %s' to copy it or --force to print it anyway", name, v, s.Name, name)
+}
+
+// showMeta prints when the secret was created and last changed. --with-meta
+// prints all of it, the metadata option adds a short footer on terminals.
+func (s *Action) showMeta(ctx context.Context, name string, endsWithNewline bool) {
+	if IsPasswordOnly(ctx) {
+		return
+	}
+	if !IsShowMeta(ctx) && (!s.cfg.Metadata || !ctxutil.IsTerminal(ctx) || HasKey(ctx)) {
+		return
+	}
+
+	m, err := s.Store.Meta(ctx, name)
+	if err != nil || m.IsZero() {
+		debug.Log("no metadata for %s: %v", name, err)
+		return
+	}
+
+	if !out.HasNewline(ctx) && !endsWithNewline {
+		out.Print(ctx, "\n")
+	}
+	ctx = out.WithNewline(ctx, true)
+
+	if !IsShowMeta(ctx) {
+		out.Print(ctx, "last changed "+metaChange(m))
+		return
+	}
+
+	if !m.Created.IsZero() {
+		out.Printf(ctx, "Created:  %s (%s)", m.Created.Format(time.RFC1123), humanize.Time(m.Created))
+	}
+	out.Printf(ctx, "Modified: %s (%s)", m.Modified.Format(time.RFC1123), metaChange(m))
+	if m.Revision != "" {
+		out.Printf(ctx, "Revision: %s", m.Revision)
+	}
+}
+
+// metaChange describes the last change, e.g. "3 months ago by alice"
+func metaChange(m backend.Meta) string {
+	if m.Author == "" {
+		return humanize.Time(m.Modified)
+	}
+	return humanize.Time(m.Modified) + " by " + m.Author
+}
+
 func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string, string, error) {
 	// YAML key
 	if HasKey(ctx) && ctxutil.IsShowParsing(ctx) {
@@ -189,6 +395,9 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 			return "", "", ExitError(ExitNotFound, store.ErrNoKey, store.ErrNoKey.Error())
 		}
 		val := strings.Join(values, "\n")
+		if IsPrintQR(ctx) || HasChars(ctx) {
+			return val, "", nil
+		}
 		return val, val, nil
 	} else if HasKey(ctx) {
 		out.Warning(ctx, "Parsing is disabled but a key was provided.")
@@ -200,21 +409,29 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 	fullBody := strings.TrimPrefix(string(sec.Bytes()), secrets.Ident+"\n")
 
 	// first line of the secret only
-	if IsPrintQR(ctx) || IsOnlyClip(ctx) {
+	if IsPrintQR(ctx) || IsOnlyClip(ctx) || IsAutotype(ctx) || HasChars(ctx) {
 		return pw, "", nil
 	}
+	// the first line verbatim, without a CRLF line ending
 	if IsPasswordOnly(ctx) {
+		pw = strings.TrimSuffix(pw, "\r")
 		return pw, pw, nil
 	}
 
-	// everything but the first line
+	// everything but the password
 	if ctxutil.IsShowSafeContent(ctx) && !ctxutil.IsForce(ctx) {
 		var sb strings.Builder
+		if pw != "" {
+			sb.WriteString(randAsterisk())
+			if sec.Body() != "" || len(sec.Keys()) > 0 {
+				sb.WriteString("\n")
+			}
+		}
 		for i, k := range sec.Keys() {
 			sb.WriteString(k)
 			sb.WriteString(": ")
 			// check if this key should be obstructed
-			if isUnsafeKey(k, sec) {
+			if isUnsafeKey(k, sec, s.cfg.GetUnsafeKeys()) {
 				debug.Log("obstructing unsafe key %s", k)
 				sb.WriteString(randAsterisk())
 			} else {
@@ -241,11 +458,61 @@ func (s *Action) showGetContent(ctx context.Context, sec gopass.Secret) (string,
 	return sec.Password(), fullBody, nil
 }
 
-func isUnsafeKey(key string, sec gopass.Secret) bool {
-	if strings.ToLower(key) == "password" {
+// showExpiry prints a banner if the secret has an expiry date. It's only
+// printed to a terminal and on stderr, so the output stays as it is.
+func showExpiry(ctx context.Context, sec gopass.Secret) {
+	if !ctxutil.IsTerminal(ctx) {
+		return
+	}
+	exp, found, err := expiry.Get(sec)
+	if !found {
+		return
+	}
+	if err != nil {
+		out.Warningf(ctx, "%s", err)
+		return
+	}
+
+	now := time.Now()
+	colorFn := color.YellowString
+	if expiry.IsExpired(exp, now) {
+		colorFn = color.RedString
+	}
+	fmt.Fprintln(out.Stderr, colorFn(expiry.Describe(exp, now)))
+}
+
+// showAutotype types the secret into the focused window. If a key was given
+// only its value is typed, otherwise the autotype sequence of the secret.
+func (s *Action) showAutotype(ctx context.Context, name string, sec gopass.Secret, pw string) error {
+	steps := []autotype.Step{{Text: pw}}
+	if !HasKey(ctx) {
+		var err error
+		steps, err = autotype.Sequence(sec)
+		if err != nil {
+			return ExitError(ExitUsage, err, "invalid autotype sequence in %s: %s", name, err)
+		}
+	}
+
+	if err := autotype.Type(ctx, name, steps); err != nil {
+		return ExitError(ExitUnsupported, err, "failed to type %s: %s", name, err)
+	}
+	return nil
+}
+
+// isUnsafeKey returns true if the key must be obstructed by safecontent. These
+// are the password key, the replaced passwords, the configured unsafe keys and
+// the ones listed in the unsafe-keys key of the secret.
+func isUnsafeKey(key string, sec gopass.Secret, unsafeKeys []string) bool {
+	if strings.ToLower(key) == "password" || secrets.IsOldPassword(key) {
 		return true
 	}
 
+	for _, uk := range unsafeKeys {
+		if strings.EqualFold(uk, key) {
+			return true
+		}
+	}
+
 	uks, found := sec.Get("unsafe-keys")
 	if !found || uks == "" {
 		return false
@@ -292,7 +559,7 @@ func (s *Action) showHandleError(ctx context.Context, c *cli.Context, name strin
 		if IsClip(ctx) {
 			_ = notify.Notify(ctx, "gopass - error", fmt.Sprintf("failed to retrieve secret %q: %s", name, err))
 		}
-		return ExitError(ExitUnknown, err, "failed to retrieve secret %q: %s", name, err)
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", name, err)
 	}
 
 	if newName := s.hasAliasDomain(ctx, name); newName != "" {
@@ -314,11 +581,97 @@ func (s *Action) showHandleError(ctx context.Context, c *cli.Context, name strin
 	return nil
 }
 
-func (s *Action) showPrintQR(name, pw string) error {
-	qr, err := qrcon.QRCode(pw)
+// showPrintQR prints the value as a QR code. The value itself is only printed
+// along with it in password only mode. On a terminal the QR code is cleared
+// after the configured timeout.
+func (s *Action) showPrintQR(ctx context.Context, name, pw string) error {
+	qr, err := qrcon.Terminal(pw)
 	if err != nil {
+		if errors.Is(err, qrcon.ErrTooLong) {
+			return ExitError(ExitUnsupported, err, "failed to encode %q as QR: %s", name, err)
+		}
 		return ExitError(ExitUnknown, err, "failed to encode %q as QR: %s", name, err)
 	}
-	fmt.Fprintln(stdout, qr)
+	qr += "\n"
+	if IsPasswordOnly(ctx) {
+		qr += pw + "\n"
+	}
+	s.printTimed(ctx, qr)
+	return nil
+}
+
+// showChars prints the characters at the requested positions of the
+// password, e.g. for banks asking for some of them. With --clip they are
+// copied instead, without any separator.
+func (s *Action) showChars(ctx context.Context, name, pw string) error {
+	runes := []rune(pw)
+	pos := GetChars(ctx)
+	chars := make([]rune, 0, len(pos))
+	for _, p := range pos {
+		if p > len(runes) {
+			return ExitError(ExitUsage, nil, "position %d exceeds the password of %s, it has %d characters", p, name, len(runes))
+		}
+		chars = append(chars, runes[p-1])
+	}
+
+	if IsClip(ctx) {
+		if err := clipboard.CopyTo(ctx, name, []byte(string(chars)), s.cfg.ClipTimeout); err != nil {
+			return err
+		}
+		if IsOnlyClip(ctx) {
+			return nil
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "Position")
+	for _, p := range pos {
+		fmt.Fprintf(tw, "\t%d", p)
+	}
+	fmt.Fprint(tw, "\nCharacter")
+	for _, c := range chars {
+		fmt.Fprintf(tw, "\t%c", c)
+	}
+	fmt.Fprint(tw, "\n")
+	_ = tw.Flush()
+
+	s.printTimed(ctx, buf.String())
 	return nil
 }
+
+// parseCharPositions parses a comma separated list of 1-indexed positions
+func parseCharPositions(in string) ([]int, error) {
+	var pos []int
+	for _, p := range strings.Split(in, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		i, err := strconv.Atoi(p)
+		if err != nil || i < 1 {
+			return nil, fmt.Errorf("invalid position %q, positions start at 1", p)
+		}
+		pos = append(pos, i)
+	}
+	if len(pos) < 1 {
+		return nil, fmt.Errorf("no positions given, e.g. --chars 3,7,12")
+	}
+	return pos, nil
+}
+
+// printTimed prints sensitive output like a QR code. On a terminal it's
+// cleared after the configured timeout.
+func (s *Action) printTimed(ctx context.Context, text string) {
+	fmt.Fprint(stdout, text)
+
+	if !ctxutil.IsTerminal(ctx) || s.cfg.QRTimeout < 1 {
+		return
+	}
+	select {
+	case <-time.After(time.Duration(s.cfg.QRTimeout) * time.Second):
+	case <-ctx.Done():
+	}
+	// move the cursor up to the start of the output and clear the screen below
+	fmt.Fprintf(stdout, "\033[%dA\033[J", strings.Count(text, "\n"))
+}
@@ -5,17 +5,24 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/atotto/clipboard"
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/autotype"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/qrcon"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 )
 
 func TestShowMulti(t *testing.T) {
@@ -70,6 +77,7 @@ func TestShowMulti(t *testing.T) {
 
 	t.Run("show twoliner with safecontent enabled", func(t *testing.T) {
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 		c := gptest.CliCtx(ctx, t, "bar/baz")
 
 		assert.NoError(t, act.Show(c))
@@ -81,6 +89,7 @@ func TestShowMulti(t *testing.T) {
 
 	t.Run("show foo with safecontent enabled, should error out", func(t *testing.T) {
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 
 		c := gptest.CliCtx(ctx, t, "foo")
 		assert.NoError(t, act.Show(c))
@@ -97,6 +106,7 @@ func TestShowMulti(t *testing.T) {
 
 	t.Run("show twoliner with safecontent enabled, but with the clip flag, which should copy just the secret", func(t *testing.T) {
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"clip": "true"}, "bar/baz")
 
 		assert.NoError(t, act.Show(c))
@@ -115,6 +125,7 @@ func TestShowMulti(t *testing.T) {
 		buf.Reset()
 
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 		c := gptest.CliCtx(ctx, t, "unsafe/keys")
 		assert.NoError(t, act.Show(c))
 		assert.Contains(t, buf.String(), "*****")
@@ -125,6 +136,7 @@ func TestShowMulti(t *testing.T) {
 
 	t.Run("show twoliner with safecontent enabled", func(t *testing.T) {
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 		c := gptest.CliCtx(ctx, t, "bar/baz")
 
 		assert.NoError(t, act.Show(c))
@@ -136,14 +148,67 @@ func TestShowMulti(t *testing.T) {
 
 	t.Run("show twoliner with parsing disabled and safecontent enabled", func(t *testing.T) {
 		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
 		ctx = ctxutil.WithShowParsing(ctx, false)
 		c := gptest.CliCtx(ctx, t, "bar/baz")
 
 		assert.NoError(t, act.Show(c))
 		assert.Contains(t, buf.String(), "bar: zab")
-		// password should not show up neither be obstructed
+		// the password line is obstructed
 		assert.NotContains(t, buf.String(), "123")
-		assert.NotContains(t, buf.String(), "***")
+		assert.Contains(t, buf.String(), "*****\n")
+		buf.Reset()
+	})
+
+	t.Run("show twoliner with safecontent enabled masks the password line", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
+		c := gptest.CliCtx(ctx, t, "bar/baz")
+
+		assert.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "*****\nbar: zab")
+		buf.Reset()
+	})
+
+	t.Run("show with safecontent enabled and password only", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "bar/baz")
+
+		assert.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "123")
+		buf.Reset()
+	})
+
+	t.Run("show with safecontent enabled but not to a terminal", func(t *testing.T) {
+		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		c := gptest.CliCtx(ctx, t, "bar/baz")
+
+		assert.NoError(t, act.Show(c))
+		assert.Equal(t, "123\nbar: zab", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("show forced safe not to a terminal", func(t *testing.T) {
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"safe": "true"}, "bar/baz")
+
+		assert.NoError(t, act.Show(c))
+		assert.Equal(t, "*****\nbar: zab", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("show with configured unsafe keys", func(t *testing.T) {
+		act.cfg.UnsafeKeys = "hello"
+		defer func() {
+			act.cfg.UnsafeKeys = ""
+		}()
+
+		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
+		c := gptest.CliCtx(ctx, t, "unsafe/keys")
+		assert.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "hello: *****")
+		assert.NotContains(t, buf.String(), "world")
 		buf.Reset()
 	})
 
@@ -324,6 +389,199 @@ func TestShowAutoClip(t *testing.T) {
 	})
 }
 
+func TestShowShowAutoClip(t *testing.T) {
+	ov := clipboard.Unsupported
+	defer func() {
+		clipboard.Unsupported = ov
+	}()
+	clipboard.Unsupported = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.ShowAutoClip = true
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	t.Run("copy on a terminal", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "foo")))
+		assert.Contains(t, buf.String(), "WARNING")
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("print with clip=false", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctxutil.WithTerminal(ctx, true), t, map[string]string{"clip": "false"}, "foo")))
+		assert.Contains(t, buf.String(), "secret")
+	})
+
+	t.Run("print to a pipe", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, false), t, "foo")))
+		assert.NotContains(t, buf.String(), "WARNING")
+		assert.Contains(t, buf.String(), "secret")
+	})
+}
+
+func TestShowRecording(t *testing.T) {
+	ov := clipboard.Unsupported
+	defer func() {
+		clipboard.Unsupported = ov
+	}()
+	clipboard.Unsupported = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	act.cfg.RecordingVars = "GOPASS_TEST_RECORDING"
+	t.Setenv("GOPASS_TEST_RECORDING", "1")
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	t.Run("refuse on a terminal", func(t *testing.T) {
+		defer buf.Reset()
+		for _, flags := range []map[string]string{{}, {"password": "true"}, {"qr": "true"}, {"chars": "1"}} {
+			err := act.Show(gptest.CliCtxWithFlags(ctxutil.WithTerminal(ctx, true), t, flags, "foo"))
+			require.Error(t, err, flags)
+			assert.Contains(t, err.Error(), "GOPASS_TEST_RECORDING is set", flags)
+		}
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("copy on a terminal", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctxutil.WithTerminal(ctx, true), t, map[string]string{"clip": "true"}, "foo")))
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("print with force", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtxWithFlags(ctxutil.WithTerminal(ctx, true), t, map[string]string{"unsafe": "true"}, "foo")))
+		assert.Contains(t, buf.String(), "secret")
+	})
+
+	t.Run("print to a pipe", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, false), t, "foo")))
+		assert.Contains(t, buf.String(), "secret")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.RecordingCheck = false
+		defer func() {
+			act.cfg.RecordingCheck = true
+		}()
+		assert.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "foo")))
+		assert.Contains(t, buf.String(), "secret")
+	})
+}
+
+func TestShowPasswordOnly(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithShowSafeContent(ctx, true)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	for name, content := range map[string]string{
+		"kv":    "secret\nuser: alice\nbody",
+		"crlf":  "secret\r\nuser: alice\r\n",
+		"blank": "\nuser: alice",
+		"empty": "",
+	} {
+		require.NoError(t, act.Store.Set(ctx, name, secrets.ParsePlain([]byte(content))))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		terminal bool
+		flags    map[string]string
+		args     []string
+		out      string
+		err      bool
+	}{
+		{name: "pipe", args: []string{"kv"}, out: "secret"},
+		{name: "terminal", terminal: true, args: []string{"kv"}, out: "secret\n"},
+		{name: "terminal without newline", terminal: true, flags: map[string]string{"no-newline": "true"}, args: []string{"kv"}, out: "secret"},
+		{name: "key", terminal: true, flags: map[string]string{"key": "user"}, args: []string{"kv"}, out: "alice\n"},
+		{name: "crlf", args: []string{"crlf"}, out: "secret"},
+		{name: "blank first line", args: []string{"blank"}, err: true},
+		{name: "empty", args: []string{"empty"}, err: true},
+		{name: "missing key", flags: map[string]string{"key": "pin"}, args: []string{"kv"}, err: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			defer buf.Reset()
+
+			flags := map[string]string{"password": "true"}
+			for k, v := range tc.flags {
+				flags[k] = v
+			}
+			c := gptest.CliCtxWithFlags(ctxutil.WithTerminal(ctx, tc.terminal), t, flags, tc.args...)
+			err := act.Show(c)
+			if tc.err {
+				require.Error(t, err)
+				var exitErr cli.ExitCoder
+				require.ErrorAs(t, err, &exitErr)
+				assert.Equal(t, ExitUnknown, exitErr.ExitCode())
+				assert.Equal(t, "", buf.String())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, buf.String())
+		})
+	}
+}
+
 func TestShowHandleRevision(t *testing.T) {
 	u := gptest.NewUnitTester(t)
 	defer u.Remove()
@@ -353,6 +611,145 @@ func TestShowHandleRevision(t *testing.T) {
 	})
 }
 
+func TestShowMeta(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, act.Store.Set(ctxutil.WithCommitTimestamp(ctx, time.Now().AddDate(-2, 0, 0)), "web", sec))
+	sec.SetPassword("bar")
+	require.NoError(t, act.Store.Set(ctxutil.WithCommitTimestamp(ctx, time.Now().AddDate(0, -3, 0)), "web", sec))
+
+	t.Run("with meta", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"with-meta": "true"}, "web")
+		require.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "bar\nCreated:  ")
+		assert.Contains(t, buf.String(), " (2 years ago)\nModified: ")
+		assert.Contains(t, buf.String(), " (3 months ago by foo bar)\nRevision: ")
+	})
+
+	t.Run("with meta and password only", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"with-meta": "true", "password": "true"}, "web")
+		require.NoError(t, act.Show(c))
+		assert.Equal(t, "bar", buf.String())
+	})
+
+	act.cfg.Metadata = true
+	defer func() {
+		act.cfg.Metadata = false
+	}()
+
+	t.Run("metadata footer", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "web")
+		require.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "\nlast changed 3 months ago by foo bar\n")
+		assert.NotContains(t, buf.String(), "Created")
+	})
+
+	t.Run("no metadata footer without terminal", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtx(ctx, t, "web")
+		require.NoError(t, act.Show(c))
+		assert.NotContains(t, buf.String(), "last changed")
+	})
+}
+
+func TestShowExpiry(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = errBuf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	for name, exp := range map[string]string{
+		"expired": "2020-01-01",
+		"later":   "2099-01-01",
+		"invalid": "soon",
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword("secret")
+		require.NoError(t, sec.Set("expires", exp))
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "expired")))
+		assert.Contains(t, errBuf.String(), "EXPIRED on 2020-01-01")
+		assert.NotContains(t, buf.String(), "EXPIRED")
+	})
+
+	t.Run("expires later", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "later")))
+		assert.Contains(t, errBuf.String(), "expires in ")
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctxutil.WithTerminal(ctx, true), t, "invalid")))
+		assert.Contains(t, errBuf.String(), `invalid expiry date "soon"`)
+	})
+
+	t.Run("no banner without terminal", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "expired")))
+		assert.Equal(t, "", errBuf.String())
+	})
+}
+
 func TestShowHandleError(t *testing.T) {
 	u := gptest.NewUnitTester(t)
 	defer u.Remove()
@@ -401,6 +798,250 @@ func TestShowPrintQR(t *testing.T) {
 		out.Stdout = os.Stdout
 	}()
 
-	assert.NoError(t, act.showPrintQR("foo", "bar"))
-	buf.Reset()
+	t.Run("QR code only", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.showPrintQR(ctx, "foo", "bar"))
+		assert.NotContains(t, buf.String(), "bar")
+	})
+
+	t.Run("QR code and password", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.showPrintQR(WithPasswordOnly(ctx, true), "foo", "bar"))
+		assert.True(t, strings.HasSuffix(buf.String(), "\nbar\n"))
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.showPrintQR(ctx, "foo", strings.Repeat("a", qrcon.MaxLength+1))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "limit of 2331 bytes")
+	})
+
+	t.Run("clear after timeout", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.QRTimeout = 1
+		assert.NoError(t, act.showPrintQR(ctxutil.WithTerminal(ctx, true), "foo", "bar"))
+		lines := strings.Count(buf.String(), "\n")
+		assert.True(t, strings.HasSuffix(buf.String(), fmt.Sprintf("\033[%dA\033[J", lines)))
+	})
+
+	t.Run("show key as QR code", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.QRTimeout = 0
+		sec := secrets.NewKV()
+		sec.SetPassword("secret")
+		assert.NoError(t, sec.Set("user", "jane"))
+		assert.NoError(t, act.Store.Set(ctx, "qr", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"qr": "true", "key": "user"}, "qr")
+		assert.NoError(t, act.Show(c))
+		assert.NotContains(t, buf.String(), "jane")
+		assert.NotContains(t, buf.String(), "secret")
+	})
+}
+
+func TestShowChars(t *testing.T) {
+	ov := clipboard.Unsupported
+	defer func() {
+		clipboard.Unsupported = ov
+	}()
+	clipboard.Unsupported = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("pässwörd1234")
+	assert.NoError(t, sec.Set("pin", "9876"))
+	assert.NoError(t, act.Store.Set(ctx, "bank", sec))
+
+	t.Run("table", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"chars": "2, 6,12"}, "bank")
+		assert.NoError(t, act.Show(c))
+		assert.Equal(t, "Position   2  6  12\nCharacter  ä  ö  4\n", buf.String())
+	})
+
+	t.Run("key", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"chars": "4", "key": "pin"}, "bank")
+		assert.NoError(t, act.Show(c))
+		assert.Equal(t, "Position   4\nCharacter  6\n", buf.String())
+	})
+
+	t.Run("clip", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"chars": "1,2", "clip": "true"}, "bank")
+		assert.NoError(t, act.Show(c))
+		assert.NotContains(t, buf.String(), "Position")
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"chars": "3,13"}, "bank")
+		err := act.Show(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "position 13 exceeds the password of bank, it has 12 characters")
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("clear after timeout", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.QRTimeout = 1
+		assert.NoError(t, act.showChars(WithChars(ctxutil.WithTerminal(ctx, true), []int{1}), "bank", "secret"))
+		assert.True(t, strings.HasSuffix(buf.String(), "\033[2A\033[J"))
+	})
+}
+
+func TestParseCharPositions(t *testing.T) {
+	for in, want := range map[string][]int{
+		"3,7,12":    {3, 7, 12},
+		" 3, 7 ,12": {3, 7, 12},
+		"1":         {1},
+		"2,2,1,":    {2, 2, 1},
+	} {
+		pos, err := parseCharPositions(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, pos, in)
+	}
+
+	for _, in := range []string{"", ",", "0", "-1", "a", "1,b"} {
+		_, err := parseCharPositions(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestShowAutotype(t *testing.T) {
+	ov := clipboard.Unsupported
+	defer func() {
+		clipboard.Unsupported = ov
+	}()
+	clipboard.Unsupported = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = autotype.WithCountdown(ctx, 0)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+
+	t.Run("show --type is disabled over SSH", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"type": "true"}, "foo")
+		err := act.Show(c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), autotype.ErrSSH.Error())
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("invalid autotype sequence", func(t *testing.T) {
+		defer buf.Reset()
+		sec := secrets.NewKV()
+		sec.SetPassword("123")
+		assert.NoError(t, sec.Set("autotype", "login :tab pass"))
+		assert.NoError(t, act.Store.Set(ctx, "web/site", sec))
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"type": "true"}, "web/site")
+		assert.Error(t, act.Show(c))
+	})
+
+	t.Run("autotype setting falls back to the clipboard over SSH", func(t *testing.T) {
+		defer buf.Reset()
+		act.cfg.AutoType = true
+		defer func() {
+			act.cfg.AutoType = false
+		}()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"clip": "true"}, "foo")
+		assert.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "WARNING")
+	})
+}
+
+func TestShowDual(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = errBuf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	sec := secrets.New()
+	sec.SetPassword("123")
+	require.NoError(t, act.Store.Set(ctx, "foo/bar", sec))
+	require.NoError(t, act.Store.Set(ctx, "work", sec))
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, act.Store.AddMount(ctx, "work", u.StoreDir("work")))
+
+	t.Run("secret wins", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtxWithFlags(ctx, t, map[string]string{"password": "true"}, "foo")))
+		assert.Equal(t, "secret", buf.String())
+		assert.Contains(t, errBuf.String(), "there is a folder named foo as well")
+	})
+
+	t.Run("trailing slash lists the folder", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "foo/")))
+		assert.Contains(t, buf.String(), "bar")
+		assert.Empty(t, errBuf.String())
+	})
+
+	t.Run("mount point hides a secret", func(t *testing.T) {
+		defer buf.Reset()
+		defer errBuf.Reset()
+		require.NoError(t, act.Show(gptest.CliCtx(ctx, t, "work")))
+		assert.Contains(t, buf.String(), "foo")
+		assert.Contains(t, errBuf.String(), "the secret work of the parent store is hidden by the mount work")
+	})
 }
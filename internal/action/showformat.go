@@ -0,0 +1,93 @@
+package action
+
+import (
+	"context"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// secretOutput is the schema of show --format json|yaml. It must be kept
+// stable.
+type secretOutput struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	// Values maps each key to its values, always a list
+	Values   map[string][]string `json:"values"`
+	Body     string              `json:"body,omitempty"`
+	Metadata *secretMetadata     `json:"metadata,omitempty"`
+}
+
+// secretMetadata describes the last change of a secret. It's only available
+// with a storage backend keeping a history.
+type secretMetadata struct {
+	Created  *time.Time `json:"created,omitempty"`
+	Modified *time.Time `json:"modified,omitempty"`
+	Revision string     `json:"revision,omitempty"`
+	Author   string     `json:"author,omitempty"`
+}
+
+// showStructured prints a single secret including its password in a machine
+// readable format. Since the password can't be masked it requires --unsafe or
+// the formatpasswords option.
+func (s *Action) showStructured(ctx context.Context, name, format string) error {
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s show --format %s [name]", s.Name, format)
+	}
+	if !ctxutil.IsForce(ctx) && !s.cfg.FormatPasswords {
+		return ExitError(ExitUsage, nil, "show --format %s prints the password. Use --unsafe or enable it with '%s config show.formatpasswords true'", format, s.Name)
+	}
+
+	var sec gopass.Secret
+	var err error
+	target := name
+	if HasRevision(ctx) {
+		revision, perr := s.parseRevision(ctx, name, GetRevision(ctx))
+		if perr != nil {
+			return ExitError(ExitGit, perr, "Failed to get revisions: %s", perr)
+		}
+		_, sec, err = s.Store.GetRevision(ctx, name, revision)
+	} else {
+		target, sec, err = s.Store.Resolve(ctx, name)
+	}
+	if err != nil {
+		return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", name, err)
+	}
+
+	res := secretOutput{
+		Name:     name,
+		Password: sec.Password(),
+		Values:   make(map[string][]string, len(sec.Keys())),
+		Body:     sec.Body(),
+		Metadata: s.secretMetadata(ctx, target),
+	}
+	for _, k := range sec.Keys() {
+		if vs, found := sec.Values(k); found {
+			res.Values[k] = vs
+		}
+	}
+
+	if err := out.Encode(stdout, format, res); err != nil {
+		return ExitError(ExitUnknown, err, "failed to encode secret: %s", err)
+	}
+	return nil
+}
+
+// secretMetadata returns when the secret was created and last changed or nil
+// if the storage backend knows nothing about it
+func (s *Action) secretMetadata(ctx context.Context, name string) *secretMetadata {
+	m, err := s.Store.Meta(ctx, name)
+	if err != nil || m.IsZero() {
+		debug.Log("no metadata for %s: %v", name, err)
+		return nil
+	}
+	return &secretMetadata{
+		Created:  timeOutput(m.Created),
+		Modified: timeOutput(m.Modified),
+		Revision: m.Revision,
+		Author:   m.Author,
+	}
+}
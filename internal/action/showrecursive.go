@@ -0,0 +1,147 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+)
+
+// subtreeEntries returns the entry name, if it exists, and all entries below
+// it across all mounts
+func (s *Action) subtreeEntries(ctx context.Context, name string) ([]string, error) {
+	name = strings.TrimSuffix(s.Store.ExpandAlias(name), "/")
+
+	var entries []string
+	if s.Store.Exists(ctx, name) {
+		entries = append(entries, name)
+	}
+
+	if name == "" {
+		all, err := s.Store.List(ctx, tree.INF)
+		if err != nil {
+			return nil, err
+		}
+		return all, nil
+	}
+
+	t, err := s.Store.Tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := t.FindFolder(name)
+	if err != nil {
+		// not a folder
+		return entries, nil
+	}
+	entries = append(entries, sub.List(tree.INF)...)
+	sort.Strings(entries)
+
+	return entries, nil
+}
+
+// showRecursive displays all secrets below name, either one after the other
+// or as a single JSON or YAML object
+func (s *Action) showRecursive(ctx context.Context, name, format string) error {
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s show --recursive [folder]", s.Name)
+	}
+	entries, err := s.subtreeEntries(ctx, name)
+	if err != nil {
+		return ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+	if len(entries) < 1 {
+		return ExitError(ExitNotFound, nil, "Entry %q not found", name)
+	}
+	debug.Log("showing %d entries below %q", len(entries), name)
+
+	redact := ctxutil.IsShowSafeContent(ctx) && !ctxutil.IsForce(ctx)
+	if redact {
+		out.Warning(ctx, "safecontent=true. Use -f to display passwords")
+	}
+
+	secs := make(map[string]gopass.Secret, len(entries))
+	for _, e := range entries {
+		_, sec, err := s.Store.Resolve(ctx, e)
+		if err != nil {
+			return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to retrieve secret %q: %s", e, err)
+		}
+		secs[e] = sec
+	}
+
+	if out.IsStructured(format) {
+		return showStructuredRecursive(format, entries, secs, redact, s.cfg.GetUnsafeKeys())
+	}
+
+	for _, e := range entries {
+		out.Printf(ctx, "Secret: %s", e)
+		out.Print(ctx, out.Secret(showText(secs[e], redact, s.cfg.GetUnsafeKeys())))
+		out.Print(ctx, "")
+	}
+	return nil
+}
+
+// showText returns the content of a secret, with the password and unsafe keys
+// masked when it must be redacted
+func showText(sec gopass.Secret, redact bool, unsafeKeys []string) string {
+	if !redact {
+		return strings.TrimPrefix(string(sec.Bytes()), secrets.Ident+"\n")
+	}
+
+	var sb strings.Builder
+	if sec.Password() != "" {
+		sb.WriteString(randAsterisk() + "\n")
+	}
+	for _, k := range sec.Keys() {
+		vs, _ := sec.Values(k)
+		if isUnsafeKey(k, sec, unsafeKeys) {
+			vs = []string{randAsterisk()}
+		}
+		for _, v := range vs {
+			fmt.Fprintf(&sb, "%s: %s\n", k, v)
+		}
+	}
+	sb.WriteString(sec.Body())
+	return sb.String()
+}
+
+// showStructuredRecursive prints the secrets as one object mapping the names
+// to their password, values and body. Like with show --format the values of
+// a key are always a list.
+func showStructuredRecursive(format string, entries []string, secs map[string]gopass.Secret, redact bool, unsafeKeys []string) error {
+	res := make(map[string]map[string]interface{}, len(entries))
+	for _, e := range entries {
+		sec := secs[e]
+		values := make(map[string][]string, len(sec.Keys()))
+		for _, k := range sec.Keys() {
+			if redact && isUnsafeKey(k, sec, unsafeKeys) {
+				continue
+			}
+			if vs, found := sec.Values(k); found {
+				values[k] = vs
+			}
+		}
+		obj := map[string]interface{}{
+			"values": values,
+		}
+		if !redact {
+			obj["password"] = sec.Password()
+		}
+		if body := sec.Body(); body != "" {
+			obj["body"] = body
+		}
+		res[e] = obj
+	}
+
+	if err := out.Encode(stdout, format, res); err != nil {
+		return ExitError(ExitUnknown, err, "failed to encode secrets: %s", err)
+	}
+	return nil
+}
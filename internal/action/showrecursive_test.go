@@ -0,0 +1,153 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowRecursive(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	stdout = buf
+	defer func() {
+		stdout = os.Stdout
+		out.Stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	sec.Set("user", "admin")
+	sec.Set("url", "db.example.org")
+	require.NoError(t, act.Store.Set(ctx, "prod/db", sec))
+	sec = secrets.NewKV()
+	sec.SetPassword("hunter2")
+	sec.Add("host", "a")
+	sec.Add("host", "b")
+	_, _ = sec.Write([]byte("some notes"))
+	require.NoError(t, act.Store.Set(ctx, "prod/web/api", sec))
+	sec = secrets.NewKV()
+	sec.Set("password", "not the password")
+	require.NoError(t, act.Store.Set(ctx, "prod/vault", sec))
+
+	t.Run("show --recursive --format json", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true", "format": "json"}, "prod/")
+		require.NoError(t, act.Show(c))
+
+		var res map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res), buf.String())
+		assert.Equal(t, map[string]map[string]interface{}{
+			"prod/db": {
+				"password": "s3cret",
+				"values": map[string]interface{}{
+					"user": []interface{}{"admin"},
+					"url":  []interface{}{"db.example.org"},
+				},
+			},
+			"prod/web/api": {
+				"password": "hunter2",
+				"values": map[string]interface{}{
+					"host": []interface{}{"a", "b"},
+				},
+				"body": "some notes",
+			},
+			"prod/vault": {
+				"password": "",
+				"values": map[string]interface{}{
+					"password": []interface{}{"not the password"},
+				},
+			},
+		}, res)
+	})
+
+	t.Run("show --recursive with safecontent", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true", "format": "json", "safe": "true"}, "prod/web")
+		require.NoError(t, act.Show(c))
+
+		var res map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res), buf.String())
+		require.Contains(t, res, "prod/web/api")
+		assert.NotContains(t, res["prod/web/api"], "password")
+		assert.NotContains(t, buf.String(), "hunter2")
+	})
+
+	t.Run("show --recursive as text with safecontent", func(t *testing.T) {
+		defer buf.Reset()
+		ctx := ctxutil.WithShowSafeContent(ctx, true)
+		ctx = ctxutil.WithTerminal(ctx, true)
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "prod/web")
+		require.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "Secret: prod/web/api\n*****\n")
+		assert.NotContains(t, buf.String(), "hunter2")
+	})
+
+	t.Run("show --recursive as text", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "prod")
+		require.NoError(t, act.Show(c))
+		assert.Contains(t, buf.String(), "Secret: prod/db\ns3cret\n")
+		assert.Contains(t, buf.String(), "Secret: prod/web/api\nhunter2\n")
+	})
+
+	t.Run("show --recursive of a missing folder", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "staging")
+		assert.Error(t, act.Show(c))
+	})
+
+	t.Run("show --recursive with an invalid format", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true", "format": "xml"}, "prod")
+		assert.Error(t, act.Show(c))
+	})
+
+	t.Run("rm -r does not list entries of nested mounts", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, u.InitStore("team"))
+		require.NoError(t, act.Store.AddMount(ctx, "prod/team", u.StoreDir("team")))
+		defer func() {
+			assert.NoError(t, act.Store.RemoveMount(ctx, "prod/team"))
+		}()
+		require.NoError(t, act.Store.Set(ctx, "prod/team/key", secrets.NewKV()))
+		buf.Reset()
+
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "prod")
+		assert.Error(t, act.Delete(c))
+		assert.Contains(t, buf.String(), "The following 3 entries will be deleted:")
+		assert.NotContains(t, buf.String(), "prod/team/key")
+	})
+
+	t.Run("rm -r lists all entries", func(t *testing.T) {
+		defer buf.Reset()
+		c := gptest.CliCtxWithFlags(ctx, t, map[string]string{"recursive": "true"}, "prod")
+		require.NoError(t, act.Delete(c))
+		assert.Contains(t, buf.String(), "The following 3 entries will be deleted:\n  prod/db\n  prod/vault\n  prod/web/api\n")
+		assert.False(t, act.Store.IsDir(ctx, "prod"))
+	})
+}
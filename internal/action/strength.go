@@ -0,0 +1,23 @@
+package action
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/strength"
+)
+
+// printStrength prints a one-line assessment of the strength of the password
+// of the named secret, unless quiet is set. The assessment is only shown on
+// the terminal, it's never written to the secret.
+func printStrength(ctx context.Context, name, pw string) {
+	if IsQuiet(ctx) || pw == "" {
+		return
+	}
+	e := strength.Check(pw, name)
+	if err := e.Validate(0); err != nil {
+		out.Warningf(ctx, "Password strength: %s", e)
+		return
+	}
+	out.Printf(ctx, "Password strength: %s", e)
+}
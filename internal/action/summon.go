@@ -0,0 +1,63 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/urfave/cli/v2"
+)
+
+// Summon implements a summon provider. It prints the password, or the value
+// of the key given as path#key, without a trailing newline and nothing else
+// to stdout. It never asks any questions and exits with 1 on any error.
+func (s *Action) Summon(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	ctx = ctxutil.WithInteractive(ctx, false)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = gpg.WithNoPinentry(ctx, true)
+
+	// summon consumers read the value byte by byte, everything else goes
+	// to stderr
+	oldStdout := out.Stdout
+	out.Stdout = out.Stderr
+	defer func() {
+		out.Stdout = oldStdout
+	}()
+
+	if c.Args().Len() != 1 {
+		return ExitError(ExitUnknown, nil, "Usage: %s summon <secret>[#key]", s.Name)
+	}
+	if inited, err := s.Store.IsInitialized(ctx); err != nil || !inited {
+		return ExitError(ExitUnknown, err, "password-store is not initialized. Try '%s init'", s.Name)
+	}
+
+	// a secret named like the argument wins over path#key
+	name, key := c.Args().First(), ""
+	if i := strings.LastIndex(name, "#"); i > 0 && !s.Store.Exists(ctx, name) {
+		name, key = name[:i], name[i+1:]
+	}
+
+	debug.Log("summon: name %q, key %q", name, key)
+	sec, err := s.Store.Get(ctx, name)
+	if err != nil {
+		return ExitError(ExitUnknown, err, "failed to retrieve secret %q: %s", name, err)
+	}
+
+	value := sec.Password()
+	if key != "" {
+		v, found := sec.Get(key)
+		if !found {
+			return ExitError(ExitUnknown, nil, "key %q not found in %q", key, name)
+		}
+		value = v
+	}
+
+	if _, err := fmt.Fprint(stdout, value); err != nil {
+		return ExitError(ExitUnknown, err, "failed to write value: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestSummon(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	errBuf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = errBuf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	sec.Set("user", "admin")
+	_, _ = sec.Write([]byte("some notes\n"))
+	require.NoError(t, act.Store.Set(ctx, "deploy/db#1", sec))
+
+	t.Run("password", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Summon(gptest.CliCtx(ctx, t, "deploy/db#1")))
+		assert.Equal(t, "s3cret", buf.String())
+	})
+
+	t.Run("key", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Summon(gptest.CliCtx(ctx, t, "deploy/db#1#user")))
+		assert.Equal(t, "admin", buf.String())
+	})
+
+	for _, args := range [][]string{
+		{"deploy/db#1#pass"},
+		{"deploy/web"},
+		{},
+		{"deploy/db#1", "deploy/db#1"},
+	} {
+		t.Run("error", func(t *testing.T) {
+			defer buf.Reset()
+			err := act.Summon(gptest.CliCtx(ctx, t, args...))
+			require.Error(t, err)
+			var ec cli.ExitCoder
+			require.True(t, errors.As(err, &ec))
+			assert.Equal(t, 1, ec.ExitCode())
+			assert.Equal(t, "", buf.String())
+		})
+	}
+}
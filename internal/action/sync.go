@@ -1,9 +1,13 @@
 package action
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/gopasspw/gopass/internal/tree"
 
@@ -11,45 +15,79 @@ import (
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 
-	"github.com/fatih/color"
 	"github.com/urfave/cli/v2"
 )
 
+// maxSyncWorkers is the default number of mounts synced concurrently
+const maxSyncWorkers = 4
+
+// syncCounter is implemented by storage backends that can tell how many
+// commits were exchanged with the remote
+type syncCounter interface {
+	Head(ctx context.Context) string
+	SyncedCommits(ctx context.Context, since string) (int, int, error)
+}
+
+// syncResult is the outcome of syncing a single mount
+type syncResult struct {
+	name string
+	// skipped is the reason if the mount wasn't synced with a remote
+	skipped  string
+	noRemote bool
+	err      error
+
+	pulled         int
+	pushed         int
+	entriesAdded   int
+	entriesRemoved int
+	keysAdded      int
+	keysRemoved    int
+}
+
 // Sync all stores with their remotes
 func (s *Action) Sync(c *cli.Context) error {
-	return s.sync(ctxutil.WithGlobalFlags(c), c.String("store"))
+	ctx := ctxutil.WithExplicitSync(ctxutil.WithGlobalFlags(c), true)
+	if ctxutil.IsOffline(ctx) {
+		return ExitError(ExitGit, nil, "Not syncing while offline, run 'gopass sync' without --offline or GOPASS_OFFLINE to push the local commits")
+	}
+	return s.sync(ctx, c.StringSlice("store"))
 }
 
-func (s *Action) sync(ctx context.Context, store string) error {
+func (s *Action) sync(ctx context.Context, stores []string) error {
+	mps, err := s.syncMountPoints(stores)
+	if err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
 	out.Printf(ctx, "🚥 Syncing with all remotes ...")
 
 	numEntries := 0
 	if l, err := s.Store.Tree(ctx); err == nil {
 		numEntries = len(l.List(tree.INF))
 	}
-	numMPs := 0
 
-	mps := s.Store.MountPoints()
-	mps = append([]string{""}, mps...)
+	results := s.syncAll(ctx, mps)
 
-	// sync all stores (root and all mounted sub stores)
-	for _, mp := range mps {
-		if store != "" {
-			if store != "root" && mp != store {
-				continue
-			}
-			if store == "root" && mp != "" {
-				continue
-			}
-		}
+	// importing and exporting keys and merging conflicts may ask the user,
+	// so this can't be done concurrently
+	for i := range results {
+		s.syncRecipients(ctx, mps[i], &results[i])
+		s.syncKeys(ctx, mps[i], &results[i])
+		s.syncConflicts(ctx, mps[i], &results[i])
+	}
 
-		numMPs++
-		_ = s.syncMount(ctx, mp)
+	s.printSyncSummary(ctx, results)
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
 	}
-	out.OKf(ctx, "All done")
 
 	// Calculate number of changed entries.
 	// This is a rough estimate as additions and deletions
@@ -63,106 +101,275 @@ func (s *Action) sync(ctx context.Context, store string) error {
 	} else if numEntries < 0 {
 		diff = fmt.Sprintf(" Removed %d entries", -1*numEntries)
 	}
-	_ = notify.Notify(ctx, "gopass - sync", fmt.Sprintf("Finished. Synced %d remotes.%s", numMPs, diff))
+
+	pulled, pushed := 0, 0
+	for _, r := range results {
+		pulled += r.pulled
+		pushed += r.pushed
+	}
+	commits := fmt.Sprintf(" Pulled %d and pushed %d commits.", pulled, pushed)
+
+	if failed > 0 {
+		_ = notify.Notify(ctx, "gopass - sync", fmt.Sprintf("Failed to sync %d of %d remotes.%s%s", failed, len(results), commits, diff))
+		return ExitError(ExitGit, nil, "Failed to sync %d of %d stores", failed, len(results))
+	}
+	out.OKf(ctx, "All done")
+	_ = notify.Notify(ctx, "gopass - sync", fmt.Sprintf("Finished. Synced %d remotes.%s%s", len(results), commits, diff))
 
 	return nil
 }
 
-// syncMount syncs a single mount
-func (s *Action) syncMount(ctx context.Context, mp string) error {
-	ctxno := out.WithNewline(ctx, false)
-	name := mp
+// syncMountPoints returns the mount points to sync. Stores selected with
+// --store are always synced, otherwise all mounts but those with nosync set.
+func (s *Action) syncMountPoints(stores []string) ([]string, error) {
+	all := append([]string{""}, s.Store.MountPoints()...)
+
+	if len(stores) < 1 {
+		mps := make([]string, 0, len(all))
+		for _, mp := range all {
+			if mp != "" && s.cfg.IsNoSync(mp) {
+				debug.Log("not syncing %q. nosync=true", mp)
+				continue
+			}
+			mps = append(mps, mp)
+		}
+		return mps, nil
+	}
+
+	mps := make([]string, 0, len(stores))
+	seen := make(map[string]bool, len(stores))
+	for _, name := range stores {
+		if name == "root" {
+			name = ""
+		}
+		if seen[name] {
+			continue
+		}
+		found := false
+		for _, mp := range all {
+			if mp == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no such mount point %q", name)
+		}
+		seen[name] = true
+		mps = append(mps, name)
+	}
+	return mps, nil
+}
+
+// syncAll pulls and pushes the given mounts. They are independent, so
+// multiple mounts are synced concurrently.
+func (s *Action) syncAll(ctx context.Context, mps []string) []syncResult {
+	workers := maxSyncWorkers
+	if n := ctxutil.GetWorkers(ctx); n > 0 {
+		workers = n
+	}
+	if workers > len(mps) {
+		workers = len(mps)
+	}
+	debug.Log("syncing %d mounts with %d workers", len(mps), workers)
+
+	results := make([]syncResult, len(mps))
+	pending := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range pending {
+				results[idx] = s.syncMount(ctx, mps[idx])
+			}
+		}()
+	}
+	for i := range mps {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+
+	return results
+}
+
+// syncMount pulls and pushes a single mount
+func (s *Action) syncMount(ctx context.Context, mp string) syncResult {
+	r := syncResult{name: mp}
 	if mp == "" {
-		name = "<root>"
+		r.name = "<root>"
 	}
-	out.Printf(ctxno, color.GreenString("[%s] ", name))
 
 	sub, err := s.Store.GetSubStore(mp)
 	if err != nil {
-		out.Errorf(ctx, "Failed to get sub store %q: %s", name, err)
-		return fmt.Errorf("failed to get sub stores (%s)", err)
+		out.Errorf(ctx, "Failed to get sub store %q: %s", r.name, err)
+		r.err = fmt.Errorf("failed to get sub stores (%s)", err)
+		return r
 	}
 
 	if sub == nil {
-		out.Errorf(ctx, "Failed to get sub stores '%s: nil'", name)
-		return fmt.Errorf("failed to get sub stores (nil)")
+		out.Errorf(ctx, "Failed to get sub stores '%s: nil'", r.name)
+		r.err = fmt.Errorf("failed to get sub stores (nil)")
+		return r
 	}
 
 	l, err := sub.List(ctx, "")
 	if err != nil {
 		out.Errorf(ctx, "Failed to list store: %s", err)
 	}
+	rs := sub.Recipients(ctx)
 
-	// TODO: Remove this hard coded check
-	if sub.Storage().Name() == "fs" {
-		out.Printf(ctxno, "\n   WARNING: Mount uses Storage backend 'fs'. Not syncing!\n")
-	} else {
-		out.Printf(ctxno, "\n   "+color.GreenString("git pull and push ... "))
-		if err := sub.Storage().Push(ctx, "", ""); err != nil {
-			if errors.Is(err, store.ErrGitNoRemote) {
-				out.Printf(ctx, "Skipped (no remote)")
-				debug.Log("Failed to push %q to its remote: %s", name, err)
-				return err
-			}
+	sc, counts := sub.Storage().(syncCounter)
+	head := ""
+	if counts {
+		head = sc.Head(ctx)
+	}
 
-			out.Errorf(ctx, "Failed to push %q to its remote: %s", name, err)
-			return err
+	// read-only mounts are only pulled, never pushed
+	op, sync := "push", sub.Storage().Push
+	if sub.IsReadOnly() {
+		op, sync = "pull", sub.Storage().Pull
+	}
+	if err := sync(ctx, "", ""); err != nil {
+		// e.g. the fs storage backend
+		if errors.Is(err, store.ErrGitNotInit) {
+			debug.Log("Failed to %s %q: %s", op, r.name, err)
+			r.skipped = "storage backend " + sub.Storage().Name()
+			return r
+		}
+		if errors.Is(err, store.ErrGitNoRemote) {
+			debug.Log("Failed to %s %q: %s", op, r.name, err)
+			r.skipped = "no remote"
+			r.noRemote = true
+			return r
 		}
-		out.Printf(ctxno, color.GreenString("OK"))
 
-		ln, err := sub.List(ctx, "")
+		out.Errorf(ctx, "Failed to %s %q: %s", op, r.name, err)
+		r.err = err
+		return r
+	}
+
+	if counts {
+		r.pulled, r.pushed, err = sc.SyncedCommits(ctx, head)
 		if err != nil {
-			out.Errorf(ctx, "Failed to list store: %s", err)
+			debug.Log("failed to count synced commits of %q: %s", r.name, err)
 		}
+	}
 
-		added, removed := diff.List(l, ln)
-		debug.Log("diff - added: %d - removed: %d", added, removed)
-		if added > 0 {
-			out.Printf(ctxno, color.GreenString(" (Added %d entries)", added))
-		}
-		if removed > 0 {
-			out.Printf(ctxno, color.GreenString(" (Removed %d entries)", removed))
-		}
-		if added < 1 && removed < 1 {
-			out.Printf(ctxno, color.GreenString(" (no changes)"))
-		}
+	ln, err := sub.List(ctx, "")
+	if err != nil {
+		out.Errorf(ctx, "Failed to list store: %s", err)
 	}
+	r.entriesAdded, r.entriesRemoved = diff.List(l, ln)
+	r.keysAdded, r.keysRemoved = diff.List(rs, sub.Recipients(ctx))
+	debug.Log("diff %q - added: %d - removed: %d", r.name, r.entriesAdded, r.entriesRemoved)
+
+	return r
+}
 
+// syncRecipients makes sure that the user accepts any recipients changed by
+// the pull before their keys are imported
+func (s *Action) syncRecipients(ctx context.Context, mp string, r *syncResult) {
+	if r.err != nil || r.skipped != "" {
+		return
+	}
+
+	sub, err := s.Store.GetSubStore(mp)
+	if err != nil || sub == nil {
+		return
+	}
+
+	if err := sub.CheckRecipientsHash(ctx); err != nil {
+		out.Errorf(ctx, "Not accepting the recipients of %q: %s", r.name, err)
+		r.err = err
+	}
+}
+
+// syncKeys imports and exports the public keys of the recipients of a mount
+// and pushes again if any keys were exported
+func (s *Action) syncKeys(ctx context.Context, mp string, r *syncResult) {
 	debug.Log("Syncing Mount %s. Exportkeys: %t", mp, ctxutil.IsExportKeys(ctx))
-	var exported bool
-	if ctxutil.IsExportKeys(ctx) {
-		// import keys
-		out.Printf(ctxno, "\n   "+color.GreenString("importing missing keys ... "))
-		if err := sub.ImportMissingPublicKeys(ctx); err != nil {
-			out.Errorf(ctx, "Failed to import missing public keys for %q: %s", name, err)
-			return err
-		}
-		out.Printf(ctxno, color.GreenString("OK"))
+	if r.err != nil || r.noRemote || !ctxutil.IsExportKeys(ctx) {
+		return
+	}
 
-		// export keys
-		out.Printf(ctxno, "\n   "+color.GreenString("exporting missing keys ... "))
-		rs, err := sub.GetRecipients(ctx, "")
-		if err != nil {
-			out.Errorf(ctx, "Failed to load recipients for %q: %s", name, err)
-			return err
-		}
-		exported, err = sub.ExportMissingPublicKeys(ctx, rs)
+	sub, err := s.Store.GetSubStore(mp)
+	if err != nil || sub == nil {
+		return
+	}
+
+	if err := sub.ImportMissingPublicKeys(ctx); err != nil {
+		out.Errorf(ctx, "Failed to import missing public keys for %q: %s", r.name, err)
+		r.err = err
+		return
+	}
+
+	rs, err := sub.GetRecipients(ctx, "")
+	if err != nil {
+		out.Errorf(ctx, "Failed to load recipients for %q: %s", r.name, err)
+		r.err = err
+		return
+	}
+	if sub.IsReadOnly() {
+		return
+	}
+	exported, err := sub.ExportMissingPublicKeys(ctx, rs)
+	if err != nil {
+		out.Errorf(ctx, "Failed to export missing public keys for %q: %s", r.name, err)
+		r.err = err
+		return
+	}
+
+	// only run second push if we did export any keys
+	if !exported || r.skipped != "" {
+		return
+	}
+	s.pushExportedKeys(ctx, sub, r)
+}
+
+func (s *Action) pushExportedKeys(ctx context.Context, sub *leaf.Store, r *syncResult) {
+	sc, counts := sub.Storage().(syncCounter)
+	head := ""
+	if counts {
+		head = sc.Head(ctx)
+	}
+
+	if err := sub.Storage().Push(ctx, "", ""); err != nil {
+		out.Errorf(ctx, "Failed to push %q to its remote: %s", r.name, err)
+		r.err = err
+		return
+	}
+
+	if counts {
+		pulled, pushed, err := sc.SyncedCommits(ctx, head)
 		if err != nil {
-			out.Errorf(ctx, "Failed to export missing public keys for %q: %s", name, err)
-			return err
+			debug.Log("failed to count synced commits of %q: %s", r.name, err)
 		}
+		r.pulled += pulled
+		r.pushed += pushed
 	}
+}
 
-	// only run second push if we did export any keys
-	if exported {
-		if err := sub.Storage().Push(ctx, "", ""); err != nil {
-			out.Errorf(ctx, "Failed to push %q to its remote: %s", name, err)
-			return err
+// printSyncSummary prints a table with the changes to each mount
+func (s *Action) printSyncSummary(ctx context.Context, results []syncResult) {
+	buf := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STORE\tPULLED\tPUSHED\tENTRIES\tRECIPIENTS\tSTATUS")
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\tFAILED: %s\n", r.name, r.err)
+		case r.skipped != "":
+			fmt.Fprintf(tw, "%s\t-\t-\t-\t-\tskipped (%s)\n", r.name, r.skipped)
+		default:
+			fmt.Fprintf(tw, "%s\t%d\t%d\t+%d/-%d\t+%d/-%d\tOK\n", r.name, r.pulled, r.pushed, r.entriesAdded, r.entriesRemoved, r.keysAdded, r.keysRemoved)
 		}
-		out.Printf(ctxno, color.GreenString("OK"))
-	} else {
-		out.Printf(ctxno, color.GreenString("nothing to do"))
 	}
-	out.Printf(ctx, "\n   "+color.GreenString("done"))
-	return nil
+	_ = tw.Flush()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		out.Printf(ctx, "%s", line)
+	}
 }
@@ -3,6 +3,7 @@ package action
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
@@ -28,6 +29,8 @@ func TestSync(t *testing.T) {
 
 	ctx := context.Background()
 	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	// the mock keys can't be exported
+	ctx = ctxutil.WithExportKeys(ctx, false)
 	act, err := newMock(ctx, u)
 	require.NoError(t, err)
 	require.NotNil(t, act)
@@ -37,8 +40,81 @@ func TestSync(t *testing.T) {
 		assert.NoError(t, act.Sync(gptest.CliCtx(ctx, t)))
 	})
 
+	t.Run("offline", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.Sync(gptest.CliCtx(ctxutil.WithOffline(ctx, true), t))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "offline")
+	})
+
 	t.Run("sync --store=root", func(t *testing.T) {
 		defer buf.Reset()
-		assert.NoError(t, act.Sync(gptest.CliCtxWithFlags(ctx, t, map[string]string{"store": "root"})))
+		assert.NoError(t, act.sync(ctx, []string{"root"}))
+		assert.Contains(t, buf.String(), "STORE")
+		assert.Contains(t, buf.String(), "<root>")
+		assert.Contains(t, buf.String(), "skipped (storage backend fs)")
+	})
+
+	t.Run("sync unknown store", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.sync(ctx, []string{"root", "nope"}))
+	})
+
+	t.Run("failing key export", func(t *testing.T) {
+		defer buf.Reset()
+		err := act.sync(ctxutil.WithExportKeys(ctx, true), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Failed to sync 1 of 1 stores")
+		assert.Contains(t, buf.String(), "FAILED")
+	})
+}
+
+func TestSyncMountPoints(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, u.InitStore("vpn"))
+	require.NoError(t, act.Store.AddMount(ctx, "work", u.StoreDir("work")))
+	require.NoError(t, act.Store.AddMount(ctx, "vpn", u.StoreDir("vpn")))
+	act.cfg.MountNoSync = map[string]bool{"vpn": true}
+
+	mps, err := act.syncMountPoints(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "work"}, mps)
+
+	// explicitly selected stores are always synced
+	mps, err = act.syncMountPoints([]string{"vpn", "root", "vpn"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vpn", ""}, mps)
+
+	_, err = act.syncMountPoints([]string{"nope"})
+	assert.Error(t, err)
+}
+
+func TestPrintSyncSummary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	act := &Action{}
+	act.printSyncSummary(context.Background(), []syncResult{
+		{name: "<root>", pulled: 2, pushed: 1, entriesAdded: 3, keysRemoved: 1},
+		{name: "work", skipped: "no remote", noRemote: true},
+		{name: "vpn", err: fmt.Errorf("timeout")},
 	})
+	want := `STORE   PULLED  PUSHED  ENTRIES  RECIPIENTS  STATUS
+<root>  2       1       +3/-0    +0/-1       OK
+work    -       -       -        -           skipped (no remote)
+vpn     -       -       -        -           FAILED: timeout
+`
+	assert.Equal(t, want, buf.String())
 }
@@ -35,6 +35,9 @@ const (
 # - ssha256: e.g. {{ .Content | ssha256 }}
 # - ssha512: e.g. {{ .Content | ssha512 }}
 # - get "key": e.g. {{ get "path/to/some/other/secret" | md5sum }}
+# - genpw: e.g. {{ genpw 24 }} or {{ genpw 8 "digits" }}
+# - date: e.g. {{ date }} or {{ date "02.01.2006" }}
+# - prompt: e.g. {{ prompt "user" }} or {{ prompt "user" "admin" }}
 `
 )
 
@@ -46,6 +49,13 @@ func (s *Action) TemplatesPrint(c *cli.Context) error {
 		return ExitError(ExitList, err, "failed to list templates: %s", err)
 	}
 	fmt.Fprintln(stdout, t.Format(tree.INF))
+
+	if named := s.Store.ListNamedTemplates(ctx); len(named) > 0 {
+		fmt.Fprintln(stdout, "Named templates:")
+		for _, n := range named {
+			fmt.Fprintf(stdout, "- %s\n", n)
+		}
+	}
 	return nil
 }
 
@@ -54,7 +64,11 @@ func (s *Action) TemplatePrint(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	name := c.Args().First()
 
-	content, err := s.Store.GetTemplate(ctx, name)
+	getTemplate := s.Store.GetTemplate
+	if c.Bool("named") {
+		getTemplate = s.Store.GetNamedTemplate
+	}
+	content, err := getTemplate(ctx, name)
 	if err != nil {
 		return ExitError(ExitIO, err, "failed to retrieve template: %s", err)
 	}
@@ -69,10 +83,18 @@ func (s *Action) TemplateEdit(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	name := c.Args().First()
 
+	hasTemplate, getTemplate, setTemplate := s.Store.HasTemplate, s.Store.GetTemplate, s.Store.SetTemplate
+	if c.Bool("named") {
+		if name == "" {
+			return ExitError(ExitUsage, nil, "usage: %s templates edit --named [name]", s.Name)
+		}
+		hasTemplate, getTemplate, setTemplate = s.Store.HasNamedTemplate, s.Store.GetNamedTemplate, s.Store.SetNamedTemplate
+	}
+
 	var content []byte
-	if s.Store.HasTemplate(ctx, name) {
+	if hasTemplate(ctx, name) {
 		var err error
-		content, err = s.Store.GetTemplate(ctx, name)
+		content, err = getTemplate(ctx, name)
 		if err != nil {
 			return ExitError(ExitIO, err, "failed to retrieve template: %s", err)
 		}
@@ -91,7 +113,7 @@ func (s *Action) TemplateEdit(c *cli.Context) error {
 		return nil
 	}
 
-	return s.Store.SetTemplate(ctx, name, nContent)
+	return setTemplate(ctx, name, nContent)
 }
 
 // TemplateRemove will remove a single template
@@ -102,6 +124,13 @@ func (s *Action) TemplateRemove(c *cli.Context) error {
 		return ExitError(ExitUsage, nil, "usage: %s templates remove [name]", s.Name)
 	}
 
+	if c.Bool("named") {
+		if !s.Store.HasNamedTemplate(ctx, name) {
+			return ExitError(ExitNotFound, nil, "template %q not found", name)
+		}
+		return s.Store.RemoveNamedTemplate(ctx, name)
+	}
+
 	if !s.Store.HasTemplate(ctx, name) {
 		return ExitError(ExitNotFound, nil, "template %q not found", name)
 	}
@@ -119,36 +148,41 @@ func (s *Action) templatesList(ctx context.Context) []string {
 	return t.List(tree.INF)
 }
 
-// TemplatesComplete prints a list of all templates for bash completion
+// TemplatesComplete prints a list of all templates, including the named
+// ones, for bash completion
 func (s *Action) TemplatesComplete(c *cli.Context) {
 	ctx := ctxutil.WithGlobalFlags(c)
 
 	for _, v := range s.templatesList(ctx) {
 		fmt.Fprintln(stdout, v)
 	}
+	for _, v := range s.Store.ListNamedTemplates(ctx) {
+		fmt.Fprintln(stdout, v)
+	}
 }
 
-func (s *Action) renderTemplate(ctx context.Context, name string, content []byte) ([]byte, bool) {
+// renderTemplate applies the template of the folder of a new secret, if any.
+// Rendering errors must abort the creation of the secret.
+func (s *Action) renderTemplate(ctx context.Context, name string, content []byte) ([]byte, bool, error) {
 	tName, tmpl, found := s.Store.LookupTemplate(ctx, name)
 	if !found {
 		debug.Log("No template found for %s", name)
-		return content, false
+		return content, false, nil
 	}
 
 	tmplStr := strings.TrimSpace(string(tmpl))
 	if tmplStr == "" {
 		debug.Log("Skipping empty template %q, for %s", tName, name)
-		return content, false
+		return content, false, nil
 	}
 
 	// load template if it exists
 	nc, err := tpl.Execute(ctx, string(tmpl), name, content, s.Store)
 	if err != nil {
-		fmt.Fprintf(stdout, "failed to execute template %q: %s\n", tName, err)
-		return content, false
+		return nil, false, ExitError(ExitUnknown, err, "failed to execute template %q: %s", tName, err)
 	}
 
 	out.Printf(ctx, "Note: Using template %s", tName)
 
-	return nc, true
+	return nc, true, nil
 }
@@ -77,4 +77,35 @@ func TestTemplates(t *testing.T) {
 		defer buf.Reset()
 		assert.NoError(t, act.TemplateRemove(gptest.CliCtx(ctx, t, "foo")))
 	})
+
+	t.Run("named templates", func(t *testing.T) {
+		defer buf.Reset()
+		named := map[string]string{"named": "true"}
+		require.NoError(t, act.Store.SetNamedTemplate(ctx, "db", []byte("{{ genpw 24 }}")))
+
+		assert.NoError(t, act.TemplatesPrint(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), "Named templates:\n- db\n")
+		buf.Reset()
+
+		act.TemplatesComplete(gptest.CliCtx(ctx, t))
+		assert.Equal(t, "db\n", buf.String())
+		buf.Reset()
+
+		assert.NoError(t, act.TemplatePrint(gptest.CliCtxWithFlags(ctx, t, named, "db")))
+		assert.Equal(t, "{{ genpw 24 }}\n", buf.String())
+
+		// not a folder template
+		assert.Error(t, act.TemplateRemove(gptest.CliCtx(ctx, t, "db")))
+		assert.NoError(t, act.TemplateRemove(gptest.CliCtxWithFlags(ctx, t, named, "db")))
+		assert.False(t, act.Store.HasNamedTemplate(ctx, "db"))
+	})
+
+	t.Run("template errors abort", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Store.SetTemplate(ctx, "web", []byte("{{ .Content }}\nuser: {{ prompt \"user\" }}\n")))
+
+		ctx := ctxutil.WithInteractive(ctx, false)
+		assert.Error(t, act.insertSingle(ctx, "web/shop", "secret", nil, false))
+		assert.False(t, act.Store.Exists(ctx, "web/shop"))
+	})
 }
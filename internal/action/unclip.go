@@ -4,22 +4,34 @@ import (
 	"os"
 	"time"
 
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
 	"github.com/urfave/cli/v2"
 )
 
-// Unclip tries to erase the content of the clipboard
+// Unclip tries to erase the content of the clipboard. When started by
+// gopass after copying a secret it only clears the clipboard if it still
+// contains that secret. When invoked by the user it always clears it.
 func (s *Action) Unclip(c *cli.Context) error {
 	ctx := ctxutil.WithGlobalFlags(c)
 	force := c.Bool("force")
 	timeout := c.Int("timeout")
 	checksum := os.Getenv("GOPASS_UNCLIP_CHECKSUM")
+	if checksum == "" {
+		force = true
+	}
+	if helper := os.Getenv("GOPASS_CLIPBOARD"); helper != "" {
+		ctx = clipboard.WithHelper(ctx, helper)
+	}
 
 	time.Sleep(time.Second * time.Duration(timeout))
 	if err := clipboard.Clear(ctx, checksum, force); err != nil {
 		return ExitError(ExitIO, err, "Failed to clear clipboard: %s", err)
 	}
+	if checksum == "" {
+		out.OKf(ctx, "Clipboard cleared")
+	}
 	return nil
 }
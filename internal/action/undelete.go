@@ -0,0 +1,98 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Undelete restores a removed secret from the git history
+func (s *Action) Undelete(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	name := c.Args().First()
+	if name == "" {
+		return ExitError(ExitUsage, nil, "Usage: %s undelete [--revision <revision>] <NAME>", s.Name)
+	}
+
+	if st := s.Store.Storage(ctx, name); st == nil || st.Name() == "fs" {
+		return ExitError(ExitUnsupported, nil, "Can not restore %q: the store has no history. Use 'gopass git init' to enable it", name)
+	}
+
+	if s.Store.Exists(ctx, name) {
+		if !c.Bool("force") {
+			return ExitError(ExitAborted, nil, "Secret %q exists. Use --force to overwrite it with an old revision", name)
+		}
+		if c.String("revision") == "" {
+			return ExitError(ExitUsage, nil, "Secret %q exists. Use --revision to select the revision to restore", name)
+		}
+	}
+
+	revision, err := s.undeleteRevision(ctx, name, c.String("revision"))
+	if err != nil {
+		return err
+	}
+
+	if err := s.Store.Restore(ctx, name, revision); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ExitError(ExitNotFound, err, "Secret %q did not exist at revision %s", name, revision)
+		}
+		return ExitError(ExitGit, err, "Failed to restore %q: %s", name, err)
+	}
+
+	out.OKf(ctx, "Restored %s from revision %s", name, revision)
+	return nil
+}
+
+// undeleteRevision returns the requested revision or the most recent one
+// containing the secret
+func (s *Action) undeleteRevision(ctx context.Context, name, revision string) (string, error) {
+	if revision != "" {
+		rev, err := s.parseRevision(ctx, name, revision)
+		if err != nil {
+			return "", ExitError(ExitGit, err, "Failed to get revisions: %s", err)
+		}
+		return rev, nil
+	}
+
+	revs, err := s.Store.ListRevisions(ctx, name)
+	if err != nil {
+		return "", ExitError(ExitGit, err, "Failed to get revisions: %s", err)
+	}
+	for _, rev := range revs {
+		// the secret can't be read at the commit that removed it. A secret
+		// that can't be decrypted anymore is still restored as is.
+		_, _, err := s.Store.GetRevision(ctx, name, rev.Hash)
+		if errors.Is(err, store.ErrNotFound) {
+			debug.Log("%q did not exist at %s", name, rev.Hash)
+			continue
+		}
+		return rev.Hash, nil
+	}
+	return "", ExitError(ExitNotFound, nil, "Secret %q not found in the history", name)
+}
+
+// Deleted lists the secrets that were removed from the store
+func (s *Action) Deleted(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+
+	dels, err := s.Store.ListDeleted(ctx)
+	if err != nil {
+		return ExitError(ExitGit, err, "Failed to list deleted secrets: %s", err)
+	}
+
+	limit := c.Int("limit")
+	for i, del := range dels {
+		if limit > 0 && i >= limit {
+			break
+		}
+		out.Printf(ctx, "%s - %s - %s", del.Date.Format(time.RFC3339), del.Name, del.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndelete(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	r1 := gptest.UnsetVars(termio.NameVars...)
+	r2 := gptest.UnsetVars(termio.EmailVars...)
+	defer r1()
+	defer r2()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = backend.WithStorageBackend(ctx, backend.GitFS)
+
+	cfg := config.New()
+	cfg.Path = u.StoreDir("")
+	act, err := newAction(cfg, semver.Version{}, false)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	require.NoError(t, act.IsInitialized(gptest.CliCtx(ctx, t)))
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	require.NoError(t, act.rcsInit(ctx, "", "foo bar", "foo.bar@example.org"))
+	buf.Reset()
+
+	set := func(name, pw string) {
+		sec := secrets.New()
+		sec.SetPassword(pw)
+		require.NoError(t, act.Store.Set(ctx, name, sec))
+	}
+	pw := func(name string) string {
+		sec, err := act.Store.Get(ctx, name)
+		require.NoError(t, err)
+		return sec.Password()
+	}
+
+	set("baz", "first")
+	set("baz", "second")
+	require.NoError(t, act.Store.Delete(ctx, "baz"))
+
+	t.Run("list deleted", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Deleted(gptest.CliCtx(ctx, t)))
+		assert.Contains(t, buf.String(), " - baz - ")
+	})
+
+	t.Run("undelete the last revision", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Undelete(gptest.CliCtx(ctx, t, "baz")))
+		assert.Equal(t, "second", pw("baz"))
+	})
+
+	t.Run("restored secrets are not listed", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Deleted(gptest.CliCtx(ctx, t)))
+		assert.NotContains(t, buf.String(), "baz")
+	})
+
+	t.Run("existing secret requires force", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Undelete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"revision": "-1"}, "baz")))
+		assert.Error(t, act.Undelete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true"}, "baz")))
+	})
+
+	t.Run("undelete an older revision", func(t *testing.T) {
+		defer buf.Reset()
+		assert.NoError(t, act.Undelete(gptest.CliCtxWithFlags(ctx, t, map[string]string{"force": "true", "revision": "-1"}, "baz")))
+		assert.Equal(t, "first", pw("baz"))
+	})
+
+	t.Run("undelete unknown secret", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.Undelete(gptest.CliCtx(ctx, t, "nope")))
+	})
+}
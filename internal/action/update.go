@@ -1,8 +1,7 @@
 package action
 
 import (
-	"fmt"
-	"runtime"
+	"context"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/updater"
@@ -22,8 +21,12 @@ func (s *Action) Update(c *cli.Context) error {
 		return nil
 	}
 
-	if runtime.GOOS == "windows" {
-		return fmt.Errorf("gopass update is not supported on windows (#1722)")
+	if ctxutil.IsNoNetwork(ctx) {
+		return ExitError(ExitUsage, nil, "Can't check for updates offline")
+	}
+
+	if c.Bool("check-only") {
+		return s.updateCheck(ctx)
 	}
 
 	out.Printf(ctx, "⚒ Checking for available updates ...")
@@ -34,3 +37,24 @@ func (s *Action) Update(c *cli.Context) error {
 	out.OKf(ctx, "gopass is up to date")
 	return nil
 }
+
+// updateCheck only tells if an update is available
+func (s *Action) updateCheck(ctx context.Context) error {
+	rel, available, err := updater.Check(ctx, s.version)
+	if err != nil {
+		return ExitError(ExitUnknown, err, "Failed to check for updates: %s", err)
+	}
+
+	if !available {
+		out.OKf(ctx, "gopass is up to date (%s)", s.version)
+		return nil
+	}
+
+	out.Noticef(ctx, "gopass %s is available (current: %s)", rel.Version, s.version)
+	if err := updater.IsUpdateable(ctx); err != nil {
+		out.Printf(ctx, "Update it with your package manager or download it from https://www.gopass.pw/#install (%s)", err)
+		return nil
+	}
+	out.Printf(ctx, "Run 'gopass update' to install it")
+	return nil
+}
@@ -100,13 +100,21 @@ func TestUpdate(t *testing.T) {
 
 	buf := &bytes.Buffer{}
 	out.Stdout = buf
+	out.Stderr = buf
 	stdout = buf
 	defer func() {
 		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
 		stdout = os.Stdout
 	}()
 
 	// TODO: This should not fail, but then we need to provide valid signatures
 	assert.Error(t, act.Update(gptest.CliCtx(ctx, t)))
 	buf.Reset()
+
+	t.Run("check only", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.Update(gptest.CliCtxWithFlags(ctxutil.WithHidden(ctx, false), t, map[string]string{"check-only": "true"})))
+		assert.Contains(t, buf.String(), "gopass 1.6.6 is available")
+	})
 }
@@ -0,0 +1,261 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/gopasspw/gopass/internal/importer"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/internal/vault"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/urfave/cli/v2"
+)
+
+// vaultStateTTL is how long an interrupted import or export can be resumed
+const vaultStateTTL = 30 * 24 * time.Hour
+
+// ImportVault imports the latest version of all secrets below a path of a
+// Vault KV v2 mount
+func (s *Action) ImportVault(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	src := c.String("path")
+	if src == "" || c.Args().Len() > 0 {
+		return ExitError(ExitUsage, nil, "Usage: %s import vault --path <mount/folder> [--prefix <folder>]", s.Name)
+	}
+
+	conflict, err := importConflict(c.String("conflict"))
+	if err != nil {
+		return err
+	}
+	prefix := strings.Trim(c.String("prefix"), "/")
+	if err := s.Store.CheckWritable(prefix); err != nil {
+		return ExitError(ExitMount, err, "%s", err)
+	}
+
+	client, addr, err := vaultClient(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	_, dir := vault.SplitPath(src)
+
+	state := newVaultState("import", addr, src)
+	after := ""
+	if c.Bool("resume") {
+		after = state.get()
+		if after != "" {
+			out.Printf(ctx, "Resuming the import after %s", after)
+		}
+	}
+
+	dryRun := c.Bool("dry-run")
+	var imported, skipped int
+	err = client.Walk(ctx, dir, after, func(p string) error {
+		data, err := client.Read(ctx, p)
+		if errors.Is(err, vault.ErrNotFound) {
+			// the latest version was deleted
+			debug.Log("skipping deleted secret %s", p)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+
+		e := importer.Vault(strings.TrimPrefix(p, dir+"/"), data)
+		item := s.importPlan(ctx, []importer.Entry{e}, prefix, conflict)[0]
+		if dryRun {
+			if item.exists && conflict == importSkip {
+				out.Printf(ctx, "Would skip %s, it already exists", item.name)
+				return nil
+			}
+			out.Printf(ctx, "Would import %s as %s", p, item.name)
+			imported++
+			return nil
+		}
+
+		if item.exists && conflict == importSkip {
+			out.Warningf(ctx, "Skipping %s, it already exists", item.name)
+			skipped++
+			return state.set(p)
+		}
+
+		debug.Log("importing %s as %s", p, item.name)
+		ctx := ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Imported %s from vault", item.name))
+		if err := s.Store.Set(ctx, item.name, importSecret(item.name, item.entry)); err != nil {
+			return fmt.Errorf("failed to save %s: %w", item.name, err)
+		}
+		imported++
+		return state.set(p)
+	})
+	if err != nil {
+		return ExitError(ExitIO, err, "failed to import from %s after %d secrets: %s. Use --resume to continue", src, imported, err)
+	}
+
+	if dryRun {
+		out.Printf(ctx, "Would import %d secrets", imported)
+		return nil
+	}
+	state.clear()
+	out.OKf(ctx, "Imported %d secrets from %s, skipped %d", imported, src, skipped)
+	return nil
+}
+
+// ExportVault writes the secrets below a folder to a path of a Vault KV v2
+// mount, every secret as a new version
+func (s *Action) ExportVault(c *cli.Context) error {
+	ctx := ctxutil.WithGlobalFlags(c)
+	dst := c.String("path")
+	folder := strings.Trim(c.Args().First(), "/")
+	if dst == "" || c.Args().Len() > 1 {
+		return ExitError(ExitUsage, nil, "Usage: %s export vault --path <mount/folder> [folder]", s.Name)
+	}
+
+	names, err := s.vaultExportNames(ctx, folder)
+	if err != nil {
+		return err
+	}
+
+	client, addr, err := vaultClient(c)
+	if err != nil {
+		return ExitError(ExitUsage, err, "%s", err)
+	}
+	_, dir := vault.SplitPath(dst)
+
+	state := newVaultState("export", addr, dst+"-"+folder)
+	after := ""
+	if c.Bool("resume") {
+		after = state.get()
+		if after != "" {
+			out.Printf(ctx, "Resuming the export after %s", after)
+		}
+	}
+
+	dryRun := c.Bool("dry-run")
+	var exported int
+	for _, name := range names {
+		if after != "" && name <= after {
+			continue
+		}
+		p := strings.TrimPrefix(strings.TrimPrefix(name, folder), "/")
+		if dir != "" {
+			p = path.Join(dir, p)
+		}
+		if dryRun {
+			out.Printf(ctx, "Would export %s to %s", name, p)
+			exported++
+			continue
+		}
+
+		sec, err := s.Store.Get(ctx, name)
+		if err != nil {
+			return ExitError(exitCodeFor(err, ExitDecrypt), err, "failed to decrypt %s after %d secrets: %s. Use --resume to continue", name, exported, err)
+		}
+		values := make(map[string][]string, len(sec.Keys()))
+		for _, k := range sec.Keys() {
+			if vs, found := sec.Values(k); found {
+				values[k] = vs
+			}
+		}
+
+		debug.Log("exporting %s to %s", name, p)
+		if err := client.Write(ctx, p, vault.Data(sec.Password(), strings.TrimSpace(sec.Body()), values)); err != nil {
+			return ExitError(ExitIO, err, "failed to write %s after %d secrets: %s. Use --resume to continue", p, exported, err)
+		}
+		exported++
+		if err := state.set(name); err != nil {
+			return ExitError(ExitIO, err, "failed to remember the progress: %s", err)
+		}
+	}
+
+	if dryRun {
+		out.Printf(ctx, "Would export %d secrets", exported)
+		return nil
+	}
+	state.clear()
+	out.OKf(ctx, "Exported %d secrets to %s", exported, dst)
+	return nil
+}
+
+// vaultExportNames returns the sorted names of all secrets below the folder,
+// all secrets if it's empty
+func (s *Action) vaultExportNames(ctx context.Context, folder string) ([]string, error) {
+	list, err := s.Store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, ExitError(ExitList, err, "failed to list store: %s", err)
+	}
+	names := make([]string, 0, len(list))
+	for _, name := range list {
+		if folder == "" || strings.HasPrefix(name, folder+"/") {
+			names = append(names, name)
+		}
+	}
+	if len(names) < 1 {
+		return nil, ExitError(ExitNotFound, nil, "no secrets to export")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// vaultClient returns a client for the mount of --path. The token is only
+// read from VAULT_TOKEN, so it never shows up in the process list.
+func vaultClient(c *cli.Context) (*vault.Client, string, error) {
+	addr := c.String("addr")
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	mount, _ := vault.SplitPath(c.String("path"))
+	client, err := vault.New(addr, os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_NAMESPACE"), mount, c.Int("rate"))
+	return client, addr, err
+}
+
+// vaultState remembers the last secret imported from or exported to Vault,
+// so an interrupted run can be resumed
+type vaultState struct {
+	cache *cache.OnDisk
+	key   string
+}
+
+func newVaultState(direction, addr, p string) *vaultState {
+	od, err := cache.NewOnDisk("vault", vaultStateTTL)
+	if err != nil {
+		debug.Log("failed to init vault state: %s", err)
+		return &vaultState{}
+	}
+	return &vaultState{
+		cache: od,
+		key:   direction + "-" + addr + "-" + p,
+	}
+}
+
+func (v *vaultState) get() string {
+	if v.cache == nil {
+		return ""
+	}
+	res, err := v.cache.Get(v.key)
+	if err != nil || len(res) < 1 {
+		return ""
+	}
+	return res[0]
+}
+
+func (v *vaultState) set(p string) error {
+	if v.cache == nil {
+		return nil
+	}
+	return v.cache.Set(v.key, []string{p})
+}
+
+func (v *vaultState) clear() {
+	if err := v.set(""); err != nil {
+		debug.Log("failed to clear vault state: %s", err)
+	}
+}
@@ -0,0 +1,166 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault is a KV v2 mount named secret without pagination. Reading a
+// secret named fail is denied.
+type fakeVault struct {
+	sync.Mutex
+	data map[string]map[string]interface{}
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	p := strings.Trim(strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata"), "/v1/secret/data"), "/")
+	switch r.Method {
+	case "LIST":
+		seen := map[string]bool{}
+		for k := range f.data {
+			if p != "" && !strings.HasPrefix(k, p+"/") {
+				continue
+			}
+			k = strings.TrimPrefix(k, p+"/")
+			if i := strings.Index(k, "/"); i >= 0 {
+				k = k[:i+1]
+			}
+			seen[k] = true
+		}
+		keys := make([]string, 0, len(seen))
+		for k := range seen {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+	case http.MethodGet:
+		if strings.HasSuffix(p, "/fail") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": f.data[p]}})
+	case http.MethodPost:
+		var req struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.data[p] = req.Data
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestVault(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+	act, err := newMock(ctx, u)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	stdout = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+		stdout = os.Stdout
+	}()
+
+	fv := &fakeVault{data: map[string]map[string]interface{}{
+		"team/db":   {"password": "s3cret", "db": map[string]interface{}{"host": "db1"}},
+		"team/api":  {"token": "abc"},
+		"team/fail": {},
+	}}
+	srv := httptest.NewServer(fv)
+	defer srv.Close()
+	t.Setenv("VAULT_TOKEN", "t0ken")
+
+	flags := func(path string, extra map[string]string) map[string]string {
+		f := map[string]string{"addr": srv.URL, "path": path, "rate": "0"}
+		for k, v := range extra {
+			f[k] = v
+		}
+		return f
+	}
+
+	t.Run("import without path", func(t *testing.T) {
+		defer buf.Reset()
+		assert.Error(t, act.ImportVault(gptest.CliCtx(ctx, t)))
+	})
+
+	t.Run("import dry-run", func(t *testing.T) {
+		defer buf.Reset()
+		delete(fv.data, "team/fail")
+		require.NoError(t, act.ImportVault(gptest.CliCtxWithFlags(ctx, t, flags("secret/team", map[string]string{"prefix": "vault", "dry-run": "true"}))))
+		assert.Contains(t, buf.String(), "Would import team/db as vault/db")
+		assert.False(t, act.Store.Exists(ctx, "vault/db"))
+	})
+
+	t.Run("import", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, act.ImportVault(gptest.CliCtxWithFlags(ctx, t, flags("secret/team", map[string]string{"prefix": "vault"}))))
+		assert.Contains(t, buf.String(), "Imported 2 secrets from secret/team, skipped 0")
+
+		sec, err := act.Store.Get(ctx, "vault/db")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", sec.Password())
+		host, _ := sec.Get("db.host")
+		assert.Equal(t, "db1", host)
+	})
+
+	t.Run("import resume", func(t *testing.T) {
+		defer buf.Reset()
+		fv.data["team/fail"] = map[string]interface{}{}
+		fv.data["team/web"] = map[string]interface{}{"password": "w3b"}
+		assert.Error(t, act.ImportVault(gptest.CliCtxWithFlags(ctx, t, flags("secret/team", map[string]string{"prefix": "resumed"}))))
+		assert.True(t, act.Store.Exists(ctx, "resumed/db"))
+		assert.False(t, act.Store.Exists(ctx, "resumed/web"))
+
+		delete(fv.data, "team/fail")
+		require.NoError(t, act.Store.Delete(ctx, "resumed/db"))
+		require.NoError(t, act.ImportVault(gptest.CliCtxWithFlags(ctx, t, flags("secret/team", map[string]string{"prefix": "resumed", "resume": "true"}))))
+		assert.True(t, act.Store.Exists(ctx, "resumed/web"))
+		// secrets before the failure are not imported again
+		assert.False(t, act.Store.Exists(ctx, "resumed/db"))
+	})
+
+	t.Run("export", func(t *testing.T) {
+		defer buf.Reset()
+		sec := secrets.NewKV()
+		sec.SetPassword("l0cal")
+		require.NoError(t, sec.Set("db.host", "db2"))
+		require.NoError(t, sec.Set("user", "admin"))
+		require.NoError(t, act.Store.Set(ctx, "local/db", sec))
+
+		require.NoError(t, act.ExportVault(gptest.CliCtxWithFlags(ctx, t, flags("secret/exported", nil), "local")))
+		assert.Contains(t, buf.String(), "Exported 1 secrets to secret/exported")
+		assert.Equal(t, map[string]interface{}{
+			"password": "l0cal",
+			"user":     "admin",
+			"db":       map[string]interface{}{"host": "db2"},
+		}, fv.data["exported/db"])
+	})
+}
@@ -29,7 +29,7 @@ func (s *Action) Version(c *cli.Context) error {
 
 	cli.VersionPrinter(c)
 
-	cryptoVer := versionInfo(ctx, s.Store.Crypto(ctx, ""))
+	cryptoVer := cryptoVersionInfo(ctx, s.Store.Crypto(ctx, ""))
 	storageVer := versionInfo(ctx, s.Store.Storage(ctx, ""))
 
 	tpl := "%-10s - %10s - %10s\n"
@@ -37,7 +37,7 @@ func (s *Action) Version(c *cli.Context) error {
 
 	// report all used crypto, sync and fs backends
 	for _, mp := range s.Store.MountPoints() {
-		cv := versionInfo(ctx, s.Store.Crypto(ctx, mp))
+		cv := cryptoVersionInfo(ctx, s.Store.Crypto(ctx, mp))
 		sv := versionInfo(ctx, s.Store.Storage(ctx, mp))
 
 		if cv != cryptoVer || sv != storageVer {
@@ -74,6 +74,18 @@ func versionInfo(ctx context.Context, v versioner) string {
 	return fmt.Sprintf("%s %s", v.Name(), v.Version(ctx))
 }
 
+func cryptoVersionInfo(ctx context.Context, c backend.Crypto) string {
+	if c == nil {
+		return "<none>"
+	}
+	v, err := c.Version(ctx)
+	if err != nil {
+		debug.Log("failed to detect %s version: %s", c.Name(), err)
+		return fmt.Sprintf("%s <unknown>", c.Name())
+	}
+	return fmt.Sprintf("%s %s", c.Name(), v)
+}
+
 func (s *Action) checkVersion(ctx context.Context, u chan string) {
 	if disabled := os.Getenv("CHECKPOINT_DISABLE"); disabled != "" {
 		u <- ""
@@ -81,6 +93,12 @@ func (s *Action) checkVersion(ctx context.Context, u chan string) {
 		return
 	}
 
+	if ctxutil.IsNoNetwork(ctx) {
+		u <- ""
+		debug.Log("remote version check disabled (offline)")
+		return
+	}
+
 	// force checking for updates, mainly for testing
 	force := os.Getenv("GOPASS_FORCE_CHECK") != ""
 
@@ -0,0 +1,232 @@
+// Package archive implements portable backups of a store. An archive is a
+// tar stream of the plaintext secrets and a manifest, encrypted as a whole
+// with age. The plaintext is only ever streamed through the encryption and
+// never written to disk.
+package archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+)
+
+const (
+	// Version is the version of the archive format
+	Version = 1
+
+	manifestName = "manifest.json"
+	secretsDir   = "secrets/"
+)
+
+// Entry is a single secret of an archive
+type Entry struct {
+	Name    string
+	Content []byte
+}
+
+// Manifest lists all secrets of an archive along with their checksums
+type Manifest struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is the manifest record of a single secret
+type ManifestEntry struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Writer writes an encrypted archive
+type Writer struct {
+	enc      io.WriteCloser
+	tw       *tar.Writer
+	manifest Manifest
+}
+
+// NewWriter returns a writer that encrypts the archive for the given
+// recipients and writes it to w. Close must be called to write the manifest
+// and to flush the encryption.
+func NewWriter(w io.Writer, recipients ...age.Recipient) (*Writer, error) {
+	enc, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+	return &Writer{
+		enc: enc,
+		tw:  tar.NewWriter(enc),
+		manifest: Manifest{
+			Version: Version,
+			Created: time.Now().UTC().Truncate(time.Second),
+		},
+	}, nil
+}
+
+// Add adds a secret to the archive
+func (w *Writer) Add(name string, content []byte) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	if err := w.write(secretsDir+name, content); err != nil {
+		return err
+	}
+	w.manifest.Entries = append(w.manifest.Entries, ManifestEntry{
+		Name:   name,
+		Size:   len(content),
+		SHA256: checksum(content),
+	})
+	return nil
+}
+
+// AddFrom adds a secret of the given size read from r, e.g. a large
+// attachment, without holding it in memory. It fails if r doesn't return
+// exactly size bytes, the archive is unusable then.
+func (w *Writer) AddFrom(name string, size int64, r io.Reader) error {
+	if err := validName(name); err != nil {
+		return err
+	}
+	if err := w.tw.WriteHeader(w.header(secretsDir+name, size)); err != nil {
+		return fmt.Errorf("failed to write header of %s: %w", name, err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(w.tw, io.TeeReader(r, h))
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if n != size {
+		return fmt.Errorf("failed to write %s: expected %d bytes, got %d", name, size, n)
+	}
+	w.manifest.Entries = append(w.manifest.Entries, ManifestEntry{
+		Name:   name,
+		Size:   int(size),
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	})
+	return nil
+}
+
+// Close writes the manifest and finishes the archive. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	buf, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := w.write(manifestName, buf); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return w.enc.Close()
+}
+
+func (w *Writer) write(name string, content []byte) error {
+	if err := w.tw.WriteHeader(w.header(name, int64(len(content)))); err != nil {
+		return fmt.Errorf("failed to write header of %s: %w", name, err)
+	}
+	if _, err := w.tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *Writer) header(name string, size int64) *tar.Header {
+	return &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0600,
+		Size:     size,
+		ModTime:  w.manifest.Created,
+	}
+}
+
+// Read decrypts the archive and returns its secrets, in the order of the
+// manifest. It fails if any secret is missing or does not match its
+// checksum.
+func Read(r io.Reader, identities ...age.Identity) ([]Entry, *Manifest, error) {
+	dec, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	var manifest *Manifest
+	contents := map[string][]byte{}
+	tr := tar.NewReader(dec)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		buf, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		switch {
+		case hdr.Name == manifestName:
+			manifest = &Manifest{}
+			if err := json.Unmarshal(buf, manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+		case strings.HasPrefix(hdr.Name, secretsDir):
+			name := strings.TrimPrefix(hdr.Name, secretsDir)
+			if err := validName(name); err != nil {
+				return nil, nil, err
+			}
+			contents[name] = buf
+		default:
+			return nil, nil, fmt.Errorf("unexpected file %s in archive", hdr.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("archive has no manifest")
+	}
+	if manifest.Version != Version {
+		return nil, nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+
+	entries := make([]Entry, 0, len(manifest.Entries))
+	for _, me := range manifest.Entries {
+		content, found := contents[me.Name]
+		if !found {
+			return nil, nil, fmt.Errorf("%s is missing in the archive", me.Name)
+		}
+		if len(content) != me.Size || checksum(content) != me.SHA256 {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s", me.Name)
+		}
+		delete(contents, me.Name)
+		entries = append(entries, Entry{Name: me.Name, Content: content})
+	}
+	for name := range contents {
+		return nil, nil, fmt.Errorf("%s is not listed in the manifest", name)
+	}
+
+	return entries, manifest, nil
+}
+
+// validName rejects names that could escape the store on import
+func validName(name string) error {
+	if name == "" || path.Clean(name) != name || strings.HasPrefix(name, "/") || name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid secret name %q", name)
+	}
+	return nil
+}
+
+func checksum(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, id.Recipient())
+	require.NoError(t, err)
+	require.NoError(t, w.Add("foo", []byte("secret\nuser: alice")))
+	require.NoError(t, w.Add("bar/baz", []byte("other")))
+	require.NoError(t, w.Add("empty", nil))
+	require.NoError(t, w.AddFrom("large", 5, strings.NewReader("large")))
+	assert.Error(t, w.Add("../escape", []byte("nope")))
+	assert.Error(t, w.Add("/abs", []byte("nope")))
+	require.NoError(t, w.Close())
+
+	assert.NotContains(t, buf.String(), "alice")
+
+	entries, manifest, err := Read(bytes.NewReader(buf.Bytes()), id)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Name: "foo", Content: []byte("secret\nuser: alice")},
+		{Name: "bar/baz", Content: []byte("other")},
+		{Name: "empty", Content: []byte{}},
+		{Name: "large", Content: []byte("large")},
+	}, entries)
+	assert.Equal(t, Version, manifest.Version)
+	assert.Equal(t, 4, len(manifest.Entries))
+	assert.Equal(t, 18, manifest.Entries[0].Size)
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	_, _, err = Read(bytes.NewReader(buf.Bytes()), other)
+	assert.Error(t, err)
+
+	t.Run("size mismatch", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, id.Recipient())
+		require.NoError(t, err)
+		assert.Error(t, w.AddFrom("short", 10, strings.NewReader("short")))
+	})
+}
+
+func TestPassphrase(t *testing.T) {
+	r, err := age.NewScryptRecipient("passphrase")
+	require.NoError(t, err)
+	r.SetWorkFactor(10)
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, r)
+	require.NoError(t, err)
+	require.NoError(t, w.Add("foo", []byte("secret")))
+	require.NoError(t, w.Close())
+
+	id, err := age.NewScryptIdentity("passphrase")
+	require.NoError(t, err)
+	entries, _, err := Read(buf, id)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Name: "foo", Content: []byte("secret")}}, entries)
+}
+
+// tampered builds an archive with the given files, bypassing the Writer
+func tampered(t *testing.T, id *age.X25519Identity, files map[string]string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	enc, err := age.Encrypt(buf, id.Recipient())
+	require.NoError(t, err)
+	tw := tar.NewWriter(enc)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, enc.Close())
+	return buf.Bytes()
+}
+
+func TestReadInvalid(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	// sha256 of "secret"
+	sum := "2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b"
+	manifest := `{"version": 1, "entries": [{"name": "foo", "size": 6, "sha256": "` + sum + `"}]}`
+
+	_, _, err = Read(bytes.NewReader(tampered(t, id, map[string]string{
+		"manifest.json": manifest,
+		"secrets/foo":   "secret",
+	})), id)
+	require.NoError(t, err)
+
+	for name, files := range map[string]map[string]string{
+		"no manifest": {"secrets/foo": "secret"},
+		"missing":     {"manifest.json": manifest},
+		"mismatch":    {"manifest.json": manifest, "secrets/foo": "secreT"},
+		"unlisted":    {"manifest.json": manifest, "secrets/foo": "secret", "secrets/bar": "bar"},
+		"unexpected":  {"manifest.json": manifest, "secrets/foo": "secret", "other": "bar"},
+		"escape":      {"manifest.json": manifest, "secrets/foo": "secret", "secrets/../bar": "bar"},
+		"version":     {"manifest.json": `{"version": 2}`},
+	} {
+		_, _, err := Read(bytes.NewReader(tampered(t, id, files)), id)
+		assert.Error(t, err, name)
+	}
+}
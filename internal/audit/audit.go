@@ -5,23 +5,115 @@ package audit
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/expiry"
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/policy"
+	"github.com/gopasspw/gopass/internal/store/decrypt"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+	"github.com/gopasspw/gopass/pkg/strength"
 	"github.com/gopasspw/gopass/pkg/termio"
-	"github.com/nbutton23/zxcvbn-go"
 
 	"github.com/fatih/color"
 	"github.com/muesli/crunchy"
 )
 
+// Severity of a finding
+type Severity int
+
+const (
+	// SeverityLow is used for old passwords
+	SeverityLow Severity = iota
+	// SeverityMedium is used for shared passwords
+	SeverityMedium
+	// SeverityHigh is used for weak passwords and secrets that could not be audited
+	SeverityHigh
+)
+
+var severityNames = []string{"low", "medium", "high"}
+
+// String implements fmt.Stringer
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Severity) UnmarshalText(text []byte) error {
+	sev, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
+// ParseSeverity parses the name of a severity
+func ParseSeverity(name string) (Severity, error) {
+	for i, sn := range severityNames {
+		if strings.EqualFold(name, sn) {
+			return Severity(i), nil
+		}
+	}
+	return SeverityLow, fmt.Errorf("unknown severity %q. Must be one of %s", name, strings.Join(severityNames, ", "))
+}
+
+// Types of findings
+const (
+	FindingShared   = "shared"
+	FindingWeak     = "weak"
+	FindingRules    = "rules"
+	FindingPolicy   = "policy"
+	FindingOld      = "old"
+	FindingExpired  = "expired"
+	FindingExpiring = "expiring"
+	FindingError    = "error"
+)
+
+// findingTypes are all types of findings in the order they are printed
+var findingTypes = []struct {
+	name     string
+	title    string
+	none     string
+	severity Severity
+}{
+	{FindingShared, "Shared secrets", "No shared secrets found.", SeverityMedium},
+	{FindingWeak, "Weak secrets", "No weak secrets detected.", SeverityHigh},
+	{FindingRules, "Password rule violations", "", SeverityMedium},
+	{FindingPolicy, "Policy violations", "", SeverityHigh},
+	{FindingOld, "Old secrets", "No old secrets found.", SeverityLow},
+	{FindingExpired, "Expired secrets", "", SeverityHigh},
+	{FindingExpiring, "Expiring secrets", "", SeverityMedium},
+	{FindingError, "Errors", "", SeverityHigh},
+}
+
+// Finding is a flaw found in one or more secrets. It only contains the names
+// of the secrets, never their content.
+type Finding struct {
+	Type     string   `json:"type"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Secrets  []string `json:"secrets"`
+}
+
 // auditedSecret with its name, content a warning message and a pipeline error.
 type auditedSecret struct {
 	name string
@@ -29,32 +121,47 @@ type auditedSecret struct {
 	// the secret's content as a string. Needed for checking for duplicates.
 	content string
 
-	// message to the user about some flaw in the secret
-	messages []string
+	// messages to the user about some flaw in the secret, by finding type
+	messages map[string][]string
 
 	// real error that something in the pipeline went wrong
 	err error
 }
 
+func (a *auditedSecret) add(typ, msg string) {
+	if a.messages == nil {
+		a.messages = make(map[string][]string, 1)
+	}
+	a.messages[typ] = append(a.messages[typ], msg)
+}
+
 type secretGetter interface {
 	Get(context.Context, string) (gopass.Secret, error)
 	ListRevisions(context.Context, string) ([]backend.Revision, error)
 }
 
-type validator func(string, gopass.Secret) error
+// ruleLookup is implemented by stores supporting (custom) password rules
+type ruleLookup interface {
+	PasswordRule(context.Context, string) (string, pwrules.Rule, bool)
+}
 
-// Batch runs a password strength audit on multiple secrets
-func Batch(ctx context.Context, secrets []string, secStore secretGetter) error {
-	out.Printf(ctx, "Checking %d secrets. This may take some time ...\n", len(secrets))
+// policyLookup is implemented by stores supporting password policies
+type policyLookup interface {
+	PasswordPolicy(context.Context, string) (policy.Policy, bool, error)
+}
 
-	// Secrets that still need auditing.
-	pending := make(chan string, 100)
+type validator func(string, gopass.Secret) error
 
-	// Secrets that have been audited.
-	checked := make(chan auditedSecret, 100)
+// Batch runs a password strength audit on multiple secrets. The results are
+// printed grouped by the type of the finding. An error is returned if there
+// are any findings of at least the severity set in the context.
+func Batch(ctx context.Context, names []string, secStore secretGetter) error {
+	if !out.IsStructured(GetFormat(ctx)) {
+		out.Printf(ctx, "Checking %d secrets. This may take some time ...\n", len(names))
+	}
 
-	// Spawn workers that run the auditing of all secrets concurrently.
 	cv := crunchy.NewValidator()
+	minEntropy := GetMinEntropy(ctx)
 	validators := []validator{
 		func(_ string, sec gopass.Secret) error {
 			return cv.Check(sec.Password())
@@ -62,6 +169,11 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter) error {
 		func(name string, sec gopass.Secret) error {
 			ui := make([]string, 0, len(sec.Keys())+1)
 			for _, k := range sec.Keys() {
+				// replaced passwords are expected to differ from the
+				// current one
+				if secrets.IsOldPassword(k) {
+					continue
+				}
 				pw, found := sec.Get(k)
 				if !found {
 					continue
@@ -69,11 +181,7 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter) error {
 				ui = append(ui, pw)
 			}
 			ui = append(ui, name)
-			match := zxcvbn.PasswordStrength(sec.Password(), ui)
-			if match.Score < 3 {
-				return fmt.Errorf("weak password (%d / 4)", match.Score)
-			}
-			return nil
+			return strength.Check(sec.Password(), ui...).Validate(minEntropy)
 		},
 		func(name string, sec gopass.Secret) error {
 			if name == sec.Password() {
@@ -83,119 +191,153 @@ func Batch(ctx context.Context, secrets []string, secStore secretGetter) error {
 		},
 	}
 
-	// It would be nice to parallelize this operation and limit the maxJobs to
-	// runtime.NumCPU(), but sadly this causes various problems with multiple
-	// gnupg jobs running in parallel. See the entire discussion here:
-	//
-	// https://github.com/gopasspw/gopass/pull/245
-
-	maxJobs := 1 // do not change
-	done := make(chan struct{}, maxJobs)
-	for jobs := 0; jobs < maxJobs; jobs++ {
-		go audit(ctx, secStore, validators, pending, checked, done)
-	}
-
-	go func() {
-		for _, secret := range secrets {
-			pending <- secret
-		}
-		close(pending)
-	}()
-	go func() {
-		for i := 0; i < maxJobs; i++ {
-			<-done
-		}
-		close(checked)
-	}()
-
 	duplicates := make(map[string][]string)
-	messages := make(map[string][]string)
-	errors := make(map[string][]string)
+	// finding type -> message -> secrets
+	messages := make(map[string]map[string][]string, len(findingTypes))
 
-	bar := termio.NewProgressBar(int64(len(secrets)))
-	bar.Hidden = ctxutil.IsHidden(ctx)
+	bar := termio.NewProgressBar(int64(len(names)))
+	bar.Hidden = ctxutil.IsHidden(ctx) || out.IsStructured(GetFormat(ctx))
 
-	i := 0
-	for secret := range checked {
+	// the secrets are decrypted concurrently, but audited in order
+	err := decrypt.All(ctx, secStore, names, func(r decrypt.Result) error {
+		secret := audit(ctx, secStore, validators, r)
 		if secret.err != nil {
-			en := secret.err.Error()
-			errors[en] = append(errors[en], secret.name)
+			secret.add(FindingError, secret.err.Error())
 		} else if secret.content != "" {
 			duplicates[secret.content] = append(duplicates[secret.content], secret.name)
 		}
-		for _, m := range secret.messages {
-			messages[m] = append(messages[m], secret.name)
+		for typ, msgs := range secret.messages {
+			if messages[typ] == nil {
+				messages[typ] = make(map[string][]string)
+			}
+			for _, m := range msgs {
+				messages[typ][m] = append(messages[typ][m], secret.name)
+			}
 		}
 
 		bar.Inc()
-		i++
-		if i == len(secrets) {
-			break
-		}
-	}
+		return nil
+	})
 	bar.Done()
+	if err != nil {
+		return fmt.Errorf("audit aborted: %w", err)
+	}
 
-	return auditPrintResults(ctx, duplicates, messages, errors)
-}
-
-func audit(ctx context.Context, secStore secretGetter, validators []validator, secrets <-chan string, checked chan<- auditedSecret, done chan struct{}) {
-	for secret := range secrets {
-		as := auditedSecret{
-			name: secret,
-		}
-		// check for context cancelation
-		select {
-		case <-ctx.Done():
-			as.err = errors.New("user aborted")
-			checked <- as
-			continue
-		default:
+	findings := collectFindings(duplicates, messages)
+	if format := GetFormat(ctx); out.IsStructured(format) {
+		if err := printStructured(format, findings); err != nil {
+			return err
 		}
+	} else {
+		printFindings(ctx, findings)
+	}
 
-		debug.Log("Checking %s", secret)
-		sec, err := secStore.Get(ctx, secret)
-		if err != nil {
-			debug.Log("Failed to check %s: %s", secret, err)
-			as.err = err
-			if sec != nil {
-				as.content = sec.Password()
-			}
-			// failed to properly retrieve the secret
-			checked <- as
+	failSev := GetFailSeverity(ctx)
+	for _, f := range findings {
+		if f.Severity < failSev {
 			continue
 		}
+		_ = notify.Notify(ctx, "gopass - audit", "Finished. Found weak passwords and/or duplicates")
+		return fmt.Errorf("found weak passwords or duplicates")
+	}
 
-		as.content = sec.Password()
+	_ = notify.Notify(ctx, "gopass - audit", "Finished. No weak passwords or duplicates found!")
+	return nil
+}
 
-		// do not check empty secrets
-		if as.content == "" {
-			checked <- as
-			continue
+func audit(ctx context.Context, secStore secretGetter, validators []validator, r decrypt.Result) auditedSecret {
+	secret, sec := r.Name, r.Secret
+	as := auditedSecret{
+		name: secret,
+	}
+
+	debug.Log("Checking %s", secret)
+	if r.Err != nil {
+		debug.Log("Failed to check %s: %s", secret, r.Err)
+		as.err = r.Err
+		if sec != nil {
+			as.content = sec.Password()
 		}
+		// failed to properly retrieve the secret
+		return as
+	}
+
+	as.content = sec.Password()
+
+	// the expiry date is checked even if the token isn't the password
+	if window := GetExpiring(ctx); window > 0 {
+		auditExpiry(&as, sec, window)
+	}
 
-		// handle password validation errors
-		if errs := allValid(validators, secret, sec); len(errs) > 0 {
-			for _, e := range errs {
-				as.messages = append(as.messages, e.Error())
+	// do not check empty secrets
+	if as.content == "" {
+		return as
+	}
+
+	// handle password validation errors
+	for _, e := range allValid(validators, secret, sec) {
+		as.add(FindingWeak, e.Error())
+	}
+
+	// handle passwords violating the rules of their domain
+	if rl, ok := secStore.(ruleLookup); ok {
+		if domain, rule, found := rl.PasswordRule(ctx, secret); found {
+			if err := pwgen.CheckRule(rule, as.content); err != nil {
+				as.add(FindingRules, fmt.Sprintf("Violates the password rules for %s: %s", domain, err))
 			}
-			checked <- as
-			continue
 		}
+	}
 
-		// handle old passwords
-		revs, err := secStore.ListRevisions(ctx, secret)
-		if err != nil {
-			as.messages = append(as.messages, err.Error())
-		} else {
-			if len(revs) > 0 && time.Since(revs[0].Date) > 90*24*time.Hour {
-				as.messages = append(as.messages, "Password too old (90d)")
+	// handle passwords violating the policy of their folder
+	if pl, ok := secStore.(policyLookup); ok {
+		p, found, err := pl.PasswordPolicy(ctx, secret)
+		switch {
+		case err != nil:
+			as.add(FindingError, err.Error())
+		case found:
+			if vs := p.Check(as.content, secret); len(vs) > 0 {
+				as.add(FindingPolicy, fmt.Sprintf("Violates the policy for %s/: %s", p.Prefix, policy.Rules(vs)))
 			}
 		}
+	}
+
+	// handle old passwords
+	maxAge := GetMaxAge(ctx)
+	if maxAge <= 0 {
+		return as
+	}
+	revs, err := secStore.ListRevisions(ctx, secret)
+	if err != nil {
+		as.add(FindingError, err.Error())
+		return as
+	}
+	if len(revs) > 0 && time.Since(revs[0].Date) > maxAge {
+		as.add(FindingOld, fmt.Sprintf("Password too old (%dd)", int(maxAge.Hours()/24)))
+	}
+
+	// record every password for possible duplicates
+	return as
+}
 
-		// record every password for possible duplicates
-		checked <- as
+// auditExpiry reports secrets that have expired or expire within the window.
+// Secrets without an expiry date are ignored.
+func auditExpiry(as *auditedSecret, sec gopass.Secret, window time.Duration) {
+	exp, found, err := expiry.Get(sec)
+	if !found {
+		return
+	}
+	if err != nil {
+		as.add(FindingError, err.Error())
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case expiry.IsExpired(exp, now):
+		as.add(FindingExpired, fmt.Sprintf("Expired on %s", expiry.Format(exp)))
+	case exp.Sub(now) <= window:
+		as.add(FindingExpiring, fmt.Sprintf("Expires on %s", expiry.Format(exp)))
 	}
-	done <- struct{}{}
 }
 
 func allValid(vs []validator, name string, sec gopass.Secret) []error {
@@ -208,55 +350,94 @@ func allValid(vs []validator, name string, sec gopass.Secret) []error {
 	return errs
 }
 
-func printAuditResults(m map[string][]string, format string, color func(format string, a ...interface{}) string) bool {
-	b := false
-
-	for msg, secrets := range m {
-		b = true
-		fmt.Fprint(out.Stdout, color(format, msg))
-		for _, secret := range secrets {
-			fmt.Fprint(out.Stdout, color("\t- %s\n", secret))
+// collectFindings turns the collected messages into findings, ordered by
+// type and message
+func collectFindings(duplicates map[string][]string, messages map[string]map[string][]string) []Finding {
+	findings := make([]Finding, 0, len(messages))
+	for _, ft := range findingTypes {
+		if ft.name == FindingShared {
+			for _, content := range sortedDuplicates(duplicates) {
+				if secrets := duplicates[content]; len(secrets) > 1 {
+					findings = append(findings, Finding{
+						Type:     ft.name,
+						Severity: ft.severity,
+						Message:  "Detected a shared secret",
+						Secrets:  secrets,
+					})
+				}
+			}
+			continue
+		}
+		m := messages[ft.name]
+		for _, msg := range sortedKeys(m) {
+			findings = append(findings, Finding{
+				Type:     ft.name,
+				Severity: ft.severity,
+				Message:  msg,
+				Secrets:  m[msg],
+			})
 		}
 	}
-
-	return b
-}
-
-// Single runs a password strength audit on a single password
-func Single(ctx context.Context, password string) {
-	validator := crunchy.NewValidator()
-	if err := validator.Check(password); err != nil {
-		out.Printf(ctx, fmt.Sprintf("Warning: %s", err))
-	}
+	return findings
 }
 
-func auditPrintResults(ctx context.Context, duplicates, messages, errors map[string][]string) error {
-	foundDuplicates := false
-	for _, secrets := range duplicates {
-		if len(secrets) > 1 {
-			foundDuplicates = true
+func printFindings(ctx context.Context, findings []Finding) {
+	for _, ft := range findingTypes {
+		colorFn := color.CyanString
+		if ft.name == FindingError {
+			colorFn = color.RedString
+		}
 
-			out.Printf(ctx, "Detected a shared secret for:")
-			for _, secret := range secrets {
-				out.Printf(ctx, "\t- %s", secret)
+		found := false
+		for _, f := range findings {
+			if f.Type != ft.name {
+				continue
+			}
+			if !found {
+				fmt.Fprint(out.Stdout, color.New(color.Bold).Sprintf("%s (severity: %s)\n", ft.title, ft.severity))
+				found = true
+			}
+			fmt.Fprint(out.Stdout, colorFn("%s:\n", f.Message))
+			for _, secret := range f.Secrets {
+				fmt.Fprint(out.Stdout, colorFn("\t- %s\n", secret))
 			}
 		}
+		if !found && ft.none != "" {
+			out.Printf(ctx, ft.none)
+		}
 	}
-	if !foundDuplicates {
-		out.Printf(ctx, "No shared secrets found.")
-	}
+}
 
-	foundWeakPasswords := printAuditResults(messages, "%s:\n", color.CyanString)
-	if !foundWeakPasswords {
-		out.Printf(ctx, "No weak secrets detected.")
+func printStructured(format string, findings []Finding) error {
+	if err := out.Encode(out.Stdout, format, struct {
+		Findings []Finding `json:"findings"`
+	}{
+		Findings: findings,
+	}); err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
 	}
-	foundErrors := printAuditResults(errors, "%s:\n", color.RedString)
+	return nil
+}
 
-	if foundWeakPasswords || foundDuplicates || foundErrors {
-		_ = notify.Notify(ctx, "gopass - audit", "Finished. Found weak passwords and/or duplicates")
-		return fmt.Errorf("found weak passwords or duplicates")
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	_ = notify.Notify(ctx, "gopass - audit", "Finished. No weak passwords or duplicates found!")
-	return nil
+// sortedDuplicates returns the keys of the duplicates map ordered by the
+// first secret name, so that the (secret) content doesn't leak through the
+// ordering
+func sortedDuplicates(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return m[keys[i]][0] < m[keys[j]][0]
+	})
+	return keys
 }
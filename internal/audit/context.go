@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+// DefaultMaxAge is the default age after which a password is reported as old
+const DefaultMaxAge = 90 * 24 * time.Hour
+
+type contextKey int
+
+const (
+	ctxKeyMinEntropy contextKey = iota
+	ctxKeyMaxAge
+	ctxKeyFormat
+	ctxKeyFailSeverity
+	ctxKeyExpiring
+)
+
+// WithMinEntropy returns a context with the minimum entropy (in bits) a
+// password must have set. If it's not set the zxcvbn score is used instead.
+func WithMinEntropy(ctx context.Context, bits float64) context.Context {
+	return context.WithValue(ctx, ctxKeyMinEntropy, bits)
+}
+
+// GetMinEntropy returns the minimum entropy (in bits) a password must have
+// or 0 if the zxcvbn score should be used
+func GetMinEntropy(ctx context.Context) float64 {
+	fv, ok := ctx.Value(ctxKeyMinEntropy).(float64)
+	if !ok {
+		return 0
+	}
+	return fv
+}
+
+// WithMaxAge returns a context with the age after which a password is
+// reported as old set. Zero disables the check.
+func WithMaxAge(ctx context.Context, age time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyMaxAge, age)
+}
+
+// GetMaxAge returns the age after which a password is reported as old or
+// the default (90 days)
+func GetMaxAge(ctx context.Context) time.Duration {
+	dv, ok := ctx.Value(ctxKeyMaxAge).(time.Duration)
+	if !ok {
+		return DefaultMaxAge
+	}
+	return dv
+}
+
+// WithFormat returns a context with the output format of the results set,
+// e.g. json or yaml
+func WithFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, ctxKeyFormat, format)
+}
+
+// GetFormat returns the output format of the results or the default (text)
+func GetFormat(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyFormat).(string)
+	if !ok || sv == "" {
+		return out.FormatText
+	}
+	return sv
+}
+
+// WithFailSeverity returns a context with the minimum severity of a finding
+// that makes the audit fail set
+func WithFailSeverity(ctx context.Context, sev Severity) context.Context {
+	return context.WithValue(ctx, ctxKeyFailSeverity, sev)
+}
+
+// GetFailSeverity returns the minimum severity of a finding that makes the
+// audit fail or the default (SeverityLow, i.e. any finding)
+func GetFailSeverity(ctx context.Context) Severity {
+	sv, ok := ctx.Value(ctxKeyFailSeverity).(Severity)
+	if !ok {
+		return SeverityLow
+	}
+	return sv
+}
+
+// WithExpiring returns a context with the window set in which secrets are
+// reported as expiring. Zero disables the check.
+func WithExpiring(ctx context.Context, window time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyExpiring, window)
+}
+
+// GetExpiring returns the window in which secrets are reported as expiring
+// or the default (0, i.e. the check is disabled)
+func GetExpiring(ctx context.Context) time.Duration {
+	dv, ok := ctx.Value(ctxKeyExpiring).(time.Duration)
+	if !ok {
+		return 0
+	}
+	return dv
+}
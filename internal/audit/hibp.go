@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/notify"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/decrypt"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/hibp/api"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/fatih/color"
+)
+
+// BreachChecker looks up SHA-1 sums of passwords in lists of breached
+// passwords. It returns the (upper case) sums found and how often they were
+// seen, if known. The dump scanner from pkg/hibp/dump implements it.
+type BreachChecker interface {
+	LookupCounts(ctx context.Context, sums []string) map[string]uint64
+}
+
+// ProbabilisticChecker is a BreachChecker that may report passwords that
+// were never breached, e.g. a bloom filter from pkg/hibp/bloom. Its matches
+// are reported as possible matches.
+type ProbabilisticChecker interface {
+	BreachChecker
+	FalsePositiveRate() float64
+}
+
+// APIChecker checks passwords against the HIBP range API. Only the first
+// five characters of each SHA-1 sum are sent to the server. Prefixes that
+// can not be looked up are reported as a warning.
+type APIChecker struct {
+	Client *api.Client
+}
+
+// LookupCounts implements BreachChecker
+func (a APIChecker) LookupCounts(ctx context.Context, sums []string) map[string]uint64 {
+	client := a.Client
+	if client == nil {
+		client = api.NewClient()
+	}
+
+	found, errs := client.LookupBatch(ctx, sums)
+	prefixes := make([]string, 0, len(errs))
+	for prefix := range errs {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		out.Warningf(ctx, "Failed to check passwords with the hash prefix %s: %s", prefix, errs[prefix])
+	}
+	return found
+}
+
+// HIBP checks the passwords of the given secrets against haveibeenpwned.com
+// using the given checkers. The names of the secrets with breached passwords
+// are printed, never their content. An error is returned if any breached
+// password was found.
+func HIBP(ctx context.Context, secrets []string, secStore decrypt.Getter, checkers ...BreachChecker) error {
+	out.Printf(ctx, "Checking %d secrets against haveibeenpwned.com. This may take some time ...", len(secrets))
+
+	// SHA-1 sum -> secret names
+	sums := make(map[string][]string, len(secrets))
+
+	bar := termio.NewProgressBar(int64(len(secrets)))
+	bar.Hidden = ctxutil.IsHidden(ctx)
+
+	err := decrypt.All(ctx, secStore, secrets, func(r decrypt.Result) error {
+		defer bar.Inc()
+		if r.Err != nil {
+			out.Errorf(ctx, "Failed to decrypt %s: %s", r.Name, r.Err)
+			return nil
+		}
+		pw := r.Secret.Password()
+		if pw == "" {
+			return nil
+		}
+		sum := fmt.Sprintf("%X", sha1.Sum([]byte(pw)))
+		sums[sum] = append(sums[sum], r.Name)
+		return nil
+	})
+	bar.Done()
+	if err != nil {
+		return fmt.Errorf("audit aborted: %w", err)
+	}
+
+	in := make([]string, 0, len(sums))
+	for sum := range sums {
+		in = append(in, sum)
+	}
+
+	// secret name -> breach count
+	breached := make(map[string]uint64)
+	// secret name -> false positive rate, for the names only found by
+	// probabilistic checkers
+	possible := make(map[string]float64)
+	for _, c := range checkers {
+		// the dump scanner sorts its input
+		lookup := make([]string, len(in))
+		copy(lookup, in)
+		pc, isProbabilistic := c.(ProbabilisticChecker)
+		for sum, count := range c.LookupCounts(ctx, lookup) {
+			for _, name := range sums[strings.ToUpper(sum)] {
+				if isProbabilistic {
+					if rate, found := possible[name]; !found || pc.FalsePositiveRate() < rate {
+						possible[name] = pc.FalsePositiveRate()
+					}
+					continue
+				}
+				if count >= breached[name] {
+					breached[name] = count
+				}
+			}
+		}
+	}
+	for name := range breached {
+		delete(possible, name)
+	}
+	debug.Log("found %d breached and %d possibly breached secrets", len(breached), len(possible))
+
+	if len(breached) < 1 && len(possible) < 1 {
+		out.OKf(ctx, "No breached passwords found.")
+		_ = notify.Notify(ctx, "gopass - audit hibp", "Finished. No breached passwords found!")
+		return nil
+	}
+
+	names := make([]string, 0, len(breached)+len(possible))
+	for name := range breached {
+		names = append(names, name)
+	}
+	for name := range possible {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprint(out.Stdout, color.New(color.Bold).Sprintf("Breached secrets (severity: %s)\n", SeverityHigh))
+	for _, name := range names {
+		if rate, found := possible[name]; found {
+			fmt.Fprint(out.Stdout, color.YellowString("\t- %s (possible match, %g%% false positives)\n", name, rate*100))
+			continue
+		}
+		if count := breached[name]; count > 0 {
+			fmt.Fprint(out.Stdout, color.RedString("\t- %s (seen %d times)\n", name, count))
+			continue
+		}
+		fmt.Fprint(out.Stdout, color.RedString("\t- %s\n", name))
+	}
+	if len(possible) > 0 {
+		out.Printf(ctx, "Possible matches were found in a bloom filter. A small fraction of them may be")
+		out.Printf(ctx, "false positives, check them with --api or --dumps to be sure.")
+	}
+
+	_ = notify.Notify(ctx, "gopass - audit hibp", "Finished. Found breached passwords")
+	return fmt.Errorf("found %d secrets with breached passwords", len(names))
+}
@@ -0,0 +1,177 @@
+// Package autolock locks long running gopass processes, e.g. the REPL or the
+// API server, after a period of inactivity, when the system goes to sleep and
+// when they receive SIGUSR1. Locking wipes the in-process caches, so the next
+// operation has to ask for the passphrase again.
+package autolock
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// Inactivity, Sleep, Signal and Command are the reasons for locking
+	Inactivity = "inactivity"
+	Sleep      = "sleep"
+	Signal     = "signal"
+	Command    = "command"
+
+	// checkInterval is how often the clocks are compared to detect that the
+	// system was suspended
+	checkInterval = 5 * time.Second
+	// sleepThreshold is the minimum difference between the clocks that is
+	// considered a suspend. Smaller ones may be adjustments of the wall
+	// clock.
+	sleepThreshold = 30 * time.Second
+)
+
+// Watcher calls the lock function once an idle period without operations has
+// passed, and when the system sleeps or the process receives SIGUSR1. The
+// lock function must be safe to call from any goroutine.
+type Watcher struct {
+	idle time.Duration
+	lock func(reason string)
+
+	mu     sync.Mutex
+	active int
+	timer  *time.Timer
+	reason string
+	// last is the time of the last clock check, with its monotonic reading
+	last time.Time
+}
+
+// New creates a watcher locking after idle without operations. Zero only
+// locks on sleep, the signal or a call to Lock.
+func New(idle time.Duration, lock func(reason string)) *Watcher {
+	w := &Watcher{
+		idle: idle,
+		lock: lock,
+		last: time.Now(),
+	}
+	if idle > 0 {
+		w.timer = time.AfterFunc(idle, w.expire)
+	}
+	return w
+}
+
+// Run watches for sleep and SIGUSR1 until the context is canceled
+func (w *Watcher) Run(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	if s := lockSignals(); len(s) > 0 {
+		signal.Notify(sigs, s...)
+		defer signal.Stop(sigs)
+	}
+	sleeps := watchSleep(ctx)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.mu.Lock()
+			if w.timer != nil {
+				w.timer.Stop()
+			}
+			w.mu.Unlock()
+			return
+		case <-sigs:
+			w.Lock(Signal)
+		case <-sleeps:
+			w.Lock(Sleep)
+		case <-ticker.C:
+			w.checkSleep()
+		}
+	}
+}
+
+// Begin marks the start of an operation. The watcher doesn't lock because of
+// inactivity until the operation ends, but a sleep of the system that has
+// been missed so far is detected now.
+func (w *Watcher) Begin() {
+	w.checkSleep()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.active++
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// End marks the end of an operation and restarts the idle period once no
+// operation is running anymore
+func (w *Watcher) End() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active > 0 {
+		w.active--
+	}
+	if w.active == 0 && w.timer != nil {
+		w.timer.Reset(w.idle)
+	}
+}
+
+// Lock locks right away, even if an operation is running
+func (w *Watcher) Lock(reason string) {
+	w.mu.Lock()
+	w.reason = reason
+	w.mu.Unlock()
+
+	debug.Log("locking, reason: %s", reason)
+	w.lock(reason)
+}
+
+// Locked returns the reason of the last lock, if the watcher has locked since
+// Unlock was called
+func (w *Watcher) Locked() (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.reason, w.reason != ""
+}
+
+// Unlock records that the user has authenticated again after a lock
+func (w *Watcher) Unlock() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.reason = ""
+}
+
+// expire is called by the idle timer
+func (w *Watcher) expire() {
+	w.mu.Lock()
+	active := w.active
+	w.mu.Unlock()
+
+	if active > 0 {
+		return
+	}
+	w.Lock(Inactivity)
+}
+
+// checkSleep locks if the system was suspended since the last check. The
+// monotonic clock stops while the system sleeps, but the wall clock doesn't.
+func (w *Watcher) checkSleep() {
+	now := time.Now()
+
+	w.mu.Lock()
+	last := w.last
+	w.last = now
+	w.mu.Unlock()
+
+	mono := now.Sub(last)
+	wall := now.Round(0).Sub(last.Round(0))
+	if wall-mono < sleepThreshold {
+		return
+	}
+	debug.Log("the system was suspended for about %s", wall-mono)
+	w.Lock(Sleep)
+}
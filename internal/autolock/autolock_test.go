@@ -0,0 +1,79 @@
+package autolock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	sync.Mutex
+	reasons []string
+}
+
+func (r *recorder) lock(reason string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *recorder) get() []string {
+	r.Lock()
+	defer r.Unlock()
+
+	return append([]string{}, r.reasons...)
+}
+
+func TestInactivity(t *testing.T) {
+	r := &recorder{}
+	w := New(50*time.Millisecond, r.lock)
+
+	// a running operation is never interrupted
+	w.Begin()
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, r.get())
+	_, locked := w.Locked()
+	assert.False(t, locked)
+
+	w.End()
+	assert.Eventually(t, func() bool { return len(r.get()) == 1 }, time.Second, 10*time.Millisecond)
+	reason, locked := w.Locked()
+	assert.True(t, locked)
+	assert.Equal(t, Inactivity, reason)
+
+	// locked only once until the next operation
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, []string{Inactivity}, r.get())
+
+	w.Unlock()
+	_, locked = w.Locked()
+	assert.False(t, locked)
+}
+
+func TestLock(t *testing.T) {
+	r := &recorder{}
+	w := New(0, r.lock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	w.Begin()
+	w.Lock(Command)
+	w.End()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []string{Command}, r.get())
+	reason, locked := w.Locked()
+	assert.True(t, locked)
+	assert.Equal(t, Command, reason)
+
+	cancel()
+	<-done
+}
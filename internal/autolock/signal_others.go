@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package autolock
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockSignals are the signals that lock right away
+func lockSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package autolock
+
+import "os"
+
+// lockSignals are the signals that lock right away. Windows has no SIGUSR1.
+func lockSignals() []os.Signal {
+	return nil
+}
@@ -0,0 +1,106 @@
+//go:build darwin && cgo
+// +build darwin,cgo
+
+package autolock
+
+/*
+#cgo LDFLAGS: -framework CoreFoundation -framework IOKit
+#include <stdlib.h>
+#include <CoreFoundation/CoreFoundation.h>
+#include <IOKit/IOMessage.h>
+#include <IOKit/pwr_mgt/IOPMLib.h>
+
+typedef struct {
+	io_connect_t root;
+	IONotificationPortRef port;
+	io_object_t notifier;
+	int sleeping;
+} sleepWatch;
+
+static void powerChanged(void *refcon, io_service_t service, uint32_t messageType, void *messageArgument) {
+	sleepWatch *w = refcon;
+
+	switch (messageType) {
+	case kIOMessageCanSystemSleep:
+		IOAllowPowerChange(w->root, (intptr_t)messageArgument);
+		break;
+	case kIOMessageSystemWillSleep:
+		// the system waits up to 30 seconds if the change isn't allowed
+		w->sleeping = 1;
+		IOAllowPowerChange(w->root, (intptr_t)messageArgument);
+		break;
+	}
+}
+
+// startSleepWatch registers for the power notifications on the run loop of
+// the current thread
+static sleepWatch *startSleepWatch(void) {
+	sleepWatch *w = calloc(1, sizeof(sleepWatch));
+	if (w == NULL) {
+		return NULL;
+	}
+	w->root = IORegisterForSystemPower(w, &w->port, powerChanged, &w->notifier);
+	if (w->root == MACH_PORT_NULL) {
+		free(w);
+		return NULL;
+	}
+	CFRunLoopAddSource(CFRunLoopGetCurrent(), IONotificationPortGetRunLoopSource(w->port), kCFRunLoopDefaultMode);
+	return w;
+}
+
+// waitForSleep runs the run loop for up to a second and returns whether the
+// system is about to sleep
+static int waitForSleep(sleepWatch *w) {
+	w->sleeping = 0;
+	CFRunLoopRunInMode(kCFRunLoopDefaultMode, 1.0, true);
+	return w->sleeping;
+}
+
+static void stopSleepWatch(sleepWatch *w) {
+	CFRunLoopRemoveSource(CFRunLoopGetCurrent(), IONotificationPortGetRunLoopSource(w->port), kCFRunLoopDefaultMode);
+	IODeregisterForSystemPower(&w->notifier);
+	IOServiceClose(w->root);
+	IONotificationPortDestroy(w->port);
+	free(w);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// watchSleep returns a channel receiving a value whenever IOKit announces
+// that the system is about to sleep. If the power notifications are not
+// available a sleep is only detected after the system has resumed.
+func watchSleep(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	go func() {
+		// the notifications are delivered to the run loop of the thread
+		// they were registered on
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		w := C.startSleepWatch()
+		if w == nil {
+			debug.Log("failed to register for power notifications, can not watch for sleep")
+			return
+		}
+		defer C.stopSleepWatch(w)
+
+		for ctx.Err() == nil {
+			if C.waitForSleep(w) == 0 {
+				continue
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch
+}
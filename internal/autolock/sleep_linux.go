@@ -0,0 +1,61 @@
+//go:build linux
+// +build linux
+
+package autolock
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"github.com/godbus/dbus"
+)
+
+const (
+	logindInterface = "org.freedesktop.login1.Manager"
+	prepareForSleep = logindInterface + ".PrepareForSleep"
+)
+
+// watchSleep returns a channel receiving a value whenever logind announces
+// that the system is about to sleep. Without a system bus, e.g. in a
+// container, the channel never receives anything and a sleep is only
+// detected after the system has resumed.
+func watchSleep(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		debug.Log("no system bus, can not watch for sleep: %s", err)
+		return ch
+	}
+	match := "type='signal',interface='" + logindInterface + "',member='PrepareForSleep'"
+	if call := conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, match); call.Err != nil {
+		debug.Log("failed to watch for sleep: %s", call.Err)
+		return ch
+	}
+
+	sigs := make(chan *dbus.Signal, 4)
+	conn.Signal(sigs)
+	go func() {
+		defer conn.RemoveSignal(sigs)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-sigs:
+				if sig == nil || sig.Name != prepareForSleep || len(sig.Body) < 1 {
+					continue
+				}
+				// the signal is sent with false after resuming
+				if sleeping, ok := sig.Body[0].(bool); !ok || !sleeping {
+					continue
+				}
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,21 @@
+//go:build !linux && !(darwin && cgo)
+// +build !linux
+// +build !darwin !cgo
+
+package autolock
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// watchSleep returns a channel that never receives anything. A sleep is only
+// detected after the system has resumed, before the next operation starts.
+func watchSleep(ctx context.Context) <-chan struct{} {
+	if runtime.GOOS == "darwin" {
+		debug.Log("built without cgo, can not watch for sleep")
+	}
+	return make(chan struct{})
+}
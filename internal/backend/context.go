@@ -8,6 +8,8 @@ const (
 	ctxKeyCryptoBackend contextKey = iota
 	ctxKeyRCSBackend
 	ctxKeyStorageBackend
+	ctxKeyFsckFix
+	ctxKeyCloneDepth
 )
 
 // CryptoBackendName returns the name of the given backend
@@ -72,3 +74,32 @@ func HasStorageBackend(ctx context.Context) bool {
 func StorageBackendName(sb StorageBackend) string {
 	return StorageNameFromBackend(sb)
 }
+
+// WithFsckFix returns a context with the flag for fixing the problems the
+// storage backend finds during fsck set
+func WithFsckFix(ctx context.Context, fix bool) context.Context {
+	return context.WithValue(ctx, ctxKeyFsckFix, fix)
+}
+
+// IsFsckFix returns true if the storage backend should fix the problems it
+// finds during fsck
+func IsFsckFix(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyFsckFix).(bool)
+	return ok && bv
+}
+
+// WithCloneDepth returns a context with the number of commits to fetch when
+// cloning a store. 0 fetches the full history.
+func WithCloneDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, ctxKeyCloneDepth, depth)
+}
+
+// GetCloneDepth returns the number of commits to fetch when cloning a store
+// or 0 for the full history
+func GetCloneDepth(ctx context.Context) int {
+	iv, ok := ctx.Value(ctxKeyCloneDepth).(int)
+	if !ok || iv < 0 {
+		return 0
+	}
+	return iv
+}
@@ -3,7 +3,9 @@ package backend
 import (
 	"context"
 	"fmt"
+	"io"
 	"sort"
+	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -46,16 +48,75 @@ type Crypto interface {
 
 	Encrypt(ctx context.Context, plaintext []byte, recipients []string) ([]byte, error)
 	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+	// EncryptStream and DecryptStream work like Encrypt and Decrypt but
+	// read the input from r and write the output to w, e.g. for large
+	// attachments. Backends that can't stream use EncryptBuffered and
+	// DecryptBuffered.
+	EncryptStream(ctx context.Context, r io.Reader, recipients []string, w io.Writer) error
+	DecryptStream(ctx context.Context, r io.Reader, w io.Writer) error
 	RecipientIDs(ctx context.Context, ciphertext []byte) ([]string, error)
 
 	Name() string
-	Version(context.Context) semver.Version
+	Version(context.Context) (semver.Version, error)
 	Initialized(ctx context.Context) error
 	Ext() string    // filename extension
 	IDFile() string // recipient IDs
 	Concurrency() int
 }
 
+// EncryptBuffered implements EncryptStream for backends that can't stream.
+// The whole plaintext and ciphertext are held in memory.
+func EncryptBuffered(ctx context.Context, c Crypto, r io.Reader, recipients []string, w io.Writer) error {
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	ciphertext, err := c.Encrypt(ctx, plaintext, recipients)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(ciphertext)
+	return err
+}
+
+// DecryptBuffered implements DecryptStream for backends that can't stream.
+// The whole ciphertext and plaintext are held in memory.
+func DecryptBuffered(ctx context.Context, c Crypto, r io.Reader, w io.Writer) error {
+	ciphertext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	plaintext, err := c.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(plaintext)
+	return err
+}
+
+// NoSecretKeyError is returned by Decrypt if the ciphertext can't be
+// decrypted because none of the secret keys it is encrypted for is available
+type NoSecretKeyError struct {
+	Recipients []MissingKey
+}
+
+// MissingKey is a key a ciphertext is encrypted for but whose secret key is
+// not available
+type MissingKey struct {
+	ID          string
+	Fingerprint string // empty if the public key is unknown, too
+	Name        string // e.g. the email of the public key
+	Own         bool   // the key is ultimately trusted, i.e. it's one of ours
+}
+
+func (e *NoSecretKeyError) Error() string {
+	ids := make([]string, 0, len(e.Recipients))
+	for _, r := range e.Recipients {
+		ids = append(ids, r.ID)
+	}
+	return fmt.Sprintf("no secret key for any of %s", strings.Join(ids, ", "))
+}
+
 // RegisterCrypto registers a new crypto backend with the backend registry.
 func RegisterCrypto(id CryptoBackend, name string, loader CryptoLoader) {
 	cryptoRegistry[id] = loader
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	rdebug "runtime/debug"
 	"sort"
 	"strings"
 	"time"
@@ -18,14 +19,16 @@ import (
 	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/pkg/appdir"
 	"github.com/gopasspw/gopass/pkg/debug"
-	"github.com/gopasspw/gopass/pkg/termio"
 )
 
 const (
 	// Ext is the file extension for age encrypted secrets
 	Ext = "age"
 	// IDFile is the name for age recipients
-	IDFile = ".age-ids"
+	IDFile = ".age-recipients"
+	// OldIDFile is the name of the age recipients file used by earlier
+	// versions. It is migrated to IDFile when the store is loaded.
+	OldIDFile = ".age-ids"
 )
 
 // Age is an age backend
@@ -36,6 +39,7 @@ type Age struct {
 	ghCache *cache.OnDisk
 	askPass *askPass
 	krCache map[string]age.Identity
+	socket  string
 }
 
 // New creates a new Age backend
@@ -44,13 +48,18 @@ func New() (*Age, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Age{
+	a := &Age{
 		binary:  "age",
 		ghc:     github.NewClient(nil),
 		ghCache: cDir,
-		keyring: filepath.Join(appdir.UserConfig(), "age-keyring.age"),
+		keyring: filepath.Join(appdir.UserConfig(), "age", "identities"),
 		askPass: DefaultAskPass,
-	}, nil
+		socket:  AgentSocket(),
+	}
+	if err := a.migrateKeyring(filepath.Join(appdir.UserConfig(), "age-keyring.age")); err != nil {
+		return nil, err
+	}
+	return a, nil
 }
 
 // Initialized returns nil
@@ -67,11 +76,22 @@ func (a *Age) Name() string {
 	return "age"
 }
 
-// Version return 1.0.0
-func (a *Age) Version(ctx context.Context) semver.Version {
+// Version returns the version of the age library gopass was built with or
+// 0.0.1 if it's unknown
+func (a *Age) Version(ctx context.Context) (semver.Version, error) {
+	if bi, ok := rdebug.ReadBuildInfo(); ok {
+		for _, dep := range bi.Deps {
+			if dep.Path != "filippo.io/age" {
+				continue
+			}
+			if v, err := semver.ParseTolerant(dep.Version); err == nil {
+				return v, nil
+			}
+		}
+	}
 	return semver.Version{
 		Patch: 1,
-	}
+	}, nil
 }
 
 // Ext returns the extension
@@ -87,42 +107,44 @@ func (a *Age) IDFile() string {
 func (a *Age) parseRecipients(ctx context.Context, recipients []string) ([]age.Recipient, error) {
 	out := make([]age.Recipient, 0, len(recipients))
 	for _, r := range recipients {
-		if strings.HasPrefix(r, "age1") {
-			id, err := age.ParseX25519Recipient(r)
-			if err != nil {
-				debug.Log("Failed to parse recipient %q as X25519: %s", r, err)
-				continue
-			}
-			out = append(out, id)
-			continue
-		}
-		if strings.HasPrefix(r, "ssh-") {
-			id, err := agessh.ParseRecipient(r)
-			if err != nil {
-				debug.Log("Failed to parse recipient %q as SSH: %s", r, err)
-				continue
-			}
-			out = append(out, id)
-			continue
-		}
 		if strings.HasPrefix(r, "github:") {
 			pks, err := a.getPublicKeysGithub(ctx, strings.TrimPrefix(r, "github:"))
 			if err != nil {
 				return out, err
 			}
 			for _, pk := range pks {
-				id, err := agessh.ParseRecipient(r)
+				id, err := agessh.ParseRecipient(pk)
 				if err != nil {
 					debug.Log("Failed to parse GitHub recipient %q: %q: %s", r, pk, err)
 					continue
 				}
 				out = append(out, id)
 			}
+			continue
+		}
+		id, err := parseRecipient(r)
+		if err != nil {
+			debug.Log("Failed to parse recipient %q: %s", r, err)
+			continue
 		}
+		out = append(out, id)
 	}
 	return out, nil
 }
 
+// parseRecipient parses a native age (age1...) or SSH (ssh-ed25519, ssh-rsa)
+// public key
+func parseRecipient(r string) (age.Recipient, error) {
+	switch {
+	case strings.HasPrefix(r, "age1"):
+		return age.ParseX25519Recipient(r)
+	case strings.HasPrefix(r, "ssh-"):
+		return agessh.ParseRecipient(r)
+	default:
+		return nil, fmt.Errorf("unknown recipient type %q", r)
+	}
+}
+
 // ListIdentities lists all identities
 func (a *Age) ListIdentities(ctx context.Context) ([]string, error) {
 	ids, err := a.getAllIdentities(ctx)
@@ -159,11 +181,15 @@ func (a *Age) getAllIdentities(ctx context.Context) (map[string]age.Identity, er
 	if err != nil {
 		return nil, err
 	}
+
+	ids := make(map[string]age.Identity, len(native)+len(ssh))
+	for k, v := range native {
+		ids[k] = v
+	}
 	for k, v := range ssh {
-		native[k] = v
+		ids[k] = v
 	}
-
-	return native, nil
+	return ids, nil
 }
 
 func (a *Age) getNativeIdentities(ctx context.Context) (map[string]age.Identity, error) {
@@ -175,25 +201,6 @@ func (a *Age) getNativeIdentities(ctx context.Context) (map[string]age.Identity,
 		debug.Log("failed to load native identities: %+v", err)
 		return nil, err
 	}
-	debug.Log("keyring: %+v", kr)
-	if len(kr) < 1 {
-		// TODO we shouldn't print in here, use a callback
-		ok, err := termio.AskForBool(ctx, "🔑 No existing age identities found. Do you want to generate a new one?", true)
-		if err != nil {
-			return nil, err
-		}
-		if !ok {
-			return nil, fmt.Errorf("user aborted")
-		}
-		debug.Log("generating new age keypair")
-		id, err := a.genKey(ctx)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]age.Identity{
-			id.Recipient().String(): id,
-		}, nil
-	}
 	ids := make(map[string]age.Identity, len(kr))
 	for _, k := range kr {
 		id, err := age.ParseX25519Identity(k.Identity)
@@ -0,0 +1,137 @@
+package age
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestAge(t *testing.T) *Age {
+	t.Helper()
+
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(td)
+	})
+
+	// no SSH identities
+	oldHome := os.Getenv("HOME")
+	require.NoError(t, os.Setenv("HOME", td))
+	sshCache = nil
+	t.Cleanup(func() {
+		_ = os.Setenv("HOME", oldHome)
+		sshCache = nil
+	})
+
+	return &Age{
+		keyring: filepath.Join(td, "age", "identities"),
+		askPass: &askPass{
+			cache: cache.NewInMemTTL(time.Hour, time.Hour),
+			pinentry: func() (piner, error) {
+				return nil, fmt.Errorf("no pinentry in tests")
+			},
+		},
+	}
+}
+
+func sshPublicKey(t *testing.T) string {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	pk, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pk))) + " bob@example.org"
+}
+
+func TestGenerateIdentity(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAge(t)
+
+	ids, err := a.ListIdentities(ctx)
+	require.NoError(t, err)
+	assert.Len(t, ids, 0)
+
+	require.NoError(t, a.GenerateIdentity(ctx, "Alice", "alice@example.org", "passphrase"))
+	fi, err := os.Stat(a.keyring)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// no prompt, the passphrase is cached
+	ids, err = a.ListIdentities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	recps, err := a.ListRecipients(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ids, recps)
+
+	buf, err := a.Encrypt(ctx, []byte("secret"), ids)
+	require.NoError(t, err)
+	plain, err := a.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+
+	// both can be streamed, too
+	stream := &bytes.Buffer{}
+	require.NoError(t, a.EncryptStream(ctx, strings.NewReader("streamed"), ids, stream))
+	plain, err = a.Decrypt(ctx, stream.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "streamed", string(plain))
+	stream.Reset()
+	require.NoError(t, a.DecryptStream(ctx, bytes.NewReader(buf), stream))
+	assert.Equal(t, "secret", stream.String())
+
+	// the keyring is encrypted with the passphrase
+	ciphertext, err := os.ReadFile(a.keyring)
+	require.NoError(t, err)
+	id, err := age.NewScryptIdentity("passphrase")
+	require.NoError(t, err)
+	_, err = a.decrypt(ciphertext, id)
+	assert.NoError(t, err)
+}
+
+func TestMigrateKeyring(t *testing.T) {
+	a := newTestAge(t)
+
+	old := filepath.Join(filepath.Dir(filepath.Dir(a.keyring)), "age-keyring.age")
+	require.NoError(t, a.migrateKeyring(old))
+	assert.NoFileExists(t, a.keyring)
+
+	require.NoError(t, os.WriteFile(old, []byte("keyring"), 0600))
+	require.NoError(t, a.migrateKeyring(old))
+	assert.NoFileExists(t, old)
+	buf, err := os.ReadFile(a.keyring)
+	require.NoError(t, err)
+	assert.Equal(t, "keyring", string(buf))
+}
+
+func TestRecipients(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAge(t)
+
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	sshKey := sshPublicKey(t)
+
+	found, err := a.FindRecipients(ctx, id.Recipient().String(), sshKey, "age1invalid", "0xDEADBEEF")
+	require.NoError(t, err)
+	assert.Equal(t, []string{id.Recipient().String(), sshKey}, found)
+
+	recps, err := a.parseRecipients(ctx, []string{id.Recipient().String(), sshKey, "invalid"})
+	require.NoError(t, err)
+	assert.Len(t, recps, 2)
+}
@@ -0,0 +1,327 @@
+package age
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// DefaultAgentTTL is the default time the agent keeps the identities
+	// unlocked
+	DefaultAgentTTL = time.Hour
+
+	// agentTimeout limits a single request. It includes the time the user
+	// needs to enter the passphrase if the agent is locked.
+	agentTimeout = 2 * time.Minute
+)
+
+var (
+	// ErrAgentNotRunning is returned if no agent is listening on the socket
+	ErrAgentNotRunning = errors.New("age agent is not running")
+)
+
+// agentRequest is sent by gopass to the agent. Only the ciphertext is ever
+// transmitted, never the passphrase or the identities.
+type agentRequest struct {
+	Op   string `json:"op"`
+	Data []byte `json:"data,omitempty"`
+}
+
+type agentResponse struct {
+	Data  []byte `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// AgentSocket returns the location of the agent socket
+func AgentSocket() string {
+	return filepath.Join(appdir.UserCache(), "age-agent", "agent.sock")
+}
+
+// Activity is told about the decryptions of the agent, e.g. to lock it after
+// a period of inactivity
+type Activity interface {
+	Begin()
+	End()
+}
+
+// Agent holds the unlocked native identities of the age keyring in locked
+// memory and decrypts secrets for other gopass processes of the same user
+type Agent struct {
+	a      *Age
+	socket string
+	ttl    time.Duration
+	act    Activity
+
+	mu    sync.Mutex
+	ids   *lockedBuffer
+	timer *time.Timer
+}
+
+// NewAgent creates a new agent for the keyring of the given backend. The
+// identities are purged ttl after they have been unlocked, zero keeps them
+// until the agent is locked.
+func NewAgent(a *Age, ttl time.Duration) *Agent {
+	return &Agent{
+		a:      a,
+		socket: a.socket,
+		ttl:    ttl,
+	}
+}
+
+// SetActivity sets the receiver of the begin and end of every decryption
+func (ag *Agent) SetActivity(act Activity) {
+	ag.act = act
+}
+
+// Lock purges the unlocked identities. The next decryption asks for the
+// passphrase again.
+func (ag *Agent) Lock() {
+	ag.purge()
+}
+
+// Run listens on the agent socket until the context is canceled
+func (ag *Agent) Run(ctx context.Context) error {
+	if err := agentSupported(); err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(ag.socket)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return fmt.Errorf("failed to restrict access to %s: %w", dir, err)
+	}
+	if err := newAgentClient(ag.socket).ping(); err == nil {
+		return fmt.Errorf("an agent is already listening on %s", ag.socket)
+	}
+	// remove a stale socket left by an agent that was killed
+	_ = os.Remove(ag.socket)
+
+	l, err := net.Listen("unix", ag.socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ag.socket, err)
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+	defer ag.purge()
+
+	if err := os.Chmod(ag.socket, 0600); err != nil {
+		return fmt.Errorf("failed to restrict access to %s: %w", ag.socket, err)
+	}
+	debug.Log("age agent listening on %s", ag.socket)
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go ag.serve(ctx, conn)
+	}
+}
+
+func (ag *Agent) serve(ctx context.Context, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if err := checkPeer(conn); err != nil {
+		debug.Log("rejected connection: %s", err)
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(agentTimeout))
+
+	var req agentRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		debug.Log("failed to read request: %s", err)
+		return
+	}
+
+	var resp agentResponse
+	switch req.Op {
+	case "ping":
+	case "lock":
+		ag.purge()
+	case "decrypt":
+		if ag.act != nil {
+			ag.act.Begin()
+		}
+		plaintext, err := ag.decrypt(ctx, req.Data)
+		if ag.act != nil {
+			ag.act.End()
+		}
+		if err != nil {
+			resp.Error = err.Error()
+			break
+		}
+		resp.Data = plaintext
+	default:
+		resp.Error = fmt.Sprintf("unknown request %q", req.Op)
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		debug.Log("failed to send response: %s", err)
+	}
+}
+
+func (ag *Agent) decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	ids, err := ag.identities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ag.a.decrypt(ciphertext, ids...)
+}
+
+// identities unlocks the keyring, if necessary, and returns the native and
+// SSH identities
+func (ag *Agent) identities(ctx context.Context) ([]age.Identity, error) {
+	ag.mu.Lock()
+	defer ag.mu.Unlock()
+
+	if ag.ids == nil {
+		kr, err := ag.a.loadKeyring(ctx)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		lines := make([][]byte, 0, len(kr))
+		for _, k := range kr {
+			lines = append(lines, []byte(k.Identity))
+		}
+		buf, err := newLockedBuffer(bytes.Join(lines, []byte("\n")))
+		if err != nil {
+			return nil, err
+		}
+		ag.ids = buf
+		// the in-process caches are not locked, drop the passphrase
+		ag.a.Lock()
+		if ag.ttl > 0 {
+			ag.timer = time.AfterFunc(ag.ttl, ag.purge)
+		}
+		debug.Log("unlocked %d identities", len(kr))
+	}
+
+	ids := make([]age.Identity, 0, 4)
+	for _, line := range bytes.Split(ag.ids.Bytes(), []byte("\n")) {
+		if len(line) < 1 {
+			continue
+		}
+		id, err := age.ParseX25519Identity(string(line))
+		if err != nil {
+			debug.Log("Failed to parse identity: %s", err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	ssh, err := ag.a.getSSHIdentities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ssh {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// purge wipes the unlocked identities
+func (ag *Agent) purge() {
+	ag.mu.Lock()
+	defer ag.mu.Unlock()
+
+	if ag.timer != nil {
+		ag.timer.Stop()
+		ag.timer = nil
+	}
+	if ag.ids != nil {
+		ag.ids.Destroy()
+		ag.ids = nil
+		debug.Log("purged identities")
+	}
+	ag.a.Lock()
+}
+
+// unlocked returns true if the agent holds unlocked identities
+func (ag *Agent) unlocked() bool {
+	ag.mu.Lock()
+	defer ag.mu.Unlock()
+
+	return ag.ids != nil
+}
+
+// AgentLock purges the identities held by the agent. It returns
+// ErrAgentNotRunning if there is no agent.
+func (a *Age) AgentLock() error {
+	_, err := newAgentClient(a.socket).call(agentRequest{Op: "lock"})
+	return err
+}
+
+// AgentRunning returns true if an agent is listening on the socket
+func (a *Age) AgentRunning() bool {
+	return newAgentClient(a.socket).ping() == nil
+}
+
+type agentClient struct {
+	socket string
+}
+
+func newAgentClient(socket string) *agentClient {
+	return &agentClient{socket: socket}
+}
+
+func (c *agentClient) ping() error {
+	_, err := c.call(agentRequest{Op: "ping"})
+	return err
+}
+
+func (c *agentClient) decrypt(ciphertext []byte) ([]byte, error) {
+	return c.call(agentRequest{Op: "decrypt", Data: ciphertext})
+}
+
+func (c *agentClient) call(req agentRequest) ([]byte, error) {
+	conn, err := net.DialTimeout("unix", c.socket, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotRunning, err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// make sure we are not sending secrets to an agent of another user
+	if err := checkPeer(conn); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotRunning, err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(agentTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request to the age agent: %w", err)
+	}
+	var resp agentResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response from the age agent: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("age agent: %s", resp.Error)
+	}
+	return resp.Data, nil
+}
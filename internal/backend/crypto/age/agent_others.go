@@ -0,0 +1,32 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package age
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+func agentSupported() error {
+	return fmt.Errorf("the age agent is not supported on %s", runtime.GOOS)
+}
+
+// checkPeer always fails, the peer credentials can not be checked on this
+// platform
+func checkPeer(conn net.Conn) error {
+	return agentSupported()
+}
+
+type lockedBuffer struct{}
+
+func newLockedBuffer(data []byte) (*lockedBuffer, error) {
+	return nil, agentSupported()
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	return nil
+}
+
+func (b *lockedBuffer) Destroy() {}
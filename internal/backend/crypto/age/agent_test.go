@@ -0,0 +1,131 @@
+package age
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAgent returns a backend with a passphrase protected keyring, the
+// recipient of its identity and an agent using the same keyring
+func newTestAgent(t *testing.T, ttl time.Duration) (*Age, string, *Agent) {
+	t.Helper()
+
+	ctx := context.Background()
+	a := newTestAge(t)
+	a.socket = filepath.Join(filepath.Dir(filepath.Dir(a.keyring)), "agent", "agent.sock")
+	require.NoError(t, a.GenerateIdentity(ctx, "Alice", "alice@example.org", "passphrase"))
+	ids, err := a.ListIdentities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	return a, ids[0], NewAgent(a, ttl)
+}
+
+func runTestAgent(t *testing.T, ag *Agent) {
+	t.Helper()
+
+	if err := agentSupported(); err != nil {
+		t.Skip(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ag.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	require.Eventually(t, func() bool {
+		return newAgentClient(ag.socket).ping() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestAgentFallback(t *testing.T) {
+	ctx := ctxutil.WithAgeAgent(context.Background(), true)
+	a, recp, _ := newTestAgent(t, time.Hour)
+
+	assert.False(t, a.AgentRunning())
+	assert.ErrorIs(t, a.AgentLock(), ErrAgentNotRunning)
+
+	// no agent, the keyring is unlocked in this process
+	buf, err := a.Encrypt(ctx, []byte("secret"), []string{recp})
+	require.NoError(t, err)
+	plain, err := a.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+}
+
+func TestAgent(t *testing.T) {
+	ctx := ctxutil.WithAgeAgent(context.Background(), true)
+	a, recp, ag := newTestAgent(t, time.Hour)
+	runTestAgent(t, ag)
+
+	fi, err := os.Stat(ag.socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	fi, err = os.Stat(filepath.Dir(ag.socket))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), fi.Mode().Perm())
+
+	assert.True(t, a.AgentRunning())
+	assert.Error(t, NewAgent(a, time.Hour).Run(context.Background()), "second agent")
+
+	buf, err := a.Encrypt(ctx, []byte("secret"), []string{recp})
+	require.NoError(t, err)
+	plain, err := a.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+	assert.True(t, ag.unlocked())
+
+	// the agent dropped the cached passphrase after unlocking, so every
+	// further decrypt must go through the agent
+	_, found := a.askPass.cache.Get(a.keyring)
+	assert.False(t, found)
+	plain, err = a.Decrypt(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+
+	// after locking the agent needs the passphrase again. There is no
+	// pinentry in tests so it fails instead of falling back.
+	require.NoError(t, a.AgentLock())
+	assert.False(t, ag.unlocked())
+	_, err = a.Decrypt(ctx, buf)
+	assert.Error(t, err)
+}
+
+func TestAgentTTL(t *testing.T) {
+	ctx := ctxutil.WithAgeAgent(context.Background(), true)
+	a, recp, ag := newTestAgent(t, 50*time.Millisecond)
+	runTestAgent(t, ag)
+
+	buf, err := a.Encrypt(ctx, []byte("secret"), []string{recp})
+	require.NoError(t, err)
+	_, err = a.Decrypt(ctx, buf)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return !ag.unlocked()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLockedBuffer(t *testing.T) {
+	if err := agentSupported(); err != nil {
+		t.Skip(err)
+	}
+
+	b, err := newLockedBuffer([]byte("AGE-SECRET-KEY-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "AGE-SECRET-KEY-1", string(b.Bytes()))
+	b.Destroy()
+	assert.Len(t, b.Bytes(), 0)
+}
@@ -3,11 +3,13 @@ package age
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/pinentry/cli"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/gopasspw/pinentry"
 )
 
@@ -55,12 +57,26 @@ func (a *askPass) Ping(_ context.Context) error {
 }
 
 func (a *askPass) Passphrase(key string, reason string, repeat bool) (string, error) {
+	// for scripts and tests, there is no way to pass the passphrase otherwise
+	if pw := os.Getenv("GOPASS_AGE_PASSWORD"); pw != "" {
+		debug.Log("Using the passphrase from GOPASS_AGE_PASSWORD")
+		return pw, nil
+	}
 	if value, found := a.cache.Get(key); found || a.testing {
 		debug.Log("Read value for %s from cache", key)
 		return value, nil
 	}
 	debug.Log("Value for %s not found in cache", key)
 
+	if termio.HasAskpass() {
+		pass, err := termio.AskForPassword(context.TODO(), "passphrase "+reason, repeat)
+		if err != nil {
+			return "", err
+		}
+		a.cache.Set(key, pass)
+		return pass, nil
+	}
+
 	pi, err := a.pinentry()
 	if err != nil {
 		return "", fmt.Errorf("pinentry (%s) error: %w", pinentry.GetBinary(), err)
@@ -3,17 +3,61 @@ package age
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
 	"filippo.io/age"
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
 // Decrypt will attempt to decrypt the given payload
 func (a *Age) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if ctxutil.IsAgeAgent(ctx) {
+		plaintext, err := newAgentClient(a.socket).decrypt(ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		if !errors.Is(err, ErrAgentNotRunning) {
+			return nil, err
+		}
+		debug.Log("decrypting without the agent: %s", err)
+	}
+
+	ids, err := a.identities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	debug.Debug("decrypting", "identities", len(ids))
+	return a.decrypt(ciphertext, ids...)
+}
+
+// DecryptStream decrypts the ciphertext read from r and writes the plaintext
+// to w. The agent only decrypts whole ciphertexts, so with the agent enabled
+// the ciphertext is buffered.
+func (a *Age) DecryptStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	if ctxutil.IsAgeAgent(ctx) {
+		return backend.DecryptBuffered(ctx, a, r, w)
+	}
+
+	ids, err := a.identities(ctx)
+	if err != nil {
+		return err
+	}
+	dec, err := age.Decrypt(r, ids...)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, dec)
+	return err
+}
+
+// identities returns all identities of the keyring, asking for its
+// passphrase if necessary
+func (a *Age) identities(ctx context.Context) ([]age.Identity, error) {
 	if !ctxutil.HasPasswordCallback(ctx) {
 		debug.Log("no password callback found, redirecting to askPass")
 		ctx = ctxutil.WithPasswordCallback(ctx, func(prompt string, _ bool) ([]byte, error) {
@@ -21,11 +65,7 @@ func (a *Age) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
 			return []byte(pw), err
 		})
 	}
-	ids, err := a.getAllIds(ctx)
-	if err != nil {
-		return nil, err
-	}
-	return a.decrypt(ciphertext, ids...)
+	return a.getAllIds(ctx)
 }
 
 func (a *Age) decrypt(ciphertext []byte, ids ...age.Identity) ([]byte, error) {
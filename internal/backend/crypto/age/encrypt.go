@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"filippo.io/age"
@@ -13,7 +14,35 @@ import (
 
 // Encrypt will encrypt the given payload
 func (a *Age) Encrypt(ctx context.Context, plaintext []byte, recipients []string) ([]byte, error) {
-	// add our own public key
+	recp, err := a.recipients(ctx, recipients)
+	if err != nil {
+		return nil, err
+	}
+	debug.Debug("encrypting", "recipients", len(recp))
+	return a.encrypt(plaintext, recp...)
+}
+
+// EncryptStream encrypts the plaintext read from r and writes the ciphertext
+// to w, without holding either in memory
+func (a *Age) EncryptStream(ctx context.Context, r io.Reader, recipients []string, w io.Writer) error {
+	recp, err := a.recipients(ctx, recipients)
+	if err != nil {
+		return err
+	}
+	enc, err := age.Encrypt(w, recp...)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(enc, r)
+	if err != nil {
+		return err
+	}
+	debug.Log("Wrote %d bytes of plaintext for %+v", n, recp)
+	return enc.Close()
+}
+
+// recipients parses the recipients and adds our own public key
+func (a *Age) recipients(ctx context.Context, recipients []string) ([]age.Recipient, error) {
 	pks, err := a.pkself(ctx)
 	if err != nil {
 		return nil, err
@@ -23,8 +52,7 @@ func (a *Age) Encrypt(ctx context.Context, plaintext []byte, recipients []string
 		return nil, err
 	}
 	recp = append(recp, pks)
-	recp = dedupe(recp)
-	return a.encrypt(plaintext, recp...)
+	return dedupe(recp), nil
 }
 
 // dedupe the recipients, only works for native age recipients
@@ -80,3 +108,36 @@ func (a *Age) encryptFile(ctx context.Context, filename string, plaintext []byte
 
 	return os.WriteFile(filename, buf, 0600)
 }
+
+// ciphertextHeader is the first line of every binary age file
+const ciphertextHeader = "age-encryption.org/v1\n"
+
+// IsRecipient returns true if r is a public key age can encrypt for, i.e. a
+// native age or SSH public key
+func IsRecipient(r string) bool {
+	_, err := parseRecipient(r)
+	return err == nil
+}
+
+// IsCiphertext returns true if buf starts like a binary age file
+func IsCiphertext(buf []byte) bool {
+	return bytes.HasPrefix(buf, []byte(ciphertextHeader))
+}
+
+// EncryptFor encrypts the plaintext for the given public keys only. Unlike
+// Encrypt it doesn't add our own key, e.g. to share a secret with someone who
+// is not a recipient of the store.
+func EncryptFor(plaintext []byte, recipients ...string) ([]byte, error) {
+	recp := make([]age.Recipient, 0, len(recipients))
+	for _, r := range recipients {
+		id, err := parseRecipient(r)
+		if err != nil {
+			return nil, err
+		}
+		recp = append(recp, id)
+	}
+	if len(recp) < 1 {
+		return nil, fmt.Errorf("no recipients")
+	}
+	return (&Age{}).encrypt(plaintext, recp...)
+}
@@ -1,8 +1,10 @@
 package age
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"fmt"
+	"io"
 	"sort"
 	"testing"
 
@@ -36,6 +38,35 @@ func TestDedupe(t *testing.T) {
 	assert.Equal(t, want, out)
 }
 
+func TestEncryptFor(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	assert.True(t, IsRecipient(id.Recipient().String()))
+	assert.False(t, IsRecipient("0xDEADBEEF"))
+
+	ct, err := EncryptFor([]byte("secret"), id.Recipient().String())
+	require.NoError(t, err)
+	assert.True(t, IsCiphertext(ct))
+	assert.False(t, IsCiphertext([]byte("secret")))
+
+	r, err := age.Decrypt(bytes.NewReader(ct), id)
+	require.NoError(t, err)
+	pt, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(pt))
+
+	_, err = age.Decrypt(bytes.NewReader(ct), other)
+	assert.Error(t, err, "only encrypted for the given key")
+
+	_, err = EncryptFor([]byte("secret"), "0xDEADBEEF")
+	assert.Error(t, err)
+	_, err = EncryptFor([]byte("secret"))
+	assert.Error(t, err)
+}
+
 type Recipients []age.Recipient
 
 func (r Recipients) Len() int {
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -22,6 +23,10 @@ func (a *Age) getPublicKeysGithub(ctx context.Context, user string) ([]string, e
 		return pk, nil
 	}
 
+	if ctxutil.IsNoNetwork(ctx) {
+		return nil, fmt.Errorf("can not fetch the keys of %s: network access disabled", user)
+	}
+
 	keys, err := githubListKeys(ctx, user)
 	if err != nil {
 		return nil, err
@@ -41,20 +41,22 @@ func (a *Age) pkself(ctx context.Context) (age.Recipient, error) {
 
 func (a *Age) genKey(ctx context.Context) (*age.X25519Identity, error) {
 	debug.Log("No native age key found. Generating ...")
-	id, err := a.generateIdentity(ctx, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil))
+	id, err := a.generateIdentity(ctx, termio.DetectName(ctx, nil), termio.DetectEmail(ctx, nil), "")
 	if err != nil {
 		return nil, err
 	}
 	return id, nil
 }
 
-// GenerateIdentity will create a new native private key
-func (a *Age) GenerateIdentity(ctx context.Context, name, email, _ string) error {
-	_, err := a.generateIdentity(ctx, name, email)
+// GenerateIdentity will create a new native private key. The passphrase
+// protects the keyring if it does not exist, yet. Otherwise the new key is
+// added to the existing keyring.
+func (a *Age) GenerateIdentity(ctx context.Context, name, email, passphrase string) error {
+	_, err := a.generateIdentity(ctx, name, email, passphrase)
 	return err
 }
 
-func (a *Age) generateIdentity(ctx context.Context, name, email string) (*age.X25519Identity, error) {
+func (a *Age) generateIdentity(ctx context.Context, name, email, passphrase string) (*age.X25519Identity, error) {
 	id, err := age.GenerateX25519Identity()
 	if err != nil {
 		return id, err
@@ -71,13 +73,44 @@ func (a *Age) generateIdentity(ctx context.Context, name, email string) (*age.X2
 		newKeyring = true
 	}
 
+	if newKeyring && passphrase != "" {
+		ctx = ctxutil.WithPasswordCallback(ctx, func(string, bool) ([]byte, error) {
+			return []byte(passphrase), nil
+		})
+		// avoid asking for the passphrase we were just given
+		a.askPass.cache.Set(a.keyring, passphrase)
+	}
+
 	kr = append(kr, Keypair{
 		Name:     name,
 		Email:    email,
 		Identity: id.String(),
 	})
 
-	return id, a.saveKeyring(ctx, kr, newKeyring)
+	if err := a.saveKeyring(ctx, kr, newKeyring); err != nil {
+		return nil, err
+	}
+	a.krCache = nil
+	return id, nil
+}
+
+// migrateKeyring moves the keyring from the location used by earlier
+// versions
+func (a *Age) migrateKeyring(old string) error {
+	if _, err := os.Stat(a.keyring); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(old); err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(a.keyring), 0700); err != nil {
+		return fmt.Errorf("failed to create directory for the keyring at %s: %w", a.keyring, err)
+	}
+	if err := os.Rename(old, a.keyring); err != nil {
+		return fmt.Errorf("failed to move the keyring from %s to %s: %w", old, a.keyring, err)
+	}
+	debug.Log("moved keyring from %s to %s", old, a.keyring)
+	return nil
 }
 
 func (a *Age) loadKeyring(ctx context.Context) (Keyring, error) {
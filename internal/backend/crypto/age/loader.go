@@ -24,7 +24,7 @@ func (l loader) New(ctx context.Context) (backend.Crypto, error) {
 }
 
 func (l loader) Handles(s backend.Storage) error {
-	if s.Exists(context.TODO(), IDFile) {
+	if s.Exists(context.TODO(), IDFile) || s.Exists(context.TODO(), OldIDFile) {
 		return nil
 	}
 	return fmt.Errorf("not supported")
@@ -0,0 +1,53 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package age
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockedBuffer is a buffer outside of the Go heap that is excluded from
+// swapping
+type lockedBuffer struct {
+	buf []byte
+	n   int
+}
+
+func newLockedBuffer(data []byte) (*lockedBuffer, error) {
+	size := os.Getpagesize()
+	if len(data) > size {
+		size = ((len(data) + size - 1) / size) * size
+	}
+
+	buf, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate memory: %w", err)
+	}
+	if err := unix.Mlock(buf); err != nil {
+		_ = unix.Munmap(buf)
+		return nil, fmt.Errorf("failed to lock memory: %w", err)
+	}
+
+	copy(buf, data)
+	return &lockedBuffer{buf: buf, n: len(data)}, nil
+}
+
+// Bytes returns the content of the buffer. It must not be used after Destroy.
+func (b *lockedBuffer) Bytes() []byte {
+	return b.buf[:b.n]
+}
+
+// Destroy wipes and releases the buffer
+func (b *lockedBuffer) Destroy() {
+	for i := range b.buf {
+		b.buf[i] = 0
+	}
+	_ = unix.Munlock(b.buf)
+	_ = unix.Munmap(b.buf)
+	b.buf = nil
+	b.n = 0
+}
@@ -2,6 +2,7 @@ package age
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,6 +29,10 @@ func (a *Age) getSSHIdentities(ctx context.Context) (map[string]age.Identity, er
 	sshDir := filepath.Join(uhd, ".ssh")
 	files, err := os.ReadDir(sshDir)
 	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			sshCache = map[string]age.Identity{}
+			return sshCache, nil
+		}
 		return nil, err
 	}
 	ids := make(map[string]age.Identity, len(files))
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -29,28 +30,27 @@ func (a *Age) FindIdentities(ctx context.Context, keys ...string) ([]string, err
 	return matches, nil
 }
 
-// FindRecipients is TODO
+// FindRecipients returns all keys that are valid age or SSH public keys.
+// GitHub users (github:user) are replaced by their SSH public keys.
 func (a *Age) FindRecipients(ctx context.Context, keys ...string) ([]string, error) {
-	// TODO should not need to decrypt keyring
-	remote := make([]string, 0, len(keys))
-	local := make([]string, 0, len(keys))
+	out := make([]string, 0, len(keys))
 	for _, key := range keys {
-		if !strings.HasPrefix(key, "github:") {
-			local = append(local, key)
+		if strings.HasPrefix(key, "github:") {
+			pks, err := a.getPublicKeysGithub(ctx, strings.TrimPrefix(key, "github:"))
+			if err != nil {
+				debug.Log("Failed to get key %s from github: %s", key, err)
+				continue
+			}
+			out = append(out, pks...)
 			continue
 		}
-		pks, err := a.getPublicKeysGithub(ctx, strings.TrimPrefix(key, "github:"))
-		if err != nil {
-			debug.Log("Failed to get key %s from github: %s", key, err)
+		if _, err := parseRecipient(key); err != nil {
+			debug.Log("Invalid recipient %s: %s", key, err)
 			continue
 		}
-		remote = append(remote, pks...)
+		out = append(out, key)
 	}
-	ids, err := a.FindIdentities(ctx, local...)
-	if err != nil {
-		return nil, err
-	}
-	return append(ids, remote...), nil
+	return out, nil
 }
 
 // FormatKey returns the key id
@@ -63,9 +63,10 @@ func (a *Age) Fingerprint(ctx context.Context, id string) string {
 	return id
 }
 
-// ListRecipients is not supported for the age backend
-func (a *Age) ListRecipients(context.Context) ([]string, error) {
-	return nil, fmt.Errorf("not implemented")
+// ListRecipients returns the recipients of all identities. There is no
+// keyring of public keys, these are the only ones known.
+func (a *Age) ListRecipients(ctx context.Context) ([]string, error) {
+	return a.ListIdentities(ctx)
 }
 
 // ReadNamesFromKey is not supported for the age backend
@@ -75,5 +76,5 @@ func (a *Age) ReadNamesFromKey(ctx context.Context, buf []byte) ([]string, error
 
 // RecipientIDs is not supported for the age backend
 func (a *Age) RecipientIDs(ctx context.Context, buf []byte) ([]string, error) {
-	return nil, fmt.Errorf("reading recipient IDs is not supported by the age backend by design")
+	return nil, fmt.Errorf("reading recipient IDs is not supported by the age backend by design: %w", backend.ErrNotSupported)
 }
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// ErrAgentNotRunning is returned if there is no gpg-agent to ask. It's never
+// started just to be asked about its cache.
+var ErrAgentNotRunning = errors.New("gpg-agent is not running")
+
+// agentNotRunning is printed by gpg-connect-agent --no-autostart if there is
+// no agent
+const agentNotRunning = "no gpg-agent running"
+
+// AgentKey is a secret key and whether the gpg-agent has cached the
+// passphrase of the key or one of its subkeys
+type AgentKey struct {
+	Key    gpg.Key
+	Cached bool
+	// Keygrips of the key and its subkeys with a cached passphrase
+	Keygrips []string
+}
+
+// AgentStatus returns the cache state of each secret key. It uses the
+// gpg-agent of the GNUPGHOME of this instance and fails with
+// ErrAgentNotRunning if there is none.
+func (g *GPG) AgentStatus(ctx context.Context) ([]AgentKey, error) {
+	kl, err := g.listKeys(ctx, "secret")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the secret keys: %w", err)
+	}
+	lines, err := g.agent(ctx, "KEYINFO --list")
+	if err != nil {
+		return nil, err
+	}
+	cached := cachedKeygrips(lines)
+
+	keys := make([]AgentKey, 0, len(kl))
+	for _, k := range kl {
+		ak := AgentKey{Key: k}
+		for _, grip := range keygrips(k) {
+			if cached[grip] {
+				ak.Keygrips = append(ak.Keygrips, grip)
+			}
+		}
+		ak.Cached = len(ak.Keygrips) > 0
+		keys = append(keys, ak)
+	}
+	return keys, nil
+}
+
+// AgentClear makes the gpg-agent forget the cached passphrases of the secret
+// keys and returns how many it has forgotten. With all the agent is reloaded
+// instead, so it forgets all passphrases, including those of keys that are
+// used by other programs only.
+func (g *GPG) AgentClear(ctx context.Context, all bool) (int, error) {
+	keys, err := g.AgentStatus(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var cmds []string
+	for _, k := range keys {
+		for _, grip := range k.Keygrips {
+			cmds = append(cmds, "CLEAR_PASSPHRASE --mode=normal "+grip)
+		}
+	}
+	if all {
+		cmds = []string{"RELOADAGENT"}
+	}
+	if len(cmds) < 1 {
+		return 0, nil
+	}
+	if _, err := g.agent(ctx, cmds...); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, k := range keys {
+		n += len(k.Keygrips)
+	}
+	return n, nil
+}
+
+// agent sends the commands to the gpg-agent and returns the status lines of
+// the responses, without the leading "S ". The agent is not started if it's
+// not running.
+func (g *GPG) agent(ctx context.Context, cmds ...string) ([]string, error) {
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+
+	args := append([]string{"--no-autostart"}, cmds...)
+	cmd := exec.CommandContext(ctx, g.connectAgentBinary(), append(args, "/bye")...)
+	if g.home != "" {
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+g.home)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	done := debug.Exec(cmd)
+	buf, err := cmd.Output()
+	done(err, stderr.String())
+	if strings.Contains(stderr.String(), agentNotRunning) {
+		return nil, ErrAgentNotRunning
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w: %s", cmd.Path, ctxutil.ExecError(ctx, err), strings.TrimSpace(stderr.String()))
+	}
+
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case strings.HasPrefix(line, "ERR "):
+			return nil, fmt.Errorf("gpg-agent failed: %s", strings.TrimPrefix(line, "ERR "))
+		case strings.HasPrefix(line, "S "):
+			lines = append(lines, strings.TrimPrefix(line, "S "))
+		}
+	}
+	return lines, nil
+}
+
+// cachedKeygrips returns the keygrips of the keys with a cached passphrase
+// from the response to KEYINFO --list. Each line is
+//
+//	KEYINFO <keygrip> <type> <serialno> <idstr> <cached> <protection> ...
+//
+// where cached is 1 if the passphrase is cached.
+func cachedKeygrips(lines []string) map[string]bool {
+	cached := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[0] != "KEYINFO" {
+			continue
+		}
+		if fields[5] == "1" {
+			cached[fields[1]] = true
+		}
+	}
+	return cached
+}
+
+// keygrips returns the keygrips of the key and its subkeys, if it was listed
+// with --with-keygrip
+func keygrips(k gpg.Key) []string {
+	grips := make([]string, 0, len(k.SubKeys)+1)
+	if k.Keygrip != "" {
+		grips = append(grips, k.Keygrip)
+	}
+	for _, sk := range k.SubKeys {
+		if sk.Keygrip != "" {
+			grips = append(grips, sk.Keygrip)
+		}
+	}
+	sort.Strings(grips)
+	return grips
+}
@@ -0,0 +1,109 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnectAgent returns a gpg listing a secret key with a subkey and a
+// gpg-connect-agent reporting the passphrase of the subkey as cached until it
+// is cleared. The agent logs its arguments to agent.log in the returned dir.
+func fakeConnectAgent(t *testing.T) (*GPG, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	bin := filepath.Join(td, "gpg")
+	require.NoError(t, os.WriteFile(bin, []byte(`#!/bin/sh
+cat <<COLONS
+sec:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC:::+:::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+grp:::::::::0B5B6E1F8C5A04B8A5C2E9F3D1B7A6C4E2F0D8B1:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb:u:255:18:921948EA957021AC:1577880000::::::e:::+:::cv25519::
+fpr:::::::::4B38904832BDA8D1A74C710B921948EA957021AC:
+grp:::::::::7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D:
+COLONS
+`), 0755))
+
+	cleared := filepath.Join(td, "cleared")
+	stopped := filepath.Join(td, "stopped")
+	agent := filepath.Join(td, "gpg-connect-agent")
+	require.NoError(t, os.WriteFile(agent, []byte(`#!/bin/sh
+echo "$*" >> `+filepath.Join(td, "agent.log")+`
+if [ -f `+stopped+` ]; then
+	echo "gpg-connect-agent: no gpg-agent running in this session" >&2
+	exit 1
+fi
+case "$*" in
+*KEYINFO*)
+	cached=1
+	[ -f `+cleared+` ] && cached=-
+	echo "S KEYINFO 0B5B6E1F8C5A04B8A5C2E9F3D1B7A6C4E2F0D8B1 D - - - P - - -"
+	echo "S KEYINFO 7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D D - - $cached P - - -"
+	echo "S KEYINFO FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF D - - 1 P - - -"
+	;;
+*CLEAR_PASSPHRASE*|*RELOADAGENT*)
+	touch `+cleared+`
+	;;
+esac
+echo OK
+`), 0755))
+
+	lc, err := lru.New2Q(16)
+	require.NoError(t, err)
+	return &GPG{binary: bin, connectAgent: agent, listCache: lc}, td
+}
+
+func TestAgentStatus(t *testing.T) {
+	ctx := ctxutil.WithNoKeyCache(context.Background(), true)
+	g, td := fakeConnectAgent(t)
+	log := filepath.Join(td, "agent.log")
+
+	keys, err := g.AgentStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "0xA4B0BF3E1195AE66", keys[0].Key.ID())
+	assert.True(t, keys[0].Cached)
+	assert.Equal(t, []string{"7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D"}, keys[0].Keygrips)
+
+	// only the passphrases of our keys are cleared
+	n, err := g.AgentClear(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	lb, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(lb), "--no-autostart CLEAR_PASSPHRASE --mode=normal 7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D /bye\n")
+	assert.NotContains(t, string(lb), "CLEAR_PASSPHRASE --mode=normal FFFF")
+
+	keys, err = g.AgentStatus(ctx)
+	require.NoError(t, err)
+	assert.False(t, keys[0].Cached)
+	n, err = g.AgentClear(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// all reloads the agent
+	_, err = g.AgentClear(ctx, true)
+	require.NoError(t, err)
+	lb, err = os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Contains(t, string(lb), "--no-autostart RELOADAGENT /bye\n")
+
+	// the agent is not started
+	require.NoError(t, os.WriteFile(filepath.Join(td, "stopped"), nil, 0600))
+	_, err = g.AgentStatus(ctx)
+	assert.True(t, errors.Is(err, ErrAgentNotRunning), err)
+	_, err = g.AgentClear(ctx, true)
+	assert.True(t, errors.Is(err, ErrAgentNotRunning), err)
+}
@@ -20,11 +20,25 @@ func detectBinary(name string) (string, error) {
 	p, err := gpgconf.Path("gpg")
 	if err != nil || p == "" || !fsutil.IsFile(p) {
 		debug.Log("gpgconf failed (%q), falling back to path lookup: %q", p, err)
-		// otherwise fall back to the default and try
-		// to look up "gpg"
-		return exec.LookPath("gpg")
+		// otherwise fall back to the default and try to look up "gpg2"
+		// first, since "gpg" might still be GnuPG 1.x on some systems
+		return lookPath("gpg2", "gpg")
 	}
 
 	debug.Log("gpgconf returned %q for gpg", p)
 	return p, nil
 }
+
+// lookPath returns the first of the given binaries found in PATH
+func lookPath(names ...string) (string, error) {
+	var err error
+	for _, name := range names {
+		var p string
+		p, err = exec.LookPath(name)
+		if err == nil {
+			return p, nil
+		}
+		debug.Log("%q not found: %s", name, err)
+	}
+	return "", err
+}
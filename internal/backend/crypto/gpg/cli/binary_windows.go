@@ -24,9 +24,13 @@ func detectBinary(bin string) (string, error) {
 	for _, b := range bins {
 		debug.Log("Looking for %q ...", b)
 		if p, err := exec.LookPath(b); err == nil {
+			ver, err := version(context.TODO(), p)
+			if err != nil {
+				debug.Log("failed to detect version of %q: %s", p, err)
+			}
 			gb := gpgBin{
 				path: p,
-				ver:  version(context.TODO(), p),
+				ver:  ver,
 			}
 			debug.Log("Found %q at %q (%s)", b, p, gb.ver.String())
 			bv = append(bv, gb)
@@ -4,23 +4,136 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
 )
 
-// Decrypt will try to decrypt the given file
+// Decrypt will try to decrypt the given file. If a passphrase file is set
+// (see PassphraseFileEnv) gpg is run in pinentry loopback mode. If the secret
+// key is on a smartcard that is not inserted the user is asked to insert it
+// and decryption is retried once.
 func (g *GPG) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
-	args := append(g.args, "--decrypt")
-	cmd := exec.CommandContext(ctx, g.binary, args...)
-	cmd.Stdin = bytes.NewReader(ciphertext)
-	cmd.Stderr = os.Stderr
+	if !hasDisplay() {
+		g.updateStartupTTY(ctx)
+	}
+	buf, err := g.decrypt(ctx, ciphertext)
+	if err == nil {
+		return buf, nil
+	}
+	if errors.Is(err, errPinentry) {
+		return g.decryptWithoutPinentry(ctx, ciphertext, err)
+	}
+	var nsk *backend.NoSecretKeyError
+	if errors.As(err, &nsk) {
+		return nil, err
+	}
+
+	k, found := g.cardKey(ctx, ciphertext)
+	if !found {
+		return buf, err
+	}
+	out.Printf(ctx, "secret key %s is on smartcard %s, please insert it", k.ID(), k.CardSerial)
+	if !ctxutil.IsInteractive(ctx) {
+		return nil, fmt.Errorf("secret key %s is on smartcard %s: %w", k.ID(), k.CardSerial, err)
+	}
+	if retry, _ := termio.AskForBool(ctx, "Retry after inserting the smartcard?", true); !retry {
+		return nil, fmt.Errorf("secret key %s is on smartcard %s: %w", k.ID(), k.CardSerial, err)
+	}
+	return g.decrypt(ctx, ciphertext)
+}
+
+// DecryptStream works like Decrypt but pipes the ciphertext read from r
+// through gpg and writes the plaintext to w, without holding either in
+// memory. Since r can only be read once a failed decryption is not retried,
+// e.g. after inserting a smartcard. The plaintext written to w must be
+// discarded if an error is returned, gpg only detects a modified ciphertext
+// at its end.
+func (g *GPG) DecryptStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	if !hasDisplay() {
+		g.updateStartupTTY(ctx)
+	}
+	return g.decryptTo(ctx, r, w, false)
+}
+
+func (g *GPG) decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := g.decryptTo(ctx, bytes.NewReader(ciphertext), buf, true); err != nil {
+		return buf.Bytes(), err
+	}
+	return buf.Bytes(), nil
+}
+
+// decryptTo runs gpg to decrypt r to w. With timeout set non-interactive
+// calls are timed out.
+func (g *GPG) decryptTo(ctx context.Context, r io.Reader, w io.Writer, timeout bool) error {
+	lbArgs, lb, err := g.loopbackArgs(ctx)
+	if err != nil {
+		return err
+	}
+	if lb == nil && gpg.IsNoPinentry(ctx) {
+		lbArgs = g.noPinentryArgs(ctx)
+	} else if pw := g.getPassphrase(); lb == nil && pw != nil {
+		lbArgs, lb = newLoopback(pw)
+	}
+	args := append(append(withStatus(g.args), lbArgs...), "--decrypt")
+
+	// the user may need a while to enter the passphrase or to touch the
+	// smartcard, only non-interactive calls are timed out
+	var cancel context.CancelFunc
+	if timeout && (lb != nil || gpg.IsNoPinentry(ctx)) {
+		ctx, cancel = ctxutil.WithLocalDeadline(ctx)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
 
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	return cmd.Output()
+	cmd := g.command(ctx, args...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	// the raw output of gpg is only shown with --verbose, the status lines
+	// are used to explain a failure
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if ctxutil.IsVerbose(ctx) {
+		cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
+	}
+
+	done := debug.Exec(cmd)
+	if lb == nil {
+		err = cmd.Run()
+	} else {
+		err = lb.run(cmd)
+	}
+	done(err, stderr.String())
+	if err == nil {
+		return nil
+	}
+	// gpg reports the keys as missing if the agent refused loopback mode
+	if errors.Is(err, ErrLoopbackDenied) {
+		return err
+	}
+	return g.decryptError(ctx, stderr.Bytes(), ctxutil.ExecError(ctx, err))
+}
+
+// noPinentryArgs returns the args to make gpg fail instead of asking for the
+// passphrase if the agent has not cached it
+func (g *GPG) noPinentryArgs(ctx context.Context) []string {
+	v, err := version(ctx, g.binary)
+	if err != nil || v.LT(loopbackVersion) {
+		debug.Log("gpg %s does not support --pinentry-mode error: %s", v, err)
+		return nil
+	}
+	return []string{"--batch", "--pinentry-mode", "error"}
 }
 
 // RecipientIDs returns a list of recipient IDs for a given file
@@ -29,13 +142,15 @@ func (g *GPG) RecipientIDs(ctx context.Context, buf []byte) ([]string, error) {
 	recp := make([]string, 0, 5)
 
 	args := []string{"--batch", "--list-only", "--list-packets", "--no-default-keyring", "--secret-keyring", "/dev/null"}
-	cmd := exec.CommandContext(ctx, g.binary, args...)
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
 	cmd.Stdin = bytes.NewReader(buf)
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-
+	done := debug.Exec(cmd)
 	cmdout, err := cmd.CombinedOutput()
+	done(err, "")
 	if err != nil {
-		return []string{}, err
+		return []string{}, ctxutil.ExecError(ctx, err)
 	}
 
 	scanner := bufio.NewScanner(bytes.NewBuffer(cmdout))
@@ -47,8 +162,15 @@ func (g *GPG) RecipientIDs(ctx context.Context, buf []byte) ([]string, error) {
 		}
 		m := splitPacket(line)
 		if keyid, found := m["keyid"]; found {
+			// hidden recipients (throw-keyids) can't be identified
+			if strings.Trim(keyid, "0") == "" {
+				continue
+			}
 			kl, err := g.listKeys(ctx, "public", keyid)
 			if err != nil || len(kl) < 1 {
+				// keep keys missing from our keyring, they might belong
+				// to recipients that have been removed
+				recp = append(recp, "0x"+strings.ToUpper(keyid))
 				continue
 			}
 			recp = append(recp, kl[0].Fingerprint)
@@ -61,3 +183,31 @@ func (g *GPG) RecipientIDs(ctx context.Context, buf []byte) ([]string, error) {
 	}
 	return recp, nil
 }
+
+// cardKey returns the secret key the ciphertext was encrypted for if that
+// key is only available on a smartcard. It returns false if any of the
+// recipients has its secret key material on disk, since decryption must
+// have failed for a different reason then.
+func (g *GPG) cardKey(ctx context.Context, ciphertext []byte) (gpg.Key, bool) {
+	rs, err := g.RecipientIDs(ctx, ciphertext)
+	if err != nil {
+		debug.Log("failed to get recipients: %s", err)
+		return gpg.Key{}, false
+	}
+
+	var card gpg.Key
+	for _, r := range rs {
+		kl, err := g.listKeys(ctx, "secret", r)
+		if err != nil || len(kl) < 1 {
+			continue
+		}
+		k := kl[0]
+		if k.HasSecretKeyMaterial() {
+			return gpg.Key{}, false
+		}
+		if k.CardSerial != "" && card.Fingerprint == "" {
+			card = k
+		}
+	}
+	return card, card.Fingerprint != ""
+}
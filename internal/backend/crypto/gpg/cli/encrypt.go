@@ -3,11 +3,12 @@ package cli
 import (
 	"bytes"
 	"context"
+	"io"
 	"os"
-	"os/exec"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -15,31 +16,63 @@ import (
 // the trust-model will be set to always as to avoid (annoying) "unusable public key"
 // errors when encrypting.
 func (g *GPG) Encrypt(ctx context.Context, plaintext []byte, recipients []string) ([]byte, error) {
-	args := append(g.args, "--encrypt")
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+
+	buf := &bytes.Buffer{}
+	err := g.encrypt(ctx, bytes.NewReader(plaintext), recipients, buf)
+	return buf.Bytes(), err
+}
+
+// EncryptStream works like Encrypt but pipes the plaintext read from r
+// through gpg and writes the ciphertext to w, without holding either in
+// memory. It's not timed out since the pace is set by r and w.
+func (g *GPG) EncryptStream(ctx context.Context, r io.Reader, recipients []string, w io.Writer) error {
+	return g.encrypt(ctx, r, recipients, w)
+}
+
+func (g *GPG) encrypt(ctx context.Context, r io.Reader, recipients []string, w io.Writer) error {
+	lbArgs, lb, err := g.loopbackArgs(ctx)
+	if err != nil {
+		return err
+	}
+	args := append(append(g.args, lbArgs...), "--encrypt")
 	if gpg.IsAlwaysTrust(ctx) {
 		// changing the trustmodel is possibly dangerous. A user should always
 		// explicitly opt-in to do this
 		args = append(args, "--trust-model=always")
 	}
-	for _, r := range recipients {
-		kl, err := g.listKeys(ctx, "public", r)
+	for _, id := range recipients {
+		kl, err := g.listKeys(ctx, "public", id)
 		if err != nil {
 			debug.Log("Failed to check key %s. Adding anyway. %s", err)
 		} else if len(kl.UseableKeys(gpg.IsAlwaysTrust(ctx))) < 1 {
-			out.Printf(ctx, "Not using invalid key %s for encryption. (Check its expiration date or its encryption capabilities.)", r)
+			out.Printf(ctx, "Not using invalid key %s for encryption. (Check its expiration date or its encryption capabilities.)", id)
 			continue
 		}
-		args = append(args, "--recipient", r)
+		args = append(args, "--recipient", id)
 	}
 
-	buf := &bytes.Buffer{}
-
-	cmd := exec.CommandContext(ctx, g.binary, args...)
-	cmd.Stdin = bytes.NewReader(plaintext)
-	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
+	cmd := g.command(ctx, args...)
+	cmd.Stdin = r
+	cmd.Stdout = w
+	// the raw output of gpg is only shown with --verbose, it's part of the
+	// error otherwise
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if ctxutil.IsVerbose(ctx) {
+		cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
+	}
 
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	err := cmd.Run()
-	return buf.Bytes(), err
+	done := debug.Exec(cmd)
+	if lb != nil {
+		err = lb.run(cmd)
+	} else {
+		err = cmd.Run()
+	}
+	done(err, stderr.String())
+	if err != nil {
+		return execError(ctx, err, stderr.String())
+	}
+	return nil
 }
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/colons"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// DefaultKeyserver is used to look up public keys if no keyserver is configured
+const DefaultKeyserver = "hkps://keys.openpgp.org"
+
+// maxKeySize is the maximum size of a public key we are willing to download
+const maxKeySize = 1 << 20
+
+// zbase32 is the alphabet used by z-base-32 (RFC 6189), as required by WKD
+const zbase32 = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+var (
+	// ErrKeyNotFound is returned if a public key could not be found
+	// via WKD or on the keyserver
+	ErrKeyNotFound = fmt.Errorf("public key not found")
+
+	httpClient = &http.Client{Timeout: 15 * time.Second}
+)
+
+// FetchPublicKey tries to download the public key with the given fingerprint
+// (or key ID). It will try a Web Key Directory lookup for each of the given
+// emails first and then fall back to the configured keyserver. The downloaded
+// key is checked to match the requested fingerprint before it's returned.
+// The key is not imported.
+func (g *GPG) FetchPublicKey(ctx context.Context, fingerprint string, emails ...string) ([]byte, error) {
+	if ctxutil.IsNoNetwork(ctx) {
+		return nil, fmt.Errorf("can not fetch key %s: network access disabled", fingerprint)
+	}
+
+	for _, email := range emails {
+		for _, u := range wkdURLs(email) {
+			buf, err := g.fetchKey(ctx, u, fingerprint)
+			if err != nil {
+				debug.Log("WKD lookup of %s at %s failed: %s", fingerprint, u, err)
+				continue
+			}
+			return buf, nil
+		}
+	}
+
+	u, err := keyserverURL(gpg.GetKeyserver(ctx), fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := g.fetchKey(ctx, u, fingerprint)
+	if err != nil {
+		debug.Log("keyserver lookup of %s at %s failed: %s", fingerprint, u, err)
+		return nil, fmt.Errorf("%s: %w", fingerprint, ErrKeyNotFound)
+	}
+	return buf, nil
+}
+
+// fetchKey downloads a key and makes sure it matches the requested fingerprint
+func (g *GPG) fetchKey(ctx context.Context, u, fingerprint string) ([]byte, error) {
+	buf, err := fetchURL(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	kl, err := g.ReadKeys(ctx, buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(kl.FindBy(fingerprint)) < 1 {
+		return nil, fmt.Errorf("downloaded key does not match %s", fingerprint)
+	}
+	return buf, nil
+}
+
+// ReadKeys parses the given public keys without importing them
+func (g *GPG) ReadKeys(ctx context.Context, buf []byte) (gpg.KeyList, error) {
+	if len(buf) < 1 {
+		return nil, fmt.Errorf("empty input")
+	}
+
+	args := append(g.args, "--with-colons", "--with-fingerprint", "--fixed-list-mode", "--import-options", "show-only", "--import")
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
+	cmd.Stdin = bytes.NewReader(buf)
+	var errBuf = bytes.Buffer{}
+	cmd.Stderr = &errBuf
+
+	done := debug.Exec(cmd)
+	cmdout, err := cmd.Output()
+	done(err, errBuf.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ctxutil.ExecError(ctx, err), errBuf.String())
+	}
+
+	kl := colons.Parse(bytes.NewBuffer(cmdout))
+	if len(kl) < 1 {
+		return nil, fmt.Errorf("no public keys found")
+	}
+	return kl, nil
+}
+
+func fetchURL(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	debug.Log("HTTP Request: %s", u)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed: %s", resp.Status)
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySize))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < 1 {
+		return nil, fmt.Errorf("empty response")
+	}
+	return buf, nil
+}
+
+// wkdURLs returns the Web Key Directory URLs for the given email, using the
+// advanced method first and the direct method second
+func wkdURLs(email string) []string {
+	p := strings.LastIndex(email, "@")
+	if p < 1 || p == len(email)-1 {
+		return nil
+	}
+	local := email[:p]
+	domain := strings.ToLower(email[p+1:])
+	sum := sha1.Sum([]byte(strings.ToLower(local)))
+	hash := zbase32Encode(sum[:])
+	q := "?l=" + url.QueryEscape(local)
+
+	return []string{
+		fmt.Sprintf("https://openpgpkey.%s/.well-known/openpgpkey/%s/hu/%s%s", domain, domain, hash, q),
+		fmt.Sprintf("https://%s/.well-known/openpgpkey/hu/%s%s", domain, hash, q),
+	}
+}
+
+// keyserverURL returns the HKP lookup URL for the given key on the given keyserver
+func keyserverURL(ks, fingerprint string) (string, error) {
+	if ks == "" {
+		ks = DefaultKeyserver
+	}
+	if !strings.Contains(ks, "://") {
+		ks = "hkps://" + ks
+	}
+
+	u, err := url.Parse(ks)
+	if err != nil {
+		return "", fmt.Errorf("invalid keyserver %q: %w", ks, err)
+	}
+	switch u.Scheme {
+	case "hkps", "https":
+		u.Scheme = "https"
+	case "hkp", "http":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			u.Host += ":11371"
+		}
+	default:
+		return "", fmt.Errorf("unsupported keyserver scheme %q", u.Scheme)
+	}
+
+	u.Path = "/pks/lookup"
+	u.RawQuery = url.Values{
+		"op":      []string{"get"},
+		"options": []string{"mr"},
+		"search":  []string{"0x" + strings.TrimPrefix(strings.ToUpper(fingerprint), "0X")},
+	}.Encode()
+	return u.String(), nil
+}
+
+// zbase32Encode encodes the given bytes using z-base-32
+func zbase32Encode(buf []byte) string {
+	var sb strings.Builder
+	var acc, bits uint
+	for _, b := range buf {
+		acc = acc<<8 | uint(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			sb.WriteByte(zbase32[(acc>>bits)&0x1f])
+		}
+	}
+	if bits > 0 {
+		sb.WriteByte(zbase32[(acc<<(5-bits))&0x1f])
+	}
+	return sb.String()
+}
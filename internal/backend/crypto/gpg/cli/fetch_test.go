@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWKDURLs(t *testing.T) {
+	// example taken from the WKD draft
+	assert.Equal(t, []string{
+		"https://openpgpkey.example.org/.well-known/openpgpkey/example.org/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe",
+		"https://example.org/.well-known/openpgpkey/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe",
+	}, wkdURLs("Joe.Doe@Example.ORG"))
+
+	for _, in := range []string{"", "foo", "@example.org", "foo@"} {
+		assert.Nil(t, wkdURLs(in), in)
+	}
+}
+
+func TestKeyserverURL(t *testing.T) {
+	for _, tc := range []struct {
+		ks   string
+		fp   string
+		want string
+	}{
+		{
+			fp:   "deadbeef",
+			want: "https://keys.openpgp.org/pks/lookup?op=get&options=mr&search=0xDEADBEEF",
+		},
+		{
+			ks:   "keyserver.ubuntu.com",
+			fp:   "0xDEADBEEF",
+			want: "https://keyserver.ubuntu.com/pks/lookup?op=get&options=mr&search=0xDEADBEEF",
+		},
+		{
+			ks:   "hkp://keys.example.org",
+			fp:   "DEADBEEF",
+			want: "http://keys.example.org:11371/pks/lookup?op=get&options=mr&search=0xDEADBEEF",
+		},
+		{
+			ks:   "http://localhost:8080",
+			fp:   "DEADBEEF",
+			want: "http://localhost:8080/pks/lookup?op=get&options=mr&search=0xDEADBEEF",
+		},
+	} {
+		got, err := keyserverURL(tc.ks, tc.fp)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, tc.ks)
+	}
+
+	_, err := keyserverURL("ldap://keys.example.org", "DEADBEEF")
+	assert.Error(t, err)
+}
+
+func TestFetchURL(t *testing.T) {
+	ctx := context.Background()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "key")
+	}))
+	defer ts.Close()
+
+	buf, err := fetchURL(ctx, ts.URL+"/key")
+	require.NoError(t, err)
+	assert.Equal(t, "key", string(buf))
+
+	_, err = fetchURL(ctx, ts.URL+"/missing")
+	assert.Error(t, err)
+}
+
+func TestFetchPublicKeyNoNetwork(t *testing.T) {
+	ctx := ctxutil.WithNoNetwork(context.Background(), true)
+
+	g := &GPG{}
+	_, err := g.FetchPublicKey(ctx, "DEADBEEF", "joe.doe@example.org")
+	assert.Error(t, err)
+}
@@ -1,42 +1,128 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"strings"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
+// keyGenTimeout is the maximum time we wait for gpg to generate a key
+var keyGenTimeout = 5 * time.Minute
+
+var (
+	// ErrKeyGenTimeout is returned if gpg did not finish generating a key in time
+	ErrKeyGenTimeout = fmt.Errorf("key generation timed out. See https://github.com/gopasspw/gopass/blob/master/docs/entropy.md")
+	// ErrNotEnoughEntropy is returned if gpg could not gather enough entropy
+	ErrNotEnoughEntropy = fmt.Errorf("not enough entropy to generate a key. See https://github.com/gopasspw/gopass/blob/master/docs/entropy.md")
+)
+
 // GenerateIdentity will create a new GPG keypair in batch mode
 func (g *GPG) GenerateIdentity(ctx context.Context, name, email, passphrase string) error {
-	buf := &bytes.Buffer{}
-	// https://git.gnupg.org/cgi-bin/gitweb.cgi?p=gnupg.git;a=blob;f=doc/DETAILS;h=de0f21ccba60c3037c2a155156202df1cd098507;hb=refs/heads/STABLE-BRANCH-1-4#l716
-	_, _ = buf.WriteString(`%echo Generating a RSA/RSA key pair
-Key-Type: RSA
-Key-Length: 2048
-Subkey-Type: RSA
-Subkey-Length: 2048
-Expire-Date: 0
-`)
-	_, _ = buf.WriteString("Name-Real: " + name + "\n")
-	_, _ = buf.WriteString("Name-Email: " + email + "\n")
-	_, _ = buf.WriteString("Passphrase: " + passphrase + "\n")
+	_, err := g.GenerateKey(ctx, name, email, passphrase)
+	return err
+}
+
+// GenerateKey will create a new GPG keypair in batch mode and return it.
+// It will create an ed25519/cv25519 key pair unless RSA keys are requested
+// in the context.
+func (g *GPG) GenerateKey(ctx context.Context, name, email, passphrase string) (gpg.Key, error) {
+	ctx, cancel := context.WithTimeout(ctx, keyGenTimeout)
+	defer cancel()
 
-	args := []string{"--batch", "--gen-key"}
-	cmd := exec.CommandContext(ctx, g.binary, args...)
-	cmd.Stdin = bytes.NewReader(buf.Bytes())
+	args := []string{"--batch", "--status-fd", "1", "--generate-key"}
+	cmd := g.command(ctx, args...)
+	cmd.Stdin = strings.NewReader(keyGenParams(name, email, passphrase, gpg.IsRSAKeys(ctx)))
 
-	out := &bytes.Buffer{}
-	cmd.Stdout = out
-	cmd.Stderr = out
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command: '%s %+v': %q - %w", cmd.Path, cmd.Args, out.String(), err)
+	done := debug.Exec(cmd)
+	err := cmd.Run()
+	done(err, stderr.String())
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return gpg.Key{}, ErrKeyGenTimeout
+		}
+		if strings.Contains(stderr.String(), "Not enough random bytes") {
+			return gpg.Key{}, ErrNotEnoughEntropy
+		}
+		return gpg.Key{}, fmt.Errorf("failed to run command: '%s %+v': %q - %w", cmd.Path, cmd.Args, stderr.String(), err)
 	}
 	g.privKeys = nil
 	g.pubKeys = nil
-	return nil
+
+	fp := createdKey(stdout.Bytes())
+	if fp == "" {
+		// some gpg versions or wrappers don't report the new key
+		debug.Log("gpg did not report the created key: %s", stdout.String())
+		return gpg.Key{}, nil
+	}
+
+	kl, err := g.listKeys(ctx, "secret", fp)
+	if err != nil {
+		return gpg.Key{}, fmt.Errorf("failed to list generated key %s: %w", fp, err)
+	}
+	if len(kl) != 1 {
+		return gpg.Key{}, fmt.Errorf("generated key %s not found", fp)
+	}
+	return kl[0], nil
+}
+
+// keyGenParams returns the parameters for unattended key generation, see
+// https://www.gnupg.org/documentation/manuals/gnupg/Unattended-GPG-key-generation.html
+func keyGenParams(name, email, passphrase string, rsa bool) string {
+	var sb strings.Builder
+	if rsa {
+		_, _ = sb.WriteString(`%echo Generating a RSA/RSA key pair
+Key-Type: RSA
+Key-Length: 4096
+Key-Usage: sign,cert
+Subkey-Type: RSA
+Subkey-Length: 4096
+Subkey-Usage: encrypt
+`)
+	} else {
+		_, _ = sb.WriteString(`%echo Generating a ed25519/cv25519 key pair
+Key-Type: EDDSA
+Key-Curve: ed25519
+Key-Usage: sign,cert
+Subkey-Type: ECDH
+Subkey-Curve: cv25519
+Subkey-Usage: encrypt
+`)
+	}
+	_, _ = sb.WriteString("Expire-Date: 0\n")
+	_, _ = sb.WriteString("Name-Real: " + name + "\n")
+	_, _ = sb.WriteString("Name-Email: " + email + "\n")
+	if passphrase == "" {
+		_, _ = sb.WriteString("%no-protection\n")
+	} else {
+		_, _ = sb.WriteString("Passphrase: " + passphrase + "\n")
+	}
+	_, _ = sb.WriteString("%commit\n")
+	return sb.String()
+}
+
+// createdKey returns the fingerprint of the key reported by gpg in the
+// KEY_CREATED status line
+func createdKey(status []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(status))
+	for s.Scan() {
+		// [GNUPG:] KEY_CREATED B <fingerprint> [<handle>]
+		fields := strings.Fields(s.Text())
+		if len(fields) < 4 || fields[0] != "[GNUPG:]" || fields[1] != "KEY_CREATED" {
+			continue
+		}
+		return fields[3]
+	}
+	return ""
 }
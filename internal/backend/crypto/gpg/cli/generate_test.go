@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyGenParams(t *testing.T) {
+	p := keyGenParams("foo", "foo@bar.com", "bar", false)
+	assert.Contains(t, p, "Key-Curve: ed25519\n")
+	assert.Contains(t, p, "Subkey-Curve: cv25519\n")
+	assert.Contains(t, p, "Name-Real: foo\n")
+	assert.Contains(t, p, "Name-Email: foo@bar.com\n")
+	assert.Contains(t, p, "Passphrase: bar\n")
+	assert.NotContains(t, p, "%no-protection")
+
+	p = keyGenParams("foo", "foo@bar.com", "", true)
+	assert.Contains(t, p, "Key-Type: RSA\nKey-Length: 4096\n")
+	assert.Contains(t, p, "%no-protection\n")
+	assert.NotContains(t, p, "Passphrase:")
+}
+
+func TestCreatedKey(t *testing.T) {
+	status := `[GNUPG:] KEY_CONSIDERED 96A78264CEDB7DFFDF53FB92FA5B5F39235A4F64 0
+[GNUPG:] KEY_CREATED B 96A78264CEDB7DFFDF53FB92FA5B5F39235A4F64
+`
+	assert.Equal(t, "96A78264CEDB7DFFDF53FB92FA5B5F39235A4F64", createdKey([]byte(status)))
+	assert.Equal(t, "", createdKey([]byte("[GNUPG:] PROGRESS primegen X 100 0\n")))
+}
@@ -4,8 +4,12 @@ package cli
 import (
 	"context"
 	"os"
+	"os/exec"
+	"runtime"
+	"sync"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/pkg/debug"
 	lru "github.com/hashicorp/golang-lru"
 )
@@ -23,11 +27,22 @@ var (
 // GPG is a gpg wrapper
 type GPG struct {
 	binary    string
+	home      string
 	args      []string
 	pubKeys   gpg.KeyList
 	privKeys  gpg.KeyList
 	listCache *lru.TwoQueueCache
+	keyCache  *cache.OnDisk
 	throwKids bool
+
+	// connectAgent overrides the gpg-connect-agent binary
+	connectAgent string
+	ttyOnce      sync.Once
+	// pinMu serializes the fallbacks if the agent can't show a pinentry
+	pinMu sync.Mutex
+	// passphrase was entered by the user if the agent can't show a pinentry
+	passphrase []byte
+	pwMu       sync.Mutex
 }
 
 // Config is the gpg wrapper config
@@ -35,6 +50,7 @@ type Config struct {
 	Binary string
 	Args   []string
 	Umask  int
+	Home   string // GNUPGHOME, uses the environment if empty
 }
 
 // New creates a new GPG wrapper
@@ -50,7 +66,7 @@ func New(ctx context.Context, cfg Config) (*GPG, error) {
 		}
 	}
 
-	gcfg, err := gpgConfig()
+	gcfg, err := gpgConfig(cfg.Home)
 	if err != nil {
 		debug.Log("failed to read GPG config: %s", err)
 	}
@@ -58,18 +74,21 @@ func New(ctx context.Context, cfg Config) (*GPG, error) {
 
 	g := &GPG{
 		binary:    "gpg",
+		home:      cfg.Home,
 		args:      append(defaultArgs, cfg.Args...),
 		throwKids: throwKids,
 	}
 
 	debug.Log("initializing LRU cache")
-	cache, err := lru.New2Q(1024)
+	lc, err := lru.New2Q(1024)
 	if err != nil {
 		return nil, err
 	}
-	g.listCache = cache
+	g.listCache = lc
 	debug.Log("LRU cache initialized")
 
+	g.keyCache = newKeyCache()
+
 	debug.Log("detecting binary")
 	bin, err := Binary(ctx, cfg.Binary)
 	if err != nil {
@@ -78,9 +97,30 @@ func New(ctx context.Context, cfg Config) (*GPG, error) {
 	g.binary = bin
 	debug.Log("binary detected")
 
+	v, err := version(ctx, bin)
+	if err != nil {
+		debug.Log("failed to detect gpg version: %s", err)
+		return g, nil
+	}
+	if err := checkVersion(bin, v); err != nil {
+		return nil, err
+	}
+	g.args = filterArgs(g.args, v)
+	debug.Log("gpg version %s detected", v)
+
 	return g, nil
 }
 
+// command returns a gpg command using the GNUPGHOME of this instance. This
+// also selects the matching gpg-agent, since its socket lives in GNUPGHOME.
+func (g *GPG) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, g.binary, args...)
+	if g.home != "" {
+		cmd.Env = append(os.Environ(), "GNUPGHOME="+g.home)
+	}
+	return cmd
+}
+
 // Initialized always returns nil
 func (g *GPG) Initialized(ctx context.Context) error {
 	return nil
@@ -101,8 +141,8 @@ func (g *GPG) IDFile() string {
 	return IDFile
 }
 
-// Concurrency returns 1 to avoid concurrency issues
-// with many GPG setups.
+// Concurrency returns the number of CPUs. Bulk operations make sure the
+// first decryption, which might trigger a pinentry, is not run concurrently.
 func (g *GPG) Concurrency() int {
-	return 1
+	return runtime.NumCPU()
 }
@@ -4,10 +4,24 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEncrypt(t *testing.T) {
@@ -29,3 +43,331 @@ func TestDecrypt(t *testing.T) {
 	_, err := g.Decrypt(ctx, []byte("foo"))
 	assert.NoError(t, err)
 }
+
+func TestTimeout(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "gpg")
+	require.NoError(t, os.WriteFile(fn, []byte("#!/bin/sh\nexec sleep 10\n"), 0755))
+	g := &GPG{binary: fn}
+
+	ctx := ctxutil.WithExecTimeout(context.Background(), 400*time.Millisecond)
+	_, err := g.Encrypt(ctx, []byte("foo"), nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	_, err = g.Decrypt(gpg.WithNoPinentry(ctx, true), []byte("foo"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+
+	// the passphrase may be asked for, so only Ctrl+C stops it
+	ctx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(200*time.Millisecond, cancel)
+	_, err = g.Decrypt(ctx, []byte("foo"))
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
+
+func TestGnupgHome(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+
+	ctx := context.Background()
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+
+	newGPG := func(name string) (*GPG, gpg.Key) {
+		home := t.TempDir()
+		require.NoError(t, os.Chmod(home, 0700))
+		t.Cleanup(func() {
+			_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		})
+
+		g, err := New(ctx, Config{Home: home})
+		require.NoError(t, err)
+		k, err := g.GenerateKey(ctx, name, strings.ToLower(name)+"@example.org", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, k.Fingerprint)
+		return g, k
+	}
+
+	ga, ka := newGPG("Alice")
+	gb, kb := newGPG("Bob")
+
+	rs, err := ga.ListRecipients(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, rs, ka.ID())
+	assert.NotContains(t, rs, kb.ID())
+
+	// gpg fails to list unknown keys
+	rs, _ = ga.FindRecipients(ctx, kb.Fingerprint)
+	assert.Empty(t, rs)
+
+	// encrypting into mount A must never use keys from keyring B
+	ctx = gpg.WithAlwaysTrust(ctx, true)
+	buf, err := ga.Encrypt(ctx, []byte("foo"), []string{ka.Fingerprint})
+	require.NoError(t, err)
+	ids, err := ga.RecipientIDs(ctx, buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{ka.Fingerprint}, ids)
+
+	// keys missing from the keyring are returned by their key ID
+	ids, err = gb.RecipientIDs(ctx, buf)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Regexp(t, "^0x[0-9A-F]{16}$", ids[0])
+
+	_, err = ga.Encrypt(ctx, []byte("foo"), []string{kb.Fingerprint})
+	assert.Error(t, err)
+}
+
+func TestLoopback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
+	}
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+
+	ctx := context.Background()
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+
+	home := t.TempDir()
+	require.NoError(t, os.Chmod(home, 0700))
+	killAgent := func() {
+		_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+	}
+	t.Cleanup(killAgent)
+
+	g, err := New(ctx, Config{Home: home})
+	require.NoError(t, err)
+	k, err := g.GenerateKey(ctx, "Alice", "alice@example.org", "s3cr3t")
+	require.NoError(t, err)
+
+	ctx = gpg.WithAlwaysTrust(ctx, true)
+	buf, err := g.Encrypt(ctx, []byte("foo"), []string{k.Fingerprint})
+	require.NoError(t, err)
+	// make sure the passphrase is not cached by the agent
+	killAgent()
+
+	pwFile := filepath.Join(t.TempDir(), "passphrase")
+
+	t.Run("correct passphrase", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pwFile, []byte("s3cr3t\n"), 0600))
+		out, err := g.Decrypt(gpg.WithPassphraseFile(ctx, pwFile), buf)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", string(out))
+		killAgent()
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pwFile, []byte("s3cr3t\n"), 0600))
+		ctx := gpg.WithPassphraseFile(ctx, pwFile)
+		ciphertext := &bytes.Buffer{}
+		require.NoError(t, g.EncryptStream(ctx, strings.NewReader("streamed"), []string{k.Fingerprint}, ciphertext))
+		plaintext := &bytes.Buffer{}
+		require.NoError(t, g.DecryptStream(ctx, ciphertext, plaintext))
+		assert.Equal(t, "streamed", plaintext.String())
+		killAgent()
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pwFile, []byte("hunter2"), 0600))
+		_, err := g.Decrypt(gpg.WithPassphraseFile(ctx, pwFile), buf)
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "hunter2")
+		killAgent()
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pwFile, []byte("s3cr3t"), 0600))
+		t.Setenv(PassphraseFileEnv, pwFile)
+		out, err := g.Decrypt(ctx, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "foo", string(out))
+		killAgent()
+	})
+
+	t.Run("denied by agent", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(pwFile, []byte("s3cr3t"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(home, "gpg-agent.conf"), []byte("no-allow-loopback-pinentry\n"), 0600))
+		defer func() {
+			_ = os.Remove(filepath.Join(home, "gpg-agent.conf"))
+			killAgent()
+		}()
+		_, err := g.Decrypt(gpg.WithPassphraseFile(ctx, pwFile), buf)
+		assert.ErrorIs(t, err, ErrLoopbackDenied)
+	})
+
+	t.Run("missing passphrase file", func(t *testing.T) {
+		_, err := g.Decrypt(gpg.WithPassphraseFile(ctx, filepath.Join(home, "nonexistent")), buf)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoopbackUnsupported(t *testing.T) {
+	ctx := context.Background()
+
+	versionMu.Lock()
+	versions["/nonexistent/gpg1"] = semver.Version{Major: 2, Minor: 1, Patch: 11}
+	versionMu.Unlock()
+	defer func() {
+		versionMu.Lock()
+		delete(versions, "/nonexistent/gpg1")
+		versionMu.Unlock()
+	}()
+
+	g := &GPG{binary: "/nonexistent/gpg1"}
+	_, err := g.Decrypt(gpg.WithPassphraseFile(ctx, "/nonexistent/passphrase"), []byte("foo"))
+	assert.ErrorIs(t, err, ErrLoopbackUnsupported)
+}
+
+func TestDecryptNoSecretKey(t *testing.T) {
+	td := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(td, "status"), []byte(noSeckeyStatus), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(td, "badpass"), []byte(badPassphraseStatus), 0600))
+	fn := filepath.Join(td, "gpg")
+	require.NoError(t, os.WriteFile(fn, []byte(`#!/bin/sh
+case "$*" in
+*--decrypt*)
+	cat "`+td+`/$(cat)" >&2
+	exit 2
+	;;
+*--list-public-keys*1111111111111111*)
+	echo "pub:u:4096:1:1111111111111111:1500000000:::u:::scESC:"
+	echo "fpr:::::::::AAAAAAAAAAAAAAAAAAAAAAAA1111111111111111:"
+	echo "uid:u::::1500000000::HASH::Alice <alice@corp.example>:"
+	;;
+esac
+`), 0755))
+	lc, err := lru.New2Q(16)
+	require.NoError(t, err)
+	g := &GPG{binary: fn, listCache: lc}
+	ctx := ctxutil.WithNoKeyCache(context.Background(), true)
+
+	_, err = g.Decrypt(ctx, []byte("status"))
+	var nsk *backend.NoSecretKeyError
+	require.True(t, errors.As(err, &nsk), err)
+	assert.Equal(t, []backend.MissingKey{
+		{ID: "0x2222222222222222"},
+		{ID: "0x1111111111111111", Fingerprint: "AAAAAAAAAAAAAAAAAAAAAAAA1111111111111111", Name: "alice@corp.example", Own: true},
+	}, nsk.Recipients)
+	assert.Equal(t, "no secret key for any of 0x2222222222222222, 0x1111111111111111", err.Error())
+
+	// other failures keep the messages of gpg
+	_, err = g.Decrypt(ctx, []byte("badpass"))
+	assert.False(t, errors.As(err, &nsk))
+	assert.Contains(t, err.Error(), "gpg: public key decryption failed: Bad passphrase")
+}
+
+// fakeAgent returns a gpg binary that fails to show a pinentry until the
+// startup tty of the agent was updated, unless the passphrase is given in
+// loopback mode, and a gpg-connect-agent that updates it if ok is true
+func fakeAgent(t *testing.T, ok bool) (string, string, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	updated := filepath.Join(td, "updated")
+	bin := filepath.Join(td, "gpg")
+	require.NoError(t, os.WriteFile(bin, []byte(`#!/bin/sh
+case "$*" in
+*--version*)
+	echo "gpg (GnuPG) 2.2.27"
+	exit 0
+	;;
+*--passphrase-fd*)
+	read pw <&3
+	if [ "$pw" = "secret" ]; then
+		echo "plaintext"
+		exit 0
+	fi
+	echo "gpg: public key decryption failed: Bad passphrase" >&2
+	exit 2
+	;;
+esac
+if [ -f "`+updated+`" ]; then
+	echo "plaintext"
+	exit 0
+fi
+echo "gpg: public key decryption failed: Inappropriate ioctl for device" >&2
+exit 2
+`), 0755))
+
+	agent := filepath.Join(td, "gpg-connect-agent")
+	script := "#!/bin/sh\necho \"$*\" >> " + filepath.Join(td, "agent.log") + "\n"
+	if ok {
+		script += "touch " + updated + "\n"
+	} else {
+		script += "exit 1\n"
+	}
+	require.NoError(t, os.WriteFile(agent, []byte(script), 0755))
+	return bin, agent, filepath.Join(td, "agent.log")
+}
+
+func TestDecryptUpdateStartupTTY(t *testing.T) {
+	t.Setenv("GPG_TTY", "/dev/pts/1")
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+	ctx := gpg.WithUpdateStartupTTY(context.Background(), true)
+
+	bin, agent, log := fakeAgent(t, true)
+	g := &GPG{binary: bin, connectAgent: agent}
+	buf, err := g.Decrypt(ctx, []byte("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext\n", string(buf))
+	buf, err = g.Decrypt(ctx, []byte("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext\n", string(buf))
+
+	// the agent is only updated once
+	lb, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "updatestartuptty /bye\n", string(lb))
+
+	// disabled by the config
+	bin, agent, log = fakeAgent(t, true)
+	g = &GPG{binary: bin, connectAgent: agent}
+	_, err = g.Decrypt(ctxutil.WithInteractive(gpg.WithUpdateStartupTTY(ctx, false), false), []byte("foo"))
+	assert.True(t, errors.Is(err, errPinentry), err)
+	assert.NoFileExists(t, log)
+}
+
+func TestDecryptPassphrasePrompt(t *testing.T) {
+	t.Setenv("GPG_TTY", "/dev/pts/1")
+	t.Setenv("DISPLAY", ":0")
+	ctx := gpg.WithUpdateStartupTTY(context.Background(), true)
+
+	prompts := 0
+	ctx = termio.WithPassPromptFunc(ctx, func(context.Context, string) (string, error) {
+		prompts++
+		return "secret", nil
+	})
+
+	// the agent is updated after the pinentry failed, but it doesn't help
+	bin, agent, log := fakeAgent(t, false)
+	g := &GPG{binary: bin, connectAgent: agent}
+	buf, err := g.Decrypt(ctx, []byte("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext\n", string(buf))
+	assert.FileExists(t, log)
+	assert.Equal(t, 1, prompts)
+
+	// the passphrase is remembered
+	buf, err = g.Decrypt(ctx, []byte("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext\n", string(buf))
+	assert.Equal(t, 1, prompts)
+
+	// a wrong passphrase is forgotten
+	g = &GPG{binary: bin, connectAgent: agent}
+	_, err = g.Decrypt(termio.WithPassPromptFunc(ctx, func(context.Context, string) (string, error) {
+		return "wrong", nil
+	}), []byte("foo"))
+	assert.Error(t, err)
+	assert.Nil(t, g.getPassphrase())
+
+	// scripts get the error
+	g = &GPG{binary: bin, connectAgent: agent}
+	_, err = g.Decrypt(ctxutil.WithInteractive(ctx, false), []byte("foo"))
+	assert.True(t, errors.Is(err, errPinentry), err)
+	assert.Equal(t, 1, prompts)
+}
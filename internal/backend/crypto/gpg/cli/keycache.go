@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// keyCacheTTL is the maximum age of a cached key listing. Changes to the
+// keyring are detected by the modification times of the keyring files,
+// the TTL only guards against changes we can't detect (e.g. keyboxd).
+const keyCacheTTL = time.Hour
+
+// keyringFiles are the files and directories (relative to GNUPGHOME) whose
+// modification times are used to invalidate cached key listings
+var keyringFiles = []string{
+	"pubring.kbx",
+	"pubring.gpg",
+	"secring.gpg",
+	"trustdb.gpg",
+	"private-keys-v1.d",
+}
+
+// gpgHome returns the GnuPG home directory. An explicitly configured home
+// takes precedence over the environment.
+func gpgHome(home string) string {
+	if home != "" {
+		return home
+	}
+	if sv := os.Getenv("GNUPGHOME"); sv != "" {
+		return sv
+	}
+
+	uhd, _ := os.UserHomeDir()
+	return filepath.Join(uhd, ".gnupg")
+}
+
+func newKeyCache() *cache.OnDisk {
+	kc, err := cache.NewOnDisk("gpg-keys", keyCacheTTL)
+	if err != nil {
+		debug.Log("failed to initialize key cache: %s", err)
+		return nil
+	}
+	return kc
+}
+
+// keyCacheKey returns the cache key for a key listing with the given args.
+// It changes whenever any of the keyring files in GNUPGHOME is modified.
+func keyCacheKey(home string, args []string) string {
+	home = gpgHome(home)
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s", home, strings.Join(args, "\x00"))
+	for _, fn := range keyringFiles {
+		fi, err := os.Stat(filepath.Join(home, fn))
+		if err != nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "\x00%s:%d:%d", fn, fi.ModTime().UnixNano(), fi.Size())
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// getKeyCache returns the cached gpg output for the given args, if any
+func (g *GPG) getKeyCache(args []string) ([]byte, bool) {
+	if g.keyCache == nil {
+		return nil, false
+	}
+	lines, err := g.keyCache.Get(keyCacheKey(g.home, args))
+	if err != nil {
+		debug.Log("key cache miss for %+v: %s", args, err)
+		return nil, false
+	}
+	return []byte(strings.Join(lines, "\n")), true
+}
+
+// setKeyCache stores the gpg output for the given args
+func (g *GPG) setKeyCache(args []string, out []byte) {
+	if g.keyCache == nil {
+		return
+	}
+	if err := g.keyCache.Set(keyCacheKey(g.home, args), strings.Split(string(out), "\n")); err != nil {
+		debug.Log("failed to update key cache for %+v: %s", args, err)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCache(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	t.Setenv("GOPASS_HOMEDIR", td)
+	gh := filepath.Join(td, ".gnupg")
+	require.NoError(t, os.MkdirAll(gh, 0700))
+	t.Setenv("GNUPGHOME", gh)
+
+	pubring := filepath.Join(gh, "pubring.kbx")
+	require.NoError(t, os.WriteFile(pubring, []byte("foo"), 0600))
+
+	kc, err := cache.NewOnDisk("gpg-keys-test", time.Hour)
+	require.NoError(t, err)
+	g := &GPG{keyCache: kc}
+
+	args := []string{"--list-public-keys"}
+	_, found := g.getKeyCache(args)
+	assert.False(t, found)
+
+	g.setKeyCache(args, []byte("pub:u:4096\nfpr:::::::::ABCDEF:\n"))
+	buf, found := g.getKeyCache(args)
+	assert.True(t, found)
+	assert.Equal(t, "pub:u:4096\nfpr:::::::::ABCDEF:\n", string(buf))
+
+	// different args must not share an entry
+	_, found = g.getKeyCache([]string{"--list-secret-keys"})
+	assert.False(t, found)
+
+	// modifying the keyring invalidates the cache
+	ts := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(pubring, ts, ts))
+	_, found = g.getKeyCache(args)
+	assert.False(t, found)
+
+	// no cache configured
+	g = &GPG{}
+	g.setKeyCache(args, []byte("foo"))
+	_, found = g.getKeyCache(args)
+	assert.False(t, found)
+}
@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg/colons"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 
 	//lint:ignore SA1019 we'll try to migrate away later
@@ -19,24 +22,41 @@ import (
 
 // listKey lists all keys of the given type and matching the search strings
 func (g *GPG) listKeys(ctx context.Context, typ string, search ...string) (gpg.KeyList, error) {
-	args := []string{"--with-colons", "--with-fingerprint", "--fixed-list-mode", "--list-" + typ + "-keys"}
+	args := []string{"--with-colons", "--with-fingerprint", "--with-keygrip", "--fixed-list-mode", "--list-" + typ + "-keys"}
 	args = append(args, search...)
 	if e, found := g.listCache.Get(strings.Join(args, ",")); found && gpg.UseCache(ctx) {
 		if ev, ok := e.(gpg.KeyList); ok {
 			return ev, nil
 		}
 	}
-	cmd := exec.CommandContext(ctx, g.binary, args...)
+	useKeyCache := !ctxutil.IsNoKeyCache(ctx)
+	if useKeyCache {
+		if cmdout, found := g.getKeyCache(args); found {
+			debug.Log("using cached key listing for %+v", args)
+			kl := colons.Parse(bytes.NewBuffer(cmdout))
+			g.listCache.Add(strings.Join(args, ","), kl)
+			return kl, nil
+		}
+	}
+
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
 	var errBuf = bytes.Buffer{}
 	cmd.Stderr = &errBuf
 
-	debug.Log("%s %+v\n", cmd.Path, cmd.Args)
+	done := debug.Exec(cmd)
 	cmdout, err := cmd.Output()
+	done(err, errBuf.String())
 	if err != nil {
 		if bytes.Contains(cmdout, []byte("secret key not available")) {
 			return gpg.KeyList{}, nil
 		}
-		return gpg.KeyList{}, fmt.Errorf("%s: %s|%s", err, cmdout, errBuf.String())
+		return gpg.KeyList{}, fmt.Errorf("%w: %s|%s", ctxutil.ExecError(ctx, err), cmdout, errBuf.String())
+	}
+
+	if useKeyCache {
+		g.setKeyCache(args, cmdout)
 	}
 
 	kl := colons.Parse(bytes.NewBuffer(cmdout))
@@ -71,10 +91,64 @@ func (g *GPG) FindRecipients(ctx context.Context, search ...string) ([]string, e
 		recp = kl.Recipients()
 	}
 
+	// refuse keys that can only sign instead of pretending they weren't found
+	if len(recp) < 1 && len(search) > 0 {
+		if sl := kl.UseableKeysFor(gpg.PurposeSign, true); len(sl) > 0 {
+			return nil, fmt.Errorf("%s: %w", strings.Join(sl.Recipients(), ", "), gpg.ErrSignOnly)
+		}
+	}
+
 	debug.Log("found useable keys for %+v: %+v (all: %+v)", search, recp, kl.Recipients())
 	return recp, nil
 }
 
+// ExpiringKeys returns a terse description of each key matching the given ids
+// that will expire within the given duration
+func (g *GPG) ExpiringKeys(ctx context.Context, d time.Duration, ids ...string) ([]string, error) {
+	if len(ids) < 1 {
+		return nil, nil
+	}
+	kl, err := g.listKeys(ctx, "public", ids...)
+	if err != nil {
+		return nil, err
+	}
+	exp := make([]string, 0, len(kl))
+	for _, k := range kl {
+		if !k.ExpiresIn(d) {
+			continue
+		}
+		exp = append(exp, k.OneLine())
+	}
+	sort.Strings(exp)
+	return exp, nil
+}
+
+// ResolveRecipient looks up the public key matching the given query (an
+// email, a name or a fingerprint suffix) and returns its fingerprint. It
+// returns an empty string if no key matches and an error listing all
+// candidates if the query is ambiguous
+func (g *GPG) ResolveRecipient(ctx context.Context, q string) (string, error) {
+	if len(strings.TrimSpace(q)) < gpg.MinQueryLength {
+		return "", fmt.Errorf("query %q is too short, need at least %d characters", q, gpg.MinQueryLength)
+	}
+	kl, err := g.listKeys(ctx, "public")
+	if err != nil {
+		return "", err
+	}
+	cands := kl.FindBy(q)
+	switch len(cands) {
+	case 0:
+		return "", nil
+	case 1:
+		return cands[0].Fingerprint, nil
+	}
+	lines := make([]string, 0, len(cands))
+	for _, k := range cands {
+		lines = append(lines, " - "+k.OneLine())
+	}
+	return "", fmt.Errorf("query %q is ambiguous, matching keys:\n%s", q, strings.Join(lines, "\n"))
+}
+
 // ListIdentities returns a parsed list of GPG secret keys
 func (g *GPG) ListIdentities(ctx context.Context) ([]string, error) {
 	if g.privKeys == nil {
@@ -102,6 +176,16 @@ func (g *GPG) FindIdentities(ctx context.Context, search ...string) ([]string, e
 	return kl.UseableKeys(gpg.IsAlwaysTrust(ctx)).Recipients(), nil
 }
 
+// SigningKeys returns the private keys matching the given ids that can be
+// used to sign, e.g. git commits
+func (g *GPG) SigningKeys(ctx context.Context, ids ...string) ([]string, error) {
+	kl, err := g.listKeys(ctx, "secret", ids...)
+	if err != nil || kl == nil {
+		return nil, err
+	}
+	return kl.UseableKeysFor(gpg.PurposeSign, true).Recipients(), nil
+}
+
 func (g *GPG) findKey(ctx context.Context, id string) gpg.Key {
 	kl, _ := g.listKeys(ctx, "secret", id)
 	if len(kl) >= 1 {
@@ -116,6 +200,15 @@ func (g *GPG) findKey(ctx context.Context, id string) gpg.Key {
 	}
 }
 
+// PublicKey returns the public key with the given id, if it is in the keyring
+func (g *GPG) PublicKey(ctx context.Context, id string) (gpg.Key, bool) {
+	kl, err := g.listKeys(ctx, "public", id)
+	if err != nil || len(kl) < 1 {
+		return gpg.Key{}, false
+	}
+	return kl[0], true
+}
+
 // Fingerprint returns the fingerprint
 func (g *GPG) Fingerprint(ctx context.Context, id string) string {
 	return g.findKey(ctx, id).Fingerprint
@@ -167,14 +260,24 @@ func (g *GPG) ImportPublicKey(ctx context.Context, buf []byte) error {
 	}
 
 	args := append(g.args, "--import")
-	cmd := exec.CommandContext(ctx, g.binary, args...)
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
 	cmd.Stdin = bytes.NewReader(buf)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// gpg reports the imported keys on stderr, they are only shown with
+	// --verbose
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if ctxutil.IsVerbose(ctx) {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = io.MultiWriter(stderr, os.Stderr)
+	}
 
-	debug.Log("gpg.ImportPublicKey: %s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to run command: '%s %+v': %w", cmd.Path, cmd.Args, err)
+	done := debug.Exec(cmd)
+	err := cmd.Run()
+	done(err, stderr.String())
+	if err != nil {
+		return fmt.Errorf("failed to run command: '%s %+v': %w", cmd.Path, cmd.Args, execError(ctx, err, stderr.String()))
 	}
 
 	// clear key cache
@@ -190,12 +293,15 @@ func (g *GPG) ExportPublicKey(ctx context.Context, id string) ([]byte, error) {
 	}
 
 	args := append(g.args, "--armor", "--export", id)
-	cmd := exec.CommandContext(ctx, g.binary, args...)
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
 
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
+	done := debug.Exec(cmd)
 	out, err := cmd.Output()
+	done(err, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to run command '%s %+v': %w", cmd.Path, cmd.Args, err)
+		return nil, fmt.Errorf("failed to run command '%s %+v': %w", cmd.Path, cmd.Args, ctxutil.ExecError(ctx, err))
 	}
 
 	if len(out) < 1 {
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
 )
@@ -27,6 +28,7 @@ func (l loader) New(ctx context.Context) (backend.Crypto, error) {
 		Umask:  fsutil.Umask(),
 		Args:   GPGOpts(),
 		Binary: os.Getenv("GOPASS_GPG_BINARY"),
+		Home:   gpg.GetGnupgHome(ctx),
 	})
 }
 
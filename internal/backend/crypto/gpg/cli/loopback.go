@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// PassphraseFileEnv is the environment variable pointing to a file
+// containing the passphrase used in pinentry loopback mode
+const PassphraseFileEnv = "GOPASS_GPG_PASSPHRASE_FILE"
+
+var (
+	// ErrLoopbackUnsupported is returned if loopback mode is requested but
+	// the gpg binary doesn't support it
+	ErrLoopbackUnsupported = fmt.Errorf("pinentry loopback mode requires GnuPG %s or newer", loopbackVersion)
+	// ErrLoopbackDenied is returned if the gpg-agent refuses loopback mode
+	ErrLoopbackDenied = fmt.Errorf("gpg-agent does not allow pinentry loopback mode. Remove no-allow-loopback-pinentry from gpg-agent.conf")
+)
+
+// passphraseFile returns the passphrase file from the context or the
+// environment. An empty string means loopback mode is not requested.
+func passphraseFile(ctx context.Context) string {
+	if fn := gpg.GetPassphraseFile(ctx); fn != "" {
+		return fn
+	}
+	return os.Getenv(PassphraseFileEnv)
+}
+
+// loopback is a gpg invocation in pinentry loopback mode. The passphrase is
+// passed on file descriptor 3 so it never shows up in the process arguments.
+type loopback struct {
+	passphrase []byte
+	stderr     bytes.Buffer
+}
+
+// loopbackArgs returns the args required for loopback mode and the loopback
+// state, or nil if loopback mode was not requested
+func (g *GPG) loopbackArgs(ctx context.Context) ([]string, *loopback, error) {
+	fn := passphraseFile(ctx)
+	if fn == "" {
+		return nil, nil, nil
+	}
+
+	if err := g.checkLoopback(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read passphrase file: %w", err)
+	}
+
+	debug.Log("using pinentry loopback mode with passphrase from %q", fn)
+	args, lb := newLoopback(bytes.TrimRight(buf, "\r\n"))
+	return args, lb, nil
+}
+
+// checkLoopback returns an error if the gpg binary doesn't support loopback mode
+func (g *GPG) checkLoopback(ctx context.Context) error {
+	v, err := version(ctx, g.binary)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrLoopbackUnsupported, err)
+	}
+	if v.LT(loopbackVersion) {
+		return fmt.Errorf("%w: found GnuPG %s", ErrLoopbackUnsupported, v)
+	}
+	return nil
+}
+
+// newLoopback copies the passphrase, so the newline appended by start never
+// writes into the cached passphrase shared by concurrent decryptions
+func newLoopback(passphrase []byte) ([]string, *loopback) {
+	pw := make([]byte, len(passphrase), len(passphrase)+1)
+	copy(pw, passphrase)
+	return []string{"--batch", "--pinentry-mode", "loopback", "--passphrase-fd", "3"}, &loopback{
+		passphrase: pw,
+	}
+}
+
+// start attaches the passphrase pipe and starts the command
+func (l *loopback) start(cmd *exec.Cmd) error {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, pr)
+	if cmd.Stderr == nil {
+		cmd.Stderr = &l.stderr
+	} else {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &l.stderr)
+	}
+
+	if err := cmd.Start(); err != nil {
+		_ = pr.Close()
+		_ = pw.Close()
+		return err
+	}
+	// the child has its own copy of the read end now
+	_ = pr.Close()
+
+	go func() {
+		_, _ = pw.Write(append(l.passphrase, '\n'))
+		_ = pw.Close()
+	}()
+	return nil
+}
+
+// run runs the command with the passphrase attached and translates any
+// loopback specific errors
+func (l *loopback) run(cmd *exec.Cmd) error {
+	if err := l.start(cmd); err != nil {
+		return err
+	}
+	return l.wrap(cmd.Wait())
+}
+
+func (l *loopback) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	if strings.Contains(l.stderr.String(), "setting pinentry mode 'loopback' failed") {
+		return ErrLoopbackDenied
+	}
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return fmt.Errorf("gpg failed in loopback mode (wrong passphrase?): exit code %d", ee.ExitCode())
+	}
+	return err
+}
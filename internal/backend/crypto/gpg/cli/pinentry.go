@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// errPinentry is returned if the gpg-agent could not ask for the passphrase
+var errPinentry = errors.New("gpg-agent could not show a pinentry")
+
+// pinentryMessages are printed by gpg if the agent failed to start the
+// pinentry, e.g. a graphical one without a display or a curses one without
+// a terminal
+var pinentryMessages = []string{
+	"Inappropriate ioctl for device",
+	"No pinentry",
+}
+
+func pinentryFailed(msgs []string) bool {
+	for _, m := range msgs {
+		for _, pm := range pinentryMessages {
+			if strings.Contains(m, pm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasDisplay returns true if a graphical pinentry can be shown
+func hasDisplay() bool {
+	if os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+		return true
+	}
+	// pinentry-mac doesn't need X11, unless we're logged in remotely
+	return runtime.GOOS == "darwin" && os.Getenv("SSH_CONNECTION") == ""
+}
+
+// connectAgentBinary returns the gpg-connect-agent matching the gpg binary
+func (g *GPG) connectAgentBinary() string {
+	if g.connectAgent != "" {
+		return g.connectAgent
+	}
+	name := "gpg-connect-agent"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if fn := filepath.Join(filepath.Dir(g.binary), name); filepath.IsAbs(fn) {
+		if _, err := os.Stat(fn); err == nil {
+			return fn
+		}
+	}
+	return name
+}
+
+// updateStartupTTY tells the gpg-agent to show the pinentry on our terminal.
+// Otherwise it keeps using the terminal or display of the session it was
+// started from, e.g. the desktop session while we're logged in over SSH. This
+// is done at most once and returns true if the agent was updated by this call.
+func (g *GPG) updateStartupTTY(ctx context.Context) bool {
+	if !gpg.IsUpdateStartupTTY(ctx) || os.Getenv("GPG_TTY") == "" {
+		return false
+	}
+
+	updated := false
+	g.ttyOnce.Do(func() {
+		ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, g.connectAgentBinary(), "updatestartuptty", "/bye")
+		if g.home != "" {
+			cmd.Env = append(os.Environ(), "GNUPGHOME="+g.home)
+		}
+		done := debug.Exec(cmd)
+		buf, err := cmd.CombinedOutput()
+		done(err, string(buf))
+		if err != nil {
+			debug.Log("failed to update the startup tty of the gpg-agent: %s", ctxutil.ExecError(ctx, err))
+			return
+		}
+		updated = true
+	})
+	return updated
+}
+
+// decryptWithoutPinentry retries a decryption that failed because the agent
+// could not show a pinentry. The agent is pointed to our terminal first, if
+// that doesn't help the user is asked for the passphrase, which is passed to
+// gpg in loopback mode. The passphrase is remembered for further decryptions.
+func (g *GPG) decryptWithoutPinentry(ctx context.Context, ciphertext []byte, err error) ([]byte, error) {
+	g.pinMu.Lock()
+	defer g.pinMu.Unlock()
+
+	// another decryption may have fixed it in the meantime
+	if g.updateStartupTTY(ctx) || g.getPassphrase() != nil {
+		buf, rerr := g.decrypt(ctx, ciphertext)
+		if rerr == nil || !errors.Is(rerr, errPinentry) {
+			return buf, rerr
+		}
+	}
+
+	if !ctxutil.IsInteractive(ctx) || gpg.IsNoPinentry(ctx) || passphraseFile(ctx) != "" {
+		return nil, err
+	}
+	if lerr := g.checkLoopback(ctx); lerr != nil {
+		debug.Log("can not ask for the passphrase: %s", lerr)
+		return nil, err
+	}
+
+	pw, perr := termio.AskForPassword(ctx, "the passphrase of your GPG key", false)
+	if perr != nil {
+		return nil, perr
+	}
+	if pw == "" {
+		return nil, err
+	}
+	g.setPassphrase([]byte(pw))
+	buf, err := g.decrypt(ctx, ciphertext)
+	if err != nil {
+		g.setPassphrase(nil)
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (g *GPG) getPassphrase() []byte {
+	g.pwMu.Lock()
+	defer g.pwMu.Unlock()
+
+	return g.passphrase
+}
+
+func (g *GPG) setPassphrase(pw []byte) {
+	g.pwMu.Lock()
+	defer g.pwMu.Unlock()
+
+	g.passphrase = pw
+}
+
+// Lock forgets the passphrase entered by the user. Passphrases cached by the
+// gpg-agent are kept for its own cache TTL.
+func (g *GPG) Lock() {
+	g.setPassphrase(nil)
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinentryFailed(t *testing.T) {
+	assert.True(t, pinentryFailed([]string{"gpg: public key decryption failed: Inappropriate ioctl for device"}))
+	assert.True(t, pinentryFailed([]string{"gpg: encrypted with RSA key", "gpg: public key decryption failed: No pinentry"}))
+	assert.False(t, pinentryFailed([]string{"gpg: decryption failed: No secret key"}))
+	assert.False(t, pinentryFailed(nil))
+}
+
+func TestHasDisplay(t *testing.T) {
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	assert.True(t, hasDisplay())
+
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	assert.True(t, hasDisplay())
+
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+	assert.False(t, hasDisplay())
+
+	t.Setenv("SSH_CONNECTION", "")
+	assert.Equal(t, runtime.GOOS == "darwin", hasDisplay())
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// statusPrefix starts every line gpg writes to the --status-fd, see
+// doc/DETAILS in the gnupg sources
+const statusPrefix = "[GNUPG:] "
+
+// withStatus returns the args to make gpg write status lines to stderr. It
+// does not report missing secret keys in quiet mode, so --quiet is dropped.
+func withStatus(args []string) []string {
+	out := make([]string, 0, len(args)+2)
+	for _, a := range args {
+		if a == "--quiet" || a == "-q" {
+			continue
+		}
+		out = append(out, a)
+	}
+	return append(out, "--status-fd", "2")
+}
+
+// statusLine is a single machine readable status message of gpg
+type statusLine struct {
+	keyword string
+	args    []string
+}
+
+// parseStatus splits the output of gpg into the status lines and the human
+// readable messages it was interleaved with
+func parseStatus(buf []byte) ([]statusLine, []string) {
+	var status []statusLine
+	var msgs []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, statusPrefix) {
+			msgs = append(msgs, line)
+			continue
+		}
+		p := strings.Fields(strings.TrimPrefix(line, statusPrefix))
+		if len(p) < 1 {
+			continue
+		}
+		status = append(status, statusLine{keyword: p[0], args: p[1:]})
+	}
+	return status, msgs
+}
+
+// missingKeys returns the IDs of the keys the message is encrypted for if
+// the secret keys of all of them are missing. Hidden recipients are left out,
+// nil is returned if there are only hidden ones or if any secret key is
+// available, gpg failed for a different reason then.
+func missingKeys(status []statusLine) []string {
+	var encTo []string
+	noSeckey := map[string]bool{}
+	for _, l := range status {
+		if len(l.args) < 1 {
+			continue
+		}
+		switch l.keyword {
+		case "ENC_TO":
+			encTo = append(encTo, l.args[0])
+		case "NO_SECKEY":
+			noSeckey[l.args[0]] = true
+		}
+	}
+
+	var ids []string
+	for _, id := range encTo {
+		if !noSeckey[id] {
+			return nil
+		}
+		// hidden recipients (throw-keyids) can't be identified
+		if strings.Trim(id, "0") == "" {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// decryptError turns the status output of a failed decryption into an error
+// that explains why it failed
+func (g *GPG) decryptError(ctx context.Context, stderr []byte, err error) error {
+	status, msgs := parseStatus(stderr)
+	if ids := missingKeys(status); len(ids) > 0 {
+		e := &backend.NoSecretKeyError{
+			Recipients: make([]backend.MissingKey, 0, len(ids)),
+		}
+		for _, id := range ids {
+			e.Recipients = append(e.Recipients, g.missingKey(ctx, id))
+		}
+		return e
+	}
+	if pinentryFailed(msgs) {
+		return fmt.Errorf("%w: %s: %s", errPinentry, err, strings.Join(msgs, "\n"))
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.Join(msgs, "\n"))
+	}
+	return err
+}
+
+// missingKey looks up the recipient with the given key ID in the public keyring
+func (g *GPG) missingKey(ctx context.Context, id string) backend.MissingKey {
+	mk := backend.MissingKey{
+		ID: "0x" + strings.ToUpper(id),
+	}
+	k, found := g.PublicKey(ctx, id)
+	if !found {
+		debug.Log("public key %s not found", id)
+		return mk
+	}
+	mk.ID = k.ID()
+	mk.Fingerprint = k.Fingerprint
+	mk.Name = k.Identity().Email
+	if mk.Name == "" {
+		mk.Name = k.Identity().Name
+	}
+	mk.Own = k.Ownertrust == "u"
+	return mk
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const noSeckeyStatus = `[GNUPG:] ENC_TO 2222222222222222 1 0
+gpg: encrypted with 4096-bit RSA key, ID 2222222222222222, created 2017-07-14
+      "Bob <bob@corp.example>"
+[GNUPG:] NO_SECKEY 2222222222222222
+[GNUPG:] ENC_TO 1111111111111111 1 0
+gpg: encrypted with RSA key, ID 1111111111111111
+[GNUPG:] NO_SECKEY 1111111111111111
+[GNUPG:] BEGIN_DECRYPTION
+[GNUPG:] DECRYPTION_FAILED
+gpg: decryption failed: No secret key
+[GNUPG:] END_DECRYPTION
+`
+
+const badPassphraseStatus = `[GNUPG:] ENC_TO 2222222222222222 1 0
+[GNUPG:] NO_SECKEY 2222222222222222
+[GNUPG:] ENC_TO 1111111111111111 1 0
+[GNUPG:] KEY_CONSIDERED AAAAAAAAAAAAAAAAAAAAAAAA1111111111111111 0
+[GNUPG:] BAD_PASSPHRASE 1111111111111111
+gpg: public key decryption failed: Bad passphrase
+[GNUPG:] BEGIN_DECRYPTION
+[GNUPG:] DECRYPTION_FAILED
+gpg: decryption failed: No secret key
+[GNUPG:] END_DECRYPTION
+`
+
+func TestWithStatus(t *testing.T) {
+	assert.Equal(t, []string{"--yes", "--status-fd", "2"}, withStatus([]string{"--quiet", "--yes", "-q"}))
+	assert.Equal(t, []string{"--status-fd", "2"}, withStatus(nil))
+}
+
+func TestParseStatus(t *testing.T) {
+	status, msgs := parseStatus([]byte(noSeckeyStatus))
+	assert.Len(t, status, 7)
+	assert.Equal(t, statusLine{keyword: "ENC_TO", args: []string{"2222222222222222", "1", "0"}}, status[0])
+	assert.Equal(t, statusLine{keyword: "DECRYPTION_FAILED", args: []string{}}, status[5])
+	assert.Equal(t, []string{
+		"gpg: encrypted with 4096-bit RSA key, ID 2222222222222222, created 2017-07-14",
+		`"Bob <bob@corp.example>"`,
+		"gpg: encrypted with RSA key, ID 1111111111111111",
+		"gpg: decryption failed: No secret key",
+	}, msgs)
+
+	status, msgs = parseStatus(nil)
+	assert.Empty(t, status)
+	assert.Empty(t, msgs)
+}
+
+func TestMissingKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "no secret key",
+			in:   noSeckeyStatus,
+			want: []string{"2222222222222222", "1111111111111111"},
+		},
+		{
+			name: "bad passphrase",
+			in:   badPassphraseStatus,
+		},
+		{
+			name: "hidden recipients",
+			in:   "[GNUPG:] ENC_TO 0000000000000000 1 0\n[GNUPG:] NO_SECKEY 0000000000000000\n",
+		},
+		{
+			name: "no status",
+			in:   "gpg: no valid OpenPGP data found.\n",
+		},
+	} {
+		status, _ := parseStatus([]byte(tc.in))
+		assert.Equal(t, tc.want, missingKeys(status), tc.name)
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// ExportOwnertrust returns the output of gpg --export-ownertrust. If any ids
+// are given only the entries of the matching keys are returned.
+func (g *GPG) ExportOwnertrust(ctx context.Context, ids ...string) ([]byte, error) {
+	args := append(g.args, "--export-ownertrust")
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
+	var errBuf = bytes.Buffer{}
+	cmd.Stderr = &errBuf
+
+	done := debug.Exec(cmd)
+	buf, err := cmd.Output()
+	done(err, errBuf.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to run command '%s %+v': %s - %w", cmd.Path, cmd.Args, errBuf.String(), ctxutil.ExecError(ctx, err))
+	}
+	if len(ids) < 1 {
+		return buf, nil
+	}
+
+	kl := g.lookupKeys(ctx, ids...)
+	want := make(map[string]bool, len(kl))
+	for _, k := range kl {
+		want[strings.ToUpper(k.Fingerprint)] = true
+	}
+
+	ot := gpg.ParseOwnertrust(buf)
+	for fp := range ot {
+		if !want[fp] {
+			delete(ot, fp)
+		}
+	}
+	return ot.Bytes(), nil
+}
+
+// ImportOwnertrust feeds the given ownertrust entries to gpg --import-ownertrust
+func (g *GPG) ImportOwnertrust(ctx context.Context, r io.Reader) error {
+	args := append(g.args, "--import-ownertrust")
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+	cmd := g.command(ctx, args...)
+	cmd.Stdin = r
+	var errBuf = bytes.Buffer{}
+	cmd.Stderr = &errBuf
+
+	done := debug.Exec(cmd)
+	err := cmd.Run()
+	done(err, errBuf.String())
+	if err != nil {
+		return fmt.Errorf("failed to run command '%s %+v': %s - %w", cmd.Path, cmd.Args, errBuf.String(), ctxutil.ExecError(ctx, err))
+	}
+
+	// clear key cache, the validity of keys might have changed
+	g.privKeys = nil
+	g.pubKeys = nil
+	return nil
+}
+
+// UntrustedKeys returns all keys matching the given ids that are in the
+// keyring but have an unknown or undefined validity
+func (g *GPG) UntrustedKeys(ctx context.Context, ids ...string) (gpg.KeyList, error) {
+	if len(ids) < 1 {
+		return nil, nil
+	}
+	kl := g.lookupKeys(ctx, ids...)
+	ul := make(gpg.KeyList, 0, len(kl))
+	for _, k := range kl {
+		if k.IsUntrusted() {
+			ul = append(ul, k)
+		}
+	}
+	return ul, nil
+}
+
+// lookupKeys returns the public keys matching the given ids. Unlike listKeys
+// it ignores ids that are not in the keyring, gpg would fail the whole
+// listing otherwise.
+func (g *GPG) lookupKeys(ctx context.Context, ids ...string) gpg.KeyList {
+	kl := make(gpg.KeyList, 0, len(ids))
+	for _, id := range ids {
+		l, err := g.listKeys(ctx, "public", id)
+		if err != nil {
+			debug.Log("failed to look up key %s: %s", id, err)
+			continue
+		}
+		kl = append(kl, l...)
+	}
+	return kl
+}
+
+// FormatTrust returns the ownertrust and validity of the given key
+func (g *GPG) FormatTrust(ctx context.Context, id string) string {
+	return g.findKey(ctx, id).TrustLine()
+}
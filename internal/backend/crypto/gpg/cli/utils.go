@@ -2,10 +2,14 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 )
 
 func splitPacket(in string) map[string]string {
@@ -21,18 +25,25 @@ func splitPacket(in string) map[string]string {
 	return m
 }
 
-// gpgConfigLoc returns the location of the GPG config file
-func gpgConfigLoc() string {
-	if sv := os.Getenv("GNUPGHOME"); sv != "" {
-		return filepath.Join(sv, "gpg.conf")
+// execError appends what gpg wrote to stderr to the error of a failed
+// command, unless it was interrupted
+func execError(ctx context.Context, err error, stderr string) error {
+	if ctx.Err() != nil {
+		return ctxutil.ExecError(ctx, err)
+	}
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
 	}
+	return err
+}
 
-	uhd, _ := os.UserHomeDir()
-	return filepath.Join(uhd, ".gnupg", "gpg.conf")
+// gpgConfigLoc returns the location of the GPG config file
+func gpgConfigLoc(home string) string {
+	return filepath.Join(gpgHome(home), "gpg.conf")
 }
 
-func gpgConfig() (map[string]string, error) {
-	fh, err := os.Open(gpgConfigLoc())
+func gpgConfig(home string) (map[string]string, error) {
+	fh, err := os.Open(gpgConfigLoc(home))
 	if err != nil {
 		return nil, err
 	}
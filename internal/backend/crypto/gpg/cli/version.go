@@ -4,10 +4,28 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"fmt"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"github.com/blang/semver/v4"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+var (
+	// minVersion is the oldest GnuPG version supported by this backend
+	minVersion = semver.Version{Major: 2, Minor: 1}
+	// loopbackVersion is the first GnuPG version supporting --pinentry-mode loopback
+	loopbackVersion = semver.Version{Major: 2, Minor: 1, Patch: 12}
+
+	// ErrUnsupportedVersion is returned if the gpg binary is too old
+	ErrUnsupportedVersion = fmt.Errorf("unsupported gpg version")
+
+	// versions caches the detected version of each gpg binary
+	versions  = map[string]semver.Version{}
+	versionMu sync.Mutex
 )
 
 type gpgBin struct {
@@ -29,31 +47,82 @@ func (v byVersion) Less(i, j int) bool {
 	return v[i].ver.LT(v[j].ver)
 }
 
-// Version will returns GPG version information
-func (g *GPG) Version(ctx context.Context) semver.Version {
+// Version will returns GPG version information or an error if gpg --version
+// failed or its output could not be parsed
+func (g *GPG) Version(ctx context.Context) (semver.Version, error) {
 	return version(ctx, g.Binary())
 }
 
-func version(ctx context.Context, binary string) semver.Version {
-	v := semver.Version{}
+// version returns the version of the given gpg binary. The result is cached
+// for the lifetime of the process.
+func version(ctx context.Context, binary string) (semver.Version, error) {
+	versionMu.Lock()
+	defer versionMu.Unlock()
+
+	if v, found := versions[binary]; found {
+		return v, nil
+	}
+
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
 
 	cmd := exec.CommandContext(ctx, binary, "--version")
 	out, err := cmd.Output()
 	if err != nil {
-		return v
+		return semver.Version{}, fmt.Errorf("failed to run %s --version: %w", binary, ctxutil.ExecError(ctx, err))
 	}
 
+	v, err := parseVersion(out)
+	if err != nil {
+		return v, err
+	}
+	versions[binary] = v
+	return v, nil
+}
+
+// parseVersion extracts the version from the output of gpg --version, e.g.
+// "gpg (GnuPG) 2.2.40"
+func parseVersion(out []byte) (semver.Version, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(out))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(line, "gpg ") {
-			p := strings.Fields(line)
-			sv, err := semver.Parse(p[len(p)-1])
-			if err != nil {
-				continue
+		if !strings.HasPrefix(line, "gpg ") {
+			continue
+		}
+		p := strings.Fields(line)
+		sv, err := semver.ParseTolerant(p[len(p)-1])
+		if err != nil {
+			continue
+		}
+		return sv, nil
+	}
+	return semver.Version{}, fmt.Errorf("no version found")
+}
+
+// checkVersion makes sure the gpg binary is recent enough
+func checkVersion(binary string, v semver.Version) error {
+	if v.GTE(minVersion) {
+		return nil
+	}
+	return fmt.Errorf("%w: found GnuPG %s at %q, but gopass requires GnuPG %s or newer. Please install GnuPG 2 (e.g. the gnupg2 package) or point GOPASS_GPG_BINARY to it", ErrUnsupportedVersion, v, binary, minVersion)
+}
+
+// filterArgs removes any arguments not supported by the given gpg version
+func filterArgs(args []string, v semver.Version) []string {
+	if v.GTE(loopbackVersion) {
+		return args
+	}
+
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "--pinentry-mode") {
+			debug.Log("gpg %s does not support %s, ignoring", v, args[i])
+			if args[i] == "--pinentry-mode" {
+				i++
 			}
-			return sv
+			continue
 		}
+		out = append(out, args[i])
 	}
-	return v
+	return out
 }
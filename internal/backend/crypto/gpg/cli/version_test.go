@@ -1,10 +1,14 @@
 package cli
 
 import (
+	"context"
+	"path/filepath"
 	"sort"
 	"testing"
 
 	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSort(t *testing.T) {
@@ -39,3 +43,73 @@ func TestSort(t *testing.T) {
 		t.Errorf("wrong sort order")
 	}
 }
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want semver.Version
+	}{
+		{
+			in:   "gpg (GnuPG) 2.2.40\nlibgcrypt 1.10.1\n",
+			want: semver.Version{Major: 2, Minor: 2, Patch: 40},
+		},
+		{
+			in:   "gpg (GnuPG) 1.4.23\nCopyright (C) 2015 Free Software Foundation, Inc.\n",
+			want: semver.Version{Major: 1, Minor: 4, Patch: 23},
+		},
+		{
+			in:   "gpg (GnuPG/MacGPG2) 2.2.24\n",
+			want: semver.Version{Major: 2, Minor: 2, Patch: 24},
+		},
+		{
+			in:   "gpg (GnuPG) 2.3\n",
+			want: semver.Version{Major: 2, Minor: 3},
+		},
+	} {
+		v, err := parseVersion([]byte(tc.in))
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, v, tc.in)
+	}
+
+	_, err := parseVersion([]byte("foo 1.2.3\n"))
+	assert.Error(t, err)
+}
+
+func TestCheckVersion(t *testing.T) {
+	assert.NoError(t, checkVersion("/usr/bin/gpg", semver.Version{Major: 2, Minor: 2, Patch: 40}))
+	assert.NoError(t, checkVersion("/usr/bin/gpg", semver.Version{Major: 2, Minor: 1}))
+
+	err := checkVersion("/usr/bin/gpg", semver.Version{Major: 1, Minor: 4, Patch: 23})
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+	assert.Contains(t, err.Error(), "1.4.23")
+	assert.Contains(t, err.Error(), "/usr/bin/gpg")
+}
+
+func TestFilterArgs(t *testing.T) {
+	args := []string{"--quiet", "--pinentry-mode", "loopback", "--yes", "--pinentry-mode=loopback"}
+
+	assert.Equal(t, args, filterArgs(args, semver.Version{Major: 2, Minor: 1, Patch: 12}))
+	assert.Equal(t, []string{"--quiet", "--yes"}, filterArgs(args, semver.Version{Major: 2, Minor: 1, Patch: 11}))
+}
+
+func TestVersionCache(t *testing.T) {
+	versionMu.Lock()
+	versions["/nonexistent/gpg"] = semver.Version{Major: 2, Minor: 4}
+	versionMu.Unlock()
+	defer func() {
+		versionMu.Lock()
+		delete(versions, "/nonexistent/gpg")
+		versionMu.Unlock()
+	}()
+
+	v, err := version(context.Background(), "/nonexistent/gpg")
+	require.NoError(t, err)
+	assert.Equal(t, semver.Version{Major: 2, Minor: 4}, v)
+}
+
+func TestVersionError(t *testing.T) {
+	g := &GPG{binary: filepath.Join(t.TempDir(), "gpg")}
+
+	_, err := g.Version(context.Background())
+	assert.Error(t, err)
+}
@@ -64,10 +64,14 @@ func Parse(reader io.Reader) gpg.KeyList {
 	scanner := bufio.NewScanner(reader)
 
 	var cur gpg.Key
+	var curSub string
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		fields := strings.Split(line, ":")
+		// some tools mark stubs with a suffix on the record type, e.g. ssb>
+		typ, stub := recordType(fields[0])
+		fields[0] = typ
 
 		switch fields[0] {
 		case "pub":
@@ -80,6 +84,7 @@ func Parse(reader io.Reader) gpg.KeyList {
 			if validity == "" && fields[0] == "sec" {
 				validity = "u"
 			}
+			curSub = ""
 			cur = gpg.Key{
 				KeyType:        fields[0],
 				Validity:       validity,
@@ -87,17 +92,57 @@ func Parse(reader io.Reader) gpg.KeyList {
 				CreationDate:   parseTS(fields[5]),
 				ExpirationDate: parseTS(fields[6]),
 				Ownertrust:     fields[8],
+				PubKeyAlgo:     parseInt(fields[3]),
+				Curve:          curveName(fields),
 				Identities:     make(map[string]gpg.Identity, 1),
-				SubKeys:        make(map[string]struct{}, 1),
+				SubKeys:        make(map[string]gpg.SubKey, 1),
 				Caps:           parseKeyCaps(fields[11]),
+				PrimaryCaps:    parseKeyCaps(strings.ToUpper(lowerOnly(fields[11]))),
+			}
+			if fields[0] == "sec" {
+				cur.CardSerial, cur.Stub = parseToken(fields, stub)
 			}
 		case "sub":
 			fallthrough
 		case "ssb":
-			cur.SubKeys[fields[4]] = struct{}{}
+			if cur.SubKeys == nil {
+				continue
+			}
+			curSub = fields[4]
+			sk := gpg.SubKey{
+				KeyType:        fields[0],
+				Validity:       fields[1],
+				KeyLength:      parseInt(fields[2]),
+				PubKeyAlgo:     parseInt(fields[3]),
+				Curve:          curveName(fields),
+				CreationDate:   parseTS(fields[5]),
+				ExpirationDate: parseTS(fields[6]),
+				Caps:           parseKeyCaps(strings.ToUpper(fields[11])),
+			}
+			if fields[0] == "ssb" {
+				sk.CardSerial, sk.Stub = parseToken(fields, stub)
+				if cur.CardSerial == "" {
+					cur.CardSerial = sk.CardSerial
+				}
+			}
+			cur.SubKeys[curSub] = sk
 		case "fpr":
 			if cur.Fingerprint == "" {
 				cur.Fingerprint = fields[9]
+				continue
+			}
+			if sk, found := cur.SubKeys[curSub]; found && sk.Fingerprint == "" {
+				sk.Fingerprint = fields[9]
+				cur.SubKeys[curSub] = sk
+			}
+		case "grp":
+			if curSub == "" {
+				cur.Keygrip = fields[9]
+				continue
+			}
+			if sk, found := cur.SubKeys[curSub]; found && sk.Keygrip == "" {
+				sk.Keygrip = fields[9]
+				cur.SubKeys[curSub] = sk
 			}
 		case "uid":
 			sn := fields[7]
@@ -112,6 +157,55 @@ func Parse(reader io.Reader) gpg.KeyList {
 	return kl
 }
 
+// lowerOnly returns only the lower case characters of the given key
+// capabilities field. These denote the capabilities of this very key while
+// the upper case ones describe the capabilities of the key including its
+// subkeys
+func lowerOnly(field string) string {
+	out := make([]rune, 0, len(field))
+	for _, r := range field {
+		if r >= 'a' && r <= 'z' {
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
+
+// recordType returns the record type without any stub marker. A trailing
+// '>' marks a key on a smartcard, a trailing '#' a key that is not available.
+func recordType(typ string) (string, bool) {
+	if t := strings.TrimRight(typ, ">#"); t != typ {
+		return t, true
+	}
+	return typ, false
+}
+
+// parseToken returns the serial number of the smartcard holding the secret
+// key (field 14) and whether the secret key is a stub. The field contains
+// the card serial, '#' for a stub without a card or '+' if the secret
+// key is available.
+func parseToken(fields []string, stub bool) (string, bool) {
+	if len(fields) < 15 {
+		return "", stub
+	}
+	switch sn := fields[14]; sn {
+	case "", "+":
+		return "", stub
+	case "#":
+		return "", true
+	default:
+		return sn, true
+	}
+}
+
+// curveName returns the curve name (field 16) of a key record, if any
+func curveName(fields []string) string {
+	if len(fields) < 17 {
+		return ""
+	}
+	return fields[16]
+}
+
 func parseKeyCaps(field string) gpg.Capabilities {
 	keycaps := gpg.Capabilities{}
 
@@ -141,6 +235,7 @@ func parseColonIdentity(fields []string) gpg.Identity {
 	id := fields[9]
 	ni := gpg.Identity{
 		Name:           id,
+		Validity:       fields[1],
 		CreationDate:   parseTS(fields[5]),
 		ExpirationDate: parseTS(fields[6]),
 	}
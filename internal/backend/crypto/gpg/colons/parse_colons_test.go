@@ -1,10 +1,14 @@
 package colons
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseColonIdentity(t *testing.T) {
@@ -13,6 +17,7 @@ func TestParseColonIdentity(t *testing.T) {
 		name    string
 		comment string
 		email   string
+		revoked bool
 	}{
 		{
 			in:      "uid:-::::1460666077::780A2FDD0570B3E52E5B1E24EBB406B68526CAFD::ThisIsNotAnAlias:",
@@ -38,10 +43,153 @@ func TestParseColonIdentity(t *testing.T) {
 			comment: "user",
 			email:   "",
 		},
+		{
+			in:      "uid:r::::1441103821::AEFC3F5B6CAD79A946D7F0FF83BB8B7E10B578CA::John Doe <john.doe@old-employer.com>:",
+			name:    "John Doe",
+			comment: "",
+			email:   "john.doe@old-employer.com",
+			revoked: true,
+		},
 	} {
 		gi := parseColonIdentity(strings.Split(tc.in, ":"))
 		assert.Equal(t, tc.name, gi.Name)
 		assert.Equal(t, tc.comment, gi.Comment)
 		assert.Equal(t, tc.email, gi.Email)
+		assert.Equal(t, tc.revoked, gi.IsRevoked())
+	}
+}
+
+func TestParseSubKeys(t *testing.T) {
+	in := `tru::1:1620000000:0:3:1:5
+pub:u:4096:1:62AF4031C82E0039:1514768461:::u:::scESC::::::23::0:
+fpr:::::::::25FF1614B8F87B52FFFF99B962AF4031C82E0039:
+uid:u::::1514768461::AEFC3F5B6CAD79A946D7F0FF83BB8B7E10B578CA::John Doe <john.doe@example.com>::::::::::0:
+sub:e:4096:1:5E6DB4E4C692E6C3:1514768461:1546304461:::::e::::::23:
+fpr:::::::::D6A92DD4A9B1E8A5A49F7FA15E6DB4E4C692E6C3:
+sub:u:255:18:A8F3B29E3C4F1D07:1609459200:4102444800:::::e::::::cv25519:
+fpr:::::::::B43E8F1F9F7B1D6A8E4C2D3AA8F3B29E3C4F1D07:
+`
+	kl := Parse(strings.NewReader(in))
+	assert.Equal(t, 1, len(kl))
+	k := kl[0]
+	assert.Equal(t, 2, len(k.SubKeys))
+
+	expired := k.SubKeys["5E6DB4E4C692E6C3"]
+	assert.Equal(t, "sub", expired.KeyType)
+	assert.Equal(t, 4096, expired.KeyLength)
+	assert.Equal(t, "D6A92DD4A9B1E8A5A49F7FA15E6DB4E4C692E6C3", expired.Fingerprint)
+	assert.True(t, expired.Caps.Encrypt)
+	assert.True(t, expired.IsExpired())
+
+	fresh := k.SubKeys["A8F3B29E3C4F1D07"]
+	assert.Equal(t, 255, fresh.KeyLength)
+	assert.Equal(t, "B43E8F1F9F7B1D6A8E4C2D3AA8F3B29E3C4F1D07", fresh.Fingerprint)
+	assert.True(t, fresh.Caps.Encrypt)
+	assert.False(t, fresh.Caps.Sign)
+	assert.True(t, fresh.IsValid())
+
+	assert.Equal(t, "25FF1614B8F87B52FFFF99B962AF4031C82E0039", k.Fingerprint)
+	assert.True(t, k.IsUseable(false))
+}
+
+func TestParseKeygrips(t *testing.T) {
+	in := `sec:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC:::+:::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+grp:::::::::0B5B6E1F8C5A04B8A5C2E9F3D1B7A6C4E2F0D8B1:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb:u:255:18:921948EA957021AC:1577880000::::::e:::+:::cv25519::
+fpr:::::::::4B38904832BDA8D1A74C710B921948EA957021AC:
+grp:::::::::7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D:
+`
+	kl := Parse(strings.NewReader(in))
+	require.Equal(t, 1, len(kl))
+	assert.Equal(t, "0B5B6E1F8C5A04B8A5C2E9F3D1B7A6C4E2F0D8B1", kl[0].Keygrip)
+	assert.Equal(t, "7C1D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D", kl[0].SubKeys["921948EA957021AC"].Keygrip)
+}
+
+func TestKeyStringGolden(t *testing.T) {
+	for _, name := range []string{"rsa", "ed25519"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			in, err := os.ReadFile(filepath.Join("testdata", name+".colons"))
+			require.NoError(t, err)
+			want, err := os.ReadFile(filepath.Join("testdata", name+".golden"))
+			require.NoError(t, err)
+
+			kl := Parse(bytes.NewReader(in))
+			require.Equal(t, 1, len(kl))
+			assert.Equal(t, strings.TrimSpace(string(want)), kl[0].String())
+			// the output must be stable across invocations
+			for i := 0; i < 10; i++ {
+				assert.Equal(t, kl[0].String(), kl[0].String())
+			}
+		})
+	}
+}
+
+func TestParseSmartcard(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		in       string
+		serial   string
+		material bool
+	}{
+		{
+			name: "subkeys on card",
+			in: `sec:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC:::#:::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb:u:255:18:921948EA957021AC:1577880000::::::e:::D2760001240103040006123456780000:::cv25519::
+fpr:::::::::0A1B2C3D4E5F60718293A4B5921948EA957021AC:
+`,
+			serial:   "D2760001240103040006123456780000",
+			material: false,
+		},
+		{
+			name: "stub records",
+			in: `sec#:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC::::::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb>:u:255:18:921948EA957021AC:1577880000::::::e:::D2760001240103040006123456780000:::cv25519::
+fpr:::::::::0A1B2C3D4E5F60718293A4B5921948EA957021AC:
+`,
+			serial:   "D2760001240103040006123456780000",
+			material: false,
+		},
+		{
+			name: "primary on disk",
+			in: `sec:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC:::+:::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb:u:255:18:921948EA957021AC:1577880000::::::e:::D2760001240103040006123456780000:::cv25519::
+fpr:::::::::0A1B2C3D4E5F60718293A4B5921948EA957021AC:
+`,
+			serial:   "D2760001240103040006123456780000",
+			material: true,
+		},
+		{
+			name: "on disk",
+			in: `sec:u:255:22:A4B0BF3E1195AE66:1577880000:::u:::scESC:::+:::ed25519:::0:
+fpr:::::::::412C1687B7F68AF9E2D5A6D5A4B0BF3E1195AE66:
+uid:u::::1577880000::87DA50864CFF358796554C4EEA9E95192CB0C06D::Jane Doe <jane.doe@example.org>::::::::::0:
+ssb:u:255:18:921948EA957021AC:1577880000::::::e:::+:::cv25519::
+fpr:::::::::0A1B2C3D4E5F60718293A4B5921948EA957021AC:
+`,
+			serial:   "",
+			material: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			kl := Parse(strings.NewReader(tc.in))
+			require.Equal(t, 1, len(kl))
+			k := kl[0]
+			assert.Equal(t, "sec", k.KeyType)
+			assert.Equal(t, tc.serial, k.CardSerial)
+			assert.Equal(t, tc.material, k.HasSecretKeyMaterial())
+			sk := k.SubKeys["921948EA957021AC"]
+			assert.Equal(t, "ssb", sk.KeyType)
+			assert.Equal(t, tc.serial, sk.CardSerial)
+		})
 	}
 }
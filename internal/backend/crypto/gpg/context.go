@@ -7,6 +7,12 @@ type contextKey int
 const (
 	ctxKeyAlwaysTrust contextKey = iota
 	ctxKeyUseCache
+	ctxKeyKeyserver
+	ctxKeyRSAKeys
+	ctxKeyGnupgHome
+	ctxKeyPassphraseFile
+	ctxKeyNoPinentry
+	ctxKeyUpdateStartupTTY
 )
 
 // WithAlwaysTrust will return a context with the flag for always trust set
@@ -37,3 +43,98 @@ func UseCache(ctx context.Context) bool {
 	}
 	return nc
 }
+
+// WithKeyserver returns a context with the keyserver used to fetch missing
+// public keys set
+func WithKeyserver(ctx context.Context, ks string) context.Context {
+	return context.WithValue(ctx, ctxKeyKeyserver, ks)
+}
+
+// GetKeyserver returns the keyserver used to fetch missing public keys or
+// an empty string if none is set
+func GetKeyserver(ctx context.Context) string {
+	ks, ok := ctx.Value(ctxKeyKeyserver).(string)
+	if !ok {
+		return ""
+	}
+	return ks
+}
+
+// WithRSAKeys returns a context with the flag to generate RSA instead of
+// ed25519/cv25519 keys set
+func WithRSAKeys(ctx context.Context, rsa bool) context.Context {
+	return context.WithValue(ctx, ctxKeyRSAKeys, rsa)
+}
+
+// IsRSAKeys returns true if new keys should be RSA keys
+func IsRSAKeys(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyRSAKeys).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithGnupgHome returns a context with the GNUPGHOME used by newly created
+// gpg backends set
+func WithGnupgHome(ctx context.Context, home string) context.Context {
+	return context.WithValue(ctx, ctxKeyGnupgHome, home)
+}
+
+// GetGnupgHome returns the GNUPGHOME from the context or an empty string
+// if the environment should be used
+func GetGnupgHome(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyGnupgHome).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithPassphraseFile returns a context with the file containing the
+// passphrase used for pinentry loopback mode set
+func WithPassphraseFile(ctx context.Context, fn string) context.Context {
+	return context.WithValue(ctx, ctxKeyPassphraseFile, fn)
+}
+
+// GetPassphraseFile returns the file containing the passphrase used for
+// pinentry loopback mode or an empty string if loopback mode is not requested
+func GetPassphraseFile(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyPassphraseFile).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithNoPinentry returns a context with the flag to fail instead of asking
+// for a passphrase set
+func WithNoPinentry(ctx context.Context, np bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoPinentry, np)
+}
+
+// IsNoPinentry returns true if decryption should fail if the passphrase is
+// not cached by the agent
+func IsNoPinentry(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyNoPinentry).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithUpdateStartupTTY returns a context with the flag to point the gpg-agent
+// to the current terminal if no graphical pinentry can be shown set
+func WithUpdateStartupTTY(ctx context.Context, up bool) context.Context {
+	return context.WithValue(ctx, ctxKeyUpdateStartupTTY, up)
+}
+
+// IsUpdateStartupTTY returns true if the gpg-agent should be pointed to the
+// current terminal if no graphical pinentry can be shown
+func IsUpdateStartupTTY(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyUpdateStartupTTY).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
@@ -16,3 +16,63 @@ func TestAlwaysTrust(t *testing.T) {
 		t.Errorf("AlwaysTrust should be true")
 	}
 }
+
+func TestKeyserver(t *testing.T) {
+	ctx := context.Background()
+
+	if ks := GetKeyserver(ctx); ks != "" {
+		t.Errorf("Keyserver should be empty, got %q", ks)
+	}
+
+	if ks := GetKeyserver(WithKeyserver(ctx, "hkps://keys.example.org")); ks != "hkps://keys.example.org" {
+		t.Errorf("Keyserver should be set, got %q", ks)
+	}
+}
+
+func TestRSAKeys(t *testing.T) {
+	ctx := context.Background()
+
+	if IsRSAKeys(ctx) {
+		t.Errorf("RSAKeys should be false")
+	}
+
+	if !IsRSAKeys(WithRSAKeys(ctx, true)) {
+		t.Errorf("RSAKeys should be true")
+	}
+}
+
+func TestGnupgHome(t *testing.T) {
+	ctx := context.Background()
+
+	if home := GetGnupgHome(ctx); home != "" {
+		t.Errorf("GnupgHome should be empty, got %q", home)
+	}
+
+	if home := GetGnupgHome(WithGnupgHome(ctx, "/tmp/gnupg")); home != "/tmp/gnupg" {
+		t.Errorf("GnupgHome should be set, got %q", home)
+	}
+}
+
+func TestNoPinentry(t *testing.T) {
+	ctx := context.Background()
+
+	if IsNoPinentry(ctx) {
+		t.Errorf("NoPinentry should be false")
+	}
+
+	if !IsNoPinentry(WithNoPinentry(ctx, true)) {
+		t.Errorf("NoPinentry should be true")
+	}
+}
+
+func TestUpdateStartupTTY(t *testing.T) {
+	ctx := context.Background()
+
+	if IsUpdateStartupTTY(ctx) {
+		t.Errorf("UpdateStartupTTY should be false")
+	}
+
+	if !IsUpdateStartupTTY(WithUpdateStartupTTY(ctx, true)) {
+		t.Errorf("UpdateStartupTTY should be true")
+	}
+}
@@ -1,16 +1,40 @@
 package gpg
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Identity is a GPG identity, one key can have many IDs
 type Identity struct {
 	Name           string
 	Comment        string
 	Email          string
+	Validity       string
 	CreationDate   time.Time
 	ExpirationDate time.Time
 }
 
+// IsRevoked returns true if this user-id has been revoked
+func (i Identity) IsRevoked() bool {
+	return i.Validity == "r"
+}
+
+// Matches returns true if the query is a case-insensitive substring of
+// either the name, email or comment of this identity
+func (i Identity) Matches(q string) bool {
+	q = strings.ToLower(q)
+	if q == "" {
+		return false
+	}
+	for _, f := range []string{i.Name, i.Email, i.Comment} {
+		if strings.Contains(strings.ToLower(f), q) {
+			return true
+		}
+	}
+	return false
+}
+
 // ID returns the GPG ID format
 func (i Identity) ID() string {
 	out := i.Name
@@ -24,5 +48,26 @@ func (i Identity) ID() string {
 // String implement fmt.Stringer. This method resembles the output gpg uses
 // for user-ids
 func (i Identity) String() string {
-	return "uid                            " + i.ID()
+	return "uid           [" + validityLabel(i.Validity) + "] " + i.ID()
+}
+
+// validityLabel returns the fixed width validity label gpg uses for user-ids
+func validityLabel(v string) string {
+	switch v {
+	case "u":
+		return "ultimate"
+	case "f":
+		return "  full  "
+	case "m":
+		return "marginal"
+	case "n":
+		return " never  "
+	case "q":
+		return "  undef "
+	case "e":
+		return " expired"
+	case "r":
+		return " revoked"
+	}
+	return " unknown"
 }
@@ -17,5 +17,31 @@ func TestIdentity(t *testing.T) {
 	}
 
 	assert.Equal(t, id.ID(), "John Doe (johnny) <john.doe@example.org>")
-	assert.Equal(t, id.String(), "uid                            "+id.ID())
+	assert.Equal(t, id.String(), "uid           [ unknown] "+id.ID())
+}
+
+func TestIdentityRevoked(t *testing.T) {
+	id := Identity{
+		Name:     "John Doe",
+		Email:    "john.doe@example.org",
+		Validity: "r",
+	}
+
+	assert.True(t, id.IsRevoked())
+	assert.Equal(t, "uid           [ revoked] John Doe <john.doe@example.org>", id.String())
+}
+
+func TestIdentityMatches(t *testing.T) {
+	id := Identity{
+		Name:    "Alice Doe",
+		Comment: "work",
+		Email:   "alice@example.com",
+	}
+
+	assert.True(t, id.Matches("alice@example.com"))
+	assert.True(t, id.Matches("ALICE"))
+	assert.True(t, id.Matches("doe"))
+	assert.True(t, id.Matches("Work"))
+	assert.False(t, id.Matches("bob"))
+	assert.False(t, id.Matches(""))
 }
@@ -15,9 +15,15 @@ type Key struct {
 	ExpirationDate time.Time
 	Ownertrust     string
 	Fingerprint    string
+	PubKeyAlgo     int
+	Curve          string
 	Identities     map[string]Identity
-	SubKeys        map[string]struct{}
-	Caps           Capabilities
+	SubKeys        map[string]SubKey
+	Caps           Capabilities // capabilities of the whole key, including subkeys
+	PrimaryCaps    Capabilities // capabilities of the primary key only
+	CardSerial     string       // serial number of the smartcard holding the secret key, if any
+	Stub           bool         // the secret primary key is not available on disk
+	Keygrip        string       // identifies the primary key in the gpg-agent, if listed with --with-keygrip
 }
 
 // Capabilities of a Key
@@ -29,12 +35,80 @@ type Capabilities struct {
 	Deactivated    bool
 }
 
+// Purpose is what a key is going to be used for
+type Purpose int
+
+const (
+	// PurposeEncrypt is used for keys that should encrypt secrets
+	PurposeEncrypt Purpose = iota
+	// PurposeSign is used for keys that should sign, e.g. git commits
+	PurposeSign
+	// PurposeCertify is used for keys that should certify other keys
+	PurposeCertify
+	// PurposeAuthenticate is used for keys that should authenticate, e.g. SSH
+	PurposeAuthenticate
+)
+
+// String implements fmt.Stringer
+func (p Purpose) String() string {
+	switch p {
+	case PurposeEncrypt:
+		return "encryption"
+	case PurposeSign:
+		return "signing"
+	case PurposeCertify:
+		return "certification"
+	case PurposeAuthenticate:
+		return "authentication"
+	}
+	return "unknown"
+}
+
+// Usage returns the usage flags in the format gpg uses, e.g. SC or E
+func (c Capabilities) Usage() string {
+	out := ""
+	if c.Sign {
+		out += "S"
+	}
+	if c.Certify {
+		out += "C"
+	}
+	if c.Encrypt {
+		out += "E"
+	}
+	if c.Authentication {
+		out += "A"
+	}
+	return out
+}
+
+// Has returns true if the capabilities cover the given purpose
+func (c Capabilities) Has(p Purpose) bool {
+	switch p {
+	case PurposeEncrypt:
+		return c.Encrypt
+	case PurposeSign:
+		return c.Sign
+	case PurposeCertify:
+		return c.Certify
+	case PurposeAuthenticate:
+		return c.Authentication
+	}
+	return false
+}
+
 // IsUseable returns true if GPG would assume this key is useable for encryption
 func (k Key) IsUseable(alwaysTrust bool) bool {
+	return k.IsUseableFor(PurposeEncrypt, alwaysTrust)
+}
+
+// IsUseableFor returns true if GPG would assume this key is useable for
+// the given purpose
+func (k Key) IsUseableFor(p Purpose, alwaysTrust bool) bool {
 	if k.Caps.Deactivated {
 		return false
 	}
-	if !k.Caps.Encrypt {
+	if !k.canUseFor(p) {
 		return false
 	}
 	if !k.ExpirationDate.IsZero() && k.ExpirationDate.Before(time.Now()) {
@@ -54,24 +128,178 @@ func (k Key) IsUseable(alwaysTrust bool) bool {
 	return false
 }
 
-// String implement fmt.Stringer. This method produces output that is close to, but
-// not exactly the same, as the output form GPG itself
+// canUseFor returns true if either the primary key or at least one valid
+// subkey has the capability required for the given purpose
+func (k Key) canUseFor(p Purpose) bool {
+	// without any subkey information we have to rely on the capabilities
+	// listed on the primary key record (which include those of the subkeys)
+	if len(k.SubKeys) < 1 {
+		return k.Caps.Has(p)
+	}
+	subCap := false
+	for _, sk := range k.SubKeys {
+		if !sk.Caps.Has(p) {
+			continue
+		}
+		if sk.IsValid() {
+			return true
+		}
+		subCap = true
+	}
+	// if no subkey has this capability at all but the key as a whole does,
+	// the capability must come from the primary key itself
+	return k.Caps.Has(p) && !subCap
+}
+
+// DefaultExpiryWarning is the default time window before a keys expiration
+// date in which we will warn about it
+const DefaultExpiryWarning = 30 * 24 * time.Hour
+
+// ExpiresIn returns true if the key is still valid now but will expire
+// within the given duration. If the key has encryption subkeys it will also
+// be considered expiring if all of its currently valid encryption subkeys
+// expire within that time
+func (k Key) ExpiresIn(d time.Duration) bool {
+	now := time.Now()
+	deadline := now.Add(d)
+	expiresBefore := func(t time.Time) bool {
+		return !t.IsZero() && t.After(now) && !t.After(deadline)
+	}
+
+	if expiresBefore(k.ExpirationDate) {
+		return true
+	}
+
+	found := false
+	for _, sk := range k.SubKeys {
+		if !sk.Caps.Encrypt || !sk.IsValid() {
+			continue
+		}
+		if !expiresBefore(sk.ExpirationDate) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// IsAlmostExpired returns true if the key will expire within the
+// DefaultExpiryWarning window
+func (k Key) IsAlmostExpired() bool {
+	return k.ExpiresIn(DefaultExpiryWarning)
+}
+
+// HasSecretKeyMaterial returns true if this is a secret key and at least part
+// of its secret key material is available on disk, i.e. it is not only a
+// stub pointing to a smartcard
+func (k Key) HasSecretKeyMaterial() bool {
+	if k.KeyType != "sec" {
+		return false
+	}
+	if !k.Stub {
+		return true
+	}
+	for _, sk := range k.SubKeys {
+		if sk.KeyType == "ssb" && !sk.Stub {
+			return true
+		}
+	}
+	return false
+}
+
+// String implement fmt.Stringer. This method resembles the output of
+// gpg --list-keys (or gpg -K for secret keys) for a single key. The output
+// is deterministic, i.e. identities and subkeys are always rendered in the
+// same order
 func (k Key) String() string {
-	fp := ""
-	if len(k.Fingerprint) > 24 {
-		fp = k.Fingerprint[24:]
+	caps := k.PrimaryCaps
+	if len(k.SubKeys) < 1 {
+		caps = k.Caps
 	}
-	out := fmt.Sprintf("%s   %dD/0x%s %s", k.KeyType, k.KeyLength, fp, k.CreationDate.Format("2006-01-02"))
-	if !k.ExpirationDate.IsZero() {
-		out += fmt.Sprintf(" [expires: %s]", k.ExpirationDate.Format("2006-01-02"))
+	out := keyLine(k.KeyType, algoName(k.PubKeyAlgo, k.KeyLength, k.Curve), k.CreationDate, k.ExpirationDate, caps)
+	out += "\n      " + k.Fingerprint
+	for _, id := range k.sortedIdentities() {
+		out += "\n" + id.String()
 	}
-	out += "\n      Key fingerprint = " + k.Fingerprint
-	for _, id := range k.Identities {
-		out += fmt.Sprintf("\n" + id.String())
+	for _, sk := range k.sortedSubKeys() {
+		out += "\n" + sk.String()
 	}
 	return out
 }
 
+// sortedIdentities returns the primary identity first, followed by all other
+// identities in order of their creation
+func (k Key) sortedIdentities() []Identity {
+	primary := k.Identity()
+	ids := make([]Identity, 0, len(k.Identities))
+	for _, i := range k.Identities {
+		if i == primary {
+			continue
+		}
+		ids = append(ids, i)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].CreationDate.Equal(ids[j].CreationDate) {
+			return ids[i].ID() < ids[j].ID()
+		}
+		return ids[i].CreationDate.Before(ids[j].CreationDate)
+	})
+	if len(k.Identities) > 0 {
+		ids = append([]Identity{primary}, ids...)
+	}
+	return ids
+}
+
+// sortedSubKeys returns all subkeys in order of their creation
+func (k Key) sortedSubKeys() []SubKey {
+	sks := make([]SubKey, 0, len(k.SubKeys))
+	for _, sk := range k.SubKeys {
+		sks = append(sks, sk)
+	}
+	sort.Slice(sks, func(i, j int) bool {
+		if sks[i].CreationDate.Equal(sks[j].CreationDate) {
+			return sks[i].Fingerprint < sks[j].Fingerprint
+		}
+		return sks[i].CreationDate.Before(sks[j].CreationDate)
+	})
+	return sks
+}
+
+// keyLine formats a pub, sec, sub or ssb line like gpg does
+func keyLine(typ, algo string, created, expires time.Time, caps Capabilities) string {
+	out := fmt.Sprintf("%-5s %s %s", typ, algo, created.Format("2006-01-02"))
+	if u := caps.Usage(); u != "" {
+		out += " [" + u + "]"
+	}
+	if !expires.IsZero() {
+		label := "expires"
+		if expires.Before(time.Now()) {
+			label = "expired"
+		}
+		out += fmt.Sprintf(" [%s: %s]", label, expires.Format("2006-01-02"))
+	}
+	return out
+}
+
+// algoName returns the name gpg uses for the given public key algorithm, e.g.
+// rsa4096 or ed25519
+func algoName(algo, length int, curve string) string {
+	switch algo {
+	case 1, 2, 3:
+		return fmt.Sprintf("rsa%d", length)
+	case 16, 20:
+		return fmt.Sprintf("elg%d", length)
+	case 17:
+		return fmt.Sprintf("dsa%d", length)
+	case 18, 19, 22:
+		if curve != "" {
+			return curve
+		}
+		return "ecc"
+	}
+	return "unknown"
+}
+
 // OneLine prints a terse representation of this key on one line (includes only
 // the first identity!)
 func (k Key) OneLine() string {
@@ -81,15 +309,25 @@ func (k Key) OneLine() string {
 	return fmt.Sprintf("0x%s - %s", k.Fingerprint[24:], k.Identity().ID())
 }
 
-// Identity returns the first identity
+// Identity returns the most recent identity. Revoked identities are only
+// considered if there are no other identities left
 func (k Key) Identity() Identity {
 	ids := make([]Identity, 0, len(k.Identities))
 	for _, i := range k.Identities {
 		ids = append(ids, i)
 	}
 	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].CreationDate.Equal(ids[j].CreationDate) {
+			return ids[i].ID() < ids[j].ID()
+		}
 		return ids[i].CreationDate.After(ids[j].CreationDate)
 	})
+	for _, i := range ids {
+		if i.IsRevoked() {
+			continue
+		}
+		return i
+	}
 	for _, i := range ids {
 		return i
 	}
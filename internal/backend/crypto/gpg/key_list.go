@@ -6,6 +6,13 @@ import (
 	"strings"
 )
 
+// MinQueryLength is the minimum length of a search query for FindBy
+const MinQueryLength = 4
+
+// ErrSignOnly is returned if a key can only be used for signing but
+// is requested for encryption
+var ErrSignOnly = fmt.Errorf("key can only be used for signing")
+
 // KeyList is a searchable slice of Keys
 type KeyList []Key
 
@@ -26,10 +33,15 @@ func (kl KeyList) Recipients() []string {
 
 // UseableKeys returns the list of useable (valid keys)
 func (kl KeyList) UseableKeys(alwaysTrust bool) KeyList {
+	return kl.UseableKeysFor(PurposeEncrypt, alwaysTrust)
+}
+
+// UseableKeysFor returns the list of keys that are useable for the given purpose
+func (kl KeyList) UseableKeysFor(p Purpose, alwaysTrust bool) KeyList {
 	nkl := make(KeyList, 0, len(kl))
 	sort.Sort(kl)
 	for _, k := range kl {
-		if !k.IsUseable(alwaysTrust) {
+		if !k.IsUseableFor(p, alwaysTrust) {
 			continue
 		}
 		nkl = append(nkl, k)
@@ -77,6 +89,50 @@ func (kl KeyList) FindKey(id string) (Key, error) {
 	return Key{}, fmt.Errorf("no matching key found")
 }
 
+// FindBy returns all keys that match the given query, either by fingerprint
+// suffix (which includes the key ID), by subkey ID or by any of their
+// identities. Deactivated keys and queries shorter than MinQueryLength
+// will never match
+func (kl KeyList) FindBy(q string) KeyList {
+	q = strings.TrimSpace(q)
+	if len(q) < MinQueryLength {
+		return nil
+	}
+	hq := strings.ToUpper(strings.TrimPrefix(q, "0x"))
+
+	nkl := make(KeyList, 0, 1)
+	for _, k := range kl {
+		if k.Caps.Deactivated {
+			continue
+		}
+		if k.matches(q, hq) {
+			nkl = append(nkl, k)
+		}
+	}
+	sort.Sort(nkl)
+	return nkl
+}
+
+func (k Key) matches(q, hq string) bool {
+	if hq != "" && strings.HasSuffix(strings.ToUpper(k.Fingerprint), hq) {
+		return true
+	}
+	for id, sk := range k.SubKeys {
+		if hq == "" {
+			break
+		}
+		if strings.HasSuffix(strings.ToUpper(id), hq) || strings.HasSuffix(strings.ToUpper(sk.Fingerprint), hq) {
+			return true
+		}
+	}
+	for _, ident := range k.Identities {
+		if ident.Matches(q) {
+			return true
+		}
+	}
+	return false
+}
+
 func (kl KeyList) Len() int {
 	return len(kl)
 }
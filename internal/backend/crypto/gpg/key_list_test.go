@@ -12,7 +12,7 @@ func TestKeyList(t *testing.T) {
 		genTestKey("Jane", "jane", "Doe", "jane.doe@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E0019"),
 		genTestKey("Jim", "jimmy", "Doe", "jim.doe@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E2019", "z", "none"),
 	}
-	kl[2].SubKeys = map[string]struct{}{
+	kl[2].SubKeys = map[string]SubKey{
 		"0xDEADBEEF": {},
 	}
 
@@ -59,3 +59,45 @@ func TestKeyList(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "0x62AF4031C82E2019", k.ID())
 }
+
+func TestKeyListUseableFor(t *testing.T) {
+	kl := KeyList{
+		genTestKey("John", "johnny", "Doe", "john.doe@example.org"),
+		genTestKey("Jane", "jane", "Doe", "jane.doe@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E0019"),
+	}
+	kl[1].Caps = Capabilities{Sign: true}
+
+	assert.Equal(t, []string{"0x62AF4031C82E0039"}, kl.UseableKeysFor(PurposeEncrypt, false).Recipients())
+	assert.Equal(t, []string{"0x62AF4031C82E0019"}, kl.UseableKeysFor(PurposeSign, false).Recipients())
+}
+
+func TestKeyListFindBy(t *testing.T) {
+	kl := KeyList{
+		genTestKey("Alice", "work", "Doe", "alice@example.com"),
+		genTestKey("Alice", "home", "Doe", "alice@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E0019"),
+		genTestKey("Bob", "bobby", "Doe", "bob@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E2019"),
+		genTestKey("Mallory", "old", "Doe", "mallory@example.org", "25FF1614B8F87B52FFFF99B962AF4031C82E3019"),
+	}
+	kl[3].Caps.Deactivated = true
+	kl[2].SubKeys = map[string]SubKey{
+		"DEADBEEFDEADBEEF": {},
+	}
+
+	for _, tc := range []struct {
+		q    string
+		want []string
+	}{
+		{q: "alice@example.com", want: []string{"0x62AF4031C82E0039"}},
+		{q: "ALICE", want: []string{"0x62AF4031C82E0019", "0x62AF4031C82E0039"}},
+		{q: "0x62af4031c82e2019", want: []string{"0x62AF4031C82E2019"}},
+		{q: "25FF1614B8F87B52FFFF99B962AF4031C82E0019", want: []string{"0x62AF4031C82E0019"}},
+		{q: "DEADBEEF", want: []string{"0x62AF4031C82E2019"}},
+		{q: "bobby", want: []string{"0x62AF4031C82E2019"}},
+		// too short
+		{q: "bob", want: []string{}},
+		// deactivated
+		{q: "mallory", want: []string{}},
+	} {
+		assert.Equal(t, tc.want, kl.FindBy(tc.q).Recipients(), tc.q)
+	}
+}
@@ -43,6 +43,7 @@ func genTestKey(args ...string) Key {
 	return Key{
 		KeyType:        "sec",
 		KeyLength:      2048,
+		PubKeyAlgo:     1,
 		Validity:       validity,
 		CreationDate:   creation,
 		ExpirationDate: expiration,
@@ -75,7 +76,28 @@ func TestKey(t *testing.T) {
 	assert.Equal(t, "", k.Identity().Name)
 	k = genTestKey()
 	assert.Equal(t, k.IsUseable(false), true)
-	assert.Equal(t, "sec   2048D/0x62AF4031C82E0039 2018-01-01 [expires: 2218-01-01]\n      Key fingerprint = 25FF1614B8F87B52FFFF99B962AF4031C82E0039\nuid                            John Doe (johnny) <john.doe@example.org>", k.String())
+	assert.Equal(t, "sec   rsa2048 2018-01-01 [E] [expires: 2218-01-01]\n      25FF1614B8F87B52FFFF99B962AF4031C82E0039\nuid           [ unknown] John Doe (johnny) <john.doe@example.org>", k.String())
+
+	k.SubKeys = map[string]SubKey{
+		"62AF4031C82E0040": {
+			KeyType:        "ssb",
+			KeyLength:      255,
+			PubKeyAlgo:     18,
+			Curve:          "cv25519",
+			CreationDate:   time.Date(2019, 1, 1, 1, 1, 1, 0, time.UTC),
+			ExpirationDate: time.Date(2218, 1, 1, 1, 1, 1, 0, time.UTC),
+			Caps:           Capabilities{Encrypt: true},
+		},
+		"62AF4031C82E0041": {
+			KeyType:      "ssb",
+			KeyLength:    4096,
+			PubKeyAlgo:   1,
+			CreationDate: time.Date(2018, 1, 1, 1, 1, 1, 0, time.UTC),
+			Caps:         Capabilities{Sign: true},
+		},
+	}
+	k.PrimaryCaps = Capabilities{Certify: true}
+	assert.Equal(t, "sec   rsa2048 2018-01-01 [C] [expires: 2218-01-01]\n      25FF1614B8F87B52FFFF99B962AF4031C82E0039\nuid           [ unknown] John Doe (johnny) <john.doe@example.org>\nssb   rsa4096 2018-01-01 [S]\nssb   cv25519 2019-01-01 [E] [expires: 2218-01-01]", k.String())
 	assert.Equal(t, "0x62AF4031C82E0039 - John Doe (johnny) <john.doe@example.org>", k.OneLine())
 	assert.Equal(t, "0x62AF4031C82E0039", k.ID())
 }
@@ -140,3 +162,141 @@ func TestUseability(t *testing.T) {
 		assert.True(t, k.IsUseable(false))
 	}
 }
+
+func TestUseabilitySubKeys(t *testing.T) {
+	now := time.Now()
+
+	// the only encryption subkey expired yesterday
+	k := genTestKey()
+	k.SubKeys = map[string]SubKey{
+		"62AF4031C82E0040": {
+			KeyType:        "sub",
+			Validity:       "e",
+			ExpirationDate: now.Add(-24 * time.Hour),
+			Caps:           Capabilities{Encrypt: true},
+		},
+	}
+	assert.False(t, k.IsUseable(false))
+
+	// a fresh rotation subkey next to the expired one
+	k.SubKeys["62AF4031C82E0041"] = SubKey{
+		KeyType:        "sub",
+		Validity:       "u",
+		CreationDate:   now.Add(-time.Hour),
+		ExpirationDate: now.Add(365 * 24 * time.Hour),
+		Caps:           Capabilities{Encrypt: true},
+	}
+	assert.True(t, k.IsUseable(false))
+
+	// a revoked encryption subkey is not useable either
+	k.SubKeys["62AF4031C82E0041"] = SubKey{
+		KeyType:  "sub",
+		Validity: "r",
+		Caps:     Capabilities{Encrypt: true},
+	}
+	assert.False(t, k.IsUseable(false))
+
+	// only a signing subkey, encryption is provided by the primary key
+	k.SubKeys = map[string]SubKey{
+		"62AF4031C82E0042": {
+			KeyType: "sub",
+			Caps:    Capabilities{Sign: true},
+		},
+	}
+	assert.True(t, k.IsUseable(false))
+}
+
+func TestKeyIdentityRevoked(t *testing.T) {
+	k := genTestKey()
+	k.Identities["Old Employer"] = Identity{
+		Name:         "John Doe",
+		Email:        "john.doe@old-employer.com",
+		Validity:     "r",
+		CreationDate: time.Date(2020, 1, 1, 1, 1, 1, 0, time.UTC),
+	}
+	// the revoked identity is newer but must not be picked
+	assert.Equal(t, "0x62AF4031C82E0039 - John Doe (johnny) <john.doe@example.org>", k.OneLine())
+
+	// if all identities are revoked we still return one
+	k = Key{
+		Fingerprint: "25FF1614B8F87B52FFFF99B962AF4031C82E0039",
+		Identities: map[string]Identity{
+			"Old Employer": {
+				Name:     "John Doe",
+				Email:    "john.doe@old-employer.com",
+				Validity: "r",
+			},
+		},
+	}
+	assert.Equal(t, "0x62AF4031C82E0039 - John Doe <john.doe@old-employer.com>", k.OneLine())
+}
+
+func TestUseabilityPurpose(t *testing.T) {
+	k := genTestKey()
+	k.Caps = Capabilities{Sign: true, Certify: true}
+	assert.False(t, k.IsUseable(false))
+	assert.False(t, k.IsUseableFor(PurposeEncrypt, false))
+	assert.True(t, k.IsUseableFor(PurposeSign, false))
+	assert.True(t, k.IsUseableFor(PurposeCertify, false))
+	assert.False(t, k.IsUseableFor(PurposeAuthenticate, false))
+
+	// the signing subkey expired, only certify is left
+	k.SubKeys = map[string]SubKey{
+		"62AF4031C82E0040": {
+			ExpirationDate: time.Now().Add(-time.Hour),
+			Caps:           Capabilities{Sign: true},
+		},
+	}
+	assert.False(t, k.IsUseableFor(PurposeSign, false))
+	assert.True(t, k.IsUseableFor(PurposeCertify, false))
+
+	assert.Equal(t, "signing", PurposeSign.String())
+}
+
+func TestExpiresIn(t *testing.T) {
+	now := time.Now()
+
+	soon := genTestKey()
+	soon.ExpirationDate = now.Add(10 * 24 * time.Hour)
+	later := genTestKey()
+	later.ExpirationDate = now.Add(90 * 24 * time.Hour)
+
+	assert.True(t, soon.ExpiresIn(30*24*time.Hour))
+	assert.True(t, soon.IsAlmostExpired())
+	assert.False(t, later.ExpiresIn(30*24*time.Hour))
+	assert.False(t, later.IsAlmostExpired())
+
+	// already expired keys are not expiring
+	expired := genTestKey()
+	expired.ExpirationDate = now.Add(-time.Hour)
+	assert.False(t, expired.IsAlmostExpired())
+
+	// the only encryption subkey expires soon
+	sub := genTestKey()
+	sub.SubKeys = map[string]SubKey{
+		"62AF4031C82E0040": {
+			ExpirationDate: now.Add(10 * 24 * time.Hour),
+			Caps:           Capabilities{Encrypt: true},
+		},
+	}
+	assert.True(t, sub.IsAlmostExpired())
+
+	// but a rotation subkey is valid for longer
+	sub.SubKeys["62AF4031C82E0041"] = SubKey{
+		ExpirationDate: now.Add(90 * 24 * time.Hour),
+		Caps:           Capabilities{Encrypt: true},
+	}
+	assert.False(t, sub.IsAlmostExpired())
+}
+
+func TestHasSecretKeyMaterial(t *testing.T) {
+	assert.False(t, Key{KeyType: "pub"}.HasSecretKeyMaterial())
+	assert.True(t, Key{KeyType: "sec"}.HasSecretKeyMaterial())
+	assert.False(t, Key{KeyType: "sec", Stub: true}.HasSecretKeyMaterial())
+	assert.False(t, Key{KeyType: "sec", Stub: true, SubKeys: map[string]SubKey{
+		"921948EA957021AC": {KeyType: "ssb", Stub: true, CardSerial: "D2760001240103040006123456780000"},
+	}}.HasSecretKeyMaterial())
+	assert.True(t, Key{KeyType: "sec", Stub: true, SubKeys: map[string]SubKey{
+		"921948EA957021AC": {KeyType: "ssb"},
+	}}.HasSecretKeyMaterial())
+}
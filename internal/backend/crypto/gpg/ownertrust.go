@@ -0,0 +1,88 @@
+package gpg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ownertrust maps key fingerprints to their ownertrust level in the format
+// used by gpg --export-ownertrust and --import-ownertrust
+type Ownertrust map[string]string
+
+// ParseOwnertrust parses the output of gpg --export-ownertrust. Comments and
+// invalid lines are ignored
+func ParseOwnertrust(buf []byte) Ownertrust {
+	ot := make(Ownertrust, 8)
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// FINGERPRINT:LEVEL:
+		p := strings.Split(line, ":")
+		if len(p) < 2 || p[0] == "" || p[1] == "" {
+			continue
+		}
+		ot[strings.ToUpper(p[0])] = p[1]
+	}
+	return ot
+}
+
+// Bytes returns the ownertrust in the format accepted by gpg --import-ownertrust,
+// sorted by fingerprint
+func (o Ownertrust) Bytes() []byte {
+	fps := make([]string, 0, len(o))
+	for fp := range o {
+		fps = append(fps, fp)
+	}
+	sort.Strings(fps)
+
+	buf := &bytes.Buffer{}
+	for _, fp := range fps {
+		fmt.Fprintf(buf, "%s:%s:\n", fp, o[fp])
+	}
+	return buf.Bytes()
+}
+
+// Level returns a human readable name for the ownertrust level of the given
+// fingerprint
+func (o Ownertrust) Level(fp string) string {
+	switch o[strings.ToUpper(fp)] {
+	case "2":
+		return TrustName("q")
+	case "3":
+		return TrustName("n")
+	case "4":
+		return TrustName("m")
+	case "5":
+		return TrustName("f")
+	case "6":
+		return TrustName("u")
+	}
+	return TrustName("-")
+}
+
+// TrustName returns a human readable name for the given validity
+// or ownertrust value
+func TrustName(v string) string {
+	return strings.TrimSpace(validityLabel(v))
+}
+
+// IsUntrusted returns true if the validity of this key is unknown or
+// undefined, i.e. it can't be used without --always-trust
+func (k Key) IsUntrusted() bool {
+	switch k.Validity {
+	case "", "-", "q", "o":
+		return true
+	}
+	return false
+}
+
+// TrustLine returns a terse description of the trust of this key
+func (k Key) TrustLine() string {
+	return fmt.Sprintf("ownertrust: %s, validity: %s", TrustName(k.Ownertrust), TrustName(k.Validity))
+}
@@ -0,0 +1,43 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnertrust(t *testing.T) {
+	in := `# List of assigned trustvalues, created Wed Oct 14 12:00:00 2026 UTC
+# (Use "gpg --import-ownertrust" to restore them)
+af7599b4bd71f6a02fe45e4d731ebb1e19b0d9ed:6:
+2B2C0B0F6C2B9C32D7B66B8B0516F3A5F1F0B9C3:4:
+invalid
+:5:
+`
+	ot := ParseOwnertrust([]byte(in))
+	assert.Equal(t, Ownertrust{
+		"AF7599B4BD71F6A02FE45E4D731EBB1E19B0D9ED": "6",
+		"2B2C0B0F6C2B9C32D7B66B8B0516F3A5F1F0B9C3": "4",
+	}, ot)
+
+	assert.Equal(t, "2B2C0B0F6C2B9C32D7B66B8B0516F3A5F1F0B9C3:4:\nAF7599B4BD71F6A02FE45E4D731EBB1E19B0D9ED:6:\n", string(ot.Bytes()))
+	assert.Equal(t, ot, ParseOwnertrust(ot.Bytes()))
+
+	assert.Equal(t, "ultimate", ot.Level("af7599b4bd71f6a02fe45e4d731ebb1e19b0d9ed"))
+	assert.Equal(t, "marginal", ot.Level("2B2C0B0F6C2B9C32D7B66B8B0516F3A5F1F0B9C3"))
+	assert.Equal(t, "unknown", ot.Level("DEADBEEF"))
+}
+
+func TestTrust(t *testing.T) {
+	k := Key{Validity: "-", Ownertrust: "f"}
+	assert.True(t, k.IsUntrusted())
+	assert.Equal(t, "ownertrust: full, validity: unknown", k.TrustLine())
+
+	k.Validity = "q"
+	assert.True(t, k.IsUntrusted())
+
+	k.Validity = "m"
+	assert.False(t, k.IsUntrusted())
+	assert.Equal(t, "marginal", TrustName("m"))
+	assert.Equal(t, "undef", TrustName("q"))
+}
@@ -0,0 +1,97 @@
+package gpg
+
+// Strength classes of key algorithms, see Strength
+const (
+	// StrengthOK keys are considered secure for the foreseeable future
+	StrengthOK = "ok"
+	// StrengthLegacy keys are still considered secure but should be
+	// replaced, e.g. RSA 2048
+	StrengthLegacy = "legacy"
+	// StrengthWeak keys must be replaced, e.g. RSA 1024 or DSA
+	StrengthWeak = "weak"
+	// StrengthUnknown is used for algorithms and curves not in the table
+	StrengthUnknown = "unknown"
+)
+
+// strengthCurves classifies the ECC curves supported by gpg
+var strengthCurves = map[string]string{
+	"ed25519":         StrengthOK,
+	"cv25519":         StrengthOK,
+	"curve25519":      StrengthOK,
+	"ed448":           StrengthOK,
+	"cv448":           StrengthOK,
+	"nistp256":        StrengthOK,
+	"nistp384":        StrengthOK,
+	"nistp521":        StrengthOK,
+	"brainpoolP256r1": StrengthOK,
+	"brainpoolP384r1": StrengthOK,
+	"brainpoolP512r1": StrengthOK,
+	"secp256k1":       StrengthLegacy,
+}
+
+// strengthRank orders the classes from strong to weak
+var strengthRank = map[string]int{
+	StrengthOK:      0,
+	StrengthUnknown: 1,
+	StrengthLegacy:  2,
+	StrengthWeak:    3,
+}
+
+// Strength classifies a public key algorithm, as listed by gpg --with-colons,
+// and its key length or curve. RSA and ElGamal keys shorter than 2048 bits
+// and all DSA keys are weak, 2048 bit keys are legacy. ECC keys are
+// classified by their curve.
+func Strength(algo, length int, curve string) string {
+	switch algo {
+	case 1, 2, 3, 16, 20:
+		switch {
+		case length < 2048:
+			return StrengthWeak
+		case length < 3072:
+			return StrengthLegacy
+		}
+		return StrengthOK
+	case 17:
+		return StrengthWeak
+	case 18, 19, 22:
+		if s, found := strengthCurves[curve]; found {
+			return s
+		}
+	}
+	return StrengthUnknown
+}
+
+// Strength returns the strength of the primary key and the subkeys used to
+// encrypt for this key, whichever is the weakest
+func (k Key) Strength() string {
+	class, _ := k.weakest()
+	return class
+}
+
+// WeakestAlgorithm returns the name of the algorithm, e.g. rsa1024, of the
+// part of this key that determines its Strength
+func (k Key) WeakestAlgorithm() string {
+	_, algo := k.weakest()
+	return algo
+}
+
+// weakest returns the strength and the algorithm name of the weakest of the
+// primary key and the valid encryption subkeys
+func (k Key) weakest() (string, string) {
+	class := Strength(k.PubKeyAlgo, k.KeyLength, k.Curve)
+	algo := algoName(k.PubKeyAlgo, k.KeyLength, k.Curve)
+	for _, sk := range k.sortedSubKeys() {
+		if !sk.Caps.Encrypt || !sk.IsValid() {
+			continue
+		}
+		if c := sk.Strength(); strengthRank[c] > strengthRank[class] {
+			class, algo = c, algoName(sk.PubKeyAlgo, sk.KeyLength, sk.Curve)
+		}
+	}
+	return class, algo
+}
+
+// Strength returns the strength of this subkey
+func (s SubKey) Strength() string {
+	return Strength(s.PubKeyAlgo, s.KeyLength, s.Curve)
+}
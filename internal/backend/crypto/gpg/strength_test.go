@@ -0,0 +1,64 @@
+package gpg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrength(t *testing.T) {
+	for _, tc := range []struct {
+		algo   int
+		length int
+		curve  string
+		want   string
+	}{
+		{algo: 1, length: 1024, want: StrengthWeak},
+		{algo: 1, length: 2047, want: StrengthWeak},
+		{algo: 1, length: 2048, want: StrengthLegacy},
+		{algo: 1, length: 3072, want: StrengthOK},
+		{algo: 1, length: 4096, want: StrengthOK},
+		{algo: 16, length: 1024, want: StrengthWeak},
+		{algo: 17, length: 3072, want: StrengthWeak},
+		{algo: 22, length: 255, curve: "ed25519", want: StrengthOK},
+		{algo: 18, length: 255, curve: "cv25519", want: StrengthOK},
+		{algo: 19, length: 256, curve: "nistp256", want: StrengthOK},
+		{algo: 19, length: 256, curve: "secp256k1", want: StrengthLegacy},
+		{algo: 19, length: 256, curve: "frp256v1", want: StrengthUnknown},
+		{algo: 0, length: 0, want: StrengthUnknown},
+	} {
+		assert.Equal(t, tc.want, Strength(tc.algo, tc.length, tc.curve), "%d/%d/%s", tc.algo, tc.length, tc.curve)
+	}
+}
+
+func TestKeyStrength(t *testing.T) {
+	k := Key{
+		PubKeyAlgo: 22,
+		KeyLength:  255,
+		Curve:      "ed25519",
+		SubKeys: map[string]SubKey{
+			"enc": {
+				PubKeyAlgo: 1,
+				KeyLength:  1024,
+				Caps:       Capabilities{Encrypt: true},
+			},
+			"sign": {
+				PubKeyAlgo: 17,
+				KeyLength:  1024,
+				Caps:       Capabilities{Sign: true},
+			},
+		},
+	}
+	// the encryption subkey is weak, the signing subkey doesn't matter
+	assert.Equal(t, StrengthWeak, k.Strength())
+	assert.Equal(t, StrengthWeak, k.SubKeys["enc"].Strength())
+	assert.Equal(t, "rsa1024", k.WeakestAlgorithm())
+
+	// expired subkeys are not used to encrypt
+	enc := k.SubKeys["enc"]
+	enc.ExpirationDate = time.Now().Add(-time.Hour)
+	k.SubKeys["enc"] = enc
+	assert.Equal(t, StrengthOK, k.Strength())
+	assert.Equal(t, "ed25519", k.WeakestAlgorithm())
+}
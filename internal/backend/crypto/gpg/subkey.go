@@ -0,0 +1,47 @@
+package gpg
+
+import "time"
+
+// SubKey is a GPG subkey (public or secret). Subkeys carry their own
+// capabilities and expiration, independent of the primary key
+type SubKey struct {
+	KeyType        string
+	KeyLength      int
+	Validity       string
+	Fingerprint    string
+	PubKeyAlgo     int
+	Curve          string
+	CreationDate   time.Time
+	ExpirationDate time.Time
+	Caps           Capabilities
+	CardSerial     string // serial number of the smartcard holding the secret subkey, if any
+	Stub           bool   // the secret subkey is not available on disk
+	Keygrip        string // identifies the subkey in the gpg-agent, if listed with --with-keygrip
+}
+
+// IsExpired returns true if this subkey has an expiration date in the past
+func (s SubKey) IsExpired() bool {
+	if s.Validity == "e" {
+		return true
+	}
+	return !s.ExpirationDate.IsZero() && s.ExpirationDate.Before(time.Now())
+}
+
+// IsRevoked returns true if this subkey has been revoked
+func (s SubKey) IsRevoked() bool {
+	return s.Validity == "r"
+}
+
+// IsValid returns true if this subkey is neither expired, revoked nor disabled
+func (s SubKey) IsValid() bool {
+	if s.Caps.Deactivated {
+		return false
+	}
+	return !s.IsExpired() && !s.IsRevoked()
+}
+
+// String implement fmt.Stringer. This method resembles the output gpg uses
+// for subkeys
+func (s SubKey) String() string {
+	return keyLine(s.KeyType, algoName(s.PubKeyAlgo, s.KeyLength, s.Curve), s.CreationDate, s.ExpirationDate, s.Caps)
+}
@@ -4,6 +4,7 @@ package plain
 import (
 	"context"
 	"fmt"
+	"io"
 	"runtime"
 	"strings"
 	"time"
@@ -96,6 +97,18 @@ func (m *Mocker) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
 	return ciphertext, nil
 }
 
+// EncryptStream copies the input unaltered
+func (m *Mocker) EncryptStream(ctx context.Context, r io.Reader, recipients []string, w io.Writer) error {
+	_, err := io.Copy(w, r)
+	return err
+}
+
+// DecryptStream copies the input unaltered
+func (m *Mocker) DecryptStream(ctx context.Context, r io.Reader, w io.Writer) error {
+	_, err := io.Copy(w, r)
+	return err
+}
+
 // ExportPublicKey does nothing
 func (m *Mocker) ExportPublicKey(context.Context, string) ([]byte, error) {
 	return nil, nil
@@ -107,8 +120,8 @@ func (m *Mocker) ImportPublicKey(context.Context, []byte) error {
 }
 
 // Version returns dummy version info
-func (m *Mocker) Version(context.Context) semver.Version {
-	return semver.Version{}
+func (m *Mocker) Version(context.Context) (semver.Version, error) {
+	return semver.Version{}, nil
 }
 
 // Binary always returns 'gpg'
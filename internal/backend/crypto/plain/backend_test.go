@@ -1,8 +1,10 @@
 package plain
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
@@ -43,6 +45,10 @@ func TestPlain(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, string(content), "foobar")
 
+	stream := &bytes.Buffer{}
+	assert.NoError(t, m.EncryptStream(ctx, strings.NewReader("foobar"), nil, stream))
+	assert.Equal(t, "foobar", stream.String())
+
 	assert.Equal(t, "gpg", m.Binary())
 
 	assert.Error(t, m.GenerateIdentity(ctx, "", "", ""))
@@ -61,7 +67,9 @@ func TestPlain(t *testing.T) {
 	buf, err = m.ExportPublicKey(ctx, "")
 	assert.NoError(t, err)
 	assert.NoError(t, m.ImportPublicKey(ctx, buf))
-	assert.Equal(t, semver.Version{}, m.Version(ctx))
+	v, err := m.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, semver.Version{}, v)
 
 	assert.Equal(t, "", m.FormatKey(ctx, "", ""))
 	assert.Equal(t, "", m.Fingerprint(ctx, ""))
@@ -21,6 +21,7 @@ type rcs interface {
 
 	Revisions(ctx context.Context, name string) ([]Revision, error)
 	GetRevision(ctx context.Context, name, revision string) ([]byte, error)
+	Deleted(ctx context.Context) ([]Deletion, error)
 
 	Status(ctx context.Context) ([]byte, error)
 	Compact(ctx context.Context) error
@@ -34,6 +35,62 @@ type Revision struct {
 	Date        time.Time
 	Subject     string
 	Body        string
+	// Trailers are the git trailers of the commit message, e.g. Gopass-Op.
+	// Only set by Changes.
+	Trailers map[string]string
+}
+
+// Deletion is a file that was removed in a SCM revision
+type Deletion struct {
+	Revision
+	Name string
+}
+
+// The operations of a Change
+const (
+	ChangeCreated     = "created"
+	ChangeUpdated     = "updated"
+	ChangeDeleted     = "deleted"
+	ChangeRenamed     = "renamed"
+	ChangeReencrypted = "re-encrypted"
+	ChangeRecipients  = "recipients"
+)
+
+// Change is a change of a single file in a SCM revision
+type Change struct {
+	Revision
+	// Op is one of the Change* operations. The storage only knows about
+	// files being created, updated, deleted or renamed.
+	Op   string
+	Name string
+	// OldName is the previous name of a renamed file
+	OldName string
+	// Added and Removed are the recipients added to or removed from a
+	// recipients file
+	Added   []string
+	Removed []string
+}
+
+// SignatureProblem is a commit without a valid signature
+type SignatureProblem struct {
+	Revision
+	// Problem describes what's wrong with the signature, e.g. "unsigned"
+	Problem string
+}
+
+// HistoryCompaction describes the rewrite of a history that keeps only the
+// last revisions of each file
+type HistoryCompaction struct {
+	Commits       int
+	KeptCommits   int
+	Revisions     int
+	KeptRevisions int
+	// Size is the size of the repository and Savings the size of the objects
+	// only used by the removed revisions, both in bytes
+	Size    int64
+	Savings int64
+	// Remote is the URL of the remote the new history was pushed to, if any
+	Remote string
 }
 
 // Revisions implements the sort interface
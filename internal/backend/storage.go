@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -40,6 +41,7 @@ type Storage interface {
 	IsDir(ctx context.Context, name string) bool
 	Prune(ctx context.Context, prefix string) error
 	Link(ctx context.Context, from, to string) error
+	Meta(ctx context.Context, name string) (Meta, error)
 
 	Name() string
 	Path() string
@@ -47,6 +49,23 @@ type Storage interface {
 	Fsck(context.Context) error
 }
 
+// Meta describes when an entity was created and last changed. It's derived
+// from the history of the storage, backends without any return the zero
+// value.
+type Meta struct {
+	Created  time.Time
+	Modified time.Time
+	// Author is the name of the last one who changed the entity
+	Author string
+	// Revision identifies the last change, e.g. the git commit
+	Revision string
+}
+
+// IsZero returns true if nothing is known about the entity
+func (m Meta) IsZero() bool {
+	return m == Meta{}
+}
+
 // RegisterStorage registers a new storage backend with the registry.
 func RegisterStorage(id StorageBackend, name string, loader StorageLoader) {
 	storageRegistry[id] = loader
@@ -0,0 +1,158 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+// BackupExt is appended to the name of a secret to get the name of the copy
+// of its previous version
+const BackupExt = ".bak"
+
+// tempFile is the part of os.File used to write a file atomically
+type tempFile interface {
+	io.Writer
+	Name() string
+	Chmod(os.FileMode) error
+	Sync() error
+	Close() error
+}
+
+// createTemp creates the temp file new content is written to. It's a variable
+// so that tests can inject write errors.
+var createTemp = func(dir, pattern string) (tempFile, error) {
+	return os.CreateTemp(dir, pattern)
+}
+
+// backups are the copies of the previous versions of changed secrets that are
+// kept until the changes have been committed
+type backups struct {
+	mu    sync.Mutex
+	files map[string]struct{}
+}
+
+// writeFile replaces the content of filename atomically. The content is
+// written to a temp file in the same directory which is renamed over the
+// target once it has been synced to disk. A crash will leave either the old
+// or the new content, but never a truncated file. If reading the content
+// from r fails the target is left untouched. Existing files keep their
+// permissions.
+func (s *Store) writeFile(ctx context.Context, filename string, r io.Reader, perm os.FileMode) error {
+	if fi, err := os.Stat(filename); err == nil {
+		perm = fi.Mode().Perm()
+	}
+
+	dir := filepath.Dir(filename)
+	tmp, err := createTemp(dir, "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	keep := false
+	defer func() {
+		if keep {
+			return
+		}
+		if err := os.Remove(tmpName); err != nil && !os.IsNotExist(err) {
+			debug.Log("failed to remove temp file %s: %s", tmpName, err)
+		}
+	}()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set permissions of %s: %w", filename, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", filename, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", filename, err)
+	}
+
+	if ctxutil.IsKeepBackup(ctx) && fsutil.IsFile(filename) {
+		if err := s.backup(filename); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", filename, err)
+	}
+	keep = true
+
+	// the rename is only durable once the directory has been synced, too
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", dir, err)
+	}
+	return nil
+}
+
+// backup keeps the current version of filename as filename.bak until
+// RemoveBackups is called. Further changes before that keep the existing
+// backup, so it always holds the last committed version.
+func (s *Store) backup(filename string) error {
+	bak := filename + BackupExt
+
+	s.backups.mu.Lock()
+	defer s.backups.mu.Unlock()
+	if _, found := s.backups.files[bak]; found && fsutil.IsFile(bak) {
+		return nil
+	}
+
+	if err := os.Remove(bak); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old backup %s: %w", bak, err)
+	}
+	// a hard link keeps the old content around without copying it, the
+	// rename only replaces the name of the original
+	if err := os.Link(filename, bak); err != nil {
+		debug.Log("failed to link %s to %s: %s. Copying", filename, bak, err)
+		buf, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to backup %s: %w", filename, err)
+		}
+		if err := os.WriteFile(bak, buf, 0600); err != nil {
+			return fmt.Errorf("failed to backup %s: %w", filename, err)
+		}
+	}
+	debug.Log("kept previous version of %s as %s", filename, bak)
+
+	if s.backups.files == nil {
+		s.backups.files = make(map[string]struct{}, 1)
+	}
+	s.backups.files[bak] = struct{}{}
+	return nil
+}
+
+// RemoveBackups removes the copies of the previous versions of all secrets
+// changed since the last call. Call it once the changes have been committed.
+func (s *Store) RemoveBackups() {
+	s.backups.mu.Lock()
+	defer s.backups.mu.Unlock()
+
+	names := make([]string, 0, len(s.backups.files))
+	for bak := range s.backups.files {
+		if err := os.Remove(bak); err != nil && !os.IsNotExist(err) {
+			debug.Log("failed to remove backup %s: %s", bak, err)
+			continue
+		}
+		debug.Log("removed backup %s", bak)
+		if name, err := filepath.Rel(s.path, bak); err == nil {
+			names = append(names, name)
+		}
+	}
+	s.backups.files = nil
+	s.updateIndex(names...)
+}
@@ -0,0 +1,118 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingFile fails after writing the first few bytes
+type failingFile struct {
+	*os.File
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p[:len(p)/2])
+	if err != nil {
+		return n, err
+	}
+	return n, errors.New("disk on fire")
+}
+
+func TestSetWriteError(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	s := New(path)
+
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("original content")))
+
+	createTemp = func(dir, pattern string) (tempFile, error) {
+		f, err := os.CreateTemp(dir, pattern)
+		if err != nil {
+			return nil, err
+		}
+		return &failingFile{File: f}, nil
+	}
+	defer func() {
+		createTemp = func(dir, pattern string) (tempFile, error) {
+			return os.CreateTemp(dir, pattern)
+		}
+	}()
+
+	err := s.Set(ctx, "foo.gpg", []byte("new content which is never written completely"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk on fire")
+
+	buf, err := s.Get(ctx, "foo.gpg")
+	require.NoError(t, err)
+	assert.Equal(t, "original content", string(buf))
+
+	// the temp file is gone
+	files, err := os.ReadDir(path)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "foo.gpg", files[0].Name())
+}
+
+func TestSetKeepsPermissions(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	s := New(path)
+	fn := filepath.Join(path, "foo.gpg")
+
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("foo")))
+	require.NoError(t, os.Chmod(fn, 0600))
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("bar")))
+
+	fi, err := os.Stat(fn)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+}
+
+func TestBackup(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir()
+	s := New(path)
+	fn := filepath.Join(path, "foo.gpg")
+
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("first")))
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("second")))
+	assert.NoFileExists(t, fn+BackupExt)
+
+	ctx = ctxutil.WithKeepBackup(ctx, true)
+	require.NoError(t, s.Set(ctx, "bar.gpg", []byte("new")))
+	assert.NoFileExists(t, filepath.Join(path, "bar.gpg"+BackupExt))
+
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("third")))
+	buf, err := os.ReadFile(fn + BackupExt)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(buf))
+
+	// the backup always holds the last committed version
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("third again")))
+	buf, err = os.ReadFile(fn + BackupExt)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(buf))
+
+	s.RemoveBackups()
+	assert.NoFileExists(t, fn+BackupExt)
+	require.NoError(t, s.Set(ctx, "foo.gpg", []byte("fourth")))
+	buf, err = os.ReadFile(fn + BackupExt)
+	require.NoError(t, err)
+	assert.Equal(t, "third again", string(buf))
+
+	buf, err = s.Get(ctx, "foo.gpg")
+	require.NoError(t, err)
+	assert.Equal(t, "fourth", string(buf))
+
+	// without git the change is final once it's added
+	assert.Error(t, s.Add(ctx, "foo.gpg"))
+	assert.NoFileExists(t, fn+BackupExt)
+}
@@ -1,13 +1,22 @@
 package fs
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"syscall"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/ignore"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -15,30 +24,57 @@ import (
 	"github.com/gopasspw/gopass/pkg/termio"
 )
 
-// Fsck checks the storage integrity
+// Fsck checks the storage integrity. Permissions which are too wide, files
+// owned by someone else and leftover temp files are only fixed with
+// backend.IsFsckFix.
 func (s *Store) Fsck(ctx context.Context) error {
 	pcb := ctxutil.GetProgressCallback(ctx)
 
+	// permissions and owners can't be checked on e.g. FAT file systems
+	posix := hasPOSIXPerms(s.path)
+	if !posix {
+		out.Noticef(ctx, "%s doesn't support POSIX permissions. Not checking permissions and owners", s.path)
+	}
+
 	entries, err := s.List(ctx, "")
 	if err != nil {
 		return err
 	}
+	// files matched by the ignore rules, e.g. notes, aren't secrets
+	buf, err := os.ReadFile(filepath.Join(s.path, ignore.File))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		debug.Log("failed to read %s: %s", ignore.File, err)
+	}
+	im := ignore.New(buf)
+
 	dirs := make(map[string]struct{}, len(entries))
 	for _, entry := range entries {
 		pcb()
 		debug.Log("checking entry %q", entry)
 
 		filename := filepath.Join(s.path, entry)
+		if isTempFile(entry) {
+			s.fsckRemoveTempFile(ctx, filename)
+			continue
+		}
 		dirs[filepath.Dir(filename)] = struct{}{}
 
-		if err := s.fsckCheckFile(ctx, filename); err != nil {
+		if posix {
+			if err := s.fsckCheckFile(ctx, filename); err != nil {
+				return err
+			}
+		}
+		if im.Match(entry) {
+			continue
+		}
+		if err := fsckCheckPlaintext(ctx, filename); err != nil {
 			return err
 		}
 	}
 
 	for dir := range dirs {
 		debug.Log("checking dir %q", dir)
-		if err := s.fsckCheckDir(ctx, dir); err != nil {
+		if err := s.fsckCheckDir(ctx, dir, posix); err != nil {
 			return err
 		}
 	}
@@ -48,7 +84,7 @@ func (s *Store) Fsck(ctx context.Context) error {
 	}
 
 	debug.Log("checking root dir %q", s.path)
-	if err := s.fsckCheckDir(ctx, s.path); err != nil {
+	if err := s.fsckCheckDir(ctx, s.path, posix); err != nil {
 		return err
 	}
 
@@ -62,11 +98,17 @@ func (s *Store) fsckCheckFile(ctx context.Context, filename string) error {
 		return err
 	}
 
+	fsckCheckOwner(ctx, filename, fi)
+
 	if fi.Mode().Perm()&0177 == 0 {
 		return nil
 	}
 
 	out.Printf(ctx, "Permissions too wide: %s (%s)", filename, fi.Mode().String())
+	if !backend.IsFsckFix(ctx) {
+		out.Printf(ctx, "  Run fsck with the --fix flag to set them to rw-------")
+		return nil
+	}
 
 	np := uint32(fi.Mode().Perm() & 0600)
 	out.Printf(ctx, "  Fixing permissions from %s to %s", fi.Mode().Perm().String(), os.FileMode(np).Perm().String())
@@ -76,21 +118,28 @@ func (s *Store) fsckCheckFile(ctx context.Context, filename string) error {
 	return nil
 }
 
-func (s *Store) fsckCheckDir(ctx context.Context, dirname string) error {
+func (s *Store) fsckCheckDir(ctx context.Context, dirname string, posix bool) error {
 	fi, err := os.Stat(dirname)
 	if err != nil {
 		return err
 	}
 
+	if posix {
+		fsckCheckOwner(ctx, dirname, fi)
+	}
+
 	// check if any group or other perms are set,
 	// i.e. check for perms other than rwx------
-	if fi.Mode().Perm()&077 != 0 {
+	if posix && fi.Mode().Perm()&077 != 0 {
 		out.Printf(ctx, "Permissions too wide %s on dir %s", fi.Mode().Perm().String(), dirname)
-
-		np := uint32(fi.Mode().Perm() & 0700)
-		out.Printf(ctx, "  Fixing permissions from %s to %s", fi.Mode().Perm().String(), os.FileMode(np).Perm().String())
-		if err := syscall.Chmod(dirname, np); err != nil {
-			out.Errorf(ctx, "  Failed to set permissions for %s to rwx------: %s", dirname, err)
+		if backend.IsFsckFix(ctx) {
+			np := uint32(fi.Mode().Perm() & 0700)
+			out.Printf(ctx, "  Fixing permissions from %s to %s", fi.Mode().Perm().String(), os.FileMode(np).Perm().String())
+			if err := syscall.Chmod(dirname, np); err != nil {
+				out.Errorf(ctx, "  Failed to set permissions for %s to rwx------: %s", dirname, err)
+			}
+		} else {
+			out.Printf(ctx, "  Run fsck with the --fix flag to set them to rwx------")
 		}
 	}
 
@@ -106,6 +155,125 @@ func (s *Store) fsckCheckDir(ctx context.Context, dirname string) error {
 	return nil
 }
 
+// fsckCheckOwner reports files and directories not owned by the current user.
+// Changing the owner usually requires root, failures are only reported.
+func fsckCheckOwner(ctx context.Context, filename string, fi os.FileInfo) {
+	uid, found := fileOwner(fi)
+	if !found || uid == os.Getuid() {
+		return
+	}
+
+	out.Printf(ctx, "Owned by another user (uid %d): %s", uid, filename)
+	if !backend.IsFsckFix(ctx) {
+		out.Printf(ctx, "  Run fsck with the --fix flag to change the owner to uid %d", os.Getuid())
+		return
+	}
+	if !termio.Confirm(ctx, fmt.Sprintf("  Change the owner of %s to uid %d?", filename, os.Getuid())) {
+		return
+	}
+	if err := os.Lchown(filename, os.Getuid(), os.Getgid()); err != nil {
+		out.Errorf(ctx, "  Failed to change the owner of %s: %s", filename, err)
+	}
+}
+
+// reTempFile matches the temp files of interrupted writes, see writeFile
+var reTempFile = regexp.MustCompile(`^(\..+\.tmp\d+|\.tmp-.*)$`)
+
+func isTempFile(entry string) bool {
+	return reTempFile.MatchString(path.Base(entry))
+}
+
+// fsckRemoveTempFile removes a temp file left over by an interrupted write.
+// The storage is locked during fsck, so no write is in progress.
+func (s *Store) fsckRemoveTempFile(ctx context.Context, filename string) {
+	out.Printf(ctx, "Leftover temp file of an interrupted write: %s", filename)
+	if !backend.IsFsckFix(ctx) {
+		out.Printf(ctx, "  Run fsck with the --fix flag to remove it")
+		return
+	}
+	if !termio.Confirm(ctx, fmt.Sprintf("  Remove %s?", filename)) {
+		return
+	}
+	if err := os.Remove(filename); err != nil {
+		out.Errorf(ctx, "  Failed to remove %s: %s", filename, err)
+		return
+	}
+	if name, err := filepath.Rel(s.path, filename); err == nil {
+		s.updateIndex(filepath.ToSlash(name))
+	}
+}
+
+// fsckCheckPlaintext reports secrets that don't start like an OpenPGP or an
+// age message, e.g. ones copied into the store by hand. They are never
+// changed, only the user can tell what they are.
+func fsckCheckPlaintext(ctx context.Context, filename string) error {
+	if ext := filepath.Ext(filename); ext != ".gpg" && ext != ".age" {
+		return nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 64)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+	if looksEncrypted(buf[:n]) {
+		return nil
+	}
+
+	out.Errorf(ctx, "Not encrypted: %s does not look like an OpenPGP or age message. It might contain a secret in plain text", filename)
+	return nil
+}
+
+func looksEncrypted(buf []byte) bool {
+	for _, magic := range []string{
+		"age-encryption.org/",
+		"-----BEGIN AGE ENCRYPTED FILE-----",
+		"-----BEGIN PGP MESSAGE-----",
+	} {
+		if bytes.HasPrefix(buf, []byte(magic)) {
+			return true
+		}
+	}
+	// binary OpenPGP packets always have the high bit of the tag set
+	return len(buf) > 0 && buf[0]&0x80 != 0
+}
+
+// hasPOSIXPerms probes if the permissions of files in dir can be changed.
+// Some file systems, e.g. FAT, report the same permissions for every file.
+func hasPOSIXPerms(dir string) bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+
+	f, err := os.CreateTemp(dir, ".fsck.tmp")
+	if err != nil {
+		debug.Log("failed to probe the permissions of %s: %s", dir, err)
+		return true
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	_ = f.Close()
+
+	for _, perm := range []os.FileMode{0600, 0640} {
+		if err := os.Chmod(f.Name(), perm); err != nil {
+			debug.Log("failed to change the permissions of %s: %s", f.Name(), err)
+			return false
+		}
+		fi, err := os.Stat(f.Name())
+		if err != nil || fi.Mode().Perm() != perm {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Store) fsckCheckEmptyDirs() error {
 	v := []string{}
 	if err := filepath.Walk(s.path, func(fp string, fi os.FileInfo, ferr error) error {
@@ -1,13 +1,18 @@
 package fs
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFsck(t *testing.T) {
@@ -33,3 +38,116 @@ func TestFsck(t *testing.T) {
 
 	assert.NoError(t, s.Fsck(ctx))
 }
+
+func TestFsckFix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no POSIX permissions")
+	}
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	path, cleanup := newTempDir(t)
+	defer cleanup()
+
+	s := New(path)
+	wide := filepath.Join(path, "wide.gpg")
+	plain := filepath.Join(path, "plain.gpg")
+	tmp := filepath.Join(path, ".wide.gpg.tmp123456")
+	require.NoError(t, os.WriteFile(wide, []byte{0x85, 0x01, 0x0c}, 0644))
+	require.NoError(t, os.WriteFile(plain, []byte("hunter2\n"), 0600))
+	require.NoError(t, os.WriteFile(tmp, []byte{0x85}, 0600))
+	require.NoError(t, os.Chmod(wide, 0644))
+
+	t.Run("check only", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, s.Fsck(ctx))
+		assert.Contains(t, buf.String(), "Permissions too wide: "+wide)
+		assert.Contains(t, buf.String(), "Leftover temp file of an interrupted write: "+tmp)
+		assert.Contains(t, buf.String(), "Not encrypted: "+plain)
+		assert.NotContains(t, buf.String(), "Not encrypted: "+wide)
+
+		fi, err := os.Stat(wide)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+		assert.FileExists(t, tmp)
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, s.Fsck(backend.WithFsckFix(ctx, true)))
+
+		fi, err := os.Stat(wide)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+		assert.NoFileExists(t, tmp)
+		// suspicious files are never touched
+		assert.FileExists(t, plain)
+
+		l, err := s.List(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"plain.gpg", "wide.gpg"}, l)
+	})
+}
+
+func TestFsckIgnore(t *testing.T) {
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	path, cleanup := newTempDir(t)
+	defer cleanup()
+
+	s := New(path)
+	notes := filepath.Join(path, "docs", "notes.gpg")
+	plain := filepath.Join(path, "plain.gpg")
+	require.NoError(t, os.MkdirAll(filepath.Dir(notes), 0700))
+	require.NoError(t, os.WriteFile(notes, []byte("notes\n"), 0600))
+	require.NoError(t, os.WriteFile(plain, []byte("hunter2\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".gopass-ignore"), []byte("docs/\n"), 0600))
+
+	require.NoError(t, s.Fsck(ctx))
+	assert.Contains(t, buf.String(), "Not encrypted: "+plain)
+	assert.NotContains(t, buf.String(), "Not encrypted: "+notes)
+}
+
+func TestIsTempFile(t *testing.T) {
+	for in, want := range map[string]bool{
+		"foo/.bar.gpg.tmp123456": true,
+		".gpg-id.tmp42":          true,
+		".tmp-foo":               true,
+		"foo/bar.gpg":            false,
+		".gpg-id":                false,
+		"foo.tmp":                false,
+		".foo.tmp":               false,
+	} {
+		assert.Equal(t, want, isTempFile(in), in)
+	}
+}
+
+func TestLooksEncrypted(t *testing.T) {
+	for in, want := range map[string]bool{
+		"age-encryption.org/v1\n-> X25519":   true,
+		"-----BEGIN AGE ENCRYPTED FILE-----": true,
+		"-----BEGIN PGP MESSAGE-----":        true,
+		"\x85\x02\x0c":                       true,
+		"hunter2\nuser: jane":                false,
+		"":                                   false,
+	} {
+		assert.Equal(t, want, looksEncrypted([]byte(in)), in)
+	}
+}
@@ -0,0 +1,370 @@
+package fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// indexVersion must be increased whenever the format of the index changes
+const indexVersion = 1
+
+// index is the list of all entries of a store together with the modification
+// times of all directories it was built from. Any change of the entries
+// changes the modification time of their directory, so the index is valid as
+// long as all of them are unchanged. It only contains names, never content.
+type index struct {
+	Version int    `json:"version"`
+	Path    string `json:"path"`
+	// Dirs maps each directory, "" for the root, to its mtime in ns
+	Dirs    map[string]int64 `json:"dirs"`
+	Entries []string         `json:"entries"`
+}
+
+// storeIndex is the index of a store loaded into memory
+type storeIndex struct {
+	mu  sync.Mutex
+	idx *index
+}
+
+// indexFile returns the name of the index of the store. It's kept in the
+// cache dir so that it doesn't end up in the store or its git history.
+func indexFile(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		path = p
+	}
+	return filepath.Join(appdir.UserCache(), "index", fmt.Sprintf("%x", sha256.Sum256([]byte(path)))[:16]+".json")
+}
+
+// entries returns the sorted names of all entries of the store. They are
+// taken from the index if it's still valid, otherwise the store is walked
+// and the index is rebuilt. The result must not be modified.
+func (s *Store) entries(ctx context.Context) ([]string, error) {
+	if ctxutil.IsNoIndex(ctx) {
+		idx, err := s.buildIndex()
+		if err != nil {
+			return nil, err
+		}
+		return idx.Entries, nil
+	}
+
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	if s.index.idx.valid(s.path) {
+		return s.index.idx.Entries, nil
+	}
+	// another process may have updated the index in the meantime
+	idx, err := loadIndex(indexFile(s.path))
+	if err != nil {
+		debug.Log("failed to load index of %s: %s", s.path, err)
+	}
+	if idx.valid(s.path) {
+		debug.Log("using index of %s", s.path)
+		s.index.idx = idx
+		return idx.Entries, nil
+	}
+
+	debug.Log("index of %s is missing or outdated. rebuilding", s.path)
+	idx, err = s.buildIndex()
+	if err != nil {
+		return nil, err
+	}
+	s.index.idx = idx
+	if err := idx.save(indexFile(s.path)); err != nil {
+		debug.Log("failed to save index of %s: %s", s.path, err)
+	}
+	return idx.Entries, nil
+}
+
+// RebuildIndex discards the index of the store and builds a new one
+func (s *Store) RebuildIndex(ctx context.Context) error {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	idx, err := s.buildIndex()
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", s.path, err)
+	}
+	s.index.idx = idx
+	if err := idx.save(indexFile(s.path)); err != nil {
+		return fmt.Errorf("failed to save index of %s: %w", s.path, err)
+	}
+	debug.Log("rebuilt index of %s with %d entries", s.path, len(idx.Entries))
+	return nil
+}
+
+// updateIndex rescans the directories of the given entries after they have
+// been changed by us. The directory of each entry is always rescanned, its
+// parents only if they have been changed, too. Nothing is done if there is no
+// index yet, it's built on the next listing.
+func (s *Store) updateIndex(names ...string) {
+	if len(names) < 1 {
+		return
+	}
+
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	idx := s.index.idx
+	if idx == nil {
+		var err error
+		idx, err = loadIndex(indexFile(s.path))
+		if err != nil {
+			debug.Log("no index of %s to update: %s", s.path, err)
+			return
+		}
+	}
+	// the entries may still be used by a caller of entries
+	idx.Entries = append(make([]string, 0, len(idx.Entries)+len(names)), idx.Entries...)
+
+	for _, name := range names {
+		dir := indexDir(filepath.ToSlash(name))
+		if err := idx.rescan(s.path, dir); err != nil {
+			debug.Log("failed to update index of %s, discarding it: %s", s.path, err)
+			s.discardIndex()
+			return
+		}
+		for dir != "" {
+			dir = indexDir(dir)
+			if mt, found := idx.Dirs[dir]; found && mt == dirMtime(s.path, dir) {
+				continue
+			}
+			if err := idx.rescan(s.path, dir); err != nil {
+				debug.Log("failed to update index of %s, discarding it: %s", s.path, err)
+				s.discardIndex()
+				return
+			}
+		}
+	}
+	sort.Strings(idx.Entries)
+
+	s.index.idx = idx
+	if err := idx.save(indexFile(s.path)); err != nil {
+		debug.Log("failed to save index of %s: %s", s.path, err)
+	}
+}
+
+// discardIndex removes the index so it's rebuilt on the next listing. The
+// caller must hold the index lock.
+func (s *Store) discardIndex() {
+	s.index.idx = nil
+	if err := os.Remove(indexFile(s.path)); err != nil && !os.IsNotExist(err) {
+		debug.Log("failed to remove index of %s: %s", s.path, err)
+	}
+}
+
+// buildIndex walks the whole store. Hidden directories are skipped.
+func (s *Store) buildIndex() (*index, error) {
+	idx := &index{
+		Version: indexVersion,
+		Path:    s.path,
+		Dirs:    map[string]int64{},
+		Entries: make([]string, 0, 100),
+	}
+	if err := idx.walk(s.path, ""); err != nil {
+		return nil, err
+	}
+	sort.Strings(idx.Entries)
+	return idx, nil
+}
+
+// walk adds the directory and all its content to the index. The mtime of
+// each directory is recorded before its content is read, so a concurrent
+// change always invalidates the index.
+func (idx *index) walk(root, dir string) error {
+	return filepath.Walk(filepath.Join(root, filepath.FromSlash(dir)), func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(p, root)), "/")
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") && name != "" {
+				return filepath.SkipDir
+			}
+			idx.Dirs[name] = info.ModTime().UnixNano()
+			return nil
+		}
+		idx.Entries = append(idx.Entries, name)
+		return nil
+	})
+}
+
+// rescan replaces the direct content of dir in the index with the one on disk
+// and walks any new sub directories. The entries are left unsorted.
+func (idx *index) rescan(root, dir string) error {
+	if hiddenDir(dir) {
+		return nil
+	}
+
+	// everything below a removed directory is gone
+	p := filepath.Join(root, filepath.FromSlash(dir))
+	fi, err := os.Lstat(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err != nil || !fi.IsDir() {
+		idx.removeDir(dir)
+		return nil
+	}
+
+	des, err := os.ReadDir(p)
+	if err != nil {
+		return err
+	}
+	entries := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if indexDir(e) != dir {
+			entries = append(entries, e)
+		}
+	}
+	idx.Entries = entries
+
+	seen := make(map[string]bool, len(des))
+	for _, de := range des {
+		name := path.Join(dir, de.Name())
+		if !de.IsDir() {
+			idx.Entries = append(idx.Entries, name)
+			continue
+		}
+		if strings.HasPrefix(de.Name(), ".") {
+			continue
+		}
+		seen[name] = true
+		if _, found := idx.Dirs[name]; !found {
+			if err := idx.walk(root, name); err != nil {
+				return err
+			}
+		}
+	}
+	for d := range idx.Dirs {
+		if d != "" && indexDir(d) == dir && !seen[d] {
+			idx.removeDir(d)
+		}
+	}
+
+	idx.Dirs[dir] = fi.ModTime().UnixNano()
+	return nil
+}
+
+// removeDir removes the directory and all its content from the index
+func (idx *index) removeDir(dir string) {
+	prefix := dir + "/"
+	entries := idx.Entries[:0]
+	for _, e := range idx.Entries {
+		if !strings.HasPrefix(e, prefix) {
+			entries = append(entries, e)
+		}
+	}
+	idx.Entries = entries
+
+	for d := range idx.Dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			delete(idx.Dirs, d)
+		}
+	}
+}
+
+// valid returns true if none of the directories has been changed since the
+// index was built
+func (idx *index) valid(root string) bool {
+	if idx == nil || idx.Version != indexVersion || idx.Path != root {
+		return false
+	}
+	if _, found := idx.Dirs[""]; !found {
+		return false
+	}
+	for dir, mt := range idx.Dirs {
+		if dirMtime(root, dir) != mt {
+			debug.Log("%q in %s changed since the index was built", dir, root)
+			return false
+		}
+	}
+	return true
+}
+
+func (idx *index) save(fn string) error {
+	buf, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(fn)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create index dir: %w", err)
+	}
+	fh, err := os.CreateTemp(dir, "."+filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err := fh.Write(buf); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+func loadIndex(fn string) (*index, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	idx := &index{}
+	if err := json.Unmarshal(buf, idx); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", fn, err)
+	}
+	if idx.Version != indexVersion || idx.Dirs == nil {
+		return nil, fmt.Errorf("unsupported index version %d", idx.Version)
+	}
+	return idx, nil
+}
+
+// dirMtime returns the mtime of the directory in ns or -1 if it's gone
+func dirMtime(root, dir string) int64 {
+	fi, err := os.Lstat(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil || !fi.IsDir() {
+		return -1
+	}
+	return fi.ModTime().UnixNano()
+}
+
+// indexDir returns the directory of the entry, "" for the root
+func indexDir(name string) string {
+	d := path.Dir(name)
+	if d == "." || d == "/" {
+		return ""
+	}
+	return d
+}
+
+// hiddenDir returns true if the directory is not part of the listing
+func hiddenDir(dir string) bool {
+	for _, p := range strings.Split(dir, "/") {
+		if strings.HasPrefix(p, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// touchDir changes the mtime of a directory like any change in it would,
+// without depending on the timestamp granularity of the file system
+func touchDir(t *testing.T, dir string) {
+	t.Helper()
+
+	ts := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(dir, ts, ts))
+}
+
+func TestIndex(t *testing.T) {
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+	path := t.TempDir()
+	ctx := context.Background()
+
+	s := New(path)
+	for _, name := range []string{"foo", "bar/baz", "bar/zab/one", ".public-keys/0xDEADBEEF"} {
+		require.NoError(t, s.Set(ctx, name, []byte(name)))
+	}
+
+	// no index is created before the first listing
+	assert.NoFileExists(t, indexFile(path))
+
+	l, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar/baz", "bar/zab/one", "foo"}, l)
+
+	fi, err := os.Stat(indexFile(path))
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+	}
+
+	idx, err := loadIndex(indexFile(path))
+	require.NoError(t, err)
+	assert.True(t, idx.valid(path))
+	assert.Equal(t, []string{"bar/baz", "bar/zab/one", "foo"}, idx.Entries)
+
+	t.Run("valid index is used", func(t *testing.T) {
+		idx.Entries = append(idx.Entries, "only/in/index")
+		require.NoError(t, idx.save(indexFile(path)))
+
+		l, err := New(path).List(ctx, "only")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"only/in/index"}, l)
+
+		// but not with --no-cache
+		l, err = New(path).List(ctxutil.WithNoIndex(ctx, true), "only")
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, l)
+
+		require.NoError(t, s.RebuildIndex(ctx))
+		l, err = New(path).List(ctx, "only")
+		require.NoError(t, err)
+		assert.Equal(t, []string{}, l)
+	})
+
+	t.Run("changes by others invalidate the index", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(path, "bar", "zab", "two"), []byte("two"), 0600))
+		touchDir(t, filepath.Join(path, "bar", "zab"))
+
+		l, err := New(path).List(ctx, "bar/zab")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bar/zab/one", "bar/zab/two"}, l)
+	})
+
+	t.Run("our changes update the index", func(t *testing.T) {
+		s := New(path)
+		_, err := s.List(ctx, "")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Set(ctx, "new/dir/secret", []byte("secret")))
+		require.NoError(t, s.Set(ctx, ".public-keys/0xFEEDBEEF", []byte("key")))
+		require.NoError(t, s.Delete(ctx, "bar/baz"))
+		require.NoError(t, s.Link(ctx, "foo", "link/foo"))
+		require.NoError(t, s.Prune(ctx, "bar/zab"))
+
+		want := []string{"foo", "link/foo", "new/dir/secret"}
+		idx, err := loadIndex(indexFile(path))
+		require.NoError(t, err)
+		assert.True(t, idx.valid(path))
+		assert.Equal(t, want, idx.Entries)
+		assert.NotContains(t, idx.Dirs, "bar")
+		assert.NotContains(t, idx.Dirs, ".public-keys")
+
+		l, err := New(path).List(ctxutil.WithNoIndex(ctx, true), "")
+		require.NoError(t, err)
+		assert.Equal(t, want, l)
+	})
+
+	t.Run("backups are indexed", func(t *testing.T) {
+		s := New(path)
+		bctx := ctxutil.WithKeepBackup(ctx, true)
+		require.NoError(t, s.Set(bctx, "foo", []byte("new")))
+
+		l, err := s.List(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"foo", "foo" + BackupExt}, l)
+
+		s.RemoveBackups()
+		idx, err := loadIndex(indexFile(path))
+		require.NoError(t, err)
+		assert.True(t, idx.valid(path))
+		assert.NotContains(t, idx.Entries, "foo"+BackupExt)
+	})
+}
+
+func TestIndexRemovedStore(t *testing.T) {
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "store")
+	ctx := context.Background()
+
+	s := New(path)
+	require.NoError(t, s.Set(ctx, "foo", []byte("foo")))
+	_, err := s.List(ctx, "")
+	require.NoError(t, err)
+
+	require.NoError(t, os.RemoveAll(path))
+	_, err = New(path).List(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestIndexDir(t *testing.T) {
+	for in, out := range map[string]string{
+		"foo":         "",
+		"foo/bar":     "foo",
+		"foo/bar/baz": "foo/bar",
+	} {
+		assert.Equal(t, out, indexDir(in), in)
+	}
+
+	assert.False(t, hiddenDir(""))
+	assert.False(t, hiddenDir("foo/bar"))
+	assert.True(t, hiddenDir(".git"))
+	assert.True(t, hiddenDir("foo/.hidden/bar"))
+}
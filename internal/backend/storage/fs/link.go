@@ -58,6 +58,7 @@ func (s *Store) Link(ctx context.Context, from, to string) error {
 	}()
 
 	toDir := filepath.Dir(toPath)
+	defer s.updateIndex(to)
 	if err := os.MkdirAll(toDir, 0700); err != nil {
 		return fmt.Errorf("failed to create destination dir %q: %w", toDir, err)
 	}
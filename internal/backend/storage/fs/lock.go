@@ -0,0 +1,187 @@
+package fs
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// DefaultLockTimeout is how long we wait for a store locked by another
+	// process
+	DefaultLockTimeout = 10 * time.Second
+	// lockRetry is the interval between two attempts to acquire a lock
+	lockRetry = 100 * time.Millisecond
+)
+
+// errWouldBlock is returned by tryLock if another process holds the lock
+var errWouldBlock = errors.New("lock is held by another process")
+
+// LockedError is returned if the store is still locked by another process
+// after the lock timeout. PID, Host and Since are what the holder recorded.
+type LockedError struct {
+	PID   int
+	Host  string
+	Since time.Time
+}
+
+func (e *LockedError) Error() string {
+	if e.PID < 1 {
+		return "store is locked by another process"
+	}
+	if e.Host == "" {
+		return fmt.Sprintf("store is locked by PID %d since %s", e.PID, e.Since.Format("15:04:05"))
+	}
+	return fmt.Sprintf("store is locked by PID %d on %s since %s", e.PID, e.Host, e.Since.Format("15:04:05"))
+}
+
+// storeLock is an advisory, inter-process lock of a store. Nested locks of
+// the same store within one process share the lock file.
+type storeLock struct {
+	mu   sync.Mutex
+	refs int
+	f    *os.File
+}
+
+// Lock takes the advisory lock of this store for changes. If another process
+// holds the lock it waits up to the lock timeout from the context. The lock of
+// a crashed process is released by the OS. Release the lock by calling the
+// returned function. Read only operations don't need the lock.
+func (s *Store) Lock(ctx context.Context) (func(), error) {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+
+	if s.lock.refs > 0 {
+		s.lock.refs++
+		return s.unlock, nil
+	}
+
+	timeout := DefaultLockTimeout
+	if ctxutil.HasLockTimeout(ctx) {
+		timeout = ctxutil.GetLockTimeout(ctx)
+	}
+
+	f, err := acquire(ctx, lockFile(s.path), timeout)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.f = f
+	s.lock.refs = 1
+	debug.Log("locked %s", s.path)
+	return s.unlock, nil
+}
+
+func (s *Store) unlock() {
+	s.lock.mu.Lock()
+	defer s.lock.mu.Unlock()
+
+	s.lock.refs--
+	if s.lock.refs > 0 {
+		return
+	}
+	// the lock file is kept, removing it would race with other processes
+	// waiting for it
+	if err := unlockFile(s.lock.f); err != nil {
+		debug.Log("failed to unlock %s: %s", s.path, err)
+	}
+	_ = s.lock.f.Close()
+	s.lock.f = nil
+	debug.Log("unlocked %s", s.path)
+}
+
+// lockFile returns the name of the lock file of the store. It's kept in the
+// cache dir so that it doesn't end up in the store or its git history.
+func lockFile(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		path = p
+	}
+	return filepath.Join(appdir.UserCache(), "locks", fmt.Sprintf("%x", sha256.Sum256([]byte(path)))[:16]+".lock")
+}
+
+// acquire waits until the lock file is locked by us and records our PID
+func acquire(ctx context.Context, fn string, timeout time.Duration) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(fn, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file: %w", err)
+		}
+		err = tryLock(f)
+		if err == nil {
+			writeHolder(f)
+			return f, nil
+		}
+		_ = f.Close()
+		if !errors.Is(err, errWouldBlock) {
+			return nil, fmt.Errorf("failed to lock %s: %w", fn, err)
+		}
+
+		// a live process holds the lock. The recorded PID may look dead
+		// anyway, e.g. if it's on another host, but the file must never be
+		// removed, another process would lock a new file while the holder
+		// still has the old one.
+		pid, host, since := readHolder(fn)
+		if time.Now().After(deadline) {
+			return nil, &LockedError{PID: pid, Host: host, Since: since}
+		}
+		debug.Log("waiting for lock %s held by PID %d", fn, pid)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetry):
+		}
+	}
+}
+
+// writeHolder records our PID, the time we got the lock and our host
+func writeHolder(f *os.File) {
+	host, _ := os.Hostname()
+	if err := f.Truncate(0); err != nil {
+		debug.Log("failed to truncate lock file: %s", err)
+		return
+	}
+	if _, err := f.WriteAt([]byte(fmt.Sprintf("%d\n%d\n%s\n", os.Getpid(), time.Now().Unix(), host)), 0); err != nil {
+		debug.Log("failed to write lock file: %s", err)
+	}
+}
+
+// readHolder returns the PID, the host and the time the holder got the lock.
+// The host is missing in lock files of older versions.
+func readHolder(fn string) (int, string, time.Time) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return 0, "", time.Time{}
+	}
+	p := strings.Fields(string(buf))
+	if len(p) < 2 {
+		return 0, "", time.Time{}
+	}
+	pid, err := strconv.Atoi(p[0])
+	if err != nil {
+		return 0, "", time.Time{}
+	}
+	var host string
+	if len(p) > 2 {
+		host = p[2]
+	}
+	ts, err := strconv.ParseInt(p[1], 10, 64)
+	if err != nil {
+		return pid, host, time.Time{}
+	}
+	return pid, host, time.Unix(ts, 0)
+}
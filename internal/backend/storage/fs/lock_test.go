@@ -0,0 +1,93 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock(t *testing.T) {
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+	path := t.TempDir()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithLockTimeout(ctx, 200*time.Millisecond)
+
+	s := New(path)
+	unlock, err := s.Lock(ctx)
+	require.NoError(t, err)
+
+	// nested locks share the lock
+	unlockNested, err := s.Lock(ctx)
+	require.NoError(t, err)
+	unlockNested()
+
+	host, _ := os.Hostname()
+	pid, holderHost, since := readHolder(lockFile(path))
+	assert.Equal(t, os.Getpid(), pid)
+	assert.Equal(t, host, holderHost)
+	assert.WithinDuration(t, time.Now(), since, time.Minute)
+
+	// another instance uses another file descriptor, just like another
+	// process would
+	other := New(path)
+	start := time.Now()
+	_, err = other.Lock(ctx)
+	require.Error(t, err)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(200*time.Millisecond))
+	var le *LockedError
+	require.True(t, errors.As(err, &le))
+	assert.Equal(t, os.Getpid(), le.PID)
+	assert.Equal(t, host, le.Host)
+	assert.Contains(t, err.Error(), "store is locked by PID")
+
+	unlock()
+	unlockOther, err := other.Lock(ctx)
+	require.NoError(t, err)
+	unlockOther()
+}
+
+func TestLockStaleFile(t *testing.T) {
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+	path := t.TempDir()
+	ctx := ctxutil.WithLockTimeout(context.Background(), 0)
+
+	// a lock file left behind by a crashed process isn't locked anymore
+	s := New(path)
+	unlock, err := s.Lock(ctx)
+	require.NoError(t, err)
+	unlock()
+	require.NoError(t, os.WriteFile(lockFile(path), []byte("999999999\n42\n"), 0600))
+
+	unlock, err = New(path).Lock(ctx)
+	require.NoError(t, err)
+	pid, _, _ := readHolder(lockFile(path))
+	assert.Equal(t, os.Getpid(), pid)
+
+	// a held lock is never broken, even if the recorded PID looks dead
+	require.NoError(t, os.WriteFile(lockFile(path), []byte("999999999\n42\nother\n"), 0600))
+	fi, err := os.Stat(lockFile(path))
+	require.NoError(t, err)
+	_, err = New(path).Lock(ctx)
+	var le *LockedError
+	require.True(t, errors.As(err, &le))
+	assert.Equal(t, "store is locked by PID 999999999 on other since "+time.Unix(42, 0).Format("15:04:05"), err.Error())
+	ni, err := os.Stat(lockFile(path))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(fi, ni))
+	unlock()
+}
+
+func TestLockedError(t *testing.T) {
+	since := time.Date(2021, 12, 1, 12, 1, 5, 0, time.Local)
+	assert.Equal(t, "store is locked by PID 1234 since 12:01:05", (&LockedError{PID: 1234, Since: since}).Error())
+	assert.Equal(t, "store is locked by PID 1234 on laptop since 12:01:05", (&LockedError{PID: 1234, Host: "laptop", Since: since}).Error())
+	assert.Equal(t, "store is locked by another process", (&LockedError{}).Error())
+}
@@ -2,14 +2,19 @@ package fs
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/store"
 )
 
-// Add does nothing
+// Add does nothing. Without git there is no commit to wait for, so the
+// backups of changed secrets are removed.
 func (s *Store) Add(ctx context.Context, args ...string) error {
+	s.RemoveBackups()
 	return store.ErrGitNotInit
 }
 
@@ -62,11 +67,31 @@ func (s *Store) Revisions(context.Context, string) ([]backend.Revision, error) {
 		}}, nil
 }
 
+// Meta returns the modification time of the file. Without a history the
+// creation time and the author are unknown.
+func (s *Store) Meta(ctx context.Context, name string) (backend.Meta, error) {
+	if runtime.GOOS == "windows" {
+		name = filepath.FromSlash(name)
+	}
+	fi, err := os.Stat(filepath.Join(s.path, filepath.Clean(name)))
+	if err != nil {
+		return backend.Meta{}, err
+	}
+	return backend.Meta{
+		Modified: fi.ModTime(),
+	}, nil
+}
+
 // GetRevision is not implemented
 func (s *Store) GetRevision(context.Context, string, string) ([]byte, error) {
 	return []byte("foo\nbar"), nil
 }
 
+// Deleted is not implemented
+func (s *Store) Deleted(context.Context) ([]backend.Deletion, error) {
+	return []backend.Deletion{}, nil
+}
+
 // Status is not implemented
 func (s *Store) Status(context.Context) ([]byte, error) {
 	return []byte(""), nil
@@ -3,6 +3,7 @@ package fs
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/stretchr/testify/assert"
@@ -34,3 +35,22 @@ func TestRCS(t *testing.T) {
 	assert.Equal(t, "foo\nbar", string(body))
 	assert.NoError(t, g.RemoveRemote(ctx, "foo"))
 }
+
+func TestMeta(t *testing.T) {
+	ctx := context.Background()
+	path, cleanup := newTempDir(t)
+	defer cleanup()
+
+	g := New(path)
+	_, err := g.Meta(ctx, "foo")
+	assert.Error(t, err)
+
+	require.NoError(t, g.Set(ctx, "foo", []byte("bar")))
+	m, err := g.Meta(ctx, "foo")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), m.Modified, time.Minute)
+	// there is no history
+	assert.True(t, m.Created.IsZero())
+	assert.Equal(t, "", m.Author)
+	assert.Equal(t, "", m.Revision)
+}
@@ -3,12 +3,13 @@
 package fs
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strings"
 
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -19,7 +20,10 @@ import (
 
 // Store is a fs based store
 type Store struct {
-	path string
+	path    string
+	lock    storeLock
+	backups backups
+	index   storeIndex
 }
 
 // New creates a new store
@@ -42,20 +46,50 @@ func (s *Store) Get(ctx context.Context, name string) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
+// Open returns a reader for the named content, e.g. to decrypt a large
+// secret without reading it into memory
+func (s *Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if runtime.GOOS == "windows" {
+		name = filepath.FromSlash(name)
+	}
+	path := filepath.Join(s.path, filepath.Clean(name))
+	debug.Log("Opening %s from %s", name, path)
+	return os.Open(path)
+}
+
+// Size returns the size of the named content in bytes
+func (s *Store) Size(ctx context.Context, name string) (int64, error) {
+	if runtime.GOOS == "windows" {
+		name = filepath.FromSlash(name)
+	}
+	fi, err := os.Stat(filepath.Join(s.path, filepath.Clean(name)))
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
 // Set writes the given content
 func (s *Store) Set(ctx context.Context, name string, value []byte) error {
+	return s.SetFrom(ctx, name, bytes.NewReader(value))
+}
+
+// SetFrom writes the content read from r. Like Set it replaces the content
+// atomically, if r fails the previous content is kept.
+func (s *Store) SetFrom(ctx context.Context, name string, r io.Reader) error {
 	if runtime.GOOS == "windows" {
 		name = filepath.FromSlash(name)
 	}
 	filename := filepath.Join(s.path, filepath.Clean(name))
 	filedir := filepath.Dir(filename)
+	defer s.updateIndex(name)
 	if !fsutil.IsDir(filedir) {
 		if err := os.MkdirAll(filedir, 0700); err != nil {
 			return err
 		}
 	}
-	debug.Log("Writing %s to %s", name, filepath.Join(s.path, name))
-	return os.WriteFile(filepath.Join(s.path, name), value, 0644)
+	debug.Log("Writing %s to %s", name, filename)
+	return s.writeFile(ctx, filename, r, 0644)
 }
 
 // Delete removes the named entity
@@ -65,6 +99,7 @@ func (s *Store) Delete(ctx context.Context, name string) error {
 	}
 	path := filepath.Join(s.path, filepath.Clean(name))
 	debug.Log("Deleting %s from %s", name, path)
+	defer s.updateIndex(name)
 
 	if err := os.Remove(path); err != nil {
 		return err
@@ -116,33 +151,16 @@ func (s *Store) Exists(ctx context.Context, name string) bool {
 func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 	prefix = strings.TrimPrefix(prefix, "/")
 	debug.Log("Listing %s", prefix)
-	files := make([]string, 0, 100)
-	if err := filepath.Walk(s.path, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != s.path {
-			return filepath.SkipDir
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if path == s.path {
-			return nil
-		}
-		name := strings.TrimPrefix(path, s.path+string(filepath.Separator))
-		if runtime.GOOS == "windows" {
-			name = filepath.ToSlash(name)
-		}
-		if !strings.HasPrefix(name, prefix) {
-			return nil
-		}
-		files = append(files, name)
-		return nil
-	}); err != nil {
+	entries, err := s.entries(ctx)
+	if err != nil {
 		return nil, err
 	}
-	sort.Strings(files)
+	files := make([]string, 0, len(entries))
+	for _, name := range entries {
+		if strings.HasPrefix(name, prefix) {
+			files = append(files, name)
+		}
+	}
 	return files, nil
 }
 
@@ -161,6 +179,7 @@ func (s *Store) IsDir(ctx context.Context, name string) bool {
 func (s *Store) Prune(ctx context.Context, prefix string) error {
 	path := filepath.Join(s.path, filepath.Clean(prefix))
 	debug.Log("Purning %s from %s", prefix, path)
+	defer s.updateIndex(filepath.Clean(prefix))
 
 	if err := os.RemoveAll(path); err != nil {
 		return err
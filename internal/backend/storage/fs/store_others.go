@@ -11,3 +11,22 @@ import (
 func notEmptyErr(err error) bool {
 	return err.(*os.PathError).Err == syscall.ENOTEMPTY
 }
+
+// syncDir flushes the directory entries of dir to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// fileOwner returns the uid of the owner of the file
+func fileOwner(fi os.FileInfo) (int, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(st.Uid), true
+}
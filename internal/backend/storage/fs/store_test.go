@@ -2,11 +2,16 @@ package fs
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSetAndGet(t *testing.T) {
@@ -17,7 +22,7 @@ func TestSetAndGet(t *testing.T) {
 	path, cleanup := newTempDir(t)
 	defer cleanup()
 
-	s := &Store{path}
+	s := &Store{path: path}
 
 	fileHasContent := func(filename string, content []byte) {
 		written, _ := s.Get(ctx, filename)
@@ -105,7 +110,7 @@ func TestRemoveEmptyParentDirectories(t *testing.T) {
 			}
 
 			s := &Store{
-				path,
+				path: path,
 			}
 			if err := s.removeEmptyParentDirectories(filepath.Join(subdir, "deletedFile")); err != nil {
 				t.Error(err)
@@ -185,7 +190,7 @@ func TestDelete(t *testing.T) {
 			}
 
 			store := &Store{
-				path,
+				path: path,
 			}
 			err := store.Delete(context.Background(), filepath.Join(test.toDelete...))
 
@@ -215,3 +220,35 @@ func newTempDir(t *testing.T) (string, func()) {
 		_ = os.RemoveAll(td)
 	}
 }
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+
+	path, cleanup := newTempDir(t)
+	defer cleanup()
+
+	s := &Store{path: path}
+
+	filename := filepath.Join("a", "file")
+	require.NoError(t, s.SetFrom(ctx, filename, strings.NewReader("content")))
+
+	size, err := s.Size(ctx, filename)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), size)
+
+	rc, err := s.Open(ctx, filename)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "content", string(buf))
+
+	// a failing reader keeps the previous content
+	assert.Error(t, s.SetFrom(ctx, filename, iotest.ErrReader(errors.New("read failed"))))
+	buf, err = s.Get(ctx, filename)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(buf))
+
+	_, err = s.Open(ctx, "missing")
+	assert.Error(t, err)
+}
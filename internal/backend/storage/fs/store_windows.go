@@ -8,3 +8,14 @@ import (
 func notEmptyErr(err error) bool {
 	return err.(*os.PathError).Err == syscall.ERROR_DIR_NOT_EMPTY
 }
+
+// syncDir does nothing. Directories can't be synced on Windows, renames are
+// durable once they return.
+func syncDir(dir string) error {
+	return nil
+}
+
+// fileOwner returns false, files are not owned by a uid on Windows
+func fileOwner(fi os.FileInfo) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,383 @@
+package gitfs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+// nullID is the object id git uses for a missing file
+const nullID = "0000000000000000000000000000000000000000"
+
+// compactCommit is a commit of the current branch as needed to recreate it
+type compactCommit struct {
+	hash string
+	// env sets the author and committer of the recreated commit
+	env     []string
+	msg     string
+	changes []compactChange
+}
+
+// compactChange is the change of a single file, blob is the null id if the
+// file was removed
+type compactChange struct {
+	mode string
+	blob string
+	path string
+}
+
+// compactPlan describes which commits and revisions are kept
+type compactPlan struct {
+	// keepFrom is the index of the oldest kept revision of each file. Before
+	// that the file doesn't exist in the new history.
+	keepFrom map[string]int
+	kept     []bool
+	// dropped are the blobs only used by removed revisions
+	dropped map[string]bool
+	stats   backend.HistoryCompaction
+}
+
+// CompactHistory rewrites the history of the current branch to keep only the
+// last keep revisions of each file and removes the old objects from the
+// repository. Every kept commit keeps its author, date and message. The new
+// history is force pushed to the remote, if any. A dry run only returns what
+// would be removed.
+func (g *Git) CompactHistory(ctx context.Context, keep int, dryRun bool) (backend.HistoryCompaction, error) {
+	if keep < 1 {
+		return backend.HistoryCompaction{}, fmt.Errorf("at least one revision must be kept")
+	}
+	if !g.IsInitialized() {
+		return backend.HistoryCompaction{}, store.ErrGitNotInit
+	}
+	if g.inRebase() || fsutil.IsFile(filepath.Join(g.fs.Path(), ".git", "MERGE_HEAD")) {
+		return backend.HistoryCompaction{}, fmt.Errorf("a rebase or merge is in progress")
+	}
+	head := g.Head(ctx)
+	if head == "" {
+		return backend.HistoryCompaction{}, nil
+	}
+	branch := g.defaultBranch(ctx)
+	if branch == "HEAD" {
+		return backend.HistoryCompaction{}, fmt.Errorf("not on a branch")
+	}
+	remote := g.defaultRemote(ctx, branch)
+	// the old objects are kept as long as any other ref reaches them
+	refs, err := g.sharedRefs(ctx, head, "refs/heads/"+branch, "refs/remotes/"+remote+"/"+branch)
+	if err != nil {
+		return backend.HistoryCompaction{}, err
+	}
+	if len(refs) > 0 {
+		return backend.HistoryCompaction{}, fmt.Errorf("%s also reference the history of %s. Remove them before compacting it", strings.Join(refs, ", "), branch)
+	}
+
+	commits, err := g.compactLog(ctx)
+	if err != nil {
+		return backend.HistoryCompaction{}, err
+	}
+	plan := planCompaction(commits, keep)
+	if err := g.estimateSavings(ctx, &plan); err != nil {
+		return plan.stats, err
+	}
+	debug.Log("compacting the history of %s: %+v", g.fs.Path(), plan.stats)
+	if dryRun || plan.stats.KeptCommits == plan.stats.Commits {
+		return plan.stats, nil
+	}
+
+	unlock, err := g.Lock(ctx)
+	if err != nil {
+		return plan.stats, err
+	}
+	defer unlock()
+
+	url, _ := g.ConfigGet(ctx, "remote."+remote+".url")
+	if url != "" {
+		// the commits only on the remote would be lost by the force push
+		if ctxutil.IsNoNetwork(ctx) {
+			return plan.stats, fmt.Errorf("the new history can not be pushed to %s without network access", remote)
+		}
+		_, behind, err := g.RemoteStatus(ctx)
+		if err != nil {
+			return plan.stats, fmt.Errorf("failed to fetch %s: %w", remote, err)
+		}
+		if behind > 0 {
+			return plan.stats, fmt.Errorf("%s has %d new commits. Sync before compacting the history", remote, behind)
+		}
+	}
+
+	newHead, err := g.rewriteHistory(ctx, commits, plan)
+	if err != nil {
+		return plan.stats, fmt.Errorf("failed to rewrite the history: %w", err)
+	}
+	// the content of the store must not change
+	if g.revParse(ctx, newHead+"^{tree}") != g.revParse(ctx, head+"^{tree}") {
+		return plan.stats, fmt.Errorf("the rewritten history %s doesn't end with the current content. Nothing was changed", newHead)
+	}
+	if err := g.Cmd(ctx, "gitUpdateRef", "update-ref", "-m", "gopass: compact history", "HEAD", newHead, head); err != nil {
+		return plan.stats, fmt.Errorf("failed to update %s: %w", branch, err)
+	}
+	g.meta.reset()
+	debug.Log("rewrote %s from %s to %s", branch, head, newHead)
+
+	if url != "" {
+		if err := g.Cmd(ctx, "gitPush", "push", "--force-with-lease", remote, branch); err != nil {
+			return plan.stats, fmt.Errorf("the history was rewritten but pushing it to %s failed: %w", remote, err)
+		}
+		g.recordSync(ctx)
+		plan.stats.Remote = url
+	}
+
+	if err := g.Cmd(ctx, "gitReflogExpire", "reflog", "expire", "--expire=now", "--all"); err != nil {
+		return plan.stats, err
+	}
+	return plan.stats, g.Cmd(ctx, "gitGC", "gc", "--prune=now")
+}
+
+func (g *Git) revParse(ctx context.Context, rev string) string {
+	stdout, _, err := g.captureCmd(ctx, "gitRevParse", "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(stdout))
+}
+
+// sharedRefs returns the refs, e.g. tags, other branches or the stash, that
+// have commits in common with head. The skipped refs and symbolic refs are
+// left out.
+func (g *Git) sharedRefs(ctx context.Context, head string, skip ...string) ([]string, error) {
+	stdout, stderr, err := g.captureCmd(ctx, "gitForEachRef", "for-each-ref", "--format=%(refname) %(symref)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %s", strings.TrimSpace(string(stderr)))
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, ref := range skip {
+		skipped[ref] = true
+	}
+	var refs []string
+	for _, line := range strings.Split(string(stdout), "\n") {
+		f := strings.Fields(line)
+		if len(f) != 1 || skipped[f[0]] {
+			continue
+		}
+		// merge-base fails if there is no common ancestor
+		if _, _, err := g.captureCmd(ctx, "gitMergeBase", "merge-base", head, f[0]); err != nil {
+			continue
+		}
+		refs = append(refs, f[0])
+	}
+	return refs, nil
+}
+
+// compactLog returns the commits of the current branch, oldest first. Merges
+// are treated like any other commit with the changes against the first
+// parent.
+func (g *Git) compactLog(ctx context.Context) ([]compactCommit, error) {
+	args := []string{
+		"log",
+		"--first-parent",
+		"-m",
+		"--reverse",
+		"--raw",
+		"--no-renames",
+		"--no-abbrev",
+		"-z",
+		"--date=raw",
+		`--format=%x1e%H%x1f%an%x1f%ae%x1f%ad%x1f%cn%x1f%ce%x1f%cd%x1f%B`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "gitLog", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history: %s", strings.TrimSpace(string(stderr)))
+	}
+	return parseCompactLog(stdout)
+}
+
+// parseCompactLog parses the output of git log -z --raw with the format of
+// compactLog. The header of each commit is terminated by a NUL, followed by
+// pairs of the raw diff line and the path.
+func parseCompactLog(buf []byte) ([]compactCommit, error) {
+	var commits []compactCommit
+	for _, rec := range bytes.Split(buf, []byte{0x1e}) {
+		if len(bytes.TrimSpace(rec)) == 0 {
+			continue
+		}
+		parts := bytes.Split(rec, []byte{0})
+		header := strings.SplitN(string(parts[0]), "\x1f", 8)
+		if len(header) < 8 {
+			return nil, fmt.Errorf("invalid log entry %q", string(parts[0]))
+		}
+		c := compactCommit{
+			hash: header[0],
+			env: []string{
+				"GIT_AUTHOR_NAME=" + header[1],
+				"GIT_AUTHOR_EMAIL=" + header[2],
+				"GIT_AUTHOR_DATE=" + header[3],
+				"GIT_COMMITTER_NAME=" + header[4],
+				"GIT_COMMITTER_EMAIL=" + header[5],
+				"GIT_COMMITTER_DATE=" + header[6],
+			},
+			msg: header[7],
+		}
+		for i := 1; i+1 < len(parts); i += 2 {
+			// :100644 100644 <old blob> <new blob> M
+			f := strings.Fields(string(parts[i]))
+			if len(f) < 5 {
+				return nil, fmt.Errorf("invalid change %q of %s", string(parts[i]), c.hash)
+			}
+			c.changes = append(c.changes, compactChange{
+				mode: f[1],
+				blob: f[3],
+				path: string(parts[i+1]),
+			})
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// planCompaction decides which revisions to keep. These are the last keep
+// changes of each file and the commits containing them. The last commit is
+// always kept, so the content stays the same.
+func planCompaction(commits []compactCommit, keep int) compactPlan {
+	type revision struct {
+		idx  int
+		blob string
+	}
+	revs := make(map[string][]revision)
+	for i, c := range commits {
+		for _, ch := range c.changes {
+			revs[ch.path] = append(revs[ch.path], revision{idx: i, blob: ch.blob})
+		}
+	}
+
+	plan := compactPlan{
+		keepFrom: make(map[string]int, len(revs)),
+		kept:     make([]bool, len(commits)),
+		dropped:  make(map[string]bool),
+	}
+	keptBlobs := make(map[string]bool)
+	for path, rs := range revs {
+		from := 0
+		if len(rs) > keep {
+			from = len(rs) - keep
+		}
+		plan.keepFrom[path] = rs[from].idx
+		for j, r := range rs {
+			plan.stats.Revisions++
+			if j < from {
+				plan.dropped[r.blob] = true
+				continue
+			}
+			plan.stats.KeptRevisions++
+			plan.kept[r.idx] = true
+			keptBlobs[r.blob] = true
+		}
+	}
+	if len(commits) > 0 {
+		plan.kept[len(commits)-1] = true
+	}
+
+	// the same content may be used by a kept revision of another file
+	for blob := range plan.dropped {
+		if keptBlobs[blob] || blob == nullID {
+			delete(plan.dropped, blob)
+		}
+	}
+	plan.stats.Commits = len(commits)
+	for _, k := range plan.kept {
+		if k {
+			plan.stats.KeptCommits++
+		}
+	}
+	return plan
+}
+
+// estimateSavings sets the size of the repository and the size of the blobs
+// that would be removed
+func (g *Git) estimateSavings(ctx context.Context, plan *compactPlan) error {
+	stdout, stderr, err := g.captureCmd(ctx, "gitCatFile", "cat-file", "--batch-all-objects", "--batch-check=%(objectname) %(objectsize:disk)")
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %s", strings.TrimSpace(string(stderr)))
+	}
+	for _, line := range strings.Split(string(stdout), "\n") {
+		f := strings.Fields(line)
+		if len(f) < 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		plan.stats.Size += size
+		if plan.dropped[f[0]] {
+			plan.stats.Savings += size
+		}
+	}
+	return nil
+}
+
+// rewriteHistory recreates the kept commits with only the kept revisions and
+// returns the new head. The trees are built in a temporary index, the
+// working tree and the index of the repository are not touched.
+func (g *Git) rewriteHistory(ctx context.Context, commits []compactCommit, plan compactPlan) (string, error) {
+	index := filepath.Join(g.fs.Path(), ".git", "gopass-compact-index")
+	_ = os.Remove(index)
+	defer func() {
+		_ = os.Remove(index)
+	}()
+	env := []string{"GIT_INDEX_FILE=" + index}
+
+	sign, err := g.signArgs()
+	if err != nil {
+		return "", err
+	}
+
+	var parent string
+	for i, c := range commits {
+		info := &bytes.Buffer{}
+		for _, ch := range c.changes {
+			if i < plan.keepFrom[ch.path] {
+				continue
+			}
+			if ch.blob == nullID {
+				fmt.Fprintf(info, "0 %s\t%s\x00", nullID, ch.path)
+				continue
+			}
+			fmt.Fprintf(info, "%s %s\t%s\x00", ch.mode, ch.blob, ch.path)
+		}
+		if info.Len() > 0 {
+			if _, stderr, err := g.captureCmdInput(ctx, "gitUpdateIndex", env, info, "update-index", "-z", "--index-info"); err != nil {
+				return "", fmt.Errorf("failed to update the index for %s: %s", c.hash, strings.TrimSpace(string(stderr)))
+			}
+		}
+		if !plan.kept[i] {
+			continue
+		}
+
+		stdout, stderr, err := g.captureCmdInput(ctx, "gitWriteTree", env, nil, "write-tree")
+		if err != nil {
+			return "", fmt.Errorf("failed to write the tree of %s: %s", c.hash, strings.TrimSpace(string(stderr)))
+		}
+		args := []string{"commit-tree", strings.TrimSpace(string(stdout))}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		args = append(args, sign...)
+		stdout, stderr, err = g.captureCmdInput(ctx, "gitCommitTree", c.env, strings.NewReader(c.msg), args...)
+		if err != nil {
+			return "", fmt.Errorf("failed to recreate %s: %s", c.hash, strings.TrimSpace(string(stderr)))
+		}
+		parent = strings.TrimSpace(string(stdout))
+		debug.Log("recreated %s as %s", c.hash, parent)
+	}
+	return parent, nil
+}
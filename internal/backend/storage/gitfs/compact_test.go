@@ -0,0 +1,116 @@
+package gitfs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactHistory(t *testing.T) {
+	ctx := context.Background()
+
+	td := t.TempDir()
+	remote := filepath.Join(td, "remote.git")
+	require.NoError(t, exec.Command("git", "init", "--bare", "--quiet", remote).Run())
+
+	require.NoError(t, os.Mkdir(filepath.Join(td, "store"), 0700))
+	g, err := Init(ctx, filepath.Join(td, "store"), "Alice", "alice@example.org")
+	require.NoError(t, err)
+	require.NoError(t, g.AddRemote(ctx, "origin", remote))
+
+	for _, content := range []string{"foo 1", "foo 2", "foo 3", "foo 4"} {
+		commitFile(ctx, t, g, "foo.gpg", content)
+	}
+	commitFile(ctx, t, g, "bar.gpg", "bar 1")
+	commitFile(ctx, t, g, "old.gpg", "old 1")
+	require.NoError(t, os.Remove(filepath.Join(g.fs.Path(), "old.gpg")))
+	require.NoError(t, g.Add(ctx, "old.gpg"))
+	require.NoError(t, g.Commit(ctx, "remove old.gpg"))
+	commitFile(ctx, t, g, "foo.gpg", "foo 5")
+	require.NoError(t, g.Push(ctx, "", ""))
+
+	head := g.Head(ctx)
+	tree := g.revParse(ctx, "HEAD^{tree}")
+
+	t.Run("keep at least one revision", func(t *testing.T) {
+		_, err := g.CompactHistory(ctx, 0, true)
+		assert.Error(t, err)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		stats, err := g.CompactHistory(ctx, 2, true)
+		require.NoError(t, err)
+		// .gitattributes, foo 1-5, bar, old.gpg added and removed
+		assert.Equal(t, 9, stats.Commits)
+		assert.Equal(t, 9, stats.Revisions)
+		// foo 4, foo 5, bar, old.gpg and .gitattributes
+		assert.Equal(t, 6, stats.KeptRevisions)
+		assert.Equal(t, 6, stats.KeptCommits)
+		assert.Greater(t, stats.Size, stats.Savings)
+		assert.Greater(t, stats.Savings, int64(0))
+		assert.Equal(t, head, g.Head(ctx))
+	})
+
+	t.Run("refuse other refs", func(t *testing.T) {
+		require.NoError(t, g.Cmd(ctx, "gitTag", "tag", "v1", "HEAD~2"))
+		_, err := g.CompactHistory(ctx, 2, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refs/tags/v1 also reference the history of master")
+		require.NoError(t, g.Cmd(ctx, "gitTag", "tag", "-d", "v1"))
+
+		require.NoError(t, g.Cmd(ctx, "gitBranch", "branch", "other", "HEAD~1"))
+		_, err = g.CompactHistory(ctx, 2, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refs/heads/other")
+		assert.Equal(t, head, g.Head(ctx))
+		require.NoError(t, g.Cmd(ctx, "gitBranch", "branch", "-D", "other"))
+	})
+
+	t.Run("nothing to remove", func(t *testing.T) {
+		stats, err := g.CompactHistory(ctx, 5, false)
+		require.NoError(t, err)
+		assert.Equal(t, stats.Commits, stats.KeptCommits)
+		assert.Equal(t, head, g.Head(ctx))
+	})
+
+	t.Run("compact the history", func(t *testing.T) {
+		stats, err := g.CompactHistory(ctx, 2, false)
+		require.NoError(t, err)
+		assert.Equal(t, remote, stats.Remote)
+
+		assert.NotEqual(t, head, g.Head(ctx))
+		assert.Equal(t, tree, g.revParse(ctx, "HEAD^{tree}"))
+
+		revs, err := g.Revisions(ctx, "foo.gpg")
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, "update foo.gpg", revs[0].Subject)
+		assert.Equal(t, "Alice", revs[0].AuthorName)
+		buf, err := g.GetRevision(ctx, "foo.gpg", revs[1].Hash)
+		require.NoError(t, err)
+		assert.Equal(t, "foo 4", string(buf))
+
+		revs, err = g.Revisions(ctx, "bar.gpg")
+		require.NoError(t, err)
+		assert.Len(t, revs, 1)
+
+		dels, err := g.Deleted(ctx)
+		require.NoError(t, err)
+		require.Len(t, dels, 1)
+		assert.Equal(t, "old.gpg", dels[0].Name)
+
+		// the old commits are gone
+		assert.Equal(t, "", g.revParse(ctx, head+"^{commit}"))
+
+		cmd := exec.Command("git", "--git-dir", remote, "rev-list", "--count", "HEAD")
+		buf, err = cmd.Output()
+		require.NoError(t, err)
+		assert.Equal(t, "6", strings.TrimSpace(string(buf)))
+	})
+}
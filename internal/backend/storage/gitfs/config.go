@@ -10,6 +10,7 @@ import (
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
@@ -99,14 +100,20 @@ func (g *Git) ConfigGet(ctx context.Context, key string) (string, error) {
 
 	buf := &strings.Builder{}
 
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, "git", "config", "--get", key)
 	cmd.Dir = g.fs.Path()
 	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
 
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return "", err
+	done := debug.Exec(cmd)
+	err := cmd.Run()
+	done(err, stderr.String())
+	if err != nil {
+		return "", ctxutil.ExecError(ctx, err)
 	}
 
 	return strings.TrimSpace(buf.String()), nil
@@ -120,14 +127,20 @@ func (g *Git) ConfigList(ctx context.Context) (map[string]string, error) {
 
 	buf := &strings.Builder{}
 
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, "git", "config", "--list")
 	cmd.Dir = g.fs.Path()
 	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
-
-	debug.Log("%s %+v", cmd.Path, cmd.Args)
-	if err := cmd.Run(); err != nil {
-		return nil, err
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+
+	done := debug.Exec(cmd)
+	err := cmd.Run()
+	done(err, stderr.String())
+	if err != nil {
+		return nil, ctxutil.ExecError(ctx, err)
 	}
 
 	lines := strings.Split(buf.String(), "\n")
@@ -28,6 +28,7 @@ type contextKey int
 
 const (
 	ctxKeyPathOverride contextKey = iota
+	ctxKeyProgress
 )
 
 func withPathOverride(ctx context.Context, path string) context.Context {
@@ -41,9 +42,52 @@ func getPathOverride(ctx context.Context, def string) string {
 	return def
 }
 
+// withProgress returns a context for git commands whose progress is passed
+// through to stderr
+func withProgress(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyProgress, true)
+}
+
+func isProgress(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyProgress).(bool)
+	return ok && bv
+}
+
 // Git is a cli based git backend
 type Git struct {
 	fs *fs.Store
+
+	signCommits bool
+	signingKey  string
+
+	pullStrategy string
+	noAutoPush   bool
+	syncInterval time.Duration
+	onSync       func(context.Context)
+
+	meta metaCache
+
+	// probed is the result of probing the remote, if it was probed
+	probed *bool
+}
+
+// SignCommits makes all following commits and merges signed with the given
+// key. If the key is empty they fail with store.ErrGitNoSigningKey instead of
+// creating unsigned commits.
+func (g *Git) SignCommits(key string) {
+	g.signCommits = true
+	g.signingKey = key
+}
+
+// signArgs returns the arguments to sign a commit, if enabled
+func (g *Git) signArgs() ([]string, error) {
+	if !g.signCommits {
+		return nil, nil
+	}
+	if g.signingKey == "" {
+		return nil, store.ErrGitNoSigningKey
+	}
+	return []string{"--gpg-sign=" + g.signingKey}, nil
 }
 
 // New creates a new git cli based git backend
@@ -57,17 +101,97 @@ func New(path string) (*Git, error) {
 }
 
 // Clone clones an existing git repo and returns a new cli based git backend
-// configured for this clone repo
+// configured for this clone repo. A clone that was interrupted, e.g. by a
+// dropped connection, is continued with a fetch. Transferring a large store
+// may take much longer than other network commands, so it's only canceled by
+// the user.
 func Clone(ctx context.Context, repo, path string) (*Git, error) {
 	g := &Git{
 		fs: fs.New(path),
 	}
-	if err := g.Cmd(withPathOverride(ctx, filepath.Dir(path)), "Clone", "clone", repo, path); err != nil {
+	ctx = ctxutil.WithExecTimeout(ctx, 0)
+
+	if g.IsInitialized() {
+		if err := g.resumeClone(ctx, repo); err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+
+	args := append([]string{"clone"}, depthArgs(ctx)...)
+	args = append(args, repo, path)
+	if err := g.progressCmd(withPathOverride(ctx, filepath.Dir(path)), "Clone", args...); err != nil {
 		return nil, err
 	}
 	return g, nil
 }
 
+// resumeClone fetches the objects missing from an interrupted clone of repo
+// and checks out the default branch of the remote, like clone does. Other
+// repositories are left alone.
+func (g *Git) resumeClone(ctx context.Context, repo string) error {
+	url, _, err := g.captureCmd(ctx, "resumeClone", "config", "--get", "remote.origin.url")
+	if err != nil || strings.TrimSpace(string(url)) != repo {
+		return fmt.Errorf("%s already contains a git repository that is not a clone of %s", g.fs.Path(), repo)
+	}
+	out.Noticef(ctx, "Continuing the interrupted clone in %s ...", g.fs.Path())
+
+	args := append([]string{"fetch"}, depthArgs(ctx)...)
+	if err := g.progressCmd(ctx, "resumeClone", append(args, "origin")...); err != nil {
+		return err
+	}
+	if g.Head(ctx) != "" {
+		debug.Log("%s has been checked out already", g.fs.Path())
+		return nil
+	}
+
+	if err := g.Cmd(ctx, "resumeClone", "remote", "set-head", "origin", "--auto"); err != nil {
+		return err
+	}
+	ref, _, err := g.captureCmd(ctx, "resumeClone", "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to determine the default branch of %s: %w", repo, err)
+	}
+	branch := strings.TrimPrefix(strings.TrimSpace(string(ref)), "origin/")
+	return g.Cmd(ctx, "resumeClone", "checkout", "-B", branch, "--track", "origin/"+branch)
+}
+
+// depthArgs returns the arguments for a shallow clone, if one was requested
+func depthArgs(ctx context.Context) []string {
+	if depth := backend.GetCloneDepth(ctx); depth > 0 {
+		return []string{"--depth", strconv.Itoa(depth)}
+	}
+	return nil
+}
+
+// progressCmd runs a git command transferring objects like Cmd. On a terminal
+// git's progress, i.e. the share of the received objects and the throughput,
+// is passed through to stderr.
+func (g *Git) progressCmd(ctx context.Context, name string, args ...string) error {
+	if !ctxutil.IsTerminal(ctx) || ctxutil.IsHidden(ctx) {
+		return g.Cmd(ctx, name, args...)
+	}
+
+	args = append([]string{args[0], "--progress"}, args[1:]...)
+	if _, stderr, err := g.captureCmd(withProgress(ctx), name, args...); err != nil {
+		debug.Log("CMD: %s %+v\nError: %s", name, args, err)
+		return fmt.Errorf("%w: %s", err, gitErrors(stderr))
+	}
+	return nil
+}
+
+// gitErrors returns the messages of a failed git command without the
+// progress lines
+func gitErrors(stderr []byte) string {
+	var msgs []string
+	for _, line := range strings.FieldsFunc(string(stderr), func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			msgs = append(msgs, line)
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
 // Init initializes this store's git repo
 func Init(ctx context.Context, path, userName, userEmail string) (*Git, error) {
 	g := &Git{
@@ -110,36 +234,152 @@ func Init(ctx context.Context, path, userName, userEmail string) (*Git, error) {
 	return g, nil
 }
 
+// networkCommands are the git commands accessing a remote
+var networkCommands = map[string]bool{
+	"clone":     true,
+	"fetch":     true,
+	"ls-remote": true,
+	"pull":      true,
+	"push":      true,
+}
+
+// execContext returns the context to run the git command with. Network
+// operations get the exec timeout and local ones a quarter of it. Signing
+// commands are not timed out since gpg might ask for the passphrase.
+func execContext(ctx context.Context, args []string) (context.Context, context.CancelFunc) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--gpg-sign") {
+			return context.WithCancel(ctx)
+		}
+	}
+	if networkCommands[subcommand(args)] {
+		return ctxutil.WithNetworkDeadline(ctx)
+	}
+	return ctxutil.WithLocalDeadline(ctx)
+}
+
+// subcommand returns the git command, e.g. push, skipping any options in
+// front of it
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-c" || args[i] == "-C":
+			i++
+		case strings.HasPrefix(args[i], "-"):
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
+
 func (g *Git) captureCmd(ctx context.Context, name string, args ...string) ([]byte, []byte, error) {
+	return g.captureCmdInput(ctx, name, nil, nil, args...)
+}
+
+// captureCmdInput runs the git command like captureCmd with the additional
+// environment variables and reading from stdin, if not nil
+func (g *Git) captureCmdInput(ctx context.Context, name string, env []string, stdin io.Reader, args ...string) ([]byte, []byte, error) {
 	bufOut := &bytes.Buffer{}
 	bufErr := &bytes.Buffer{}
 
+	ctx, cancel := execContext(ctx, args)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, "git", args[0:]...)
 	cmd.Dir = getPathOverride(ctx, g.fs.Path())
+	cmd.Stdin = stdin
 	cmd.Stdout = bufOut
 	cmd.Stderr = bufErr
-
-	if debug.IsEnabled() && ctxutil.IsVerbose(ctx) {
-		cmd.Stdout = io.MultiWriter(bufOut, os.Stdout)
+	// some errors are detected by matching the output of git, which is
+	// only possible if it isn't translated
+	cmd.Env = append(append(os.Environ(), "LC_ALL=C"), env...)
+
+	// the output of git is part of the debug log, it's only shown with
+	// --verbose or while cloning
+	switch {
+	case isProgress(ctx):
+		cmd.Stderr = io.MultiWriter(bufErr, out.Stderr)
+	case ctxutil.IsVerbose(ctx):
 		cmd.Stderr = io.MultiWriter(bufErr, os.Stderr)
 	}
 
-	debug.Log("store.%s: %s %+v (%s)", name, cmd.Path, cmd.Args, g.fs.Path())
-	err := cmd.Run()
+	done := debug.Exec(cmd, "op", name)
+	start := time.Now()
+	err := run(ctx, cmd)
+	if err != nil && ctx.Err() != nil {
+		// the output may still be written to if run gave up waiting
+		done(err, "")
+		g.removeIndexLock(start)
+		return nil, nil, fmt.Errorf("git %s %w", subcommand(args), ctxutil.ExecError(ctx, err))
+	}
+	done(err, bufErr.String())
 	return bufOut.Bytes(), bufErr.Bytes(), err
 }
 
+// waitDelay is the time to wait for the output of a killed git command to be
+// closed. A child of git, e.g. ssh waiting for an unreachable remote, might
+// keep it open.
+var waitDelay = time.Second
+
+// run runs the command like cmd.Run, but doesn't wait for more than waitDelay
+// once the context is done
+func run(ctx context.Context, cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(waitDelay):
+		debug.Log("gave up waiting for %s %+v", cmd.Path, cmd.Args)
+		return ctx.Err()
+	}
+}
+
+// removeIndexLock removes the index lock left behind by a killed git command.
+// Only a lock created after the command was started is removed, an older one
+// belongs to someone else.
+func (g *Git) removeIndexLock(since time.Time) {
+	fn := filepath.Join(g.fs.Path(), ".git", "index.lock")
+	fi, err := os.Stat(fn)
+	if err != nil || fi.ModTime().Before(since.Truncate(time.Second)) {
+		return
+	}
+	if err := os.Remove(fn); err != nil {
+		debug.Log("failed to remove %s: %s", fn, err)
+		return
+	}
+	debug.Log("removed %s left behind by a killed git command", fn)
+}
+
 // Cmd runs an git command
 func (g *Git) Cmd(ctx context.Context, name string, args ...string) error {
 	stdout, stderr, err := g.captureCmd(ctx, name, args...)
 	if err != nil {
 		debug.Log("CMD: %s %+v\nError: %s\nOutput:\n  Stdout: %q\n  Stderr: %q", name, args, err, string(stdout), string(stderr))
-		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(stderr)))
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(stderr)))
 	}
 
 	return nil
 }
 
+// Lock takes the advisory lock of the store, see fs.Store.Lock
+func (g *Git) Lock(ctx context.Context) (func(), error) {
+	return g.fs.Lock(ctx)
+}
+
 // Name returns git
 func (g *Git) Name() string {
 	return "git"
@@ -149,8 +389,13 @@ func (g *Git) Name() string {
 func (g *Git) Version(ctx context.Context) semver.Version {
 	v := semver.Version{}
 
+	ctx, cancel := ctxutil.WithLocalDeadline(ctx)
+	defer cancel()
+
 	cmd := exec.CommandContext(ctx, "git", "version")
+	done := debug.Exec(cmd)
 	cmdout, err := cmd.Output()
+	done(err, "")
 	if err != nil {
 		debug.Log("Failed to run 'git version': %s", err)
 		return v
@@ -221,10 +466,47 @@ func (g *Git) Commit(ctx context.Context, msg string) error {
 	}
 
 	if !g.HasStagedChanges(ctx) {
+		g.fs.RemoveBackups()
 		return store.ErrGitNothingToCommit
 	}
 
-	return g.Cmd(ctx, "gitCommit", "commit", fmt.Sprintf("--date=%d +00:00", ctxutil.GetCommitTimestamp(ctx).UTC().Unix()), "-m", msg)
+	sign, err := g.signArgs()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"commit", fmt.Sprintf("--date=%d +00:00", ctxutil.GetCommitTimestamp(ctx).UTC().Unix())}
+	args = append(args, sign...)
+	if err := g.Cmd(ctx, "gitCommit", append(args, "-m", msg)...); err != nil {
+		return err
+	}
+	g.meta.reset()
+	debug.Info("committed", "path", g.fs.Path())
+
+	// the previous versions are in the history now
+	g.fs.RemoveBackups()
+	return nil
+}
+
+// CommitEmpty creates a commit without any changes, e.g. to record an event
+// in the history. Staged changes are not included.
+func (g *Git) CommitEmpty(ctx context.Context, msg string) error {
+	if !g.IsInitialized() {
+		return store.ErrGitNotInit
+	}
+
+	sign, err := g.signArgs()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"commit", "--allow-empty", "--only", fmt.Sprintf("--date=%d +00:00", ctxutil.GetCommitTimestamp(ctx).UTC().Unix())}
+	args = append(args, sign...)
+	if err := g.Cmd(ctx, "gitCommitEmpty", append(args, "-m", msg)...); err != nil {
+		return err
+	}
+	g.meta.reset()
+	return nil
 }
 
 func (g *Git) defaultRemote(ctx context.Context, branch string) string {
@@ -259,7 +541,7 @@ func (g *Git) defaultBranch(ctx context.Context) string {
 // PushPull pushes the repo to it's origin.
 // optional arguments: remote and branch
 func (g *Git) PushPull(ctx context.Context, op, remote, branch string) error {
-	if ctxutil.IsNoNetwork(ctx) {
+	if ctxutil.IsNoNetwork(ctx) && !ctxutil.IsOffline(ctx) {
 		debug.Log("Skipping network ops. NoNetwork=true")
 		return nil
 	}
@@ -274,32 +556,76 @@ func (g *Git) PushPull(ctx context.Context, op, remote, branch string) error {
 		remote = g.defaultRemote(ctx, branch)
 	}
 
-	if v, err := g.ConfigGet(ctx, "remote."+remote+".url"); err != nil || v == "" {
+	remoteURL, err := g.ConfigGet(ctx, "remote."+remote+".url")
+	if err != nil || remoteURL == "" {
 		return store.ErrGitNoRemote
 	}
 
-	if err := g.Cmd(ctx, "gitPush", "pull", remote, branch); err != nil {
+	if op == "push" && g.skipSync(ctx) {
+		return nil
+	}
+
+	// the commits stay local until the next sync
+	offline := ctxutil.IsOffline(ctx)
+	if !offline && ctxutil.IsAutoOffline(ctx) && !ctxutil.IsExplicitSync(ctx) {
+		offline = !g.reachable(ctx, remoteURL)
+	}
+	if offline {
+		debug.Log("Skipping %s. Offline=true", op)
+		if op == "push" {
+			g.noteNotPushed(ctx, remote, branch)
+		}
+		return nil
+	}
+
+	if err := g.pushPull(ctx, op, remote, branch); err != nil {
+		return err
+	}
+	// outside of the lock, the callback may run commands in the repo
+	if g.onSync != nil {
+		g.onSync(ctx)
+	}
+	return nil
+}
+
+func (g *Git) pushPull(ctx context.Context, op, remote, branch string) error {
+	unlock, err := g.Lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// merge commits created by the pull must be signed as well
+	sign, err := g.signArgs()
+	if err != nil {
+		return err
+	}
+
+	if err := g.pull(ctx, remote, branch, sign); err != nil {
 		if op == "pull" {
 			return err
 		}
 		out.Warningf(ctx, "Failed to pull before git push: %s", err)
 	}
 	if op == "pull" {
+		debug.Info("pulled", "path", g.fs.Path(), "remote", remote, "branch", branch)
 		return nil
 	}
 
 	if uf := g.ListUntrackedFiles(ctx); len(uf) > 0 {
 		out.Warningf(ctx, "Found untracked files: %+v", uf)
 	}
-	return g.Cmd(ctx, "gitPush", "push", remote, branch)
+	if err := g.Cmd(ctx, "gitPush", "push", remote, branch); err != nil {
+		return err
+	}
+	// the URL of the remote is never logged, it may contain credentials
+	debug.Info("pushed", "path", g.fs.Path(), "remote", remote, "branch", branch)
+	g.recordSync(ctx)
+	return nil
 }
 
 // Push pushes to the git remote
 func (g *Git) Push(ctx context.Context, remote, branch string) error {
-	if ctxutil.IsNoNetwork(ctx) {
-		debug.Log("Skipping network ops. NoNetwork=true")
-		return nil
-	}
 	return g.PushPull(ctx, "push", remote, branch)
 }
 
@@ -386,11 +712,113 @@ func (g *Git) GetRevision(ctx context.Context, name, revision string) ([]byte, e
 	stdout, stderr, err := g.captureCmd(ctx, "GetRevision", args...)
 	if err != nil {
 		debug.Log("Command failed: %s", string(stderr))
+		se := string(stderr)
+		switch {
+		case strings.Contains(se, "does not exist in") || strings.Contains(se, "but not in"):
+			return nil, fmt.Errorf("%q did not exist at revision %q: %w", name, revision, store.ErrNotFound)
+		case strings.Contains(se, "invalid object name") || strings.Contains(se, "unknown revision"):
+			return nil, fmt.Errorf("unknown revision %q", revision)
+		}
 		return nil, err
 	}
 	return stdout, nil
 }
 
+// Deleted lists all files removed in any revision, newest first. A file
+// that was removed more than once is listed once for each removal.
+func (g *Git) Deleted(ctx context.Context) ([]backend.Deletion, error) {
+	args := []string{
+		"-c", "core.quotePath=false",
+		"log",
+		"--diff-filter=D",
+		"--name-only",
+		`--format=%x1e%H%x1f%an%x1f%ae%x1f%at%x1f%s`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "Deleted", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+		return nil, err
+	}
+	var dels []backend.Deletion
+	for _, rev := range strings.Split(string(stdout), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(rev), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+
+		r := parseRevision(strings.Split(lines[0], "\x1f"))
+		for _, name := range lines[1:] {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			dels = append(dels, backend.Deletion{Revision: r, Name: name})
+		}
+	}
+	return dels, nil
+}
+
+// VerifyCommits checks the signatures of all commits and returns those
+// without a valid signature, newest first
+func (g *Git) VerifyCommits(ctx context.Context) ([]backend.SignatureProblem, error) {
+	args := []string{
+		"log",
+		`--format=%H%x1f%an%x1f%ae%x1f%at%x1f%s%x1f%G?%x1f%GK%x1e`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "VerifyCommits", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+		return nil, err
+	}
+
+	var probs []backend.SignatureProblem
+	for _, rev := range strings.Split(string(stdout), "\x1e") {
+		p := strings.Split(strings.TrimSpace(rev), "\x1f")
+		if len(p) < 7 {
+			continue
+		}
+		problem := signatureProblem(p[5], p[6])
+		if problem == "" {
+			continue
+		}
+
+		r := backend.Revision{
+			Hash:        p[0],
+			AuthorName:  p[1],
+			AuthorEmail: p[2],
+			Subject:     p[4],
+		}
+		if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
+			r.Date = time.Unix(iv, 0)
+		}
+		probs = append(probs, backend.SignatureProblem{Revision: r, Problem: problem})
+	}
+	return probs, nil
+}
+
+// signatureProblem describes the git signature status (%G?), or returns an
+// empty string for a good signature
+// see https://git-scm.com/docs/git-log#Documentation/git-log.txt-emGem
+func signatureProblem(status, key string) string {
+	switch status {
+	case "G", "U":
+		return ""
+	case "N":
+		return "unsigned"
+	case "B":
+		return "bad signature by " + key
+	case "X":
+		return "expired signature by " + key
+	case "Y":
+		return "signed by expired key " + key
+	case "R":
+		return "signed by revoked key " + key
+	case "E":
+		return "signature by unknown key " + key
+	}
+	return "unknown signature status " + status
+}
+
 // Status return the git status output
 func (g *Git) Status(ctx context.Context) ([]byte, error) {
 	stdout, stderr, err := g.captureCmd(ctx, "GitStatus", "status")
@@ -403,5 +831,15 @@ func (g *Git) Status(ctx context.Context) ([]byte, error) {
 
 // Compact will run git gc
 func (g *Git) Compact(ctx context.Context) error {
-	return g.Cmd(ctx, "gitGC", "gc", "--aggressive")
+	return g.GC(ctx, true)
+}
+
+// GC runs git gc to pack the repository and remove unreachable objects.
+// The aggressive mode takes longer but packs them tighter.
+func (g *Git) GC(ctx context.Context, aggressive bool) error {
+	args := []string{"gc"}
+	if aggressive {
+		args = append(args, "--aggressive")
+	}
+	return g.Cmd(ctx, "gitGC", args...)
 }
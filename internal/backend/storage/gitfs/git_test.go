@@ -3,11 +3,18 @@ package gitfs
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
 	"github.com/stretchr/testify/assert"
@@ -53,6 +60,23 @@ func TestGit(t *testing.T) {
 
 		assert.Error(t, git.Push(ctx, "origin", "master"))
 		assert.Error(t, git.Pull(ctx, "origin", "master"))
+
+		probs, err := git.VerifyCommits(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, probs)
+		assert.Equal(t, "added some-file", probs[0].Subject)
+		assert.Equal(t, "unsigned", probs[0].Problem)
+	})
+
+	t.Run("sign commits without key", func(t *testing.T) {
+		git, err := New(gitdir)
+		require.NoError(t, err)
+		git.SignCommits("")
+
+		tf := filepath.Join(gitdir, "unsigned-file")
+		require.NoError(t, os.WriteFile(tf, []byte("foobar"), 0644))
+		assert.NoError(t, git.Add(ctx, "unsigned-file"))
+		assert.ErrorIs(t, git.Commit(ctx, "added unsigned-file"), store.ErrGitNoSigningKey)
 	})
 
 	t.Run("open existing repo", func(t *testing.T) {
@@ -85,3 +109,210 @@ func TestGit(t *testing.T) {
 		assert.Equal(t, "foobar", string(content))
 	})
 }
+
+func TestSignatureProblem(t *testing.T) {
+	for status, want := range map[string]string{
+		"G": "",
+		"U": "",
+		"N": "unsigned",
+		"B": "bad signature by DEADBEEF",
+		"R": "signed by revoked key DEADBEEF",
+		"E": "signature by unknown key DEADBEEF",
+	} {
+		assert.Equal(t, want, signatureProblem(status, "DEADBEEF"), status)
+	}
+}
+
+func TestCommitRemovesBackups(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithKeepBackup(ctx, true)
+
+	git, err := Init(ctx, t.TempDir(), "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	commitFile(ctx, t, git, "foo.gpg", "first")
+
+	bak := filepath.Join(git.Path(), "foo.gpg.bak")
+	require.NoError(t, git.Set(ctx, "foo.gpg", []byte("second")))
+	assert.FileExists(t, bak)
+
+	require.NoError(t, git.Add(ctx, "foo.gpg"))
+	require.NoError(t, git.Commit(ctx, "update foo.gpg"))
+	assert.NoFileExists(t, bak)
+	assert.Equal(t, "second", readFile(t, git, "foo.gpg"))
+}
+
+// fakeGit replaces git with a script that takes the index lock and hangs.
+// The background sleep keeps the output open after the script is killed,
+// like ssh does for git push.
+func fakeGit(t *testing.T) *Git {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake git is a shell script")
+	}
+
+	bin := filepath.Join(t.TempDir(), "bin")
+	require.NoError(t, os.Mkdir(bin, 0755))
+	script := "#!/bin/sh\ntouch .git/index.lock\nsleep 10 &\nexec sleep 10\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	wd := waitDelay
+	waitDelay = 100 * time.Millisecond
+	t.Cleanup(func() {
+		waitDelay = wd
+	})
+
+	path := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(path, ".git"), 0755))
+	return &Git{fs: fs.New(path)}
+}
+
+func TestCmdTimeout(t *testing.T) {
+	git := fakeGit(t)
+	lock := filepath.Join(git.Path(), ".git", "index.lock")
+	ctx := ctxutil.WithExecTimeout(context.Background(), 400*time.Millisecond)
+
+	t.Run("local commands get a quarter", func(t *testing.T) {
+		start := time.Now()
+		err := git.Cmd(ctx, "gitAdd", "add", "foo.gpg")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+		assert.Contains(t, err.Error(), "git add timed out")
+		assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+		assert.NoFileExists(t, lock)
+	})
+
+	t.Run("network commands get all", func(t *testing.T) {
+		start := time.Now()
+		err := git.Cmd(ctx, "gitPush", "push", "origin", "master")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+		assert.GreaterOrEqual(t, int64(time.Since(start)), int64(400*time.Millisecond))
+		assert.NoFileExists(t, lock)
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		err := git.Cmd(ctx, "gitCommit", "commit", "--gpg-sign=DEADBEEF", "-m", "foo")
+		assert.True(t, errors.Is(err, context.Canceled), err)
+		assert.Contains(t, err.Error(), "git commit interrupted")
+		assert.NoFileExists(t, lock)
+	})
+
+	t.Run("older lock is kept", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(lock, nil, 0644))
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(lock, old, old))
+
+		git.removeIndexLock(time.Now())
+		assert.FileExists(t, lock)
+	})
+}
+
+func TestCmdUntranslated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the alias is a shell command")
+	}
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LANGUAGE", "de")
+
+	ctx := context.Background()
+	git, err := Init(ctx, t.TempDir(), "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+
+	stdout, _, err := git.captureCmd(ctx, "gitLocale", "-c", "alias.locale=!echo $LC_ALL", "locale")
+	require.NoError(t, err)
+	assert.Equal(t, "C", strings.TrimSpace(string(stdout)))
+}
+
+func TestExecContext(t *testing.T) {
+	ctx := ctxutil.WithExecTimeout(context.Background(), time.Minute)
+
+	for args, want := range map[string]time.Duration{
+		"push origin master":                time.Minute,
+		"-c core.quotePath=false ls-files":  15 * time.Second,
+		"commit -m foo":                     15 * time.Second,
+		"commit --gpg-sign=DEADBEEF -m foo": 0,
+	} {
+		ectx, cancel := execContext(ctx, strings.Fields(args))
+		dl, found := ectx.Deadline()
+		cancel()
+		if want == 0 {
+			assert.False(t, found, args)
+			continue
+		}
+		assert.WithinDuration(t, time.Now().Add(want), dl, 5*time.Second, args)
+	}
+
+	assert.Equal(t, "rebase", subcommand([]string{"-c", "core.editor=true", "rebase", "--continue"}))
+	assert.Equal(t, "", subcommand([]string{"--version"}))
+}
+
+func TestClone(t *testing.T) {
+	td := t.TempDir()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	origin := filepath.Join(td, "origin")
+	require.NoError(t, os.Mkdir(origin, 0755))
+	g, err := Init(ctx, origin, "Dead Beef", "dead.beef@example.org")
+	require.NoError(t, err)
+	for _, fn := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(filepath.Join(origin, fn), []byte(fn), 0644))
+		require.NoError(t, g.Add(ctx, fn))
+		require.NoError(t, g.Commit(ctx, "add "+fn))
+	}
+	repo := "file://" + origin
+
+	t.Run("shallow with progress", func(t *testing.T) {
+		defer buf.Reset()
+		ctx := ctxutil.WithTerminal(ctx, true)
+		ctx = backend.WithCloneDepth(ctx, 1)
+		path := filepath.Join(td, "shallow")
+		_, err := Clone(ctx, repo, path)
+		require.NoError(t, err)
+		assert.FileExists(t, filepath.Join(path, ".git", "shallow"))
+		assert.FileExists(t, filepath.Join(path, "second"))
+		assert.Contains(t, buf.String(), "objects")
+	})
+
+	t.Run("resume interrupted clone", func(t *testing.T) {
+		defer buf.Reset()
+		// git clone creates the repository and the remote before fetching
+		path := filepath.Join(td, "partial")
+		require.NoError(t, os.Mkdir(path, 0755))
+		partial := &Git{fs: fs.New(path)}
+		require.NoError(t, partial.Cmd(ctx, "init", "init"))
+		require.NoError(t, partial.AddRemote(ctx, "origin", repo))
+
+		cloned, err := Clone(ctx, repo, path)
+		require.NoError(t, err)
+		assert.Equal(t, g.Head(ctx), cloned.Head(ctx))
+		assert.FileExists(t, filepath.Join(path, "first"))
+		assert.Contains(t, buf.String(), "Continuing the interrupted clone")
+
+		// later pulls work as after a regular clone
+		assert.NoError(t, cloned.Pull(ctx, "", ""))
+	})
+
+	t.Run("other repository", func(t *testing.T) {
+		defer buf.Reset()
+		_, err := Clone(ctx, "file:///some/other/repo", origin)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a clone of file:///some/other/repo")
+	})
+
+	assert.Equal(t, "fatal: early EOF\nerror: index-pack failed", gitErrors([]byte("Receiving objects:  10% (1/10)\rReceiving objects:  20% (2/10)\nfatal: early EOF\nerror: index-pack failed\n")))
+}
@@ -0,0 +1,108 @@
+package gitfs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Changes lists the changes of all files, newest first. Merge commits are
+// skipped, their changes are listed with the commits they merged. Renames are
+// detected by git, i.e. a file whose content changed too much while it was
+// moved is deleted and created again. The trailers of each commit message
+// are returned along with the subject.
+func (g *Git) Changes(ctx context.Context) ([]backend.Change, error) {
+	args := []string{
+		"-c", "core.quotePath=false",
+		"log",
+		"-M",
+		"--no-textconv",
+		"--name-status",
+		`--format=%x1e%H%x1f%an%x1f%ae%x1f%at%x1f%s%x1f%(trailers:only,unfold,separator=%x1d)`,
+	}
+	stdout, stderr, err := g.captureCmd(ctx, "Changes", args...)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+		return nil, err
+	}
+
+	var changes []backend.Change
+	for _, rev := range strings.Split(string(stdout), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(rev), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+
+		r := parseRevision(strings.Split(lines[0], "\x1f"))
+		for _, line := range lines[1:] {
+			p := strings.Split(strings.TrimSpace(line), "\t")
+			if len(p) < 2 || p[0] == "" {
+				continue
+			}
+			c := backend.Change{Revision: r, Name: p[len(p)-1]}
+			switch p[0][0] {
+			case 'A', 'C':
+				c.Op = backend.ChangeCreated
+			case 'D':
+				c.Op = backend.ChangeDeleted
+			case 'R':
+				c.Op = backend.ChangeRenamed
+				c.OldName = p[1]
+			default:
+				c.Op = backend.ChangeUpdated
+			}
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// parseRevision parses the hash, author name, email, timestamp, subject and
+// trailers of a commit, as printed by git log
+// --format=%H%x1f%an%x1f%ae%x1f%at%x1f%s%x1f%(trailers:only,unfold,separator=%x1d)
+func parseRevision(p []string) backend.Revision {
+	r := backend.Revision{}
+	r.Hash = p[0]
+	if len(p) > 1 {
+		r.AuthorName = p[1]
+	}
+	if len(p) > 2 {
+		r.AuthorEmail = p[2]
+	}
+	if len(p) > 3 {
+		if iv, err := strconv.ParseInt(p[3], 10, 64); err == nil {
+			r.Date = time.Unix(iv, 0)
+		}
+	}
+	if len(p) > 4 {
+		r.Subject = p[4]
+	}
+	if len(p) > 5 {
+		r.Trailers = parseTrailers(p[5])
+	}
+	return r
+}
+
+// parseTrailers parses the trailers of a commit message separated by \x1d.
+// The first value of a repeated key wins.
+func parseTrailers(s string) map[string]string {
+	var trailers map[string]string
+	for _, t := range strings.Split(s, "\x1d") {
+		p := strings.SplitN(t, ":", 2)
+		if len(p) < 2 {
+			continue
+		}
+		k := strings.TrimSpace(p[0])
+		if trailers == nil {
+			trailers = make(map[string]string, 4)
+		}
+		if _, found := trailers[k]; !found {
+			trailers[k] = strings.TrimSpace(p[1])
+		}
+	}
+	return trailers
+}
@@ -0,0 +1,101 @@
+package gitfs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	ctx := context.Background()
+
+	td := t.TempDir()
+	g, err := Init(ctx, td, "Alice", "alice@example.org")
+	require.NoError(t, err)
+
+	commitFile(ctx, t, g, "foo.gpg", "foo content that is long enough to detect renames")
+	commitFile(ctx, t, g, "foo.gpg", "foo content that is long enough to detect renames!")
+
+	cmd := exec.Command("git", "mv", "foo.gpg", "bar.gpg")
+	cmd.Dir = td
+	require.NoError(t, cmd.Run())
+	require.NoError(t, g.Commit(ctx, "rename foo"))
+
+	require.NoError(t, os.Remove(filepath.Join(td, "bar.gpg")))
+	require.NoError(t, g.Add(ctx, "bar.gpg"))
+	require.NoError(t, g.Commit(ctx, "remove bar"))
+
+	changes, err := g.Changes(ctx)
+	require.NoError(t, err)
+
+	var ops []string
+	for _, c := range changes {
+		ops = append(ops, c.Op+" "+c.Name)
+	}
+	// the initial commit of Init adds the .gitattributes
+	assert.Equal(t, []string{
+		"deleted bar.gpg",
+		"renamed bar.gpg",
+		"updated foo.gpg",
+		"created foo.gpg",
+		"created .gitattributes",
+	}, ops)
+	assert.Equal(t, "foo.gpg", changes[1].OldName)
+	assert.Equal(t, "rename foo", changes[1].Subject)
+	assert.Equal(t, "Alice", changes[1].AuthorName)
+	assert.Equal(t, "alice@example.org", changes[1].AuthorEmail)
+	assert.Len(t, changes[1].Hash, 40)
+	assert.False(t, changes[1].Date.IsZero())
+	assert.Equal(t, backend.ChangeCreated, changes[3].Op)
+	assert.Nil(t, changes[1].Trailers)
+}
+
+func TestChangesTrailers(t *testing.T) {
+	ctx := context.Background()
+
+	td := t.TempDir()
+	g, err := Init(ctx, td, "Alice", "alice@example.org")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(td, "foo.gpg"), []byte("foo"), 0600))
+	require.NoError(t, g.Add(ctx, "foo.gpg"))
+	require.NoError(t, g.Commit(ctx, "insert foo\n\nGopass-Op: insert\nGopass-Name: foo\nGopass-Name: bar\nGopass-Version: 1.12.0"))
+
+	changes, err := g.Changes(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, changes)
+	assert.Equal(t, "insert foo", changes[0].Subject)
+	assert.Equal(t, map[string]string{
+		"Gopass-Op":      "insert",
+		"Gopass-Name":    "foo",
+		"Gopass-Version": "1.12.0",
+	}, changes[0].Trailers)
+}
+
+func TestCommitEmpty(t *testing.T) {
+	ctx := context.Background()
+
+	td := t.TempDir()
+	g, err := Init(ctx, td, "Alice", "alice@example.org")
+	require.NoError(t, err)
+
+	// staged changes are not part of the empty commit
+	require.NoError(t, os.WriteFile(filepath.Join(td, "foo.gpg"), []byte("foo"), 0600))
+	require.NoError(t, g.Add(ctx, "foo.gpg"))
+	require.NoError(t, g.CommitEmpty(ctx, "share foo\n\nGopass-Op: share\nGopass-Name: foo"))
+	assert.True(t, g.HasStagedChanges(ctx))
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B", "--stat")
+	cmd.Dir = td
+	buf, err := cmd.Output()
+	require.NoError(t, err)
+	// no files are listed
+	assert.Equal(t, "share foo\n\nGopass-Op: share\nGopass-Name: foo\n\n", string(buf))
+}
@@ -0,0 +1,88 @@
+package gitfs
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// metaCache holds the metadata derived from the git log. Walking the history
+// is expensive, so every entity is only looked up once per run, unless the
+// history changes in between.
+type metaCache struct {
+	sync.Mutex
+	entries map[string]backend.Meta
+}
+
+func (c *metaCache) get(name string) (backend.Meta, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	m, found := c.entries[name]
+	return m, found
+}
+
+func (c *metaCache) set(name string, m backend.Meta) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]backend.Meta, 1)
+	}
+	c.entries[name] = m
+}
+
+func (c *metaCache) reset() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.entries = nil
+}
+
+// Meta returns when the entity was added to git, when it was last committed
+// and by whom. Entities that were never committed fall back to the metadata
+// of the file.
+func (g *Git) Meta(ctx context.Context, name string) (backend.Meta, error) {
+	if m, found := g.meta.get(name); found {
+		return m, nil
+	}
+
+	stdout, stderr, err := g.captureCmd(ctx, "gitLogMeta", "log", `--format=%H%x1f%an%x1f%at`, "--", name)
+	if err != nil {
+		debug.Log("Command failed: %s", string(stderr))
+		return backend.Meta{}, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(stdout)), "\n")
+	if lines[0] == "" {
+		debug.Log("%s was never committed", name)
+		return g.fs.Meta(ctx, name)
+	}
+
+	var m backend.Meta
+	// the log starts with the latest commit
+	if p := strings.Split(lines[0], "\x1f"); len(p) == 3 {
+		m.Revision = p[0]
+		m.Author = p[1]
+		m.Modified = unixTime(p[2])
+	}
+	if p := strings.Split(lines[len(lines)-1], "\x1f"); len(p) == 3 {
+		m.Created = unixTime(p[2])
+	}
+
+	g.meta.set(name, m)
+	return m, nil
+}
+
+func unixTime(s string) time.Time {
+	iv, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(iv, 0)
+}
@@ -0,0 +1,64 @@
+package gitfs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeta(t *testing.T) {
+	ctx := context.Background()
+
+	td := t.TempDir()
+	g, err := Init(ctx, td, "Alice", "alice@example.org")
+	require.NoError(t, err)
+
+	t.Run("not committed", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(td, "foo.gpg"), []byte("foo"), 0600))
+		m, err := g.Meta(ctx, "foo.gpg")
+		require.NoError(t, err)
+		assert.False(t, m.Modified.IsZero())
+		assert.True(t, m.Created.IsZero())
+		assert.Equal(t, "", m.Author)
+	})
+
+	created := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	modified := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	commitFile(ctxutil.WithCommitTimestamp(ctx, created), t, g, "foo.gpg", "foo")
+	require.NoError(t, g.InitConfig(ctx, "Bob", "bob@example.org"))
+	commitFile(ctxutil.WithCommitTimestamp(ctx, modified), t, g, "foo.gpg", "bar")
+
+	t.Run("committed", func(t *testing.T) {
+		m, err := g.Meta(ctx, "foo.gpg")
+		require.NoError(t, err)
+		assert.True(t, created.Equal(m.Created))
+		assert.True(t, modified.Equal(m.Modified))
+		assert.Equal(t, "Bob", m.Author)
+		assert.Len(t, m.Revision, 40)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(td, "foo.gpg"), []byte("baz"), 0600))
+		cmd := exec.Command("git", "commit", "-a", "-m", "outside of gopass")
+		cmd.Dir = td
+		require.NoError(t, cmd.Run())
+
+		m, err := g.Meta(ctx, "foo.gpg")
+		require.NoError(t, err)
+		assert.True(t, modified.Equal(m.Modified))
+
+		// committing through the backend updates it
+		commitFile(ctx, t, g, "foo.gpg", "qux")
+		m, err = g.Meta(ctx, "foo.gpg")
+		require.NoError(t, err)
+		assert.True(t, m.Modified.After(modified))
+	})
+}
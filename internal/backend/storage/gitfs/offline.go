@@ -0,0 +1,132 @@
+package gitfs
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// probeKey is the local git config key caching the result of the last
+	// probe of the remote, e.g. "1634200000 offline"
+	probeKey = "gopass.lastprobe"
+	// probeTimeout is how long connecting to the remote may take before
+	// gopass works offline
+	probeTimeout = 2 * time.Second
+	// probeCacheTTL is how long the result of a probe is used by the
+	// following gopass invocations
+	probeCacheTTL = 5 * time.Minute
+)
+
+// reachable probes the remote with the given URL once per invocation and
+// returns false if it can't be connected to. Remotes which can't be probed,
+// e.g. local paths or host aliases from the SSH config, are reachable.
+func (g *Git) reachable(ctx context.Context, remoteURL string) bool {
+	if g.probed != nil {
+		return *g.probed
+	}
+
+	ok := g.probe(ctx, remoteURL)
+	g.probed = &ok
+	return ok
+}
+
+func (g *Git) probe(ctx context.Context, remoteURL string) bool {
+	if sv, err := g.ConfigGet(ctx, probeKey); err == nil {
+		if ts, result, found := parseProbe(sv); found && time.Since(ts) < probeCacheTTL {
+			debug.Log("Using the probe of %s from %s: %s", remoteURL, ts, result)
+			return result != "offline"
+		}
+	}
+
+	addr := remoteAddr(remoteURL)
+	if addr == "" {
+		debug.Log("Not probing %s", remoteURL)
+		return true
+	}
+
+	ok := true
+	d := net.Dialer{Timeout: probeTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		debug.Log("Failed to connect to %s: %s", addr, err)
+		ok = false
+	} else {
+		_ = conn.Close()
+	}
+
+	result := "online"
+	if !ok {
+		result = "offline"
+	}
+	if err := g.ConfigSet(ctx, probeKey, strconv.FormatInt(time.Now().Unix(), 10)+" "+result); err != nil {
+		debug.Log("failed to cache the probe: %s", err)
+	}
+	return ok
+}
+
+func parseProbe(sv string) (time.Time, string, bool) {
+	p := strings.Fields(sv)
+	if len(p) != 2 {
+		return time.Time{}, "", false
+	}
+	iv, err := strconv.ParseInt(p[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(iv, 0), p[1], true
+}
+
+// remoteAddr returns the host and port a git remote URL connects to or an
+// empty string if it can't be probed
+func remoteAddr(remoteURL string) string {
+	var host, port string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return ""
+		}
+		host, port = u.Hostname(), u.Port()
+		if port == "" {
+			port = map[string]string{"ssh": "22", "git": "9418", "http": "80", "https": "443"}[u.Scheme]
+		}
+	} else {
+		// scp like syntax, e.g. git@example.org:store.git, anything else is
+		// a local path
+		i := strings.Index(remoteURL, ":")
+		if i < 2 || strings.ContainsAny(remoteURL[:i], `/\`) {
+			return ""
+		}
+		host, port = remoteURL[:i], "22"
+		if j := strings.LastIndex(host, "@"); j >= 0 {
+			host = host[j+1:]
+		}
+	}
+
+	// hosts without a domain are likely aliases from the SSH config
+	if host == "" || port == "" || !strings.Contains(host, ".") {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// noteNotPushed tells the user that the local commits will only be pushed
+// by the next sync
+func (g *Git) noteNotPushed(ctx context.Context, remote, branch string) {
+	n, err := g.countCommits(ctx, remote+"/"+branch+"..HEAD")
+	if err != nil {
+		debug.Log("failed to count the unpushed commits: %s", err)
+		out.Noticef(ctx, "Committed locally (offline: not pushed). Run 'gopass sync' once you are online")
+		return
+	}
+	if n < 1 {
+		return
+	}
+	out.Noticef(ctx, "%d local commits in %s (offline: not pushed). Run 'gopass sync' once you are online", n, g.fs.Path())
+}
@@ -0,0 +1,84 @@
+package gitfs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteAddr(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://github.com/example/store.git":     "github.com:443",
+		"http://git.example.org:8080/store.git":    "git.example.org:8080",
+		"ssh://git@git.example.org/store.git":      "git.example.org:22",
+		"ssh://git@git.example.org:2222/store.git": "git.example.org:2222",
+		"git://git.example.org/store.git":          "git.example.org:9418",
+		"git@github.com:example/store.git":         "github.com:22",
+		"github.com:example/store.git":             "github.com:22",
+		"myserver:store.git":                       "",
+		"git@myserver:store.git":                   "",
+		"/srv/git/store.git":                       "",
+		"../store.git":                             "",
+		"file:///srv/git/store.git":                "",
+		`C:\git\store.git`:                         "",
+	} {
+		assert.Equal(t, want, remoteAddr(in), in)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithExplicitSync(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	a, b, _ := divergedClones(ctx, t)
+	b.ConfigureSync("rebase", true, 0)
+	implicit := ctxutil.WithExplicitSync(ctx, false)
+
+	t.Run("offline", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, b.Push(ctxutil.WithOffline(implicit, true), "", ""))
+		assert.Contains(t, buf.String(), "2 local commits")
+		assert.Contains(t, buf.String(), "(offline: not pushed)")
+
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "alice", readFile(t, a, "foo.gpg"))
+	})
+
+	t.Run("remote not reachable", func(t *testing.T) {
+		defer buf.Reset()
+		// pretend a recent probe failed
+		require.NoError(t, b.ConfigSet(ctx, probeKey, strconv.FormatInt(time.Now().Unix(), 10)+" offline"))
+
+		require.NoError(t, b.Push(ctxutil.WithAutoOffline(implicit, true), "", ""))
+		assert.Contains(t, buf.String(), "(offline: not pushed)")
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "alice", readFile(t, a, "foo.gpg"))
+	})
+
+	t.Run("explicit sync", func(t *testing.T) {
+		defer buf.Reset()
+		// the user asked for it, so the remote is not probed
+		require.NoError(t, b.Push(ctxutil.WithAutoOffline(ctx, true), "", ""))
+		assert.NotContains(t, buf.String(), "offline")
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "bob", readFile(t, a, "foo.gpg"))
+	})
+}
@@ -3,6 +3,7 @@ package gitfs
 import (
 	"context"
 	"fmt"
+	"io"
 )
 
 // Get retrieves the named content
@@ -15,6 +16,21 @@ func (g *Git) Set(ctx context.Context, name string, value []byte) error {
 	return g.fs.Set(ctx, name, value)
 }
 
+// Open returns a reader for the named content
+func (g *Git) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return g.fs.Open(ctx, name)
+}
+
+// Size returns the size of the named content in bytes
+func (g *Git) Size(ctx context.Context, name string) (int64, error) {
+	return g.fs.Size(ctx, name)
+}
+
+// SetFrom writes the content read from r
+func (g *Git) SetFrom(ctx context.Context, name string, r io.Reader) error {
+	return g.fs.SetFrom(ctx, name, r)
+}
+
 // Delete removes the named entity
 func (g *Git) Delete(ctx context.Context, name string) error {
 	return g.fs.Delete(ctx, name)
@@ -65,3 +81,8 @@ func (g *Git) Fsck(ctx context.Context) error {
 func (g *Git) Link(ctx context.Context, from, to string) error {
 	return g.fs.Link(ctx, from, to)
 }
+
+// RebuildIndex rebuilds the index of the entries
+func (g *Git) RebuildIndex(ctx context.Context) error {
+	return g.fs.RebuildIndex(ctx)
+}
@@ -0,0 +1,402 @@
+package gitfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/fsutil"
+)
+
+const (
+	// lastSyncKey is the local git config key holding the time of the last
+	// successful push
+	lastSyncKey = "gopass.lastsync"
+	// maxConflictRounds limits the number of commits a rebase can stop at
+	maxConflictRounds = 100
+	// conflictSection is the local git config section remembering the merge
+	// base of every remote copy kept by a conflicting pull
+	conflictSection = "gopass-conflict"
+)
+
+// ConfigureSync sets how remote changes are integrated (merge, rebase or
+// ff-only) and when the implicit syncs after each change happen. If autoPush
+// is false or the last sync was less than interval ago they are skipped.
+// Syncs requested by the user (ctxutil.IsExplicitSync) always happen.
+func (g *Git) ConfigureSync(strategy string, autoPush bool, interval time.Duration) {
+	g.pullStrategy = strategy
+	g.noAutoPush = !autoPush
+	g.syncInterval = interval
+}
+
+// OnSync registers a function that is called after every successful pull or
+// push, implicit or requested by the user
+func (g *Git) OnSync(fn func(context.Context)) {
+	g.onSync = fn
+}
+
+// skipSync returns true if an implicit sync should not happen now
+func (g *Git) skipSync(ctx context.Context) bool {
+	if ctxutil.IsExplicitSync(ctx) {
+		return false
+	}
+	if g.noAutoPush {
+		debug.Log("Skipping implicit sync of %s. autopush=false", g.fs.Path())
+		return true
+	}
+	if g.syncInterval <= 0 {
+		return false
+	}
+	if since := time.Since(g.lastSync(ctx)); since < g.syncInterval {
+		debug.Log("Skipping implicit sync of %s. Last sync %s ago, interval %s", g.fs.Path(), since, g.syncInterval)
+		return true
+	}
+	return false
+}
+
+// lastSync returns the time of the last successful push or the zero time
+func (g *Git) lastSync(ctx context.Context) time.Time {
+	sv, err := g.ConfigGet(ctx, lastSyncKey)
+	if err != nil {
+		return time.Time{}
+	}
+	iv, err := strconv.ParseInt(sv, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(iv, 0)
+}
+
+// recordSync remembers the time of a successful push
+func (g *Git) recordSync(ctx context.Context) {
+	if err := g.ConfigSet(ctx, lastSyncKey, strconv.FormatInt(time.Now().Unix(), 10)); err != nil {
+		debug.Log("failed to record the last sync: %s", err)
+	}
+}
+
+// Head returns the current commit or an empty string for an empty repo
+func (g *Git) Head(ctx context.Context) string {
+	stdout, _, err := g.captureCmd(ctx, "gitRevParse", "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(stdout))
+}
+
+// SyncedCommits returns the number of commits, without merges, pulled since
+// the given commit and pushed by the last sync
+func (g *Git) SyncedCommits(ctx context.Context, since string) (int, int, error) {
+	pullRange := "FETCH_HEAD"
+	if since != "" {
+		pullRange = since + "..FETCH_HEAD"
+	}
+	pulled, err := g.countCommits(ctx, pullRange)
+	if err != nil {
+		return 0, 0, err
+	}
+	pushed, err := g.countCommits(ctx, "FETCH_HEAD..HEAD")
+	if err != nil {
+		return 0, 0, err
+	}
+	return pulled, pushed, nil
+}
+
+// RemoteURL returns the URL of the remote the current branch is synced
+// with, if any
+func (g *Git) RemoteURL(ctx context.Context) string {
+	u, err := g.ConfigGet(ctx, "remote."+g.defaultRemote(ctx, g.defaultBranch(ctx))+".url")
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// RemoteStatus fetches the remote of the current branch and returns the
+// number of commits, without merges, only in the local branch (ahead) and
+// only in the remote one (behind). Nothing is merged.
+func (g *Git) RemoteStatus(ctx context.Context) (int, int, error) {
+	if ctxutil.IsNoNetwork(ctx) {
+		return 0, 0, fmt.Errorf("network access disabled")
+	}
+	if !g.IsInitialized() {
+		return 0, 0, store.ErrGitNotInit
+	}
+
+	branch := g.defaultBranch(ctx)
+	remote := g.defaultRemote(ctx, branch)
+	if v, err := g.ConfigGet(ctx, "remote."+remote+".url"); err != nil || v == "" {
+		return 0, 0, store.ErrGitNoRemote
+	}
+
+	if err := g.Cmd(ctx, "gitFetch", "fetch", "--quiet", remote, branch); err != nil {
+		return 0, 0, err
+	}
+	ahead, err := g.countCommits(ctx, "FETCH_HEAD..HEAD")
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := g.countCommits(ctx, "HEAD..FETCH_HEAD")
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+func (g *Git) countCommits(ctx context.Context, revs string) (int, error) {
+	stdout, stderr, err := g.captureCmd(ctx, "gitRevList", "rev-list", "--count", "--no-merges", revs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count commits: %s", strings.TrimSpace(string(stderr)))
+	}
+	return strconv.Atoi(strings.TrimSpace(string(stdout)))
+}
+
+// pull integrates the remote changes using the configured strategy. If the
+// same files were changed locally and remotely the local version is kept and
+// the remote one is stored next to it.
+func (g *Git) pull(ctx context.Context, remote, branch string, sign []string) error {
+	defer g.meta.reset()
+
+	args := []string{"pull"}
+	switch g.pullStrategy {
+	case "rebase":
+		args = append(args, "--rebase")
+	case "ff-only":
+		args = append(args, "--ff-only")
+	default:
+		args = append(args, "--no-rebase")
+	}
+	args = append(args, sign...)
+
+	_, stderr, err := g.captureCmd(ctx, "gitPull", append(args, remote, branch)...)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		// undo what the killed pull did, even if we're interrupted
+		g.abortPull(ctxutil.WithoutCancel(ctx))
+		return err
+	}
+	se := strings.TrimSpace(string(stderr))
+
+	if g.pullStrategy == "ff-only" {
+		return fmt.Errorf("can not fast-forward %s, the local and remote changes have diverged. Use the merge or rebase pullstrategy or reconcile them manually: %s", g.fs.Path(), se)
+	}
+
+	conflicts, cerr := g.unmerged(ctx)
+	if cerr != nil || len(conflicts) < 1 {
+		g.abortPull(ctx)
+		return fmt.Errorf("%s: %s", err, se)
+	}
+	if err := g.resolveConflicts(ctx, conflicts, sign); err != nil {
+		g.abortPull(ctx)
+		return fmt.Errorf("failed to resolve conflicting changes: %w", err)
+	}
+	return nil
+}
+
+// resolveConflicts keeps both versions of all conflicting files and finishes
+// the pending merge or rebase. A rebase may stop at every replayed commit.
+func (g *Git) resolveConflicts(ctx context.Context, conflicts map[string]map[int]string, sign []string) error {
+	short := "remote"
+	if stdout, _, err := g.captureCmd(ctx, "gitRevParse", "rev-parse", "--short", "FETCH_HEAD"); err == nil {
+		short = strings.TrimSpace(string(stdout))
+	}
+
+	// the local commit before a rebase started is ORIG_HEAD
+	local := "HEAD"
+	if g.inRebase() {
+		local = "ORIG_HEAD"
+	}
+	base := ""
+	if stdout, _, err := g.captureCmd(ctx, "gitMergeBase", "merge-base", local, "FETCH_HEAD"); err == nil {
+		base = strings.TrimSpace(string(stdout))
+	}
+
+	for i := 0; i < maxConflictRounds; i++ {
+		rebase := g.inRebase()
+		for name, stages := range conflicts {
+			if err := g.keepBoth(ctx, name, stages, rebase, short, base); err != nil {
+				return err
+			}
+		}
+
+		if !rebase {
+			args := append([]string{"commit", "--no-edit"}, sign...)
+			return g.Cmd(ctx, "gitCommit", args...)
+		}
+
+		// the editor must not be started for the commit message
+		_, stderr, err := g.captureCmd(ctx, "gitRebase", "-c", "core.editor=true", "rebase", "--continue")
+		if err == nil {
+			return nil
+		}
+		conflicts, err = g.unmerged(ctx)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) < 1 {
+			return fmt.Errorf("failed to continue rebase: %s", strings.TrimSpace(string(stderr)))
+		}
+	}
+	return fmt.Errorf("too many conflicting commits")
+}
+
+// keepBoth resolves the conflict of a single file. The local version stays
+// at its place and the remote version is renamed to
+// <name>.conflict-<commit><ext>, e.g. foo/bar.conflict-1a2b3c4.gpg, so that
+// it is still a valid secret. The merge base is remembered for gopass merge.
+func (g *Git) keepBoth(ctx context.Context, name string, stages map[int]string, rebase bool, short, base string) error {
+	// during a rebase "ours" (stage 2) is the upstream branch we replay our
+	// commits on and "theirs" (stage 3) our local commit
+	local, remote := stages[2], stages[3]
+	if rebase {
+		local, remote = remote, local
+	}
+
+	fn := filepath.Join(g.fs.Path(), name)
+	if local != "" {
+		if err := g.writeBlob(ctx, local, fn); err != nil {
+			return err
+		}
+	} else if err := os.Remove(fn); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	files := []string{name}
+
+	if remote != "" {
+		cname := conflictName(name, short)
+		if local == "" {
+			// removed locally, the remote change will be in the conflict
+			// copy only
+			cname = name
+		}
+		if err := g.writeBlob(ctx, remote, filepath.Join(g.fs.Path(), cname)); err != nil {
+			return err
+		}
+		files = append(files, cname)
+		if cname != name {
+			if base != "" && stages[1] != "" {
+				if err := g.ConfigSet(ctx, conflictKey(cname), base); err != nil {
+					debug.Log("failed to record the merge base of %s: %s", cname, err)
+				}
+			}
+			out.Warningf(ctx, "%s was changed locally and remotely. Kept the remote version as %s, please reconcile them, e.g. with gopass merge %s", secretName(name), secretName(cname), secretName(name))
+		} else {
+			out.Warningf(ctx, "%s was removed locally but changed remotely. Kept the remote version, please remove it again if that's intended", secretName(name))
+		}
+	} else {
+		out.Warningf(ctx, "%s was changed locally but removed remotely. Kept the local version, please remove it again if that's intended", secretName(name))
+	}
+
+	return g.Add(ctx, files...)
+}
+
+// ConflictBase returns the commit both versions of a conflicting file were
+// based on, given the name of the remote copy. It's empty if there was none
+// or the copy was pulled from elsewhere.
+func (g *Git) ConflictBase(ctx context.Context, name string) string {
+	base, err := g.ConfigGet(ctx, conflictKey(name))
+	if err != nil {
+		return ""
+	}
+	return base
+}
+
+// ResolveConflict forgets the merge base of a remote copy that was merged
+func (g *Git) ResolveConflict(ctx context.Context, name string) error {
+	if g.ConflictBase(ctx, name) == "" {
+		return nil
+	}
+	return g.Cmd(ctx, "gitConfigRemoveSection", "config", "--local", "--remove-section", conflictSection+"."+name)
+}
+
+func conflictKey(name string) string {
+	return conflictSection + "." + name + ".base"
+}
+
+func (g *Git) writeBlob(ctx context.Context, blob, fn string) error {
+	stdout, stderr, err := g.captureCmd(ctx, "gitCatFile", "cat-file", "blob", blob)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %s", blob, strings.TrimSpace(string(stderr)))
+	}
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(fn, stdout, 0600)
+}
+
+// unmerged returns the blobs of every stage of all conflicting files
+func (g *Git) unmerged(ctx context.Context) (map[string]map[int]string, error) {
+	stdout, stderr, err := g.captureCmd(ctx, "gitLsFiles", "-c", "core.quotePath=false", "ls-files", "--unmerged")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %s", strings.TrimSpace(string(stderr)))
+	}
+	conflicts := make(map[string]map[int]string)
+	for _, line := range strings.Split(string(stdout), "\n") {
+		// <mode> <object> <stage>\t<file>
+		p := strings.SplitN(line, "\t", 2)
+		if len(p) < 2 {
+			continue
+		}
+		f := strings.Fields(p[0])
+		if len(f) < 3 {
+			continue
+		}
+		stage, err := strconv.Atoi(f[2])
+		if err != nil {
+			continue
+		}
+		if conflicts[p[1]] == nil {
+			conflicts[p[1]] = make(map[int]string, 3)
+		}
+		conflicts[p[1]][stage] = f[1]
+	}
+	return conflicts, nil
+}
+
+func (g *Git) inRebase() bool {
+	return fsutil.IsDir(filepath.Join(g.fs.Path(), ".git", "rebase-merge")) || fsutil.IsDir(filepath.Join(g.fs.Path(), ".git", "rebase-apply"))
+}
+
+// abortPull leaves the repo as it was before the pull
+func (g *Git) abortPull(ctx context.Context) {
+	if g.inRebase() {
+		if err := g.Cmd(ctx, "gitRebase", "rebase", "--abort"); err != nil {
+			out.Errorf(ctx, "Failed to abort rebase: %s", err)
+		}
+		return
+	}
+	if fsutil.IsFile(filepath.Join(g.fs.Path(), ".git", "MERGE_HEAD")) {
+		if err := g.Cmd(ctx, "gitMerge", "merge", "--abort"); err != nil {
+			out.Errorf(ctx, "Failed to abort merge: %s", err)
+		}
+	}
+}
+
+// conflictName returns the name of the remote copy of a conflicting file
+func conflictName(name, short string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if base == "" || strings.HasSuffix(base, "/") {
+		// dotfiles, e.g. .gpg-id
+		return name + ".conflict-" + short
+	}
+	return base + ".conflict-" + short + ext
+}
+
+// secretName strips the extension of encrypted files
+func secretName(name string) string {
+	if ext := filepath.Ext(name); ext != "" && ext != name && !strings.HasSuffix(name, "/"+ext) {
+		return strings.TrimSuffix(name, ext)
+	}
+	return name
+}
@@ -0,0 +1,254 @@
+package gitfs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// divergedClones returns two clones of a shared remote that both changed
+// foo.gpg in different ways. Only the first one pushed its change.
+func divergedClones(ctx context.Context, t *testing.T) (*Git, *Git, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	remote := filepath.Join(td, "remote")
+	require.NoError(t, exec.Command("git", "init", "--bare", remote).Run())
+
+	a, err := Clone(ctx, remote, filepath.Join(td, "a"))
+	require.NoError(t, err)
+	require.NoError(t, a.InitConfig(ctx, "Alice", "alice@example.org"))
+	commitFile(ctx, t, a, "foo.gpg", "base")
+	commitFile(ctx, t, a, "bar.gpg", "base")
+	require.NoError(t, a.Push(ctx, "", ""))
+
+	b, err := Clone(ctx, remote, filepath.Join(td, "b"))
+	require.NoError(t, err)
+	require.NoError(t, b.InitConfig(ctx, "Bob", "bob@example.org"))
+
+	commitFile(ctx, t, a, "foo.gpg", "alice")
+	require.NoError(t, a.Push(ctx, "", ""))
+	commitFile(ctx, t, b, "foo.gpg", "bob")
+	commitFile(ctx, t, b, "bar.gpg", "bob")
+
+	return a, b, remote
+}
+
+func commitFile(ctx context.Context, t *testing.T, g *Git, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(g.fs.Path(), name), []byte(content), 0600))
+	require.NoError(t, g.Add(ctx, name))
+	require.NoError(t, g.Commit(ctx, "update "+name))
+}
+
+func readFile(t *testing.T, g *Git, name string) string {
+	t.Helper()
+
+	buf, err := os.ReadFile(filepath.Join(g.fs.Path(), name))
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func shortHead(ctx context.Context, t *testing.T, g *Git) string {
+	t.Helper()
+
+	stdout, _, err := g.captureCmd(ctx, "gitRevParse", "rev-parse", "--short", "HEAD")
+	require.NoError(t, err)
+	return string(bytes.TrimSpace(stdout))
+}
+
+func TestPullStrategies(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithExplicitSync(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	for _, strategy := range []string{"merge", "rebase"} {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			defer buf.Reset()
+
+			a, b, _ := divergedClones(ctx, t)
+			remoteHead := shortHead(ctx, t, a)
+
+			head := b.Head(ctx)
+			b.ConfigureSync(strategy, true, 0)
+			require.NoError(t, b.Push(ctx, "", ""))
+			assert.False(t, b.inRebase())
+
+			pulled, pushed, err := b.SyncedCommits(ctx, head)
+			require.NoError(t, err)
+			assert.Equal(t, 1, pulled)
+			assert.Equal(t, 2, pushed)
+
+			// the local version is kept, the remote one stored next to it
+			cname := "foo.conflict-" + remoteHead + ".gpg"
+			assert.Equal(t, "bob", readFile(t, b, "foo.gpg"))
+			assert.Equal(t, "alice", readFile(t, b, cname))
+			assert.Equal(t, "bob", readFile(t, b, "bar.gpg"))
+			assert.Contains(t, buf.String(), "foo was changed locally and remotely")
+			assert.Contains(t, buf.String(), "foo.conflict-"+remoteHead)
+
+			// both versions have been pushed
+			require.NoError(t, a.Pull(ctx, "", ""))
+			assert.Equal(t, "bob", readFile(t, a, "foo.gpg"))
+			assert.Equal(t, "alice", readFile(t, a, cname))
+		})
+	}
+
+	t.Run("ff-only", func(t *testing.T) {
+		defer buf.Reset()
+
+		_, b, _ := divergedClones(ctx, t)
+		head := shortHead(ctx, t, b)
+
+		b.ConfigureSync("ff-only", true, 0)
+		err := b.Pull(ctx, "", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "diverged")
+		assert.Equal(t, head, shortHead(ctx, t, b))
+		assert.Equal(t, "bob", readFile(t, b, "foo.gpg"))
+	})
+}
+
+func TestConflictBase(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithExplicitSync(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	for _, strategy := range []string{"merge", "rebase"} {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			defer buf.Reset()
+
+			a, b, _ := divergedClones(ctx, t)
+			cname := "foo.conflict-" + shortHead(ctx, t, a) + ".gpg"
+
+			b.ConfigureSync(strategy, true, 0)
+			require.NoError(t, b.Push(ctx, "", ""))
+
+			base := b.ConflictBase(ctx, cname)
+			require.NotEmpty(t, base)
+			content, err := b.GetRevision(ctx, "foo.gpg", base)
+			require.NoError(t, err)
+			assert.Equal(t, "base", string(content))
+
+			// not recorded for copies pulled from the remote
+			require.NoError(t, a.Pull(ctx, "", ""))
+			assert.Equal(t, "", a.ConflictBase(ctx, cname))
+
+			require.NoError(t, b.ResolveConflict(ctx, cname))
+			assert.Equal(t, "", b.ConflictBase(ctx, cname))
+			assert.NoError(t, b.ResolveConflict(ctx, cname))
+		})
+	}
+}
+
+func TestAutoPush(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithExplicitSync(ctx, true)
+
+	buf := &bytes.Buffer{}
+	out.Stdout = buf
+	out.Stderr = buf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	a, b, _ := divergedClones(ctx, t)
+	implicit := ctxutil.WithExplicitSync(ctx, false)
+	synced := 0
+	b.OnSync(func(context.Context) { synced++ })
+
+	t.Run("disabled", func(t *testing.T) {
+		b.ConfigureSync("rebase", false, 0)
+		require.NoError(t, b.Push(implicit, "", ""))
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "alice", readFile(t, a, "foo.gpg"))
+		assert.Equal(t, 0, synced)
+
+		require.NoError(t, b.Push(ctx, "", ""))
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "bob", readFile(t, a, "foo.gpg"))
+		assert.Equal(t, 1, synced)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		b.ConfigureSync("rebase", true, time.Hour)
+		commitFile(ctx, t, b, "bar.gpg", "bob again")
+		require.NoError(t, b.Push(implicit, "", ""))
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "bob", readFile(t, a, "bar.gpg"))
+		assert.Equal(t, 1, synced)
+
+		b.ConfigureSync("rebase", true, time.Nanosecond)
+		require.NoError(t, b.Push(implicit, "", ""))
+		require.NoError(t, a.Pull(ctx, "", ""))
+		assert.Equal(t, "bob again", readFile(t, a, "bar.gpg"))
+		assert.Equal(t, 2, synced)
+	})
+}
+
+func TestConflictName(t *testing.T) {
+	for name, want := range map[string]string{
+		"foo.gpg":           "foo.conflict-abc.gpg",
+		"foo/bar.age":       "foo/bar.conflict-abc.age",
+		".gpg-id":           ".gpg-id.conflict-abc",
+		"foo/.gpg-id":       "foo/.gpg-id.conflict-abc",
+		"foo/bar.baz/zab":   "foo/bar.baz/zab.conflict-abc",
+		"foo/bar.baz/z.gpg": "foo/bar.baz/z.conflict-abc.gpg",
+	} {
+		assert.Equal(t, want, conflictName(name, "abc"), name)
+	}
+}
+
+func TestRemoteStatus(t *testing.T) {
+	ctx := context.Background()
+
+	_, b, remote := divergedClones(ctx, t)
+	assert.Equal(t, remote, b.RemoteURL(ctx))
+
+	ahead, behind, err := b.RemoteStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Equal(t, 1, behind)
+	// nothing was merged
+	assert.Equal(t, "bob", readFile(t, b, "foo.gpg"))
+
+	_, _, err = b.RemoteStatus(ctxutil.WithNoNetwork(ctx, true))
+	assert.Error(t, err)
+
+	require.NoError(t, b.RemoveRemote(ctx, "origin"))
+	assert.Equal(t, "", b.RemoteURL(ctx))
+	_, _, err = b.RemoteStatus(ctx)
+	assert.ErrorIs(t, err, store.ErrGitNoRemote)
+}
@@ -59,6 +59,9 @@ func CryptoNameFromBackend(be CryptoBackend) string {
 
 // StorageBackendFromName parses the identifier into a storage backend
 func StorageBackendFromName(name string) StorageBackend {
+	if name == "git" {
+		name = "gitfs"
+	}
 	if b, found := storageNameToBackendMap[name]; found {
 		return b
 	}
@@ -56,11 +56,32 @@ func (o *OnDisk) Get(key string) ([]string, error) {
 	return strings.Split(string(buf), "\n"), nil
 }
 
-// Set adds an entry to the cache.
+// Set adds an entry to the cache. The entry is written to a temporary file
+// first and then renamed so concurrent readers never see a partial entry.
 func (o *OnDisk) Set(key string, value []string) error {
 	key = fsutil.CleanFilename(key)
 	fn := filepath.Join(o.dir, key)
-	if err := os.WriteFile(fn, []byte(strings.Join(value, "\n")), 0644); err != nil {
+
+	fh, err := os.CreateTemp(o.dir, "."+key+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s in %s: %w", key, o.dir, err)
+	}
+	tmp := fh.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err := fh.WriteString(strings.Join(value, "\n")); err != nil {
+		_ = fh.Close()
+		return fmt.Errorf("failed to write %s to %s: %w", key, tmp, err)
+	}
+	if err := fh.Close(); err != nil {
+		return fmt.Errorf("failed to write %s to %s: %w", key, tmp, err)
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, fn); err != nil {
 		return fmt.Errorf("failed to write %s to %s: %w", key, fn, err)
 	}
 	return nil
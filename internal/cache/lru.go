@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+// LRU implements a bounded cache of byte slices in memory. Once it's full the
+// least recently used entry is evicted. Values are copied when they are set
+// and returned and overwritten with zeros when they are evicted, replaced or
+// removed. It is concurrency safe.
+type LRU struct {
+	sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+}
+
+// NewLRU creates a new cache holding at most size entries
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// Get retrieves a copy of a single entry and marks it as recently used
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, found := c.entries[key]
+	if !found {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+	return append([]byte(nil), e.Value.(*lruEntry).value...), true
+}
+
+// Set creates or overwrites an entry, evicting the least recently used ones
+// if the cache is full
+func (c *LRU) Set(key string, value []byte) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.size < 1 {
+		return
+	}
+
+	value = append([]byte(nil), value...)
+	if e, found := c.entries[key]; found {
+		ce := e.Value.(*lruEntry)
+		zero(ce.value)
+		ce.value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Remove removes a single entry from the cache
+func (c *LRU) Remove(key string) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, found := c.entries[key]; found {
+		c.remove(e)
+	}
+}
+
+// RemovePrefix removes all entries whose key starts with the prefix
+func (c *LRU) RemovePrefix(prefix string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for key, e := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(e)
+		}
+	}
+}
+
+// Purge removes all entries from the cache
+func (c *LRU) Purge() {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, e := range c.entries {
+		c.remove(e)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (c *LRU) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.order.Len()
+}
+
+// Stats returns how often Get found an entry and how often it didn't
+func (c *LRU) Stats() (hits, misses int) {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.hits, c.misses
+}
+
+func (c *LRU) remove(e *list.Element) {
+	ce := e.Value.(*lruEntry)
+	zero(ce.value)
+	delete(c.entries, ce.key)
+	c.order.Remove(e)
+}
+
+// zero overwrites the value. Copies made by the Go runtime, e.g. when a slice
+// grew, can not be reached anymore.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
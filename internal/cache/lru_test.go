@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(2)
+
+	_, found := c.Get("foo")
+	assert.False(t, found)
+
+	foo := []byte("foo")
+	c.Set("foo", foo)
+	foo[0] = 'b'
+	val, found := c.Get("foo")
+	assert.True(t, found)
+	assert.Equal(t, "foo", string(val))
+
+	// returned values are copies
+	val[0] = 'z'
+	val, _ = c.Get("foo")
+	assert.Equal(t, "foo", string(val))
+
+	// bar is evicted, foo was used more recently
+	c.Set("bar", []byte("bar"))
+	_, _ = c.Get("foo")
+	c.Set("baz", []byte("baz"))
+	assert.Equal(t, 2, c.Len())
+	_, found = c.Get("bar")
+	assert.False(t, found)
+	_, found = c.Get("foo")
+	assert.True(t, found)
+
+	hits, misses := c.Stats()
+	assert.Equal(t, 4, hits)
+	assert.Equal(t, 2, misses)
+
+	c.Set("foo", []byte("new"))
+	val, _ = c.Get("foo")
+	assert.Equal(t, "new", string(val))
+
+	c.Remove("foo")
+	_, found = c.Get("foo")
+	assert.False(t, found)
+
+	c.Set("dir/a", []byte("a"))
+	c.RemovePrefix("dir/")
+	assert.Equal(t, 1, c.Len())
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRUZero(t *testing.T) {
+	c := NewLRU(1)
+	c.Set("foo", []byte("secret"))
+	e := c.entries["foo"].Value.(*lruEntry)
+	stored := e.value
+
+	c.Set("bar", []byte("other"))
+	assert.Equal(t, make([]byte, 6), stored)
+}
+
+func TestLRUDisabled(t *testing.T) {
+	c := NewLRU(0)
+	c.Set("foo", []byte("foo"))
+	_, found := c.Get("foo")
+	assert.False(t, found)
+}
@@ -0,0 +1,155 @@
+package bash
+
+import (
+	"bytes"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/urfave/cli/v2"
+)
+
+// secretCommands are the commands taking a secret name as argument. They are
+// completed with gopass ls --prefix instead of urfave/cli's slow generic
+// completion.
+var secretCommands = map[string]bool{
+	"audit":    true,
+	"cat":      true,
+	"copy":     true,
+	"delete":   true,
+	"edit":     true,
+	"generate": true,
+	"history":  true,
+	"insert":   true,
+	"link":     true,
+	"list":     true,
+	"merge":    true,
+	"move":     true,
+	"otp":      true,
+	"show":     true,
+	"sum":      true,
+	"summon":   true,
+}
+
+var reIdent = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// ident turns the program name into a valid shell identifier
+func ident(name string) string {
+	return reIdent.ReplaceAllString(name, "_")
+}
+
+func flagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+// flagNames returns all names of the flags, e.g. --clip -c
+func flagNames(flags []cli.Flag) string {
+	var names []string
+	for _, f := range flags {
+		for _, n := range f.Names() {
+			names = append(names, flagName(n))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// valueFlags returns the names of all flags of the app and its commands that
+// expect a value
+func valueFlags(a *cli.App) string {
+	seen := map[string]bool{}
+	add := func(flags []cli.Flag) {
+		for _, f := range flags {
+			if _, ok := f.(*cli.BoolFlag); ok {
+				continue
+			}
+			for _, n := range f.Names() {
+				seen[flagName(n)] = true
+			}
+		}
+	}
+
+	add(a.Flags)
+	for _, c := range a.Commands {
+		add(c.Flags)
+		for _, sc := range c.Subcommands {
+			add(sc.Flags)
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func visibleNames(cmds []*cli.Command, filter func(*cli.Command) bool) []string {
+	var names []string
+	for _, c := range cmds {
+		if c.Hidden || !filter(c) {
+			continue
+		}
+		names = append(names, c.Name)
+		names = append(names, c.Aliases...)
+	}
+	return names
+}
+
+func all(*cli.Command) bool {
+	return true
+}
+
+// commandNames returns the names and aliases of all visible commands
+func commandNames(cmds []*cli.Command) string {
+	return strings.Join(visibleNames(cmds, all), " ")
+}
+
+// commandPattern returns a case pattern matching all visible commands
+func commandPattern(cmds []*cli.Command) string {
+	return pattern(visibleNames(cmds, all))
+}
+
+// secretPattern returns a case pattern matching the commands taking a secret
+func secretPattern(cmds []*cli.Command) string {
+	return pattern(visibleNames(cmds, func(c *cli.Command) bool {
+		return secretCommands[c.Name]
+	}))
+}
+
+func pattern(names []string) string {
+	if len(names) < 1 {
+		// only matches the empty command
+		return `""`
+	}
+	return strings.Join(names, "|")
+}
+
+// GetCompletion returns a bash completion script
+func GetCompletion(a *cli.App) (string, error) {
+	tplFuncs := template.FuncMap{
+		"commandNames":   commandNames,
+		"commandPattern": commandPattern,
+		"flagNames":      flagNames,
+		"ident":          ident,
+		"secretPattern":  secretPattern,
+		"valueFlags":     valueFlags,
+	}
+	tpl, err := template.New("bash").Funcs(tplFuncs).Parse(bashTemplate)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tpl.Execute(buf, a); err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		buf.WriteString("\ncomplete -F _" + ident(a.Name) + "_complete " + a.Name + ".exe")
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,86 @@
+package bash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func testApp() *cli.App {
+	app := cli.NewApp()
+	app.Name = "gopass"
+	app.Flags = []cli.Flag{
+		&cli.BoolFlag{Name: "clip", Aliases: []string{"c"}},
+		&cli.StringFlag{Name: "key"},
+	}
+	app.Commands = []*cli.Command{
+		{
+			Name:    "copy",
+			Aliases: []string{"cp"},
+			Flags: []cli.Flag{
+				&cli.BoolFlag{Name: "force", Aliases: []string{"f"}},
+			},
+		},
+		{
+			Name: "mounts",
+			Subcommands: []*cli.Command{
+				{
+					Name:    "remove",
+					Aliases: []string{"rm"},
+					Flags: []cli.Flag{
+						&cli.StringFlag{Name: "store"},
+					},
+				},
+				{Name: "hidden", Hidden: true},
+			},
+		},
+	}
+	return app
+}
+
+func TestFlagNames(t *testing.T) {
+	assert.Equal(t, "--clip -c --key", flagNames(testApp().Flags))
+	assert.Equal(t, "", flagNames(nil))
+}
+
+func TestValueFlags(t *testing.T) {
+	assert.Equal(t, "--key --store", valueFlags(testApp()))
+}
+
+func TestCommandNames(t *testing.T) {
+	app := testApp()
+	assert.Equal(t, "copy cp mounts", commandNames(app.Commands))
+	assert.Equal(t, "remove rm", commandNames(app.Commands[1].Subcommands))
+	assert.Equal(t, "copy|cp|mounts", commandPattern(app.Commands))
+	assert.Equal(t, "copy|cp", secretPattern(app.Commands))
+	assert.Equal(t, `""`, secretPattern(app.Commands[1:]))
+}
+
+func TestIdent(t *testing.T) {
+	assert.Equal(t, "gopass", ident("gopass"))
+	assert.Equal(t, "bash_test", ident("bash.test"))
+	assert.Equal(t, "my_gopass", ident("my-gopass"))
+}
+
+func TestGetCompletion(t *testing.T) {
+	sv, err := GetCompletion(testApp())
+	require.NoError(t, err)
+	assert.Contains(t, sv, "complete -F _gopass_complete gopass")
+	assert.Contains(t, sv, `gopass ls --flat --prefix "$1"`)
+	assert.Contains(t, sv, `_gopass_value_flags=" --key --store "`)
+	assert.Contains(t, sv, `"mounts remove"|"mounts rm")`)
+	assert.Contains(t, sv, "copy|cp)\n        printf '%s\\n' --force -f\n")
+	assert.NotContains(t, sv, "hidden")
+
+	bashTemplate = "{{.unexported}}"
+	sv, err = GetCompletion(testApp())
+	assert.Error(t, err)
+	assert.Contains(t, sv, "")
+
+	bashTemplate = "{{}}"
+	sv, err = GetCompletion(testApp())
+	assert.Error(t, err)
+	assert.Contains(t, sv, "")
+}
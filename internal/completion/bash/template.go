@@ -0,0 +1,127 @@
+package bash
+
+// see https://www.gnu.org/software/bash/manual/html_node/Programmable-Completion.html
+var bashTemplate = `{{ $prog := .Name }}{{ $fn := .Name | ident }}# bash completion for {{ $prog }}
+# source <({{ $prog }} completion bash)
+
+_{{ $fn }}_value_flags=" {{ valueFlags . }} "
+
+_{{ $fn }}_secrets() {
+    {{ $prog }} ls --flat --prefix "$1" 2>/dev/null </dev/null
+}
+
+_{{ $fn }}_commands() {
+    printf '%s\n' {{ commandNames .Commands }}
+}
+
+_{{ $fn }}_flags() {
+    case "$1" in
+{{- range .Commands }}{{ if not .Hidden }}{{ $cmd := . }}{{ range .Subcommands }}{{ if not .Hidden }}
+    "{{ $cmd.Name }} {{ .Name }}"{{ range .Aliases }}|"{{ $cmd.Name }} {{ . }}"{{ end }})
+        printf '%s\n' {{ flagNames .Flags }}
+        ;;{{ end }}{{ end }}
+    {{ .Name }}{{ range .Aliases }}|{{ . }}{{ end }})
+        printf '%s\n' {{ flagNames .Flags }}
+        ;;{{ end }}{{ end }}
+    *" "*)
+        ;;
+    *)
+        printf '%s\n' {{ flagNames .Flags }}
+        ;;
+    esac
+}
+
+_{{ $fn }}_subcommands() {
+    case "$1" in
+{{- range .Commands }}{{ if and (not .Hidden) .Subcommands }}
+    {{ .Name }}{{ range .Aliases }}|{{ . }}{{ end }})
+        printf '%s\n' {{ commandNames .Subcommands }}
+        ;;{{ end }}{{ end }}
+    esac
+}
+
+_{{ $fn }}_complete() {
+    local cur prev cmd secret after word i
+    local -a args
+    local IFS=$'\n'
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    # collect the arguments before the cursor and the first one after it,
+    # skipping flags and their values
+    for (( i=1; i < ${#COMP_WORDS[@]}; i++ )); do
+        word="${COMP_WORDS[i]}"
+        if [[ $i -eq $COMP_CWORD || "$word" == -* || "$_{{ $fn }}_value_flags" == *" ${COMP_WORDS[i-1]} "* ]]; then
+            continue
+        fi
+        if [[ $i -lt $COMP_CWORD ]]; then
+            args+=("$word")
+        elif [[ -z "$after" ]]; then
+            after="$word"
+        fi
+    done
+    cmd="${args[0]}"
+
+    case "$cmd" in
+    {{ commandPattern .Commands }})
+        secret="${args[1]:-$after}"
+        ;;
+    *)
+        # gopass <secret> is gopass show <secret>
+        secret="${cmd:-$after}"
+        ;;
+    esac
+
+    case "$prev" in
+    --store)
+        COMPREPLY=( $(compgen -W "$({{ $prog }} completion mounts 2>/dev/null </dev/null)" -- "$cur") )
+        return 0
+        ;;
+    --key)
+        if [[ -n "$secret" ]]; then
+            COMPREPLY=( $(compgen -W "$({{ $prog }} completion keys "$secret" 2>/dev/null </dev/null)" -- "$cur") )
+        fi
+        return 0
+        ;;
+    esac
+    if [[ "$_{{ $fn }}_value_flags" == *" $prev "* ]]; then
+        return 0
+    fi
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=( $(compgen -W "$(_{{ $fn }}_flags "$cmd"; _{{ $fn }}_flags "${args[0]} ${args[1]}")" -- "$cur") )
+        return 0
+    fi
+
+    if [[ ${#args[@]} -eq 0 ]]; then
+        COMPREPLY=( $(compgen -W "$(_{{ $fn }}_commands)" -- "$cur") $(_{{ $fn }}_secrets "$cur") )
+        return 0
+    fi
+
+    case "$cmd" in
+    {{ secretPattern .Commands }})
+        if [[ ${#args[@]} -eq 2 && "$cmd" == "show" ]]; then
+            COMPREPLY=( $(compgen -W "$({{ $prog }} completion keys "${args[1]}" 2>/dev/null </dev/null)" -- "$cur") )
+        else
+            COMPREPLY=( $(_{{ $fn }}_secrets "$cur") )
+        fi
+        ;;
+    {{ commandPattern .Commands }})
+        if [[ ${#args[@]} -eq 1 ]]; then
+            COMPREPLY=( $(compgen -W "$(_{{ $fn }}_subcommands "$cmd")" -- "$cur") )
+        fi
+        if [[ ${#COMPREPLY[@]} -eq 0 ]]; then
+            COMPREPLY=( $(compgen -W "$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null </dev/null)" -- "$cur") )
+        fi
+        ;;
+    *)
+        if [[ ${#args[@]} -eq 1 ]]; then
+            COMPREPLY=( $(compgen -W "$({{ $prog }} completion keys "$cmd" 2>/dev/null </dev/null)" -- "$cur") )
+        fi
+        ;;
+    esac
+    return 0
+}
+
+complete -F _{{ $fn }}_complete {{ $prog }}`
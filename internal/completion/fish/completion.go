@@ -41,6 +41,8 @@ func formatFlagFunc(typ string) func(cli.Flag) (string, error) {
 		switch ft := f.(type) {
 		case *cli.BoolFlag:
 			return formatFlag(ft.Name, ft.Usage, typ), nil
+		case *cli.DurationFlag:
+			return formatFlag(ft.Name, ft.Usage, typ), nil
 		case *cli.Float64Flag:
 			return formatFlag(ft.Name, ft.Usage, typ), nil
 		case *cli.GenericFlag:
@@ -28,7 +28,28 @@ function __fish_{{ $prog }}_print_gpg_keys
 end
 
 function __fish_{{ $prog }}_print_entries
-  {{ $prog }} ls --flat
+  {{ $prog }} ls --flat --prefix (commandline -ct) 2>/dev/null
+end
+
+function __fish_{{ $prog }}_print_mounts
+  {{ $prog }} completion mounts 2>/dev/null
+end
+
+# prints the keys of the secret given anywhere on the command line, only if
+# completion.decrypt is enabled
+function __fish_{{ $prog }}_print_keys
+  set -l cmd (commandline -o)
+  set -l cur (commandline -ct)
+  set -l prev ''
+  for w in $cmd[2..-1]
+    if not string match -q -- '-*' $w; and not contains -- $prev --store --key; and [ $w != $cur ]
+      if not contains -- $w {{ range .Commands }}{{ .Name }} {{ end }}
+        {{ $prog }} completion keys $w 2>/dev/null
+        return
+      end
+    end
+    set prev $w
+  end
 end
 
 function __fish_{{ $prog }}_print_dir
@@ -41,6 +62,8 @@ end
 complete -c $PROG -e
 complete -c $PROG -f -n '__fish_{{ $prog }}_needs_command' -a "(__fish_{{ $prog }}_print_entries)"
 complete -c $PROG -f -s c -l clip -r -a "(__fish_{{ $prog }}_print_entries)"
+complete -c $PROG -f -l store -r -a "(__fish_{{ $prog }}_print_mounts)"
+complete -c $PROG -f -l key -r -a "(__fish_{{ $prog }}_print_keys)"
 {{- $gflags := .Flags -}}
 {{ range .Commands }}
 complete -c $PROG -f -n '__fish_{{ $prog }}_needs_command' -a {{ .Name }} -d 'Command: {{ .Usage }}'
@@ -49,14 +72,17 @@ complete -c $PROG -f -n '__fish_{{ $prog }}_needs_command' -a {{ .Name }} -d 'Co
 complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }}' -a "(__fish_{{ $prog }}_print_entries)"{{ end -}}
 {{- if or (eq $cmd "insert") (eq $cmd "generate") (eq $cmd "list") (eq $cmd "ls") }}
 complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }}' -a "(__fish_{{ $prog }}_print_dir)"{{ end -}}
-{{- range .Subcommands }}
+{{- range .Subcommands }}{{ if not .Hidden }}
 {{- $subcmd := .Name }}
 complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }}' -a {{ $subcmd }} -d 'Subcommand: {{ .Usage }}'
 {{- range .Flags }}
-complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }} {{ $subcmd }} {{ if ne (. | formatShortFlag) "" }}-s {{ . | formatShortFlag }} {{ end }}-l {{ . | formatLongFlag }} -d "{{ . | formatFlagUsage }}"'
+complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }} {{ $subcmd }}' {{ if ne (. | formatShortFlag) "" }}-s {{ . | formatShortFlag }} {{ end }}-l {{ . | formatLongFlag }} -d "{{ . | formatFlagUsage }}"
 {{- end }}
-{{- range $gflags }}
-complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }} {{ $subcmd }} {{ if ne (. | formatShortFlag) "" }}-s {{ . | formatShortFlag }} {{ end }}-l {{ . | formatLongFlag }} -d "{{ . | formatFlagUsage }}"'
+{{- end }}{{ end }}
+{{- range .Flags }}
+complete -c $PROG -f -n '__fish_{{ $prog }}_uses_command {{ $cmd }}' {{ if ne (. | formatShortFlag) "" }}-s {{ . | formatShortFlag }} {{ end }}-l {{ . | formatLongFlag }} -d "{{ . | formatFlagUsage }}"
 {{- end }}
 {{- end }}
+{{- range $gflags }}
+complete -c $PROG -f -n '__fish_{{ $prog }}_needs_command' {{ if ne (. | formatShortFlag) "" }}-s {{ . | formatShortFlag }} {{ end }}-l {{ . | formatLongFlag }} -d "{{ . | formatFlagUsage }}"
 {{- end }}`
@@ -24,6 +24,8 @@ func formatFlagFunc() func(cli.Flag) (string, error) {
 		switch ft := f.(type) {
 		case *cli.BoolFlag:
 			return formatFlag(ft.Name, ft.Usage), nil
+		case *cli.DurationFlag:
+			return formatFlag(ft.Name, ft.Usage), nil
 		case *cli.Float64Flag:
 			return formatFlag(ft.Name, ft.Usage), nil
 		case *cli.GenericFlag:
@@ -50,9 +52,25 @@ func formatFlagFunc() func(cli.Flag) (string, error) {
 	}
 }
 
+// flagAction returns the completion of the value of --store and --key
+func flagAction(prog string, f cli.Flag) string {
+	if _, ok := f.(*cli.StringFlag); !ok {
+		return ""
+	}
+	switch longName(f.Names()[0]) {
+	case "store":
+		return ":mount:_" + prog + "_complete_mounts"
+	case "key":
+		return ":key:_" + prog + "_complete_secret_keys"
+	default:
+		return ""
+	}
+}
+
 // GetCompletion returns a zsh completion script
 func GetCompletion(a *cli.App) (string, error) {
 	tplFuncs := template.FuncMap{
+		"flagAction": flagAction,
 		"formatFlag": formatFlagFunc(),
 	}
 	tpl, err := template.New("zsh").Funcs(tplFuncs).Parse(zshTemplate)
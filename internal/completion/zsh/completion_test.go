@@ -61,6 +61,7 @@ func TestFormatflagFunc(t *testing.T) {
 	ff := formatFlagFunc()
 	for _, flag := range []cli.Flag{
 		&cli.BoolFlag{Name: "foo", Usage: "bar"},
+		&cli.DurationFlag{Name: "foo", Usage: "bar"},
 		&cli.Float64Flag{Name: "foo", Usage: "bar"},
 		&cli.GenericFlag{Name: "foo", Usage: "bar"},
 		&cli.Int64Flag{Name: "foo", Usage: "bar"},
@@ -80,3 +81,10 @@ func TestFormatflagFunc(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "", sv)
 }
+
+func TestFlagAction(t *testing.T) {
+	assert.Equal(t, ":mount:_gopass_complete_mounts", flagAction("gopass", &cli.StringFlag{Name: "store"}))
+	assert.Equal(t, ":key:_gopass_complete_secret_keys", flagAction("gopass", &cli.StringFlag{Name: "key"}))
+	assert.Equal(t, "", flagAction("gopass", &cli.StringFlag{Name: "foo"}))
+	assert.Equal(t, "", flagAction("gopass", &cli.BoolFlag{Name: "store"}))
+}
@@ -15,11 +15,11 @@ _{{ $prog }} () {
 	  {{ .Name }}{{ range .Aliases }}|{{ . }}{{ end }})
 	      {{- if .Subcommands }}
 	      local -a subcommands
-	      subcommands=({{ range .Subcommands }}
-	      "{{ .Name }}:{{ .Usage }}"{{ end }}
+	      subcommands=({{ range .Subcommands }}{{ if not .Hidden }}
+	      "{{ .Name }}:{{ .Usage }}"{{ end }}{{ end }}
 	      )
 	      {{- end }}
-	      {{ if .Flags }}_arguments :{{ range .Flags }} "{{ . | formatFlag }}"{{ end }}{{ end }}
+	      {{ if .Flags }}_arguments :{{ range .Flags }} "{{ . | formatFlag }}{{ . | flagAction $prog }}"{{ end }}{{ end }}
 	      _describe -t commands "{{ $prog }} {{ .Name }}" subcommands
 	      {{ if or (eq .Name "insert") (eq .Name "generate")  (eq .Name "list") }}_{{ $prog }}_complete_folders{{ end }}
 	      {{ if or (eq .Name "copy") (eq .Name "move") (eq .Name "delete") (eq .Name "show") (eq .Name "edit") (eq .Name "insert") (eq .Name "generate") }}_{{ $prog }}_complete_passwords{{ end }}
@@ -35,7 +35,7 @@ _{{ $prog }} () {
 	  "{{ .Name }}:{{ .Usage }}"{{ end }}
 	)
 	_describe -t command '{{ $prog }}' subcommands
-	_arguments : {{ range .Flags }}"{{ . | formatFlag }}" {{ end }}
+	_arguments : {{ range .Flags }}"{{ . | formatFlag }}{{ . | flagAction $prog }}" {{ end }}
 	_{{ $prog }}_complete_passwords
     fi
 }
@@ -49,7 +49,29 @@ _{{ $prog }}_complete_passwords () {
     local IFS=$'\n'
     _arguments : \
 	"--clip[Copy the first line of the secret into the clipboard]"
-    _values 'passwords' $({{ $prog }} ls --flat)
+    _values 'passwords' $({{ $prog }} ls --flat --prefix "$PREFIX" 2> /dev/null)
+}
+
+_{{ $prog }}_complete_mounts () {
+    local -a mounts
+    mounts=("${(@f)$({{ $prog }} completion mounts 2> /dev/null)}")
+    _describe -t mounts "mounts" mounts
+}
+
+# completes the keys of the secret given anywhere on the command line, only
+# if completion.decrypt is enabled
+_{{ $prog }}_complete_secret_keys () {
+    local secret i
+    local -a keys
+    for (( i = 2; i <= $#words; i++ )); do
+	(( i == CURRENT )) && continue
+	[[ ${words[i]} == -* || ${words[i-1]} == --(store|key) ]] && continue
+	secret=${(Q)words[i]}
+	break
+    done
+    [[ -n $secret ]] || return 1
+    keys=("${(@f)$({{ $prog }} completion keys "$secret" 2> /dev/null)}")
+    _describe -t keys "keys" keys
 }
 
 _{{ $prog }}_complete_folders () {
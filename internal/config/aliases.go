@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AddAlias defines name as a short name for the path prefix target and saves
+// the config. Aliases may point to other aliases, but not to themselves.
+func (c *Config) AddAlias(name, target string) error {
+	target = strings.Trim(target, "/")
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid alias %q: must be a single path element", name)
+	}
+	if target == "" {
+		return fmt.Errorf("alias %q needs a target", name)
+	}
+
+	aliases := make(map[string]string, len(c.Aliases)+1)
+	for k, v := range c.Aliases {
+		aliases[k] = v
+	}
+	aliases[name] = target
+	if chain, found := aliasCycle(aliases, name); found {
+		return fmt.Errorf("alias %q would create a cycle: %s", name, strings.Join(chain, " -> "))
+	}
+
+	c.Aliases = aliases
+	return c.Save()
+}
+
+// RemoveAlias removes the alias name and saves the config
+func (c *Config) RemoveAlias(name string) error {
+	if _, found := c.Aliases[name]; !found {
+		return fmt.Errorf("no such alias %q", name)
+	}
+	delete(c.Aliases, name)
+	return c.Save()
+}
+
+// AliasNames returns the sorted names of all aliases
+func (c *Config) AliasNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandAlias replaces a leading alias in name with its target, e.g.
+// prod/db becomes customers/acme/production/db. Names without an alias are
+// returned unchanged.
+func (c *Config) ExpandAlias(name string) string {
+	if c == nil || len(c.Aliases) < 1 {
+		return name
+	}
+
+	seen := make(map[string]bool, len(c.Aliases))
+	for {
+		p := strings.SplitN(name, "/", 2)
+		target, found := c.Aliases[p[0]]
+		if !found || seen[p[0]] {
+			return name
+		}
+		seen[p[0]] = true
+
+		name = target
+		if len(p) > 1 {
+			name += "/" + p[1]
+		}
+	}
+}
+
+// aliasCycle returns the chain of aliases if expanding name leads back to
+// an alias that was already expanded
+func aliasCycle(aliases map[string]string, name string) ([]string, bool) {
+	chain := []string{name}
+	cur := aliases[name]
+	for {
+		first := strings.SplitN(cur, "/", 2)[0]
+		target, found := aliases[first]
+		if !found {
+			return nil, false
+		}
+		for _, a := range chain {
+			if a == first {
+				return append(chain, first), true
+			}
+		}
+		chain = append(chain, first)
+		cur = target + strings.TrimPrefix(cur, first)
+	}
+}
@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	t.Setenv("GOPASS_CONFIG", filepath.Join(t.TempDir(), ".gopass.yml"))
+
+	cfg := config.New()
+	require.NoError(t, cfg.AddAlias("prod", "customers/acme/production/"))
+	require.NoError(t, cfg.AddAlias("db", "prod/databases"))
+	assert.Equal(t, []string{"db", "prod"}, cfg.AliasNames())
+
+	for in, want := range map[string]string{
+		"":                 "",
+		"prod":             "customers/acme/production",
+		"prod/":            "customers/acme/production/",
+		"prod/web":         "customers/acme/production/web",
+		"db/postgres":      "customers/acme/production/databases/postgres",
+		"production/web":   "production/web",
+		"other/prod/web":   "other/prod/web",
+		"customers/acme/x": "customers/acme/x",
+	} {
+		assert.Equal(t, want, cfg.ExpandAlias(in), in)
+	}
+
+	// aliases are persisted in the config
+	assert.Equal(t, cfg.Aliases, config.Load().Aliases)
+
+	assert.Error(t, cfg.AddAlias("", "foo"))
+	assert.Error(t, cfg.AddAlias("a/b", "foo"))
+	assert.Error(t, cfg.AddAlias("foo", "/"))
+
+	err := cfg.AddAlias("prod", "db/prod")
+	require.Error(t, err)
+	assert.Equal(t, `alias "prod" would create a cycle: prod -> db -> prod`, err.Error())
+	assert.Error(t, cfg.AddAlias("self", "self/sub"))
+	assert.Equal(t, "customers/acme/production", cfg.Aliases["prod"])
+
+	require.NoError(t, cfg.RemoveAlias("db"))
+	assert.Equal(t, "db/postgres", cfg.ExpandAlias("db/postgres"))
+	assert.Error(t, cfg.RemoveAlias("db"))
+
+	var nilCfg *config.Config
+	assert.Equal(t, "prod/web", nilCfg.ExpandAlias("prod/web"))
+}
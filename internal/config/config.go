@@ -6,8 +6,56 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/gopasspw/gopass/pkg/fsutil"
 )
 
+// DefaultExpiryWarn is the default number of days before a recipient key
+// expires that we start warning about it
+const DefaultExpiryWarn = 30
+
+// DefaultExecTimeout is the default number of seconds network operations of
+// external commands, e.g. git push, may take
+const DefaultExecTimeout = 60
+
+// DefaultDecryptCache is the default number of decrypted secrets kept in
+// memory while gopass runs
+const DefaultDecryptCache = 100
+
+// DefaultBinaryLimit is the default maximum size of binary files in bytes
+const DefaultBinaryLimit = 1 << 20
+
+// DefaultOldPasswords is the default number of replaced passwords gopass
+// generate keeps in a secret
+const DefaultOldPasswords = 3
+
+// DefaultLockAfter is the default number of seconds without activity after
+// which long running modes, e.g. the REPL, lock the stores
+const DefaultLockAfter = 900
+
+// DefaultLockTimeout is the default number of seconds to wait for a store
+// locked by another process
+const DefaultLockTimeout = 10
+
+// DefaultGitCredentialPrefix is the default folder holding the secrets of
+// the git credential helper
+const DefaultGitCredentialPrefix = "git"
+
+// DefaultRecordingVars are the environment variables indicating that the
+// terminal is recorded, e.g. by asciinema or script. The logging of terminal
+// multiplexers, e.g. tmux pipe-pane or screen -L, doesn't set any.
+const DefaultRecordingVars = "ASCIINEMA_REC,SCRIPT"
+
+// DefaultPullStrategy is the default way to integrate remote changes
+const DefaultPullStrategy = "merge"
+
+// PullStrategies are the supported ways to integrate remote changes
+var PullStrategies = []string{"merge", "rebase", "ff-only"}
+
+// GenerateModes are the password generators gopass generate can use by
+// default
+var GenerateModes = []string{"cryptic", "memorable", "xkcd", "pronounceable", "external"}
+
 var (
 	// ErrConfigNotFound is returned on load if the config was not found
 	ErrConfigNotFound = fmt.Errorf("config not found")
@@ -17,18 +65,70 @@ var (
 
 // Config is the current config struct
 type Config struct {
-	AutoClip      bool              `yaml:"autoclip"`      // decide whether passwords are automatically copied or not
-	AutoImport    bool              `yaml:"autoimport"`    // import missing public keys w/o asking
-	ClipTimeout   int               `yaml:"cliptimeout"`   // clear clipboard after seconds
-	ExportKeys    bool              `yaml:"exportkeys"`    // automatically export public keys of all recipients
-	NoPager       bool              `yaml:"nopager"`       // do not invoke a pager to display long lists
-	Notifications bool              `yaml:"notifications"` // enable desktop notifications
-	Parsing       bool              `yaml:"parsing"`       // allows to switch off all output parsing
-	Path          string            `yaml:"path"`
-	SafeContent   bool              `yaml:"safecontent"` // avoid showing passwords in terminal
-	Mounts        map[string]string `yaml:"mounts"`
-
-	ConfigPath string `yaml:"-"`
+	AgeAgent              bool              `yaml:"ageagent"`            // decrypt age secrets through a running gopass agent
+	AutoClip              bool              `yaml:"autoclip"`            // decide whether passwords are automatically copied or not
+	AutoImport            bool              `yaml:"autoimport"`          // import missing public keys w/o asking
+	AutoOffline           bool              `yaml:"autooffline"`         // probe the git remotes and work offline if they can't be reached
+	AutoPush              bool              `yaml:"autopush"`            // push changes to the git remote right away
+	AutoSync              bool              `yaml:"autosync"`            // pull and push after each change, overrides autopush
+	AutoSyncInterval      int               `yaml:"autosyncinterval"`    // minimum seconds between two implicit syncs, 0 syncs after every change
+	AutoType              bool              `yaml:"autotype"`            // type the password instead of copying it with show -c
+	BinaryLimit           int               `yaml:"binarylimit"`         // maximum size of binary files in bytes, 0 disables the limit
+	CheckRecipientHash    bool              `yaml:"checkrecipienthash"`  // confirm recipient changes made outside of gopass before encrypting
+	Clipboard             string            `yaml:"clipboard"`           // clipboard helper, empty or auto for automatic detection
+	ClipTimeout           int               `yaml:"cliptimeout"`         // clear clipboard after seconds
+	PickerCmd             string            `yaml:"cmd"`                 // picker used by gopass pick, empty to detect rofi, wofi or dmenu
+	CommitMsgHashNames    bool              `yaml:"commitmsghashnames"`  // replace secret names in commit messages and trailers with hashes
+	CommitMsgTemplate     string            `yaml:"commitmsgtemplate"`   // text/template for the subject of store commits, empty for the built-in messages
+	CompletionDecrypt     bool              `yaml:"decrypt"`             // decrypt the typed secret to complete --key during shell completion
+	DecryptCache          int               `yaml:"decryptcache"`        // number of decrypted secrets kept in memory while gopass runs, 0 disables the cache
+	ExecTimeout           int               `yaml:"exectimeout"`         // seconds git network operations may take, local git and gpg commands get a quarter, 0 disables the timeouts
+	ExpiryWarn            int               `yaml:"expirywarn"`          // warn about expiring recipient keys this many days in advance
+	ExportKeys            bool              `yaml:"exportkeys"`          // automatically export public keys of all recipients
+	FormatPasswords       bool              `yaml:"formatpasswords"`     // allow show --format json|yaml to print the password without --unsafe
+	GitCredentialPrefix   string            `yaml:"gitcredentialprefix"` // folder holding the secrets of gopass git-credential
+	Hooks                 bool              `yaml:"hooks"`               // run the executables in the hooks folder of the config dir around changes
+	KeepBackup            bool              `yaml:"keepbackup"`          // keep the previous version of changed secrets until they are committed
+	KeyCache              bool              `yaml:"keycache"`            // cache gpg key listings on disk
+	Keyserver             string            `yaml:"keyserver"`           // keyserver used to fetch missing public keys
+	LockAfter             int               `yaml:"lockafter"`           // seconds without activity after which the REPL, serve, jsonapi listen and the agent lock, 0 disables it
+	LockTimeout           int               `yaml:"locktimeout"`         // seconds to wait for a store locked by another gopass process
+	Metadata              bool              `yaml:"metadata"`            // show when a secret was last changed and by whom
+	GenerateMode          string            `yaml:"mode"`                // default password generator of generate, empty for cryptic
+	NoAmbiguous           bool              `yaml:"noambiguous"`         // do not use easily confused characters in generated passwords
+	NoDigits              bool              `yaml:"nodigits"`            // do not use digits in generated passwords
+	NoPager               bool              `yaml:"nopager"`             // do not invoke a pager to display long lists
+	Notifications         bool              `yaml:"notifications"`       // enable desktop notifications
+	NoUppercase           bool              `yaml:"nouppercase"`         // do not use uppercase letters in generated passwords
+	OldPasswords          int               `yaml:"oldpasswords"`        // number of replaced passwords kept in the secret by generate, 0 disables it
+	Ownertrust            bool              `yaml:"ownertrust"`          // keep a snapshot of the recipients ownertrust in the store
+	Parsing               bool              `yaml:"parsing"`             // allows to switch off all output parsing
+	Path                  string            `yaml:"path"`
+	PullStrategy          string            `yaml:"pullstrategy"`     // how to integrate remote changes: merge, rebase or ff-only
+	QRTimeout             int               `yaml:"qrtimeout"`        // clear QR codes and characters shown with --chars from the terminal after seconds
+	RecordingCheck        bool              `yaml:"recordingcheck"`   // refuse to print passwords to a terminal that seems to be recorded
+	RecordingVars         string            `yaml:"recordingvars"`    // comma separated environment variables set while the terminal is recorded, empty for the defaults
+	SafeContent           bool              `yaml:"safecontent"`      // avoid showing passwords in terminal
+	ShowAutoClip          bool              `yaml:"showautoclip"`     // copy the password with show instead of printing it to a terminal
+	SignCommits           bool              `yaml:"signcommits"`      // sign all git commits with the users own recipient key
+	Symbols               string            `yaml:"symbols"`          // symbols used in generated passwords, empty for none
+	UnsafeKeys            string            `yaml:"unsafekeys"`       // comma separated keys masked by safecontent
+	UpdateStartupTTY      bool              `yaml:"updatestartuptty"` // point the gpg-agent to the current terminal if no graphical pinentry can be shown
+	WordlistFile          string            `yaml:"wordlistfile"`     // custom wordlist for xkcd style passphrases
+	Workers               int               `yaml:"workers"`          // number of concurrent workers for re-encryption, 0 uses the default
+	Mounts                map[string]string `yaml:"mounts"`
+	GnupgHome             map[string]string `yaml:"gnupghome,omitempty"`             // per mount GNUPGHOME
+	MountSignCommits      map[string]bool   `yaml:"mountsigncommits,omitempty"`      // per mount override of signcommits
+	MountAutoPush         map[string]bool   `yaml:"mountautopush,omitempty"`         // per mount override of autopush
+	MountAutoSyncInterval map[string]int    `yaml:"mountautosyncinterval,omitempty"` // per mount override of autosyncinterval
+	MountPullStrategy     map[string]string `yaml:"mountpullstrategy,omitempty"`     // per mount override of pullstrategy
+	MountNoSync           map[string]bool   `yaml:"mountnosync,omitempty"`           // mounts skipped by gopass sync
+	MountReadOnly         map[string]bool   `yaml:"mountreadonly,omitempty"`         // mounts that must not be changed
+	Aliases               map[string]string `yaml:"aliases,omitempty"`               // personal short names for long path prefixes
+	Credentials           map[string]string `yaml:"credentials,omitempty"`           // systemd credentials served by gopass cred serve and their secrets
+
+	ConfigPath string  `yaml:"-"`
+	Layers     *Layers `yaml:"-"`
 
 	// Catches all undefined files and must be empty after parsing
 	XXX map[string]interface{} `yaml:",inline"`
@@ -37,14 +137,29 @@ type Config struct {
 // New creates a new config with sane default values
 func New() *Config {
 	return &Config{
-		AutoImport:    true,
-		ClipTimeout:   45,
-		ExportKeys:    true,
-		Mounts:        make(map[string]string),
-		Notifications: true,
-		Parsing:       true,
-		Path:          PwStoreDir(""),
-		ConfigPath:    configLocation(),
+		AutoImport:         true,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        45,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		ExportKeys:         true,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		Mounts:             make(map[string]string),
+		Notifications:      true,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               PwStoreDir(""),
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		ConfigPath:         configLocation(),
 	}
 }
 
@@ -59,17 +174,41 @@ func (c *Config) Config() *Config {
 	return c
 }
 
-// SetConfigValue will try to set the given key to the value in the config struct
+// SetConfigValue will try to set the given key to the value in the user
+// config and save it. The key can be given with or without its section, e.g.
+// git.autopush or autopush. If an environment variable overrides the option
+// the new value is only used once the variable is unset.
 func (c *Config) SetConfigValue(key, value string) error {
+	key = OptionName(key)
+	if err := checkValue(key, value); err != nil {
+		return err
+	}
+	if c.Layers == nil {
+		c.Layers = newLayers(c)
+	}
+
+	if c.fromEnv(key) {
+		tmp := New()
+		if err := tmp.setConfigValue(key, value); err != nil {
+			return err
+		}
+		c.Layers.user[key] = tmp.ConfigMap()[key]
+		return c.Save()
+	}
+
 	if err := c.setConfigValue(key, value); err != nil {
 		return err
 	}
+	c.Layers.user[key] = c.ConfigMap()[key]
+	c.Layers.origin[key] = Origin{Layer: LayerUser, Source: configLocation()}
 	return c.Save()
 }
 
 // setConfigValue will try to set the given key to the value in the config struct
 func (c *Config) setConfigValue(key, value string) error {
-	value = strings.ToLower(value)
+	if !preserveCase[key] {
+		value = strings.ToLower(value)
+	}
 	o := reflect.ValueOf(c).Elem()
 	for i := 0; i < o.NumField(); i++ {
 		jsonArg := o.Type().Field(i).Tag.Get("yaml")
@@ -85,15 +224,12 @@ func (c *Config) setConfigValue(key, value string) error {
 			f.SetString(value)
 			return nil
 		case reflect.Bool:
-			if value == "true" || value == "on" {
-				f.SetBool(true)
-				return nil
-			} else if value == "false" || value == "off" {
-				f.SetBool(false)
-				return nil
-			} else {
-				return fmt.Errorf("not a bool: %s", value)
+			bv, err := parseBool(value)
+			if err != nil {
+				return err
 			}
+			f.SetBool(bv)
+			return nil
 		case reflect.Int:
 			iv, err := strconv.Atoi(value)
 			if err != nil {
@@ -108,6 +244,283 @@ func (c *Config) setConfigValue(key, value string) error {
 	return fmt.Errorf("unknown config option %q", key)
 }
 
+// SetMountConfigValue will try to set the given per mount key to the value
+// and save the config. An empty value removes the setting.
+func (c *Config) SetMountConfigValue(mount, key, value string) error {
+	if _, found := c.Mounts[mount]; !found {
+		return fmt.Errorf("no such mount point %q", mount)
+	}
+	switch OptionName(key) {
+	case "gnupghome":
+		if value == "" {
+			delete(c.GnupgHome, mount)
+			break
+		}
+		if c.GnupgHome == nil {
+			c.GnupgHome = make(map[string]string, 1)
+		}
+		c.GnupgHome[mount] = fsutil.CleanPath(value)
+	case "signcommits":
+		if value == "" {
+			delete(c.MountSignCommits, mount)
+			break
+		}
+		bv, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		if c.MountSignCommits == nil {
+			c.MountSignCommits = make(map[string]bool, 1)
+		}
+		c.MountSignCommits[mount] = bv
+	case "autopush":
+		if value == "" {
+			delete(c.MountAutoPush, mount)
+			break
+		}
+		bv, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		if c.MountAutoPush == nil {
+			c.MountAutoPush = make(map[string]bool, 1)
+		}
+		c.MountAutoPush[mount] = bv
+	case "autosyncinterval":
+		if value == "" {
+			delete(c.MountAutoSyncInterval, mount)
+			break
+		}
+		iv, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("failed to convert %q to integer: %w", value, err)
+		}
+		if c.MountAutoSyncInterval == nil {
+			c.MountAutoSyncInterval = make(map[string]int, 1)
+		}
+		c.MountAutoSyncInterval[mount] = iv
+	case "pullstrategy":
+		if value == "" {
+			delete(c.MountPullStrategy, mount)
+			break
+		}
+		if err := checkPullStrategy(value); err != nil {
+			return err
+		}
+		if c.MountPullStrategy == nil {
+			c.MountPullStrategy = make(map[string]string, 1)
+		}
+		c.MountPullStrategy[mount] = strings.ToLower(value)
+	case "nosync":
+		if value == "" {
+			delete(c.MountNoSync, mount)
+			break
+		}
+		bv, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		if c.MountNoSync == nil {
+			c.MountNoSync = make(map[string]bool, 1)
+		}
+		c.MountNoSync[mount] = bv
+	case "readonly":
+		if value == "" {
+			delete(c.MountReadOnly, mount)
+			break
+		}
+		bv, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		if c.MountReadOnly == nil {
+			c.MountReadOnly = make(map[string]bool, 1)
+		}
+		c.MountReadOnly[mount] = bv
+	default:
+		return fmt.Errorf("unknown mount config option %q", key)
+	}
+	return c.Save()
+}
+
+// MountConfigMap returns a map of the per mount config values for the given
+// mount
+func (c *Config) MountConfigMap(mount string) map[string]string {
+	m := map[string]string{
+		"autopush":         "",
+		"autosyncinterval": "",
+		"gnupghome":        c.GnupgHome[mount],
+		"nosync":           strconv.FormatBool(c.MountNoSync[mount]),
+		"pullstrategy":     c.MountPullStrategy[mount],
+		"readonly":         strconv.FormatBool(c.MountReadOnly[mount]),
+		"signcommits":      "",
+	}
+	if bv, found := c.MountAutoPush[mount]; found {
+		m["autopush"] = strconv.FormatBool(bv)
+	}
+	if iv, found := c.MountAutoSyncInterval[mount]; found {
+		m["autosyncinterval"] = strconv.Itoa(iv)
+	}
+	if bv, found := c.MountSignCommits[mount]; found {
+		m["signcommits"] = strconv.FormatBool(bv)
+	}
+	return m
+}
+
+// mountValue returns the per mount value of the given option, if it is set
+func (c *Config) mountValue(mount, name string) (string, bool) {
+	if _, found := c.Mounts[mount]; !found {
+		return "", false
+	}
+	switch name {
+	case "gnupghome":
+		v, found := c.GnupgHome[mount]
+		return v, found
+	case "nosync":
+		bv, found := c.MountNoSync[mount]
+		return strconv.FormatBool(bv), found
+	case "readonly":
+		bv, found := c.MountReadOnly[mount]
+		return strconv.FormatBool(bv), found
+	}
+	if v := c.MountConfigMap(mount)[name]; v != "" {
+		return v, true
+	}
+	return "", false
+}
+
+// IsSignCommits returns true if commits to the given mount must be signed.
+// The per mount setting takes precedence over signcommits, unless
+// GOPASS_GIT_SIGNCOMMITS is set.
+func (c *Config) IsSignCommits(mount string) bool {
+	if bv, found := c.MountSignCommits[mount]; found && !c.fromEnv("signcommits") {
+		return bv
+	}
+	return c.SignCommits
+}
+
+// IsAutoPush returns true if changes to the given mount are pushed right
+// away. The per mount setting takes precedence over autopush, unless
+// GOPASS_GIT_AUTOPUSH is set.
+func (c *Config) IsAutoPush(mount string) bool {
+	if bv, found := c.MountAutoPush[mount]; found && !c.fromEnv("autopush") {
+		return bv
+	}
+	return c.AutoPush
+}
+
+// GetAutoSyncInterval returns the minimum number of seconds between two
+// implicit syncs of the given mount. The per mount setting takes precedence
+// over autosyncinterval, unless GOPASS_GIT_AUTOSYNCINTERVAL is set.
+func (c *Config) GetAutoSyncInterval(mount string) int {
+	if iv, found := c.MountAutoSyncInterval[mount]; found && !c.fromEnv("autosyncinterval") {
+		return iv
+	}
+	return c.AutoSyncInterval
+}
+
+// GetPullStrategy returns how remote changes are integrated into the given
+// mount. The per mount setting takes precedence over pullstrategy, unless
+// GOPASS_GIT_PULLSTRATEGY is set.
+func (c *Config) GetPullStrategy(mount string) string {
+	if sv := c.MountPullStrategy[mount]; sv != "" && !c.fromEnv("pullstrategy") {
+		return sv
+	}
+	if c.PullStrategy == "" {
+		return DefaultPullStrategy
+	}
+	return c.PullStrategy
+}
+
+// GetGitCredentialPrefix returns the folder holding the secrets of the git
+// credential helper
+func (c *Config) GetGitCredentialPrefix() string {
+	if c.GitCredentialPrefix == "" {
+		return DefaultGitCredentialPrefix
+	}
+	return c.GitCredentialPrefix
+}
+
+// GetUnsafeKeys returns the keys masked by safecontent in every secret
+func (c *Config) GetUnsafeKeys() []string {
+	keys := make([]string, 0, 4)
+	for _, k := range strings.Split(c.UnsafeKeys, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// GetRecordingVars returns the environment variables indicating that the
+// terminal is recorded
+func (c *Config) GetRecordingVars() []string {
+	sv := c.RecordingVars
+	if strings.TrimSpace(sv) == "" {
+		sv = DefaultRecordingVars
+	}
+	vars := make([]string, 0, 4)
+	for _, v := range strings.Split(sv, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// IsNoSync returns true if gopass sync should skip the given mount
+func (c *Config) IsNoSync(mount string) bool {
+	return c.MountNoSync[mount]
+}
+
+// IsReadOnly returns true if the given mount must not be changed
+func (c *Config) IsReadOnly(mount string) bool {
+	return c.MountReadOnly[mount]
+}
+
+// checkValue validates the values of options with a fixed set of values
+func checkValue(key, value string) error {
+	switch key {
+	case "pullstrategy":
+		return checkPullStrategy(value)
+	case "mode":
+		return checkGenerateMode(value)
+	default:
+		return nil
+	}
+}
+
+func checkGenerateMode(value string) error {
+	if value == "" {
+		return nil
+	}
+	for _, m := range GenerateModes {
+		if strings.ToLower(value) == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown generate mode %q, must be one of %s", value, strings.Join(GenerateModes, ", "))
+}
+
+func checkPullStrategy(value string) error {
+	for _, s := range PullStrategies {
+		if strings.ToLower(value) == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pull strategy %q, must be one of %s", value, strings.Join(PullStrategies, ", "))
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "on":
+		return true, nil
+	case "false", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("not a bool: %s", value)
+}
+
 func (c *Config) String() string {
 	return fmt.Sprintf("%#v", c)
 }
@@ -21,8 +21,8 @@ func TestNewConfig(t *testing.T) {
 
 	cfg := config.New()
 	cs := cfg.String()
-	assert.Contains(t, cs, `&config.Config{AutoClip:false, AutoImport:true, ClipTimeout:45, ExportKeys:true, NoPager:false, Notifications:true,`)
-	assert.Contains(t, cs, `SafeContent:false, Mounts:map[string]string{},`)
+	assert.Contains(t, cs, `&config.Config{AgeAgent:false, AutoClip:false, AutoImport:true, AutoOffline:false, AutoPush:true, AutoSync:true, AutoSyncInterval:0, AutoType:false, BinaryLimit:1048576, CheckRecipientHash:true, Clipboard:"", ClipTimeout:45, PickerCmd:"", CommitMsgHashNames:false, CommitMsgTemplate:"", CompletionDecrypt:false, DecryptCache:100, ExecTimeout:60, ExpiryWarn:30, ExportKeys:true, FormatPasswords:false, GitCredentialPrefix:"", Hooks:false, KeepBackup:false, KeyCache:true, Keyserver:"", LockAfter:900, LockTimeout:10, Metadata:false, GenerateMode:"", NoAmbiguous:false, NoDigits:false, NoPager:false, Notifications:true, NoUppercase:false,`)
+	assert.Contains(t, cs, `PullStrategy:"merge", QRTimeout:45, RecordingCheck:true, RecordingVars:"", SafeContent:false, ShowAutoClip:false, SignCommits:false, Symbols:"", UnsafeKeys:"", UpdateStartupTTY:true, WordlistFile:"", Workers:0, Mounts:map[string]string{},`)
 
 	cfg = &config.Config{
 		Mounts: make(map[string]string, 2),
@@ -30,8 +30,8 @@ func TestNewConfig(t *testing.T) {
 	cfg.Mounts["foo"] = ""
 	cfg.Mounts["bar"] = ""
 	cs = cfg.String()
-	assert.Contains(t, cs, `&config.Config{AutoClip:false, AutoImport:false, ClipTimeout:0, ExportKeys:false, NoPager:false, Notifications:false,`)
-	assert.Contains(t, cs, `SafeContent:false, Mounts:map[string]string{"bar":"", "foo":""},`)
+	assert.Contains(t, cs, `&config.Config{AgeAgent:false, AutoClip:false, AutoImport:false, AutoOffline:false, AutoPush:false, AutoSync:false, AutoSyncInterval:0, AutoType:false, BinaryLimit:0, CheckRecipientHash:false, Clipboard:"", ClipTimeout:0, PickerCmd:"", CommitMsgHashNames:false, CommitMsgTemplate:"", CompletionDecrypt:false, DecryptCache:0, ExecTimeout:0, ExpiryWarn:0, ExportKeys:false, FormatPasswords:false, GitCredentialPrefix:"", Hooks:false, KeepBackup:false, KeyCache:false, Keyserver:"", LockAfter:0, LockTimeout:0, Metadata:false, GenerateMode:"", NoAmbiguous:false, NoDigits:false, NoPager:false, Notifications:false, NoUppercase:false,`)
+	assert.Contains(t, cs, `PullStrategy:"", QRTimeout:0, RecordingCheck:false, RecordingVars:"", SafeContent:false, ShowAutoClip:false, SignCommits:false, Symbols:"", UnsafeKeys:"", UpdateStartupTTY:false, WordlistFile:"", Workers:0, Mounts:map[string]string{"bar":"", "foo":""},`)
 }
 
 func TestSetConfigValue(t *testing.T) {
@@ -42,4 +42,73 @@ func TestSetConfigValue(t *testing.T) {
 	assert.NoError(t, cfg.SetConfigValue("cliptimeout", "900"))
 	assert.NoError(t, cfg.SetConfigValue("path", "/tmp"))
 	assert.Error(t, cfg.SetConfigValue("autoclip", "yo"))
+
+	assert.Equal(t, []string{}, cfg.GetUnsafeKeys())
+	assert.NoError(t, cfg.SetConfigValue("unsafekeys", "Recovery, pin,,"))
+	assert.Equal(t, []string{"recovery", "pin"}, cfg.GetUnsafeKeys())
+}
+
+func TestSetMountConfigValue(t *testing.T) {
+	assert.NoError(t, os.Setenv("GOPASS_CONFIG", filepath.Join(os.TempDir(), ".gopass.yml")))
+
+	cfg := config.New()
+	cfg.Mounts["work"] = "/tmp/work"
+	assert.NoError(t, cfg.SetMountConfigValue("work", "gnupghome", "/tmp/Work-GnuPG"))
+	assert.Equal(t, "/tmp/Work-GnuPG", cfg.GnupgHome["work"])
+	assert.Equal(t, map[string]string{"autopush": "", "autosyncinterval": "", "gnupghome": "/tmp/Work-GnuPG", "nosync": "false", "pullstrategy": "", "readonly": "false", "signcommits": ""}, cfg.MountConfigMap("work"))
+
+	assert.NoError(t, cfg.SetMountConfigValue("work", "gnupghome", ""))
+	assert.Equal(t, "", cfg.MountConfigMap("work")["gnupghome"])
+
+	assert.False(t, cfg.IsSignCommits("work"))
+	cfg.SignCommits = true
+	assert.True(t, cfg.IsSignCommits("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "signcommits", "off"))
+	assert.Equal(t, "false", cfg.MountConfigMap("work")["signcommits"])
+	assert.False(t, cfg.IsSignCommits("work"))
+	assert.True(t, cfg.IsSignCommits(""))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "signcommits", ""))
+	assert.True(t, cfg.IsSignCommits("work"))
+	assert.Error(t, cfg.SetMountConfigValue("work", "signcommits", "maybe"))
+
+	assert.True(t, cfg.IsAutoPush("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "autopush", "false"))
+	assert.False(t, cfg.IsAutoPush("work"))
+	assert.True(t, cfg.IsAutoPush(""))
+
+	cfg.AutoSyncInterval = 60
+	assert.Equal(t, 60, cfg.GetAutoSyncInterval("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "autosyncinterval", "3600"))
+	assert.Equal(t, 3600, cfg.GetAutoSyncInterval("work"))
+	assert.Equal(t, "3600", cfg.MountConfigMap("work")["autosyncinterval"])
+	assert.Error(t, cfg.SetMountConfigValue("work", "autosyncinterval", "soon"))
+
+	assert.Equal(t, "merge", cfg.GetPullStrategy("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "pullstrategy", "Rebase"))
+	assert.Equal(t, "rebase", cfg.GetPullStrategy("work"))
+	assert.Equal(t, "merge", cfg.GetPullStrategy(""))
+	assert.Error(t, cfg.SetMountConfigValue("work", "pullstrategy", "octopus"))
+	assert.Error(t, cfg.SetConfigValue("pullstrategy", "octopus"))
+	assert.NoError(t, cfg.SetConfigValue("pullstrategy", "ff-only"))
+	assert.Error(t, cfg.SetConfigValue("generate.mode", "diceware"))
+	assert.NoError(t, cfg.SetConfigValue("generate.mode", "Pronounceable"))
+	assert.Equal(t, "pronounceable", cfg.GenerateMode)
+	assert.Equal(t, "ff-only", cfg.GetPullStrategy(""))
+
+	assert.False(t, cfg.IsNoSync("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "nosync", "true"))
+	assert.True(t, cfg.IsNoSync("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "nosync", ""))
+	assert.False(t, cfg.IsNoSync("work"))
+
+	assert.False(t, cfg.IsReadOnly("work"))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "readonly", "true"))
+	assert.True(t, cfg.IsReadOnly("work"))
+	assert.Equal(t, "true", cfg.MountConfigMap("work")["readonly"])
+	assert.False(t, cfg.IsReadOnly(""))
+	assert.NoError(t, cfg.SetMountConfigValue("work", "readonly", "false"))
+	assert.False(t, cfg.IsReadOnly("work"))
+
+	assert.Error(t, cfg.SetMountConfigValue("work", "autoclip", "true"))
+	assert.Error(t, cfg.SetMountConfigValue("personal", "gnupghome", "/tmp"))
 }
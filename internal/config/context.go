@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 )
@@ -15,6 +16,15 @@ func (c *Config) WithContext(ctx context.Context) context.Context {
 	if !ctxutil.HasExportKeys(ctx) {
 		ctx = ctxutil.WithExportKeys(ctx, c.ExportKeys)
 	}
+	if !ctxutil.HasNoKeyCache(ctx) {
+		ctx = ctxutil.WithNoKeyCache(ctx, !c.KeyCache)
+	}
+	if !ctxutil.HasAutoSync(ctx) {
+		ctx = ctxutil.WithAutoSync(ctx, c.AutoSync)
+	}
+	if !ctxutil.HasAutoOffline(ctx) {
+		ctx = ctxutil.WithAutoOffline(ctx, c.AutoOffline)
+	}
 	if !ctxutil.HasNoPager(ctx) {
 		ctx = ctxutil.WithNoPager(ctx, c.NoPager)
 	}
@@ -27,5 +37,26 @@ func (c *Config) WithContext(ctx context.Context) context.Context {
 	if !ctxutil.HasShowParsing(ctx) {
 		ctx = ctxutil.WithShowParsing(ctx, c.Parsing)
 	}
+	if !ctxutil.HasCheckRecipientHash(ctx) {
+		ctx = ctxutil.WithCheckRecipientHash(ctx, c.CheckRecipientHash)
+	}
+	if !ctxutil.HasKeepBackup(ctx) {
+		ctx = ctxutil.WithKeepBackup(ctx, c.KeepBackup)
+	}
+	if !ctxutil.HasHooks(ctx) {
+		ctx = ctxutil.WithHooks(ctx, c.Hooks)
+	}
+	if !ctxutil.HasAgeAgent(ctx) {
+		ctx = ctxutil.WithAgeAgent(ctx, c.AgeAgent)
+	}
+	if !ctxutil.HasLockTimeout(ctx) {
+		ctx = ctxutil.WithLockTimeout(ctx, time.Duration(c.LockTimeout)*time.Second)
+	}
+	if !ctxutil.HasExecTimeout(ctx) {
+		ctx = ctxutil.WithExecTimeout(ctx, time.Duration(c.ExecTimeout)*time.Second)
+	}
+	if c.Workers > 0 {
+		ctx = ctxutil.WithWorkers(ctx, c.Workers)
+	}
 	return ctx
 }
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AddCredential maps the systemd credential name to a secret and saves the
+// config. The name is either the credential id of LoadCredential= or
+// <unit>/<id> to serve it to a single unit only.
+func (c *Config) AddCredential(name, secret string) error {
+	secret = strings.Trim(secret, "/")
+	if name == "" || strings.Count(name, "/") > 1 || strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") {
+		return fmt.Errorf("invalid credential name %q: must be <id> or <unit>/<id>", name)
+	}
+	if strings.ContainsAny(name, " \t\n:") {
+		return fmt.Errorf("invalid credential name %q: must not contain whitespace or colons", name)
+	}
+	if secret == "" {
+		return fmt.Errorf("credential %q needs a secret", name)
+	}
+
+	if c.Credentials == nil {
+		c.Credentials = make(map[string]string, 1)
+	}
+	c.Credentials[name] = secret
+	return c.Save()
+}
+
+// RemoveCredential removes the credential name and saves the config
+func (c *Config) RemoveCredential(name string) error {
+	if _, found := c.Credentials[name]; !found {
+		return fmt.Errorf("no such credential %q", name)
+	}
+	delete(c.Credentials, name)
+	return c.Save()
+}
+
+// CredentialNames returns the sorted names of all credentials
+func (c *Config) CredentialNames() []string {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Credentials))
+	for name := range c.Credentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CredentialSecret returns the secret of the credential id requested by the
+// given unit. A credential mapped for this unit wins over one for all units.
+func (c *Config) CredentialSecret(unit, id string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	if secret, found := c.Credentials[unit+"/"+id]; found && unit != "" {
+		return secret, true
+	}
+	secret, found := c.Credentials[id]
+	return secret, found
+}
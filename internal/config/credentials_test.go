@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentials(t *testing.T) {
+	t.Setenv("GOPASS_CONFIG", filepath.Join(t.TempDir(), ".gopass.yml"))
+
+	cfg := config.New()
+	require.NoError(t, cfg.AddCredential("db-password", "/infra/db/"))
+	require.NoError(t, cfg.AddCredential("web.service/db-password", "infra/web/db"))
+	assert.Equal(t, []string{"db-password", "web.service/db-password"}, cfg.CredentialNames())
+
+	// credentials are persisted in the config
+	assert.Equal(t, cfg.Credentials, config.Load().Credentials)
+
+	for _, tc := range []struct {
+		unit   string
+		id     string
+		secret string
+	}{
+		{unit: "app.service", id: "db-password", secret: "infra/db"},
+		{unit: "web.service", id: "db-password", secret: "infra/web/db"},
+		{id: "db-password", secret: "infra/db"},
+		{unit: "web.service", id: "api-token"},
+	} {
+		secret, found := cfg.CredentialSecret(tc.unit, tc.id)
+		assert.Equal(t, tc.secret != "", found, tc.unit+"/"+tc.id)
+		assert.Equal(t, tc.secret, secret, tc.unit+"/"+tc.id)
+	}
+
+	assert.Error(t, cfg.AddCredential("", "foo"))
+	assert.Error(t, cfg.AddCredential("a/b/c", "foo"))
+	assert.Error(t, cfg.AddCredential("/foo", "foo"))
+	assert.Error(t, cfg.AddCredential("foo bar", "foo"))
+	assert.Error(t, cfg.AddCredential("foo", "/"))
+
+	require.NoError(t, cfg.RemoveCredential("db-password"))
+	assert.Error(t, cfg.RemoveCredential("db-password"))
+	assert.Equal(t, []string{"web.service/db-password"}, config.Load().CredentialNames())
+}
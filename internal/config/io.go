@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
@@ -56,6 +57,7 @@ func loadConfig(l string, relaxed bool) *Config {
 func loadDefault() *Config {
 	cfg := New()
 	cfg.Path = PwStoreDir("")
+	cfg.applyLayers(nil)
 	debug.Log("Loaded default config: %+v", cfg)
 	return cfg
 }
@@ -72,7 +74,7 @@ func load(cf string, relaxed bool) (*Config, error) {
 		return nil, ErrConfigNotFound
 	}
 
-	cfg, err := decode(buf, relaxed)
+	cfg, legacy, err := decodeVersion(buf, relaxed)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading config from %s: %s\n", cf, err)
 		return nil, ErrConfigNotParsed
@@ -80,21 +82,67 @@ func load(cf string, relaxed bool) (*Config, error) {
 	if cfg.Mounts == nil {
 		cfg.Mounts = make(map[string]string)
 	}
+	// like gopass config does when setting it
+	cfg.GenerateMode = strings.ToLower(cfg.GenerateMode)
 	cfg.ConfigPath = cf
+
+	if !legacy {
+		cfg.applyLayers(topLevelKeys(buf))
+		return cfg, nil
+	}
+
+	// every option of a legacy config is kept as if it was set explicitly
+	keys := make([]string, 0, len(cfg.ConfigMap()))
+	for k := range cfg.ConfigMap() {
+		keys = append(keys, k)
+	}
+	cfg.applyLayers(keys)
+	if err := migrate(cfg, buf); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to migrate the config at %s: %s\n", cf, err)
+	}
 	return cfg, nil
 }
 
+// migrate writes a config loaded from a legacy format in the current format.
+// The legacy config is kept as a backup if it is replaced.
+func migrate(cfg *Config, buf []byte) error {
+	if cfg.ConfigPath == configLocation() {
+		backup := cfg.ConfigPath + ".bak"
+		if err := os.WriteFile(backup, buf, 0600); err != nil {
+			return fmt.Errorf("failed to back up the config to %s: %w", backup, err)
+		}
+		debug.Log("Backed up legacy config to %s", backup)
+	}
+	return cfg.Save()
+}
+
+// topLevelKeys returns the names of the options set in a config file
+func topLevelKeys(buf []byte) []string {
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(buf, &m); err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 func checkOverflow(m map[string]interface{}) error {
 	if len(m) < 1 {
 		return nil
 	}
+	return fmt.Errorf("unknown fields: %+v", overflowKeys(m))
+}
 
-	var keys []string
+func overflowKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-	return fmt.Errorf("unknown fields: %+v", keys)
+	return keys
 }
 
 type configer interface {
@@ -103,13 +151,37 @@ type configer interface {
 }
 
 func decode(buf []byte, relaxed bool) (*Config, error) {
+	cfg, _, err := decodeVersion(buf, relaxed)
+	return cfg, err
+}
+
+// decodeVersion decodes the config and returns true if it was in a legacy
+// format. Unknown options are ignored, but kept in the config, so they are
+// not lost when the config is saved. Unless relaxed is set a warning is
+// printed for them.
+func decodeVersion(buf []byte, relaxed bool) (*Config, bool, error) {
 	mostRecent := &Config{
-		AutoImport:    true,
-		ClipTimeout:   45,
-		ExportKeys:    true,
-		Notifications: true,
-		Parsing:       true,
-		Path:          PwStoreDir(""),
+		AutoImport:         true,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        45,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		ExportKeys:         true,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		Notifications:      true,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               PwStoreDir(""),
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
 	}
 	cfgs := []configer{
 		// most recent config must come first
@@ -125,12 +197,9 @@ func decode(buf []byte, relaxed bool) (*Config, error) {
 		&Pre140{},
 		&Pre130{},
 	}
-	if relaxed {
-		// most recent config must come last as well, will be tried w/o
-		// overflow checks
-		cfgs = append(cfgs, mostRecent)
-	}
-	var warn string
+	// most recent config must come last as well, will be tried w/o
+	// overflow checks
+	cfgs = append(cfgs, mostRecent)
 	for i, cfg := range cfgs {
 		debug.Log("Trying to unmarshal config into %T", cfg)
 		if err := yaml.Unmarshal(buf, cfg); err != nil {
@@ -139,49 +208,35 @@ func decode(buf []byte, relaxed bool) (*Config, error) {
 		}
 		if err := cfg.CheckOverflow(); err != nil {
 			debug.Log("Extra elements in config: %s", err)
-			if i == 0 {
-				warn = fmt.Sprintf("Failed to load config %T. Do you need to remove deprecated fields? %s\n", cfg, err)
-			}
 			// usually we are strict about extra fields, i.e. any field left
 			// unparsed means this config failed and we try the next one.
 			if i < len(cfgs)-1 {
 				continue
 			}
-			// in relaxed mode we append an extra copy of the most recent
-			// config to the end of the slice and might just ignore these
-			// extra fields.
+			// Some users build gopass from the master branch and might have
+			// options in their config this version doesn't know about, yet.
+			// Others might have removed options. Both must not make us
+			// forget the rest of the config.
 			if !relaxed {
-				continue
+				fmt.Fprintf(os.Stderr, "Ignoring unknown config options: %s\n", strings.Join(overflowKeys(mostRecent.XXX), ", "))
 			}
-			debug.Log("Ignoring extra config fields for fallback config (only)")
 		}
 		debug.Log("Loaded config: %T: %+v", cfg, cfg)
 		conf := cfg.Config()
-		if i > 0 {
+		legacy := i > 0 && i < len(cfgs)-1
+		if legacy {
 			debug.Log("Loaded legacy config. Should rewrite config.")
 		}
-		return conf, nil
-	}
-	// We try to provide a seamless config upgrade path for users of our
-	// released versions. But some users build gopass from the master branch
-	// and these might run into issues when we remove config options.
-	// Since our config parser is pedantic (it has to) we fail parsing on
-	// unknown config options. If we remove one and the user rebuilds it's
-	// gopass binary without changing the config, it will fail to parse the
-	// current config and the legacy configs will likely fail as well.
-	// But if we always display the warning users with configs from previous
-	// releases will always see the warning. So instead we only display the
-	// warning if parsing of the most up to date config struct fails AND
-	// not other succeeds.
-	if warn != "" {
-		fmt.Fprint(os.Stderr, warn)
-	}
-	return nil, ErrConfigNotParsed
-}
-
-// Save saves the config
+		return conf, legacy, nil
+	}
+	return nil, false, ErrConfigNotParsed
+}
+
+// Save saves the config. Only the options set in the user config, or changed
+// since the config was loaded, are written. Values from the system config or
+// the environment are never written.
 func (c *Config) Save() error {
-	buf, err := yaml.Marshal(c)
+	buf, err := c.marshal()
 	if err != nil {
 		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
@@ -199,3 +254,50 @@ func (c *Config) Save() error {
 	debug.Log("Saved config to %s: %+v\n", cfgLoc, c)
 	return nil
 }
+
+func (c *Config) marshal() ([]byte, error) {
+	if c == nil || c.Layers == nil {
+		return yaml.Marshal(c)
+	}
+	l := c.Layers
+
+	cur := c.ConfigMap()
+	write := make(map[string]string, len(l.user))
+	for k, v := range cur {
+		if c.fromEnv(k) {
+			if uv, found := l.user[k]; found {
+				write[k] = uv
+			}
+			continue
+		}
+		if _, found := l.user[k]; found || v != l.loaded[k] || k == "path" {
+			write[k] = v
+			l.user[k] = v
+		}
+	}
+
+	// the environment must not end up in the config file
+	cp := *c
+	for k, v := range write {
+		if err := cp.setConfigValue(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(&cp); err != nil {
+		return nil, err
+	}
+	content := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k := node.Content[i].Value
+		if _, isOption := cur[k]; isOption {
+			if _, found := write[k]; !found {
+				continue
+			}
+		}
+		content = append(content, node.Content[i], node.Content[i+1])
+	}
+	node.Content = content
+	return yaml.Marshal(&node)
+}
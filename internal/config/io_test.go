@@ -35,15 +35,30 @@ mounts:
   foo/sub: /home/johndoe/.password-store-foo-sub
   work: /home/johndoe/.password-store-work`,
 			want: &Config{
-				AutoClip:      true,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    true,
-				NoPager:       false,
-				Notifications: true,
-				Parsing:       true,
-				Path:          "/home/johndoe/.password-store",
-				SafeContent:   false,
+				AutoClip:           true,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         true,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      true,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/johndoe/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"foo/sub": "/home/johndoe/.password-store-foo-sub",
 					"work":    "/home/johndoe/.password-store-work",
@@ -64,15 +79,30 @@ mounts:
   foo/sub: /home/johndoe/.password-store-foo-sub
   work: /home/johndoe/.password-store-work`,
 			want: &Config{
-				AutoClip:      true,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    true,
-				NoPager:       false,
-				Notifications: true,
-				Parsing:       true,
-				Path:          "/home/johndoe/.password-store",
-				SafeContent:   false,
+				AutoClip:           true,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         true,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      true,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/johndoe/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"foo/sub": "/home/johndoe/.password-store-foo-sub",
 					"work":    "/home/johndoe/.password-store-work",
@@ -129,15 +159,30 @@ mounts:
     usesymbols: true
 `,
 			want: &Config{
-				AutoClip:      true,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/johndoe/.password-store",
-				SafeContent:   false,
+				AutoClip:           true,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/johndoe/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"foo/sub": "/home/johndoe/.password-store-foo-sub",
 					"work":    "/home/johndoe/.password-store-work",
@@ -175,15 +220,30 @@ mounts:
     safecontent: false
 version: 1.4.0`,
 			want: &Config{
-				AutoClip:      false,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/johndoe/.password-store",
-				SafeContent:   false,
+				AutoClip:           false,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/johndoe/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"foo/sub": "/home/johndoe/.password-store-foo-sub",
 					"work":    "/home/johndoe/.password-store-work",
@@ -205,15 +265,30 @@ path: /home/foo/.password-store
 safecontent: true
 version: "1.3.0"`,
 			want: &Config{
-				AutoClip:      false,
-				AutoImport:    true,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/foo/.password-store",
-				SafeContent:   true,
+				AutoClip:           false,
+				AutoImport:         true,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/foo/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        true,
 				Mounts: map[string]string{
 					"dev":       "/Users/johndoe/.password-store-dev",
 					"ops":       "/Users/johndoe/.password-store-ops",
@@ -243,15 +318,30 @@ persistkeys: true
 safecontent: true
 version: "1.2.0"`,
 			want: &Config{
-				AutoClip:      false,
-				AutoImport:    true,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/foo/.password-store",
-				SafeContent:   true,
+				AutoClip:           false,
+				AutoImport:         true,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/foo/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        true,
 				Mounts: map[string]string{
 					"dev":       "/Users/johndoe/.password-store-dev",
 					"ops":       "/Users/johndoe/.password-store-ops",
@@ -279,15 +369,30 @@ persistkeys: true
 safecontent: false
 version: 1.1.0`,
 			want: &Config{
-				AutoClip:      false,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/johndoe/.password-store",
-				SafeContent:   false,
+				AutoClip:           false,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/johndoe/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"dev":       "/home/johndoe/.password-store-dev",
 					"ops":       "/home/johndoe/.password-store-ops",
@@ -313,15 +418,30 @@ path: /home/foo/.password-store
 persistkeys: false
 version: "1.0.0"`,
 			want: &Config{
-				AutoClip:      false,
-				AutoImport:    false,
-				ClipTimeout:   45,
-				ExportKeys:    false,
-				NoPager:       false,
-				Notifications: false,
-				Parsing:       true,
-				Path:          "/home/foo/.password-store",
-				SafeContent:   false,
+				AutoClip:           false,
+				AutoImport:         false,
+				AutoPush:           true,
+				AutoSync:           true,
+				BinaryLimit:        DefaultBinaryLimit,
+				CheckRecipientHash: true,
+				ClipTimeout:        45,
+				ExpiryWarn:         30,
+				ExportKeys:         false,
+				KeyCache:           true,
+				DecryptCache:       DefaultDecryptCache,
+				ExecTimeout:        DefaultExecTimeout,
+				RecordingCheck:     true,
+				LockAfter:          DefaultLockAfter,
+				LockTimeout:        DefaultLockTimeout,
+				NoPager:            false,
+				Notifications:      false,
+				OldPasswords:       DefaultOldPasswords,
+				Parsing:            true,
+				Path:               "/home/foo/.password-store",
+				PullStrategy:       DefaultPullStrategy,
+				QRTimeout:          45,
+				UpdateStartupTTY:   true,
+				SafeContent:        false,
 				Mounts: map[string]string{
 					"dev":       "/Users/johndoe/.password-store-dev",
 					"ops":       "/Users/johndoe/.password-store-ops",
@@ -384,6 +504,15 @@ func TestLoad(t *testing.T) {
 	assert.True(t, cfg.SafeContent)
 }
 
+func TestLoadGenerateMode(t *testing.T) {
+	gcfg := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(gcfg, []byte("mode: XKCD\npath: /home/johndoe/.password-store\n"), 0600))
+
+	cfg, err := load(gcfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, "xkcd", cfg.GenerateMode)
+}
+
 func TestLoadError(t *testing.T) {
 	gcfg := filepath.Join(os.TempDir(), ".gopass-err.yml")
 	assert.NoError(t, os.Setenv("GOPASS_CONFIG", gcfg))
@@ -28,16 +28,31 @@ type Pre1127 struct {
 // Config converts the Pre1127 config to the current config struct
 func (c *Pre1127) Config() *Config {
 	cfg := &Config{
-		AutoClip:      c.AutoClip,
-		AutoImport:    c.AutoImport,
-		ClipTimeout:   c.ClipTimeout,
-		ExportKeys:    c.ExportKeys,
-		NoPager:       c.NoPager,
-		Notifications: c.Notifications,
-		Parsing:       c.Parsing,
-		Path:          c.Path,
-		SafeContent:   c.SafeContent,
-		Mounts:        make(map[string]string, len(c.Mounts)),
+		AutoClip:           c.AutoClip,
+		AutoImport:         c.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		ExportKeys:         c.ExportKeys,
+		NoPager:            c.NoPager,
+		Notifications:      c.Notifications,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            c.Parsing,
+		Path:               c.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	for k, v := range c.Mounts {
 		cfg.Mounts[k] = v
@@ -76,16 +91,31 @@ func (c *Pre1102) CheckOverflow() error {
 // Config converts the Pre1102 config to the current config struct
 func (c *Pre1102) Config() *Config {
 	cfg := &Config{
-		AutoClip:      c.AutoClip,
-		AutoImport:    c.AutoImport,
-		ClipTimeout:   c.ClipTimeout,
-		ExportKeys:    c.ExportKeys,
-		NoPager:       c.NoPager,
-		Notifications: c.Notifications,
-		Parsing:       true,
-		Path:          c.Path,
-		SafeContent:   c.SafeContent,
-		Mounts:        make(map[string]string, len(c.Mounts)),
+		AutoClip:           c.AutoClip,
+		AutoImport:         c.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		ExportKeys:         c.ExportKeys,
+		NoPager:            c.NoPager,
+		Notifications:      c.Notifications,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               c.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	for k, v := range c.Mounts {
 		cfg.Mounts[k] = v
@@ -131,15 +161,30 @@ func (c *Pre193) CheckOverflow() error {
 // Config converts the Pre193 config to the current config struct
 func (c *Pre193) Config() *Config {
 	cfg := &Config{
-		AutoClip:      c.Root.AutoClip,
-		AutoImport:    c.Root.AutoImport,
-		ClipTimeout:   c.Root.ClipTimeout,
-		NoPager:       c.Root.NoPager,
-		Notifications: c.Root.Notifications,
-		Parsing:       true,
-		Path:          c.Root.Path,
-		SafeContent:   c.Root.SafeContent,
-		Mounts:        make(map[string]string, len(c.Mounts)),
+		AutoClip:           c.Root.AutoClip,
+		AutoImport:         c.Root.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.Root.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		NoPager:            c.Root.NoPager,
+		Notifications:      c.Root.Notifications,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               c.Root.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.Root.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	if p, err := pathFromURL(c.Root.Path); err == nil {
 		cfg.Path = p
@@ -193,15 +238,30 @@ func (c *Pre182) CheckOverflow() error {
 // Config converts the Pre182 config to the current config struct
 func (c *Pre182) Config() *Config {
 	cfg := &Config{
-		AutoClip:      c.Root.AutoClip,
-		AutoImport:    c.Root.AutoImport,
-		ClipTimeout:   c.Root.ClipTimeout,
-		NoPager:       c.Root.NoPager,
-		Notifications: c.Root.Notifications,
-		Parsing:       true,
-		Path:          c.Root.Path,
-		SafeContent:   c.Root.SafeContent,
-		Mounts:        make(map[string]string, len(c.Mounts)),
+		AutoClip:           c.Root.AutoClip,
+		AutoImport:         c.Root.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.Root.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		NoPager:            c.Root.NoPager,
+		Notifications:      c.Root.Notifications,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               c.Root.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.Root.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	if p, err := pathFromURL(c.Root.Path); err == nil {
 		cfg.Path = p
@@ -240,12 +300,27 @@ func (c *Pre140) CheckOverflow() error {
 // Config converts the Pre140 config to the current config struct
 func (c *Pre140) Config() *Config {
 	cfg := &Config{
-		AutoImport:  c.AutoImport,
-		ClipTimeout: c.ClipTimeout,
-		Parsing:     true,
-		Path:        c.Path,
-		SafeContent: c.SafeContent,
-		Mounts:      make(map[string]string, len(c.Mounts)),
+		AutoImport:         c.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               c.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	for k, v := range c.Mounts {
 		cfg.Mounts[k] = v
@@ -284,12 +359,27 @@ func (c *Pre130) CheckOverflow() error {
 // Config converts the Pre130 config to the current config struct
 func (c *Pre130) Config() *Config {
 	cfg := &Config{
-		AutoImport:  c.AutoImport,
-		ClipTimeout: c.ClipTimeout,
-		Parsing:     true,
-		Path:        c.Path,
-		SafeContent: c.SafeContent,
-		Mounts:      make(map[string]string, len(c.Mounts)),
+		AutoImport:         c.AutoImport,
+		AutoPush:           true,
+		AutoSync:           true,
+		BinaryLimit:        DefaultBinaryLimit,
+		CheckRecipientHash: true,
+		ClipTimeout:        c.ClipTimeout,
+		DecryptCache:       DefaultDecryptCache,
+		ExecTimeout:        DefaultExecTimeout,
+		RecordingCheck:     true,
+		ExpiryWarn:         DefaultExpiryWarn,
+		KeyCache:           true,
+		LockAfter:          DefaultLockAfter,
+		LockTimeout:        DefaultLockTimeout,
+		OldPasswords:       DefaultOldPasswords,
+		Parsing:            true,
+		Path:               c.Path,
+		PullStrategy:       DefaultPullStrategy,
+		QRTimeout:          45,
+		UpdateStartupTTY:   true,
+		SafeContent:        c.SafeContent,
+		Mounts:             make(map[string]string, len(c.Mounts)),
 	}
 	for k, v := range c.Mounts {
 		cfg.Mounts[k] = v
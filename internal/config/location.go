@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/gopasspw/gopass/pkg/appdir"
@@ -52,6 +53,17 @@ func configLocations() []string {
 	return l
 }
 
+// systemConfigLocation returns the location of the system wide config file
+func systemConfigLocation() string {
+	if cf := os.Getenv("GOPASS_SYSTEM_CONFIG"); cf != "" {
+		return cf
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "gopass", "config.yml")
+	}
+	return filepath.Join("/etc", "gopass", "config.yml")
+}
+
 // PwStoreDir reads the password store dir from the environment
 // or returns the default location if the env is not set
 func PwStoreDir(mount string) string {
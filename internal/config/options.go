@@ -0,0 +1,355 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sections groups the config options. The sectioned key, e.g. git.autopush,
+// can be used instead of the plain option name on the command line and
+// determines the name of the environment variable overriding the option,
+// e.g. GOPASS_GIT_AUTOPUSH.
+var sections = map[string]string{
+	"ageagent":            "age",
+	"autoclip":            "generate",
+	"autoimport":          "gpg",
+	"autooffline":         "core",
+	"autopush":            "git",
+	"autosync":            "core",
+	"autosyncinterval":    "git",
+	"autotype":            "show",
+	"binarylimit":         "core",
+	"checkrecipienthash":  "core",
+	"clipboard":           "show",
+	"cliptimeout":         "show",
+	"cmd":                 "picker",
+	"commitmsghashnames":  "git",
+	"commitmsgtemplate":   "git",
+	"decrypt":             "completion",
+	"decryptcache":        "core",
+	"exectimeout":         "core",
+	"expirywarn":          "gpg",
+	"formatpasswords":     "show",
+	"exportkeys":          "gpg",
+	"gitcredentialprefix": "git",
+	"gnupghome":           "gpg",
+	"hooks":               "core",
+	"keepbackup":          "core",
+	"keycache":            "gpg",
+	"keyserver":           "gpg",
+	"lockafter":           "core",
+	"locktimeout":         "core",
+	"metadata":            "show",
+	"mode":                "generate",
+	"noambiguous":         "generate",
+	"nodigits":            "generate",
+	"nopager":             "core",
+	"nosync":              "git",
+	"notifications":       "core",
+	"nouppercase":         "generate",
+	"oldpasswords":        "generate",
+	"ownertrust":          "gpg",
+	"parsing":             "core",
+	"path":                "core",
+	"pullstrategy":        "git",
+	"qrtimeout":           "show",
+	"readonly":            "core",
+	"recordingcheck":      "show",
+	"recordingvars":       "show",
+	"safecontent":         "show",
+	"showautoclip":        "show",
+	"signcommits":         "git",
+	"symbols":             "generate",
+	"unsafekeys":          "show",
+	"updatestartuptty":    "gpg",
+	"wordlistfile":        "generate",
+	"workers":             "core",
+}
+
+// preserveCase are the options whose values are not lower cased
+var preserveCase = map[string]bool{
+	"cmd":                 true,
+	"commitmsgtemplate":   true,
+	"gitcredentialprefix": true,
+	"path":                true,
+	"recordingvars":       true,
+	"wordlistfile":        true,
+}
+
+// sectionedNames are the sectioned keys of options whose name in the section
+// differs from the plain option name, e.g. because another section uses the
+// same name
+var sectionedNames = map[string]string{
+	"autooffline":  "core.auto-offline",
+	"showautoclip": "show.autoclip",
+}
+
+// alternativeKeys are other accepted keys of options, e.g. hyphenated
+// spellings or keys in other sections. They are never shown, the sectioned
+// key is.
+var alternativeKeys = map[string]string{
+	"core.check-recipient-hash": "checkrecipienthash",
+	"core.clipboard":            "clipboard",
+	"core.exec-timeout":         "exectimeout",
+	"core.expiry-warn":          "expirywarn",
+	"core.exportkeys":           "exportkeys",
+	"core.keycache":             "keycache",
+	"core.lock-after":           "lockafter",
+	"core.signcommits":          "signcommits",
+	"git.autosync-interval":     "autosyncinterval",
+	"git.commit-msg-hash-names": "commitmsghashnames",
+	"git.commit-msg-template":   "commitmsgtemplate",
+	"git.pull-strategy":         "pullstrategy",
+	"gpg.home":                  "gnupghome",
+	"sync.disable":              "nosync",
+}
+
+// OptionKey returns the sectioned key of the given option, e.g. git.autopush
+// for autopush
+func OptionKey(name string) string {
+	if key, found := sectionedNames[name]; found {
+		return key
+	}
+	if section, found := sections[name]; found {
+		return section + "." + name
+	}
+	return name
+}
+
+// OptionName returns the plain option name for a plain, sectioned or
+// alternative key, e.g. autopush for git.autopush. It does not check if the
+// option exists.
+func OptionName(key string) string {
+	key = strings.ToLower(key)
+	if name, found := alternativeKeys[key]; found {
+		return name
+	}
+	for name, k := range sectionedNames {
+		if k == key {
+			return name
+		}
+	}
+	p := strings.SplitN(key, ".", 2)
+	if len(p) < 2 {
+		return key
+	}
+	if sections[p[1]] != p[0] {
+		return key
+	}
+	return p[1]
+}
+
+// EnvName returns the name of the environment variable overriding the
+// given option
+func EnvName(name string) string {
+	return "GOPASS_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(OptionKey(name)))
+}
+
+// Layer is a source of config values. Higher layers take precedence.
+type Layer int
+
+const (
+	// LayerDefault are the built-in defaults
+	LayerDefault Layer = iota
+	// LayerSystem is the system wide config file
+	LayerSystem
+	// LayerUser is the config file of the user
+	LayerUser
+	// LayerMount is the per mount section of the user config file
+	LayerMount
+	// LayerEnv are GOPASS_<SECTION>_<KEY> environment variables
+	LayerEnv
+)
+
+// Origin describes where an effective config value came from
+type Origin struct {
+	Layer Layer
+	// Source is the config file, the mount or the environment variable
+	Source string
+}
+
+func (o Origin) String() string {
+	switch o.Layer {
+	case LayerSystem, LayerUser:
+		return "file:" + o.Source
+	case LayerMount:
+		return "mount:" + o.Source
+	case LayerEnv:
+		return "env:" + o.Source
+	default:
+		return "default"
+	}
+}
+
+// Layers keeps track of the layer each effective value came from, so values
+// from the system config or the environment are never written to the user
+// config
+type Layers struct {
+	system string
+	// origin of each option not using the built-in default
+	origin map[string]Origin
+	// values of the options set in the user config file
+	user map[string]string
+	// effective values after loading, used to detect changes
+	loaded map[string]string
+}
+
+func newLayers(c *Config) *Layers {
+	return &Layers{
+		system: systemConfigLocation(),
+		origin: make(map[string]Origin, 8),
+		user:   make(map[string]string, 8),
+		loaded: c.ConfigMap(),
+	}
+}
+
+// applyLayers applies the system config and the environment on top of the
+// values read from the user config file. userKeys are the options set in the
+// user config file.
+func (c *Config) applyLayers(userKeys []string) {
+	l := newLayers(c)
+	c.Layers = l
+
+	values := c.ConfigMap()
+	user := make(map[string]bool, len(userKeys))
+	for _, k := range userKeys {
+		if _, found := values[k]; !found {
+			continue
+		}
+		user[k] = true
+		l.user[k] = values[k]
+		l.origin[k] = Origin{Layer: LayerUser, Source: c.ConfigPath}
+	}
+
+	if sys, err := loadSystemConfig(l.system); err == nil {
+		for k, v := range sys {
+			if user[k] {
+				continue
+			}
+			if err := c.setConfigValue(k, v); err != nil {
+				fmt.Fprintf(os.Stderr, "Ignoring %s in %s: %s\n", k, l.system, err)
+				continue
+			}
+			l.origin[k] = Origin{Layer: LayerSystem, Source: l.system}
+		}
+	}
+
+	for k := range values {
+		ev := EnvName(k)
+		v, found := os.LookupEnv(ev)
+		if !found {
+			continue
+		}
+		if err := c.setConfigValue(k, v); err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring %s: %s\n", ev, err)
+			continue
+		}
+		l.origin[k] = Origin{Layer: LayerEnv, Source: ev}
+	}
+
+	l.loaded = c.ConfigMap()
+}
+
+// Origin returns where the effective value of the given option for the given
+// mount came from. The per mount value is only used if the option can be set
+// per mount and no environment variable overrides it.
+func (c *Config) Origin(mount, name string) Origin {
+	name = OptionName(name)
+	var o Origin
+	if c.Layers != nil {
+		o = c.Layers.origin[name]
+	}
+	if o.Layer == LayerEnv || mount == "" {
+		return o
+	}
+	if _, found := c.mountValue(mount, name); found {
+		return Origin{Layer: LayerMount, Source: mount}
+	}
+	return o
+}
+
+// fromEnv returns true if the option is overridden by an environment variable
+func (c *Config) fromEnv(name string) bool {
+	if c.Layers == nil {
+		return false
+	}
+	return c.Layers.origin[name].Layer == LayerEnv
+}
+
+// Get returns the effective value of the given option as a string. The key
+// can be given with or without its section, e.g. git.autopush or autopush.
+func (c *Config) Get(key string) string {
+	return c.ConfigMap()[OptionName(key)]
+}
+
+// GetBool returns the effective value of the given bool option, e.g.
+// git.autopush
+func (c *Config) GetBool(key string) bool {
+	bv, err := strconv.ParseBool(c.Get(key))
+	if err != nil {
+		return false
+	}
+	return bv
+}
+
+// GetInt returns the effective value of the given int option, e.g.
+// core.locktimeout
+func (c *Config) GetInt(key string) int {
+	iv, err := strconv.Atoi(c.Get(key))
+	if err != nil {
+		return 0
+	}
+	return iv
+}
+
+// IsOption returns true if the given key names a global option
+func (c *Config) IsOption(key string) bool {
+	_, found := c.ConfigMap()[OptionName(key)]
+	return found
+}
+
+// Keys returns the sectioned keys of all global options, sorted
+func (c *Config) Keys() []string {
+	m := c.ConfigMap()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, OptionKey(k))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// UnsetConfigValue removes the option from the user config, so the value of
+// the system config or the default is used again, and saves the config
+func (c *Config) UnsetConfigValue(key string) error {
+	name := OptionName(key)
+	values := c.ConfigMap()
+	if _, found := values[name]; !found {
+		return fmt.Errorf("unknown config option %q", key)
+	}
+	if c.Layers == nil {
+		c.Layers = newLayers(c)
+	}
+	l := c.Layers
+	delete(l.user, name)
+
+	if !c.fromEnv(name) {
+		value := New().ConfigMap()[name]
+		o := Origin{}
+		if sys, err := loadSystemConfig(l.system); err == nil {
+			if sv, found := sys[name]; found {
+				value = sv
+				o = Origin{Layer: LayerSystem, Source: l.system}
+			}
+		}
+		if err := c.setConfigValue(name, value); err != nil {
+			return err
+		}
+		l.origin[name] = o
+	}
+	l.loaded[name] = c.ConfigMap()[name]
+	return c.Save()
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionKeys(t *testing.T) {
+	for key, name := range map[string]string{
+		"autopush":                  "autopush",
+		"git.autopush":              "autopush",
+		"GIT.AutoPush":              "autopush",
+		"show.cliptimeout":          "cliptimeout",
+		"core.autopush":             "core.autopush",
+		"foo.bar":                   "foo.bar",
+		"generate.autoclip":         "autoclip",
+		"show.autoclip":             "showautoclip",
+		"showautoclip":              "showautoclip",
+		"core.auto-offline":         "autooffline",
+		"core.expiry-warn":          "expirywarn",
+		"core.keycache":             "keycache",
+		"core.exportkeys":           "exportkeys",
+		"gpg.home":                  "gnupghome",
+		"core.clipboard":            "clipboard",
+		"core.signcommits":          "signcommits",
+		"git.pull-strategy":         "pullstrategy",
+		"git.autosync-interval":     "autosyncinterval",
+		"sync.disable":              "nosync",
+		"core.check-recipient-hash": "checkrecipienthash",
+		"git.commit-msg-template":   "commitmsgtemplate",
+		"git.commit-msg-hash-names": "commitmsghashnames",
+		"core.lock-after":           "lockafter",
+		"core.exec-timeout":         "exectimeout",
+	} {
+		assert.Equal(t, name, OptionName(key), key)
+	}
+
+	assert.Equal(t, "git.autopush", OptionKey("autopush"))
+	assert.Equal(t, "foo", OptionKey("foo"))
+	assert.Equal(t, "GOPASS_GIT_AUTOPUSH", EnvName("autopush"))
+	assert.Equal(t, "GOPASS_SHOW_CLIPTIMEOUT", EnvName("cliptimeout"))
+	assert.Equal(t, "show.autoclip", OptionKey("showautoclip"))
+	assert.Equal(t, "GOPASS_SHOW_AUTOCLIP", EnvName("showautoclip"))
+	assert.Equal(t, "GOPASS_CORE_AUTO_OFFLINE", EnvName("autooffline"))
+
+	// every option must have a section
+	for k := range New().ConfigMap() {
+		assert.Contains(t, sections, k)
+	}
+}
+
+func setupLayers(t *testing.T, user, system string) (string, string) {
+	t.Helper()
+
+	td := t.TempDir()
+	ucfg := filepath.Join(td, "config.yml")
+	scfg := filepath.Join(td, "system", "config.yml")
+	t.Setenv("GOPASS_CONFIG", ucfg)
+	t.Setenv("GOPASS_SYSTEM_CONFIG", scfg)
+
+	if user != "" {
+		require.NoError(t, os.WriteFile(ucfg, []byte(user), 0600))
+	}
+	if system != "" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(scfg), 0755))
+		require.NoError(t, os.WriteFile(scfg, []byte(system), 0644))
+	}
+	return ucfg, scfg
+}
+
+func TestLayers(t *testing.T) {
+	ucfg, scfg := setupLayers(t, `autoclip: true
+cliptimeout: 20
+path: /tmp/store
+`, `cliptimeout: 30
+git.autopush: false
+locktimeout: 10
+metadata: false
+`)
+	t.Setenv("GOPASS_CORE_LOCKTIMEOUT", "5")
+
+	cfg, err := load(ucfg, false)
+	require.NoError(t, err)
+
+	// user beats system
+	assert.Equal(t, 20, cfg.ClipTimeout)
+	assert.Equal(t, Origin{Layer: LayerUser, Source: ucfg}, cfg.Origin("", "cliptimeout"))
+	// system beats default
+	assert.False(t, cfg.AutoPush)
+	assert.Equal(t, "file:"+scfg, cfg.Origin("", "git.autopush").String())
+	// env beats system
+	assert.Equal(t, 5, cfg.LockTimeout)
+	assert.Equal(t, "env:GOPASS_CORE_LOCKTIMEOUT", cfg.Origin("", "locktimeout").String())
+	// defaults
+	assert.True(t, cfg.KeyCache)
+	assert.Equal(t, "default", cfg.Origin("", "keycache").String())
+
+	assert.Equal(t, "20", cfg.Get("show.cliptimeout"))
+	assert.True(t, cfg.GetBool("generate.autoclip"))
+	assert.False(t, cfg.GetBool("git.autopush"))
+	assert.Equal(t, 5, cfg.GetInt("core.locktimeout"))
+	assert.Equal(t, 0, cfg.GetInt("core.path"))
+	assert.True(t, cfg.IsOption("show.safecontent"))
+	assert.False(t, cfg.IsOption("foo.bar"))
+	assert.Contains(t, cfg.Keys(), "git.autopush")
+
+	// neither the system value nor the env override are written to the
+	// user config
+	require.NoError(t, cfg.SetConfigValue("show.safecontent", "true"))
+	buf, err := os.ReadFile(ucfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "safecontent: true")
+	assert.Contains(t, string(buf), "cliptimeout: 20")
+	assert.NotContains(t, string(buf), "autopush")
+	assert.NotContains(t, string(buf), "locktimeout")
+
+	// setting an option overridden by the environment keeps the effective
+	// value, but records the user value
+	require.NoError(t, cfg.SetConfigValue("locktimeout", "7"))
+	assert.Equal(t, 5, cfg.LockTimeout)
+	buf, err = os.ReadFile(ucfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "locktimeout: 7")
+
+	// unsetting falls back to the system value
+	require.NoError(t, cfg.UnsetConfigValue("cliptimeout"))
+	assert.Equal(t, 30, cfg.ClipTimeout)
+	assert.Equal(t, "file:"+scfg, cfg.Origin("", "cliptimeout").String())
+	buf, err = os.ReadFile(ucfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(buf), "cliptimeout")
+
+	// or the default
+	require.NoError(t, cfg.UnsetConfigValue("autoclip"))
+	assert.False(t, cfg.AutoClip)
+	assert.Equal(t, "default", cfg.Origin("", "autoclip").String())
+
+	assert.Error(t, cfg.UnsetConfigValue("foo"))
+}
+
+func TestLayersMount(t *testing.T) {
+	ucfg, _ := setupLayers(t, `path: /tmp/store
+mounts:
+  work: /tmp/work
+`, "")
+
+	cfg, err := load(ucfg, false)
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetMountConfigValue("work", "git.autopush", "false"))
+	assert.False(t, cfg.IsAutoPush("work"))
+	assert.True(t, cfg.IsAutoPush(""))
+	assert.Equal(t, "mount:work", cfg.Origin("work", "autopush").String())
+
+	// the environment beats the mount value
+	t.Setenv("GOPASS_GIT_AUTOPUSH", "true")
+	cfg, err = load(ucfg, false)
+	require.NoError(t, err)
+	assert.True(t, cfg.IsAutoPush("work"))
+	assert.Equal(t, "env:GOPASS_GIT_AUTOPUSH", cfg.Origin("work", "autopush").String())
+}
+
+func TestSystemConfig(t *testing.T) {
+	ucfg, scfg := setupLayers(t, `cliptimeout: 20
+path: /tmp/store
+`, "")
+
+	cfg, err := load(ucfg, false)
+	require.NoError(t, err)
+
+	require.NoError(t, cfg.SetSystemConfigValue("show.cliptimeout", "30"))
+	require.NoError(t, cfg.SetSystemConfigValue("nopager", "true"))
+	assert.Error(t, cfg.SetSystemConfigValue("nopager", "maybe"))
+	assert.Error(t, cfg.SetSystemConfigValue("pullstrategy", "foo"))
+
+	fi, err := os.Stat(scfg)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+
+	// the user value still wins
+	assert.Equal(t, 20, cfg.ClipTimeout)
+	assert.True(t, cfg.NoPager)
+	v, found := cfg.SystemConfigValue("cliptimeout")
+	assert.True(t, found)
+	assert.Equal(t, "30", v)
+
+	require.NoError(t, cfg.UnsetSystemConfigValue("nopager"))
+	assert.False(t, cfg.NoPager)
+	_, found = cfg.SystemConfigValue("nopager")
+	assert.False(t, found)
+
+	// unknown and non-scalar options in the system config are ignored
+	require.NoError(t, os.WriteFile(scfg, []byte("foo: bar\nmounts:\n  a: b\nnopager: true\n"), 0644))
+	m, err := loadSystemConfig(scfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nopager": "true"}, m)
+}
+
+func TestUnknownOptionsPreserved(t *testing.T) {
+	ucfg, _ := setupLayers(t, `autoclip: true
+foobar: 42
+path: /tmp/store
+`, "")
+
+	cfg, err := load(ucfg, false)
+	require.NoError(t, err)
+	assert.True(t, cfg.AutoClip)
+
+	require.NoError(t, cfg.SetConfigValue("nopager", "true"))
+	buf, err := os.ReadFile(ucfg)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "foobar: 42")
+	assert.Contains(t, string(buf), "nopager: true")
+}
+
+func TestMigrateLegacy(t *testing.T) {
+	legacy := `root:
+  autoimport: false
+  cliptimeout: 45
+  noconfirm: false
+  path: /tmp/store
+mounts:
+  work:
+    path: /tmp/work
+version: 1.4.0
+`
+	ucfg, _ := setupLayers(t, legacy, "")
+
+	cfg, err := load(ucfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, 45, cfg.ClipTimeout)
+
+	backup, err := os.ReadFile(ucfg + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, legacy, string(backup))
+
+	buf, err := os.ReadFile(ucfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(buf), "noconfirm")
+	assert.NotContains(t, string(buf), "version")
+	assert.Contains(t, string(buf), "cliptimeout: 45")
+	assert.Contains(t, string(buf), "work: /tmp/work")
+
+	// loading the migrated config does not migrate again
+	require.NoError(t, os.Remove(ucfg+".bak"))
+	cfg, err = load(ucfg, false)
+	require.NoError(t, err)
+	assert.Equal(t, 45, cfg.ClipTimeout)
+	assert.Equal(t, "/tmp/work", cfg.Mounts["work"])
+	_, err = os.Stat(ucfg + ".bak")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadSystemConfig returns the options set in the system wide config file.
+// It uses the same format as the user config, but only global options are
+// supported.
+func loadSystemConfig(fn string) (map[string]string, error) {
+	raw, err := readSystemConfig(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	known := New().ConfigMap()
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		name := OptionName(k)
+		if _, found := known[name]; !found {
+			fmt.Fprintf(os.Stderr, "Ignoring unknown config option %s in %s\n", k, fn)
+			continue
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			fmt.Fprintf(os.Stderr, "Ignoring %s in %s: not a single value\n", k, fn)
+			continue
+		}
+		m[name] = fmt.Sprintf("%v", v)
+	}
+	debug.Log("Loaded %d options from the system config at %s", len(m), fn)
+	return m, nil
+}
+
+func readSystemConfig(fn string) (map[string]interface{}, error) {
+	buf, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(buf, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", fn, err)
+	}
+	return raw, nil
+}
+
+// SystemConfigValue returns the value of the option in the system wide config
+// file, if it is set there
+func (c *Config) SystemConfigValue(key string) (string, bool) {
+	fn := systemConfigLocation()
+	if c.Layers != nil {
+		fn = c.Layers.system
+	}
+	m, err := loadSystemConfig(fn)
+	if err != nil {
+		return "", false
+	}
+	v, found := m[OptionName(key)]
+	return v, found
+}
+
+// SetSystemConfigValue sets the option in the system wide config file. It is
+// used unless the option is set in the user config or the environment.
+func (c *Config) SetSystemConfigValue(key, value string) error {
+	name := OptionName(key)
+	if err := checkValue(name, value); err != nil {
+		return err
+	}
+	tmp := New()
+	if err := tmp.setConfigValue(name, value); err != nil {
+		return err
+	}
+	return c.updateSystemConfig(name, tmp.fieldValue(name), tmp.ConfigMap()[name])
+}
+
+// UnsetSystemConfigValue removes the option from the system wide config file
+func (c *Config) UnsetSystemConfigValue(key string) error {
+	name := OptionName(key)
+	if !c.IsOption(name) {
+		return fmt.Errorf("unknown config option %q", key)
+	}
+	return c.updateSystemConfig(name, nil, New().ConfigMap()[name])
+}
+
+// updateSystemConfig writes the value to the system config, nil removes the
+// option. The effective value is updated unless a higher layer sets it.
+func (c *Config) updateSystemConfig(name string, value interface{}, effective string) error {
+	if c.Layers == nil {
+		c.Layers = newLayers(c)
+	}
+	l := c.Layers
+
+	raw, err := readSystemConfig(l.system)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if raw == nil {
+		raw = map[string]interface{}{}
+	}
+	for k := range raw {
+		if OptionName(k) == name {
+			delete(raw, k)
+		}
+	}
+	if value != nil {
+		raw[name] = value
+	}
+
+	buf, err := yaml.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.system), 0755); err != nil {
+		return fmt.Errorf("failed to create dir %q: %w", filepath.Dir(l.system), err)
+	}
+	if err := os.WriteFile(l.system, buf, 0644); err != nil {
+		return fmt.Errorf("failed to write system config file to %q: %w", l.system, err)
+	}
+	debug.Log("Saved system config to %s", l.system)
+
+	if _, found := l.user[name]; found || c.fromEnv(name) {
+		return nil
+	}
+	if err := c.setConfigValue(name, effective); err != nil {
+		return err
+	}
+	l.loaded[name] = c.ConfigMap()[name]
+	if value == nil {
+		delete(l.origin, name)
+		return nil
+	}
+	l.origin[name] = Origin{Layer: LayerSystem, Source: l.system}
+	return nil
+}
+
+// fieldValue returns the typed value of the given option
+func (c *Config) fieldValue(name string) interface{} {
+	o := reflect.ValueOf(c).Elem()
+	for i := 0; i < o.NumField(); i++ {
+		if o.Type().Field(i).Tag.Get("yaml") != name {
+			continue
+		}
+		return o.Field(i).Interface()
+	}
+	return nil
+}
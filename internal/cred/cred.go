@@ -0,0 +1,200 @@
+// Package cred serves secrets to systemd services. systemd loads a
+// credential configured with LoadCredential=<id>:<socket> by connecting to
+// the socket and reading until it is closed. Its end of the connection is
+// bound to the abstract address \0<random>/unit/<unit>/<id>, so the server
+// knows which credential is requested by which unit.
+package cred
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// DefaultSocket is the default path of the credential socket
+const DefaultSocket = "/run/gopass-cred.sock"
+
+// serveTimeout is the maximum time to resolve and send a single credential
+const serveTimeout = 30 * time.Second
+
+// ErrUnknown is returned by a Resolver for credentials it doesn't know about
+var ErrUnknown = errors.New("unknown credential")
+
+// Resolver returns the content of the credential id requested by unit
+type Resolver func(ctx context.Context, unit, id string) ([]byte, error)
+
+// Server answers the credential requests of systemd on a unix socket
+type Server struct {
+	socket  string
+	uids    map[int]bool
+	resolve Resolver
+}
+
+// NewServer creates a new server. Only peers running as one of the given
+// uids are served, systemd itself connects as root.
+func NewServer(socket string, uids []int, resolve Resolver) *Server {
+	allowed := make(map[int]bool, len(uids))
+	for _, uid := range uids {
+		allowed[uid] = true
+	}
+	return &Server{
+		socket:  socket,
+		uids:    allowed,
+		resolve: resolve,
+	}
+}
+
+// Run listens on the socket until the context is canceled
+func (s *Server) Run(ctx context.Context) error {
+	if err := supported(); err != nil {
+		return err
+	}
+	if len(s.uids) < 1 {
+		return fmt.Errorf("no uids allowed to connect")
+	}
+
+	if conn, err := net.Dial("unix", s.socket); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("a server is already listening on %s", s.socket)
+	}
+	// remove a stale socket left by a server that was killed
+	_ = os.Remove(s.socket)
+
+	l, err := net.Listen("unix", s.socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.socket, err)
+	}
+	defer func() {
+		_ = l.Close()
+	}()
+
+	// other users can only connect at all if they are allowed to, the peer
+	// uid is checked for every connection anyway
+	if err := os.Chmod(s.socket, s.socketMode()); err != nil {
+		return fmt.Errorf("failed to restrict access to %s: %w", s.socket, err)
+	}
+	debug.Log("credential server listening on %s", s.socket)
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.serve(ctx, conn)
+	}
+}
+
+func (s *Server) socketMode() os.FileMode {
+	for uid := range s.uids {
+		if uid != 0 && uid != os.Getuid() {
+			return 0666
+		}
+	}
+	return 0600
+}
+
+func (s *Server) serve(ctx context.Context, conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	uid, err := peerUID(conn)
+	if err != nil {
+		debug.Log("rejected connection: %s", err)
+		return
+	}
+	if !s.uids[uid] {
+		debug.Log("rejected connection from uid %d", uid)
+		return
+	}
+
+	unit, id, err := ParsePeer(conn.RemoteAddr().String())
+	if err != nil {
+		debug.Log("rejected connection from uid %d: %s", uid, err)
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(serveTimeout))
+
+	content, err := s.resolve(ctx, unit, id)
+	if err != nil {
+		debug.Log("failed to resolve credential %q of %s: %s", id, unit, err)
+		return
+	}
+	if _, err := conn.Write(content); err != nil {
+		debug.Log("failed to send credential %q to %s: %s", id, unit, err)
+		return
+	}
+	debug.Log("sent credential %q to %s", id, unit)
+}
+
+// ParsePeer returns the unit and the credential id from the address systemd
+// binds its end of the connection to, e.g. @f1e2d3c4/unit/web.service/db
+func ParsePeer(addr string) (string, string, error) {
+	if !strings.HasPrefix(addr, "@") && !strings.HasPrefix(addr, "\x00") {
+		return "", "", fmt.Errorf("peer address %q is not an abstract address, the credential is unknown", addr)
+	}
+	p := strings.Split(addr[1:], "/")
+	if len(p) != 4 || p[1] != "unit" || p[2] == "" || p[3] == "" {
+		return "", "", fmt.Errorf("peer address %q does not name a unit and credential", addr)
+	}
+	return p[2], p[3], nil
+}
+
+// WriteFile writes the credential to a file only readable by the current
+// user, e.g. root in ExecStartPre=. The file is replaced atomically, so the
+// service never reads a partial credential. A directory other users can
+// write to is refused, unless force is set.
+func WriteFile(dst string, content []byte, force bool) error {
+	dir := filepath.Dir(dst)
+	if !force {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if fi.Mode().Perm()&0002 != 0 {
+			return fmt.Errorf("refusing to write to %s, the directory is writable by other users. Use --force to write anyway", dst)
+		}
+	}
+
+	fh, err := os.CreateTemp(dir, "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	if err := writeTemp(fh, content); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func writeTemp(fh *os.File, content []byte) error {
+	if err := fh.Chmod(0400); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if _, err := fh.Write(content); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	return fh.Close()
+}
@@ -0,0 +1,92 @@
+//go:build linux
+// +build linux
+
+package cred
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// load requests a credential like systemd does
+func load(t *testing.T, socket, unit, id string) string {
+	t.Helper()
+
+	laddr := &net.UnixAddr{Name: fmt.Sprintf("@%d/unit/%s/%s", time.Now().UnixNano(), unit, id), Net: "unix"}
+	conn, err := net.DialUnix("unix", laddr, &net.UnixAddr{Name: socket, Net: "unix"})
+	require.NoError(t, err)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	buf, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	socket := filepath.Join(t.TempDir(), "cred.sock")
+	resolve := func(ctx context.Context, unit, id string) ([]byte, error) {
+		if id != "db-password" {
+			return nil, ErrUnknown
+		}
+		return []byte("s3cret-for-" + unit), nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(socket, []int{os.Getuid()}, resolve).Run(ctx)
+	}()
+	// the socket is only accessible by the current user once the server is ready
+	require.Eventually(t, func() bool {
+		fi, err := os.Stat(socket)
+		return err == nil && fi.Mode().Perm() == 0600
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "s3cret-for-web.service", load(t, socket, "web.service", "db-password"))
+	assert.Equal(t, "", load(t, socket, "web.service", "api-token"))
+
+	// only one server per socket
+	assert.Error(t, NewServer(socket, []int{os.Getuid()}, resolve).Run(ctx))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServerRejectsUID(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	socket := filepath.Join(t.TempDir(), "cred.sock")
+	resolve := func(ctx context.Context, unit, id string) ([]byte, error) {
+		return []byte("s3cret"), nil
+	}
+
+	go func() {
+		_ = NewServer(socket, []int{os.Getuid() + 1}, resolve).Run(ctx)
+	}()
+	// other users can connect, the peer check refuses them
+	require.Eventually(t, func() bool {
+		fi, err := os.Stat(socket)
+		return err == nil && fi.Mode().Perm() == 0666
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "", load(t, socket, "web.service", "db-password"))
+}
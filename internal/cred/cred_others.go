@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package cred
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+func supported() error {
+	return fmt.Errorf("systemd credentials are not supported on %s", runtime.GOOS)
+}
+
+// peerUID always fails, the peer credentials can not be checked on this
+// platform
+func peerUID(conn net.Conn) (int, error) {
+	return 0, supported()
+}
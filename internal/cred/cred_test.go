@@ -0,0 +1,61 @@
+package cred
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePeer(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		unit string
+		id   string
+	}{
+		{addr: "@f1e2d3c4/unit/web.service/db-password", unit: "web.service", id: "db-password"},
+		{addr: "\x00f1e2d3c4/unit/web.service/db-password", unit: "web.service", id: "db-password"},
+		{addr: "@f1e2d3c4/unit/web.service"},
+		{addr: "@f1e2d3c4/user/web.service/db-password"},
+		{addr: "@f1e2d3c4/unit//db-password"},
+		{addr: "/tmp/client.sock"},
+		{addr: ""},
+	} {
+		unit, id, err := ParsePeer(tc.addr)
+		if tc.unit == "" {
+			assert.Error(t, err, tc.addr)
+			continue
+		}
+		require.NoError(t, err, tc.addr)
+		assert.Equal(t, tc.unit, unit, tc.addr)
+		assert.Equal(t, tc.id, id, tc.addr)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "db-password")
+
+	require.NoError(t, WriteFile(dst, []byte("s3cret"), false))
+	// an existing read-only credential is replaced
+	require.NoError(t, WriteFile(dst, []byte("n3w"), false))
+
+	content, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "n3w", string(content))
+	fi, err := os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0400), fi.Mode().Perm())
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "no temporary files are left")
+
+	shared := filepath.Join(dir, "shared")
+	require.NoError(t, os.Mkdir(shared, 0777))
+	require.NoError(t, os.Chmod(shared, 0777))
+	assert.Error(t, WriteFile(filepath.Join(shared, "db-password"), []byte("s3cret"), false))
+	assert.NoError(t, WriteFile(filepath.Join(shared, "db-password"), []byte("s3cret"), true))
+}
@@ -11,17 +11,24 @@ import (
 )
 
 // GetSelection show a navigateable multiple-choice list to the user
-// and returns the selected entry along with the action
+// and returns the selected entry along with the action. On a terminal the
+// choices can be filtered by typing and selected with the arrow keys,
+// otherwise the user is asked for the number of the choice.
 func GetSelection(ctx context.Context, prompt string, choices []string) (string, int) {
 	if ctxutil.IsAlwaysYes(ctx) || !ctxutil.IsInteractive(ctx) {
 		return "impossible", 0
 	}
 
+	if act, sel, ok := selectTerminal(prompt, choices); ok {
+		return act, sel
+	}
+
+	// the choices are part of the prompt, just like the question
 	for i, c := range choices {
-		fmt.Print(color.GreenString("[%  d]", i))
-		fmt.Printf(" %s\n", c)
+		fmt.Fprint(termio.Stderr, color.GreenString("[%  d]", i))
+		fmt.Fprintf(termio.Stderr, " %s\n", c)
 	}
-	fmt.Println()
+	fmt.Fprintln(termio.Stderr)
 	var i int
 	for {
 		var err error
@@ -33,9 +40,9 @@ func GetSelection(ctx context.Context, prompt string, choices []string) (string,
 			return "aborted", 0
 		}
 		if err != nil {
-			fmt.Println(err.Error())
+			fmt.Fprintln(termio.Stderr, err.Error())
 		}
 	}
-	fmt.Println(i)
+	fmt.Fprintln(termio.Stderr, i)
 	return "default", i
 }
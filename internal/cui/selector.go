@@ -0,0 +1,250 @@
+package cui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/fuzzy"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+	"golang.org/x/term"
+)
+
+// maxSelectorHeight is the maximum number of choices shown at once
+const maxSelectorHeight = 15
+
+type key int
+
+const (
+	keyNone key = iota
+	keyRune
+	keyUp
+	keyDown
+	keyEnter
+	keyBackspace
+	keyClear
+	keyAbort
+)
+
+// selector is an interactive list of choices. Typing filters the choices
+// using fuzzy matching, the arrow keys move the cursor and enter selects
+// the choice under the cursor.
+type selector struct {
+	prompt  string
+	choices []string
+	height  int
+
+	filter  []rune
+	matches []int
+	cursor  int
+	offset  int
+	drawn   int
+}
+
+// selectTerminal runs the selector if stdin and stderr are terminals. It
+// returns false if they are not.
+func selectTerminal(prompt string, choices []string) (string, int, bool) {
+	in, ok := termio.Stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(in.Fd())) {
+		return "", 0, false
+	}
+	errOut, ok := termio.Stderr.(*os.File)
+	if !ok || !term.IsTerminal(int(errOut.Fd())) {
+		return "", 0, false
+	}
+
+	height := maxSelectorHeight
+	if _, rows, err := term.GetSize(int(errOut.Fd())); err == nil && rows > 0 && rows-2 < height {
+		height = rows - 2
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		debug.Log("failed to put the terminal into raw mode: %s", err)
+		return "", 0, false
+	}
+	defer func() {
+		if err := term.Restore(int(in.Fd()), state); err != nil {
+			debug.Log("failed to restore the terminal: %s", err)
+		}
+	}()
+
+	s := &selector{
+		prompt:  prompt,
+		choices: choices,
+		height:  height,
+	}
+	act, sel := s.run(bufio.NewReader(in), termio.Stderr)
+	return act, sel, true
+}
+
+// run reads keys until a choice is selected or the user aborts. It returns
+// the action and the index of the selected choice.
+func (s *selector) run(r *bufio.Reader, w io.Writer) (string, int) {
+	s.update()
+	for {
+		s.draw(w)
+
+		k, c, err := readKey(r)
+		if err != nil {
+			s.clear(w)
+			return "aborted", 0
+		}
+
+		switch k {
+		case keyEnter:
+			if len(s.matches) < 1 {
+				continue
+			}
+			s.clear(w)
+			return "default", s.matches[s.cursor]
+		case keyAbort:
+			s.clear(w)
+			return "aborted", 0
+		case keyUp:
+			s.move(-1)
+		case keyDown:
+			s.move(1)
+		case keyBackspace:
+			if len(s.filter) > 0 {
+				s.filter = s.filter[:len(s.filter)-1]
+				s.update()
+			}
+		case keyClear:
+			s.filter = s.filter[:0]
+			s.update()
+		case keyRune:
+			s.filter = append(s.filter, c)
+			s.update()
+		}
+	}
+}
+
+// update filters the choices and resets the cursor
+func (s *selector) update() {
+	s.cursor = 0
+	s.offset = 0
+	if len(s.filter) < 1 {
+		s.matches = make([]int, len(s.choices))
+		for i := range s.choices {
+			s.matches[i] = i
+		}
+		return
+	}
+	s.matches = fuzzy.RankIndex(string(s.filter), s.choices)
+}
+
+// move moves the cursor and scrolls the visible choices if necessary
+func (s *selector) move(n int) {
+	s.cursor += n
+	if s.cursor >= len(s.matches) {
+		s.cursor = len(s.matches) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+s.height {
+		s.offset = s.cursor - s.height + 1
+	}
+}
+
+// draw replaces the previously drawn selector. The terminal is in raw
+// mode, so every line ends with a carriage return.
+func (s *selector) draw(w io.Writer) {
+	s.clear(w)
+
+	fmt.Fprintf(w, "%s\r\n", s.prompt)
+	s.drawn++
+	for i := s.offset; i < len(s.matches) && i < s.offset+s.height; i++ {
+		if i == s.cursor {
+			fmt.Fprintf(w, "%s %s\r\n", color.GreenString(">"), color.GreenString(s.choices[s.matches[i]]))
+		} else {
+			fmt.Fprintf(w, "  %s\r\n", s.choices[s.matches[i]])
+		}
+		s.drawn++
+	}
+	fmt.Fprintf(w, "%d/%d > %s", len(s.matches), len(s.choices), string(s.filter))
+}
+
+// clear removes the selector from the terminal
+func (s *selector) clear(w io.Writer) {
+	if s.drawn > 0 {
+		fmt.Fprintf(w, "\x1b[%dA", s.drawn)
+	}
+	fmt.Fprint(w, "\r\x1b[J")
+	s.drawn = 0
+}
+
+// readKey reads a single key press
+func readKey(r *bufio.Reader) (key, rune, error) {
+	c, _, err := r.ReadRune()
+	if err != nil {
+		return keyNone, 0, err
+	}
+
+	switch c {
+	case '\r', '\n':
+		return keyEnter, 0, nil
+	case 0x03, 0x04: // Ctrl-C, Ctrl-D
+		return keyAbort, 0, nil
+	case 0x7f, 0x08: // Backspace
+		return keyBackspace, 0, nil
+	case 0x15: // Ctrl-U
+		return keyClear, 0, nil
+	case 0x10: // Ctrl-P
+		return keyUp, 0, nil
+	case 0x0e: // Ctrl-N
+		return keyDown, 0, nil
+	case 0x1b:
+		return readEscape(r)
+	}
+
+	if c < 0x20 {
+		return keyNone, 0, nil
+	}
+	return keyRune, c, nil
+}
+
+// readEscape reads the rest of an escape sequence. A single escape without
+// any further input aborts.
+func readEscape(r *bufio.Reader) (key, rune, error) {
+	if r.Buffered() < 1 {
+		return keyAbort, 0, nil
+	}
+	b, err := r.ReadByte()
+	if err != nil {
+		return keyNone, 0, err
+	}
+	if b != '[' && b != 'O' {
+		return keyNone, 0, nil
+	}
+
+	// skip any parameters up to the final byte
+	for {
+		b, err = r.ReadByte()
+		if err != nil {
+			return keyNone, 0, err
+		}
+		if b >= 0x40 && b <= 0x7e {
+			break
+		}
+	}
+
+	switch b {
+	case 'A':
+		return keyUp, 0, nil
+	case 'B':
+		return keyDown, 0, nil
+	default:
+		return keyNone, 0, nil
+	}
+}
@@ -0,0 +1,68 @@
+package cui
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelector(t *testing.T) {
+	color.NoColor = true
+	choices := []string{"foo", "bar", "baz", "misc/foobar"}
+
+	for _, tc := range []struct {
+		name  string
+		input string
+		act   string
+		sel   int
+	}{
+		{name: "first", input: "\r", act: "default", sel: 0},
+		{name: "arrow down", input: "\x1b[B\x1b[B\r", act: "default", sel: 2},
+		{name: "arrow up at the top", input: "\x1b[A\r", act: "default", sel: 0},
+		{name: "ctrl-n and ctrl-p", input: "\x0e\x0e\x0e\x10\r", act: "default", sel: 2},
+		{name: "down at the bottom", input: strings.Repeat("\x1b[B", 10) + "\r", act: "default", sel: 3},
+		{name: "filter", input: "ba\r", act: "default", sel: 1},
+		{name: "filter and move", input: "ba\x1b[B\r", act: "default", sel: 2},
+		{name: "fuzzy filter", input: "mfb\r", act: "default", sel: 3},
+		{name: "backspace", input: "bax\x7f\x1b[B\r", act: "default", sel: 2},
+		{name: "clear filter", input: "bar\x15\r", act: "default", sel: 0},
+		{name: "no match", input: "xyz\r", act: "aborted", sel: 0},
+		{name: "ctrl-c", input: "\x03", act: "aborted", sel: 0},
+		{name: "escape", input: "\x1b", act: "aborted", sel: 0},
+		{name: "eof", input: "", act: "aborted", sel: 0},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			s := &selector{
+				prompt:  "Select",
+				choices: choices,
+				height:  2,
+			}
+			act, sel := s.run(bufio.NewReader(strings.NewReader(tc.input)), buf)
+			assert.Equal(t, tc.act, act)
+			assert.Equal(t, tc.sel, sel)
+			assert.Contains(t, buf.String(), "Select\r\n")
+		})
+	}
+}
+
+func TestSelectorDraw(t *testing.T) {
+	color.NoColor = true
+	buf := &bytes.Buffer{}
+	s := &selector{
+		prompt:  "Select",
+		choices: []string{"foo", "bar", "baz"},
+		height:  2,
+	}
+	s.update()
+	s.move(2)
+	s.draw(buf)
+
+	assert.Equal(t, "\r\x1b[JSelect\r\n  bar\r\n> baz\r\n3/3 > ", buf.String())
+	assert.Equal(t, 3, s.drawn)
+}
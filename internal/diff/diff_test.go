@@ -1,6 +1,7 @@
 package diff
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +36,100 @@ func TestList(t *testing.T) {
 		assert.Equal(t, tc.removed, r)
 	}
 }
+
+func TestMerge(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		base      string
+		local     string
+		remote    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "unchanged",
+			base:   "a,b,c",
+			local:  "a,b,c",
+			remote: "a,b,c",
+			want:   "a,b,c",
+		},
+		{
+			name:   "changed locally",
+			base:   "a,b,c",
+			local:  "a,x,c",
+			remote: "a,b,c",
+			want:   "a,x,c",
+		},
+		{
+			name:   "changed remotely",
+			base:   "a,b,c",
+			local:  "a,b,c",
+			remote: "a,b,y",
+			want:   "a,b,y",
+		},
+		{
+			name:   "different lines",
+			base:   "a,b,c,d",
+			local:  "x,b,c,d",
+			remote: "a,b,c,d,y",
+			want:   "x,b,c,d,y",
+		},
+		{
+			name:   "removed and added",
+			base:   "a,b,c,d",
+			local:  "a,c,d",
+			remote: "a,b,c,e,d",
+			want:   "a,c,e,d",
+		},
+		{
+			name:   "neighbouring lines",
+			base:   "a,b,c,d",
+			local:  "a,x,c,d",
+			remote: "a,b,y,d",
+			want:   "a,x,y,d",
+		},
+		{
+			name:   "same change",
+			base:   "a,b,c",
+			local:  "a,x,c",
+			remote: "a,x,c",
+			want:   "a,x,c",
+		},
+		{
+			name:      "conflict",
+			base:      "a,b,c",
+			local:     "a,x,c",
+			remote:    "a,y,c",
+			want:      "a," + MarkerLocal + ",x," + MarkerBase + ",b," + MarkerSep + ",y," + MarkerRemote + ",c",
+			conflicts: 1,
+		},
+		{
+			name:      "added at the same place",
+			base:      "a,c",
+			local:     "a,x,c",
+			remote:    "a,y,c",
+			want:      "a," + MarkerLocal + ",x," + MarkerSep + ",y," + MarkerRemote + ",c",
+			conflicts: 1,
+		},
+		{
+			name:      "no base",
+			local:     "a,x",
+			remote:    "a,y",
+			want:      MarkerLocal + ",a,x," + MarkerSep + ",a,y," + MarkerRemote,
+			conflicts: 1,
+		},
+	} {
+		res, conflicts := Merge(lines(tc.base), lines(tc.local), lines(tc.remote))
+		assert.Equal(t, tc.want, strings.Join(res, ","), tc.name)
+		assert.Equal(t, tc.conflicts, conflicts, tc.name)
+		assert.Equal(t, tc.conflicts > 0, HasConflicts(res), tc.name)
+	}
+}
+
+// lines splits a comma separated list of lines
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,157 @@
+package diff
+
+// Conflict markers written by Merge around lines changed differently in both
+// versions
+const (
+	MarkerLocal  = "<<<<<<< local"
+	MarkerBase   = "||||||| base"
+	MarkerSep    = "======="
+	MarkerRemote = ">>>>>>> remote"
+)
+
+// Merge does a line based three-way merge of the local and remote versions
+// derived from base. Lines changed in only one version or the same way in
+// both are taken over, so are changes of neighbouring lines replacing the
+// same number of lines. All other changes are conflicts, which are kept in
+// the result between conflict markers. It returns the merged lines and the
+// number of conflicts. Without a base (nil) the versions conflict as a whole
+// unless they are equal or one of them is empty.
+func Merge(base, local, remote []string) ([]string, int) {
+	ml := matches(base, local)
+	mr := matches(base, remote)
+
+	var res []string
+	var conflicts int
+	chunk := func(b, l, r []string) {
+		if m, ok := merge(b, l, r); ok {
+			res = append(res, m...)
+			return
+		}
+		// changes of neighbouring lines, e.g. two different keys of a
+		// secret, can be merged line by line
+		if lm, ok := mergeLines(b, l, r); ok {
+			res = append(res, lm...)
+			return
+		}
+
+		conflicts++
+		res = append(res, MarkerLocal)
+		res = append(res, l...)
+		if len(b) > 0 {
+			res = append(res, MarkerBase)
+			res = append(res, b...)
+		}
+		res = append(res, MarkerSep)
+		res = append(res, r...)
+		res = append(res, MarkerRemote)
+	}
+
+	var i, j, k int
+	for {
+		// the next base line left unchanged in both versions
+		n := i
+		for n < len(base) && (ml[n] < 0 || mr[n] < 0) {
+			n++
+		}
+		if n >= len(base) {
+			chunk(base[i:], local[j:], remote[k:])
+			return res, conflicts
+		}
+		if n > i || ml[n] > j || mr[n] > k {
+			chunk(base[i:n], local[j:ml[n]], remote[k:mr[n]])
+		}
+		res = append(res, base[n])
+		i, j, k = n+1, ml[n]+1, mr[n]+1
+	}
+}
+
+// merge returns the changed version of a chunk unless both were changed
+// differently
+func merge(b, l, r []string) ([]string, bool) {
+	switch {
+	case equal(l, r), equal(b, r):
+		return l, true
+	case equal(b, l):
+		return r, true
+	}
+	return nil, false
+}
+
+// mergeLines merges chunks with the same number of lines line by line
+func mergeLines(b, l, r []string) ([]string, bool) {
+	if len(b) < 2 || len(l) != len(b) || len(r) != len(b) {
+		return nil, false
+	}
+	res := make([]string, 0, len(b))
+	for i := range b {
+		m, ok := merge(b[i:i+1], l[i:i+1], r[i:i+1])
+		if !ok {
+			return nil, false
+		}
+		res = append(res, m...)
+	}
+	return res, true
+}
+
+// HasConflicts returns true if any line is a conflict marker written by Merge
+func HasConflicts(lines []string) bool {
+	for _, l := range lines {
+		switch l {
+		case MarkerLocal, MarkerBase, MarkerSep, MarkerRemote:
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns the index of the line in b matching each line of a in
+// their longest common subsequence or -1
+func matches(a, b []string) []int {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			m[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return m
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+// +build !windows
+
+package editor
+
+import (
+	"os/exec"
+)
+
+// command returns the command to run the editor with the given arguments
+func command(editor string, args []string) *exec.Cmd {
+	return exec.Command(editor, args...)
+}
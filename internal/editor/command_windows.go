@@ -0,0 +1,56 @@
+//go:build windows
+// +build windows
+
+package editor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// command returns the command to run the editor with the given arguments.
+// Batch files, e.g. code.cmd installed by VS Code, can't be started directly,
+// they are run by cmd.exe.
+func command(editor string, args []string) *exec.Cmd {
+	bin := editor
+	if p, err := exec.LookPath(editor); err == nil {
+		bin = p
+	}
+	if !isBatch(bin) {
+		return exec.Command(bin, args...)
+	}
+
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	cmd := exec.Command(comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: batchCmdLine(comspec, bin, args),
+	}
+	return cmd
+}
+
+// isBatch returns true if the file is run by cmd.exe
+func isBatch(fn string) bool {
+	switch strings.ToLower(filepath.Ext(fn)) {
+	case ".bat", ".cmd":
+		return true
+	default:
+		return false
+	}
+}
+
+// batchCmdLine returns the command line running the batch file with cmd.exe.
+// With /s cmd.exe strips the outer quotes and runs the rest as is, so every
+// argument is quoted once.
+func batchCmdLine(comspec, bin string, args []string) string {
+	quoted := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{bin}, args...) {
+		quoted = append(quoted, `"`+a+`"`)
+	}
+	return syscall.EscapeArg(comspec) + ` /d /s /c "` + strings.Join(quoted, " ") + `"`
+}
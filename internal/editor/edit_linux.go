@@ -4,7 +4,6 @@
 package editor
 
 import (
-	"os"
 	"os/exec"
 
 	"github.com/urfave/cli/v2"
@@ -17,7 +16,7 @@ func Path(c *cli.Context) string {
 			return ed
 		}
 	}
-	if ed := os.Getenv("EDITOR"); ed != "" {
+	if ed := fromEnv(); ed != "" {
 		return ed
 	}
 	if p, err := exec.LookPath("editor"); err == nil {
@@ -33,6 +33,44 @@ func TestEditor(t *testing.T) {
 	}
 }
 
+func TestEditorArgs(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+
+	// the name of the file is appended to the arguments
+	out, err := Invoke(ctx, `sh -c 'echo "new content" > "$0"'`, []byte("old"))
+	require.NoError(t, err)
+	assert.Equal(t, "new content\n", string(out))
+
+	_, err = Invoke(ctx, "false", []byte("old"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nothing was saved")
+}
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		ed   string
+		args []string
+	}{
+		{in: "vi", ed: "vi", args: []string{}},
+		{in: "code --wait", ed: "code", args: []string{"--wait"}},
+		{in: `"/opt/my editor/bin/ed" -n`, ed: "/opt/my editor/bin/ed", args: []string{"-n"}},
+	} {
+		ed, args, err := split(tc.in)
+		require.NoError(t, err)
+		assert.Equal(t, tc.ed, ed)
+		assert.Equal(t, tc.args, args)
+	}
+
+	_, _, err := split("")
+	assert.Error(t, err)
+	_, _, err = split(`vi "unterminated`)
+	assert.Error(t, err)
+}
+
 func TestGetEditor(t *testing.T) {
 	app := cli.NewApp()
 
@@ -48,11 +86,17 @@ func TestGetEditor(t *testing.T) {
 
 	assert.Equal(t, "fooed", Path(c))
 
-	// EDITOR
+	// GOPASS_EDITOR, VISUAL and EDITOR
 	fs = flag.NewFlagSet("default", flag.ContinueOnError)
 	c = cli.NewContext(app, fs, nil)
 	assert.NoError(t, os.Setenv("EDITOR", "fooenv"))
 	assert.Equal(t, "fooenv", Path(c))
+	assert.NoError(t, os.Setenv("VISUAL", "foovisual"))
+	assert.Equal(t, "foovisual", Path(c))
+	assert.NoError(t, os.Setenv("GOPASS_EDITOR", "code --wait"))
+	assert.Equal(t, "code --wait", Path(c))
+	assert.NoError(t, os.Unsetenv("GOPASS_EDITOR"))
+	assert.NoError(t, os.Unsetenv("VISUAL"))
 	assert.NoError(t, os.Unsetenv("EDITOR"))
 
 	// editor
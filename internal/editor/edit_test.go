@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEdit(t *testing.T) {
@@ -27,3 +29,52 @@ func TestEdit(t *testing.T) {
 	assert.Error(t, err)
 	buf.Reset()
 }
+
+func TestSplitWindows(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{in: "notepad.exe", want: []string{"notepad.exe"}},
+		{in: "  notepad.exe\t ", want: []string{"notepad.exe"}},
+		{in: `C:\Windows\notepad.exe`, want: []string{`C:\Windows\notepad.exe`}},
+		{in: `"C:\Program Files\Notepad++\notepad++.exe" -multiInst -nosession`, want: []string{`C:\Program Files\Notepad++\notepad++.exe`, "-multiInst", "-nosession"}},
+		{in: `code --wait`, want: []string{"code", "--wait"}},
+		{in: `ed "" -x`, want: []string{"ed", "", "-x"}},
+		{in: `ed a\"b`, want: []string{"ed", `a"b`}},
+		{in: `ed a\\"b c"`, want: []string{"ed", `a\b c`}},
+		{in: `ed "a""b"`, want: []string{"ed", `a"b`}},
+		{in: `ed C:\dir\`, want: []string{"ed", `C:\dir\`}},
+		{in: "", want: nil},
+	} {
+		args, err := splitWindows(tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, args, tc.in)
+	}
+
+	_, err := splitWindows(`"C:\Program Files\unterminated.exe`)
+	assert.Error(t, err)
+}
+
+func TestSplitUnquoted(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Program Files", "Notepad++")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	bin := filepath.Join(dir, "notepad++.exe")
+	require.NoError(t, os.WriteFile(bin, []byte("MZ"), 0644))
+
+	ed, rest, found := splitUnquoted(bin + ` -multiInst "C:\my files\notes"`)
+	require.True(t, found)
+	assert.Equal(t, bin, ed)
+	assert.Equal(t, `-multiInst "C:\my files\notes"`, rest)
+
+	// the extension is optional, like for CreateProcess
+	ed, rest, found = splitUnquoted(filepath.Join(dir, "notepad++") + " -multiInst")
+	require.True(t, found)
+	assert.Equal(t, bin, ed)
+	assert.Equal(t, "-multiInst", rest)
+
+	_, _, found = splitUnquoted(`"` + bin + `" -multiInst`)
+	assert.False(t, found)
+	_, _, found = splitUnquoted("code --wait")
+	assert.False(t, found)
+}
@@ -4,8 +4,6 @@
 package editor
 
 import (
-	"os"
-
 	"github.com/urfave/cli/v2"
 )
 
@@ -16,7 +14,7 @@ func Path(c *cli.Context) string {
 			return ed
 		}
 	}
-	if ed := os.Getenv("EDITOR"); ed != "" {
+	if ed := fromEnv(); ed != "" {
 		return ed
 	}
 	return "notepad.exe"
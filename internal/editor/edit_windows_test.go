@@ -5,8 +5,10 @@ import (
 	"flag"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 	"github.com/urfave/cli/v2"
 
@@ -64,3 +66,53 @@ func TestGetEditor(t *testing.T) {
 	assert.Equal(t, "notepad.exe", Path(c))
 	assert.NoError(t, os.Setenv("PATH", op))
 }
+
+func TestSplit(t *testing.T) {
+	ed, args, err := split(`"C:\Program Files\Notepad++\notepad++.exe" -multiInst`)
+	require.NoError(t, err)
+	assert.Equal(t, `C:\Program Files\Notepad++\notepad++.exe`, ed)
+	assert.Equal(t, []string{"-multiInst"}, args)
+
+	// an existing file is not split
+	fn := filepath.Join(t.TempDir(), "my editor.exe")
+	require.NoError(t, os.WriteFile(fn, []byte("MZ"), 0644))
+	ed, args, err = split(fn)
+	require.NoError(t, err)
+	assert.Equal(t, fn, ed)
+	assert.Len(t, args, 0)
+
+	// an unquoted path with spaces is found before the arguments
+	ed, args, err = split(fn + " -multiInst")
+	require.NoError(t, err)
+	assert.Equal(t, fn, ed)
+	assert.Equal(t, []string{"-multiInst"}, args)
+}
+
+func TestCommand(t *testing.T) {
+	assert.True(t, isBatch(`C:\tools\code.CMD`))
+	assert.True(t, isBatch("edit.bat"))
+	assert.False(t, isBatch("notepad.exe"))
+
+	assert.Equal(t, `cmd.exe /d /s /c ""C:\my tools\code.cmd" "--wait" "C:\tmp\gopass-edit""`,
+		batchCmdLine("cmd.exe", `C:\my tools\code.cmd`, []string{"--wait", `C:\tmp\gopass-edit`}))
+
+	bat := filepath.Join(t.TempDir(), "editor.cmd")
+	require.NoError(t, os.WriteFile(bat, []byte("@echo off\r\necho new content> %1\r\n"), 0644))
+	cmd := command(bat, []string{"foo"})
+	require.NotNil(t, cmd.SysProcAttr)
+	assert.Contains(t, cmd.SysProcAttr.CmdLine, `"`+bat+`" "foo"`)
+
+	cmd = command("notepad.exe", []string{"foo"})
+	assert.Nil(t, cmd.SysProcAttr)
+}
+
+func TestBatchEditor(t *testing.T) {
+	ctx := ctxutil.WithTerminal(context.Background(), true)
+
+	bat := filepath.Join(t.TempDir(), "editor.cmd")
+	require.NoError(t, os.WriteFile(bat, []byte("@echo off\r\necho new content> %1\r\n"), 0644))
+
+	out, err := Invoke(ctx, bat, []byte("old"))
+	require.NoError(t, err)
+	assert.Equal(t, "new content\n", string(out))
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -32,6 +31,17 @@ var (
 	vimOptsRe           = regexp.MustCompile(`au\s+BufNewFile,BufRead\s+.*gopass.*setlocal\s+noswapfile\s+nobackup\s+noundofile`)
 )
 
+// fromEnv returns the editor from the environment. GOPASS_EDITOR takes
+// precedence over VISUAL and EDITOR.
+func fromEnv() string {
+	for _, k := range []string{"GOPASS_EDITOR", "VISUAL", "EDITOR"} {
+		if ed := os.Getenv(k); ed != "" {
+			return ed
+		}
+	}
+	return ""
+}
+
 // Check will validate the editor config
 func Check(ctx context.Context, editor string) error {
 	if !strings.Contains(editor, "vi") {
@@ -84,27 +94,20 @@ func Invoke(ctx context.Context, editor string, content []byte) ([]byte, error)
 		return []byte{}, fmt.Errorf("failed to close tmpfile to start with %s %v: %w", editor, tmpfile.Name(), err)
 	}
 
-	var args []string
-	if runtime.GOOS != "windows" {
-		cmdArgs, err := shellquote.Split(editor)
-		if err != nil {
-			return []byte{}, fmt.Errorf("failed to parse EDITOR command `%s`", editor)
-		}
-
-		editor = cmdArgs[0]
-		args = append(cmdArgs[1:], tmpfile.Name())
-	} else {
-		args = []string{tmpfile.Name()}
+	editor, args, err := split(editor)
+	if err != nil {
+		return []byte{}, err
 	}
+	args = append(args, tmpfile.Name())
 
-	cmd := exec.Command(editor, args...)
+	cmd := command(editor, args)
 	cmd.Stdin = Stdin
 	cmd.Stdout = Stdout
 	cmd.Stderr = Stderr
 
 	if err := cmd.Run(); err != nil {
 		debug.Log("cmd: %s %+v - error: %+v", cmd.Path, cmd.Args, err)
-		return []byte{}, fmt.Errorf("failed to run %s with %s file: %w", editor, tmpfile.Name(), err)
+		return []byte{}, fmt.Errorf("%s failed, nothing was saved: %w", editor, err)
 	}
 
 	nContent, err := os.ReadFile(tmpfile.Name())
@@ -118,3 +121,118 @@ func Invoke(ctx context.Context, editor string, content []byte) ([]byte, error)
 
 	return nContent, nil
 }
+
+// split splits the editor command into the binary and its arguments, e.g.
+// "code --wait". On Windows the command is only split if it is not the path
+// of an existing file, since paths often contain spaces and backslashes.
+func split(editor string) (string, []string, error) {
+	if runtime.GOOS == "windows" {
+		if fsutil.IsFile(editor) {
+			return editor, nil, nil
+		}
+		if bin, rest, found := splitUnquoted(editor); found {
+			args, err := splitWindows(rest)
+			if err != nil {
+				return "", nil, fmt.Errorf("failed to parse EDITOR command `%s`: %w", editor, err)
+			}
+			return bin, args, nil
+		}
+	}
+
+	splitFn := shellquote.Split
+	if runtime.GOOS == "windows" {
+		splitFn = splitWindows
+	}
+	args, err := splitFn(editor)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse EDITOR command `%s`: %w", editor, err)
+	}
+	if len(args) < 1 {
+		return "", nil, fmt.Errorf("empty editor command")
+	}
+	return args[0], args[1:], nil
+}
+
+// splitUnquoted finds the binary of a command line starting with an unquoted
+// path containing spaces, e.g. `C:\Program Files\Notepad++\notepad++.exe
+// -multiInst`. Like CreateProcess the shortest prefix naming an existing file
+// is the binary.
+func splitUnquoted(cmdline string) (string, string, bool) {
+	cmdline = strings.TrimLeft(cmdline, " \t")
+	if strings.HasPrefix(cmdline, `"`) {
+		return "", "", false
+	}
+	for i := 0; i < len(cmdline); i++ {
+		if cmdline[i] != ' ' {
+			continue
+		}
+		for _, fn := range []string{cmdline[:i], cmdline[:i] + ".exe"} {
+			if fsutil.IsFile(fn) {
+				return fn, cmdline[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// splitWindows splits a command line like CommandLineToArgvW does, e.g.
+// `"C:\Program Files\Notepad++\notepad++.exe" -multiInst`. Backslashes are
+// only special in front of a double quote, so paths don't need to be
+// escaped.
+func splitWindows(cmdline string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, inQuotes := false, false
+	backslashes := 0
+
+	for i := 0; i < len(cmdline); i++ {
+		c := cmdline[i]
+		switch {
+		case c == '\\':
+			backslashes++
+			inArg = true
+			continue
+		case c == '"':
+			// 2n backslashes are n backslashes and a quote, 2n+1 are n
+			// backslashes and a literal quote
+			arg.WriteString(strings.Repeat("\\", backslashes/2))
+			literal := backslashes%2 == 1
+			backslashes = 0
+			inArg = true
+			if literal {
+				arg.WriteByte('"')
+				continue
+			}
+			// "" inside quotes is a literal quote
+			if inQuotes && i+1 < len(cmdline) && cmdline[i+1] == '"' {
+				arg.WriteByte('"')
+				i++
+				continue
+			}
+			inQuotes = !inQuotes
+			continue
+		}
+
+		arg.WriteString(strings.Repeat("\\", backslashes))
+		backslashes = 0
+		if (c == ' ' || c == '\t') && !inQuotes {
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+			continue
+		}
+		arg.WriteByte(c)
+		inArg = true
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quoted string")
+	}
+	arg.WriteString(strings.Repeat("\\", backslashes))
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
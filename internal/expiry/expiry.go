@@ -0,0 +1,124 @@
+// Package expiry handles the expiry date of secrets, e.g. of API tokens. It
+// is kept in the expires key of a secret as a date (YYYY-MM-DD) or an
+// RFC 3339 timestamp. Dates without a time expire at the start of that day
+// in the local time zone.
+package expiry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// Key is the key of a secret holding its expiry date
+const Key = "expires"
+
+const dateLayout = "2006-01-02"
+
+const day = 24 * time.Hour
+
+// units are the suffixes of durations a Go duration doesn't support
+var units = map[string]time.Duration{
+	"d": day,
+	"w": 7 * day,
+	"y": 365 * day,
+}
+
+// ParseDuration parses a duration in days (90d), weeks (2w), years (1y) or
+// anything time.ParseDuration accepts, e.g. 36h
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range units {
+		if !strings.HasSuffix(value, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(value, suffix))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q. Use e.g. 30d, 2w, 1y or 36h", value)
+	}
+	return d, nil
+}
+
+// ParseDate parses an absolute expiry date, either a date (YYYY-MM-DD) or
+// an RFC 3339 timestamp
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(dateLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid expiry date %q. Use YYYY-MM-DD or an RFC 3339 timestamp", value)
+}
+
+// Parse parses an absolute expiry date or a duration relative to now
+func Parse(value string, now time.Time) (time.Time, error) {
+	if t, err := ParseDate(value); err == nil {
+		return t, nil
+	}
+	d, err := ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q. Use a date (YYYY-MM-DD), an RFC 3339 timestamp or a duration, e.g. 90d", value)
+	}
+	if d%day == 0 {
+		// whole days give a date, not the current time of day
+		y, m, dd := now.In(time.Local).Date()
+		return time.Date(y, m, dd+int(d/day), 0, 0, 0, 0, time.Local), nil
+	}
+	return now.Add(d), nil
+}
+
+// Format formats the expiry date like it's stored in the secret. Midnight
+// in the local time zone is formatted as a date only.
+func Format(t time.Time) string {
+	lt := t.In(time.Local)
+	if lt.Hour() == 0 && lt.Minute() == 0 && lt.Second() == 0 && lt.Nanosecond() == 0 {
+		return lt.Format(dateLayout)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Get returns the expiry date of the secret. It's not found if the secret has
+// no expires key.
+func Get(sec gopass.Secret) (time.Time, bool, error) {
+	value, found := sec.Get(Key)
+	if !found || strings.TrimSpace(value) == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := ParseDate(value)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	return t, true, nil
+}
+
+// IsExpired returns true if the expiry date has been reached
+func IsExpired(t, now time.Time) bool {
+	return !now.Before(t)
+}
+
+// Describe returns a short description of the expiry date relative to now,
+// e.g. "expires in 12 days" or "EXPIRED on 2025-06-30"
+func Describe(t, now time.Time) string {
+	if IsExpired(t, now) {
+		return "EXPIRED on " + Format(t)
+	}
+	switch days := int(t.Sub(now) / day); days {
+	case 0:
+		return "expires in less than a day"
+	case 1:
+		return "expires in 1 day"
+	default:
+		return fmt.Sprintf("expires in %d days", days)
+	}
+}
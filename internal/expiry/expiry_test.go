@@ -0,0 +1,83 @@
+package expiry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * day,
+		"2w":  14 * day,
+		"1y":  365 * day,
+		"36h": 36 * time.Hour,
+		" 0d": 0,
+	} {
+		d, err := ParseDuration(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, d, in)
+	}
+
+	for _, in := range []string{"", "d", "-3d", "3.5d", "soon", "-1h"} {
+		_, err := ParseDuration(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, 6, 1, 15, 30, 0, 0, time.Local)
+
+	for in, want := range map[string]time.Time{
+		"2025-06-30":           time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local),
+		"2025-06-30T12:00:00Z": time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC),
+		"90d":                  time.Date(2025, 8, 30, 0, 0, 0, 0, time.Local),
+		"12h":                  now.Add(12 * time.Hour),
+	} {
+		ts, err := Parse(in, now)
+		require.NoError(t, err, in)
+		assert.True(t, want.Equal(ts), "%s: %s != %s", in, want, ts)
+	}
+
+	_, err := Parse("next week", now)
+	assert.Error(t, err)
+	_, err = ParseDate("90d")
+	assert.Error(t, err)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "2025-06-30", Format(time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local)))
+	assert.Equal(t, "2025-06-30T12:00:00Z", Format(time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestGet(t *testing.T) {
+	sec := secrets.NewKV()
+	_, found, err := Get(sec)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, sec.Set(Key, "2025-06-30"))
+	ts, found, err := Get(sec)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "2025-06-30", Format(ts))
+
+	require.NoError(t, sec.Set(Key, "soon"))
+	_, found, err = Get(sec)
+	assert.Error(t, err)
+	assert.True(t, found)
+}
+
+func TestDescribe(t *testing.T) {
+	exp := time.Date(2025, 6, 30, 0, 0, 0, 0, time.Local)
+
+	assert.Equal(t, "EXPIRED on 2025-06-30", Describe(exp, exp))
+	assert.Equal(t, "EXPIRED on 2025-06-30", Describe(exp, exp.Add(time.Hour)))
+	assert.Equal(t, "expires in less than a day", Describe(exp, exp.Add(-time.Hour)))
+	assert.Equal(t, "expires in 1 day", Describe(exp, exp.Add(-30*time.Hour)))
+	assert.Equal(t, "expires in 12 days", Describe(exp, exp.Add(-12*day)))
+}
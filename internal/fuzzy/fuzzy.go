@@ -0,0 +1,177 @@
+// Package fuzzy implements fuzzy matching of secret names. A pattern matches
+// if all of its characters appear in the name in the same order, ignoring
+// case. Matches are scored similar to fzf, preferring consecutive characters
+// and characters at the start of a path component or word.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// bonusBoundary is given to characters at the start of the name or
+	// following a separator, e.g. the b in foo/bar
+	bonusBoundary = scoreMatch / 2
+	// bonusConsecutive is given to characters following another match
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+	// bonusFirstCharMultiplier weights the bonus of the first character
+	// of the pattern
+	bonusFirstCharMultiplier = 2
+)
+
+// Score returns the score of the name for the pattern and whether it
+// matches at all. Higher scores are better matches.
+func Score(pattern, name string) (int, bool) {
+	p := lower([]rune(pattern))
+	if len(p) < 1 {
+		return 0, true
+	}
+	n := []rune(name)
+	l := lower(n)
+
+	// find the end of the first occurrence
+	pi := 0
+	end := -1
+	for i, r := range l {
+		if r != p[pi] {
+			continue
+		}
+		pi++
+		if pi == len(p) {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, false
+	}
+
+	// walk back to find the shortest window ending there
+	pi = len(p) - 1
+	start := end
+	for i := end; i >= 0; i-- {
+		if l[i] != p[pi] {
+			continue
+		}
+		pi--
+		if pi < 0 {
+			start = i
+			break
+		}
+	}
+
+	return score(p, n, l, start, end), true
+}
+
+// score scores the match of the pattern inside the window of the name
+func score(p, n, l []rune, start, end int) int {
+	var s int
+	pi := 0
+	gap := 0
+	consecutive := false
+	for i := start; i <= end && pi < len(p); i++ {
+		if l[i] != p[pi] {
+			if gap == 0 {
+				s += scoreGapStart
+			} else {
+				s += scoreGapExtension
+			}
+			gap++
+			consecutive = false
+			continue
+		}
+
+		s += scoreMatch
+		b := bonus(n, i)
+		if pi == 0 {
+			b *= bonusFirstCharMultiplier
+		}
+		if consecutive && b < bonusConsecutive {
+			b = bonusConsecutive
+		}
+		s += b
+
+		pi++
+		gap = 0
+		consecutive = true
+	}
+	return s
+}
+
+// lower returns the lower case runes. Unlike strings.ToLower it keeps the
+// positions of the runes.
+func lower(rs []rune) []rune {
+	l := make([]rune, len(rs))
+	for i, r := range rs {
+		l[i] = unicode.ToLower(r)
+	}
+	return l
+}
+
+// bonus returns the bonus for a match at the given position
+func bonus(n []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev := n[i-1]
+	switch {
+	case strings.ContainsRune("/-_. @:", prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(n[i]):
+		// camelCase
+		return bonusBoundary
+	default:
+		return 0
+	}
+}
+
+// Rank returns the names matching the pattern, best matches first. Names
+// with the same score are ordered by length and then alphabetically.
+func Rank(pattern string, names []string) []string {
+	idx := RankIndex(pattern, names)
+	res := make([]string, 0, len(idx))
+	for _, i := range idx {
+		res = append(res, names[i])
+	}
+	return res
+}
+
+// RankIndex is like Rank but returns the indices of the matching names
+func RankIndex(pattern string, names []string) []int {
+	type match struct {
+		idx   int
+		score int
+	}
+
+	matches := make([]match, 0, len(names))
+	for i, name := range names {
+		s, ok := Score(pattern, name)
+		if !ok {
+			continue
+		}
+		matches = append(matches, match{idx: i, score: s})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		ni, nj := names[matches[i].idx], names[matches[j].idx]
+		if len(ni) != len(nj) {
+			return len(ni) < len(nj)
+		}
+		return ni < nj
+	})
+
+	res := make([]int, 0, len(matches))
+	for _, m := range matches {
+		res = append(res, m.idx)
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{pattern: "", name: "foo", match: true},
+		{pattern: "foo", name: "foo", match: true},
+		{pattern: "FOO", name: "foo", match: true},
+		{pattern: "fb", name: "foo/bar", match: true},
+		{pattern: "wgh", name: "websites/github.com", match: true},
+		{pattern: "bf", name: "foo/bar", match: false},
+		{pattern: "foox", name: "foo", match: false},
+		{pattern: "äö", name: "ÄÖ", match: true},
+	} {
+		_, match := Score(tc.pattern, tc.name)
+		assert.Equal(t, tc.match, match, "%q in %q", tc.pattern, tc.name)
+	}
+}
+
+func TestScoreOrder(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		better  string
+		worse   string
+	}{
+		// consecutive characters
+		{pattern: "git", better: "web/github", worse: "web/gxixt"},
+		// start of a path component
+		{pattern: "gh", better: "web/github/hub", worse: "web/xgxh"},
+		// shorter gaps
+		{pattern: "ab", better: "axb", worse: "axxxb"},
+		// the shortest window is used
+		{pattern: "ab", better: "ab", worse: "a/xab"},
+	} {
+		b, ok := Score(tc.pattern, tc.better)
+		assert.True(t, ok)
+		w, ok := Score(tc.pattern, tc.worse)
+		assert.True(t, ok)
+		assert.Greater(t, b, w, "%q: %q (%d) should be better than %q (%d)", tc.pattern, tc.better, b, tc.worse, w)
+	}
+}
+
+func TestRank(t *testing.T) {
+	names := []string{
+		"misc/gxhxub",
+		"websites/github.com/alice",
+		"websites/gitlab.com/alice",
+		"websites/github.com/bob",
+		"work/github",
+	}
+
+	assert.Equal(t, []string{
+		"work/github",
+		"websites/github.com/bob",
+		"websites/github.com/alice",
+		"misc/gxhxub",
+	}, Rank("ghub", names))
+	assert.Equal(t, []string{}, Rank("nope", names))
+}
+
+func TestRankIndex(t *testing.T) {
+	assert.Equal(t, []int{2, 0}, RankIndex("fo", []string{"foo/bar", "bar", "foo"}))
+}
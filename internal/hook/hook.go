@@ -0,0 +1,97 @@
+// Package hook runs the user's executables before and after changes to a
+// store, e.g. to validate secrets or to notify other systems. Hooks are only
+// looked up in the hooks folder next to the gopass config file and never
+// inside of a store, so nothing pushed to a shared store is ever executed.
+// They are disabled unless core.hooks is set.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// PreWrite runs before a secret is written. It can abort the write.
+	PreWrite = "pre-write"
+	// PostWrite runs after a secret was written
+	PostWrite = "post-write"
+	// PreRemove runs before a secret or a folder is removed. It can abort
+	// the removal.
+	PreRemove = "pre-remove"
+	// PostSync runs after a mount was synced with its remote
+	PostSync = "post-sync"
+)
+
+// Dir returns the folder the hooks are looked up in
+func Dir() string {
+	return filepath.Join(config.Directory(), "hooks")
+}
+
+// Run executes the named hook, if hooks are enabled and it exists, with the
+// hook name, the mount and the secret as arguments and the store directory
+// as the working directory. The output of the hook is written to stderr. If
+// the hook exits non-zero the returned error contains what it wrote to
+// stderr.
+func Run(ctx context.Context, name, mount, secret, dir string) error {
+	if !ctxutil.IsHooks(ctx) {
+		return nil
+	}
+
+	fn := filepath.Join(Dir(), name)
+	fi, err := os.Stat(fn)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			debug.Log("failed to stat hook %s: %s", fn, err)
+		}
+		return nil
+	}
+	if !fi.Mode().IsRegular() {
+		debug.Log("ignoring hook %s: not a regular file", fn)
+		return nil
+	}
+	if runtime.GOOS != "windows" {
+		if fi.Mode().Perm()&0111 == 0 {
+			debug.Log("ignoring hook %s: not executable", fn)
+			return nil
+		}
+		if fi.Mode().Perm()&0002 != 0 {
+			return fmt.Errorf("refusing to run the %s hook %s: it is writable by everyone", name, fn)
+		}
+	}
+
+	if ctxutil.IsDryRun(ctx) {
+		out.Printf(ctx, "Would run the %s hook %s", name, fn)
+		return nil
+	}
+
+	ctx, cancel := ctxutil.WithNetworkDeadline(ctx)
+	defer cancel()
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, fn, name, mount, secret)
+	cmd.Dir = dir
+	cmd.Stdout = out.Stderr
+	cmd.Stderr = stderr
+
+	debug.Log("running hook %s %s %s %s in %s", fn, name, mount, secret, dir)
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s hook failed: %w: %s", name, ctxutil.ExecError(ctx, err), msg)
+		}
+		return fmt.Errorf("%s hook failed: %w", name, ctxutil.ExecError(ctx, err))
+	}
+	_, _ = out.Stderr.Write(stderr.Bytes())
+	return nil
+}
@@ -0,0 +1,94 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHook(t *testing.T, name, script string, mode os.FileMode) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(Dir(), 0700))
+	fn := filepath.Join(Dir(), name)
+	require.NoError(t, os.WriteFile(fn, []byte("#!/bin/sh\n"+script), mode))
+	require.NoError(t, os.Chmod(fn, mode))
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+
+	td := t.TempDir()
+	t.Setenv("GOPASS_CONFIG", filepath.Join(td, "config", "config.yml"))
+	assert.Equal(t, filepath.Join(td, "config", "hooks"), Dir())
+
+	storeDir := filepath.Join(td, "store")
+	require.NoError(t, os.Mkdir(storeDir, 0700))
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+	writeHook(t, PreWrite, `echo "$@ in $(pwd)" > args; echo done`, 0700)
+
+	t.Run("disabled", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, Run(ctx, PreWrite, "sub", "foo/bar", storeDir))
+		assert.NoFileExists(t, filepath.Join(storeDir, "args"))
+	})
+
+	ctx = ctxutil.WithHooks(ctx, true)
+
+	t.Run("missing hook", func(t *testing.T) {
+		assert.NoError(t, Run(ctx, PostWrite, "sub", "foo/bar", storeDir))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, Run(ctxutil.WithDryRun(ctx, true), PreWrite, "sub", "foo/bar", storeDir))
+		assert.NoFileExists(t, filepath.Join(storeDir, "args"))
+	})
+
+	t.Run("arguments and working directory", func(t *testing.T) {
+		defer buf.Reset()
+		require.NoError(t, Run(ctx, PreWrite, "sub", "foo/bar", storeDir))
+		assert.Equal(t, "done\n", buf.String())
+
+		args, err := os.ReadFile(filepath.Join(storeDir, "args"))
+		require.NoError(t, err)
+		assert.Equal(t, "pre-write sub foo/bar in "+storeDir+"\n", string(args))
+	})
+
+	t.Run("failing hook", func(t *testing.T) {
+		defer buf.Reset()
+		writeHook(t, PreRemove, "echo 'foo/bar is protected' >&2\nexit 1\n", 0700)
+		err := Run(ctx, PreRemove, "", "foo/bar", storeDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-remove hook failed")
+		assert.Contains(t, err.Error(), "foo/bar is protected")
+	})
+
+	t.Run("not executable", func(t *testing.T) {
+		writeHook(t, PostSync, "exit 1\n", 0600)
+		assert.NoError(t, Run(ctx, PostSync, "", "", storeDir))
+	})
+
+	t.Run("writable by everyone", func(t *testing.T) {
+		writeHook(t, PostSync, "exit 0\n", 0777)
+		assert.Error(t, Run(ctx, PostSync, "", "", storeDir))
+	})
+}
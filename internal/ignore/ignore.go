@@ -0,0 +1,161 @@
+// Package ignore matches the files of a store against ignore rules in the
+// gitignore syntax. Ignored files are not secrets, e.g. a README or CI config
+// kept in the repo. They are left alone by the storage but not listed.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// File is the name of the ignore file at the root of a store
+const File = ".gopass-ignore"
+
+// Defaults are always applied before the rules of the ignore file. The
+// templates live in .gopass/.
+var Defaults = []string{
+	".git*",
+	File,
+	".public-keys/",
+	".gpg-keys/",
+	".gopass/",
+}
+
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+	// anchored rules match the whole path, others any path component
+	anchored bool
+}
+
+// Matcher tells if a file is ignored. The zero value ignores nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// New returns a matcher for the defaults and the rules in buf, i.e. the
+// content of an ignore file. Invalid rules are skipped.
+func New(buf []byte) *Matcher {
+	m := &Matcher{}
+	for _, p := range Defaults {
+		m.add(p)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(buf))
+	for sc.Scan() {
+		m.add(sc.Text())
+	}
+	return m
+}
+
+func (m *Matcher) add(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	r := rule{}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// a slash at the start or in the middle anchors the rule at the root
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		debug.Log("invalid ignore rule %q: %s", line, err)
+		return
+	}
+	r.re = re
+	m.rules = append(m.rules, r)
+}
+
+// Match returns true if the file at name, relative to the root of the store
+// and with its extension, is ignored. A file is also ignored if one of its
+// folders is. The last matching rule wins.
+func (m *Matcher) Match(name string) bool {
+	if m == nil {
+		return false
+	}
+	name = strings.Trim(name, "/")
+	parts := strings.Split(name, "/")
+
+	ignored := false
+	for _, r := range m.rules {
+		if r.matches(parts) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// matches checks the file and all of its folders
+func (r rule) matches(parts []string) bool {
+	for i := range parts {
+		if r.dirOnly && i == len(parts)-1 {
+			break
+		}
+		candidate := parts[i]
+		if r.anchored {
+			candidate = strings.Join(parts[:i+1], "/")
+		}
+		if r.re.MatchString(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp translates a gitignore glob to a regular expression. * and ?
+// don't match a slash, ** matches any number of folders.
+func globToRegexp(glob string) string {
+	sb := &strings.Builder{}
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
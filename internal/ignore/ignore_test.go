@@ -0,0 +1,73 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	m := New(nil)
+	for _, name := range []string{
+		".gitattributes",
+		".gitlab-ci.yml",
+		"team/.gitignore",
+		".gopass-ignore",
+		".public-keys/0xDEADBEEF",
+		".gopass/templates/web.gpg",
+	} {
+		assert.True(t, m.Match(name), name)
+	}
+	for _, name := range []string{
+		"web/github.gpg",
+		"git.gpg",
+		"public-keys.gpg",
+	} {
+		assert.False(t, m.Match(name), name)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := New([]byte(`# not secrets
+README.md
+*.sh
+/tools/
+docs/**/*.gpg
+!docs/keep/**
+ci/*.y*ml
+bin/[!a-c]*
+
+
+notes\*
+# an unclosed bracket is literal
+[invalid
+`))
+
+	for name, ignored := range map[string]bool{
+		"README.md":              true,
+		"team/README.md":         true,
+		"deploy.sh":              true,
+		"scripts/deploy.sh":      true,
+		"tools/rotate.gpg":       true,
+		"tools":                  false,
+		"team/tools/rotate.gpg":  false,
+		"docs/a.gpg":             true,
+		"docs/a/b/c.gpg":         true,
+		"docs/keep/c.gpg":        false,
+		"ci/build.yaml":          true,
+		"ci/build.yml":           true,
+		"ci/sub/build.yml":       false,
+		"bin/deploy":             true,
+		"bin/build":              false,
+		"notes*":                 true,
+		"notes.gpg":              false,
+		"web/github.gpg":         false,
+		"[invalid":               true,
+		"web/README.md/leak.gpg": true,
+	} {
+		assert.Equal(t, ignored, m.Match(name), name)
+	}
+
+	var zero *Matcher
+	assert.False(t, zero.Match("README.md"))
+}
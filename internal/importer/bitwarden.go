@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// bwExport is the unencrypted JSON export of a Bitwarden vault
+type bwExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bwItem `json:"items"`
+}
+
+type bwItem struct {
+	FolderID string `json:"folderId"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	Fields   []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	// cards and identities are mapped key by key
+	Card     map[string]interface{} `json:"card"`
+	Identity map[string]interface{} `json:"identity"`
+}
+
+// Bitwarden reads an unencrypted JSON export of a Bitwarden vault. Folders
+// are nested by slashes in their names, just like in Bitwarden.
+func Bitwarden(r io.Reader) ([]Entry, error) {
+	var ex bwExport
+	if err := json.NewDecoder(r).Decode(&ex); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden JSON: %w", err)
+	}
+	if ex.Encrypted {
+		return nil, fmt.Errorf("encrypted Bitwarden exports are not supported, export the vault as unencrypted JSON")
+	}
+
+	folders := make(map[string][]string, len(ex.Folders))
+	for _, f := range ex.Folders {
+		folders[f.ID] = strings.Split(f.Name, "/")
+	}
+
+	entries := make([]Entry, 0, len(ex.Items))
+	for _, item := range ex.Items {
+		entry := Entry{
+			Name:  cleanName(folders[item.FolderID], item.Name),
+			Notes: strings.TrimSpace(item.Notes),
+		}
+		if l := item.Login; l != nil {
+			entry.Password = strings.TrimRight(l.Password, "\r\n")
+			entry.add("username", l.Username)
+			for _, u := range l.URIs {
+				entry.add("url", u.URI)
+			}
+			entry.add("totp", l.TOTP)
+		}
+		bwAddMap(&entry, item.Card)
+		bwAddMap(&entry, item.Identity)
+		for _, f := range item.Fields {
+			entry.add(f.Name, f.Value)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// bwAddMap adds the values of a card or an identity sorted by key
+func bwAddMap(e *Entry, m map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if m[k] == nil {
+			continue
+		}
+		e.add(k, fmt.Sprintf("%v", m[k]))
+	}
+}
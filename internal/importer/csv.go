@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Columns maps the keys of a secret to the columns of a CSV file. A column is
+// either a number, counted from 1, or the name of a column in the header.
+type Columns map[string]string
+
+// Row is a row of a CSV file with the values of the mapped columns
+type Row struct {
+	// Number is the number of the row in the file, counted from 1 and
+	// including the header
+	Number int
+	// Values are the values of the mapped columns by key
+	Values map[string]string
+	// Err is set if the row can not be mapped, e.g. because it is too short
+	Err error
+}
+
+// ParseColumns parses a list of key=column pairs, e.g.
+// path=1,password=3,user=username
+func ParseColumns(spec string) (Columns, error) {
+	cols := Columns{}
+	for _, p := range strings.Split(spec, ",") {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid column mapping %q, use key=column", p)
+		}
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		col := strings.TrimSpace(kv[1])
+		if key == "" || col == "" {
+			return nil, fmt.Errorf("invalid column mapping %q, use key=column", p)
+		}
+		if _, found := cols[key]; found {
+			return nil, fmt.Errorf("key %q is mapped twice", key)
+		}
+		if n, err := strconv.Atoi(col); err == nil && n < 1 {
+			return nil, fmt.Errorf("invalid column %d for %q, columns are counted from 1", n, key)
+		}
+		cols[key] = col
+	}
+	if len(cols) < 1 {
+		return nil, fmt.Errorf("no columns mapped")
+	}
+	return cols, nil
+}
+
+// NeedsHeader returns true if a column is given by name
+func (c Columns) NeedsHeader() bool {
+	for _, col := range c {
+		if _, err := strconv.Atoi(col); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CSV reads all rows of a CSV file, separated by comma, e.g. a tab for TSV.
+// The first row is skipped as the header if it is needed to find the columns
+// or if header is set. Quoted fields can span several lines and a leading
+// UTF-8 byte order mark is removed.
+func CSV(r io.Reader, comma rune, cols Columns, header bool) ([]Row, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		_, _ = br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+
+	// the index of every mapped column, counted from 0
+	index := make(map[string]int, len(cols))
+	for key, col := range cols {
+		if n, err := strconv.Atoi(col); err == nil {
+			index[key] = n - 1
+		}
+	}
+
+	number := 0
+	if header || cols.NeedsHeader() {
+		rec, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		number++
+		names := make(map[string]int, len(rec))
+		for i, h := range rec {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if _, found := names[h]; !found {
+				names[h] = i
+			}
+		}
+		for key, col := range cols {
+			if _, found := index[key]; found {
+				continue
+			}
+			i, found := names[strings.ToLower(col)]
+			if !found {
+				return nil, fmt.Errorf("no column %q in the CSV header", col)
+			}
+			index[key] = i
+		}
+	}
+
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var rows []Row
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		number++
+
+		row := Row{Number: number, Values: make(map[string]string, len(index))}
+		for _, key := range keys {
+			i := index[key]
+			if i >= len(rec) {
+				row.Err = fmt.Errorf("no column %d for %q, the row has %d columns", i+1, key, len(rec))
+				break
+			}
+			row.Values[key] = rec[i]
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Entry converts the values of the row to an entry. The path is the name of
+// the entry, the password and the notes become the same parts of the secret
+// and all other values become keys.
+func (r Row) Entry(name string) Entry {
+	e := Entry{Name: name}
+	keys := make([]string, 0, len(r.Values))
+	for key := range r.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v := r.Values[key]
+		switch key {
+		case "path":
+		case "password":
+			e.Password = strings.TrimRight(v, "\r\n")
+		case "notes":
+			if notes := strings.TrimSpace(v); notes != "" && e.Notes != "" {
+				e.Notes = notes + "\n\n" + e.Notes
+			} else if notes != "" {
+				e.Notes = notes
+			}
+		default:
+			e.add(key, v)
+		}
+	}
+	return e
+}
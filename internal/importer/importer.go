@@ -0,0 +1,113 @@
+// Package importer reads the exports of other password managers, e.g. pass,
+// KeePass, Bitwarden and 1Password, and maps their entries to secrets.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Entry is a single secret read from an export
+type Entry struct {
+	// Name is the slash separated path of the secret
+	Name string
+	// Password becomes the first line of the secret
+	Password string
+	// Fields are key-value pairs, e.g. the username and the url
+	Fields []Field
+	// Notes become the body of the secret
+	Notes string
+	// Raw is the verbatim content of the secret. If set all other fields
+	// besides the name are ignored.
+	Raw []byte
+	// Binary is the content of an attachment. If set the entry becomes a
+	// binary secret.
+	Binary []byte
+}
+
+// Field is a key-value pair of an entry
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Decrypter decrypts the files of a pass store
+type Decrypter interface {
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// Formats returns the names of all supported formats
+func Formats() []string {
+	return []string{"pass", "keepassxml", "bitwarden-json", "1password-csv"}
+}
+
+// Read reads all entries of the export at path. The path is a directory for
+// pass and a file for all other formats. The decrypter is only used for pass.
+func Read(ctx context.Context, format, path string, dec Decrypter) ([]Entry, error) {
+	switch format {
+	case "pass":
+		return Pass(ctx, path, dec)
+	case "keepassxml":
+		return readFile(path, KeePassXML)
+	case "bitwarden-json":
+		return readFile(path, Bitwarden)
+	case "1password-csv":
+		return readFile(path, OnePassword)
+	default:
+		return nil, fmt.Errorf("unknown format %q. Use one of %s", format, strings.Join(Formats(), ", "))
+	}
+}
+
+func readFile(path string, parse func(io.Reader) ([]Entry, error)) ([]Entry, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	return parse(fh)
+}
+
+// add adds a field to the entry. Empty values are skipped and values with
+// several lines are appended to the notes, since key-value pairs can only
+// span a single line.
+func (e *Entry) add(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if strings.Contains(value, "\n") {
+		if e.Notes != "" {
+			e.Notes += "\n\n"
+		}
+		e.Notes += "[" + key + "]\n" + value
+		return
+	}
+	e.Fields = append(e.Fields, Field{Key: strings.ToLower(key), Value: value})
+}
+
+// cleanName joins the folders and the title of an entry to the name of a
+// secret. Slashes inside the title do not create folders.
+func cleanName(folders []string, title string) string {
+	parts := make([]string, 0, len(folders)+1)
+	for _, f := range folders {
+		if f = cleanPart(f); f != "" {
+			parts = append(parts, f)
+		}
+	}
+	t := cleanPart(title)
+	if t == "" {
+		t = "untitled"
+	}
+	return strings.Join(append(parts, t), "/")
+}
+
+// cleanPart removes characters that can not be used in a single component
+// of the name of a secret
+func cleanPart(p string) string {
+	p = strings.NewReplacer("/", "-", "\\", "-", "\n", " ", "\r", " ").Replace(p)
+	return strings.TrimLeft(strings.TrimSpace(p), ".")
+}
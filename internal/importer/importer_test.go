@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDecrypter strips the ENC: prefix of the fixtures
+type fakeDecrypter struct{}
+
+func (fakeDecrypter) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	s := string(ciphertext)
+	if !strings.HasPrefix(s, "ENC:") {
+		return nil, fmt.Errorf("not encrypted")
+	}
+	return []byte(strings.TrimPrefix(s, "ENC:")), nil
+}
+
+func TestPass(t *testing.T) {
+	ctx := context.Background()
+
+	entries, err := Read(ctx, "pass", "testdata/pass", fakeDecrypter{})
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Name: "db", Raw: []byte("hunter2\n")},
+		{Name: "web/example.org", Raw: []byte("s3cr3t\nuser: alice\n")},
+	}, entries)
+
+	_, err = Read(ctx, "pass", "testdata/pass", nil)
+	assert.Error(t, err)
+	_, err = Read(ctx, "pass", "testdata/bitwarden.json", fakeDecrypter{})
+	assert.Error(t, err)
+}
+
+func TestKeePassXML(t *testing.T) {
+	entries, err := Read(context.Background(), "keepassxml", "testdata/keepass.xml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{
+			Name:     "example.org",
+			Password: "s3cr3t",
+			Fields: []Field{
+				{Key: "username", Value: "alice"},
+				{Key: "url", Value: "https://example.org"},
+				{Key: "totp", Value: "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP"},
+				{Key: "pin", Value: "1234"},
+			},
+			Notes: "first line\nsecond line",
+		},
+		{Name: "example.org/key.txt", Binary: []byte("hello attachment\n")},
+		{
+			Name:     "Work/SSH/server-prod",
+			Password: "hunter2",
+			Notes:    "[Private Key]\n-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		},
+		{Name: "Work/SSH/server-prod/inline.bin", Binary: []byte("inline data")},
+	}, entries)
+
+	_, err = KeePassXML(strings.NewReader("<KeePassFile><Root>"))
+	assert.Error(t, err)
+}
+
+func TestBitwarden(t *testing.T) {
+	entries, err := Read(context.Background(), "bitwarden-json", "testdata/bitwarden.json", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{
+			Name:     "example.org",
+			Password: "s3cr3t",
+			Fields: []Field{
+				{Key: "username", Value: "alice"},
+				{Key: "url", Value: "https://example.org"},
+				{Key: "url", Value: "https://login.example.org"},
+				{Key: "totp", Value: "JBSWY3DPEHPK3PXP"},
+				{Key: "pin", Value: "1234"},
+			},
+			Notes: "some notes",
+		},
+		{
+			Name:     "Work/Servers/db",
+			Password: "hunter2",
+			Fields:   []Field{{Key: "username", Value: "root"}},
+		},
+		{
+			Name: "Work/Servers/Visa",
+			Fields: []Field{
+				{Key: "brand", Value: "Visa"},
+				{Key: "cardholdername", Value: "Alice"},
+				{Key: "expmonth", Value: "12"},
+				{Key: "expyear", Value: "2030"},
+				{Key: "number", Value: "4111111111111111"},
+			},
+		},
+		{Name: "note", Notes: "just a note"},
+	}, entries)
+
+	_, err = Bitwarden(strings.NewReader(`{"encrypted": true, "items": []}`))
+	assert.Error(t, err)
+}
+
+func TestOnePassword(t *testing.T) {
+	entries, err := Read(context.Background(), "1password-csv", "testdata/1password.csv", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{
+			Name:     "example.org",
+			Password: "s3cr3t",
+			Fields: []Field{
+				{Key: "url", Value: "https://example.org"},
+				{Key: "username", Value: "alice"},
+				{Key: "totp", Value: "otpauth://totp/example?secret=JBSWY3DPEHPK3PXP"},
+				{Key: "tags", Value: "web"},
+			},
+			Notes: "first line\nsecond line",
+		},
+		{
+			Name:     "no-slashes",
+			Password: "hunter2",
+			Fields:   []Field{{Key: "username", Value: "bob"}},
+		},
+	}, entries)
+
+	entries, err = OnePassword(strings.NewReader("vault,title,password\nPrivate,foo,bar\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Name: "Private/foo", Password: "bar"}}, entries)
+}
+
+func TestParseColumns(t *testing.T) {
+	cols, err := ParseColumns("path=1, Password=3,user=Login Name,")
+	require.NoError(t, err)
+	assert.Equal(t, Columns{"path": "1", "password": "3", "user": "Login Name"}, cols)
+	assert.True(t, cols.NeedsHeader())
+
+	cols, err = ParseColumns("path=1,password=2")
+	require.NoError(t, err)
+	assert.False(t, cols.NeedsHeader())
+
+	for _, spec := range []string{"", "path", "path=", "path=1,path=2", "path=0"} {
+		_, err := ParseColumns(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	in := "\xef\xbb\xbfURL,Login,Password\n" +
+		"https://example.org,alice,\"s3,cr\"\"t\"\n" +
+		"https://example.com,bob,\"multi\nline\"\n" +
+		"short\n"
+
+	cols := Columns{"url": "url", "user": "2", "password": "Password"}
+	rows, err := CSV(strings.NewReader(in), ',', cols, false)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, Row{Number: 2, Values: map[string]string{"url": "https://example.org", "user": "alice", "password": "s3,cr\"t"}}, rows[0])
+	assert.Equal(t, "multi\nline", rows[1].Values["password"])
+	assert.Equal(t, 3, rows[1].Number)
+	assert.Error(t, rows[2].Err)
+
+	rows, err = CSV(strings.NewReader("a\tb\n1\t2\n"), '\t', Columns{"path": "2"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []Row{{Number: 2, Values: map[string]string{"path": "2"}}}, rows)
+
+	_, err = CSV(strings.NewReader(in), ',', Columns{"path": "title"}, false)
+	assert.Error(t, err)
+
+	e := Row{Values: map[string]string{"path": "web/foo", "password": "bar", "user": "alice", "notes": "hello"}}.Entry("web/foo")
+	assert.Equal(t, Entry{Name: "web/foo", Password: "bar", Fields: []Field{{Key: "user", Value: "alice"}}, Notes: "hello"}, e)
+}
+
+func TestVault(t *testing.T) {
+	data := map[string]interface{}{
+		"password": "s3cret",
+		"notes":    "rotated yearly",
+		"User":     "admin",
+		"port":     json.Number("5432"),
+		"tls":      true,
+		"db": map[string]interface{}{
+			"host":    "db1",
+			"replica": map[string]interface{}{"host": "db2"},
+		},
+		"hosts": []interface{}{"a", "b"},
+		"users": []interface{}{map[string]interface{}{"name": "bob"}},
+		"cert":  "-----BEGIN-----\nabc\n-----END-----",
+		"empty": nil,
+	}
+	assert.Equal(t, Entry{
+		Name:     "team/app/db",
+		Password: "s3cret",
+		Fields: []Field{
+			{Key: "user", Value: "admin"},
+			{Key: "db.host", Value: "db1"},
+			{Key: "db.replica.host", Value: "db2"},
+			{Key: "hosts", Value: "a"},
+			{Key: "hosts", Value: "b"},
+			{Key: "port", Value: "5432"},
+			{Key: "tls", Value: "true"},
+			{Key: "users.0.name", Value: "bob"},
+		},
+		Notes: "rotated yearly\n\n[cert]\n-----BEGIN-----\nabc\n-----END-----",
+	}, Vault("/team/app/db", data))
+}
+
+func TestReadUnknownFormat(t *testing.T) {
+	_, err := Read(context.Background(), "lastpass", "testdata/1password.csv", nil)
+	assert.Error(t, err)
+}
+
+func TestCleanName(t *testing.T) {
+	for _, tc := range []struct {
+		folders []string
+		title   string
+		name    string
+	}{
+		{title: "foo", name: "foo"},
+		{folders: []string{"a", "b"}, title: "foo", name: "a/b/foo"},
+		{folders: []string{"", " a "}, title: " foo/bar ", name: "a/foo-bar"},
+		{title: "", name: "untitled"},
+		{title: "..", name: "untitled"},
+		{title: ".hidden", name: "hidden"},
+		{title: "two\nlines", name: "two lines"},
+	} {
+		assert.Equal(t, tc.name, cleanName(tc.folders, tc.title))
+	}
+}
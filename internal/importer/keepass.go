@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// kpFile is the XML export of a KeePass 2 or KeePassXC database
+type kpFile struct {
+	Meta struct {
+		RecycleBinUUID string     `xml:"RecycleBinUUID"`
+		Binaries       []kpBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []kpGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+// kpBinary is either a binary in Meta or the binary of an entry, which
+// references the former or contains the data itself
+type kpBinary struct {
+	ID         string `xml:"ID,attr"`
+	Ref        string `xml:"Ref,attr"`
+	Compressed string `xml:"Compressed,attr"`
+	Data       string `xml:",chardata"`
+}
+
+type kpGroup struct {
+	UUID    string    `xml:"UUID"`
+	Name    string    `xml:"Name"`
+	Groups  []kpGroup `xml:"Group"`
+	Entries []kpEntry `xml:"Entry"`
+}
+
+// kpEntry is a single entry. Older versions below History are not mapped
+// and thus not imported.
+type kpEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string   `xml:"Key"`
+		Value kpBinary `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// KeePassXML reads an unencrypted XML export of a KeePass 2 or KeePassXC
+// database. Groups become folders, the name of the root group and the
+// recycle bin are skipped. Attachments become binary secrets below the
+// entry.
+func KeePassXML(r io.Reader) ([]Entry, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var f kpFile
+	if err := xml.Unmarshal(buf, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse KeePass XML: %w", err)
+	}
+
+	binaries := make(map[string][]byte, len(f.Meta.Binaries))
+	for _, b := range f.Meta.Binaries {
+		data, err := b.decode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode binary %s: %w", b.ID, err)
+		}
+		binaries[b.ID] = data
+	}
+
+	var entries []Entry
+	for _, g := range f.Root.Groups {
+		// the root group is named after the database
+		entries, err = kpWalk(g, nil, f.Meta.RecycleBinUUID, binaries, entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func kpWalk(g kpGroup, folders []string, recycleBin string, binaries map[string][]byte, entries []Entry) ([]Entry, error) {
+	if recycleBin != "" && g.UUID == recycleBin {
+		return entries, nil
+	}
+
+	for _, e := range g.Entries {
+		entry := kpConvert(e, folders)
+		entries = append(entries, entry)
+
+		for _, b := range e.Binaries {
+			data, found := binaries[b.Value.Ref]
+			if !found {
+				var err error
+				if data, err = b.Value.decode(); err != nil {
+					return nil, fmt.Errorf("failed to decode attachment %s of %s: %w", b.Key, entry.Name, err)
+				}
+			}
+			entries = append(entries, Entry{
+				Name:   entry.Name + "/" + cleanName(nil, b.Key),
+				Binary: data,
+			})
+		}
+	}
+
+	for _, sg := range g.Groups {
+		var err error
+		entries, err = kpWalk(sg, append(folders[:len(folders):len(folders)], sg.Name), recycleBin, binaries, entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func kpConvert(e kpEntry, folders []string) Entry {
+	var title string
+	entry := Entry{}
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title":
+			title = s.Value
+		case "Password":
+			entry.Password = strings.TrimRight(s.Value, "\r\n")
+		case "Notes":
+			entry.Notes = strings.TrimSpace(s.Value)
+		}
+	}
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title", "Password", "Notes":
+		case "UserName":
+			entry.add("username", s.Value)
+		case "URL":
+			entry.add("url", s.Value)
+		case "otp":
+			entry.add("totp", s.Value)
+		default:
+			entry.add(s.Key, s.Value)
+		}
+	}
+	entry.Name = cleanName(folders, title)
+	return entry
+}
+
+// decode decodes the base64 encoded and possibly gzip compressed data
+func (b kpBinary) decode() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b.Data))
+	if err != nil {
+		return nil, err
+	}
+	if compressed, _ := strconv.ParseBool(b.Compressed); !compressed {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OnePassword reads a CSV export of 1Password. The columns are identified by
+// the header, so exports of 1Password 7 and 8 work. If there is a vault
+// column the entries are imported into a folder per vault. Columns without
+// a special meaning become keys of the secrets.
+func OnePassword(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, h := range header {
+		h = strings.TrimPrefix(h, "\ufeff")
+		header[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+
+	var entries []Entry
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		var title string
+		var folders []string
+		entry := Entry{}
+		for i, v := range rec {
+			if i >= len(header) {
+				break
+			}
+			switch header[i] {
+			case "title", "name":
+				title = v
+			case "vault":
+				folders = []string{v}
+			case "password", "login password":
+				entry.Password = strings.TrimRight(v, "\r\n")
+			case "notes", "notesplain":
+				// keep fields with several lines that were added before
+				if notes := strings.TrimSpace(v); notes != "" && entry.Notes != "" {
+					entry.Notes = notes + "\n\n" + entry.Notes
+				} else if notes != "" {
+					entry.Notes = notes
+				}
+			case "username", "login username":
+				entry.add("username", v)
+			case "url", "urls", "website", "login url":
+				entry.add("url", v)
+			case "otpauth", "one-time password":
+				entry.add("totp", v)
+			case "favorite", "archived", "uuid", "":
+			default:
+				entry.add(header[i], v)
+			}
+		}
+		entry.Name = cleanName(folders, title)
+		entries = append(entries, entry)
+	}
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Pass reads the secrets of a pass store. Every .gpg file is decrypted and
+// its content is imported as is, so it is re-encrypted for the recipients of
+// the gopass store. Hidden files and folders, e.g. .git and .gpg-id, are
+// skipped.
+func Pass(ctx context.Context, dir string, dec Decrypter) ([]Entry, error) {
+	if dec == nil {
+		return nil, fmt.Errorf("no crypto backend to decrypt %s", dir)
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var entries []Entry
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".gpg") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content, err := dec.Decrypt(ctx, buf)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", rel, err)
+		}
+
+		entries = append(entries, Entry{
+			Name: strings.TrimSuffix(filepath.ToSlash(rel), ".gpg"),
+			Raw:  content,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Vault converts the data of a HashiCorp Vault KV secret to an entry. The
+// password key becomes the password and the notes key the notes. Nested maps
+// are flattened to dotted keys, e.g. db.host, matching the keys of YAML
+// secrets. Lists of values become repeated keys.
+func Vault(name string, data map[string]interface{}) Entry {
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	e := Entry{Name: cleanName(parts[:len(parts)-1], parts[len(parts)-1])}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// the notes go first, multi-line values of other keys are appended
+	if s, ok := data["notes"].(string); ok {
+		e.Notes = strings.TrimSpace(s)
+	}
+	for _, k := range keys {
+		v := data[k]
+		if k == "notes" && e.Notes != "" {
+			continue
+		}
+		if s, ok := v.(string); ok && k == "password" && !strings.Contains(s, "\n") {
+			e.Password = s
+			continue
+		}
+		e.flatten(k, v)
+	}
+	return e
+}
+
+// flatten adds the value to the entry, nested maps with dotted keys. Lists of
+// lists or maps get the index as key.
+func (e *Entry) flatten(key string, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			e.flatten(key+"."+k, v[k])
+		}
+	case []interface{}:
+		for i, iv := range v {
+			switch iv.(type) {
+			case map[string]interface{}, []interface{}:
+				e.flatten(key+"."+strconv.Itoa(i), iv)
+			default:
+				e.flatten(key, iv)
+			}
+		}
+	case json.Number:
+		e.add(key, v.String())
+	default:
+		e.add(key, fmt.Sprintf("%v", v))
+	}
+}
@@ -0,0 +1,100 @@
+// Package interrupt is the only handler of SIGINT and SIGTERM in gopass. The
+// first signal cancels the context of the command, which stops the running
+// gpg and git processes and lets gopass finish with the stores left
+// consistent. Another one exits right away.
+//
+// The REPL and the commands run by gopass redirect the signals while they
+// run, then the signals neither cancel the context nor exit.
+package interrupt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+var (
+	mu        sync.Mutex
+	cancel    context.CancelFunc
+	redirects []func(os.Signal)
+	received  int
+)
+
+// exit is called on the second signal. Tests replace it.
+var exit = func() {
+	os.Exit(130)
+}
+
+// Notify returns a copy of the context that is canceled by the first signal.
+// Call stop once the command has finished.
+func Notify(ctx context.Context) (context.Context, func()) {
+	ctx, c := context.WithCancel(ctx)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	mu.Lock()
+	cancel = c
+	received = 0
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				handle(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		close(done)
+		c()
+	}
+}
+
+// Redirect passes the signals to fn until restore is called, instead of
+// canceling the context or exiting. Redirects nest, the latest one gets the
+// signals. They must be restored in the reverse order.
+func Redirect(fn func(os.Signal)) (restore func()) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	redirects = append(redirects, fn)
+	n := len(redirects)
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		redirects = redirects[:n-1]
+	}
+}
+
+func handle(sig os.Signal) {
+	mu.Lock()
+	if n := len(redirects); n > 0 {
+		fn := redirects[n-1]
+		mu.Unlock()
+		fn(sig)
+		return
+	}
+	received++
+	first := received == 1
+	c := cancel
+	mu.Unlock()
+
+	if first {
+		debug.Log("canceling on %s", sig)
+		c()
+		return
+	}
+	debug.Log("exiting on %s", sig)
+	exit()
+}
@@ -0,0 +1,56 @@
+package interrupt
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	exited := make(chan struct{}, 1)
+	exit = func() { exited <- struct{}{} }
+	defer func() {
+		exit = func() { os.Exit(130) }
+	}()
+
+	ctx, stop := Notify(context.Background())
+	defer stop()
+
+	// a redirect gets the signals instead
+	got := make(chan os.Signal, 2)
+	restore := Redirect(func(sig os.Signal) { got <- sig })
+	handle(syscall.SIGTERM)
+	handle(os.Interrupt)
+	require.Equal(t, syscall.SIGTERM, <-got)
+	require.Equal(t, os.Interrupt, <-got)
+	assert.NoError(t, ctx.Err())
+	assert.Len(t, exited, 0)
+	restore()
+
+	handle(os.Interrupt)
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not canceled")
+	}
+	assert.Len(t, exited, 0)
+
+	handle(os.Interrupt)
+	assert.Len(t, exited, 1)
+}
+
+func TestRedirectNested(t *testing.T) {
+	var calls []string
+	outer := Redirect(func(os.Signal) { calls = append(calls, "outer") })
+	inner := Redirect(func(os.Signal) { calls = append(calls, "inner") })
+	handle(os.Interrupt)
+	inner()
+	handle(os.Interrupt)
+	outer()
+	assert.Equal(t, []string{"inner", "outer"}, calls)
+}
@@ -0,0 +1,248 @@
+// Package jsonapi implements a native messaging host for browser extensions,
+// e.g. gopassbridge. Messages are JSON objects prefixed by their length, see
+// https://developer.mozilla.org/en-US/docs/Mozilla/Add-ons/WebExtensions/Native_messaging
+package jsonapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+)
+
+// defaultLength is the length of generated passwords if the request does
+// not specify one
+const defaultLength = 24
+
+// usernameKeys are the keys holding the username of a secret, in order of
+// precedence
+var usernameKeys = []string{"user", "username", "login"}
+
+type storer interface {
+	List(ctx context.Context, maxDepth int) ([]string, error)
+	Get(ctx context.Context, name string) (gopass.Secret, error)
+	Set(ctx context.Context, name string, sec gopass.Byter) error
+	Exists(ctx context.Context, name string) bool
+}
+
+// Activity is told about every request, e.g. to lock the store after a
+// period of inactivity
+type Activity interface {
+	Begin()
+	End()
+}
+
+// API answers the requests of a browser extension
+type API struct {
+	store   storer
+	r       io.Reader
+	w       io.Writer
+	origin  string
+	allowed []string
+	act     Activity
+}
+
+// New creates a new API reading requests from r and writing responses to w.
+// Only callers with an origin in allowed may retrieve or create secrets.
+func New(store storer, r io.Reader, w io.Writer, origin string, allowed []string) *API {
+	return &API{
+		store:   store,
+		r:       r,
+		w:       w,
+		origin:  origin,
+		allowed: allowed,
+	}
+}
+
+// SetActivity sets the receiver of the begin and end of every request
+func (a *API) SetActivity(act Activity) {
+	a.act = act
+}
+
+// Serve answers requests until the browser closes the connection
+func (a *API) Serve(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		msg, err := readMessage(a.r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				debug.Log("connection closed")
+				return nil
+			}
+			return err
+		}
+
+		if err := a.answer(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// answer responds to a single request and sends the response
+func (a *API) answer(ctx context.Context, msg []byte) error {
+	if a.act != nil {
+		a.act.Begin()
+		defer a.act.End()
+	}
+
+	resp, err := a.respond(ctx, msg)
+	if err != nil {
+		debug.Log("request failed: %s", err)
+		resp = errorResponse{Error: err.Error()}
+	}
+	return writeMessage(a.w, resp)
+}
+
+// respond returns the response to a single request
+func (a *API) respond(ctx context.Context, msg []byte) (interface{}, error) {
+	var mt messageType
+	if err := json.Unmarshal(msg, &mt); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+	debug.Log("received %q request from %q", mt.Type, a.origin)
+
+	switch mt.Type {
+	case "query":
+		var req queryMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		return a.query(ctx, req)
+	case "getLogin":
+		var req getLoginMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		if err := a.checkOrigin(); err != nil {
+			return nil, err
+		}
+		return a.getLogin(ctx, req)
+	case "create":
+		var req createMessage
+		if err := json.Unmarshal(msg, &req); err != nil {
+			return nil, fmt.Errorf("failed to decode request: %w", err)
+		}
+		if err := a.checkOrigin(); err != nil {
+			return nil, err
+		}
+		return a.create(ctx, req)
+	default:
+		return nil, fmt.Errorf("unknown request type %q", mt.Type)
+	}
+}
+
+// checkOrigin makes sure the caller is one of the allowed extensions. It
+// must be called before responding with any secret.
+func (a *API) checkOrigin() error {
+	if a.origin == "" {
+		return fmt.Errorf("unknown origin")
+	}
+	for _, o := range a.allowed {
+		if o == a.origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin %q is not allowed", a.origin)
+}
+
+// query returns the names of all secrets containing the host. If none is
+// found the leading subdomains are removed one after the other,
+// e.g. login.example.org is tried as example.org as well.
+func (a *API) query(ctx context.Context, req queryMessage) ([]string, error) {
+	host := strings.ToLower(strings.TrimSpace(req.Host))
+	if host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+
+	names, err := a.store.List(ctx, tree.INF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+
+	for {
+		res := make([]string, 0, 10)
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(name), host) {
+				res = append(res, name)
+			}
+		}
+		if len(res) > 0 || strings.Count(host, ".") < 2 {
+			sort.Strings(res)
+			return res, nil
+		}
+		host = host[strings.Index(host, ".")+1:]
+	}
+}
+
+// getLogin returns the username and password of a secret
+func (a *API) getLogin(ctx context.Context, req getLoginMessage) (loginResponse, error) {
+	sec, err := a.store.Get(ctx, req.Entry)
+	if err != nil {
+		return loginResponse{}, fmt.Errorf("failed to get %q: %w", req.Entry, err)
+	}
+	return loginResponse{
+		Username: username(req.Entry, sec),
+		Password: sec.Password(),
+	}, nil
+}
+
+// create saves a new secret and returns its username and password
+func (a *API) create(ctx context.Context, req createMessage) (loginResponse, error) {
+	if req.Name == "" {
+		return loginResponse{}, fmt.Errorf("missing entry name")
+	}
+	if a.store.Exists(ctx, req.Name) {
+		return loginResponse{}, fmt.Errorf("secret %q already exists", req.Name)
+	}
+
+	pw := req.Password
+	if req.Generate {
+		length := req.Length
+		if length < 1 {
+			length = defaultLength
+		}
+		pw = pwgen.GeneratePassword(length, req.UseSymbols)
+	}
+	if pw == "" {
+		return loginResponse{}, fmt.Errorf("missing password")
+	}
+
+	sec := secrets.NewKV()
+	sec.SetPassword(pw)
+	if req.Login != "" {
+		_ = sec.Set("user", req.Login)
+	}
+	if err := a.store.Set(ctxutil.WithCommitMessage(ctx, "Created by a browser extension"), req.Name, sec); err != nil {
+		return loginResponse{}, fmt.Errorf("failed to save %q: %w", req.Name, err)
+	}
+
+	return loginResponse{
+		Username: username(req.Name, sec),
+		Password: pw,
+	}, nil
+}
+
+// username returns the first username key of the secret or the last
+// component of its name
+func username(name string, sec gopass.Secret) string {
+	for _, k := range usernameKeys {
+		if v, found := sec.Get(k); found && v != "" {
+			return v
+		}
+	}
+	return path.Base(name)
+}
@@ -0,0 +1,186 @@
+package jsonapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrigin = "chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/"
+
+type fakeStore map[string]gopass.Secret
+
+func (f fakeStore) List(context.Context, int) ([]string, error) {
+	names := make([]string, 0, len(f))
+	for k := range f {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (f fakeStore) Get(_ context.Context, name string) (gopass.Secret, error) {
+	sec, found := f[name]
+	if !found {
+		return nil, fmt.Errorf("entry is not in the password store")
+	}
+	return sec, nil
+}
+
+func (f fakeStore) Set(_ context.Context, name string, sec gopass.Byter) error {
+	s, err := secrets.ParseKV(sec.Bytes())
+	if err != nil {
+		return err
+	}
+	f[name] = s
+	return nil
+}
+
+func (f fakeStore) Exists(_ context.Context, name string) bool {
+	_, found := f[name]
+	return found
+}
+
+// session speaks to a running API over pipes, like a browser would
+type session struct {
+	t    *testing.T
+	w    *io.PipeWriter
+	r    *io.PipeReader
+	done chan error
+}
+
+func newSession(t *testing.T, store storer, origin string) *session {
+	t.Helper()
+
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	s := &session{
+		t:    t,
+		w:    reqW,
+		r:    respR,
+		done: make(chan error, 1),
+	}
+	api := New(store, reqR, respW, origin, []string{testOrigin})
+	go func() {
+		s.done <- api.Serve(context.Background())
+		_ = respW.Close()
+	}()
+	return s
+}
+
+func (s *session) request(req interface{}, resp interface{}) {
+	s.t.Helper()
+
+	require.NoError(s.t, writeMessage(s.w, req))
+	buf, err := readMessage(s.r)
+	require.NoError(s.t, err)
+	require.NoError(s.t, json.Unmarshal(buf, resp), string(buf))
+}
+
+func (s *session) close() error {
+	_ = s.w.Close()
+	return <-s.done
+}
+
+func testStore() fakeStore {
+	store := fakeStore{}
+	for name, user := range map[string]string{
+		"websites/example.org/alice":     "alice@example.org",
+		"websites/example.org/bob":       "",
+		"websites/login.example.com/eve": "",
+		"misc/wifi":                      "",
+	} {
+		sec := secrets.NewKV()
+		sec.SetPassword("pw-" + name)
+		if user != "" {
+			_ = sec.Set("user", user)
+		}
+		store[name] = sec
+	}
+	return store
+}
+
+func TestAPI(t *testing.T) {
+	store := testStore()
+	s := newSession(t, store, testOrigin)
+
+	t.Run("query", func(t *testing.T) {
+		var res []string
+		s.request(map[string]string{"type": "query", "host": "example.org"}, &res)
+		assert.Equal(t, []string{"websites/example.org/alice", "websites/example.org/bob"}, res)
+	})
+
+	t.Run("query subdomain", func(t *testing.T) {
+		var res []string
+		s.request(map[string]string{"type": "query", "host": "www.example.org"}, &res)
+		assert.Equal(t, []string{"websites/example.org/alice", "websites/example.org/bob"}, res)
+
+		s.request(map[string]string{"type": "query", "host": "example.net"}, &res)
+		assert.Equal(t, []string{}, res)
+	})
+
+	t.Run("getLogin", func(t *testing.T) {
+		var res loginResponse
+		s.request(map[string]string{"type": "getLogin", "entry": "websites/example.org/alice"}, &res)
+		assert.Equal(t, loginResponse{Username: "alice@example.org", Password: "pw-websites/example.org/alice"}, res)
+
+		s.request(map[string]string{"type": "getLogin", "entry": "websites/example.org/bob"}, &res)
+		assert.Equal(t, loginResponse{Username: "bob", Password: "pw-websites/example.org/bob"}, res)
+	})
+
+	t.Run("getLogin of a missing secret", func(t *testing.T) {
+		var res errorResponse
+		s.request(map[string]string{"type": "getLogin", "entry": "websites/example.org/carol"}, &res)
+		assert.Contains(t, res.Error, "not in the password store")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		var res loginResponse
+		s.request(map[string]interface{}{"type": "create", "entry_name": "websites/example.net/carol", "login": "carol", "password": "s3cret"}, &res)
+		assert.Equal(t, loginResponse{Username: "carol", Password: "s3cret"}, res)
+		require.Contains(t, store, "websites/example.net/carol")
+		assert.Equal(t, "s3cret", store["websites/example.net/carol"].Password())
+
+		s.request(map[string]interface{}{"type": "create", "entry_name": "websites/example.net/dave", "generate": true, "length": 12}, &res)
+		assert.Equal(t, "dave", res.Username)
+		assert.Len(t, res.Password, 12)
+
+		var eres errorResponse
+		s.request(map[string]interface{}{"type": "create", "entry_name": "websites/example.net/carol", "password": "other"}, &eres)
+		assert.Contains(t, eres.Error, "already exists")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		var res errorResponse
+		s.request(map[string]string{"type": "frobnicate"}, &res)
+		assert.Equal(t, `unknown request type "frobnicate"`, res.Error)
+	})
+
+	assert.NoError(t, s.close())
+}
+
+func TestAPIOrigin(t *testing.T) {
+	for _, origin := range []string{"", "chrome-extension://evil/"} {
+		store := testStore()
+		s := newSession(t, store, origin)
+
+		var res errorResponse
+		s.request(map[string]string{"type": "getLogin", "entry": "websites/example.org/alice"}, &res)
+		assert.NotEmpty(t, res.Error)
+
+		var raw map[string]interface{}
+		s.request(map[string]interface{}{"type": "create", "entry_name": "websites/example.net/carol", "generate": true}, &raw)
+		assert.NotContains(t, raw, "password")
+		assert.NotContains(t, store, "websites/example.net/carol")
+
+		assert.NoError(t, s.close())
+	}
+}
@@ -0,0 +1,50 @@
+package jsonapi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the maximum size of a single message. Browsers reject
+// larger messages from the host, requests are much smaller anyway.
+const maxMessageSize = 1024 * 1024
+
+// readMessage reads a single message prefixed by its length as 32 bit
+// unsigned integer in little endian byte order. It returns io.EOF if the
+// browser closed the connection.
+func readMessage(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, err
+	}
+	if length > maxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", length, maxMessageSize)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+	return buf, nil
+}
+
+// writeMessage encodes the value as JSON and writes it prefixed by its length
+func writeMessage(w io.Writer, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if len(buf) > maxMessageSize {
+		return fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", len(buf), maxMessageSize)
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(buf))); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
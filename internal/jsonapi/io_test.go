@@ -0,0 +1,40 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFraming(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeMessage(buf, map[string]string{"type": "query"}))
+	assert.Equal(t, []byte{16, 0, 0, 0}, buf.Bytes()[:4])
+	assert.Equal(t, `{"type":"query"}`, buf.String()[4:])
+
+	msg, err := readMessage(buf)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"query"}`, string(msg))
+
+	_, err = readMessage(buf)
+	assert.ErrorIs(t, err, io.EOF)
+
+	// truncated message
+	buf.Reset()
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(10)))
+	buf.WriteString("{}")
+	_, err = readMessage(buf)
+	assert.Error(t, err)
+
+	// too large
+	buf.Reset()
+	require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(maxMessageSize+1)))
+	_, err = readMessage(buf)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// Name is the name of the native messaging host. It is the one gopassbridge
+// expects.
+const Name = "com.justwatch.gopass"
+
+// Browsers are the browsers a manifest can be installed for
+var Browsers = []string{"brave", "chrome", "chromium", "firefox"}
+
+// defaultExtensionIDs are the IDs of gopassbridge in the browser stores
+var defaultExtensionIDs = map[string]string{
+	"brave":    "kkhfnlkhiapbiehimabddjbimfaijdhk",
+	"chrome":   "kkhfnlkhiapbiehimabddjbimfaijdhk",
+	"chromium": "kkhfnlkhiapbiehimabddjbimfaijdhk",
+	"firefox":  "{eec37db0-22ad-4bf1-9068-5ae08df8c7e9}",
+}
+
+// Manifest is a native messaging host manifest. Chrome based browsers
+// identify extensions by their origin, Firefox by their ID.
+type Manifest struct {
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Path              string   `json:"path"`
+	Type              string   `json:"type"`
+	AllowedOrigins    []string `json:"allowed_origins,omitempty"`
+	AllowedExtensions []string `json:"allowed_extensions,omitempty"`
+}
+
+// NewManifest returns the manifest for the browser starting the wrapper
+// script. Without extension IDs only gopassbridge is allowed.
+func NewManifest(browser, wrapper string, extensionIDs []string) (*Manifest, error) {
+	if _, found := defaultExtensionIDs[browser]; !found {
+		return nil, fmt.Errorf("unsupported browser %q. Must be one of %s", browser, strings.Join(Browsers, ", "))
+	}
+	if len(extensionIDs) < 1 {
+		extensionIDs = []string{defaultExtensionIDs[browser]}
+	}
+
+	m := &Manifest{
+		Name:        Name,
+		Description: "Gopass wrapper to search and return passwords",
+		Path:        wrapper,
+		Type:        "stdio",
+	}
+	for _, id := range extensionIDs {
+		if browser == "firefox" {
+			m.AllowedExtensions = append(m.AllowedExtensions, id)
+			continue
+		}
+		m.AllowedOrigins = append(m.AllowedOrigins, "chrome-extension://"+id+"/")
+	}
+	return m, nil
+}
+
+// Bytes returns the manifest as JSON
+func (m *Manifest) Bytes() ([]byte, error) {
+	buf, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
+
+// ManifestPath returns the path the browser expects the manifest at
+func ManifestPath(browser string) (string, error) {
+	dir, err := manifestDir(browser)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, Name+".json"), nil
+}
+
+// Install writes the manifest to the given path and registers it with the
+// browser if required
+func (m *Manifest) Install(browser, path string) error {
+	buf, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create manifest dir: %w", err)
+	}
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return register(browser, path)
+}
+
+// WriteWrapper writes the script started by the browser. Browsers can not
+// pass any arguments of their own, so the script adds them.
+func WriteWrapper(path, binary string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create wrapper dir: %w", err)
+	}
+	if err := os.WriteFile(path, wrapperScript(binary), 0755); err != nil {
+		return fmt.Errorf("failed to write wrapper: %w", err)
+	}
+	return nil
+}
+
+// shellQuote quotes s for use in a shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Caller returns the origin of the extension starting the host and the path
+// of the manifest, if the browser passes it. Chrome passes the origin,
+// Firefox the manifest and the extension ID.
+func Caller(args []string) (string, string) {
+	var origin, manifest string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			// e.g. --parent-window on Windows
+			continue
+		case strings.HasSuffix(arg, ".json"):
+			manifest = arg
+		default:
+			origin = arg
+		}
+	}
+	return origin, manifest
+}
+
+// AllowedOrigins returns the origins and extension IDs allowed by the
+// manifests of all browsers and the additional manifests.
+func AllowedOrigins(manifests ...string) []string {
+	for _, b := range Browsers {
+		p, err := ManifestPath(b)
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, p)
+	}
+
+	var allowed []string
+	for _, p := range manifests {
+		if p == "" {
+			continue
+		}
+		buf, err := os.ReadFile(p)
+		if err != nil {
+			debug.Log("failed to read manifest %q: %s", p, err)
+			continue
+		}
+		var m Manifest
+		if err := json.Unmarshal(buf, &m); err != nil {
+			debug.Log("failed to decode manifest %q: %s", p, err)
+			continue
+		}
+		if m.Name != Name {
+			continue
+		}
+		allowed = append(allowed, m.AllowedOrigins...)
+		allowed = append(allowed, m.AllowedExtensions...)
+	}
+	return allowed
+}
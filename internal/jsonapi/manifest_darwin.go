@@ -0,0 +1,40 @@
+//go:build darwin
+// +build darwin
+
+package jsonapi
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/config"
+)
+
+// WrapperName is the file name of the wrapper script
+const WrapperName = "gopass_wrapper.sh"
+
+var manifestDirs = map[string]string{
+	"brave":    "Library/Application Support/BraveSoftware/Brave-Browser/NativeMessagingHosts",
+	"chrome":   "Library/Application Support/Google/Chrome/NativeMessagingHosts",
+	"chromium": "Library/Application Support/Chromium/NativeMessagingHosts",
+	"firefox":  "Library/Application Support/Mozilla/NativeMessagingHosts",
+}
+
+func manifestDir(browser string) (string, error) {
+	dir, found := manifestDirs[browser]
+	if !found {
+		return "", fmt.Errorf("unsupported browser %q", browser)
+	}
+	return filepath.Join(config.Homedir(), filepath.FromSlash(dir)), nil
+}
+
+// register is a no-op, browsers look for the manifest in a fixed location
+func register(browser, path string) error {
+	return nil
+}
+
+// wrapperScript returns the wrapper. Browsers are started with a minimal
+// PATH, so the usual locations of gpg are added.
+func wrapperScript(binary string) []byte {
+	return []byte(fmt.Sprintf("#!/bin/sh\n\nexport PATH=\"$PATH:/usr/local/bin:/opt/homebrew/bin:/usr/local/MacGPG2/bin\"\nexec %s jsonapi listen \"$@\"\n", shellQuote(binary)))
+}
@@ -0,0 +1,38 @@
+//go:build !darwin && !windows
+// +build !darwin,!windows
+
+package jsonapi
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/internal/config"
+)
+
+// WrapperName is the file name of the wrapper script
+const WrapperName = "gopass_wrapper.sh"
+
+var manifestDirs = map[string]string{
+	"brave":    ".config/BraveSoftware/Brave-Browser/NativeMessagingHosts",
+	"chrome":   ".config/google-chrome/NativeMessagingHosts",
+	"chromium": ".config/chromium/NativeMessagingHosts",
+	"firefox":  ".mozilla/native-messaging-hosts",
+}
+
+func manifestDir(browser string) (string, error) {
+	dir, found := manifestDirs[browser]
+	if !found {
+		return "", fmt.Errorf("unsupported browser %q", browser)
+	}
+	return filepath.Join(config.Homedir(), filepath.FromSlash(dir)), nil
+}
+
+// register is a no-op, browsers look for the manifest in a fixed location
+func register(browser, path string) error {
+	return nil
+}
+
+func wrapperScript(binary string) []byte {
+	return []byte(fmt.Sprintf("#!/bin/sh\n\nexec %s jsonapi listen \"$@\"\n", shellQuote(binary)))
+}
@@ -0,0 +1,97 @@
+package jsonapi
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewManifest(t *testing.T) {
+	t.Parallel()
+
+	m, err := NewManifest("chrome", "/usr/lib/gopass/gopass_wrapper.sh", nil)
+	require.NoError(t, err)
+	buf, err := m.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, `{
+    "name": "com.justwatch.gopass",
+    "description": "Gopass wrapper to search and return passwords",
+    "path": "/usr/lib/gopass/gopass_wrapper.sh",
+    "type": "stdio",
+    "allowed_origins": [
+        "chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/"
+    ]
+}
+`, string(buf))
+
+	m, err = NewManifest("firefox", "/usr/lib/gopass/gopass_wrapper.sh", []string{"a@example.org", "b@example.org"})
+	require.NoError(t, err)
+	assert.Nil(t, m.AllowedOrigins)
+	assert.Equal(t, []string{"a@example.org", "b@example.org"}, m.AllowedExtensions)
+
+	_, err = NewManifest("lynx", "/usr/lib/gopass/gopass_wrapper.sh", nil)
+	assert.Error(t, err)
+}
+
+func TestCaller(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args     []string
+		origin   string
+		manifest string
+	}{
+		{
+			args:   []string{"chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/"},
+			origin: "chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/",
+		},
+		{
+			args:   []string{"chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/", "--parent-window=1234"},
+			origin: "chrome-extension://kkhfnlkhiapbiehimabddjbimfaijdhk/",
+		},
+		{
+			args:     []string{"/home/alice/.mozilla/native-messaging-hosts/com.justwatch.gopass.json", "{eec37db0-22ad-4bf1-9068-5ae08df8c7e9}"},
+			origin:   "{eec37db0-22ad-4bf1-9068-5ae08df8c7e9}",
+			manifest: "/home/alice/.mozilla/native-messaging-hosts/com.justwatch.gopass.json",
+		},
+		{},
+	} {
+		origin, manifest := Caller(tc.args)
+		assert.Equal(t, tc.origin, origin)
+		assert.Equal(t, tc.manifest, manifest)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	td := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", td)
+	t.Setenv("GOPASS_CONFIG", filepath.Join(td, "config.yml"))
+	if runtime.GOOS == "windows" {
+		t.Skip("writes the registry")
+	}
+
+	assert.Empty(t, AllowedOrigins())
+
+	wrapper := filepath.Join(td, WrapperName)
+	require.NoError(t, WriteWrapper(wrapper, "/usr/bin/gopass"))
+	buf, err := os.ReadFile(wrapper)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), "exec '/usr/bin/gopass' jsonapi listen \"$@\"\n")
+
+	m, err := NewManifest("chromium", wrapper, []string{"abc"})
+	require.NoError(t, err)
+	p, err := ManifestPath("chromium")
+	require.NoError(t, err)
+	require.NoError(t, m.Install("chromium", p))
+	assert.Equal(t, []string{"chrome-extension://abc/"}, AllowedOrigins())
+
+	m, err = NewManifest("firefox", wrapper, nil)
+	require.NoError(t, err)
+	other := filepath.Join(td, "other.json")
+	require.NoError(t, m.Install("firefox", other))
+	assert.Equal(t, []string{"{eec37db0-22ad-4bf1-9068-5ae08df8c7e9}", "chrome-extension://abc/"}, AllowedOrigins(other))
+}
@@ -0,0 +1,50 @@
+//go:build windows
+// +build windows
+
+package jsonapi
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+
+	"github.com/gopasspw/gopass/internal/config"
+)
+
+// WrapperName is the file name of the wrapper script
+const WrapperName = "gopass_wrapper.bat"
+
+var registryKeys = map[string]string{
+	"brave":    `Software\BraveSoftware\Brave-Browser\NativeMessagingHosts\`,
+	"chrome":   `Software\Google\Chrome\NativeMessagingHosts\`,
+	"chromium": `Software\Chromium\NativeMessagingHosts\`,
+	"firefox":  `Software\Mozilla\NativeMessagingHosts\`,
+}
+
+// manifestDir returns a folder in the config dir. Browsers on Windows find
+// the manifest through the registry.
+func manifestDir(browser string) (string, error) {
+	if _, found := registryKeys[browser]; !found {
+		return "", fmt.Errorf("unsupported browser %q", browser)
+	}
+	return filepath.Join(config.Directory(), "NativeMessagingHosts", browser), nil
+}
+
+// register points the registry key of the browser to the manifest
+func register(browser, path string) error {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, registryKeys[browser]+Name, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to create registry key: %w", err)
+	}
+	defer k.Close()
+
+	if err := k.SetStringValue("", path); err != nil {
+		return fmt.Errorf("failed to set registry key: %w", err)
+	}
+	return nil
+}
+
+func wrapperScript(binary string) []byte {
+	return []byte(fmt.Sprintf("@echo off\r\n\"%s\" jsonapi listen %%*\r\n", binary))
+}
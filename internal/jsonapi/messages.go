@@ -0,0 +1,38 @@
+package jsonapi
+
+// messageType is the part all requests have in common
+type messageType struct {
+	Type string `json:"type"`
+}
+
+// queryMessage searches for the names of the secrets of a host
+type queryMessage struct {
+	Host string `json:"host"`
+}
+
+// getLoginMessage asks for the username and password of a secret
+type getLoginMessage struct {
+	Entry string `json:"entry"`
+}
+
+// createMessage creates a new secret, either with the given password or a
+// generated one
+type createMessage struct {
+	Name       string `json:"entry_name"`
+	Login      string `json:"login"`
+	Password   string `json:"password"`
+	Length     int    `json:"length"`
+	Generate   bool   `json:"generate"`
+	UseSymbols bool   `json:"use_symbols"`
+}
+
+// loginResponse is the response to getLogin and create
+type loginResponse struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// errorResponse is sent instead of a response if a request failed
+type errorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,196 @@
+// Package k8s renders secrets as Kubernetes v1 Secret manifests and applies
+// them to a cluster with kubectl.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+const (
+	// LabelPath is the label identifying the gopass path of a Secret
+	LabelPath = "gopass.pw/path"
+	// AnnotationPath holds the unmodified gopass path, label values are
+	// restricted to 63 characters and can't contain slashes
+	AnnotationPath = "gopass.pw/path"
+	// LabelManagedBy marks the Secrets written by gopass
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	// DefaultPasswordKey is the data key of the password
+	DefaultPasswordKey = "password"
+	// FieldManager is the field manager of server-side apply
+	FieldManager = "gopass"
+)
+
+var (
+	// kubectl is the binary used to apply the manifests
+	kubectl = "kubectl"
+
+	validKey   = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	invalidKey = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+	invalidDNS = regexp.MustCompile(`[^-.a-z0-9]+`)
+)
+
+// Secret is a v1 Secret manifest
+type Secret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   Metadata          `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string][]byte `json:"data"`
+}
+
+// Metadata is the object metadata of a Secret
+type Metadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// New returns an empty Secret for the gopass path. If name is empty it is
+// derived from the path.
+func New(name, namespace, path string) (*Secret, error) {
+	if name == "" {
+		name = Name(path)
+	}
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+	return &Secret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: Metadata{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				LabelManagedBy: "gopass",
+				LabelPath:      LabelValue(path),
+			},
+			Annotations: map[string]string{
+				AnnotationPath: path,
+			},
+		},
+		Type: "Opaque",
+		Data: map[string][]byte{},
+	}, nil
+}
+
+// Add adds a data key. A key must only be set once.
+func (s *Secret) Add(key string, value []byte) error {
+	if !validKey.MatchString(key) || key == "." || key == ".." {
+		return fmt.Errorf("invalid data key %q: must only contain alphanumerics, '-', '_' and '.'", key)
+	}
+	if _, found := s.Data[key]; found {
+		return fmt.Errorf("duplicate data key %q", key)
+	}
+	s.Data[key] = value
+	return nil
+}
+
+// AddSecret adds the password under passwordKey and every key of the KV
+// body. Keys with multiple values are joined by newlines.
+func (s *Secret) AddSecret(sec gopass.Secret, passwordKey string) error {
+	if err := s.Add(passwordKey, []byte(sec.Password())); err != nil {
+		return err
+	}
+	for _, k := range sec.Keys() {
+		vs, found := sec.Values(k)
+		if !found {
+			continue
+		}
+		if err := s.Add(k, []byte(strings.Join(vs, "\n"))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Name returns a valid Secret name for the gopass path, i.e. a lowercase
+// RFC 1123 subdomain
+func Name(path string) string {
+	name := strings.ToLower(strings.ReplaceAll(path, "/", "-"))
+	name = invalidDNS.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-.")
+	return shorten(name, 253)
+}
+
+// Key returns a valid data key for the gopass path
+func Key(path string) string {
+	return invalidKey.ReplaceAllString(strings.ReplaceAll(path, "/", "."), "-")
+}
+
+// LabelValue returns a valid label value identifying the gopass path.
+// Over-long paths are shortened with a hash of the path.
+func LabelValue(path string) string {
+	value := strings.Trim(Key(path), "-._")
+	return strings.Trim(shorten(value, 63), "-._")
+}
+
+func shorten(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	sum := fmt.Sprintf("%x", sha256.Sum256([]byte(s)))[:8]
+	return s[:max-9] + "-" + sum
+}
+
+func validName(name string) error {
+	if name == "" || len(name) > 253 || invalidDNS.MatchString(name) ||
+		strings.Trim(name, "-.") != name {
+		return fmt.Errorf("invalid Secret name %q: must be a lowercase RFC 1123 subdomain", name)
+	}
+	return nil
+}
+
+// Encode writes the manifests as YAML documents
+func Encode(w io.Writer, secrets []*Secret) error {
+	for i, s := range secrets {
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if err := out.Encode(w, out.FormatYAML, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply creates or updates the Secrets with server-side apply. kubectl uses
+// the given kubeconfig or its default one. The manifests are passed on stdin,
+// so the values never show up in the process list. The output of kubectl is
+// written to w.
+func Apply(ctx context.Context, w io.Writer, secrets []*Secret, kubeconfig string) error {
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, secrets); err != nil {
+		return err
+	}
+
+	args := []string{"apply", "--server-side", "--force-conflicts", "--field-manager=" + FieldManager, "-f", "-"}
+	if kubeconfig != "" {
+		args = append([]string{"--kubeconfig", kubeconfig}, args...)
+	}
+	cmd := exec.CommandContext(ctx, kubectl, args...)
+	cmd.Stdin = buf
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	// only the command is logged, never the manifests
+	debug.Log("applying %d Secrets: %s %+v", len(secrets), cmd.Path, cmd.Args)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s apply failed: %w", kubectl, err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	sec := secrets.NewKV()
+	sec.SetPassword("s3cret")
+	require.NoError(t, sec.Set("user", "admin"))
+	require.NoError(t, sec.Add("host", "db1"))
+	require.NoError(t, sec.Add("host", "db2"))
+
+	s, err := New("", "prod", "infra/DB")
+	require.NoError(t, err)
+	require.NoError(t, s.AddSecret(sec, DefaultPasswordKey))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Encode(buf, []*Secret{s}))
+	assert.Equal(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: infra-db
+  namespace: prod
+  labels:
+    app.kubernetes.io/managed-by: gopass
+    gopass.pw/path: infra.DB
+  annotations:
+    gopass.pw/path: infra/DB
+type: Opaque
+data:
+  host: ZGIxCmRiMg==
+  password: czNjcmV0
+  user: YWRtaW4=
+`, buf.String())
+
+	// the password key must not collide with the keys of the secret
+	s, err = New("db", "", "infra/db")
+	require.NoError(t, err)
+	assert.Error(t, s.AddSecret(sec, "user"))
+
+	assert.Error(t, s.Add("foo bar", nil))
+	assert.Error(t, s.Add("..", nil))
+
+	_, err = New("Invalid_Name", "", "infra/db")
+	assert.Error(t, err)
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, "infra-web-db", Name("infra/web/DB"))
+	assert.Equal(t, "my-app-db", Name("my app/db"))
+	assert.Equal(t, "infra.web.db_password", Key("infra/web/db_password"))
+	assert.Equal(t, "a-b.c", Key("a b/c"))
+
+	long := strings.Repeat("a/", 50)
+	assert.True(t, len(LabelValue(long)) <= 63)
+	assert.NotEqual(t, LabelValue(long), LabelValue(long+"b"))
+	assert.Equal(t, "infra.db", LabelValue("/infra/db/"))
+}
+
+func TestEncodeMultiple(t *testing.T) {
+	a, err := New("a", "", "a")
+	require.NoError(t, err)
+	b, err := New("b", "", "b")
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Encode(buf, []*Secret{a, b}))
+	docs := strings.Split(buf.String(), "---\n")
+	require.Len(t, docs, 2)
+	assert.Contains(t, docs[0], "name: a")
+	assert.Contains(t, docs[1], "name: b")
+}
+
+func TestApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no shell on windows")
+	}
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest")
+	fake := filepath.Join(dir, "kubectl")
+	require.NoError(t, os.WriteFile(fake, []byte("#!/bin/sh\necho \"$@\"\ncat > "+manifest+"\n"), 0755))
+
+	oldKubectl := kubectl
+	kubectl = fake
+	defer func() {
+		kubectl = oldKubectl
+	}()
+
+	s, err := New("db", "prod", "infra/db")
+	require.NoError(t, err)
+	require.NoError(t, s.Add("password", []byte("s3cret")))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Apply(context.Background(), buf, []*Secret{s}, "/tmp/kubeconfig"))
+	assert.Equal(t, "--kubeconfig /tmp/kubeconfig apply --server-side --force-conflicts --field-manager=gopass -f -\n", buf.String())
+
+	content, err := os.ReadFile(manifest)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "password: czNjcmV0")
+	assert.NotContains(t, buf.String(), "czNjcmV0")
+
+	kubectl = filepath.Join(dir, "missing")
+	assert.Error(t, Apply(context.Background(), buf, []*Secret{s}, ""))
+}
@@ -0,0 +1,57 @@
+// Package link handles links between secrets. A link is a secret of its own
+// that only contains a Ref header with the full name of its target, e.g.
+//
+//	Ref: websites/aws.amazon.com
+//
+// Reading a link returns its target instead, changing it asks whether to
+// follow or to break the link.
+package link
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+)
+
+// Key is the key of a link holding the name of its target
+const Key = "ref"
+
+// MaxDepth is the number of links followed before giving up
+const MaxDepth = 8
+
+var (
+	// ErrLoop is returned if a link points back to itself, directly or
+	// through other links
+	ErrLoop = errors.New("links form a loop")
+	// ErrTooDeep is returned if there are more than MaxDepth links in a row
+	ErrTooDeep = errors.New("too many links in a row")
+)
+
+// New creates a link to target
+func New(target string) gopass.Secret {
+	sec, _ := secrets.ParseKV([]byte("\nRef: " + target))
+	return sec
+}
+
+// Target returns the target of a link. Only secrets without a password,
+// body or other keys are links, so a secret that happens to have a ref key
+// is never mistaken for one. The content is parsed again, so it works even
+// if parsing was disabled when the secret was read.
+func Target(sec gopass.Secret) (string, bool) {
+	if sec == nil {
+		return "", false
+	}
+	kv, err := secrets.ParseKV(sec.Bytes())
+	if err != nil || kv.Password() != "" || strings.TrimSpace(kv.Body()) != "" {
+		return "", false
+	}
+	keys := kv.Keys()
+	if len(keys) != 1 || keys[0] != Key {
+		return "", false
+	}
+	target, _ := kv.Get(Key)
+	target = strings.Trim(strings.TrimSpace(target), "/")
+	return target, target != ""
+}
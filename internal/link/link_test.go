@@ -0,0 +1,37 @@
+package link
+
+import (
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTarget(t *testing.T) {
+	sec := New("websites/aws.amazon.com")
+	assert.Equal(t, "\nRef: websites/aws.amazon.com", string(sec.Bytes()))
+
+	target, found := Target(sec)
+	assert.True(t, found)
+	assert.Equal(t, "websites/aws.amazon.com", target)
+
+	// parsing may be disabled when reading the secret
+	target, found = Target(secrets.ParsePlain(sec.Bytes()))
+	assert.True(t, found)
+	assert.Equal(t, "websites/aws.amazon.com", target)
+
+	for _, tc := range []string{
+		"",
+		"password",
+		"password\nref: infra/aws/root",
+		"\nref: infra/aws/root\nuser: root",
+		"\nref: infra/aws/root\nsome notes",
+		"\nref: /",
+	} {
+		_, found := Target(secrets.ParsePlain([]byte(tc)))
+		assert.False(t, found, tc)
+	}
+	_, found = Target(nil)
+	assert.False(t, found)
+}
@@ -7,8 +7,10 @@ import (
 	"context"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 )
 
 const (
@@ -19,7 +21,8 @@ const (
 var execCommand = exec.Command
 var execLookPath = exec.LookPath
 
-// Notify displays a desktop notification using osascript
+// Notify displays a desktop notification using terminal-notifier or
+// osascript. It does nothing if neither is available.
 func Notify(ctx context.Context, subj, msg string) error {
 	if os.Getenv("GOPASS_NO_NOTIFY") != "" || !ctxutil.IsNotifications(ctx) {
 		return nil
@@ -37,12 +40,19 @@ func Notify(ctx context.Context, subj, msg string) error {
 func osaNotification(msg string, subj string) error {
 	_, err := executableExists(osascript)
 	if err != nil {
-		return err
+		debug.Log("No notification helper found: %s", err)
+		return nil
 	}
-	args := []string{"-e", `display notification "` + msg + `" with title "` + subj + `"`}
+	args := []string{"-e", `display notification ` + osaQuote(msg) + ` with title ` + osaQuote(subj)}
 	return execNotification(osascript, args)
 }
 
+// osaQuote returns s as an AppleScript string literal
+func osaQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
 // exec notification program with passed arguments
 func execNotification(executable string, args []string) error {
 	return execCommand(executable, args...).Start()
@@ -73,3 +73,8 @@ func mockExecCommand(command string, args ...string) *exec.Cmd {
 	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
 	return cmd
 }
+
+func TestOsaQuote(t *testing.T) {
+	assert.Equal(t, `"Copied \"foo\" to clipboard"`, osaQuote(`Copied "foo" to clipboard`))
+	assert.Equal(t, `"a\\b"`, osaQuote(`a\b`))
+}
@@ -13,7 +13,8 @@ import (
 	"github.com/godbus/dbus"
 )
 
-// Notify displays a desktop notification with dbus
+// Notify displays a desktop notification with dbus. It does nothing if no
+// notification daemon is available.
 func Notify(ctx context.Context, subj, msg string) error {
 	if os.Getenv("GOPASS_NO_NOTIFY") != "" || !ctxutil.IsNotifications(ctx) {
 		debug.Log("Notifications disabled")
@@ -21,15 +22,17 @@ func Notify(ctx context.Context, subj, msg string) error {
 	}
 	conn, err := dbus.SessionBus()
 	if err != nil {
+		// no session bus, e.g. on a headless system. Notifications are
+		// optional so this is not an error.
 		debug.Log("DBus failure: %s", err)
-		return err
+		return nil
 	}
 
 	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
 	call := obj.Call("org.freedesktop.Notifications.Notify", 0, "gopass", uint32(0), iconURI(), subj, msg, []string{}, map[string]dbus.Variant{}, int32(5000))
 	if call.Err != nil {
+		// no notification daemon is running
 		debug.Log("DBus notification failure: %s", call.Err)
-		return err
 	}
 
 	return nil
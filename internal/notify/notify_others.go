@@ -5,11 +5,13 @@ package notify
 
 import (
 	"context"
-	"fmt"
 	"runtime"
+
+	"github.com/gopasspw/gopass/pkg/debug"
 )
 
-// Notify is not yet implemented on this platform
+// Notify is not yet implemented on this platform and does nothing
 func Notify(ctx context.Context, subj, msg string) error {
-	return fmt.Errorf("GOOS %s not yet supported", runtime.GOOS)
+	debug.Log("Notifications not supported on GOOS %s", runtime.GOOS)
+	return nil
 }
@@ -5,20 +5,42 @@ package notify
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 )
 
-// Notify displays a desktop notification through msg
+// toastAppID is the application id of PowerShell. Toasts of unregistered
+// application ids are not shown.
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$n = $t.GetElementsByTagName('text')
+$n.Item(0).AppendChild($t.CreateTextNode(%s)) > $null
+$n.Item(1).AppendChild($t.CreateTextNode(%s)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(%s).Show([Windows.UI.Notifications.ToastNotification]::new($t))`
+
+// Notify displays a desktop notification as a toast or, if PowerShell is not
+// available, through msg. It does nothing if neither is available.
 func Notify(ctx context.Context, subj, msg string) error {
 	if os.Getenv("GOPASS_NO_NOTIFY") != "" || !ctxutil.IsNotifications(ctx) {
 		return nil
 	}
+
+	if ps, err := exec.LookPath("powershell.exe"); err == nil {
+		script := fmt.Sprintf(toastScript, psQuote(subj), psQuote(msg), psQuote(toastAppID))
+		return exec.Command(ps, "-NoProfile", "-NonInteractive", "-Command", script).Start()
+	}
+
 	winmsg, err := exec.LookPath("msg")
 	if err != nil {
-		return err
+		debug.Log("No notification helper found: %s", err)
+		return nil
 	}
 
 	return exec.Command(winmsg,
@@ -27,3 +49,8 @@ func Notify(ctx context.Context, subj, msg string) error {
 		subj+"\n\n"+msg,
 	).Start()
 }
+
+// psQuote returns s as a single quoted PowerShell string
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,242 @@
+package otpbackup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// aegisSlotPassword is the type of a key slot protected by a password. The
+// other slots (raw and biometric) can only be used by the app.
+const aegisSlotPassword = 1
+
+// ErrWrongPassword is returned if no key slot of an encrypted backup can be
+// opened with the given password
+var ErrWrongPassword = errors.New("wrong password")
+
+// aegisVault is an Aegis backup, see
+// https://github.com/beemdevelopment/Aegis/blob/master/docs/vault.md
+type aegisVault struct {
+	Version int         `json:"version"`
+	Header  aegisHeader `json:"header"`
+	// DB is an aegisDB or, if the vault is encrypted, a base64 encoded
+	// string
+	DB json.RawMessage `json:"db"`
+}
+
+type aegisHeader struct {
+	Slots  []aegisSlot  `json:"slots"`
+	Params *aegisParams `json:"params"`
+}
+
+type aegisSlot struct {
+	Type      int          `json:"type"`
+	UUID      string       `json:"uuid"`
+	Key       string       `json:"key"`
+	KeyParams *aegisParams `json:"key_params"`
+	N         int          `json:"n,omitempty"`
+	R         int          `json:"r,omitempty"`
+	P         int          `json:"p,omitempty"`
+	Salt      string       `json:"salt,omitempty"`
+}
+
+type aegisParams struct {
+	Nonce string `json:"nonce"`
+	Tag   string `json:"tag"`
+}
+
+type aegisDB struct {
+	Version int          `json:"version"`
+	Entries []aegisEntry `json:"entries"`
+}
+
+type aegisEntry struct {
+	Type   string    `json:"type"`
+	UUID   string    `json:"uuid"`
+	Name   string    `json:"name"`
+	Issuer string    `json:"issuer"`
+	Note   string    `json:"note"`
+	Icon   *string   `json:"icon"`
+	Info   aegisInfo `json:"info"`
+}
+
+type aegisInfo struct {
+	Secret  string `json:"secret"`
+	Algo    string `json:"algo"`
+	Digits  int    `json:"digits"`
+	Period  int    `json:"period,omitempty"`
+	Counter uint64 `json:"counter,omitempty"`
+}
+
+// ReadAegis reads a plain or an encrypted Aegis backup
+func ReadAegis(r io.Reader, pw PasswordFunc) ([]Entry, error) {
+	var v aegisVault
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to parse Aegis JSON: %w", err)
+	}
+	if v.Version != 1 {
+		return nil, fmt.Errorf("unsupported Aegis backup version %d", v.Version)
+	}
+
+	buf := []byte(v.DB)
+	if v.Header.Params != nil {
+		password, err := pw()
+		if err != nil {
+			return nil, err
+		}
+		buf, err = v.decrypt(password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var db aegisDB
+	if err := json.Unmarshal(buf, &db); err != nil {
+		return nil, fmt.Errorf("failed to parse the Aegis entries: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(db.Entries))
+	for _, e := range db.Entries {
+		entries = append(entries, Entry{
+			Type:      strings.ToLower(e.Type),
+			Issuer:    strings.TrimSpace(e.Issuer),
+			Account:   strings.TrimSpace(e.Name),
+			Secret:    e.Info.Secret,
+			Algorithm: e.Info.Algo,
+			Digits:    e.Info.Digits,
+			Period:    e.Info.Period,
+			Counter:   e.Info.Counter,
+			Note:      strings.TrimSpace(e.Note),
+		})
+	}
+	return entries, nil
+}
+
+// decrypt returns the entries of an encrypted vault. The master key is
+// encrypted with a key derived from the password in each password slot.
+func (v *aegisVault) decrypt(password string) ([]byte, error) {
+	var ct string
+	if err := json.Unmarshal(v.DB, &ct); err != nil {
+		return nil, fmt.Errorf("failed to parse the encrypted Aegis entries: %w", err)
+	}
+	db, err := base64.StdEncoding.DecodeString(ct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the encrypted Aegis entries: %w", err)
+	}
+
+	for _, slot := range v.Header.Slots {
+		if slot.Type != aegisSlotPassword || slot.KeyParams == nil {
+			continue
+		}
+		salt, err := hex.DecodeString(slot.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid salt: %w", err)
+		}
+		key, err := scrypt.Key([]byte(password), salt, slot.N, slot.R, slot.P, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive the key: %w", err)
+		}
+		encKey, err := hex.DecodeString(slot.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key: %w", err)
+		}
+		mk, err := aegisOpen(key, encKey, slot.KeyParams)
+		if err != nil {
+			// another slot may use the same password
+			continue
+		}
+		buf, err := aegisOpen(mk, db, v.Header.Params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt the Aegis entries: %w", err)
+		}
+		return buf, nil
+	}
+	return nil, ErrWrongPassword
+}
+
+// aegisOpen decrypts the ciphertext with AES-256-GCM
+func aegisOpen(key, ct []byte, params *aegisParams) ([]byte, error) {
+	nonce, err := hex.DecodeString(params.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := hex.DecodeString(params.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Open(nil, nonce, append(append([]byte{}, ct...), tag...), nil)
+}
+
+// WriteAegis writes an unencrypted Aegis backup. It can be imported by
+// Aegis and encrypted there.
+func WriteAegis(w io.Writer, entries []Entry) error {
+	db := aegisDB{
+		Version: 2,
+		Entries: make([]aegisEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		uuid, err := newUUID()
+		if err != nil {
+			return err
+		}
+		ae := aegisEntry{
+			Type:   strings.ToLower(e.Type),
+			UUID:   uuid,
+			Name:   e.Account,
+			Issuer: e.Issuer,
+			Note:   e.Note,
+			Info: aegisInfo{
+				Secret: e.Secret,
+				Algo:   strings.ToUpper(e.Algorithm),
+				Digits: e.Digits,
+			},
+		}
+		if ae.Type == "hotp" {
+			ae.Info.Counter = e.Counter
+		} else {
+			ae.Info.Period = e.Period
+		}
+		db.Entries = append(db.Entries, ae)
+	}
+
+	buf, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(aegisVault{
+		Version: 1,
+		DB:      buf,
+	})
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
@@ -0,0 +1,89 @@
+package otpbackup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// andOTPEntry is a single entry of an andOTP backup
+type andOTPEntry struct {
+	Secret    string   `json:"secret"`
+	Issuer    string   `json:"issuer"`
+	Label     string   `json:"label"`
+	Digits    int      `json:"digits"`
+	Type      string   `json:"type"`
+	Algorithm string   `json:"algorithm"`
+	Thumbnail string   `json:"thumbnail"`
+	Period    int      `json:"period,omitempty"`
+	Counter   uint64   `json:"counter,omitempty"`
+	Tags      []string `json:"tags"`
+}
+
+// ReadAndOTP reads a plain andOTP backup. Encrypted backups must be
+// decrypted by andOTP first.
+func ReadAndOTP(r io.Reader) ([]Entry, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(buf), []byte("[")) {
+		return nil, fmt.Errorf("not a plain andOTP backup. Encrypted backups are not supported, create a plain one in andOTP")
+	}
+
+	var list []andOTPEntry
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse andOTP JSON: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(list))
+	for _, ae := range list {
+		e := Entry{
+			Type:      strings.ToLower(ae.Type),
+			Issuer:    strings.TrimSpace(ae.Issuer),
+			Account:   strings.TrimSpace(ae.Label),
+			Secret:    ae.Secret,
+			Algorithm: ae.Algorithm,
+			Digits:    ae.Digits,
+			Period:    ae.Period,
+			Counter:   ae.Counter,
+		}
+		// older versions only have a label, e.g. "GitHub - jane"
+		if e.Issuer == "" {
+			if p := strings.SplitN(e.Account, " - ", 2); len(p) == 2 {
+				e.Issuer, e.Account = strings.TrimSpace(p[0]), strings.TrimSpace(p[1])
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// WriteAndOTP writes a plain andOTP backup
+func WriteAndOTP(w io.Writer, entries []Entry) error {
+	list := make([]andOTPEntry, 0, len(entries))
+	for _, e := range entries {
+		ae := andOTPEntry{
+			Secret:    e.Secret,
+			Issuer:    e.Issuer,
+			Label:     e.Account,
+			Digits:    e.Digits,
+			Type:      strings.ToUpper(e.Type),
+			Algorithm: strings.ToUpper(e.Algorithm),
+			Thumbnail: "Default",
+			Tags:      []string{},
+		}
+		if ae.Type == "HOTP" {
+			ae.Counter = e.Counter
+		} else {
+			ae.Period = e.Period
+		}
+		list = append(list, ae)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
@@ -0,0 +1,121 @@
+// Package otpbackup reads and writes the backups of phone authenticator apps,
+// e.g. Aegis and andOTP.
+package otpbackup
+
+import (
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/otp"
+)
+
+// Entry is a single OTP key of a backup
+type Entry struct {
+	// Type is the type of the key, e.g. totp, hotp or steam
+	Type      string
+	Issuer    string
+	Account   string
+	Secret    string // base32 encoded
+	Algorithm string
+	Digits    int
+	Period    int
+	Counter   uint64
+	Note      string
+}
+
+// PasswordFunc asks for the passphrase of an encrypted backup
+type PasswordFunc func() (string, error)
+
+// Formats returns the names of all supported formats
+func Formats() []string {
+	return []string{"aegis", "andotp"}
+}
+
+// Read reads all entries of a backup. The password func is only called for
+// encrypted backups.
+func Read(format string, r io.Reader, pw PasswordFunc) ([]Entry, error) {
+	switch format {
+	case "aegis":
+		return ReadAegis(r, pw)
+	case "andotp":
+		return ReadAndOTP(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q. Use one of %s", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// Write writes an unencrypted backup of all entries
+func Write(format string, w io.Writer, entries []Entry) error {
+	switch format {
+	case "aegis":
+		return WriteAegis(w, entries)
+	case "andotp":
+		return WriteAndOTP(w, entries)
+	default:
+		return fmt.Errorf("unknown format %q. Use one of %s", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// URL returns the otpauth:// URL of the entry. The URL of a key gopass can't
+// generate codes for, e.g. a Steam key, is returned along with the reason.
+func (e Entry) URL() (string, error) {
+	label := e.Account
+	if e.Issuer != "" {
+		label = e.Issuer + ":" + e.Account
+	}
+
+	v := url.Values{}
+	v.Set("secret", strings.ToUpper(strings.ReplaceAll(e.Secret, " ", "")))
+	if e.Issuer != "" {
+		v.Set("issuer", e.Issuer)
+	}
+	if algo := strings.ToUpper(e.Algorithm); algo != "" && algo != "SHA1" {
+		v.Set("algorithm", algo)
+	}
+	if e.Digits != 0 && e.Digits != 6 {
+		v.Set("digits", strconv.Itoa(e.Digits))
+	}
+	typ := strings.ToLower(e.Type)
+	if typ == otp.TypeHOTP {
+		v.Set("counter", strconv.FormatUint(e.Counter, 10))
+	} else if e.Period != 0 && e.Period != 30 {
+		v.Set("period", strconv.Itoa(e.Period))
+	}
+
+	u := (&url.URL{
+		Scheme:   "otpauth",
+		Host:     typ,
+		Path:     "/" + label,
+		RawQuery: v.Encode(),
+	}).String()
+	if _, err := otp.Parse(u); err != nil {
+		return u, err
+	}
+	return u, nil
+}
+
+// FromKey returns the entry for an OTP key read from a secret
+func FromKey(k *otp.Key) Entry {
+	e := Entry{
+		Type:      k.Type,
+		Issuer:    k.Issuer,
+		Account:   k.Label,
+		Secret:    strings.TrimRight(base32.StdEncoding.EncodeToString(k.Secret), "="),
+		Algorithm: k.Algorithm,
+		Digits:    k.Digits,
+		Period:    k.Period,
+		Counter:   k.Counter,
+	}
+	// the label of an otpauth:// URL is issuer:account
+	if i := strings.Index(e.Account, ":"); i >= 0 {
+		if e.Issuer == "" {
+			e.Issuer = e.Account[:i]
+		}
+		e.Account = strings.TrimSpace(e.Account[i+1:])
+	}
+	return e
+}
@@ -0,0 +1,175 @@
+package otpbackup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/scrypt"
+)
+
+const aegisDBJSON = `{
+  "version": 2,
+  "entries": [
+    {
+      "type": "totp",
+      "uuid": "3ae6f1ad-2e65-4ed2-a953-1ec0dff2386d",
+      "name": "jane@example.com",
+      "issuer": "GitHub",
+      "note": "recovery codes in the safe",
+      "icon": null,
+      "info": {"secret": "JBSWY3DPEHPK3PXP", "algo": "SHA1", "digits": 6, "period": 30}
+    },
+    {
+      "type": "hotp",
+      "uuid": "5d7b5e2a-7b14-4a76-9d8c-6f2a4a5f8b33",
+      "name": "jane",
+      "issuer": "Bank",
+      "icon": null,
+      "info": {"secret": "GEZDGNBVGY3TQOJQ", "algo": "SHA256", "digits": 8, "counter": 4}
+    },
+    {
+      "type": "steam",
+      "uuid": "8f8b3c1e-2a5e-4c3b-8a8e-0c1b5b6f3c4d",
+      "name": "gamer",
+      "issuer": "Steam",
+      "icon": null,
+      "info": {"secret": "JBSWY3DPEHPK3PXP", "algo": "SHA1", "digits": 5, "period": 30}
+    }
+  ]
+}`
+
+func TestReadAegis(t *testing.T) {
+	noPassword := func() (string, error) {
+		t.Fatal("the password must not be asked for")
+		return "", nil
+	}
+
+	entries, err := ReadAegis(strings.NewReader(`{"version": 1, "header": {"slots": null, "params": null}, "db": `+aegisDBJSON+`}`), noPassword)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, Entry{
+		Type:      "totp",
+		Issuer:    "GitHub",
+		Account:   "jane@example.com",
+		Secret:    "JBSWY3DPEHPK3PXP",
+		Algorithm: "SHA1",
+		Digits:    6,
+		Period:    30,
+		Note:      "recovery codes in the safe",
+	}, entries[0])
+
+	u, err := entries[0].URL()
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/GitHub:jane@example.com?issuer=GitHub&secret=JBSWY3DPEHPK3PXP", u)
+
+	u, err = entries[1].URL()
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://hotp/Bank:jane?algorithm=SHA256&counter=4&digits=8&issuer=Bank&secret=GEZDGNBVGY3TQOJQ", u)
+
+	// the key is kept even though gopass can't use it
+	u, err = entries[2].URL()
+	assert.Error(t, err)
+	assert.Contains(t, u, "otpauth://steam/")
+}
+
+func TestReadAegisEncrypted(t *testing.T) {
+	vault := encryptAegis(t, "hunter2", []byte(aegisDBJSON))
+
+	entries, err := ReadAegis(bytes.NewReader(vault), func() (string, error) { return "hunter2", nil })
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "GitHub", entries[0].Issuer)
+
+	_, err = ReadAegis(bytes.NewReader(vault), func() (string, error) { return "wrong", nil })
+	assert.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestRoundtrip(t *testing.T) {
+	in := []Entry{
+		{Type: "totp", Issuer: "GitHub", Account: "jane", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6, Period: 30},
+		{Type: "hotp", Issuer: "Bank", Account: "jane", Secret: "GEZDGNBVGY3TQOJQ", Algorithm: "SHA256", Digits: 8, Counter: 4},
+	}
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, Write(format, buf, in))
+
+			out, err := Read(format, buf, nil)
+			require.NoError(t, err)
+			assert.Equal(t, in, out)
+		})
+	}
+}
+
+func TestReadAndOTP(t *testing.T) {
+	entries, err := ReadAndOTP(strings.NewReader(`[
+  {"secret": "JBSWY3DPEHPK3PXP", "issuer": "GitHub", "label": "jane", "digits": 6, "type": "TOTP", "algorithm": "SHA1", "period": 30, "tags": []},
+  {"secret": "GEZDGNBVGY3TQOJQ", "label": "Bank - jane", "digits": 6, "type": "TOTP", "algorithm": "SHA512", "period": 60, "tags": []}
+]`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GitHub", entries[0].Issuer)
+	assert.Equal(t, "Bank", entries[1].Issuer)
+	assert.Equal(t, "jane", entries[1].Account)
+
+	u, err := entries[1].URL()
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/Bank:jane?algorithm=SHA512&issuer=Bank&period=60&secret=GEZDGNBVGY3TQOJQ", u)
+
+	_, err = ReadAndOTP(strings.NewReader("\x8a\x12binary"))
+	assert.Error(t, err)
+}
+
+// encryptAegis returns an Aegis vault with the db encrypted like the app does
+func encryptAegis(t *testing.T, password string, db []byte) []byte {
+	t.Helper()
+
+	seal := func(key, plain []byte) (string, *aegisParams) {
+		block, err := aes.NewCipher(key)
+		require.NoError(t, err)
+		gcm, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		nonce := bytes.Repeat([]byte{0x42}, gcm.NonceSize())
+		ct := gcm.Seal(nil, nonce, plain, nil)
+		tag := ct[len(ct)-gcm.Overhead():]
+		return hex.EncodeToString(ct[:len(ct)-gcm.Overhead()]), &aegisParams{
+			Nonce: hex.EncodeToString(nonce),
+			Tag:   hex.EncodeToString(tag),
+		}
+	}
+
+	salt := bytes.Repeat([]byte{0x17}, 32)
+	key, err := scrypt.Key([]byte(password), salt, 1<<10, 8, 1, 32)
+	require.NoError(t, err)
+	mk := bytes.Repeat([]byte{0x23}, 32)
+	encKey, keyParams := seal(key, mk)
+	encDB, dbParams := seal(mk, db)
+	ct, err := hex.DecodeString(encDB)
+	require.NoError(t, err)
+	dbJSON, err := json.Marshal(base64.StdEncoding.EncodeToString(ct))
+	require.NoError(t, err)
+
+	buf, err := json.Marshal(aegisVault{
+		Version: 1,
+		Header: aegisHeader{
+			Slots: []aegisSlot{
+				{Type: 2, UUID: "biometric"},
+				{Type: aegisSlotPassword, UUID: "password", Key: encKey, KeyParams: keyParams, N: 1 << 10, R: 8, P: 1, Salt: hex.EncodeToString(salt)},
+			},
+			Params: dbParams,
+		},
+		DB: dbJSON,
+	})
+	require.NoError(t, err)
+	return buf
+}
@@ -0,0 +1,81 @@
+package out
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FormatText is the human readable default output
+	FormatText = "text"
+	// FormatJSON is machine readable JSON output
+	FormatJSON = "json"
+	// FormatYAML is machine readable YAML output
+	FormatYAML = "yaml"
+)
+
+// ParseFormat returns the output format for the value of a --format flag
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON, FormatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q. Must be text, json or yaml", format)
+	}
+}
+
+// IsStructured returns true if the format is machine readable
+func IsStructured(format string) bool {
+	return format == FormatJSON || format == FormatYAML
+}
+
+// Encode writes v to w in the given machine readable format. The schema is
+// defined by the json tags of v, the YAML output uses the same keys in the
+// same order.
+func Encode(w io.Writer, format string, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", format, err)
+	}
+
+	switch format {
+	case FormatJSON:
+		var ibuf bytes.Buffer
+		if err := json.Indent(&ibuf, buf, "", "  "); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", format, err)
+		}
+		ibuf.WriteString("\n")
+		_, err := ibuf.WriteTo(w)
+		return err
+	case FormatYAML:
+		// JSON is valid YAML, it only needs to be converted to block style
+		var node yaml.Node
+		if err := yaml.Unmarshal(buf, &node); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", format, err)
+		}
+		blockStyle(&node)
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", format, err)
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// blockStyle removes the flow style and quotes of the decoded JSON. The
+// encoder quotes strings again where necessary.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,70 @@
+package out
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]string{
+		"":     FormatText,
+		"text": FormatText,
+		"json": FormatJSON,
+		"yaml": FormatYAML,
+	} {
+		f, err := ParseFormat(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, f, in)
+	}
+
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+
+	assert.True(t, IsStructured(FormatJSON))
+	assert.True(t, IsStructured(FormatYAML))
+	assert.False(t, IsStructured(FormatText))
+}
+
+func TestEncode(t *testing.T) {
+	v := struct {
+		Name   string            `json:"name"`
+		Values map[string]string `json:"values"`
+		Tags   []string          `json:"tags"`
+		Body   string            `json:"body,omitempty"`
+	}{
+		Name:   "0123",
+		Values: map[string]string{"b": "true", "a": "foo"},
+		Tags:   []string{},
+		Body:   "foo\nbar\n",
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, Encode(buf, FormatJSON, v))
+	assert.Equal(t, `{
+  "name": "0123",
+  "values": {
+    "a": "foo",
+    "b": "true"
+  },
+  "tags": [],
+  "body": "foo\nbar\n"
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, Encode(buf, FormatYAML, v))
+	assert.Equal(t, `name: "0123"
+values:
+  a: foo
+  b: "true"
+tags: []
+body: |
+  foo
+  bar
+`, buf.String())
+
+	assert.Error(t, Encode(buf, "xml", v))
+}
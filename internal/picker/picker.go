@@ -0,0 +1,104 @@
+// Package picker lets the user select one of many entries with a dmenu style
+// program. The entries are written to its stdin, one per line, and the
+// selected one is read from its stdout. rofi, wofi and dmenu are detected
+// automatically, any other program reading lines from stdin can be
+// configured.
+package picker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	shellquote "github.com/kballard/go-shellquote"
+)
+
+var (
+	// ErrCancelled is returned if the user closed the picker without
+	// selecting an entry
+	ErrCancelled = errors.New("picker cancelled")
+	// ErrNoPicker is returned if no picker is configured and none of the
+	// known ones is installed
+	ErrNoPicker = errors.New("no picker found")
+)
+
+// pickers are the arguments of the known pickers, the prompt is appended
+var pickers = map[string][]string{
+	"rofi":  {"rofi", "-dmenu", "-i", "-p"},
+	"wofi":  {"wofi", "--dmenu", "--insensitive", "--prompt"},
+	"dmenu": {"dmenu", "-i", "-p"},
+}
+
+// lookPath is replaced in tests
+var lookPath = exec.LookPath
+
+// Command returns the picker command line. The configured command is split
+// like a shell would, otherwise the first installed picker for the current
+// session type is used.
+func Command(cmd, prompt string) ([]string, error) {
+	if cmd != "" {
+		args, err := shellquote.Split(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse picker command %q: %w", cmd, err)
+		}
+		if len(args) < 1 {
+			return nil, fmt.Errorf("empty picker command %q", cmd)
+		}
+		return args, nil
+	}
+
+	var candidates []string
+	switch {
+	case os.Getenv("XDG_SESSION_TYPE") == "wayland" || os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = []string{"wofi", "rofi"}
+	case os.Getenv("DISPLAY") != "":
+		candidates = []string{"rofi", "dmenu"}
+	default:
+		return nil, fmt.Errorf("%w: no graphical session. Please set picker.cmd", ErrNoPicker)
+	}
+
+	for _, name := range candidates {
+		if _, err := lookPath(name); err == nil {
+			args := append([]string{}, pickers[name]...)
+			return append(args, prompt), nil
+		}
+	}
+	return nil, fmt.Errorf("%w. Please install %s or set picker.cmd", ErrNoPicker, strings.Join(candidates, " or "))
+}
+
+// Pick runs the picker command with the entries on stdin and returns the
+// selected one. A non-zero exit status or an empty selection means the user
+// cancelled.
+func Pick(ctx context.Context, args []string, entries []string) (string, error) {
+	if len(args) < 1 {
+		return "", ErrNoPicker
+	}
+
+	buf := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(strings.Join(entries, "\n") + "\n")
+	cmd.Stdout = buf
+	cmd.Stderr = os.Stderr
+
+	debug.Log("Picking one of %d entries with %v", len(entries), args)
+	if err := cmd.Run(); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			debug.Log("%s exited with %d", args[0], ee.ExitCode())
+			return "", ErrCancelled
+		}
+		return "", fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+
+	sel := strings.TrimSpace(strings.SplitN(buf.String(), "\n", 2)[0])
+	if sel == "" {
+		return "", ErrCancelled
+	}
+	return sel, nil
+}
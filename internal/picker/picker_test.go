@@ -0,0 +1,82 @@
+package picker
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	defer func() {
+		lookPath = exec.LookPath
+	}()
+	installed := map[string]bool{}
+	lookPath = func(name string) (string, error) {
+		if installed[name] {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	args, err := Command(`my-picker --title "gopass secrets"`, "gopass")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"my-picker", "--title", "gopass secrets"}, args)
+	_, err = Command(`my-picker "unterminated`, "gopass")
+	assert.Error(t, err)
+
+	t.Setenv("XDG_SESSION_TYPE", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("DISPLAY", "")
+	_, err = Command("", "gopass")
+	assert.ErrorIs(t, err, ErrNoPicker)
+
+	t.Setenv("DISPLAY", ":0")
+	_, err = Command("", "gopass")
+	assert.ErrorIs(t, err, ErrNoPicker)
+
+	installed["dmenu"] = true
+	args, err = Command("", "gopass")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dmenu", "-i", "-p", "gopass"}, args)
+
+	installed["rofi"] = true
+	args, err = Command("", "gopass")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rofi", "-dmenu", "-i", "-p", "gopass"}, args)
+
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	args, err = Command("", "gopass")
+	require.NoError(t, err)
+	assert.Equal(t, "rofi", args[0])
+	installed["wofi"] = true
+	args, err = Command("", "gopass")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wofi", "--dmenu", "--insensitive", "--prompt", "gopass"}, args)
+}
+
+func TestPick(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh on windows")
+	}
+
+	ctx := context.Background()
+	entries := []string{"foo", "bar/baz", "zab"}
+
+	sel, err := Pick(ctx, []string{"sh", "-c", "grep baz"}, entries)
+	require.NoError(t, err)
+	assert.Equal(t, "bar/baz", sel)
+
+	_, err = Pick(ctx, []string{"sh", "-c", "exit 1"}, entries)
+	assert.ErrorIs(t, err, ErrCancelled)
+
+	_, err = Pick(ctx, []string{"sh", "-c", "cat >/dev/null"}, entries)
+	assert.ErrorIs(t, err, ErrCancelled)
+
+	_, err = Pick(ctx, []string{"/nonexistent/picker"}, entries)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCancelled)
+}
@@ -0,0 +1,182 @@
+// Package policy implements the password policies of a store. The policy in
+// .gopass/policy/<prefix> applies to all secrets below the folder prefix,
+// e.g. .gopass/policy/infra/prod to infra/prod/db. Policy files are not
+// encrypted, so they can be reviewed and linted without the keys of the
+// store:
+//
+//	# production credentials
+//	min-entropy: 80
+//	min-length: 16
+//	required: lower, upper, digit, symbol
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gopasspw/gopass/pkg/strength"
+)
+
+// Dir is the folder of a store holding the policy files
+const Dir = ".gopass/policy"
+
+// classes are the character classes a policy can require
+var classes = map[string]func(rune) bool{
+	"lower":  unicode.IsLower,
+	"upper":  unicode.IsUpper,
+	"digit":  unicode.IsDigit,
+	"symbol": func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSymbol(r) },
+}
+
+// Policy lists the requirements for the passwords below a folder
+type Policy struct {
+	// Prefix is the folder the policy applies to, without a trailing slash
+	Prefix string
+	// MinEntropy is the minimum estimated entropy in bits
+	MinEntropy float64
+	// MinLength is the minimum number of characters
+	MinLength int
+	// Required are the character classes that must occur at least once
+	Required []string
+}
+
+// Violation is a rule of a policy a password doesn't satisfy
+type Violation struct {
+	// Rule is the rule as written in the policy file, e.g. "min-length: 16".
+	// It never contains (parts of) the password.
+	Rule string
+	// Detail describes by how much the password misses the rule
+	Detail string
+}
+
+// String implements fmt.Stringer
+func (v Violation) String() string {
+	if v.Detail == "" {
+		return v.Rule
+	}
+	return fmt.Sprintf("%s (%s)", v.Rule, v.Detail)
+}
+
+// File returns the name of the policy file for the folder prefix
+func File(prefix string) string {
+	return Dir + "/" + strings.Trim(prefix, "/")
+}
+
+// Prefixes returns the folders whose policy could apply to the secret name,
+// the most specific one first
+func Prefixes(name string) []string {
+	var prefixes []string
+	for dir := path.Dir(strings.Trim(name, "/")); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		prefixes = append(prefixes, dir)
+	}
+	return prefixes
+}
+
+// Parse parses a policy file. Each line holds a rule in the form "key: value",
+// empty lines and lines starting with # are ignored. Unknown rules are an
+// error, so that a typo can't silently weaken a policy.
+func Parse(buf []byte) (Policy, error) {
+	var p Policy
+	seen := make(map[string]int, 3)
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	lineNo := 0
+	for s.Scan() {
+		lineNo++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) < 2 || strings.TrimSpace(kv[1]) == "" {
+			return p, fmt.Errorf("line %d: want key: value, got %q", lineNo, line)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if prev, found := seen[key]; found {
+			return p, fmt.Errorf("line %d: %s is set in line %d already", lineNo, key, prev)
+		}
+		seen[key] = lineNo
+
+		switch key {
+		case "min-entropy":
+			fv, err := strconv.ParseFloat(value, 64)
+			if err != nil || fv <= 0 {
+				return p, fmt.Errorf("line %d: min-entropy must be a positive number of bits, got %q", lineNo, value)
+			}
+			p.MinEntropy = fv
+		case "min-length":
+			iv, err := strconv.Atoi(value)
+			if err != nil || iv <= 0 {
+				return p, fmt.Errorf("line %d: min-length must be a positive number, got %q", lineNo, value)
+			}
+			p.MinLength = iv
+		case "required":
+			for _, class := range strings.Split(value, ",") {
+				class = strings.TrimSpace(class)
+				if _, found := classes[class]; !found {
+					return p, fmt.Errorf("line %d: unknown character class %q. Must be one of %s", lineNo, class, strings.Join(classNames(), ", "))
+				}
+				p.Required = append(p.Required, class)
+			}
+		default:
+			return p, fmt.Errorf("line %d: unknown rule %q. Must be one of min-entropy, min-length, required", lineNo, key)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return p, err
+	}
+	if len(seen) == 0 {
+		return p, fmt.Errorf("no rules")
+	}
+	return p, nil
+}
+
+// Check returns the rules of the policy the password violates, if any. The
+// user inputs (e.g. the secret name) are treated like dictionary words when
+// estimating the entropy.
+func (p Policy) Check(pw string, userInputs ...string) []Violation {
+	var vs []Violation
+	if n := len([]rune(pw)); p.MinLength > 0 && n < p.MinLength {
+		vs = append(vs, Violation{
+			Rule:   fmt.Sprintf("min-length: %d", p.MinLength),
+			Detail: fmt.Sprintf("has %d", n),
+		})
+	}
+	for _, class := range p.Required {
+		if strings.IndexFunc(pw, classes[class]) < 0 {
+			vs = append(vs, Violation{Rule: "required: " + class})
+		}
+	}
+	if p.MinEntropy > 0 {
+		if e := strength.Check(pw, userInputs...); e.Entropy < p.MinEntropy {
+			vs = append(vs, Violation{
+				Rule:   fmt.Sprintf("min-entropy: %s", strconv.FormatFloat(p.MinEntropy, 'f', -1, 64)),
+				Detail: fmt.Sprintf("has %.0f", e.Entropy),
+			})
+		}
+	}
+	return vs
+}
+
+// Rules joins the rules of the violations, e.g. for a commit trailer
+func Rules(vs []Violation) string {
+	rules := make([]string, 0, len(vs))
+	for _, v := range vs {
+		rules = append(rules, v.Rule)
+	}
+	return strings.Join(rules, "; ")
+}
+
+func classNames() []string {
+	names := make([]string, 0, len(classes))
+	for name := range classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
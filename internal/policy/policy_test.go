@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte("# production credentials\n\nmin-entropy: 80\nmin-length: 16\nrequired: lower, upper,digit, symbol\n"))
+	require.NoError(t, err)
+	assert.Equal(t, Policy{
+		MinEntropy: 80,
+		MinLength:  16,
+		Required:   []string{"lower", "upper", "digit", "symbol"},
+	}, p)
+
+	for _, tc := range []struct {
+		in  string
+		err string
+	}{
+		{"", "no rules"},
+		{"# nothing\n", "no rules"},
+		{"min-entropy 80\n", "line 1: want key: value"},
+		{"min-length:\n", "line 1: want key: value"},
+		{"min-entropy: lots\n", "line 1: min-entropy must be a positive number"},
+		{"min-length: 8\nmin-length: -1\n", "line 2: min-length is set in line 1 already"},
+		{"min-length: 0\n", "line 1: min-length must be a positive number"},
+		{"required: lower, emoji\n", `line 1: unknown character class "emoji". Must be one of digit, lower, symbol, upper`},
+		{"minlength: 8\n", `line 1: unknown rule "minlength"`},
+	} {
+		_, err := Parse([]byte(tc.in))
+		require.Error(t, err, tc.in)
+		assert.Contains(t, err.Error(), tc.err, tc.in)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := Policy{
+		MinEntropy: 80,
+		MinLength:  16,
+		Required:   []string{"upper", "symbol"},
+	}
+
+	assert.Empty(t, p.Check("Tk9#vQ2$mW7!pL4@xR8&"))
+
+	vs := p.Check("foobar")
+	require.Len(t, vs, 4)
+	assert.Equal(t, "min-length: 16 (has 6)", vs[0].String())
+	assert.Equal(t, "required: upper", vs[1].String())
+	assert.Equal(t, "required: symbol", vs[2].String())
+	assert.Equal(t, "min-entropy: 80", vs[3].Rule)
+	assert.Equal(t, "min-length: 16; required: upper; required: symbol; min-entropy: 80", Rules(vs))
+	for _, v := range vs {
+		assert.NotContains(t, v.String(), "foobar")
+	}
+
+	// the user inputs lower the estimate
+	p = Policy{MinEntropy: 40}
+	assert.Empty(t, p.Check("correcthorsebatterystaple"))
+	assert.Len(t, p.Check("correcthorsebatterystaple", "correcthorsebatterystaple"), 1)
+}
+
+func TestPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"infra/prod", "infra"}, Prefixes("infra/prod/db"))
+	assert.Equal(t, []string{"infra/prod", "infra"}, Prefixes("/infra/prod/db/"))
+	assert.Empty(t, Prefixes("db"))
+	assert.Equal(t, ".gopass/policy/infra/prod", File("infra/prod/"))
+}
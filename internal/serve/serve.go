@@ -0,0 +1,374 @@
+// Package serve implements a minimal read-only REST API, so other tools on
+// the same machine can read secrets without running gopass for each of them.
+// Every request must carry the bearer token the server was started with.
+package serve
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// DefaultListen is the default address of the API
+const DefaultListen = "127.0.0.1:9777"
+
+// tokenBytes is the number of random bytes of a generated token
+const tokenBytes = 32
+
+// requestTimeout is the maximum time to read a request and send the answer
+const requestTimeout = 30 * time.Second
+
+// Store is the part of the password store served by the API
+type Store interface {
+	List(ctx context.Context, maxDepth int) ([]string, error)
+	Resolve(ctx context.Context, name string) (string, gopass.Secret, error)
+}
+
+// Activity is told about every authenticated request, e.g. to lock the store
+// after a period of inactivity
+type Activity interface {
+	Begin()
+	End()
+}
+
+// Config configures a Server
+type Config struct {
+	// Listen is the TCP address to listen on, e.g. 127.0.0.1:9777. It's
+	// ignored if Socket is set.
+	Listen string
+	// Socket is the path of a unix socket to listen on instead
+	Socket string
+	// Token must be sent as bearer token with every request
+	Token string
+	// CertFile and KeyFile enable TLS
+	CertFile string
+	KeyFile  string
+	// Allow are the folders that may be served, all secrets if empty
+	Allow []string
+	// InsecureRemote allows listening on addresses other than loopback
+	InsecureRemote bool
+	// Log receives an entry for every request. It contains the secret names
+	// but never their content.
+	Log *log.Logger
+	// Activity is optional
+	Activity Activity
+}
+
+// Server serves the secrets of a store
+type Server struct {
+	cfg   Config
+	store Store
+	log   *log.Logger
+}
+
+// NewServer creates a new server
+func NewServer(s Store, cfg Config) *Server {
+	l := cfg.Log
+	if l == nil {
+		l = log.New(io.Discard, "", 0)
+	}
+	return &Server{
+		cfg:   cfg,
+		store: s,
+		log:   l,
+	}
+}
+
+// NewToken returns a random token
+func NewToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ReadToken reads the token from the first line of a file
+func ReadToken(filename string) (string, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	token := strings.TrimSpace(strings.SplitN(string(buf), "\n", 2)[0])
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", filename)
+	}
+	return token, nil
+}
+
+// Address returns the address the server listens on, for humans
+func (s *Server) Address() string {
+	if s.cfg.Socket != "" {
+		return "unix:" + s.cfg.Socket
+	}
+	scheme := "http"
+	if s.cfg.CertFile != "" {
+		scheme = "https"
+	}
+	return scheme + "://" + s.cfg.Listen
+}
+
+// Check validates the config without listening
+func (s *Server) Check() error {
+	if s.cfg.Token == "" {
+		return fmt.Errorf("no token")
+	}
+	if (s.cfg.CertFile == "") != (s.cfg.KeyFile == "") {
+		return fmt.Errorf("both a TLS certificate and a key are required")
+	}
+	if s.cfg.Socket != "" {
+		return nil
+	}
+	return CheckListen(s.cfg.Listen, s.cfg.InsecureRemote)
+}
+
+// CheckListen returns an error if addr is not a loopback address unless
+// remote addresses are allowed
+func CheckListen(addr string, insecureRemote bool) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if insecureRemote || isLoopback(host) {
+		return nil
+	}
+	return fmt.Errorf("refusing to listen on %s, it's not a loopback address. Use --insecure-remote to allow it", addr)
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Run serves the API until the context is canceled
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.Check(); err != nil {
+		return err
+	}
+
+	l, err := s.listen()
+	if err != nil {
+		return err
+	}
+	debug.Log("API listening on %s", s.Address())
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: requestTimeout,
+		WriteTimeout:      requestTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	go func() {
+		<-ctx.Done()
+		sctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}()
+
+	if s.cfg.CertFile != "" {
+		err = srv.ServeTLS(l, s.cfg.CertFile, s.cfg.KeyFile)
+	} else {
+		err = srv.Serve(l)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	if s.cfg.Socket == "" {
+		l, err := net.Listen("tcp", s.cfg.Listen)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", s.cfg.Listen, err)
+		}
+		return l, nil
+	}
+
+	if conn, err := net.Dial("unix", s.cfg.Socket); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("a server is already listening on %s", s.cfg.Socket)
+	}
+	// remove a stale socket left by a server that was killed
+	_ = os.Remove(s.cfg.Socket)
+
+	l, err := net.Listen("unix", s.cfg.Socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", s.cfg.Socket, err)
+	}
+	if err := os.Chmod(s.cfg.Socket, 0600); err != nil {
+		_ = l.Close()
+		return nil, fmt.Errorf("failed to restrict access to %s: %w", s.cfg.Socket, err)
+	}
+	return l, nil
+}
+
+// Handler returns the handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/secrets", s.listSecrets)
+	mux.HandleFunc("/v1/secret/", s.getSecret)
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			s.log.Printf("%s %s %s: invalid token", r.RemoteAddr, r.Method, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, "the API is read-only")
+			return
+		}
+		if s.cfg.Activity != nil {
+			s.cfg.Activity.Begin()
+			defer s.cfg.Activity.End()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// secretList is the answer of GET /v1/secrets. It must be kept stable.
+type secretList struct {
+	Secrets []string `json:"secrets"`
+}
+
+func (s *Server) listSecrets(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	s.log.Printf("%s list %q", r.RemoteAddr, prefix)
+
+	names, err := s.store.List(r.Context(), tree.INF)
+	if err != nil {
+		debug.Log("failed to list secrets: %s", err)
+		writeError(w, http.StatusInternalServerError, "failed to list secrets")
+		return
+	}
+
+	res := secretList{Secrets: make([]string, 0, len(names))}
+	for _, name := range names {
+		if inFolder(name, prefix) && s.allowed(name) {
+			res.Secrets = append(res.Secrets, name)
+		}
+	}
+	sort.Strings(res.Secrets)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// secretOutput is the answer of GET /v1/secret/<name>, like show --format
+// json without the metadata. It must be kept stable.
+type secretOutput struct {
+	Name     string              `json:"name"`
+	Password string              `json:"password"`
+	Values   map[string][]string `json:"values"`
+	Body     string              `json:"body,omitempty"`
+}
+
+func (s *Server) getSecret(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/v1/secret/")
+	if name == "" || path.Clean("/" + name)[1:] != name {
+		s.log.Printf("%s get %q: invalid name", r.RemoteAddr, name)
+		writeError(w, http.StatusBadRequest, "invalid name")
+		return
+	}
+	if !s.allowed(name) {
+		s.log.Printf("%s get %q: not allowed", r.RemoteAddr, name)
+		writeError(w, http.StatusForbidden, "not allowed")
+		return
+	}
+
+	target, sec, err := s.store.Resolve(r.Context(), name)
+	if err != nil {
+		s.log.Printf("%s get %q: %s", r.RemoteAddr, name, err)
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to read secret")
+		return
+	}
+	// a link must not leak a secret outside of the allowed folders
+	if !s.allowed(target) {
+		s.log.Printf("%s get %q: link to %q not allowed", r.RemoteAddr, name, target)
+		writeError(w, http.StatusForbidden, "not allowed")
+		return
+	}
+	s.log.Printf("%s get %q", r.RemoteAddr, name)
+
+	res := secretOutput{
+		Name:     name,
+		Password: sec.Password(),
+		Values:   make(map[string][]string, len(sec.Keys())),
+		Body:     sec.Body(),
+	}
+	for _, k := range sec.Keys() {
+		if vs, found := sec.Values(k); found {
+			res.Values[k] = vs
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// allowed returns true if the secret is below one of the allowed folders
+func (s *Server) allowed(name string) bool {
+	if len(s.cfg.Allow) < 1 {
+		return true
+	}
+	for _, prefix := range s.cfg.Allow {
+		if inFolder(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// inFolder returns true if name is the folder or below it. An empty folder
+// contains everything.
+func inFolder(name, folder string) bool {
+	folder = strings.Trim(folder, "/")
+	if folder == "" {
+		return true
+	}
+	return name == folder || strings.HasPrefix(name, folder+"/")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		debug.Log("failed to send answer: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
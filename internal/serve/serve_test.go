@@ -0,0 +1,218 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore map[string]string
+
+func (f fakeStore) List(context.Context, int) ([]string, error) {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f fakeStore) Resolve(_ context.Context, name string) (string, gopass.Secret, error) {
+	if target := strings.TrimPrefix(f[name], "link:"); target != f[name] {
+		name = target
+	}
+	content, found := f[name]
+	if !found {
+		return name, nil, store.ErrNotFound
+	}
+	sec, err := secrets.ParseKV([]byte(content))
+	return name, sec, err
+}
+
+func testStore() fakeStore {
+	return fakeStore{
+		"infra/db":       "s3cret\nuser: admin\nnotes",
+		"infra/web":      "w3b\n",
+		"infrastructure": "other\n",
+		"personal/bank":  "b4nk\n",
+		"infra/bank":     "link:personal/bank",
+	}
+}
+
+func request(t *testing.T, h http.Handler, method, url, token string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, url, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	res := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res), rec.Body.String())
+	return rec.Code, res
+}
+
+func TestHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	s := NewServer(testStore(), Config{
+		Token: "t0ken",
+		Allow: []string{"infra/"},
+		Log:   log.New(buf, "", 0),
+	})
+	h := s.Handler()
+
+	t.Run("list", func(t *testing.T) {
+		code, res := request(t, h, http.MethodGet, "/v1/secrets", "t0ken")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []interface{}{"infra/bank", "infra/db", "infra/web"}, res["secrets"])
+
+		code, res = request(t, h, http.MethodGet, "/v1/secrets?prefix=infra/db", "t0ken")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []interface{}{"infra/db"}, res["secrets"])
+
+		code, res = request(t, h, http.MethodGet, "/v1/secrets?prefix=personal", "t0ken")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []interface{}{}, res["secrets"])
+	})
+
+	t.Run("get", func(t *testing.T) {
+		buf.Reset()
+		code, res := request(t, h, http.MethodGet, "/v1/secret/infra/db", "t0ken")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "infra/db", res["name"])
+		assert.Equal(t, "s3cret", res["password"])
+		assert.Equal(t, map[string]interface{}{"user": []interface{}{"admin"}}, res["values"])
+		assert.Equal(t, "notes", res["body"])
+		assert.Contains(t, buf.String(), `get "infra/db"`)
+		assert.NotContains(t, buf.String(), "s3cret")
+	})
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		url    string
+		token  string
+		code   int
+	}{
+		{name: "no token", method: http.MethodGet, url: "/v1/secret/infra/db", code: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, url: "/v1/secrets", token: "t0ke", code: http.StatusUnauthorized},
+		{name: "read-only", method: http.MethodPost, url: "/v1/secret/infra/db", token: "t0ken", code: http.StatusMethodNotAllowed},
+		{name: "not allowed", method: http.MethodGet, url: "/v1/secret/personal/bank", token: "t0ken", code: http.StatusForbidden},
+		{name: "similar folder", method: http.MethodGet, url: "/v1/secret/infrastructure", token: "t0ken", code: http.StatusForbidden},
+		{name: "link out of the allowed folders", method: http.MethodGet, url: "/v1/secret/infra/bank", token: "t0ken", code: http.StatusForbidden},
+		{name: "not found", method: http.MethodGet, url: "/v1/secret/infra/missing", token: "t0ken", code: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			code, res := request(t, h, tc.method, tc.url, tc.token)
+			assert.Equal(t, tc.code, code)
+			assert.NotEmpty(t, res["error"])
+			assert.NotContains(t, res, "password")
+		})
+	}
+
+	t.Run("escape", func(t *testing.T) {
+		for _, name := range []string{"infra/../personal/bank", "infra//db", "infra/db/"} {
+			rec := httptest.NewRecorder()
+			s.getSecret(rec, httptest.NewRequest(http.MethodGet, "/v1/secret/"+name, nil))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, name)
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		ok   bool
+	}{
+		{addr: "127.0.0.1:9777", ok: true},
+		{addr: "[::1]:9777", ok: true},
+		{addr: "localhost:9777", ok: true},
+		{addr: ":9777"},
+		{addr: "0.0.0.0:9777"},
+		{addr: "192.168.1.2:9777"},
+		{addr: "example.com:9777"},
+		{addr: "127.0.0.1"},
+	} {
+		err := CheckListen(tc.addr, false)
+		if tc.ok {
+			assert.NoError(t, err, tc.addr)
+		} else {
+			assert.Error(t, err, tc.addr)
+		}
+	}
+	assert.NoError(t, CheckListen("0.0.0.0:9777", true))
+
+	assert.Error(t, NewServer(testStore(), Config{Listen: DefaultListen}).Check(), "no token")
+	assert.Error(t, NewServer(testStore(), Config{Listen: DefaultListen, Token: "t0ken", CertFile: "cert.pem"}).Check(), "no key")
+	assert.NoError(t, NewServer(testStore(), Config{Socket: "/tmp/gopass.sock", Token: "t0ken"}).Check())
+}
+
+func TestReadToken(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(fn, []byte("t0ken\n"), 0600))
+	token, err := ReadToken(fn)
+	require.NoError(t, err)
+	assert.Equal(t, "t0ken", token)
+
+	require.NoError(t, os.WriteFile(fn, []byte("\n"), 0600))
+	_, err = ReadToken(fn)
+	assert.Error(t, err)
+
+	token, err = NewToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 2*tokenBytes)
+}
+
+func TestRunSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(testStore(), Config{Socket: socket, Token: "t0ken"}).Run(ctx)
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	req, err := http.NewRequest(http.MethodGet, "http://gopass/v1/secret/infra/web", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer t0ken")
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = client.Do(req)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	fi, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,95 @@
+// Package share implements the plaintext format of a secret shared with
+// gopass share. It's encrypted for someone who is not a recipient of the
+// store and embeds when it expires. The expiry can't be enforced, the
+// receiving gopass refuses to show an expired secret.
+package share
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// version is the value of the first header, it's changed if the format
+	// changes in an incompatible way
+	version       = "1"
+	headerShare   = "Gopass-Share"
+	headerName    = "Name"
+	headerExpires = "Expires"
+)
+
+// Secret is a shared secret
+type Secret struct {
+	// Name is the name of the secret in the store it was shared from
+	Name string
+	// Expires is the time after which it must not be shown anymore
+	Expires time.Time
+	// Content is the secret as stored
+	Content []byte
+}
+
+// Bytes returns the plaintext of the shared secret. The headers are followed
+// by an empty line and the content.
+func (s Secret) Bytes() []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s: %s\n", headerShare, version)
+	fmt.Fprintf(buf, "%s: %s\n", headerName, s.Name)
+	fmt.Fprintf(buf, "%s: %s\n", headerExpires, s.Expires.UTC().Format(time.RFC3339))
+	buf.WriteString("\n")
+	buf.Write(s.Content)
+	return buf.Bytes()
+}
+
+// Expired returns true if the secret must not be shown anymore
+func (s Secret) Expired(now time.Time) bool {
+	return !now.Before(s.Expires)
+}
+
+// Parse parses the plaintext of a shared secret
+func Parse(buf []byte) (Secret, error) {
+	var s Secret
+	br := bufio.NewReader(bytes.NewReader(buf))
+
+	headers := map[string]string{}
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return s, fmt.Errorf("not a shared secret: the headers are incomplete")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			return s, fmt.Errorf("not a shared secret: invalid header %q", line)
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	if v := headers[headerShare]; v != version {
+		return s, fmt.Errorf("not a shared secret or unsupported version %q", v)
+	}
+	s.Name = headers[headerName]
+
+	exp, found := headers[headerExpires]
+	if !found {
+		return s, fmt.Errorf("shared secret without %s header", headerExpires)
+	}
+	t, err := time.Parse(time.RFC3339, exp)
+	if err != nil {
+		return s, fmt.Errorf("invalid %s header %q: %w", headerExpires, exp, err)
+	}
+	s.Expires = t
+
+	content, err := io.ReadAll(br)
+	if err != nil {
+		return s, err
+	}
+	s.Content = content
+	return s, nil
+}
@@ -0,0 +1,45 @@
+package share
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	exp := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s := Secret{
+		Name:    "infra/db",
+		Expires: exp,
+		Content: []byte("s3cret\nuser: admin\n"),
+	}
+
+	buf := s.Bytes()
+	assert.Equal(t, "Gopass-Share: 1\nName: infra/db\nExpires: 2026-10-15T12:00:00Z\n\ns3cret\nuser: admin\n", string(buf))
+
+	got, err := Parse(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "infra/db", got.Name)
+	assert.True(t, exp.Equal(got.Expires))
+	assert.Equal(t, s.Content, got.Content)
+
+	assert.False(t, got.Expired(exp.Add(-time.Second)))
+	assert.True(t, got.Expired(exp))
+	assert.True(t, got.Expired(exp.Add(time.Hour)))
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"s3cret\nuser: admin\n",
+		"Gopass-Share: 2\nExpires: 2026-10-15T12:00:00Z\n\ns3cret",
+		"Gopass-Share: 1\nName: infra/db\n\ns3cret",
+		"Gopass-Share: 1\nExpires: tomorrow\n\ns3cret",
+		"Gopass-Share: 1\nExpires: 2026-10-15T12:00:00Z\n",
+	} {
+		_, err := Parse([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
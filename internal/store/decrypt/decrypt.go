@@ -0,0 +1,145 @@
+// Package decrypt implements a concurrent pipeline to decrypt many secrets,
+// e.g. for searching or auditing a store. Secrets are decrypted by a
+// bounded number of workers, but the results are always handed to the
+// consumer in the order of the input, regardless of completion order.
+package decrypt
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// maxJobs is the default upper bound for the number of concurrent workers
+const maxJobs = 8
+
+// Getter is the part of a store needed to decrypt secrets
+type Getter interface {
+	Get(context.Context, string) (gopass.Secret, error)
+}
+
+// Result is a decrypted secret or the error that occurred while decrypting it
+type Result struct {
+	Name   string
+	Secret gopass.Secret
+	Err    error
+}
+
+type indexed struct {
+	idx int
+	Result
+}
+
+// Jobs returns the number of concurrent workers. It uses the workers set in
+// the context, if any, or the number of CPUs, but at most maxJobs.
+func Jobs(ctx context.Context) int {
+	if n := ctxutil.GetWorkers(ctx); n > 0 {
+		return n
+	}
+	if n := runtime.NumCPU(); n < maxJobs {
+		return n
+	}
+	return maxJobs
+}
+
+// All decrypts all given secrets and calls fn for each of them, in the order
+// of names. The first secret is decrypted before any concurrent workers are
+// started since it might trigger a pinentry. If fn returns an error or the
+// context is canceled all outstanding decryptions are canceled and the error
+// is returned.
+func All(ctx context.Context, g Getter, names []string, fn func(Result) error) error {
+	if len(names) < 1 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := Jobs(ctx)
+	debug.Log("decrypting %d secrets with %d workers", len(names), jobs)
+
+	first := get(ctx, g, names[0])
+	if err := fn(first); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	pending := make(chan int)
+	results := make(chan indexed, jobs)
+
+	// producer
+	go func() {
+		defer close(pending)
+		for i := 1; i < len(names); i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case pending <- i:
+			}
+		}
+	}()
+
+	// workers
+	done := make(chan struct{})
+	for i := 0; i < jobs; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for idx := range pending {
+				r := indexed{idx: idx, Result: get(ctx, g, names[idx])}
+				select {
+				case <-ctx.Done():
+					return
+				case results <- r:
+				}
+			}
+		}()
+	}
+	go func() {
+		for i := 0; i < jobs; i++ {
+			<-done
+		}
+		close(results)
+	}()
+
+	// consumer, reorders the results to match the input
+	next := 1
+	buffered := make(map[int]Result, jobs)
+	for r := range results {
+		buffered[r.idx] = r.Result
+		for {
+			res, found := buffered[next]
+			if !found {
+				break
+			}
+			delete(buffered, next)
+			next++
+			if err := fn(res); err != nil {
+				cancel()
+				drain(results)
+				return err
+			}
+		}
+	}
+
+	return ctx.Err()
+}
+
+func get(ctx context.Context, g Getter, name string) Result {
+	sec, err := g.Get(ctx, name)
+	return Result{
+		Name:   name,
+		Secret: sec,
+		Err:    err,
+	}
+}
+
+// drain consumes any remaining results so the workers can terminate
+func drain(results <-chan indexed) {
+	for range results {
+	}
+}
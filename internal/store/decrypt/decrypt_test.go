@@ -0,0 +1,160 @@
+package decrypt
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowGetter returns secrets after a random delay and fails for any
+// name containing "broken"
+type slowGetter struct {
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	started   []string
+}
+
+func (g *slowGetter) Get(ctx context.Context, name string) (gopass.Secret, error) {
+	g.mu.Lock()
+	g.active++
+	if g.active > g.maxActive {
+		g.maxActive = g.active
+	}
+	g.started = append(g.started, name)
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.active--
+		g.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(rand.Intn(2000)) * time.Microsecond):
+	}
+
+	if strings.Contains(name, "broken") {
+		return nil, fmt.Errorf("failed to decrypt")
+	}
+	sec := secrets.New()
+	sec.SetPassword("pw-" + name)
+	return sec, nil
+}
+
+func testNames(n int) []string {
+	names := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("secret/%03d", i)
+		if i%7 == 3 {
+			name += "-broken"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func collect(t *testing.T, ctx context.Context, g Getter, names []string) []string {
+	t.Helper()
+
+	res := make([]string, 0, len(names))
+	require.NoError(t, All(ctx, g, names, func(r Result) error {
+		if r.Err != nil {
+			res = append(res, r.Name+": "+r.Err.Error())
+			return nil
+		}
+		res = append(res, r.Name+": "+r.Secret.Password())
+		return nil
+	}))
+	return res
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	names := testNames(100)
+
+	sequential := collect(t, ctxutil.WithWorkers(ctx, 1), &slowGetter{}, names)
+	require.Len(t, sequential, len(names))
+	for i, name := range names {
+		assert.True(t, strings.HasPrefix(sequential[i], name+": "), sequential[i])
+	}
+
+	for _, jobs := range []int{2, 8, 32} {
+		g := &slowGetter{}
+		assert.Equal(t, sequential, collect(t, ctxutil.WithWorkers(ctx, jobs), g, names), "jobs=%d", jobs)
+		assert.LessOrEqual(t, g.maxActive, jobs)
+	}
+}
+
+func TestAllEmpty(t *testing.T) {
+	assert.NoError(t, All(context.Background(), &slowGetter{}, nil, func(Result) error {
+		t.Fatal("must not be called")
+		return nil
+	}))
+}
+
+func TestAllFirstAlone(t *testing.T) {
+	ctx := ctxutil.WithWorkers(context.Background(), 8)
+	g := &slowGetter{}
+
+	require.NoError(t, All(ctx, g, testNames(20), func(r Result) error {
+		if r.Name == "secret/000" {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			// no other decryption may start before the first one finished
+			assert.Equal(t, []string{"secret/000"}, g.started)
+		}
+		return nil
+	}))
+}
+
+func TestAllAbort(t *testing.T) {
+	ctx := ctxutil.WithWorkers(context.Background(), 4)
+
+	var seen []string
+	err := All(ctx, &slowGetter{}, testNames(50), func(r Result) error {
+		seen = append(seen, r.Name)
+		if len(seen) == 10 {
+			return fmt.Errorf("stop")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, testNames(50)[:10], seen)
+}
+
+func TestAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(ctxutil.WithWorkers(context.Background(), 4))
+	defer cancel()
+
+	n := 0
+	err := All(ctx, &slowGetter{}, testNames(100), func(r Result) error {
+		n++
+		if n == 5 {
+			cancel()
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, n, 100)
+}
+
+func TestJobs(t *testing.T) {
+	ctx := context.Background()
+
+	assert.GreaterOrEqual(t, Jobs(ctx), 1)
+	assert.LessOrEqual(t, Jobs(ctx), maxJobs)
+	assert.Equal(t, 16, Jobs(ctxutil.WithWorkers(ctx, 16)))
+}
@@ -19,8 +19,18 @@ var (
 	ErrGitNotInit = fmt.Errorf("git is not initialized")
 	// ErrGitNoRemote is returned if git has no origin remote
 	ErrGitNoRemote = fmt.Errorf("git has no remote origin")
+	// ErrGitNoSigningKey is returned if commits must be signed but there is
+	// no key to sign them
+	ErrGitNoSigningKey = fmt.Errorf("signing commits is enabled but no usable signing key was found")
 	// ErrGitNothingToCommit is returned if there are no staged changes
 	ErrGitNothingToCommit = fmt.Errorf("git has nothing to commit")
+	// ErrRecipientsChanged is returned if the recipients have been changed,
+	// e.g. by a pull, and the user didn't accept the change
+	ErrRecipientsChanged = fmt.Errorf("the recipients have changed since they were last acknowledged. Run 'gopass recipients ack' to accept them")
+	// ErrInvalidName is returned if a secret name can't be used
+	ErrInvalidName = fmt.Errorf("invalid secret name")
+	// ErrReadOnly is returned when changing a read-only mount
+	ErrReadOnly = fmt.Errorf("read-only")
 	// ErrEmptySecret is returned if a secret exists but has no content
 	ErrEmptySecret = fmt.Errorf("empty secret")
 	// ErrNoBody is returned if a secret exists but has no content beyond a password
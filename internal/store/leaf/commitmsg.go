@@ -0,0 +1,161 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+)
+
+// Operations recorded in the Gopass-Op trailer of each commit
+const (
+	OpInsert      = "insert"
+	OpUpdate      = "update"
+	OpRemove      = "remove"
+	OpMove        = "move"
+	OpCopy        = "copy"
+	OpRestore     = "restore"
+	OpMerge       = "merge"
+	OpTemplate    = "template"
+	OpReencrypt   = "re-encrypt"
+	OpRecipients  = "recipients"
+	OpConvert     = "convert"
+	OpMaintenance = "maintenance"
+	OpShare       = "share"
+)
+
+// Git trailers appended to each commit message. Unlike the subject they
+// don't depend on the commit message template, so they are what tools
+// parsing the history, e.g. gopass audit, should rely on.
+const (
+	TrailerOp      = "Gopass-Op"
+	TrailerName    = "Gopass-Name"
+	TrailerFrom    = "Gopass-From"
+	TrailerMount   = "Gopass-Mount"
+	TrailerVersion = "Gopass-Version"
+	// TrailerRecipient and TrailerExpires are only added to share commits
+	TrailerRecipient = "Gopass-Recipient"
+	TrailerExpires   = "Gopass-Expires"
+	// TrailerWeak lists the policy rules a password saved with --force-weak
+	// violates
+	TrailerWeak = "Gopass-Weak"
+)
+
+// hashedPrefix marks hashed secret names in the trailers
+const hashedPrefix = "sha256:"
+
+// hashedTemplate is used instead of the built-in messages, which contain
+// secret names, if names are hashed
+const hashedTemplate = "{{.Op}} {{.Name}}"
+
+// CommitData are the variables available to the commit message template
+type CommitData struct {
+	// Op is one of the Op* operations
+	Op string
+	// Name is the full name of the secret, including the mount, or its hash
+	Name string
+	// From is the previous full name of a moved or copied secret or its hash
+	From string
+	// Mount is the mount point of the store, empty for the root store
+	Mount string
+	// Version is the version of gopass
+	Version string
+	// Message is the built-in commit message. It may contain secret names
+	// even if they are hashed.
+	Message string
+}
+
+// HashName returns the hash of a secret name used instead of the name if
+// git.commitmsghashnames is enabled. It is not salted, so the same name has
+// the same hash in every store and the commits of a secret can be looked up
+// by its name. That makes it a lookup aid that keeps names out of plain
+// sight, not a privacy measure: a guessed name can be confirmed and short
+// or common names can be found by brute force.
+func HashName(name string) string {
+	return hashedPrefix + fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:16]
+}
+
+// CommitMessage renders the message of a commit of this store. It consists
+// of the subject rendered from the commit message template and the gopass
+// trailers. name and from are full names, including the mount, and may be
+// empty. msg is the built-in message used if no template is configured.
+func (s *Store) CommitMessage(ctx context.Context, op, name, from, msg string) string {
+	return s.commitMessage(ctx, op, name, from, msg, nil)
+}
+
+// commitMessage renders a commit message with additional trailers after the
+// common ones
+func (s *Store) commitMessage(ctx context.Context, op, name, from, msg string, extra [][2]string) string {
+	d := CommitData{
+		Op:      op,
+		Name:    name,
+		From:    from,
+		Mount:   s.alias,
+		Version: ctxutil.GetVersion(ctx),
+		Message: msg,
+	}
+	if s.hashNames {
+		if d.Name != "" {
+			d.Name = HashName(d.Name)
+		}
+		if d.From != "" {
+			d.From = HashName(d.From)
+		}
+	}
+
+	lines := []string{s.commitSubject(ctx, d)}
+	for _, t := range append([][2]string{
+		{TrailerOp, d.Op},
+		{TrailerName, d.Name},
+		{TrailerFrom, d.From},
+		{TrailerMount, d.Mount},
+		{TrailerVersion, d.Version},
+	}, extra...) {
+		if t[1] == "" {
+			continue
+		}
+		if len(lines) == 1 {
+			// git only parses trailers in the last paragraph
+			lines = append(lines, "")
+		}
+		lines = append(lines, t[0]+": "+t[1])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// commitSubject renders the commit message template. An invalid template
+// falls back to the built-in message, a commit must never fail because of it.
+func (s *Store) commitSubject(ctx context.Context, d CommitData) string {
+	tmpl := s.commitTemplate
+	if tmpl == "" && (s.hashNames || d.Message == "") {
+		tmpl = hashedTemplate
+	}
+	if tmpl == "" {
+		return d.Message
+	}
+
+	buf := &bytes.Buffer{}
+	t, err := template.New("commit").Parse(tmpl)
+	if err == nil {
+		err = t.Execute(buf, d)
+	}
+	if err != nil {
+		out.Warningf(ctx, "Invalid commit message template (git.commitmsgtemplate): %s", err)
+		if s.hashNames {
+			return strings.TrimSpace(d.Op + " " + d.Name)
+		}
+		return d.Message
+	}
+
+	// git only expects a single subject line
+	subject := strings.Join(strings.Fields(buf.String()), " ")
+	if subject == "" {
+		return d.Op
+	}
+	return subject
+}
@@ -0,0 +1,77 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitMessage(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithVersion(ctx, "1.12.0")
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	s := &Store{alias: "team"}
+	assert.Equal(t, "Save secret to web/foo: Generated Password\n\n"+
+		"Gopass-Op: insert\n"+
+		"Gopass-Name: team/web/foo\n"+
+		"Gopass-Mount: team\n"+
+		"Gopass-Version: 1.12.0", s.CommitMessage(ctx, OpInsert, "team/web/foo", "", "Save secret to web/foo: Generated Password"))
+
+	s = &Store{}
+	assert.Equal(t, "Move from web/foo to web/bar\n\n"+
+		"Gopass-Op: move\n"+
+		"Gopass-Name: web/bar\n"+
+		"Gopass-From: web/foo", s.CommitMessage(context.Background(), OpMove, "web/bar", "web/foo", "Move from web/foo to web/bar"))
+	assert.Equal(t, "re-encrypt\n\nGopass-Op: re-encrypt", s.CommitMessage(context.Background(), OpReencrypt, "", "", ""), "no message")
+
+	t.Run("template", func(t *testing.T) {
+		s := &Store{alias: "team", commitTemplate: "[{{.Mount}}] {{.Op}} {{.Name}}\n{{.Version}}"}
+		assert.Equal(t, "[team] update team/web/foo 1.12.0\n\n"+
+			"Gopass-Op: update\n"+
+			"Gopass-Name: team/web/foo\n"+
+			"Gopass-Mount: team\n"+
+			"Gopass-Version: 1.12.0", s.CommitMessage(ctx, OpUpdate, "team/web/foo", "", "Save secret to web/foo: Edited"))
+	})
+
+	t.Run("hashed names", func(t *testing.T) {
+		s := &Store{hashNames: true}
+		h := HashName("web/foo")
+		assert.Len(t, h, len(hashedPrefix)+16)
+		assert.Equal(t, "remove "+h+"\n\n"+
+			"Gopass-Op: remove\n"+
+			"Gopass-Name: "+h, s.CommitMessage(context.Background(), OpRemove, "web/foo", "", "Remove web/foo from store."))
+
+		// the message is only used if the template asks for it
+		s.commitTemplate = "{{.Op}} {{.From}} -> {{.Name}}: {{.Message}}"
+		assert.Equal(t, "copy "+h+" -> "+HashName("web/bar")+": Copied from web/foo to web/bar\n\n"+
+			"Gopass-Op: copy\n"+
+			"Gopass-Name: "+HashName("web/bar")+"\n"+
+			"Gopass-From: "+h, s.CommitMessage(context.Background(), OpCopy, "web/bar", "web/foo", "Copied from web/foo to web/bar"))
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		defer buf.Reset()
+		s := &Store{commitTemplate: "{{.Nope}}"}
+		assert.Equal(t, "Remove web/foo from store.\n\n"+
+			"Gopass-Op: remove\n"+
+			"Gopass-Name: web/foo", s.CommitMessage(context.Background(), OpRemove, "web/foo", "", "Remove web/foo from store."))
+		assert.Contains(t, buf.String(), "Invalid commit message template")
+
+		s.hashNames = true
+		assert.Equal(t, "remove "+HashName("web/foo")+"\n\n"+
+			"Gopass-Op: remove\n"+
+			"Gopass-Name: "+HashName("web/foo"), s.CommitMessage(context.Background(), OpRemove, "web/foo", "", "Remove web/foo from store."))
+	})
+}
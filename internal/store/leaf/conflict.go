@@ -0,0 +1,122 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// conflictRE matches the remote copies of secrets changed locally and
+// remotely kept by a sync, e.g. foo/bar.conflict-1a2b3c4
+var conflictRE = regexp.MustCompile(`^(.+)\.conflict-([0-9a-f]+|remote)$`)
+
+// conflictTracker is implemented by storage backends that remember the
+// merge base of the remote copies
+type conflictTracker interface {
+	ConflictBase(ctx context.Context, name string) string
+	ResolveConflict(ctx context.Context, name string) error
+}
+
+// Conflicts returns the remote copies of all secrets changed locally and
+// remotely, by the name of the secret. Both are prefixed with the mount
+// point.
+func (s *Store) Conflicts(ctx context.Context) (map[string][]string, error) {
+	names, err := s.listAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[string]bool, len(names))
+	for _, name := range names {
+		exists[name] = true
+	}
+
+	res := make(map[string][]string)
+	for _, name := range names {
+		m := conflictRE.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		// a secret removed locally is restored from the remote version, so
+		// there is nothing to merge
+		if !exists[m[1]] {
+			continue
+		}
+		res[m[1]] = append(res[m[1]], name)
+	}
+	for _, copies := range res {
+		sort.Strings(copies)
+	}
+	return res, nil
+}
+
+// ConflictBase returns the version of the secret both the local version and
+// the remote copy were based on. It returns store.ErrNotFound if it is not
+// known.
+func (s *Store) ConflictBase(ctx context.Context, name, copy string) (gopass.Secret, error) {
+	ct, ok := s.storage.(conflictTracker)
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	rev := ct.ConflictBase(ctx, s.passfile(copy))
+	if rev == "" {
+		return nil, store.ErrNotFound
+	}
+	return s.GetRevision(ctx, name, rev)
+}
+
+// ResolveConflict replaces the secret with the merged version and removes the
+// remote copies in a single commit
+func (s *Store) ResolveConflict(ctx context.Context, name string, sec gopass.Byter, copies []string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the merged version is no change of the user, the hooks are not run
+	commit := ctxutil.IsGitCommit(ctx)
+	if err := s.Set(ctxutil.WithHooks(ctxutil.WithGitCommit(ctx, false), false), name, sec); err != nil {
+		return fmt.Errorf("failed to write %q: %w", name, err)
+	}
+	for _, c := range copies {
+		if err := s.deleteSingle(ctx, s.passfile(c)); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return fmt.Errorf("failed to delete %q: %w", c, err)
+		}
+		if ct, ok := s.storage.(conflictTracker); ok {
+			if err := ct.ResolveConflict(ctx, s.passfile(c)); err != nil {
+				debug.Log("failed to forget the merge base of %s: %s", c, err)
+			}
+		}
+	}
+
+	if !commit {
+		return nil
+	}
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpMerge, s.withAlias(name), "", fmt.Sprintf("Merged conflicting changes of %s from %s", name, strings.Join(copies, ", ")))); err != nil {
+		switch {
+		case errors.Is(err, store.ErrGitNotInit):
+			debug.Log("skipping git commit - git not initialized")
+		case errors.Is(err, store.ErrGitNothingToCommit):
+			debug.Log("skipping git commit - nothing to commit")
+		default:
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNoRemote) {
+			return nil
+		}
+		return fmt.Errorf("failed to push change to git remote: %w", err)
+	}
+	return nil
+}
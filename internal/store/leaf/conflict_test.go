@@ -0,0 +1,57 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflicts(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithExportKeys(ctx, false)
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("foo")
+	for _, name := range []string{"web/db", "web/db.conflict-1a2b3c4", "web/db.conflict-5d6e7f8", "old.conflict-remote", "foo.conflict-files"} {
+		require.NoError(t, s.Set(ctx, name, sec))
+	}
+
+	conflicts, err := s.Conflicts(ctx)
+	require.NoError(t, err)
+	// old was removed locally
+	assert.Equal(t, map[string][]string{
+		"web/db": {"web/db.conflict-1a2b3c4", "web/db.conflict-5d6e7f8"},
+	}, conflicts)
+
+	// the fs storage doesn't know the merge base
+	_, err = s.ConflictBase(ctx, "web/db", "web/db.conflict-1a2b3c4")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	sec.SetPassword("bar")
+	require.NoError(t, s.ResolveConflict(ctx, "web/db", sec, conflicts["web/db"]))
+	assert.False(t, s.Exists(ctx, "web/db.conflict-1a2b3c4"))
+	assert.False(t, s.Exists(ctx, "web/db.conflict-5d6e7f8"))
+	got, err := s.Get(ctx, "web/db")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", got.Password())
+
+	conflicts, err = s.Conflicts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
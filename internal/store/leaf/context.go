@@ -2,7 +2,10 @@ package leaf
 
 import (
 	"context"
+	"time"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/internal/store"
 )
 
@@ -15,6 +18,22 @@ const (
 	ctxKeyCheckRecipients
 	ctxKeyFsckDecrypt
 	ctxKeyNoGitOps
+	ctxKeyFsckVerify
+	ctxKeySignCommits
+	ctxKeyPullStrategy
+	ctxKeyAutoPush
+	ctxKeyAutoSyncInterval
+	ctxKeyRecipientsChecked
+	ctxKeyFsckFix
+	ctxKeyFsckReport
+	ctxKeyReadOnly
+	ctxKeyFsckChecksums
+	ctxKeyDecryptCache
+	ctxKeyCommitTemplate
+	ctxKeyCommitHashNames
+	ctxKeyCommitOp
+	ctxKeyNoIgnore
+	ctxKeyWeakPassword
 )
 
 // WithFsckCheck returns a context with the flag for fscks check set
@@ -115,6 +134,47 @@ func IsFsckDecrypt(ctx context.Context) bool {
 	return is(ctx, ctxKeyFsckDecrypt, false)
 }
 
+// WithFsckChecksums will return a context with the flag for verifying and
+// adding the checksums of attachments during fsck set
+func WithFsckChecksums(ctx context.Context, cs bool) context.Context {
+	return context.WithValue(ctx, ctxKeyFsckChecksums, cs)
+}
+
+// IsFsckChecksums will return the value of the checksums during fsck flag,
+// defaulting to false
+func IsFsckChecksums(ctx context.Context) bool {
+	return is(ctx, ctxKeyFsckChecksums, false)
+}
+
+// WithFsckFix will return a context with the flag for re-encrypting secrets
+// with wrong recipients during fsck set. The storage backend fixes its own
+// problems, too.
+func WithFsckFix(ctx context.Context, fix bool) context.Context {
+	return backend.WithFsckFix(context.WithValue(ctx, ctxKeyFsckFix, fix), fix)
+}
+
+// IsFsckFix will return the value of the fix during fsck flag, defaulting to
+// false.
+func IsFsckFix(ctx context.Context) bool {
+	return is(ctx, ctxKeyFsckFix, false)
+}
+
+// WithFsckReport will return a context with a report that collects the
+// problems found by fsck.
+func WithFsckReport(ctx context.Context, r *FsckReport) context.Context {
+	return context.WithValue(ctx, ctxKeyFsckReport, r)
+}
+
+// GetFsckReport will return the fsck report from the context or nil if there
+// is none.
+func GetFsckReport(ctx context.Context) *FsckReport {
+	r, ok := ctx.Value(ctxKeyFsckReport).(*FsckReport)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
 // WithNoGitOps returns a context with the value for NoGitOps set.
 // This will skip any git operations in concurrent goroutines.
 func WithNoGitOps(ctx context.Context, d bool) context.Context {
@@ -127,6 +187,164 @@ func IsNoGitOps(ctx context.Context) bool {
 	return is(ctx, ctxKeyNoGitOps, false)
 }
 
+// WithFsckVerify will return a context with the flag for verifying the
+// commit signatures during fsck set.
+func WithFsckVerify(ctx context.Context, v bool) context.Context {
+	return context.WithValue(ctx, ctxKeyFsckVerify, v)
+}
+
+// IsFsckVerify will return the value of the verify commit signatures during
+// fsck flag, defaulting to false.
+func IsFsckVerify(ctx context.Context) bool {
+	return is(ctx, ctxKeyFsckVerify, false)
+}
+
+// WithSignCommits returns a context with the value for signing commits set.
+// It must be set when creating the store.
+func WithSignCommits(ctx context.Context, sign bool) context.Context {
+	return context.WithValue(ctx, ctxKeySignCommits, sign)
+}
+
+// IsSignCommits returns the value for signing commits from the context or
+// the default (false).
+func IsSignCommits(ctx context.Context) bool {
+	return is(ctx, ctxKeySignCommits, false)
+}
+
+// WithReadOnly returns a context with the flag for refusing all changes to
+// the store set. It must be set when creating the store.
+func WithReadOnly(ctx context.Context, ro bool) context.Context {
+	return context.WithValue(ctx, ctxKeyReadOnly, ro)
+}
+
+// IsReadOnly returns the value of the read-only flag or the default (false).
+func IsReadOnly(ctx context.Context) bool {
+	return is(ctx, ctxKeyReadOnly, false)
+}
+
+// WithPullStrategy returns a context with the way remote changes are
+// integrated set. It must be set when creating the store.
+func WithPullStrategy(ctx context.Context, strategy string) context.Context {
+	return context.WithValue(ctx, ctxKeyPullStrategy, strategy)
+}
+
+// GetPullStrategy returns the way remote changes are integrated or an empty
+// string for the storage default.
+func GetPullStrategy(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyPullStrategy).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithAutoPush returns a context with the flag for pushing after each change
+// set. It must be set when creating the store.
+func WithAutoPush(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyAutoPush, bv)
+}
+
+// IsAutoPush returns the value of auto push or the default (true).
+func IsAutoPush(ctx context.Context) bool {
+	return is(ctx, ctxKeyAutoPush, true)
+}
+
+// WithAutoSyncInterval returns a context with the minimum time between two
+// implicit syncs set. It must be set when creating the store.
+func WithAutoSyncInterval(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyAutoSyncInterval, d)
+}
+
+// GetAutoSyncInterval returns the minimum time between two implicit syncs or
+// zero to sync after every change.
+func GetAutoSyncInterval(ctx context.Context) time.Duration {
+	d, ok := ctx.Value(ctxKeyAutoSyncInterval).(time.Duration)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+// WithDecryptCache returns a context with the cache for decrypted secrets
+// set. It must be set when creating the store and can be shared by all
+// mounts.
+func WithDecryptCache(ctx context.Context, c *cache.LRU) context.Context {
+	return context.WithValue(ctx, ctxKeyDecryptCache, c)
+}
+
+// GetDecryptCache returns the cache for decrypted secrets or nil if secrets
+// must not be cached
+func GetDecryptCache(ctx context.Context) *cache.LRU {
+	c, ok := ctx.Value(ctxKeyDecryptCache).(*cache.LRU)
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+// WithCommitTemplate returns a context with the template for the subject
+// of commit messages set. It must be set when creating the store.
+func WithCommitTemplate(ctx context.Context, tmpl string) context.Context {
+	return context.WithValue(ctx, ctxKeyCommitTemplate, tmpl)
+}
+
+// GetCommitTemplate returns the template for the subject of commit messages
+// or an empty string for the built-in messages
+func GetCommitTemplate(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyCommitTemplate).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithCommitHashNames returns a context with the flag for replacing secret
+// names in commit messages with their hashes set. It must be set when
+// creating the store.
+func WithCommitHashNames(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyCommitHashNames, bv)
+}
+
+// IsCommitHashNames returns the value of the hash names flag or the default
+// (false)
+func IsCommitHashNames(ctx context.Context) bool {
+	return is(ctx, ctxKeyCommitHashNames, false)
+}
+
+// commitOp is the operation recorded when a secret is written, if it's not
+// a plain insert or update
+type commitOp struct {
+	op   string
+	from string
+}
+
+// withCommitOp returns a context with the operation and the previous name
+// recorded in the commit of a written secret set
+func withCommitOp(ctx context.Context, op, from string) context.Context {
+	return context.WithValue(ctx, ctxKeyCommitOp, commitOp{op: op, from: from})
+}
+
+// getCommitOp returns the operation and the previous name recorded in the
+// commit of a written secret, if any
+func getCommitOp(ctx context.Context) (string, string) {
+	co, ok := ctx.Value(ctxKeyCommitOp).(commitOp)
+	if !ok {
+		return "", ""
+	}
+	return co.op, co.from
+}
+
+// withRecipientsChecked returns a context with the flag for recipients that
+// have already been checked against the acknowledged ones set
+func withRecipientsChecked(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyRecipientsChecked, bv)
+}
+
+// isRecipientsChecked returns true if the recipients have already been checked
+func isRecipientsChecked(ctx context.Context) bool {
+	return is(ctx, ctxKeyRecipientsChecked, false)
+}
+
 // hasBool is a helper function for checking if a bool has been set in
 // the provided context.
 func hasBool(ctx context.Context, key contextKey) bool {
@@ -143,3 +361,34 @@ func is(ctx context.Context, key contextKey, def bool) bool {
 	}
 	return bv
 }
+
+// WithNoIgnore returns a context with the flag for listing files matched by
+// the ignore rules set
+func WithNoIgnore(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoIgnore, bv)
+}
+
+// IsNoIgnore returns the value of no ignore
+func IsNoIgnore(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyNoIgnore).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
+
+// WithWeakPassword returns a context with the policy rules set a written
+// password violates. They are recorded in the commit, see TrailerWeak.
+func WithWeakPassword(ctx context.Context, rules string) context.Context {
+	return context.WithValue(ctx, ctxKeyWeakPassword, rules)
+}
+
+// GetWeakPassword returns the policy rules a written password violates, if
+// any
+func GetWeakPassword(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyWeakPassword).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
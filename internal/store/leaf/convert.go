@@ -1,129 +1,274 @@
 package leaf
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
-	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/cui"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/appdir"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/termio"
 )
 
-// Convert will convert an existing store to a new store with possibly
-// different set of crypto and storage backends. Please note that it
-// will happily convert to the same set of backends if requested.
-func (s *Store) Convert(ctx context.Context, cryptoBe backend.CryptoBackend, storageBe backend.StorageBackend, move bool) error {
+// convertState tracks the progress of a conversion so it can be resumed
+// after an interruption
+type convertState struct {
+	Path       string          `json:"path"`
+	Crypto     string          `json:"crypto"`
+	Storage    string          `json:"storage"`
+	Recipients []string        `json:"recipients,omitempty"`
+	OldExt     string          `json:"old_ext,omitempty"`
+	OldIDFile  string          `json:"old_id_file,omitempty"`
+	Done       map[string]bool `json:"done,omitempty"`
+	// Converted is set once all secrets are converted and verified. Only
+	// the old files are left to remove.
+	Converted bool `json:"converted,omitempty"`
+}
 
-	// create temp path
-	tmpPath := s.path + "-autoconvert"
-	if err := os.MkdirAll(tmpPath, 0700); err != nil {
-		return err
+// Convert converts the store in place to a different set of crypto and
+// storage backends. Every secret is re-encrypted and verified before the old
+// files are removed. The conversion is committed as one change, unless
+// freshHistory is set which starts a new history instead. An interrupted
+// conversion is resumed when it is run again with the same backends.
+func (s *Store) Convert(ctx context.Context, cryptoBe backend.CryptoBackend, storageBe backend.StorageBackend, freshHistory bool) error {
+	if s.readOnly {
+		return fmt.Errorf("store is read-only")
 	}
-	debug.Log("create temporary store path for conversion: %s", tmpPath)
 
-	// init new store at temp path
-	st, err := backend.InitStorage(ctx, storageBe, tmpPath)
+	st, err := s.loadConvertState()
 	if err != nil {
 		return err
 	}
-	debug.Log("initialized storage %s at %s", st, tmpPath)
+	if st.Path != "" && (st.Path != s.path || st.Crypto != cryptoBe.String() || st.Storage != storageBe.String()) {
+		return fmt.Errorf("there is an unfinished conversion of %s to %s and %s. Run it again with these backends to resume it or remove %s to start over", st.Path, st.Crypto, st.Storage, s.convertStateFile())
+	}
+	st.Path = s.path
+	st.Crypto = cryptoBe.String()
+	st.Storage = storageBe.String()
 
+	if st.Converted || backend.CryptoBackendFromName(s.crypto.Name()) != cryptoBe {
+		if err := s.convertCrypto(ctx, cryptoBe, st); err != nil {
+			return err
+		}
+	}
+
+	if err := s.convertStorage(ctx, storageBe, freshHistory); err != nil {
+		return err
+	}
+
+	if err := s.storage.Add(ctx, s.path); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add %s to git: %w", s.path, err)
+		}
+	}
+	if err := s.storage.Commit(ctx, s.CommitMessage(ctx, OpConvert, "", "", fmt.Sprintf("Converted store to %s and %s", st.Crypto, st.Storage))); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) && !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+
+	if err := os.Remove(s.convertStateFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// convertCrypto re-encrypts every secret with the new crypto backend. The new
+// files are written next to the old ones and only replace them once all of
+// them could be decrypted again.
+func (s *Store) convertCrypto(ctx context.Context, cryptoBe backend.CryptoBackend, st *convertState) error {
 	crypto, err := backend.NewCrypto(ctx, cryptoBe)
 	if err != nil {
 		return err
 	}
-	debug.Log("initialized Crypto %s", crypto)
-	// TODO need to initialize recipients
+	debug.Log("converting %s from %s to %s", s.path, s.crypto.Name(), crypto.Name())
+	if crypto.Ext() == s.crypto.Ext() && !st.Converted {
+		return fmt.Errorf("can not convert %s to %s in place, they use the same file extension", s.crypto.Name(), crypto.Name())
+	}
+
+	if len(st.Recipients) < 1 {
+		key, err := cui.AskForPrivateKey(ctx, crypto, "Please select a private key for encrypting secrets:")
+		if err != nil {
+			return err
+		}
+		st.Recipients = []string{key}
+		st.OldExt = s.crypto.Ext()
+		st.OldIDFile = s.crypto.IDFile()
+	}
+	if st.Done == nil {
+		st.Done = make(map[string]bool)
+	}
 
-	tmpStore := &Store{
-		alias:   s.alias,
-		path:    tmpPath,
-		crypto:  crypto,
-		storage: st,
+	if !st.Converted {
+		if err := s.convertSecrets(ctx, crypto, st); err != nil {
+			return err
+		}
+		st.Converted = true
+		if err := s.saveConvertState(st); err != nil {
+			return err
+		}
 	}
 
-	// init new store
-	key, err := cui.AskForPrivateKey(ctx, crypto, "Please select a private key")
+	// from here on only the old files are left to remove
+	if err := s.storage.Set(ctx, crypto.IDFile(), recipients.Marshal(st.Recipients)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", crypto.IDFile(), err)
+	}
+	files, err := s.storage.List(ctx, "")
 	if err != nil {
 		return err
 	}
-	if err := tmpStore.Init(ctx, tmpPath, key); err != nil {
-		return err
+	for _, fn := range files {
+		if !strings.HasSuffix(fn, "."+st.OldExt) && filepath.Base(fn) != st.OldIDFile {
+			continue
+		}
+		debug.Log("removing %s", fn)
+		if err := s.storage.Delete(ctx, fn); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", fn, err)
+		}
 	}
 
-	// copy everything from old to temp, including all revisions
-	entries, err := s.List(ctx, "")
+	s.crypto = crypto
+	out.OKf(ctx, "Converted %d secrets to %s", len(st.Done), crypto.Name())
+	return nil
+}
+
+func (s *Store) convertSecrets(ctx context.Context, crypto backend.Crypto, st *convertState) error {
+	names, err := s.listAll(ctx, "")
 	if err != nil {
 		return err
 	}
 
 	out.Printf(ctx, "Converting store ...")
-	bar := termio.NewProgressBar(int64(len(entries)))
-	bar.Hidden = ctxutil.IsHidden(ctx)
-	if !ctxutil.IsTerminal(ctx) || ctxutil.IsHidden(ctx) {
-		bar = nil
-	}
+	bar := termio.NewProgressBar(int64(len(names)))
+	bar.Hidden = ctxutil.IsHidden(ctx) || !ctxutil.IsTerminal(ctx)
+	defer bar.Done()
+
+	for _, name := range names {
+		name = strings.TrimPrefix(name, s.alias+Sep)
+		bar.Inc()
+		if st.Done[name] {
+			debug.Log("%s is already converted", name)
+			continue
+		}
 
-	ctx = ctxutil.WithNoNetwork(ctx, true)
-	for _, e := range entries {
-		e = strings.TrimPrefix(e, s.alias+Sep)
-		debug.Log("converting %s", e)
-		revs, err := s.ListRevisions(ctx, e)
+		ciphertext, err := s.storage.Get(ctx, s.storedPassfile(ctx, name))
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to read %s: %w", name, err)
 		}
-		if len(revs) < 2 {
-			debug.Log("entry %s has no revisions. convering latest", e)
-			sec, err := s.Get(ctx, e)
-			if err != nil {
-				return err
-			}
-			if err := tmpStore.Set(ctx, e, sec); err != nil {
-				return err
-			}
-			continue
+		plaintext, err := s.crypto.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
 		}
-		sort.Sort(sort.Reverse(backend.Revisions(revs)))
-		for _, r := range revs {
-			debug.Log("converting %s@%s", e, r.Hash)
-			sec, err := s.GetRevision(ctx, e, r.Hash)
-			if err != nil {
-				return err
-			}
-			msg := fmt.Sprintf("%s\n%s\nCommitted as: %s\nDate: %s\nAuthor: %s <%s>",
-				r.Subject,
-				r.Body,
-				r.Hash,
-				r.Date.Format(time.RFC3339),
-				r.AuthorName,
-				r.AuthorEmail,
-			)
-			ctx := ctxutil.WithCommitMessage(ctx, msg)
-			ctx = ctxutil.WithCommitTimestamp(ctx, r.Date)
-			if err := tmpStore.Set(ctx, e, sec); err != nil {
-				return err
-			}
+		ciphertext, err = crypto.Encrypt(ctx, plaintext, st.Recipients)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", name, err)
 		}
-		bar.Inc()
+
+		fn := strings.TrimPrefix(name+"."+crypto.Ext(), "/")
+		if err := s.storage.Set(ctx, fn, ciphertext); err != nil {
+			return fmt.Errorf("failed to write %s: %w", fn, err)
+		}
+		if err := convertVerify(ctx, s.storage, crypto, fn, plaintext); err != nil {
+			return err
+		}
+
+		st.Done[name] = true
+		if err := s.saveConvertState(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertVerify makes sure the converted secret can be decrypted again
+func convertVerify(ctx context.Context, storage backend.Storage, crypto backend.Crypto, fn string, want []byte) error {
+	buf, err := storage.Get(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", fn, err)
 	}
-	bar.Done()
+	got, err := crypto.Decrypt(ctx, buf)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the converted %s: %w", fn, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("the converted %s does not match the original", fn)
+	}
+	return nil
+}
 
-	if !move {
+// convertStorage switches the store to the new storage backend. A git history
+// that is no longer used is moved next to the store so nothing is lost.
+func (s *Store) convertStorage(ctx context.Context, storageBe backend.StorageBackend, freshHistory bool) error {
+	gitDir := filepath.Join(s.path, ".git")
+	current := backend.StorageBackendFromName(s.storage.Name())
+	hasGit := current == backend.GitFS
+	if current == storageBe && (!freshHistory || !hasGit) {
 		return nil
 	}
 
-	// rename old to backup
-	if err := os.Rename(s.path, filepath.Join(filepath.Dir(s.path), filepath.Base(s.path)+"-backup")); err != nil {
+	if hasGit {
+		backup := s.path + "-history-backup"
+		if _, err := os.Stat(backup); err == nil {
+			return fmt.Errorf("can not move the git history to %s, it already exists", backup)
+		}
+		if err := os.Rename(gitDir, backup); err != nil {
+			return fmt.Errorf("failed to move the git history to %s: %w", backup, err)
+		}
+		out.Noticef(ctx, "Moved the previous git history to %s", backup)
+	}
+
+	st, err := backend.InitStorage(ctx, storageBe, s.path)
+	if err != nil {
+		return err
+	}
+	debug.Log("converted storage of %s to %s", s.path, st)
+	s.storage = st
+	return nil
+}
+
+func (s *Store) convertStateFile() string {
+	name := s.alias
+	if name == "" {
+		name = "root"
+	}
+	return filepath.Join(appdir.UserConfig(), "convert", url.PathEscape(name)+".json")
+}
+
+func (s *Store) loadConvertState() (*convertState, error) {
+	st := &convertState{}
+	buf, err := os.ReadFile(s.convertStateFile())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, st); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.convertStateFile(), err)
+	}
+	return st, nil
+}
+
+func (s *Store) saveConvertState(st *convertState) error {
+	fn := s.convertStateFile()
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	buf, err := json.Marshal(st)
+	if err != nil {
 		return err
 	}
-	// rename temp to old
-	return os.Rename(tmpPath, s.path)
+	return os.WriteFile(fn, buf, 0600)
 }
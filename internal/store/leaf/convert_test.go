@@ -0,0 +1,133 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/age"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/cui"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// convertTestStore returns a plain store and the recipient of a new age
+// identity
+func convertTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(tempdir)
+	})
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	cui.Stdout = obuf
+	t.Cleanup(func() {
+		out.Stdout = os.Stdout
+		cui.Stdout = os.Stdout
+	})
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	oldHome := os.Getenv("HOME")
+	require.NoError(t, os.Setenv("HOME", tempdir))
+	t.Cleanup(func() {
+		_ = os.Setenv("HOME", oldHome)
+	})
+
+	ctx := context.Background()
+	a, err := age.New()
+	require.NoError(t, err)
+	require.NoError(t, a.GenerateIdentity(ctx, "Alice", "alice@example.org", "passphrase"))
+	ids, err := a.ListIdentities(ctx)
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+
+	sec := secrets.New()
+	sec.SetPassword("secret")
+	require.NoError(t, s.Set(ctx, "foo/bar/baz", sec))
+
+	return s, ids[0]
+}
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithUsername(ctx, "Alice")
+	ctx = ctxutil.WithEmail(ctx, "alice@example.org")
+	s, recp := convertTestStore(t)
+
+	require.NoError(t, s.Convert(ctx, backend.Age, backend.GitFS, false))
+
+	assert.Equal(t, "age", s.crypto.Name())
+	assert.Equal(t, backend.GitFS, backend.StorageBackendFromName(s.storage.Name()))
+	assert.DirExists(t, filepath.Join(s.path, ".git"))
+
+	assert.False(t, s.storage.Exists(ctx, plain.IDFile))
+	assert.False(t, s.storage.Exists(ctx, "foo/bar/baz."+plain.Ext))
+	assert.True(t, s.storage.Exists(ctx, "baz/ing/a."+age.Ext))
+	assert.Equal(t, []string{recp}, s.Recipients(ctx))
+
+	sec, err := s.Get(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", sec.Password())
+
+	assert.NoFileExists(t, s.convertStateFile())
+
+	// converting back is committed as one change on top of the history
+	require.NoError(t, s.Convert(ctx, backend.Plain, backend.GitFS, false))
+	revs, err := s.storage.Revisions(ctx, "foo/bar/baz."+plain.Ext)
+	require.NoError(t, err)
+	require.NotEmpty(t, revs)
+	assert.Equal(t, "Converted store to plain and gitfs", revs[0].Subject)
+	sec, err = s.Get(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", sec.Password())
+}
+
+func TestConvertResume(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	s, recp := convertTestStore(t)
+
+	a, err := age.New()
+	require.NoError(t, err)
+
+	// foo/bar/baz was converted before the interruption
+	buf, err := a.Encrypt(ctx, []byte("resumed"), []string{recp})
+	require.NoError(t, err)
+	require.NoError(t, s.storage.Set(ctx, "foo/bar/baz."+age.Ext, buf))
+	require.NoError(t, s.saveConvertState(&convertState{
+		Path:       s.path,
+		Crypto:     backend.Age.String(),
+		Storage:    backend.FS.String(),
+		Recipients: []string{recp},
+		OldExt:     plain.Ext,
+		OldIDFile:  plain.IDFile,
+		Done:       map[string]bool{"foo/bar/baz": true},
+	}))
+
+	// a different conversion must not continue the unfinished one
+	assert.Error(t, s.Convert(ctx, backend.Age, backend.GitFS, false))
+
+	require.NoError(t, s.Convert(ctx, backend.Age, backend.FS, false))
+
+	sec, err := s.Get(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, "resumed", sec.Password())
+	assert.True(t, s.storage.Exists(ctx, "baz/ing/a."+age.Ext))
+	assert.False(t, s.storage.Exists(ctx, "baz/ing/a."+plain.Ext))
+	assert.NoFileExists(t, s.convertStateFile())
+}
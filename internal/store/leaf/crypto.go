@@ -2,14 +2,21 @@ package leaf
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/mail"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/backend/crypto/age"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
 )
 
 func (s *Store) initCryptoBackend(ctx context.Context) error {
@@ -18,6 +25,57 @@ func (s *Store) initCryptoBackend(ctx context.Context) error {
 		return err
 	}
 	s.crypto = cb
+	return s.migrateAgeIDFiles(ctx)
+}
+
+// migrateAgeIDFiles renames the recipient files of age stores created by
+// earlier versions to the current name
+func (s *Store) migrateAgeIDFiles(ctx context.Context) error {
+	if _, ok := s.crypto.(*age.Age); !ok || s.readOnly {
+		return nil
+	}
+	files, err := s.storage.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	changed := make([]string, 0, 2)
+	for _, fn := range files {
+		if filepath.Base(fn) != age.OldIDFile {
+			continue
+		}
+		idf := filepath.Join(filepath.Dir(fn), age.IDFile)
+		if s.storage.Exists(ctx, idf) {
+			continue
+		}
+		buf, err := s.storage.Get(ctx, fn)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fn, err)
+		}
+		if err := s.Writer(ctx).Set(ctx, idf, buf); err != nil {
+			return fmt.Errorf("failed to write %s: %w", idf, err)
+		}
+		if err := s.Writer(ctx).Delete(ctx, fn); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", fn, err)
+		}
+		debug.Log("[%s] renamed %s to %s", s.alias, fn, idf)
+		changed = append(changed, fn, idf)
+	}
+	if len(changed) < 1 {
+		return nil
+	}
+
+	if err := s.Writer(ctx).Add(ctx, changed...); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %v to git: %w", changed, err)
+	}
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpRecipients, "", "", fmt.Sprintf("Renamed %s to %s", age.OldIDFile, age.IDFile))); err != nil {
+		if !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -56,6 +114,9 @@ func (s *Store) ImportMissingPublicKeys(ctx context.Context) error {
 		// get info about this public key
 		names, err := s.decodePublicKey(ctx, r)
 		if err != nil {
+			if s.fetchPublicKey(ctx, r) {
+				continue
+			}
 			out.Errorf(ctx, "[%s] Failed to decode public key %s: %s", s.alias, r, err)
 			continue
 		}
@@ -71,11 +132,17 @@ func (s *Store) ImportMissingPublicKeys(ctx context.Context) error {
 		debug.Log("[%s] Public Key %s not found in keyring, importing", s.alias, r)
 
 		// try to load this recipient
-		if err := s.importPublicKey(ctx, r); err != nil {
+		keys, err := s.importPublicKey(ctx, r)
+		if err != nil {
 			out.Errorf(ctx, "[%s] Failed to import public key for %s: %s", s.alias, r, err)
 			continue
 		}
-		out.Printf(ctx, "[%s] Imported public key for %s into Keyring", s.alias, r)
+		if len(keys) < 1 {
+			out.Printf(ctx, "[%s] Imported public key for %s into Keyring", s.alias, r)
+		}
+		for _, k := range keys {
+			out.Printf(ctx, "[%s] Imported %s into Keyring", s.alias, k)
+		}
 	}
 	return nil
 }
@@ -115,7 +182,7 @@ func (s *Store) exportPublicKey(ctx context.Context, exp keyExporter, r string)
 		return "", fmt.Errorf("exported key too small")
 	}
 
-	if err := s.storage.Set(ctx, filename, pk); err != nil {
+	if err := s.Writer(ctx).Set(ctx, filename, pk); err != nil {
 		return "", fmt.Errorf("failed to write exported public key to store: %w", err)
 	}
 
@@ -126,12 +193,17 @@ type keyImporter interface {
 	ImportPublicKey(ctx context.Context, key []byte) error
 }
 
-// import an public key into the default keyring
-func (s *Store) importPublicKey(ctx context.Context, r string) error {
+type keyReader interface {
+	ReadKeys(ctx context.Context, buf []byte) (gpg.KeyList, error)
+}
+
+// import an public key into the default keyring. It returns the one line
+// representations of the imported keys, if the backend supports reading them.
+func (s *Store) importPublicKey(ctx context.Context, r string) ([]string, error) {
 	im, ok := s.crypto.(keyImporter)
 	if !ok {
 		debug.Log("importing public keys not supported by %T", s.crypto)
-		return nil
+		return nil, nil
 	}
 
 	for _, kd := range []string{keyDir, oldKeyDir} {
@@ -142,11 +214,169 @@ func (s *Store) importPublicKey(ctx context.Context, r string) error {
 		}
 		pk, err := s.storage.Get(ctx, filename)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if err := im.ImportPublicKey(ctx, pk); err != nil {
+			return nil, err
+		}
+		return s.describeKeys(ctx, pk), nil
+	}
+	return nil, fmt.Errorf("public key not found in store")
+}
+
+// describeKeys returns the one line representations of the given public keys
+func (s *Store) describeKeys(ctx context.Context, buf []byte) []string {
+	kr, ok := s.crypto.(keyReader)
+	if !ok {
+		return nil
+	}
+	kl, err := kr.ReadKeys(ctx, buf)
+	if err != nil {
+		debug.Log("[%s] failed to read public keys: %s", s.alias, err)
+		return nil
+	}
+	keys := make([]string, 0, len(kl))
+	for _, k := range kl {
+		keys = append(keys, k.OneLine())
+	}
+	return keys
+}
+
+// removePublicKey removes the exported public key of the given recipient from
+// the store. The removal is staged but not committed.
+func (s *Store) removePublicKey(ctx context.Context, r string) error {
+	for _, kd := range []string{keyDir, oldKeyDir} {
+		filename := filepath.Join(kd, r)
+		if !s.storage.Exists(ctx, filename) {
+			continue
+		}
+		if err := s.Writer(ctx).Delete(ctx, filename); err != nil {
+			return fmt.Errorf("failed to remove public key %q: %w", filename, err)
+		}
+		if err := s.Writer(ctx).Add(ctx, filename); err != nil && !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add %q to git: %w", filename, err)
 		}
-		return im.ImportPublicKey(ctx, pk)
+		debug.Log("[%s] removed public key %s", s.alias, filename)
+	}
+	return nil
+}
+
+type keyFetcher interface {
+	keyImporter
+	keyReader
+	FetchPublicKey(ctx context.Context, fingerprint string, emails ...string) ([]byte, error)
+}
+
+// fetchMissingPublicKeys tries to fetch the public keys of all recipients
+// that are not in the keyring. It returns true if any key was imported.
+func (s *Store) fetchMissingPublicKeys(ctx context.Context, rs []string) bool {
+	var fetched bool
+	for _, r := range rs {
+		kl, err := s.crypto.FindRecipients(ctx, r)
+		if err != nil || len(kl) > 0 {
+			continue
+		}
+		if s.fetchPublicKey(ctx, r) {
+			fetched = true
+		}
+	}
+	return fetched
+}
+
+// fetchPublicKey asks the user to fetch the given public key from WKD or a
+// keyserver and imports it. It returns true if the key was imported.
+func (s *Store) fetchPublicKey(ctx context.Context, r string) bool {
+	kf, ok := s.crypto.(keyFetcher)
+	if !ok {
+		debug.Log("fetching public keys not supported by %T", s.crypto)
+		return false
+	}
+	if (!ctxutil.IsInteractive(ctx) && !ctxutil.IsConfirm(ctx)) || ctxutil.IsNoNetwork(ctx) {
+		debug.Log("[%s] not fetching public key %s (non-interactive or no network)", s.alias, r)
+		return false
+	}
+
+	if !termio.Confirm(ctx, fmt.Sprintf("Fetch key %s from keyserver?", keyName(r))) {
+		return false
+	}
+
+	buf, err := kf.FetchPublicKey(ctx, r, s.recipientEmails(ctx, r)...)
+	if err != nil {
+		out.Errorf(ctx, "[%s] Failed to fetch public key %s: %s", s.alias, r, err)
+		return false
+	}
+
+	keys := s.describeKeys(ctx, buf)
+	if len(keys) < 1 {
+		out.Errorf(ctx, "[%s] Failed to read fetched public key %s", s.alias, r)
+		return false
+	}
+	for _, k := range keys {
+		out.Printf(ctx, "[%s] Fetched %s", s.alias, k)
+	}
+
+	if err := kf.ImportPublicKey(ctx, buf); err != nil {
+		out.Errorf(ctx, "[%s] Failed to import public key for %s: %s", s.alias, r, err)
+		return false
+	}
+	out.Printf(ctx, "[%s] Imported public key for %s into Keyring", s.alias, r)
+	return true
+}
+
+// recipientEmails returns any email addresses we know for the given
+// recipient. These are taken from the recipient itself, if it's an email,
+// or from the exported public key in the store.
+func (s *Store) recipientEmails(ctx context.Context, r string) []string {
+	var emails []string
+	if a, err := mail.ParseAddress(r); err == nil {
+		emails = append(emails, a.Address)
+	}
+	names, err := s.decodePublicKey(ctx, r)
+	if err != nil {
+		return emails
+	}
+	for _, name := range names {
+		if a, err := mail.ParseAddress(name); err == nil {
+			emails = append(emails, a.Address)
+		}
+	}
+	return emails
+}
+
+// keyName formats a recipient for display, key IDs and fingerprints are
+// prefixed with 0x
+func keyName(r string) string {
+	if strings.Contains(r, "@") || strings.HasPrefix(r, "0x") {
+		return r
+	}
+	return "0x" + r
+}
+
+type expiryChecker interface {
+	ExpiringKeys(ctx context.Context, d time.Duration, ids ...string) ([]string, error)
+}
+
+// ExpiringRecipients returns the recipients of this store whose keys will
+// expire within the given duration
+func (s *Store) ExpiringRecipients(ctx context.Context, d time.Duration) []string {
+	ec, ok := s.crypto.(expiryChecker)
+	if !ok {
+		debug.Log("checking key expiration not supported by %T", s.crypto)
+		return nil
+	}
+
+	rs, err := s.GetRecipients(ctx, "")
+	if err != nil {
+		debug.Log("[%s] failed to get recipients: %s", s.alias, err)
+		return nil
+	}
+
+	exp, err := ec.ExpiringKeys(ctx, d, rs...)
+	if err != nil {
+		debug.Log("[%s] failed to check key expiration: %s", s.alias, err)
+		return nil
 	}
-	return fmt.Errorf("public key not found in store")
+	return exp
 }
 
 type locker interface {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/backend/crypto/age"
 	"github.com/gopasspw/gopass/internal/out"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +39,56 @@ func TestGPG(t *testing.T) {
 
 	assert.NoError(t, s.ImportMissingPublicKeys(ctx))
 }
+
+func TestKeyName(t *testing.T) {
+	assert.Equal(t, "0xDEADBEEF", keyName("DEADBEEF"))
+	assert.Equal(t, "0xDEADBEEF", keyName("0xDEADBEEF"))
+	assert.Equal(t, "joe.doe@example.org", keyName("joe.doe@example.org"))
+}
+
+func TestRecipientEmails(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"joe.doe@example.org"}, s.recipientEmails(ctx, "Joe Doe <joe.doe@example.org>"))
+	assert.Empty(t, s.recipientEmails(ctx, "DEADBEEF"))
+}
+
+func TestMigrateAgeIDFiles(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.storage.Set(ctx, age.OldIDFile, []byte("age1foo\n")))
+	require.NoError(t, s.storage.Set(ctx, "team/"+age.OldIDFile, []byte("age1bar\n")))
+
+	// not an age store
+	require.NoError(t, s.migrateAgeIDFiles(ctx))
+	assert.True(t, s.storage.Exists(ctx, age.OldIDFile))
+
+	s.crypto, err = age.New()
+	require.NoError(t, err)
+	require.NoError(t, s.migrateAgeIDFiles(ctx))
+
+	assert.False(t, s.storage.Exists(ctx, age.OldIDFile))
+	assert.False(t, s.storage.Exists(ctx, "team/"+age.OldIDFile))
+	buf, err := s.storage.Get(ctx, "team/"+age.IDFile)
+	require.NoError(t, err)
+	assert.Equal(t, "age1bar\n", string(buf))
+	assert.Equal(t, []string{age.IDFile, "team/" + age.IDFile}, s.idFiles(ctx))
+}
@@ -0,0 +1,51 @@
+package leaf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// cachePrefix returns the prefix of the keys of all cached plaintexts of the
+// secret
+func (s *Store) cachePrefix(name string) string {
+	return s.alias + "\x00" + name + "\x00"
+}
+
+// decrypt returns the plaintext of the secret from the decryption cache or
+// decrypts and caches it. The key includes the hash of the ciphertext, so a
+// secret changed by another process is never served from the cache.
+func (s *Store) decrypt(ctx context.Context, name string, ciphertext []byte) ([]byte, error) {
+	if s.plain == nil {
+		return s.crypto.Decrypt(ctx, ciphertext)
+	}
+
+	sum := sha256.Sum256(ciphertext)
+	key := s.cachePrefix(name) + hex.EncodeToString(sum[:])
+	if content, found := s.plain.Get(key); found {
+		hits, misses := s.plain.Stats()
+		debug.Info("decryption cache hit", "store", s.alias, "name", name, "hits", hits, "misses", misses)
+		return content, nil
+	}
+
+	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return content, err
+	}
+	s.plain.Set(key, content)
+	return content, nil
+}
+
+// forgetPlaintext removes the cached plaintext of a secret after it has been
+// changed or removed. With tree set the secrets below it are removed as well.
+func (s *Store) forgetPlaintext(name string, tree bool) {
+	if s.plain == nil {
+		return
+	}
+	s.plain.RemovePrefix(s.cachePrefix(name))
+	if tree {
+		s.plain.RemovePrefix(s.alias + "\x00" + name + Sep)
+	}
+}
@@ -0,0 +1,82 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/cache"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingCrypto struct {
+	backend.Crypto
+	decrypted int
+}
+
+func (c *countingCrypto) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	c.decrypted++
+	return c.Crypto.Decrypt(ctx, ciphertext)
+}
+
+func TestDecryptCache(t *testing.T) {
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	s, err := createSubStore(t.TempDir())
+	require.NoError(t, err)
+	cc := &countingCrypto{Crypto: s.crypto}
+	s.crypto = cc
+	s.plain = cache.NewLRU(10)
+
+	get := func(ctx context.Context, name, want string) {
+		t.Helper()
+		sec, err := s.Get(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, want, sec.Password())
+	}
+
+	sec := secrets.New()
+	sec.SetPassword("one")
+	require.NoError(t, s.Set(ctx, "foo", sec))
+
+	get(ctx, "foo", "one")
+	get(ctx, "foo", "one")
+	get(ctx, "foo", "one")
+	assert.Equal(t, 1, cc.decrypted)
+	assert.Equal(t, "", buf.String())
+
+	// a write invalidates the cached plaintext
+	sec.SetPassword("two")
+	require.NoError(t, s.Set(ctx, "foo", sec))
+	get(ctx, "foo", "two")
+	assert.Equal(t, 2, cc.decrypted)
+
+	// so does a change by another process
+	ciphertext, err := cc.Encrypt(ctx, []byte("three"), []string{"0xDEADBEEF"})
+	require.NoError(t, err)
+	require.NoError(t, s.storage.Set(ctx, s.passfile("foo"), ciphertext))
+	get(ctx, "foo", "three")
+	assert.Equal(t, 3, cc.decrypted)
+
+	get(ctx, "foo", "three")
+	// cache hits are only written to the debug log
+	get(ctxutil.WithVerbose(ctx, true), "foo", "three")
+	assert.Equal(t, 3, cc.decrypted)
+	assert.Equal(t, "", buf.String())
+
+	require.NoError(t, s.Delete(ctx, "foo"))
+	assert.Equal(t, 0, s.plain.Len())
+}
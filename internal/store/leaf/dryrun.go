@@ -0,0 +1,128 @@
+package leaf
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+)
+
+// dryRunStorage is the sink all changes are written to in dry-run mode. It
+// prints what would be written and committed instead, everything else is
+// read from the real storage. Nothing is changed.
+type dryRunStorage struct {
+	backend.Storage
+	alias string
+}
+
+// Writer returns the storage changes must be written to. In dry-run mode
+// (ctxutil.WithDryRun) that's a sink only printing the changes.
+func (s *Store) Writer(ctx context.Context) backend.Storage {
+	if !ctxutil.IsDryRun(ctx) {
+		return s.storage
+	}
+	return &dryRunStorage{Storage: s.storage, alias: s.alias}
+}
+
+func (d *dryRunStorage) name(fn string) string {
+	if d.alias == "" {
+		return fn
+	}
+	return d.alias + Sep + fn
+}
+
+// Set prints the file that would be written
+func (d *dryRunStorage) Set(ctx context.Context, name string, value []byte) error {
+	op := "create"
+	if d.Storage.Exists(ctx, name) {
+		op = "overwrite"
+	}
+	out.Printf(ctx, "Would %s %s", op, d.name(name))
+	return nil
+}
+
+// Delete prints the file that would be removed
+func (d *dryRunStorage) Delete(ctx context.Context, name string) error {
+	if !d.Storage.Exists(ctx, name) {
+		return store.ErrNotFound
+	}
+	out.Printf(ctx, "Would remove %s", d.name(name))
+	return nil
+}
+
+// Prune prints all files below prefix that would be removed
+func (d *dryRunStorage) Prune(ctx context.Context, prefix string) error {
+	files, err := d.Storage.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, fn := range files {
+		if fn == prefix || strings.HasPrefix(fn, strings.TrimSuffix(prefix, "/")+"/") {
+			out.Printf(ctx, "Would remove %s", d.name(fn))
+		}
+	}
+	return nil
+}
+
+// Link prints the link that would be created
+func (d *dryRunStorage) Link(ctx context.Context, from, to string) error {
+	out.Printf(ctx, "Would link %s to %s", d.name(to), d.name(from))
+	return nil
+}
+
+// Add does nothing. It fails like the real storage if there is no history.
+func (d *dryRunStorage) Add(ctx context.Context, args ...string) error {
+	if !d.hasHistory() {
+		return store.ErrGitNotInit
+	}
+	return nil
+}
+
+// Commit prints the commit message
+func (d *dryRunStorage) Commit(ctx context.Context, msg string) error {
+	if !d.hasHistory() {
+		return store.ErrGitNotInit
+	}
+	where := ""
+	if d.alias != "" {
+		where = " to " + d.alias
+	}
+	out.Printf(ctx, "Would commit%s: %s", where, msg)
+	return nil
+}
+
+// CommitEmpty prints the commit message
+func (d *dryRunStorage) CommitEmpty(ctx context.Context, msg string) error {
+	return d.Commit(ctx, msg)
+}
+
+// Push does nothing
+func (d *dryRunStorage) Push(ctx context.Context, remote, location string) error {
+	return nil
+}
+
+// Pull does nothing
+func (d *dryRunStorage) Pull(ctx context.Context, remote, location string) error {
+	return nil
+}
+
+// hasHistory returns true if the real storage would commit the changes
+func (d *dryRunStorage) hasHistory() bool {
+	gi, ok := d.Storage.(interface{ IsInitialized() bool })
+	return ok && gi.IsInitialized()
+}
+
+// dryRunScope prints the scope a recipients change would re-encrypt
+func dryRunScope(ctx context.Context, entries []string) {
+	out.Printf(ctx, "Would re-encrypt %d secrets:", len(entries))
+	sorted := append([]string{}, entries...)
+	sort.Strings(sorted)
+	for _, e := range sorted {
+		out.Printf(ctx, "  %s", e)
+	}
+}
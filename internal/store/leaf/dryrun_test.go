@@ -0,0 +1,102 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithDryRun(ctx, true)
+
+	tempdir := t.TempDir()
+	genRecs, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("foo")
+
+	t.Run("set", func(t *testing.T) {
+		defer obuf.Reset()
+
+		require.NoError(t, s.Set(ctx, "new/secret", sec))
+		assert.False(t, s.Exists(ctx, "new/secret"))
+		assert.Contains(t, obuf.String(), "Would encrypt new/secret for 0xDEADBEEF, 0xFEEDBEEF")
+		assert.Contains(t, obuf.String(), "Would create new/secret.txt")
+
+		require.NoError(t, s.Set(ctx, "foo/bar/baz", sec))
+		assert.Contains(t, obuf.String(), "Would overwrite foo/bar/baz.txt")
+		buf, err := s.storage.Get(ctx, "foo/bar/baz.txt")
+		require.NoError(t, err)
+		assert.Empty(t, buf)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		defer obuf.Reset()
+
+		require.NoError(t, s.Delete(ctx, "foo/bar/baz"))
+		assert.True(t, s.Exists(ctx, "foo/bar/baz"))
+		assert.Contains(t, obuf.String(), "Would remove foo/bar/baz.txt")
+
+		assert.Error(t, s.Delete(ctx, "nothing"))
+
+		require.NoError(t, s.Prune(ctx, "baz"))
+		assert.True(t, s.Exists(ctx, "baz/ing/a"))
+		assert.Contains(t, obuf.String(), "Would remove baz/ing/a.txt")
+	})
+
+	t.Run("move", func(t *testing.T) {
+		defer obuf.Reset()
+
+		require.NoError(t, s.Move(ctx, "foo/bar/baz", "moved"))
+		assert.True(t, s.Exists(ctx, "foo/bar/baz"))
+		assert.False(t, s.Exists(ctx, "moved"))
+		assert.Contains(t, obuf.String(), "Would create moved.txt")
+		assert.Contains(t, obuf.String(), "Would remove foo/bar/baz.txt")
+	})
+
+	t.Run("recipients", func(t *testing.T) {
+		defer obuf.Reset()
+
+		// a new scope would only re-encrypt the secrets below it
+		require.NoError(t, s.AddRecipientAt(ctx, "foo/bar/", "0xA3683834"))
+		assert.False(t, s.storage.Exists(ctx, filepath.Join("foo", "bar", plain.IDFile)))
+		assert.Contains(t, obuf.String(), "Would create "+filepath.Join("foo", "bar", plain.IDFile))
+		assert.Contains(t, obuf.String(), "Would re-encrypt 1 secrets:\n  foo/bar/baz\n")
+		obuf.Reset()
+
+		require.NoError(t, s.RemoveRecipient(ctx, "0xFEEDBEEF"))
+		assert.Equal(t, genRecs, s.Recipients(ctx))
+		assert.Contains(t, obuf.String(), "Would overwrite "+plain.IDFile)
+		assert.Contains(t, obuf.String(), "Would re-encrypt 2 secrets:\n  baz/ing/a\n  foo/bar/baz\n")
+
+		// validation errors are reported like in a real run
+		assert.Error(t, s.RemoveRecipient(ctx, "0xBADBEEF"))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 )
 
 // Fsck checks all entries matching the given prefix
@@ -20,6 +21,21 @@ func (s *Store) Fsck(ctx context.Context, path string) error {
 	ctx = out.AddPrefix(ctx, "["+s.alias+"] ")
 	debug.Log("Checking %s", path)
 
+	// read-only mounts are only checked, never changed
+	if s.readOnly {
+		out.Printf(ctx, "Checking all secrets in read-only store")
+		if err := s.fsckNames(ctx, path, false); err != nil {
+			return err
+		}
+		return s.fsckEntries(ctx, path)
+	}
+
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// first let the storage backend check itself
 	out.Printf(ctx, "Checking storage backend")
 	if err := s.storage.Fsck(ctx); err != nil {
@@ -32,35 +48,94 @@ func (s *Store) Fsck(ctx context.Context, path string) error {
 		return fmt.Errorf("storage backend compaction failed: %w", err)
 	}
 
+	out.Printf(ctx, "Checking secret names")
+	if err := s.fsckNames(ctx, path, IsFsckFix(ctx)); err != nil {
+		return err
+	}
+
 	pcb := ctxutil.GetProgressCallback(ctx)
 
 	// then we'll make sure all the secrets are readable by us and every
 	// valid recipient
 	out.Printf(ctx, "Checking all secrets in store")
-	names, err := s.List(ctx, path)
+	names, err := s.listAll(ctx, path)
 	if err != nil {
 		return fmt.Errorf("failed to list entries: %w", err)
 	}
 
 	sort.Strings(names)
-	for _, name := range names {
+	var fix []string
+	failed := 0
+	for _, e := range names {
 		pcb()
+		name := e
 		if strings.HasPrefix(name, s.alias+"/") {
 			name = strings.TrimPrefix(name, s.alias+"/")
 		}
 		ctx := ctxutil.WithNoNetwork(ctx, true)
 		debug.Log("[%s] Checking %s", path, name)
-		if err := s.fsckCheckEntry(ctx, name); err != nil {
-			return fmt.Errorf("failed to check %q: %w", name, err)
+		switch s.fsckCheckEntry(ctx, name) {
+		case fsckWrongRecipients:
+			fix = append(fix, e)
+		case fsckFailed:
+			failed++
 		}
 	}
 
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if IsFsckChecksums(ctx) {
+		if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpMaintenance, "", "", "fsck: add checksums")); err != nil && !errors.Is(err, store.ErrGitNothingToCommit) && !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to commit checksums: %w", err)
+		}
+	}
+
+	if IsFsckFix(ctx) && len(fix) > 0 {
+		out.Printf(ctx, "Re-encrypting %d secrets to fix their recipients", len(fix))
+		if err := s.reencryptEntries(ctxutil.WithCommitMessage(ctx, "fsck fix recipients"), fix); err != nil {
+			return fmt.Errorf("failed to fix recipients: %w", err)
+		}
+		for _, e := range fix {
+			GetFsckReport(ctx).markFixed(s.reportName(strings.TrimPrefix(e, s.alias+"/")))
+		}
+	}
+
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNoRemote) {
 			out.Printf(ctx, "RCS Push failed: %s", err)
 		}
 	}
 
+	if IsFsckVerify(ctx) {
+		if err := s.fsckVerifyCommits(ctx); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to check %d secrets", failed)
+	}
+	return nil
+}
+
+// fsckEntries checks all entries matching the given prefix without fixing
+// anything
+func (s *Store) fsckEntries(ctx context.Context, path string) error {
+	names, err := s.listAll(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+
+	sort.Strings(names)
+	pcb := ctxutil.GetProgressCallback(ctx)
+	failed := 0
+	for _, e := range names {
+		pcb()
+		ctx := ctxutil.WithNoNetwork(ctx, true)
+		if s.fsckCheckEntry(ctx, strings.TrimPrefix(e, s.alias+"/")) == fsckFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to check %d secrets", failed)
+	}
 	return nil
 }
 
@@ -69,62 +144,124 @@ type convertedSecret interface {
 	FromMime() bool
 }
 
-func (s *Store) fsckCheckEntry(ctx context.Context, name string) error {
+// result of checking a single secret
+const (
+	fsckOK = iota
+	fsckWrongRecipients
+	fsckFailed
+)
+
+func (s *Store) fsckCheckEntry(ctx context.Context, name string) int {
+	report := GetFsckReport(ctx)
+
 	// make sure we can actually decode this secret
 	// if this fails there is no way we could fix this
-	if IsFsckDecrypt(ctx) {
+	if IsFsckDecrypt(ctx) || IsFsckChecksums(ctx) {
 		// we need to make sure Parsing is enabled in order to parse old Mime secrets
 		ctx = ctxutil.WithShowParsing(ctx, true)
 		secret, err := s.Get(ctx, name)
 		if err != nil {
-			return fmt.Errorf("failed to decode secret %s: %w", name, err)
+			out.Errorf(ctx, "Failed to decrypt %s: %s", name, err)
+			report.Add(FsckProblem{Type: FsckDecryptFailed, Secret: s.reportName(name), Error: err.Error()})
+			return fsckFailed
 		}
 		if cs, ok := secret.(convertedSecret); ok && cs.FromMime() {
 			out.Warningf(ctx, "leftover Mime secret: %s\nYou should consider editing it to re-encrypt it.", name)
 		}
+		if IsFsckChecksums(ctx) && secrets.IsAttachment(secret) {
+			if rc := s.fsckChecksum(ctx, name, secret); rc != fsckOK {
+				return rc
+			}
+		}
 	}
 
-	// now compare the recipients this secret was encoded for and fix it if
-	// if doesn't match
-	ciphertext, err := s.storage.Get(ctx, s.passfile(name))
+	// now compare the recipients this secret was encoded for with the ones
+	// it should be encrypted for
+	ciphertext, err := s.storage.Get(ctx, s.storedPassfile(ctx, name))
 	if err != nil {
-		return fmt.Errorf("failed to get raw secret: %w", err)
+		out.Errorf(ctx, "Failed to read %s: %s", name, err)
+		report.Add(FsckProblem{Type: FsckUnreadable, Secret: s.reportName(name), Error: err.Error()})
+		return fsckFailed
 	}
 
 	itemRecps, err := s.crypto.RecipientIDs(ctx, ciphertext)
+	if errors.Is(err, backend.ErrNotSupported) {
+		debug.Log("not checking the recipients of %s: %s", name, err)
+		return fsckOK
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read recipient IDs from raw secret: %w", err)
+		out.Errorf(ctx, "Failed to read the recipients of %s: %s", name, err)
+		report.Add(FsckProblem{Type: FsckUnreadable, Secret: s.reportName(name), Error: err.Error()})
+		return fsckFailed
 	}
 	itemRecps = fingerprints(ctx, s.crypto, itemRecps)
 
 	perItemStoreRecps, err := s.GetRecipients(ctx, name)
 	if err != nil {
-		return fmt.Errorf("failed to get recipients from store: %w", err)
+		out.Errorf(ctx, "Failed to get the recipients for %s: %s", name, err)
+		report.Add(FsckProblem{Type: FsckUnreadable, Secret: s.reportName(name), Error: err.Error()})
+		return fsckFailed
 	}
 	perItemStoreRecps = fingerprints(ctx, s.crypto, perItemStoreRecps)
 
 	// check itemRecps matches storeRecps
-	missing, extra := compareStringSlices(perItemStoreRecps, itemRecps)
+	missing, extra := compareRecipients(perItemStoreRecps, itemRecps)
 	if len(missing) > 0 {
-		out.Errorf(ctx, "Missing recipients on %s: %+v\nRun fsck with the --decrypt flag to re-encrypt it automatically, or edit this secret yourself.", name, missing)
+		out.Errorf(ctx, "Missing recipients on %s: %+v\nRun fsck with the --fix flag to re-encrypt it automatically, or edit this secret yourself.", name, missing)
+		report.Add(FsckProblem{Type: FsckMissingRecipients, Secret: s.reportName(name), Recipients: missing})
 	}
 
 	if len(extra) > 0 {
-		out.Errorf(ctx, "Extra recipients on %s: %+v\nRun fsck with the --decrypt flag to re-encrypt it automatically, or edit this secret yourself.", name, extra)
+		out.Errorf(ctx, "Extra recipients on %s: %+v\nRun fsck with the --fix flag to re-encrypt it automatically, or edit this secret yourself.", name, extra)
+		report.Add(FsckProblem{Type: FsckExtraRecipients, Secret: s.reportName(name), Recipients: extra})
 	}
 
-	if IsFsckDecrypt(ctx) && (len(missing) > 0 || len(extra) > 0) {
-		out.Printf(ctx, "Re-encrypting automatically %s to fix the recipients.", name)
-		sec, err := s.Get(ctx, name)
-		if err != nil {
-			return fmt.Errorf("failed to decode secret: %w", err)
-		}
-		if err := s.Set(ctxutil.WithCommitMessage(ctx, "fsck fix recipients"), name, sec); err != nil {
-			return fmt.Errorf("failed to write secret: %w", err)
-		}
+	if len(missing) > 0 || len(extra) > 0 {
+		return fsckWrongRecipients
 	}
+	return fsckOK
+}
 
-	return nil
+// fsckChecksum compares the content of an attachment with its checksum. The
+// checksum is added to attachments without one, unless the store is read-only.
+func (s *Store) fsckChecksum(ctx context.Context, name string, sec gopass.Secret) int {
+	buf, err := secrets.AttachmentContent(sec)
+	if err != nil {
+		out.Errorf(ctx, "Failed to decode %s: %s", name, err)
+		GetFsckReport(ctx).Add(FsckProblem{Type: FsckChecksumMismatch, Secret: s.reportName(name), Error: err.Error()})
+		return fsckFailed
+	}
+
+	found, err := secrets.VerifyChecksum(sec, buf)
+	if err != nil {
+		out.Errorf(ctx, "Corrupted attachment %s: %s", name, err)
+		GetFsckReport(ctx).Add(FsckProblem{Type: FsckChecksumMismatch, Secret: s.reportName(name), Error: err.Error()})
+		return fsckFailed
+	}
+	if found || s.readOnly {
+		return fsckOK
+	}
+
+	if err := sec.Set(secrets.ContentSHA256, secrets.Checksum(buf)); err != nil {
+		out.Errorf(ctx, "Failed to add the checksum to %s: %s", name, err)
+		return fsckOK
+	}
+	// all checksums are committed at once by Fsck, the hooks are only run
+	// for changes of the user
+	if err := s.Set(ctxutil.WithHooks(ctxutil.WithGitCommit(ctx, false), false), name, sec); err != nil {
+		out.Errorf(ctx, "Failed to add the checksum to %s: %s", name, err)
+		return fsckOK
+	}
+	out.Printf(ctx, "Added checksum to %s", name)
+	return fsckOK
+}
+
+// reportName returns the name of the secret including the mount point
+func (s *Store) reportName(name string) string {
+	if s.alias == "" {
+		return name
+	}
+	return s.alias + Sep + name
 }
 
 func fingerprints(ctx context.Context, crypto backend.Crypto, in []string) []string {
@@ -135,30 +272,61 @@ func fingerprints(ctx context.Context, crypto backend.Crypto, in []string) []str
 	return out
 }
 
-func compareStringSlices(want, have []string) ([]string, []string) {
+// compareRecipients returns the recipients in want that are missing from have
+// and the ones in have that are not in want. Recipients the crypto backend
+// couldn't resolve to a fingerprint match a fingerprint ending with their key
+// ID.
+func compareRecipients(want, have []string) ([]string, []string) {
 	missing := []string{}
 	extra := []string{}
 
-	wantMap := make(map[string]struct{}, len(want))
-	haveMap := make(map[string]struct{}, len(have))
-
 	for _, w := range want {
-		wantMap[w] = struct{}{}
+		if !containsKey(have, w) {
+			missing = append(missing, w)
+		}
 	}
 	for _, h := range have {
-		haveMap[h] = struct{}{}
+		if !containsKey(want, h) {
+			extra = append(extra, h)
+		}
 	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	return compact(missing), compact(extra)
+}
 
-	for k := range wantMap {
-		if _, found := haveMap[k]; !found {
-			missing = append(missing, k)
+func containsKey(keys []string, id string) bool {
+	for _, k := range keys {
+		if sameKey(k, id) {
+			return true
 		}
 	}
-	for k := range haveMap {
-		if _, found := wantMap[k]; !found {
-			extra = append(extra, k)
-		}
+	return false
+}
+
+// sameKey returns true if both IDs are equal or one of them is a long key ID
+// the other fingerprint ends with
+func sameKey(a, b string) bool {
+	a = strings.ToUpper(strings.TrimPrefix(a, "0x"))
+	b = strings.ToUpper(strings.TrimPrefix(b, "0x"))
+	if a == b {
+		return true
 	}
+	if len(a) < 16 || len(b) < 16 {
+		return false
+	}
+	return strings.HasSuffix(a, b) || strings.HasSuffix(b, a)
+}
 
-	return missing, extra
+// compact removes duplicates from a sorted slice
+func compact(in []string) []string {
+	res := in[:0]
+	for i, v := range in {
+		if i > 0 && in[i-1] == v {
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
 }
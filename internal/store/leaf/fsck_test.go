@@ -3,7 +3,9 @@ package leaf
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
@@ -22,8 +24,10 @@ func TestFsck(t *testing.T) {
 
 	obuf := &bytes.Buffer{}
 	out.Stdout = obuf
+	out.Stderr = obuf
 	defer func() {
 		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
 	}()
 
 	// common setup
@@ -47,15 +51,161 @@ func TestFsck(t *testing.T) {
 	assert.NoError(t, s.Fsck(ctx, ""))
 	obuf.Reset()
 
+	// the plain backend claims that every secret is encrypted for its
+	// static keys
+	report := &FsckReport{}
+	fctx := WithFsckReport(ctx, report)
+	fctx = WithFsckDecrypt(fctx, true)
+	fctx = WithFsckFix(fctx, true)
+	assert.NoError(t, s.Fsck(fctx, ""))
+	assert.Contains(t, obuf.String(), "Missing recipients on foo/bar: [john.doe]")
+	assert.Contains(t, obuf.String(), "Re-encrypting 3 secrets to fix their recipients")
+
+	counts, fixed := report.Counts()
+	assert.Equal(t, map[string]int{
+		FsckMissingRecipients: 3,
+		FsckExtraRecipients:   3,
+		FsckDecryptFailed:     0,
+		FsckUnreadable:        0,
+		FsckShadowed:          0,
+		FsckChecksumMismatch:  0,
+		FsckDanglingLink:      0,
+		FsckUnnormalizedName:  0,
+	}, counts)
+	assert.Equal(t, 6, fixed)
+	problems := report.Problems()
+	require.Len(t, problems, 6)
+	assert.Equal(t, FsckProblem{Type: FsckMissingRecipients, Secret: "foo/bar", Recipients: []string{"john.doe"}, Fixed: true}, problems[0])
+	assert.Equal(t, FsckExtraRecipients, problems[3].Type)
+	obuf.Reset()
+
+	// corrupted secrets are reported, but the others are still checked
+	s.crypto = corruptCrypto{Mocker: plain.New()}
+	require.NoError(t, s.storage.Set(ctx, "foo/baz.txt", []byte("corrupt")))
+	report = &FsckReport{}
+	fctx = WithFsckDecrypt(WithFsckReport(ctx, report), true)
+	err = s.Fsck(fctx, "")
+	assert.Error(t, err)
+	counts, _ = report.Counts()
+	assert.Equal(t, 1, counts[FsckDecryptFailed])
+	assert.Equal(t, 2, counts[FsckMissingRecipients])
+	assert.Contains(t, obuf.String(), "Failed to decrypt foo/baz")
+	obuf.Reset()
+	s.crypto = plain.New()
+
+	// attachments without a checksum get one, corrupted ones are reported
+	for _, e := range []string{"att/new", "att/broken"} {
+		sec := secrets.NewKV()
+		require.NoError(t, sec.Set("Content-Transfer-Encoding", "Base64"))
+		sec.Write([]byte("Zm9vYmFy"))
+		require.NoError(t, s.Set(ctx, e, sec))
+	}
+	sec, err := s.Get(ctx, "att/broken")
+	require.NoError(t, err)
+	require.NoError(t, sec.Set(secrets.ContentSHA256, secrets.Checksum([]byte("foo"))))
+	require.NoError(t, s.Set(ctx, "att/broken", sec))
+
+	report = &FsckReport{}
+	fctx = WithFsckChecksums(WithFsckReport(ctx, report), true)
+	assert.Error(t, s.Fsck(fctx, "att"))
+	assert.Contains(t, obuf.String(), "Corrupted attachment att/broken: checksum mismatch")
+	assert.Contains(t, obuf.String(), "Added checksum to att/new")
+	counts, _ = report.Counts()
+	assert.Equal(t, 1, counts[FsckChecksumMismatch])
+	obuf.Reset()
+
+	sec, err = s.Get(ctx, "att/new")
+	require.NoError(t, err)
+	cs, found := sec.Get(secrets.ContentSHA256)
+	assert.True(t, found)
+	assert.Equal(t, secrets.Checksum([]byte("foobar")), cs)
+	assert.Equal(t, "Zm9vYmFy", sec.Body())
+
 	// common tear down
 	_ = os.RemoveAll(tempdir)
 }
 
-func TestCompareStringSlices(t *testing.T) {
+func TestFsckNames(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS file systems don't distinguish normalization forms")
+	}
+
+	ctx := context.Background()
+	ctx = ctxutil.WithExportKeys(ctx, false)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	tempdir := t.TempDir()
+	s := &Store{
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.saveRecipients(ctx, []string{"john.doe"}, "test"))
+
+	nfc := "caf\u00e9"
+	nfd := "cafe\u0301"
+	require.NoError(t, s.storage.Set(ctx, "web/"+nfd+".txt", []byte("decomposed")))
+	require.NoError(t, s.storage.Set(ctx, "twice/"+nfd+".txt", []byte("decomposed")))
+	require.NoError(t, s.storage.Set(ctx, "twice/"+nfc+".txt", []byte("composed")))
+
+	report := &FsckReport{}
+	require.NoError(t, s.Fsck(WithFsckReport(ctx, report), ""))
+	counts, fixed := report.Counts()
+	assert.Equal(t, 2, counts[FsckUnnormalizedName])
+	assert.Equal(t, 0, fixed)
+	assert.Contains(t, obuf.String(), "The name of web/"+nfc+" is not normalized")
+	assert.Contains(t, obuf.String(), "twice/"+nfc+" is stored twice")
+	assert.True(t, s.storage.Exists(ctx, "web/"+nfd+".txt"))
+	obuf.Reset()
+
+	report = &FsckReport{}
+	require.NoError(t, s.Fsck(WithFsckFix(WithFsckReport(ctx, report), true), ""))
+	var renamed []string
+	for _, p := range report.Problems() {
+		if p.Type == FsckUnnormalizedName && p.Fixed {
+			renamed = append(renamed, p.Secret)
+		}
+	}
+	assert.Equal(t, []string{"web/" + nfc}, renamed)
+	assert.False(t, s.storage.Exists(ctx, "web/"+nfd+".txt"))
+	assert.True(t, s.storage.Exists(ctx, "web/"+nfc+".txt"))
+	// both are kept if renaming would overwrite a secret
+	assert.True(t, s.storage.Exists(ctx, "twice/"+nfd+".txt"))
+	assert.True(t, s.storage.Exists(ctx, "twice/"+nfc+".txt"))
+}
+
+// corruptCrypto fails to decrypt secrets containing "corrupt"
+type corruptCrypto struct {
+	*plain.Mocker
+}
+
+func (c corruptCrypto) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if string(ciphertext) == "corrupt" {
+		return nil, fmt.Errorf("corrupted")
+	}
+	return c.Mocker.Decrypt(ctx, ciphertext)
+}
+
+func TestCompareRecipients(t *testing.T) {
 	want := []string{"foo", "bar"}
-	have := []string{"baz", "bar"}
+	have := []string{"baz", "bar", "baz"}
 
-	missing, extra := compareStringSlices(want, have)
+	missing, extra := compareRecipients(want, have)
 	assert.Equal(t, []string{"foo"}, missing)
 	assert.Equal(t, []string{"baz"}, extra)
+
+	// unknown keys are matched by their long key ID
+	missing, extra = compareRecipients(
+		[]string{"1234567890ABCDEF1234567890ABCDEF12345678", "0xCAFEBABE"},
+		[]string{"0x90abcdef12345678", "0xDEADBEEFDEADBEEF"},
+	)
+	assert.Equal(t, []string{"0xCAFEBABE"}, missing)
+	assert.Equal(t, []string{"0xDEADBEEFDEADBEEF"}, extra)
 }
@@ -0,0 +1,95 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fsckNames reports secrets whose names are not normalized to NFC, e.g. ones
+// created on macOS before gopass normalized names, and renames them if fix is
+// set. Lookups find them either way but the same name could be stored twice.
+func (s *Store) fsckNames(ctx context.Context, path string, fix bool) error {
+	// macOS file systems don't distinguish both forms and HFS+ always lists
+	// decomposed names, renaming can't help there
+	if runtime.GOOS == "darwin" {
+		return nil
+	}
+
+	lst, err := s.storage.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list entries: %w", err)
+	}
+	stored := make(map[string]bool, len(lst))
+	for _, p := range lst {
+		stored[p] = true
+	}
+
+	report := GetFsckReport(ctx)
+	cExt := "." + s.crypto.Ext()
+	var paths []string
+	for _, p := range lst {
+		nfc := norm.NFC.String(p)
+		if nfc == p || !strings.HasSuffix(p, cExt) || !strings.HasPrefix(nfc, path) {
+			continue
+		}
+		name := strings.TrimSuffix(nfc, cExt)
+		problem := FsckProblem{Type: FsckUnnormalizedName, Secret: s.reportName(name)}
+		switch {
+		case stored[nfc]:
+			out.Errorf(ctx, "%s is stored twice, with a composed and a decomposed name. Please remove one of them", name)
+			problem.Error = "a secret with the normalized name exists"
+		case !fix:
+			out.Warningf(ctx, "The name of %s is not normalized. Run 'gopass fsck --fix' to rename it", name)
+		default:
+			if err := s.renameStored(ctx, p, nfc); err != nil {
+				out.Errorf(ctx, "Failed to rename %s: %s", name, err)
+				problem.Error = err.Error()
+				break
+			}
+			out.OKf(ctx, "Normalized the name of %s", name)
+			problem.Fixed = true
+			paths = append(paths, p, nfc)
+		}
+		report.Add(problem)
+	}
+
+	if len(paths) < 1 {
+		return nil
+	}
+	if err := s.Writer(ctx).Add(ctx, paths...); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %q to git: %w", paths, err)
+	}
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpMaintenance, "", "", "fsck: normalize names")); err != nil && !errors.Is(err, store.ErrGitNothingToCommit) {
+		return fmt.Errorf("failed to commit renamed secrets: %w", err)
+	}
+	return nil
+}
+
+// renameStored moves the ciphertext of a secret to another passfile. The new
+// file is written first so a failure can't lose the secret.
+func (s *Store) renameStored(ctx context.Context, from, to string) error {
+	buf, err := s.storage.Get(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", from, err)
+	}
+	if err := s.Writer(ctx).Set(ctx, to, buf); err != nil {
+		return fmt.Errorf("failed to write %q: %w", to, err)
+	}
+	if err := s.Writer(ctx).Delete(ctx, from); err != nil {
+		return fmt.Errorf("failed to delete %q: %w", from, err)
+	}
+	debug.Log("renamed %q to %q", from, to)
+	return nil
+}
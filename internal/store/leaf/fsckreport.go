@@ -0,0 +1,133 @@
+package leaf
+
+import (
+	"sort"
+	"sync"
+)
+
+// Problem classes reported by fsck
+const (
+	// FsckMissingRecipients are secrets not encrypted for all of their
+	// current recipients
+	FsckMissingRecipients = "missing-recipients"
+	// FsckExtraRecipients are secrets still encrypted for keys that are no
+	// recipients anymore
+	FsckExtraRecipients = "extra-recipients"
+	// FsckDecryptFailed are secrets that could not be decrypted
+	FsckDecryptFailed = "decrypt-failed"
+	// FsckUnreadable are secrets whose recipients could not be determined
+	FsckUnreadable = "unreadable"
+	// FsckShadowed are secrets hidden by a more specific mount point
+	FsckShadowed = "shadowed"
+	// FsckChecksumMismatch are attachments whose content doesn't match
+	// their checksum
+	FsckChecksumMismatch = "checksum-mismatch"
+	// FsckDanglingLink are links whose target doesn't exist or that form
+	// a loop
+	FsckDanglingLink = "dangling-link"
+	// FsckUnnormalizedName are secrets whose name is not in Unicode
+	// normalization form C
+	FsckUnnormalizedName = "unnormalized-name"
+)
+
+// FsckProblems are all problem classes in the order they are reported
+var FsckProblems = []string{
+	FsckMissingRecipients,
+	FsckExtraRecipients,
+	FsckDecryptFailed,
+	FsckUnreadable,
+	FsckShadowed,
+	FsckChecksumMismatch,
+	FsckDanglingLink,
+	FsckUnnormalizedName,
+}
+
+// FsckProblem is a problem with a single secret found by fsck
+type FsckProblem struct {
+	Type       string   `json:"type"`
+	Secret     string   `json:"secret"`
+	Recipients []string `json:"recipients,omitempty"`
+	Mount      string   `json:"mount,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Fixed      bool     `json:"fixed"`
+}
+
+// FsckReport collects the problems found by fsck in all stores. A nil report
+// discards all problems.
+type FsckReport struct {
+	mu       sync.Mutex
+	problems []FsckProblem
+}
+
+// Add records a problem
+func (r *FsckReport) Add(p FsckProblem) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.problems = append(r.problems, p)
+}
+
+// markFixed marks the recipient problems of the given secret as fixed
+func (r *FsckReport) markFixed(secret string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.problems {
+		if p.Secret != secret {
+			continue
+		}
+		if p.Type == FsckMissingRecipients || p.Type == FsckExtraRecipients {
+			r.problems[i].Fixed = true
+		}
+	}
+}
+
+// Problems returns all problems ordered by their class and the name of the
+// secret
+func (r *FsckReport) Problems() []FsckProblem {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order := make(map[string]int, len(FsckProblems))
+	for i, c := range FsckProblems {
+		order[c] = i
+	}
+
+	res := append([]FsckProblem{}, r.problems...)
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Type != res[j].Type {
+			return order[res[i].Type] < order[res[j].Type]
+		}
+		return res[i].Secret < res[j].Secret
+	})
+	return res
+}
+
+// Counts returns the number of problems per class and the number of problems
+// that have been fixed
+func (r *FsckReport) Counts() (map[string]int, int) {
+	counts := make(map[string]int, len(FsckProblems))
+	for _, c := range FsckProblems {
+		counts[c] = 0
+	}
+	fixed := 0
+	for _, p := range r.Problems() {
+		counts[p.Type]++
+		if p.Fixed {
+			fixed++
+		}
+	}
+	return counts, fixed
+}
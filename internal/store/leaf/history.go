@@ -0,0 +1,216 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/recipients"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// changeLister is implemented by storage backends that keep a history of
+// all changes
+type changeLister interface {
+	Changes(ctx context.Context) ([]backend.Change, error)
+}
+
+// Changes returns the changes of the secrets and recipients of this store,
+// newest first. The names of the secrets are prefixed with the mount point,
+// recipient changes are reported for the recipients file, e.g. team/.gpg-id.
+// Other files, e.g. the exported public keys, are left out. The gopass
+// trailers of the commit messages are preferred over their subjects, which
+// depend on the commit message template.
+func (s *Store) Changes(ctx context.Context) ([]backend.Change, error) {
+	cl, ok := s.storage.(changeLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s storage of %s keeps no history: %w", s.storage.Name(), s.path, backend.ErrNotSupported)
+	}
+
+	changes, err := cl.Changes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of %s: %w", s.path, err)
+	}
+
+	// secrets changed along with the recipients are re-encrypted for them
+	recipientCommits := make(map[string]bool)
+	for _, c := range changes {
+		if s.isIDFile(c.Name) {
+			recipientCommits[c.Hash] = true
+		}
+	}
+
+	names := s.hashedNames(changes)
+	cExt := "." + s.crypto.Ext()
+	res := make([]backend.Change, 0, len(changes))
+	for _, c := range changes {
+		switch {
+		case s.isIDFile(c.Name):
+			c.Added, c.Removed = s.recipientsDiff(ctx, c)
+			c.Op = backend.ChangeRecipients
+			c.OldName = ""
+		case strings.HasSuffix(c.Name, cExt):
+			c.Name = strings.TrimSuffix(c.Name, cExt)
+			c.OldName = strings.TrimSuffix(c.OldName, cExt)
+			if c.Op == backend.ChangeUpdated && (recipientCommits[c.Hash] || isReencryptCommit(c.Revision)) {
+				c.Op = backend.ChangeReencrypted
+			}
+		default:
+			continue
+		}
+
+		c.Name = s.withAlias(c.Name)
+		if c.OldName != "" {
+			c.OldName = s.withAlias(c.OldName)
+		}
+		// gopass mv re-encrypts the secret, so git can't tell that it was
+		// moved. The commit message does.
+		if c.Op == backend.ChangeCreated {
+			if from := movedFrom(c.Revision, c.Name, names); from != "" {
+				c.Op = backend.ChangeRenamed
+				c.OldName = from
+			}
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+// hashedNames maps the hashes of the names of all changed secrets to their
+// names, to find the old names of secrets moved with hashed names
+func (s *Store) hashedNames(changes []backend.Change) map[string]string {
+	cExt := "." + s.crypto.Ext()
+	names := make(map[string]string, len(changes))
+	for _, c := range changes {
+		for _, n := range []string{c.Name, c.OldName} {
+			if strings.HasSuffix(n, cExt) {
+				n = s.withAlias(strings.TrimSuffix(n, cExt))
+				names[HashName(n)] = n
+			}
+		}
+	}
+	return names
+}
+
+func (s *Store) isIDFile(name string) bool {
+	return path.Base(name) == s.crypto.IDFile()
+}
+
+func (s *Store) withAlias(name string) string {
+	if s.alias == "" {
+		return name
+	}
+	return s.alias + Sep + name
+}
+
+// recipientsDiff returns the recipients added and removed by the change of a
+// recipients file, as fingerprints if the keys are known
+func (s *Store) recipientsDiff(ctx context.Context, c backend.Change) ([]string, []string) {
+	// a file that doesn't exist at a revision has no recipients
+	before := make(map[string]bool)
+	if buf, err := s.storage.GetRevision(ctx, c.Name, c.Hash+"^"); err == nil {
+		for _, r := range recipients.Unmarshal(buf) {
+			before[r] = true
+		}
+	} else {
+		debug.Log("no recipients before %s: %s", c.Hash, err)
+	}
+	after := make(map[string]bool)
+	if buf, err := s.storage.GetRevision(ctx, c.Name, c.Hash); err == nil {
+		for _, r := range recipients.Unmarshal(buf) {
+			after[r] = true
+		}
+	} else {
+		debug.Log("no recipients at %s: %s", c.Hash, err)
+	}
+
+	var added, removed []string
+	for r := range after {
+		if !before[r] {
+			added = append(added, s.fingerprint(ctx, r))
+		}
+	}
+	for r := range before {
+		if !after[r] {
+			removed = append(removed, s.fingerprint(ctx, r))
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// fingerprint returns the fingerprint of the recipient or the recipient as
+// written in the recipients file if the key is unknown
+func (s *Store) fingerprint(ctx context.Context, r string) string {
+	if fp := s.crypto.Fingerprint(ctx, r); fp != "" {
+		return fp
+	}
+	return r
+}
+
+// isReencryptCommit returns true if the commit re-encrypted secrets. Commits
+// without a Gopass-Op trailer, i.e. made by older versions, are recognized by
+// their subject.
+func isReencryptCommit(r backend.Revision) bool {
+	switch op := r.Trailers[TrailerOp]; op {
+	case OpReencrypt, OpRecipients, OpConvert:
+		return true
+	case "":
+		return isReencryptSubject(r.Subject)
+	default:
+		return false
+	}
+}
+
+// isReencryptSubject returns true if the commit message is one of those used
+// when re-encrypting secrets
+func isReencryptSubject(subject string) bool {
+	subject = strings.ToLower(subject)
+	for _, s := range []string{"recipient", "re-encrypt", "reencrypt", "converted store"} {
+		if strings.Contains(subject, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// movedFrom returns the old name of a secret saved by gopass mv. names maps
+// hashed names to the names of all known secrets.
+func movedFrom(r backend.Revision, name string, names map[string]string) string {
+	op := r.Trailers[TrailerOp]
+	if op == "" {
+		return movedFromSubject(r.Subject, name)
+	}
+	if op != OpMove || !trailerIs(r.Trailers[TrailerName], name) {
+		return ""
+	}
+	from := r.Trailers[TrailerFrom]
+	if strings.HasPrefix(from, hashedPrefix) {
+		return names[from]
+	}
+	return from
+}
+
+// trailerIs returns true if the name trailer refers to name, either directly
+// or as its hash
+func trailerIs(trailer, name string) bool {
+	return trailer == name || trailer == HashName(name)
+}
+
+// movedFromSubject returns the old name of a secret saved by gopass mv
+// without trailers, i.e. with the commit message
+// "Save secret to <name>: Move from <from> to <name>"
+func movedFromSubject(subject, name string) string {
+	const prefix = "Move from "
+	suffix := " to " + name
+
+	i := strings.Index(subject, prefix)
+	if i < 0 || !strings.HasSuffix(subject, suffix) || i+len(prefix) >= len(subject)-len(suffix) {
+		return ""
+	}
+	return subject[i+len(prefix) : len(subject)-len(suffix)]
+}
@@ -0,0 +1,116 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangesWithoutHistory(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+	require.Equal(t, "fs", s.Storage().Name())
+
+	_, err = s.Changes(ctx)
+	assert.ErrorIs(t, err, backend.ErrNotSupported)
+}
+
+func TestMovedFrom(t *testing.T) {
+	for _, tc := range []struct {
+		subject string
+		name    string
+		from    string
+	}{
+		{subject: "Save secret to web/new: Move from web/old to web/new", name: "web/new", from: "web/old"},
+		{subject: "Save secret to new: Move from team/old to team/new", name: "team/new", from: "team/old"},
+		{subject: "Save secret to web/new: Move from my old to web/new", name: "web/new", from: "my old"},
+		{subject: "Save secret to web/new: Copy from web/old to web/new", name: "web/new"},
+		{subject: "Save secret to web/new: Move from web/old to web/other", name: "web/new"},
+		{subject: "Save secret to web/new: Move from  to web/new", name: "web/new"},
+		{subject: "Save secret to web/new: Generated Password", name: "web/new"},
+	} {
+		assert.Equal(t, tc.from, movedFrom(backend.Revision{Subject: tc.subject}, tc.name, nil), tc.subject)
+	}
+
+	// the trailers win over the subject
+	names := map[string]string{HashName("web/old"): "web/old"}
+	for _, tc := range []struct {
+		trailers map[string]string
+		name     string
+		from     string
+	}{
+		{trailers: map[string]string{TrailerOp: OpMove, TrailerName: "web/new", TrailerFrom: "web/old"}, name: "web/new", from: "web/old"},
+		{trailers: map[string]string{TrailerOp: OpMove, TrailerName: HashName("web/new"), TrailerFrom: HashName("web/old")}, name: "web/new", from: "web/old"},
+		{trailers: map[string]string{TrailerOp: OpMove, TrailerName: HashName("web/new"), TrailerFrom: HashName("web/gone")}, name: "web/new"},
+		{trailers: map[string]string{TrailerOp: OpMove, TrailerName: "web/other", TrailerFrom: "web/old"}, name: "web/new"},
+		{trailers: map[string]string{TrailerOp: OpCopy, TrailerName: "web/new", TrailerFrom: "web/old"}, name: "web/new"},
+		{trailers: map[string]string{TrailerOp: OpInsert, TrailerName: "web/new"}, name: "web/new"},
+	} {
+		r := backend.Revision{Subject: "Save secret to web/new: Move from web/old to web/new", Trailers: tc.trailers}
+		assert.Equal(t, tc.from, movedFrom(r, tc.name, names), tc.trailers)
+	}
+}
+
+func TestIsReencryptSubject(t *testing.T) {
+	assert.True(t, isReencryptSubject("Added Recipient 0xDEADBEEF"))
+	assert.True(t, isReencryptSubject("Removed Recipient 0xDEADBEEF"))
+	assert.True(t, isReencryptSubject("fsck fix recipients"))
+	assert.True(t, isReencryptSubject("Converted store to age and gitfs"))
+	assert.False(t, isReencryptSubject("Save secret to foo: Generated Password"))
+}
+
+func TestIsReencryptCommit(t *testing.T) {
+	assert.True(t, isReencryptCommit(backend.Revision{Subject: "Added Recipient 0xDEADBEEF"}))
+	assert.True(t, isReencryptCommit(backend.Revision{Subject: "custom", Trailers: map[string]string{TrailerOp: OpReencrypt}}))
+	assert.True(t, isReencryptCommit(backend.Revision{Subject: "custom", Trailers: map[string]string{TrailerOp: OpRecipients}}))
+	assert.False(t, isReencryptCommit(backend.Revision{Subject: "update recipients.txt", Trailers: map[string]string{TrailerOp: OpUpdate}}))
+}
+
+func TestChangesWithTrailers(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithUsername(ctx, "foo")
+	ctx = ctxutil.WithEmail(ctx, "foo@baz.com")
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+	require.NoError(t, s.GitInit(backend.WithStorageBackend(ctx, backend.GitFS)))
+	s.hashNames = true
+	s.commitTemplate = "{{.Op}}"
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, s.Set(ctx, "web/old", sec))
+	require.NoError(t, s.Move(ctx, "web/old", "web/new"))
+
+	changes, err := s.Changes(ctx)
+	require.NoError(t, err)
+	var ops []string
+	for _, c := range changes {
+		ops = append(ops, c.Subject+" "+c.Op+" "+c.Name+" "+c.OldName)
+	}
+	// gopass mv is a rename even though the subject doesn't say so
+	assert.Contains(t, ops, "move renamed web/new web/old")
+	assert.Contains(t, ops, "remove deleted web/old ")
+	assert.Contains(t, ops, "insert created web/old ")
+}
@@ -0,0 +1,21 @@
+package leaf
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/internal/hook"
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+// runHook runs the named hook for a secret of this store
+func (s *Store) runHook(ctx context.Context, name, secret string) error {
+	return hook.Run(ctx, name, s.alias, secret, s.path)
+}
+
+// runPostHook runs the named hook after a change. The change can't be undone
+// anymore, so a failing hook is only reported.
+func (s *Store) runPostHook(ctx context.Context, name, secret string) {
+	if err := s.runHook(ctx, name, secret); err != nil {
+		out.Warningf(ctx, "%s", err)
+	}
+}
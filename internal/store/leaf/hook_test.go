@@ -0,0 +1,64 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/hook"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+
+	td := t.TempDir()
+	s, err := createSubStore(td)
+	require.NoError(t, err)
+
+	t.Setenv("GOPASS_CONFIG", filepath.Join(td, "config", "config.yml"))
+	require.NoError(t, os.MkdirAll(hook.Dir(), 0700))
+
+	// nothing in protected/ may be changed
+	script := "#!/bin/sh\ncase \"$3\" in protected|protected/*) echo \"$3 is protected\" >&2; exit 1;; esac\n"
+	for _, name := range []string{hook.PreWrite, hook.PreRemove} {
+		require.NoError(t, os.WriteFile(filepath.Join(hook.Dir(), name), []byte(script), 0700))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(hook.Dir(), hook.PostWrite), []byte("#!/bin/sh\necho \"$3\" >> ../written\n"), 0700))
+
+	ctx := context.Background()
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, s.Set(ctx, "protected/foo", sec))
+
+	ctx = ctxutil.WithHooks(ctx, true)
+
+	err = s.Set(ctx, "protected/bar", sec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protected/bar is protected")
+	assert.False(t, s.Exists(ctx, "protected/bar"))
+
+	err = s.Delete(ctx, "protected/foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protected/foo is protected")
+	assert.True(t, s.Exists(ctx, "protected/foo"))
+	assert.Error(t, s.Prune(ctx, "protected"))
+
+	// internal writes don't run the hooks
+	require.NoError(t, s.reencryptEntry(ctx, "protected/foo"))
+
+	require.NoError(t, s.Set(ctx, "public/foo", sec))
+	require.NoError(t, s.Delete(ctx, "public/foo"))
+
+	buf, err := os.ReadFile(filepath.Join(td, "written"))
+	require.NoError(t, err)
+	assert.Equal(t, "public/foo\n", string(buf))
+}
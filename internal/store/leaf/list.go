@@ -2,9 +2,13 @@ package leaf
 
 import (
 	"context"
+	"sort"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/ignore"
 	"github.com/gopasspw/gopass/pkg/debug"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 var (
@@ -12,7 +16,9 @@ var (
 	Sep = "/"
 )
 
-// List will list all entries in this store
+// List will list all entries in this store. The names are normalized to NFC,
+// even if they are stored decomposed. Files matched by the ignore rules of
+// the store are skipped, unless the context says otherwise.
 func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 	if s.storage == nil || s.crypto == nil {
 		return nil, nil
@@ -22,14 +28,35 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	if nfd := norm.NFD.String(prefix); nfd != prefix {
+		more, err := s.storage.List(ctx, nfd)
+		if err != nil {
+			return nil, err
+		}
+		lst = append(lst, more...)
+		sort.Strings(lst)
+	}
 	debug.Log("Listing %s: %+v\n", prefix, lst)
 	out := make([]string, 0, len(lst))
+	seen := make(map[string]bool, len(lst))
 	cExt := "." + s.crypto.Ext()
+	var im *ignore.Matcher
+	if !IsNoIgnore(ctx) {
+		im = s.ignoreMatcher(ctx)
+	}
 	for _, path := range lst {
 		if !strings.HasSuffix(path, cExt) {
 			continue
 		}
-		path = strings.TrimSuffix(path, cExt)
+		if im.Match(path) {
+			debug.Log("ignoring %s", path)
+			continue
+		}
+		path = norm.NFC.String(strings.TrimSuffix(path, cExt))
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
 		if s.alias != "" {
 			path = s.alias + Sep + path
 		}
@@ -37,3 +64,40 @@ func (s *Store) List(ctx context.Context, prefix string) ([]string, error) {
 	}
 	return out, nil
 }
+
+// listAll lists all entries of this store, including those matched by the
+// ignore rules. The ignore rules only hide entries from the user, everything
+// that changes or checks the secrets themselves, e.g. re-encrypting them for
+// a removed recipient, must see all of them.
+func (s *Store) listAll(ctx context.Context, prefix string) ([]string, error) {
+	return s.List(WithNoIgnore(ctx, true), prefix)
+}
+
+// ignoreMatcher returns the ignore rules of this store. Only the defaults
+// apply if the store has no ignore file.
+func (s *Store) ignoreMatcher(ctx context.Context) *ignore.Matcher {
+	if !s.storage.Exists(ctx, ignore.File) {
+		return ignore.New(nil)
+	}
+	buf, err := s.storage.Get(ctx, ignore.File)
+	if err != nil {
+		debug.Log("failed to read %s: %s", ignore.File, err)
+		return ignore.New(nil)
+	}
+	return ignore.New(buf)
+}
+
+// indexer is implemented by storage backends keeping an index of their
+// entries
+type indexer interface {
+	RebuildIndex(ctx context.Context) error
+}
+
+// RebuildIndex rebuilds the index of the entries if the storage backend
+// keeps one
+func (s *Store) RebuildIndex(ctx context.Context) error {
+	if ix, ok := s.storage.(indexer); ok {
+		return ix.RebuildIndex(ctx)
+	}
+	return nil
+}
@@ -101,3 +101,41 @@ func TestList(t *testing.T) {
 		_ = os.RemoveAll(tempdir)
 	}
 }
+
+func TestListIgnore(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithExportKeys(ctx, false)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	tempdir := t.TempDir()
+	s := &Store{
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	assert.NoError(t, s.saveRecipients(ctx, []string{"john.doe"}, "test"))
+
+	for _, e := range []string{"foo", "bar/baz", "docs/readme"} {
+		sec := secrets.New()
+		sec.SetPassword("bar")
+		require.NoError(t, s.Set(ctx, e, sec))
+	}
+
+	lst, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar/baz", "docs/readme", "foo"}, lst)
+
+	require.NoError(t, s.storage.Set(ctx, ".gopass-ignore", []byte("docs/\n")))
+	lst, err = s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar/baz", "foo"}, lst)
+
+	lst, err = s.List(WithNoIgnore(ctx, true), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar/baz", "docs/readme", "foo"}, lst)
+}
@@ -0,0 +1,48 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// storageLocker is implemented by storage backends that can be locked
+// against concurrent changes by other processes
+type storageLocker interface {
+	Lock(ctx context.Context) (func(), error)
+}
+
+// IsReadOnly returns true if this store must not be changed
+func (s *Store) IsReadOnly() bool {
+	return s.readOnly
+}
+
+// CheckWritable returns an error if this store must not be changed
+func (s *Store) CheckWritable() error {
+	if s.readOnly {
+		return fmt.Errorf("mount '%s' is %w", s.alias, store.ErrReadOnly)
+	}
+	return nil
+}
+
+// lockStorage takes the lock of the storage backend for a change to the
+// store. The returned function releases it. Read-only stores can't be
+// locked.
+func (s *Store) lockStorage(ctx context.Context) (func(), error) {
+	if err := s.CheckWritable(); err != nil {
+		return nil, err
+	}
+
+	sl, ok := s.storage.(storageLocker)
+	if !ok {
+		debug.Log("locking not supported by %T", s.storage)
+		return func() {}, nil
+	}
+	unlock, err := sl.Lock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	return unlock, nil
+}
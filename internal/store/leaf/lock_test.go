@@ -0,0 +1,73 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockStorage(t *testing.T) {
+	tempdir := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", tempdir)
+
+	ctx := context.Background()
+	ctx = ctxutil.WithLockTimeout(ctx, 100*time.Millisecond)
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	// another process holding the lock
+	unlock, err := fs.New(s.path).Lock(ctx)
+	require.NoError(t, err)
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	err = s.Set(ctx, "zab", sec)
+	require.Error(t, err)
+	var le *fs.LockedError
+	assert.True(t, errors.As(err, &le))
+
+	unlock()
+	assert.NoError(t, s.Set(ctx, "zab", sec))
+}
+
+func TestReadOnly(t *testing.T) {
+	tempdir := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", tempdir)
+
+	ctx := context.Background()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+	s.alias = "team"
+	s.readOnly = true
+
+	assert.True(t, s.IsReadOnly())
+	err = s.CheckWritable()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, store.ErrReadOnly))
+	assert.Equal(t, "mount 'team' is read-only", err.Error())
+
+	// secrets can be read but not changed
+	_, err = s.Get(ctx, "foo/bar/baz")
+	assert.NoError(t, err)
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	assert.True(t, errors.Is(s.Set(ctx, "zab", sec), store.ErrReadOnly))
+	assert.True(t, errors.Is(s.Delete(ctx, "foo/bar/baz"), store.ErrReadOnly))
+	assert.True(t, errors.Is(s.Move(ctx, "foo/bar/baz", "bar"), store.ErrReadOnly))
+	assert.True(t, s.Exists(ctx, "foo/bar/baz"))
+
+	// fsck only checks the entries
+	assert.NoError(t, s.Fsck(ctx, ""))
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/hook"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -16,6 +17,12 @@ import (
 // supported. Each entry has to be decoded and encoded for the destination
 // to make sure it's encrypted for the right set of recipients.
 func (s *Store) Copy(ctx context.Context, from, to string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// recursive copy?
 	if s.IsDir(ctx, from) {
 		return fmt.Errorf("recursive operations are not supported")
@@ -25,6 +32,7 @@ func (s *Store) Copy(ctx context.Context, from, to string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get %q from store: %w", from, err)
 	}
+	ctx = withCommitOp(ctx, OpCopy, s.withAlias(from))
 	if err := s.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Copied from %s to %s", from, to)), to, content); err != nil {
 		return fmt.Errorf("failed to save %q to store: %w", to, err)
 	}
@@ -36,6 +44,12 @@ func (s *Store) Copy(ctx context.Context, from, to string) error {
 // for the destination store with the right set of recipients and remove it
 // from the old location afterwards.
 func (s *Store) Move(ctx context.Context, from, to string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// recursive move?
 	if s.IsDir(ctx, from) {
 		return fmt.Errorf("recursive operations are not supported")
@@ -45,6 +59,7 @@ func (s *Store) Move(ctx context.Context, from, to string) error {
 	if err != nil {
 		return fmt.Errorf("failed to decrypt %q: %w", from, err)
 	}
+	ctx = withCommitOp(ctx, OpMove, s.withAlias(from))
 	if err := s.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Move from %s to %s", from, to)), to, content); err != nil {
 		return fmt.Errorf("failed to write %q: %w", to, err)
 	}
@@ -54,20 +69,118 @@ func (s *Store) Move(ctx context.Context, from, to string) error {
 	return nil
 }
 
-// Delete will remove an single entry from the store
+// SameScope returns true if secrets at both names are encrypted for the same
+// recipients scope
+func (s *Store) SameScope(ctx context.Context, from, to string) bool {
+	return s.idFile(ctx, from) == s.idFile(ctx, to)
+}
+
+// MoveCiphertext moves or copies the encrypted secret from one location to
+// another without decrypting it. It must only be used within one recipients
+// scope, otherwise the secret stays encrypted for the wrong recipients.
+func (s *Store) MoveCiphertext(ctx context.Context, from, to string, delete bool) error {
+	if err := s.CheckWritable(); err != nil {
+		return err
+	}
+	if err := store.ValidateName(to); err != nil {
+		return err
+	}
+	if err := s.runHook(ctx, hook.PreWrite, to); err != nil {
+		return err
+	}
+	if delete {
+		if err := s.runHook(ctx, hook.PreRemove, from); err != nil {
+			return err
+		}
+	}
+	if err := s.moveCiphertext(ctx, from, to, delete); err != nil {
+		return err
+	}
+	s.runPostHook(ctx, hook.PostWrite, to)
+	return nil
+}
+
+func (s *Store) moveCiphertext(ctx context.Context, from, to string, delete bool) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if !s.SameScope(ctx, from, to) {
+		return fmt.Errorf("%s and %s have different recipients", from, to)
+	}
+
+	pFrom, pTo := s.storedPassfile(ctx, from), s.passfile(to)
+	buf, err := s.storage.Get(ctx, pFrom)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", from, err)
+	}
+	if err := s.Writer(ctx).Set(ctx, pTo, buf); err != nil {
+		return fmt.Errorf("failed to write %q: %w", to, err)
+	}
+	s.forgetPlaintext(to, false)
+	paths := []string{pTo}
+	if delete {
+		if err := s.Writer(ctx).Delete(ctx, pFrom); err != nil {
+			return fmt.Errorf("failed to delete %q: %w", from, err)
+		}
+		s.forgetPlaintext(from, false)
+		paths = append(paths, pFrom)
+	}
+
+	if err := s.Writer(ctx).Add(ctx, paths...); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %q to git: %w", paths, err)
+	}
+	return nil
+}
+
+// Delete will remove an single entry from the store. The pre-remove hook can
+// abort the removal.
 func (s *Store) Delete(ctx context.Context, name string) error {
+	if err := s.CheckWritable(); err != nil {
+		return err
+	}
+	if err := s.runHook(ctx, hook.PreRemove, name); err != nil {
+		return err
+	}
+
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return s.delete(ctx, name, false)
 }
 
-// Prune will remove a subtree from the Store
+// Prune will remove a subtree from the Store. The pre-remove hook can abort
+// the removal.
 func (s *Store) Prune(ctx context.Context, tree string) error {
+	if err := s.CheckWritable(); err != nil {
+		return err
+	}
+	if err := s.runHook(ctx, hook.PreRemove, tree); err != nil {
+		return err
+	}
+
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return s.delete(ctx, tree, true)
 }
 
 // delete will either delete one file or an directory tree depending on the
 // recurse flag
 func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
-	path := s.passfile(name)
+	path := s.storedPassfile(ctx, name)
+	defer s.forgetPlaintext(name, recurse)
 
 	if recurse {
 		if err := s.deleteRecurse(ctx, name, path); err != nil {
@@ -81,12 +194,13 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 			return err
 		}
 	}
+	debug.Info("removed", "store", s.alias, "name", name, "recursive", recurse)
 
 	if !ctxutil.IsGitCommit(ctx) {
 		return nil
 	}
 
-	if err := s.storage.Commit(ctx, fmt.Sprintf("Remove %s from store.", name)); err != nil {
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpRemove, s.withAlias(name), "", fmt.Sprintf("Remove %s from store.", name))); err != nil {
 		switch {
 		case errors.Is(err, store.ErrGitNotInit):
 			debug.Log("skipping git commit - git not initialized")
@@ -97,7 +211,7 @@ func (s *Store) delete(ctx context.Context, name string, recurse bool) error {
 		}
 	}
 
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) || errors.Is(err, store.ErrGitNoRemote) {
 			return nil
 		}
@@ -115,12 +229,12 @@ func (s *Store) deleteRecurse(ctx context.Context, name, path string) error {
 	name = strings.TrimPrefix(name, string(filepath.Separator))
 
 	debug.Log("Pruning %s", name)
-	if err := s.storage.Prune(ctx, name); err != nil {
+	if err := s.Writer(ctx).Prune(ctx, name); err != nil {
 		debug.Log("storage.Prune(%v) failed", name)
 		return err
 	}
 
-	if err := s.storage.Add(ctx, name); err != nil {
+	if err := s.Writer(ctx).Add(ctx, name); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -136,11 +250,11 @@ func (s *Store) deleteSingle(ctx context.Context, path string) error {
 	}
 
 	debug.Log("Deleting %s", path)
-	if err := s.storage.Delete(ctx, path); err != nil {
+	if err := s.Writer(ctx).Delete(ctx, path); err != nil {
 		return err
 	}
 
-	if err := s.storage.Add(ctx, path); err != nil {
+	if err := s.Writer(ctx).Add(ctx, path); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -306,3 +306,45 @@ func TestPrune(t *testing.T) {
 		_ = os.RemoveAll(tempdir)
 	}
 }
+
+func TestMoveCiphertext(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	tempdir := t.TempDir()
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.AddRecipientAt(ctx, "team/", "0xA3683834"))
+
+	assert.True(t, s.SameScope(ctx, "foo/bar/baz", "baz/zab"))
+	assert.True(t, s.SameScope(ctx, "team/a", "team/sub/b"))
+	assert.False(t, s.SameScope(ctx, "foo/bar/baz", "team/baz"))
+
+	// the encrypted content is kept as is
+	require.NoError(t, s.storage.Set(ctx, s.passfile("foo/bar/baz"), []byte("ciphertext")))
+	require.NoError(t, s.MoveCiphertext(ctx, "foo/bar/baz", "baz/copy", false))
+	require.NoError(t, s.MoveCiphertext(ctx, "foo/bar/baz", "baz/moved", true))
+	assert.False(t, s.Exists(ctx, "foo/bar/baz"))
+	for _, name := range []string{"baz/copy", "baz/moved"} {
+		buf, err := s.storage.Get(ctx, s.passfile(name))
+		require.NoError(t, err)
+		assert.Equal(t, "ciphertext", string(buf))
+	}
+
+	// but never moved into another scope
+	assert.Error(t, s.MoveCiphertext(ctx, "baz/moved", "team/moved", true))
+	assert.True(t, s.Exists(ctx, "baz/moved"))
+}
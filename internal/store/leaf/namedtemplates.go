@@ -0,0 +1,123 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+)
+
+// TemplateDir is the folder holding the named templates of a store. They are
+// encrypted like any other secret, but not listed as one.
+const TemplateDir = ".gopass/templates"
+
+// namedTemplate returns the name of the secret holding the named template
+func namedTemplate(name string) (string, error) {
+	name = strings.TrimPrefix(name, Sep)
+	clean := path.Clean("/" + name)
+	if name == "" || clean != "/"+name || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	return TemplateDir + Sep + name, nil
+}
+
+// ListNamedTemplates returns the names of all named templates in this store
+func (s *Store) ListNamedTemplates(ctx context.Context, prefix string) []string {
+	if s.crypto == nil {
+		return nil
+	}
+
+	dir := filepath.Join(s.path, filepath.FromSlash(TemplateDir))
+	cExt := "." + s.crypto.Ext()
+	var names []string
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, cExt) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), cExt)
+		if prefix != "" {
+			name = prefix + Sep + name
+		}
+		names = append(names, name)
+		return nil
+	}); err != nil && !os.IsNotExist(err) {
+		debug.Log("failed to list named templates: %s", err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// namedTemplateEntries returns the secrets holding the named templates, e.g.
+// to re-encrypt them along with all other secrets
+func (s *Store) namedTemplateEntries(ctx context.Context) []string {
+	prefix := TemplateDir
+	if s.alias != "" {
+		prefix = s.alias + Sep + TemplateDir
+	}
+	return s.ListNamedTemplates(ctx, prefix)
+}
+
+// HasNamedTemplate returns true if the named template exists
+func (s *Store) HasNamedTemplate(ctx context.Context, name string) bool {
+	tn, err := namedTemplate(name)
+	if err != nil {
+		return false
+	}
+	return s.storage.Exists(ctx, s.passfile(tn))
+}
+
+// GetNamedTemplate returns the decrypted content of the named template
+func (s *Store) GetNamedTemplate(ctx context.Context, name string) ([]byte, error) {
+	tn, err := namedTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := s.storage.Get(ctx, s.passfile(tn))
+	if err != nil {
+		debug.Log("template %s not found: %s", name, err)
+		return nil, store.ErrNotFound
+	}
+	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt template %q: %w", name, err)
+	}
+	return content, nil
+}
+
+// SetNamedTemplate encrypts and (over)writes the named template
+func (s *Store) SetNamedTemplate(ctx context.Context, name string, content []byte) error {
+	tn, err := namedTemplate(name)
+	if err != nil {
+		return err
+	}
+	sec := &secrets.Plain{}
+	if _, err := sec.Write(content); err != nil {
+		return err
+	}
+	return s.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Save template %s", name)), tn, sec)
+}
+
+// RemoveNamedTemplate deletes the named template
+func (s *Store) RemoveNamedTemplate(ctx context.Context, name string) error {
+	tn, err := namedTemplate(name)
+	if err != nil {
+		return err
+	}
+	return s.Delete(ctx, tn)
+}
@@ -0,0 +1,31 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/policy"
+)
+
+// PasswordPolicy returns the policy for the given secret from the plaintext
+// policy files in .gopass/policy. The policy of the closest folder applies.
+// It returns false if there is none.
+func (s *Store) PasswordPolicy(ctx context.Context, name string) (policy.Policy, bool, error) {
+	for _, prefix := range policy.Prefixes(name) {
+		fn := policy.File(prefix)
+		if !s.storage.Exists(ctx, fn) || s.storage.IsDir(ctx, fn) {
+			continue
+		}
+		buf, err := s.storage.Get(ctx, fn)
+		if err != nil {
+			return policy.Policy{}, false, fmt.Errorf("failed to read %s: %w", fn, err)
+		}
+		p, err := policy.Parse(buf)
+		if err != nil {
+			return policy.Policy{}, false, fmt.Errorf("failed to parse %s: %w", fn, err)
+		}
+		p.Prefix = prefix
+		return p, true, nil
+	}
+	return policy.Policy{}, false, nil
+}
@@ -0,0 +1,25 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+)
+
+// PasswordRules returns the custom password rules from the .pwrules file in
+// the root of this store. It returns no rules if there is no such file.
+func (s *Store) PasswordRules(ctx context.Context) (pwrules.Rules, error) {
+	if !s.storage.Exists(ctx, pwrules.RulesFile) {
+		return nil, nil
+	}
+	buf, err := s.storage.Get(ctx, pwrules.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pwrules.RulesFile, err)
+	}
+	rs, err := pwrules.ParseRules(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", pwrules.RulesFile, err)
+	}
+	return rs, nil
+}
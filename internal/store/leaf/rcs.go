@@ -2,16 +2,47 @@ package leaf
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/hook"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
 )
 
+// syncConfigurer is implemented by storage backends that sync with a remote
+type syncConfigurer interface {
+	ConfigureSync(strategy string, autoPush bool, interval time.Duration)
+}
+
+// syncNotifier is implemented by storage backends that sync with a remote
+type syncNotifier interface {
+	OnSync(fn func(context.Context))
+}
+
+// initSync passes the sync settings from the context on to the storage and
+// runs the post-sync hook after every sync, by gopass sync or autosync
+func (s *Store) initSync(ctx context.Context) {
+	if sn, ok := s.storage.(syncNotifier); ok {
+		sn.OnSync(func(ctx context.Context) {
+			s.runPostHook(ctx, hook.PostSync, "")
+		})
+	}
+	sc, ok := s.storage.(syncConfigurer)
+	if !ok {
+		debug.Log("sync settings not supported by %T", s.storage)
+		return
+	}
+	sc.ConfigureSync(GetPullStrategy(ctx), IsAutoPush(ctx), GetAutoSyncInterval(ctx))
+}
+
 // GitInit initializes the git storage
 func (s *Store) GitInit(ctx context.Context) error {
 	storage, err := backend.InitStorage(ctx, backend.GetStorageBackend(ctx), s.path)
@@ -24,13 +55,18 @@ func (s *Store) GitInit(ctx context.Context) error {
 
 // ListRevisions will list all revisions for a secret
 func (s *Store) ListRevisions(ctx context.Context, name string) ([]backend.Revision, error) {
-	p := s.passfile(name)
+	p := s.storedPassfile(ctx, name)
 	return s.storage.Revisions(ctx, p)
 }
 
+// Meta returns when the secret was created and last changed
+func (s *Store) Meta(ctx context.Context, name string) (backend.Meta, error) {
+	return s.storage.Meta(ctx, s.storedPassfile(ctx, name))
+}
+
 // GetRevision will retrieve a single revision from the backend
 func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.Secret, error) {
-	p := s.passfile(name)
+	p := s.storedPassfile(ctx, name)
 	ciphertext, err := s.storage.GetRevision(ctx, p, revision)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ciphertext of %q@%q: %w", name, revision, err)
@@ -39,6 +75,10 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 	content, err := s.crypto.Decrypt(ctx, ciphertext)
 	if err != nil {
 		debug.Log("Decryption failed: %s", err)
+		var nsk *backend.NoSecretKeyError
+		if errors.As(err, &nsk) {
+			s.printNoSecretKey(ctx, name, nsk)
+		}
 		return nil, store.ErrDecrypt
 	}
 
@@ -49,6 +89,84 @@ func (s *Store) GetRevision(ctx context.Context, name, revision string) (gopass.
 	return sec, nil
 }
 
+// ListDeleted lists the secrets that were removed from the store and do not
+// exist anymore. Only the most recent removal of each secret is returned.
+func (s *Store) ListDeleted(ctx context.Context) ([]backend.Deletion, error) {
+	dels, err := s.storage.Deleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cExt := "." + s.crypto.Ext()
+	seen := make(map[string]bool, len(dels))
+	out := make([]backend.Deletion, 0, len(dels))
+	for _, del := range dels {
+		if !strings.HasSuffix(del.Name, cExt) || seen[del.Name] {
+			continue
+		}
+		seen[del.Name] = true
+		if s.storage.Exists(ctx, del.Name) {
+			continue
+		}
+		del.Name = strings.TrimSuffix(del.Name, cExt)
+		if s.alias != "" {
+			del.Name = s.alias + Sep + del.Name
+		}
+		out = append(out, del)
+	}
+	return out, nil
+}
+
+// Restore writes the encrypted content of a secret at the given revision
+// back to the store and commits it. The content is restored as is, i.e. it
+// is encrypted for the recipients at that revision. The pre-write hook can
+// abort it.
+func (s *Store) Restore(ctx context.Context, name, revision string) error {
+	if err := s.CheckWritable(); err != nil {
+		return err
+	}
+	if err := s.runHook(ctx, hook.PreWrite, name); err != nil {
+		return err
+	}
+	if err := s.restore(ctx, name, revision); err != nil {
+		return err
+	}
+	s.runPostHook(ctx, hook.PostWrite, name)
+	return nil
+}
+
+func (s *Store) restore(ctx context.Context, name, revision string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := s.storedPassfile(ctx, name)
+	ciphertext, err := s.storage.GetRevision(ctx, p, revision)
+	if err != nil {
+		return fmt.Errorf("failed to get ciphertext of %q@%q: %w", name, revision, err)
+	}
+
+	if err := s.Writer(ctx).Set(ctx, p, ciphertext); err != nil {
+		return fmt.Errorf("failed to write secret: %w", err)
+	}
+	s.forgetPlaintext(name, false)
+
+	if err := s.Writer(ctx).Add(ctx, p); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %q to git: %w", p, err)
+	}
+
+	if !ctxutil.IsGitCommit(ctx) {
+		return nil
+	}
+
+	return s.gitCommitAndPush(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Restored from revision %s", revision)), OpRestore, name, "")
+}
+
 // GitStatus shows the git status output
 func (s *Store) GitStatus(ctx context.Context, _ string) error {
 	buf, err := s.storage.Status(ctx)
@@ -58,3 +176,44 @@ func (s *Store) GitStatus(ctx context.Context, _ string) error {
 	out.Printf(ctx, string(buf))
 	return nil
 }
+
+// garbageCollector is implemented by storage backends that can remove
+// unreachable objects and pack the remaining ones
+type garbageCollector interface {
+	GC(ctx context.Context, aggressive bool) error
+}
+
+// GC packs the storage, if supported by the backend
+func (s *Store) GC(ctx context.Context, aggressive bool) error {
+	gc, ok := s.storage.(garbageCollector)
+	if !ok {
+		return fmt.Errorf("the %s storage of %s can not be packed: %w", s.storage.Name(), s.path, backend.ErrNotSupported)
+	}
+	return gc.GC(ctx, aggressive)
+}
+
+// historyCompacter is implemented by storage backends that can drop old
+// revisions from their history
+type historyCompacter interface {
+	CompactHistory(ctx context.Context, keep int, dryRun bool) (backend.HistoryCompaction, error)
+}
+
+// CompactHistory rewrites the history of the store to keep only the last
+// keep revisions of each secret. A dry run only reports what would be
+// removed.
+func (s *Store) CompactHistory(ctx context.Context, keep int, dryRun bool) (backend.HistoryCompaction, error) {
+	hc, ok := s.storage.(historyCompacter)
+	if !ok {
+		return backend.HistoryCompaction{}, fmt.Errorf("the %s storage of %s keeps no history: %w", s.storage.Name(), s.path, backend.ErrNotSupported)
+	}
+	if dryRun {
+		return hc.CompactHistory(ctx, keep, true)
+	}
+
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return backend.HistoryCompaction{}, err
+	}
+	defer unlock()
+	return hc.CompactHistory(ctx, keep, false)
+}
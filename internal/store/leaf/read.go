@@ -2,10 +2,15 @@ package leaf
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
 
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
+	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/debug"
@@ -15,7 +20,7 @@ import (
 
 // Get returns the plaintext of a single key
 func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
-	p := s.passfile(name)
+	p := s.storedPassfile(ctx, name)
 
 	ciphertext, err := s.storage.Get(ctx, p)
 	if err != nil {
@@ -23,11 +28,17 @@ func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 		return nil, store.ErrNotFound
 	}
 
-	content, err := s.crypto.Decrypt(ctx, ciphertext)
+	content, err := s.decrypt(ctx, name, ciphertext)
 	if err != nil {
+		var nsk *backend.NoSecretKeyError
+		if errors.As(err, &nsk) {
+			s.printNoSecretKey(ctx, name, nsk)
+			return nil, store.ErrDecrypt
+		}
 		out.Errorf(ctx, "Decryption failed: %s\n%s", err, string(content))
 		return nil, store.ErrDecrypt
 	}
+	debug.Info("decrypted secret", "store", s.alias, "name", name)
 
 	if !ctxutil.IsShowParsing(ctx) {
 		return secrets.ParsePlain(content), nil
@@ -35,3 +46,73 @@ func (s *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 
 	return secparse.Parse(content)
 }
+
+// printNoSecretKey explains which keys the secret is encrypted for and how
+// the user can get access to it
+func (s *Store) printNoSecretKey(ctx context.Context, name string, nsk *backend.NoSecretKeyError) {
+	keys := make([]string, 0, len(nsk.Recipients))
+	encTo := make(map[string]bool, len(nsk.Recipients))
+	for _, r := range nsk.Recipients {
+		keys = append(keys, describeMissingKey(r))
+		if r.Fingerprint != "" {
+			encTo[r.Fingerprint] = true
+		}
+	}
+	out.Errorf(ctx, "Secret %q is encrypted for %s", path.Join(s.alias, name), joinAnd(keys))
+
+	fsck := "gopass fsck --fix"
+	if s.alias != "" {
+		fsck += " " + s.alias + "/"
+	}
+	rs, err := s.GetRecipients(ctx, name)
+	if err != nil {
+		debug.Log("failed to get recipients of %s: %s", name, err)
+	}
+	recipients := make(map[string]bool, len(rs))
+	for _, r := range rs {
+		recipients[s.crypto.Fingerprint(ctx, r)] = true
+	}
+	ids, err := s.crypto.ListIdentities(ctx)
+	if err != nil {
+		debug.Log("failed to list identities: %s", err)
+	}
+	for _, id := range ids {
+		fp := s.crypto.Fingerprint(ctx, id)
+		if recipients[fp] && !encTo[fp] {
+			out.Printf(ctx, "Your key %s is a recipient of the store but the secret has not been re-encrypted for it yet.", id)
+			out.Printf(ctx, "Ask a recipient to re-encrypt it by running '%s'", fsck)
+			return
+		}
+	}
+	if len(ids) > 0 {
+		add := "gopass recipients add"
+		if s.alias != "" {
+			add += " --store " + s.alias
+		}
+		out.Printf(ctx, "None of your keys is a recipient of the store. Ask a recipient to add yours by running '%s %s'", add, ids[0])
+		return
+	}
+	out.Printf(ctx, "You don't have any secret key. Import yours or ask a recipient to add a new one and to re-encrypt the secret by running '%s'", fsck)
+}
+
+func describeMissingKey(k backend.MissingKey) string {
+	var details []string
+	if k.Name != "" {
+		details = append(details, k.Name)
+	}
+	if k.Own {
+		details = append(details, "you: no secret key")
+	}
+	if len(details) < 1 {
+		return k.ID
+	}
+	return fmt.Sprintf("%s (%s)", k.ID, strings.Join(details, ", "))
+}
+
+// joinAnd joins the elements like "a, b and c"
+func joinAnd(elems []string) string {
+	if len(elems) < 2 {
+		return strings.Join(elems, "")
+	}
+	return strings.Join(elems[:len(elems)-1], ", ") + " and " + elems[len(elems)-1]
+}
@@ -0,0 +1,67 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintNoSecretKey(t *testing.T) {
+	ctx := context.Background()
+	color.NoColor = true
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	s, err := createSubStore(t.TempDir())
+	require.NoError(t, err)
+	s.alias = "work"
+
+	nsk := &backend.NoSecretKeyError{
+		Recipients: []backend.MissingKey{
+			{ID: "0xAAAA", Fingerprint: "AAAA", Name: "alice@corp", Own: true},
+			{ID: "0xBBBB", Fingerprint: "BBBB", Name: "bob@corp"},
+			{ID: "0xCCCC"},
+		},
+	}
+
+	// our key is a recipient of the store
+	require.NoError(t, s.storage.Set(ctx, s.crypto.IDFile(), []byte("0xDEADBEEF\nBBBB\n")))
+	s.printNoSecretKey(ctx, "db", nsk)
+	assert.Contains(t, obuf.String(), `Secret "work/db" is encrypted for 0xAAAA (alice@corp, you: no secret key), 0xBBBB (bob@corp) and 0xCCCC`)
+	assert.Contains(t, obuf.String(), "gopass fsck --fix work/")
+	obuf.Reset()
+
+	// we're not a recipient
+	require.NoError(t, s.storage.Set(ctx, s.crypto.IDFile(), []byte("BBBB\n")))
+	s.printNoSecretKey(ctx, "db", nsk)
+	assert.Contains(t, obuf.String(), "gopass recipients add --store work 0xDEADBEEF")
+	obuf.Reset()
+
+	// the root store
+	s.alias = ""
+	require.NoError(t, s.storage.Set(ctx, s.crypto.IDFile(), []byte("0xDEADBEEF\n")))
+	s.printNoSecretKey(ctx, "db", nsk)
+	assert.Contains(t, obuf.String(), `Secret "db" is encrypted for`)
+	assert.Contains(t, obuf.String(), "'gopass fsck --fix'")
+}
+
+func TestJoinAnd(t *testing.T) {
+	assert.Equal(t, "", joinAnd(nil))
+	assert.Equal(t, "a", joinAnd([]string{"a"}))
+	assert.Equal(t, "a and b", joinAnd([]string{"a", "b"}))
+	assert.Equal(t, "a, b and c", joinAnd([]string{"a", "b", "c"}))
+}
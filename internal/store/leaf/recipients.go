@@ -9,12 +9,12 @@ import (
 
 	"errors"
 
-	"github.com/google/go-cmp/cmp"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/recipients"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
 )
 
 const (
@@ -31,7 +31,8 @@ func (s *Store) Recipients(ctx context.Context) []string {
 	return rs
 }
 
-// RecipientsTree returns a mapping of secrets to recipients
+// RecipientsTree returns a mapping of directories to recipients. Every
+// nested id file starts a new scope.
 func (s *Store) RecipientsTree(ctx context.Context) map[string][]string {
 	idfs := s.idFiles(ctx)
 	out := make(map[string][]string, len(idfs))
@@ -46,43 +47,110 @@ func (s *Store) RecipientsTree(ctx context.Context) map[string][]string {
 			debug.Log("failed to list recipients: %s", err)
 			continue
 		}
-		if cmp.Equal(out[""], srs) {
-			debug.Log("root recipients equal secret recipients from %s", idf)
-			continue
-		}
-		dir := filepath.Dir(idf)
+		dir := filepath.ToSlash(filepath.Dir(idf))
 		out[dir] = srs
 	}
 	out[""] = root
 	return out
 }
 
+// RecipientsAt returns the recipients of the scope starting at the given
+// directory or those of the closest parent scope
+func (s *Store) RecipientsAt(ctx context.Context, dir string) []string {
+	rs, err := s.getRecipients(ctx, s.idFile(ctx, cleanScope(dir)))
+	if err != nil {
+		out.Errorf(ctx, "failed to read recipient list: %s", err)
+	}
+	return rs
+}
+
+// RecipientsFileAt returns the id file the recipients of the given directory or
+// secret are read from, i.e. the closest one walking up from it
+func (s *Store) RecipientsFileAt(ctx context.Context, dir string) string {
+	return s.idFile(ctx, cleanScope(dir))
+}
+
+// SecretsAt returns the secrets encrypted for the recipients of the scope
+// starting at the given directory or those of the closest parent scope.
+// Secrets below nested scopes are not included.
+func (s *Store) SecretsAt(ctx context.Context, dir string) ([]string, error) {
+	entries, err := s.scopeEntries(ctx, s.idFile(ctx, cleanScope(dir)))
+	if err != nil {
+		return nil, err
+	}
+
+	tplPrefix := TemplateDir + Sep
+	if s.alias != "" {
+		tplPrefix = s.alias + Sep + tplPrefix
+	}
+	secrets := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e, tplPrefix) {
+			continue
+		}
+		secrets = append(secrets, e)
+	}
+	return secrets, nil
+}
+
 // AddRecipient adds a new recipient to the list
 func (s *Store) AddRecipient(ctx context.Context, id string) error {
-	rs, err := s.GetRecipients(ctx, "")
+	return s.AddRecipientAt(ctx, "", id)
+}
+
+// AddRecipientAt adds a new recipient to the scope starting at the given
+// directory. If there is no id file in this directory yet a new scope is
+// created. Only the secrets in this scope are re-encrypted.
+func (s *Store) AddRecipientAt(ctx context.Context, dir, id string) error {
+	unlock, err := s.lockStorage(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read recipient list: %w", err)
+		return err
 	}
+	defer unlock()
 
-	debug.Log("new recipient: %q - existing: %+v", id, rs)
-	for _, k := range rs {
-		if k == id {
-			return fmt.Errorf("recipient already in store")
-		}
+	if err := s.CheckRecipientsHash(ctx); err != nil {
+		return err
 	}
 
-	rs = append(rs, id)
+	idf := s.scopeFile(dir)
 
-	if err := s.saveRecipients(ctx, rs, "Added Recipient "+id); err != nil {
+	var rs []string
+	if dir := cleanScope(dir); dir != "" && !s.storage.Exists(ctx, idf) {
+		out.Printf(ctx, "Creating new recipients scope for %s", dir)
+		// make sure we don't lock ourselves out of the new scope
+		rs = s.ensureOurKeyID(ctx, []string{id})
+	} else {
+		rs, err = s.getRecipients(ctx, idf)
+		if err != nil {
+			return fmt.Errorf("failed to read recipient list: %w", err)
+		}
+
+		debug.Log("new recipient: %q - existing: %+v", id, rs)
+		for _, k := range rs {
+			if k == id {
+				return fmt.Errorf("recipient already in store")
+			}
+		}
+
+		rs = append(rs, id)
+	}
+
+	if err := s.saveScopeRecipients(ctx, idf, rs, "Added Recipient "+id); err != nil {
 		return fmt.Errorf("failed to save recipients: %w", err)
 	}
 
 	out.Printf(ctx, "Reencrypting existing secrets. This may take some time ...")
-	return s.reencrypt(ctxutil.WithCommitMessage(ctx, "Added Recipient "+id))
+	return s.reencryptScope(ctxutil.WithCommitMessage(ctx, "Added Recipient "+id), idf)
 }
 
 // SaveRecipients persists the current recipients on disk
 func (s *Store) SaveRecipients(ctx context.Context) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	rs, err := s.GetRecipients(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to get recipients: %w", err)
@@ -92,6 +160,12 @@ func (s *Store) SaveRecipients(ctx context.Context) error {
 
 // SetRecipients will update the stored recipients and the associated checksum
 func (s *Store) SetRecipients(ctx context.Context, rs []string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return s.saveRecipients(ctx, rs, "Set Recipients")
 }
 
@@ -99,12 +173,34 @@ func (s *Store) SetRecipients(ctx context.Context, rs []string) error {
 // but if this key is not available on this machine we
 // just try to remove it literally
 func (s *Store) RemoveRecipient(ctx context.Context, id string) error {
+	return s.RemoveRecipientAt(ctx, "", id)
+}
+
+// RemoveRecipientAt will remove the given recipient from the scope starting
+// at the given directory. Removing the last recipient of a nested scope removes
+// its id file, so the secrets fall back to the recipients of the parent scope.
+func (s *Store) RemoveRecipientAt(ctx context.Context, dir, id string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.CheckRecipientsHash(ctx); err != nil {
+		return err
+	}
+
+	idf := s.scopeFile(dir)
+	if dir := cleanScope(dir); dir != "" && !s.storage.Exists(ctx, idf) {
+		return fmt.Errorf("%s has no recipients of its own", dir)
+	}
+
 	keys, err := s.crypto.FindRecipients(ctx, id)
 	if err != nil {
 		out.Printf(ctx, "Warning: Failed to get GPG Key Info for %s: %s", id, err)
 	}
 
-	rs, err := s.GetRecipients(ctx, "")
+	rs, err := s.getRecipients(ctx, idf)
 	if err != nil {
 		return fmt.Errorf("failed to read recipient list: %w", err)
 	}
@@ -129,11 +225,120 @@ RECIPIENTS:
 		return fmt.Errorf("recipient not in store")
 	}
 
-	if err := s.saveRecipients(ctx, nk, "Removed Recipient "+id); err != nil {
+	if len(nk) < 1 && cleanScope(dir) != "" {
+		if err := s.removeScope(ctx, idf, "Removed Recipient "+id); err != nil {
+			return err
+		}
+		s.removeUnusedPublicKeys(ctx, rs)
+		return s.offerReencryptSubtree(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+id), cleanScope(dir))
+	}
+
+	if err := s.saveScopeRecipients(ctx, idf, nk, "Removed Recipient "+id); err != nil {
 		return fmt.Errorf("failed to save recipients: %w", err)
 	}
 
-	return s.reencrypt(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+id))
+	// remove the exported public keys of all removed recipients
+	s.removeUnusedPublicKeys(ctx, removedRecipients(rs, nk))
+
+	return s.reencryptScope(ctxutil.WithCommitMessage(ctx, "Removed Recipient "+id), idf)
+}
+
+// removeUnusedPublicKeys removes the exported public keys of the given
+// recipients unless they are still used by any scope
+func (s *Store) removeUnusedPublicKeys(ctx context.Context, removed []string) {
+	if !ctxutil.IsExportKeys(ctx) {
+		return
+	}
+
+	used := make(map[string]bool, len(removed))
+	for _, rs := range s.RecipientsTree(ctx) {
+		for _, r := range rs {
+			used[r] = true
+		}
+	}
+
+	for _, k := range removed {
+		if used[k] {
+			debug.Log("not removing public key for %s. still in use", k)
+			continue
+		}
+		if err := s.removePublicKey(ctx, k); err != nil {
+			out.Errorf(ctx, "Failed to remove public key for %s: %s", k, err)
+		}
+	}
+}
+
+// removeScope removes a nested id file. The secrets of this scope will be
+// encrypted for the recipients of the parent scope from now on.
+func (s *Store) removeScope(ctx context.Context, idf, msg string) error {
+	if err := s.Writer(ctx).Delete(ctx, idf); err != nil {
+		return fmt.Errorf("failed to remove recipients file %q: %w", idf, err)
+	}
+
+	if err := s.Writer(ctx).Add(ctx, idf); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) {
+			return fmt.Errorf("failed to add file %q to git: %w", idf, err)
+		}
+	}
+
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpRecipients, "", "", msg)); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) && !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+
+	s.updateRecipientsState(ctx)
+	return nil
+}
+
+// offerReencryptSubtree asks the user to re-encrypt the secrets of a
+// removed scope for the recipients of the parent scope
+func (s *Store) offerReencryptSubtree(ctx context.Context, dir string) error {
+	idf := s.idFile(ctx, dir)
+	scope := idf
+	if ctxutil.IsDryRun(ctx) {
+		// the removed id file is still there
+		idf = s.idFile(ctx, filepath.Dir(dir))
+	}
+	out.Printf(ctx, "Secrets below %s are encrypted for the recipients from %s now", dir, idf)
+
+	if !ctxutil.IsDryRun(ctx) && !termio.Confirm(ctx, fmt.Sprintf("Do you want to re-encrypt the secrets below %s for these recipients?", dir)) {
+		out.Printf(ctx, "Existing secrets have not been re-encrypted. Run 'gopass fsck --decrypt' to do so later.")
+		return nil
+	}
+
+	out.Printf(ctx, "Reencrypting existing secrets. This may take some time ...")
+	entries, err := s.scopeEntries(ctx, scope)
+	if err != nil {
+		return err
+	}
+
+	prefix := dir + Sep
+	if s.alias != "" {
+		prefix = s.alias + Sep + prefix
+	}
+	subtree := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e, prefix) {
+			subtree = append(subtree, e)
+		}
+	}
+	return s.reencryptEntries(ctx, subtree)
+}
+
+// removedRecipients returns all recipients in rs that are not in nk
+func removedRecipients(rs, nk []string) []string {
+	keep := make(map[string]bool, len(nk))
+	for _, k := range nk {
+		keep[k] = true
+	}
+	removed := make([]string, 0, len(rs)-len(nk))
+	for _, k := range rs {
+		if !keep[k] {
+			removed = append(removed, k)
+		}
+	}
+	return removed
 }
 
 func (s *Store) ensureOurKeyID(ctx context.Context, rs []string) []string {
@@ -187,6 +392,12 @@ type keyExporter interface {
 // ExportMissingPublicKeys will export any possibly missing public keys to the
 // stores .public-keys directory
 func (s *Store) ExportMissingPublicKeys(ctx context.Context, rs []string) (bool, error) {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
 	exp, ok := s.crypto.(keyExporter)
 	if !ok {
 		debug.Log("not exporting public keys for %T", s.crypto)
@@ -209,7 +420,7 @@ func (s *Store) ExportMissingPublicKeys(ctx context.Context, rs []string) (bool,
 		}
 		// at least one key has been exported
 		exported = true
-		if err := s.storage.Add(ctx, path); err != nil {
+		if err := s.Writer(ctx).Add(ctx, path); err != nil {
 			if errors.Is(err, store.ErrGitNotInit) {
 				continue
 			}
@@ -217,7 +428,7 @@ func (s *Store) ExportMissingPublicKeys(ctx context.Context, rs []string) (bool,
 			out.Errorf(ctx, "failed to add public key for %q to git: %s", r, err)
 			continue
 		}
-		if err := s.storage.Commit(ctx, fmt.Sprintf("Exported Public Keys %s", r)); err != nil && err != store.ErrGitNothingToCommit {
+		if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpMaintenance, "", "", fmt.Sprintf("Exported Public Keys %s", r))); err != nil && err != store.ErrGitNothingToCommit {
 			failed = true
 			out.Errorf(ctx, "Failed to git commit: %s", err)
 			continue
@@ -231,23 +442,28 @@ func (s *Store) ExportMissingPublicKeys(ctx context.Context, rs []string) (bool,
 
 // Save all Recipients in memory to the .gpg-id file on disk.
 func (s *Store) saveRecipients(ctx context.Context, rs []string, msg string) error {
+	return s.saveScopeRecipients(ctx, s.idFile(ctx, ""), rs, msg)
+}
+
+// saveScopeRecipients writes the given recipients to the given id file
+func (s *Store) saveScopeRecipients(ctx context.Context, idf string, rs []string, msg string) error {
 	if len(rs) < 1 {
 		return fmt.Errorf("can not remove all recipients")
 	}
 
-	idf := s.idFile(ctx, "")
 	buf := recipients.Marshal(rs)
-	if err := s.storage.Set(ctx, idf, buf); err != nil {
+	if err := s.Writer(ctx).Set(ctx, idf, buf); err != nil {
 		return fmt.Errorf("failed to write recipients file: %w", err)
 	}
+	s.updateRecipientsState(ctx)
 
-	if err := s.storage.Add(ctx, idf); err != nil {
+	if err := s.Writer(ctx).Add(ctx, idf); err != nil {
 		if err != store.ErrGitNotInit {
 			return fmt.Errorf("failed to add file %q to git: %w", idf, err)
 		}
 	}
 
-	if err := s.storage.Commit(ctx, msg); err != nil {
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpRecipients, "", "", msg)); err != nil {
 		if err != store.ErrGitNotInit && err != store.ErrGitNothingToCommit {
 			return fmt.Errorf("failed to commit changes to git: %w", err)
 		}
@@ -261,7 +477,7 @@ func (s *Store) saveRecipients(ctx context.Context, rs []string, msg string) err
 	}
 
 	// push to remote repo
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -213,6 +213,46 @@ func TestRemoveRecipient(t *testing.T) {
 	assert.Equal(t, []string{"0xFEEDBEEF"}, rs)
 }
 
+func TestRemoveRecipientPublicKey(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = ctxutil.WithExportKeys(ctx, true)
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+	_, _, err = createStore(tempdir, nil, nil)
+	assert.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	for _, k := range []string{"0xDEADBEEF", "0xFEEDBEEF"} {
+		require.NoError(t, s.storage.Set(ctx, filepath.Join(keyDir, k), []byte(k)))
+	}
+
+	require.NoError(t, s.RemoveRecipient(ctx, "0xDEADBEEF"))
+	assert.False(t, s.storage.Exists(ctx, filepath.Join(keyDir, "0xDEADBEEF")))
+	assert.True(t, s.storage.Exists(ctx, filepath.Join(keyDir, "0xFEEDBEEF")))
+}
+
+func TestRemovedRecipients(t *testing.T) {
+	assert.Equal(t, []string{"b", "d"}, removedRecipients([]string{"a", "b", "c", "d"}, []string{"a", "c"}))
+	assert.Equal(t, []string{}, removedRecipients([]string{"a"}, []string{"a"}))
+}
+
 func TestListRecipients(t *testing.T) {
 	ctx := context.Background()
 
@@ -245,3 +285,67 @@ func TestListRecipients(t *testing.T) {
 
 	assert.Equal(t, "0xDEADBEEF", s.OurKeyID(ctx))
 }
+
+func TestRecipientScopes(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+
+	tempdir := t.TempDir()
+	genRecs, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	// a new scope gets the new recipient and our own key
+	require.NoError(t, s.AddRecipientAt(ctx, "foo/bar/", "0xA3683834"))
+	assert.Contains(t, obuf.String(), "Creating new recipients scope for foo/bar")
+	assert.True(t, s.storage.Exists(ctx, filepath.Join("foo", "bar", plain.IDFile)))
+	rs, err := s.GetRecipients(ctx, "foo/bar/baz")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0xA3683834", "0xDEADBEEF"}, rs)
+	assert.Equal(t, genRecs, s.Recipients(ctx))
+	assert.Equal(t, rs, s.RecipientsAt(ctx, "foo/bar/baz"))
+	assert.Equal(t, filepath.Join("foo", "bar", plain.IDFile), s.RecipientsFileAt(ctx, "foo/bar/baz"))
+	assert.Equal(t, plain.IDFile, s.RecipientsFileAt(ctx, "foo/baz"))
+
+	assert.Equal(t, map[string][]string{
+		"":        genRecs,
+		"foo/bar": rs,
+	}, s.RecipientsTree(ctx))
+
+	// re-encryption is limited to the secrets of a scope
+	entries, err := s.scopeEntries(ctx, s.scopeFile(""))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baz/ing/a"}, entries)
+	entries, err = s.scopeEntries(ctx, s.scopeFile("foo/bar"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo/bar/baz"}, entries)
+	secrets, err := s.SecretsAt(ctx, "foo/bar/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo/bar/baz"}, secrets)
+	secrets, err = s.SecretsAt(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baz/ing/a"}, secrets)
+
+	assert.Error(t, s.RemoveRecipientAt(ctx, "baz", "0xDEADBEEF"))
+
+	// removing the last recipient of a scope falls back to the parent scope
+	require.NoError(t, s.RemoveRecipientAt(ctx, "foo/bar", "0xA3683834"))
+	assert.Equal(t, []string{"0xDEADBEEF"}, s.RecipientsAt(ctx, "foo/bar"))
+	require.NoError(t, s.RemoveRecipientAt(ctx, "foo/bar", "0xDEADBEEF"))
+	assert.False(t, s.storage.Exists(ctx, filepath.Join("foo", "bar", plain.IDFile)))
+	assert.Equal(t, genRecs, s.RecipientsAt(ctx, "foo/bar/baz"))
+	assert.Contains(t, obuf.String(), "Secrets below foo/bar are encrypted for the recipients from "+plain.IDFile)
+	assert.Equal(t, map[string][]string{"": genRecs}, s.RecipientsTree(ctx))
+}
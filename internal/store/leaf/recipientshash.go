@@ -0,0 +1,227 @@
+package leaf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// recipientsState is the last acknowledged set of recipients of a store. It
+// is kept in the config dir, outside of the store, so that a pull can't
+// change it.
+type recipientsState struct {
+	Hash       string              `json:"hash"`
+	Recipients map[string][]string `json:"recipients"`
+}
+
+// recipientChange is a recipient added to or removed from a scope
+type recipientChange struct {
+	Scope     string
+	Recipient string
+}
+
+// CheckRecipientsHash makes sure that the recipients of this store are the
+// ones the user acknowledged last. On first use the current recipients are
+// trusted. If they have been changed outside of gopass, e.g. by a pull, the
+// changes are shown and have to be confirmed interactively. Otherwise no
+// secrets must be encrypted for the new recipients.
+func (s *Store) CheckRecipientsHash(ctx context.Context) error {
+	if !ctxutil.IsCheckRecipientHash(ctx) || isRecipientsChecked(ctx) {
+		return nil
+	}
+
+	cur := s.RecipientsTree(ctx)
+	st, err := s.loadRecipientsState()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read acknowledged recipients: %w", err)
+		}
+		debug.Log("no acknowledged recipients for %q. trusting them on first use", s.alias)
+		return s.saveRecipientsState(cur)
+	}
+	if st.Hash == hashRecipients(cur) {
+		return nil
+	}
+
+	s.printRecipientChanges(ctx, st.Recipients, cur)
+	if ctxutil.IsConfirm(ctx) {
+		out.Warningf(ctx, "Trusting the new recipients of %s without asking", s.name())
+		return s.saveRecipientsState(cur)
+	}
+	if !ctxutil.IsInteractive(ctx) || ctxutil.IsAlwaysYes(ctx) {
+		return store.ErrRecipientsChanged
+	}
+	if !termio.AskForConfirmation(ctx, "Do you trust the new recipients?") {
+		return store.ErrRecipientsChanged
+	}
+	return s.saveRecipientsState(cur)
+}
+
+// AckRecipients accepts the current recipients of this store. Any changes
+// since they were last acknowledged are shown first.
+func (s *Store) AckRecipients(ctx context.Context) error {
+	cur := s.RecipientsTree(ctx)
+	st, err := s.loadRecipientsState()
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read acknowledged recipients: %w", err)
+	}
+	if err == nil && st.Hash == hashRecipients(cur) {
+		out.Printf(ctx, "The recipients of %s are unchanged", s.name())
+		return nil
+	}
+	if err == nil {
+		s.printRecipientChanges(ctx, st.Recipients, cur)
+		if !termio.Confirm(ctx, "Do you trust the new recipients?") {
+			return store.ErrRecipientsChanged
+		}
+	}
+
+	if err := s.saveRecipientsState(cur); err != nil {
+		return err
+	}
+	out.OKf(ctx, "Acknowledged the recipients of %s", s.name())
+	return nil
+}
+
+// updateRecipientsState records the recipients after they have been changed
+// by the user
+func (s *Store) updateRecipientsState(ctx context.Context) {
+	if !ctxutil.IsCheckRecipientHash(ctx) || ctxutil.IsDryRun(ctx) {
+		return
+	}
+	if err := s.saveRecipientsState(s.RecipientsTree(ctx)); err != nil {
+		out.Errorf(ctx, "Failed to save the acknowledged recipients: %s", err)
+	}
+}
+
+func (s *Store) printRecipientChanges(ctx context.Context, before, after map[string][]string) {
+	added, removed := diffRecipients(before, after)
+	out.Warningf(ctx, "The recipients of %s have been changed since they were last acknowledged:", s.name())
+	for _, c := range added {
+		out.Printf(ctx, "  + %s%s", scopePrefix(c.Scope), s.describeRecipient(ctx, c.Recipient))
+	}
+	for _, c := range removed {
+		out.Printf(ctx, "  - %s%s", scopePrefix(c.Scope), s.describeRecipient(ctx, c.Recipient))
+	}
+}
+
+// describeRecipient returns the fingerprint and the first identity of a
+// recipient
+func (s *Store) describeRecipient(ctx context.Context, id string) string {
+	kl, err := s.crypto.FindRecipients(ctx, id)
+	if err != nil || len(kl) < 1 {
+		return id + " (missing public key)"
+	}
+	return s.crypto.FormatKey(ctx, kl[0], "")
+}
+
+func (s *Store) name() string {
+	if s.alias == "" {
+		return "the root store"
+	}
+	return s.alias
+}
+
+func (s *Store) recipientsStateFile() string {
+	name := s.alias
+	if name == "" {
+		name = "root"
+	}
+	return filepath.Join(appdir.UserConfig(), "recipients", url.PathEscape(name)+".json")
+}
+
+func (s *Store) loadRecipientsState() (recipientsState, error) {
+	st := recipientsState{}
+	buf, err := os.ReadFile(s.recipientsStateFile())
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(buf, &st); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+func (s *Store) saveRecipientsState(rs map[string][]string) error {
+	buf, err := json.MarshalIndent(recipientsState{
+		Hash:       hashRecipients(rs),
+		Recipients: rs,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fn := s.recipientsStateFile()
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	debug.Log("saving acknowledged recipients of %q to %s", s.alias, fn)
+	return os.WriteFile(fn, buf, 0600)
+}
+
+// hashRecipients returns a hash over the recipients of all scopes
+func hashRecipients(rs map[string][]string) string {
+	scopes := make([]string, 0, len(rs))
+	for scope := range rs {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	h := sha256.New()
+	for _, scope := range scopes {
+		recps := append([]string{}, rs[scope]...)
+		sort.Strings(recps)
+		for _, r := range recps {
+			fmt.Fprintf(h, "%s\x00%s\n", scope, r)
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// diffRecipients returns the recipients added and removed in each scope
+func diffRecipients(before, after map[string][]string) ([]recipientChange, []recipientChange) {
+	return missingRecipients(after, before), missingRecipients(before, after)
+}
+
+// missingRecipients returns all recipients in a that are not in the same
+// scope of b
+func missingRecipients(a, b map[string][]string) []recipientChange {
+	var missing []recipientChange
+	for scope, recps := range a {
+		have := make(map[string]bool, len(b[scope]))
+		for _, r := range b[scope] {
+			have[r] = true
+		}
+		for _, r := range recps {
+			if !have[r] {
+				missing = append(missing, recipientChange{Scope: scope, Recipient: r})
+			}
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Scope != missing[j].Scope {
+			return missing[i].Scope < missing[j].Scope
+		}
+		return missing[i].Recipient < missing[j].Recipient
+	})
+	return missing
+}
+
+func scopePrefix(scope string) string {
+	if scope == "" {
+		return ""
+	}
+	return scope + "/: "
+}
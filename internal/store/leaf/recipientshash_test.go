@@ -0,0 +1,98 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plain "github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRecipientsHash(t *testing.T) {
+	tempdir := t.TempDir()
+	t.Setenv("GOPASS_HOMEDIR", t.TempDir())
+
+	ctx := context.Background()
+	ctx = ctxutil.WithCheckRecipientHash(ctx, true)
+	ctx = ctxutil.WithInteractive(ctx, false)
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+
+	// trust on first use
+	require.NoError(t, s.CheckRecipientsHash(ctx))
+	assert.FileExists(t, s.recipientsStateFile())
+	require.NoError(t, s.Set(ctx, "foo", sec))
+
+	// a recipient added by a pull
+	require.NoError(t, os.MkdirAll(filepath.Join(tempdir, "work"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(tempdir, "work", plain.IDFile), []byte("0xDEADBEEF\n0xBADC0FFEE\n"), 0600))
+	err = s.Set(ctx, "work/foo", sec)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, store.ErrRecipientsChanged))
+	assert.Contains(t, obuf.String(), "The recipients of the root store have been changed")
+	assert.Contains(t, obuf.String(), "+ work/: 0xBADC0FFEE (missing public key)")
+	assert.Contains(t, obuf.String(), "+ work/: 0xDEADBEEF")
+	assert.False(t, s.Exists(ctx, "work/foo"))
+
+	// --yes does not accept it implicitly
+	assert.Error(t, s.Set(ctxutil.WithAlwaysYes(ctx, true), "work/foo", sec))
+	assert.NoError(t, s.Set(ctxutil.WithCheckRecipientHash(ctx, false), "work/foo", sec))
+
+	obuf.Reset()
+	require.NoError(t, s.AckRecipients(ctxutil.WithAlwaysYes(ctx, true)))
+	assert.Contains(t, obuf.String(), "Acknowledged the recipients of the root store")
+	require.NoError(t, s.Set(ctx, "work/foo", sec))
+
+	// changes made with gopass are acknowledged implicitly
+	require.NoError(t, s.RemoveRecipientAt(ctx, "work", "0xBADC0FFEE"))
+	require.NoError(t, s.Set(ctx, "work/foo", sec))
+
+	obuf.Reset()
+	require.NoError(t, s.AckRecipients(ctx))
+	assert.Contains(t, obuf.String(), "The recipients of the root store are unchanged")
+}
+
+func TestDiffRecipients(t *testing.T) {
+	added, removed := diffRecipients(map[string][]string{
+		"":    {"a", "b"},
+		"foo": {"a"},
+	}, map[string][]string{
+		"":    {"b", "c"},
+		"bar": {"d"},
+	})
+	assert.Equal(t, []recipientChange{{Scope: "", Recipient: "c"}, {Scope: "bar", Recipient: "d"}}, added)
+	assert.Equal(t, []recipientChange{{Scope: "", Recipient: "a"}, {Scope: "foo", Recipient: "a"}}, removed)
+
+	assert.Equal(t, hashRecipients(map[string][]string{"": {"a", "b"}}), hashRecipients(map[string][]string{"": {"b", "a"}}))
+	assert.NotEqual(t, hashRecipients(map[string][]string{"": {"a", "b"}}), hashRecipients(map[string][]string{"foo": {"a", "b"}}))
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -16,16 +16,88 @@ import (
 	"github.com/gopasspw/gopass/pkg/termio"
 )
 
+// maxWorkers is the default upper bound for the number of concurrent
+// re-encryption workers
+const maxWorkers = 8
+
 // reencrypt will re-encrypt all entries for the current recipients
 func (s *Store) reencrypt(ctx context.Context) error {
-	entries, err := s.List(ctx, "")
+	entries, err := s.listAll(ctx, "")
 	if err != nil {
 		return fmt.Errorf("failed to list store: %w", err)
 	}
+	entries = append(entries, s.namedTemplateEntries(ctx)...)
 
-	// Most gnupg setups don't work well with concurrency > 1, but
-	// for other backends - e.g. age - this could very well be > 1.
-	conc := s.crypto.Concurrency()
+	return s.reencryptEntries(ctx, entries)
+}
+
+// reencryptScope will re-encrypt all entries using the recipients from the
+// given id file. Entries below a nested id file are left alone.
+func (s *Store) reencryptScope(ctx context.Context, idf string) error {
+	entries, err := s.scopeEntries(ctx, idf)
+	if err != nil {
+		return err
+	}
+
+	return s.reencryptEntries(ctx, entries)
+}
+
+// scopeEntries returns all entries using the recipients from the given id
+// file
+func (s *Store) scopeEntries(ctx context.Context, idf string) ([]string, error) {
+	prefix := filepath.ToSlash(filepath.Dir(idf))
+	if prefix == "." {
+		prefix = ""
+	}
+
+	entries, err := s.listAll(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list store: %w", err)
+	}
+	if prefix == "" {
+		entries = append(entries, s.namedTemplateEntries(ctx)...)
+	}
+
+	want := idf
+	if ctxutil.IsDryRun(ctx) && !s.storage.Exists(ctx, idf) {
+		// a new scope is not created in dry-run mode, so its secrets still
+		// use the id file of the parent scope
+		want = s.idFile(ctx, prefix)
+	}
+
+	scoped := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e
+		if s.alias != "" {
+			name = strings.TrimPrefix(e, s.alias+Sep)
+		}
+		if s.idFile(ctx, name) != want {
+			continue
+		}
+		scoped = append(scoped, e)
+	}
+	return scoped, nil
+}
+
+// reencryptEntries will re-encrypt the given entries for their current
+// recipients
+func (s *Store) reencryptEntries(ctx context.Context, entries []string) error {
+	if err := s.CheckRecipientsHash(ctx); err != nil {
+		return err
+	}
+	ctx = withRecipientsChecked(ctx, true)
+
+	if ctxutil.IsDryRun(ctx) {
+		// nothing is decrypted in dry-run mode
+		dryRunScope(ctx, entries)
+		if err := s.reencryptGitCommit(ctx); err != nil {
+			return err
+		}
+		return s.reencryptGitPush(ctx)
+	}
+
+	conc := s.workers(ctx)
+	failed := make(map[string]error)
 
 	// save original value of auto push
 	{
@@ -37,30 +109,41 @@ func (s *Store) reencrypt(ctx context.Context) error {
 		bar.Hidden = !ctxutil.IsTerminal(ctx) || ctxutil.IsHidden(ctx)
 
 		var wg sync.WaitGroup
-		jobs := make(chan string)
-		// We use a logger to write without race condition on stdout
-		logger := log.New(os.Stdout, "", 0)
-		out.Printf(ctx, "Starting reencrypt")
+		var mu sync.Mutex
+		reencryptEntry := func(e string) {
+			e = strings.TrimPrefix(e, s.alias)
+			if err := s.reencryptEntry(WithNoGitOps(ctx, conc > 1), e); err != nil {
+				debug.Log("Failed to re-encrypt %s: %s", e, err)
+				mu.Lock()
+				failed[e] = err
+				mu.Unlock()
+				bar.Fail()
+				return
+			}
+			bar.Inc()
+		}
 
+		out.Printf(ctx, "Starting reencrypt with %d workers", conc)
+
+		// the first decryption might trigger a pinentry, so we do it before
+		// we start any workers. After that the crypto backend (e.g. the
+		// gpg-agent) will usually have cached the passphrase.
+		if len(entries) > 0 {
+			reencryptEntry(entries[0])
+		}
+
+		jobs := make(chan string)
 		for i := 0; i < conc; i++ {
 			wg.Add(1) // we start a new job
-			go func(workerId int) {
+			go func() {
 				// the workers are fed through an unbuffered channel
 				for e := range jobs {
-					content, err := s.Get(ctx, e)
-					if err != nil {
-						logger.Printf("Worker %d: Failed to get current value for %s: %s\n", workerId, e, err)
-						continue
-					}
-					if err := s.Set(WithNoGitOps(ctx, conc > 1), e, content); err != nil {
-						logger.Printf("Worker %d: Failed to write %s: %s\n", workerId, e, err)
-						continue
-					}
+					reencryptEntry(e)
 				}
 				wg.Done() // report the job as finished
-			}(i)
+			}()
 		}
-		for _, e := range entries {
+		for i := 1; i < len(entries); i++ {
 			// check for context cancelation
 			select {
 			case <-ctx.Done():
@@ -72,12 +155,7 @@ func (s *Store) reencrypt(ctx context.Context) error {
 			default:
 			}
 
-			if bar != nil {
-				bar.Inc()
-			}
-
-			e = strings.TrimPrefix(e, s.alias)
-			jobs <- e
+			jobs <- entries[i]
 		}
 		// We close the channel, so the workers will terminate
 		close(jobs)
@@ -90,8 +168,8 @@ func (s *Store) reencrypt(ctx context.Context) error {
 	// to avoid a race condition on git .index.lock file, so we do it now.
 	if conc > 1 {
 		for _, name := range entries {
-			p := s.passfile(name)
-			if err := s.storage.Add(ctx, p); err != nil {
+			p := s.storedPassfile(ctx, strings.TrimPrefix(name, s.alias))
+			if err := s.Writer(ctx).Add(ctx, p); err != nil {
 				if errors.Is(err, store.ErrGitNotInit) {
 					debug.Log("skipping git add - git not initialized")
 					continue
@@ -102,7 +180,60 @@ func (s *Store) reencrypt(ctx context.Context) error {
 		}
 	}
 
-	if err := s.storage.Commit(ctx, ctxutil.GetCommitMessage(ctx)); err != nil {
+	if err := s.reencryptGitCommit(ctx); err != nil {
+		return err
+	}
+
+	if err := s.reencryptGitPush(ctx); err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		names := make([]string, 0, len(failed))
+		for name := range failed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			out.Errorf(ctx, "Failed to re-encrypt %s: %s", name, failed[name])
+		}
+		return fmt.Errorf("failed to re-encrypt %d of %d entries", len(failed), len(entries))
+	}
+	return nil
+}
+
+// reencryptEntry decrypts a single entry and encrypts it for the current
+// recipients
+func (s *Store) reencryptEntry(ctx context.Context, name string) error {
+	content, err := s.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get current value: %w", err)
+	}
+	// the hooks are only run for changes of the user
+	if err := s.Set(ctxutil.WithHooks(ctx, false), name, content); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// workers returns the number of concurrent re-encryption workers. It defaults
+// to the concurrency supported by the crypto backend, but at most maxWorkers.
+func (s *Store) workers(ctx context.Context) int {
+	if n := ctxutil.GetWorkers(ctx); n > 0 {
+		return n
+	}
+	conc := s.crypto.Concurrency()
+	if conc > maxWorkers {
+		conc = maxWorkers
+	}
+	if conc < 1 {
+		conc = 1
+	}
+	return conc
+}
+
+func (s *Store) reencryptGitCommit(ctx context.Context) error {
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpReencrypt, "", "", ctxutil.GetCommitMessage(ctx))); err != nil {
 		switch {
 		case errors.Is(err, store.ErrGitNotInit):
 			debug.Log("skipping git commit - git not initialized")
@@ -112,12 +243,11 @@ func (s *Store) reencrypt(ctx context.Context) error {
 			return fmt.Errorf("failed to commit changes to git: %w", err)
 		}
 	}
-
-	return s.reencryptGitPush(ctx)
+	return nil
 }
 
 func (s *Store) reencryptGitPush(ctx context.Context) error {
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			msg := "Warning: git is not initialized for this.storage. Ignoring auto-push option\n" +
 				"Run: gopass git init"
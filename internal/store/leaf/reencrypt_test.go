@@ -0,0 +1,178 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/ignore"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowMocker simulates a crypto backend with a fixed latency, e.g. a
+// gpg process, and fails to decrypt any ciphertext containing "broken"
+type slowMocker struct {
+	*plain.Mocker
+	delay time.Duration
+
+	mu        sync.Mutex
+	active    int
+	maxActive int
+	encrypted int
+}
+
+func (m *slowMocker) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	m.mu.Lock()
+	m.active++
+	if m.active > m.maxActive {
+		m.maxActive = m.active
+	}
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.active--
+		m.mu.Unlock()
+	}()
+
+	time.Sleep(m.delay)
+	if bytes.Contains(ciphertext, []byte("broken")) {
+		return nil, fmt.Errorf("decryption failed")
+	}
+	return m.Mocker.Decrypt(ctx, ciphertext)
+}
+
+func (m *slowMocker) Encrypt(ctx context.Context, plaintext []byte, recipients []string) ([]byte, error) {
+	m.mu.Lock()
+	m.encrypted++
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+	return m.Mocker.Encrypt(ctx, plaintext, recipients)
+}
+
+func createReencryptStore(t testing.TB, n int, sm *slowMocker) *Store {
+	t.Helper()
+
+	tempdir := t.TempDir()
+	entries := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		entries = append(entries, fmt.Sprintf("entry/%04d", i))
+	}
+	_, _, err := createStore(tempdir, nil, entries)
+	require.NoError(t, err)
+
+	return &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  sm,
+		storage: fs.New(tempdir),
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithTerminal(ctx, false)
+	ctx = ctxutil.WithWorkers(ctx, 4)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	sm := &slowMocker{Mocker: plain.New(), delay: time.Millisecond}
+	s := createReencryptStore(t, 20, sm)
+	require.NoError(t, os.WriteFile(filepath.Join(s.path, "entry", "0010."+plain.Ext), []byte("broken"), 0644))
+
+	err := s.reencrypt(ctx)
+	require.Error(t, err)
+	assert.Equal(t, "failed to re-encrypt 1 of 20 entries", err.Error())
+	assert.Contains(t, obuf.String(), "Failed to re-encrypt entry/0010")
+	assert.LessOrEqual(t, sm.maxActive, 4)
+
+	// all other entries were re-encrypted
+	for _, name := range []string{"entry/0000", "entry/0019"} {
+		_, err := s.Get(ctx, name)
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestReencryptIgnored(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	out.Stderr = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+		out.Stderr = os.Stderr
+	}()
+
+	sm := &slowMocker{Mocker: plain.New()}
+	s := createReencryptStore(t, 4, sm)
+	require.NoError(t, s.storage.Set(ctx, ignore.File, []byte("entry/0002.*\n")))
+
+	lst, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"entry/0000", "entry/0001", "entry/0003"}, lst)
+
+	// the ignored secret must be encrypted for the new recipients as well
+	require.NoError(t, s.reencrypt(ctx))
+	assert.Equal(t, 4, sm.encrypted)
+
+	sm.encrypted = 0
+	require.NoError(t, s.reencryptScope(ctx, s.idFile(ctx, "")))
+	assert.Equal(t, 4, sm.encrypted)
+}
+
+func TestWorkers(t *testing.T) {
+	ctx := context.Background()
+
+	s := &Store{crypto: plain.New()}
+	want := runtime.NumCPU()
+	if want > maxWorkers {
+		want = maxWorkers
+	}
+	assert.Equal(t, want, s.workers(ctx))
+	assert.Equal(t, 16, s.workers(ctxutil.WithWorkers(ctx, 16)))
+}
+
+func BenchmarkReencrypt(b *testing.B) {
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	defer func() {
+		out.Stdout = os.Stdout
+	}()
+
+	for _, workers := range []int{1, 8} {
+		workers := workers
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			ctx := context.Background()
+			ctx = ctxutil.WithHidden(ctx, true)
+			ctx = ctxutil.WithWorkers(ctx, workers)
+
+			s := createReencryptStore(b, 500, &slowMocker{Mocker: plain.New(), delay: time.Millisecond})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.reencrypt(ctx); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// emptyCommitter is implemented by storage backends that can record an event
+// in the history without changing any file
+type emptyCommitter interface {
+	CommitEmpty(ctx context.Context, msg string) error
+}
+
+// RecordShare records that a secret was shared with someone who is not a
+// recipient of the store in an empty commit. The secret itself is not
+// changed. It returns store.ErrGitNotInit if the store has no history.
+func (s *Store) RecordShare(ctx context.Context, name, recipient string, expires time.Time) error {
+	ec, ok := s.Writer(ctx).(emptyCommitter)
+	if !ok {
+		debug.Log("recording shares not supported by %T", s.storage)
+		return store.ErrGitNotInit
+	}
+
+	msg := s.commitMessage(ctx, OpShare, s.withAlias(name), "", fmt.Sprintf("Shared %s with %s", name, recipient), [][2]string{
+		{TrailerRecipient, recipient},
+		{TrailerExpires, expires.UTC().Format(time.RFC3339)},
+	})
+	return ec.CommitEmpty(ctx, msg)
+}
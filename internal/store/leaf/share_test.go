@@ -0,0 +1,54 @@
+package leaf
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordShare(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithUsername(ctx, "foo")
+	ctx = ctxutil.WithEmail(ctx, "foo@baz.com")
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+
+	s, err := createSubStore(tempdir)
+	require.NoError(t, err)
+
+	exp := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, s.RecordShare(ctx, "web/db", "0xFEEDBEEF", exp), store.ErrGitNotInit)
+
+	require.NoError(t, s.GitInit(backend.WithStorageBackend(ctx, backend.GitFS)))
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, s.Set(ctx, "web/db", sec))
+	require.NoError(t, s.RecordShare(ctx, "web/db", "0xFEEDBEEF", exp))
+
+	cmd := exec.Command("git", "log", "-1", "--format=%B", "--stat")
+	cmd.Dir = filepath.Join(tempdir, "sub")
+	buf, err := cmd.Output()
+	require.NoError(t, err)
+	msg := string(buf)
+	assert.Contains(t, msg, "Shared web/db with 0xFEEDBEEF")
+	assert.Contains(t, msg, TrailerOp+": "+OpShare)
+	assert.Contains(t, msg, TrailerRecipient+": 0xFEEDBEEF")
+	assert.Contains(t, msg, TrailerExpires+": 2026-10-15T12:00:00Z")
+	// the secret is not changed
+	assert.NotContains(t, msg, "web/db.")
+}
@@ -0,0 +1,83 @@
+package leaf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// signingKeyer is implemented by crypto backends that can sign commits
+type signingKeyer interface {
+	SigningKeys(ctx context.Context, ids ...string) ([]string, error)
+}
+
+// commitSigner is implemented by storage backends that can sign commits
+type commitSigner interface {
+	SignCommits(key string)
+}
+
+// commitVerifier is implemented by storage backends that can verify the
+// signatures of their commits
+type commitVerifier interface {
+	VerifyCommits(ctx context.Context) ([]backend.SignatureProblem, error)
+}
+
+// initCommitSigning makes the storage sign all commits with our own
+// recipient key. If there is no such key committing fails later on.
+func (s *Store) initCommitSigning(ctx context.Context) {
+	cs, ok := s.storage.(commitSigner)
+	if !ok {
+		debug.Log("commit signing not supported by %T", s.storage)
+		return
+	}
+
+	key, err := s.signingKey(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Can not sign commits to %s: %s", s.path, err)
+	}
+	debug.Log("signing commits to %s with %q", s.path, key)
+	cs.SignCommits(key)
+}
+
+// signingKey returns the first private key that is a recipient of this store
+// and can be used for signing
+func (s *Store) signingKey(ctx context.Context) (string, error) {
+	sk, ok := s.crypto.(signingKeyer)
+	if !ok {
+		return "", fmt.Errorf("the %s crypto backend can not sign commits", s.crypto.Name())
+	}
+
+	for _, r := range s.Recipients(ctx) {
+		kl, err := sk.SigningKeys(ctx, r)
+		if err != nil || len(kl) < 1 {
+			continue
+		}
+		return kl[0], nil
+	}
+	return "", fmt.Errorf("none of the recipients is a private key that can sign")
+}
+
+// fsckVerifyCommits reports all commits without a valid signature
+func (s *Store) fsckVerifyCommits(ctx context.Context) error {
+	cv, ok := s.storage.(commitVerifier)
+	if !ok {
+		out.Printf(ctx, "Storage backend %s does not support commit signatures", s.storage.Name())
+		return nil
+	}
+
+	out.Printf(ctx, "Verifying commit signatures")
+	probs, err := cv.VerifyCommits(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify commits: %w", err)
+	}
+	for _, p := range probs {
+		out.Errorf(ctx, "Commit %s (%s, %s <%s>): %s", p.Hash, p.Subject, p.AuthorName, p.AuthorEmail, p.Problem)
+	}
+	if len(probs) > 0 {
+		return fmt.Errorf("found %d commits without a valid signature", len(probs))
+	}
+	return nil
+}
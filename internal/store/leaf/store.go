@@ -8,23 +8,35 @@ import (
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/pkg/debug"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // Store is password store
 type Store struct {
-	alias   string
-	path    string
-	crypto  backend.Crypto
-	storage backend.Storage
+	alias    string
+	path     string
+	readOnly bool
+	crypto   backend.Crypto
+	storage  backend.Storage
+	// plain caches decrypted secrets, it may be nil
+	plain *cache.LRU
+	// commitTemplate and hashNames shape the commit messages
+	commitTemplate string
+	hashNames      bool
 }
 
 // Init initializes this sub store
 func Init(ctx context.Context, alias, path string) (*Store, error) {
 	debug.Log("Initializing %s at %s", alias, path)
 	s := &Store{
-		alias: alias,
-		path:  path,
+		alias:          alias,
+		path:           path,
+		plain:          GetDecryptCache(ctx),
+		commitTemplate: GetCommitTemplate(ctx),
+		hashNames:      IsCommitHashNames(ctx),
 	}
 
 	st, err := backend.InitStorage(ctx, backend.GetStorageBackend(ctx), path)
@@ -49,8 +61,12 @@ func New(ctx context.Context, alias, path string) (*Store, error) {
 	debug.Log("Instantiating %s at %s", alias, path)
 
 	s := &Store{
-		alias: alias,
-		path:  path,
+		alias:          alias,
+		path:           path,
+		readOnly:       IsReadOnly(ctx),
+		plain:          GetDecryptCache(ctx),
+		commitTemplate: GetCommitTemplate(ctx),
+		hashNames:      IsCommitHashNames(ctx),
 	}
 
 	// init storage and rcs backend
@@ -65,7 +81,13 @@ func New(ctx context.Context, alias, path string) (*Store, error) {
 	}
 	debug.Log("Crypto initialized")
 
-	debug.Log("Instantiated %s at %s - storage: %+#v - crypto: %+#v", alias, path, s.storage, s.crypto)
+	if IsSignCommits(ctx) {
+		s.initCommitSigning(ctx)
+	}
+	s.initSync(ctx)
+
+	// not the crypto backend, it may hold a cached passphrase
+	debug.Log("Instantiated %s at %s - storage: %s", alias, path, s.storage)
 	return s, nil
 }
 
@@ -104,24 +126,36 @@ func (s *Store) idFiles(ctx context.Context) []string {
 	if err != nil {
 		return nil
 	}
-	fileSet := make(map[string]struct{}, len(files))
+	out := make([]string, 0, 1)
 	for _, file := range files {
-		if strings.HasPrefix(filepath.Base(file), ".") {
+		if filepath.Base(file) != s.crypto.IDFile() {
 			continue
 		}
-		idf := s.idFile(ctx, file)
-		if s.storage.Exists(ctx, idf) {
-			fileSet[idf] = struct{}{}
-		}
-	}
-	out := make([]string, 0, len(fileSet))
-	for file := range fileSet {
 		out = append(out, file)
 	}
 	sort.Strings(out)
 	return out
 }
 
+// scopeFile returns the path to the id file of the scope starting at the
+// given directory. It doesn't have to exist.
+func (s *Store) scopeFile(dir string) string {
+	if s.crypto == nil {
+		return ""
+	}
+	return filepath.Join(cleanScope(dir), s.crypto.IDFile())
+}
+
+// cleanScope normalizes the directory of a recipients scope. The root of the
+// store is the empty string.
+func cleanScope(dir string) string {
+	dir = strings.Trim(filepath.ToSlash(filepath.Clean(dir)), Sep)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
 // Equals returns true if this.storage has the same on-disk path as the other
 func (s *Store) Equals(other *Store) bool {
 	if other == nil {
@@ -132,12 +166,16 @@ func (s *Store) Equals(other *Store) bool {
 
 // IsDir returns true if the entry is folder inside the store
 func (s *Store) IsDir(ctx context.Context, name string) bool {
-	return s.storage.IsDir(ctx, name)
+	if s.storage.IsDir(ctx, name) {
+		return true
+	}
+	nfd := norm.NFD.String(name)
+	return nfd != name && s.storage.IsDir(ctx, nfd)
 }
 
 // Exists checks the existence of a single entry
 func (s *Store) Exists(ctx context.Context, name string) bool {
-	return s.storage.Exists(ctx, s.passfile(name))
+	return s.storage.Exists(ctx, s.storedPassfile(ctx, name))
 }
 
 func (s *Store) useableKeys(ctx context.Context, name string) ([]string, error) {
@@ -163,6 +201,19 @@ func (s *Store) passfile(name string) string {
 	return strings.TrimPrefix(name+"."+s.crypto.Ext(), "/")
 }
 
+// storedPassfile returns the passfile a secret is actually stored in. Names
+// are normalized to NFC but secrets created on macOS or by other tools may be
+// stored decomposed (NFD). Lookups must find them either way. If neither
+// exists the NFC passfile is returned.
+func (s *Store) storedPassfile(ctx context.Context, name string) string {
+	p := s.passfile(name)
+	nfd := norm.NFD.String(p)
+	if nfd == p || s.storage.Exists(ctx, p) || !s.storage.Exists(ctx, nfd) {
+		return p
+	}
+	return nfd
+}
+
 // String implement fmt.Stringer
 func (s *Store) String() string {
 	return fmt.Sprintf("Store(Alias: %s, Path: %s)", s.alias, s.path)
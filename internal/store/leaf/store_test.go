@@ -2,6 +2,7 @@ package leaf
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
@@ -9,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 
 	_ "github.com/gopasspw/gopass/internal/backend/crypto"
@@ -102,6 +105,68 @@ func TestStore(t *testing.T) {
 	}
 }
 
+func TestUnnormalizedNames(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	s := &Store{
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.saveRecipients(ctx, []string{"john.doe"}, "test"))
+
+	// created decomposed, e.g. on macOS
+	nfc := "web/caf\u00e9"
+	nfd := "web/cafe\u0301"
+	require.NoError(t, s.storage.Set(ctx, nfd+".txt", []byte("secret")))
+
+	assert.True(t, s.Exists(ctx, nfc))
+	assert.True(t, s.IsDir(ctx, "web"))
+	sec, err := s.Get(ctx, nfc)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", sec.Password())
+
+	lst, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{nfc}, lst)
+
+	// updates keep the stored name instead of adding a second secret
+	sec.SetPassword("updated")
+	require.NoError(t, s.Set(ctx, nfc, sec))
+	lst, err = s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{nfc}, lst)
+	sec, err = s.Get(ctx, nfd)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", sec.Password())
+
+	require.NoError(t, s.Delete(ctx, nfc))
+	assert.False(t, s.Exists(ctx, nfc))
+}
+
+func TestSetInvalidName(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	s := &Store{
+		path:    tempdir,
+		crypto:  plain.New(),
+		storage: fs.New(tempdir),
+	}
+	require.NoError(t, s.saveRecipients(ctx, []string{"john.doe"}, "test"))
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	for _, name := range []string{"../foo", "foo/../../bar", "foo\x1b[2J", "foo\nbar"} {
+		err := s.Set(ctx, name, sec)
+		assert.Error(t, err, name)
+		assert.True(t, errors.Is(err, store.ErrInvalidName), name)
+	}
+	require.NoError(t, s.Set(ctx, "foo", sec))
+	assert.True(t, errors.Is(s.MoveCiphertext(ctx, "foo", "../bar", false), store.ErrInvalidName))
+}
+
 func TestIdFile(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,134 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// storageStreamer is implemented by storage backends that can read and write
+// files without holding them in memory
+type storageStreamer interface {
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	Size(ctx context.Context, name string) (int64, error)
+	SetFrom(ctx context.Context, name string, r io.Reader) error
+}
+
+// SetFrom works like Set but encrypts the plaintext read from r, e.g. a large
+// attachment, and writes the ciphertext while it's encrypted. Neither is held
+// in memory. Since r can only be read once SetFrom can't retry after fetching
+// missing public keys.
+func (s *Store) SetFrom(ctx context.Context, name string, r io.Reader) error {
+	return s.write(ctx, name, func(ctx context.Context, p string, recipients []string) error {
+		st, ok := s.storage.(storageStreamer)
+		if !ok {
+			debug.Log("streaming not supported by %T", s.storage)
+			buf := &bytes.Buffer{}
+			if err := s.crypto.EncryptStream(ctx, r, recipients, buf); err != nil {
+				debug.Log("Failed encrypt secret: %s", err)
+				return store.ErrEncrypt
+			}
+			if err := s.storage.Set(ctx, p, buf.Bytes()); err != nil {
+				return fmt.Errorf("failed to write secret: %w", err)
+			}
+			return nil
+		}
+
+		pr, pw := io.Pipe()
+		encErr := make(chan error, 1)
+		go func() {
+			err := s.crypto.EncryptStream(ctx, r, recipients, pw)
+			_ = pw.CloseWithError(err)
+			encErr <- err
+		}()
+
+		err := st.SetFrom(ctx, p, pr)
+		// stops the encryption if the storage failed
+		_ = pr.CloseWithError(err)
+		if eerr := <-encErr; eerr != nil && (err == nil || errors.Is(err, eerr)) {
+			debug.Log("Failed encrypt secret: %s", eerr)
+			return store.ErrEncrypt
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write secret: %w", err)
+		}
+		return nil
+	})
+}
+
+// Open decrypts a secret and returns a reader for its plaintext, e.g. for a
+// large attachment. Unlike Get the plaintext is neither parsed nor cached.
+// If the decryption fails Read returns the error and the plaintext read until
+// then must be discarded. The reader must be closed.
+func (s *Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	p := s.storedPassfile(ctx, name)
+
+	var ciphertext io.ReadCloser
+	var err error
+	if st, ok := s.storage.(storageStreamer); ok {
+		ciphertext, err = st.Open(ctx, p)
+	} else {
+		var buf []byte
+		buf, err = s.storage.Get(ctx, p)
+		ciphertext = io.NopCloser(bytes.NewReader(buf))
+	}
+	if err != nil {
+		debug.Log("File %s not found: %s", p, err)
+		return nil, store.ErrNotFound
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			_ = ciphertext.Close()
+		}()
+
+		err := s.crypto.DecryptStream(ctx, ciphertext, pw)
+		var nsk *backend.NoSecretKeyError
+		switch {
+		case err == nil:
+		case errors.As(err, &nsk):
+			s.printNoSecretKey(ctx, name, nsk)
+			err = store.ErrDecrypt
+		default:
+			err = fmt.Errorf("%w: %s", store.ErrDecrypt, err)
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	return &plaintextReader{PipeReader: pr, done: done}, nil
+}
+
+// Size returns the size of the ciphertext of a secret in bytes. The
+// plaintext may be larger or smaller, e.g. if it was compressed.
+func (s *Store) Size(ctx context.Context, name string) (int64, error) {
+	p := s.storedPassfile(ctx, name)
+	if st, ok := s.storage.(storageStreamer); ok {
+		return st.Size(ctx, p)
+	}
+	buf, err := s.storage.Get(ctx, p)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(buf)), nil
+}
+
+// plaintextReader is the plaintext of a secret that is being decrypted
+type plaintextReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// Close stops the decryption and waits for it to finish
+func (p *plaintextReader) Close() error {
+	err := p.PipeReader.Close()
+	<-p.done
+	return err
+}
@@ -0,0 +1,131 @@
+package leaf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader fails after returning its content
+type failingReader struct {
+	r io.Reader
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("read failed")
+	}
+	return n, err
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+
+	s, err := createSubStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetFrom(ctx, "foo", strings.NewReader("secret\nuser: alice")))
+
+	sec, err := s.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", sec.Password())
+
+	size, err := s.Size(ctx, "foo")
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(0))
+
+	rc, err := s.Open(ctx, "foo")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "secret\nuser: alice", string(buf))
+
+	t.Run("close before the end", func(t *testing.T) {
+		require.NoError(t, s.SetFrom(ctx, "large", strings.NewReader(strings.Repeat("x", 1<<20))))
+		rc, err := s.Open(ctx, "large")
+		require.NoError(t, err)
+		_, err = rc.Read(make([]byte, 16))
+		require.NoError(t, err)
+		assert.NoError(t, rc.Close())
+	})
+
+	t.Run("failing reader keeps the old content", func(t *testing.T) {
+		err := s.SetFrom(ctx, "foo", &failingReader{r: strings.NewReader("new")})
+		assert.True(t, errors.Is(err, store.ErrEncrypt), err)
+		sec, err := s.Get(ctx, "foo")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", sec.Password())
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		ctx := ctxutil.WithDryRun(ctx, true)
+		require.NoError(t, s.SetFrom(ctx, "dry", &failingReader{r: strings.NewReader("new")}))
+		assert.False(t, s.Exists(ctx, "dry"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := s.Open(ctx, "missing")
+		assert.True(t, errors.Is(err, store.ErrNotFound), err)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		assert.Error(t, s.SetFrom(ctx, "../../escape", strings.NewReader("new")))
+	})
+}
+
+// zeroReader returns an endless stream of zeros
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// BenchmarkStreamAttachment writes and reads a 100 MB attachment. The memory
+// allocated must not grow with its size.
+func BenchmarkStreamAttachment(b *testing.B) {
+	const size = 100 << 20
+	ctx := context.Background()
+
+	s, err := createSubStore(b.TempDir())
+	require.NoError(b, err)
+
+	b.SetBytes(size)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		require.NoError(b, s.SetFrom(ctx, "large", secrets.NewAttachmentEncoder("large.bin", io.LimitReader(zeroReader{}, size))))
+
+		rc, err := s.Open(ctx, "large")
+		require.NoError(b, err)
+		ar, err := secrets.NewAttachmentReader(rc)
+		require.NoError(b, err)
+		n, err := io.Copy(io.Discard, ar)
+		require.NoError(b, err)
+		require.NoError(b, rc.Close())
+		assert.Equal(b, int64(size), n)
+
+		runtime.ReadMemStats(&after)
+		if alloc := after.TotalAlloc - before.TotalAlloc; alloc > size/10 {
+			b.Fatalf("allocated %d bytes to stream %d bytes", alloc, size)
+		}
+	}
+}
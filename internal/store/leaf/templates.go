@@ -97,13 +97,19 @@ func (s *Store) GetTemplate(ctx context.Context, name string) ([]byte, error) {
 
 // SetTemplate will (over)write the content to the template file
 func (s *Store) SetTemplate(ctx context.Context, name string, content []byte) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	p := s.templatefile(name)
 
-	if err := s.storage.Set(ctx, p, content); err != nil {
+	if err := s.Writer(ctx).Set(ctx, p, content); err != nil {
 		return fmt.Errorf("failed to write template: %w", err)
 	}
 
-	if err := s.storage.Add(ctx, p); err != nil {
+	if err := s.Writer(ctx).Add(ctx, p); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -114,18 +120,24 @@ func (s *Store) SetTemplate(ctx context.Context, name string, content []byte) er
 		return nil
 	}
 
-	return s.gitCommitAndPush(ctx, name)
+	return s.gitCommitAndPush(ctx, OpTemplate, name, "")
 }
 
 // RemoveTemplate will delete the named template if it exists
 func (s *Store) RemoveTemplate(ctx context.Context, name string) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	p := s.templatefile(name)
 
-	if err := s.storage.Delete(ctx, p); err != nil {
+	if err := s.Writer(ctx).Delete(ctx, p); err != nil {
 		return fmt.Errorf("failed to remote template: %w", err)
 	}
 
-	if err := s.storage.Add(ctx, p); err != nil {
+	if err := s.Writer(ctx).Add(ctx, p); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -136,5 +148,5 @@ func (s *Store) RemoveTemplate(ctx context.Context, name string) error {
 		return nil
 	}
 
-	return s.gitCommitAndPush(ctx, name)
+	return s.gitCommitAndPush(ctx, OpTemplate, name, "")
 }
@@ -3,6 +3,7 @@ package leaf
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/gopasspw/gopass/internal/backend"
@@ -57,3 +58,49 @@ func TestTemplates(t *testing.T) {
 
 	assert.Error(t, s.RemoveTemplate(ctx, "foo"))
 }
+
+func TestNamedTemplates(t *testing.T) {
+	ctx := context.Background()
+
+	tempdir := t.TempDir()
+	color.NoColor = true
+
+	_, _, err := createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+	ctx = backend.WithStorageBackendString(ctx, "fs")
+	s, err := New(ctx, "", tempdir)
+	require.NoError(t, err)
+
+	before, err := s.List(ctx, "")
+	require.NoError(t, err)
+
+	assert.Empty(t, s.ListNamedTemplates(ctx, ""))
+	assert.False(t, s.HasNamedTemplate(ctx, "db"))
+	_, err = s.GetNamedTemplate(ctx, "db")
+	assert.Error(t, err)
+
+	require.NoError(t, s.SetNamedTemplate(ctx, "db", []byte("{{ genpw 24 }}\nuser: {{ .Name }}\n")))
+	require.NoError(t, s.SetNamedTemplate(ctx, "web/nginx", []byte("{{ .Content }}")))
+	assert.Equal(t, []string{"db", "web/nginx"}, s.ListNamedTemplates(ctx, ""))
+	assert.Equal(t, []string{"work/db", "work/web/nginx"}, s.ListNamedTemplates(ctx, "work"))
+	assert.True(t, s.HasNamedTemplate(ctx, "db"))
+
+	b, err := s.GetNamedTemplate(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "{{ genpw 24 }}\nuser: {{ .Name }}\n", string(b))
+
+	// named templates aren't secrets
+	after, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+	assert.FileExists(t, filepath.Join(tempdir, ".gopass", "templates", "db."+s.crypto.Ext()))
+
+	for _, name := range []string{"", "../db", "//db", "db/../../x", ".hidden"} {
+		assert.Error(t, s.SetNamedTemplate(ctx, name, []byte("foo")), name)
+	}
+
+	require.NoError(t, s.RemoveNamedTemplate(ctx, "db"))
+	assert.Equal(t, []string{"web/nginx"}, s.ListNamedTemplates(ctx, ""))
+}
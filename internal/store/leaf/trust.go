@@ -0,0 +1,132 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/termio"
+)
+
+// ownertrustFile contains a snapshot of the ownertrust of all recipients
+const ownertrustFile = ".gpg-ownertrust"
+
+type ownertrustManager interface {
+	ExportOwnertrust(ctx context.Context, ids ...string) ([]byte, error)
+	ImportOwnertrust(ctx context.Context, r io.Reader) error
+	UntrustedKeys(ctx context.Context, ids ...string) (gpg.KeyList, error)
+}
+
+// SyncOwnertrust offers to import the ownertrust of any untrusted recipient
+// from the snapshot in the store and updates the snapshot with the local
+// ownertrust of all recipients afterwards. Trust is never modified without
+// asking the user first.
+func (s *Store) SyncOwnertrust(ctx context.Context) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tm, ok := s.crypto.(ownertrustManager)
+	if !ok {
+		debug.Log("ownertrust not supported by %T", s.crypto)
+		return nil
+	}
+
+	rs, err := s.GetRecipients(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to get recipients: %w", err)
+	}
+
+	snap := gpg.Ownertrust{}
+	if s.storage.Exists(ctx, ownertrustFile) {
+		buf, err := s.storage.Get(ctx, ownertrustFile)
+		if err != nil {
+			return fmt.Errorf("failed to read ownertrust snapshot: %w", err)
+		}
+		snap = gpg.ParseOwnertrust(buf)
+	}
+
+	if err := s.importOwnertrust(ctx, tm, rs, snap); err != nil {
+		out.Errorf(ctx, "[%s] Failed to import ownertrust: %s", s.alias, err)
+	}
+
+	return s.saveOwnertrust(ctx, tm, rs, snap)
+}
+
+func (s *Store) importOwnertrust(ctx context.Context, tm ownertrustManager, rs []string, snap gpg.Ownertrust) error {
+	if len(snap) < 1 || !ctxutil.IsInteractive(ctx) {
+		return nil
+	}
+
+	ul, err := tm.UntrustedKeys(ctx, rs...)
+	if err != nil {
+		return err
+	}
+
+	imp := make(gpg.Ownertrust, len(ul))
+	for _, k := range ul {
+		level, found := snap[k.Fingerprint]
+		if !found {
+			continue
+		}
+		// do not use AskForConfirmation, --yes must not change any trust
+		msg := fmt.Sprintf("[%s] %s has %s validity. Import ownertrust %q from the store?", s.alias, k.OneLine(), gpg.TrustName(k.Validity), snap.Level(k.Fingerprint))
+		if want, err := termio.AskForBool(ctx, msg, false); err != nil || !want {
+			continue
+		}
+		imp[k.Fingerprint] = level
+	}
+	if len(imp) < 1 {
+		return nil
+	}
+
+	if err := tm.ImportOwnertrust(ctx, bytes.NewReader(imp.Bytes())); err != nil {
+		return err
+	}
+	out.Printf(ctx, "[%s] Imported ownertrust for %d keys", s.alias, len(imp))
+	return nil
+}
+
+func (s *Store) saveOwnertrust(ctx context.Context, tm ownertrustManager, rs []string, snap gpg.Ownertrust) error {
+	buf, err := tm.ExportOwnertrust(ctx, rs...)
+	if err != nil {
+		return fmt.Errorf("failed to export ownertrust: %w", err)
+	}
+
+	merged := make(gpg.Ownertrust, len(snap))
+	for fp, level := range snap {
+		merged[fp] = level
+	}
+	for fp, level := range gpg.ParseOwnertrust(buf) {
+		merged[fp] = level
+	}
+	if bytes.Equal(merged.Bytes(), snap.Bytes()) {
+		debug.Log("[%s] ownertrust snapshot is up to date", s.alias)
+		return nil
+	}
+
+	if err := s.Writer(ctx).Set(ctx, ownertrustFile, merged.Bytes()); err != nil {
+		return fmt.Errorf("failed to write ownertrust snapshot: %w", err)
+	}
+	if err := s.Writer(ctx).Add(ctx, ownertrustFile); err != nil {
+		if errors.Is(err, store.ErrGitNotInit) {
+			return nil
+		}
+		return fmt.Errorf("failed to add %q to git: %w", ownertrustFile, err)
+	}
+	if err := s.Writer(ctx).Commit(ctx, s.CommitMessage(ctx, OpMaintenance, "", "", "Updated ownertrust snapshot")); err != nil {
+		if !errors.Is(err, store.ErrGitNotInit) && !errors.Is(err, store.ErrGitNothingToCommit) {
+			return fmt.Errorf("failed to commit changes to git: %w", err)
+		}
+	}
+	return nil
+}
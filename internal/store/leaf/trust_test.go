@@ -0,0 +1,93 @@
+package leaf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/backend/crypto/plain"
+	"github.com/gopasspw/gopass/internal/backend/storage/fs"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/termio"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	trustFPDead = "0123456789ABCDEF0123456789ABCDEFDEADBEEF"
+	trustFPFeed = "0123456789ABCDEF0123456789ABCDEFFEEDBEEF"
+)
+
+type trustMocker struct {
+	*plain.Mocker
+	imported string
+}
+
+func (m *trustMocker) ExportOwnertrust(ctx context.Context, ids ...string) ([]byte, error) {
+	return []byte(trustFPFeed + ":4:\n"), nil
+}
+
+func (m *trustMocker) ImportOwnertrust(ctx context.Context, r io.Reader) error {
+	buf, err := io.ReadAll(r)
+	m.imported = string(buf)
+	return err
+}
+
+func (m *trustMocker) UntrustedKeys(ctx context.Context, ids ...string) (gpg.KeyList, error) {
+	return gpg.KeyList{{Fingerprint: trustFPDead, Validity: "-"}}, nil
+}
+
+func TestSyncOwnertrust(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, true)
+
+	tempdir, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(tempdir)
+	}()
+	_, _, err = createStore(tempdir, nil, nil)
+	require.NoError(t, err)
+
+	obuf := &bytes.Buffer{}
+	out.Stdout = obuf
+	termio.Stdin = strings.NewReader("y\n")
+	defer func() {
+		out.Stdout = os.Stdout
+		termio.Stdin = os.Stdin
+	}()
+
+	tm := &trustMocker{Mocker: plain.New()}
+	s := &Store{
+		alias:   "",
+		path:    tempdir,
+		crypto:  tm,
+		storage: fs.New(tempdir),
+	}
+
+	// no snapshot, nothing to import
+	require.NoError(t, s.SyncOwnertrust(ctx))
+	assert.Equal(t, "", tm.imported)
+	buf, err := s.storage.Get(ctx, ownertrustFile)
+	require.NoError(t, err)
+	assert.Equal(t, trustFPFeed+":4:\n", string(buf))
+
+	// untrusted recipient with a snapshot entry
+	require.NoError(t, s.storage.Set(ctx, ownertrustFile, []byte(trustFPDead+":5:\n")))
+	require.NoError(t, s.SyncOwnertrust(ctx))
+	assert.Equal(t, trustFPDead+":5:\n", tm.imported)
+	buf, err = s.storage.Get(ctx, ownertrustFile)
+	require.NoError(t, err)
+	assert.Equal(t, trustFPDead+":5:\n"+trustFPFeed+":4:\n", string(buf))
+
+	// --yes must not change any trust
+	tm.imported = ""
+	require.NoError(t, s.SyncOwnertrust(ctxutil.WithAlwaysYes(ctx, true)))
+	assert.Equal(t, "", tm.imported)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/gopasspw/gopass/internal/hook"
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/queue"
 	"github.com/gopasspw/gopass/internal/store"
@@ -14,13 +15,64 @@ import (
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
-// Set encodes and writes the cipertext of one entry to disk
+// encryptFunc encrypts a secret for the recipients and writes the ciphertext
+// to the file p of the storage
+type encryptFunc func(ctx context.Context, p string, recipients []string) error
+
+// Set encodes and writes the cipertext of one entry to disk. The pre-write
+// hook can abort the write.
 func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
+	return s.write(ctx, name, func(ctx context.Context, p string, recipients []string) error {
+		ciphertext, err := s.crypto.Encrypt(ctx, sec.Bytes(), recipients)
+		if err != nil && s.fetchMissingPublicKeys(ctx, recipients) {
+			debug.Log("Failed encrypt secret: %s. Retrying with fetched public keys", err)
+			ciphertext, err = s.crypto.Encrypt(ctx, sec.Bytes(), recipients)
+		}
+		if err != nil {
+			debug.Log("Failed encrypt secret: %s", err)
+			return store.ErrEncrypt
+		}
+		if err := s.storage.Set(ctx, p, ciphertext); err != nil {
+			return fmt.Errorf("failed to write secret: %w", err)
+		}
+		return nil
+	})
+}
+
+// write checks the name and runs the hooks around set
+func (s *Store) write(ctx context.Context, name string, enc encryptFunc) error {
+	if err := s.CheckWritable(); err != nil {
+		return err
+	}
 	if strings.Contains(name, "//") {
 		return fmt.Errorf("invalid secret name: %s", name)
 	}
+	if err := store.ValidateName(name); err != nil {
+		return err
+	}
 
-	p := s.passfile(name)
+	if err := s.runHook(ctx, hook.PreWrite, name); err != nil {
+		return err
+	}
+	if err := s.set(ctx, name, enc); err != nil {
+		return err
+	}
+	s.runPostHook(ctx, hook.PostWrite, name)
+	return nil
+}
+
+func (s *Store) set(ctx context.Context, name string, enc encryptFunc) error {
+	unlock, err := s.lockStorage(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.CheckRecipientsHash(ctx); err != nil {
+		return err
+	}
+
+	p := s.storedPassfile(ctx, name)
 
 	recipients, err := s.useableKeys(ctx, name)
 	if err != nil {
@@ -30,15 +82,26 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 	// make sure the encryptor can decrypt later
 	recipients = s.ensureOurKeyID(ctx, recipients)
 
-	ciphertext, err := s.crypto.Encrypt(ctx, sec.Bytes(), recipients)
-	if err != nil {
-		debug.Log("Failed encrypt secret: %s", err)
-		return store.ErrEncrypt
+	op, from := getCommitOp(ctx)
+	if op == "" {
+		op = OpUpdate
+		if !s.storage.Exists(ctx, p) {
+			op = OpInsert
+		}
 	}
 
-	if err := s.storage.Set(ctx, p, ciphertext); err != nil {
-		return fmt.Errorf("failed to write secret: %w", err)
+	if ctxutil.IsDryRun(ctx) {
+		out.Printf(ctx, "Would encrypt %s for %s", name, strings.Join(recipients, ", "))
+		if err := s.Writer(ctx).Set(ctx, p, nil); err != nil {
+			return fmt.Errorf("failed to write secret: %w", err)
+		}
+	} else {
+		if err := enc(ctx, p, recipients); err != nil {
+			return err
+		}
+		debug.Info("wrote secret", "store", s.alias, "name", name, "recipients", len(recipients))
 	}
+	s.forgetPlaintext(name, false)
 
 	// It is not possible to perform concurrent git add and git commit commands
 	// so we need to skip this step when using concurrency and perform them
@@ -48,7 +111,7 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return nil
 	}
 
-	if err := s.storage.Add(ctx, p); err != nil {
+	if err := s.Writer(ctx).Add(ctx, p); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			return nil
 		}
@@ -59,16 +122,26 @@ func (s *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 		return nil
 	}
 
+	// the queue doesn't know about dry-run mode
+	if ctxutil.IsDryRun(ctx) {
+		return s.gitCommitAndPush(ctx, op, name, from)
+	}
+
 	// try to enqueue this task, if the queue is not available
 	// it will return the task and we will execute it inline
 	t := queue.GetQueue(ctx).Add(func(ctx context.Context) error {
-		return s.gitCommitAndPush(ctx, name)
+		return s.gitCommitAndPush(ctx, op, name, from)
 	})
 	return t(ctx)
 }
 
-func (s *Store) gitCommitAndPush(ctx context.Context, name string) error {
-	if err := s.storage.Commit(ctx, fmt.Sprintf("Save secret to %s: %s", name, ctxutil.GetCommitMessage(ctx))); err != nil {
+// gitCommitAndPush commits the change of a single secret. from is the full
+// name a moved or copied secret had before.
+func (s *Store) gitCommitAndPush(ctx context.Context, op, name, from string) error {
+	msg := s.commitMessage(ctx, op, s.withAlias(name), from, fmt.Sprintf("Save secret to %s: %s", name, ctxutil.GetCommitMessage(ctx)), [][2]string{
+		{TrailerWeak, GetWeakPassword(ctx)},
+	})
+	if err := s.Writer(ctx).Commit(ctx, msg); err != nil {
 		switch {
 		case errors.Is(err, store.ErrGitNotInit):
 			debug.Log("commitAndPush - skipping git commit - git not initialized")
@@ -80,7 +153,7 @@ func (s *Store) gitCommitAndPush(ctx context.Context, name string) error {
 	}
 
 	debug.Log("syncing with remote ...")
-	if err := s.storage.Push(ctx, "", ""); err != nil {
+	if err := s.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			msg := "Warning: git is not initialized for this.storage. Ignoring auto-push option\n" +
 				"Run: gopass git init"
@@ -188,11 +188,21 @@ func (m *InMem) Revisions(context.Context, string) ([]backend.Revision, error) {
 		}}, nil
 }
 
+// Meta is not implemented
+func (m *InMem) Meta(context.Context, string) (backend.Meta, error) {
+	return backend.Meta{}, nil
+}
+
 // GetRevision is not implemented
 func (m *InMem) GetRevision(context.Context, string, string) ([]byte, error) {
 	return []byte("foo\nbar"), nil
 }
 
+// Deleted is not implemented
+func (m *InMem) Deleted(context.Context) ([]backend.Deletion, error) {
+	return []backend.Deletion{}, nil
+}
+
 // Status is not implemented
 func (m *InMem) Status(context.Context) ([]byte, error) {
 	return []byte(""), nil
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CleanName returns the secret name with forward slashes in Unicode
+// normalization form C (NFC). On Windows the names typed or completed by the
+// shell, e.g. work\db, use backslashes which must not end up in the store as
+// part of the name. On macOS names may be decomposed (NFD), e.g. when they
+// are completed from file names, so the same name could be stored twice.
+func CleanName(name string) string {
+	return cleanName(name, os.PathSeparator)
+}
+
+// cleanName replaces the path separator sep in name with a slash, removes
+// duplicate slashes and normalizes it to NFC. A trailing slash is kept, it
+// marks a folder.
+func cleanName(name string, sep byte) string {
+	if sep != '/' {
+		name = strings.ReplaceAll(name, string(sep), "/")
+	}
+	for strings.Contains(name, "//") {
+		name = strings.ReplaceAll(name, "//", "/")
+	}
+	return NormalizeName(name)
+}
+
+// NormalizeName returns name in Unicode normalization form C
+func NormalizeName(name string) string {
+	return norm.NFC.String(name)
+}
+
+// ValidateName checks whether a new secret may be created with this name.
+// Control characters can't be typed or displayed and . or .. as a path
+// component would place the secret outside of its folder or the store.
+func ValidateName(name string) error {
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: %q contains the control character %U", ErrInvalidName, name, r)
+		}
+	}
+	for _, p := range strings.Split(name, "/") {
+		if p == "." || p == ".." {
+			return fmt.Errorf("%w: %q must not contain %q", ErrInvalidName, name, p)
+		}
+	}
+	return nil
+}
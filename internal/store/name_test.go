@@ -0,0 +1,69 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanName(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		sep  byte
+		want string
+	}{
+		{in: "work/db", sep: '/', want: "work/db"},
+		{in: "work//db", sep: '/', want: "work/db"},
+		{in: "work/", sep: '/', want: "work/"},
+		{in: `work\db`, sep: '/', want: `work\db`},
+		{in: `work\db`, sep: '\\', want: "work/db"},
+		{in: `work\team/db`, sep: '\\', want: "work/team/db"},
+		{in: `work\\db\`, sep: '\\', want: "work/db/"},
+		{in: "", sep: '\\', want: ""},
+	} {
+		assert.Equal(t, tc.want, cleanName(tc.in, tc.sep), tc.in)
+	}
+
+	assert.Equal(t, "work/db", CleanName("work/db"))
+}
+
+func TestCleanNameNormalizes(t *testing.T) {
+	nfd := "cafe\u0301"
+	nfc := "caf\u00e9"
+
+	assert.Equal(t, nfc, CleanName(nfd))
+	assert.Equal(t, nfc, CleanName(nfc))
+	assert.Equal(t, "work/"+nfc+"/db", cleanName("work\\"+nfd+"\\db", '\\'))
+	assert.Equal(t, nfc, NormalizeName(nfd))
+}
+
+func TestValidateName(t *testing.T) {
+	for _, tc := range []string{
+		"foo",
+		"work/db",
+		"café",
+		"..foo",
+		"foo../bar",
+		"foo/.bar",
+	} {
+		assert.NoError(t, ValidateName(tc), tc)
+	}
+
+	for _, tc := range []string{
+		"foo\nbar",
+		"foo\x00",
+		"foo\tbar",
+		"\x1b[31mfoo",
+		"foo\u0085",
+		"../foo",
+		"foo/../../bar",
+		"foo/..",
+		"./foo",
+		"..",
+	} {
+		err := ValidateName(tc)
+		assert.Error(t, err, tc)
+		assert.True(t, errors.Is(err, ErrInvalidName), tc)
+	}
+}
@@ -0,0 +1,23 @@
+package root
+
+import (
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// ExpandAlias replaces a leading path alias in name with its target. Aliases
+// are personal shortcuts defined in the config, they are never written into
+// a store. The name is normalized to use forward slashes first.
+func (r *Store) ExpandAlias(name string) string {
+	return r.cfg.ExpandAlias(store.CleanName(name))
+}
+
+// addAliases adds all path aliases to the top level of the tree
+func (r *Store) addAliases(root *tree.Root) {
+	for _, name := range r.cfg.AliasNames() {
+		if err := root.AddAlias(name, r.cfg.Aliases[name]); err != nil {
+			debug.Log("not showing alias %s: %s", name, err)
+		}
+	}
+}
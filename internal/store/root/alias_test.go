@@ -0,0 +1,63 @@
+package root
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	color.NoColor = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	require.NoError(t, u.InitStore("customers/acme/production"))
+	require.NoError(t, rs.AddMount(ctx, "customers/acme/production", u.StoreDir("customers/acme/production")))
+	rs.cfg.Aliases = map[string]string{"prod": "customers/acme/production"}
+
+	sec := secrets.New()
+	sec.SetPassword("secret")
+	require.NoError(t, rs.Set(ctx, "prod/db", sec))
+
+	// the entry ends up in the mount, not in a prod folder of the root store
+	assert.False(t, rs.store.Exists(ctx, "prod/db"))
+	sub, err := rs.GetSubStore("customers/acme/production")
+	require.NoError(t, err)
+	assert.True(t, sub.Exists(ctx, "db"))
+
+	got, err := rs.Get(ctx, "prod/db")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", got.Password())
+	assert.True(t, rs.IsDir(ctx, "prod"))
+
+	require.NoError(t, rs.Move(ctx, "prod/db", "prod/db2"))
+	assert.True(t, rs.Exists(ctx, "customers/acme/production/db2"))
+	assert.False(t, rs.Exists(ctx, "prod/db"))
+
+	// aliases are shown, but never listed
+	st, err := rs.Tree(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, st.Format(tree.INF), "prod -> customers/acme/production\n")
+	assert.Contains(t, st.List(tree.INF), "customers/acme/production/db2")
+	assert.NotContains(t, st.List(tree.INF), "prod")
+
+	require.NoError(t, rs.Delete(ctx, "prod/db2"))
+	assert.False(t, sub.Exists(ctx, "db2"))
+}
@@ -2,9 +2,13 @@ package root
 
 import (
 	"context"
+	"sort"
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 	multierror "github.com/hashicorp/go-multierror"
 )
@@ -12,6 +16,7 @@ import (
 // Fsck checks all stores/entries matching the given prefix
 func (s *Store) Fsck(ctx context.Context, path string) error {
 	var result error
+	prefix := path
 
 	for alias, sub := range s.mounts {
 		if sub == nil {
@@ -32,5 +37,70 @@ func (s *Store) Fsck(ctx context.Context, path string) error {
 		result = multierror.Append(result, err)
 	}
 
+	if err := s.fsckShadowed(ctx, prefix); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if err := s.fsckLinks(ctx, prefix); err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	return result
 }
+
+// fsckShadowed reports all entries hidden by a more specific mount point
+func (s *Store) fsckShadowed(ctx context.Context, prefix string) error {
+	shadowed, err := s.Shadowed(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(shadowed))
+	for name := range shadowed {
+		if prefix != "" && !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := leaf.GetFsckReport(ctx)
+	for _, name := range names {
+		mp := shadowed[name]
+		out.Warningf(ctx, "%s is shadowed by mount %q. Move it elsewhere after unmounting with 'gopass mounts remove %s' and mount it again.", name, mp, mp)
+		report.Add(leaf.FsckProblem{Type: leaf.FsckShadowed, Secret: name, Mount: mp})
+	}
+	return nil
+}
+
+// fsckLinks rebuilds the link index of all entries matching the prefix and
+// reports links whose target is missing or that form a loop. Secrets that
+// can't be decrypted are skipped, they are reported by --decrypt.
+func (s *Store) fsckLinks(ctx context.Context, prefix string) error {
+	names, err := s.List(leaf.WithNoIgnore(ctx, true), tree.INF)
+	if err != nil {
+		return err
+	}
+
+	report := leaf.GetFsckReport(ctx)
+	hctx := ctxutil.WithHidden(ctx, true)
+	links := map[string]string{}
+	for _, name := range names {
+		if prefix != "" && !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		target, found := s.LinkTarget(hctx, name)
+		if !found {
+			continue
+		}
+		links[name] = target
+		if _, _, err := s.Resolve(hctx, name); err != nil {
+			out.Warningf(ctx, "%s is a dangling link: %s", name, err)
+			report.Add(leaf.FsckProblem{Type: leaf.FsckDanglingLink, Secret: name, Error: err.Error()})
+		}
+	}
+	debug.Log("found %d links below %q", len(links), prefix)
+
+	s.updateLinkIndex(prefix, links, true)
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"github.com/gopasspw/gopass/internal/backend"
-	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/fsutil"
@@ -31,7 +30,7 @@ func (r *Store) Init(ctx context.Context, alias, path string, ids ...string) err
 	if !backend.HasStorageBackend(ctx) {
 		ctx = backend.WithStorageBackend(ctx, backend.GitFS)
 	}
-	sub, err := leaf.New(ctx, alias, path)
+	sub, err := leaf.New(r.withMountConfig(ctx, alias), alias, path)
 	if err != nil {
 		return fmt.Errorf("failed to instantiate new sub store: %w", err)
 	}
@@ -64,22 +63,27 @@ func (r *Store) initialize(ctx context.Context) error {
 	// create the base store
 	path := fsutil.CleanPath(r.cfg.Path)
 	debug.Log("initialize - %s", path)
-	s, err := leaf.New(ctx, "", path)
+	s, err := leaf.New(r.withMountConfig(ctx, ""), "", path)
 	if err != nil {
 		return fmt.Errorf("failed to initialize the root store at %q: %w", r.cfg.Path, err)
 	}
 	debug.Log("Root Store initialized at %s", path)
 	r.store = s
 
-	// initialize all mounts
+	// the mounts are only opened when they are accessed, so a command using
+	// one of them doesn't have to wait for all others
+	r.mu.Lock()
+	if r.lazy == nil {
+		r.lazy = make(map[string]lazyMount, len(r.cfg.Mounts))
+	}
 	for alias, path := range r.cfg.Mounts {
-		path := fsutil.CleanPath(path)
-		if err := r.addMount(ctx, alias, path); err != nil {
-			out.Errorf(ctx, "Failed to initialize mount %s (%s). Ignoring: %s", alias, path, err)
+		if _, found := r.mounts[alias]; found {
 			continue
 		}
-		debug.Log("Sub-Store mounted at %s from %s", alias, path)
+		r.lazy[alias] = lazyMount{ctx: ctx, path: fsutil.CleanPath(path)}
+		debug.Log("Sub-Store %s at %s will be mounted on first access", alias, path)
 	}
+	r.mu.Unlock()
 
 	// check for duplicate mounts
 	if err := r.checkMounts(); err != nil {
@@ -3,16 +3,97 @@ package root
 import (
 	"context"
 	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/link"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
-// Link creates a symlink
-func (r *Store) Link(ctx context.Context, from, to string) error {
-	subFrom, fName := r.getStore(from)
-	subTo, tName := r.getStore(to)
+// Link creates a secret at alias pointing to target. Unlike a symlink it
+// works across mounts.
+func (r *Store) Link(ctx context.Context, target, alias string) error {
+	target = strings.Trim(target, "/")
+	alias = strings.Trim(alias, "/")
+
+	if target == alias {
+		return fmt.Errorf("can not link %q to itself", alias)
+	}
+	if r.Exists(ctx, alias) {
+		return fmt.Errorf("destination %q already exists", alias)
+	}
+	if !r.Exists(ctx, target) {
+		return fmt.Errorf("target %q does not exist", target)
+	}
+	// links to links are fine, as long as they end in a secret
+	if _, _, err := r.Resolve(ctx, target); err != nil {
+		return err
+	}
 
-	if !subFrom.Equals(subTo) {
-		return fmt.Errorf("sylinks across stores are not supported")
+	if err := r.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Linked %s to %s", alias, target)), alias, link.New(target)); err != nil {
+		return fmt.Errorf("failed to create link %q: %w", alias, err)
+	}
+	if ctxutil.IsDryRun(ctx) {
+		return nil
 	}
 
-	return subFrom.Link(ctx, fName, tName)
+	r.updateLinkIndex("", map[string]string{alias: target}, false)
+	return nil
+}
+
+// Resolve returns the secret at name. If it's a link it returns the name and
+// the secret of its target instead, following at most link.MaxDepth links.
+func (r *Store) Resolve(ctx context.Context, name string) (string, gopass.Secret, error) {
+	chain := []string{name}
+	for {
+		sec, err := r.Get(ctx, name)
+		if err != nil {
+			if len(chain) > 1 {
+				return name, nil, fmt.Errorf("link %s points to %s: %w", chain[0], strings.Join(chain[1:], " -> "), err)
+			}
+			return name, nil, err
+		}
+
+		target, found := link.Target(sec)
+		if !found {
+			return name, sec, nil
+		}
+		for _, seen := range chain {
+			if seen == target {
+				return name, nil, fmt.Errorf("%w: %s -> %s", link.ErrLoop, strings.Join(chain, " -> "), target)
+			}
+		}
+		if len(chain) > link.MaxDepth {
+			return name, nil, fmt.Errorf("%w: %s", link.ErrTooDeep, strings.Join(chain, " -> "))
+		}
+
+		debug.Log("following link %s to %s", name, target)
+		chain = append(chain, target)
+		name = target
+	}
+}
+
+// LinkTarget returns the target of name if it's a link
+func (r *Store) LinkTarget(ctx context.Context, name string) (string, bool) {
+	sec, err := r.Get(ctxutil.WithShowParsing(ctx, false), name)
+	if err != nil {
+		return "", false
+	}
+	return link.Target(sec)
+}
+
+// LinksTo returns the existing links pointing to name according to the link
+// index. The index is rebuilt by fsck, links created by other clients are
+// only known after that.
+func (r *Store) LinksTo(ctx context.Context, name string) []string {
+	idx := r.loadLinkIndex()
+
+	var aliases []string
+	for _, alias := range idx.linksTo(name) {
+		if r.Exists(ctx, alias) {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
 }
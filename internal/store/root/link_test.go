@@ -0,0 +1,111 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/link"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLink(t *testing.T) {
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	color.NoColor = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	require.NoError(t, u.InitStore("infra"))
+	require.NoError(t, rs.AddMount(ctx, "infra", u.StoreDir("infra")))
+
+	sec := secrets.New()
+	sec.SetPassword("hunter2")
+	require.NoError(t, rs.Set(ctx, "websites/aws.amazon.com", sec))
+
+	// links work across mounts
+	require.NoError(t, rs.Link(ctx, "websites/aws.amazon.com", "infra/aws/root"))
+	require.NoError(t, rs.Link(ctx, "infra/aws/root", "aws"))
+
+	name, sec, err := rs.Resolve(ctx, "aws")
+	require.NoError(t, err)
+	assert.Equal(t, "websites/aws.amazon.com", name)
+	assert.Equal(t, "hunter2", sec.Password())
+
+	target, found := rs.LinkTarget(ctx, "aws")
+	assert.True(t, found)
+	assert.Equal(t, "infra/aws/root", target)
+	_, found = rs.LinkTarget(ctx, "websites/aws.amazon.com")
+	assert.False(t, found)
+
+	assert.Error(t, rs.Link(ctx, "websites/aws.amazon.com", "aws"), "alias exists")
+	assert.Error(t, rs.Link(ctx, "websites/missing", "missing"), "target missing")
+	assert.Error(t, rs.Link(ctx, "aws", "aws"), "self link")
+
+	assert.Equal(t, []string{"infra/aws/root"}, rs.LinksTo(ctx, "websites/aws.amazon.com"))
+
+	st, err := rs.Tree(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, st.List(tree.INF), "aws")
+	assert.Contains(t, st.Format(tree.INF), "aws -> infra/aws/root")
+
+	t.Run("dangling", func(t *testing.T) {
+		require.NoError(t, rs.Set(ctx, "dangling", link.New("gone")))
+		_, _, err := rs.Resolve(ctx, "dangling")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, store.ErrNotFound))
+		assert.Contains(t, err.Error(), "link dangling points to gone")
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		require.NoError(t, rs.Set(ctx, "loop/a", link.New("loop/b")))
+		require.NoError(t, rs.Set(ctx, "loop/b", link.New("loop/a")))
+		_, _, err := rs.Resolve(ctx, "loop/a")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, link.ErrLoop))
+		assert.Contains(t, err.Error(), "loop/a -> loop/b -> loop/a")
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		prev := "websites/aws.amazon.com"
+		for _, name := range []string{"d/1", "d/2", "d/3", "d/4", "d/5", "d/6", "d/7", "d/8", "d/9"} {
+			require.NoError(t, rs.Set(ctx, name, link.New(prev)))
+			prev = name
+		}
+		_, _, err := rs.Resolve(ctx, "d/8")
+		assert.NoError(t, err)
+		_, _, err = rs.Resolve(ctx, "d/9")
+		assert.True(t, errors.Is(err, link.ErrTooDeep))
+	})
+
+	t.Run("fsck", func(t *testing.T) {
+		report := &leaf.FsckReport{}
+		require.NoError(t, rs.Fsck(leaf.WithFsckReport(ctx, report), ""))
+		var dangling []string
+		for _, p := range report.Problems() {
+			if p.Type == leaf.FsckDanglingLink {
+				dangling = append(dangling, p.Secret)
+			}
+		}
+		assert.Equal(t, []string{"d/9", "dangling", "loop/a", "loop/b"}, dangling)
+
+		// the index now knows all links
+		assert.Equal(t, []string{"dangling"}, rs.LinksTo(ctx, "gone"))
+		assert.Equal(t, []string{"loop/b"}, rs.LinksTo(ctx, "loop/a"))
+	})
+}
@@ -0,0 +1,126 @@
+package root
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/appdir"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// linkIndexVersion must be increased whenever the format of the index changes
+const linkIndexVersion = 1
+
+// linkIndex maps the full names of all known links to their targets. Finding
+// the links otherwise requires decrypting every secret, so it's built by
+// fsck and extended by link. It's kept in the cache dir so that it doesn't
+// end up in the store or its git history.
+type linkIndex struct {
+	Version int               `json:"version"`
+	Path    string            `json:"path"`
+	Links   map[string]string `json:"links"`
+}
+
+// linkIndexFile returns the name of the link index of the root store
+func linkIndexFile(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		path = p
+	}
+	return filepath.Join(appdir.UserCache(), "links", fmt.Sprintf("%x", sha256.Sum256([]byte(path)))[:16]+".json")
+}
+
+// loadLinkIndex returns the link index, an empty one if there is none yet
+func (r *Store) loadLinkIndex() *linkIndex {
+	idx := &linkIndex{
+		Version: linkIndexVersion,
+		Path:    r.Path(),
+		Links:   map[string]string{},
+	}
+
+	buf, err := os.ReadFile(linkIndexFile(r.Path()))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			debug.Log("failed to read link index: %s", err)
+		}
+		return idx
+	}
+	loaded := &linkIndex{}
+	if err := json.Unmarshal(buf, loaded); err != nil {
+		debug.Log("failed to decode link index: %s", err)
+		return idx
+	}
+	if loaded.Version != linkIndexVersion || loaded.Path != idx.Path || loaded.Links == nil {
+		debug.Log("ignoring link index of version %d for %s", loaded.Version, loaded.Path)
+		return idx
+	}
+	return loaded
+}
+
+// updateLinkIndex adds the given links to the index. With replace all known
+// links below prefix, or all of them if it's empty, are removed first.
+func (r *Store) updateLinkIndex(prefix string, links map[string]string, replace bool) {
+	idx := r.loadLinkIndex()
+	if replace {
+		for alias := range idx.Links {
+			if prefix == "" || strings.HasPrefix(alias, prefix) {
+				delete(idx.Links, alias)
+			}
+		}
+	}
+	for alias, target := range links {
+		idx.Links[alias] = target
+	}
+
+	if err := idx.save(linkIndexFile(r.Path())); err != nil {
+		debug.Log("failed to save link index: %s", err)
+	}
+}
+
+// linksTo returns the sorted names of all links pointing to target
+func (idx *linkIndex) linksTo(target string) []string {
+	var aliases []string
+	for alias, t := range idx.Links {
+		if t == target {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+func (idx *linkIndex) save(fn string) error {
+	buf, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(fn)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create link index dir: %w", err)
+	}
+	fh, err := os.CreateTemp(dir, "."+filepath.Base(fn)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := fh.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+
+	if _, err := fh.Write(buf); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
@@ -23,8 +23,15 @@ func (r *Store) List(ctx context.Context, maxDepth int) ([]string, error) {
 // Tree returns the tree representation of the entries
 func (r *Store) Tree(ctx context.Context) (*tree.Root, error) {
 	root := tree.New("gopass")
+	links := r.loadLinkIndex().Links
 	addFileFunc := func(in ...string) {
 		for _, f := range in {
+			if target, found := links[f]; found {
+				if err := root.AddLink(f, target); err != nil {
+					out.Errorf(ctx, "Failed to add link %s to tree: %s", f, err)
+				}
+				continue
+			}
 			var ct string
 			switch {
 			case strings.HasSuffix(f, ".b64"):
@@ -51,31 +58,55 @@ func (r *Store) Tree(ctx context.Context) (*tree.Root, error) {
 		}
 	}
 
+	// entries hidden by a mount point are added last, so they don't replace
+	// the entries of the mount
+	var shadowed []string
+	addStoreFunc := func(alias string, in ...string) {
+		for _, f := range in {
+			if r.shadowingMount(alias, f) != "" {
+				shadowed = append(shadowed, f)
+				continue
+			}
+			addFileFunc(f)
+		}
+	}
+
 	sf, err := r.store.List(ctx, "")
 	if err != nil {
 		return nil, err
 	}
-	addFileFunc(sf...)
+	addStoreFunc("", sf...)
 	addTplFunc(r.store.ListTemplates(ctx, "")...)
 
 	mps := r.MountPoints()
 	sort.Sort(store.ByPathLen(mps))
 	for _, alias := range mps {
-		substore := r.mounts[alias]
+		substore := r.mount(alias)
 		if substore == nil {
 			continue
 		}
-		if err := root.AddMount(alias, substore.Path()); err != nil {
+		add := root.AddMount
+		if substore.IsReadOnly() {
+			add = root.AddReadOnlyMount
+		}
+		if err := add(alias, substore.Path()); err != nil {
 			return nil, fmt.Errorf("failed to add mount: %w", err)
 		}
 		sf, err := substore.List(ctx, "")
 		if err != nil {
 			return nil, fmt.Errorf("failed to add file: %w", err)
 		}
-		addFileFunc(sf...)
+		addStoreFunc(alias, sf...)
 		addTplFunc(substore.ListTemplates(ctx, alias)...)
 	}
 
+	for _, f := range shadowed {
+		if err := root.AddShadowed(f); err != nil {
+			out.Errorf(ctx, "Failed to add shadowed file %s to tree: %s", f, err)
+		}
+	}
+	r.addAliases(root)
+
 	return root, nil
 }
 
@@ -102,3 +133,21 @@ func (r *Store) Format(ctx context.Context, maxDepth int) (string, error) {
 	}
 	return t.Format(maxDepth), nil
 }
+
+// RebuildIndex rebuilds the index of the entries of the root store and all
+// mounts
+func (r *Store) RebuildIndex(ctx context.Context) error {
+	if err := r.store.RebuildIndex(ctx); err != nil {
+		return fmt.Errorf("failed to rebuild the index of the root store: %w", err)
+	}
+	for _, alias := range r.MountPoints() {
+		sub := r.mount(alias)
+		if sub == nil {
+			continue
+		}
+		if err := sub.RebuildIndex(ctx); err != nil {
+			return fmt.Errorf("failed to rebuild the index of %s: %w", alias, err)
+		}
+	}
+	return nil
+}
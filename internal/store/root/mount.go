@@ -29,10 +29,7 @@ func (r *Store) addMount(ctx context.Context, alias, path string, keys ...string
 	if alias == "" {
 		return fmt.Errorf("alias must not be empty")
 	}
-	if r.mounts == nil {
-		r.mounts = make(map[string]*leaf.Store, 1)
-	}
-	if _, found := r.mounts[alias]; found {
+	if r.isMounted(alias) {
 		return AlreadyMountedError(alias)
 	}
 
@@ -45,7 +42,12 @@ func (r *Store) addMount(ctx context.Context, alias, path string, keys ...string
 		return fmt.Errorf("failed to init sub store %q at %q: %w", alias, fullPath, err)
 	}
 
+	r.mu.Lock()
+	if r.mounts == nil {
+		r.mounts = make(map[string]*leaf.Store, 1)
+	}
 	r.mounts[alias] = s
+	r.mu.Unlock()
 	if r.cfg.Mounts == nil {
 		r.cfg.Mounts = make(map[string]string, 1)
 	}
@@ -55,9 +57,82 @@ func (r *Store) addMount(ctx context.Context, alias, path string, keys ...string
 	return nil
 }
 
+// lazyMount is a configured mount that is opened on first access
+type lazyMount struct {
+	// ctx is the context the root store was initialized with
+	ctx  context.Context
+	path string
+}
+
+func (r *Store) isMounted(alias string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, found := r.mounts[alias]
+	_, pending := r.lazy[alias]
+	return found || pending
+}
+
+// mount returns the store mounted at alias. A configured mount is opened on
+// the first access. If that fails a warning is printed and the mount is
+// ignored from then on, like any other unknown mount it returns nil.
+func (r *Store) mount(alias string) *leaf.Store {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.openMount(alias)
+}
+
+// openMount does the work for mount. The caller must hold the lock.
+func (r *Store) openMount(alias string) *leaf.Store {
+	if sub, found := r.mounts[alias]; found {
+		return sub
+	}
+	lm, found := r.lazy[alias]
+	if !found {
+		return nil
+	}
+	delete(r.lazy, alias)
+
+	debug.Log("opening mount %s at %s", alias, lm.path)
+	sub, err := r.initSub(lm.ctx, alias, lm.path, nil)
+	if err != nil {
+		out.Warningf(lm.ctx, "Failed to initialize mount %s (%s). Ignoring: %s", alias, lm.path, err)
+		return nil
+	}
+	if r.mounts == nil {
+		r.mounts = make(map[string]*leaf.Store, 1)
+	}
+	r.mounts[alias] = sub
+	debug.Log("Sub-Store mounted at %s from %s", alias, lm.path)
+	return sub
+}
+
+// allMounts opens all mounts that have not been accessed, yet. It returns
+// the ones that could be opened.
+func (r *Store) allMounts() map[string]*leaf.Store {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for alias := range r.lazy {
+		r.openMount(alias)
+	}
+	m := make(map[string]*leaf.Store, len(r.mounts))
+	for alias, sub := range r.mounts {
+		m[alias] = sub
+	}
+	return m
+}
+
+// OpenMounts opens all mounts. Usually they are only opened on first access,
+// this surfaces the warnings about broken mounts right away.
+func (r *Store) OpenMounts() {
+	_ = r.allMounts()
+}
+
 func (r *Store) initSub(ctx context.Context, alias, path string, keys []string) (*leaf.Store, error) {
 	// init regular sub store
-	s, err := leaf.New(ctx, alias, path)
+	s, err := leaf.New(r.withMountConfig(ctx, alias), alias, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize store %q at %q: %w", alias, path, err)
 	}
@@ -85,23 +160,30 @@ func (r *Store) initSub(ctx context.Context, alias, path string, keys []string)
 
 // RemoveMount removes and existing mount
 func (r *Store) RemoveMount(ctx context.Context, alias string) error {
-	if _, found := r.mounts[alias]; !found {
+	if !r.isMounted(alias) {
 		out.Warningf(ctx, "%s is not mounted", alias)
 	}
-	if _, found := r.mounts[alias]; !found {
-		out.Warningf(ctx, "%s is not initialized", alias)
-	}
+	r.mu.Lock()
 	delete(r.mounts, alias)
+	delete(r.lazy, alias)
+	r.mu.Unlock()
 	delete(r.cfg.Mounts, alias)
+	delete(r.cfg.MountReadOnly, alias)
 	return nil
 }
 
-// Mounts returns a map of mounts with their paths
+// Mounts returns a map of mounts with their paths. It doesn't open any mount.
 func (r *Store) Mounts() map[string]string {
-	m := make(map[string]string, len(r.mounts))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := make(map[string]string, len(r.mounts)+len(r.lazy))
 	for alias, sub := range r.mounts {
 		m[alias] = sub.Path()
 	}
+	for alias, lm := range r.lazy {
+		m[alias] = lm.path
+	}
 	return m
 }
 
@@ -109,10 +191,16 @@ func (r *Store) Mounts() map[string]string {
 // the longer a mount point the more specific it is. This allows to "shadow" a
 // shorter mount point by a longer one.
 func (r *Store) MountPoints() []string {
-	mps := make([]string, 0, len(r.mounts))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mps := make([]string, 0, len(r.mounts)+len(r.lazy))
 	for k := range r.mounts {
 		mps = append(mps, k)
 	}
+	for k := range r.lazy {
+		mps = append(mps, k)
+	}
 	sort.Sort(sort.Reverse(store.ByPathLen(mps)))
 	return mps
 }
@@ -127,9 +215,21 @@ func (r *Store) MountPoint(name string) string {
 	return ""
 }
 
-// Lock drops all cached credentials
+// Lock drops all cached credentials of the mounts that have been opened and
+// the decrypted secrets
 func (r *Store) Lock() error {
+	if r.plain != nil {
+		r.plain.Purge()
+	}
+
+	r.mu.Lock()
+	mounts := make([]*leaf.Store, 0, len(r.mounts))
 	for _, sub := range r.mounts {
+		mounts = append(mounts, sub)
+	}
+	r.mu.Unlock()
+
+	for _, sub := range mounts {
 		if err := sub.Lock(); err != nil {
 			return err
 		}
@@ -140,12 +240,18 @@ func (r *Store) Lock() error {
 // getStore returns the Store object at the most-specific mount point for the
 // given key. returns sub store reference, truncated path to secret
 func (r *Store) getStore(name string) (*leaf.Store, string) {
-	name = strings.TrimSuffix(name, "/")
-	mp := r.MountPoint(name)
-	if sub, found := r.mounts[mp]; found {
-		return sub, strings.TrimPrefix(name, sub.Alias())
+	name = strings.TrimSuffix(r.ExpandAlias(name), "/")
+	// a mount that can't be opened is dropped, so the next less specific
+	// one is tried
+	for {
+		mp := r.MountPoint(name)
+		if mp == "" {
+			return r.store, name
+		}
+		if sub := r.mount(mp); sub != nil {
+			return sub, strings.TrimPrefix(name, sub.Alias())
+		}
 	}
-	return r.store, name
 }
 
 // GetSubStore returns an exact match for a mount point or an error if this
@@ -154,22 +260,22 @@ func (r *Store) GetSubStore(name string) (*leaf.Store, error) {
 	if name == "" {
 		return r.store, nil
 	}
-	if sub, found := r.mounts[name]; found {
+	if sub := r.mount(name); sub != nil {
 		return sub, nil
 	}
-	debug.Log("mounts available: %+v", r.mounts)
+	debug.Log("mounts available: %+v", r.Mounts())
 	return nil, fmt.Errorf("no such mount point %q", name)
 }
 
 // checkMounts performs some sanity checks on our mounts. At the moment it
 // only checks if some path is mounted twice.
 func (r *Store) checkMounts() error {
-	paths := make(map[string]string, len(r.mounts))
-	for k, v := range r.mounts {
-		if _, found := paths[v.Path()]; found {
-			return fmt.Errorf("doubly mounted path at %s: %s", v.Path(), k)
+	paths := make(map[string]string, len(r.cfg.Mounts))
+	for k, v := range r.Mounts() {
+		if _, found := paths[v]; found {
+			return fmt.Errorf("doubly mounted path at %s: %s", v, k)
 		}
-		paths[v.Path()] = k
+		paths[v] = k
 	}
 	return nil
 }
@@ -1,9 +1,17 @@
 package root
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 
@@ -36,3 +44,164 @@ func TestMount(t *testing.T) {
 	// removing mounts should never fail
 	assert.NoError(t, rs.RemoveMount(ctx, "foo"))
 }
+
+func TestLock(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+
+	rs := New(&config.Config{
+		Path:         u.StoreDir(""),
+		DecryptCache: 10,
+	})
+	_, err := rs.IsInitialized(ctx)
+	require.NoError(t, err)
+
+	_, err = rs.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rs.plain.Len())
+
+	// the decrypted secrets are dropped along with the credentials
+	require.NoError(t, rs.Lock())
+	assert.Equal(t, 0, rs.plain.Len())
+}
+
+func TestLazyMounts(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, u.InitStore("home"))
+	rs := New(&config.Config{
+		Path: u.StoreDir(""),
+		Mounts: map[string]string{
+			"work":   u.StoreDir("work"),
+			"home":   u.StoreDir("home"),
+			"broken": filepath.Join(u.Dir, "missing"),
+		},
+	})
+	_, err := rs.IsInitialized(ctx)
+	require.NoError(t, err)
+
+	// no mount is opened before it's accessed
+	assert.Len(t, rs.mounts, 0)
+	assert.Equal(t, map[string]string{
+		"work":   u.StoreDir("work"),
+		"home":   u.StoreDir("home"),
+		"broken": filepath.Join(u.Dir, "missing"),
+	}, rs.Mounts())
+
+	_, err = rs.Get(ctx, "work/foo")
+	require.NoError(t, err)
+	assert.Len(t, rs.mounts, 1)
+	assert.NotNil(t, rs.mounts["work"])
+	assert.Equal(t, "", buf.String())
+
+	// a broken mount only warns once it's accessed and is ignored from then
+	assert.False(t, rs.Exists(ctx, "broken/foo"))
+	assert.Contains(t, buf.String(), "Failed to initialize mount broken")
+	assert.Len(t, rs.MountPoints(), 2)
+	_, err = rs.GetSubStore("broken")
+	assert.Error(t, err)
+
+	// listing opens all mounts
+	l, err := rs.List(ctx, tree.INF)
+	require.NoError(t, err)
+	assert.Contains(t, l, "home/foo")
+	assert.Len(t, rs.mounts, 2)
+
+	assert.Error(t, rs.AddMount(ctx, "work", u.StoreDir("work")))
+}
+
+func TestOpenMounts(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+
+	rs := New(&config.Config{
+		Path: u.StoreDir(""),
+		Mounts: map[string]string{
+			"broken": filepath.Join(u.Dir, "missing"),
+		},
+	})
+	_, err := rs.IsInitialized(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"broken"}, rs.MountPoints())
+
+	rs.OpenMounts()
+	assert.Contains(t, buf.String(), "Failed to initialize mount broken")
+	assert.Equal(t, []string{}, rs.MountPoints())
+}
+
+// BenchmarkMounts compares accessing a single secret of one of many mounts
+// with opening all of them, like it was done before mounts were opened lazily
+func BenchmarkMounts(b *testing.B) {
+	ctx := context.Background()
+	ctx = ctxutil.WithHidden(ctx, true)
+	ctx = backend.WithCryptoBackendString(ctx, "plain")
+
+	dir := b.TempDir()
+	cfg := &config.Config{
+		Path:   filepath.Join(dir, "root"),
+		Mounts: map[string]string{},
+	}
+	createBenchStore(b, cfg.Path)
+	for i := 0; i < 6; i++ {
+		alias := fmt.Sprintf("mount%d", i)
+		cfg.Mounts[alias] = filepath.Join(dir, alias)
+		createBenchStore(b, cfg.Mounts[alias])
+	}
+
+	for _, eager := range []bool{false, true} {
+		eager := eager
+		b.Run(fmt.Sprintf("eager=%t", eager), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				c := *cfg
+				rs := New(&c)
+				if _, err := rs.IsInitialized(ctx); err != nil {
+					b.Fatal(err)
+				}
+				if eager {
+					rs.OpenMounts()
+				}
+				if _, err := rs.Get(ctx, "mount3/foo"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func createBenchStore(b *testing.B, dir string) {
+	b.Helper()
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".plain-id"), []byte("0xDEADBEEF"), 0600); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "foo.txt"), []byte("secret"), 0600); err != nil {
+		b.Fatal(err)
+	}
+}
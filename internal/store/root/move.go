@@ -16,7 +16,9 @@ import (
 
 // Copy will copy one entry to another location. Multi-store copies are
 // supported. Each entry has to be decoded and encoded for the destination
-// to make sure it's encrypted for the right set of recipients.
+// to make sure it's encrypted for the right set of recipients. With
+// ctxutil.WithForce copies within the same mount and recipients scope skip
+// that and copy the encrypted entry as is.
 func (r *Store) Copy(ctx context.Context, from, to string) error {
 	return r.move(ctx, from, to, false)
 }
@@ -24,15 +26,26 @@ func (r *Store) Copy(ctx context.Context, from, to string) error {
 // Move will move one entry from one location to another. Cross-store moves are
 // supported. Moving an entry will decode it from the old location, encode it
 // for the destination store with the right set of recipients and remove it
-// from the old location afterwards.
+// from the old location afterwards. With ctxutil.WithForce moves within the
+// same mount and recipients scope skip the re-encryption.
 func (r *Store) Move(ctx context.Context, from, to string) error {
 	return r.move(ctx, from, to, true)
 }
 
 func (r *Store) move(ctx context.Context, from, to string, delete bool) error {
+	from, to = r.ExpandAlias(from), r.ExpandAlias(to)
 	subFrom, fromPrefix := r.getStore(from)
 	subTo, _ := r.getStore(to)
 
+	if err := subTo.CheckWritable(); err != nil {
+		return err
+	}
+	if delete {
+		if err := subFrom.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
 	srcIsDir := r.IsDir(ctx, from)
 	dstIsDir := r.IsDir(ctx, to)
 	if srcIsDir && r.Exists(ctx, to) && !dstIsDir {
@@ -42,26 +55,7 @@ func (r *Store) move(ctx context.Context, from, to string, delete bool) error {
 	if err := r.moveFromTo(ctx, subFrom, from, to, fromPrefix, srcIsDir, dstIsDir, delete); err != nil {
 		return err
 	}
-	if err := subFrom.Storage().Commit(ctx, fmt.Sprintf("Move from %s to %s", from, to)); delete && err != nil {
-		switch {
-		case errors.Is(err, store.ErrGitNotInit):
-			debug.Log("skipping git commit - git not initialized")
-		default:
-			return fmt.Errorf("failed to commit changes to git (from): %w", err)
-		}
-	}
-	if !subFrom.Equals(subTo) {
-		if err := subTo.Storage().Commit(ctx, fmt.Sprintf("Move from %s to %s", from, to)); err != nil {
-			switch errors.Unwrap(err) {
-			case store.ErrGitNotInit:
-				debug.Log("skipping git commit - git not initialized")
-			default:
-				return fmt.Errorf("failed to commit changes to git (to): %w", err)
-			}
-		}
-	}
-
-	if err := subFrom.Storage().Push(ctx, "", ""); err != nil {
+	if err := subFrom.Writer(ctx).Push(ctx, "", ""); err != nil {
 		if errors.Is(err, store.ErrGitNotInit) {
 			msg := "Warning: git is not initialized for this storage. Ignoring auto-push option\n" +
 				"Run: gopass git init"
@@ -77,7 +71,7 @@ func (r *Store) move(ctx context.Context, from, to string, delete bool) error {
 		return fmt.Errorf("failed to push change to git remote: %w", err)
 	}
 	if !subFrom.Equals(subTo) {
-		if err := subTo.Storage().Push(ctx, "", ""); err != nil {
+		if err := subTo.Writer(ctx).Push(ctx, "", ""); err != nil {
 			if errors.Is(err, store.ErrGitNotInit) {
 				msg := "Warning: git is not initialized for this storage. Ignoring auto-push option\n" +
 					"Run: gopass git init"
@@ -96,15 +90,23 @@ func (r *Store) move(ctx context.Context, from, to string, delete bool) error {
 	return nil
 }
 
+// moveFromTo moves or copies all entries one by one. Each entry is committed
+// on its own, so an interrupted operation leaves all stores in a consistent
+// state.
 func (r *Store) moveFromTo(ctx context.Context, subFrom *leaf.Store, from, to, fromPrefix string, srcIsDir, dstIsDir, delete bool) error {
 	ctx = ctxutil.WithGitCommit(ctx, false)
+	op := "Copy"
+	if delete {
+		op = "Move"
+	}
 
 	entries := []string{from}
 	// if the source is a directory we enumerate all it's children
 	// and move them one by one.
 	if r.IsDir(ctx, from) {
 		var err error
-		entries, err = subFrom.List(ctx, fromPrefix)
+		// the secrets matched by the ignore rules are moved along
+		entries, err = subFrom.List(leaf.WithNoIgnore(ctx, true), fromPrefix)
 		if err != nil {
 			return err
 		}
@@ -115,7 +117,13 @@ func (r *Store) moveFromTo(ctx context.Context, subFrom *leaf.Store, from, to, f
 
 	debug.Log("Moving %q to %q (entries: %+v)", from, to, entries)
 
-	for _, src := range entries {
+	for i, src := range entries {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s interrupted after %d of %d entries: %w", strings.ToLower(op), i, len(entries), err)
+		}
+		// an entry that has been started is always finished and committed
+		ctx := ctxutil.WithoutCancel(ctx)
+
 		dst := to
 		if srcIsDir {
 			// Follow the rsync convention to not re-create the source folder at the destination when a "/" is found
@@ -131,12 +139,29 @@ func (r *Store) moveFromTo(ctx context.Context, subFrom *leaf.Store, from, to, f
 		}
 		debug.Log("Moving %q (%q) => %q (%q) (sid:%t, did:%t, delete:%t)\n", from, src, to, dst, srcIsDir, dstIsDir, delete)
 
+		// the encrypted entry can only be moved as is if it stays in the same
+		// recipients scope, everything else is re-encrypted for the
+		// recipients of the destination
+		subSrc, srcName := r.getStore(src)
+		subDst, dstName := r.getStore(dst)
+		if ctxutil.IsForce(ctx) && subSrc.Equals(subDst) && subSrc.SameScope(ctx, srcName, dstName) {
+			debug.Log("Moving %q to %q without re-encrypting", src, dst)
+			if err := subSrc.MoveCiphertext(ctx, srcName, dstName, delete); err != nil {
+				return fmt.Errorf("failed to move %q to %q: %w", src, dst, err)
+			}
+			if err := commitMove(ctx, subSrc, subDst, src, dst, fmt.Sprintf("%s from %s to %s", op, src, dst), delete); err != nil {
+				return err
+			}
+			continue
+		}
+
 		content, err := r.Get(ctx, src)
 		if err != nil {
 			return fmt.Errorf("source %s does not exist in source store %s: %s", from, subFrom.Alias(), err)
 		}
 
-		if err := r.Set(ctxutil.WithCommitMessage(ctx, fmt.Sprintf("Move from %s to %s", src, dst)), dst, content); err != nil {
+		msg := fmt.Sprintf("%s from %s to %s", op, src, dst)
+		if err := r.Set(ctxutil.WithCommitMessage(ctx, msg), dst, content); err != nil {
 			return fmt.Errorf("failed to save secret %q: %w", to, err)
 		}
 
@@ -146,6 +171,35 @@ func (r *Store) moveFromTo(ctx context.Context, subFrom *leaf.Store, from, to, f
 				return fmt.Errorf("failed to delete secret %q: %w", src, err)
 			}
 		}
+		if err := commitMove(ctx, subSrc, subDst, src, dst, msg, delete); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitMove commits a single moved or copied entry to the destination store
+// and, for moves, to the source store. src and dst are full names.
+func commitMove(ctx context.Context, subFrom, subTo *leaf.Store, src, dst, msg string, delete bool) error {
+	op := leaf.OpCopy
+	if delete {
+		op = leaf.OpMove
+	}
+	stores := []*leaf.Store{subTo}
+	if delete && !subFrom.Equals(subTo) {
+		stores = append(stores, subFrom)
+	}
+	for _, sub := range stores {
+		if err := sub.Writer(ctx).Commit(ctx, sub.CommitMessage(ctx, op, dst, src, msg)); err != nil {
+			switch {
+			case errors.Is(err, store.ErrGitNotInit):
+				debug.Log("skipping git commit - git not initialized")
+			case errors.Is(err, store.ErrGitNothingToCommit):
+				debug.Log("skipping git commit - nothing to commit")
+			default:
+				return fmt.Errorf("failed to commit changes to git: %w", err)
+			}
+		}
 	}
 	return nil
 }
@@ -161,7 +215,8 @@ func (r *Store) Delete(ctx context.Context, name string) error {
 
 // Prune will remove a subtree from the Store
 func (r *Store) Prune(ctx context.Context, tree string) error {
-	for mp := range r.mounts {
+	tree = r.ExpandAlias(tree)
+	for _, mp := range r.MountPoints() {
 		if strings.HasPrefix(mp, tree) {
 			return fmt.Errorf("can not prune subtree with mounts. Unmount first: `gopass mounts remove %s`", mp)
 		}
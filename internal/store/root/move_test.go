@@ -143,6 +143,16 @@ func TestMove(t *testing.T) {
 		"baz",
 		"zab",
 	}, entries)
+
+	// -> move --force zab fast/ => OK, without re-encrypting
+	assert.NoError(t, rs.Move(ctxutil.WithForce(ctx, true), "zab", "fast/"))
+	entries, err = rs.List(ctx, tree.INF)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"bar",
+		"baz",
+		"fast/zab",
+	}, entries)
 }
 
 func TestCopy(t *testing.T) {
@@ -226,3 +236,35 @@ func TestCopy(t *testing.T) {
 		}, entries)
 	})
 }
+
+func TestMoveInterrupted(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	u.Entries = []string{
+		"foo/bar",
+		"foo/baz",
+	}
+	require.NoError(t, u.InitStore(""))
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	assert.NoError(t, rs.Delete(ctx, "foo"))
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// no entry is touched once the operation has been canceled
+	err = rs.Move(cctx, "foo/", "bar/")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "move interrupted after 0 of 2 entries")
+	entries, err := rs.List(ctx, tree.INF)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"foo/bar",
+		"foo/baz",
+	}, entries)
+}
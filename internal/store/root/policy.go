@@ -0,0 +1,21 @@
+package root
+
+import (
+	"context"
+
+	"github.com/gopasspw/gopass/internal/policy"
+)
+
+// PasswordPolicy looks up the password policy for the given secret in the
+// store it belongs to. The prefix of the policy includes the mount point.
+func (r *Store) PasswordPolicy(ctx context.Context, name string) (policy.Policy, bool, error) {
+	store, sub := r.getStore(name)
+	p, found, err := store.PasswordPolicy(ctx, sub)
+	if err != nil || !found {
+		return p, found, err
+	}
+	if alias := store.Alias(); alias != "" {
+		p.Prefix = alias + "/" + p.Prefix
+	}
+	return p, true, nil
+}
@@ -0,0 +1,68 @@
+package root
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, rs.AddMount(ctx, "work", u.StoreDir("work")))
+
+	writePolicy := func(dir, prefix, content string) {
+		fn := filepath.Join(dir, ".gopass", "policy", filepath.FromSlash(prefix))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fn), 0700))
+		require.NoError(t, os.WriteFile(fn, []byte(content), 0600))
+	}
+	writePolicy(u.StoreDir(""), "infra/prod", "min-entropy: 80\n")
+	writePolicy(u.StoreDir(""), "infra/staging/db", "min-length: 8\n")
+	writePolicy(u.StoreDir("work"), "ci", "min-length: 32\n")
+	writePolicy(u.StoreDir(""), "broken", "max-entropy: 1\n")
+
+	_, found, err := rs.PasswordPolicy(ctx, "infra/staging/web")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// policies apply to all subfolders
+	p, found, err := rs.PasswordPolicy(ctx, "infra/prod/legacy/web")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "infra/prod", p.Prefix)
+	assert.Equal(t, 80.0, p.MinEntropy)
+
+	p, found, err = rs.PasswordPolicy(ctx, "infra/staging/db/root")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "infra/staging/db", p.Prefix)
+	assert.Equal(t, 8, p.MinLength)
+
+	p, found, err = rs.PasswordPolicy(ctx, "work/ci/token")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "work/ci", p.Prefix)
+
+	_, _, err = rs.PasswordPolicy(ctx, "broken/foo")
+	assert.Error(t, err)
+
+	// policy files are not secrets
+	names, err := rs.List(ctx, 0)
+	require.NoError(t, err)
+	assert.NotContains(t, names, "infra/prod")
+}
@@ -0,0 +1,32 @@
+package root
+
+import (
+	"context"
+	"path"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
+)
+
+// PasswordRule looks up the password rule for the given secret. Starting
+// with the last one, each element of the name is looked up in the custom
+// rules of the store the secret belongs to and then in the built-in rules.
+// It returns the matching domain and its rule.
+func (r *Store) PasswordRule(ctx context.Context, name string) (string, pwrules.Rule, bool) {
+	sub, name := r.getStore(name)
+	custom, err := sub.PasswordRules(ctx)
+	if err != nil {
+		out.Warningf(ctx, "Ignoring custom password rules: %s", err)
+	}
+	for name != "" && name != "." && name != "/" {
+		d := path.Base(name)
+		if rule, found := custom.Lookup(d); found {
+			return d, rule, true
+		}
+		if rule, found := pwrules.LookupRule(d); found {
+			return d, rule, true
+		}
+		name = path.Dir(name)
+	}
+	return "", pwrules.Rule{}, false
+}
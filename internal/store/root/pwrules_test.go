@@ -0,0 +1,42 @@
+package root
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordRule(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	_, _, found := rs.PasswordRule(ctx, "foo/example.com/user")
+	assert.False(t, found)
+
+	require.NoError(t, os.WriteFile(filepath.Join(u.StoreDir(""), ".pwrules"), []byte("*.example.com minlength: 10; maxlength: 12;\n"), 0600))
+
+	domain, rule, found := rs.PasswordRule(ctx, "foo/login.example.com/user")
+	assert.True(t, found)
+	assert.Equal(t, "login.example.com", domain)
+	assert.Equal(t, 10, rule.Minlen)
+	assert.Equal(t, 12, rule.Maxlen)
+
+	// built-in rules are used as a fallback
+	domain, _, found = rs.PasswordRule(ctx, "web/americanexpress.com")
+	assert.True(t, found)
+	assert.Equal(t, "americanexpress.com", domain)
+}
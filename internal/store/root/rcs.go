@@ -2,10 +2,17 @@ package root
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/internal/store/leaf"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
@@ -53,6 +60,19 @@ func (r *Store) ListRevisions(ctx context.Context, name string) ([]backend.Revis
 	return store.ListRevisions(ctx, name)
 }
 
+// Meta returns when the named entity was created and last changed
+func (r *Store) Meta(ctx context.Context, name string) (backend.Meta, error) {
+	store, name := r.getStore(name)
+	return store.Meta(ctx, name)
+}
+
+// RecordShare records in the history of the store of the secret that it was
+// shared with someone who is not a recipient
+func (r *Store) RecordShare(ctx context.Context, name, recipient string, expires time.Time) error {
+	store, name := r.getStore(name)
+	return store.RecordShare(ctx, name, recipient, expires)
+}
+
 // GetRevision will try to retrieve the given revision from the sync backend
 func (r *Store) GetRevision(ctx context.Context, name, revision string) (context.Context, gopass.Secret, error) {
 	store, name := r.getStore(name)
@@ -60,9 +80,155 @@ func (r *Store) GetRevision(ctx context.Context, name, revision string) (context
 	return ctx, sec, err
 }
 
+// ListDeleted lists the secrets removed from any mount, most recent first
+func (r *Store) ListDeleted(ctx context.Context) ([]backend.Deletion, error) {
+	dels, err := r.store.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range r.MountPoints() {
+		sub := r.mount(alias)
+		if sub == nil {
+			continue
+		}
+		sd, err := sub.ListDeleted(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deleted secrets of %s: %w", alias, err)
+		}
+		dels = append(dels, sd...)
+	}
+	sort.SliceStable(dels, func(i, j int) bool {
+		return dels[i].Date.After(dels[j].Date)
+	})
+	return dels, nil
+}
+
+// Changes returns the changes of the secrets and recipients below prefix in
+// all mounts, oldest first. Renamed secrets are followed, i.e. their changes
+// under the old name are included as well. Mounts without a history are
+// skipped.
+func (r *Store) Changes(ctx context.Context, prefix string) ([]backend.Change, error) {
+	subs := []*leaf.Store{r.store}
+	for _, alias := range r.MountPoints() {
+		if sub := r.mount(alias); sub != nil {
+			subs = append(subs, sub)
+		}
+	}
+
+	var changes []backend.Change
+	var supported bool
+	for _, sub := range subs {
+		sc, err := sub.Changes(ctx)
+		if errors.Is(err, backend.ErrNotSupported) {
+			debug.Log("no history of %q: %s", sub.Alias(), err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		supported = true
+		changes = append(changes, sc...)
+	}
+	if !supported {
+		return nil, fmt.Errorf("no history: %w", backend.ErrNotSupported)
+	}
+
+	// newest first to follow the renames back in time
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Date.After(changes[j].Date)
+	})
+
+	prefix = strings.TrimSuffix(r.ExpandAlias(prefix), "/")
+	followed := make(map[string]bool)
+	res := make([]backend.Change, 0, len(changes))
+	for _, c := range changes {
+		if !followed[c.Name] && !inTree(prefix, c.Name) {
+			continue
+		}
+		if c.Op == backend.ChangeRenamed && c.OldName != "" {
+			followed[c.OldName] = true
+		}
+		res = append(res, c)
+	}
+
+	// oldest first
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, nil
+}
+
+// inTree returns true if name is prefix or below it
+func inTree(prefix, name string) bool {
+	return prefix == "" || name == prefix || strings.HasPrefix(name, prefix+"/")
+}
+
+// Conflicts returns the remote copies of all secrets changed locally and
+// remotely in any mount, by the name of the secret
+func (r *Store) Conflicts(ctx context.Context) (map[string][]string, error) {
+	res, err := r.store.Conflicts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range r.MountPoints() {
+		sub := r.mount(alias)
+		if sub == nil {
+			continue
+		}
+		sc, err := sub.Conflicts(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list conflicts of %s: %w", alias, err)
+		}
+		for name, copies := range sc {
+			res[name] = copies
+		}
+	}
+	return res, nil
+}
+
+// ConflictBase returns the version of the secret both the local version and
+// the remote copy were based on, if known
+func (r *Store) ConflictBase(ctx context.Context, name, copy string) (gopass.Secret, error) {
+	store, name := r.getStore(name)
+	// the copy is always next to the secret
+	_, copy = r.getStore(copy)
+	return store.ConflictBase(ctx, name, copy)
+}
+
+// ResolveConflict replaces the secret with the merged version and removes the
+// remote copies
+func (r *Store) ResolveConflict(ctx context.Context, name string, sec gopass.Byter, copies []string) error {
+	store, name := r.getStore(name)
+	names := make([]string, 0, len(copies))
+	for _, c := range copies {
+		_, c = r.getStore(c)
+		names = append(names, c)
+	}
+	return store.ResolveConflict(ctx, name, sec, names)
+}
+
+// Restore restores a secret from the given revision
+func (r *Store) Restore(ctx context.Context, name, revision string) error {
+	store, name := r.getStore(name)
+	return store.Restore(ctx, name, revision)
+}
+
 // RCSStatus show the git status
 func (r *Store) RCSStatus(ctx context.Context, name string) error {
 	store, name := r.getStore(name)
 	out.Printf(ctx, "Store: %s", store.Path())
 	return store.GitStatus(ctx, name)
 }
+
+// RCSGC packs the git repository of the store
+func (r *Store) RCSGC(ctx context.Context, name string, aggressive bool) error {
+	store, _ := r.getStore(name)
+	return store.GC(ctx, aggressive)
+}
+
+// CompactHistory drops all but the last keep revisions of each secret from
+// the history of the store
+func (r *Store) CompactHistory(ctx context.Context, name string, keep int, dryRun bool) (backend.HistoryCompaction, error) {
+	store, _ := r.getStore(name)
+	return store.CompactHistory(ctx, keep, dryRun)
+}
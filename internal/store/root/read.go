@@ -2,14 +2,61 @@ package root
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
 
 // Get returns the plaintext of a single key
 func (r *Store) Get(ctx context.Context, name string) (gopass.Secret, error) {
 	// forward to substore
-	store, name := r.getStore(name)
-	sec, err := store.Get(ctx, name)
+	sub, sn := r.getStore(name)
+	sec, err := sub.Get(ctx, sn)
+	if errors.Is(err, store.ErrNotFound) {
+		if mp, found := r.shadowedBy(ctx, name); found {
+			return nil, &ShadowedError{Name: name, Mount: mp}
+		}
+	}
 	return sec, err
 }
+
+// Warmup decrypts the first of the given secrets in each mount. Batch
+// operations call it before they start, so the passphrase is asked for right
+// away and not minutes into the run.
+func (r *Store) Warmup(ctx context.Context, names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		mp := r.MountPoint(name)
+		if seen[mp] {
+			continue
+		}
+		seen[mp] = true
+
+		if _, err := r.Get(ctx, name); err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Open returns a reader for the plaintext of a single secret, see
+// leaf.Store.Open
+func (r *Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	sub, sn := r.getStore(name)
+	rc, err := sub.Open(ctx, sn)
+	if errors.Is(err, store.ErrNotFound) {
+		if mp, found := r.shadowedBy(ctx, name); found {
+			return nil, &ShadowedError{Name: name, Mount: mp}
+		}
+	}
+	return rc, err
+}
+
+// Size returns the size of the ciphertext of a single secret in bytes
+func (r *Store) Size(ctx context.Context, name string) (int64, error) {
+	sub, sn := r.getStore(name)
+	return sub.Size(ctx, sn)
+}
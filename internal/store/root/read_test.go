@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 
@@ -25,3 +26,19 @@ func TestGet(t *testing.T) {
 	_, err = rs.Get(ctx, "foo")
 	assert.NoError(t, err)
 }
+
+func TestWarmup(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	assert.NoError(t, rs.Warmup(ctx, nil))
+	assert.NoError(t, rs.Warmup(ctx, []string{"foo", "doesnotexist"}))
+	assert.ErrorIs(t, rs.Warmup(ctx, []string{"doesnotexist", "foo"}), store.ErrNotFound)
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/out"
 	"github.com/gopasspw/gopass/internal/store"
 	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
 
 	"github.com/fatih/color"
@@ -20,16 +22,68 @@ func (r *Store) ListRecipients(ctx context.Context, store string) []string {
 	return sub.Recipients(ctx)
 }
 
+// ListRecipientsAt lists all recipients of the scope starting at the given
+// directory of the given store
+func (r *Store) ListRecipientsAt(ctx context.Context, store, dir string) []string {
+	sub, _ := r.getStore(store)
+	return sub.RecipientsAt(ctx, dir)
+}
+
+// RecipientsFileAt returns the id file the recipients of the given directory
+// or secret of the given store are read from
+func (r *Store) RecipientsFileAt(ctx context.Context, store, dir string) string {
+	sub, _ := r.getStore(store)
+	return sub.RecipientsFileAt(ctx, dir)
+}
+
+// ListSecretsAt lists the secrets encrypted for the recipients of the scope
+// starting at the given directory of the given store
+func (r *Store) ListSecretsAt(ctx context.Context, store, dir string) ([]string, error) {
+	sub, _ := r.getStore(store)
+	return sub.SecretsAt(ctx, dir)
+}
+
+// ListRecipientScopes lists the recipients of each scope of the given store.
+// The scopes are the directories with their own recipients, "" is the store
+// itself.
+func (r *Store) ListRecipientScopes(ctx context.Context, store string) map[string][]string {
+	sub, _ := r.getStore(store)
+	return sub.RecipientsTree(ctx)
+}
+
 // AddRecipient adds a single recipient to the given store
 func (r *Store) AddRecipient(ctx context.Context, store, rec string) error {
+	return r.AddRecipientAt(ctx, store, "", rec)
+}
+
+// AddRecipientAt adds a single recipient to the scope starting at the given
+// directory of the given store
+func (r *Store) AddRecipientAt(ctx context.Context, store, dir, rec string) error {
 	sub, _ := r.getStore(store)
-	return sub.AddRecipient(ctx, rec)
+	return sub.AddRecipientAt(ctx, dir, rec)
 }
 
 // RemoveRecipient removes a single recipient from the given store
 func (r *Store) RemoveRecipient(ctx context.Context, store, rec string) error {
+	return r.RemoveRecipientAt(ctx, store, "", rec)
+}
+
+// RemoveRecipientAt removes a single recipient from the scope starting at the
+// given directory of the given store
+func (r *Store) RemoveRecipientAt(ctx context.Context, store, dir, rec string) error {
 	sub, _ := r.getStore(store)
-	return sub.RemoveRecipient(ctx, rec)
+	return sub.RemoveRecipientAt(ctx, dir, rec)
+}
+
+// ExpiringRecipients lists all recipients of the given store whose keys will
+// expire within the given duration
+func (r *Store) ExpiringRecipients(ctx context.Context, store string, d time.Duration) []string {
+	sub, _ := r.getStore(store)
+	return sub.ExpiringRecipients(ctx, d)
+}
+
+type trustFormatter interface {
+	FormatTrust(ctx context.Context, id string) string
 }
 
 func (r *Store) addRecipient(ctx context.Context, prefix string, root *tree.Root, recp string, pretty bool) error {
@@ -40,6 +94,9 @@ func (r *Store) addRecipient(ctx context.Context, prefix string, root *tree.Root
 		if len(kl) > 0 {
 			if pretty {
 				key = sub.Crypto().FormatKey(ctx, kl[0], "")
+				if tf, ok := sub.Crypto().(trustFormatter); ok && ctxutil.IsVerbose(ctx) {
+					key += " (" + tf.FormatTrust(ctx, kl[0]) + ")"
+				}
 			} else {
 				key = kl[0]
 			}
@@ -55,7 +112,7 @@ func (r *Store) addRecipient(ctx context.Context, prefix string, root *tree.Root
 
 // ImportMissingPublicKeys import missing public keys in any substore
 func (r *Store) ImportMissingPublicKeys(ctx context.Context) error {
-	for alias, sub := range r.mounts {
+	for alias, sub := range r.allMounts() {
 		if err := sub.ImportMissingPublicKeys(ctx); err != nil {
 			out.Errorf(ctx, "[%s] Failed to import missing public keys: %s", alias, err)
 		}
@@ -64,10 +121,28 @@ func (r *Store) ImportMissingPublicKeys(ctx context.Context) error {
 	return r.store.ImportMissingPublicKeys(ctx)
 }
 
+// SyncOwnertrust syncs the ownertrust snapshots of all stores with the local
+// keyring
+func (r *Store) SyncOwnertrust(ctx context.Context) error {
+	for alias, sub := range r.allMounts() {
+		if sub.IsReadOnly() {
+			continue
+		}
+		if err := sub.SyncOwnertrust(ctx); err != nil {
+			out.Errorf(ctx, "[%s] Failed to sync ownertrust: %s", alias, err)
+		}
+	}
+
+	return r.store.SyncOwnertrust(ctx)
+}
+
 // SaveRecipients persists the recipients to disk. Only useful if persist keys is
 // enabled
 func (r *Store) SaveRecipients(ctx context.Context) error {
-	for alias, sub := range r.mounts {
+	for alias, sub := range r.allMounts() {
+		if sub.IsReadOnly() {
+			continue
+		}
 		if err := sub.SaveRecipients(ctx); err != nil {
 			out.Errorf(ctx, "[%s] Failed to save recipients: %s", alias, err)
 		}
@@ -94,7 +169,7 @@ func (r *Store) RecipientsTree(ctx context.Context, pretty bool) (*tree.Root, er
 	mps := r.MountPoints()
 	sort.Sort(store.ByPathLen(mps))
 	for _, alias := range mps {
-		substore := r.mounts[alias]
+		substore := r.mount(alias)
 		if substore == nil {
 			continue
 		}
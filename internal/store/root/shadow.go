@@ -0,0 +1,99 @@
+package root
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// ShadowedError is returned when accessing an entry that is hidden by a more
+// specific mount point. It wraps store.ErrNotFound.
+type ShadowedError struct {
+	Name  string
+	Mount string
+}
+
+func (e *ShadowedError) Error() string {
+	return fmt.Sprintf("entry is shadowed by mount '%s'", e.Mount)
+}
+
+// Unwrap returns store.ErrNotFound
+func (e *ShadowedError) Unwrap() error {
+	return store.ErrNotFound
+}
+
+// Shadowed returns all entries that are hidden by a more specific mount point
+// with that mount point, e.g. the entry work/old-vpn of the root store is
+// shadowed by the mount work.
+func (r *Store) Shadowed(ctx context.Context) (map[string]string, error) {
+	shadowed := make(map[string]string)
+
+	stores := map[string]*leaf.Store{"": r.store}
+	for alias, sub := range r.allMounts() {
+		if sub == nil {
+			continue
+		}
+		stores[alias] = sub
+	}
+
+	for alias, s := range stores {
+		names, err := s.List(leaf.WithNoIgnore(ctx, true), "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %q: %w", alias, err)
+		}
+		for _, name := range names {
+			if mp := r.shadowingMount(alias, name); mp != "" {
+				shadowed[name] = mp
+			}
+		}
+	}
+	debug.Log("shadowed entries: %+v", shadowed)
+
+	return shadowed, nil
+}
+
+// shadowingMount returns the mount point hiding the given entry of the store
+// mounted at alias or an empty string if it isn't shadowed
+func (r *Store) shadowingMount(alias, name string) string {
+	if mp := r.MountPoint(name); mp != alias {
+		return mp
+	}
+	return ""
+}
+
+// shadowedBy returns the mount point hiding the given entry in one of the less
+// specific stores, if any
+func (r *Store) shadowedBy(ctx context.Context, name string) (string, bool) {
+	name = strings.TrimSuffix(r.ExpandAlias(name), "/")
+	mp := r.MountPoint(name)
+	if mp == "" {
+		return "", false
+	}
+
+	aliases := []string{""}
+	for _, alias := range r.MountPoints() {
+		if alias != mp && strings.HasPrefix(mp+"/", alias+"/") {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		sub := r.store
+		if alias != "" {
+			sub = r.mount(alias)
+		}
+		if sub == nil {
+			continue
+		}
+		if sub.Exists(ctx, strings.TrimPrefix(name, alias+"/")) {
+			return mp, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,119 @@
+package root
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/internal/tree"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/gopasspw/gopass/tests/gptest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowed(t *testing.T) {
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	color.NoColor = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, rs.Set(ctx, "work/old-vpn", sec))
+
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, rs.AddMount(ctx, "work", u.StoreDir("work")))
+
+	shadowed, err := rs.Shadowed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"work/old-vpn": "work"}, shadowed)
+
+	// shadowed entries are only shown in the tree
+	st, err := rs.Tree(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, st.List(tree.INF), "work/old-vpn")
+	assert.Contains(t, st.List(tree.INF), "work/foo")
+	assert.Contains(t, st.Format(tree.INF), "old-vpn (shadowed)")
+
+	_, err = rs.Get(ctx, "work/old-vpn")
+	require.Error(t, err)
+	assert.Equal(t, "entry is shadowed by mount 'work'", err.Error())
+	assert.True(t, errors.Is(err, store.ErrNotFound))
+
+	_, err = rs.Get(ctx, "work/missing")
+	assert.Equal(t, store.ErrNotFound, err)
+
+	// fsck reports them
+	report := &leaf.FsckReport{}
+	require.NoError(t, rs.Fsck(leaf.WithFsckReport(ctx, report), ""))
+	var problems []leaf.FsckProblem
+	for _, p := range report.Problems() {
+		if p.Type == leaf.FsckShadowed {
+			problems = append(problems, p)
+		}
+	}
+	assert.Equal(t, []leaf.FsckProblem{{Type: leaf.FsckShadowed, Secret: "work/old-vpn", Mount: "work"}}, problems)
+}
+
+func TestShadowedMountPoint(t *testing.T) {
+	ctx := context.Background()
+	ctx = backend.WithCryptoBackend(ctx, backend.Plain)
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+	color.NoColor = true
+
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	sec := secrets.New()
+	sec.SetPassword("foo")
+	require.NoError(t, rs.Set(ctx, "work", sec))
+	require.NoError(t, rs.Set(ctx, "web", sec))
+	require.NoError(t, rs.Set(ctx, "web/admin", sec))
+
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, rs.AddMount(ctx, "work", u.StoreDir("work")))
+
+	shadowed, err := rs.Shadowed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"work": "work"}, shadowed)
+
+	// the mount point is a folder, the secret of the root store is hidden
+	assert.True(t, rs.IsDir(ctx, "work"))
+	assert.False(t, rs.Exists(ctx, "work"))
+	_, err = rs.Get(ctx, "work")
+	require.Error(t, err)
+	assert.Equal(t, "entry is shadowed by mount 'work'", err.Error())
+
+	// a secret next to a folder of the same name in one store is both
+	assert.True(t, rs.IsDir(ctx, "web"))
+	assert.True(t, rs.Exists(ctx, "web"))
+
+	st, err := rs.Tree(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo", "web", "web/admin", "work/foo"}, st.List(tree.INF))
+	assert.Equal(t, `gopass
+├── foo
+├── web/ (also a secret)
+│   └── admin
+└── work (`+u.StoreDir("work")+`)
+    └── foo
+`, st.Format(tree.INF))
+}
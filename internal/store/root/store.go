@@ -4,17 +4,28 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/backend"
+	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
+	"github.com/gopasspw/gopass/internal/cache"
 	"github.com/gopasspw/gopass/internal/config"
 	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 )
 
 // Store is the public facing password store
 type Store struct {
-	cfg    *config.Config
+	cfg *config.Config
+	// mu guards mounts and lazy
+	mu     sync.Mutex
 	mounts map[string]*leaf.Store
-	store  *leaf.Store
+	// lazy contains the configured mounts that have not been opened yet
+	lazy  map[string]lazyMount
+	store *leaf.Store
+	// plain caches the decrypted secrets of all mounts, it may be nil
+	plain *cache.LRU
 }
 
 // New creates a new store
@@ -26,10 +37,29 @@ func New(cfg *config.Config) *Store {
 		cfg:    cfg,
 		mounts: make(map[string]*leaf.Store, len(cfg.Mounts)),
 	}
+	if cfg.DecryptCache > 0 {
+		r.plain = cache.NewLRU(cfg.DecryptCache)
+	}
 
 	return r
 }
 
+// withMountConfig returns a context with the per mount settings of the given
+// mount set. It must be used when creating the backends of a mount.
+func (r *Store) withMountConfig(ctx context.Context, alias string) context.Context {
+	if home := r.cfg.GnupgHome[alias]; home != "" {
+		ctx = gpg.WithGnupgHome(ctx, home)
+	}
+	ctx = leaf.WithPullStrategy(ctx, r.cfg.GetPullStrategy(alias))
+	ctx = leaf.WithAutoPush(ctx, ctxutil.IsAutoSync(ctx) && r.cfg.IsAutoPush(alias))
+	ctx = leaf.WithAutoSyncInterval(ctx, time.Duration(r.cfg.GetAutoSyncInterval(alias))*time.Second)
+	ctx = leaf.WithReadOnly(ctx, r.cfg.IsReadOnly(alias))
+	ctx = leaf.WithDecryptCache(ctx, r.plain)
+	ctx = leaf.WithCommitTemplate(ctx, r.cfg.CommitMsgTemplate)
+	ctx = leaf.WithCommitHashNames(ctx, r.cfg.CommitMsgHashNames)
+	return leaf.WithSignCommits(ctx, r.cfg.IsSignCommits(alias))
+}
+
 // WithContext populates the context with the store config
 func (r *Store) WithContext(ctx context.Context) context.Context {
 	return r.cfg.WithContext(ctx)
@@ -41,6 +71,13 @@ func (r *Store) Exists(ctx context.Context, name string) bool {
 	return store.Exists(ctx, name)
 }
 
+// CheckWritable returns an error if the entry must not be changed because
+// it's in a read-only mount
+func (r *Store) CheckWritable(name string) error {
+	store, _ := r.getStore(name)
+	return store.CheckWritable()
+}
+
 // IsDir checks if a given key is actually a folder
 func (r *Store) IsDir(ctx context.Context, name string) bool {
 	store, name := r.getStore(name)
@@ -48,10 +85,12 @@ func (r *Store) IsDir(ctx context.Context, name string) bool {
 }
 
 func (r *Store) String() string {
+	r.mu.Lock()
 	ms := make([]string, 0, len(r.mounts))
 	for alias, sub := range r.mounts {
 		ms = append(ms, alias+"="+sub.String())
 	}
+	r.mu.Unlock()
 	path := ""
 	if r.store != nil {
 		path = r.store.Path()
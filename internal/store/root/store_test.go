@@ -10,6 +10,8 @@ import (
 
 	"github.com/gopasspw/gopass/internal/backend"
 	"github.com/gopasspw/gopass/internal/config"
+	"github.com/gopasspw/gopass/internal/store/leaf"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/tests/gptest"
 
 	_ "github.com/gopasspw/gopass/internal/backend/crypto"
@@ -123,6 +125,21 @@ func TestListNested(t *testing.T) {
 	assert.NotNil(t, rs.Storage(ctx, "sub1"))
 }
 
+func TestWithMountConfig(t *testing.T) {
+	ctx := context.Background()
+
+	s := New(&config.Config{
+		AutoPush:      true,
+		MountAutoPush: map[string]bool{"work": false},
+	})
+	assert.True(t, leaf.IsAutoPush(s.withMountConfig(ctx, "")))
+	assert.False(t, leaf.IsAutoPush(s.withMountConfig(ctx, "work")))
+
+	// autosync disables the implicit sync of all mounts
+	ctx = ctxutil.WithAutoSync(ctx, false)
+	assert.False(t, leaf.IsAutoPush(s.withMountConfig(ctx, "")))
+}
+
 func createRootStore(ctx context.Context, u *gptest.Unit) (*Store, error) {
 	ctx = backend.WithCryptoBackendString(ctx, "plain")
 	s := New(
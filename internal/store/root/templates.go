@@ -3,6 +3,7 @@ package root
 import (
 	"context"
 	"fmt"
+	"path"
 	"path/filepath"
 	"sort"
 
@@ -35,7 +36,7 @@ func (r *Store) TemplateTree(ctx context.Context) (*tree.Root, error) {
 	mps := r.MountPoints()
 	sort.Sort(store.ByPathLen(mps))
 	for _, alias := range mps {
-		substore := r.mounts[alias]
+		substore := r.mount(alias)
 		if substore == nil {
 			continue
 		}
@@ -76,3 +77,54 @@ func (r *Store) RemoveTemplate(ctx context.Context, name string) error {
 	store, name := r.getStore(name)
 	return store.RemoveTemplate(ctx, name)
 }
+
+// ListNamedTemplates returns the names of the named templates of all stores
+func (r *Store) ListNamedTemplates(ctx context.Context) []string {
+	names := r.store.ListNamedTemplates(ctx, "")
+	for _, alias := range r.MountPoints() {
+		if sub := r.mount(alias); sub != nil {
+			names = append(names, sub.ListNamedTemplates(ctx, alias)...)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupNamedTemplate returns the named template to create the given secret
+// with. The template is taken from the store of the secret, if it has one
+// with that name, and from the store given by the template name otherwise.
+func (r *Store) LookupNamedTemplate(ctx context.Context, tmpl, name string) (string, []byte, error) {
+	if sub, _ := r.getStore(name); sub.HasNamedTemplate(ctx, tmpl) {
+		content, err := sub.GetNamedTemplate(ctx, tmpl)
+		return path.Join(sub.Alias(), tmpl), content, err
+	}
+	if !r.HasNamedTemplate(ctx, tmpl) {
+		return "", nil, fmt.Errorf("template %q not found: %w", tmpl, store.ErrNotFound)
+	}
+	content, err := r.GetNamedTemplate(ctx, tmpl)
+	return tmpl, content, err
+}
+
+// HasNamedTemplate returns true if the named template exists
+func (r *Store) HasNamedTemplate(ctx context.Context, name string) bool {
+	store, name := r.getStore(name)
+	return store.HasNamedTemplate(ctx, name)
+}
+
+// GetNamedTemplate returns the content of the named template
+func (r *Store) GetNamedTemplate(ctx context.Context, name string) ([]byte, error) {
+	store, name := r.getStore(name)
+	return store.GetNamedTemplate(ctx, name)
+}
+
+// SetNamedTemplate encrypts and (over)writes the named template
+func (r *Store) SetNamedTemplate(ctx context.Context, name string, content []byte) error {
+	store, name := r.getStore(name)
+	return store.SetNamedTemplate(ctx, name, content)
+}
+
+// RemoveNamedTemplate deletes the named template
+func (r *Store) RemoveNamedTemplate(ctx context.Context, name string) error {
+	store, name := r.getStore(name)
+	return store.RemoveNamedTemplate(ctx, name)
+}
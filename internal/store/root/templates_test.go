@@ -45,3 +45,45 @@ func TestTemplate(t *testing.T) {
 	assert.Equal(t, "foobar", string(b))
 	assert.NoError(t, rs.RemoveTemplate(ctx, "foo"))
 }
+
+func TestNamedTemplate(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+	require.NoError(t, u.InitStore("work"))
+	require.NoError(t, rs.AddMount(ctx, "work", u.StoreDir("work")))
+
+	_, _, err = rs.LookupNamedTemplate(ctx, "db", "foo")
+	assert.Error(t, err)
+
+	require.NoError(t, rs.SetNamedTemplate(ctx, "db", []byte("root")))
+	require.NoError(t, rs.SetNamedTemplate(ctx, "work/db", []byte("work")))
+	require.NoError(t, rs.SetNamedTemplate(ctx, "work/web", []byte("web")))
+	assert.Equal(t, []string{"db", "work/db", "work/web"}, rs.ListNamedTemplates(ctx))
+
+	// the template of the store of the secret wins
+	name, b, err := rs.LookupNamedTemplate(ctx, "db", "work/mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "work/db", name)
+	assert.Equal(t, "work", string(b))
+
+	name, b, err = rs.LookupNamedTemplate(ctx, "db", "private/mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "db", name)
+	assert.Equal(t, "root", string(b))
+
+	// templates of other stores can be used by their full name
+	name, b, err = rs.LookupNamedTemplate(ctx, "work/web", "private/nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "work/web", name)
+	assert.Equal(t, "web", string(b))
+
+	assert.NoError(t, rs.RemoveNamedTemplate(ctx, "work/db"))
+	assert.False(t, rs.HasNamedTemplate(ctx, "work/db"))
+}
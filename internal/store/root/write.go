@@ -2,6 +2,7 @@ package root
 
 import (
 	"context"
+	"io"
 
 	"github.com/gopasspw/gopass/pkg/gopass"
 )
@@ -11,3 +12,10 @@ func (r *Store) Set(ctx context.Context, name string, sec gopass.Byter) error {
 	store, name := r.getStore(name)
 	return store.Set(ctx, name, sec)
 }
+
+// SetFrom encrypts the plaintext read from r without holding it in memory,
+// see leaf.Store.SetFrom
+func (r *Store) SetFrom(ctx context.Context, name string, rd io.Reader) error {
+	store, name := r.getStore(name)
+	return store.SetFrom(ctx, name, rd)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/gopasspw/gopass/internal/tree"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets"
 	"github.com/gopasspw/gopass/tests/gptest"
@@ -31,3 +32,39 @@ func TestSet(t *testing.T) {
 	err = rs.Set(ctx, "zab2", sec)
 	assert.NoError(t, err)
 }
+
+func TestSetNormalizesName(t *testing.T) {
+	u := gptest.NewUnitTester(t)
+	defer u.Remove()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithAlwaysYes(ctx, true)
+	ctx = ctxutil.WithHidden(ctx, true)
+
+	rs, err := createRootStore(ctx, u)
+	require.NoError(t, err)
+
+	nfc := "caf\u00e9"
+	nfd := "cafe\u0301"
+
+	sec := &secrets.Plain{}
+	sec.SetPassword("foo")
+	require.NoError(t, rs.Set(ctx, nfd, sec))
+
+	// either form finds the same secret
+	for _, name := range []string{nfc, nfd} {
+		assert.True(t, rs.Exists(ctx, name), name)
+		got, err := rs.Get(ctx, name)
+		require.NoError(t, err, name)
+		assert.Equal(t, "foo", got.Password())
+	}
+	lst, err := rs.List(ctx, tree.INF)
+	require.NoError(t, err)
+	assert.Contains(t, lst, nfc)
+	assert.NotContains(t, lst, nfd)
+
+	require.NoError(t, rs.Move(ctx, nfd, "new"+nfd))
+	assert.True(t, rs.Exists(ctx, "new"+nfc))
+
+	assert.Error(t, rs.Set(ctx, "foo/../../bar", sec))
+}
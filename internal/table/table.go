@@ -0,0 +1,226 @@
+// Package table renders aligned tables with colored rows that fit into the
+// width of the terminal
+package table
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const (
+	// gap is the number of spaces between two columns
+	gap = 2
+	// minWidth is the width a column is never truncated below
+	minWidth = 8
+	ellipsis = "…"
+)
+
+// Status decides the color of a row
+type Status int
+
+const (
+	// None is not colored
+	None Status = iota
+	// OK is green, e.g. a usable key
+	OK
+	// Warning is yellow, e.g. a key that expires soon
+	Warning
+	// Error is red, e.g. an expired or missing key
+	Error
+)
+
+// Truncate selects which cells of a column are shortened if the table is
+// wider than the terminal
+type Truncate int
+
+const (
+	// Keep never truncates the cells, e.g. for fingerprints
+	Keep Truncate = iota
+	// End truncates the end of the cells, e.g. for emails
+	End
+	// Start truncates the start of the cells, e.g. for paths
+	Start
+)
+
+// Column is a column of a table
+type Column struct {
+	// Header is printed in the first line
+	Header   string
+	Truncate Truncate
+}
+
+type row struct {
+	status Status
+	cells  []string
+}
+
+// Table is a table. The zero values of Width and Color print plain aligned
+// text without truncating anything.
+type Table struct {
+	// Width is the maximum width of a line, zero is unlimited
+	Width int
+	// Color colors the rows by their status
+	Color   bool
+	columns []Column
+	rows    []row
+}
+
+// New creates a new table with the given columns
+func New(columns ...Column) *Table {
+	return &Table{
+		columns: columns,
+	}
+}
+
+// ForTerminal creates a new table that fits into the terminal attached to f.
+// Rows are only colored if f is a terminal and colors aren't disabled, e.g.
+// with NO_COLOR.
+func ForTerminal(f *os.File, columns ...Column) *Table {
+	t := New(columns...)
+	if f == nil || !term.IsTerminal(int(f.Fd())) {
+		return t
+	}
+	if w, _, err := term.GetSize(int(f.Fd())); err == nil {
+		t.Width = w
+	}
+	t.Color = !color.NoColor && os.Getenv("NO_COLOR") == ""
+	return t
+}
+
+// AddRow adds a row. Missing cells are left empty, extra cells are dropped.
+func (t *Table) AddRow(status Status, cells ...string) {
+	r := row{
+		status: status,
+		cells:  make([]string, len(t.columns)),
+	}
+	copy(r.cells, cells)
+	t.rows = append(t.rows, r)
+}
+
+// Len returns the number of rows
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Render writes the table. Trailing spaces are omitted.
+func (t *Table) Render(w io.Writer) error {
+	widths := t.widths()
+
+	sb := &strings.Builder{}
+	headers := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		headers[i] = c.Header
+	}
+	t.line(sb, widths, headers, None)
+	for _, r := range t.rows {
+		t.line(sb, widths, r.cells, r.status)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (t *Table) line(sb *strings.Builder, widths []int, cells []string, status Status) {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = truncate(cell, widths[i], t.columns[i].Truncate)
+		if i < len(cells)-1 {
+			cell += strings.Repeat(" ", widths[i]-length(cell))
+		}
+		parts[i] = cell
+	}
+	line := strings.TrimRight(strings.Join(parts, strings.Repeat(" ", gap)), " ")
+	if t.Color {
+		line = colorize(line, status)
+	}
+	sb.WriteString(line)
+	sb.WriteString("\n")
+}
+
+// widths returns the width of each column. If the table is too wide the
+// widest truncatable column is shortened until it fits or all truncatable
+// columns are as short as possible.
+func (t *Table) widths() []int {
+	widths := make([]int, len(t.columns))
+	for i, c := range t.columns {
+		widths[i] = length(c.Header)
+		for _, r := range t.rows {
+			if l := length(r.cells[i]); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	if t.Width < 1 {
+		return widths
+	}
+
+	total := gap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	for total > t.Width {
+		widest := -1
+		for i, c := range t.columns {
+			if c.Truncate == Keep || widths[i] <= minColumnWidth(c) {
+				continue
+			}
+			if widest < 0 || widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// minColumnWidth returns the width a column can be truncated to
+func minColumnWidth(c Column) int {
+	if l := length(c.Header); l > minWidth {
+		return l
+	}
+	return minWidth
+}
+
+// truncate shortens s to width and marks it with an ellipsis
+func truncate(s string, width int, mode Truncate) string {
+	if mode == Keep || length(s) <= width {
+		return s
+	}
+	r := []rune(s)
+	if mode == Start {
+		return ellipsis + string(r[len(r)-width+1:])
+	}
+	return string(r[:width-1]) + ellipsis
+}
+
+// length returns the number of characters of s
+func length(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+func colorize(line string, status Status) string {
+	var c *color.Color
+	switch status {
+	case OK:
+		c = color.New(color.FgGreen)
+	case Warning:
+		c = color.New(color.FgYellow)
+	case Error:
+		c = color.New(color.FgRed)
+	default:
+		return line
+	}
+	// the caller decided to use colors, don't let the global setting
+	// interfere
+	c.EnableColor()
+	return c.Sprint(line)
+}
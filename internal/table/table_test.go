@@ -0,0 +1,80 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTable() *Table {
+	t := New(
+		Column{Header: "SCOPE", Truncate: Start},
+		Column{Header: "FINGERPRINT"},
+		Column{Header: "IDENTITY", Truncate: End},
+		Column{Header: "STATUS"},
+	)
+	t.AddRow(OK, "gopass", "4F2C3C1B8E0A2E6E1D4A7B9C0D1E2F3A4B5C6D7E", "Jane Doe <jane.doe@example.com>", "ok")
+	t.AddRow(Warning, "gopass/work/finance", "1A2B3C4D5E6F708192A3B4C5D6E7F8091A2B3C4D", "John Roe (work) <john.roe@corp.example.org>", "expires in 12 days")
+	t.AddRow(Error, "team/ops", "0xDEADBEEF", "", "missing")
+	t.AddRow(None, "team/ops", "", "Ünïcödé Ñame <unicode@example.com>")
+	return t
+}
+
+func TestRender(t *testing.T) {
+	for _, width := range []int{0, 120, 100, 60} {
+		width := width
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			tbl := testTable()
+			tbl.Width = width
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, tbl.Render(buf))
+			assertGolden(t, fmt.Sprintf("width-%d", width), buf.String())
+		})
+	}
+
+	t.Run("color", func(t *testing.T) {
+		tbl := testTable()
+		tbl.Width = 80
+		tbl.Color = true
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, tbl.Render(buf))
+		assertGolden(t, "color", buf.String())
+	})
+}
+
+func TestAddRow(t *testing.T) {
+	tbl := New(Column{Header: "A"}, Column{Header: "B"})
+	tbl.AddRow(None, "1")
+	tbl.AddRow(None, "1", "2", "3")
+	assert.Equal(t, 2, tbl.Len())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, tbl.Render(buf))
+	assert.Equal(t, "A  B\n1\n1  2\n", buf.String())
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "fingerprint", truncate("fingerprint", 4, Keep))
+	assert.Equal(t, "fin…", truncate("fingerprint", 4, End))
+	assert.Equal(t, "…int", truncate("fingerprint", 4, Start))
+	assert.Equal(t, "short", truncate("short", 8, End))
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	fn := filepath.Join("testdata", name+".golden")
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		require.NoError(t, os.WriteFile(fn, []byte(got), 0644))
+	}
+	want, err := os.ReadFile(fn)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), got, name)
+}
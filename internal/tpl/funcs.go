@@ -6,12 +6,17 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2i"
 	"github.com/gopasspw/gopass/internal/pwschemes/argon2id"
 	"github.com/gopasspw/gopass/internal/pwschemes/bcrypt"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/pwgen"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/jsimonetti/pwscheme/md5crypt"
 	"github.com/jsimonetti/pwscheme/ssha"
 	"github.com/jsimonetti/pwscheme/ssha256"
@@ -33,8 +38,15 @@ const (
 	FuncArgon2i     = "argon2i"
 	FuncArgon2id    = "argon2id"
 	FuncBcrypt      = "bcrypt"
+	FuncGenPw       = "genpw"
+	FuncDate        = "date"
+	FuncPrompt      = "prompt"
 )
 
+// now returns the current time. It's a variable so that tests can use a fixed
+// date.
+var now = time.Now
+
 func md5sum() func(...string) (string, error) {
 	return func(s ...string) (string, error) {
 		return fmt.Sprintf("%x", md5.Sum([]byte(s[0]))), nil
@@ -189,6 +201,79 @@ func getValues(ctx context.Context, kv kvstore) func(...string) ([]string, error
 	}
 }
 
+// genpw generates a password of the given length. The optional charset is
+// either one of alnum (the default), alpha, digits and all or the literal
+// characters to use.
+func genpw() func(int, ...string) (string, error) {
+	return func(length int, cs ...string) (string, error) {
+		if length < 1 {
+			return "", fmt.Errorf("invalid password length %d", length)
+		}
+		if len(cs) < 1 {
+			return pwgen.GeneratePassword(length, false), nil
+		}
+		if len(cs) > 1 {
+			return "", fmt.Errorf("too many arguments")
+		}
+		switch cs[0] {
+		case "", "alnum":
+			return pwgen.GeneratePassword(length, false), nil
+		case "all":
+			return pwgen.GeneratePassword(length, true), nil
+		case "alpha":
+			return pwgen.GeneratePasswordCharset(length, pwgen.CharAlpha), nil
+		case "digits":
+			return pwgen.GeneratePasswordCharset(length, pwgen.Digits), nil
+		default:
+			return pwgen.GeneratePasswordCharset(length, cs[0]), nil
+		}
+	}
+}
+
+// date returns the current date, formatted with the optional Go time layout
+func date() func(...string) (string, error) {
+	return func(s ...string) (string, error) {
+		layout := "2006-01-02"
+		if len(s) > 0 && s[0] != "" {
+			layout = s[0]
+		}
+		return now().Format(layout), nil
+	}
+}
+
+// prompt asks the user for the value of a named variable. Every variable is
+// only asked for once per template, the optional second argument is the
+// default value.
+func prompt(ctx context.Context) func(string, ...string) (string, error) {
+	vars := make(map[string]string)
+	return func(name string, def ...string) (string, error) {
+		if v, found := vars[name]; found {
+			return v, nil
+		}
+		d := ""
+		if len(def) > 0 {
+			d = def[0]
+		}
+		if !ctxutil.IsInteractive(ctx) {
+			if d != "" {
+				vars[name] = d
+				return d, nil
+			}
+			return "", fmt.Errorf("can not ask for %q in non-interactive mode", name)
+		}
+		v, err := termio.AskForString(ctx, name, d)
+		if err != nil {
+			return "", err
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return "", fmt.Errorf("no value for %q", name)
+		}
+		vars[name] = v
+		return v, nil
+	}
+}
+
 func funcMap(ctx context.Context, kv kvstore) template.FuncMap {
 	return template.FuncMap{
 		FuncGet:         get(ctx, kv),
@@ -204,5 +289,8 @@ func funcMap(ctx context.Context, kv kvstore) template.FuncMap {
 		FuncArgon2i:     argon2iFunc(),
 		FuncArgon2id:    argon2idFunc(),
 		FuncBcrypt:      bcryptFunc(),
+		FuncGenPw:       genpw(),
+		FuncDate:        date(),
+		FuncPrompt:      prompt(ctx),
 	}
 }
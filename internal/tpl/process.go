@@ -0,0 +1,148 @@
+package tpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// These functions are available to config file templates rendered by
+// Process
+const (
+	FuncGopass     = "gopass"
+	FuncGopassKey  = "gopassKey"
+	FuncGopassJSON = "gopassJSON"
+)
+
+// What Process does with missing secrets and keys, like the missingkey
+// option of text/template
+const (
+	MissingError = "error"
+	MissingZero  = "zero"
+)
+
+// Process renders a config file template, e.g. a docker-compose env file,
+// with the secrets inlined. A missing secret or key fails the rendering,
+// unless missing is MissingZero. Then it's replaced by an empty string.
+func Process(ctx context.Context, name string, tpl []byte, kv kvstore, missing string) ([]byte, error) {
+	switch missing {
+	case "", MissingError:
+		missing = MissingError
+	case MissingZero:
+	default:
+		return nil, fmt.Errorf("unknown missingkey option %q. Must be error or zero", missing)
+	}
+	zero := missing == MissingZero
+
+	funcs := funcMap(ctx, kv)
+	// the get functions render the error instead of failing and the template
+	// might be read from stdin, so there is nobody to prompt
+	for _, f := range []string{FuncGet, FuncGetPassword, FuncGetValue, FuncGetValues, FuncPrompt} {
+		delete(funcs, f)
+	}
+	funcs[FuncGopass] = gopassPassword(ctx, kv, zero)
+	funcs[FuncGopassKey] = gopassKey(ctx, kv, zero)
+	funcs[FuncGopassJSON] = gopassJSON(ctx, kv, zero)
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=" + missing).Parse(string(tpl))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// lookup returns the secret or nil if it doesn't exist and zero is set
+func lookup(ctx context.Context, kv kvstore, name string, zero bool) (gopass.Secret, error) {
+	if kv == nil {
+		return nil, fmt.Errorf("KV is nil")
+	}
+	sec, err := kv.Get(ctx, name)
+	if err != nil {
+		if zero && errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	return sec, nil
+}
+
+// gopassPassword returns the password, i.e. the first line, of a secret or
+// the value of a single key, like gopassKey, if one is given. The key
+// "password" is the password, like in gopassJSON.
+func gopassPassword(ctx context.Context, kv kvstore, zero bool) func(string, ...string) (string, error) {
+	getKey := gopassKey(ctx, kv, zero)
+	return func(name string, keys ...string) (string, error) {
+		switch {
+		case len(keys) > 1:
+			return "", fmt.Errorf("%s takes a secret and at most one key, got %d keys", FuncGopass, len(keys))
+		case len(keys) == 1 && keys[0] != "password":
+			return getKey(name, keys[0])
+		}
+		sec, err := lookup(ctx, kv, name, zero)
+		if err != nil || sec == nil {
+			return "", err
+		}
+		return sec.Password(), nil
+	}
+}
+
+// gopassKey returns the value of a single key of a secret
+func gopassKey(ctx context.Context, kv kvstore, zero bool) func(string, string) (string, error) {
+	return func(name, key string) (string, error) {
+		sec, err := lookup(ctx, kv, name, zero)
+		if err != nil || sec == nil {
+			return "", err
+		}
+		v, found := sec.Get(key)
+		if !found && !zero {
+			return "", fmt.Errorf("secret %q has no key %q", name, key)
+		}
+		return v, nil
+	}
+}
+
+// gopassJSON returns the password and all keys of a secret as a JSON object.
+// Keys with multiple values are lists.
+func gopassJSON(ctx context.Context, kv kvstore, zero bool) func(string) (string, error) {
+	return func(name string) (string, error) {
+		sec, err := lookup(ctx, kv, name, zero)
+		if err != nil {
+			return "", err
+		}
+		if sec == nil {
+			return "{}", nil
+		}
+
+		obj := make(map[string]interface{}, len(sec.Keys())+1)
+		for _, k := range sec.Keys() {
+			vs, found := sec.Values(k)
+			if !found {
+				continue
+			}
+			if len(vs) == 1 {
+				obj[k] = vs[0]
+				continue
+			}
+			obj[k] = vs
+		}
+		// the password always wins over a key of the same name
+		obj["password"] = sec.Password()
+
+		buf, err := json.Marshal(obj)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode secret %q: %w", name, err)
+		}
+		return string(buf), nil
+	}
+}
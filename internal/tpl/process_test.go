@@ -0,0 +1,107 @@
+package tpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/store"
+	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type processMock map[string]string
+
+func (p processMock) Get(ctx context.Context, name string) (gopass.Secret, error) {
+	content, found := p[name]
+	if !found {
+		return nil, store.ErrNotFound
+	}
+	return secparse.Parse([]byte(content))
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+
+	kv := processMock{
+		"infra/db": "s3cret\n---\nuser: admin\nport: 5432\n",
+		"bare":     "only",
+	}
+
+	for _, tc := range []struct {
+		name     string
+		tpl      string
+		missing  string
+		out      string
+		notFound bool
+	}{
+		{
+			name: "password",
+			tpl:  `DB_PASSWORD={{ gopass "infra/db" }}`,
+			out:  "DB_PASSWORD=s3cret",
+		},
+		{
+			name: "key",
+			tpl:  `DB_USER={{ gopassKey "infra/db" "user" }}:{{ gopassKey "infra/db" "port" }}`,
+			out:  "DB_USER=admin:5432",
+		},
+		{
+			name: "password with a key",
+			tpl:  `{{ gopass "infra/db" "user" }}:{{ gopass "infra/db" "password" }}`,
+			out:  "admin:s3cret",
+		},
+		{
+			name: "too many keys",
+			tpl:  `{{ gopass "infra/db" "user" "port" }}`,
+		},
+		{
+			name: "json",
+			tpl:  `{{ gopassJSON "infra/db" }} {{ gopassJSON "bare" }}`,
+			out:  `{"password":"s3cret","port":"5432","user":"admin"} {"password":"only"}`,
+		},
+		{
+			name: "other functions",
+			tpl:  `{{ gopass "bare" | md5sum }}`,
+			out:  "6299ba2cbd9661a5e3872b715521cd6a",
+		},
+		{
+			name:     "missing secret",
+			tpl:      `DB_PASSWORD={{ gopass "infra/web" }}`,
+			notFound: true,
+		},
+		{
+			name: "missing key",
+			tpl:  `{{ gopassKey "infra/db" "host" }}`,
+		},
+		{
+			name:    "missing secret zero",
+			tpl:     `A={{ gopass "infra/web" }} B={{ gopassKey "bare" "user" }} C={{ gopassJSON "nothing" }}`,
+			missing: MissingZero,
+			out:     "A= B= C={}",
+		},
+		{
+			name: "get functions are not available",
+			tpl:  `{{ getpw "infra/db" }}`,
+		},
+		{
+			name:    "unknown option",
+			tpl:     `foo`,
+			missing: "default",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			buf, err := Process(ctx, "test", []byte(tc.tpl), kv, tc.missing)
+			if tc.out == "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.notFound, errors.Is(err, store.ErrNotFound))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(buf))
+		})
+	}
+}
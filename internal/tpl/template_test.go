@@ -2,11 +2,17 @@ package tpl
 
 import (
 	"context"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 	"github.com/gopasspw/gopass/pkg/gopass"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/gopasspw/gopass/pkg/termio"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type kvMock struct{}
@@ -113,3 +119,54 @@ func TestVars(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateFuncs(t *testing.T) {
+	ctx := context.Background()
+
+	oldNow := now
+	now = func() time.Time { return time.Date(2021, 12, 24, 18, 0, 0, 0, time.UTC) }
+	defer func() { now = oldNow }()
+
+	for _, tc := range []struct {
+		Template string
+		Match    string
+	}{
+		{Template: `{{ genpw 24 }}`, Match: `^[a-zA-Z0-9]{24}$`},
+		{Template: `{{ genpw 8 "digits" }}`, Match: `^[0-9]{8}$`},
+		{Template: `{{ genpw 12 "alpha" }}`, Match: `^[a-zA-Z]{12}$`},
+		{Template: `{{ genpw 6 "ab" }}`, Match: `^[ab]{6}$`},
+		{Template: `{{ date }}`, Match: `^2021-12-24$`},
+		{Template: `{{ date "02.01.2006" }}`, Match: `^24\.12\.2021$`},
+		{Template: `{{ prompt "user" "admin" }}@{{ .Dir }}`, Match: `^admin@db$`},
+	} {
+		tc := tc
+		t.Run(tc.Template, func(t *testing.T) {
+			buf, err := Execute(ctx, tc.Template, "db/prod", nil, kvMock{})
+			require.NoError(t, err)
+			assert.Regexp(t, tc.Match, string(buf))
+		})
+	}
+
+	for _, tc := range []string{
+		`{{ genpw 0 }}`,
+		`{{ genpw "24" }}`,
+		`{{ genpw 24 "alnum" "all" }}`,
+		`{{ prompt "user" }}`,
+	} {
+		_, err := Execute(ctx, tc, "db/prod", nil, kvMock{})
+		assert.Error(t, err, tc)
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	ctx := context.Background()
+	ctx = ctxutil.WithInteractive(ctx, true)
+
+	termio.Stdin = strings.NewReader("alice\n")
+	defer func() { termio.Stdin = os.Stdin }()
+
+	// every variable is only asked for once
+	buf, err := Execute(ctx, `{{ prompt "user" }}:{{ prompt "user" }}`, "db/prod", nil, kvMock{})
+	require.NoError(t, err)
+	assert.Equal(t, "alice:alice", string(buf))
+}
@@ -0,0 +1,68 @@
+package tree
+
+// Entry is the machine readable representation of a node, e.g. for
+// gopass ls --format json. Its schema must be kept stable.
+type Entry struct {
+	Name string `json:"name"`
+	// Type is one of dir, secret, mount or alias
+	Type string `json:"type"`
+	// Path is the location of a mount or the target of an alias, Link the
+	// target of a secret linking to another one
+	Path     string `json:"path,omitempty"`
+	Link     string `json:"link,omitempty"`
+	Template bool   `json:"template,omitempty"`
+	ReadOnly bool   `json:"readonly,omitempty"`
+	Shadowed bool   `json:"shadowed,omitempty"`
+	// Secret marks a folder that is a secret as well
+	Secret   bool    `json:"secret,omitempty"`
+	Children []Entry `json:"children,omitempty"`
+}
+
+// Entry returns the tree as nested entries. Folders below maxDepth are
+// included without their content.
+func (r *Root) Entry(maxDepth int) Entry {
+	return Entry{
+		Name:     r.Name,
+		Type:     "dir",
+		Children: r.Subtree.entries(maxDepth, 1),
+	}
+}
+
+func (t *Tree) entries(maxDepth, curDepth int) []Entry {
+	if t == nil || len(t.Nodes) < 1 {
+		return nil
+	}
+	es := make([]Entry, 0, len(t.Nodes))
+	for _, n := range t.Nodes {
+		es = append(es, n.entry(maxDepth, curDepth))
+	}
+	return es
+}
+
+func (n *Node) entry(maxDepth, curDepth int) Entry {
+	e := Entry{
+		Name:     n.Name,
+		Type:     "secret",
+		Template: n.Template,
+		Shadowed: n.Shadowed,
+	}
+	switch {
+	case n.Alias:
+		e.Type = "alias"
+		e.Path = n.Path
+	case n.Link:
+		e.Link = n.Path
+	case n.Mount:
+		e.Type = "mount"
+		e.Path = n.Path
+		e.ReadOnly = n.ReadOnly
+	case n.Type == "dir":
+		e.Type = "dir"
+		e.Secret = n.Leaf
+	}
+	if maxDepth > INF && curDepth > maxDepth {
+		return e
+	}
+	e.Children = n.Subtree.entries(maxDepth, curDepth+1)
+	return e
+}
@@ -0,0 +1,53 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntry(t *testing.T) {
+	r := New("gopass")
+	r.AddTemplate("foo")
+	r.AddFile("foo/bar/baz", "")
+	r.AddFile("zab", "")
+	r.AddLink("foo/bar/ref", "zab")
+	r.AddReadOnlyMount("mnt/m1", "/tmp/m1")
+	r.AddFile("mnt/m1/foo", "")
+	r.AddAlias("web", "foo/bar/baz")
+
+	want := Entry{
+		Name: "gopass",
+		Type: "dir",
+		Children: []Entry{
+			{Name: "foo", Type: "dir", Template: true, Children: []Entry{
+				{Name: "bar", Type: "dir", Children: []Entry{
+					{Name: "baz", Type: "secret"},
+					{Name: "ref", Type: "secret", Link: "zab"},
+				}},
+			}},
+			{Name: "mnt", Type: "dir", Children: []Entry{
+				{Name: "m1", Type: "mount", Path: "/tmp/m1", ReadOnly: true, Children: []Entry{
+					{Name: "foo", Type: "secret"},
+				}},
+			}},
+			{Name: "web", Type: "alias", Path: "foo/bar/baz"},
+			{Name: "zab", Type: "secret"},
+		},
+	}
+	assert.Equal(t, want, r.Entry(INF))
+
+	// folders below the limit are included without their content
+	assert.Equal(t, Entry{
+		Name: "gopass",
+		Type: "dir",
+		Children: []Entry{
+			{Name: "foo", Type: "dir", Template: true},
+			{Name: "mnt", Type: "dir"},
+			{Name: "web", Type: "alias", Path: "foo/bar/baz"},
+			{Name: "zab", Type: "secret"},
+		},
+	}, r.Entry(0))
+
+	assert.Equal(t, Entry{Name: "empty", Type: "dir"}, New("empty").Entry(INF))
+}
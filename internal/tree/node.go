@@ -2,6 +2,7 @@ package tree
 
 import (
 	"bytes"
+	"fmt"
 )
 
 // Node is a tree node
@@ -10,8 +11,15 @@ type Node struct {
 	Type     string
 	Template bool
 	Mount    bool
-	Path     string
-	Subtree  *Tree
+	ReadOnly bool
+	Shadowed bool
+	Alias    bool
+	Link     bool
+	// Leaf marks a folder that is a secret as well, e.g. aws next to
+	// aws/root
+	Leaf    bool
+	Path    string
+	Subtree *Tree
 }
 
 const (
@@ -42,6 +50,9 @@ func (n Node) Equals(other Node) bool {
 	if n.Type != other.Type {
 		return false
 	}
+	if n.Leaf != other.Leaf {
+		return false
+	}
 	if n.Subtree != nil {
 		if other.Subtree == nil {
 			return false
@@ -82,8 +93,20 @@ func (n *Node) format(prefix string, last bool, maxDepth, curDepth int) string {
 
 	// any mount will be colored and include the on-disk path
 	switch {
+	case n.Alias:
+		_, _ = out.WriteString(colAlias(n.Name) + " -> " + n.Path)
+	case n.Link:
+		_, _ = out.WriteString(n.Name + " -> " + colLink(n.Path))
+	case n.Mount && n.ReadOnly:
+		_, _ = out.WriteString(colMount(n.Name+" ("+n.Path+")") + " " + colRO("ro"))
 	case n.Mount:
 		_, _ = out.WriteString(colMount(n.Name + " (" + n.Path + ")"))
+	case n.Shadowed && n.Type == "dir":
+		_, _ = out.WriteString(colShadow(n.Name + sep))
+	case n.Shadowed:
+		_, _ = out.WriteString(colShadow(n.Name + " (shadowed)"))
+	case n.Type == "dir" && n.Leaf:
+		_, _ = out.WriteString(colDir(n.Name+sep) + " " + colLeaf("(also a secret)"))
 	case n.Type == "dir":
 		_, _ = out.WriteString(colDir(n.Name + sep))
 	default:
@@ -93,10 +116,19 @@ func (n *Node) format(prefix string, last bool, maxDepth, curDepth int) string {
 	if n.Template {
 		_, _ = out.WriteString(" " + colTpl("(template)"))
 	}
+	// summarize folders whose content is cut off. Their shadowed content
+	// is not shown, so the folder itself is marked.
+	cut := maxDepth > INF && curDepth > maxDepth
+	switch {
+	case cut && n.Subtree != nil && n.Shadowed:
+		_, _ = out.WriteString(" " + colShadow("(shadowed)"))
+	case cut && n.Subtree != nil:
+		_, _ = out.WriteString(" " + entries(n.Len()))
+	}
 	// finish this output
 	_, _ = out.WriteString("\n")
 
-	if n.Subtree == nil {
+	if n.Subtree == nil || cut {
 		return out.String()
 	}
 
@@ -108,12 +140,32 @@ func (n *Node) format(prefix string, last bool, maxDepth, curDepth int) string {
 	return out.String()
 }
 
+// entries returns the number of entries for a folder summary
+func entries(n int) string {
+	if n == 1 {
+		return "(1 entry)"
+	}
+	return fmt.Sprintf("(%d entries)", n)
+}
+
+// isSecret returns true if the node is a plain secret, i.e. not a mount point
+// or a folder that only contains a template, nor a hidden entry
+func (n *Node) isSecret() bool {
+	return n.Type == "file" && !n.Mount && !n.Template && !n.Shadowed && !n.Alias
+}
+
 // Len returns the length of this subtree
 func (n *Node) Len() int {
+	if n.Shadowed || n.Alias {
+		return 0
+	}
 	if n.Type == "file" {
 		return 1
 	}
 	var l int
+	if n.Leaf {
+		l++
+	}
 	for _, t := range n.Subtree.Nodes {
 		l += t.Len()
 	}
@@ -124,6 +176,10 @@ func (n *Node) list(prefix string, maxDepth, curDepth int, files bool) []string
 	if maxDepth >= 0 && curDepth > maxDepth {
 		return nil
 	}
+	// shadowed entries can't be accessed and aliases only point to entries
+	if n.Shadowed || n.Alias {
+		return nil
+	}
 
 	if prefix != "" {
 		prefix += sep
@@ -134,13 +190,19 @@ func (n *Node) list(prefix string, maxDepth, curDepth int, files bool) []string
 	if n.Type == "file" && files {
 		// we return the file
 		return []string{prefix}
-	} else if curDepth == maxDepth && n.Type != "file" {
+	}
+
+	out := make([]string, 0, n.Len())
+	// a folder that is a secret as well is listed before its content
+	if n.Leaf && files {
+		out = append(out, prefix)
+	}
+	if curDepth == maxDepth && n.Type != "file" {
 		// otherwise if we are "at the bottom" and it's not a file
 		// we return the directory name with a separator at the end
-		return []string{prefix + sep}
+		return append(out, prefix+sep)
 	}
 
-	out := make([]string, 0, n.Len())
 	// if we don't have subitems, then it's a leaf and we return
 	// (notice that this is what ends the recursion when maxDepth is set to -1)
 	if n.Subtree == nil {
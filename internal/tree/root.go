@@ -17,9 +17,14 @@ const (
 )
 
 var (
-	colMount = color.New(color.FgCyan, color.Bold).SprintfFunc()
-	colDir   = color.New(color.FgBlue, color.Bold).SprintfFunc()
-	colTpl   = color.New(color.FgGreen, color.Bold).SprintfFunc()
+	colMount  = color.New(color.FgCyan, color.Bold).SprintfFunc()
+	colDir    = color.New(color.FgBlue, color.Bold).SprintfFunc()
+	colTpl    = color.New(color.FgGreen, color.Bold).SprintfFunc()
+	colShadow = color.New(color.FgHiBlack).SprintfFunc()
+	colRO     = color.New(color.FgYellow).SprintfFunc()
+	colAlias  = color.New(color.FgMagenta).SprintfFunc()
+	colLink   = color.New(color.FgMagenta).SprintfFunc()
+	colLeaf   = color.New(color.FgYellow).SprintfFunc()
 	// sep is intentionally NOT platform-agnostic. This is used for the CLI output
 	// and should always be a regular slash.
 	sep = "/"
@@ -42,40 +47,80 @@ func New(name string) *Root {
 
 // AddFile adds a new file to the tree
 func (r *Root) AddFile(path string, _ string) error {
-	return r.insert(path, false, "")
+	return r.insert(path, Node{})
 }
 
 // AddMount adds a new mount point to the tree
 func (r *Root) AddMount(path, dest string) error {
-	return r.insert(path, false, dest)
+	return r.insert(path, Node{Mount: true, Path: dest})
+}
+
+// AddReadOnlyMount adds a new mount point that can't be changed to the tree
+func (r *Root) AddReadOnlyMount(path, dest string) error {
+	return r.insert(path, Node{Mount: true, ReadOnly: true, Path: dest})
 }
 
 // AddTemplate adds a template to the tree
 func (r *Root) AddTemplate(path string) error {
-	return r.insert(path, true, "")
+	return r.insert(path, Node{Template: true})
+}
+
+// AddShadowed adds a file that is hidden by a mount point to the tree. It's
+// only shown when formatting the tree, but never listed. Add it after the
+// mount points.
+func (r *Root) AddShadowed(path string) error {
+	return r.insert(path, Node{Shadowed: true})
+}
+
+// AddLink adds a secret linking to the secret target to the tree. It's
+// listed like any other secret.
+func (r *Root) AddLink(path, target string) error {
+	return r.insert(path, Node{Link: true, Path: target})
+}
+
+// AddAlias adds an alias for the path prefix target to the top level of the
+// tree. It's only shown when formatting the tree, but never listed.
+func (r *Root) AddAlias(name, target string) error {
+	_, err := r.Subtree.Insert(&Node{Name: name, Type: "file", Alias: true, Path: target})
+	return err
 }
 
-func (r *Root) insert(path string, template bool, mountPath string) error {
+// insert adds the last element of path with the properties of leaf. Missing
+// folders are created on the way. A secret and a folder of the same name end
+// up in one folder node marked as Leaf, no matter which one is added first.
+func (r *Root) insert(path string, leaf Node) error {
+	secret := !leaf.Mount && !leaf.Template && !leaf.Shadowed
 	t := r.Subtree
 	p := strings.Split(path, "/")
 	for i, e := range p {
 		n := &Node{
-			Name:    e,
-			Type:    "dir",
-			Subtree: NewTree(),
+			Name:     e,
+			Type:     "dir",
+			Shadowed: leaf.Shadowed,
+			Subtree:  NewTree(),
 		}
 		if i == len(p)-1 {
 			n.Type = "file"
 			n.Subtree = nil
-			n.Template = template
-			if mountPath != "" {
+			n.Template = leaf.Template
+			if leaf.Mount && leaf.Path != "" {
 				n.Mount = true
-				n.Path = mountPath
+				n.ReadOnly = leaf.ReadOnly
+				n.Path = leaf.Path
 			}
+			if leaf.Link {
+				n.Link = true
+				n.Path = leaf.Path
+			}
+		}
+		node, err := t.Insert(n)
+		// a secret named like an existing folder
+		if err != nil && i == len(p)-1 && secret && node.Type == "dir" && !node.Mount {
+			node.Leaf = true
 		}
-		node, _ := t.Insert(n)
 		// do we need to extend an existing subtree?
 		if i < len(p)-1 && node.Subtree == nil {
+			node.Leaf = node.isSecret()
 			node.Subtree = NewTree()
 			node.Type = "dir"
 		}
@@ -25,13 +25,14 @@ func TestRoot(t *testing.T) {
 │       └── zab
 └── mnt/
     └── m1 (/tmp/m1)
-        └── foo/
+        └── foo/ (also a secret)
             └── bar
 `, r.Format(INF))
 
 	assert.Equal(t, []string{
 		"foo/bar/baz",
 		"foo/bar/zab",
+		"mnt/m1/foo",
 		"mnt/m1/foo/bar",
 	}, r.List(INF))
 	assert.Equal(t, []string{
@@ -44,7 +45,7 @@ func TestRoot(t *testing.T) {
 	f, err := r.FindFolder("mnt/m1")
 	assert.NoError(t, err)
 	assert.Equal(t, `gopass
-└── foo/
+└── foo/ (also a secret)
     └── bar
 `, f.Format(INF))
 }
@@ -76,3 +77,141 @@ func TestMountShadow(t *testing.T) {
 	_, err := r.FindFolder("mnt/m1")
 	assert.Error(t, err)
 }
+
+func TestShadowedEntries(t *testing.T) {
+	color.NoColor = true
+
+	r := New("gopass")
+	r.AddFile("foo/bar", "")
+	r.AddMount("foo", "/tmp/m1")
+	r.AddFile("foo/baz", "")
+	r.AddShadowed("foo/old/vpn")
+	r.AddShadowed("foo/zab")
+	// entries of the mount win
+	r.AddShadowed("foo/baz")
+	assert.Equal(t, `gopass
+└── foo (/tmp/m1)
+    ├── baz
+    ├── old/
+    │   └── vpn (shadowed)
+    └── zab (shadowed)
+`, r.Format(INF))
+	assert.Equal(t, `gopass
+└── foo (/tmp/m1)
+    ├── baz
+    ├── old/ (shadowed)
+    └── zab (shadowed)
+`, r.Format(1))
+	assert.Equal(t, `gopass
+└── foo (/tmp/m1) (1 entry)
+`, r.Format(0))
+
+	assert.Equal(t, []string{"foo/baz"}, r.List(INF))
+	assert.Equal(t, []string{"foo/"}, r.ListFolders(INF))
+	assert.Equal(t, 1, r.Len())
+}
+
+func TestDualNodes(t *testing.T) {
+	color.NoColor = true
+
+	for _, order := range [][]string{{"aws", "aws/root"}, {"aws/root", "aws"}} {
+		r := New("gopass")
+		for _, f := range order {
+			r.AddFile(f, "")
+		}
+		r.AddFile("gcp", "")
+		assert.Equal(t, `gopass
+├── aws/ (also a secret)
+│   └── root
+└── gcp
+`, r.Format(INF), order)
+		assert.Equal(t, []string{"aws", "aws/root", "gcp"}, r.List(INF))
+		assert.Equal(t, []string{"aws", "aws/", "gcp"}, r.List(0))
+		assert.Equal(t, []string{"aws/"}, r.ListFolders(INF))
+		assert.Equal(t, 3, r.Len())
+
+		e := r.Entry(INF)
+		assert.Equal(t, "dir", e.Children[0].Type)
+		assert.True(t, e.Children[0].Secret)
+		assert.False(t, e.Children[1].Secret)
+	}
+
+	// the secret of the root store is hidden by the mount of the same name
+	r := New("gopass")
+	r.AddMount("work", "/tmp/work")
+	r.AddFile("work/vpn", "")
+	r.AddShadowed("work")
+	assert.Equal(t, `gopass
+└── work (/tmp/work)
+    └── vpn
+`, r.Format(INF))
+	assert.Equal(t, []string{"work/vpn"}, r.List(INF))
+
+	// templates don't make a folder a secret
+	r = New("gopass")
+	r.AddFile("foo/bar", "")
+	r.AddTemplate("foo")
+	assert.Equal(t, []string{"foo/bar"}, r.List(INF))
+}
+
+func TestReadOnlyMount(t *testing.T) {
+	color.NoColor = true
+
+	r := New("gopass")
+	r.AddMount("foo", "/tmp/m1")
+	r.AddFile("foo/bar", "")
+	r.AddReadOnlyMount("team", "/tmp/team")
+	r.AddFile("team/db", "")
+	assert.Equal(t, `gopass
+├── foo (/tmp/m1)
+│   └── bar
+└── team (/tmp/team) ro
+    └── db
+`, r.Format(INF))
+	assert.Equal(t, []string{"foo/bar", "team/db"}, r.List(INF))
+}
+
+func TestAliases(t *testing.T) {
+	color.NoColor = true
+
+	r := New("gopass")
+	r.AddFile("customers/acme/production/db", "")
+	assert.NoError(t, r.AddAlias("prod", "customers/acme/production"))
+	assert.Error(t, r.AddAlias("customers", "foo"))
+	assert.Equal(t, `gopass
+├── customers/
+│   └── acme/
+│       └── production/
+│           └── db
+└── prod -> customers/acme/production
+`, r.Format(INF))
+	assert.Equal(t, []string{"customers/acme/production/db"}, r.List(INF))
+	assert.Equal(t, 1, r.Len())
+}
+
+func TestFormatDepth(t *testing.T) {
+	color.NoColor = true
+
+	r := New("gopass")
+	r.AddFile("foo/bar/baz", "")
+	r.AddFile("foo/bar/zab", "")
+	r.AddFile("foo/oof", "")
+	r.AddMount("mnt/m1", "/tmp/m1")
+	r.AddFile("mnt/m1/foo", "")
+	r.AddFile("top", "")
+
+	assert.Equal(t, `gopass
+├── foo/ (3 entries)
+├── mnt/ (1 entry)
+└── top
+`, r.Format(0))
+
+	assert.Equal(t, `gopass
+├── foo/
+│   ├── bar/ (2 entries)
+│   └── oof
+├── mnt/
+│   └── m1 (/tmp/m1) (1 entry)
+└── top
+`, r.Format(1))
+}
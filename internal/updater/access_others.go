@@ -3,8 +3,28 @@
 
 package updater
 
-import "golang.org/x/sys/unix"
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
 
 func canWrite(path string) error {
 	return unix.Access(path, unix.W_OK)
 }
+
+// checkOwner refuses to replace files owned by root unless we're root. Those
+// are usually installed by a package manager, even if the user was able to
+// write them.
+func checkOwner(path string, fi os.FileInfo) error {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if st.Uid == 0 && os.Geteuid() != 0 {
+		return fmt.Errorf("%q is owned by root. Use your package manager or run gopass update as root", path)
+	}
+	return nil
+}
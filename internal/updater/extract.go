@@ -14,6 +14,9 @@ import (
 	"github.com/gopasspw/gopass/pkg/debug"
 )
 
+// extractFile extracts the gopass binary from the archive and replaces dest
+// with it. The binary is written to a temporary file in the same directory
+// first, so dest is either the old or the new binary, never a partial one.
 func extractFile(buf []byte, filename, dest string) error {
 	var mode = os.FileMode(0755)
 
@@ -23,20 +26,34 @@ func extractFile(buf []byte, filename, dest string) error {
 		mode = fi.Mode()
 	}
 
-	if err := os.Remove(dest); err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("unable to remove destination file: %q", err)
-		}
-	}
-
-	dfh, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	dfh, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".update-")
 	if err != nil {
-		return fmt.Errorf("failed to open file %q: %w", dest, err)
+		return fmt.Errorf("failed to create a temporary file next to %q: %w", dest, err)
 	}
+	tmp := dfh.Name()
 	defer func() {
 		_ = dfh.Close()
+		// only left if the update failed
+		_ = os.Remove(tmp)
 	}()
 
+	if err := extractArchive(buf, filename, dfh); err != nil {
+		return err
+	}
+	if err := dfh.Close(); err != nil {
+		return fmt.Errorf("failed to write %q: %w", tmp, err)
+	}
+	if err := os.Chmod(tmp, mode.Perm()); err != nil {
+		return fmt.Errorf("failed to set the mode of %q: %w", tmp, err)
+	}
+
+	if err := replaceFile(tmp, dest); err != nil {
+		return fmt.Errorf("failed to replace %q: %w", dest, err)
+	}
+	return nil
+}
+
+func extractArchive(buf []byte, filename string, dfh io.Writer) error {
 	var rd io.Reader = bytes.NewReader(buf)
 	switch filepath.Ext(filename) {
 	case ".gz":
@@ -44,17 +61,17 @@ func extractFile(buf []byte, filename, dest string) error {
 		if err != nil {
 			return err
 		}
-		return extractTar(gzr, dfh, dest)
+		return extractTar(gzr, dfh)
 	case ".bz2":
-		return extractTar(bzip2.NewReader(rd), dfh, dest)
+		return extractTar(bzip2.NewReader(rd), dfh)
 	case ".zip":
-		return extractZip(buf, dfh, dest)
+		return extractZip(buf, dfh)
 	default:
 		return fmt.Errorf("unsupported")
 	}
 }
 
-func extractZip(buf []byte, dfh io.WriteCloser, dest string) error {
+func extractZip(buf []byte, dfh io.Writer) error {
 	zrd, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
 	if err != nil {
 		return err
@@ -72,19 +89,17 @@ func extractZip(buf []byte, dfh io.WriteCloser, dest string) error {
 
 		n, err := io.Copy(dfh, file)
 		if err != nil {
-			dfh.Close()
-			os.Remove(dest)
 			return fmt.Errorf("failed to read gopass.exe from zip file: %w", err)
 		}
 		// success
-		debug.Log("wrote %d bytes to %v", n, dest)
+		debug.Log("extracted %d bytes", n)
 		return nil
 	}
 
 	return fmt.Errorf("file not found in archive")
 }
 
-func extractTar(rd io.Reader, dfh io.WriteCloser, dest string) error {
+func extractTar(rd io.Reader, dfh io.Writer) error {
 	tarReader := tar.NewReader(rd)
 	for {
 		header, err := tarReader.Next()
@@ -104,12 +119,10 @@ func extractTar(rd io.Reader, dfh io.WriteCloser, dest string) error {
 
 		n, err := io.Copy(dfh, tarReader)
 		if err != nil {
-			dfh.Close()
-			os.Remove(dest)
 			return fmt.Errorf("failed to read gopass from tar file: %w", err)
 		}
 		// success
-		debug.Log("wrote %d bytes to %v", n, dest)
+		debug.Log("extracted %d bytes", n)
 		return nil
 	}
 	return fmt.Errorf("file not found in archive")
@@ -0,0 +1,57 @@
+package updater
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFile(t *testing.T) {
+	td := t.TempDir()
+	dest := filepath.Join(td, "gopass")
+	require.NoError(t, os.WriteFile(dest, []byte("old"), 0750))
+
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	body := []byte("new")
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "gopass",
+		Mode:     0600,
+		Size:     int64(len(body)),
+	}))
+	_, err := tw.Write(body)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+
+	// a broken archive keeps the old binary
+	assert.Error(t, extractFile([]byte("garbage"), "gopass-linux-amd64.tar.gz", dest))
+	got, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(got))
+
+	require.NoError(t, extractFile(buf.Bytes(), "gopass-linux-amd64.tar.gz", dest))
+	got, err = os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(got))
+
+	fi, err := os.Stat(dest)
+	require.NoError(t, err)
+	if fi.Mode().Perm() != 0666 {
+		// no mode bits on windows
+		assert.Equal(t, os.FileMode(0750), fi.Mode().Perm())
+	}
+
+	// no temporary files are left
+	files, err := os.ReadDir(td)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -77,7 +77,7 @@ func FetchLatestRelease(ctx context.Context) (Release, error) {
 	url := fmt.Sprintf(BaseURL, owner, repo)
 	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return Release{}, nil
+		return Release{}, err
 	}
 
 	// pin to API version 3 to avoid breaking our structs
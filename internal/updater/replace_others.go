@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package updater
+
+import "os"
+
+// replaceFile atomically replaces dest with src. A running binary can be
+// replaced by a rename.
+func replaceFile(src, dest string) error {
+	return os.Rename(src, dest)
+}
@@ -0,0 +1,33 @@
+//go:build windows
+// +build windows
+
+package updater
+
+import (
+	"os"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+// replaceFile replaces dest with src. Windows can't overwrite or remove a
+// running binary, but it can rename it. So the old binary is moved out of the
+// way first and removed by the next update.
+func replaceFile(src, dest string) error {
+	old := dest + ".old"
+	if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+		debug.Log("failed to remove the previous binary %q: %s", old, err)
+	}
+
+	if err := os.Rename(dest, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(src, dest); err != nil {
+		// restore the old binary
+		_ = os.Rename(old, dest)
+		return err
+	}
+
+	// fails while the old binary is running
+	_ = os.Remove(old)
+	return nil
+}
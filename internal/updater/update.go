@@ -31,14 +31,13 @@ func Update(ctx context.Context, currentVersion semver.Version) error {
 		return err
 	}
 
-	rel, err := FetchLatestRelease(ctx)
+	rel, available, err := Check(ctx, currentVersion)
 	if err != nil {
 		return err
 	}
 
-	debug.Log("Current: %s - Latest: %s", currentVersion.String(), rel.Version.String())
 	// binary is newer or equal to the latest release -> nothing to do
-	if currentVersion.GTE(rel.Version) {
+	if !available {
 		out.Printf(ctx, "gopass is up to date (%s)", currentVersion.String())
 		if gfu := os.Getenv("GOPASS_FORCE_UPDATE"); gfu == "" {
 			return nil
@@ -101,3 +100,15 @@ func Update(ctx context.Context, currentVersion semver.Version) error {
 	debug.Log("success!")
 	return nil
 }
+
+// Check fetches the latest release and returns true if it's newer than the
+// current version
+func Check(ctx context.Context, currentVersion semver.Version) (Release, bool, error) {
+	rel, err := FetchLatestRelease(ctx)
+	if err != nil {
+		return rel, false, err
+	}
+
+	debug.Log("Current: %s - Latest: %s", currentVersion.String(), rel.Version.String())
+	return rel, currentVersion.LT(rel.Version), nil
+}
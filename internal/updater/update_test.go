@@ -47,6 +47,18 @@ func TestIsUpdateable(t *testing.T) {
 			},
 			ok: true,
 		},
+		{
+			name: "disabled",
+			pre: func() error {
+				return os.Setenv("GOPASS_NO_UPDATE", "true")
+			},
+			exec: func(context.Context) (string, error) {
+				return "action.test", nil
+			},
+			post: func() error {
+				return os.Unsetenv("GOPASS_NO_UPDATE")
+			},
+		},
 		{
 			name: "force update",
 			pre: func() error {
@@ -18,6 +18,11 @@ func IsUpdateable(ctx context.Context) error {
 	}
 
 	debug.Log("File: %s", fn)
+	// packagers can disable the self update
+	if nu := os.Getenv("GOPASS_NO_UPDATE"); nu != "" {
+		return fmt.Errorf("updates are disabled by GOPASS_NO_UPDATE")
+	}
+
 	// check if this is a test binary
 	if strings.HasSuffix(filepath.Base(fn), ".test") {
 		return nil
@@ -45,9 +50,20 @@ func IsUpdateable(ctx context.Context) error {
 	if err := canWrite(fn); err != nil {
 		return fmt.Errorf("can not write %q: %w", fn, err)
 	}
+	if err := checkOwner(fn, fi); err != nil {
+		return err
+	}
 
-	// no need to check the directory since we'll be writing to the destination file directly
-	return nil
+	// the new binary is written next to the old one and renamed over it
+	dir := filepath.Dir(fn)
+	di, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if err := canWrite(dir); err != nil {
+		return fmt.Errorf("can not write %q: %w", dir, err)
+	}
+	return checkOwner(dir, di)
 }
 
 var executable = func(ctx context.Context) (string, error) {
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"sort"
+	"strings"
+)
+
+// Data returns the data of a Vault secret for the password, notes and keys
+// of a gopass secret. Dotted keys become nested maps, e.g. db.host, unless
+// they conflict with another key. Keys with multiple values become lists.
+func Data(password, notes string, values map[string][]string) map[string]interface{} {
+	data := make(map[string]interface{}, len(values)+2)
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	// shorter keys first, so a plain key wins over nested ones
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		vs := values[k]
+		if len(vs) < 1 {
+			continue
+		}
+		var v interface{} = vs[0]
+		if len(vs) > 1 {
+			l := make([]interface{}, 0, len(vs))
+			for _, s := range vs {
+				l = append(l, s)
+			}
+			v = l
+		}
+		if !setNested(data, strings.Split(k, "."), v) {
+			data[k] = v
+		}
+	}
+
+	if password != "" {
+		data["password"] = password
+	}
+	if notes != "" {
+		data["notes"] = notes
+	}
+	return data
+}
+
+// setNested sets the value at the path of keys, creating the missing maps.
+// It returns false if the path conflicts with an existing value.
+func setNested(m map[string]interface{}, path []string, v interface{}) bool {
+	for _, k := range path {
+		if k == "" {
+			return false
+		}
+	}
+	for i, k := range path {
+		if i == len(path)-1 {
+			if _, found := m[k]; found {
+				return false
+			}
+			m[k] = v
+			return true
+		}
+		next, found := m[k]
+		if !found {
+			nm := map[string]interface{}{}
+			m[k] = nm
+			m = nm
+			continue
+		}
+		nm, ok := next.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		m = nm
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+// Package vault is a minimal client of the HashiCorp Vault KV v2 HTTP API.
+// It lists, reads and writes the latest version of secrets in a KV mount.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// DefaultRate is the default number of requests per second
+	DefaultRate = 20
+	// maxRetries is the number of retries of rate limited or failed requests
+	maxRetries = 5
+)
+
+// pageSize is the number of keys requested per LIST request. Servers that
+// don't support pagination return all keys at once.
+var pageSize = 500
+
+// ErrNotFound is returned for secrets that don't exist or whose latest
+// version was deleted
+var ErrNotFound = errors.New("secret not found")
+
+// Client talks to a single KV v2 mount
+type Client struct {
+	addr      string
+	token     string
+	namespace string
+	mount     string
+	hc        *http.Client
+
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+	// backoff is the first delay before retrying a request, doubled on every retry
+	backoff time.Duration
+}
+
+// New creates a client for the KV v2 mount at addr. The token is used for all
+// requests, rate limits the requests per second, 0 disables the limit.
+func New(addr, token, namespace, mount string, rate int) (*Client, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("no Vault address, set VAULT_ADDR or use --addr")
+	}
+	if _, err := url.Parse(addr); err != nil {
+		return nil, fmt.Errorf("invalid Vault address %q: %w", addr, err)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no Vault token, set VAULT_TOKEN")
+	}
+	mount = strings.Trim(mount, "/")
+	if mount == "" {
+		return nil, fmt.Errorf("no KV mount")
+	}
+
+	c := &Client{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     mount,
+		hc:        &http.Client{Timeout: 30 * time.Second},
+		backoff:   time.Second,
+	}
+	if rate > 0 {
+		c.interval = time.Second / time.Duration(rate)
+	}
+	return c, nil
+}
+
+// SplitPath splits a path like secret/team/app into the mount, secret, and
+// the folder inside of it, team/app
+func SplitPath(p string) (string, string) {
+	p = strings.Trim(p, "/")
+	if i := strings.Index(p, "/"); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return p, ""
+}
+
+// List returns the keys in the folder. Folders end with a slash.
+func (c *Client) List(ctx context.Context, dir string) ([]string, error) {
+	var keys []string
+	after := ""
+	for {
+		q := url.Values{}
+		q.Set("limit", strconv.Itoa(pageSize))
+		if after != "" {
+			q.Set("after", after)
+		}
+
+		var res struct {
+			Data struct {
+				Keys []string `json:"keys"`
+			} `json:"data"`
+		}
+		if err := c.do(ctx, "LIST", c.url("metadata", dir)+"?"+q.Encode(), nil, &res); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				// an empty folder
+				return keys, nil
+			}
+			return nil, err
+		}
+
+		var n int
+		for _, k := range res.Data.Keys {
+			// servers without pagination return the same keys again
+			if after != "" && k <= after {
+				continue
+			}
+			keys = append(keys, k)
+			n++
+		}
+		if len(res.Data.Keys) != pageSize || n == 0 {
+			break
+		}
+		after = keys[len(keys)-1]
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Walk calls fn for every secret below dir, in lexical order. Secrets up to
+// and including after are skipped, so an interrupted walk can be resumed.
+func (c *Client) Walk(ctx context.Context, dir, after string, fn func(p string) error) error {
+	dir = strings.Trim(dir, "/")
+	keys, err := c.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		p := k
+		if dir != "" {
+			p = dir + "/" + k
+		}
+		if strings.HasSuffix(k, "/") {
+			// skip folders which only contain secrets up to after
+			if after != "" && p < after && !strings.HasPrefix(after, p) {
+				continue
+			}
+			if err := c.Walk(ctx, p, after, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if after != "" && p <= after {
+			continue
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read returns the data of the latest version of the secret
+func (c *Client) Read(ctx context.Context, p string) (map[string]interface{}, error) {
+	var res struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.url("data", p), nil, &res); err != nil {
+		return nil, err
+	}
+	if res.Data.Data == nil {
+		return nil, ErrNotFound
+	}
+	return res.Data.Data, nil
+}
+
+// Write stores the data as a new version of the secret
+func (c *Client) Write(ctx context.Context, p string, data map[string]interface{}) error {
+	buf, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, c.url("data", p), buf, nil)
+}
+
+func (c *Client) url(kind, p string) string {
+	p = strings.Trim(p, "/")
+	u := c.addr + "/v1/" + c.mount + "/" + kind
+	if p != "" {
+		u += "/" + p
+	}
+	return u
+}
+
+// do sends a request and decodes the response into res. Rate limited
+// requests and server errors are retried.
+func (c *Client) do(ctx context.Context, method, u string, body []byte, res interface{}) error {
+	delay := c.backoff
+	for i := 0; ; i++ {
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+
+		status, retryAfter, err := c.send(ctx, method, u, body, res)
+		if err == nil {
+			return nil
+		}
+		retry := status == http.StatusTooManyRequests || status >= 500
+		if !retry || i >= maxRetries {
+			return err
+		}
+
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		// only the path is logged, the token is a header
+		debug.Log("%s %s failed with status %d, retrying in %s", method, u, status, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, u string, body []byte, res interface{}) (int, time.Duration, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	req.Header.Set("X-Vault-Request", "true")
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return resp.StatusCode, 0, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var retryAfter time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			retryAfter = time.Duration(s) * time.Second
+		}
+		return resp.StatusCode, retryAfter, fmt.Errorf("%s %s: %s", method, strings.TrimPrefix(u, c.addr), apiError(resp))
+	}
+	if res == nil || resp.StatusCode == http.StatusNoContent {
+		return resp.StatusCode, 0, nil
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(res); err != nil {
+		return resp.StatusCode, 0, fmt.Errorf("failed to decode response of %s: %w", strings.TrimPrefix(u, c.addr), err)
+	}
+	return resp.StatusCode, 0, nil
+}
+
+// wait blocks until the next request is allowed by the rate limit
+func (c *Client) wait(ctx context.Context) error {
+	if c.interval <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	next := c.last.Add(c.interval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.last = next
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(next)):
+		return nil
+	}
+}
+
+// apiError returns the errors reported by Vault
+func apiError(resp *http.Response) string {
+	var res struct {
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&res); err != nil || len(res.Errors) < 1 {
+		return resp.Status
+	}
+	return resp.Status + ": " + strings.Join(res.Errors, ", ")
+}
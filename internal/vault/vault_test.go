@@ -0,0 +1,238 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKV is a KV v2 mount named secret. LIST requests are paginated.
+type fakeKV struct {
+	sync.Mutex
+	data     map[string]map[string]interface{}
+	throttle int
+	requests int
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	f.requests++
+
+	if r.Header.Get("X-Vault-Token") != "t0ken" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	if f.throttle > 0 {
+		f.throttle--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	switch {
+	case r.Method == "LIST" && strings.HasPrefix(r.URL.Path, "/v1/secret/metadata"):
+		f.list(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		d, found := f.data[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": d}})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		var req struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.data[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")] = req.Data
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeKV) list(w http.ResponseWriter, r *http.Request) {
+	dir := strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata"), "/")
+	if dir != "" {
+		dir += "/"
+	}
+	seen := map[string]bool{}
+	for p := range f.data {
+		if !strings.HasPrefix(p, dir) {
+			continue
+		}
+		k := strings.TrimPrefix(p, dir)
+		if i := strings.Index(k, "/"); i >= 0 {
+			k = k[:i+1]
+		}
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		if after := r.URL.Query().Get("after"); after == "" || k > after {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) < 1 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	sort.Strings(keys)
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < len(keys) {
+		keys = keys[:limit]
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"keys": keys}})
+}
+
+func newFake(t *testing.T) (*fakeKV, *Client) {
+	t.Helper()
+
+	f := &fakeKV{data: map[string]map[string]interface{}{
+		"team/app/db":    {"password": "s3cret"},
+		"team/app/api":   {"token": "abc"},
+		"team/web":       {"password": "w3b"},
+		"team/web/admin": {"password": "adm1n"},
+		"other":          {"password": "x"},
+	}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, "t0ken", "", "secret", 0)
+	require.NoError(t, err)
+	c.backoff = time.Millisecond
+	return f, c
+}
+
+func TestWalk(t *testing.T) {
+	ctx := context.Background()
+	oldPageSize := pageSize
+	pageSize = 1
+	defer func() {
+		pageSize = oldPageSize
+	}()
+
+	_, c := newFake(t)
+
+	var paths []string
+	require.NoError(t, c.Walk(ctx, "team", "", func(p string) error {
+		paths = append(paths, p)
+		return nil
+	}))
+	assert.Equal(t, []string{"team/app/api", "team/app/db", "team/web", "team/web/admin"}, paths)
+
+	// resume after the last secret
+	paths = nil
+	require.NoError(t, c.Walk(ctx, "", "team/app/db", func(p string) error {
+		paths = append(paths, p)
+		return nil
+	}))
+	assert.Equal(t, []string{"team/web", "team/web/admin"}, paths)
+
+	// an empty folder
+	require.NoError(t, c.Walk(ctx, "nothing", "", func(p string) error {
+		t.Errorf("unexpected secret %s", p)
+		return nil
+	}))
+}
+
+func TestReadWrite(t *testing.T) {
+	ctx := context.Background()
+	f, c := newFake(t)
+
+	data, err := c.Read(ctx, "team/app/db")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "s3cret"}, data)
+
+	_, err = c.Read(ctx, "team/app/nope")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, c.Write(ctx, "team/new", map[string]interface{}{"password": "n3w"}))
+	assert.Equal(t, map[string]interface{}{"password": "n3w"}, f.data["team/new"])
+
+	// rate limited requests are retried
+	f.throttle = 2
+	data, err = c.Read(ctx, "team/web")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "w3b"}, data)
+
+	c.token = "wrong"
+	_, err = c.Read(ctx, "team/web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+	assert.NotContains(t, err.Error(), "wrong")
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	_, c := newFake(t)
+	c.interval = 20 * time.Millisecond
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := c.Read(ctx, "other")
+		require.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) >= 60*time.Millisecond)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("", "t0ken", "", "secret", 0)
+	assert.Error(t, err)
+	_, err = New("http://localhost:8200", "", "", "secret", 0)
+	assert.Error(t, err)
+	_, err = New("http://localhost:8200", "t0ken", "", "/", 0)
+	assert.Error(t, err)
+}
+
+func TestSplitPath(t *testing.T) {
+	for in, want := range map[string][2]string{
+		"secret/":          {"secret", ""},
+		"secret":           {"secret", ""},
+		"/secret/team/app": {"secret", "team/app"},
+	} {
+		mount, dir := SplitPath(in)
+		assert.Equal(t, want, [2]string{mount, dir}, in)
+	}
+}
+
+func TestData(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"password": "s3cret",
+		"notes":    "some notes",
+		"user":     "admin",
+		"db": map[string]interface{}{
+			"host": "db1",
+			"replica": map[string]interface{}{
+				"host": "db2",
+			},
+		},
+		"hosts":       []interface{}{"a", "b"},
+		"url":         "https://example.com",
+		"url.comment": "conflicts with url",
+		"trailing.":   "x",
+	}, Data("s3cret", "some notes", map[string][]string{
+		"user":            {"admin"},
+		"db.host":         {"db1"},
+		"db.replica.host": {"db2"},
+		"hosts":           {"a", "b"},
+		"url":             {"https://example.com"},
+		"url.comment":     {"conflicts with url"},
+		"trailing.":       {"x"},
+		"none":            {},
+	}))
+}
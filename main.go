@@ -11,7 +11,6 @@ import (
 	"io"
 	"log"
 	"os"
-	"os/signal"
 	"runtime"
 	"runtime/pprof"
 	"sort"
@@ -21,8 +20,11 @@ import (
 	_ "github.com/gopasspw/gopass/internal/backend/crypto"
 	"github.com/gopasspw/gopass/internal/backend/crypto/gpg"
 	_ "github.com/gopasspw/gopass/internal/backend/storage"
+	"github.com/gopasspw/gopass/internal/interrupt"
 	"github.com/gopasspw/gopass/internal/queue"
+	"github.com/gopasspw/gopass/pkg/clipboard"
 	"github.com/gopasspw/gopass/pkg/ctxutil"
+	"github.com/gopasspw/gopass/pkg/debug"
 	"github.com/gopasspw/gopass/pkg/protect"
 
 	"github.com/blang/semver/v4"
@@ -68,21 +70,12 @@ func main() {
 	}
 	ctx := context.Background()
 
-	// trap Ctrl+C and call cancel on the context
-	ctx, cancel := context.WithCancel(ctx)
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt)
-	defer func() {
-		signal.Stop(sigChan)
-		cancel()
-	}()
-	go func() {
-		select {
-		case <-sigChan:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
+	// trap Ctrl+C and call cancel on the context. This kills all running
+	// gpg and git processes and makes gopass finish quickly, while still
+	// leaving the stores consistent. A second Ctrl+C exits right away,
+	// unless the REPL or a command run by gopass has redirected it.
+	ctx, stop := interrupt.Notify(ctx)
+	defer stop()
 
 	cli.ErrWriter = errorWriter{
 		out: colorable.NewColorableStderr(),
@@ -116,6 +109,7 @@ func setupApp(ctx context.Context, sv semver.Version) (context.Context, *cli.App
 
 	// set config values
 	ctx = initContext(ctx, cfg)
+	ctx = ctxutil.WithVersion(ctx, sv.String())
 
 	// initialize action handlers
 	action, err := ap.New(cfg, sv)
@@ -128,7 +122,7 @@ func setupApp(ctx context.Context, sv semver.Version) (context.Context, *cli.App
 	if !cfg.AutoImport {
 		ctx = ctxutil.WithImportFunc(ctx, termio.AskForKeyImport)
 	}
-	ctx = leaf.WithFsckFunc(ctx, termio.AskForConfirmation)
+	ctx = leaf.WithFsckFunc(ctx, termio.Confirm)
 
 	app := cli.NewApp()
 
@@ -141,7 +135,19 @@ func setupApp(ctx context.Context, sv semver.Version) (context.Context, *cli.App
 		action.Complete(c)
 	}
 
+	app.CustomAppHelpTemplate = cli.AppHelpTemplate + "\nEXIT CODES:\n" + ap.ExitCodesHelp()
+	app.ExitErrHandler = ap.ExitErrHandler
+
 	app.Flags = ap.ShowFlags()
+	app.Before = func(c *cli.Context) error {
+		switch {
+		case c.Bool("debug"):
+			debug.Enable(debug.LevelDebug)
+		case c.Bool("verbose"):
+			debug.Enable(debug.LevelInfo)
+		}
+		return nil
+	}
 	app.Action = func(c *cli.Context) error {
 		if err := action.IsInitialized(c); err != nil {
 			return err
@@ -161,13 +167,17 @@ func getCommands(action *ap.Action, app *cli.App) []*cli.Command {
 	cmds := []*cli.Command{
 		{
 			Name:  "completion",
-			Usage: "Bash and ZSH completion",
+			Usage: "Bash, ZSH and fish completion",
 			Description: "" +
-				"Source the output of this command with bash or zsh to get auto completion",
+				"Source the output of this command with bash, zsh or fish to get auto completion. " +
+				"Secret names are completed by calling back into gopass, the keys of a secret for --key " +
+				"only if completion.decrypt is enabled.",
 			Subcommands: []*cli.Command{{
-				Name:   "bash",
-				Usage:  "Source for auto completion in bash",
-				Action: action.CompletionBash,
+				Name:  "bash",
+				Usage: "Source for auto completion in bash",
+				Action: func(c *cli.Context) error {
+					return action.CompletionBash(app)
+				},
 			}, {
 				Name:  "zsh",
 				Usage: "Source for auto completion in zsh",
@@ -186,6 +196,17 @@ func getCommands(action *ap.Action, app *cli.App) []*cli.Command {
 				Action: func(c *cli.Context) error {
 					return action.CompletionOpenBSDKsh(app)
 				},
+			}, {
+				Name:   "mounts",
+				Usage:  "Print the mount points, called by the completion scripts",
+				Hidden: true,
+				Action: action.CompletionMounts,
+			}, {
+				Name:      "keys",
+				Usage:     "Print the keys of a secret if completion.decrypt is enabled, called by the completion scripts",
+				ArgsUsage: "[secret]",
+				Hidden:    true,
+				Action:    action.CompletionKeys,
 			}},
 		},
 	}
@@ -242,6 +263,15 @@ func initContext(ctx context.Context, cfg *config.Config) context.Context {
 	// always trust
 	ctx = gpg.WithAlwaysTrust(ctx, true)
 
+	if cfg.Keyserver != "" {
+		ctx = gpg.WithKeyserver(ctx, cfg.Keyserver)
+	}
+	ctx = gpg.WithUpdateStartupTTY(ctx, cfg.UpdateStartupTTY)
+
+	if cfg.Clipboard != "" {
+		ctx = clipboard.WithHelper(ctx, cfg.Clipboard)
+	}
+
 	// check recipients conflicts with always trust, make sure it's not enabled
 	// when always trust is
 	if gpg.IsAlwaysTrust(ctx) {
@@ -260,6 +290,24 @@ func initContext(ctx context.Context, cfg *config.Config) context.Context {
 		ctx = ctxutil.WithInteractive(ctx, false)
 		ctx = ctxutil.WithStdin(ctx, true)
 	}
+	// there is nobody to answer if stdin is closed or /dev/null
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		ctx = ctxutil.WithInteractive(ctx, false)
+	}
+
+	// the askpass program answers questions without a terminal
+	if termio.HasAskpass() {
+		ctx = ctxutil.WithInteractive(ctx, true)
+	}
+	if os.Getenv("GOPASS_NO_INTERACTION") != "" {
+		ctx = ctxutil.WithInteractive(ctx, false)
+	}
+	if os.Getenv("GOPASS_FORCE") != "" {
+		ctx = ctxutil.WithConfirm(ctx, true)
+	}
+	if os.Getenv("GOPASS_OFFLINE") != "" {
+		ctx = ctxutil.WithOffline(ctx, true)
+	}
 
 	// disable colored output on windows since cmd.exe doesn't support ANSI color
 	// codes. Other terminal may do, but until we can figure that out better
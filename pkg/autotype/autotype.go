@@ -0,0 +1,213 @@
+// Package autotype types secrets into the focused window by sending
+// synthetic key strokes. It uses xdotool on X11 and wtype or ydotool on
+// Wayland. What is typed is controlled by the autotype sequence of a secret,
+// e.g. "autotype: user :tab pass :enter".
+package autotype
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// DefaultSequence is used for secrets without an autotype key. It only
+// types the password.
+const DefaultSequence = "pass"
+
+var (
+	// ErrSSH is returned when running in an SSH session. The keystrokes
+	// would end up on the remote machine's display, if any.
+	ErrSSH = errors.New("autotype is disabled in SSH sessions")
+	// ErrNoHelper is returned if none of the helper binaries is installed
+	ErrNoHelper = errors.New("no autotype helper found")
+)
+
+// Step is a single step of an autotype sequence. Either some text is typed,
+// a special key is pressed or nothing is done for some time.
+type Step struct {
+	Text  string
+	Key   string
+	Delay time.Duration
+}
+
+// special keys that can be used in a sequence, e.g. ":tab"
+var specialKeys = map[string]string{
+	"tab":   "Tab",
+	"enter": "Return",
+	"space": "space",
+}
+
+// Sequence parses the autotype sequence of the given secret. The sequence
+// is a list of words which are either "pass" for the password, a special
+// key (":tab", ":enter", ":space"), ":delay" to wait a second or the name
+// of another key of the secret.
+func Sequence(sec gopass.Secret) ([]Step, error) {
+	seq, found := sec.Get("autotype")
+	if !found || strings.TrimSpace(seq) == "" {
+		seq = DefaultSequence
+	}
+
+	steps := make([]Step, 0, 4)
+	for _, tok := range strings.Fields(seq) {
+		if strings.HasPrefix(tok, ":") {
+			name := strings.ToLower(strings.TrimPrefix(tok, ":"))
+			if name == "delay" {
+				steps = append(steps, Step{Delay: time.Second})
+				continue
+			}
+			key, found := specialKeys[name]
+			if !found {
+				return nil, fmt.Errorf("unknown key %q in autotype sequence", tok)
+			}
+			steps = append(steps, Step{Key: key})
+			continue
+		}
+		if tok == "pass" {
+			steps = append(steps, Step{Text: sec.Password()})
+			continue
+		}
+		val, found := sec.Get(tok)
+		if !found {
+			return nil, fmt.Errorf("key %q of the autotype sequence not found", tok)
+		}
+		steps = append(steps, Step{Text: val})
+	}
+	return steps, nil
+}
+
+// Type types the given steps into the focused window after a countdown
+// (see WithCountdown) to allow the user to focus the target field
+func Type(ctx context.Context, name string, steps []Step) error {
+	if IsSSH() {
+		return ErrSSH
+	}
+	t, err := detect()
+	if err != nil {
+		return err
+	}
+	debug.Log("using %s to type %s", t.name, name)
+
+	for left := GetCountdown(ctx); left > 0; left -= time.Second {
+		out.Printf(ctx, "Typing %s in %d seconds. Focus the target field now ...", name, int(left.Seconds()))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	for _, step := range steps {
+		if err := t.run(ctx, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSSH returns true if gopass is running in an SSH session
+func IsSSH() bool {
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY"} {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// typer is an external command that sends keystrokes
+type typer struct {
+	name string
+	// typeArgs type the text read from stdin
+	typeArgs []string
+	// keyArgs press a special key
+	keyArgs func(string) []string
+}
+
+// ydotool uses Linux input event codes
+var ydotoolKeys = map[string]string{
+	"Tab":    "15",
+	"Return": "28",
+	"space":  "57",
+}
+
+var typers = map[string]typer{
+	"xdotool": {
+		name:     "xdotool",
+		typeArgs: []string{"type", "--clearmodifiers", "--file", "-"},
+		keyArgs: func(key string) []string {
+			return []string{"key", "--clearmodifiers", key}
+		},
+	},
+	"wtype": {
+		name:     "wtype",
+		typeArgs: []string{"-"},
+		keyArgs: func(key string) []string {
+			return []string{"-k", key}
+		},
+	},
+	"ydotool": {
+		name:     "ydotool",
+		typeArgs: []string{"type", "--file", "-"},
+		keyArgs: func(key string) []string {
+			code := ydotoolKeys[key]
+			return []string{"key", code + ":1", code + ":0"}
+		},
+	},
+}
+
+// detect returns the helper for the current session type
+func detect() (*typer, error) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("autotype is not supported on %s", runtime.GOOS)
+	}
+
+	candidates := []string{"ydotool"}
+	switch {
+	case os.Getenv("XDG_SESSION_TYPE") == "wayland" || os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = []string{"wtype", "ydotool"}
+	case os.Getenv("DISPLAY") != "":
+		candidates = []string{"xdotool", "ydotool"}
+	}
+
+	for _, name := range candidates {
+		if _, err := exec.LookPath(name); err == nil {
+			t := typers[name]
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w. Please install the %s package", ErrNoHelper, strings.Join(candidates, " or "))
+}
+
+func (t *typer) run(ctx context.Context, step Step) error {
+	if step.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(step.Delay):
+		}
+		return nil
+	}
+
+	args := t.typeArgs
+	if step.Key != "" {
+		args = t.keyArgs(step.Key)
+	}
+	// the text is passed on stdin so it doesn't show up in the process list
+	cmd := exec.CommandContext(ctx, t.name, args...)
+	cmd.Stdin = bytes.NewBufferString(step.Text)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", t.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+package autotype
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/gopass/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a fake xdotool that records its arguments and input
+const fakeXdotool = `#!/bin/sh
+echo "$@" >> "$TYPELOG"
+if [ "$1" = "type" ]; then
+	cat >> "$TYPELOG"
+	echo >> "$TYPELOG"
+fi
+`
+
+func TestSequence(t *testing.T) {
+	sec := secrets.New()
+	sec.SetPassword("s3cret")
+
+	steps, err := Sequence(sec)
+	require.NoError(t, err)
+	assert.Equal(t, []Step{{Text: "s3cret"}}, steps)
+
+	assert.NoError(t, sec.Set("user", "jane"))
+	assert.NoError(t, sec.Set("autotype", "user :tab pass :delay :enter"))
+	steps, err = Sequence(sec)
+	require.NoError(t, err)
+	assert.Equal(t, []Step{
+		{Text: "jane"},
+		{Key: "Tab"},
+		{Text: "s3cret"},
+		{Delay: time.Second},
+		{Key: "Return"},
+	}, steps)
+
+	assert.NoError(t, sec.Set("autotype", "user :escape pass"))
+	_, err = Sequence(sec)
+	assert.Error(t, err)
+
+	assert.NoError(t, sec.Set("autotype", "login :tab pass"))
+	_, err = Sequence(sec)
+	assert.Error(t, err)
+}
+
+func TestType(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	for _, key := range []string{"SSH_CONNECTION", "SSH_CLIENT", "SSH_TTY", "WAYLAND_DISPLAY", "XDG_SESSION_TYPE"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("DISPLAY", ":0")
+	t.Setenv("PATH", td+string(os.PathListSeparator)+"/bin"+string(os.PathListSeparator)+"/usr/bin")
+	logFile := filepath.Join(td, "log")
+	t.Setenv("TYPELOG", logFile)
+
+	ctx := WithCountdown(context.Background(), 0)
+	steps := []Step{{Text: "jane"}, {Key: "Tab"}, {Text: "s3cret"}}
+
+	t.Run("missing helper", func(t *testing.T) {
+		t.Setenv("PATH", td)
+		err := Type(ctx, "foo", steps)
+		assert.ErrorIs(t, err, ErrNoHelper)
+		assert.Contains(t, err.Error(), "xdotool")
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(td, "xdotool"), []byte(fakeXdotool), 0755))
+
+	t.Run("type with xdotool", func(t *testing.T) {
+		require.NoError(t, Type(ctx, "foo", steps))
+		buf, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		assert.Equal(t, "type --clearmodifiers --file -\njane\nkey --clearmodifiers Tab\ntype --clearmodifiers --file -\ns3cret\n", string(buf))
+	})
+
+	t.Run("disabled over SSH", func(t *testing.T) {
+		t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+		assert.ErrorIs(t, Type(ctx, "foo", steps), ErrSSH)
+	})
+}
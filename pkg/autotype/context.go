@@ -0,0 +1,30 @@
+package autotype
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCountdown is the default time to wait before typing
+const DefaultCountdown = 3 * time.Second
+
+type contextKey int
+
+const (
+	ctxKeyCountdown contextKey = iota
+)
+
+// WithCountdown returns a context with the time to wait before typing set
+func WithCountdown(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyCountdown, d)
+}
+
+// GetCountdown returns the time to wait before typing or the default
+// (3 seconds)
+func GetCountdown(ctx context.Context) time.Duration {
+	dv, ok := ctx.Value(ctxKeyCountdown).(time.Duration)
+	if !ok {
+		return DefaultCountdown
+	}
+	return dv
+}
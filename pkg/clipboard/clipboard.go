@@ -8,14 +8,13 @@ import (
 	"github.com/gopasspw/gopass/internal/notify"
 	"github.com/gopasspw/gopass/internal/out"
 
-	"github.com/atotto/clipboard"
 	"github.com/fatih/color"
 )
 
 var (
 	// Helpers can be overridden at compile time, e.g. go build \
 	// -ldflags=='-X github.com/gopasspw/gopass/pkg/clipboard.Helpers=termux-api'
-	Helpers = "xsel of xclip"
+	Helpers = "wl-clipboard, xclip or xsel"
 	// ErrNotSupported is returned when the clipboard is not accessible
 	ErrNotSupported = fmt.Errorf("WARNING: No clipboard available. Install " + Helpers + " or use -f to print to console")
 )
@@ -23,13 +22,13 @@ var (
 // CopyTo copies the given data to the clipboard and enqueues automatic
 // clearing of the clipboard
 func CopyTo(ctx context.Context, name string, content []byte, timeout int) error {
-	if clipboard.Unsupported {
+	if isUnsupported(ctx) {
 		out.Printf(ctx, "%s", ErrNotSupported)
 		_ = notify.Notify(ctx, "gopass - clipboard", fmt.Sprintf("%s", ErrNotSupported))
 		return nil
 	}
 
-	if err := clipboard.WriteAll(string(content)); err != nil {
+	if err := writeAll(ctx, string(content)); err != nil {
 		_ = notify.Notify(ctx, "gopass - clipboard", "failed to write to clipboard")
 		return fmt.Errorf("failed to write to clipboard: %w", err)
 	}
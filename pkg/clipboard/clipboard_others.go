@@ -17,9 +17,9 @@ import (
 )
 
 // clear will spwan a copy of gopass that waits in a detached background
-// process group until the timeout is expired. It will then compare the contents
+// session until the timeout is expired. It will then compare the contents
 // of the clipboard and erase it if it still contains the data gopass copied
-// to it.
+// to it. Running in its own session it survives the exit of the shell.
 func clear(ctx context.Context, content []byte, timeout int) error {
 	hash := fmt.Sprintf("%x", sha256.Sum256(content))
 
@@ -29,9 +29,9 @@ func clear(ctx context.Context, content []byte, timeout int) error {
 	cmd := exec.Command(os.Args[0], "unclip", "--timeout", strconv.Itoa(timeout))
 	// https://groups.google.com/d/msg/golang-nuts/shST-SDqIp4/za4oxEiVtI0J
 	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
+		Setsid: true,
 	}
-	cmd.Env = append(os.Environ(), "GOPASS_UNCLIP_CHECKSUM="+hash)
+	cmd.Env = append(os.Environ(), "GOPASS_UNCLIP_CHECKSUM="+hash, "GOPASS_CLIPBOARD="+GetHelper(ctx))
 	if !ctxutil.IsNotifications(ctx) {
 		cmd.Env = append(cmd.Env, "GOPASS_NO_NOTIFY=true")
 	}
@@ -10,8 +10,14 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"syscall"
+
+	"github.com/gopasspw/gopass/pkg/ctxutil"
 )
 
+// detachedProcess is DETACHED_PROCESS from the Windows API
+const detachedProcess = 0x00000008
+
 // clear will spwan a copy of gopass that waits in a detached background
 // process group until the timeout is expired. It will then compare the contents
 // of the clipboard and erase it if it still contains the data gopass copied
@@ -19,8 +25,15 @@ import (
 func clear(ctx context.Context, content []byte, timeout int) error {
 	hash := fmt.Sprintf("%x", sha256.Sum256(content))
 
-	cmd := exec.CommandContext(ctx, os.Args[0], "unclip", "--timeout", strconv.Itoa(timeout))
-	cmd.Env = append(os.Environ(), "GOPASS_UNCLIP_CHECKSUM="+hash)
+	cmd := exec.Command(os.Args[0], "unclip", "--timeout", strconv.Itoa(timeout))
+	// do not tie the process to the console, it must outlive gopass
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+	cmd.Env = append(os.Environ(), "GOPASS_UNCLIP_CHECKSUM="+hash, "GOPASS_CLIPBOARD="+GetHelper(ctx))
+	if !ctxutil.IsNotifications(ctx) {
+		cmd.Env = append(cmd.Env, "GOPASS_NO_NOTIFY=true")
+	}
 	return cmd.Start()
 }
 
@@ -0,0 +1,24 @@
+package clipboard
+
+import "context"
+
+type contextKey int
+
+const (
+	ctxKeyHelper contextKey = iota
+)
+
+// WithHelper returns a context with the name of the clipboard helper set
+func WithHelper(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ctxKeyHelper, name)
+}
+
+// GetHelper returns the name of the clipboard helper or HelperAuto if none
+// was set
+func GetHelper(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyHelper).(string)
+	if !ok || sv == "" {
+		return HelperAuto
+	}
+	return sv
+}
@@ -0,0 +1,124 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// HelperAuto selects the clipboard helper automatically. It uses wl-copy and
+// wl-paste on Wayland, xclip or xsel on X11, pbcopy on macOS and the native
+// clipboard on Windows.
+const HelperAuto = "auto"
+
+// helper is an external command used to access the clipboard
+type helper struct {
+	copy  []string
+	paste []string
+	// clear is used instead of copying an empty string, if set
+	clear []string
+}
+
+// helpers are the clipboard helpers that can be selected explicitly
+var helpers = map[string]helper{
+	"wl-clipboard": {
+		copy:  []string{"wl-copy"},
+		paste: []string{"wl-paste", "--no-newline"},
+		clear: []string{"wl-copy", "--clear"},
+	},
+	"xclip": {
+		copy:  []string{"xclip", "-in", "-selection", "clipboard"},
+		paste: []string{"xclip", "-out", "-selection", "clipboard"},
+	},
+	"xsel": {
+		copy:  []string{"xsel", "--input", "--clipboard"},
+		paste: []string{"xsel", "--output", "--clipboard"},
+		clear: []string{"xsel", "--clear", "--clipboard"},
+	},
+	"pbcopy": {
+		copy:  []string{"pbcopy"},
+		paste: []string{"pbpaste"},
+	},
+}
+
+// HelperNames returns the names of all clipboard helpers that can be selected
+func HelperNames() []string {
+	names := make([]string, 0, len(helpers)+1)
+	for name := range helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{HelperAuto}, names...)
+}
+
+// lookupHelper returns the explicitly selected helper or nil if the helper
+// should be detected automatically
+func lookupHelper(ctx context.Context) (*helper, error) {
+	name := GetHelper(ctx)
+	if name == "" || name == HelperAuto {
+		return nil, nil
+	}
+	h, found := helpers[name]
+	if !found {
+		return nil, fmt.Errorf("unknown clipboard helper %q. Must be one of %s", name, strings.Join(HelperNames(), ", "))
+	}
+	return &h, nil
+}
+
+// isUnsupported returns true if the clipboard can not be accessed. Unknown
+// helpers are reported when they are used.
+func isUnsupported(ctx context.Context) bool {
+	h, err := lookupHelper(ctx)
+	if err != nil {
+		return false
+	}
+	if h == nil {
+		return nativeUnsupported()
+	}
+	for _, cmd := range [][]string{h.copy, h.paste} {
+		if _, err := exec.LookPath(cmd[0]); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func readAll(ctx context.Context) (string, error) {
+	h, err := lookupHelper(ctx)
+	if err != nil {
+		return "", err
+	}
+	if h == nil {
+		return nativeReadAll()
+	}
+
+	buf, err := exec.CommandContext(ctx, h.paste[0], h.paste[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", h.paste[0], err)
+	}
+	return string(buf), nil
+}
+
+func writeAll(ctx context.Context, content string) error {
+	h, err := lookupHelper(ctx)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return nativeWriteAll(content)
+	}
+
+	args := h.copy
+	if content == "" && h.clear != nil {
+		args = h.clear
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewBufferString(content)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a fake xsel that keeps the clipboard in a file
+const fakeXsel = `#!/bin/sh
+case "$1" in
+	--input) cat > "$CLIPFILE" ;;
+	--output) cat "$CLIPFILE" ;;
+	--clear) : > "$CLIPFILE" ;;
+esac
+`
+
+func TestHelper(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	require.NoError(t, os.WriteFile(filepath.Join(td, "xsel"), []byte(fakeXsel), 0755))
+	clipFile := filepath.Join(td, "clipboard")
+	t.Setenv("PATH", td+string(os.PathListSeparator)+"/bin"+string(os.PathListSeparator)+"/usr/bin")
+	t.Setenv("CLIPFILE", clipFile)
+	t.Setenv("GOPASS_NO_NOTIFY", "true")
+
+	ctx := context.Background()
+
+	t.Run("unknown helper", func(t *testing.T) {
+		ctx := WithHelper(ctx, "foo")
+		assert.False(t, isUnsupported(ctx))
+		assert.Error(t, writeAll(ctx, "bar"))
+	})
+
+	t.Run("missing helper", func(t *testing.T) {
+		t.Setenv("PATH", td)
+		ctx := WithHelper(ctx, "wl-clipboard")
+		assert.True(t, isUnsupported(ctx))
+		assert.Equal(t, ErrNotSupported, Clear(ctx, "", true))
+	})
+
+	ctx = WithHelper(ctx, "xsel")
+	require.False(t, isUnsupported(ctx))
+
+	t.Run("copy and paste", func(t *testing.T) {
+		require.NoError(t, writeAll(ctx, "s3cret"))
+		cur, err := readAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", cur)
+	})
+
+	t.Run("do not clear changed content", func(t *testing.T) {
+		require.NoError(t, writeAll(ctx, "something else"))
+		assert.NoError(t, Clear(ctx, fmt.Sprintf("%x", sha256.Sum256([]byte("s3cret"))), false))
+		cur, err := readAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "something else", cur)
+	})
+
+	t.Run("clear own content", func(t *testing.T) {
+		require.NoError(t, writeAll(ctx, "s3cret"))
+		assert.NoError(t, Clear(ctx, fmt.Sprintf("%x", sha256.Sum256([]byte("s3cret"))), false))
+		cur, err := readAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "", cur)
+	})
+
+	t.Run("force clear", func(t *testing.T) {
+		require.NoError(t, writeAll(ctx, "something else"))
+		assert.NoError(t, Clear(ctx, "", true))
+		cur, err := readAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "", cur)
+	})
+}
+
+func TestHelperNames(t *testing.T) {
+	assert.Equal(t, []string{"auto", "pbcopy", "wl-clipboard", "xclip", "xsel"}, HelperNames())
+	assert.Equal(t, HelperAuto, GetHelper(context.Background()))
+	assert.Equal(t, "xsel", GetHelper(WithHelper(context.Background(), "xsel")))
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package clipboard
+
+import (
+	"github.com/atotto/clipboard"
+)
+
+// nativeUnsupported returns true if none of the clipboard helpers supported
+// by atotto/clipboard is installed
+func nativeUnsupported() bool {
+	return clipboard.Unsupported
+}
+
+func nativeReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+func nativeWriteAll(content string) error {
+	return clipboard.WriteAll(content)
+}
@@ -0,0 +1,193 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"github.com/atotto/clipboard"
+	"github.com/gopasspw/gopass/pkg/debug"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// openTimeout is how long to wait for other programs, e.g. clipboard
+// managers, to release the clipboard
+var openTimeout = time.Second
+
+var (
+	user32   = windows.NewLazySystemDLL("user32.dll")
+	kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+	procOpenClipboard              = user32.NewProc("OpenClipboard")
+	procCloseClipboard             = user32.NewProc("CloseClipboard")
+	procEmptyClipboard             = user32.NewProc("EmptyClipboard")
+	procGetClipboardData           = user32.NewProc("GetClipboardData")
+	procSetClipboardData           = user32.NewProc("SetClipboardData")
+	procIsClipboardFormatAvailable = user32.NewProc("IsClipboardFormatAvailable")
+	procRegisterClipboardFormatW   = user32.NewProc("RegisterClipboardFormatW")
+
+	procGlobalAlloc   = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree    = kernel32.NewProc("GlobalFree")
+	procGlobalLock    = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock  = kernel32.NewProc("GlobalUnlock")
+	procGlobalSize    = kernel32.NewProc("GlobalSize")
+	procRtlMoveMemory = kernel32.NewProc("RtlMoveMemory")
+)
+
+// historyFormats keep the content out of the clipboard history (Win+V), the
+// cloud clipboard and clipboard monitors, see
+// https://docs.microsoft.com/en-us/windows/win32/dataxchg/clipboard-formats#cloud-clipboard-and-clipboard-history-formats
+var historyFormats = []string{
+	"ExcludeClipboardContentFromMonitorProcessing",
+	"CanIncludeInClipboardHistory",
+	"CanUploadToCloudClipboard",
+}
+
+// nativeUnsupported returns false, the clipboard is always available on
+// Windows. Setting atotto/clipboard.Unsupported disables it, e.g. in tests.
+func nativeUnsupported() bool {
+	return clipboard.Unsupported
+}
+
+// nativeReadAll returns the text in the clipboard
+func nativeReadAll() (string, error) {
+	// the clipboard is owned by the thread that opened it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openClipboard(); err != nil {
+		return "", err
+	}
+	defer func() {
+		_, _, _ = procCloseClipboard.Call()
+	}()
+
+	if r, _, _ := procIsClipboardFormatAvailable.Call(cfUnicodeText); r == 0 {
+		return "", nil
+	}
+	h, _, err := procGetClipboardData.Call(cfUnicodeText)
+	if h == 0 {
+		return "", fmt.Errorf("failed to get the clipboard data: %w", err)
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		return "", fmt.Errorf("failed to lock the clipboard data: %w", err)
+	}
+	defer func() {
+		_, _, _ = procGlobalUnlock.Call(h)
+	}()
+
+	size, _, _ := procGlobalSize.Call(h)
+	buf := make([]uint16, size/2)
+	if len(buf) < 1 {
+		return "", nil
+	}
+	_, _, _ = procRtlMoveMemory.Call(uintptr(unsafe.Pointer(&buf[0])), p, uintptr(len(buf)*2))
+	return windows.UTF16ToString(buf), nil
+}
+
+// nativeWriteAll replaces the content of the clipboard with the text. An
+// empty text clears the clipboard.
+func nativeWriteAll(content string) error {
+	data, err := windows.UTF16FromString(content)
+	if err != nil {
+		return fmt.Errorf("failed to encode the content: %w", err)
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := openClipboard(); err != nil {
+		return err
+	}
+	defer func() {
+		_, _, _ = procCloseClipboard.Call()
+	}()
+
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return fmt.Errorf("failed to empty the clipboard: %w", err)
+	}
+	if content == "" {
+		return nil
+	}
+
+	h, err := globalCopy(unsafe.Pointer(&data[0]), len(data)*2)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procSetClipboardData.Call(cfUnicodeText, h); r == 0 {
+		_, _, _ = procGlobalFree.Call(h)
+		return fmt.Errorf("failed to set the clipboard data: %w", err)
+	}
+
+	for _, name := range historyFormats {
+		if err := setDWORD(name, 0); err != nil {
+			debug.Log("failed to set clipboard format %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// openClipboard opens the clipboard, retrying while it's used by another
+// program
+func openClipboard() error {
+	deadline := time.Now().Add(openTimeout)
+	for {
+		r, _, err := procOpenClipboard.Call(0)
+		if r != 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("failed to open the clipboard: %w", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// globalCopy copies n bytes at src to a new movable global memory object. The
+// clipboard owns the object once it was passed to SetClipboardData.
+func globalCopy(src unsafe.Pointer, n int) (uintptr, error) {
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(n))
+	if h == 0 {
+		return 0, fmt.Errorf("failed to allocate memory: %w", err)
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		_, _, _ = procGlobalFree.Call(h)
+		return 0, fmt.Errorf("failed to lock memory: %w", err)
+	}
+	_, _, _ = procRtlMoveMemory.Call(p, uintptr(src), uintptr(n))
+	_, _, _ = procGlobalUnlock.Call(h)
+	return h, nil
+}
+
+// setDWORD adds the registered clipboard format with the value v to the
+// clipboard
+func setDWORD(format string, v uint32) error {
+	name, err := windows.UTF16PtrFromString(format)
+	if err != nil {
+		return err
+	}
+	id, _, err := procRegisterClipboardFormatW.Call(uintptr(unsafe.Pointer(name)))
+	if id == 0 {
+		return fmt.Errorf("failed to register clipboard format: %w", err)
+	}
+	h, err := globalCopy(unsafe.Pointer(&v), 4)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procSetClipboardData.Call(id, h); r == 0 {
+		_, _, _ = procGlobalFree.Call(h)
+		return fmt.Errorf("failed to set clipboard data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build windows
+// +build windows
+
+package clipboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeClipboard(t *testing.T) {
+	old, err := nativeReadAll()
+	if err != nil {
+		t.Skipf("clipboard not available: %s", err)
+	}
+	defer func() {
+		_ = nativeWriteAll(old)
+	}()
+
+	assert.False(t, nativeUnsupported())
+
+	for _, content := range []string{"foo", "pässwörd 🔑", "line1\r\nline2"} {
+		require.NoError(t, nativeWriteAll(content))
+		got, err := nativeReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+	}
+
+	// an empty string clears the clipboard
+	require.NoError(t, nativeWriteAll(""))
+	got, err := nativeReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	assert.Error(t, nativeWriteAll("nul\x00byte"))
+}
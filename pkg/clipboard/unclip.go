@@ -6,27 +6,28 @@ import (
 	"fmt"
 
 	"github.com/gopasspw/gopass/internal/notify"
-
-	"github.com/atotto/clipboard"
 )
 
 // Clear will attempt to erase the clipboard
 func Clear(ctx context.Context, checksum string, force bool) error {
-	if clipboard.Unsupported {
+	if isUnsupported(ctx) {
 		return ErrNotSupported
 	}
 
-	cur, err := clipboard.ReadAll()
-	if err != nil {
-		return fmt.Errorf("failed to read clipboard: %w", err)
-	}
-
-	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(cur)))
-	if hash != checksum && !force {
-		return nil
+	if !force {
+		cur, err := readAll(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read clipboard: %w", err)
+		}
+
+		// never clobber anything the user copied in the meantime
+		hash := fmt.Sprintf("%x", sha256.Sum256([]byte(cur)))
+		if hash != checksum {
+			return nil
+		}
 	}
 
-	if err := clipboard.WriteAll(""); err != nil {
+	if err := writeAll(ctx, ""); err != nil {
 		_ = notify.Notify(ctx, "gopass - clipboard", "Failed to clear clipboard")
 		return fmt.Errorf("failed to write clipboard: %w", err)
 	}
@@ -36,9 +37,8 @@ func Clear(ctx context.Context, checksum string, force bool) error {
 		return fmt.Errorf("failed to clear clipboard history: %w", err)
 	}
 
-	if err := notify.Notify(ctx, "gopass - clipboard", "Clipboard has been cleared"); err != nil {
-		return fmt.Errorf("failed to send unclip notification: %w", err)
-	}
+	// the notification is best effort, the clipboard has been cleared
+	_ = notify.Notify(ctx, "gopass - clipboard", "Clipboard has been cleared")
 
 	return nil
 }
@@ -2,6 +2,7 @@ package ctxutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -35,15 +36,62 @@ const (
 	ctxKeyCommitTimestamp
 	ctxKeyShowParsing
 	ctxKeyHidden
+	ctxKeyNoKeyCache
+	ctxKeyWorkers
+	ctxKeyExplicitSync
+	ctxKeyLockTimeout
+	ctxKeyKeepBackup
+	ctxKeyCheckRecipientHash
+	ctxKeyAgeAgent
+	ctxKeyAutoSync
+	ctxKeyNoIndex
+	ctxKeyExecTimeout
+	ctxKeyDryRun
+	ctxKeyHooks
+	ctxKeyVersion
+	ctxKeyOffline
+	ctxKeyAutoOffline
+	ctxKeyConfirm
 )
 
 // WithGlobalFlags parses any global flags from the cli context and returns
 // a regular context
 func WithGlobalFlags(c *cli.Context) context.Context {
+	ctx := c.Context
 	if c.Bool("yes") {
-		return WithAlwaysYes(c.Context, true)
+		ctx = WithAlwaysYes(ctx, true)
 	}
-	return c.Context
+	if c.Bool("no-cache") {
+		ctx = WithNoKeyCache(ctx, true)
+		ctx = WithNoIndex(ctx, true)
+	}
+	if c.IsSet("no-autosync") {
+		ctx = WithAutoSync(ctx, !c.Bool("no-autosync"))
+	}
+	if c.IsSet("no-pager") {
+		ctx = WithNoPager(ctx, c.Bool("no-pager"))
+	}
+	if c.IsSet("no-notify") {
+		ctx = WithNotifications(ctx, !c.Bool("no-notify"))
+	}
+	if c.Bool("verbose") {
+		ctx = WithVerbose(ctx, true)
+	}
+	if c.Bool("dry-run") {
+		ctx = WithDryRun(ctx, true)
+	}
+	if c.Bool("offline") {
+		ctx = WithOffline(ctx, true)
+	}
+	// the global --force confirms destructive actions of any command, even
+	// if it has a --force flag of its own. It doesn't show any secrets.
+	for _, lc := range c.Lineage() {
+		if lc.Bool("force") {
+			ctx = WithConfirm(ctx, true)
+			break
+		}
+	}
+	return ctx
 }
 
 // ProgressCallback is a callback for updateing progress
@@ -308,6 +356,19 @@ func IsForce(ctx context.Context) bool {
 	return is(ctx, ctxKeyForce, false)
 }
 
+// WithConfirm returns a context with the flag set that confirms destructive
+// actions without asking, e.g. by the global --force or GOPASS_FORCE. Unlike
+// WithForce it doesn't turn off any of the checks protecting secrets from
+// being shown.
+func WithConfirm(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyConfirm, bv)
+}
+
+// IsConfirm returns the value of the confirm flag or the default (false)
+func IsConfirm(ctx context.Context) bool {
+	return is(ctx, ctxKeyConfirm, false)
+}
+
 // WithCommitMessage returns a context with a commit message set
 func WithCommitMessage(ctx context.Context, sv string) context.Context {
 	return context.WithValue(ctx, ctxKeyCommitMessage, sv)
@@ -327,6 +388,21 @@ func GetCommitMessage(ctx context.Context) string {
 	return sv
 }
 
+// WithNoKeyCache returns a context with the value of no key cache set
+func WithNoKeyCache(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoKeyCache, bv)
+}
+
+// HasNoKeyCache returns true if no key cache was set
+func HasNoKeyCache(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyNoKeyCache)
+}
+
+// IsNoKeyCache returns the value of no key cache or false
+func IsNoKeyCache(ctx context.Context) bool {
+	return is(ctx, ctxKeyNoKeyCache, false)
+}
+
 // WithNoNetwork returns a context with the value of no network set
 func WithNoNetwork(ctx context.Context, bv bool) context.Context {
 	return context.WithValue(ctx, ctxKeyNoNetwork, bv)
@@ -469,3 +545,278 @@ func IsHidden(ctx context.Context) bool {
 	}
 	return bv
 }
+
+// WithWorkers returns a context with the number of workers used for bulk
+// operations, e.g. re-encryption, set
+func WithWorkers(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, ctxKeyWorkers, n)
+}
+
+// GetWorkers returns the number of workers used for bulk operations or 0 if
+// the backend default should be used
+func GetWorkers(ctx context.Context) int {
+	iv, ok := ctx.Value(ctxKeyWorkers).(int)
+	if !ok {
+		return 0
+	}
+	return iv
+}
+
+// WithExplicitSync returns a context with the flag for syncs requested by the
+// user set. Those are never skipped, unlike the implicit ones after a change.
+func WithExplicitSync(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyExplicitSync, bv)
+}
+
+// IsExplicitSync returns true if the user requested the sync
+func IsExplicitSync(ctx context.Context) bool {
+	return is(ctx, ctxKeyExplicitSync, false)
+}
+
+// WithDryRun returns a context with the flag for dry runs set. Changes are
+// validated and printed, but never written.
+func WithDryRun(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyDryRun, bv)
+}
+
+// IsDryRun returns true if changes must not be written
+func IsDryRun(ctx context.Context) bool {
+	return is(ctx, ctxKeyDryRun, false)
+}
+
+// WithLockTimeout returns a context with the time to wait for a store locked
+// by another process set
+func WithLockTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyLockTimeout, d)
+}
+
+// HasLockTimeout returns true if a lock timeout has been set
+func HasLockTimeout(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyLockTimeout).(time.Duration)
+	return ok
+}
+
+// GetLockTimeout returns the time to wait for a locked store or 0
+func GetLockTimeout(ctx context.Context) time.Duration {
+	d, ok := ctx.Value(ctxKeyLockTimeout).(time.Duration)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+// WithExecTimeout returns a context with the maximum duration of network
+// operations of external commands, e.g. git push, set. 0 disables the
+// timeouts.
+func WithExecTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, ctxKeyExecTimeout, d)
+}
+
+// HasExecTimeout returns true if an exec timeout has been set
+func HasExecTimeout(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxKeyExecTimeout).(time.Duration)
+	return ok
+}
+
+// GetExecTimeout returns the maximum duration of network operations of
+// external commands or 0
+func GetExecTimeout(ctx context.Context) time.Duration {
+	d, ok := ctx.Value(ctxKeyExecTimeout).(time.Duration)
+	if !ok {
+		return 0
+	}
+	return d
+}
+
+// WithKeepBackup returns a context with the flag for keeping the previous
+// version of a changed secret until it has been committed set
+func WithKeepBackup(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyKeepBackup, bv)
+}
+
+// HasKeepBackup returns true if a value for KeepBackup has been set in this
+// context
+func HasKeepBackup(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyKeepBackup)
+}
+
+// IsKeepBackup returns the value of KeepBackup or the default (false)
+func IsKeepBackup(ctx context.Context) bool {
+	return is(ctx, ctxKeyKeepBackup, false)
+}
+
+// WithCheckRecipientHash returns a context with the flag for checking the
+// recipients against the last acknowledged ones before encrypting set
+func WithCheckRecipientHash(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyCheckRecipientHash, bv)
+}
+
+// HasCheckRecipientHash returns true if a value for CheckRecipientHash has been
+// set in this context
+func HasCheckRecipientHash(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyCheckRecipientHash)
+}
+
+// IsCheckRecipientHash returns the value of CheckRecipientHash or the default
+// (false)
+func IsCheckRecipientHash(ctx context.Context) bool {
+	return is(ctx, ctxKeyCheckRecipientHash, false)
+}
+
+// WithAgeAgent returns a context with the flag for decrypting age secrets
+// through the agent set
+func WithAgeAgent(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyAgeAgent, bv)
+}
+
+// HasAgeAgent returns true if a value for AgeAgent has been set in this
+// context
+func HasAgeAgent(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyAgeAgent)
+}
+
+// IsAgeAgent returns the value of AgeAgent or the default (false)
+func IsAgeAgent(ctx context.Context) bool {
+	return is(ctx, ctxKeyAgeAgent, false)
+}
+
+// WithAutoSync returns a context with the flag for the implicit pull and push
+// after each change set
+func WithAutoSync(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyAutoSync, bv)
+}
+
+// HasAutoSync returns true if a value for AutoSync has been set in this
+// context
+func HasAutoSync(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyAutoSync)
+}
+
+// IsAutoSync returns the value of AutoSync or the default (true)
+func IsAutoSync(ctx context.Context) bool {
+	return is(ctx, ctxKeyAutoSync, true)
+}
+
+// WithNoIndex returns a context with the flag to list stores without their
+// on-disk index set
+func WithNoIndex(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyNoIndex, bv)
+}
+
+// HasNoIndex returns true if a value for NoIndex has been set in this context
+func HasNoIndex(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyNoIndex)
+}
+
+// IsNoIndex returns the value of NoIndex or the default (false)
+func IsNoIndex(ctx context.Context) bool {
+	return is(ctx, ctxKeyNoIndex, false)
+}
+
+// WithHooks returns a context with the flag for running the user's hooks
+// around changes set
+func WithHooks(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyHooks, bv)
+}
+
+// HasHooks returns true if a value for Hooks has been set in this context
+func HasHooks(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyHooks)
+}
+
+// IsHooks returns the value of Hooks or the default (false)
+func IsHooks(ctx context.Context) bool {
+	return is(ctx, ctxKeyHooks, false)
+}
+
+// WithOffline returns a context with the flag for the offline mode set. It
+// disables all network access, like WithNoNetwork, and marks the commits that
+// could not be pushed.
+func WithOffline(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(WithNoNetwork(ctx, bv), ctxKeyOffline, bv)
+}
+
+// IsOffline returns the value of Offline or the default (false)
+func IsOffline(ctx context.Context) bool {
+	return is(ctx, ctxKeyOffline, false)
+}
+
+// WithAutoOffline returns a context with the flag for probing the git remotes
+// and working offline if they can't be reached set
+func WithAutoOffline(ctx context.Context, bv bool) context.Context {
+	return context.WithValue(ctx, ctxKeyAutoOffline, bv)
+}
+
+// HasAutoOffline returns true if a value for AutoOffline has been set in this
+// context
+func HasAutoOffline(ctx context.Context) bool {
+	return hasBool(ctx, ctxKeyAutoOffline)
+}
+
+// IsAutoOffline returns the value of AutoOffline or the default (false)
+func IsAutoOffline(ctx context.Context) bool {
+	return is(ctx, ctxKeyAutoOffline, false)
+}
+
+// WithVersion returns a context with the version of gopass set, e.g. to
+// record it in commit messages
+func WithVersion(ctx context.Context, sv string) context.Context {
+	return context.WithValue(ctx, ctxKeyVersion, sv)
+}
+
+// GetVersion returns the version of gopass or an empty string if it's unknown
+func GetVersion(ctx context.Context) string {
+	sv, ok := ctx.Value(ctxKeyVersion).(string)
+	if !ok {
+		return ""
+	}
+	return sv
+}
+
+// WithNetworkDeadline returns a context for an external command accessing the
+// network, e.g. git push. It's canceled after the exec timeout.
+func WithNetworkDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withDeadline(ctx, GetExecTimeout(ctx))
+}
+
+// WithLocalDeadline returns a context for a local external command, e.g.
+// gpg --list-keys. It's canceled after a quarter of the exec timeout.
+func WithLocalDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withDeadline(ctx, GetExecTimeout(ctx)/4)
+}
+
+func withDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// ExecError returns a descriptive error if an external command failed because
+// its context timed out or was canceled, e.g. by Ctrl+C. Otherwise err is
+// returned unchanged.
+func ExecError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out (see core.exectimeout): %w", ctx.Err())
+	}
+	return fmt.Errorf("interrupted: %w", ctx.Err())
+}
+
+// uncanceled keeps the values of a context, but ignores its cancelation
+type uncanceled struct {
+	context.Context
+}
+
+func (uncanceled) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (uncanceled) Done() <-chan struct{}       { return nil }
+func (uncanceled) Err() error                  { return nil }
+
+// WithoutCancel returns a context with all values of ctx that is not
+// canceled when ctx is, e.g. to finish an operation that must not be
+// interrupted
+func WithoutCancel(ctx context.Context) context.Context {
+	return uncanceled{ctx}
+}
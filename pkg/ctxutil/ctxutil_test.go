@@ -2,8 +2,11 @@ package ctxutil
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v2"
@@ -111,6 +114,10 @@ func TestForce(t *testing.T) {
 	assert.Equal(t, false, IsForce(ctx))
 	assert.Equal(t, true, IsForce(WithForce(ctx, true)))
 	assert.Equal(t, false, IsForce(WithForce(ctx, false)))
+
+	assert.Equal(t, false, IsConfirm(ctx))
+	assert.Equal(t, true, IsConfirm(WithConfirm(ctx, true)))
+	assert.Equal(t, false, IsForce(WithConfirm(ctx, true)))
 }
 
 func TestCommitMessage(t *testing.T) {
@@ -121,6 +128,13 @@ func TestCommitMessage(t *testing.T) {
 	assert.Equal(t, "", GetCommitMessage(WithCommitMessage(ctx, "")))
 }
 
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, "", GetVersion(ctx))
+	assert.Equal(t, "1.12.0", GetVersion(WithVersion(ctx, "1.12.0")))
+}
+
 func TestComposite(t *testing.T) {
 	ctx := context.Background()
 	ctx = WithTerminal(ctx, false)
@@ -136,6 +150,7 @@ func TestComposite(t *testing.T) {
 	ctx = WithEmail(ctx, "foo@bar.com")
 	ctx = WithUsername(ctx, "foo")
 	ctx = WithNoNetwork(ctx, true)
+	ctx = WithNoKeyCache(ctx, true)
 	ctx = WithCommitMessage(ctx, "foobar")
 	ctx = WithForce(ctx, true)
 	ctx = WithGitInit(ctx, false)
@@ -176,6 +191,9 @@ func TestComposite(t *testing.T) {
 	assert.Equal(t, true, IsNoNetwork(ctx))
 	assert.Equal(t, true, HasNoNetwork(ctx))
 
+	assert.Equal(t, true, IsNoKeyCache(ctx))
+	assert.Equal(t, true, HasNoKeyCache(ctx))
+
 	assert.Equal(t, "foobar", GetCommitMessage(ctx))
 	assert.Equal(t, true, HasCommitMessage(ctx))
 
@@ -201,6 +219,46 @@ func TestGlobalFlags(t *testing.T) {
 	c.Context = ctx
 
 	assert.Equal(t, true, IsAlwaysYes(WithGlobalFlags(c)))
+	assert.False(t, HasAutoSync(WithGlobalFlags(c)))
+
+	fs = flag.NewFlagSet("default", flag.ContinueOnError)
+	for _, name := range []string{"no-autosync", "no-pager", "no-notify", "no-cache", "verbose"} {
+		bf := cli.BoolFlag{
+			Name:  name,
+			Usage: name,
+		}
+		assert.NoError(t, bf.Apply(fs))
+	}
+	assert.NoError(t, fs.Parse([]string{"--no-autosync", "--no-pager", "--no-notify=false", "--no-cache", "--verbose"}))
+	c = cli.NewContext(app, fs, nil)
+	c.Context = WithNotifications(ctx, false)
+
+	gctx := WithGlobalFlags(c)
+	assert.False(t, IsAutoSync(gctx))
+	assert.True(t, IsNoPager(gctx))
+	assert.True(t, IsNotifications(gctx))
+	assert.True(t, IsNoKeyCache(gctx))
+	assert.True(t, IsNoIndex(gctx))
+	assert.True(t, IsVerbose(gctx))
+	assert.False(t, IsForce(gctx))
+	assert.False(t, IsConfirm(gctx))
+
+	// a global --force is used even if the command has a --force, too
+	gfs := flag.NewFlagSet("global", flag.ContinueOnError)
+	lfs := flag.NewFlagSet("local", flag.ContinueOnError)
+	for _, fs := range []*flag.FlagSet{gfs, lfs} {
+		bf := cli.BoolFlag{
+			Name:  "force",
+			Usage: "force",
+		}
+		assert.NoError(t, bf.Apply(fs))
+	}
+	assert.NoError(t, gfs.Parse([]string{"--force"}))
+	c = cli.NewContext(app, lfs, cli.NewContext(app, gfs, nil))
+	c.Context = ctx
+	gctx = WithGlobalFlags(c)
+	assert.True(t, IsConfirm(gctx))
+	assert.False(t, IsForce(gctx))
 }
 
 func TestImportFunc(t *testing.T) {
@@ -221,3 +279,161 @@ func TestHidden(t *testing.T) {
 	assert.False(t, IsHidden(ctx))
 	assert.True(t, IsHidden(WithHidden(ctx, true)))
 }
+
+func TestWorkers(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Equal(t, 0, GetWorkers(ctx))
+	assert.Equal(t, 4, GetWorkers(WithWorkers(ctx, 4)))
+}
+
+func TestExplicitSync(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsExplicitSync(ctx))
+	assert.True(t, IsExplicitSync(WithExplicitSync(ctx, true)))
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsDryRun(ctx))
+	assert.True(t, IsDryRun(WithDryRun(ctx, true)))
+}
+
+func TestLockTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasLockTimeout(ctx))
+	assert.Equal(t, time.Duration(0), GetLockTimeout(ctx))
+	assert.True(t, HasLockTimeout(WithLockTimeout(ctx, 0)))
+	assert.Equal(t, time.Minute, GetLockTimeout(WithLockTimeout(ctx, time.Minute)))
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasHooks(ctx))
+	assert.False(t, IsHooks(ctx))
+	assert.True(t, HasHooks(WithHooks(ctx, false)))
+	assert.True(t, IsHooks(WithHooks(ctx, true)))
+}
+
+func TestKeepBackup(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasKeepBackup(ctx))
+	assert.False(t, IsKeepBackup(ctx))
+	assert.True(t, HasKeepBackup(WithKeepBackup(ctx, false)))
+	assert.True(t, IsKeepBackup(WithKeepBackup(ctx, true)))
+}
+
+func TestCheckRecipientHash(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasCheckRecipientHash(ctx))
+	assert.False(t, IsCheckRecipientHash(ctx))
+	assert.True(t, HasCheckRecipientHash(WithCheckRecipientHash(ctx, false)))
+	assert.True(t, IsCheckRecipientHash(WithCheckRecipientHash(ctx, true)))
+}
+
+func TestAgeAgent(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasAgeAgent(ctx))
+	assert.False(t, IsAgeAgent(ctx))
+	assert.True(t, HasAgeAgent(WithAgeAgent(ctx, false)))
+	assert.True(t, IsAgeAgent(WithAgeAgent(ctx, true)))
+}
+
+func TestAutoSync(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasAutoSync(ctx))
+	assert.True(t, IsAutoSync(ctx))
+	assert.True(t, HasAutoSync(WithAutoSync(ctx, true)))
+	assert.False(t, IsAutoSync(WithAutoSync(ctx, false)))
+}
+
+func TestNoIndex(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasNoIndex(ctx))
+	assert.False(t, IsNoIndex(ctx))
+	assert.True(t, HasNoIndex(WithNoIndex(ctx, false)))
+	assert.True(t, IsNoIndex(WithNoIndex(ctx, true)))
+}
+
+func TestWithoutCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithTerminal(context.Background(), true))
+	uctx := WithoutCancel(ctx)
+	cancel()
+
+	assert.Error(t, ctx.Err())
+	assert.NoError(t, uctx.Err())
+	assert.Nil(t, uctx.Done())
+	assert.True(t, IsTerminal(uctx))
+}
+
+func TestExecTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, HasExecTimeout(ctx))
+	assert.Equal(t, time.Duration(0), GetExecTimeout(ctx))
+	assert.True(t, HasExecTimeout(WithExecTimeout(ctx, 0)))
+	assert.Equal(t, time.Minute, GetExecTimeout(WithExecTimeout(ctx, time.Minute)))
+
+	// no timeout
+	nctx, cancel := WithNetworkDeadline(ctx)
+	_, found := nctx.Deadline()
+	assert.False(t, found)
+	cancel()
+	assert.Error(t, nctx.Err())
+
+	ctx = WithExecTimeout(ctx, time.Minute)
+	nctx, cancel = WithNetworkDeadline(ctx)
+	defer cancel()
+	dl, found := nctx.Deadline()
+	assert.True(t, found)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), dl, 5*time.Second)
+
+	lctx, cancel := WithLocalDeadline(ctx)
+	defer cancel()
+	dl, found = lctx.Deadline()
+	assert.True(t, found)
+	assert.WithinDuration(t, time.Now().Add(15*time.Second), dl, 5*time.Second)
+}
+
+func TestExecError(t *testing.T) {
+	ctx := context.Background()
+	err := fmt.Errorf("signal: killed")
+
+	assert.NoError(t, ExecError(ctx, nil))
+	assert.Equal(t, err, ExecError(ctx, err))
+
+	tctx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+	defer cancel()
+	<-tctx.Done()
+	assert.True(t, errors.Is(ExecError(tctx, err), context.DeadlineExceeded))
+	assert.Contains(t, ExecError(tctx, err).Error(), "timed out")
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.True(t, errors.Is(ExecError(cctx, err), context.Canceled))
+	assert.Contains(t, ExecError(cctx, err).Error(), "interrupted")
+}
+
+func TestOffline(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsOffline(ctx))
+	assert.True(t, IsOffline(WithOffline(ctx, true)))
+	// offline implies no network
+	assert.True(t, IsNoNetwork(WithOffline(ctx, true)))
+	assert.False(t, IsNoNetwork(WithOffline(ctx, false)))
+
+	assert.False(t, HasAutoOffline(ctx))
+	assert.False(t, IsAutoOffline(ctx))
+	assert.True(t, HasAutoOffline(WithAutoOffline(ctx, false)))
+	assert.True(t, IsAutoOffline(WithAutoOffline(ctx, true)))
+}
@@ -21,6 +21,7 @@ var (
 
 var opts struct {
 	logger *log.Logger
+	level  Level
 	funcs  map[string]bool
 	files  map[string]bool
 }
@@ -31,17 +32,19 @@ var logFn = doNotLog
 var enabled = initDebug()
 
 func initDebug() bool {
+	if sv := os.Getenv("GOPASS_DEBUG_LOG_SECRETS"); sv != "" && sv != "false" {
+		logSecrets = true
+	}
+
 	if os.Getenv("GOPASS_DEBUG") == "" && os.Getenv("GOPASS_DEBUG_LOG") == "" {
+		opts.level = levelOff
 		logFn = doNotLog
 		return false
 	}
 
-	if sv := os.Getenv("GOPASS_DEBUG_LOG_SECRETS"); sv != "" && sv != "false" {
-		logSecrets = true
-	}
-
 	initDebugLogger()
 	initDebugTags()
+	opts.level = LevelDebug
 	logFn = doLog
 
 	return true
@@ -185,54 +188,43 @@ func doNotLog(offset int, f string, args ...interface{}) {}
 
 func doLog(offset int, f string, args ...interface{}) {
 	fn, dir, file, line := getPosition(offset)
-	if len(f) == 0 || f[len(f)-1] != '\n' {
-		f += "\n"
-	}
-
-	type Shortener interface {
-		Str() string
-	}
-
-	type Safer interface {
-		SafeStr() string
-	}
 
 	argsi := make([]interface{}, len(args))
 	for i, item := range args {
-		argsi[i] = item
-		if secreter, ok := item.(Safer); ok && !logSecrets {
-			argsi[i] = secreter.SafeStr()
-			continue
-		}
-		if shortener, ok := item.(Shortener); ok {
-			argsi[i] = shortener.Str()
-		}
+		argsi[i] = safeValue(item)
 	}
 
-	pos := fmt.Sprintf("%s/%s:%d", dir, file, line)
-
-	formatString := fmt.Sprintf("%s\t%s\t%s", pos, fn, f)
+	write(fn, dir, file, line, fmt.Sprintf(f, argsi...))
+}
 
-	dbgprint := func() {
-		fmt.Fprintf(Stderr, formatString, argsi...)
+// write writes a line to the debug log file, if any, and to Stderr. If
+// GOPASS_DEBUG_FUNCS or GOPASS_DEBUG_FILES are set only the matching lines are
+// written to Stderr, otherwise all of them unless there is a log file.
+func write(fn, dir, file string, line int, msg string) {
+	if len(msg) == 0 || msg[len(msg)-1] != '\n' {
+		msg += "\n"
 	}
+	msg = fmt.Sprintf("%s/%s:%d\t%s\t%s", dir, file, line, fn, msg)
 
 	if opts.logger != nil {
-		opts.logger.Printf(formatString, argsi...)
+		opts.logger.Print(msg)
 	}
 
-	filename := fmt.Sprintf("%s/%s:%d", dir, file, line)
-	if checkFilter(opts.files, filename) {
-		dbgprint()
+	if len(opts.files) == 0 && len(opts.funcs) == 0 {
+		if opts.logger == nil {
+			fmt.Fprint(Stderr, msg)
+		}
 		return
 	}
 
-	if checkFilter(opts.funcs, fn) {
-		dbgprint()
+	filename := fmt.Sprintf("%s/%s:%d", dir, file, line)
+	if checkFilter(opts.files, filename) || checkFilter(opts.funcs, fn) {
+		fmt.Fprint(Stderr, msg)
 	}
 }
 
-// IsEnabled returns true if debug logging was enabled
+// IsEnabled returns true if debug logging was enabled, i.e. by GOPASS_DEBUG,
+// GOPASS_DEBUG_LOG or --debug
 func IsEnabled() bool {
 	return enabled
 }
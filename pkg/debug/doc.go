@@ -1,4 +1,8 @@
 // Package debug provides logging of debug information.
 //
+// Log writes free form debug messages. Info and Debug write structured records
+// with keys and values at a level, which --verbose and --debug enable. Byte
+// slices and values wrapped in Redact are never written to any log.
+//
 // This package is heavily based on github.com/restic/restic/internal/debug
 package debug
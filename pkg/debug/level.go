@@ -0,0 +1,156 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Level is the severity of a log record. The values match those of log/slog,
+// which needs a newer Go than gopass supports.
+type Level int
+
+const (
+	// LevelDebug is for everything that helps to debug gopass, e.g. the
+	// command lines of gpg and git. Enabled by --debug and GOPASS_DEBUG.
+	LevelDebug Level = -4
+	// LevelInfo is for what gopass does, e.g. which secrets it decrypts and
+	// writes. Enabled by --verbose.
+	LevelInfo Level = 0
+
+	levelOff Level = 1 << 16
+)
+
+// String implements fmt.Stringer
+func (l Level) String() string {
+	switch {
+	case l <= LevelDebug:
+		return "DEBUG"
+	case l <= LevelInfo:
+		return "INFO"
+	default:
+		return "OFF"
+	}
+}
+
+type shortener interface {
+	Str() string
+}
+
+type safer interface {
+	SafeStr() string
+}
+
+// Enable logs the records of level l and above. The log file in
+// GOPASS_DEBUG_LOG is used if it's set, otherwise they are written to Stderr.
+// A level enabled by the environment is never raised.
+func Enable(l Level) {
+	if l >= opts.level {
+		return
+	}
+	if opts.level == levelOff {
+		initDebugLogger()
+		initDebugTags()
+	}
+	opts.level = l
+	if l <= LevelDebug {
+		enabled = true
+		logFn = doLog
+	}
+}
+
+// Enabled returns true if records of level l are logged
+func Enabled(l Level) bool {
+	return l >= opts.level
+}
+
+// Info logs a record at LevelInfo. The args are alternating keys and values
+// like with log/slog, e.g.
+//
+//	debug.Info("wrote secret", "name", name, "store", alias)
+func Info(msg string, args ...interface{}) {
+	record(0, LevelInfo, msg, args...)
+}
+
+// Debug logs a record at LevelDebug. The args are alternating keys and values
+// like with log/slog.
+func Debug(msg string, args ...interface{}) {
+	record(0, LevelDebug, msg, args...)
+}
+
+func record(offset int, l Level, msg string, args ...interface{}) {
+	if l < opts.level {
+		return
+	}
+	fn, dir, file, line := getPosition(offset)
+
+	var sb strings.Builder
+	sb.WriteString("level=")
+	sb.WriteString(l.String())
+	sb.WriteString(" msg=")
+	sb.WriteString(quote(msg))
+	for len(args) > 0 {
+		key, value := "!BADKEY", args[0]
+		if k, ok := args[0].(string); ok && len(args) > 1 {
+			key, value = k, args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+
+		sb.WriteString(" ")
+		sb.WriteString(key)
+		sb.WriteString("=")
+		sb.WriteString(quote(formatValue(value)))
+	}
+
+	write(fn, dir, file, line, sb.String())
+}
+
+// safeValue replaces secrets with their safe representation
+func safeValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case Redacted:
+		return x
+	case []byte:
+		// plaintext is passed around as []byte, so it's never logged, with
+		// or without GOPASS_DEBUG_LOG_SECRETS
+		return Redacted{}
+	case safer:
+		if !logSecrets {
+			return x.SafeStr()
+		}
+	}
+	if s, ok := v.(shortener); ok {
+		return s.Str()
+	}
+	return v
+}
+
+func formatValue(v interface{}) string {
+	switch x := safeValue(v).(type) {
+	case string:
+		return x
+	case time.Duration:
+		return x.String()
+	case error:
+		return x.Error()
+	default:
+		return fmt.Sprintf("%+v", x)
+	}
+}
+
+// quote quotes s if it's empty or contains spaces, quotes, equal signs or
+// unprintable characters, like the text handler of log/slog
+func quote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
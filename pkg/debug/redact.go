@@ -0,0 +1,47 @@
+package debug
+
+import (
+	"fmt"
+	"io"
+)
+
+const redacted = "[redacted]"
+
+// Redacted wraps secret material, e.g. a password or the plaintext of a
+// secret, so it can be passed to any log call. It's rendered as [redacted] by
+// every fmt verb and as JSON or text, even if GOPASS_DEBUG_LOG_SECRETS is set.
+// Use out.Secret instead for secrets that are printed to the user but must not
+// end up in the debug log.
+type Redacted struct {
+	v interface{}
+}
+
+// Redact wraps v so it is never logged
+func Redact(v interface{}) Redacted {
+	return Redacted{v: v}
+}
+
+// String implements fmt.Stringer
+func (Redacted) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer
+func (Redacted) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter, so that no verb prints the wrapped value
+func (Redacted) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, redacted)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (Redacted) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (Redacted) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
@@ -0,0 +1,157 @@
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedacted(t *testing.T) {
+	r := Redact([]byte("hunter2"))
+
+	for _, verb := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x", "%d"} {
+		assert.Equal(t, "[redacted]", fmt.Sprintf(verb, r), verb)
+	}
+	assert.Equal(t, "[redacted]", r.String())
+
+	buf, err := json.Marshal(map[string]interface{}{"password": r})
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"[redacted]"}`, string(buf))
+}
+
+// logFuncs are the functions of this package writing to the debug log
+var logFuncs = map[string]bool{
+	"Log":   true,
+	"LogN":  true,
+	"Info":  true,
+	"Debug": true,
+}
+
+// TestLogCallsRedactPlaintext makes sure that no log call in gopass gets a
+// byte slice, which is how plaintext is passed around. It must be wrapped in
+// Redact, or converted to a string if it's known not to be secret.
+func TestLogCallsRedactPlaintext(t *testing.T) {
+	if testing.Short() {
+		t.Skip("type checks all packages")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	const module = "github.com/gopasspw/gopass"
+	cmd := exec.Command(goBin, "list", "-e", "-export", "-deps", "-f",
+		"{{.ImportPath}}\t{{.Export}}\t{{.Dir}}\t{{join .GoFiles \",\"}}\t{{len .CgoFiles}}", module+"/...")
+	cmd.Stderr = os.Stderr
+	buf, err := cmd.Output()
+	require.NoError(t, err)
+
+	type pkg struct {
+		path    string
+		dir     string
+		files   []string
+		hasCgo  bool
+		hasDeps bool
+	}
+	exports := map[string]string{}
+	var pkgs []pkg
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		require.Len(t, fields, 5, s.Text())
+		exports[fields[0]] = fields[1]
+		if fields[0] != module && !strings.HasPrefix(fields[0], module+"/") || fields[0] == module+"/pkg/debug" {
+			continue
+		}
+		pkgs = append(pkgs, pkg{
+			path:    fields[0],
+			dir:     fields[2],
+			files:   strings.Split(fields[3], ","),
+			hasCgo:  fields[4] != "0",
+			hasDeps: fields[1] != "",
+		})
+	}
+	require.NoError(t, s.Err())
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		fn, found := exports[path]
+		if !found || fn == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(fn)
+	})
+
+	for _, p := range pkgs {
+		if p.hasCgo || !p.hasDeps || p.files[0] == "" {
+			t.Logf("skipping %s", p.path)
+			continue
+		}
+
+		files := make([]*ast.File, 0, len(p.files))
+		for _, fn := range p.files {
+			f, err := parser.ParseFile(fset, filepath.Join(p.dir, fn), nil, 0)
+			require.NoError(t, err)
+			files = append(files, f)
+		}
+
+		info := &types.Info{
+			Types: map[ast.Expr]types.TypeAndValue{},
+			Uses:  map[*ast.Ident]types.Object{},
+		}
+		conf := types.Config{Importer: imp}
+		_, err := conf.Check(p.path, fset, files, info)
+		require.NoError(t, err, p.path)
+
+		for _, f := range files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || !logFuncs[sel.Sel.Name] {
+					return true
+				}
+				fn, ok := info.Uses[sel.Sel].(*types.Func)
+				if !ok || fn.Pkg() == nil || fn.Pkg().Path() != module+"/pkg/debug" {
+					return true
+				}
+				for _, arg := range call.Args {
+					if isByteSlice(info.TypeOf(arg)) {
+						t.Errorf("%s: %s gets the byte slice %s. Wrap it in debug.Redact or convert it to a string if it's not secret",
+							fset.Position(arg.Pos()), sel.Sel.Name, types.ExprString(arg))
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+func isByteSlice(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	sl, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	b, ok := sl.Elem().Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Byte
+}
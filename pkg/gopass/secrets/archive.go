@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// OldPasswordPrefix starts the keys holding replaced passwords, followed by
+// the date they were replaced, e.g. old-password-2021-05-01
+const OldPasswordPrefix = "old-password-"
+
+// IsOldPassword returns true if the key holds a replaced password
+func IsOldPassword(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), OldPasswordPrefix)
+}
+
+// OldPasswords returns the keys of the replaced passwords, the oldest first
+func OldPasswords(sec gopass.Secret) []string {
+	var keys []string
+	for _, k := range sec.Keys() {
+		if IsOldPassword(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		bi, ni := splitCounter(keys[i])
+		bj, nj := splitCounter(keys[j])
+		if bi != bj {
+			return bi < bj
+		}
+		return ni < nj
+	})
+	return keys
+}
+
+// splitCounter splits the counter added to keys replaced at the same time,
+// e.g. old-password-2021-05-01-150405.2, from the key. Keys without one are
+// the first.
+func splitCounter(key string) (string, int) {
+	i := strings.LastIndex(key, ".")
+	if i < 0 {
+		return key, 1
+	}
+	n, err := strconv.Atoi(key[i+1:])
+	if err != nil {
+		return key, 1
+	}
+	return key[:i], n
+}
+
+// ArchivePassword stores the replaced password in a dated key of the secret.
+// Only the most recent max passwords are kept, the older ones are removed.
+func ArchivePassword(sec gopass.Secret, pw string, now time.Time, max int) error {
+	if pw == "" || max < 1 {
+		return nil
+	}
+
+	key := OldPasswordPrefix + now.Format("2006-01-02")
+	if _, found := sec.Get(key); found {
+		key += now.Format("-150405")
+	}
+	for i := 2; ; i++ {
+		if _, found := sec.Get(key); !found {
+			break
+		}
+		key = strings.TrimSuffix(key, "."+strconv.Itoa(i-1)) + "." + strconv.Itoa(i)
+	}
+	if err := sec.Set(key, pw); err != nil {
+		return err
+	}
+
+	old := OldPasswords(sec)
+	for len(old) > max {
+		sec.Del(old[0])
+		old = old[1:]
+	}
+	return nil
+}
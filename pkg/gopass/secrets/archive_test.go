@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchivePassword(t *testing.T) {
+	sec := NewKV()
+	sec.SetPassword("new")
+	require.NoError(t, sec.Set("user", "jane"))
+
+	now := time.Date(2021, 5, 1, 12, 30, 0, 0, time.UTC)
+	require.NoError(t, ArchivePassword(sec, "one", now, 3))
+	require.NoError(t, ArchivePassword(sec, "two", now, 3))
+	require.NoError(t, ArchivePassword(sec, "three", now, 3))
+	assert.Equal(t, []string{
+		"old-password-2021-05-01",
+		"old-password-2021-05-01-123000",
+		"old-password-2021-05-01-123000.2",
+	}, OldPasswords(sec))
+
+	require.NoError(t, ArchivePassword(sec, "four", now.AddDate(0, 0, 1), 3))
+	assert.Equal(t, []string{
+		"old-password-2021-05-01-123000",
+		"old-password-2021-05-01-123000.2",
+		"old-password-2021-05-02",
+	}, OldPasswords(sec))
+	v, _ := sec.Get("old-password-2021-05-02")
+	assert.Equal(t, "four", v)
+	v, _ = sec.Get("user")
+	assert.Equal(t, "jane", v)
+
+	// nothing to archive
+	require.NoError(t, ArchivePassword(sec, "", now, 3))
+	require.NoError(t, ArchivePassword(sec, "five", now, 0))
+	assert.Len(t, OldPasswords(sec), 3)
+
+	assert.True(t, IsOldPassword("Old-Password-2021-05-01"))
+	assert.False(t, IsOldPassword("password"))
+
+	assert.Error(t, ArchivePassword(ParsePlain([]byte("new")), "old", now, 3))
+}
+
+func TestOldPasswordsOrder(t *testing.T) {
+	sec := NewKV()
+	now := time.Date(2021, 5, 1, 12, 30, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		require.NoError(t, ArchivePassword(sec, "pw", now, 20))
+	}
+
+	old := OldPasswords(sec)
+	require.Len(t, old, 12)
+	assert.Equal(t, "old-password-2021-05-01-123000.9", old[9])
+	assert.Equal(t, "old-password-2021-05-01-123000.10", old[10])
+	assert.Equal(t, "old-password-2021-05-01-123000.11", old[11])
+
+	// the oldest are removed first
+	require.NoError(t, ArchivePassword(sec, "pw", now, 2))
+	assert.Equal(t, []string{
+		"old-password-2021-05-01-123000.11",
+		"old-password-2021-05-01-123000.12",
+	}, OldPasswords(sec))
+}
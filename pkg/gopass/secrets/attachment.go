@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+const (
+	// ContentSHA256 is the header of an attachment holding the hex encoded
+	// SHA-256 checksum of its decoded content
+	ContentSHA256 = "Content-SHA256"
+)
+
+// ErrChecksumMismatch is returned if the content of an attachment doesn't
+// match its checksum
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// IsAttachment returns true if the secret contains a base64 encoded file,
+// e.g. one created by gopass fscopy or cat
+func IsAttachment(sec gopass.Secret) bool {
+	cte, _ := sec.Get("content-transfer-encoding")
+	return strings.EqualFold(cte, "base64")
+}
+
+// AttachmentContent returns the decoded content of an attachment
+func AttachmentContent(sec gopass.Secret) ([]byte, error) {
+	// the encoded content may be wrapped, e.g. if it was edited
+	body := strings.Join(strings.Fields(sec.Body()), "")
+	buf, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	return buf, nil
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the content
+func Checksum(buf []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(buf))
+}
+
+// VerifyChecksum compares the decoded content of an attachment with its
+// checksum. It returns false if the attachment has no checksum.
+func VerifyChecksum(sec gopass.Secret, content []byte) (bool, error) {
+	want, found := sec.Get(ContentSHA256)
+	if !found {
+		return false, nil
+	}
+	if have := Checksum(content); !strings.EqualFold(strings.TrimSpace(want), have) {
+		return true, fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, want, have)
+	}
+	return true, nil
+}
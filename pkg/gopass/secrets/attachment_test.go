@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachment(t *testing.T) {
+	in := "\ncontent-disposition: attachment; filename=\"foo.txt\"\ncontent-transfer-encoding: Base64\nZm9vYmFy\n"
+	sec, err := ParseKV([]byte(in))
+	require.NoError(t, err)
+	assert.True(t, IsAttachment(sec))
+	assert.False(t, IsAttachment(ParsePlain([]byte("foo\nbar\n"))))
+
+	buf, err := AttachmentContent(sec)
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", string(buf))
+
+	found, err := VerifyChecksum(sec, buf)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	sum := Checksum(buf)
+	assert.Equal(t, "c3ab8ff13720e8ad9047dd39466b3c8974e592c2fa383d4a3960714caef0c4f2", sum)
+	require.NoError(t, sec.Set(ContentSHA256, sum))
+	found, err = VerifyChecksum(sec, buf)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = VerifyChecksum(sec, []byte("foobaz"))
+	assert.True(t, found)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	t.Run("wrapped content", func(t *testing.T) {
+		sec, err := ParseKV([]byte("\ncontent-transfer-encoding: base64\nZm9v\nYmFy\n"))
+		require.NoError(t, err)
+		buf, err := AttachmentContent(sec)
+		require.NoError(t, err)
+		assert.Equal(t, "foobar", string(buf))
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		sec, err := ParseKV([]byte("\ncontent-transfer-encoding: base64\nnot base64!\n"))
+		require.NoError(t, err)
+		_, err = AttachmentContent(sec)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,300 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+const (
+	// maxHeaderLine is the longest header line of an attachment read while
+	// streaming
+	maxHeaderLine = 64 * 1024
+	// maxKeyPrefix is the longest run of base64 characters a header line
+	// can start with. Longer lines are part of the encoded content.
+	maxKeyPrefix = 1024
+)
+
+// NewAttachmentEncoder returns a reader for an attachment secret holding the
+// content read from r, like the ones created by gopass fscopy. The content is
+// base64 encoded while it's read, so it's never held in memory. The checksum
+// header follows the content since it's only known at its end.
+func NewAttachmentEncoder(filename string, r io.Reader) io.Reader {
+	a := &attachmentEncoder{
+		src:  r,
+		hash: sha256.New(),
+	}
+	fmt.Fprintf(&a.buf, "\ncontent-disposition: attachment; filename=\"%s\"\ncontent-transfer-encoding: Base64\n", filename)
+	a.enc = base64.NewEncoder(base64.StdEncoding, &a.buf)
+	return a
+}
+
+type attachmentEncoder struct {
+	src   io.Reader
+	hash  hash.Hash
+	enc   io.WriteCloser
+	buf   bytes.Buffer
+	chunk [32 * 1024]byte
+	done  bool
+}
+
+func (a *attachmentEncoder) Read(p []byte) (int, error) {
+	for a.buf.Len() < 1 {
+		if a.done {
+			return 0, io.EOF
+		}
+		n, err := a.src.Read(a.chunk[:])
+		if n > 0 {
+			_, _ = a.hash.Write(a.chunk[:n])
+			_, _ = a.enc.Write(a.chunk[:n])
+		}
+		if err == io.EOF {
+			_ = a.enc.Close()
+			fmt.Fprintf(&a.buf, "\n%s: %x", strings.ToLower(ContentSHA256), a.hash.Sum(nil))
+			a.done = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return a.buf.Read(p)
+}
+
+// AttachmentReader decodes an attachment secret while it's read, e.g. from
+// the plaintext of a large secret being decrypted. The headers before the
+// content are read by NewAttachmentReader, those after it, e.g. the checksum
+// written by NewAttachmentEncoder, once the content has been read.
+type AttachmentReader struct {
+	br      *bufio.Reader
+	head    []byte
+	pending []byte
+	headers map[string]string
+	dec     io.Reader
+	hash    hash.Hash
+	err     error
+}
+
+// NewAttachmentReader reads the headers of the secret read from r. Read only
+// works if it's an attachment, see IsAttachment. Otherwise Raw returns the
+// secret as read from r.
+func NewAttachmentReader(r io.Reader) (*AttachmentReader, error) {
+	a := &AttachmentReader{
+		br:      bufio.NewReaderSize(r, maxHeaderLine),
+		headers: map[string]string{},
+		hash:    sha256.New(),
+	}
+
+	// the password line
+	line, err := a.br.ReadSlice('\n')
+	a.head = append(a.head, line...)
+	if err != nil {
+		return a, a.headErr(err)
+	}
+	for {
+		line, err := a.br.ReadSlice('\n')
+		if err == nil || err == io.EOF {
+			if key, value, ok := parseLine(strings.TrimRight(string(line), "\r\n")); ok {
+				a.head = append(a.head, line...)
+				a.addHeader(key, value)
+				if err == io.EOF {
+					break
+				}
+				continue
+			}
+		} else if err != bufio.ErrBufferFull {
+			return a, err
+		}
+		// the first line of the content
+		a.pending = append(a.pending, line...)
+		break
+	}
+
+	a.dec = base64.NewDecoder(base64.StdEncoding, &bodyFilter{r: io.MultiReader(bytes.NewReader(a.pending), a.br), headers: a.addHeader})
+	return a, nil
+}
+
+// headErr ignores the end of a secret consisting of the password only
+func (a *AttachmentReader) headErr(err error) error {
+	if err == io.EOF || err == bufio.ErrBufferFull {
+		return nil
+	}
+	return err
+}
+
+func (a *AttachmentReader) addHeader(key, value string) {
+	// like Get the first value wins
+	if _, found := a.headers[key]; !found {
+		a.headers[key] = value
+	}
+}
+
+// IsAttachment returns true if the secret contains a base64 encoded file
+func (a *AttachmentReader) IsAttachment() bool {
+	cte, _ := a.Get("content-transfer-encoding")
+	return a.dec != nil && strings.EqualFold(cte, "base64")
+}
+
+// Get returns the value of a header. Headers after the content are only
+// available once it has been read.
+func (a *AttachmentReader) Get(key string) (string, bool) {
+	v, found := a.headers[strings.ToLower(key)]
+	return v, found
+}
+
+// Raw returns the secret as read from r. It must not be used after Read.
+func (a *AttachmentReader) Raw() io.Reader {
+	return io.MultiReader(bytes.NewReader(a.head), bytes.NewReader(a.pending), a.br)
+}
+
+// Read reads the decoded content. At its end the checksum is verified, if
+// the attachment has one. A mismatch is reported as ErrChecksumMismatch
+// instead of io.EOF.
+func (a *AttachmentReader) Read(p []byte) (int, error) {
+	if a.err != nil {
+		return 0, a.err
+	}
+	if !a.IsAttachment() {
+		return 0, fmt.Errorf("not an attachment")
+	}
+
+	n, err := a.dec.Read(p)
+	_, _ = a.hash.Write(p[:n])
+	switch {
+	case err == io.EOF:
+		a.err = a.verify()
+	case err != nil:
+		a.err = fmt.Errorf("failed to decode base64: %w", err)
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, a.err
+}
+
+func (a *AttachmentReader) verify() error {
+	want, found := a.Get(ContentSHA256)
+	if !found {
+		return io.EOF
+	}
+	if have := fmt.Sprintf("%x", a.hash.Sum(nil)); !strings.EqualFold(strings.TrimSpace(want), have) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, want, have)
+	}
+	return io.EOF
+}
+
+// bodyFilter passes the encoded content without any whitespace and hands
+// header lines, e.g. the checksum after the content, to headers. A line is a
+// header if it contains a character that's not valid base64 before the
+// content could have started.
+type bodyFilter struct {
+	r       io.Reader
+	headers func(key, value string)
+	// line holds the start of the current line until it's known whether
+	// it's a header
+	line    []byte
+	header  bool
+	content bool
+	chunk   [32 * 1024]byte
+	// out holds the filtered content, it's read from off on
+	out []byte
+	off int
+	eof bool
+}
+
+func (f *bodyFilter) Read(p []byte) (int, error) {
+	for f.off >= len(f.out) {
+		if f.eof {
+			return 0, io.EOF
+		}
+		f.out = f.out[:0]
+		f.off = 0
+		n, err := f.r.Read(f.chunk[:])
+		for _, c := range f.chunk[:n] {
+			if ferr := f.add(c); ferr != nil {
+				return 0, ferr
+			}
+		}
+		if err == io.EOF {
+			f.endLine()
+			f.eof = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, f.out[f.off:])
+	f.off += n
+	return n, nil
+}
+
+func (f *bodyFilter) add(c byte) error {
+	if c == '\n' {
+		f.endLine()
+		return nil
+	}
+	switch {
+	case f.content:
+		if !isSpace(c) {
+			f.out = append(f.out, c)
+		}
+		return nil
+	case f.header:
+		if len(f.line) >= maxHeaderLine {
+			return fmt.Errorf("header line too long")
+		}
+		f.line = append(f.line, c)
+		return nil
+	}
+
+	f.line = append(f.line, c)
+	switch {
+	case !isBase64(c) && !isSpace(c):
+		f.header = true
+	case len(f.line) > maxKeyPrefix:
+		f.flush()
+	}
+	return nil
+}
+
+// endLine finishes the current line
+func (f *bodyFilter) endLine() {
+	if f.header {
+		if key, value, ok := parseLine(strings.TrimSpace(string(f.line))); ok {
+			f.headers(key, value)
+		} else {
+			// e.g. invalid characters, the decoder reports them
+			f.out = append(f.out, f.line...)
+		}
+	} else {
+		f.flush()
+	}
+	f.line = f.line[:0]
+	f.header = false
+	f.content = false
+}
+
+// flush passes the start of the line to the decoder, it's part of the content
+func (f *bodyFilter) flush() {
+	for _, c := range f.line {
+		if !isSpace(c) {
+			f.out = append(f.out, c)
+		}
+	}
+	f.line = f.line[:0]
+	f.content = true
+}
+
+func isBase64(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '+' || c == '/' || c == '='
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f'
+}
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentEncoder(t *testing.T) {
+	content := bytes.Repeat([]byte("foobar\n"), 10000)
+	buf, err := io.ReadAll(NewAttachmentEncoder("foo.txt", bytes.NewReader(content)))
+	require.NoError(t, err)
+
+	// the encoded secret can be parsed like any other attachment
+	sec, err := ParseKV(buf)
+	require.NoError(t, err)
+	assert.True(t, IsAttachment(sec))
+	filename, _ := sec.Get("content-disposition")
+	assert.Equal(t, `attachment; filename="foo.txt"`, filename)
+	got, err := AttachmentContent(sec)
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+	found, err := VerifyChecksum(sec, got)
+	assert.True(t, found)
+	assert.NoError(t, err)
+}
+
+func TestAttachmentReader(t *testing.T) {
+	content := bytes.Repeat([]byte("foobar\n"), 10000)
+	enc, err := io.ReadAll(NewAttachmentEncoder("foo.txt", bytes.NewReader(content)))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name string
+		in   []byte
+	}{
+		{
+			name: "checksum after the content",
+			in:   enc,
+		},
+		{
+			name: "checksum before the content",
+			in:   []byte("\ncontent-disposition: attachment; filename=\"foo.txt\"\ncontent-transfer-encoding: Base64\ncontent-sha256: c3ab8ff13720e8ad9047dd39466b3c8974e592c2fa383d4a3960714caef0c4f2\nZm9vYmFy"),
+		},
+		{
+			name: "wrapped content",
+			in:   []byte("\ncontent-transfer-encoding: base64\nZm9v\r\n  YmFy\n\nfoo: bar\n"),
+		},
+		{
+			name: "no checksum",
+			in:   []byte("\ncontent-transfer-encoding: base64\nZm9vYmFy"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sec, err := ParseKV(tc.in)
+			require.NoError(t, err)
+			want, err := AttachmentContent(sec)
+			require.NoError(t, err)
+
+			ar, err := NewAttachmentReader(bytes.NewReader(tc.in))
+			require.NoError(t, err)
+			assert.True(t, ar.IsAttachment())
+			got, err := io.ReadAll(ar)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("headers", func(t *testing.T) {
+		ar, err := NewAttachmentReader(bytes.NewReader(enc))
+		require.NoError(t, err)
+		v, found := ar.Get("Content-Disposition")
+		assert.True(t, found)
+		assert.Equal(t, `attachment; filename="foo.txt"`, v)
+		_, found = ar.Get(ContentSHA256)
+		assert.False(t, found)
+
+		_, err = io.Copy(io.Discard, ar)
+		require.NoError(t, err)
+		_, found = ar.Get(ContentSHA256)
+		assert.True(t, found)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		in := bytes.Replace(enc, []byte("Zm9v"), []byte("Zm9w"), 1)
+		ar, err := NewAttachmentReader(bytes.NewReader(in))
+		require.NoError(t, err)
+		_, err = io.ReadAll(ar)
+		assert.True(t, errors.Is(err, ErrChecksumMismatch), err)
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		ar, err := NewAttachmentReader(strings.NewReader("\ncontent-transfer-encoding: base64\nnot base64!\n"))
+		require.NoError(t, err)
+		_, err = io.ReadAll(ar)
+		assert.Error(t, err)
+	})
+
+	t.Run("no attachment", func(t *testing.T) {
+		for _, in := range []string{
+			"password",
+			"password\nuser: foo\nsome notes\n",
+			"\n" + strings.Repeat("x", 2*maxHeaderLine),
+		} {
+			ar, err := NewAttachmentReader(strings.NewReader(in))
+			require.NoError(t, err)
+			assert.False(t, ar.IsAttachment())
+			raw, err := io.ReadAll(ar.Raw())
+			require.NoError(t, err)
+			assert.Equal(t, in, string(raw))
+		}
+	})
+}
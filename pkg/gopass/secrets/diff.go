@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gopasspw/gopass/pkg/gopass"
+)
+
+// Names of the fields of a Changeset that are not keys
+const (
+	FieldPassword = "Password"
+	FieldBody     = "Body"
+)
+
+// States of a field in a Changeset
+const (
+	Unchanged = "unchanged"
+	Added     = "added"
+	Removed   = "removed"
+	Changed   = "changed"
+)
+
+// FieldChange describes how a single field of a secret changed. Old and New
+// are the values, multiple values of a key are separated by newlines. They
+// must not be displayed unless the user asked for it.
+type FieldChange struct {
+	Field string
+	State string
+	Old   string
+	New   string
+}
+
+// Changeset describes the differences between two versions of a secret. It
+// contains the password, all keys ordered by name and the body, in this
+// order.
+type Changeset []FieldChange
+
+// Changes returns only the fields that have changed
+func (c Changeset) Changes() []FieldChange {
+	res := make([]FieldChange, 0, len(c))
+	for _, fc := range c {
+		if fc.State != Unchanged {
+			res = append(res, fc)
+		}
+	}
+	return res
+}
+
+// IsChanged returns true if any field has changed
+func (c Changeset) IsChanged() bool {
+	return len(c.Changes()) > 0
+}
+
+// Diff compares the password, all keys and the body of two versions of a
+// secret. A nil secret is treated as empty. Plain secrets are compared as KV
+// secrets, otherwise their keys would only show up as a changed body.
+func Diff(old, new gopass.Secret) Changeset {
+	old, new = diffable(old), diffable(new)
+
+	cs := Changeset{diffField(FieldPassword, []string{old.Password()}, true, []string{new.Password()}, true)}
+
+	keys := make(map[string]struct{}, len(old.Keys()))
+	for _, k := range append(old.Keys(), new.Keys()...) {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	for _, k := range sorted {
+		ov, ook := old.Values(k)
+		nv, nok := new.Values(k)
+		cs = append(cs, diffField(k, ov, ook, nv, nok))
+	}
+
+	return append(cs, diffField(FieldBody, []string{old.Body()}, true, []string{new.Body()}, true))
+}
+
+func diffable(sec gopass.Secret) gopass.Secret {
+	if sec == nil {
+		return New()
+	}
+	p, ok := sec.(*Plain)
+	if !ok {
+		return sec
+	}
+	buf := append([]byte{}, p.Bytes()...)
+	if len(buf) < 1 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+	kv, err := ParseKV(buf)
+	if err != nil {
+		return sec
+	}
+	return kv
+}
+
+func diffField(field string, old []string, oldOK bool, new []string, newOK bool) FieldChange {
+	fc := FieldChange{Field: field, Old: strings.Join(old, "\n"), New: strings.Join(new, "\n")}
+	switch {
+	case !oldOK:
+		fc.State = Added
+	case !newOK:
+		fc.State = Removed
+	case fc.Old != fc.New:
+		fc.State = Changed
+	default:
+		fc.State = Unchanged
+	}
+	return fc
+}
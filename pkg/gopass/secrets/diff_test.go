@@ -0,0 +1,55 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := NewKV()
+	old.SetPassword("foo")
+	assert.NoError(t, old.Set("user", "jane"))
+	assert.NoError(t, old.Set("url", "example.com"))
+	assert.NoError(t, old.Set("port", "22"))
+
+	new := NewKV()
+	new.SetPassword("bar")
+	assert.NoError(t, new.Set("user", "jane"))
+	assert.NoError(t, new.Set("port", "2222"))
+	assert.NoError(t, new.Add("tag", "a"))
+	assert.NoError(t, new.Add("tag", "b"))
+
+	cs := Diff(old, new)
+	assert.Equal(t, Changeset{
+		{Field: FieldPassword, State: Changed, Old: "foo", New: "bar"},
+		{Field: "port", State: Changed, Old: "22", New: "2222"},
+		{Field: "tag", State: Added, New: "a\nb"},
+		{Field: "url", State: Removed, Old: "example.com"},
+		{Field: "user", State: Unchanged, Old: "jane", New: "jane"},
+		{Field: FieldBody, State: Unchanged},
+	}, cs)
+	assert.True(t, cs.IsChanged())
+	assert.Len(t, cs.Changes(), 4)
+
+	assert.False(t, Diff(old, old).IsChanged())
+}
+
+func TestDiffPlain(t *testing.T) {
+	old := ParsePlain([]byte("foo\nuser: jane"))
+	new := ParsePlain([]byte("foo\nuser: john\nsome notes\n"))
+
+	assert.Equal(t, []FieldChange{
+		{Field: "user", State: Changed, Old: "jane", New: "john"},
+		{Field: FieldBody, State: Changed, Old: "", New: "some notes\n"},
+	}, Diff(old, new).Changes())
+}
+
+func TestDiffNil(t *testing.T) {
+	new := ParsePlain([]byte("foo\nuser: jane\n"))
+
+	assert.Equal(t, []FieldChange{
+		{Field: FieldPassword, State: Changed, New: "foo"},
+		{Field: "user", State: Added, New: "jane"},
+	}, Diff(nil, new).Changes())
+}
@@ -16,21 +16,27 @@ var _ gopass.Secret = &KV{}
 
 // NewKV creates a new KV secret
 func NewKV() *KV {
-	return &KV{
-		data: make(map[string][]string, 10),
-	}
+	return &KV{}
 }
 
 // NewKVWithData returns a new KV secret populated with data
 func NewKVWithData(pw string, kvps map[string][]string, body string, converted bool) *KV {
 	kv := &KV{
 		password: pw,
-		data:     make(map[string][]string, len(kvps)),
-		body:     body,
 		fromMime: converted,
 	}
-	for k, v := range kvps {
-		kv.data[k] = v
+	keys := make([]string, 0, len(kvps))
+	for k := range kvps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range kvps[k] {
+			kv.lines = append(kv.lines, kvLine(strings.ToLower(k), v))
+		}
+	}
+	if body != "" {
+		kv.lines = append(kv.lines, strings.Split(body, "\n")...)
 	}
 	return kv
 }
@@ -70,45 +76,51 @@ func NewKVWithData(pw string, kvps map[string][]string, body string, converted b
 //     - "hello": "world"
 //     - "gopass": "secret"
 //   - body: "Yo\nHi"
+//
+// Key-value pairs and body lines may be interleaved. All lines are kept in
+// their original order and format, so changing one key doesn't affect any
+// other line of the secret.
 type KV struct {
 	password string
-	data     map[string][]string
-	body     string
+	// lines contains everything after the password, i.e. key-value pairs
+	// and body lines, without line endings
+	lines    []string
 	fromMime bool
 }
 
+// kvLine formats a single key-value pair
+func kvLine(key, value string) string {
+	return key + ": " + value
+}
+
+// parseLine returns the lower case key and the value of a key-value line
+func parseLine(line string) (string, string, bool) {
+	if !strings.Contains(line, ":") {
+		return "", "", false
+	}
+	parts := strings.SplitN(line, ":", 2)
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1]), true
+}
+
 // Bytes serializes
 func (k *KV) Bytes() []byte {
 	buf := &bytes.Buffer{}
 	buf.WriteString(k.password)
 	buf.WriteString("\n")
-	for ik, key := range k.Keys() {
-		sv, ok := k.data[key]
-		if !ok {
-			continue
-		}
-		for iv, v := range sv {
-			_, _ = buf.WriteString(key)
-			_, _ = buf.WriteString(": ")
-			_, _ = buf.WriteString(v)
-			// the last one shouldn't add a newline, it's handled below
-			if iv < len(sv)-1 {
-				_, _ = buf.WriteString("\n")
-			}
-		}
-		// we must only add a final newline if the body is non-empty
-		if k.body != "" || ik < len(k.Keys())-1 {
-			_, _ = buf.WriteString("\n")
-		}
-	}
-	buf.WriteString(k.body)
+	buf.WriteString(strings.Join(k.lines, "\n"))
 	return buf.Bytes()
 }
 
 // Keys returns all keys
 func (k *KV) Keys() []string {
-	keys := make([]string, 0, len(k.data)+1)
-	for key := range k.data {
+	keys := make([]string, 0, len(k.lines))
+	seen := make(map[string]bool, len(k.lines))
+	for _, line := range k.lines {
+		key, _, ok := parseLine(line)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
 		keys = append(keys, key)
 	}
 	sort.Strings(keys)
@@ -117,55 +129,114 @@ func (k *KV) Keys() []string {
 
 // Get returns the first value of that key
 func (k *KV) Get(key string) (string, bool) {
-	key = strings.ToLower(key)
-
-	if v, found := k.data[key]; found {
+	if v, found := k.Values(key); found {
 		return v[0], true
 	}
 
 	return "", false
 }
 
-// Values returns all values for that key
+// Values returns all values for that key, in the order they appear in the secret
 func (k *KV) Values(key string) ([]string, bool) {
 	key = strings.ToLower(key)
-	v, found := k.data[key]
-	return v, found
+	var values []string
+	for _, line := range k.lines {
+		if lk, v, ok := parseLine(line); ok && lk == key {
+			values = append(values, v)
+		}
+	}
+	return values, len(values) > 0
+}
+
+// indices returns the line numbers of the given key and of the last
+// key-value line in the secret, or -1 if there is none
+func (k *KV) indices(key string) ([]int, int) {
+	var idx []int
+	last := -1
+	for i, line := range k.lines {
+		lk, _, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		last = i
+		if lk == key {
+			idx = append(idx, i)
+		}
+	}
+	return idx, last
 }
 
-// Set writes a single key
+// insert adds a line after the given line number
+func (k *KV) insert(after int, line string) {
+	k.lines = append(k.lines, "")
+	copy(k.lines[after+2:], k.lines[after+1:])
+	k.lines[after+1] = line
+}
+
+// Set writes a single key. An existing key is updated in place, a new key
+// is added after the last key-value pair.
 func (k *KV) Set(key string, value interface{}) error {
 	key = strings.ToLower(key)
-	if v, ok := k.data[key]; ok && len(v) > 1 {
+	idx, last := k.indices(key)
+	if len(idx) > 1 {
 		return fmt.Errorf("cannot set key %s: this entry contains multiple same keys. Please use 'gopass edit' instead", key)
 	}
-	k.data[key] = []string{fmt.Sprintf("%s", value)}
+	if len(idx) == 1 {
+		// keep the spelling of the key
+		orig := strings.TrimSpace(strings.SplitN(k.lines[idx[0]], ":", 2)[0])
+		k.lines[idx[0]] = kvLine(orig, fmt.Sprintf("%s", value))
+		return nil
+	}
+	k.insert(last, kvLine(key, fmt.Sprintf("%s", value)))
 	return nil
 }
 
-// Add appends data to a given key
+// Add appends data to a given key. The new value is added after the last
+// value of that key.
 func (k *KV) Add(key string, value interface{}) error {
 	key = strings.ToLower(key)
-	k.data[key] = append(k.data[key], fmt.Sprintf("%s", value))
+	idx, last := k.indices(key)
+	if len(idx) > 0 {
+		last = idx[len(idx)-1]
+	}
+	k.insert(last, kvLine(key, fmt.Sprintf("%s", value)))
 	return nil
 }
 
 // Del removes a given key and all of its values
 func (k *KV) Del(key string) bool {
 	key = strings.ToLower(key)
-	_, found := k.data[key]
-	delete(k.data, key)
+	lines := k.lines[:0]
+	found := false
+	for _, line := range k.lines {
+		if lk, _, ok := parseLine(line); ok && lk == key {
+			found = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	k.lines = lines
 	return found
 }
 
-// Body returns the body
+// Body returns the body, i.e. all lines that are not key-value pairs
 func (k *KV) Body() string {
-	return k.body
+	var sb strings.Builder
+	for i, line := range k.lines {
+		if _, _, ok := parseLine(line); ok {
+			continue
+		}
+		sb.WriteString(line)
+		if i < len(k.lines)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
 }
 
 // Password returns the password
 func (k *KV) Password() string {
-	return k.password
+	return passwordLine(k.password)
 }
 
 // SetPassword updates the password
@@ -175,9 +246,7 @@ func (k *KV) SetPassword(p string) {
 
 // ParseKV tries to parse a KV secret
 func ParseKV(in []byte) (*KV, error) {
-	k := &KV{
-		data: make(map[string][]string, 10),
-	}
+	k := &KV{}
 	r := bufio.NewReader(bytes.NewReader(in))
 	line, err := r.ReadString('\n')
 	if err != nil {
@@ -185,50 +254,30 @@ func ParseKV(in []byte) (*KV, error) {
 	}
 	k.password = strings.TrimRight(line, "\n")
 
-	var sb strings.Builder
-	for {
-		line, err := r.ReadString('\n')
-		if err != nil && line == "" {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
-		// append non KV pairs to the body
-		if !strings.Contains(line, ":") {
-			sb.WriteString(line)
-			continue
-		}
-		line = strings.TrimRight(line, "\n")
-
-		parts := strings.SplitN(line, ":", 2)
-		// should not happen
-		if len(parts) < 1 {
-			continue
-		}
-		for i, part := range parts {
-			parts[i] = strings.TrimSpace(part)
-		}
-		// we only store lower case keys for KV
-		parts[0] = strings.ToLower(parts[0])
-		// preserve key only entries
-		if len(parts) < 2 {
-			k.data[parts[0]] = append(k.data[parts[0]], "")
-			continue
-		}
-
-		k.data[parts[0]] = append(k.data[parts[0]], parts[1])
+	rest, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
 	}
-	if len(k.data) < 1 {
+	if len(rest) > 0 {
+		k.lines = strings.Split(string(rest), "\n")
+	}
+	if len(k.Keys()) < 1 {
 		debug.Log("no KV entries")
 	}
-	k.body = sb.String()
 	return k, nil
 }
 
 // Write appends the buffer to the secret's body
 func (k *KV) Write(buf []byte) (int, error) {
-	k.body += string(buf)
+	rest := strings.Join(k.lines, "\n")
+	if n := len(k.lines); n > 0 {
+		// the body must not continue the last line
+		if k.lines[n-1] != "" {
+			rest += "\n"
+		}
+	}
+	rest += string(buf)
+	k.lines = strings.Split(rest, "\n")
 	return len(buf), nil
 }
 
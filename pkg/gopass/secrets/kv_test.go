@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,14 +26,8 @@ password: bar
 
 	t.Logf("Secret:\n%+v\n%s\n", s, string(s.Bytes()))
 
-	mlOut := `somepasswd
-password: bar
-url: http://www.test.com/
-username: myuser@test.com
-Test / test.com
-`
 	t.Run("read back the secret", func(t *testing.T) {
-		assert.Equal(t, mlOut, string(s.Bytes()))
+		assert.Equal(t, mlValue, string(s.Bytes()))
 	})
 
 	t.Run("no_duplicate_keys", func(t *testing.T) {
@@ -91,3 +86,114 @@ zab: 123`
 	require.NoError(t, err)
 	assert.Equal(t, in, string(sec.Bytes()))
 }
+
+func TestKVRoundTrip(t *testing.T) {
+	in := `s3cret
+Notes for the admin account
+URL:http://www.example.org/
+user: jane
+Pin :  1234
+
+some: more
+  indented text
+user: john`
+
+	t.Run("unchanged", func(t *testing.T) {
+		sec, err := ParseKV([]byte(in))
+		require.NoError(t, err)
+		assert.Equal(t, in, string(sec.Bytes()))
+		assert.Equal(t, []string{"pin", "some", "url", "user"}, sec.Keys())
+		assert.Equal(t, "Notes for the admin account\n\n  indented text\n", sec.Body())
+
+		v, found := sec.Get("PIN")
+		assert.True(t, found)
+		assert.Equal(t, "1234", v)
+	})
+
+	t.Run("multiple values in order", func(t *testing.T) {
+		sec, err := ParseKV([]byte(in))
+		require.NoError(t, err)
+		vs, found := sec.Values("user")
+		assert.True(t, found)
+		assert.Equal(t, []string{"jane", "john"}, vs)
+		assert.Error(t, sec.Set("user", "joe"))
+		assert.Equal(t, in, string(sec.Bytes()))
+
+		assert.NoError(t, sec.Add("url", "https://example.com/"))
+		assert.Contains(t, string(sec.Bytes()), "URL:http://www.example.org/\nurl: https://example.com/\nuser: jane\n")
+	})
+
+	for _, tc := range []struct {
+		name string
+		fn   func(*KV) error
+		out  string
+	}{
+		{
+			name: "set existing key",
+			fn: func(sec *KV) error {
+				return sec.Set("url", "https://example.com/")
+			},
+			out: strings.Replace(in, "URL:http://www.example.org/", "URL: https://example.com/", 1),
+		},
+		{
+			name: "set new key",
+			fn: func(sec *KV) error {
+				return sec.Set("email", "jane@example.org")
+			},
+			out: in + "\nemail: jane@example.org",
+		},
+		{
+			name: "delete key",
+			fn: func(sec *KV) error {
+				sec.Del("pin")
+				return nil
+			},
+			out: strings.Replace(in, "Pin :  1234\n", "", 1),
+		},
+		{
+			name: "change password",
+			fn: func(sec *KV) error {
+				sec.SetPassword("new")
+				return nil
+			},
+			out: "new" + strings.TrimPrefix(in, "s3cret"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sec, err := ParseKV([]byte(in))
+			require.NoError(t, err)
+			require.NoError(t, tc.fn(sec))
+			assert.Equal(t, tc.out, string(sec.Bytes()))
+
+			// and parsing the result again must give the same secret
+			sec2, err := ParseKV(sec.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, string(sec2.Bytes()))
+		})
+	}
+}
+
+func TestKVNew(t *testing.T) {
+	sec := NewKV()
+	sec.SetPassword("foo")
+	assert.NoError(t, sec.Set("b", "1"))
+	assert.NoError(t, sec.Set("a", "2"))
+	_, err := sec.Write([]byte("body\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, sec.Set("c", "3"))
+	assert.Equal(t, "foo\nb: 1\na: 2\nc: 3\nbody\n", string(sec.Bytes()))
+	assert.Equal(t, "body\n", sec.Body())
+
+	sec = NewKVWithData("foo", map[string][]string{"b": {"1", "2"}, "a": {"3"}}, "body", false)
+	assert.Equal(t, "foo\na: 3\nb: 1\nb: 2\nbody", string(sec.Bytes()))
+
+	// written lines never continue the last one
+	_, err = sec.Write([]byte("more\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo\na: 3\nb: 1\nb: 2\nbody\nmore\n", string(sec.Bytes()))
+
+	sec, err = ParseKV([]byte("\ufefffoo\r\na: 1\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", sec.Password())
+	assert.Equal(t, "\ufefffoo\r\na: 1\r\n", string(sec.Bytes()))
+}
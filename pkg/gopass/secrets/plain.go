@@ -69,7 +69,16 @@ func (p *Plain) Values(key string) ([]string, bool) {
 func (p *Plain) Password() string {
 	br := bufio.NewReader(bytes.NewReader(p.buf))
 	pw, _ := br.ReadString('\n')
-	return strings.TrimSuffix(pw, "\n")
+	return passwordLine(pw)
+}
+
+// passwordLine removes the line break from the first line of a secret, as
+// well as a byte order mark in front of it. Both are kept in the secret, e.g.
+// if it has been inserted from a file with Windows line endings.
+func passwordLine(line string) string {
+	line = strings.TrimPrefix(line, "\ufeff")
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r")
 }
 
 // Set does nothing
@@ -26,7 +26,20 @@ func TestParsePlain(t *testing.T) {
 			pw:   "moar",
 			body: "",
 		},
+		{
+			desc: "windows line endings",
+			in:   "moar\r\nbody\r\n",
+			pw:   "moar",
+			body: "body\r\n",
+		},
+		{
+			desc: "byte order mark",
+			in:   "\ufeffmoar\nbody",
+			pw:   "moar",
+			body: "body",
+		},
 	} {
+		tc := tc
 		t.Run(tc.desc, func(t *testing.T) {
 			t.Parallel()
 			sec := ParsePlain([]byte(tc.in))
@@ -30,31 +30,69 @@ var _ gopass.Secret = &YAML{}
 //  1-n | Body
 //  n+1 | Separator ("---")
 //  n+2 | YAML content
+//
+// Nested keys can be accessed with dotted paths, e.g. "db.host". The YAML
+// document is kept verbatim until it's modified. Modifications keep the order
+// of the keys and any comments.
 type YAML struct {
 	password string
-	data     map[string]interface{}
+	doc      *yaml.Node // document node
+	raw      []byte     // the unmodified YAML section, including the separator
+	indent   int
 	body     string
 }
 
-// Keys returns all keys
+// root returns the top level mapping node of the document, creating it if
+// necessary
+func (y *YAML) root() *yaml.Node {
+	if y.doc == nil || len(y.doc.Content) < 1 {
+		y.doc = &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	return y.doc.Content[0]
+}
+
+// data returns the decoded YAML document
+func (y *YAML) data() map[string]interface{} {
+	data := make(map[string]interface{})
+	if y.doc == nil || len(y.doc.Content) < 1 {
+		return data
+	}
+	if err := y.doc.Decode(&data); err != nil {
+		debug.Log("failed to decode YAML: %s", err)
+	}
+	return data
+}
+
+// Keys returns all top level keys
 func (y *YAML) Keys() []string {
-	keys := make([]string, 0, len(y.data)+1)
-	for key := range y.data {
-		keys = append(keys, key)
+	if y.doc == nil {
+		return []string{}
+	}
+	root := y.root()
+	keys := make([]string, 0, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keys = append(keys, root.Content[i].Value)
 	}
 	sort.Strings(keys)
 	return keys
 }
 
-// Get returns the first value of a single key
+// Get returns the first value of a single key. Nested keys can be accessed
+// with dotted paths, e.g. "db.host".
 func (y *YAML) Get(key string) (string, bool) {
-	if y.data == nil {
-		y.data = make(map[string]interface{})
+	if y.doc == nil {
+		return "", false
 	}
-	if v, found := y.data[key]; found {
-		return fmt.Sprintf("%v", v), found
+	if _, vn := lookup(y.root(), key); vn != nil {
+		var v interface{}
+		if err := vn.Decode(&v); err == nil {
+			return fmt.Sprintf("%v", v), true
+		}
 	}
-	if v, err := yamlpath.YamlPath(y.data, key); err == nil && v != nil {
+	if v, err := yamlpath.YamlPath(y.data(), key); err == nil && v != nil {
 		return fmt.Sprintf("%v", v), true
 	}
 	return "", false
@@ -66,12 +104,40 @@ func (y *YAML) Values(key string) ([]string, bool) {
 	return []string{data}, found
 }
 
-// Set sets a key to a given value
+// Set sets a key to a given value. Nested keys can be set with dotted
+// paths, e.g. "db.port". Missing maps are created.
 func (y *YAML) Set(key string, value interface{}) error {
-	if y.data == nil {
-		y.data = make(map[string]interface{}, 1)
+	vn, err := valueNode(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value of %s: %w", key, err)
+	}
+
+	m := y.root()
+	path := []string{key}
+	if kn, _ := lookup(m, key); kn == nil {
+		path = strings.Split(key, ".")
+	}
+	for i, p := range path[:len(path)-1] {
+		_, next := lookup(m, p)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: p}, next)
+		}
+		if next.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %s: %s is not a map", key, strings.Join(path[:i+1], "."))
+		}
+		m = next
+	}
+
+	last := path[len(path)-1]
+	if kn, old := lookup(m, last); kn != nil {
+		// keep any comments of the old value
+		vn.HeadComment, vn.LineComment, vn.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		*old = *vn
+	} else {
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, vn)
 	}
-	y.data[key] = value
+	y.raw = nil
 	return nil
 }
 
@@ -80,40 +146,131 @@ func (y *YAML) Add(key string, value interface{}) error {
 	return fmt.Errorf("not supported for YAML")
 }
 
-// Del removes a single key
+// Del removes a single key. Nested keys can be removed with dotted paths.
 func (y *YAML) Del(key string) bool {
-	_, found := y.data[key]
-	delete(y.data, key)
-	return found
+	if y.doc == nil {
+		return false
+	}
+	m := y.root()
+	path := []string{key}
+	if kn, _ := lookup(m, key); kn == nil {
+		path = strings.Split(key, ".")
+	}
+	for _, p := range path[:len(path)-1] {
+		_, m = lookup(m, p)
+		if m == nil || m.Kind != yaml.MappingNode {
+			return false
+		}
+	}
+	last := path[len(path)-1]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != last {
+			continue
+		}
+		m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		y.raw = nil
+		return true
+	}
+	return false
+}
+
+// lookup returns the key and value nodes of the given key in a mapping node
+func lookup(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// valueNode returns a YAML node for the given value. Strings are stored
+// as plain scalars only if they are read back unchanged, e.g. "5432" is
+// stored as a number but "0123" is quoted to avoid the octal conversion.
+func valueNode(value interface{}) (vn *yaml.Node, err error) {
+	sv, ok := value.(string)
+	if !ok {
+		// the encoder panics on some invalid struct definitions
+		defer func() {
+			if r := recover(); r != nil {
+				debug.Log("panic: %s", r)
+				vn, err = nil, fmt.Errorf("%v", r)
+			}
+		}()
+		vn = &yaml.Node{}
+		if err := vn.Encode(value); err != nil {
+			return nil, err
+		}
+		return vn, nil
+	}
+
+	vn = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: sv}
+	if strings.Contains(sv, "\n") {
+		vn.Style = yaml.LiteralStyle
+		return vn, nil
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(sv), &v); err == nil && v != nil && fmt.Sprintf("%v", v) == sv {
+		vn.Tag = ""
+	}
+	return vn, nil
 }
 
 // ParseYAML will try to parse a YAML secret.
 func ParseYAML(in []byte) (*YAML, error) {
-	y := &YAML{
-		data: make(map[string]interface{}, 10),
-	}
+	y := &YAML{}
 	debug.Log("Parsing %s", out.Secret(in))
 	r := bufio.NewReader(bytes.NewReader(in))
 	line, err := r.ReadString('\n')
 	if err != nil {
 		return nil, err
 	}
-	line = strings.TrimSpace(line)
-	if line != "---" {
-		y.password = line
+	if strings.TrimSpace(line) != "---" {
+		y.password = strings.TrimSpace(line)
 		body, err := parseBody(r)
 		if err != nil {
 			return nil, err
 		}
 		y.body = body
+	} else {
+		y.raw = []byte(line)
 	}
 
-	if err := yaml.NewDecoder(r).Decode(y.data); err != nil && err != io.EOF {
+	rest, err := io.ReadAll(r)
+	if err != nil {
 		return nil, err
 	}
+	y.raw = append(y.raw, rest...)
+	y.indent = detectIndent(y.raw)
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(y.raw, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("YAML document is not a map")
+		}
+		y.doc = doc
+	}
 	return y, nil
 }
 
+// detectIndent returns the indentation of the first indented line
+func detectIndent(in []byte) int {
+	for _, line := range strings.Split(string(in), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return len(line) - len(trimmed)
+	}
+	return 0
+}
+
 // Body returns the body
 func (y *YAML) Body() string {
 	return y.body
@@ -157,25 +314,32 @@ func parseBody(r *bufio.Reader) (string, error) {
 
 // Bytes serialized this secret
 func (y *YAML) Bytes() []byte {
-	defer func() {
-		if r := recover(); r != nil {
-			debug.Log("panic: %s", r)
-		}
-	}()
 	buf := &bytes.Buffer{}
 	buf.WriteString(y.password)
 	if y.body != "" {
 		buf.WriteString("\n")
 		buf.WriteString(y.body)
 	}
-	if len(y.data) > 0 {
-		if !strings.HasSuffix(y.body, "\n") {
-			buf.WriteString("\n")
-		}
-		buf.WriteString("---\n")
-		if err := yaml.NewEncoder(buf).Encode(y.data); err != nil {
-			debug.Log("failed to encode YAML: %s", err)
-		}
+	if y.raw == nil && len(y.Keys()) < 1 {
+		return buf.Bytes()
+	}
+	if !strings.HasSuffix(y.body, "\n") {
+		buf.WriteString("\n")
+	}
+	if y.raw != nil {
+		buf.Write(y.raw)
+		return buf.Bytes()
+	}
+
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(buf)
+	if y.indent > 1 {
+		enc.SetIndent(y.indent)
+	} else {
+		enc.SetIndent(4)
+	}
+	if err := enc.Encode(y.doc); err != nil {
+		debug.Log("failed to encode YAML: %s", err)
 	}
 	return buf.Bytes()
 }
@@ -51,14 +51,11 @@ type inlineC struct {
 }
 
 func TestYAMLEncodingError(t *testing.T) {
-	s := &YAML{
-		data: map[string]interface{}{
-			"foo": &struct {
-				B       int
-				inlineB `yaml:",inline"`
-			}{1, inlineB{2, inlineC{3}}},
-		},
-	}
+	s := &YAML{}
+	assert.Error(t, s.Set("foo", &struct {
+		B       int
+		inlineB `yaml:",inline"`
+	}{1, inlineB{2, inlineC{3}}}))
 	assert.Equal(t, "", string(s.Bytes()))
 }
 
@@ -196,14 +193,15 @@ url: http://www.test.com/`
 	assert.Equal(t, []string{"password", "url", "username"}, s.Keys())
 }
 func TestYAMLValues(t *testing.T) {
-	s := &YAML{
-		data: map[string]interface{}{
-			"string": "string",
-			"int":    int(32),
-			"float":  2.3,
-			"slice":  []int{1, 2, 3},
-			"map":    map[string]string{"a": "b"},
-		},
+	s := &YAML{}
+	for k, v := range map[string]interface{}{
+		"string": "string",
+		"int":    int(32),
+		"float":  2.3,
+		"slice":  []int{1, 2, 3},
+		"map":    map[string]string{"a": "b"},
+	} {
+		require.NoError(t, s.Set(k, v))
 	}
 
 	get := func(k string) string {
@@ -240,3 +238,69 @@ sub:
 	assert.Equal(t, "map[subentry:123]", get("sub"))
 	assert.Equal(t, []string{"login", "number", "sub"}, s.Keys())
 }
+
+func TestYAMLNested(t *testing.T) {
+	in := `secret
+---
+# database settings
+db:
+  host: localhost # the primary
+  port: 5432
+user: admin
+`
+	s, err := ParseYAML([]byte(in))
+	require.NoError(t, err)
+
+	// unmodified secrets are kept as is
+	assert.Equal(t, in, string(s.Bytes()))
+
+	v, found := s.Get("db.host")
+	assert.True(t, found)
+	assert.Equal(t, "localhost", v)
+
+	require.NoError(t, s.Set("db.port", "5433"))
+	require.NoError(t, s.Set("db.opts.ssl", "true"))
+	require.NoError(t, s.Set("zip", "0123"))
+	assert.Error(t, s.Set("user.name", "foo"))
+	assert.Equal(t, `secret
+---
+# database settings
+db:
+  host: localhost # the primary
+  port: 5433
+  opts:
+    ssl: true
+user: admin
+zip: "0123"
+`, string(s.Bytes()))
+
+	// set and get must be idempotent
+	s, err = ParseYAML(s.Bytes())
+	require.NoError(t, err)
+	for k, want := range map[string]string{
+		"db.port":     "5433",
+		"db.opts.ssl": "true",
+		"zip":         "0123",
+	} {
+		v, found := s.Get(k)
+		assert.True(t, found, k)
+		assert.Equal(t, want, v, k)
+	}
+
+	assert.True(t, s.Del("db.opts"))
+	assert.False(t, s.Del("db.opts"))
+	assert.False(t, s.Del("user.name"))
+	_, found = s.Get("db.opts.ssl")
+	assert.False(t, found)
+	assert.Equal(t, []string{"db", "user", "zip"}, s.Keys())
+}
+
+func TestYAMLInvalid(t *testing.T) {
+	for _, in := range []string{
+		"secret\n---\nfoo: [bar\n",
+		"secret\n---\n- foo\n- bar\n",
+	} {
+		_, err := ParseYAML([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
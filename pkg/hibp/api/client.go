@@ -1,12 +1,16 @@
-// Package api implements an HIBP API client.
+// Package api implements an HIBP API client. It uses the k-anonymity range
+// API, i.e. only the first five characters of a SHA-1 sum are sent to the
+// server and the remaining suffixes are matched locally.
 package api
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -16,22 +20,148 @@ import (
 // URL is the HIBPv2 API URL
 var URL = "https://api.pwnedpasswords.com"
 
+const (
+	// DefaultInterval is the default minimum time between two requests
+	DefaultInterval = 20 * time.Millisecond
+	// DefaultWorkers is the default number of concurrent requests
+	DefaultWorkers = 4
+
+	prefixLen = 5
+)
+
 // Lookup performs a lookup against the HIBP v2 API
 func Lookup(shaSum string) (uint64, error) {
+	return NewClient().Lookup(context.Background(), shaSum)
+}
+
+// Client is a HIBP v2 range API client. The responses for each prefix are
+// cached for the lifetime of the client and the request rate is limited.
+type Client struct {
+	// Interval is the minimum time between two requests
+	Interval time.Duration
+	// Workers is the number of concurrent requests made by LookupBatch
+	Workers int
+
+	url string
+
+	mu     sync.Mutex
+	next   time.Time
+	ranges map[string]map[string]uint64
+}
+
+// NewClient creates a new client for the API at URL
+func NewClient() *Client {
+	return &Client{
+		Interval: DefaultInterval,
+		Workers:  DefaultWorkers,
+		url:      URL,
+		ranges:   make(map[string]map[string]uint64),
+	}
+}
+
+// Lookup returns how often the given SHA-1 sum was found in breaches
+func (c *Client) Lookup(ctx context.Context, shaSum string) (uint64, error) {
 	if len(shaSum) != 40 {
 		return 0, fmt.Errorf("invalid shasum")
 	}
-
 	shaSum = strings.ToUpper(shaSum)
-	prefix := shaSum[:5]
-	suffix := shaSum[5:]
 
-	var count uint64
-	url := fmt.Sprintf("%s/range/%s", URL, prefix)
+	r, err := c.getRange(ctx, shaSum[:prefixLen])
+	if err != nil {
+		return 0, err
+	}
+	return r[shaSum[prefixLen:]], nil
+}
+
+// LookupBatch looks up all given SHA-1 sums, requesting each prefix only
+// once. It returns the sums found in breaches with their count and the
+// errors for each prefix that could not be looked up.
+func (c *Client) LookupBatch(ctx context.Context, sums []string) (map[string]uint64, map[string]error) {
+	prefixes := make(map[string][]string, len(sums))
+	for _, sum := range sums {
+		if len(sum) != 40 {
+			continue
+		}
+		sum = strings.ToUpper(sum)
+		prefixes[sum[:prefixLen]] = append(prefixes[sum[:prefixLen]], sum)
+	}
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	found := make(map[string]uint64)
+	errs := make(map[string]error)
+
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for prefix := range jobs {
+				r, err := c.getRange(ctx, prefix)
+				mu.Lock()
+				if err != nil {
+					errs[prefix] = err
+				}
+				for _, sum := range prefixes[prefix] {
+					if cnt := r[sum[prefixLen:]]; cnt > 0 {
+						found[sum] = cnt
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for prefix := range prefixes {
+		jobs <- prefix
+	}
+	close(jobs)
+	wg.Wait()
+
+	return found, errs
+}
+
+// getRange returns the suffixes and counts for the given prefix, either
+// from the cache or from the API
+func (c *Client) getRange(ctx context.Context, prefix string) (map[string]uint64, error) {
+	c.mu.Lock()
+	r, found := c.ranges[prefix]
+	c.mu.Unlock()
+	if found {
+		debug.Log("[%s] cache hit", prefix)
+		return r, nil
+	}
+
+	r, err := c.fetchRange(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.ranges[prefix] = r
+	c.mu.Unlock()
+	return r, nil
+}
+
+func (c *Client) fetchRange(ctx context.Context, prefix string) (map[string]uint64, error) {
+	url := fmt.Sprintf("%s/range/%s", c.url, prefix)
+	r := make(map[string]uint64)
 
 	op := func() error {
-		debug.Log("[%s] HTTP Request: %s", shaSum, url)
-		resp, err := http.Get(url)
+		if err := c.wait(ctx); err != nil {
+			return backoff.Permanent(err)
+		}
+
+		debug.Log("[%s] HTTP Request: %s", prefix, url)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			return err
 		}
@@ -49,20 +179,21 @@ func Lookup(shaSum string) (uint64, error) {
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("HTTP request failed: %s %s", resp.Status, body)
+			err := fmt.Errorf("HTTP request failed: %s %s", resp.Status, body)
+			// only retry server errors and rate limiting
+			if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return backoff.Permanent(err)
+			}
+			return err
 		}
 
 		for _, line := range strings.Split(string(body), "\n") {
 			line = strings.TrimSpace(line)
-			if len(line) < 37 {
-				continue
-			}
-			if line[:35] != suffix {
+			if len(line) < 37 || line[35] != ':' {
 				continue
 			}
 			if iv, err := strconv.ParseUint(line[36:], 10, 64); err == nil {
-				count = iv
-				return nil
+				r[strings.ToUpper(line[:35])] = iv
 			}
 		}
 		return nil
@@ -71,6 +202,27 @@ func Lookup(shaSum string) (uint64, error) {
 	bo := backoff.NewExponentialBackOff()
 	bo.MaxElapsedTime = 10 * time.Second
 
-	err := backoff.Retry(op, bo)
-	return count, err
+	if err := backoff.Retry(op, backoff.WithContext(bo, ctx)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// wait blocks until the next request is allowed
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	t := c.next
+	if t.Before(now) {
+		t = now
+	}
+	c.next = t.Add(c.Interval)
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(time.Until(t)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"context"
 	"crypto/sha1"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -105,6 +108,50 @@ func TestLookupCR(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(0), count)
 }
+
+func TestLookupBatch(t *testing.T) {
+	matchSum := sha1sum("match")
+	otherSum := sha1sum("other")
+	failSum := sha1sum("fail")
+	var matchCount uint64 = 42
+
+	var mu sync.Mutex
+	reqs := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.String(), "/range/")
+		mu.Lock()
+		reqs[prefix]++
+		mu.Unlock()
+
+		switch prefix {
+		case matchSum[:5]:
+			fmt.Fprintf(w, "%s:%d\r\n", strings.ToLower(matchSum[5:]), matchCount)
+		case failSum[:5]:
+			http.Error(w, "bad request", http.StatusBadRequest)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	URL = ts.URL
+
+	ctx := context.Background()
+
+	c := NewClient()
+	c.Interval = time.Millisecond
+	found, errs := c.LookupBatch(ctx, []string{matchSum, matchSum, otherSum, failSum, "invalid"})
+	assert.Equal(t, map[string]uint64{matchSum: matchCount}, found)
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs[failSum[:5]])
+
+	// the range of a prefix is only requested once
+	count, err := c.Lookup(ctx, strings.ToLower(matchSum))
+	assert.NoError(t, err)
+	assert.Equal(t, matchCount, count)
+	assert.Equal(t, 1, reqs[matchSum[:5]])
+	assert.Equal(t, 1, reqs[otherSum[:5]])
+}
+
 func sha1sum(data string) string {
 	h := sha1.New()
 	_, _ = h.Write([]byte(data))
@@ -0,0 +1,230 @@
+// Package bloom implements a bloom filter of breached passwords. It is built
+// from the HIBP SHA-1 dumps and a small fraction of their size, e.g. about
+// 1.8 bytes per password for a false positive rate of 0.1%. Lookups are done
+// entirely offline. The filter is memory mapped, so only the pages holding
+// the bits of the checked passwords are read from disk.
+//
+// A filter file starts with a header of 40 bytes, all numbers are little
+// endian:
+//
+//	offset  size  content
+//	0       8     magic "GPBLOOM\n"
+//	8       4     format version (1)
+//	12      4     number of hash functions (k)
+//	16      8     number of bits (m)
+//	24      8     number of hashes added (n)
+//	32      8     false positive rate the filter was sized for (float64)
+//
+// The bits follow the header, bit i is bit i%8 of byte i/8. The positions of
+// a SHA-1 sum are derived from its first 16 bytes by double hashing, i.e.
+// h1 + i*h2 mod m for i < k.
+package bloom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+)
+
+const (
+	// Version is the version of the file format
+	Version = 1
+
+	magic      = "GPBLOOM\n"
+	headerSize = 40
+)
+
+// ErrFormat is returned if a file is no bloom filter or of an unsupported
+// version
+var ErrFormat = errors.New("not a gopass bloom filter")
+
+type header struct {
+	version uint32
+	k       uint32
+	m       uint64
+	n       uint64
+	fpRate  float64
+}
+
+func (h header) marshal() []byte {
+	buf := make([]byte, headerSize)
+	copy(buf, magic)
+	binary.LittleEndian.PutUint32(buf[8:], h.version)
+	binary.LittleEndian.PutUint32(buf[12:], h.k)
+	binary.LittleEndian.PutUint64(buf[16:], h.m)
+	binary.LittleEndian.PutUint64(buf[24:], h.n)
+	binary.LittleEndian.PutUint64(buf[32:], math.Float64bits(h.fpRate))
+	return buf
+}
+
+func unmarshalHeader(buf []byte) (header, error) {
+	if len(buf) < headerSize || string(buf[:len(magic)]) != magic {
+		return header{}, ErrFormat
+	}
+	h := header{
+		version: binary.LittleEndian.Uint32(buf[8:]),
+		k:       binary.LittleEndian.Uint32(buf[12:]),
+		m:       binary.LittleEndian.Uint64(buf[16:]),
+		n:       binary.LittleEndian.Uint64(buf[24:]),
+		fpRate:  math.Float64frombits(binary.LittleEndian.Uint64(buf[32:])),
+	}
+	if h.version != Version {
+		return header{}, fmt.Errorf("%w: unsupported version %d", ErrFormat, h.version)
+	}
+	if h.k < 1 || h.m < 1 {
+		return header{}, fmt.Errorf("%w: invalid header", ErrFormat)
+	}
+	return h, nil
+}
+
+// size returns the size of a filter file with m bits
+func size(m uint64) int64 {
+	return headerSize + int64((m+7)/8)
+}
+
+// positions calls fn with the bit positions of a SHA-1 sum
+func (h header) positions(sum []byte, fn func(pos uint64) bool) {
+	h1 := binary.LittleEndian.Uint64(sum[0:8])
+	h2 := binary.LittleEndian.Uint64(sum[8:16]) | 1
+	for i := uint64(0); i < uint64(h.k); i++ {
+		if !fn((h1 + i*h2) % h.m) {
+			return
+		}
+	}
+}
+
+// Filter is a bloom filter opened for lookups
+type Filter struct {
+	header
+	name  string
+	bits  io.ReaderAt
+	close func() error
+}
+
+// Open opens the filter file for lookups
+func Open(fn string) (*Filter, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(fh, buf); err != nil {
+		_ = fh.Close()
+		return nil, fmt.Errorf("%s: %w", fn, ErrFormat)
+	}
+	h, err := unmarshalHeader(buf)
+	if err != nil {
+		_ = fh.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+	fi, err := fh.Stat()
+	if err != nil {
+		_ = fh.Close()
+		return nil, err
+	}
+	if fi.Size() != size(h.m) {
+		_ = fh.Close()
+		return nil, fmt.Errorf("%s: %w: size %d does not match %d bits", fn, ErrFormat, fi.Size(), h.m)
+	}
+
+	f := &Filter{
+		header: h,
+		name:   fn,
+	}
+	data, unmap, err := mapFile(fh, fi.Size(), false)
+	if err != nil {
+		_ = fh.Close()
+		return nil, fmt.Errorf("failed to map %s: %w", fn, err)
+	}
+	if data == nil {
+		// no memory mapping available, read the file on demand
+		f.bits = io.NewSectionReader(fh, headerSize, fi.Size()-headerSize)
+		f.close = fh.Close
+		return f, nil
+	}
+	// the mapping stays valid after the file is closed
+	_ = fh.Close()
+	f.bits = bytes.NewReader(data[headerSize:])
+	f.close = unmap
+	return f, nil
+}
+
+// Close releases the filter
+func (f *Filter) Close() error {
+	return f.close()
+}
+
+// Name returns the file name of the filter
+func (f *Filter) Name() string {
+	return f.name
+}
+
+// Len returns the number of hashes added to the filter
+func (f *Filter) Len() uint64 {
+	return f.n
+}
+
+// FalsePositiveRate returns the false positive rate the filter was built for
+func (f *Filter) FalsePositiveRate() float64 {
+	return f.fpRate
+}
+
+// Contains returns true if the SHA-1 sum (binary) was possibly added to the
+// filter. False is always correct.
+func (f *Filter) Contains(sum []byte) (bool, error) {
+	if len(sum) < 16 {
+		return false, fmt.Errorf("invalid SHA-1 sum")
+	}
+
+	found := true
+	var err error
+	b := make([]byte, 1)
+	f.positions(sum, func(pos uint64) bool {
+		if _, err = f.bits.ReadAt(b, int64(pos/8)); err != nil {
+			found = false
+			return false
+		}
+		if b[0]&(1<<(pos%8)) == 0 {
+			found = false
+			return false
+		}
+		return true
+	})
+	return found, err
+}
+
+// LookupCounts takes a slice of hex encoded SHA-1 sums and returns the
+// (upper case) ones possibly contained in the filter. The filter doesn't
+// know how often a password was breached, so all counts are zero.
+func (f *Filter) LookupCounts(ctx context.Context, in []string) map[string]uint64 {
+	found := make(map[string]uint64)
+	for _, hash := range in {
+		if ctx.Err() != nil {
+			break
+		}
+		sum, err := hex.DecodeString(hash)
+		if err != nil || len(sum) != sha1.Size {
+			continue
+		}
+		ok, err := f.Contains(sum)
+		if err != nil {
+			out.Errorf(ctx, "Failed to read the bloom filter %s: %s", f.name, err)
+			break
+		}
+		if ok {
+			found[strings.ToUpper(hash)] = 0
+		}
+	}
+	return found
+}
@@ -0,0 +1,157 @@
+package bloom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdata/test.blm contains these passwords
+var testPasswords = []string{"password", "123456", "123", "qwerty", "letmein", "iloveyou", "monkey", "dragon"}
+
+func sha1hex(pw string) string {
+	return strings.ToUpper(fmt.Sprintf("%x", sha1.Sum([]byte(pw))))
+}
+
+func TestBuild(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	want := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		h := sha1hex(fmt.Sprintf("password%d", i))
+		want = append(want, h)
+		fmt.Fprintf(buf, "%s:%d\n", h, i+1)
+	}
+	dumpFn := filepath.Join(td, "dump.txt")
+	require.NoError(t, os.WriteFile(dumpFn, buf.Bytes(), 0644))
+
+	fn := filepath.Join(td, "filter.blm")
+	_, err = Build(ctx, fn, 0, dumpFn)
+	assert.Error(t, err)
+	_, err = Build(ctx, fn, 0.01)
+	assert.Error(t, err)
+	_, err = Build(ctx, fn, 0.01, filepath.Join(td, "missing.txt"))
+	assert.Error(t, err)
+
+	st, err := Build(ctx, fn, 0.01, dumpFn)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), st.Hashes)
+	assert.Equal(t, uint64(9586), st.Bits)
+	assert.Equal(t, uint32(7), st.HashFunctions)
+	fi, err := os.Stat(fn)
+	require.NoError(t, err)
+	assert.Equal(t, st.Size, fi.Size())
+
+	f, err := Open(fn)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, f.Close())
+	}()
+	assert.Equal(t, uint64(1000), f.Len())
+	assert.Equal(t, 0.01, f.FalsePositiveRate())
+	assert.Equal(t, fn, f.Name())
+
+	// no false negatives
+	found := f.LookupCounts(ctx, want)
+	assert.Len(t, found, len(want))
+
+	// only a few false positives
+	other := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		other = append(other, strings.ToLower(sha1hex(fmt.Sprintf("other%d", i))))
+	}
+	assert.Less(t, len(f.LookupCounts(ctx, other)), 50)
+
+	_, err = f.Contains([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestOpenInvalid(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	valid := header{version: Version, k: 3, m: 64, n: 4, fpRate: 0.1}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "magic",
+			data: append([]byte("NOBLOOM\n"), valid.marshal()[8:]...),
+		},
+		{
+			name: "version",
+			data: header{version: 2, k: 3, m: 64}.marshal(),
+		},
+		{
+			name: "no bits",
+			data: header{version: Version, k: 3}.marshal(),
+		},
+		{
+			name: "size",
+			data: append(valid.marshal(), 0, 0),
+		},
+	} {
+		fn := filepath.Join(td, tc.name+".blm")
+		require.NoError(t, os.WriteFile(fn, tc.data, 0644))
+		_, err := Open(fn)
+		assert.True(t, errors.Is(err, ErrFormat), tc.name)
+	}
+
+	_, err = Open(filepath.Join(td, "missing.blm"))
+	assert.Error(t, err)
+}
+
+func TestTestdata(t *testing.T) {
+	ctx := context.Background()
+
+	f, err := Open(filepath.Join("testdata", "test.blm"))
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, f.Close())
+	}()
+
+	assert.Equal(t, uint64(len(testPasswords)), f.Len())
+	assert.Equal(t, 0.001, f.FalsePositiveRate())
+
+	for _, pw := range testPasswords {
+		sum := sha1.Sum([]byte(pw))
+		ok, err := f.Contains(sum[:])
+		require.NoError(t, err)
+		assert.True(t, ok, pw)
+	}
+
+	found := f.LookupCounts(ctx, []string{
+		hex.EncodeToString([]byte("not a sha1 sum")),
+		"zz",
+		sha1hex("correct horse battery staple"),
+		strings.ToLower(sha1hex("123")),
+	})
+	assert.Equal(t, map[string]uint64{sha1hex("123"): 0}, found)
+}
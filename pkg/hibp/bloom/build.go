@@ -0,0 +1,135 @@
+package bloom
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/gopasspw/gopass/pkg/debug"
+	"github.com/gopasspw/gopass/pkg/hibp/dump"
+)
+
+// DefaultFalsePositiveRate is used if no rate is given
+const DefaultFalsePositiveRate = 0.001
+
+// Stats describes a filter that was built
+type Stats struct {
+	// Hashes is the number of hashes added
+	Hashes uint64
+	// Bits is the size of the filter in bits
+	Bits uint64
+	// HashFunctions is the number of bits set per hash
+	HashFunctions uint32
+	// Size is the size of the filter file in bytes
+	Size int64
+}
+
+// Build creates a filter for the given false positive rate from the HIBP
+// SHA-1 dumps and writes it to dst. The dumps are read twice, once to count
+// the hashes and once to add them. The filter is written through a memory
+// mapping, so it doesn't have to fit into memory.
+func Build(ctx context.Context, dst string, fpRate float64, dumps ...string) (Stats, error) {
+	if fpRate <= 0 || fpRate >= 1 {
+		return Stats{}, fmt.Errorf("false positive rate must be between 0 and 1, not %g", fpRate)
+	}
+	if len(dumps) < 1 {
+		return Stats{}, fmt.Errorf("no dumps given")
+	}
+
+	var n uint64
+	for _, fn := range dumps {
+		if err := dump.Walk(ctx, fn, func(string) error {
+			n++
+			return nil
+		}); err != nil {
+			return Stats{}, fmt.Errorf("failed to read %s: %w", fn, err)
+		}
+	}
+	if n < 1 {
+		return Stats{}, fmt.Errorf("no hashes found in %v", dumps)
+	}
+
+	h := dimension(n, fpRate)
+	debug.Log("building filter with %d bits and %d hash functions for %d hashes", h.m, h.k, n)
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*")
+	if err != nil {
+		return Stats{}, err
+	}
+	defer func() {
+		// the file was renamed already if the build succeeded
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := fill(ctx, tmp, h, dumps); err != nil {
+		_ = tmp.Close()
+		return Stats{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Stats{}, err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return Stats{}, err
+	}
+
+	return Stats{
+		Hashes:        n,
+		Bits:          h.m,
+		HashFunctions: h.k,
+		Size:          size(h.m),
+	}, nil
+}
+
+// dimension returns the header of an optimal filter for n entries and the
+// given false positive rate
+func dimension(n uint64, fpRate float64) header {
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	return header{
+		version: Version,
+		k:       uint32(k),
+		m:       uint64(m),
+		n:       n,
+		fpRate:  fpRate,
+	}
+}
+
+// fill writes the header and the bits of all hashes in the dumps to fh
+func fill(ctx context.Context, fh *os.File, h header, dumps []string) error {
+	sz := size(h.m)
+	if err := fh.Truncate(sz); err != nil {
+		return err
+	}
+	data, unmap, err := mapFile(fh, sz, true)
+	if err != nil {
+		return fmt.Errorf("failed to map %s: %w", fh.Name(), err)
+	}
+
+	copy(data, h.marshal())
+	bits := data[headerSize:]
+	sum := make([]byte, 20)
+	for _, fn := range dumps {
+		if err := dump.Walk(ctx, fn, func(hash string) error {
+			if _, err := hex.Decode(sum, []byte(hash[:40])); err != nil {
+				debug.Log("skipping invalid hash %q in %s: %s", hash, fn, err)
+				return nil
+			}
+			h.positions(sum, func(pos uint64) bool {
+				bits[pos/8] |= 1 << (pos % 8)
+				return true
+			})
+			return nil
+		}); err != nil {
+			_ = unmap()
+			return fmt.Errorf("failed to read %s: %w", fn, err)
+		}
+	}
+
+	return unmap()
+}
@@ -0,0 +1,38 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package bloom
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// mapFile maps the first size bytes of the file into memory. The returned
+// function flushes writes (if any) and removes the mapping.
+func mapFile(fh *os.File, size int64, write bool) ([]byte, func() error, error) {
+	if int64(int(size)) != size {
+		return nil, nil, fmt.Errorf("file too large to map: %d bytes", size)
+	}
+
+	prot := unix.PROT_READ
+	if write {
+		prot |= unix.PROT_WRITE
+	}
+	data, err := unix.Mmap(int(fh.Fd()), 0, int(size), prot, unix.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error {
+		if write {
+			if err := unix.Msync(data, unix.MS_SYNC); err != nil {
+				_ = unix.Munmap(data)
+				return err
+			}
+		}
+		return unix.Munmap(data)
+	}, nil
+}
@@ -0,0 +1,23 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package bloom
+
+import (
+	"os"
+)
+
+// mapFile doesn't map the file on this platform. Filters opened for reading
+// are read on demand, filters being built are kept in memory and written to
+// the file when the returned function is called.
+func mapFile(fh *os.File, size int64, write bool) ([]byte, func() error, error) {
+	if !write {
+		return nil, nil, nil
+	}
+
+	data := make([]byte, size)
+	return data, func() error {
+		_, err := fh.WriteAt(data, 0)
+		return err
+	}, nil
+}
@@ -1,5 +1,6 @@
 // Package dump implements an haveibeenpwned.com dump scanner. It is designed
-// to operate on HIBP SHA-1 dumps which are ordered by hash. It will work with
+// to operate on HIBP SHA-1 dumps which are ordered by hash. Uncompressed
+// dumps ordered by hash are searched using a binary search. It will work with
 // dumps ordered by prevalence, too. But processing those will take much, much
 // longer.
 //
@@ -16,6 +17,7 @@ import (
 	"os"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gopasspw/gopass/internal/out"
@@ -28,6 +30,13 @@ type Scanner struct {
 	dumps []string
 }
 
+// match is a hash found in a dump with the number of breaches it was found
+// in. The count is zero for dumps without counts (v1).
+type match struct {
+	hash  string
+	count uint64
+}
+
 // New creates a new scanner. Provide a list of filenames to HIBP SHA-1 dumps.
 // Those should be ordered by hash or lookups will take forever.
 func New(dumps ...string) (*Scanner, error) {
@@ -53,13 +62,30 @@ func (s *Scanner) LookupBatch(ctx context.Context, in []string) []string {
 		return nil
 	}
 
-	sort.Strings(in)
+	counts := s.LookupCounts(ctx, in)
+	out := make([]string, 0, len(counts))
+	for hash := range counts {
+		out = append(out, hash)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LookupCounts takes a slice of SHA1 hashes and matches them against the
+// provided dumps. It returns the (upper case) hashes found with the number
+// of breaches they were found in, if the dumps contain it.
+func (s *Scanner) LookupCounts(ctx context.Context, in []string) map[string]uint64 {
+	counts := make(map[string]uint64)
+	if len(in) < 1 {
+		return counts
+	}
+
 	for i, hash := range in {
 		in[i] = strings.ToUpper(hash)
 	}
+	sort.Strings(in)
 
-	out := make([]string, 0, len(in))
-	results := make(chan string, len(in))
+	results := make(chan match, len(in))
 	done := make(chan struct{}, len(s.dumps))
 
 	for _, fn := range s.dumps {
@@ -67,7 +93,13 @@ func (s *Scanner) LookupBatch(ctx context.Context, in []string) []string {
 	}
 	go func() {
 		for result := range results {
-			out = append(out, result)
+			if result.count > counts[result.hash] {
+				counts[result.hash] = result.count
+				continue
+			}
+			if _, found := counts[result.hash]; !found {
+				counts[result.hash] = result.count
+			}
 		}
 		done <- struct{}{}
 	}()
@@ -77,17 +109,21 @@ func (s *Scanner) LookupBatch(ctx context.Context, in []string) []string {
 	close(results)
 	<-done
 
-	return out
+	return counts
 }
 
-func (s *Scanner) scanFile(ctx context.Context, fn string, in []string, results chan string, done chan struct{}) {
+func (s *Scanner) scanFile(ctx context.Context, fn string, in []string, results chan match, done chan struct{}) {
 	defer func() {
 		done <- struct{}{}
 	}()
 
 	if isSorted(fn) {
 		debug.Log("file %s appears to be sorted", fn)
-		s.scanSortedFile(ctx, fn, in, results)
+		if strings.HasSuffix(fn, ".gz") {
+			s.scanSortedFile(ctx, fn, in, results)
+			return
+		}
+		s.searchSortedFile(ctx, fn, in, results)
 		return
 	}
 	debug.Log("file %s is not sorted", fn)
@@ -137,7 +173,170 @@ func isSorted(fn string) bool {
 	return true
 }
 
-func (s *Scanner) scanSortedFile(ctx context.Context, fn string, in []string, results chan string) {
+// Walk calls fn for the (upper case) hash of every line of the dump, in the
+// order they appear in the file. Gzipped dumps must have the suffix .gz.
+func Walk(ctx context.Context, dump string, fn func(hash string) error) error {
+	var rdr io.Reader
+	fh, err := os.Open(dump)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+	rdr = fh
+
+	if strings.HasSuffix(dump, ".gz") {
+		gzr, err := gzip.NewReader(fh)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", dump, err)
+		}
+		defer func() {
+			_ = gzr.Close()
+		}()
+		rdr = gzr
+	}
+
+	scanner := bufio.NewScanner(rdr)
+	for scanner.Scan() {
+		// check for context cancelation
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		hash, _, ok := parseLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if err := fn(hash); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseLine returns the upper case hash and the count (if any) of a line in
+// a dump
+func parseLine(line string) (string, uint64, bool) {
+	line = strings.ToUpper(strings.TrimSpace(line))
+	if len(line) < 40 {
+		return "", 0, false
+	}
+	var count uint64
+	if len(line) > 41 && line[40] == ':' {
+		count, _ = strconv.ParseUint(line[41:], 10, 64)
+	}
+	return line[:40], count, true
+}
+
+// searchSortedFile looks up each hash with a binary search. This only works
+// for uncompressed files ordered by hash.
+func (s *Scanner) searchSortedFile(ctx context.Context, fn string, in []string, results chan match) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		out.Errorf(ctx, "Failed to open file %s: %s", fn, err)
+		return
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+
+	fi, err := fh.Stat()
+	if err != nil {
+		out.Errorf(ctx, "Failed to stat file %s: %s", fn, err)
+		return
+	}
+
+	debug.Log("Searching file %s ...\n", fn)
+	for _, hash := range in {
+		// check for context cancelation
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		count, found, err := search(fh, fi.Size(), hash)
+		if err != nil {
+			out.Errorf(ctx, "Failed to search file %s: %s", fn, err)
+			return
+		}
+		if found {
+			debug.Log("[%s] MATCH: %s", fn, hash)
+			results <- match{hash: hash, count: count}
+		}
+	}
+	debug.Log("Finished searching file %s", fn)
+}
+
+// searchWindow is the size of the remaining range of a binary search that
+// is scanned line by line
+const searchWindow = 4096
+
+// search looks up a single hash in a sorted dump of the given size
+func search(r io.ReaderAt, size int64, hash string) (uint64, bool, error) {
+	// invariant: all lines starting at or before lo are smaller than hash
+	lo, hi := int64(0), size
+	for hi-lo > searchWindow {
+		mid := lo + (hi-lo)/2
+		line, err := lineAfter(r, mid, size)
+		if err == io.EOF {
+			hi = mid
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if lh, _, ok := parseLine(line); ok && lh < hash {
+			lo = mid
+			continue
+		}
+		hi = mid
+	}
+
+	// scan the remaining lines until we reach the hash
+	br := bufio.NewReader(io.NewSectionReader(r, lo, size-lo))
+	if lo > 0 {
+		// skip the (partial) line containing lo
+		if _, err := br.ReadString('\n'); err != nil {
+			return 0, false, nil
+		}
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if lh, count, ok := parseLine(line); ok {
+			if lh == hash {
+				return count, true, nil
+			}
+			if lh > hash {
+				return 0, false, nil
+			}
+		}
+		if err == io.EOF {
+			return 0, false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+	}
+}
+
+// lineAfter returns the first complete line starting after the given offset
+func lineAfter(r io.ReaderAt, off, size int64) (string, error) {
+	br := bufio.NewReader(io.NewSectionReader(r, off, size-off))
+	if _, err := br.ReadString('\n'); err != nil {
+		return "", err
+	}
+	line, err := br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		return line, nil
+	}
+	return line, err
+}
+
+func (s *Scanner) scanSortedFile(ctx context.Context, fn string, in []string, results chan match) {
 	var rdr io.Reader
 	fh, err := os.Open(fn)
 	if err != nil {
@@ -182,11 +381,13 @@ SCAN:
 			break
 		}
 
-		line := strings.TrimSpace(scanner.Text())
-		hash := line[:40]
+		hash, count, ok := parseLine(scanner.Text())
+		if !ok {
+			continue
+		}
 
 		if hash == in[i] {
-			results <- hash
+			results <- match{hash: hash, count: count}
 			debug.Log("[%s] MATCH at line %d: %s", fn, lineNo, hash)
 			numMatches++
 			// advance to next sha sum from store and next line in file
@@ -195,7 +396,7 @@ SCAN:
 		}
 		// advance in sha sums from store until we've reached the position in
 		// the file
-		for i < len(in) && line > in[i] {
+		for i < len(in) && hash > in[i] {
 			i++
 		}
 	}
@@ -203,7 +404,7 @@ SCAN:
 	debug.Log("Finished checking file %s", fn)
 }
 
-func (s *Scanner) scanUnsortedFile(ctx context.Context, fn string, in []string, results chan string) {
+func (s *Scanner) scanUnsortedFile(ctx context.Context, fn string, in []string, results chan match) {
 	var rdr io.Reader
 	fh, err := os.Open(fn)
 	if err != nil {
@@ -257,7 +458,7 @@ SCAN:
 	debug.Log("Finished checking file %s", fn)
 }
 
-func (s *Scanner) matcher(ctx context.Context, in []string, lines chan string, results chan string, done chan struct{}) {
+func (s *Scanner) matcher(ctx context.Context, in []string, lines chan string, results chan match, done chan struct{}) {
 	defer func() {
 		done <- struct{}{}
 	}()
@@ -271,11 +472,13 @@ LINE:
 		default:
 		}
 
-		line := strings.ToUpper(strings.TrimSpace(line))
-		hash := line[:40]
+		hash, count, ok := parseLine(line)
+		if !ok {
+			continue
+		}
 		for _, candidate := range in {
 			if candidate == hash {
-				results <- hash
+				results <- match{hash: hash, count: count}
 				continue LINE
 			}
 		}
@@ -1,11 +1,13 @@
 package dump
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,6 +90,71 @@ func TestScanner(t *testing.T) {
 	assert.Equal(t, []string{}, scanner.LookupBatch(ctx, []string{"foobar"}))
 }
 
+func TestScannerCounts(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	ctx := context.Background()
+
+	// a sorted dump large enough to require a few rounds of binary search
+	hashes := make([]string, 0, 2000)
+	buf := &bytes.Buffer{}
+	for i := 0; i < 2000; i++ {
+		hash := fmt.Sprintf("%040X", i*7)
+		hashes = append(hashes, hash)
+		fmt.Fprintf(buf, "%s:%d\n", hash, i+1)
+	}
+
+	fn := filepath.Join(td, "dump.txt")
+	require.NoError(t, os.WriteFile(fn, buf.Bytes(), 0644))
+
+	scanner, err := New(fn)
+	require.NoError(t, err)
+
+	in := []string{
+		strings.ToLower(hashes[0]),
+		hashes[1],
+		hashes[1000],
+		hashes[1999],
+		fmt.Sprintf("%040X", 8),
+		fmt.Sprintf("%040X", 1<<40),
+	}
+	assert.Equal(t, map[string]uint64{
+		hashes[0]:    1,
+		hashes[1]:    2,
+		hashes[1000]: 1001,
+		hashes[1999]: 2000,
+	}, scanner.LookupCounts(ctx, in))
+
+	// the same dump compressed
+	fn = filepath.Join(td, "dump.txt.gz")
+	require.NoError(t, testWriteGZ(fn, buf.Bytes()))
+
+	scanner, err = New(fn)
+	require.NoError(t, err)
+	assert.Equal(t, []string{hashes[1], hashes[1000]}, scanner.LookupBatch(ctx, []string{hashes[1000], hashes[1]}))
+
+	// dumps without counts
+	fn = filepath.Join(td, "dump-v1.txt")
+	require.NoError(t, os.WriteFile(fn, []byte(testHibpSampleSorted), 0644))
+
+	scanner, err = New(fn)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4": 0,
+		"00000010F4B38525354491E099EB1796278544B1": 0,
+		"0000000A0E3B9F25FF41DE4B5AC238C2D545C7A8": 42,
+	}, scanner.LookupCounts(ctx, []string{
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4",
+		"00000010F4B38525354491E099EB1796278544B1",
+		"0000000A0E3B9F25FF41DE4B5AC238C2D545C7A8",
+	}))
+}
+
 func testWriteGZ(fn string, buf []byte) error {
 	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -105,3 +172,28 @@ func testWriteGZ(fn string, buf []byte) error {
 	_, err = gzw.Write(buf)
 	return err
 }
+
+func TestWalk(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	ctx := context.Background()
+
+	fn := filepath.Join(td, "dump.txt.gz")
+	require.NoError(t, testWriteGZ(fn, []byte(strings.ToLower(testHibpSampleUnsorted)+"\ninvalid\n")))
+
+	var hashes []string
+	require.NoError(t, Walk(ctx, fn, func(hash string) error {
+		hashes = append(hashes, hash)
+		return nil
+	}))
+	require.Len(t, hashes, 10)
+	assert.Equal(t, "000000005AD76BD555C1D6D771DE417A4B87E4B4", hashes[0])
+	assert.Equal(t, "00000000DD7F2A1C68A35673713783CA390C9E93", hashes[7])
+
+	assert.Error(t, Walk(ctx, filepath.Join(td, "missing.txt"), func(string) error { return nil }))
+}
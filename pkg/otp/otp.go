@@ -1,65 +1,299 @@
+// Package otp implements HOTP (RFC 4226) and TOTP (RFC 6238) one time
+// passwords. Keys are read from otpauth:// URLs, see
+// https://github.com/google/google-authenticator/wiki/Key-Uri-Format,
+// or from a base32 encoded totp key in a secret.
 package otp
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/gokyle/twofactor"
 	"github.com/gopasspw/gopass/pkg/gopass"
+	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
+	"github.com/skip2/go-qrcode"
 )
 
-// Calculate will compute a OTP code from a given secret
-func Calculate(name string, sec gopass.Secret) (twofactor.OTP, string, error) {
-	otpURL, found := sec.Get("otpauth")
-	if found && strings.HasPrefix(otpURL, "//") {
-		otpURL = "otpauth:" + otpURL
-	} else {
-		// check body
-		for _, line := range strings.Split(sec.Body(), "\n") {
-			if strings.HasPrefix(line, "otpauth://") {
-				otpURL = line
-				break
-			}
-		}
-	}
+const (
+	// TypeTOTP is a time based one time password
+	TypeTOTP = "totp"
+	// TypeHOTP is a counter based one time password
+	TypeHOTP = "hotp"
 
-	if otpURL != "" {
-		return twofactor.FromURL(otpURL)
-	}
+	defaultDigits = 6
+	defaultPeriod = 30
+)
+
+var (
+	// ErrNoKey is returned if a secret doesn't contain an OTP key
+	ErrNoKey = errors.New("no OTP key found")
+	// ErrInvalidURL is returned if an otpauth:// URL can not be parsed
+	ErrInvalidURL = errors.New("invalid OTP URL")
+)
+
+// Key is an OTP key
+type Key struct {
+	Type      string // TypeTOTP or TypeHOTP
+	Label     string
+	Issuer    string
+	Secret    []byte
+	Algorithm string // SHA1, SHA256 or SHA512
+	Digits    int    // 6 or 8
+	Period    int    // in seconds, TOTP only
+	Counter   uint64 // HOTP only
 
-	// check yaml entry and fall back to password if we don't have one
-	label := name
+	// source is the URL as found in the secret, used to update the counter
+	source string
+}
+
+// FromSecret returns the OTP key of the given secret. It looks for an
+// otpauth:// URL in the otpauth key, the body, the totp key and the password,
+// in that order. As a fallback the value of the totp key or the password is
+// used as a base32 encoded TOTP key.
+func FromSecret(name string, sec gopass.Secret) (*Key, error) {
+	if u, found := sec.Get("otpauth"); found && strings.HasPrefix(u, "//") {
+		return Parse("otpauth:" + u)
+	}
+	for _, line := range strings.Split(sec.Body(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "otpauth://") {
+			return Parse(line)
+		}
+	}
 
 	secKey, found := sec.Get("totp")
 	if !found {
 		secKey = sec.Password()
 	}
-
 	if strings.HasPrefix(secKey, "otpauth://") {
-		return twofactor.FromURL(secKey)
+		return Parse(secKey)
+	}
+	if secKey == "" {
+		return nil, ErrNoKey
+	}
+
+	key, err := decodeSecret(secKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoKey, err)
+	}
+	return &Key{
+		Type:      TypeTOTP,
+		Label:     name,
+		Secret:    key,
+		Algorithm: "SHA1",
+		Digits:    defaultDigits,
+		Period:    defaultPeriod,
+	}, nil
+}
+
+// Parse parses an otpauth:// URL
+func Parse(u string) (*Key, error) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
+	}
+	if pu.Scheme != "otpauth" {
+		return nil, fmt.Errorf("%w: scheme must be otpauth, not %q", ErrInvalidURL, pu.Scheme)
+	}
+
+	k := &Key{
+		Type:      strings.ToLower(pu.Host),
+		Label:     strings.TrimPrefix(pu.Path, "/"),
+		Algorithm: "SHA1",
+		Digits:    defaultDigits,
+		Period:    defaultPeriod,
+		source:    u,
+	}
+	if k.Type != TypeTOTP && k.Type != TypeHOTP {
+		return nil, fmt.Errorf("%w: unsupported type %q", ErrInvalidURL, pu.Host)
+	}
+
+	v := pu.Query()
+	k.Issuer = v.Get("issuer")
+
+	secret := v.Get("secret")
+	if secret == "" {
+		return nil, fmt.Errorf("%w: missing secret", ErrInvalidURL)
+	}
+	k.Secret, err = decodeSecret(secret)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURL, err)
 	}
 
-	otp, err := twofactor.NewGoogleTOTP(twofactor.Pad(secKey))
-	return otp, label, err
+	if sv := v.Get("algorithm"); sv != "" {
+		k.Algorithm = strings.ToUpper(sv)
+		if newHash(k.Algorithm) == nil {
+			return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidURL, sv)
+		}
+	}
+	if sv := v.Get("digits"); sv != "" {
+		k.Digits, err = strconv.Atoi(sv)
+		if err != nil || (k.Digits != 6 && k.Digits != 8) {
+			return nil, fmt.Errorf("%w: digits must be 6 or 8, not %q", ErrInvalidURL, sv)
+		}
+	}
+	if sv := v.Get("period"); sv != "" {
+		k.Period, err = strconv.Atoi(sv)
+		if err != nil || k.Period < 1 {
+			return nil, fmt.Errorf("%w: invalid period %q", ErrInvalidURL, sv)
+		}
+	}
+	if sv := v.Get("counter"); sv != "" {
+		k.Counter, err = strconv.ParseUint(sv, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid counter %q", ErrInvalidURL, sv)
+		}
+	}
+
+	return k, nil
 }
 
-// WriteQRFile writes the given OTP code as a QR image to disk
-func WriteQRFile(otp twofactor.OTP, label, file string) error {
-	var qr []byte
-	var err error
-	switch otp.Type() {
-	case twofactor.OATH_HOTP:
-		hotp := otp.(*twofactor.HOTP)
-		qr, err = hotp.QR(label)
-	case twofactor.OATH_TOTP:
-		totp := otp.(*twofactor.TOTP)
-		qr, err = totp.QR(label)
-	default:
-		err = fmt.Errorf("QR codes can only be generated for OATH OTPs")
+// decodeSecret decodes a base32 encoded secret. Spaces and missing
+// padding are tolerated.
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("secret is not valid base32")
 	}
+	if len(key) < 1 {
+		return nil, fmt.Errorf("empty secret")
+	}
+	return key, nil
+}
+
+func newHash(algo string) func() hash.Hash {
+	switch algo {
+	case "SHA1":
+		return sha1.New
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	}
+	return nil
+}
+
+// Generate returns the code for the given counter value (RFC 4226)
+func (k *Key) Generate(counter uint64) string {
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], counter)
+
+	h := hmac.New(newHash(k.Algorithm), k.Secret)
+	_, _ = h.Write(ctr[:])
+	sum := h.Sum(nil)
+
+	// dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, code%mod)
+}
+
+// Code returns the current code. For TOTP keys it is based on the given
+// time, for HOTP keys on the current counter.
+func (k *Key) Code(t time.Time) string {
+	if k.Type == TypeHOTP {
+		return k.Generate(k.Counter)
+	}
+	return k.Generate(uint64(t.Unix()) / uint64(k.Period))
+}
+
+// Remaining returns how long the TOTP code for the given time is still
+// valid. It's always zero for HOTP keys.
+func (k *Key) Remaining(t time.Time) time.Duration {
+	if k.Type == TypeHOTP {
+		return 0
+	}
+	period := int64(k.Period)
+	return time.Duration(period-t.Unix()%period) * time.Second
+}
+
+// URL returns the otpauth:// URL of this key
+func (k *Key) URL() string {
+	v := url.Values{}
+	v.Set("secret", strings.TrimRight(base32.StdEncoding.EncodeToString(k.Secret), "="))
+	if k.Issuer != "" {
+		v.Set("issuer", k.Issuer)
+	}
+	if k.Algorithm != "SHA1" {
+		v.Set("algorithm", k.Algorithm)
+	}
+	if k.Digits != defaultDigits {
+		v.Set("digits", strconv.Itoa(k.Digits))
+	}
+	if k.Type == TypeHOTP {
+		v.Set("counter", strconv.FormatUint(k.Counter, 10))
+	} else if k.Period != defaultPeriod {
+		v.Set("period", strconv.Itoa(k.Period))
+	}
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     k.Type,
+		Path:     "/" + k.Label,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// UpdateSecret returns a copy of the secret with the counter of the
+// HOTP key updated. The key must have been read from the secret.
+func (k *Key) UpdateSecret(sec gopass.Secret) (gopass.Secret, error) {
+	if k.source == "" {
+		return nil, fmt.Errorf("can only update keys read from an otpauth URL")
+	}
+
+	pu, err := url.Parse(k.source)
+	if err != nil {
+		return nil, err
+	}
+	v := pu.Query()
+	v.Set("counter", strconv.FormatUint(k.Counter, 10))
+	pu.RawQuery = v.Encode()
+	updated := pu.String()
+
+	old := k.source
+	buf := sec.Bytes()
+	if !bytes.Contains(buf, []byte(old)) {
+		// the otpauth key omits the scheme
+		old = strings.TrimPrefix(old, "otpauth:")
+		updated = strings.TrimPrefix(updated, "otpauth:")
+	}
+	if !bytes.Contains(buf, []byte(old)) {
+		return nil, fmt.Errorf("OTP URL not found in secret")
+	}
+
+	nsec, err := secparse.Parse(bytes.Replace(buf, []byte(old), []byte(updated), 1))
+	if err != nil {
+		return nil, err
+	}
+	k.source = pu.String()
+	return nsec, nil
+}
+
+// WriteQRFile writes the URL of the given OTP key as a QR image to disk
+func WriteQRFile(k *Key, file string) error {
+	qr, err := qrcode.Encode(k.URL(), qrcode.Medium, 256)
 	if err != nil {
-		return fmt.Errorf("failed to write qr file: %w", err)
+		return fmt.Errorf("failed to encode qr code: %w", err)
 	}
 
 	if err := os.WriteFile(file, qr, 0600); err != nil {
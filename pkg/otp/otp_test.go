@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/gokyle/twofactor"
 	"github.com/gopasspw/gopass/pkg/gopass/secrets/secparse"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,25 +16,180 @@ import (
 const pw string = "password"
 const totpSecret string = "GJWTGMTNN5YWW2TNPJXWG2DHMIFA"
 const totpURL string = "otpauth://totp/example-otp.com?secret=2m32moqkjmzochgb&issuer=authenticator&digits=6"
+const hotpURL string = "otpauth://hotp/example-otp.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=0"
 
-func TestCalculate(t *testing.T) {
+func TestFromSecret(t *testing.T) {
 	testCases := [][]byte{
 		[]byte(totpSecret),
 		[]byte(fmt.Sprintf("%s\ntotp: %s", pw, totpSecret)),
 		[]byte(fmt.Sprintf("%s\n---\ntotp: %s", pw, totpSecret)),
 		[]byte(fmt.Sprintf("%s\n%s", pw, totpURL)),
 		[]byte(fmt.Sprintf("%s\n---\n%s", pw, totpURL)),
+		[]byte(fmt.Sprintf("%s\notpauth: %s", pw, strings.TrimPrefix(totpURL, "otpauth:"))),
 	}
 
 	for _, tc := range testCases {
 		s, err := secparse.Parse(tc)
 		require.NoError(t, err)
-		otp, _, err := Calculate("test", s)
-		assert.NoError(t, err, string(tc))
-		assert.NotNil(t, otp, string(tc))
+		k, err := FromSecret("test", s)
+		require.NoError(t, err, string(tc))
+		assert.Equal(t, TypeTOTP, k.Type, string(tc))
+		assert.Len(t, k.Code(time.Now()), 6, string(tc))
 	}
 }
 
+func TestFromSecretErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		err error
+	}{
+		{
+			in:  "",
+			err: ErrNoKey,
+		},
+		{
+			in:  "not base32!",
+			err: ErrNoKey,
+		},
+		{
+			in:  pw + "\notpauth://totp/foo?issuer=bar",
+			err: ErrInvalidURL,
+		},
+		{
+			in:  pw + "\notpauth://totp/foo?secret=not-base32!",
+			err: ErrInvalidURL,
+		},
+	} {
+		s, err := secparse.Parse([]byte(tc.in))
+		require.NoError(t, err)
+		_, err = FromSecret("test", s)
+		assert.ErrorIs(t, err, tc.err, tc.in)
+	}
+}
+
+// RFC 6238 Appendix B
+func TestTOTPRFC6238(t *testing.T) {
+	secrets := map[string]string{
+		"SHA1":   "12345678901234567890",
+		"SHA256": "12345678901234567890123456789012",
+		"SHA512": "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	for _, tc := range []struct {
+		ts   int64
+		algo string
+		code string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1111111109, "SHA256", "68084774"},
+		{1111111109, "SHA512", "25091201"},
+		{1111111111, "SHA1", "14050471"},
+		{1111111111, "SHA256", "67062674"},
+		{1111111111, "SHA512", "99943326"},
+		{1234567890, "SHA1", "89005924"},
+		{1234567890, "SHA256", "91819424"},
+		{1234567890, "SHA512", "93441116"},
+		{2000000000, "SHA1", "69279037"},
+		{2000000000, "SHA256", "90698825"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+		{20000000000, "SHA256", "77737706"},
+		{20000000000, "SHA512", "47863826"},
+	} {
+		k := &Key{
+			Type:      TypeTOTP,
+			Secret:    []byte(secrets[tc.algo]),
+			Algorithm: tc.algo,
+			Digits:    8,
+			Period:    30,
+		}
+		assert.Equal(t, tc.code, k.Code(time.Unix(tc.ts, 0)), "%s at %d", tc.algo, tc.ts)
+
+		// the same key must work when read from an URL
+		pk, err := Parse(k.URL())
+		require.NoError(t, err)
+		assert.Equal(t, tc.code, pk.Code(time.Unix(tc.ts, 0)), "%s at %d", tc.algo, tc.ts)
+	}
+}
+
+// RFC 4226 Appendix D
+func TestHOTPRFC4226(t *testing.T) {
+	k, err := Parse(hotpURL)
+	require.NoError(t, err)
+	assert.Equal(t, TypeHOTP, k.Type)
+	assert.Equal(t, []byte("12345678901234567890"), k.Secret)
+
+	for i, code := range []string{
+		"755224", "287082", "359152", "969429", "338314",
+		"254676", "287922", "162583", "399871", "520489",
+	} {
+		assert.Equal(t, code, k.Generate(uint64(i)))
+	}
+	assert.Equal(t, "755224", k.Code(time.Now()))
+	assert.Equal(t, time.Duration(0), k.Remaining(time.Now()))
+}
+
+func TestParse(t *testing.T) {
+	k, err := Parse("otpauth://totp/ACME:jane@example.org?secret=JBSWY3DPEHPK3PXP&issuer=ACME&algorithm=sha256&digits=8&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, "ACME:jane@example.org", k.Label)
+	assert.Equal(t, "ACME", k.Issuer)
+	assert.Equal(t, "SHA256", k.Algorithm)
+	assert.Equal(t, 8, k.Digits)
+	assert.Equal(t, 60, k.Period)
+
+	assert.Equal(t, 60*time.Second, k.Remaining(time.Unix(120, 0)))
+	assert.Equal(t, 1*time.Second, k.Remaining(time.Unix(179, 0)))
+
+	for _, in := range []string{
+		"http://totp/foo?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://motp/foo?secret=JBSWY3DPEHPK3PXP",
+		"otpauth://totp/foo",
+		"otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP&algorithm=MD5",
+		"otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP&digits=7",
+		"otpauth://totp/foo?secret=JBSWY3DPEHPK3PXP&period=0",
+		"otpauth://hotp/foo?secret=JBSWY3DPEHPK3PXP&counter=-1",
+		"otpauth://totp/foo?secret=%zz",
+	} {
+		_, err := Parse(in)
+		assert.ErrorIs(t, err, ErrInvalidURL, in)
+	}
+}
+
+func TestUpdateSecret(t *testing.T) {
+	for _, in := range []string{
+		pw + "\n" + hotpURL + "\nfoo: bar",
+		pw + "\nfoo: bar\notpauth: " + strings.TrimPrefix(hotpURL, "otpauth:"),
+	} {
+		sec, err := secparse.Parse([]byte(in))
+		require.NoError(t, err)
+
+		k, err := FromSecret("test", sec)
+		require.NoError(t, err)
+		k.Counter++
+
+		nsec, err := k.UpdateSecret(sec)
+		require.NoError(t, err)
+		assert.Equal(t, pw, nsec.Password())
+		assert.Contains(t, string(nsec.Bytes()), "counter=1")
+
+		nk, err := FromSecret("test", nsec)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), nk.Counter)
+		assert.Equal(t, "287082", nk.Code(time.Now()))
+	}
+
+	// keys without an URL can't be updated
+	sec, err := secparse.Parse([]byte(totpSecret))
+	require.NoError(t, err)
+	k, err := FromSecret("test", sec)
+	require.NoError(t, err)
+	_, err = k.UpdateSecret(sec)
+	assert.Error(t, err)
+}
+
 func TestWrite(t *testing.T) {
 	td, err := os.MkdirTemp("", "gopass-")
 	assert.NoError(t, err)
@@ -42,7 +198,8 @@ func TestWrite(t *testing.T) {
 	}()
 	tf := filepath.Join(td, "qr.png")
 
-	otp, label, err := twofactor.FromURL(totpURL)
+	k, err := Parse(totpURL)
 	assert.NoError(t, err)
-	assert.NoError(t, WriteQRFile(otp, label, tf))
+	assert.NoError(t, WriteQRFile(k, tf))
+	assert.FileExists(t, tf)
 }
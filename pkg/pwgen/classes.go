@@ -0,0 +1,108 @@
+package pwgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxRounds limits the number of passwords drawn before falling back to
+// placing one character of each class explicitly
+const maxRounds = 1024
+
+// Charset selects the character classes of a generated password
+type Charset struct {
+	Digits bool
+	Upper  bool
+	Lower  bool
+	// Symbols is the symbol alphabet. No symbols are used if it's empty.
+	Symbols string
+	// NoAmbiguous removes characters that are easily confused, e.g. 0 and O
+	NoAmbiguous bool
+}
+
+// DefaultCharset returns a charset with digits, upper and lower case letters
+// and optionally all symbols
+func DefaultCharset(symbols bool) Charset {
+	cs := Charset{
+		Digits: true,
+		Upper:  true,
+		Lower:  true,
+	}
+	if symbols {
+		cs.Symbols = Syms
+	}
+	return cs
+}
+
+// Classes returns the selected, non-empty character classes
+func (cs Charset) Classes() []string {
+	classes := make([]string, 0, 4)
+	for _, class := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{cs.Digits, Digits},
+		{cs.Upper, Upper},
+		{cs.Lower, Lower},
+		{cs.Symbols != "", cs.Symbols},
+	} {
+		if !class.enabled {
+			continue
+		}
+		chars := uniqueChars(class.chars)
+		if cs.NoAmbiguous {
+			chars = Prune(chars, Ambiq)
+		}
+		if chars == "" {
+			continue
+		}
+		classes = append(classes, chars)
+	}
+	return classes
+}
+
+// GeneratePasswordClasses generates a random password that contains at least
+// one character of each of the given classes. The passwords are drawn from
+// the union of all classes until one contains every class. This keeps the
+// distribution uniform among all such passwords. Only if that takes too long
+// (e.g. for tiny classes) one character of each class is placed explicitly.
+func GeneratePasswordClasses(length int, classes ...string) (string, error) {
+	if len(classes) < 1 {
+		return "", fmt.Errorf("no character classes selected")
+	}
+	return randomWithClasses(length, []rune(uniqueChars(strings.Join(classes, ""))), classes)
+}
+
+// randomWithClasses draws a password from chars that contains at least one
+// character of each class
+func randomWithClasses(length int, chars []rune, classes []string) (string, error) {
+	if length < len(classes) {
+		return "", fmt.Errorf("a password of %d characters can not contain all %d character classes", length, len(classes))
+	}
+
+	for i := 0; i < maxRounds; i++ {
+		pw := randomRunes(length, chars)
+		if containsAllClasses(string(pw), classes...) {
+			return string(pw), nil
+		}
+	}
+
+	pw := randomRunes(length-len(classes), chars)
+	for _, class := range classes {
+		pw = append(pw, randomRunes(1, []rune(class))...)
+	}
+	// Fisher-Yates shuffle, so the required characters end up anywhere
+	for i := len(pw) - 1; i > 0; i-- {
+		j := randomInteger(i + 1)
+		pw[i], pw[j] = pw[j], pw[i]
+	}
+	return string(pw), nil
+}
+
+func randomRunes(length int, chars []rune) []rune {
+	pw := make([]rune, 0, length)
+	for len(pw) < length {
+		pw = append(pw, chars[randomInteger(len(chars))])
+	}
+	return pw
+}
@@ -0,0 +1,72 @@
+package pwgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharsetClasses(t *testing.T) {
+	assert.Equal(t, []string{Digits, Upper, Lower}, DefaultCharset(false).Classes())
+	assert.Len(t, DefaultCharset(true).Classes(), 4)
+
+	cs := Charset{Digits: true, Upper: true, Symbols: "!!#", NoAmbiguous: true}
+	assert.Equal(t, []string{"3479", "ACEFHJKLMNPRTUVWXY", "!#"}, cs.Classes())
+
+	// classes that are empty after pruning are dropped
+	cs = Charset{Lower: true, Symbols: "0O", NoAmbiguous: true}
+	assert.Equal(t, []string{Prune(Lower, Ambiq)}, cs.Classes())
+
+	assert.Equal(t, "", GeneratePasswordCharsetClasses(8, Charset{}))
+}
+
+func TestGeneratePasswordClasses(t *testing.T) {
+	_, err := GeneratePasswordClasses(8)
+	assert.Error(t, err)
+	_, err = GeneratePasswordClasses(3, Digits, Upper, Lower, Syms)
+	assert.Error(t, err)
+
+	// forces the fallback, drawing a matching password is very unlikely
+	pw, err := GeneratePasswordClasses(4, Lower+Upper+Digits+Syms, "€", "ä", "ß")
+	require.NoError(t, err)
+	assert.Len(t, []rune(pw), 4)
+	for _, c := range []string{"€", "ä", "ß"} {
+		assert.Contains(t, pw, c)
+	}
+}
+
+// TestGeneratePasswordClassesDistribution generates many passwords to check
+// that every class is always included and that all characters of a class
+// are roughly equally likely
+func TestGeneratePasswordClassesDistribution(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping statistical test in short mode")
+	}
+
+	classes := DefaultCharset(true).Classes()
+	counts := make(map[rune]int, len(CharAll))
+	for i := 0; i < 10000; i++ {
+		pw, err := GeneratePasswordClasses(8, classes...)
+		require.NoError(t, err)
+		require.Len(t, pw, 8)
+		require.True(t, containsAllClasses(pw, classes...), pw)
+		for _, r := range pw {
+			counts[r]++
+		}
+	}
+
+	for _, class := range classes {
+		sum := 0
+		for _, r := range class {
+			sum += counts[r]
+		}
+		mean := float64(sum) / float64(len(class))
+		for _, r := range class {
+			// more than six standard deviations for each class
+			assert.InDelta(t, mean, float64(counts[r]), mean*0.2, "character %q of class %s", r, class)
+		}
+	}
+	assert.Len(t, counts, len(strings.Join(classes, "")))
+}
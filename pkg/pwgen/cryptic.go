@@ -14,8 +14,10 @@ import (
 // Cryptic is a generator for hard-to-remember passwords as required by (too)
 // many sites. Prefer memorable or xkcd-style passwords, if possible.
 type Cryptic struct {
-	Chars      string
-	Length     int
+	Chars  string
+	Length int
+	// Classes that must be included, each class is a string of characters
+	Classes    []string
 	MaxTries   int
 	Validators []func(string) error
 }
@@ -39,12 +41,18 @@ func NewCryptic(length int, symbols bool) *Cryptic {
 // NewCrypticForDomain tries to look up password rules for the given domain
 // or uses the default generator.
 func NewCrypticForDomain(length int, domain string) *Cryptic {
-	c := NewCryptic(length, true)
 	r, found := pwrules.LookupRule(domain)
 	debug.Log("found rules for %s: %t", domain, found)
 	if !found {
-		return c
+		return NewCryptic(length, true)
 	}
+	return NewCrypticForRule(length, r)
+}
+
+// NewCrypticForRule returns a generator for passwords that satisfy the given
+// rule. The length is adjusted to the limits of the rule.
+func NewCrypticForRule(length int, r pwrules.Rule) *Cryptic {
+	c := NewCryptic(length, true)
 	if r.Maxlen > 0 && c.Length > r.Maxlen {
 		c.Length = r.Maxlen
 	}
@@ -59,17 +67,12 @@ func NewCrypticForDomain(length int, domain string) *Cryptic {
 		if req == "" || strings.TrimSpace(chars) == "" {
 			continue
 		}
-		debug.Log("Adding validator for %s: Requires %q -> %q", domain, req, chars)
-		c.Validators = append(c.Validators, func(pw string) error {
-			wantChars := charsFromRule(req)
-			if wantChars == "" {
-				return nil
-			}
-			if containsAllClasses(pw, wantChars) {
-				return nil
-			}
-			return fmt.Errorf("password %s does not contain any of %s", pw, chars)
-		})
+		debug.Log("Requiring %q -> %q", req, chars)
+		c.Classes = append(c.Classes, chars)
+	}
+	// each required class needs at least one character
+	if c.Length < len(c.Classes) {
+		c.Length = len(c.Classes)
 	}
 	if r.Maxconsec > 0 {
 		c.Validators = append(c.Validators, func(pw string) error {
@@ -179,6 +182,11 @@ func (c *Cryptic) isValid(pw string) bool {
 }
 
 func (c *Cryptic) randomString() string {
+	if len(c.Classes) > 0 {
+		if pw, err := randomWithClasses(c.Length, []rune(c.Chars), c.Classes); err == nil {
+			return pw
+		}
+	}
 	pw := &bytes.Buffer{}
 	for pw.Len() < c.Length {
 		_ = pw.WriteByte(c.Chars[randomInteger(len(c.Chars))])
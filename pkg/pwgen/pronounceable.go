@@ -0,0 +1,145 @@
+package pwgen
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// consonants and vowels are the letter clusters pronounceable passwords are
+// made of. Consonant and vowel clusters always alternate, so every password
+// can only be built in a single way. This keeps the entropy estimate exact.
+var (
+	consonants = []string{
+		"b", "c", "d", "f", "g", "h", "j", "k", "l", "m", "n", "p", "r", "s", "t", "v", "w", "x", "y", "z",
+		"bl", "br", "ch", "ck", "cl", "cr", "dr", "fl", "fr", "gl", "gr", "nd", "ng", "nt", "ph", "pl",
+		"pr", "rk", "rt", "sh", "sk", "sl", "sm", "sn", "sp", "st", "th", "tr", "wh",
+	}
+	vowels = []string{
+		"a", "e", "i", "o", "u",
+		"ai", "au", "ea", "ee", "ei", "ia", "ie", "io", "oa", "oo", "ou", "ue", "ui",
+	}
+)
+
+// PronounceableOptions control how a pronounceable password is generated
+type PronounceableOptions struct {
+	// Length is the number of characters
+	Length int
+	// Capital turns one random letter into a capital
+	Capital bool
+	// Digit inserts a random digit at a random position
+	Digit bool
+}
+
+// GeneratePronounceable returns a random password of alternating consonant
+// and vowel clusters, like the default mode of pwgen(1), and its entropy in
+// bits. Such passwords are easy to read out, but weaker per character than
+// cryptic ones. Only crypto/rand is used, there is no fallback to a PRNG.
+func GeneratePronounceable(o PronounceableOptions) (string, float64, error) {
+	letters := o.Length
+	if o.Digit {
+		letters--
+	}
+	if letters < 1 {
+		return "", 0, fmt.Errorf("password length must be at least %d", o.Length-letters+1)
+	}
+
+	tables := [2][]string{consonants, vowels}
+	k, err := cryptoIntn(2)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var sb strings.Builder
+	for sb.Len() < letters {
+		candidates := fitting(tables[k], letters-sb.Len())
+		i, err := cryptoIntn(len(candidates))
+		if err != nil {
+			return "", 0, err
+		}
+		sb.WriteString(candidates[i])
+		k = 1 - k
+	}
+	pw := []byte(sb.String())
+
+	if o.Capital {
+		i, err := cryptoIntn(len(pw))
+		if err != nil {
+			return "", 0, err
+		}
+		pw[i] = strings.ToUpper(string(pw[i]))[0]
+	}
+	if o.Digit {
+		d, err := cryptoIntn(len(Digits))
+		if err != nil {
+			return "", 0, err
+		}
+		i, err := cryptoIntn(len(pw) + 1)
+		if err != nil {
+			return "", 0, err
+		}
+		pw = append(pw[:i], append([]byte{Digits[d]}, pw[i:]...)...)
+	}
+
+	return string(pw), PronounceableEntropy(o), nil
+}
+
+// PronounceableEntropy returns the entropy in bits of the passwords
+// generated with the given options
+func PronounceableEntropy(o PronounceableOptions) float64 {
+	letters := o.Length
+	if o.Digit {
+		letters--
+	}
+	if letters < 1 {
+		return 0
+	}
+
+	// memo[k][r] is the entropy of the remaining r letters starting with
+	// a cluster of table k
+	tables := [2][]string{consonants, vowels}
+	memo := [2][]float64{make([]float64, letters+1), make([]float64, letters+1)}
+	for r := 1; r <= letters; r++ {
+		for k := 0; k < 2; k++ {
+			candidates := fitting(tables[k], r)
+			n := float64(len(candidates))
+			var rest float64
+			for _, c := range candidates {
+				rest += memo[1-k][r-len(c)]
+			}
+			memo[k][r] = math.Log2(n) + rest/n
+		}
+	}
+
+	// the first cluster is a consonant or a vowel
+	bits := 1 + (memo[0][letters]+memo[1][letters])/2
+	if o.Capital {
+		bits += math.Log2(float64(letters))
+	}
+	if o.Digit {
+		bits += math.Log2(float64(len(Digits))) + math.Log2(float64(letters+1))
+	}
+	return bits
+}
+
+// fitting returns the clusters with at most n letters
+func fitting(table []string, n int) []string {
+	res := make([]string, 0, len(table))
+	for _, c := range table {
+		if len(c) <= n {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// cryptoIntn returns a random integer in [0, n) from crypto/rand
+func cryptoIntn(n int) (int, error) {
+	i, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read from crypto/rand: %w", err)
+	}
+	return int(i.Int64()), nil
+}
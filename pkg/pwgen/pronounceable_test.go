@@ -0,0 +1,76 @@
+package pwgen
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPronounceableTables(t *testing.T) {
+	// every password must be built in a single way, otherwise the entropy
+	// estimate is too high
+	for name, table := range map[string][]string{"consonants": consonants, "vowels": vowels} {
+		seen := map[string]bool{}
+		for _, c := range table {
+			assert.False(t, seen[c], "duplicate %s %q", name, c)
+			seen[c] = true
+		}
+	}
+	for _, c := range consonants {
+		assert.False(t, strings.ContainsAny(c, "aeiou"), c)
+	}
+	for _, v := range vowels {
+		assert.Equal(t, "", strings.Trim(v, "aeiou"), v)
+	}
+}
+
+func TestGeneratePronounceable(t *testing.T) {
+	for _, o := range []PronounceableOptions{
+		{Length: 1},
+		{Length: 12},
+		{Length: 16, Capital: true},
+		{Length: 10, Capital: true, Digit: true},
+		{Length: 2, Digit: true},
+	} {
+		for i := 0; i < 50; i++ {
+			pw, bits, err := GeneratePronounceable(o)
+			require.NoError(t, err)
+			assert.Len(t, pw, o.Length)
+			assert.Equal(t, PronounceableEntropy(o), bits)
+
+			var upper, digits int
+			for _, r := range pw {
+				switch {
+				case unicode.IsUpper(r):
+					upper++
+				case unicode.IsDigit(r):
+					digits++
+				default:
+					assert.True(t, unicode.IsLower(r), pw)
+				}
+			}
+			assert.Equal(t, o.Capital, upper == 1, pw)
+			assert.Equal(t, o.Digit, digits == 1, pw)
+		}
+	}
+
+	_, _, err := GeneratePronounceable(PronounceableOptions{})
+	assert.Error(t, err)
+	_, _, err = GeneratePronounceable(PronounceableOptions{Length: 1, Digit: true})
+	assert.Error(t, err)
+}
+
+func TestPronounceableEntropy(t *testing.T) {
+	// a single letter is one of 20 consonants or one of 5 vowels
+	assert.InDelta(t, 1+(math.Log2(20)+math.Log2(5))/2, PronounceableEntropy(PronounceableOptions{Length: 1}), 0.001)
+
+	bits := PronounceableEntropy(PronounceableOptions{Length: 12})
+	// weaker than random lowercase letters, but not by much
+	assert.True(t, bits > 35 && bits < 12*math.Log2(26), bits)
+	assert.True(t, PronounceableEntropy(PronounceableOptions{Length: 12, Capital: true, Digit: true}) > bits)
+	assert.Equal(t, 0.0, PronounceableEntropy(PronounceableOptions{}))
+}
@@ -6,7 +6,6 @@
 package pwgen
 
 import (
-	"fmt"
 	"os"
 	"strings"
 )
@@ -26,16 +25,28 @@ const (
 	CharAll = Digits + Upper + Lower + Syms
 )
 
-// GeneratePassword generates a random, hard to remember password
+// GeneratePassword generates a random, hard to remember password. It
+// contains every character class unless the password is too short for it.
 func GeneratePassword(length int, symbols bool) string {
-	chars := Digits + Upper + Lower
-	if symbols {
-		chars += Syms
-	}
+	return GeneratePasswordCharsetClasses(length, DefaultCharset(symbols))
+}
+
+// GeneratePasswordCharsetClasses generates a random password from the given
+// charset. It contains every selected class unless the password is too short
+// for it. It returns an empty string if no class is selected. The
+// GOPASS_CHARACTER_SET environment variable overrides the charset.
+func GeneratePasswordCharsetClasses(length int, cs Charset) string {
 	if c := os.Getenv("GOPASS_CHARACTER_SET"); c != "" {
-		chars = c
+		return GeneratePasswordCharset(length, c)
 	}
-	return GeneratePasswordCharset(length, chars)
+	classes := cs.Classes()
+	if len(classes) < 1 {
+		return ""
+	}
+	if pw, err := GeneratePasswordClasses(length, classes...); err == nil {
+		return pw
+	}
+	return GeneratePasswordCharset(length, strings.Join(classes, ""))
 }
 
 // GeneratePasswordCharset generates a random password from a given
@@ -51,11 +62,7 @@ func GeneratePasswordCharset(length int, chars string) string {
 // This is especially useful for broken (corporate) password policies
 // that mandate the use of certain character classes for no good reason
 func GeneratePasswordWithAllClasses(length int, symbols bool) (string, error) {
-	c := NewCrypticWithAllClasses(length, symbols)
-	if pw := c.Password(); pw != "" {
-		return pw, nil
-	}
-	return "", fmt.Errorf("failed to generate matching password after %d rounds", c.MaxTries)
+	return GeneratePasswordClasses(length, DefaultCharset(symbols).Classes()...)
 }
 
 // GeneratePasswordCharsetCheck generates a random password from a given
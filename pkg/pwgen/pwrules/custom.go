@@ -0,0 +1,82 @@
+package pwrules
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RulesFile is the name of the file containing custom password rules in the
+// root of a store
+const RulesFile = ".pwrules"
+
+// Rules maps domain patterns (e.g. "example.com" or "*.example.com") to
+// password rules
+type Rules map[string]Rule
+
+// ParseRules parses the content of a rules file. Each line contains a domain
+// pattern followed by a rule in the format understood by ParseRule, e.g.
+//
+//	*.example.com minlength: 8; maxlength: 16; required: digit; allowed: lower, upper;
+//
+// Empty lines and lines starting with # are ignored.
+func ParseRules(buf []byte) (Rules, error) {
+	rs := make(Rules)
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	lineNo := 0
+	for s.Scan() {
+		lineNo++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := strings.SplitN(line, " ", 2)
+		if len(p) < 2 || strings.TrimSpace(p[1]) == "" {
+			return nil, fmt.Errorf("line %d: missing rule for %q", lineNo, p[0])
+		}
+		if _, err := path.Match(p[0], ""); err != nil {
+			return nil, fmt.Errorf("line %d: invalid domain pattern %q: %w", lineNo, p[0], err)
+		}
+		r := ParseRule(strings.TrimSpace(p[1]))
+		if r.Maxlen < 1 {
+			r.Maxlen = math.MaxInt32
+		}
+		if r.Minlen > r.Maxlen {
+			return nil, fmt.Errorf("line %d: minlength %d exceeds maxlength %d", lineNo, r.Minlen, r.Maxlen)
+		}
+		rs[strings.ToLower(p[0])] = r
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Lookup returns the rule for the given domain. An exact match is preferred,
+// otherwise the longest matching pattern is used.
+func (rs Rules) Lookup(domain string) (Rule, bool) {
+	domain = strings.ToLower(domain)
+	if r, found := rs[domain]; found {
+		return r, true
+	}
+	patterns := make([]string, 0, len(rs))
+	for p := range rs {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) == len(patterns[j]) {
+			return patterns[i] < patterns[j]
+		}
+		return len(patterns[i]) > len(patterns[j])
+	})
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, domain); ok {
+			return rs[p], true
+		}
+	}
+	return Rule{}, false
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseRule(t *testing.T) {
@@ -57,3 +58,39 @@ func TestParseRule(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRules(t *testing.T) {
+	rs, err := ParseRules([]byte(`# custom rules
+example.com minlength: 8; maxlength: 16; required: digit;
+
+*.example.com minlength: 10;
+*.com maxlength: 32;
+`))
+	require.NoError(t, err)
+	assert.Len(t, rs, 3)
+
+	r, found := rs.Lookup("Example.com")
+	assert.True(t, found)
+	assert.Equal(t, 16, r.Maxlen)
+	assert.Equal(t, []string{"digit"}, r.Required)
+
+	r, found = rs.Lookup("login.example.com")
+	assert.True(t, found)
+	assert.Equal(t, 10, r.Minlen)
+
+	r, found = rs.Lookup("golang.com")
+	assert.True(t, found)
+	assert.Equal(t, 32, r.Maxlen)
+
+	_, found = rs.Lookup("golang.org")
+	assert.False(t, found)
+
+	for _, in := range []string{
+		"example.com",
+		"[example.com minlength: 8;",
+		"example.com minlength: 16; maxlength: 8;",
+	} {
+		_, err := ParseRules([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
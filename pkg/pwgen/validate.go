@@ -1,9 +1,46 @@
 package pwgen
 
 import (
+	"fmt"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
 )
 
+// CheckRule returns an error describing the first violation of the given
+// rule by the password, if any. The password is never part of the error.
+func CheckRule(r pwrules.Rule, pw string) error {
+	l := utf8.RuneCountInString(pw)
+	if l < r.Minlen {
+		return fmt.Errorf("shorter than %d characters", r.Minlen)
+	}
+	if r.Maxlen > 0 && l > r.Maxlen {
+		return fmt.Errorf("longer than %d characters", r.Maxlen)
+	}
+	for _, req := range r.Required {
+		chars := charsFromRule(req)
+		if chars == "" {
+			continue
+		}
+		if !containsAllClasses(pw, chars) {
+			return fmt.Errorf("missing a required character (%s)", req)
+		}
+	}
+	if len(r.Allowed) > 0 {
+		chars := charsFromRule(append(r.Required, r.Allowed...)...)
+		for _, c := range pw {
+			if !strings.ContainsRune(chars, c) {
+				return fmt.Errorf("contains characters that are not allowed")
+			}
+		}
+	}
+	if r.Maxconsec > 0 && !containsMaxConsecutive(pw, r.Maxconsec) {
+		return fmt.Errorf("contains %d or more identical characters in a row", r.Maxconsec)
+	}
+	return nil
+}
+
 // containsAllClasses validates that the password contains at least one
 // character from each given character class. Can also contain other classes.
 func containsAllClasses(pw string, classes ...string) bool {
@@ -3,6 +3,7 @@ package pwgen
 import (
 	"testing"
 
+	"github.com/gopasspw/gopass/pkg/pwgen/pwrules"
 	"gotest.tools/assert"
 )
 
@@ -36,3 +37,20 @@ func TestContainsOnly(t *testing.T) {
 		assert.Equal(t, true, containsOnlyClasses(tc, Upper+Lower))
 	}
 }
+
+func TestCheckRule(t *testing.T) {
+	r := pwrules.ParseRule("minlength: 8; maxlength: 12; required: digit; allowed: lower;")
+	assert.NilError(t, CheckRule(r, "abcdefg1"))
+	for pw, msg := range map[string]string{
+		"abcdef1":       "shorter than 8 characters",
+		"abcdefghijkl1": "longer than 12 characters",
+		"abcdefgh":      "missing a required character (digit)",
+		"abcdefG1":      "contains characters that are not allowed",
+	} {
+		assert.Error(t, CheckRule(r, pw), msg, pw)
+	}
+
+	r = pwrules.ParseRule("minlength: 4; max-consecutive: 3;")
+	assert.NilError(t, CheckRule(r, "aab!!c"))
+	assert.Error(t, CheckRule(r, "aaab"), "contains 3 or more identical characters in a row")
+}
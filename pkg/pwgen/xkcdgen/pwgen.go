@@ -1,6 +1,43 @@
 package xkcdgen
 
-import "github.com/martinhoefling/goxkcdpwgen/xkcdpwgen"
+import (
+	"bufio"
+	crand "crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/martinhoefling/goxkcdpwgen/xkcdpwgen"
+)
+
+// MinWordlistSize is the minimum number of distinct words a custom wordlist
+// must contain. Smaller lists make for weak passphrases.
+const MinWordlistSize = 1024
+
+// wordlistSizes are the number of words in the wordlists embedded in
+// goxkcdpwgen. "en" is the EFF long wordlist.
+var wordlistSizes = map[string]int{
+	"en": 7776,
+	"de": 23256,
+}
+
+// Options control how a passphrase is generated
+type Options struct {
+	// Words is the number of words
+	Words int
+	// Separator is put between the words
+	Separator string
+	// Capitalize the first character of each word
+	Capitalize bool
+	// Digit appends a random digit, for sites requiring one
+	Digit bool
+	// Lang selects the embedded wordlist, ignored if Wordlist is set
+	Lang string
+	// Wordlist is a custom list of words, see LoadWordlist
+	Wordlist []string
+}
 
 // Random returns a random passphrase combined from four words
 func Random() string {
@@ -17,12 +54,99 @@ func RandomLength(length int, lang string) (string, error) {
 // RandomLengthDelim returns a random passphrase combined from the desired number
 // of words and the given delimiter. Words are drawn from lang
 func RandomLengthDelim(length int, delim, lang string) (string, error) {
+	pw, _, err := Generate(Options{
+		Words:      length,
+		Separator:  delim,
+		Capitalize: delim == "",
+		Lang:       lang,
+	})
+	return pw, err
+}
+
+// Generate returns a random passphrase and its entropy in bits
+func Generate(o Options) (string, float64, error) {
+	if o.Words < 1 {
+		return "", 0, fmt.Errorf("number of words must be positive")
+	}
+
 	g := xkcdpwgen.NewGenerator()
-	g.SetNumWords(length)
-	g.SetDelimiter(delim)
-	g.SetCapitalize(delim == "")
-	if err := g.UseLangWordlist(lang); err != nil {
-		return "", err
+	g.SetNumWords(o.Words)
+	g.SetDelimiter(o.Separator)
+	g.SetCapitalize(o.Capitalize)
+
+	size := len(o.Wordlist)
+	if size > 0 {
+		g.UseCustomWordlist(o.Wordlist)
+	} else {
+		if o.Lang == "" {
+			o.Lang = "en"
+		}
+		if err := g.UseLangWordlist(o.Lang); err != nil {
+			return "", 0, err
+		}
+		size = wordlistSizes[o.Lang]
+	}
+
+	pw := g.GeneratePasswordString()
+	if o.Digit {
+		d, err := crand.Int(crand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to generate digit: %w", err)
+		}
+		pw += d.String()
+	}
+
+	return pw, Entropy(o.Words, size, o.Digit), nil
+}
+
+// Entropy returns the entropy in bits of a passphrase made of the given
+// number of words drawn from a list of size distinct words
+func Entropy(words, size int, digit bool) float64 {
+	if size < 1 {
+		return 0
+	}
+	bits := float64(words) * math.Log2(float64(size))
+	if digit {
+		bits += math.Log2(10)
+	}
+	return bits
+}
+
+// LoadWordlist reads a custom wordlist. Each non-empty line is one word,
+// lines starting with a # are ignored. The EFF dice format ("11111 abacus")
+// is supported, too. Duplicates are removed and the list must contain at
+// least MinWordlistSize words.
+func LoadWordlist(fn string) ([]string, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wordlist: %w", err)
+	}
+	defer func() {
+		_ = fh.Close()
+	}()
+
+	seen := make(map[string]struct{}, MinWordlistSize)
+	words := make([]string, 0, MinWordlistSize)
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		word := fields[len(fields)-1]
+		if _, found := seen[word]; found {
+			continue
+		}
+		seen[word] = struct{}{}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+
+	if len(words) < MinWordlistSize {
+		return nil, fmt.Errorf("wordlist %s contains only %d distinct words, need at least %d", fn, len(words), MinWordlistSize)
 	}
-	return string(g.GeneratePassword()), nil
+	return words, nil
 }
@@ -1,10 +1,15 @@
 package xkcdgen
 
 import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRandom(t *testing.T) {
@@ -21,3 +26,60 @@ func TestRandomLengthDelim(t *testing.T) {
 	_, err := RandomLengthDelim(10, " ", "cn_ZH")
 	assert.Error(t, err)
 }
+
+func TestGenerate(t *testing.T) {
+	pw, bits, err := Generate(Options{Words: 4, Separator: "-"})
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(pw, "-"), 4)
+	assert.Equal(t, "51.7", fmt.Sprintf("%.1f", bits))
+
+	pw, bits, err = Generate(Options{Words: 3, Separator: ".", Capitalize: true, Digit: true})
+	require.NoError(t, err)
+	words := strings.Split(pw, ".")
+	require.Len(t, words, 3)
+	for _, w := range words {
+		assert.Equal(t, strings.ToUpper(w[:1]), w[:1])
+	}
+	last := words[2]
+	assert.Contains(t, "0123456789", last[len(last)-1:])
+	assert.InDelta(t, 3*math.Log2(7776)+math.Log2(10), bits, 0.001)
+
+	_, _, err = Generate(Options{Words: 0})
+	assert.Error(t, err)
+}
+
+func TestLoadWordlist(t *testing.T) {
+	td, err := os.MkdirTemp("", "gopass-")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(td)
+	}()
+
+	buf := &strings.Builder{}
+	buf.WriteString("# my wordlist\n\n")
+	for i := 0; i < MinWordlistSize; i++ {
+		fmt.Fprintf(buf, "%05d\tword%d\n", i, i)
+	}
+	// duplicates are removed
+	buf.WriteString("word1\nword2\n")
+	fn := filepath.Join(td, "words.txt")
+	require.NoError(t, os.WriteFile(fn, []byte(buf.String()), 0644))
+
+	wl, err := LoadWordlist(fn)
+	require.NoError(t, err)
+	assert.Len(t, wl, MinWordlistSize)
+	assert.Equal(t, "word0", wl[0])
+
+	pw, bits, err := Generate(Options{Words: 5, Separator: " ", Wordlist: wl})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pw, "word"), pw)
+	assert.InDelta(t, 50.0, bits, 0.001)
+
+	// too small
+	require.NoError(t, os.WriteFile(fn, []byte("foo\nbar\nfoo\n"), 0644))
+	_, err = LoadWordlist(fn)
+	assert.Error(t, err)
+
+	_, err = LoadWordlist(filepath.Join(td, "missing.txt"))
+	assert.Error(t, err)
+}
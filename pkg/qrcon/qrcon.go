@@ -4,21 +4,38 @@ package qrcon
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image/color"
+	"os"
+	"strings"
 
 	"github.com/skip2/go-qrcode"
 )
 
+// MaxLength is the maximum number of bytes that fit into a QR code at
+// the recovery level used by this package (version 40, level M)
+const MaxLength = 2331
+
 const (
 	black = "\033[40m  \033[0m"
 	white = "\033[47m  \033[0m"
 )
 
+// ErrTooLong is returned if the content exceeds MaxLength
+var ErrTooLong = errors.New("content too long for a QR code")
+
+func newCode(content string) (*qrcode.QRCode, error) {
+	if len(content) > MaxLength {
+		return nil, fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrTooLong, len(content), MaxLength)
+	}
+	return qrcode.New(content, qrcode.Medium)
+}
+
 // QRCode returns a string containing an ANSI encoded
 // QR Code
 func QRCode(content string) (string, error) {
-	q, err := qrcode.New(content, qrcode.Medium)
+	q, err := newCode(content)
 	if err != nil {
 		return "", err
 	}
@@ -49,3 +66,78 @@ func sameColor(a color.Color, b color.Color) bool {
 	}
 	return false
 }
+
+// Terminal returns a QR code suitable for the current terminal. It uses
+// Unicode half blocks if the locale supports UTF-8 and falls back to ASCII
+// otherwise.
+func Terminal(content string) (string, error) {
+	if unicodeLocale() {
+		return Unicode(content)
+	}
+	return ASCII(content)
+}
+
+// Unicode returns a string containing a QR code drawn with Unicode half
+// blocks, two modules per character. Like qrencode -t UTF8 it draws the
+// light modules, so it needs a terminal with a light foreground on a dark
+// background.
+func Unicode(content string) (string, error) {
+	q, err := newCode(content)
+	if err != nil {
+		return "", err
+	}
+	bm := q.Bitmap()
+	var sb strings.Builder
+	for y := 0; y < len(bm); y += 2 {
+		for x := range bm[y] {
+			top := !bm[y][x]
+			bottom := y+1 < len(bm) && !bm[y+1][x]
+			switch {
+			case top && bottom:
+				_, _ = sb.WriteString("█")
+			case top:
+				_, _ = sb.WriteString("▀")
+			case bottom:
+				_, _ = sb.WriteString("▄")
+			default:
+				_ = sb.WriteByte(' ')
+			}
+		}
+		_ = sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// ASCII returns a string containing a QR code drawn with '#' for the
+// light modules, for terminals that can't display Unicode
+func ASCII(content string) (string, error) {
+	q, err := newCode(content)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, row := range q.Bitmap() {
+		for _, dark := range row {
+			if dark {
+				_, _ = sb.WriteString("  ")
+			} else {
+				_, _ = sb.WriteString("##")
+			}
+		}
+		_ = sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
+
+// unicodeLocale returns true if the locale of the terminal is UTF-8
+func unicodeLocale() bool {
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		sv := os.Getenv(env)
+		if sv == "" {
+			continue
+		}
+		sv = strings.ToLower(sv)
+		return strings.Contains(sv, "utf-8") || strings.Contains(sv, "utf8")
+	}
+	return false
+}
@@ -1,10 +1,14 @@
 package qrcon
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func ExampleQRCode() {
@@ -19,3 +23,47 @@ func TestQRCode(t *testing.T) {
 	_, err := QRCode("https://www.gopass.pw/")
 	assert.NoError(t, err)
 }
+
+func TestUnicode(t *testing.T) {
+	asc, err := ASCII("https://www.gopass.pw/")
+	require.NoError(t, err)
+	uni, err := Unicode("https://www.gopass.pw/")
+	require.NoError(t, err)
+
+	rows := strings.Split(strings.TrimSuffix(asc, "\n"), "\n")
+	ulines := strings.Split(strings.TrimSuffix(uni, "\n"), "\n")
+	// two modules per line
+	assert.Equal(t, (len(rows)+1)/2, len(ulines))
+	// the quiet zone is light
+	assert.Equal(t, strings.Repeat("#", len(rows[0])), rows[0])
+	assert.Equal(t, strings.Repeat("█", len(rows[0])/2), ulines[0])
+}
+
+func TestTerminal(t *testing.T) {
+	for _, env := range []string{"LC_ALL", "LC_CTYPE"} {
+		os.Unsetenv(env)
+	}
+	defer os.Unsetenv("LC_ALL")
+
+	assert.NoError(t, os.Setenv("LC_ALL", "en_US.UTF-8"))
+	qr, err := Terminal("foo")
+	require.NoError(t, err)
+	assert.Contains(t, qr, "█")
+
+	assert.NoError(t, os.Setenv("LC_ALL", "C"))
+	qr, err = Terminal("foo")
+	require.NoError(t, err)
+	assert.Contains(t, qr, "#")
+	assert.NotContains(t, qr, "█")
+}
+
+func TestTooLong(t *testing.T) {
+	_, err := Unicode(strings.Repeat("a", MaxLength))
+	assert.NoError(t, err)
+
+	for _, f := range []func(string) (string, error){QRCode, Unicode, ASCII} {
+		_, err := f(strings.Repeat("a", MaxLength+1))
+		assert.True(t, errors.Is(err, ErrTooLong))
+		assert.Contains(t, err.Error(), "2331")
+	}
+}
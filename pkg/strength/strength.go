@@ -0,0 +1,118 @@
+// Package strength estimates the strength of passwords using zxcvbn. The same
+// estimate is used to give feedback when creating or editing secrets and by
+// gopass audit, so that the thresholds are consistent everywhere.
+package strength
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nbutton23/zxcvbn-go"
+	"github.com/nbutton23/zxcvbn-go/match"
+)
+
+// MinScore is the minimum zxcvbn score (0-4) of a password that is not weak
+const MinScore = 3
+
+// Estimate is the estimated strength of a password
+type Estimate struct {
+	// Entropy in bits
+	Entropy float64
+	// Score from 0 (very weak) to 4 (very strong)
+	Score int
+	// CrackTime is a human readable estimate of the time to crack the password
+	CrackTime string
+	// Pattern describes the weakest pattern found in the password, if any,
+	// e.g. "contains a dictionary word"
+	Pattern string
+	// Token is the part of the password matching the pattern. It must only be
+	// shown to the user that entered the password, never be logged or stored.
+	Token string
+}
+
+// Check estimates the strength of the password. The user inputs (e.g. the
+// secret name) are treated like dictionary words.
+func Check(pw string, userInputs ...string) Estimate {
+	m := zxcvbn.PasswordStrength(pw, userInputs)
+	e := Estimate{
+		Entropy:   m.Entropy,
+		Score:     m.Score,
+		CrackTime: m.CrackTimeDisplay,
+	}
+	if w, found := weakest(m.MatchSequence); found {
+		e.Pattern = describe(w)
+		e.Token = w.Token
+	}
+	return e
+}
+
+// Validate returns an error if the password is weak. If minEntropy is set
+// the entropy is checked, otherwise the score. The error never contains
+// (parts of) the password.
+func (e Estimate) Validate(minEntropy float64) error {
+	if minEntropy > 0 {
+		if e.Entropy < minEntropy {
+			return fmt.Errorf("weak password (%.0f bits of entropy, want %.0f)", e.Entropy, minEntropy)
+		}
+		return nil
+	}
+	if e.Score < MinScore {
+		return fmt.Errorf("weak password (%d / 4)", e.Score)
+	}
+	return nil
+}
+
+// String returns a one-line assessment of the password including the
+// weakest pattern found. It contains the matching part of the password, so
+// it must only be shown on the terminal.
+func (e Estimate) String() string {
+	word := "strong"
+	if e.Score < MinScore {
+		word = "weak"
+	}
+	s := fmt.Sprintf("%s (%d / 4, ~%.1f bits of entropy, cracked in %s)", word, e.Score, e.Entropy, e.CrackTime)
+	if e.Pattern != "" && e.Score < 4 {
+		s += fmt.Sprintf(", %s %q", e.Pattern, e.Token)
+	}
+	return s
+}
+
+// weakest returns the match that adds the least entropy per character,
+// ignoring random (bruteforce) parts
+func weakest(ms []match.Match) (match.Match, bool) {
+	var w match.Match
+	found := false
+	for _, m := range ms {
+		if m.Pattern == "bruteforce" || m.Token == "" {
+			continue
+		}
+		if !found || m.Entropy/float64(len(m.Token)) < w.Entropy/float64(len(w.Token)) {
+			w = m
+			found = true
+		}
+	}
+	return w, found
+}
+
+func describe(m match.Match) string {
+	switch m.Pattern {
+	case "dictionary":
+		// leet speak matches have a suffix
+		switch strings.TrimSuffix(strings.ToLower(m.DictionaryName), "_3117") {
+		case "passwords":
+			return "contains common password"
+		case "user_inputs":
+			return "contains the name or data of the secret"
+		}
+		return "contains dictionary word"
+	case "spatial":
+		return "contains keyboard pattern"
+	case "repeat":
+		return "contains repeated characters"
+	case "sequence":
+		return "contains sequence"
+	case "date":
+		return "contains date"
+	}
+	return "contains pattern " + m.Pattern
+}
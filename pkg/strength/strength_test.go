@@ -0,0 +1,30 @@
+package strength
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	e := Check("winter2024")
+	assert.Less(t, e.Score, MinScore)
+	assert.Equal(t, "contains common password", e.Pattern)
+	assert.Equal(t, "winter", e.Token)
+	assert.Error(t, e.Validate(0))
+	assert.Contains(t, e.String(), `weak (0 / 4`)
+	assert.Contains(t, e.String(), `contains common password "winter"`)
+
+	e = Check("Eech4ahRoy2oowi0ohl")
+	assert.Equal(t, 4, e.Score)
+	assert.NoError(t, e.Validate(0))
+	assert.Error(t, e.Validate(100))
+	assert.NotContains(t, e.Validate(100).Error(), "Eech4ahRoy2oowi0ohl")
+	assert.Contains(t, e.String(), "strong (4 / 4")
+
+	e = Check("websites/example.com", "websites/example.com")
+	assert.Equal(t, "contains the name or data of the secret", e.Pattern)
+
+	e = Check("zxcvbnm,./")
+	assert.Equal(t, "contains keyboard pattern", e.Pattern)
+}
@@ -69,9 +69,14 @@ func (t *File) Close() error {
 	return t.fh.Close()
 }
 
-// Remove attempts to remove the tempfile
+// Remove overwrites the tempfile with zeros and attempts to remove it
 func (t *File) Remove(ctx context.Context) error {
 	_ = t.Close()
+	if err := t.wipe(); err != nil {
+		_ = t.unmount(ctx)
+		_ = os.RemoveAll(t.dir)
+		return fmt.Errorf("failed to overwrite %s: %w", t.Name(), err)
+	}
 	if err := t.unmount(ctx); err != nil {
 		return fmt.Errorf("failed to unmount %s from %s: %w", t.dev, t.dir, err)
 	}
@@ -80,3 +85,32 @@ func (t *File) Remove(ctx context.Context) error {
 	}
 	return os.RemoveAll(t.dir)
 }
+
+// wipe overwrites the content of the tempfile, in case the tempdir is not
+// on a ramdisk. Editors that replace the file on save leave nothing to wipe.
+func (t *File) wipe() error {
+	if t.fh == nil {
+		return nil
+	}
+	fh, err := os.OpenFile(t.fh.Name(), os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	fi, err := fh.Stat()
+	if err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if _, err := fh.Write(make([]byte, fi.Size())); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		_ = fh.Close()
+		return err
+	}
+	return fh.Close()
+}
@@ -109,3 +109,26 @@ func TestGlobalPrefix(t *testing.T) {
 	}()
 	assertPrefix(withGlobalPrefix, "global-prefix.some-prefix")
 }
+
+func TestWipe(t *testing.T) {
+	ctx := context.Background()
+
+	tf, err := New(ctx, "gp-test-")
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, tf.Remove(ctx))
+	}()
+
+	_, err = fmt.Fprintf(tf, "foobar")
+	require.NoError(t, err)
+	require.NoError(t, tf.Close())
+
+	require.NoError(t, tf.wipe())
+	buf, err := os.ReadFile(tf.Name())
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 6), buf)
+
+	// a removed file is not an error
+	require.NoError(t, os.Remove(tf.Name()))
+	assert.NoError(t, tf.wipe())
+}
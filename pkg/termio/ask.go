@@ -2,6 +2,7 @@ package termio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,12 @@ const (
 // AskForString asks for a string once, using the default if the
 // answer is empty. Errors are only returned on I/O errors
 func AskForString(ctx context.Context, text, def string) (string, error) {
+	return askForString(ctx, askString, text, def)
+}
+
+// askForString asks the askpass program, if any, and falls back to the
+// terminal. The kind is passed on to the askpass program.
+func askForString(ctx context.Context, kind, text, def string) (string, error) {
 	if ctxutil.IsAlwaysYes(ctx) || !ctxutil.IsInteractive(ctx) {
 		return def, nil
 	}
@@ -39,8 +46,14 @@ func AskForString(ctx context.Context, text, def string) (string, error) {
 	default:
 	}
 
-	fmt.Fprintf(Stderr, "%s [%s]: ", text, def)
-	input, err := NewReader(ctx, Stdin).ReadLine()
+	input, err := askpass(ctx, kind, fmt.Sprintf("%s [%s]", text, def))
+	if errors.Is(err, errNoAskpass) {
+		fmt.Fprintf(Stderr, "%s [%s]: ", text, def)
+		input, err = NewReader(ctx, Stdin).ReadLine()
+	}
+	if errors.Is(err, ErrAborted) {
+		return "", err
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to read user input: %w", err)
 	}
@@ -64,7 +77,10 @@ func AskForBool(ctx context.Context, text string, def bool) (bool, error) {
 		choices = "Y/n/q"
 	}
 
-	str, err := AskForString(ctx, text, choices)
+	str, err := askForString(ctx, askConfirm, text, choices)
+	if errors.Is(err, ErrAborted) {
+		return false, err
+	}
 	if err != nil {
 		return false, fmt.Errorf("failed to read user input: %w", err)
 	}
@@ -128,6 +144,43 @@ func AskForConfirmation(ctx context.Context, text string) bool {
 	return false
 }
 
+// Confirm asks to confirm a change that is skipped if the answer is no, e.g.
+// a fix by fsck. Like ConfirmDestructive it's confirmed without asking with
+// --force or --yes. Without a terminal it's skipped.
+func Confirm(ctx context.Context, text string) bool {
+	if ctxutil.IsConfirm(ctx) {
+		return true
+	}
+
+	return AskForConfirmation(ctx, text)
+}
+
+// NoInteractionError is returned if a destructive action needs to be
+// confirmed but there is nobody to ask
+type NoInteractionError struct {
+	Question string
+	Flag     string
+}
+
+func (e *NoInteractionError) Error() string {
+	return fmt.Sprintf("can not ask %q without a terminal. Pass %s to confirm", e.Question, e.Flag)
+}
+
+// ConfirmDestructive asks to confirm an action that can't be undone, e.g.
+// overwriting or removing a secret. It's confirmed without asking if --force
+// or --yes is given. Not doing it is no safe default either if we can't ask,
+// so an error naming the flag to pass is returned in that case.
+func ConfirmDestructive(ctx context.Context, text, flag string) (bool, error) {
+	if ctxutil.IsConfirm(ctx) || ctxutil.IsAlwaysYes(ctx) {
+		return true, nil
+	}
+	if !ctxutil.IsInteractive(ctx) {
+		return false, &NoInteractionError{Question: text, Flag: flag}
+	}
+
+	return AskForConfirmation(ctx, text), nil
+}
+
 // AskForKeyImport asks for permissions to import the named key
 func AskForKeyImport(ctx context.Context, key string, names []string) bool {
 	if ctxutil.IsAlwaysYes(ctx) {
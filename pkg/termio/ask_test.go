@@ -3,6 +3,7 @@ package termio
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAskForString(t *testing.T) {
@@ -174,6 +176,56 @@ n
 	assert.False(t, AskForConfirmation(ctx, "test"))
 }
 
+func TestConfirm(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+		Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+	assert.True(t, Confirm(ctxutil.WithConfirm(ctx, true), "fix?"))
+	assert.True(t, Confirm(ctxutil.WithAlwaysYes(ctx, true), "fix?"))
+	assert.False(t, Confirm(ctxutil.WithInteractive(ctx, false), "fix?"))
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+		Stderr = os.Stderr
+	}()
+
+	ctx := context.Background()
+
+	ok, err := ConfirmDestructive(ctxutil.WithConfirm(ctx, true), "overwrite?", "--force")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ConfirmDestructive(ctxutil.WithAlwaysYes(ctx, true), "overwrite?", "--force")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ConfirmDestructive(ctxutil.WithInteractive(ctx, false), "overwrite?", "--force")
+	assert.False(t, ok)
+	var nie *NoInteractionError
+	require.True(t, errors.As(err, &nie))
+	assert.Equal(t, "--force", nie.Flag)
+	assert.Contains(t, err.Error(), "Pass --force to confirm")
+
+	Stdin = strings.NewReader("y\nn\n")
+	ok, err = ConfirmDestructive(ctx, "overwrite?", "--force")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = ConfirmDestructive(ctx, "overwrite?", "--force")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
 func TestAskForKeyImport(t *testing.T) {
 	buf := &bytes.Buffer{}
 	out.Stderr = buf
@@ -0,0 +1,81 @@
+package termio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/debug"
+)
+
+const (
+	// AskpassEnv names an external program that answers all questions
+	// instead of the terminal, like SSH_ASKPASS
+	AskpassEnv = "GOPASS_ASKPASS"
+	// AskpassTypeEnv tells the askpass program what kind of question it is
+	// asked: string, confirm or password
+	AskpassTypeEnv = "GOPASS_ASKPASS_TYPE"
+)
+
+const (
+	askString   = "string"
+	askConfirm  = "confirm"
+	askPassword = "password"
+)
+
+// errNoAskpass is returned if the question must be asked on the terminal
+var errNoAskpass = fmt.Errorf("no askpass program")
+
+// HasAskpass returns true if an askpass program is configured
+func HasAskpass() bool {
+	return os.Getenv(AskpassEnv) != ""
+}
+
+// askpass runs the askpass program with the prompt as its only argument and
+// returns the first line it prints to stdout. A non-zero exit status means
+// the user cancelled. errNoAskpass is returned if there is no askpass
+// program or it could not be started.
+func askpass(ctx context.Context, kind, prompt string) (string, error) {
+	helper := os.Getenv(AskpassEnv)
+	if helper == "" {
+		return "", errNoAskpass
+	}
+
+	buf := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, helper, prompt)
+	cmd.Env = append(os.Environ(), AskpassTypeEnv+"="+kind)
+	cmd.Stdout = buf
+	cmd.Stderr = os.Stderr
+
+	debug.Log("Asking %q with %s", prompt, helper)
+	if err := cmd.Run(); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			debug.Log("%s exited with %d", helper, ee.ExitCode())
+			return "", ErrAborted
+		}
+		out.Warningf(ctx, "Failed to run %s %s: %s", AskpassEnv, helper, err)
+		return "", fmt.Errorf("%w: %s", errNoAskpass, err)
+	}
+
+	answer := buf.String()
+	if i := strings.IndexAny(answer, "\r\n"); i >= 0 {
+		answer = answer[:i]
+	}
+	return answer, nil
+}
+
+// askPass asks for a password with the askpass program if there is one and
+// on the terminal otherwise
+func askPass(ctx context.Context, prompt string) (string, error) {
+	pw, err := askpass(ctx, askPassword, prompt)
+	if !errors.Is(err, errNoAskpass) {
+		return pw, err
+	}
+	return promptPass(ctx, prompt)
+}
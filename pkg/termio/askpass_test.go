@@ -0,0 +1,82 @@
+//go:build !windows
+// +build !windows
+
+package termio
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gopasspw/gopass/internal/out"
+	"github.com/gopasspw/gopass/pkg/ctxutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAskpass(t *testing.T, script string) string {
+	t.Helper()
+
+	fn := filepath.Join(t.TempDir(), "askpass")
+	require.NoError(t, os.WriteFile(fn, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return fn
+}
+
+func TestAskpass(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out.Stderr = buf
+	Stderr = buf
+	defer func() {
+		out.Stderr = os.Stderr
+		Stderr = os.Stderr
+		Stdin = os.Stdin
+	}()
+
+	ctx := context.Background()
+	ctx = ctxutil.WithTerminal(ctx, false)
+
+	// answers with its type and prompt
+	t.Setenv(AskpassEnv, writeAskpass(t, `echo "$GOPASS_ASKPASS_TYPE:$1"; echo "second line"`))
+	assert.True(t, HasAskpass())
+
+	sv, err := AskForString(ctx, "name", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "string:name [foo]", sv)
+
+	sv, err = GetPassPromptFunc(ctx)(ctx, "Enter password")
+	require.NoError(t, err)
+	assert.Equal(t, "password:Enter password", sv)
+
+	t.Setenv(AskpassEnv, writeAskpass(t, `test "$GOPASS_ASKPASS_TYPE" = "confirm" && echo y`))
+	assert.True(t, AskForConfirmation(ctx, "overwrite?"))
+	bv, err := AskForBool(ctx, "overwrite?", false)
+	require.NoError(t, err)
+	assert.True(t, bv)
+
+	// an empty answer selects the default
+	t.Setenv(AskpassEnv, writeAskpass(t, `echo`))
+	sv, err = AskForString(ctx, "name", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", sv)
+
+	// a non-zero exit status cancels
+	t.Setenv(AskpassEnv, writeAskpass(t, `echo y; exit 1`))
+	_, err = AskForString(ctx, "name", "foo")
+	assert.ErrorIs(t, err, ErrAborted)
+	assert.False(t, AskForConfirmation(ctx, "overwrite?"))
+	_, err = AskForPassword(ctx, "password", true)
+	assert.ErrorIs(t, err, ErrAborted)
+	assert.Empty(t, buf.String())
+
+	// falls back to the terminal if the askpass program can't be run
+	t.Setenv(AskpassEnv, filepath.Join(t.TempDir(), "missing"))
+	Stdin = strings.NewReader("bar\n")
+	sv, err = AskForString(ctx, "name", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "bar", sv)
+	assert.Contains(t, buf.String(), "Failed to run "+AskpassEnv)
+}
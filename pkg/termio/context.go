@@ -6,6 +6,7 @@ type contextKey int
 
 const (
 	ctxKeyPassPromptFunc contextKey = iota
+	ctxKeyPassEcho
 )
 
 // PassPromptFunc is a password prompt function
@@ -28,7 +29,23 @@ func HasPassPromptFunc(ctx context.Context) bool {
 func GetPassPromptFunc(ctx context.Context) PassPromptFunc {
 	ppf, ok := ctx.Value(ctxKeyPassPromptFunc).(PassPromptFunc)
 	if !ok || ppf == nil {
-		return promptPass
+		return askPass
 	}
 	return ppf
 }
+
+// WithPassEcho returns a context with the flag to show passwords while typing
+// set
+func WithPassEcho(ctx context.Context, echo bool) context.Context {
+	return context.WithValue(ctx, ctxKeyPassEcho, echo)
+}
+
+// IsPassEcho returns true if passwords should be shown while typing. They can
+// still be toggled with Tab.
+func IsPassEcho(ctx context.Context) bool {
+	bv, ok := ctx.Value(ctxKeyPassEcho).(bool)
+	if !ok {
+		return false
+	}
+	return bv
+}
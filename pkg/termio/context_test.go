@@ -22,3 +22,11 @@ func TestPassPromptFunc(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test", sv)
 }
+
+func TestPassEcho(t *testing.T) {
+	ctx := context.Background()
+
+	assert.False(t, IsPassEcho(ctx))
+	assert.True(t, IsPassEcho(WithPassEcho(ctx, true)))
+	assert.False(t, IsPassEcho(WithPassEcho(ctx, false)))
+}
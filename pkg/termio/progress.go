@@ -28,6 +28,7 @@ type ProgressBar struct {
 	// and https://github.com/golang/go/issues/36606
 	total   int64
 	current int64
+	failed  int64
 
 	mutex   chan struct{}
 	lastUpd time.Time
@@ -63,6 +64,18 @@ func (p *ProgressBar) Inc() {
 	p.print()
 }
 
+// Fail adds one failed item to the progress. The number of failed items is
+// shown next to the progress.
+func (p *ProgressBar) Fail() {
+	atomic.AddInt64(&p.failed, 1)
+	p.Inc()
+}
+
+// Failed returns the number of failed items
+func (p *ProgressBar) Failed() int64 {
+	return atomic.LoadInt64(&p.failed)
+}
+
 // Set sets an arbitrary progress
 func (p *ProgressBar) Set(v int64) {
 	atomic.StoreInt64(&p.current, v)
@@ -135,6 +148,9 @@ func (p *ProgressBar) doPrint() {
 		digits = 1
 	}
 	text := fmt.Sprintf(fmt.Sprintf(" %%%dd / %%%dd ", digits, digits), cur, max)
+	if failed := atomic.LoadInt64(&p.failed); failed > 0 {
+		text = fmt.Sprintf(fmt.Sprintf(" %%%dd / %%%dd (%%d failed) ", digits, digits), cur, max, failed)
+	}
 	if p.Bytes {
 		curStr := humanize.Bytes(uint64(cur))
 		maxStr := humanize.Bytes(uint64(max))
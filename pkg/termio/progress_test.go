@@ -39,3 +39,13 @@ func TestProgressBytes(t *testing.T) {
 	assert.Equal(t, int64(max), pb.current)
 	pb.Done()
 }
+
+func TestProgressFail(t *testing.T) {
+	max := 3
+	pb := NewProgressBar(int64(max))
+	pb.Hidden = true
+	pb.Inc()
+	pb.Fail()
+	assert.Equal(t, int64(2), pb.current)
+	assert.Equal(t, int64(1), pb.Failed())
+}
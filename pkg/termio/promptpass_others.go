@@ -15,7 +15,11 @@ import (
 	"github.com/gopasspw/gopass/pkg/ctxutil"
 )
 
-// promptPass will prompt user's for a password by terminal.
+// ttyReader is kept between prompts, a pasted line break may span two of them
+var ttyReader = &pwReader{}
+
+// promptPass will prompt user's for a password by terminal. The input is
+// hidden unless echo is requested, Tab toggles it.
 func promptPass(ctx context.Context, prompt string) (string, error) {
 	if !ctxutil.IsTerminal(ctx) {
 		return AskForString(ctx, prompt, "")
@@ -44,8 +48,11 @@ func promptPass(ctx context.Context, prompt string) (string, error) {
 		os.Exit(1)
 	}()
 
-	fmt.Fprintf(Stderr, "%s: ", prompt)
-	passBytes, err := term.ReadPassword(fd)
-	fmt.Fprintln(Stderr, "")
-	return string(passBytes), err
+	if _, err := term.MakeRaw(fd); err != nil {
+		return "", fmt.Errorf("could not switch terminal to raw mode: %w", err)
+	}
+	ttyReader.in = os.Stdin
+	ttyReader.out = Stderr
+	ttyReader.echo = IsPassEcho(ctx)
+	return ttyReader.readLine(prompt)
 }
@@ -12,13 +12,17 @@ import (
 	"golang.org/x/crypto/ssh/terminal"
 )
 
-// promptPass will prompt user's for a password by terminal.
+// promptPass will prompt user's for a password by terminal. Toggling the
+// echo with Tab is not supported on Windows.
 func promptPass(ctx context.Context, prompt string) (string, error) {
 	if !ctxutil.IsTerminal(ctx) {
 		return AskForString(ctx, prompt, "")
 	}
 
 	fmt.Fprintf(Stderr, "%s: ", prompt)
+	if IsPassEcho(ctx) {
+		return NewReader(ctx, Stdin).ReadLine()
+	}
 	passBytes, err := terminal.ReadPassword(int(os.Stdin.Fd()))
 	fmt.Fprintln(Stderr, "")
 	return string(passBytes), err
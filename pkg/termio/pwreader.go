@@ -0,0 +1,118 @@
+package termio
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x08
+	keyTab       = 0x09
+	keyLF        = 0x0a
+	keyCR        = 0x0d
+	keyCtrlU     = 0x15
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// pwReader reads a password from a terminal in raw mode. It supports the
+// usual line editing keys and toggles between hidden and visible input on
+// Tab.
+type pwReader struct {
+	in   io.Reader
+	out  io.Writer
+	echo bool
+	// lastCR is set if the last line ended with a CR, so the LF of a pasted
+	// CRLF doesn't end the next line
+	lastCR bool
+}
+
+// readLine reads one line, without the line break. Ctrl+C and Ctrl+D abort.
+func (r *pwReader) readLine(prompt string) (string, error) {
+	var buf []byte
+	redraw := func() {
+		fmt.Fprintf(r.out, "\r\x1b[K%s: ", prompt)
+		if r.echo {
+			_, _ = r.out.Write(buf)
+		}
+	}
+	fmt.Fprintf(r.out, "%s: ", prompt)
+
+	b := make([]byte, 1)
+	for {
+		if _, err := io.ReadFull(r.in, b); err != nil {
+			if err == io.EOF && len(buf) > 0 {
+				return string(buf), nil
+			}
+			return "", err
+		}
+
+		switch b[0] {
+		case keyCR, keyLF:
+			if b[0] == keyLF && r.lastCR && len(buf) == 0 {
+				r.lastCR = false
+				continue
+			}
+			r.lastCR = b[0] == keyCR
+			fmt.Fprint(r.out, "\r\n")
+			return string(buf), nil
+		case keyCtrlC, keyCtrlD:
+			fmt.Fprint(r.out, "\r\n")
+			return "", ErrAborted
+		case keyBackspace, keyDelete:
+			if len(buf) > 0 {
+				_, n := utf8.DecodeLastRune(buf)
+				buf = buf[:len(buf)-n]
+				if r.echo {
+					redraw()
+				}
+			}
+		case keyCtrlU:
+			buf = buf[:0]
+			if r.echo {
+				redraw()
+			}
+		case keyTab:
+			r.echo = !r.echo
+			redraw()
+		case keyEscape:
+			// ignore escape sequences, e.g. of the arrow keys or the
+			// markers of a bracketed paste
+			if err := r.skipEscape(); err != nil {
+				return "", err
+			}
+		default:
+			if b[0] < 0x20 {
+				continue
+			}
+			buf = append(buf, b[0])
+			r.lastCR = false
+			if r.echo {
+				_, _ = r.out.Write(b)
+			}
+		}
+	}
+}
+
+// skipEscape reads the rest of an escape sequence
+func (r *pwReader) skipEscape() error {
+	b := make([]byte, 1)
+	if _, err := io.ReadFull(r.in, b); err != nil {
+		return err
+	}
+	if b[0] != '[' && b[0] != 'O' {
+		return nil
+	}
+	for {
+		if _, err := io.ReadFull(r.in, b); err != nil {
+			return err
+		}
+		// the final byte of a control sequence
+		if b[0] >= 0x40 && b[0] <= 0x7e {
+			return nil
+		}
+	}
+}
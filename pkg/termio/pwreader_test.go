@@ -0,0 +1,80 @@
+package termio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPwReader(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "enter", in: "foo\r", want: []string{"foo"}},
+		{name: "lf", in: "foo\n", want: []string{"foo"}},
+		{name: "backspace", in: "fooo\x7f\r", want: []string{"foo"}},
+		{name: "backspace utf8", in: "fooä\x08\r", want: []string{"foo"}},
+		{name: "backspace empty", in: "\x7ffoo\r", want: []string{"foo"}},
+		{name: "ctrl-u", in: "bar\x15foo\r", want: []string{"foo"}},
+		{name: "tab", in: "f\too\r", want: []string{"foo"}},
+		{name: "arrow keys", in: "fo\x1b[Do\r", want: []string{"foo"}},
+		{name: "bracketed paste", in: "\x1b[200~foo\x1b[201~\r", want: []string{"foo"}},
+		{name: "pasted crlf", in: "foo\r\nfoo\r\n", want: []string{"foo", "foo"}},
+		{name: "empty lines", in: "\r\rfoo\r", want: []string{"", "", "foo"}},
+		{name: "trailing space", in: "foo \r", want: []string{"foo "}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &pwReader{in: strings.NewReader(tc.in), out: io.Discard}
+			for _, want := range tc.want {
+				got, err := r.readLine("Enter password")
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+
+	t.Run("abort", func(t *testing.T) {
+		for _, in := range []string{"foo\x03bar\r", "\x04"} {
+			r := &pwReader{in: strings.NewReader(in), out: io.Discard}
+			_, err := r.readLine("Enter password")
+			assert.Equal(t, ErrAborted, err)
+		}
+	})
+
+	t.Run("eof", func(t *testing.T) {
+		r := &pwReader{in: strings.NewReader("foo"), out: io.Discard}
+		got, err := r.readLine("Enter password")
+		require.NoError(t, err)
+		assert.Equal(t, "foo", got)
+
+		_, err = r.readLine("Enter password")
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("echo", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		r := &pwReader{in: strings.NewReader("foo\r"), out: buf}
+		_, err := r.readLine("Enter password")
+		require.NoError(t, err)
+		assert.NotContains(t, buf.String(), "foo")
+
+		buf.Reset()
+		r = &pwReader{in: strings.NewReader("foo\r"), out: buf, echo: true}
+		_, err = r.readLine("Enter password")
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "foo")
+
+		// Tab reveals what has been typed so far
+		buf.Reset()
+		r = &pwReader{in: strings.NewReader("foo\tbar\r"), out: buf}
+		_, err = r.readLine("Enter password")
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "\r\x1b[KEnter password: foobar")
+	})
+}
@@ -0,0 +1,15 @@
+package termio
+
+import "os"
+
+// RecordedBy returns the first of the given environment variables that is
+// set to a non-empty value, e.g. ASCIINEMA_REC while asciinema records the
+// terminal. It returns an empty string if none is set.
+func RecordedBy(vars []string) string {
+	for _, v := range vars {
+		if os.Getenv(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+package termio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordedBy(t *testing.T) {
+	t.Setenv("GOPASS_TEST_RECORDING", "")
+	t.Setenv("GOPASS_TEST_SCRIPT", "1")
+
+	assert.Equal(t, "", RecordedBy(nil))
+	assert.Equal(t, "", RecordedBy([]string{"GOPASS_TEST_RECORDING"}))
+	assert.Equal(t, "GOPASS_TEST_SCRIPT", RecordedBy([]string{"GOPASS_TEST_RECORDING", "GOPASS_TEST_SCRIPT"}))
+}
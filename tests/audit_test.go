@@ -1,9 +1,13 @@
 package tests
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAudit(t *testing.T) {
@@ -21,4 +25,37 @@ func TestAudit(t *testing.T) {
 		assert.Contains(t, out, "weak password")
 		assert.Contains(t, out, "\t- fixed/secret")
 	})
+
+	t.Run("audit with excludes", func(t *testing.T) {
+		// only the generated password baz remains
+		out, err := ts.run("audit --exclude fixed/* --exclude foo/*")
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "fixed/secret")
+		assert.NotContains(t, out, "foo/")
+	})
+
+	t.Run("audit with json output", func(t *testing.T) {
+		out, err := ts.run("audit --format json --fail-on high")
+		assert.Error(t, err)
+		assert.True(t, strings.HasPrefix(out, "{\n  \"findings\": ["), out)
+		assert.Contains(t, out, `"type": "weak"`)
+	})
+
+	t.Run("audit hibp with dumps", func(t *testing.T) {
+		_, err := ts.run("audit hibp")
+		assert.Error(t, err)
+
+		// SHA-1 of "moar", the password of fixed/secret
+		dump := filepath.Join(ts.tempDir, "dump.txt")
+		require.NoError(t, os.WriteFile(dump, []byte("1A699A355FB2B46F7F104EB5F0BDCAAE5EF23E08:42\n"), 0644))
+
+		out, err := ts.run("audit hibp --dumps " + dump)
+		assert.Error(t, err)
+		assert.Contains(t, out, "\t- fixed/secret (seen 42 times)")
+		assert.NotContains(t, out, "foo/bar")
+
+		out, err = ts.run("audit hibp --dumps " + dump + " foo")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "No breached passwords found")
+	})
 }
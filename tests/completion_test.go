@@ -23,21 +23,21 @@ func TestCompletion(t *testing.T) {
 	})
 
 	t.Run("bash completion", func(t *testing.T) {
-		bash := `_gopass_bash_autocomplete() {
-     local cur opts base
-     COMPREPLY=()
-     cur="${COMP_WORDS[COMP_CWORD]}"
-     opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
-     local IFS=$'\n'
-     COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
-     return 0
- }
+		out, err := ts.run("completion bash")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "complete -F _gopass_complete gopass")
+		assert.Contains(t, out, `gopass ls --flat --prefix "$1"`)
+		assert.Contains(t, out, "gopass completion mounts")
+	})
 
-complete -F _gopass_bash_autocomplete gopass`
+	t.Run("complete mounts and keys", func(t *testing.T) {
+		_, err := ts.run("completion mounts")
+		assert.NoError(t, err)
 
-		out, err := ts.run("completion bash")
+		// keys are only completed with completion.decrypt
+		out, err := ts.run("completion keys foo")
 		assert.NoError(t, err)
-		assert.Equal(t, bash, out)
+		assert.Equal(t, "", out)
 	})
 
 	t.Run("zsh completion", func(t *testing.T) {
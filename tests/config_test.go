@@ -15,21 +15,54 @@ func TestBaseConfig(t *testing.T) {
 
 	out, err := ts.run("config")
 	assert.NoError(t, err)
-	wanted := `autoclip: false
+	wanted := `ageagent: false
+autoclip: false
 autoimport: true
+autooffline: false
+autopush: true
+autosync: true
+autosyncinterval: 0
+autotype: false
+binarylimit: 1048576
+checkrecipienthash: true
+clipboard: 
 cliptimeout: 45
+cmd: 
+commitmsghashnames: false
+commitmsgtemplate: 
+decrypt: false
+decryptcache: 100
+exectimeout: 60
+expirywarn: 30
 exportkeys: false
+formatpasswords: false
+gitcredentialprefix: 
+hooks: false
+keepbackup: false
+keycache: true
+keyserver: 
+lockafter: 900
+locktimeout: 10
+metadata: false
+mode: 
+noambiguous: false
+nodigits: false
 nopager: false
 notifications: true
+nouppercase: false
+oldpasswords: 3
+ownertrust: false
 parsing: true
 `
 	wanted += "path: " + ts.storeDir("root") + "\n"
-	wanted += "safecontent: false"
+	wanted += "pullstrategy: merge\nqrtimeout: 45\nrecordingcheck: true\nrecordingvars: \nsafecontent: false\nshowautoclip: false\nsigncommits: false\nsymbols: \nunsafekeys: \nupdatestartuptty: true\nwordlistfile: \nworkers: 0"
 
 	assert.Equal(t, wanted, out)
 
 	invertables := []string{
 		"autoimport",
+		"autopush",
+		"checkrecipienthash",
 		"safecontent",
 		"parsing",
 	}
@@ -68,16 +101,58 @@ func TestMountConfig(t *testing.T) {
 	_, err = ts.run("config")
 	assert.NoError(t, err)
 
-	wanted := `autoclip: false
+	wanted := `ageagent: false
+autoclip: false
 autoimport: true
+autooffline: false
+autopush: true
+autosync: true
+autosyncinterval: 0
+autotype: false
+binarylimit: 1048576
+checkrecipienthash: true
+clipboard: 
 cliptimeout: 45
+cmd: 
+commitmsghashnames: false
+commitmsgtemplate: 
+decrypt: false
+decryptcache: 100
+exectimeout: 60
+expirywarn: 30
 exportkeys: false
+formatpasswords: false
+gitcredentialprefix: 
+hooks: false
+keepbackup: false
+keycache: true
+keyserver: 
+lockafter: 900
+locktimeout: 10
+metadata: false
+mode: 
+noambiguous: false
+nodigits: false
 nopager: false
 notifications: true
+nouppercase: false
+oldpasswords: 3
+ownertrust: false
 parsing: true
 path: `
 	wanted += ts.storeDir("root") + "\n"
-	wanted += `safecontent: false
+	wanted += `pullstrategy: merge
+qrtimeout: 45
+recordingcheck: true
+recordingvars: 
+safecontent: false
+showautoclip: false
+signcommits: false
+symbols: 
+unsafekeys: 
+updatestartuptty: true
+wordlistfile: 
+workers: 0
 mount "mnt/m1" => "`
 	wanted += ts.storeDir("m1") + "\"\n"
 
@@ -44,6 +44,17 @@ func TestCopy(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("recursive copy with -r", func(t *testing.T) {
+		_, err := ts.run("copy -r fixed/ qux/")
+		require.NoError(t, err)
+
+		out, err := ts.run("show -f --recursive --format json qux/")
+		require.NoError(t, err)
+		assert.Contains(t, out, `"qux/secret": {`)
+		assert.Contains(t, out, `"password": "moar"`)
+		assert.Contains(t, out, `"qux/twoliner": {`)
+	})
+
 	t.Run("copy existing secret to non-existing destination", func(t *testing.T) {
 		out, err := ts.run("copy foo/bar foo/baz")
 		require.NoError(t, err)
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCodes(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	ts.initStore()
+	ts.initSecrets("")
+
+	exitCode := func(t *testing.T, err error) int {
+		t.Helper()
+
+		var ee *exec.ExitError
+		require.True(t, errors.As(err, &ee), "%s", err)
+		return ee.ExitCode()
+	}
+
+	t.Run("missing secret", func(t *testing.T) {
+		out, err := ts.run("show doesnotexist")
+		require.Error(t, err)
+		assert.Equal(t, 10, exitCode(t, err), out)
+	})
+
+	t.Run("unreadable secret", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(ts.storeDir("root"), "broken.gpg"), []byte("not encrypted"), 0600))
+
+		out, err := ts.run("show broken")
+		require.Error(t, err)
+		assert.Equal(t, 11, exitCode(t, err), out)
+	})
+
+	t.Run("cancelled prompt", func(t *testing.T) {
+		// there is nobody to confirm the removal
+		out, err := ts.run("rm foo/bar")
+		require.Error(t, err)
+		assert.Equal(t, 3, exitCode(t, err), out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cmd := exec.Command(ts.Binary, "--format", "json", "cat", "doesnotexist")
+		cmd.Dir = ts.workDir()
+		stderr, err := cmd.Output()
+		require.Error(t, err)
+		assert.Equal(t, 10, exitCode(t, err))
+		var ee *exec.ExitError
+		require.True(t, errors.As(err, &ee))
+		stderr = ee.Stderr
+
+		var res struct {
+			Error struct {
+				Class   string `json:"class"`
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(stderr, &res), string(stderr))
+		assert.Equal(t, "not_found", res.Error.Class)
+		assert.Equal(t, 10, res.Error.Code)
+		assert.Contains(t, res.Error.Message, "doesnotexist")
+	})
+}
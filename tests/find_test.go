@@ -38,7 +38,7 @@ func TestFind(t *testing.T) {
 
 	out, err = ts.run("find b")
 	assert.NoError(t, err)
-	assert.Contains(t, "Found exact match in 'foo/bar'\nbaz", out)
+	assert.Contains(t, "Found one match in 'foo/bar'\nbaz", out)
 
 	_, err = ts.run("config safecontent true")
 	require.NoError(t, err)
@@ -30,6 +30,18 @@ func TestGenerate(t *testing.T) {
 	assert.Contains(t, out, "The generated password is:")
 	assert.Len(t, lines[3], 42)
 
+	out, err = ts.run("generate -p --symbols=#% --no-digits --no-uppercase sym 16")
+	assert.NoError(t, err)
+	lines = strings.Split(out, "\n")
+	require.Greater(t, len(lines), 2)
+	assert.Regexp(t, `^[a-z#%]{16}$`, lines[3])
+
+	out, err = ts.run("generate -p --symbols sym2 16")
+	assert.NoError(t, err)
+	lines = strings.Split(out, "\n")
+	require.Greater(t, len(lines), 2)
+	assert.Len(t, lines[3], 16)
+
 	_ = os.Setenv("GOPASS_CHARACTER_SET", "a")
 	out, err = ts.run("generate -p zab 4")
 	assert.NoError(t, err)
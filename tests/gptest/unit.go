@@ -64,6 +64,7 @@ func NewUnitTester(t *testing.T) *Unit {
 		"GOPASS_HOMEDIR":            u.Dir,
 		"NO_COLOR":                  "true",
 		"GOPASS_NO_NOTIFY":          "true",
+		"GOPASS_SYSTEM_CONFIG":      filepath.Join(u.Dir, "system-config.yml"),
 		"PAGER":                     "",
 	}
 	assert.NoError(t, setupEnv(u.env))
@@ -90,12 +90,19 @@ func flagset(t *testing.T, flags map[string]string, args []string) *flag.FlagSet
 func UnsetVars(ls ...string) func() {
 	old := make(map[string]string, len(ls))
 	for _, k := range ls {
-		old[k] = os.Getenv(k)
+		if v, found := os.LookupEnv(k); found {
+			old[k] = v
+		}
 		os.Unsetenv(k)
 	}
 	return func() {
-		for k, v := range old {
-			os.Setenv(k, v)
+		for _, k := range ls {
+			if v, found := old[k]; found {
+				os.Setenv(k, v)
+				continue
+			}
+			// an empty GIT_AUTHOR_NAME is not the same as none
+			os.Unsetenv(k)
 		}
 	}
 }
@@ -25,5 +25,15 @@ func TestGrep(t *testing.T) {
 
 	out, err = ts.run("grep moar")
 	assert.NoError(t, err)
-	assert.Contains(t, out, "fixed/secret matches")
+	assert.Contains(t, out, "fixed/secret:password: *****")
+	assert.NotContains(t, out, "moar")
+
+	out, err = ts.run("grep -u moar")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "fixed/secret:password: moar")
+
+	out, err = ts.run("grep -i STUFF")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "fixed/twoliner:body: more stuff")
+	assert.Contains(t, out, "1 matches, 0 errors")
 }
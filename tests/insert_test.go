@@ -2,6 +2,7 @@ package tests
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +21,7 @@ func TestInsert(t *testing.T) {
 	_, err = ts.runCmd([]string{ts.Binary, "insert", "some/secret"}, []byte("moar"))
 	assert.NoError(t, err)
 
-	_, err = ts.runCmd([]string{ts.Binary, "insert", "some/newsecret"}, []byte("and\nmoar"))
+	_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/newsecret"}, []byte("and\nmoar"))
 	assert.NoError(t, err)
 
 	t.Run("Regression test for #1573 without actual pipes", func(t *testing.T) {
@@ -123,7 +124,7 @@ glossary": {
         }
     }
 }`
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/json"}, []byte(json))
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/json"}, []byte(json))
 		assert.NoError(t, err)
 
 		// using show -n to disable parsing
@@ -138,7 +139,7 @@ test2
 {
   "Creator": "the creator"
 }`
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/multilinewithbraces"}, []byte(input))
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/multilinewithbraces"}, []byte(input))
 		assert.NoError(t, err)
 
 		// using show -n to disable parsing
@@ -153,7 +154,7 @@ user: a user
 web: test.com
 user: second user`
 
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/multikey"}, []byte(input))
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/multikey"}, []byte(input))
 		assert.NoError(t, err)
 
 		// using show -n to disable parsing
@@ -175,7 +176,7 @@ web: test.com
 user: a user
 user: second user`
 
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/multikeyvalues"}, []byte(input))
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/multikeyvalues"}, []byte(input))
 		assert.NoError(t, err)
 
 		// using show -n to disable parsing
@@ -189,17 +190,18 @@ user: second user`
 ---
 user: 0123`
 
-		output := `yamltest
----
-user: 83`
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/yamloctal"}, []byte(input))
+		assert.NoError(t, err)
 
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/yamloctal"}, []byte(input))
+		// unmodified YAML is shown as is
+		out, err = ts.run("show -f some/yamloctal")
 		assert.NoError(t, err)
+		assert.Equal(t, input, out)
 
 		// with parsing we have 0123 interpreted as octal for 83
-		out, err = ts.run("show -f some/yamloctal")
+		out, err = ts.run("show -f some/yamloctal user")
 		assert.NoError(t, err)
-		assert.Equal(t, output, out)
+		assert.Equal(t, "83", out)
 
 		// using show -n to disable parsing
 		out, err = ts.run("show -f -n some/yamloctal")
@@ -213,18 +215,34 @@ user: 83`
 Test / test.com
 user:myuser
 url: test.com/`
-		output := `somepasswd
-url: test.com/
-user: myuser
----
-Test / test.com`
 
-		_, err = ts.runCmd([]string{ts.Binary, "insert", "some/kvwithspace"}, []byte(input))
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "some/kvwithspace"}, []byte(input))
 		assert.NoError(t, err)
 
 		out, err = ts.run("show -f some/kvwithspace")
 		assert.NoError(t, err)
-		assert.Equal(t, output, out)
+		assert.Equal(t, input, out)
+
+		out, err = ts.run("show -f some/kvwithspace user")
+		assert.NoError(t, err)
+		assert.Equal(t, "myuser", out)
 	})
 
+	t.Run("multiline with windows line endings", func(t *testing.T) {
+		input := "\ufeffs3cret\r\napiVersion: v1\r\nkind: Config\r\n"
+		_, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "infra/kubeconfig"}, []byte(input))
+		assert.NoError(t, err)
+
+		out, err = ts.run("show -o infra/kubeconfig")
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cret", out)
+
+		out, err = ts.run("show -f -n infra/kubeconfig")
+		assert.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(input), out)
+
+		out, err = ts.runCmd([]string{ts.Binary, "insert", "-m", "--key", "kind", "infra/kubeconfig"}, []byte("Pod"))
+		assert.Error(t, err)
+		assert.Contains(t, out, "--multiline")
+	})
 }
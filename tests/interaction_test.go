@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	shellquote "github.com/kballard/go-shellquote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runNoInput runs gopass without a terminal and with nothing on stdin. It
+// fails the test if the command blocks waiting for an answer.
+func (ts tester) runNoInput(arg string, env ...string) (string, error) {
+	args, err := shellquote.Split(arg)
+	require.NoError(ts.t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, ts.Binary, args...)
+	cmd.Dir = ts.workDir()
+	cmd.Env = append(os.Environ(), env...)
+
+	ts.t.Logf("%+v", cmd.Args)
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(ts.t, ctx.Err(), "%s blocked without input:\n%s", arg, out)
+	return strings.TrimSpace(string(out)), err
+}
+
+func TestNoInteraction(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	ts.initStore()
+	ts.initSecrets("")
+
+	// questions with a safe default don't block
+	_, err := ts.runNoInput("recipients")
+	assert.NoError(t, err)
+	_, err = ts.runNoInput("fsck")
+	assert.NoError(t, err)
+
+	// destructive actions fail with the flag to confirm them
+	for _, arg := range []string{
+		"generate foo/bar 12",
+		"copy fixed/secret foo/bar",
+		"move fixed/secret foo/bar",
+		"delete foo/bar",
+		"delete -r fixed",
+	} {
+		out, err := ts.runNoInput(arg)
+		assert.Error(t, err, arg)
+		assert.Contains(t, out, "without a terminal", arg)
+		assert.Contains(t, out, "--force", arg)
+	}
+
+	// nothing has been changed
+	out, err := ts.run("show -o fixed/secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "moar", out)
+
+	t.Run("global force", func(t *testing.T) {
+		_, err := ts.runNoInput("--force copy fixed/secret foo/bar")
+		assert.NoError(t, err)
+		_, err = ts.runNoInput("delete foo/bar", "GOPASS_FORCE=true")
+		assert.NoError(t, err)
+
+		_, err = ts.run("show -o foo/bar")
+		assert.Error(t, err)
+	})
+
+	t.Run("force shows no secrets", func(t *testing.T) {
+		out, err := ts.runNoInput("show --safe fixed/secret", "GOPASS_FORCE=true")
+		assert.NoError(t, err)
+		assert.NotContains(t, out, "moar")
+	})
+
+	t.Run("no interaction", func(t *testing.T) {
+		out, err := ts.runNoInput("delete fixed/secret", "GOPASS_NO_INTERACTION=true")
+		assert.Error(t, err)
+		assert.Contains(t, out, "--force")
+	})
+}
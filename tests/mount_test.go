@@ -22,9 +22,7 @@ func TestSingleMount(t *testing.T) {
 
 	out, err = ts.run("mounts")
 	assert.NoError(t, err)
-	want := "gopass (" + ts.storeDir("root") + ")\n"
-	want += "└── mnt/\n    └── m1 (" + ts.storeDir("m1") + ")"
-	assert.Equal(t, strings.TrimSpace(want), out)
+	assert.Equal(t, mountsTable(ts), out)
 
 	out, err = ts.run("show mnt/m1/secret")
 	assert.Error(t, err)
@@ -78,14 +76,12 @@ func TestMountShadowing(t *testing.T) {
 	// check the mount is there
 	out, err = ts.run("mounts")
 	assert.NoError(t, err)
-	want := "gopass (" + ts.storeDir("root") + ")\n"
-	want += "└── mnt/\n    └── m1 (" + ts.storeDir("m1") + ")"
-	assert.Equal(t, strings.TrimSpace(want), out)
+	assert.Equal(t, mountsTable(ts), out)
 
 	// check that the mount is not containing our shadowed secret
 	out, err = ts.run("show -f mnt/m1/secret")
 	assert.Error(t, err)
-	assert.Contains(t, out, "entry is not in the password store")
+	assert.Contains(t, out, "entry is shadowed by mount 'mnt/m1'")
 
 	// insert some secret at the place that is shadowed by the mount
 	_, err = ts.runCmd([]string{ts.Binary, "insert", "mnt/m1/secret"}, []byte("food"))
@@ -224,3 +220,12 @@ gopass
 	assert.NoError(t, err)
 	assert.Equal(t, strings.TrimSpace(list), out)
 }
+
+// mountsTable returns the output of gopass mounts with the root store and the
+// mount mnt/m1
+func mountsTable(ts *tester) string {
+	root := ts.storeDir("root")
+	return fmt.Sprintf("MOUNT   %-*s  CRYPTO  STORAGE  MODE\n", len(root), "PATH") +
+		fmt.Sprintf("<root>  %s  gpg     fs       rw\n", root) +
+		fmt.Sprintf("mnt/m1  %-*s  gpg     fs       rw", len(root), ts.storeDir("m1"))
+}
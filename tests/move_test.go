@@ -1,10 +1,13 @@
 package tests
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMove(t *testing.T) {
@@ -42,3 +45,90 @@ func TestMove(t *testing.T) {
 	_, err = ts.run("show -f baz")
 	assert.NoError(t, err)
 }
+
+func TestMoveReencrypt(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	ts.initStore()
+
+	// a second key for the team store
+	_, err := ts.runCmd([]string{"gpg", "--homedir", ts.gpgDir(), "--batch", "--passphrase", "", "--quick-gen-key", "Team <team@example.com>", "future-default", "default", "never"}, nil)
+	require.NoError(t, err)
+	teamKey := encryptionKeys(t, ts, "team@example.com")[0]
+	ourKey := encryptionKeys(t, ts, keyID)[0]
+
+	out, err := ts.run("init --store team --path " + ts.storeDir("team") + " --storage=fs team@example.com")
+	require.NoError(t, err, out)
+
+	_, err = ts.runWithInput("insert -f personal/foo", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ourKey}, pkeskRecipients(t, ts, filepath.Join(ts.storeDir("root"), "personal", "foo.gpg")))
+
+	// moving into the team store re-encrypts for the team
+	out, err = ts.run("mv personal/foo team/foo")
+	require.NoError(t, err, out)
+	assert.Equal(t, []string{teamKey}, pkeskRecipients(t, ts, filepath.Join(ts.storeDir("team"), "foo.gpg")))
+
+	out, err = ts.run("show -o team/foo")
+	require.NoError(t, err, out)
+	assert.Equal(t, "secret", out)
+
+	// and copying it out of the team store for us only
+	out, err = ts.run("cp team/foo personal/bar")
+	require.NoError(t, err, out)
+	assert.Equal(t, []string{ourKey}, pkeskRecipients(t, ts, filepath.Join(ts.storeDir("root"), "personal", "bar.gpg")))
+
+	// whole subtrees are re-encrypted per entry
+	_, err = ts.runWithInput("insert -f personal/sub/baz", "other")
+	require.NoError(t, err)
+	out, err = ts.run("mv personal/sub team/")
+	require.NoError(t, err, out)
+	assert.Equal(t, []string{teamKey}, pkeskRecipients(t, ts, filepath.Join(ts.storeDir("team"), "sub", "baz.gpg")))
+
+	// moves within the same scope keep the encrypted secret with --force
+	before, err := os.ReadFile(filepath.Join(ts.storeDir("team"), "foo.gpg"))
+	require.NoError(t, err)
+	out, err = ts.run("mv -f team/foo team/zab")
+	require.NoError(t, err, out)
+	after, err := os.ReadFile(filepath.Join(ts.storeDir("team"), "zab.gpg"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+// encryptionKeys returns the long key IDs of the encryption subkeys of a key
+func encryptionKeys(t *testing.T, ts *tester, id string) []string {
+	t.Helper()
+
+	out, err := ts.runCmd([]string{"gpg", "--homedir", ts.gpgDir(), "--batch", "--with-colons", "--list-keys", id}, nil)
+	require.NoError(t, err, out)
+
+	var keys []string
+	for _, line := range strings.Split(out, "\n") {
+		f := strings.Split(line, ":")
+		if len(f) > 11 && f[0] == "sub" && strings.Contains(f[11], "e") {
+			keys = append(keys, f[4])
+		}
+	}
+	require.NotEmpty(t, keys)
+	return keys
+}
+
+// pkeskRecipients returns the key IDs a file is encrypted for
+func pkeskRecipients(t *testing.T, ts *tester, fn string) []string {
+	t.Helper()
+
+	out, err := ts.runCmd([]string{"gpg", "--homedir", ts.gpgDir(), "--batch", "--list-only", "--list-packets", fn}, nil)
+	require.NoError(t, err, out)
+
+	var keys []string
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, ":pubkey enc packet:") {
+			continue
+		}
+		if i := strings.Index(line, "keyid "); i > 0 {
+			keys = append(keys, strings.TrimSpace(line[i+len("keyid "):]))
+		}
+	}
+	return keys
+}
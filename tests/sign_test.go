@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignCommits(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	for _, k := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(k+"_NAME", "John Doe")
+		t.Setenv(k+"_EMAIL", "john.doe@gopass.pw")
+	}
+
+	out, err := ts.run("init --crypto=gpgcli --storage=gitfs " + keyID)
+	require.NoError(t, err, out)
+
+	out, err = ts.run("config signcommits true")
+	require.NoError(t, err, out)
+
+	out, err = ts.runCmd([]string{ts.Binary, "insert", "foo"}, []byte("bar"))
+	require.NoError(t, err, out)
+
+	// the commits created by init were not signed
+	out, err = ts.run("fsck --verify")
+	assert.Error(t, err)
+	assert.Contains(t, out, "Add current content of password store")
+	assert.Contains(t, out, "unsigned")
+	assert.NotContains(t, out, "Save secret to foo")
+}
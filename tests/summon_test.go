@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummon(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	ts.initStore()
+
+	_, err := ts.runWithInput("insert -m deploy/db", "s3cret\nuser: admin\n")
+	require.NoError(t, err)
+
+	summon := func(arg string) ([]byte, error) {
+		cmd := exec.Command(ts.Binary, "summon", arg)
+		cmd.Dir = ts.workDir()
+		return cmd.Output()
+	}
+
+	t.Run("password", func(t *testing.T) {
+		out, err := summon("deploy/db")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", string(out))
+	})
+
+	t.Run("key", func(t *testing.T) {
+		out, err := summon("deploy/db#user")
+		require.NoError(t, err)
+		assert.Equal(t, "admin", string(out))
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		out, err := summon("deploy/web")
+		require.Error(t, err)
+		ee, ok := err.(*exec.ExitError)
+		require.True(t, ok)
+		assert.Equal(t, 1, ee.ExitCode())
+		assert.Equal(t, "", string(out))
+		assert.Contains(t, string(ee.Stderr), "not in the password store")
+	})
+}
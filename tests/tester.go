@@ -67,13 +67,14 @@ func newTester(t *testing.T) *tester {
 	ts.tempDir = td
 
 	// prepare ENVIRONMENT
-	ts.resetFn = gptest.UnsetVars("GNUPGHOME", "GOPASS_DEBUG", "NO_COLOR", "GOPASS_CONFIG", "GOPASS_NO_NOTIFY", "GOPASS_HOMEDIR")
+	ts.resetFn = gptest.UnsetVars("GNUPGHOME", "GOPASS_DEBUG", "NO_COLOR", "GOPASS_CONFIG", "GOPASS_NO_NOTIFY", "GOPASS_HOMEDIR", "GOPASS_SYSTEM_CONFIG")
 	require.NoError(t, os.Setenv("GNUPGHOME", ts.gpgDir()))
 	require.NoError(t, os.Setenv("GOPASS_DEBUG", ""))
 	require.NoError(t, os.Setenv("NO_COLOR", "true"))
 	require.NoError(t, os.Setenv("GOPASS_CONFIG", ts.gopassConfig()))
 	require.NoError(t, os.Setenv("GOPASS_NO_NOTIFY", "true"))
 	require.NoError(t, os.Setenv("GOPASS_HOMEDIR", td))
+	require.NoError(t, os.Setenv("GOPASS_SYSTEM_CONFIG", filepath.Join(td, "system-config.yml")))
 
 	// write config
 	require.NoError(t, os.MkdirAll(filepath.Dir(ts.gopassConfig()), 0700))
@@ -204,6 +205,6 @@ func (ts *tester) initSecrets(prefix string) {
 	out, err = ts.runCmd([]string{ts.Binary, "insert", prefix + "fixed/secret"}, []byte("moar"))
 	require.NoError(ts.t, err, "failed to insert password:\n%s", out)
 
-	out, err = ts.runCmd([]string{ts.Binary, "insert", prefix + "fixed/twoliner"}, []byte("and\nmore stuff"))
+	out, err = ts.runCmd([]string{ts.Binary, "insert", "-m", prefix + "fixed/twoliner"}, []byte("and\nmore stuff"))
 	require.NoError(ts.t, err, "failed to insert password:\n%s", out)
 }
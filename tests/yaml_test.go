@@ -48,7 +48,7 @@ func TestYAMLAndSecret(t *testing.T) {
 	t.Run("show the whole secret", func(t *testing.T) {
 		out, err := ts.run("show foo/bar")
 		assert.NoError(t, err)
-		assert.Equal(t, "baz: moar\npassword: moar\nbody", out)
+		assert.Equal(t, "password: moar\nbaz: moar\nbody", out)
 	})
 }
 
@@ -77,7 +77,7 @@ url: http://www.test.com/`
 	})
 
 	t.Run("insert new secret", func(t *testing.T) {
-		_, err := ts.runCmd([]string{ts.Binary, "insert", "foo/bar"}, []byte(testBody))
+		_, err := ts.runCmd([]string{ts.Binary, "insert", "-m", "foo/bar"}, []byte(testBody))
 		assert.NoError(t, err)
 	})
 
@@ -86,3 +86,32 @@ url: http://www.test.com/`
 		assert.NoError(t, err)
 	})
 }
+
+func TestNestedYAML(t *testing.T) {
+	ts := newTester(t)
+	defer ts.teardown()
+
+	ts.initStore()
+
+	_, err := ts.runCmd([]string{ts.Binary, "insert", "-m", "foo/db"}, []byte("s3cret\n---\ndb:\n  host: localhost\n"))
+	require.NoError(t, err)
+
+	t.Run("show a nested key", func(t *testing.T) {
+		out, err := ts.run("show -f foo/db db.host")
+		assert.NoError(t, err)
+		assert.Equal(t, "localhost", out)
+	})
+
+	t.Run("insert a nested key", func(t *testing.T) {
+		_, err := ts.run("insert --key db.port foo/db 5432")
+		assert.NoError(t, err)
+
+		out, err := ts.run("show -f --key db.port foo/db")
+		assert.NoError(t, err)
+		assert.Equal(t, "5432", out)
+
+		out, err = ts.run("show -f foo/db")
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cret\n---\ndb:\n  host: localhost\n  port: 5432", out)
+	})
+}